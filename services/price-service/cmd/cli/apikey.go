@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/spf13/cobra"
+)
+
+var (
+	apikeyCreateName      string
+	apikeyCreateRateLimit float64
+)
+
+// apikeyCmd groups commands managing public API keys
+var apikeyCmd = &cobra.Command{
+	Use:   "apikey",
+	Short: "Manage public API keys",
+}
+
+// apikeyCreateCmd creates a public API key
+var apikeyCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a public API key",
+	Long: `Creates a key for the read-only /api/v1 routes and prints it. Only the
+key's hash is stored, so the key cannot be shown again; create a new one if it
+is lost. Requests carry the key in the X-API-Key header.`,
+	Example: `  price-service apikey create --name acme
+  price-service apikey create --name acme --rate-limit 25`,
+	Args: cobra.NoArgs,
+	RunE: runAPIKeyCreate,
+}
+
+// apikeyListCmd lists public API keys
+var apikeyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List public API keys",
+	Args:  cobra.NoArgs,
+	RunE:  runAPIKeyList,
+}
+
+// apikeyRevokeCmd revokes a public API key
+var apikeyRevokeCmd = &cobra.Command{
+	Use:   "revoke <id>",
+	Short: "Revoke a public API key",
+	Long: `Revokes a key by its ID. Servers trust a key they looked up for up to a
+minute, so requests with it may succeed for a minute after revoking.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAPIKeyRevoke,
+}
+
+func init() {
+	rootCmd.AddCommand(apikeyCmd)
+	apikeyCmd.AddCommand(apikeyCreateCmd)
+	apikeyCmd.AddCommand(apikeyListCmd)
+	apikeyCmd.AddCommand(apikeyRevokeCmd)
+
+	apikeyCreateCmd.Flags().StringVar(&apikeyCreateName, "name", "", "Name of the client the key is for (required)")
+	apikeyCreateCmd.Flags().Float64Var(&apikeyCreateRateLimit, "rate-limit", 0, "Requests per second allowed (default 10)")
+	_ = apikeyCreateCmd.MarkFlagRequired("name")
+}
+
+func runAPIKeyCreate(cmd *cobra.Command, args []string) error {
+	var rateLimit *float64
+	if apikeyCreateRateLimit > 0 {
+		rateLimit = &apikeyCreateRateLimit
+	}
+
+	key, apiKey, err := database.CreateAPIKey(context.Background(), apikeyCreateName, rateLimit)
+	if err != nil {
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	fmt.Printf("Created API key %s for %s\n", apiKey.ID, apiKey.Name)
+	fmt.Printf("Key (shown only once): %s\n", key)
+	return nil
+}
+
+func runAPIKeyList(cmd *cobra.Command, args []string) error {
+	keys, err := database.ListAPIKeys(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to list API keys: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tPREFIX\tRATE LIMIT\tLAST USED\tREVOKED")
+	for _, k := range keys {
+		rateLimit, lastUsed, revoked := "default", "never", "-"
+		if k.RateLimit != nil {
+			rateLimit = fmt.Sprintf("%g/s", *k.RateLimit)
+		}
+		if k.LastUsedAt != nil {
+			lastUsed = k.LastUsedAt.Format("2006-01-02 15:04")
+		}
+		if k.RevokedAt != nil {
+			revoked = k.RevokedAt.Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", k.ID, k.Name, k.KeyPrefix, rateLimit, lastUsed, revoked)
+	}
+	return w.Flush()
+}
+
+func runAPIKeyRevoke(cmd *cobra.Command, args []string) error {
+	revoked, err := database.RevokeAPIKey(context.Background(), args[0])
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	if !revoked {
+		return fmt.Errorf("no active API key %s", args[0])
+	}
+
+	logger.Info().Str("id", args[0]).Msg("API key revoked")
+	return nil
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	archiveRunID  string
+	archiveFileID string
+	archiveOutDir string
+
+	gcRetentionDays int
+	gcKeepPerChain  int
+	gcDryRun        bool
+)
+
+// archiveCmd groups commands operating on archived raw files
+var archiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "Inspect and maintain archived raw files",
+	Long: `Commands for the raw file archive. Every file downloaded during ingestion is
+stored in archive storage and tracked in the archives table.`,
+}
+
+// archiveFetchCmd copies an archived raw file to local disk
+var archiveFetchCmd = &cobra.Command{
+	Use:   "fetch",
+	Short: "Download the raw archived file of an ingestion file",
+	Long: `Resolve the archive an ingestion file was parsed from and copy the raw file
+from archive storage to local disk for inspection. The checksum is verified
+against the archive record before the file is written.`,
+	Example: `  price-service archive fetch --run run_abc123 --file igf_def456
+  price-service archive fetch --run run_abc123 --file igf_def456 --out /tmp/inspect`,
+	Args: cobra.NoArgs,
+	RunE: runArchiveFetch,
+}
+
+// archiveGCCmd applies retention rules to archived raw files
+var archiveGCCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Delete archived raw files past their retention",
+	Long: `Apply retention rules to the raw file archive. Archives downloaded more than
+--retention-days ago are deleted from storage and from the archives table, except
+the newest --keep-per-chain archives of every chain. Runs and retailer items that
+referenced a deleted archive keep their data with the archive link cleared.
+
+Defaults come from storage.retention_days and storage.retention_keep_per_chain.`,
+	Example: `  price-service archive gc --dry-run
+  price-service archive gc --retention-days 30 --keep-per-chain 3`,
+	Args: cobra.NoArgs,
+	RunE: runArchiveGC,
+}
+
+func init() {
+	rootCmd.AddCommand(archiveCmd)
+	archiveCmd.AddCommand(archiveFetchCmd)
+	archiveCmd.AddCommand(archiveGCCmd)
+
+	archiveFetchCmd.Flags().StringVar(&archiveRunID, "run", "", "Ingestion run ID")
+	archiveFetchCmd.Flags().StringVar(&archiveFileID, "file", "", "Ingestion file ID")
+	archiveFetchCmd.Flags().StringVar(&archiveOutDir, "out", ".", "Directory to write the file to")
+	archiveFetchCmd.MarkFlagRequired("run")
+	archiveFetchCmd.MarkFlagRequired("file")
+
+	archiveGCCmd.Flags().IntVar(&gcRetentionDays, "retention-days", 0, "Delete archives older than this many days (default from config)")
+	archiveGCCmd.Flags().IntVar(&gcKeepPerChain, "keep-per-chain", -1, "Always keep this many newest archives per chain (default from config)")
+	archiveGCCmd.Flags().BoolVar(&gcDryRun, "dry-run", false, "List archives that would be deleted without deleting them")
+}
+
+// openArchiveStorage opens the configured archive storage backend
+func openArchiveStorage() (storage.Storage, error) {
+	return storage.Open(cfg.Storage)
+}
+
+func runArchiveFetch(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	archive, err := database.GetArchiveForIngestionFile(ctx, archiveRunID, archiveFileID)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("no archive found for file %s in run %s", archiveFileID, archiveRunID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to resolve archive: %w", err)
+	}
+
+	store, err := openArchiveStorage()
+	if err != nil {
+		return fmt.Errorf("failed to open archive storage: %w", err)
+	}
+
+	content, err := store.Get(ctx, archive.ArchivePath)
+	if err != nil {
+		return fmt.Errorf("failed to read archive %s: %w", archive.ID, err)
+	}
+
+	if checksum := storage.ComputeChecksum(content); checksum != archive.Checksum {
+		return fmt.Errorf("checksum mismatch for archive %s: expected %s, got %s", archive.ID, archive.Checksum, checksum)
+	}
+
+	if err := os.MkdirAll(archiveOutDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	outPath := filepath.Join(archiveOutDir, filepath.Base(archive.Filename))
+	if err := os.WriteFile(outPath, content, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outPath, err)
+	}
+
+	logger.Info().
+		Str("archive", archive.ID).
+		Str("chain", archive.ChainSlug).
+		Int("bytes", len(content)).
+		Str("path", outPath).
+		Msg("Fetched archived file")
+
+	return nil
+}
+
+func runArchiveGC(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	retentionDays := gcRetentionDays
+	if retentionDays == 0 {
+		retentionDays = cfg.Storage.RetentionDays
+	}
+	if retentionDays <= 0 {
+		return fmt.Errorf("retention days must be positive, got %d", retentionDays)
+	}
+
+	keepPerChain := gcKeepPerChain
+	if keepPerChain < 0 {
+		keepPerChain = cfg.Storage.RetentionKeepPerChain
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -retentionDays)
+	archives, err := database.GetExpiredArchives(ctx, cutoff, keepPerChain)
+	if err != nil {
+		return fmt.Errorf("failed to list expired archives: %w", err)
+	}
+
+	logger.Info().
+		Time("cutoff", cutoff).
+		Int("keepPerChain", keepPerChain).
+		Int("expired", len(archives)).
+		Msg("Applying archive retention")
+
+	if gcDryRun {
+		displayExpiredArchives(archives)
+		return nil
+	}
+
+	store, err := openArchiveStorage()
+	if err != nil {
+		return fmt.Errorf("failed to open archive storage: %w", err)
+	}
+
+	var deleted, failed int
+	var freedBytes int64
+	for _, archive := range archives {
+		// Delete the raw file first so a failure leaves the record for the next run
+		if err := store.Delete(ctx, archive.ArchivePath); err != nil {
+			logger.Error().Err(err).Str("archive", archive.ID).Msg("Failed to delete archived file")
+			failed++
+			continue
+		}
+		if err := database.DeleteArchive(ctx, archive.ID); err != nil {
+			logger.Error().Err(err).Str("archive", archive.ID).Msg("Failed to delete archive record")
+			failed++
+			continue
+		}
+		deleted++
+		if archive.FileSize != nil {
+			freedBytes += *archive.FileSize
+		}
+	}
+
+	logger.Info().
+		Int("deleted", deleted).
+		Int("failed", failed).
+		Int64("freedBytes", freedBytes).
+		Msg("Archive retention complete")
+
+	if failed > 0 {
+		return fmt.Errorf("failed to delete %d archive(s)", failed)
+	}
+
+	return nil
+}
+
+func displayExpiredArchives(archives []database.Archive) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "ARCHIVE ID\tCHAIN\tDOWNLOADED\tSIZE\tPATH")
+	fmt.Fprintln(w, "----------\t-----\t----------\t----\t----")
+
+	for _, a := range archives {
+		size := "-"
+		if a.FileSize != nil {
+			size = fmt.Sprintf("%d", *a.FileSize)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", a.ID, a.ChainSlug, a.DownloadedAt.Format("2006-01-02 15:04"), size, a.ArchivePath)
+	}
+
+	w.Flush()
+}
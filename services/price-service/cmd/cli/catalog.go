@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/matching"
+	"github.com/spf13/cobra"
+)
+
+var (
+	catalogImportSource string
+	catalogImportPrefer string
+	catalogImportDryRun bool
+)
+
+// catalogCmd groups commands maintaining the canonical product catalog
+var catalogCmd = &cobra.Command{
+	Use:   "catalog",
+	Short: "Maintain the canonical product catalog",
+}
+
+// catalogImportCmd seeds canonical products from an external catalog
+var catalogImportCmd = &cobra.Command{
+	Use:   "import <file.csv>",
+	Short: "Import products with verified barcodes from an external catalog",
+	Long: `Import an external product catalog, such as a GS1 Croatia export or an open
+dataset, from a CSV file with a header row. Columns are recognized by their
+usual names (GTIN/EAN/code, name/naziv, brand/marka, net content/quantity and
+its unit, category); GS1 unit codes like GRM, MLT and LTR are understood.
+
+A product is created for each barcode not known yet, so barcode matching links
+retailer items carrying it at once. For a barcode already mapped to a product,
+missing brand, net content and category are filled in and a differing name is
+added as an alias. Differing brands and net contents are recorded in
+catalog_import_conflicts and resolved by --prefer; a name sharing no word with
+the product's, which suggests a wrongly mapped barcode, is recorded and never
+applied. Every imported barcode is marked verified by --source.`,
+	Example: `  price-service catalog import gs1-hr.csv --source gs1-hr --dry-run
+  price-service catalog import off-hr.csv --source openfoodfacts --prefer existing`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCatalogImport,
+}
+
+func init() {
+	rootCmd.AddCommand(catalogCmd)
+	catalogCmd.AddCommand(catalogImportCmd)
+
+	catalogImportCmd.Flags().StringVar(&catalogImportSource, "source", "", "Name of the catalog, recorded with verified barcodes and conflicts")
+	catalogImportCmd.Flags().StringVar(&catalogImportPrefer, "prefer", matching.PreferExisting, "Value kept when brands or net contents disagree: existing or catalog")
+	catalogImportCmd.Flags().BoolVar(&catalogImportDryRun, "dry-run", false, "Report what would change without writing")
+	catalogImportCmd.MarkFlagRequired("source")
+}
+
+func runCatalogImport(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if catalogImportPrefer != matching.PreferExisting && catalogImportPrefer != matching.PreferCatalog {
+		return fmt.Errorf("invalid --prefer %q: must be %s or %s", catalogImportPrefer, matching.PreferExisting, matching.PreferCatalog)
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to open catalog: %w", err)
+	}
+	defer file.Close()
+
+	records, invalid, err := matching.ReadCatalogCSV(file)
+	if err != nil {
+		return fmt.Errorf("failed to read catalog: %w", err)
+	}
+
+	result, err := matching.ImportCatalog(ctx, database.Pool(), records, matching.CatalogImportOptions{
+		Source: catalogImportSource,
+		Prefer: catalogImportPrefer,
+		DryRun: catalogImportDryRun,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to import catalog: %w", err)
+	}
+
+	logger.Info().
+		Str("source", catalogImportSource).
+		Bool("dryRun", catalogImportDryRun).
+		Int("records", result.Records).
+		Int("invalid", invalid).
+		Int("created", result.Created).
+		Int("updated", result.Updated).
+		Int("verified", result.Verified).
+		Int("aliases", result.Aliases).
+		Int("conflicts", result.Conflicts).
+		Msg("Catalog import complete")
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/kosarica/price-service/internal/scaffold"
+	"github.com/spf13/cobra"
+)
+
+var (
+	chainInitSlug    string
+	chainInitName    string
+	chainInitType    string
+	chainInitBaseURL string
+	chainInitRoot    string
+)
+
+// chainCmd groups chain management commands
+var chainCmd = &cobra.Command{
+	Use:   "chain",
+	Short: "Manage retail chain adapters",
+}
+
+// chainInitCmd scaffolds a new chain adapter
+var chainInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Scaffold a new chain adapter",
+	Long: `Scaffold everything needed to onboard a new retail chain:
+
+  - internal/adapters/chains/<slug>.go        adapter from the CSV, XML or JSON template
+  - internal/adapters/config/config.go        ChainID constant and ChainConfigs stub
+  - internal/adapters/registry/registry.go    GetOrInit and InitializeDefaultAdapters entries
+  - internal/chains/chains.go                 slug added to DefaultChains
+  - tests/testdata/<slug>/                    directory for fixture price lists
+  - tests/unit/<slug>_golden_test.go          golden test over the fixtures
+
+Run it from the price-service module root (or pass --root).`,
+	Example: `  price-service chain init --slug mlinar
+  price-service chain init --slug mlinar --name "Mlinar" --type xml --base-url https://www.mlinar.hr/cjenici`,
+	Args: cobra.NoArgs,
+	RunE: runChainInit,
+}
+
+func init() {
+	rootCmd.AddCommand(chainCmd)
+	chainCmd.AddCommand(chainInitCmd)
+
+	chainInitCmd.Flags().StringVar(&chainInitSlug, "slug", "", "Chain slug (lowercase, e.g. mlinar)")
+	chainInitCmd.Flags().StringVar(&chainInitName, "name", "", "Display name (defaults to the capitalized slug)")
+	chainInitCmd.Flags().StringVar(&chainInitType, "type", "csv", "Primary file type: csv, xml or json")
+	chainInitCmd.Flags().StringVar(&chainInitBaseURL, "base-url", "", "Price list portal URL")
+	chainInitCmd.Flags().StringVar(&chainInitRoot, "root", ".", "Path to the price-service module root")
+	chainInitCmd.MarkFlagRequired("slug")
+}
+
+func runChainInit(cmd *cobra.Command, args []string) error {
+	result, err := scaffold.Run(chainInitRoot, scaffold.Options{
+		Slug:     chainInitSlug,
+		Name:     chainInitName,
+		FileType: chainInitType,
+		BaseURL:  chainInitBaseURL,
+	})
+	if result != nil {
+		for _, path := range result.Modified {
+			fmt.Printf("  modified  %s\n", path)
+		}
+		for _, path := range result.Created {
+			fmt.Printf("  created   %s\n", path)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("chain init failed: %w", err)
+	}
+
+	fmt.Printf(`
+Chain %s scaffolded. Next steps:
+  1. Fill in the column mapping and BaseURL for the portal
+  2. Drop sample price lists into tests/testdata/%s/
+  3. go test ./tests/unit -run Golden -update   (then review the .golden.json files)
+  4. Register the chain via POST /internal/admin/chains and add it to the frontend
+`, chainInitSlug, chainInitSlug)
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kosarica/price-service/internal/adapters/config"
+	"github.com/kosarica/price-service/internal/adapters/registry"
+	"github.com/kosarica/price-service/internal/daily"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/pkg/clock"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dailyDate   string
+	dailyChains []string
+	dailyForce  bool
+	dailyOutput string
+)
+
+// dailyCmd represents the daily command
+var dailyCmd = &cobra.Command{
+	Use:   "daily",
+	Short: "Run the nightly pipeline",
+	Long: `Run the nightly pipeline in order: discover and ingest every chain, run
+barcode and AI product matching, warm the server's price cache, roll up the
+ingestion stats, compute the anchor price compliance report and check the day's
+runs for suspect ones.
+
+Each step's status is recorded in pipeline_runs. Running the command again for
+the same date skips the steps that completed and retries the others; use
+--force to run every step again. A failing step does not stop the ones after
+it, and the command fails if any step failed.`,
+	Example: `  price-service daily
+  price-service daily --date 2026-01-19
+  price-service daily --chain konzum --chain lidl
+  price-service daily --force --output json`,
+	Args: cobra.NoArgs,
+	RunE: runDaily,
+}
+
+func init() {
+	rootCmd.AddCommand(dailyCmd)
+
+	dailyCmd.Flags().StringVar(&dailyDate, "date", "", "Day of the pipeline run, also used for discovery (format: YYYY-MM-DD, defaults to today)")
+	dailyCmd.Flags().StringSliceVar(&dailyChains, "chain", nil, "Chains to ingest (defaults to all)")
+	dailyCmd.Flags().BoolVar(&dailyForce, "force", false, "Run steps that already completed for the date again")
+	dailyCmd.Flags().StringVar(&dailyOutput, "output", "table", "Output format: table or json")
+}
+
+func runDaily(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	output := strings.ToLower(dailyOutput)
+	if output != "table" && output != "json" {
+		return fmt.Errorf("invalid output format: %s (use 'table' or 'json')", dailyOutput)
+	}
+
+	// Today in the service's time zone, held as midnight UTC like the dates
+	// of pipeline_runs
+	day := clock.Today()
+	if dailyDate != "" {
+		day = dailyDate
+	}
+	date, err := time.Parse(time.DateOnly, day)
+	if err != nil {
+		return fmt.Errorf("invalid date %q (use YYYY-MM-DD)", dailyDate)
+	}
+
+	chains := dailyChains
+	if len(chains) == 0 {
+		chains = validChains()
+	}
+	for _, chainID := range chains {
+		if !config.IsValidChainID(chainID) {
+			return fmt.Errorf("invalid chain ID: %s\nValid chains: %s", chainID, strings.Join(validChains(), ", "))
+		}
+	}
+
+	// Initialize chain registry
+	if err := registry.InitializeDefaultAdapters(); err != nil {
+		return fmt.Errorf("failed to initialize chain registry: %w", err)
+	}
+
+	steps := daily.Steps(database.Pool(), logger, date, daily.Options{
+		Chains:           chains,
+		Date:             dailyDate,
+		BarcodeBatchSize: cfg.Daily.BarcodeBatchSize,
+		ServerURL:        cfg.Daily.ServerURL,
+		APIKey:           os.Getenv("INTERNAL_API_KEY"),
+		AnchorMinAgeDays: cfg.AnchorCompliance.MinAnchorAgeDays,
+	})
+
+	logger.Info().Str("date", date.Format(time.DateOnly)).Int("steps", len(steps)).Msg("Starting daily pipeline")
+	results, err := daily.NewRunner(database.Pool(), logger).Run(ctx, date, steps, dailyForce)
+	if errors.Is(err, daily.ErrAlreadyRunning) {
+		return err
+	}
+
+	if output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if encErr := encoder.Encode(results); encErr != nil {
+			return encErr
+		}
+	} else {
+		displayDailyResults(results)
+	}
+
+	if err != nil {
+		return fmt.Errorf("daily pipeline stopped: %w", err)
+	}
+	if failed := daily.Failed(results); len(failed) > 0 {
+		return fmt.Errorf("daily pipeline steps failed: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func displayDailyResults(results []daily.StepResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "STEP\tSTATUS\tATTEMPTS\tDURATION\tERROR")
+	fmt.Fprintln(w, "----\t------\t--------\t--------\t-----")
+
+	for _, r := range results {
+		status := strings.ToUpper(r.Status)
+		duration := r.Duration.Round(time.Millisecond).String()
+		if r.Reused {
+			status += " (earlier run)"
+			duration = "-"
+		}
+		errMsg := r.Error
+		if errMsg == "" {
+			errMsg = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", r.Step, status, r.Attempts, duration, errMsg)
+	}
+
+	w.Flush()
+}
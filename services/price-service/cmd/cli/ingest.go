@@ -14,8 +14,10 @@ import (
 )
 
 var (
-	ingestDate string
-	ingestAll  bool
+	ingestDate        string
+	ingestAll         bool
+	ingestChain       string
+	ingestFromArchive bool
 )
 
 // ingestCmd represents the ingest command
@@ -26,10 +28,15 @@ var ingestCmd = &cobra.Command{
 retail chain. The pipeline will discover available files, download them, parse the content,
 and persist the normalized data to the database.
 
-Use --all to ingest all chains at once.`,
+Use --all to ingest all chains at once.
+
+Use --from-archive to reconstruct a run from the raw files archived on --date
+instead of discovering and downloading them, e.g. to reprocess files after a
+parser fix or where the chains' portals cannot be reached.`,
 	Example: `  price-service ingest konzum
   price-service ingest lidl --date 2026-01-19
-  price-service ingest --all`,
+  price-service ingest --all
+  price-service ingest --from-archive --chain lidl --date 2025-03-01`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runIngest,
 }
@@ -39,6 +46,8 @@ func init() {
 
 	ingestCmd.Flags().StringVar(&ingestDate, "date", "", "Target date for discovery (format: YYYY-MM-DD, defaults to today)")
 	ingestCmd.Flags().BoolVar(&ingestAll, "all", false, "Ingest all chains")
+	ingestCmd.Flags().StringVar(&ingestChain, "chain", "", "Chain to ingest (instead of the <chain> argument)")
+	ingestCmd.Flags().BoolVar(&ingestFromArchive, "from-archive", false, "Replay the files archived on --date instead of downloading")
 }
 
 func runIngest(cmd *cobra.Command, args []string) error {
@@ -51,16 +60,36 @@ func runIngest(cmd *cobra.Command, args []string) error {
 		chains = config.ChainIDs
 		logger.Info().Msgf("Ingesting all %d chains", len(chains))
 	} else {
-		if len(args) == 0 {
+		chainID := ingestChain
+		if len(args) > 0 {
+			chainID = args[0]
+		}
+		if chainID == "" {
 			return fmt.Errorf("either specify <chain> or use --all flag")
 		}
-		chainID := args[0]
 		if !config.IsValidChainID(chainID) {
 			return fmt.Errorf("invalid chain ID: %s\nValid chains: %s", chainID, strings.Join(validChains(), ", "))
 		}
 		chains = []config.ChainID{config.ChainID(chainID)}
 	}
 
+	// Archive replays need the day the files were archived
+	run := func(chainID string) (*pipeline.IngestionResult, error) {
+		return pipeline.Run(ctx, chainID, ingestDate)
+	}
+	if ingestFromArchive {
+		if ingestDate == "" {
+			return fmt.Errorf("--from-archive requires --date")
+		}
+		store, err := openArchiveStorage()
+		if err != nil {
+			return fmt.Errorf("failed to open archive storage: %w", err)
+		}
+		run = func(chainID string) (*pipeline.IngestionResult, error) {
+			return pipeline.RunFromArchive(ctx, chainID, ingestDate, store)
+		}
+	}
+
 	// Initialize chain registry
 	if err := registry.InitializeDefaultAdapters(); err != nil {
 		return fmt.Errorf("failed to initialize chain registry: %w", err)
@@ -72,7 +101,7 @@ func runIngest(cmd *cobra.Command, args []string) error {
 	// Process each chain
 	for _, chainID := range chains {
 		logger.Info().Str("chain", string(chainID)).Msg("Starting ingestion")
-		result, err := pipeline.Run(ctx, string(chainID), ingestDate)
+		result, err := run(string(chainID))
 		if err != nil {
 			logger.Error().Str("chain", string(chainID)).Err(err).Msg("Ingestion failed")
 			results = append(results, ingestResult{
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/spf13/cobra"
+)
+
+var (
+	itemsDedupChain  string
+	itemsDedupDryRun bool
+)
+
+// itemsCmd groups commands maintaining retailer items
+var itemsCmd = &cobra.Command{
+	Use:   "items",
+	Short: "Maintain retailer items",
+}
+
+// itemsDedupCmd merges retailer items duplicated by chains without external IDs
+var itemsDedupCmd = &cobra.Command{
+	Use:   "dedup",
+	Short: "Merge duplicate retailer items of chains without external IDs",
+	Long: `Retailer items without an external ID used to be created anew on every run.
+This command groups them by chain and identity hash (normalized name, unit and
+unit quantity), moves the references of each group's duplicates to one
+surviving item and deletes the duplicates. Every item is left with its identity
+hash, so later runs find it instead of creating another. Each identity is merged
+in its own transaction; the command is safe to interrupt and rerun.`,
+	Example: `  price-service items dedup --dry-run
+  price-service items dedup --chain lidl`,
+	Args: cobra.NoArgs,
+	RunE: runItemsDedup,
+}
+
+func init() {
+	rootCmd.AddCommand(itemsCmd)
+	itemsCmd.AddCommand(itemsDedupCmd)
+
+	itemsDedupCmd.Flags().StringVar(&itemsDedupChain, "chain", "", "Only deduplicate this chain (default all chains)")
+	itemsDedupCmd.Flags().BoolVar(&itemsDedupDryRun, "dry-run", false, "Report what would be merged without writing")
+}
+
+func runItemsDedup(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	result, err := database.DedupRetailerItems(ctx, itemsDedupChain, itemsDedupDryRun)
+	if err != nil {
+		return fmt.Errorf("failed to deduplicate retailer items: %w", err)
+	}
+
+	logger.Info().
+		Str("chain", itemsDedupChain).
+		Bool("dryRun", itemsDedupDryRun).
+		Int("identities", result.Identities).
+		Int("merged", result.Merged).
+		Int("backfilled", result.Backfilled).
+		Msg("Retailer item deduplication complete")
+
+	return nil
+}
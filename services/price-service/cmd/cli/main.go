@@ -8,6 +8,9 @@ import (
 
 	"github.com/kosarica/price-service/config"
 	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/events"
+	"github.com/kosarica/price-service/internal/logging"
+	"github.com/kosarica/price-service/internal/pkg/clock"
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
 )
@@ -46,6 +49,13 @@ func initConfig() {
 	if err != nil {
 		// Config is optional for some commands, don't fail here
 		fmt.Fprintf(os.Stderr, "Warning: failed to load config: %v\n", err)
+		return
+	}
+	// An invalid zone is fatal, as in the server, so "today" and schedule
+	// windows never differ between the two
+	if err := clock.SetZone(cfg.Timezone); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid service time zone: %v\n", err)
+		os.Exit(1)
 	}
 }
 
@@ -60,7 +70,8 @@ func persistentPreRun(cmd *cobra.Command, args []string) error {
 	logger = initLogger()
 
 	// Check if this command needs database
-	cmdNeedsDB := cmd.Name() == "ingest" || cmd.Name() == "run"
+	cmdNeedsDB := cmd.Name() == "ingest" || cmd.Name() == "run" || cmd.Name() == "daily" ||
+		(cmd.Parent() != nil && (cmd.Parent().Name() == "archive" || cmd.Parent().Name() == "rawdata" || cmd.Parent().Name() == "items" || cmd.Parent().Name() == "apikey" || cmd.Parent().Name() == "pricegroups"))
 
 	if cmdNeedsDB {
 		if cfg == nil {
@@ -70,6 +81,12 @@ func persistentPreRun(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("database initialization failed: %w", err)
 		}
 		logger.Info().Msg("Database connected")
+
+		publisher, err := events.NewPublisher(cfg.Events.Driver, cfg.Events.KafkaBrokers, cfg.Events.NATSURL, cfg.Events.Topic)
+		if err != nil {
+			return fmt.Errorf("event publisher initialization failed: %w", err)
+		}
+		events.SetDefault(publisher)
 	}
 
 	return nil
@@ -98,6 +115,14 @@ func initLogger() *zerolog.Logger {
 	}
 
 	log := zerolog.New(output).Level(level).With().Timestamp().Logger()
+	var components map[string]string
+	if cfg != nil {
+		components = cfg.Logging.Components
+	}
+	if err := logging.Configure(output, level, components); err != nil {
+		log.Warn().Err(err).Msg("Invalid component log levels, using the default level")
+		_ = logging.Configure(output, level, nil)
+	}
 	return &log
 }
 
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kosarica/price-service/internal/mappings"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mappingInferRows   int
+	mappingInferOutput string
+)
+
+// mappingCmd groups column mapping commands
+var mappingCmd = &cobra.Command{
+	Use:   "mapping",
+	Short: "Work with price file column mappings",
+}
+
+// mappingInferCmd proposes a column mapping for a sample file
+var mappingInferCmd = &cobra.Command{
+	Use:   "infer <file>",
+	Short: "Propose a column mapping for an unknown price file",
+	Long: `Profile the columns of a sample CSV or XLSX price file and propose a column mapping.
+
+Each column is classified by its values (price-like, date-like, barcode-like,
+integer or text) and matched against known Croatian and English header names.
+The proposal is a starting point for a human to confirm: review it, then store
+it as an override (PUT /internal/admin/chains/<slug>/mapping) or copy it into
+the adapter's compiled-in mapping.`,
+	Example: `  price-service mapping infer ./sample/newchain/cjenik.csv
+  price-service mapping infer ./sample/newchain/cjenik.xlsx --output json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMappingInfer,
+}
+
+func init() {
+	rootCmd.AddCommand(mappingCmd)
+	mappingCmd.AddCommand(mappingInferCmd)
+
+	mappingInferCmd.Flags().IntVar(&mappingInferRows, "rows", mappings.DefaultProfileRows, "Number of data rows to profile")
+	mappingInferCmd.Flags().StringVar(&mappingInferOutput, "output", "table", "Output format: table or json")
+}
+
+func runMappingInfer(cmd *cobra.Command, args []string) error {
+	filePath := args[0]
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	proposal, err := mappings.Infer(content, filePath, mappingInferRows)
+	if err != nil {
+		return fmt.Errorf("mapping inference failed: %w", err)
+	}
+
+	switch strings.ToLower(mappingInferOutput) {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(proposal)
+	case "table":
+		outputMappingTable(proposal)
+	default:
+		return fmt.Errorf("invalid output format: %s (use 'table' or 'json')", mappingInferOutput)
+	}
+
+	return nil
+}
+
+func outputMappingTable(p *mappings.Proposal) {
+	fmt.Printf("Format: %s  Delimiter: %q  Encoding: %s  Header: %v  Rows profiled: %d\n",
+		p.Format, p.Delimiter, p.Encoding, p.HasHeader, p.RowsRead)
+	fmt.Println(strings.Repeat("-", 60))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(w, "#\tHeader\tKind\tSamples\n")
+	for _, col := range p.Columns {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", col.Index, col.Header, col.Kind, strings.Join(col.Samples, " | "))
+	}
+	w.Flush()
+
+	fmt.Println(strings.Repeat("-", 60))
+	fields := make([]string, 0, len(p.Fields))
+	for field := range p.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintf(w, "Field\tColumn\tConfidence\tReason\n")
+	for _, field := range fields {
+		f := p.Fields[field]
+		fmt.Fprintf(w, "%s\t%s\t%.2f\t%s\n", field, f.Column, f.Confidence, f.Reason)
+	}
+	w.Flush()
+
+	if len(p.Missing) > 0 {
+		fmt.Printf("\nNo column found for required fields: %s\n", strings.Join(p.Missing, ", "))
+	}
+
+	mapping, _ := json.MarshalIndent(p.Mapping, "", "  ")
+	fmt.Printf("\nProposed override mapping:\n%s\n", mapping)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/pricegroups"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pricegroupsSimulateChain             string
+	pricegroupsSimulateHashVersion       int
+	pricegroupsSimulateIgnoreDiscountEnd bool
+	pricegroupsSimulatePriceBucketCents  int
+	pricegroupsSimulateSortByItemID      bool
+)
+
+// pricegroupsCmd groups commands inspecting price groups
+var pricegroupsCmd = &cobra.Command{
+	Use:   "pricegroups",
+	Short: "Inspect price groups",
+}
+
+// pricegroupsSimulateCmd reports how price groups would regroup under other
+// hash options
+var pricegroupsSimulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Report how many price groups would merge under other hash options",
+	Long: `Rehashes the stored prices of every price group with current member stores
+under the given hash options and reports, per chain, how many groups there
+would be and how many current groups, and their stores, would merge with
+another. Options not given are taken from the price_groups configuration,
+except the hash version, which defaults to 2. Nothing is written.
+
+A group keeps the discount window of the store that created it, so stores
+sharing a group only because the original hash ignores discount windows are
+counted as one group.`,
+	Example: `  price-service pricegroups simulate
+  price-service pricegroups simulate --chain konzum --price-bucket-cents 5`,
+	Args: cobra.NoArgs,
+	RunE: runPricegroupsSimulate,
+}
+
+func init() {
+	rootCmd.AddCommand(pricegroupsCmd)
+	pricegroupsCmd.AddCommand(pricegroupsSimulateCmd)
+
+	flags := pricegroupsSimulateCmd.Flags()
+	flags.StringVar(&pricegroupsSimulateChain, "chain", "", "Only simulate this chain (default all chains)")
+	flags.IntVar(&pricegroupsSimulateHashVersion, "hash-version", pricegroups.CanonicalHashVersion, "Hash version to simulate")
+	flags.BoolVar(&pricegroupsSimulateIgnoreDiscountEnd, "ignore-discount-end", true, "Leave discount end dates out of the hash")
+	flags.IntVar(&pricegroupsSimulatePriceBucketCents, "price-bucket-cents", 1, "Round prices to multiples of this many cents")
+	flags.BoolVar(&pricegroupsSimulateSortByItemID, "sort-by-item-id", true, "Keep one entry per item ID")
+}
+
+func runPricegroupsSimulate(cmd *cobra.Command, args []string) error {
+	opts := pricegroups.HashOptions{
+		Version:           pricegroupsSimulateHashVersion,
+		IgnoreDiscountEnd: pricegroupsSimulateIgnoreDiscountEnd,
+		PriceBucketCents:  pricegroupsSimulatePriceBucketCents,
+		SortByItemID:      pricegroupsSimulateSortByItemID,
+	}
+	if cfg != nil {
+		flags := cmd.Flags()
+		if !flags.Changed("ignore-discount-end") {
+			opts.IgnoreDiscountEnd = cfg.PriceGroups.IgnoreDiscountEnd
+		}
+		if !flags.Changed("price-bucket-cents") {
+			opts.PriceBucketCents = cfg.PriceGroups.PriceBucketCents
+		}
+		if !flags.Changed("sort-by-item-id") {
+			opts.SortByItemID = cfg.PriceGroups.SortByItemID
+		}
+	}
+
+	simulation := pricegroups.NewSimulation(opts)
+	err := database.ForEachCurrentGroupPrices(context.Background(), pricegroupsSimulateChain,
+		func(chainSlug string, storeCount int, prices []pricegroups.ItemPrice) error {
+			simulation.Add(chainSlug, storeCount, prices)
+			return nil
+		})
+	if err != nil {
+		return fmt.Errorf("failed to simulate price groups: %w", err)
+	}
+
+	fmt.Printf("Hash version %d  ignore discount end: %v  price bucket: %d cents  sort by item ID: %v\n",
+		opts.EffectiveVersion(), opts.IgnoreDiscountEnd, opts.PriceBucketCents, opts.SortByItemID)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "CHAIN\tSTORES\tGROUPS\tSIMULATED\tMERGING GROUPS\tMERGING STORES")
+	for _, r := range simulation.Results() {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\n", r.ChainSlug, r.Stores, r.Groups, r.SimulatedGroups, r.MergingGroups, r.MergingStores)
+	}
+	return w.Flush()
+}
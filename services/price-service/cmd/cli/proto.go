@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+
+	grpcserver "github.com/kosarica/price-service/internal/grpc"
+	"github.com/spf13/cobra"
+)
+
+// protoCmd prints the protobuf definitions of the gRPC API
+var protoCmd = &cobra.Command{
+	Use:   "proto",
+	Short: "Print the gRPC API's protobuf definitions",
+	Long: `Prints the .proto file of the gRPC API, built from the Go types of the
+HTTP API. The checked-in copy at proto/kosarica/price/v1/price.proto is
+regenerated with this command whenever those types change.`,
+	Example: `  price-service proto > proto/kosarica/price/v1/price.proto`,
+	Args:    cobra.NoArgs,
+	RunE:    runProto,
+}
+
+func init() {
+	rootCmd.AddCommand(protoCmd)
+}
+
+func runProto(cmd *cobra.Command, args []string) error {
+	file, err := grpcserver.ProtoFile()
+	if err != nil {
+		return fmt.Errorf("failed to build protobuf definitions: %w", err)
+	}
+	fmt.Print(file)
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rawdataBatchSize int
+	rawdataMaxRows   int
+)
+
+// rawdataCmd groups commands operating on stored raw source rows
+var rawdataCmd = &cobra.Command{
+	Use:   "rawdata",
+	Short: "Maintain stored raw source rows",
+}
+
+// rawdataMigrateCmd moves inline raw data into compressed raw payloads
+var rawdataMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Move inline raw_data of failed rows into compressed raw payloads",
+	Long: `Failed rows written before raw payloads existed keep the full raw row inline
+in retailer_items_failed.raw_data. This command moves them, batch by batch, into
+the zstd-compressed raw_payloads table and clears the inline copy. It is safe to
+interrupt and rerun; every batch commits on its own.`,
+	Example: `  price-service rawdata migrate
+  price-service rawdata migrate --batch-size 5000 --max-rows 100000`,
+	Args: cobra.NoArgs,
+	RunE: runRawdataMigrate,
+}
+
+func init() {
+	rootCmd.AddCommand(rawdataCmd)
+	rawdataCmd.AddCommand(rawdataMigrateCmd)
+
+	rawdataMigrateCmd.Flags().IntVar(&rawdataBatchSize, "batch-size", 1000, "Rows migrated per transaction")
+	rawdataMigrateCmd.Flags().IntVar(&rawdataMaxRows, "max-rows", 0, "Stop after this many rows (0 migrates everything)")
+}
+
+func runRawdataMigrate(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if rawdataBatchSize <= 0 {
+		return fmt.Errorf("batch size must be positive, got %d", rawdataBatchSize)
+	}
+
+	var total database.RawPayloadMigrationResult
+	for rawdataMaxRows == 0 || total.Rows < rawdataMaxRows {
+		batchSize := rawdataBatchSize
+		if rawdataMaxRows > 0 && rawdataMaxRows-total.Rows < batchSize {
+			batchSize = rawdataMaxRows - total.Rows
+		}
+
+		batch, err := database.MigrateFailedRowRawData(ctx, batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to migrate raw data after %d rows: %w", total.Rows, err)
+		}
+		if batch.Rows == 0 {
+			break
+		}
+
+		total.Rows += batch.Rows
+		total.OriginalBytes += batch.OriginalBytes
+		total.CompressedBytes += batch.CompressedBytes
+
+		logger.Info().
+			Int("rows", total.Rows).
+			Int64("originalBytes", total.OriginalBytes).
+			Int64("compressedBytes", total.CompressedBytes).
+			Msg("Migrated raw data batch")
+	}
+
+	logger.Info().
+		Int("rows", total.Rows).
+		Int64("originalBytes", total.OriginalBytes).
+		Int64("compressedBytes", total.CompressedBytes).
+		Msg("Raw data migration complete")
+
+	return nil
+}
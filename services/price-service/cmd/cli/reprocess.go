@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kosarica/price-service/internal/adapters/config"
+	"github.com/kosarica/price-service/internal/adapters/registry"
+	"github.com/kosarica/price-service/internal/pipeline"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reprocessChain         string
+	reprocessParserVersion string
+	reprocessDryRun        bool
+)
+
+// reprocessCmd reruns files parsed by an older parser version
+var reprocessCmd = &cobra.Command{
+	Use:   "reprocess",
+	Short: "Rerun files parsed by an older parser version from their archives",
+	Long: `Find the files of a chain whose latest parse was by a parser version older
+than --parser-version and rerun them from their archived raw files in a single
+run, oldest download first. Of archived files sharing a filename only the newest
+is rerun.
+
+Without --parser-version, files older than the chain adapter's current parser
+version are rerun, which is what is needed after deploying a parser fix.`,
+	Example: `  price-service reprocess --chain lidl --parser-version "<1.2.0"
+  price-service reprocess --chain konzum --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: runReprocess,
+}
+
+func init() {
+	rootCmd.AddCommand(reprocessCmd)
+
+	reprocessCmd.Flags().StringVar(&reprocessChain, "chain", "", "Chain to reprocess")
+	reprocessCmd.Flags().StringVar(&reprocessParserVersion, "parser-version", "", `Rerun files parsed by versions older than this, as "<version" (default: the adapter's current version)`)
+	reprocessCmd.Flags().BoolVar(&reprocessDryRun, "dry-run", false, "List the files that would be rerun without rerunning them")
+	reprocessCmd.MarkFlagRequired("chain")
+}
+
+func runReprocess(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if !config.IsValidChainID(reprocessChain) {
+		return fmt.Errorf("invalid chain ID: %s\nValid chains: %s", reprocessChain, strings.Join(validChains(), ", "))
+	}
+
+	if err := registry.InitializeDefaultAdapters(); err != nil {
+		return fmt.Errorf("failed to initialize chain registry: %w", err)
+	}
+
+	filter := reprocessParserVersion
+	if filter == "" {
+		adapter, err := registry.GetAdapter(config.ChainID(reprocessChain))
+		if err != nil {
+			return err
+		}
+		versioned, ok := adapter.(interface{ ParserVersion() string })
+		if !ok {
+			return fmt.Errorf("adapter for %s has no parser version; pass --parser-version", reprocessChain)
+		}
+		filter = "<" + versioned.ParserVersion()
+	}
+	before, err := pipeline.ParseParserVersionFilter(filter)
+	if err != nil {
+		return err
+	}
+
+	files, err := pipeline.FindStaleFiles(ctx, reprocessChain, before)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		logger.Info().Str("chain", reprocessChain).Str("before", before).Msg("No files parsed by an older parser version")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "FILE\tPARSER VERSION\tRUN ID\tARCHIVE\tDOWNLOADED")
+	for _, f := range files {
+		version := f.ParserVersion
+		if version == "" {
+			version = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", f.Filename, version, f.RunID, f.ArchiveID, f.DownloadedAt.Format("2006-01-02 15:04"))
+	}
+	w.Flush()
+
+	if reprocessDryRun {
+		logger.Info().Int("files", len(files)).Msg("Dry run, nothing rerun")
+		return nil
+	}
+
+	store, err := openArchiveStorage()
+	if err != nil {
+		return fmt.Errorf("failed to open archive storage: %w", err)
+	}
+
+	result, err := pipeline.Reprocess(ctx, reprocessChain, files, before, store)
+	if err != nil {
+		return fmt.Errorf("reprocess failed: %w", err)
+	}
+
+	displayIngestResults([]ingestResult{{
+		Chain:            reprocessChain,
+		Success:          result.Success,
+		RunID:            result.RunID,
+		FilesProcessed:   result.FilesProcessed,
+		EntriesPersisted: result.EntriesPersisted,
+		ErrorCount:       len(result.Errors),
+	}})
+	if !result.Success {
+		return fmt.Errorf("reprocess completed with %d error(s)", len(result.Errors))
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -16,16 +17,38 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 
 	"github.com/kosarica/price-service/config"
 	_ "github.com/kosarica/price-service/docs" // Swagger generated docs
+	adapterconfig "github.com/kosarica/price-service/internal/adapters/config"
+	"github.com/kosarica/price-service/internal/adapters/registry"
+	"github.com/kosarica/price-service/internal/chains"
 	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/events"
+	"github.com/kosarica/price-service/internal/featureflags"
+	"github.com/kosarica/price-service/internal/graph"
+	grpcserver "github.com/kosarica/price-service/internal/grpc"
 	"github.com/kosarica/price-service/internal/handlers"
+	"github.com/kosarica/price-service/internal/jobs"
+	"github.com/kosarica/price-service/internal/lanes"
+	"github.com/kosarica/price-service/internal/logging"
 	"github.com/kosarica/price-service/internal/middleware"
+	"github.com/kosarica/price-service/internal/optimizer"
+	"github.com/kosarica/price-service/internal/outbox"
+	"github.com/kosarica/price-service/internal/pipeline"
+	"github.com/kosarica/price-service/internal/pkg/clock"
+	"github.com/kosarica/price-service/internal/privacy"
+	"github.com/kosarica/price-service/internal/profiling"
+	"github.com/kosarica/price-service/internal/scheduler"
+	"github.com/kosarica/price-service/internal/slo"
+	"github.com/kosarica/price-service/internal/storage"
+	"github.com/kosarica/price-service/internal/storelocator"
 	"github.com/kosarica/price-service/internal/sweepers"
+	"github.com/kosarica/price-service/internal/webhooks"
 )
 
 func main() {
@@ -38,6 +61,10 @@ func main() {
 
 	logger.Info().Msg("Starting price service")
 
+	if err := clock.SetZone(cfg.Timezone); err != nil {
+		logger.Fatal().Err(err).Msg("Invalid service time zone")
+	}
+
 	dbURL := config.GetDatabaseURL()
 	if dbURL == "" {
 		logger.Fatal().Msg("DATABASE_URL not set")
@@ -58,7 +85,35 @@ func main() {
 
 	logger.Info().Msg("Database connected")
 
-	if err := handleInterruptedRuns(ctx, logger); err != nil {
+	interactiveSlots, batchSlots := lanes.SplitConnections(cfg.Database.MaxConnections)
+	if cfg.Database.InteractiveLaneSlots > 0 {
+		interactiveSlots = cfg.Database.InteractiveLaneSlots
+	}
+	if cfg.Database.BatchLaneSlots > 0 {
+		batchSlots = cfg.Database.BatchLaneSlots
+	}
+	lanes.Configure(interactiveSlots, batchSlots)
+	logger.Info().Int("interactive", interactiveSlots).Int("batch", batchSlots).Msg("Priority lanes configured")
+
+	validateChainRegistry(ctx, logger)
+
+	if err := featureflags.Refresh(ctx); err != nil {
+		logger.Warn().Err(err).Msg("Failed to load feature flags, all flags off")
+	}
+
+	// Basket optimizer over the in-memory price cache, warmed in the background
+	optimizerConfig := optimizer.Defaults().ToOptimizerConfig()
+	priceCache := optimizer.NewPriceCache(database.Pool(), optimizerConfig)
+	handlers.InitOptimizers(priceCache, optimizerConfig, optimizer.NewMetricsRecorder())
+	priceCache.ListenForInvalidations()
+	go func() {
+		if err := priceCache.Warmup(ctx); err != nil {
+			logger.Warn().Err(err).Msg("Price cache warmup failed")
+		}
+	}()
+	defer priceCache.Close()
+
+	if err := handleInterruptedRuns(ctx, logger, cfg.Ingestion); err != nil {
 		logger.Warn().Err(err).Msg("Failed to handle interrupted runs")
 	}
 
@@ -66,52 +121,255 @@ func main() {
 	taskSweeper := sweepers.NewTaskQueueSweeper(database.Pool(), logger, sweeperInterval)
 	go taskSweeper.Start(ctx)
 
+	statsRollup := jobs.NewStatsRollupJob(database.Pool(), logger, time.Hour)
+	go statsRollup.Start(ctx)
+
+	runProgressCheck := jobs.NewRunProgressCheckJob(database.Pool(), logger, 15*time.Minute)
+	go runProgressCheck.Start(ctx)
+
+	parseProfileReport := jobs.NewParseProfileReportJob(database.Pool(), logger, jobs.DefaultParseRegressionThreshold, 7*24*time.Hour)
+	go parseProfileReport.Start(ctx)
+
+	if cfg.AnchorCompliance.Interval > 0 {
+		anchorCompliance := jobs.NewAnchorComplianceJob(database.Pool(), logger, cfg.AnchorCompliance.MinAnchorAgeDays, cfg.AnchorCompliance.Interval)
+		go anchorCompliance.Start(ctx)
+	}
+
+	if cfg.StoreChurn.Interval > 0 {
+		storeChurn := sweepers.NewStoreChurnSweeper(database.Pool(), logger, cfg.StoreChurn.WindowDays, cfg.StoreChurn.Threshold, cfg.StoreChurn.Interval)
+		go storeChurn.Start(ctx)
+	}
+
+	storelocator.Configure(cfg.StoreLocator.Chains)
+	storeLocatorSyncer := storelocator.NewSyncer(database.Pool(), logger, cfg.StoreLocator.MinSimilarity, cfg.StoreLocator.Interval)
+	handlers.InitStoreLocator(storeLocatorSyncer)
+	if cfg.StoreLocator.Interval > 0 && len(storelocator.Chains()) > 0 {
+		go storeLocatorSyncer.Start(ctx)
+	}
+
+	scheduleLocation := clock.Zone()
+	if cfg.Scheduler.Timezone != "" {
+		if scheduleLocation, err = time.LoadLocation(cfg.Scheduler.Timezone); err != nil {
+			logger.Fatal().Err(err).Str("timezone", cfg.Scheduler.Timezone).Msg("Invalid scheduler timezone")
+		}
+	}
+	handlers.InitSchedules(scheduleLocation)
+	var ingestionScheduler *scheduler.Scheduler
+	if cfg.Scheduler.Enabled {
+		ingestionScheduler = scheduler.New(logger, scheduleLocation, runScheduledIngestion, cfg.Scheduler.Interval)
+		if err := ingestionScheduler.Seed(ctx, cfg.Scheduler.Schedules); err != nil {
+			logger.Error().Err(err).Msg("Failed to seed ingestion schedules from config")
+		}
+		go ingestionScheduler.Start(ctx)
+	}
+
+	if cfg.PriceHistory.Interval > 0 {
+		priceHistoryRetention := jobs.NewPriceHistoryRetentionJob(database.Pool(), logger, cfg.PriceHistory.RetentionDays, cfg.PriceHistory.Interval)
+		go priceHistoryRetention.Start(ctx)
+	}
+
+	privacy.SetCoordinatePrecision(cfg.Privacy.CoordinatePrecision)
+	if cfg.Privacy.OptimizationRetentionDays > 0 {
+		optimizationRetention := jobs.NewOptimizationRetentionJob(database.Pool(), logger, cfg.Privacy.OptimizationRetentionDays, 24*time.Hour)
+		go optimizationRetention.Start(ctx)
+	}
+
+	publisher, err := events.NewPublisher(cfg.Events.Driver, cfg.Events.KafkaBrokers, cfg.Events.NATSURL, cfg.Events.Topic)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to initialize event publisher")
+	}
+	events.SetDefault(publisher)
+	defer publisher.Close()
+
+	var outboxRelay *outbox.Relay
+	if len(cfg.Outbox.WebhookURLs) > 0 {
+		publisher := outbox.NewWebhookPublisher(cfg.Outbox.WebhookURLs, cfg.Outbox.WebhookSecret)
+		outboxRelay = outbox.NewRelay(database.Pool(), logger, cfg.Outbox.PollInterval, publisher)
+		go outboxRelay.Start(ctx)
+	} else {
+		logger.Info().Msg("No outbox webhooks configured, events stay queued")
+	}
+
+	webhookDispatcher := webhooks.NewDispatcher(database.Pool(), logger, cfg.Webhooks.PollInterval, cfg.Webhooks.MaxAttempts)
+	go webhookDispatcher.Start(ctx)
+
 	if cfg.Logging.Level == "info" || cfg.Logging.Level == "debug" {
 		gin.SetMode(gin.DebugMode)
 	} else {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	objectives := make([]slo.Objective, 0, len(cfg.SLO.Objectives))
+	for _, o := range cfg.SLO.Objectives {
+		objectives = append(objectives, slo.Objective{Name: o.Name, Route: o.Route, Method: o.Method, Latency: o.Latency, Target: o.Target})
+	}
+	sloTracker, err := slo.NewTracker(objectives, slo.Options{
+		Window:       cfg.SLO.Window,
+		FastBurnRate: cfg.SLO.FastBurnRate,
+		MinRequests:  cfg.SLO.AlertMinRequests,
+	})
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid SLO configuration")
+	}
+	slo.SetDefault(sloTracker)
+	sloAlerter := slo.NewAlerter(sloTracker, cfg.SLO.AlertWebhookURLs, logger, cfg.SLO.EvaluationInterval)
+	go sloAlerter.Start(ctx)
+
 	router := gin.New()
 	router.Use(gin.Recovery())
 	setupMiddleware(router, logger)
+	router.Use(middleware.MetricsMiddleware(sloTracker))
 
 	router.GET("/health", handlers.HealthCheck)
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	// Swagger UI endpoint - serves OpenAPI spec and interactive documentation
 	router.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Read-only public API for third parties, authenticated per API key
+	public := router.Group("/api/v1")
+	public.Use(middleware.APIKeyAuth(database.UseAPIKey, middleware.DefaultRateLimiterConfig()))
+	public.Use(middleware.LaneMiddleware(lanes.Interactive))
+	public.Use(middleware.FeatureFlagsMiddleware())
+	{
+		public.GET("/items/search", handlers.SearchItems)
+		public.GET("/prices/:chainSlug/:storeId", handlers.GetStorePrices)
+		public.POST("/basket/optimize/single", handlers.OptimizeSingle)
+		public.POST("/basket/optimize/multi", handlers.OptimizeMulti)
+	}
+
 	internal := router.Group("/internal")
 	internal.Use(middleware.InternalAuthMiddleware())
 	internal.Use(middleware.ServiceRateLimitMiddleware(50, 100))
+	internal.Use(middleware.FeatureFlagsMiddleware())
 	{
 		internal.GET("/health", handlers.HealthCheck)
 		internal.GET("/chains", handlers.ListChains)
+		internal.GET("/chains/metadata", handlers.ListChainMetadata)
+		internal.GET("/chains/:slug/metadata", handlers.GetChainMetadata)
+		internal.GET("/flags", handlers.EvaluateFeatureFlags)
+		internal.GET("/slo", handlers.GetSLOStatus)
+
+		// GraphQL read gateway; prices come from the price cache when loaded
+		var priceSource optimizer.PriceSource
+		if cache := handlers.GetPriceCache(); cache != nil {
+			priceSource = cache
+		}
+		graphqlHandler := graph.NewHandler(database.Pool(), priceSource)
+		internal.GET("/graphql", middleware.LaneMiddleware(lanes.Interactive), graphqlHandler)
+		internal.POST("/graphql", middleware.LaneMiddleware(lanes.Interactive), graphqlHandler)
 
 		admin := internal.Group("/admin")
+		admin.Use(middleware.LaneMiddleware(lanes.Batch))
 		{
+			admin.POST("/ingest", handlers.IngestChains)
 			admin.POST("/ingest/:chain", handlers.IngestChain)
+			admin.GET("/ingest/batches/:batchId", handlers.GetIngestionBatch)
+			admin.GET("/chains", handlers.ListAdminChains)
+			admin.POST("/chains", handlers.CreateChain)
+			admin.PATCH("/chains/:slug", handlers.UpdateChain)
+			admin.DELETE("/chains/:slug", handlers.DisableChain)
+			admin.GET("/chains/:slug/mapping", handlers.GetColumnMapping)
+			admin.PUT("/chains/:slug/mapping", handlers.SetColumnMapping)
+			admin.DELETE("/chains/:slug/mapping", handlers.DeleteColumnMapping)
+			admin.POST("/chains/:slug/mapping/test", handlers.TestParseColumnMapping)
+			admin.POST("/mappings/infer", handlers.InferColumnMapping)
+			admin.GET("/chains/:slug/settings", handlers.GetChainSettings)
+			admin.PUT("/chains/:slug/settings", handlers.SetChainSettings)
+			admin.DELETE("/chains/:slug/settings", handlers.DeleteChainSettings)
+			admin.GET("/chain-settings", handlers.ListChainSettings)
+			admin.POST("/products/:productId/merge", handlers.MergeProducts)
+			admin.POST("/products/:productId/split", handlers.SplitProduct)
+			admin.GET("/raw-payloads/:id", handlers.GetRawPayload)
+			admin.GET("/failed-rows/:id/raw", handlers.GetFailedRowRawData)
+			admin.GET("/privacy/users/:userRef", handlers.ExportUserData)
+			admin.DELETE("/privacy/users/:userRef", handlers.DeleteUserData)
+			admin.GET("/flags", handlers.ListFeatureFlags)
+			admin.PUT("/flags/:key", handlers.SetFeatureFlag)
+			admin.DELETE("/flags/:key", handlers.DeleteFeatureFlag)
+			admin.GET("/schedules", handlers.ListSchedules)
+			admin.GET("/schedules/:chain", handlers.GetSchedule)
+			admin.PUT("/schedules/:chain", handlers.SetSchedule)
+			admin.DELETE("/schedules/:chain", handlers.DeleteSchedule)
+			admin.GET("/store-locator/unmatched", handlers.ListUnmatchedLocatorStores)
+			admin.POST("/store-locator/:chain/sync", handlers.SyncStoreLocator)
+			admin.GET("/optimizer/versions", handlers.GetOptimizerVersions)
+			admin.PUT("/optimizer/config", handlers.SetOptimizerConfig)
+			admin.POST("/optimizer/rollback", handlers.RollbackOptimizer)
+			admin.GET("/webhooks", handlers.ListWebhooks)
+			admin.POST("/webhooks", handlers.CreateWebhook)
+			admin.GET("/webhooks/:id", handlers.GetWebhook)
+			admin.PUT("/webhooks/:id", handlers.UpdateWebhook)
+			admin.DELETE("/webhooks/:id", handlers.DeleteWebhook)
+			admin.GET("/webhooks/:id/deliveries", handlers.ListWebhookDeliveries)
+			admin.GET("/config/logging", handlers.GetLogLevels)
+			admin.PUT("/config/logging", handlers.SetLogLevels)
 		}
 
 		ingestion := internal.Group("/ingestion")
+		ingestion.Use(middleware.LaneMiddleware(lanes.Batch))
 		{
 			ingestion.GET("/runs", handlers.ListRuns)
 			ingestion.GET("/runs/:runId", handlers.GetRun)
 			ingestion.GET("/runs/:runId/files", handlers.ListFiles)
 			ingestion.GET("/runs/:runId/errors", handlers.ListErrors)
+			ingestion.GET("/runs/:runId/lineage", handlers.GetRunLineage)
 			ingestion.GET("/stats", handlers.GetStats)
+			ingestion.GET("/usage", handlers.GetUsage)
+			ingestion.GET("/parse-profiles", handlers.GetParseProfiles)
+			ingestion.GET("/parse-profiles/report", handlers.GetParseProfileReport)
+			ingestion.GET("/store-churn", handlers.ListStoreChurn)
 			ingestion.POST("/runs/:runId/rerun", handlers.RerunRun)
+			ingestion.POST("/runs/:runId/publish", handlers.PublishRun)
+			ingestion.POST("/runs/:runId/reject", handlers.RejectRun)
+			ingestion.POST("/runs/:runId/clear-suspect", handlers.ClearSuspectRun)
 			ingestion.DELETE("/runs/:runId", handlers.DeleteRun)
 		}
 
+		matching := internal.Group("/matching")
+		matching.Use(middleware.LaneMiddleware(lanes.Batch))
+		{
+			matching.GET("/overview", handlers.GetMatchingOverview)
+			matching.POST("/simulate", handlers.SimulateMatching)
+		}
+
 		prices := internal.Group("/prices")
+		prices.Use(middleware.LaneMiddleware(lanes.Interactive))
 		{
+			prices.GET("/:chainSlug/discounts", handlers.GetChainDiscounts)
 			prices.GET("/:chainSlug/:storeId", handlers.GetStorePrices)
+			prices.GET("/:chainSlug/:storeId/checksum", handlers.GetStorePriceChecksum)
+			prices.GET("/:chainSlug/:storeId/delta", handlers.GetStorePriceDelta)
+			prices.GET("/:chainSlug/:storeId/:itemId/history", handlers.GetPriceHistory)
+		}
+
+		basket := internal.Group("/basket")
+		{
+			basket.POST("/optimize/single", middleware.LaneMiddleware(lanes.Interactive), handlers.OptimizeSingle)
+			basket.POST("/optimize/multi", middleware.LaneMiddleware(lanes.Interactive), handlers.OptimizeMulti)
+			basket.POST("/availability", middleware.LaneMiddleware(lanes.Interactive), handlers.CheckBasketAvailability)
+			basket.GET("/cache/health", handlers.CacheHealth)
+			basket.POST("/cache/warmup", middleware.LaneMiddleware(lanes.Batch), handlers.CacheWarmup)
+			basket.POST("/cache/refresh/:chainSlug", middleware.LaneMiddleware(lanes.Batch), handlers.CacheRefresh)
 		}
 
 		items := internal.Group("/items")
+		items.Use(middleware.LaneMiddleware(lanes.Interactive))
 		{
 			items.GET("/search", handlers.SearchItems)
+			items.GET("/suggest", handlers.SuggestItems)
+			items.POST("/resolve", handlers.ResolveItems)
+		}
+
+		compliance := internal.Group("/compliance")
+		compliance.Use(middleware.LaneMiddleware(lanes.Batch))
+		{
+			compliance.GET("/anchor-prices", handlers.GetAnchorPriceCompliance)
+		}
+
+		cdc := internal.Group("/cdc")
+		{
+			cdc.GET("/prices", handlers.StreamPriceChanges)
 		}
 	}
 
@@ -130,12 +388,73 @@ func main() {
 		}
 	}()
 
+	var grpcSrv *grpcserver.Server
+	if cfg.GRPC.Port > 0 {
+		grpcAddr := fmt.Sprintf("%s:%d", cfg.GRPC.Host, cfg.GRPC.Port)
+		grpcSrv, err = grpcserver.NewServer(logger, os.Getenv("INTERNAL_API_KEY"))
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to create gRPC server")
+		}
+		lis, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			logger.Fatal().Err(err).Str("addr", grpcAddr).Msg("Failed to listen for gRPC")
+		}
+		go func() {
+			logger.Info().Str("addr", grpcAddr).Msg("gRPC server listening")
+			if err := grpcSrv.Serve(lis); err != nil {
+				logger.Error().Err(err).Msg("gRPC server failed")
+			}
+		}()
+	}
+
+	var pprofSrv *http.Server
+	if cfg.Profiling.Port > 0 {
+		pprofAddr := fmt.Sprintf("%s:%d", cfg.Profiling.Host, cfg.Profiling.Port)
+		pprofSrv = profiling.NewServer(pprofAddr)
+		go func() {
+			logger.Info().Str("addr", pprofAddr).Msg("Profiling server listening")
+			if err := pprofSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Error().Err(err).Msg("Profiling server failed")
+			}
+		}()
+	}
+
+	var autoProfiler *profiling.AutoProfiler
+	if cfg.Profiling.AutoCapture {
+		profileStore, err := storage.Open(cfg.Storage)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to open profile storage, automatic profiling disabled")
+		} else {
+			autoProfiler = profiling.NewAutoProfiler(profiling.AutoConfig{
+				CheckInterval:      cfg.Profiling.CheckInterval,
+				HeapThresholdBytes: uint64(cfg.Profiling.HeapThresholdMB) << 20,
+				OptimizeP99Budget:  cfg.Profiling.OptimizeP99Budget,
+				CPUDuration:        cfg.Profiling.CPUDuration,
+				Cooldown:           cfg.Profiling.Cooldown,
+			}, profileStore, logger)
+			go autoProfiler.Start(ctx)
+		}
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	logger.Info().Msg("Shutting down server...")
 	taskSweeper.Stop()
+	if ingestionScheduler != nil {
+		ingestionScheduler.Stop()
+	}
+	statsRollup.Stop()
+	parseProfileReport.Stop()
+	if outboxRelay != nil {
+		outboxRelay.Stop()
+	}
+	webhookDispatcher.Stop()
+	if autoProfiler != nil {
+		autoProfiler.Stop()
+	}
+	sloAlerter.Stop()
 
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -143,17 +462,79 @@ func main() {
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		logger.Error().Err(err).Msg("Server forced to shutdown")
 	}
+	if grpcSrv != nil {
+		grpcSrv.Shutdown(shutdownCtx)
+	}
+	if pprofSrv != nil {
+		pprofSrv.Close()
+	}
 
 	logger.Info().Msg("Server exited")
 }
 
-func handleInterruptedRuns(ctx context.Context, logger *zerolog.Logger) error {
+// validateChainRegistry loads the chain registry from the database and checks
+// the built-in adapters against it. Mismatches are logged, not fatal: ingestion
+// rejects chains that are missing or disabled in the registry.
+func validateChainRegistry(ctx context.Context, logger *zerolog.Logger) {
+	if err := chains.Refresh(ctx); err != nil {
+		logger.Warn().Err(err).Msg("Failed to load chain registry, using built-in chains")
+	}
+
+	if err := registry.InitializeDefaultAdapters(); err != nil {
+		logger.Error().Err(err).Msg("Failed to initialize chain adapters")
+		return
+	}
+
+	enabled := chains.ValidChains()
+	if err := registry.DefaultRegistry.ValidateAgainst(enabled); err != nil {
+		logger.Warn().Err(err).Msg("Chain registry mismatch")
+	}
+
+	for _, slug := range enabled {
+		if !registry.DefaultRegistry.IsRegistered(adapterconfig.ChainID(slug)) {
+			logger.Warn().Str("chain", slug).Msg("Chain is enabled in the registry but has no adapter")
+		}
+	}
+
+	logger.Info().Int("chains", len(enabled)).Msg("Chain registry loaded")
+}
+
+// runScheduledIngestion ingests a chain for its schedule, failing when the
+// run completes with errors
+func runScheduledIngestion(ctx context.Context, chainSlug string) (string, error) {
+	result, err := pipeline.RunScheduled(ctx, chainSlug)
+	if err != nil {
+		return "", err
+	}
+	if !result.Success {
+		return result.RunID, fmt.Errorf("ingestion completed with %d errors", len(result.Errors))
+	}
+	return result.RunID, nil
+}
+
+// handleInterruptedRuns deals with the runs left running by the previous
+// process: with resume on, runs that can be resumed continue in the
+// background one after another, and the others are marked interrupted
+func handleInterruptedRuns(ctx context.Context, logger *zerolog.Logger, cfg config.IngestionConfig) error {
 	pool := database.Pool()
 
+	resume := cfg.OnRestart == "resume"
+	if !resume && cfg.OnRestart != "interrupt" {
+		logger.Warn().Str("onRestart", cfg.OnRestart).Msg("Unknown INGESTION_ON_RESTART, interrupting runs")
+	}
+	var storageBackend storage.Storage
+	if resume {
+		var err error
+		if storageBackend, err = storage.Default(); err != nil {
+			logger.Warn().Err(err).Msg("Failed to initialize storage, interrupting runs instead of resuming them")
+			resume = false
+		}
+	}
+
 	rows, err := pool.Query(ctx, `
-		SELECT id, chain_slug, started_at, processed_files, total_files
+		SELECT id, chain_slug, COALESCE(started_at, created_at), processed_files, total_files
 		FROM ingestion_runs
-		WHERE status = 'running'
+		WHERE status = 'running' OR (status = 'pending' AND batch_id IS NOT NULL)
 		ORDER BY started_at DESC
 	`)
 	if err != nil {
@@ -189,15 +570,32 @@ func handleInterruptedRuns(ctx context.Context, logger *zerolog.Logger) error {
 		return nil
 	}
 
+	var resumable []string
 	for _, run := range runs {
+		reason := "Service restarted during processing"
+		if resume {
+			err := pipeline.CheckResumable(ctx, run.ID, cfg.MaxResumes)
+			if err == nil {
+				resumable = append(resumable, run.ID)
+				logger.Info().
+					Str("id", run.ID).
+					Str("chain", run.Chain).
+					Int("processed", run.ProcessedFiles).
+					Int("total", run.TotalFiles).
+					Msg("Resuming interrupted run")
+				continue
+			}
+			reason += "; not resumed: " + err.Error()
+		}
+
 		_, err := pool.Exec(ctx, `
 			UPDATE ingestion_runs
 			SET status = 'interrupted',
 			    completed_at = NOW(),
-			    metadata = jsonb_build_object(
-				    'interrupted_reason', 'Service restarted during processing')
+			    metadata = COALESCE(metadata, '{}'::jsonb) || jsonb_build_object(
+				    'interrupted_reason', $2::text)
 			WHERE id = $1
-		`, run.ID)
+		`, run.ID, reason)
 
 		if err != nil {
 			logger.Error().Err(err).Str("id", run.ID).Msg("Failed to mark run as interrupted")
@@ -211,10 +609,42 @@ func handleInterruptedRuns(ctx context.Context, logger *zerolog.Logger) error {
 			Msg("Marked interrupted run")
 	}
 
-	logger.Info().Int("count", len(runs)).Msg("Handled interrupted runs")
+	if len(resumable) > 0 {
+		go resumeRuns(ctx, logger, resumable, cfg.MaxResumes, storageBackend)
+	}
+
+	logger.Info().Int("count", len(runs)).Int("resumed", len(resumable)).Msg("Handled interrupted runs")
 	return nil
 }
 
+// resumeRuns resumes interrupted runs one at a time. A run that cannot be
+// resumed after all is marked interrupted; runs not reached before shutdown
+// stay running and are resumed at the next start.
+func resumeRuns(ctx context.Context, logger *zerolog.Logger, runIDs []string, maxResumes int, storageBackend storage.Storage) {
+	for _, runID := range runIDs {
+		if ctx.Err() != nil {
+			return
+		}
+		result, err := pipeline.ResumeRun(ctx, runID, maxResumes, storageBackend)
+		if err != nil && ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			logger.Error().Err(err).Str("id", runID).Msg("Failed to resume run")
+			if err := pipeline.MarkRunInterrupted(context.WithoutCancel(ctx), runID); err != nil {
+				logger.Error().Err(err).Str("id", runID).Msg("Failed to mark run as interrupted")
+			}
+			continue
+		}
+		logger.Info().
+			Str("id", runID).
+			Bool("success", result.Success).
+			Int("files", result.FilesProcessed).
+			Int("entries", result.EntriesPersisted).
+			Msg("Resumed run finished")
+	}
+}
+
 func initLogger(cfg config.LoggingConfig) *zerolog.Logger {
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
 
@@ -231,6 +661,10 @@ func initLogger(cfg config.LoggingConfig) *zerolog.Logger {
 	}
 
 	logger := zerolog.New(output).Level(level).With().Timestamp().Str("service", "price-service").Logger()
+	if err := logging.Configure(output, level, cfg.Components); err != nil {
+		logger.Warn().Err(err).Msg("Invalid component log levels, using the default level")
+		_ = logging.Configure(output, level, nil)
+	}
 	return &logger
 }
 
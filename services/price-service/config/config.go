@@ -13,11 +13,35 @@ import (
 
 // Config holds the application configuration
 type Config struct {
-	Server    ServerConfig    `mapstructure:"server"`
-	Database  DatabaseConfig  `mapstructure:"database"`
-	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
-	Storage   StorageConfig   `mapstructure:"storage"`
-	Logging   LoggingConfig   `mapstructure:"logging"`
+	// Timezone is the service's IANA time zone: the days of discount
+	// windows, stats buckets, discovery dates and schedules are its days
+	Timezone string `mapstructure:"timezone"`
+
+	Server      ServerConfig      `mapstructure:"server"`
+	GRPC        GRPCConfig        `mapstructure:"grpc"`
+	Database    DatabaseConfig    `mapstructure:"database"`
+	RateLimit   RateLimitConfig   `mapstructure:"rate_limit"`
+	Storage     StorageConfig     `mapstructure:"storage"`
+	Logging     LoggingConfig     `mapstructure:"logging"`
+	Outbox      OutboxConfig      `mapstructure:"outbox"`
+	Events      EventsConfig      `mapstructure:"events"`
+	Privacy     PrivacyConfig     `mapstructure:"privacy"`
+	Profiling   ProfilingConfig   `mapstructure:"profiling"`
+	SLO         SLOConfig         `mapstructure:"slo"`
+	RunBaseline RunBaselineConfig `mapstructure:"run_baseline"`
+
+	AnchorCompliance AnchorComplianceConfig `mapstructure:"anchor_compliance"`
+	StoreChurn       StoreChurnConfig       `mapstructure:"store_churn"`
+	PriceGroups      PriceGroupsConfig      `mapstructure:"price_groups"`
+	PriceHistory     PriceHistoryConfig     `mapstructure:"price_history"`
+	Scheduler        SchedulerConfig        `mapstructure:"scheduler"`
+	Ingestion        IngestionConfig        `mapstructure:"ingestion"`
+	StoreLocator     StoreLocatorConfig     `mapstructure:"store_locator"`
+	Webhooks         WebhooksConfig         `mapstructure:"webhooks"`
+	Daily            DailyConfig            `mapstructure:"daily"`
+
+	// Chains holds per-chain ingest settings by chain slug
+	Chains map[string]ChainSettingsConfig `mapstructure:"chains"`
 }
 
 // ServerConfig holds HTTP server configuration
@@ -28,6 +52,13 @@ type ServerConfig struct {
 	WriteTimeout time.Duration `mapstructure:"write_timeout"`
 }
 
+// GRPCConfig holds the gRPC server configuration
+type GRPCConfig struct {
+	// Port of the gRPC server; 0 disables it
+	Port int    `mapstructure:"port"`
+	Host string `mapstructure:"host"`
+}
+
 // DatabaseConfig holds database connection configuration
 type DatabaseConfig struct {
 	URL             string        `mapstructure:"url"`
@@ -35,6 +66,9 @@ type DatabaseConfig struct {
 	MinConnections  int           `mapstructure:"min_connections"`
 	MaxConnLifetime time.Duration `mapstructure:"max_conn_lifetime"`
 	MaxConnIdleTime time.Duration `mapstructure:"max_conn_idle_time"`
+	// Priority lane slots; 0 derives them from max_connections
+	InteractiveLaneSlots int `mapstructure:"interactive_lane_slots"`
+	BatchLaneSlots       int `mapstructure:"batch_lane_slots"`
 }
 
 // RateLimitConfig holds rate limiting configuration
@@ -47,8 +81,21 @@ type RateLimitConfig struct {
 
 // StorageConfig holds storage configuration
 type StorageConfig struct {
-	Type     string `mapstructure:"type"`
+	// Type is "local", "s3" or "gcs"
+	Type string `mapstructure:"type"`
+	// Directory for local storage, key prefix for s3 and gcs
 	BasePath string `mapstructure:"base_path"`
+	// Bucket and credentials for s3 and gcs; gcs takes an HMAC key
+	Bucket          string `mapstructure:"bucket"`
+	Region          string `mapstructure:"region"`
+	Endpoint        string `mapstructure:"endpoint"`
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	SessionToken    string `mapstructure:"session_token"`
+	ForcePathStyle  bool   `mapstructure:"force_path_style"`
+	// Archive retention used by `archive gc`
+	RetentionDays         int `mapstructure:"retention_days"`
+	RetentionKeepPerChain int `mapstructure:"retention_keep_per_chain"`
 }
 
 // LoggingConfig holds logging configuration
@@ -56,6 +103,215 @@ type LoggingConfig struct {
 	Level   string `mapstructure:"level"`
 	Format  string `mapstructure:"format"`
 	NoColor bool   `mapstructure:"no_color"`
+	// Components overrides Level per component (cache, optimizer, pipeline,
+	// handlers, adapters)
+	Components map[string]string `mapstructure:"components"`
+}
+
+// OutboxConfig holds event outbox relay configuration
+type OutboxConfig struct {
+	// Webhooks receiving outbox events; the relay is disabled when empty
+	WebhookURLs   []string      `mapstructure:"webhook_urls"`
+	WebhookSecret string        `mapstructure:"webhook_secret"`
+	PollInterval  time.Duration `mapstructure:"poll_interval"`
+}
+
+// EventsConfig holds price change event stream configuration
+type EventsConfig struct {
+	// Driver is "kafka", "nats", or empty to disable the stream
+	Driver       string   `mapstructure:"driver"`
+	KafkaBrokers []string `mapstructure:"kafka_brokers"`
+	NATSURL      string   `mapstructure:"nats_url"`
+	// Topic (Kafka) or subject (NATS) prefix; the schema version is appended
+	Topic string `mapstructure:"topic"`
+}
+
+// PrivacyConfig holds handling rules for user-adjacent data
+type PrivacyConfig struct {
+	// Decimal places kept when caller coordinates are logged or stored
+	// (2 is about 1 km); negative disables rounding
+	CoordinatePrecision int `mapstructure:"coordinate_precision"`
+	// Days stored optimization results are kept; 0 keeps them forever
+	OptimizationRetentionDays int `mapstructure:"optimization_retention_days"`
+}
+
+// ProfilingConfig holds the pprof admin server and automatic profile capture settings
+type ProfilingConfig struct {
+	// Port of the admin server exposing /debug/pprof/; 0 disables it
+	Port int    `mapstructure:"port"`
+	Host string `mapstructure:"host"`
+	// Capture profiles to archive storage when over the thresholds below
+	AutoCapture       bool          `mapstructure:"auto_capture"`
+	HeapThresholdMB   int           `mapstructure:"heap_threshold_mb"`
+	OptimizeP99Budget time.Duration `mapstructure:"optimize_p99_budget"`
+	CheckInterval     time.Duration `mapstructure:"check_interval"`
+	CPUDuration       time.Duration `mapstructure:"cpu_duration"`
+	// Minimum time between captures of the same condition
+	Cooldown time.Duration `mapstructure:"cooldown"`
+}
+
+// SLOConfig holds service level objectives and their fast burn alerting
+type SLOConfig struct {
+	// Compliance window of every objective
+	Window             time.Duration `mapstructure:"window"`
+	EvaluationInterval time.Duration `mapstructure:"evaluation_interval"`
+	// Burn rate over both the 5 minute and 1 hour windows that alerts
+	FastBurnRate float64 `mapstructure:"fast_burn_rate"`
+	// Requests in the 5 minute window needed before alerting
+	AlertMinRequests int64                `mapstructure:"alert_min_requests"`
+	AlertWebhookURLs []string             `mapstructure:"alert_webhook_urls"`
+	Objectives       []SLOObjectiveConfig `mapstructure:"objectives"`
+}
+
+// SLOObjectiveConfig is one service level objective
+type SLOObjectiveConfig struct {
+	Name string `mapstructure:"name"`
+	// Gin route pattern; a trailing * matches every route with that prefix
+	Route  string `mapstructure:"route"`
+	Method string `mapstructure:"method"`
+	// Requests slower than this count against the objective; 0 counts only 5xx
+	Latency time.Duration `mapstructure:"latency"`
+	// Fraction of requests that must be good, e.g. 0.995
+	Target float64 `mapstructure:"target"`
+}
+
+// RunBaselineConfig holds the bands a completed run is checked against,
+// compared with the chain's run on the same weekday a week earlier. Each band
+// is the largest relative deviation, up or down, that is not suspect.
+type RunBaselineConfig struct {
+	Enabled           bool    `mapstructure:"enabled"`
+	MaxFileDeviation  float64 `mapstructure:"max_file_deviation"`
+	MaxRowDeviation   float64 `mapstructure:"max_row_deviation"`
+	MaxPriceDeviation float64 `mapstructure:"max_price_deviation"`
+}
+
+// AnchorComplianceConfig holds the schedule and rule of the anchor price
+// compliance report
+type AnchorComplianceConfig struct {
+	// Interval between reports; 0 disables the job
+	Interval time.Duration `mapstructure:"interval"`
+	// Days an anchor price must have been in effect before a discount
+	MinAnchorAgeDays int `mapstructure:"min_anchor_age_days"`
+}
+
+// StoreChurnConfig holds the store price group churn sweeper settings
+type StoreChurnConfig struct {
+	// Interval between sweeps; 0 disables the sweeper
+	Interval time.Duration `mapstructure:"interval"`
+	// Days of store_group_history counted per sweep
+	WindowDays int `mapstructure:"window_days"`
+	// Reassignments within the window at which a store is flagged
+	Threshold int `mapstructure:"threshold"`
+}
+
+// PriceGroupsConfig selects the price hash that groups stores with identical
+// prices. Version 2 canonicalizes the hash input with the other options.
+type PriceGroupsConfig struct {
+	HashVersion       int  `mapstructure:"hash_version"`
+	IgnoreDiscountEnd bool `mapstructure:"ignore_discount_end"`
+	PriceBucketCents  int  `mapstructure:"price_bucket_cents"`
+	SortByItemID      bool `mapstructure:"sort_by_item_id"`
+}
+
+// PriceHistoryConfig holds the retention of the daily price history
+type PriceHistoryConfig struct {
+	// Interval between retention runs; 0 disables the job
+	Interval time.Duration `mapstructure:"interval"`
+	// Days of history kept; 0 keeps it forever and only compacts it
+	RetentionDays int `mapstructure:"retention_days"`
+}
+
+// SchedulerConfig holds the scheduled ingestion settings. Schedules maps a
+// chain slug to a cron expression; they are stored at startup for chains
+// without a schedule and managed through the admin API afterwards. Cron
+// expressions are evaluated in Timezone, the service's time zone when empty.
+type SchedulerConfig struct {
+	Enabled   bool              `mapstructure:"enabled"`
+	Interval  time.Duration     `mapstructure:"interval"`
+	Timezone  string            `mapstructure:"timezone"`
+	Schedules map[string]string `mapstructure:"schedules"`
+}
+
+// IngestionConfig holds ingestion run settings. FileWorkers is how many files
+// of a run are processed at once. OnRestart is how runs left running by a
+// restart are handled: "resume" to continue them from their unfinished
+// files, at most MaxResumes times per run, or "interrupt" to mark them
+// interrupted.
+type IngestionConfig struct {
+	FileWorkers int    `mapstructure:"file_workers"`
+	OnRestart   string `mapstructure:"on_restart"`
+	MaxResumes  int    `mapstructure:"max_resumes"`
+}
+
+// StoreLocatorConfig holds the store locator sync settings. Chains maps a
+// chain slug to its store locator; chains without one are not synced.
+type StoreLocatorConfig struct {
+	// Interval between syncs; 0 disables the periodic sync
+	Interval time.Duration `mapstructure:"interval"`
+	// Address similarity, 0 to 1, at which a store matches a locator entry
+	MinSimilarity float64                            `mapstructure:"min_similarity"`
+	Chains        map[string]StoreLocatorChainConfig `mapstructure:"chains"`
+}
+
+// StoreLocatorChainConfig locates a chain's stores in its store locator's
+// JSON. Paths are dot separated keys, with numbers indexing arrays.
+type StoreLocatorChainConfig struct {
+	URL string `mapstructure:"url"`
+	// Path of the store list; empty for a top-level list
+	ItemsPath string                   `mapstructure:"items_path"`
+	Fields    StoreLocatorFieldsConfig `mapstructure:"fields"`
+}
+
+// StoreLocatorFieldsConfig holds the paths of a store's fields within a
+// store locator entry
+type StoreLocatorFieldsConfig struct {
+	ID         string `mapstructure:"id"`
+	Name       string `mapstructure:"name"`
+	Address    string `mapstructure:"address"`
+	City       string `mapstructure:"city"`
+	PostalCode string `mapstructure:"postal_code"`
+	Latitude   string `mapstructure:"latitude"`
+	Longitude  string `mapstructure:"longitude"`
+	Hours      string `mapstructure:"hours"`
+}
+
+// WebhooksConfig holds the delivery settings of webhooks registered through
+// the admin API
+type WebhooksConfig struct {
+	// How often the dispatcher polls for pending deliveries
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	// Attempts after which a failing delivery is given up
+	MaxAttempts int `mapstructure:"max_attempts"`
+}
+
+// DailyConfig holds the settings of the daily pipeline command
+type DailyConfig struct {
+	// Base URL of the server whose price cache is warmed after ingestion;
+	// empty skips the cache refresh
+	ServerURL string `mapstructure:"server_url"`
+	// Barcodes matched per batch
+	BarcodeBatchSize int `mapstructure:"barcode_batch_size"`
+}
+
+// ChainSettingsConfig holds a chain's ingest settings. Unset fields keep the
+// chain adapter's own value; the chain_settings table overrides set ones.
+type ChainSettingsConfig struct {
+	DiscoveryEnabled *bool                `mapstructure:"discovery_enabled"`
+	RateLimit        ChainRateLimitConfig `mapstructure:"rate_limit"`
+	CSV              ChainCSVConfig       `mapstructure:"csv"`
+}
+
+// ChainRateLimitConfig holds a chain's portal request limits
+type ChainRateLimitConfig struct {
+	RequestsPerSecond *int `mapstructure:"requests_per_second"`
+	MaxRetries        *int `mapstructure:"max_retries"`
+}
+
+// ChainCSVConfig holds how a CSV chain's files are read
+type ChainCSVConfig struct {
+	Delimiter *string `mapstructure:"delimiter"`
+	Encoding  *string `mapstructure:"encoding"`
+	HasHeader *bool   `mapstructure:"has_header"`
 }
 
 var globalConfig *Config
@@ -168,33 +424,133 @@ func loadDotEnvFile(filename string) error {
 
 // bindEnvVars binds environment variables to config keys
 func bindEnvVars(v *viper.Viper) {
+	// Service time zone
+	v.BindEnv("timezone", "TIMEZONE")
+
 	// Database
 	v.BindEnv("database.url", "DATABASE_URL")
+	v.BindEnv("database.interactive_lane_slots", "DB_INTERACTIVE_LANE_SLOTS")
+	v.BindEnv("database.batch_lane_slots", "DB_BATCH_LANE_SLOTS")
 
 	// Server
 	v.BindEnv("server.port", "PORT")
 	v.BindEnv("server.host", "HOST")
 
+	// gRPC
+	v.BindEnv("grpc.port", "GRPC_PORT")
+	v.BindEnv("grpc.host", "GRPC_HOST")
+
 	// Logging
 	v.BindEnv("logging.level", "LOG_LEVEL")
+	v.BindEnv("logging.components.cache", "LOG_LEVEL_CACHE")
+	v.BindEnv("logging.components.optimizer", "LOG_LEVEL_OPTIMIZER")
+	v.BindEnv("logging.components.pipeline", "LOG_LEVEL_PIPELINE")
+	v.BindEnv("logging.components.handlers", "LOG_LEVEL_HANDLERS")
+	v.BindEnv("logging.components.adapters", "LOG_LEVEL_ADAPTERS")
 
 	// Storage
+	v.BindEnv("storage.type", "STORAGE_TYPE")
 	v.BindEnv("storage.base_path", "STORAGE_PATH")
+	v.BindEnv("storage.bucket", "STORAGE_BUCKET")
+	v.BindEnv("storage.region", "STORAGE_REGION", "AWS_REGION")
+	v.BindEnv("storage.endpoint", "STORAGE_ENDPOINT")
+	v.BindEnv("storage.access_key_id", "STORAGE_ACCESS_KEY_ID", "AWS_ACCESS_KEY_ID")
+	v.BindEnv("storage.secret_access_key", "STORAGE_SECRET_ACCESS_KEY", "AWS_SECRET_ACCESS_KEY")
+	v.BindEnv("storage.session_token", "STORAGE_SESSION_TOKEN", "AWS_SESSION_TOKEN")
+	v.BindEnv("storage.force_path_style", "STORAGE_FORCE_PATH_STYLE")
+
+	// Outbox
+	v.BindEnv("outbox.webhook_urls", "OUTBOX_WEBHOOK_URLS")
+	v.BindEnv("outbox.webhook_secret", "OUTBOX_WEBHOOK_SECRET")
+
+	// Events
+	v.BindEnv("events.driver", "EVENTS_DRIVER")
+	v.BindEnv("events.kafka_brokers", "EVENTS_KAFKA_BROKERS")
+	v.BindEnv("events.nats_url", "EVENTS_NATS_URL")
+
+	// Privacy
+	v.BindEnv("privacy.coordinate_precision", "PRIVACY_COORDINATE_PRECISION")
+	v.BindEnv("privacy.optimization_retention_days", "PRIVACY_OPTIMIZATION_RETENTION_DAYS")
+
+	// Profiling
+	v.BindEnv("profiling.port", "PPROF_PORT")
+	v.BindEnv("profiling.host", "PPROF_HOST")
+	v.BindEnv("profiling.auto_capture", "PROFILING_AUTO_CAPTURE")
+	v.BindEnv("profiling.heap_threshold_mb", "PROFILING_HEAP_THRESHOLD_MB")
+	v.BindEnv("profiling.optimize_p99_budget", "PROFILING_OPTIMIZE_P99_BUDGET")
+
+	// SLOs
+	v.BindEnv("slo.alert_webhook_urls", "SLO_ALERT_WEBHOOK_URLS")
+	v.BindEnv("slo.fast_burn_rate", "SLO_FAST_BURN_RATE")
+
+	// Run baseline
+	v.BindEnv("run_baseline.enabled", "RUN_BASELINE_ENABLED")
+	v.BindEnv("run_baseline.max_file_deviation", "RUN_BASELINE_MAX_FILE_DEVIATION")
+	v.BindEnv("run_baseline.max_row_deviation", "RUN_BASELINE_MAX_ROW_DEVIATION")
+	v.BindEnv("run_baseline.max_price_deviation", "RUN_BASELINE_MAX_PRICE_DEVIATION")
+
+	// Anchor price compliance
+	v.BindEnv("anchor_compliance.interval", "ANCHOR_COMPLIANCE_INTERVAL")
+	v.BindEnv("anchor_compliance.min_anchor_age_days", "ANCHOR_COMPLIANCE_MIN_ANCHOR_AGE_DAYS")
+
+	// Store group churn
+	v.BindEnv("store_churn.interval", "STORE_CHURN_INTERVAL")
+	v.BindEnv("store_churn.window_days", "STORE_CHURN_WINDOW_DAYS")
+	v.BindEnv("store_churn.threshold", "STORE_CHURN_THRESHOLD")
+
+	// Price groups
+	v.BindEnv("price_groups.hash_version", "PRICE_GROUPS_HASH_VERSION")
+	v.BindEnv("price_groups.ignore_discount_end", "PRICE_GROUPS_IGNORE_DISCOUNT_END")
+	v.BindEnv("price_groups.price_bucket_cents", "PRICE_GROUPS_PRICE_BUCKET_CENTS")
+	v.BindEnv("price_groups.sort_by_item_id", "PRICE_GROUPS_SORT_BY_ITEM_ID")
+
+	// Price history
+	v.BindEnv("price_history.interval", "PRICE_HISTORY_INTERVAL")
+	v.BindEnv("price_history.retention_days", "PRICE_HISTORY_RETENTION_DAYS")
+
+	// Scheduler
+	v.BindEnv("scheduler.enabled", "SCHEDULER_ENABLED")
+	v.BindEnv("scheduler.interval", "SCHEDULER_INTERVAL")
+	v.BindEnv("scheduler.timezone", "SCHEDULER_TIMEZONE")
+
+	// Ingestion
+	v.BindEnv("ingestion.file_workers", "INGESTION_FILE_WORKERS")
+	v.BindEnv("ingestion.on_restart", "INGESTION_ON_RESTART")
+	v.BindEnv("ingestion.max_resumes", "INGESTION_MAX_RESUMES")
+
+	// Store locator
+	v.BindEnv("store_locator.interval", "STORE_LOCATOR_INTERVAL")
+	v.BindEnv("store_locator.min_similarity", "STORE_LOCATOR_MIN_SIMILARITY")
+
+	// Webhooks
+	v.BindEnv("webhooks.poll_interval", "WEBHOOKS_POLL_INTERVAL")
+	v.BindEnv("webhooks.max_attempts", "WEBHOOKS_MAX_ATTEMPTS")
+	v.BindEnv("daily.server_url", "DAILY_SERVER_URL")
+	v.BindEnv("daily.barcode_batch_size", "DAILY_BARCODE_BATCH_SIZE")
 }
 
 // setDefaults sets default configuration values
 func setDefaults(v *viper.Viper) {
+	// Service time zone
+	v.SetDefault("timezone", "Europe/Zagreb")
+
 	// Server defaults
 	v.SetDefault("server.port", 3000)
 	v.SetDefault("server.host", "0.0.0.0")
 	v.SetDefault("server.read_timeout", 30*time.Second)
 	v.SetDefault("server.write_timeout", 30*time.Second)
 
+	// gRPC defaults
+	v.SetDefault("grpc.port", 0)
+	v.SetDefault("grpc.host", "0.0.0.0")
+
 	// Database defaults
 	v.SetDefault("database.max_connections", 25)
 	v.SetDefault("database.min_connections", 5)
 	v.SetDefault("database.max_conn_lifetime", 1*time.Hour)
 	v.SetDefault("database.max_conn_idle_time", 30*time.Minute)
+	v.SetDefault("database.interactive_lane_slots", 0)
+	v.SetDefault("database.batch_lane_slots", 0)
 
 	// Rate limit defaults
 	v.SetDefault("rate_limit.requests_per_second", 2)
@@ -205,11 +561,94 @@ func setDefaults(v *viper.Viper) {
 	// Storage defaults
 	v.SetDefault("storage.type", "local")
 	v.SetDefault("storage.base_path", "./data/archives")
+	v.SetDefault("storage.retention_days", 90)
+	v.SetDefault("storage.retention_keep_per_chain", 7)
 
 	// Logging defaults
 	v.SetDefault("logging.level", "info")
 	v.SetDefault("logging.format", "json")
 	v.SetDefault("logging.no_color", false)
+
+	// Outbox defaults
+	v.SetDefault("outbox.webhook_urls", []string{})
+	v.SetDefault("outbox.poll_interval", 5*time.Second)
+
+	// Events defaults
+	v.SetDefault("events.driver", "")
+	v.SetDefault("events.kafka_brokers", []string{})
+	v.SetDefault("events.nats_url", "nats://localhost:4222")
+	v.SetDefault("events.topic", "kosarica.prices.changed")
+
+	// Privacy defaults
+	v.SetDefault("privacy.coordinate_precision", 2)
+	v.SetDefault("privacy.optimization_retention_days", 30)
+
+	// Profiling defaults
+	v.SetDefault("profiling.port", 6060)
+	v.SetDefault("profiling.host", "127.0.0.1")
+	v.SetDefault("profiling.auto_capture", true)
+	v.SetDefault("profiling.heap_threshold_mb", 1536)
+	v.SetDefault("profiling.optimize_p99_budget", 2*time.Second)
+	v.SetDefault("profiling.check_interval", 30*time.Second)
+	v.SetDefault("profiling.cpu_duration", 30*time.Second)
+	v.SetDefault("profiling.cooldown", 30*time.Minute)
+
+	// SLO defaults
+	v.SetDefault("slo.window", 30*24*time.Hour)
+	v.SetDefault("slo.evaluation_interval", 30*time.Second)
+	v.SetDefault("slo.fast_burn_rate", 14.4)
+	v.SetDefault("slo.alert_min_requests", 20)
+	v.SetDefault("slo.alert_webhook_urls", []string{})
+	v.SetDefault("slo.objectives", []map[string]any{
+		{"name": "optimize-latency", "route": "/internal/basket/optimize/*", "method": "POST", "latency": "300ms", "target": 0.95},
+		{"name": "optimize-errors", "route": "/internal/basket/optimize/*", "method": "POST", "target": 0.995},
+		{"name": "prices-errors", "route": "/internal/prices/*", "method": "GET", "target": 0.995},
+	})
+
+	// Run baseline defaults
+	v.SetDefault("run_baseline.enabled", true)
+	v.SetDefault("run_baseline.max_file_deviation", 0.25)
+	v.SetDefault("run_baseline.max_row_deviation", 0.30)
+	v.SetDefault("run_baseline.max_price_deviation", 0.15)
+
+	// Anchor price compliance defaults
+	v.SetDefault("anchor_compliance.interval", "24h")
+	v.SetDefault("anchor_compliance.min_anchor_age_days", 30)
+
+	// Store group churn defaults
+	v.SetDefault("store_churn.interval", "1h")
+	v.SetDefault("store_churn.window_days", 7)
+	v.SetDefault("store_churn.threshold", 5)
+
+	// Price groups defaults
+	v.SetDefault("price_groups.hash_version", 1)
+	v.SetDefault("price_groups.ignore_discount_end", true)
+	v.SetDefault("price_groups.price_bucket_cents", 1)
+	v.SetDefault("price_groups.sort_by_item_id", true)
+
+	// Price history defaults
+	v.SetDefault("price_history.interval", "24h")
+	v.SetDefault("price_history.retention_days", 365)
+
+	// Scheduler defaults
+	v.SetDefault("scheduler.enabled", true)
+	v.SetDefault("scheduler.interval", "1m")
+	v.SetDefault("scheduler.timezone", "")
+
+	// Ingestion defaults
+	v.SetDefault("ingestion.file_workers", 4)
+	v.SetDefault("ingestion.on_restart", "resume")
+	v.SetDefault("ingestion.max_resumes", 3)
+
+	// Store locator defaults
+	v.SetDefault("store_locator.interval", "168h")
+	v.SetDefault("store_locator.min_similarity", 0.6)
+
+	// Webhooks defaults
+	v.SetDefault("webhooks.poll_interval", 5*time.Second)
+	v.SetDefault("webhooks.max_attempts", 10)
+	v.SetDefault("daily.server_url", "")
+	v.SetDefault("daily.barcode_batch_size", 100)
 }
 
 // Get returns the global configuration
@@ -15,9 +15,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/internal/basket/cache/health": {
-            "get": {
-                "description": "Returns the health status and freshness information for all cached chains",
+        "/api/v1/basket/optimize/multi": {
+            "post": {
+                "description": "Finds the optimal distribution of basket items across multiple stores. With a location the stores are ordered as a short round trip from it, numbered by visitOrder, with travelDistanceKm its length. Stores are chosen by basket total plus travelCost: a configured cost per km of the trip and per store after the first. Missing items are priced by the configured penalty strategy unless penaltyStrategy overrides it; the strategy used is reported. Under load only the greedy algorithm runs, and results served from a stale or degraded cache carry a staleness field.",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,57 +25,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "cache"
+                    "basket"
                 ],
-                "summary": "Get cache health",
-                "responses": {
-                    "200": {
-                        "description": "Cache health status",
+                "summary": "Optimize basket across multiple stores",
+                "parameters": [
+                    {
+                        "description": "Optimization request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/handlers.OptimizeRequest"
                         }
                     },
-                    "503": {
-                        "description": "Cache not initialized",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    }
-                }
-            }
-        },
-        "/internal/basket/cache/refresh/{chainSlug}": {
-            "post": {
-                "description": "Refreshes the price cache for a specific chain",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "cache"
-                ],
-                "summary": "Refresh chain cache",
-                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Add display strings of the amounts, e.g. 1,99 €, and discount percentages",
+                        "name": "includeFormatted",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "Chain slug identifier",
-                        "name": "chainSlug",
-                        "in": "path",
-                        "required": true
+                        "description": "Language of messages: hr or en (default)",
+                        "name": "Accept-Language",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Cache refreshed",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/handlers.MultiStoreResult"
                         }
                     },
                     "400": {
@@ -97,40 +77,7 @@ const docTemplate = `{
                         }
                     },
                     "503": {
-                        "description": "Cache not initialized",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    }
-                }
-            }
-        },
-        "/internal/basket/cache/warmup": {
-            "post": {
-                "description": "Triggers a full cache warmup for all chains",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "cache"
-                ],
-                "summary": "Warm up price cache",
-                "responses": {
-                    "200": {
-                        "description": "Cache warmed up",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "500": {
-                        "description": "Internal server error",
+                        "description": "Cache unavailable or optimizer overloaded (see Retry-After)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -138,8 +85,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "503": {
-                        "description": "Cache not initialized",
+                    "504": {
+                        "description": "Optimization timed out",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -150,9 +97,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/internal/basket/optimize/multi": {
+        "/api/v1/basket/optimize/single": {
             "post": {
-                "description": "Finds the optimal distribution of basket items across multiple stores",
+                "description": "Finds the best single store for a basket of items based on price and coverage. Stores are ranked by coverage, then by sortBy: price (the default), distance from the location, or balanced, the price plus a configured cost per km, and with a location each store carries its distance in km. minCoverage leaves out stores with less coverage, and offset and limit (default 50) page through the rest, with totalCandidates counting them all. Missing items are priced by the configured penalty strategy unless penaltyStrategy overrides it; the strategy used is reported. Results served from a stale or degraded cache carry a staleness field.",
                 "consumes": [
                     "application/json"
                 ],
@@ -162,7 +109,7 @@ const docTemplate = `{
                 "tags": [
                     "basket"
                 ],
-                "summary": "Optimize basket across multiple stores",
+                "summary": "Optimize basket for single store",
                 "parameters": [
                     {
                         "description": "Optimization request",
@@ -172,13 +119,25 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/handlers.OptimizeRequest"
                         }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Add display strings of the amounts, e.g. 1,99 €, and discount percentages",
+                        "name": "includeFormatted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language of messages: hr or en (default)",
+                        "name": "Accept-Language",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.MultiStoreResult"
+                            "$ref": "#/definitions/handlers.SingleStoreResponse"
                         }
                     },
                     "400": {
@@ -200,16 +159,7 @@ const docTemplate = `{
                         }
                     },
                     "503": {
-                        "description": "Cache unavailable",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "504": {
-                        "description": "Optimization timed out",
+                        "description": "Cache unavailable or optimizer overloaded (see Retry-After)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -220,9 +170,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/internal/basket/optimize/single": {
-            "post": {
-                "description": "Finds the best single store for a basket of items based on price and coverage",
+        "/api/v1/items/search": {
+            "get": {
+                "description": "Search for items by name with optional chain, category, brand and discount filters. Requires minimum 3 characters. Matching ignores case and Croatian diacritics and spelling variants, so \"mljeko\", \"mlijeko\" and \"MLIJEKO\" find the same items, and names chains publish in other languages (nameI18n) match too; when nothing matches, items within a few typos of the query are returned with fuzzy set. Facet counts per chain, category, brand and discount state are returned with the results; each facet applies every filter but its own, and at most 20 values are returned per facet, most frequent first.",
                 "consumes": [
                     "application/json"
                 ],
@@ -230,26 +180,57 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "basket"
+                    "items"
                 ],
-                "summary": "Optimize basket for single store",
+                "summary": "Search items",
                 "parameters": [
                     {
-                        "description": "Optimization request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.OptimizeRequest"
-                        }
+                        "minLength": 3,
+                        "type": "string",
+                        "description": "Search query (min 3 chars)",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by chain slug",
+                        "name": "chainSlug",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by category",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by brand",
+                        "name": "brand",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only items on discount in at least one store (true) or in none (false)",
+                        "name": "onDiscount",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Number of items to return",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Optimization results",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/handlers.SearchItemsResponse"
                         }
                     },
                     "400": {
@@ -269,22 +250,13 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
-                    },
-                    "503": {
-                        "description": "Cache unavailable",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
                     }
                 }
             }
         },
-        "/internal/ingestion/runs": {
+        "/api/v1/prices/{chainSlug}/{storeId}": {
             "get": {
-                "description": "Returns a paginated list of ingestion runs with optional chain and status filters",
+                "description": "Returns paginated prices for a specific store in a chain. Identical concurrent requests share one computed response.",
                 "consumes": [
                     "application/json"
                 ],
@@ -292,33 +264,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ingestion"
+                    "prices"
                 ],
-                "summary": "List ingestion runs",
+                "summary": "Get store prices",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Filter by chain slug",
+                        "description": "Chain slug identifier",
                         "name": "chainSlug",
-                        "in": "query"
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "enum": [
-                            "pending",
-                            "running",
-                            "completed",
-                            "failed"
-                        ],
                         "type": "string",
-                        "description": "Filter by status",
-                        "name": "status",
-                        "in": "query"
+                        "description": "Store ID",
+                        "name": "storeId",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "maximum": 100,
+                        "maximum": 500,
                         "minimum": 1,
                         "type": "integer",
-                        "default": 20,
+                        "default": 100,
                         "description": "Number of items to return",
                         "name": "limit",
                         "in": "query"
@@ -330,13 +298,19 @@ const docTemplate = `{
                         "description": "Number of items to skip",
                         "name": "offset",
                         "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Add display strings of the amounts, e.g. 1,99 €, and the discount percentage",
+                        "name": "includeFormatted",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ListRunsResponse"
+                            "$ref": "#/definitions/handlers.GetStorePricesResponse"
                         }
                     },
                     "400": {
@@ -360,9 +334,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/internal/ingestion/runs/{runId}": {
+        "/internal/admin/chain-settings": {
             "get": {
-                "description": "Returns a single ingestion run by its ID",
+                "description": "Returns the ingest setting overrides stored in the database, one entry per chain that has them",
                 "consumes": [
                     "application/json"
                 ],
@@ -370,41 +344,46 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ingestion"
-                ],
-                "summary": "Get ingestion run",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Run ID",
-                        "name": "runId",
-                        "in": "path",
-                        "required": true
-                    }
+                    "chains"
                 ],
+                "summary": "List chain settings overrides",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.IngestionRun"
+                            "$ref": "#/definitions/handlers.ListChainSettingsResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad request",
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    },
-                    "404": {
-                        "description": "Run not found",
+                    }
+                }
+            }
+        },
+        "/internal/admin/chains": {
+            "get": {
+                "description": "Returns all chains from the chains table with their metadata, including disabled chains",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chains"
+                ],
+                "summary": "List chain registry",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/handlers.ListAdminChainsResponse"
                         }
                     },
                     "500": {
@@ -418,8 +397,8 @@ const docTemplate = `{
                     }
                 }
             },
-            "delete": {
-                "description": "Deletes an ingestion run and all its associated files and errors",
+            "post": {
+                "description": "Adds a chain to the chain registry. Ingestion still requires an adapter for the slug.",
                 "consumes": [
                     "application/json"
                 ],
@@ -427,24 +406,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ingestion"
+                    "chains"
                 ],
-                "summary": "Delete ingestion run",
+                "summary": "Create chain",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Run ID",
-                        "name": "runId",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Run deleted",
+                        "description": "Chain to create",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/handlers.CreateChainRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/database.Chain"
                         }
                     },
                     "400": {
@@ -456,8 +436,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "Run not found",
+                    "409": {
+                        "description": "Chain already exists",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -477,9 +457,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/internal/ingestion/runs/{runId}/errors": {
-            "get": {
-                "description": "Returns a paginated list of errors for a specific ingestion run",
+        "/internal/admin/chains/{slug}": {
+            "delete": {
+                "description": "Marks a chain as disabled. Its data is kept; re-enable it with PATCH.",
                 "consumes": [
                     "application/json"
                 ],
@@ -487,40 +467,80 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ingestion"
+                    "chains"
                 ],
-                "summary": "List ingestion errors",
+                "summary": "Disable chain",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Run ID",
-                        "name": "runId",
+                        "description": "Chain slug",
+                        "name": "slug",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Chain"
+                        }
+                    },
+                    "404": {
+                        "description": "Chain not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Updates the name, display name, brand color, website, logo, metadata or enabled flag of a chain. Omitted fields are left unchanged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chains"
+                ],
+                "summary": "Update chain",
+                "parameters": [
                     {
-                        "maximum": 100,
-                        "minimum": 1,
-                        "type": "integer",
-                        "default": 50,
-                        "description": "Number of items to return",
-                        "name": "limit",
-                        "in": "query"
+                        "type": "string",
+                        "description": "Chain slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "minimum": 0,
-                        "type": "integer",
-                        "default": 0,
-                        "description": "Number of items to skip",
-                        "name": "offset",
-                        "in": "query"
+                        "description": "Fields to update",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.UpdateChainRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ListErrorsResponse"
+                            "$ref": "#/definitions/database.Chain"
                         }
                     },
                     "400": {
@@ -532,6 +552,15 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "404": {
+                        "description": "Chain not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -544,9 +573,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/internal/ingestion/runs/{runId}/files": {
+        "/internal/admin/chains/{slug}/mapping": {
             "get": {
-                "description": "Returns a paginated list of files for a specific ingestion run",
+                "description": "Returns the compiled-in column mapping of a CSV chain, its stored override and the mapping used for ingestion",
                 "consumes": [
                     "application/json"
                 ],
@@ -554,44 +583,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ingestion"
+                    "chains"
                 ],
-                "summary": "List ingestion files",
+                "summary": "Get column mapping",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Run ID",
-                        "name": "runId",
+                        "description": "Chain slug",
+                        "name": "slug",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "maximum": 100,
-                        "minimum": 1,
-                        "type": "integer",
-                        "default": 50,
-                        "description": "Number of items to return",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "minimum": 0,
-                        "type": "integer",
-                        "default": 0,
-                        "description": "Number of items to skip",
-                        "name": "offset",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ListFilesResponse"
+                            "$ref": "#/definitions/handlers.ColumnMappingResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad request",
+                    "404": {
+                        "description": "Chain not found or not CSV-based",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -609,11 +621,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/internal/ingestion/runs/{runId}/rerun": {
-            "post": {
-                "description": "Creates a new run that reruns a specific file, chunk, or entry from an existing run",
+            },
+            "put": {
+                "description": "Validates and stores a column mapping override for a CSV chain. Verify it with the test-parse endpoint before enabling it.",
                 "consumes": [
                     "application/json"
                 ],
@@ -621,33 +631,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ingestion"
+                    "chains"
                 ],
-                "summary": "Rerun ingestion",
+                "summary": "Set column mapping override",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Original run ID",
-                        "name": "runId",
+                        "description": "Chain slug",
+                        "name": "slug",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Rerun request",
+                        "description": "Override",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.RerunRunRequest"
+                            "$ref": "#/definitions/handlers.SetColumnMappingRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Rerun created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/database.ColumnMappingOverride"
                         }
                     },
                     "400": {
@@ -660,7 +669,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Run not found",
+                        "description": "Chain not found or not CSV-based",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -678,11 +687,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/internal/ingestion/stats": {
-            "get": {
-                "description": "Returns aggregated statistics for ingestion runs within a time range (24h/7d/30d buckets)",
+            },
+            "delete": {
+                "description": "Removes the override so the chain is parsed with its compiled-in mapping",
                 "consumes": [
                     "application/json"
                 ],
@@ -690,22 +697,15 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ingestion"
+                    "chains"
                 ],
-                "summary": "Get ingestion stats",
+                "summary": "Delete column mapping override",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Start date (RFC3339 format)",
-                        "name": "from",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "End date (RFC3339 format)",
-                        "name": "to",
-                        "in": "query",
+                        "description": "Chain slug",
+                        "name": "slug",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -713,11 +713,14 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.GetStatsResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "Bad request",
+                    "404": {
+                        "description": "Override not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -737,49 +740,46 @@ const docTemplate = `{
                 }
             }
         },
-        "/internal/items/search": {
-            "get": {
-                "description": "Search for items by name with optional chain filter. Requires minimum 3 characters.",
+        "/internal/admin/chains/{slug}/mapping/test": {
+            "post": {
+                "description": "Parses an uploaded sample file through the chain's adapter with the given override (or the stored one, enabled or not) without persisting anything",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "items"
+                    "chains"
                 ],
-                "summary": "Search items",
+                "summary": "Test-parse with column mapping",
                 "parameters": [
                     {
-                        "minLength": 3,
                         "type": "string",
-                        "description": "Search query (min 3 chars)",
-                        "name": "q",
-                        "in": "query",
+                        "description": "Chain slug",
+                        "name": "slug",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Filter by chain slug",
-                        "name": "chainSlug",
-                        "in": "query"
+                        "type": "file",
+                        "description": "Sample price file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
                     },
                     {
-                        "maximum": 100,
-                        "minimum": 1,
-                        "type": "integer",
-                        "default": 20,
-                        "description": "Number of items to return",
-                        "name": "limit",
-                        "in": "query"
+                        "type": "string",
+                        "description": "Override as a JSON object; defaults to the stored override",
+                        "name": "mapping",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.SearchItemsResponse"
+                            "$ref": "#/definitions/handlers.TestParseResponse"
                         }
                     },
                     "400": {
@@ -791,6 +791,15 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "404": {
+                        "description": "Chain not found or not CSV-based",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -803,9 +812,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/internal/prices/{chainSlug}/{storeId}": {
+        "/internal/admin/chains/{slug}/settings": {
             "get": {
-                "description": "Returns paginated prices for a specific store in a chain",
+                "description": "Returns a chain's ingest settings from the config file, its overrides stored in the database and the merged settings used for ingestion",
                 "consumes": [
                     "application/json"
                 ],
@@ -813,47 +822,80 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "prices"
+                    "chains"
                 ],
-                "summary": "Get store prices",
+                "summary": "Get chain settings",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Chain slug identifier",
-                        "name": "chainSlug",
+                        "description": "Chain slug",
+                        "name": "slug",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ChainSettingsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Chain not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Validates and stores a chain's ingest setting overrides, replacing any stored before. They apply from the chain's next run or parsed file, within a minute.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chains"
+                ],
+                "summary": "Set chain settings overrides",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "Store ID",
-                        "name": "storeId",
+                        "description": "Chain slug",
+                        "name": "slug",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "maximum": 500,
-                        "minimum": 1,
-                        "type": "integer",
-                        "default": 100,
-                        "description": "Number of items to return",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "minimum": 0,
-                        "type": "integer",
-                        "default": 0,
-                        "description": "Number of items to skip",
-                        "name": "offset",
-                        "in": "query"
+                        "description": "Overrides",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SetChainSettingsRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.GetStorePricesResponse"
+                            "$ref": "#/definitions/database.ChainSettings"
                         }
                     },
                     "400": {
@@ -865,497 +907,7290 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "404": {
+                        "description": "Chain not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Removes a chain's stored overrides, so it is ingested with the config file's settings",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chains"
+                ],
+                "summary": "Delete chain settings overrides",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Overrides not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/admin/config/logging": {
+            "get": {
+                "description": "Returns the default log level and the effective level of each component (cache, optimizer, pipeline, handlers, adapters)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "config"
+                ],
+                "summary": "Get log levels",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.LogLevelsResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Changes the default log level and per-component overrides without a restart. Changes are not persisted and reset to the configured levels on restart.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "config"
+                ],
+                "summary": "Set log levels",
+                "parameters": [
+                    {
+                        "description": "Log levels",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SetLogLevelsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.LogLevelsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/admin/failed-rows/{id}/raw": {
+            "get": {
+                "description": "Lazily loads the raw source row of a failed row, whether it is stored inline or as a compressed raw payload.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ingestion"
+                ],
+                "summary": "Get raw data of a failed row",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Failed row ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Original raw row",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Failed row or raw data not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/admin/flags": {
+            "get": {
+                "description": "Returns all feature flags with their rollout rules",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "flags"
+                ],
+                "summary": "List feature flags",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ListFeatureFlagsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/admin/flags/{key}": {
+            "put": {
+                "description": "Creates or replaces a feature flag. Chains limits the flag to a staged rollout; an empty list enables it for every chain.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "flags"
+                ],
+                "summary": "Set feature flag",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Flag key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Flag settings",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SetFeatureFlagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.FeatureFlag"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Removes a feature flag; lookups of a missing flag evaluate to off",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "flags"
+                ],
+                "summary": "Delete feature flag",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Flag key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Flag not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/admin/ingest": {
+            "post": {
+                "description": "Creates a pending run for each chain, or every enabled chain for [\"all\"], and ingests them in the background with at most concurrency chains at a time. Chains start smallest first, by the entries of their last completed run; chains never ingested start last. A chain already being ingested waits for that run to finish. Returns the batch ID to poll for aggregate progress.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ingestion"
+                ],
+                "summary": "Ingest several chains",
+                "parameters": [
+                    {
+                        "description": "Chains to ingest",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IngestChainsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IngestChainsStartedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or chain",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/admin/ingest/batches/{batchId}": {
+            "get": {
+                "description": "Returns a bulk ingest batch with the status of each chain's run and the aggregate progress. The batch is running while any run is pending or running, then completed, partial when some runs failed or were interrupted, or failed when none completed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ingestion"
+                ],
+                "summary": "Get ingestion batch",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Batch ID",
+                        "name": "batchId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IngestionBatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid batch ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Batch not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/admin/mappings/infer": {
+            "post": {
+                "description": "Profiles the columns of an uploaded CSV or XLSX sample (price-, date-, barcode-, integer- and text-like values plus header names) and proposes a column mapping for a human to confirm",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chains"
+                ],
+                "summary": "Infer column mapping",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Sample price file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 500,
+                        "description": "Number of data rows to profile",
+                        "name": "rows",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/mappings.Proposal"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/admin/optimizer/config": {
+            "put": {
+                "description": "Builds a new optimizer version from the active version's settings with the given ones changed. The version is built in the background and validated by running the last 20 served baskets through it and the active version: it is activated only if it serves every basket the active version serves and takes at most twice as long. Requests in flight finish on the version they started on. The replaced version is kept for rollback.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "optimizer"
+                ],
+                "summary": "Set optimizer config",
+                "parameters": [
+                    {
+                        "description": "Settings to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SetOptimizerConfigRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SetOptimizerConfigResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "A version is already being built",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/admin/optimizer/rollback": {
+            "post": {
+                "description": "Swaps the previous optimizer version back in without rebuilding it. The replaced version becomes the previous one, so rolling back again restores it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "optimizer"
+                ],
+                "summary": "Roll back optimizer version",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OptimizerVersionInfo"
+                        }
+                    },
+                    "409": {
+                        "description": "No previous version",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/admin/optimizer/versions": {
+            "get": {
+                "description": "Returns the optimizer version serving requests, the previous version kept for rollback, the version being built, if any, and the last version rejected by its canary run",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "optimizer"
+                ],
+                "summary": "Get optimizer versions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OptimizerVersionsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/admin/privacy/users/{userRef}": {
+            "get": {
+                "description": "Returns every stored record keyed by a caller-provided user reference (currently basket optimization results), for data subject access requests.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "privacy"
+                ],
+                "summary": "Export user data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Caller-provided user reference",
+                        "name": "userRef",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.UserDataExport"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes every stored record keyed by a caller-provided user reference, for erasure requests. Deleting an unknown reference succeeds with zero counts.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "privacy"
+                ],
+                "summary": "Delete user data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Caller-provided user reference",
+                        "name": "userRef",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.DeleteUserDataResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/admin/products/{productId}/merge": {
+            "post": {
+                "description": "Merges duplicate products into the product in one transaction. Their retailer item links, canonical barcodes, aliases, relations, match candidates, rejections and reviews move to the product, their names become its aliases, and they are deleted. The merge is logged in product_catalog_audit with the deleted rows, and a catalog.products.merged outbox event tells caches and search indexes to drop them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Merge products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product to merge into",
+                        "name": "productId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Duplicates to merge, who merges them and why",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MergeProductsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MergeProductsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/admin/products/{productId}/split": {
+            "post": {
+                "description": "Moves some of a product's linked retailer items to a new product, described by the first of them and named name if given, in one transaction. Canonical barcodes carried only by the moved items move with them. At least one item must stay with the product. The split is logged in product_catalog_audit, and a catalog.product.split outbox event tells caches and search indexes to refresh both products.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Split product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product to split",
+                        "name": "productId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Items to detach, who splits them and why",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SplitProductRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SplitProductResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Items are not some of the product's items",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/admin/raw-payloads/{id}": {
+            "get": {
+                "description": "Returns a raw source row stored compressed out of line. The body is the original row, served as JSON when it is valid JSON.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ingestion"
+                ],
+                "summary": "Get raw payload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Raw payload ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Original raw row",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Raw payload not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/admin/schedules": {
+            "get": {
+                "description": "Returns the cron schedule of every chain ingested automatically, with its next run time and the outcome of its last trigger",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "List ingestion schedules",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ListSchedulesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/admin/schedules/{chain}": {
+            "get": {
+                "description": "Returns a chain's cron schedule with its next run time and the outcome of its last trigger",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "Get ingestion schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain slug",
+                        "name": "chain",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.IngestionSchedule"
+                        }
+                    },
+                    "404": {
+                        "description": "Schedule not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Creates or replaces a chain's cron schedule: five fields (minute, hour, day of month, month, day of week) or a macro such as @daily, evaluated in the scheduler's time zone. The next run time is recomputed from now. A due run is skipped while a run of the chain is still pending or running.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "Set ingestion schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain slug",
+                        "name": "chain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Schedule settings",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SetScheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.IngestionSchedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Removes a chain's schedule, so it is only ingested on request. A schedule in the config is created again at the next startup; disable it instead to keep it off.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "Delete ingestion schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain slug",
+                        "name": "chain",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Schedule not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/admin/store-locator/unmatched": {
+            "get": {
+                "description": "Returns the physical stores the last store locator sync of their chain could not match to a listed store, for review: usually stores with a missing or misspelled address, or ones the chain no longer lists.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stores"
+                ],
+                "summary": "List stores unmatched by store locator",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by chain slug",
+                        "name": "chainSlug",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ListUnmatchedLocatorStoresResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/admin/store-locator/{chain}/sync": {
+            "post": {
+                "description": "Fetches the chain's store locator and matches each physical store to a listed store by address similarity, within the same city and postal code. Matched stores get the listed coordinates where they have none and the listed opening hours; unmatched stores are flagged for review. A locator that fails or lists no stores changes nothing.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stores"
+                ],
+                "summary": "Sync stores with store locator",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain slug",
+                        "name": "chain",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/storelocator.SyncResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Chain or store locator not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "502": {
+                        "description": "Store locator could not be read",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/admin/webhooks": {
+            "get": {
+                "description": "Returns every webhook notified of ingestion run events, and the event types webhooks can subscribe to. Secrets are not returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ListWebhooksResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Registers a URL to be notified of ingestion run events. Each delivery is a POST of the event as JSON with X-Webhook-Id, X-Webhook-Delivery-Id and X-Webhook-Event-Type headers and an X-Webhook-Signature of \"sha256=\" followed by the hex HMAC-SHA256 of the body under the webhook's secret. A secret is generated when none is given; it is returned only in this response. Delivery is at-least-once, so receivers should deduplicate on the delivery ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Create webhook",
+                "parameters": [
+                    {
+                        "description": "Webhook settings",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateWebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/admin/webhooks/{id}": {
+            "get": {
+                "description": "Returns a webhook's URL, subscribed event types and enabled state. The secret is not returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Webhook"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces a webhook's URL, event types, enabled state and description. The secret is replaced only when one is given. Pending deliveries of a disabled webhook wait until it is enabled again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Update webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Webhook settings",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Removes a webhook together with its pending and past deliveries",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/admin/webhooks/{id}/deliveries": {
+            "get": {
+                "description": "Returns a webhook's most recent deliveries, newest first, with their attempts and the status and error of the last one. Delivered and given-up deliveries are pruned after seven days.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum deliveries (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ListWebhookDeliveriesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/basket/availability": {
+            "post": {
+                "description": "Returns which items of a basket a given store has, priced as the optimizers price them, and which are missing. No stores are compared or ranked and missing items carry no penalty; use it to check a known store rather than running the single-store optimizer. Responses served from a stale snapshot carry a staleness field.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "basket"
+                ],
+                "summary": "Check basket availability at a store",
+                "parameters": [
+                    {
+                        "description": "Basket and store",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BasketAvailabilityRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Add display strings of the amounts, e.g. 1,99 €, and discount percentages",
+                        "name": "includeFormatted",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BasketAvailabilityResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Chain not cached or store not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Cache not initialized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/basket/cache/health": {
+            "get": {
+                "description": "Returns the health status and freshness information for all cached chains, and the progress of the latest warmup with the optimize requests it held or rejected",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cache"
+                ],
+                "summary": "Get cache health",
+                "responses": {
+                    "200": {
+                        "description": "Cache health status",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "503": {
+                        "description": "Cache not initialized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/basket/cache/refresh/{chainSlug}": {
+            "post": {
+                "description": "Refreshes the price cache for a specific chain",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cache"
+                ],
+                "summary": "Refresh chain cache",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain slug identifier",
+                        "name": "chainSlug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cache refreshed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Refresh held while the chain's latest run is suspect",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Cache not initialized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/basket/cache/warmup": {
+            "post": {
+                "description": "Triggers a full cache warmup for all chains",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cache"
+                ],
+                "summary": "Warm up price cache",
+                "responses": {
+                    "200": {
+                        "description": "Cache warmed up",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Cache not initialized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/basket/optimize/multi": {
+            "post": {
+                "description": "Finds the optimal distribution of basket items across multiple stores. With a location the stores are ordered as a short round trip from it, numbered by visitOrder, with travelDistanceKm its length. Stores are chosen by basket total plus travelCost: a configured cost per km of the trip and per store after the first. Missing items are priced by the configured penalty strategy unless penaltyStrategy overrides it; the strategy used is reported. Under load only the greedy algorithm runs, and results served from a stale or degraded cache carry a staleness field.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "basket"
+                ],
+                "summary": "Optimize basket across multiple stores",
+                "parameters": [
+                    {
+                        "description": "Optimization request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OptimizeRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Add display strings of the amounts, e.g. 1,99 €, and discount percentages",
+                        "name": "includeFormatted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language of messages: hr or en (default)",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MultiStoreResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Cache unavailable or optimizer overloaded (see Retry-After)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "504": {
+                        "description": "Optimization timed out",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/basket/optimize/single": {
+            "post": {
+                "description": "Finds the best single store for a basket of items based on price and coverage. Stores are ranked by coverage, then by sortBy: price (the default), distance from the location, or balanced, the price plus a configured cost per km, and with a location each store carries its distance in km. minCoverage leaves out stores with less coverage, and offset and limit (default 50) page through the rest, with totalCandidates counting them all. Missing items are priced by the configured penalty strategy unless penaltyStrategy overrides it; the strategy used is reported. Results served from a stale or degraded cache carry a staleness field.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "basket"
+                ],
+                "summary": "Optimize basket for single store",
+                "parameters": [
+                    {
+                        "description": "Optimization request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OptimizeRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Add display strings of the amounts, e.g. 1,99 €, and discount percentages",
+                        "name": "includeFormatted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language of messages: hr or en (default)",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SingleStoreResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Cache unavailable or optimizer overloaded (see Retry-After)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/cdc/prices": {
+            "get": {
+                "description": "Streams per-item price changes recorded by persist as newline-delimited JSON, in cursor order. Every record carries its cursor; pass the last cursor seen (also sent as the X-Next-Cursor trailer) as since to resume. An empty page means the consumer is caught up.",
+                "produces": [
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Stream price changes (CDC)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resume after this cursor",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by chain slug",
+                        "name": "chainSlug",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 50000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10000,
+                        "description": "Maximum records to return",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "One record per line",
+                        "schema": {
+                            "$ref": "#/definitions/database.PriceChangeRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/chains/metadata": {
+            "get": {
+                "description": "Returns display name, logo URL, brand color and website for every enabled chain. The response carries an ETag; send it back in If-None-Match to get 304 while the chains are unchanged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chains"
+                ],
+                "summary": "List chain metadata",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ListChainMetadataResponse"
+                        },
+                        "headers": {
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "private, max-age=60, must-revalidate"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Hash of the response body"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/chains/{slug}/metadata": {
+            "get": {
+                "description": "Returns display name, logo URL, brand color and website for a chain, including disabled chains. The response carries an ETag; send it back in If-None-Match to get 304 while the chain is unchanged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chains"
+                ],
+                "summary": "Get chain metadata",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ChainMetadata"
+                        },
+                        "headers": {
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "private, max-age=60, must-revalidate"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Hash of the response body"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "404": {
+                        "description": "Chain not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/compliance/anchor-prices": {
+            "get": {
+                "description": "Returns the stored anchor price (\"sidrena cijena\") compliance reports, newest first: per chain, the store prices on an active discount with no anchor price or with one set fewer than the configured minimum days before the report, and their shares of all discounted prices. Reports are computed by a scheduled job, daily by default.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "compliance"
+                ],
+                "summary": "Get anchor price compliance reports",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by chain slug",
+                        "name": "chainSlug",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 365,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 30,
+                        "description": "Number of days of history to include",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.GetAnchorPriceComplianceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/flags": {
+            "get": {
+                "description": "Evaluates all flags for a chain, applying X-Feature-Flags request overrides",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "flags"
+                ],
+                "summary": "Evaluate feature flags",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain to evaluate chain-limited flags for",
+                        "name": "chainSlug",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated overrides; prefix a key with '-' to force it off",
+                        "name": "X-Feature-Flags",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.EvaluateFeatureFlagsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/ingestion/parse-profiles": {
+            "get": {
+                "description": "Returns parse CPU time, allocation counts and rows/sec per chain, aggregated from the file metadata of profiled parses. Parses are profiled for chains with the parse_profiling feature flag on.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ingestion"
+                ],
+                "summary": "Get parser profiles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by chain slug",
+                        "name": "chainSlug",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 90,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 7,
+                        "description": "Number of days to include",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.GetParseProfilesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/ingestion/parse-profiles/report": {
+            "get": {
+                "description": "Compares each chain's profiled parses over the last 7 days with the 7 days before and flags chains whose rows/sec dropped, or CPU or allocations per row grew, by more than the threshold. Regressed chains are listed first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ingestion"
+                ],
+                "summary": "Get parser performance report",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "default": 0.2,
+                        "description": "Relative change that counts as a regression",
+                        "name": "threshold",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/jobs.ParseProfileReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/ingestion/runs": {
+            "get": {
+                "description": "Returns a paginated list of ingestion runs with optional chain and status filters. Each run links to the run it reruns (parentRunId) and to its own reruns (childRunIds).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ingestion"
+                ],
+                "summary": "List ingestion runs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by chain slug",
+                        "name": "chainSlug",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "running",
+                            "completed",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Filter by status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "staging",
+                            "staged",
+                            "published",
+                            "rejected"
+                        ],
+                        "type": "string",
+                        "description": "Filter by staging status; staged lists runs awaiting review",
+                        "name": "stagingStatus",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "passed",
+                            "suspect",
+                            "cleared"
+                        ],
+                        "type": "string",
+                        "description": "Filter by baseline status; suspect lists runs holding cache refresh",
+                        "name": "baselineStatus",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Number of items to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ListRunsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/ingestion/runs/{runId}": {
+            "get": {
+                "description": "Returns a single ingestion run by its ID. completionPercent is the share of files completed or deduplicated, reconciled from the run's files when it completes and estimated from processedFiles/totalFiles before that. The run links to the run it reruns (parentRunId) and to its own reruns (childRunIds)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ingestion"
+                ],
+                "summary": "Get ingestion run",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Run ID",
+                        "name": "runId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IngestionRun"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Run not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes an ingestion run and all its associated files and errors. A run that has reruns is only deleted with cascade=true, which deletes its reruns (and theirs) as well.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ingestion"
+                ],
+                "summary": "Delete ingestion run",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Run ID",
+                        "name": "runId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Also delete the run's reruns",
+                        "name": "cascade",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Run deleted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Run not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Run has reruns and cascade is not set",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/ingestion/runs/{runId}/clear-suspect": {
+            "post": {
+                "description": "Marks a run flagged suspect by its comparison with the same weekday a week earlier as cleared after review, releasing the hold on the chain's price cache refresh. Only suspect runs (baselineStatus suspect) can be cleared; the run's baselineReport says which checks failed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ingestion"
+                ],
+                "summary": "Clear suspect run",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Run ID",
+                        "name": "runId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Who clears the run",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ClearSuspectRunRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Run cleared",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Run not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Run is not suspect",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/ingestion/runs/{runId}/errors": {
+            "get": {
+                "description": "Returns a paginated list of errors and aggregated parse warnings for a specific ingestion run",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ingestion"
+                ],
+                "summary": "List ingestion errors",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Run ID",
+                        "name": "runId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Number of items to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "warning",
+                            "error",
+                            "critical"
+                        ],
+                        "type": "string",
+                        "description": "Only return entries of this severity",
+                        "name": "severity",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ListErrorsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/ingestion/runs/{runId}/files": {
+            "get": {
+                "description": "Returns a paginated list of files for a specific ingestion run. Files move from pending through downloading, parsing and persisting to completed, failed or skipped; statusTimestamps records when each status was entered and stageDurationsMs how long each finished stage took. Files that did not count as processed carry a discrepancyReason: skipped, deduplicated or failed. parserVersion is the version of the chain adapter's parser that parsed the file.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ingestion"
+                ],
+                "summary": "List ingestion files",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Run ID",
+                        "name": "runId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Number of items to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ListFilesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/ingestion/runs/{runId}/lineage": {
+            "get": {
+                "description": "Returns the full rerun tree containing a run: the original run at the root and every rerun below the run it reruns, oldest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ingestion"
+                ],
+                "summary": "Get run lineage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Run ID",
+                        "name": "runId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RunLineageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Run not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/ingestion/runs/{runId}/publish": {
+            "post": {
+                "description": "Writes the rows of a staged run to the live tables in a single transaction and marks it published. Only runs held for review (stagingStatus staged) can be published; the run's qualityReport says which checks failed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ingestion"
+                ],
+                "summary": "Publish staged run",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Run ID",
+                        "name": "runId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Who approves the run",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PublishRunRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.PublishRunResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Run not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Run is not awaiting publish",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/ingestion/runs/{runId}/reject": {
+            "post": {
+                "description": "Discards the rows of a staged run without publishing them and marks it rejected. Only runs held for review (stagingStatus staged) can be rejected.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ingestion"
+                ],
+                "summary": "Reject staged run",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Run ID",
+                        "name": "runId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Who rejects the run and why",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RejectRunRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Run rejected",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Run not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Run is not awaiting publish",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/ingestion/runs/{runId}/rerun": {
+            "post": {
+                "description": "Creates a new run that reruns a specific file, chunk, or entry from an existing run",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ingestion"
+                ],
+                "summary": "Rerun ingestion",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Original run ID",
+                        "name": "runId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rerun request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RerunRunRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Rerun created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Run not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/ingestion/stats": {
+            "get": {
+                "description": "Returns aggregated statistics for ingestion runs within a time range (24h/7d/30d buckets, the 7d and 30d ones in calendar days of the service's time zone), including run duration percentiles, files/hour throughput, rows persisted and price changes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ingestion"
+                ],
+                "summary": "Get ingestion stats",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start (RFC3339, or YYYY-MM-DD for the start of that day in the service's time zone)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End (RFC3339, or YYYY-MM-DD for the end of that day in the service's time zone)",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "chain"
+                        ],
+                        "type": "string",
+                        "description": "Group buckets per chain; errors of deleted runs are grouped under the chain \\",
+                        "name": "groupBy",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.GetStatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/ingestion/store-churn": {
+            "get": {
+                "description": "Returns the stores the churn sweeper flagged for being reassigned to another price group at least the configured number of times within its window, most reassigned first, each with its group history over the window. Frequent reassignment usually points at an unstable price hash or inconsistent parsing of the store's files. Flags are cleared once a store settles.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ingestion"
+                ],
+                "summary": "List stores with price group churn",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by chain slug",
+                        "name": "chainSlug",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ListStoreChurnResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/ingestion/usage": {
+            "get": {
+                "description": "Returns bytes downloaded, request counts and wall-clock time per chain per month, aggregated from run metadata",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ingestion"
+                ],
+                "summary": "Get ingestion usage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by chain slug",
+                        "name": "chainSlug",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 24,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 6,
+                        "description": "Number of months to include, counting the current one",
+                        "name": "months",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.GetUsageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/items/resolve": {
+            "post": {
+                "description": "Returns the product linked to each retailer item, by item ID or by chain and external ID, with the product's name and canonical barcodes, so other services read product links here instead of keeping their own copy. Items not linked to a product are returned without one; items that do not exist are listed as missing. At most 1000 item and external IDs per request.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "items"
+                ],
+                "summary": "Resolve items to products",
+                "parameters": [
+                    {
+                        "description": "Items to resolve",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ResolveItemsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ResolveItemsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/items/search": {
+            "get": {
+                "description": "Search for items by name with optional chain, category, brand and discount filters. Requires minimum 3 characters. Matching ignores case and Croatian diacritics and spelling variants, so \"mljeko\", \"mlijeko\" and \"MLIJEKO\" find the same items, and names chains publish in other languages (nameI18n) match too; when nothing matches, items within a few typos of the query are returned with fuzzy set. Facet counts per chain, category, brand and discount state are returned with the results; each facet applies every filter but its own, and at most 20 values are returned per facet, most frequent first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "items"
+                ],
+                "summary": "Search items",
+                "parameters": [
+                    {
+                        "minLength": 3,
+                        "type": "string",
+                        "description": "Search query (min 3 chars)",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by chain slug",
+                        "name": "chainSlug",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by category",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by brand",
+                        "name": "brand",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only items on discount in at least one store (true) or in none (false)",
+                        "name": "onDiscount",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Number of items to return",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SearchItemsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/items/suggest": {
+            "get": {
+                "description": "Returns name and brand completions of a partial query for typeahead, ignoring case and Croatian diacritics and spelling variants, ranked names and brands starting with the query first, then names with a word starting with it, then names containing it, each by how many items carry them. Queries shorter than 3 characters only complete prefixes. Backed by prefix and trigram indexes and an in-memory cache of recent queries; use the search endpoint for full results.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "items"
+                ],
+                "summary": "Suggest items",
+                "parameters": [
+                    {
+                        "maxLength": 100,
+                        "minLength": 2,
+                        "type": "string",
+                        "description": "Partial query (min 2 chars)",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by chain slug",
+                        "name": "chainSlug",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 20,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of suggestions to return",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SuggestItemsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/matching/overview": {
+            "get": {
+                "description": "Summarizes product matching for the review dashboard in one call: unlinked retailer items per chain, review queue depth by status, the daily auto-link rate (automatic links over automatic links plus items queued for review) and the barcodes with the most items flagged as suspicious. All statistics are read in a single database round trip.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matching"
+                ],
+                "summary": "Matching overview",
+                "parameters": [
+                    {
+                        "maximum": 365,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 30,
+                        "description": "Days of auto-link rate to return, ending today",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MatchingOverviewResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/matching/simulate": {
+            "post": {
+                "description": "Runs AI candidate generation and scoring for the given items, or a random sample of the unlinked items the next matching run would pick up, and returns what would be auto-linked, queued for review or left unmatched under the supplied thresholds. Nothing is written: no candidates, links or review queue entries. Items are scored from their cached embeddings, so items without one for the model are reported as not_embedded instead of being embedded. Use it to tune thresholds before a production matching run.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "matching"
+                ],
+                "summary": "Simulate AI matching",
+                "parameters": [
+                    {
+                        "description": "Items and thresholds to simulate",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SimulateMatchingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SimulateMatchingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "No product embeddings to match against",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/prices/{chainSlug}/discounts": {
+            "get": {
+                "description": "Returns the items of a chain on discount right now, from the price cache, with the deepest discount across price groups, its validity window and how many stores have the item on discount. Discounts whose window has not started or has ended are left out. Sorted by discount percent, deepest first unless sort=asc.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "List current discounts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain slug identifier",
+                        "name": "chainSlug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 0,
+                        "type": "number",
+                        "default": 0,
+                        "description": "Minimum discount percent",
+                        "name": "minPercent",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only items in this category",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "desc",
+                            "asc"
+                        ],
+                        "type": "string",
+                        "default": "desc",
+                        "description": "Sort order by discount percent",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 500,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Number of items to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.GetChainDiscountsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Chain not cached",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Cache not initialized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/prices/{chainSlug}/{storeId}": {
+            "get": {
+                "description": "Returns paginated prices for a specific store in a chain. Identical concurrent requests share one computed response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Get store prices",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain slug identifier",
+                        "name": "chainSlug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store ID",
+                        "name": "storeId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "maximum": 500,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Number of items to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Add display strings of the amounts, e.g. 1,99 €, and the discount percentage",
+                        "name": "includeFormatted",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.GetStorePricesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/prices/{chainSlug}/{storeId}/checksum": {
+            "get": {
+                "description": "Returns a hash of a store's current price set in the price cache, with its item count and when the chain's prices were loaded. The hash changes only when the store's prices, discounts or assortment do, so clients holding a price list can compare it before downloading the full list again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Get store price checksum",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain slug identifier",
+                        "name": "chainSlug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store ID",
+                        "name": "storeId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.StorePriceChecksumResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Chain not cached or store not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Cache not initialized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/prices/{chainSlug}/{storeId}/delta": {
+            "get": {
+                "description": "Returns the current prices of a store's items that changed since a version, from price group transitions and exceptions created or expired since, and the items it no longer prices. Pass the returned version as since on the next sync. Without since, or when since is older than 7 days or predates the store's price group, every price is returned with full set and the client should replace its list.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Get store price delta",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain slug identifier",
+                        "name": "chainSlug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store ID",
+                        "name": "storeId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Version returned by the previous delta",
+                        "name": "since",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.GetStorePriceDeltaResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Store not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/prices/{chainSlug}/{storeId}/{itemId}/history": {
+            "get": {
+                "description": "Returns an item's daily price series at a store between from and to, inclusive. A point is recorded for the day the item was first seen at the store and for every day its price or discount changed, holding the day's last price; the price stays the same until the next point. The first point may predate from: it is the price in effect at from. At most 730 days per request.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Get item price history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain slug identifier",
+                        "name": "chainSlug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Store ID",
+                        "name": "storeId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Retailer item ID",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day, YYYY-MM-DD (default 90 days before to)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day, YYYY-MM-DD (default today)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.GetPriceHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Store not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/slo": {
+            "get": {
+                "description": "Returns each configured service level objective with its rolling compliance, remaining error budget and budget burn rates over 5 minutes, 1 hour and 6 hours. A burn rate of 1 spends the budget exactly over the compliance window; fastBurn is set while both the 5 minute and 1 hour rates are at or above fastBurnRate, which is when webhook alerts fire.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Get SLO status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SLOStatusResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "SLO tracking not configured",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "chainsettings.Overrides": {
+            "type": "object",
+            "properties": {
+                "csvDelimiter": {
+                    "type": "string"
+                },
+                "csvEncoding": {
+                    "type": "string"
+                },
+                "csvHasHeader": {
+                    "type": "boolean"
+                },
+                "discoveryEnabled": {
+                    "type": "boolean"
+                },
+                "maxRetries": {
+                    "type": "integer"
+                },
+                "requestsPerSecond": {
+                    "type": "integer"
+                }
+            }
+        },
+        "csv.CsvColumnMapping": {
+            "type": "object",
+            "properties": {
+                "anchorPrice": {
+                    "type": "string"
+                },
+                "anchorPriceAsOf": {
+                    "type": "string"
+                },
+                "barcodes": {
+                    "type": "string"
+                },
+                "brand": {
+                    "type": "string"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "discountEnd": {
+                    "type": "string"
+                },
+                "discountPrice": {
+                    "type": "string"
+                },
+                "discountStart": {
+                    "type": "string"
+                },
+                "externalId": {
+                    "type": "string"
+                },
+                "imageUrl": {
+                    "type": "string"
+                },
+                "lowestPrice30d": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "nameEn": {
+                    "description": "Secondary names, in the language of the field suffix",
+                    "type": "string"
+                },
+                "nameIt": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "string"
+                },
+                "storeIdentifier": {
+                    "type": "string"
+                },
+                "subcategory": {
+                    "type": "string"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "unitPrice": {
+                    "type": "string"
+                },
+                "unitPriceBaseQuantity": {
+                    "type": "string"
+                },
+                "unitPriceBaseUnit": {
+                    "type": "string"
+                },
+                "unitQuantity": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.Chain": {
+            "type": "object",
+            "properties": {
+                "brand_color": {
+                    "description": "Optional hex color, e.g. '#e30613'",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "description": "Optional presentation name, falls back to Name",
+                    "type": "string"
+                },
+                "enabled": {
+                    "description": "Disabled chains are rejected by ingestion",
+                    "type": "boolean"
+                },
+                "logo_url": {
+                    "description": "Optional logo URL",
+                    "type": "string"
+                },
+                "metadata": {
+                    "description": "Free-form JSON metadata",
+                    "type": "object"
+                },
+                "name": {
+                    "description": "Human-readable name",
+                    "type": "string"
+                },
+                "slug": {
+                    "description": "konzum, lidl, plodine, etc.",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "website": {
+                    "description": "Optional website URL",
+                    "type": "string"
+                }
+            }
+        },
+        "database.ChainSettings": {
+            "type": "object",
+            "properties": {
+                "chainSlug": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "csvDelimiter": {
+                    "type": "string"
+                },
+                "csvEncoding": {
+                    "type": "string"
+                },
+                "csvHasHeader": {
+                    "type": "boolean"
+                },
+                "discoveryEnabled": {
+                    "type": "boolean"
+                },
+                "maxRetries": {
+                    "type": "integer"
+                },
+                "requestsPerSecond": {
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.ColumnMappingOverride": {
+            "type": "object",
+            "properties": {
+                "chainSlug": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "mapping": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.FeatureFlag": {
+            "type": "object",
+            "properties": {
+                "chains": {
+                    "description": "Empty means all chains",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.IngestionSchedule": {
+            "type": "object",
+            "properties": {
+                "chainSlug": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "cronExpression": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "lastError": {
+                    "type": "string"
+                },
+                "lastRunId": {
+                    "type": "string"
+                },
+                "lastStatus": {
+                    "type": "string"
+                },
+                "lastTriggeredAt": {
+                    "type": "string"
+                },
+                "nextRunAt": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.OptimizationResult": {
+            "type": "object",
+            "properties": {
+                "chainSlug": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "description": "opt_{cuid2}",
+                    "type": "string"
+                },
+                "kind": {
+                    "description": "'single' or 'multi'",
+                    "type": "string"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "request": {
+                    "type": "object"
+                },
+                "result": {
+                    "type": "object"
+                },
+                "userRef": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.PriceChangeRecord": {
+            "type": "object",
+            "properties": {
+                "chainSlug": {
+                    "type": "string"
+                },
+                "changedAt": {
+                    "type": "string"
+                },
+                "cursor": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "discountEnd": {
+                    "type": "string"
+                },
+                "discountStart": {
+                    "type": "string"
+                },
+                "fileId": {
+                    "type": "string"
+                },
+                "newDiscountPrice": {
+                    "type": "integer"
+                },
+                "newPrice": {
+                    "type": "integer"
+                },
+                "oldDiscountPrice": {
+                    "type": "integer"
+                },
+                "oldPrice": {
+                    "type": "integer"
+                },
+                "retailerItemId": {
+                    "type": "string"
+                },
+                "runId": {
+                    "type": "string"
+                },
+                "storeId": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.Webhook": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "eventTypes": {
+                    "description": "Empty subscribes to every event",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "aggregateId": {
+                    "type": "string"
+                },
+                "attempts": {
+                    "type": "integer"
+                },
+                "availableAt": {
+                    "description": "Next attempt while pending",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deliveredAt": {
+                    "type": "string"
+                },
+                "eventType": {
+                    "type": "string"
+                },
+                "failedAt": {
+                    "description": "Set when the attempts ran out",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastError": {
+                    "type": "string"
+                },
+                "lastStatus": {
+                    "type": "integer"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "webhookId": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.AutoLinkDay": {
+            "type": "object",
+            "properties": {
+                "autoLinked": {
+                    "type": "integer"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "queued": {
+                    "type": "integer"
+                },
+                "rate": {
+                    "type": "number"
+                }
+            }
+        },
+        "handlers.BasketAvailabilityRequest": {
+            "type": "object",
+            "required": [
+                "basketItems",
+                "chainSlug",
+                "storeId"
+            ],
+            "properties": {
+                "basketItems": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/handlers.BasketItem"
+                    }
+                },
+                "chainSlug": {
+                    "type": "string"
+                },
+                "storeId": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.BasketAvailabilityResponse": {
+            "type": "object",
+            "properties": {
+                "chainSlug": {
+                    "type": "string"
+                },
+                "coverageRatio": {
+                    "type": "number"
+                },
+                "formattedTotal": {
+                    "description": "FormattedTotal is Total for display, with ?includeFormatted=true",
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.ItemPriceInfo"
+                    }
+                },
+                "missingItems": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.UnavailableItem"
+                    }
+                },
+                "staleness": {
+                    "$ref": "#/definitions/handlers.Staleness"
+                },
+                "storeId": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.BasketItem": {
+            "type": "object",
+            "required": [
+                "itemId",
+                "name",
+                "quantity"
+            ],
+            "properties": {
+                "itemId": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "handlers.ChainDiscount": {
+            "type": "object",
+            "properties": {
+                "brand": {
+                    "type": "string"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "discountEnd": {
+                    "type": "string"
+                },
+                "discountPercent": {
+                    "type": "number"
+                },
+                "discountPrice": {
+                    "type": "integer"
+                },
+                "discountStart": {
+                    "type": "string"
+                },
+                "itemName": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "retailerItemId": {
+                    "type": "string"
+                },
+                "storeCount": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.ChainMetadata": {
+            "type": "object",
+            "properties": {
+                "brandColor": {
+                    "type": "string"
+                },
+                "displayName": {
+                    "type": "string"
+                },
+                "logoUrl": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                },
+                "website": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ChainSettingsResponse": {
+            "type": "object",
+            "properties": {
+                "chainSlug": {
+                    "type": "string"
+                },
+                "configDefaults": {
+                    "description": "From the chains section of the config file",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/chainsettings.Overrides"
+                        }
+                    ]
+                },
+                "effective": {
+                    "description": "Used for ingestion; unset fields keep the adapter's values",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/chainsettings.Overrides"
+                        }
+                    ]
+                },
+                "overrides": {
+                    "description": "Stored in the database",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.ChainSettings"
+                        }
+                    ]
+                }
+            }
+        },
+        "handlers.ChainStats": {
+            "type": "object",
+            "properties": {
+                "buckets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.StatsBucket"
+                    }
+                },
+                "chainSlug": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ClearSuspectRunRequest": {
+            "type": "object",
+            "required": [
+                "clearedBy"
+            ],
+            "properties": {
+                "clearedBy": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ColumnMappingResponse": {
+            "type": "object",
+            "properties": {
+                "chainSlug": {
+                    "type": "string"
+                },
+                "compiled": {
+                    "$ref": "#/definitions/csv.CsvColumnMapping"
+                },
+                "effective": {
+                    "description": "Mapping used for ingestion",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/csv.CsvColumnMapping"
+                        }
+                    ]
+                },
+                "fields": {
+                    "description": "Field names an override may set",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "override": {
+                    "$ref": "#/definitions/database.ColumnMappingOverride"
+                }
+            }
+        },
+        "handlers.ConflictingBarcode": {
+            "type": "object",
+            "properties": {
+                "barcode": {
+                    "type": "string"
+                },
+                "chains": {
+                    "type": "integer"
+                },
+                "flags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "items": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.CreateChainRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "slug"
+            ],
+            "properties": {
+                "brandColor": {
+                    "type": "string"
+                },
+                "displayName": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "logoUrl": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "object"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                },
+                "website": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.CreateWebhookResponse": {
+            "type": "object",
+            "properties": {
+                "secret": {
+                    "type": "string"
+                },
+                "webhook": {
+                    "$ref": "#/definitions/database.Webhook"
+                }
+            }
+        },
+        "handlers.DeleteUserDataResponse": {
+            "type": "object",
+            "properties": {
+                "deletedOptimizationResults": {
+                    "type": "integer"
+                },
+                "userRef": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.DiscountFacet": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "integer"
+                },
+                "inactive": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.EvaluateFeatureFlagsResponse": {
+            "type": "object",
+            "properties": {
+                "flags": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "boolean"
+                    }
+                }
+            }
+        },
+        "handlers.FacetCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.FormattedItemPrice": {
+            "type": "object",
+            "properties": {
+                "basePrice": {
+                    "type": "string"
+                },
+                "discountPercent": {
+                    "description": "Discount off the base price, e.g. \"-25%\"",
+                    "type": "string"
+                },
+                "discountPrice": {
+                    "type": "string"
+                },
+                "effectivePrice": {
+                    "type": "string"
+                },
+                "lineTotal": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.FormattedStorePrice": {
+            "type": "object",
+            "properties": {
+                "anchorPrice": {
+                    "type": "string"
+                },
+                "currentPrice": {
+                    "type": "string"
+                },
+                "discountPercent": {
+                    "description": "Discount off the current price, e.g. \"-25%\"",
+                    "type": "string"
+                },
+                "discountPrice": {
+                    "type": "string"
+                },
+                "lowestPrice30d": {
+                    "type": "string"
+                },
+                "previousPrice": {
+                    "type": "string"
+                },
+                "unitPrice": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.GetAnchorPriceComplianceResponse": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "reports": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/jobs.AnchorComplianceStats"
+                    }
+                }
+            }
+        },
+        "handlers.GetChainDiscountsResponse": {
+            "type": "object",
+            "properties": {
+                "discounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.ChainDiscount"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.GetParseProfilesResponse": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "profiles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/jobs.ParseProfileStats"
+                    }
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.GetPriceHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "chainSlug": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.PriceHistoryPoint"
+                    }
+                },
+                "retailerItemId": {
+                    "type": "string"
+                },
+                "storeId": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.GetStatsResponse": {
+            "type": "object",
+            "properties": {
+                "buckets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.StatsBucket"
+                    }
+                },
+                "chains": {
+                    "description": "Populated when groupBy=chain",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.ChainStats"
+                    }
+                }
+            }
+        },
+        "handlers.GetStorePriceDeltaResponse": {
+            "type": "object",
+            "properties": {
+                "chainSlug": {
+                    "type": "string"
+                },
+                "changed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.StorePriceChange"
+                    }
+                },
+                "full": {
+                    "type": "boolean"
+                },
+                "removed": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "storeId": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.GetStorePricesResponse": {
+            "type": "object",
+            "properties": {
+                "prices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.StorePrice"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.GetUsageResponse": {
+            "type": "object",
+            "properties": {
+                "usage": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.MonthlyUsage"
+                    }
+                }
+            }
+        },
+        "handlers.IngestChainsRequest": {
+            "type": "object",
+            "required": [
+                "chains"
+            ],
+            "properties": {
+                "chains": {
+                    "description": "Chain slugs, or [\"all\"]",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "concurrency": {
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 1
+                },
+                "targetDate": {
+                    "description": "YYYY-MM-DD format",
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.IngestChainsStartedResponse": {
+            "type": "object",
+            "properties": {
+                "batchId": {
+                    "type": "string"
+                },
+                "chains": {
+                    "description": "In start order",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "pollUrl": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.IngestionBatchProgress": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "integer"
+                },
+                "entriesPersisted": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "description": "Failed or interrupted",
+                    "type": "integer"
+                },
+                "filesProcessed": {
+                    "type": "integer"
+                },
+                "pending": {
+                    "type": "integer"
+                },
+                "percent": {
+                    "type": "number"
+                },
+                "running": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.IngestionBatchResponse": {
+            "type": "object",
+            "properties": {
+                "batchId": {
+                    "type": "string"
+                },
+                "completedAt": {
+                    "type": "string"
+                },
+                "concurrency": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "progress": {
+                    "$ref": "#/definitions/handlers.IngestionBatchProgress"
+                },
+                "runs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.IngestionBatchRunInfo"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                },
+                "targetDate": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.IngestionBatchRunInfo": {
+            "type": "object",
+            "properties": {
+                "chainSlug": {
+                    "type": "string"
+                },
+                "completedAt": {
+                    "type": "string"
+                },
+                "entriesPersisted": {
+                    "type": "integer"
+                },
+                "filesProcessed": {
+                    "type": "integer"
+                },
+                "runId": {
+                    "type": "string"
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.IngestionError": {
+            "type": "object",
+            "properties": {
+                "chunkId": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "entryId": {
+                    "type": "string"
+                },
+                "errorDetails": {
+                    "type": "string"
+                },
+                "errorMessage": {
+                    "type": "string"
+                },
+                "errorType": {
+                    "type": "string"
+                },
+                "fileId": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "runId": {
+                    "type": "string"
+                },
+                "severity": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.IngestionFile": {
+            "type": "object",
+            "properties": {
+                "chunkSize": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "discrepancyReason": {
+                    "type": "string"
+                },
+                "entryCount": {
+                    "type": "integer"
+                },
+                "fileHash": {
+                    "type": "string"
+                },
+                "fileSize": {
+                    "type": "integer"
+                },
+                "fileType": {
+                    "type": "string"
+                },
+                "filename": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "string"
+                },
+                "parserVersion": {
+                    "type": "string"
+                },
+                "processedAt": {
+                    "type": "string"
+                },
+                "processedChunks": {
+                    "type": "integer"
+                },
+                "runId": {
+                    "type": "string"
+                },
+                "stageDurationsMs": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                },
+                "statusTimestamps": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "totalChunks": {
+                    "type": "integer"
+                },
+                "warningCount": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.IngestionRun": {
+            "type": "object",
+            "properties": {
+                "averagePrice": {
+                    "type": "number"
+                },
+                "baselineReport": {
+                    "type": "string"
+                },
+                "baselineStatus": {
+                    "type": "string"
+                },
+                "chainSlug": {
+                    "type": "string"
+                },
+                "childRunIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "completedAt": {
+                    "type": "string"
+                },
+                "completionPercent": {
+                    "type": "number"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "errorCount": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "string"
+                },
+                "parentRunId": {
+                    "type": "string"
+                },
+                "processedEntries": {
+                    "type": "integer"
+                },
+                "processedFiles": {
+                    "type": "integer"
+                },
+                "qualityReport": {
+                    "type": "string"
+                },
+                "rerunTargetId": {
+                    "type": "string"
+                },
+                "rerunType": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "stagingStatus": {
+                    "type": "string"
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "totalEntries": {
+                    "type": "integer"
+                },
+                "totalFiles": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.ItemExternalRef": {
+            "type": "object",
+            "required": [
+                "chainSlug",
+                "externalId"
+            ],
+            "properties": {
+                "chainSlug": {
+                    "type": "string"
+                },
+                "externalId": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ItemPriceInfo": {
+            "type": "object",
+            "properties": {
+                "absoluteSavings": {
+                    "description": "AbsoluteSavings is the discount off the regular price in cents, omitted\nwithout a discount",
+                    "type": "integer"
+                },
+                "basePrice": {
+                    "type": "integer"
+                },
+                "discountPercent": {
+                    "description": "DiscountPercent is the discount off the regular price in whole percent,\nomitted without a discount",
+                    "type": "integer"
+                },
+                "discountPrice": {
+                    "type": "integer"
+                },
+                "effectivePrice": {
+                    "type": "integer"
+                },
+                "formatted": {
+                    "description": "Formatted holds display strings of the amounts, with ?includeFormatted=true",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handlers.FormattedItemPrice"
+                        }
+                    ]
+                },
+                "hasDiscount": {
+                    "type": "boolean"
+                },
+                "isBelowAnchorPrice": {
+                    "description": "IsBelowAnchorPrice reports whether the price paid, discounted or not,\nis below the anchor price; omitted without an anchor price",
+                    "type": "boolean"
+                },
+                "itemId": {
+                    "type": "string"
+                },
+                "itemName": {
+                    "type": "string"
+                },
+                "lineTotal": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.ListAdminChainsResponse": {
+            "type": "object",
+            "properties": {
+                "chains": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Chain"
+                    }
+                }
+            }
+        },
+        "handlers.ListChainMetadataResponse": {
+            "type": "object",
+            "properties": {
+                "chains": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.ChainMetadata"
+                    }
+                }
+            }
+        },
+        "handlers.ListChainSettingsResponse": {
+            "type": "object",
+            "properties": {
+                "settings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.ChainSettings"
+                    }
+                }
+            }
+        },
+        "handlers.ListErrorsResponse": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.IngestionError"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.ListFeatureFlagsResponse": {
+            "type": "object",
+            "properties": {
+                "flags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.FeatureFlag"
+                    }
+                }
+            }
+        },
+        "handlers.ListFilesResponse": {
+            "type": "object",
+            "properties": {
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.IngestionFile"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.ListRunsResponse": {
+            "type": "object",
+            "properties": {
+                "runs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.IngestionRun"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.ListSchedulesResponse": {
+            "type": "object",
+            "properties": {
+                "schedules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.IngestionSchedule"
+                    }
+                },
+                "timezone": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ListStoreChurnResponse": {
+            "type": "object",
+            "properties": {
+                "stores": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/sweepers.StoreChurn"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.ListUnmatchedLocatorStoresResponse": {
+            "type": "object",
+            "properties": {
+                "stores": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/storelocator.UnmatchedStore"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.ListWebhookDeliveriesResponse": {
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.WebhookDelivery"
+                    }
+                }
+            }
+        },
+        "handlers.ListWebhooksResponse": {
+            "type": "object",
+            "properties": {
+                "eventTypes": {
+                    "description": "Events webhooks can subscribe to",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "webhooks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Webhook"
+                    }
+                }
+            }
+        },
+        "handlers.Location": {
+            "type": "object",
+            "required": [
+                "latitude",
+                "longitude"
+            ],
+            "properties": {
+                "latitude": {
+                    "type": "number",
+                    "maximum": 90,
+                    "minimum": -90
+                },
+                "longitude": {
+                    "type": "number",
+                    "maximum": 180,
+                    "minimum": -180
+                }
+            }
+        },
+        "handlers.LogLevelsResponse": {
+            "type": "object",
+            "properties": {
+                "components": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "default": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.MatchingOverviewResponse": {
+            "type": "object",
+            "properties": {
+                "autoLinkRate": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.AutoLinkDay"
+                    }
+                },
+                "conflictingBarcodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.ConflictingBarcode"
+                    }
+                },
+                "generatedAt": {
+                    "type": "string"
+                },
+                "queue": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.QueueStatusCount"
+                    }
+                },
+                "unlinkedByChain": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.UnlinkedChainCount"
+                    }
+                }
+            }
+        },
+        "handlers.MergeProductsRequest": {
+            "type": "object",
+            "required": [
+                "mergedBy",
+                "productIds"
+            ],
+            "properties": {
+                "mergedBy": {
+                    "type": "string"
+                },
+                "productIds": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.MergeProductsResponse": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "integer"
+                },
+                "barcodes": {
+                    "type": "integer"
+                },
+                "mergedIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "productId": {
+                    "type": "string"
+                },
+                "retailerItemIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handlers.MissingItem": {
+            "type": "object",
+            "properties": {
+                "isOptional": {
+                    "type": "boolean"
+                },
+                "itemId": {
+                    "type": "string"
+                },
+                "itemName": {
+                    "type": "string"
+                },
+                "penalty": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "description": "Reason says why the item is missing, in the request's language",
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.MonthlyUsage": {
+            "type": "object",
+            "properties": {
+                "bytesDownloaded": {
+                    "type": "integer"
+                },
+                "chainSlug": {
+                    "type": "string"
+                },
+                "month": {
+                    "description": "\"2006-01\"",
+                    "type": "string"
+                },
+                "requests": {
+                    "type": "integer"
+                },
+                "runs": {
+                    "type": "integer"
+                },
+                "wallClockSeconds": {
+                    "type": "number"
+                }
+            }
+        },
+        "handlers.MultiStoreResult": {
+            "type": "object",
+            "properties": {
+                "algorithmUsed": {
+                    "type": "string"
+                },
+                "combinedTotal": {
+                    "type": "integer"
+                },
+                "coverageRatio": {
+                    "type": "number"
+                },
+                "formattedCombinedTotal": {
+                    "description": "FormattedCombinedTotal is CombinedTotal for display, with\n?includeFormatted=true",
+                    "type": "string"
+                },
+                "penaltyStrategy": {
+                    "type": "string"
+                },
+                "staleness": {
+                    "$ref": "#/definitions/handlers.Staleness"
+                },
+                "stores": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.StoreAllocation"
+                    }
+                },
+                "travelCost": {
+                    "description": "TravelCost is the cost of the trip the stores were chosen with",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handlers.TravelCost"
+                        }
+                    ]
+                },
+                "travelDistanceKm": {
+                    "description": "TravelDistanceKm is the round trip from the location through the\nstores in visitOrder and back, omitted without a location",
+                    "type": "number"
+                },
+                "unassignedItems": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.MissingItem"
+                    }
+                }
+            }
+        },
+        "handlers.OptimizeRequest": {
+            "type": "object",
+            "required": [
+                "basketItems"
+            ],
+            "properties": {
+                "basketItems": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/handlers.BasketItem"
+                    }
+                },
+                "chainSlug": {
+                    "type": "string"
+                },
+                "chainSlugs": {
+                    "description": "ChainSlugs combines stores of several chains (multi-store only); [\"all\"]\nmeans every chain with loaded prices. Items are matched across chains\nthrough the products they are linked to.",
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "limit": {
+                    "type": "integer",
+                    "maximum": 50,
+                    "minimum": 1
+                },
+                "location": {
+                    "$ref": "#/definitions/handlers.Location"
+                },
+                "maxDistance": {
+                    "type": "number"
+                },
+                "maxStores": {
+                    "type": "integer"
+                },
+                "minCoverage": {
+                    "description": "MinCoverage leaves out stores with a lower coverage ratio, Offset and\nLimit page through the rest (single-store only). Limit defaults to 50.",
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
+                },
+                "offset": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "penaltyStrategy": {
+                    "description": "PenaltyStrategy overrides the configured missing-item penalty strategy",
+                    "type": "string",
+                    "enum": [
+                        "mean",
+                        "simple_mean",
+                        "median",
+                        "max",
+                        "category_average",
+                        "category_table"
+                    ]
+                },
+                "sortBy": {
+                    "description": "SortBy ranks stores within a coverage bin by price (the default),\ndistance or both (single-store only); distance and balanced need a\nlocation",
+                    "type": "string",
+                    "enum": [
+                        "price",
+                        "distance",
+                        "balanced"
+                    ]
+                },
+                "userRef": {
+                    "description": "UserRef is an opaque caller-side user reference; when set the result is\nstored (with rounded coordinates) and can be exported or deleted by it",
+                    "type": "string",
+                    "maxLength": 200
+                }
+            }
+        },
+        "handlers.OptimizerSettings": {
+            "type": "object",
+            "properties": {
+                "balancedDistanceCostPerKm": {
+                    "type": "integer"
+                },
+                "coverageBins": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "extraStoreCost": {
+                    "type": "integer"
+                },
+                "maxBasketItems": {
+                    "type": "integer"
+                },
+                "maxCandidates": {
+                    "type": "integer"
+                },
+                "maxDistanceKm": {
+                    "type": "number"
+                },
+                "minBasketItems": {
+                    "type": "integer"
+                },
+                "missingItemCategoryPenalties": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "missingItemFallback": {
+                    "type": "integer"
+                },
+                "missingItemPenaltyMult": {
+                    "type": "number"
+                },
+                "missingItemPenaltyStrategy": {
+                    "type": "string"
+                },
+                "optimalTimeoutMs": {
+                    "type": "integer"
+                },
+                "topCheapestStores": {
+                    "type": "integer"
+                },
+                "topNearestStores": {
+                    "type": "integer"
+                },
+                "travelCostPerKm": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.OptimizerVersionInfo": {
+            "type": "object",
+            "properties": {
+                "activatedAt": {
+                    "type": "string"
+                },
+                "builtAt": {
+                    "type": "string"
+                },
+                "canary": {
+                    "description": "Absent for the version built at startup",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/optimizer.CanaryReport"
+                        }
+                    ]
+                },
+                "settings": {
+                    "$ref": "#/definitions/handlers.OptimizerSettings"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.OptimizerVersionsResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "$ref": "#/definitions/handlers.OptimizerVersionInfo"
+                },
+                "building": {
+                    "description": "Version being built and validated",
+                    "type": "integer"
+                },
+                "lastFailure": {
+                    "description": "Last version rejected by its canary run",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/optimizer.FailedBuild"
+                        }
+                    ]
+                },
+                "previous": {
+                    "description": "Target of a rollback",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handlers.OptimizerVersionInfo"
+                        }
+                    ]
+                }
+            }
+        },
+        "handlers.PriceHistoryPoint": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "discountPrice": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "unitPrice": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.PublishRunRequest": {
+            "type": "object",
+            "required": [
+                "approvedBy"
+            ],
+            "properties": {
+                "approvedBy": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.PublishRunResponse": {
+            "type": "object",
+            "properties": {
+                "files": {
+                    "type": "integer"
+                },
+                "persisted": {
+                    "type": "integer"
+                },
+                "priceChanges": {
+                    "type": "integer"
+                },
+                "runId": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.QueueStatusCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.RejectRunRequest": {
+            "type": "object",
+            "required": [
+                "reason",
+                "rejectedBy"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string"
+                },
+                "rejectedBy": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.RerunRunRequest": {
+            "type": "object",
+            "required": [
+                "rerunType",
+                "targetId"
+            ],
+            "properties": {
+                "rerunType": {
+                    "description": "\"file\", \"chunk\", \"entry\"",
+                    "type": "string"
+                },
+                "targetId": {
+                    "description": "ID of file/chunk/entry to rerun",
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ResolveItemsRequest": {
+            "type": "object",
+            "properties": {
+                "externalIds": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.ItemExternalRef"
+                    }
+                },
+                "itemIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handlers.ResolveItemsResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.ResolvedItem"
+                    }
+                },
+                "missingExternalIds": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.ItemExternalRef"
+                    }
+                },
+                "missingItemIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handlers.ResolvedItem": {
+            "type": "object",
+            "properties": {
+                "barcodes": {
+                    "description": "The product's canonical barcodes",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "chainSlug": {
+                    "type": "string"
+                },
+                "externalId": {
+                    "type": "string"
+                },
+                "productId": {
+                    "description": "Null when the item is not linked to a product",
+                    "type": "string"
+                },
+                "productName": {
+                    "type": "string"
+                },
+                "retailerItemId": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.RunLineageNode": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.RunLineageNode"
+                    }
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "parentRunId": {
+                    "type": "string"
+                },
+                "rerunTargetId": {
+                    "type": "string"
+                },
+                "rerunType": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.RunLineageResponse": {
+            "type": "object",
+            "properties": {
+                "rootRunId": {
+                    "type": "string"
+                },
+                "runId": {
+                    "type": "string"
+                },
+                "tree": {
+                    "$ref": "#/definitions/handlers.RunLineageNode"
+                }
+            }
+        },
+        "handlers.SLOObjectiveStatus": {
+            "type": "object",
+            "properties": {
+                "burnRates": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "compliance": {
+                    "type": "number"
+                },
+                "errorBudgetRemaining": {
+                    "type": "number"
+                },
+                "fastBurn": {
+                    "type": "boolean"
+                },
+                "good": {
+                    "type": "integer"
+                },
+                "latencyMs": {
+                    "type": "integer"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "requests": {
+                    "type": "integer"
+                },
+                "route": {
+                    "type": "string"
+                },
+                "target": {
+                    "type": "number"
+                },
+                "windowSeconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.SLOStatusResponse": {
+            "type": "object",
+            "properties": {
+                "fastBurnRate": {
+                    "type": "number"
+                },
+                "objectives": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.SLOObjectiveStatus"
+                    }
+                }
+            }
+        },
+        "handlers.SearchFacets": {
+            "type": "object",
+            "properties": {
+                "brands": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.FacetCount"
+                    }
+                },
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.FacetCount"
+                    }
+                },
+                "chains": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.FacetCount"
+                    }
+                },
+                "discount": {
+                    "$ref": "#/definitions/handlers.DiscountFacet"
+                }
+            }
+        },
+        "handlers.SearchItem": {
+            "type": "object",
+            "properties": {
+                "absoluteSavings": {
+                    "description": "AbsoluteSavings is the discount off the regular price in cents, omitted\nwithout a discount",
+                    "type": "integer"
+                },
+                "avgPrice": {
+                    "description": "Average price across stores",
+                    "type": "integer"
+                },
+                "brand": {
+                    "type": "string"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "chainSlug": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "discountPercent": {
+                    "description": "DiscountPercent is the discount off the regular price in whole percent,\nomitted without a discount",
+                    "type": "integer"
+                },
+                "externalId": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "imageUrl": {
+                    "type": "string"
+                },
+                "isBelowAnchorPrice": {
+                    "description": "IsBelowAnchorPrice reports whether the price paid, discounted or not,\nis below the anchor price; omitted without an anchor price",
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "nameI18n": {
+                    "description": "Names in other languages by ISO 639-1 code",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "storeCount": {
+                    "description": "Number of stores with this item",
+                    "type": "integer"
+                },
+                "subcategory": {
+                    "type": "string"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "unitQuantity": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.SearchItemsResponse": {
+            "type": "object",
+            "properties": {
+                "facets": {
+                    "$ref": "#/definitions/handlers.SearchFacets"
+                },
+                "fuzzy": {
+                    "description": "Fuzzy is set when nothing matched the query as typed and the items are\nthose within a few typos of it",
+                    "type": "boolean"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.SearchItem"
+                    }
+                },
+                "query": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.SetChainSettingsRequest": {
+            "type": "object",
+            "properties": {
+                "csvDelimiter": {
+                    "description": "\",\", \";\" or a tab",
+                    "type": "string"
+                },
+                "csvEncoding": {
+                    "description": "utf-8, windows-1250 or iso-8859-2",
+                    "type": "string"
+                },
+                "csvHasHeader": {
+                    "type": "boolean"
+                },
+                "discoveryEnabled": {
+                    "type": "boolean"
+                },
+                "maxRetries": {
+                    "type": "integer"
+                },
+                "requestsPerSecond": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.SetColumnMappingRequest": {
+            "type": "object",
+            "required": [
+                "mapping"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "mapping": {
+                    "description": "Field name -\u003e header name or column index; \"\" unmaps an optional field",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handlers.SetFeatureFlagRequest": {
+            "type": "object",
+            "properties": {
+                "chains": {
+                    "description": "Limit the flag to these chains; empty means all",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "description": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "handlers.SetLogLevelsRequest": {
+            "type": "object",
+            "properties": {
+                "components": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "default": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.SetOptimizerConfigRequest": {
+            "type": "object",
+            "properties": {
+                "balancedDistanceCostPerKm": {
+                    "type": "integer"
+                },
+                "coverageBins": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "extraStoreCost": {
+                    "type": "integer"
+                },
+                "maxBasketItems": {
+                    "type": "integer"
+                },
+                "maxCandidates": {
+                    "type": "integer"
+                },
+                "maxDistanceKm": {
+                    "type": "number"
+                },
+                "minBasketItems": {
+                    "type": "integer"
+                },
+                "missingItemCategoryPenalties": {
+                    "description": "Replaces the whole table",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "missingItemFallback": {
+                    "type": "integer"
+                },
+                "missingItemPenaltyMult": {
+                    "type": "number"
+                },
+                "missingItemPenaltyStrategy": {
+                    "type": "string"
+                },
+                "optimalTimeoutMs": {
+                    "type": "integer"
+                },
+                "topCheapestStores": {
+                    "type": "integer"
+                },
+                "topNearestStores": {
+                    "type": "integer"
+                },
+                "travelCostPerKm": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.SetOptimizerConfigResponse": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.SetScheduleRequest": {
+            "type": "object",
+            "required": [
+                "cronExpression"
+            ],
+            "properties": {
+                "cronExpression": {
+                    "description": "e.g. \"30 6 * * *\" or \"@daily\"",
+                    "type": "string"
+                },
+                "enabled": {
+                    "description": "Default true",
+                    "type": "boolean"
+                }
+            }
+        },
+        "handlers.SimulateMatchingRequest": {
+            "type": "object",
+            "properties": {
+                "autoLinkThreshold": {
+                    "type": "number",
+                    "maximum": 1
+                },
+                "itemIds": {
+                    "type": "array",
+                    "maxItems": 500,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "modelVersion": {
+                    "type": "string"
+                },
+                "reviewThreshold": {
+                    "type": "number",
+                    "maximum": 1
+                },
+                "sampleSize": {
+                    "type": "integer",
+                    "maximum": 500,
+                    "minimum": 1
+                }
+            }
+        },
+        "handlers.SimulateMatchingResponse": {
+            "type": "object",
+            "properties": {
+                "autoLinkThreshold": {
+                    "type": "number"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.SimulatedItem"
+                    }
+                },
+                "modelVersion": {
+                    "type": "string"
+                },
+                "reviewThreshold": {
+                    "type": "number"
+                },
+                "summary": {
+                    "$ref": "#/definitions/handlers.SimulationSummary"
+                }
+            }
+        },
+        "handlers.SimulatedCandidate": {
+            "type": "object",
+            "properties": {
+                "brand": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "productId": {
+                    "type": "string"
+                },
+                "similarity": {
+                    "type": "number"
+                }
+            }
+        },
+        "handlers.SimulatedItem": {
+            "type": "object",
+            "properties": {
+                "candidates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.SimulatedCandidate"
+                    }
+                },
+                "chainSlug": {
+                    "type": "string"
+                },
+                "decision": {
+                    "type": "string"
+                },
+                "itemId": {
+                    "type": "string"
+                },
+                "linkedProductId": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.SimulationSummary": {
+            "type": "object",
+            "properties": {
+                "autoLinked": {
+                    "type": "integer"
+                },
+                "autoLinkedElsewhere": {
+                    "description": "AutoLinkedElsewhere counts linked items that would be auto-linked to\nanother product than their current one",
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "integer"
+                },
+                "noMatch": {
+                    "type": "integer"
+                },
+                "notEmbedded": {
+                    "type": "integer"
+                },
+                "queued": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.SingleStoreResponse": {
+            "type": "object",
+            "properties": {
+                "penaltyStrategy": {
+                    "type": "string"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.SingleStoreResult"
+                    }
+                },
+                "staleness": {
+                    "$ref": "#/definitions/handlers.Staleness"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "totalCandidates": {
+                    "description": "Stores meeting minCoverage, on all pages",
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.SingleStoreResult": {
+            "type": "object",
+            "properties": {
+                "coverageBin": {
+                    "type": "integer"
+                },
+                "coverageRatio": {
+                    "type": "number"
+                },
+                "distance": {
+                    "type": "number"
+                },
+                "formattedRealTotal": {
+                    "description": "FormattedRealTotal is RealTotal for display, with ?includeFormatted=true",
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.ItemPriceInfo"
+                    }
+                },
+                "missingItems": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.MissingItem"
+                    }
+                },
+                "realTotal": {
+                    "type": "integer"
+                },
+                "sortingTotal": {
+                    "type": "integer"
+                },
+                "storeId": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.SplitProductRequest": {
+            "type": "object",
+            "required": [
+                "retailerItemIds",
+                "splitBy"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "retailerItemIds": {
+                    "type": "array",
+                    "maxItems": 500,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "splitBy": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.SplitProductResponse": {
+            "type": "object",
+            "properties": {
+                "barcodes": {
+                    "type": "integer"
+                },
+                "newProductId": {
+                    "type": "string"
+                },
+                "productId": {
+                    "type": "string"
+                },
+                "retailerItemIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handlers.Staleness": {
+            "type": "object",
+            "properties": {
+                "ageSeconds": {
+                    "type": "integer"
+                },
+                "degraded": {
+                    "type": "string"
+                },
+                "loadedAt": {
+                    "type": "string"
+                },
+                "stale": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "handlers.StatsBucket": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "filesPerHour": {
+                    "type": "number"
+                },
+                "label": {
+                    "description": "\"24h\", \"7d\", \"30d\"",
+                    "type": "string"
+                },
+                "medianDurationSeconds": {
+                    "description": "Duration and throughput aggregates over finished runs in the bucket.\nNil when no run in the bucket has both started_at and completed_at.",
+                    "type": "number"
+                },
+                "p95DurationSeconds": {
+                    "type": "number"
+                },
+                "pending": {
+                    "type": "integer"
+                },
+                "priceChanges": {
+                    "type": "integer"
+                },
+                "rowsPersisted": {
+                    "type": "integer"
+                },
+                "running": {
+                    "type": "integer"
+                },
+                "totalErrors": {
+                    "type": "integer"
+                },
+                "totalFiles": {
+                    "type": "integer"
+                },
+                "totalRuns": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.StoreAllocation": {
+            "type": "object",
+            "properties": {
+                "chainSlug": {
+                    "type": "string"
+                },
+                "distance": {
+                    "type": "number"
+                },
+                "formattedStoreTotal": {
+                    "description": "FormattedStoreTotal is StoreTotal for display, with ?includeFormatted=true",
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.ItemPriceInfo"
                     }
+                },
+                "storeId": {
+                    "type": "string"
+                },
+                "storeTotal": {
+                    "type": "integer"
+                },
+                "visitOrder": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.StorePrice": {
+            "type": "object",
+            "properties": {
+                "absoluteSavings": {
+                    "description": "AbsoluteSavings is the discount off the regular price in cents, omitted\nwithout a discount",
+                    "type": "integer"
+                },
+                "anchorPrice": {
+                    "type": "integer"
+                },
+                "brand": {
+                    "type": "string"
+                },
+                "currentPrice": {
+                    "type": "integer"
+                },
+                "discountEnd": {
+                    "type": "string"
+                },
+                "discountPercent": {
+                    "description": "DiscountPercent is the discount off the regular price in whole percent,\nomitted without a discount",
+                    "type": "integer"
+                },
+                "discountPrice": {
+                    "type": "integer"
+                },
+                "discountStart": {
+                    "type": "string"
+                },
+                "formatted": {
+                    "description": "Formatted holds display strings of the amounts, with ?includeFormatted=true",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handlers.FormattedStorePrice"
+                        }
+                    ]
+                },
+                "inStock": {
+                    "type": "boolean"
+                },
+                "isBelowAnchorPrice": {
+                    "description": "IsBelowAnchorPrice reports whether the price paid, discounted or not,\nis below the anchor price; omitted without an anchor price",
+                    "type": "boolean"
+                },
+                "itemExternalId": {
+                    "type": "string"
+                },
+                "itemName": {
+                    "type": "string"
+                },
+                "lastSeenAt": {
+                    "type": "string"
+                },
+                "lowestPrice30d": {
+                    "type": "integer"
+                },
+                "previousPrice": {
+                    "type": "integer"
+                },
+                "priceSignature": {
+                    "type": "string"
+                },
+                "retailerItemId": {
+                    "type": "string"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "unitPrice": {
+                    "type": "integer"
+                },
+                "unitPriceBaseQuantity": {
+                    "type": "string"
+                },
+                "unitPriceBaseUnit": {
+                    "type": "string"
+                },
+                "unitQuantity": {
+                    "type": "string"
                 }
             }
-        }
-    },
-    "definitions": {
-        "handlers.BasketItem": {
+        },
+        "handlers.StorePriceChange": {
             "type": "object",
-            "required": [
-                "itemId",
-                "name",
-                "quantity"
-            ],
             "properties": {
-                "itemId": {
+                "anchorPrice": {
+                    "type": "integer"
+                },
+                "discountPrice": {
+                    "type": "integer"
+                },
+                "isException": {
+                    "type": "boolean"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "retailerItemId": {
                     "type": "string"
                 },
-                "name": {
+                "unitPrice": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.StorePriceChecksumResponse": {
+            "type": "object",
+            "properties": {
+                "chainSlug": {
                     "type": "string"
                 },
-                "quantity": {
-                    "type": "integer",
-                    "minimum": 1
+                "checksum": {
+                    "type": "string"
+                },
+                "itemCount": {
+                    "type": "integer"
+                },
+                "loadedAt": {
+                    "type": "string"
+                },
+                "storeId": {
+                    "type": "string"
                 }
             }
         },
-        "handlers.GetStatsResponse": {
+        "handlers.SuggestItemsResponse": {
             "type": "object",
             "properties": {
-                "buckets": {
+                "query": {
+                    "type": "string"
+                },
+                "suggestions": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/handlers.StatsBucket"
+                        "$ref": "#/definitions/handlers.Suggestion"
                     }
                 }
             }
         },
-        "handlers.GetStorePricesResponse": {
+        "handlers.Suggestion": {
             "type": "object",
             "properties": {
-                "prices": {
+                "itemCount": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.TestParseResponse": {
+            "type": "object",
+            "properties": {
+                "errorCount": {
+                    "type": "integer"
+                },
+                "errors": {
+                    "description": "First errors only",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/handlers.StorePrice"
+                        "$ref": "#/definitions/types.ParseError"
+                    }
+                },
+                "mapping": {
+                    "$ref": "#/definitions/csv.CsvColumnMapping"
+                },
+                "sampleRows": {
+                    "description": "First parsed rows",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.NormalizedRow"
                     }
                 },
+                "totalRows": {
+                    "type": "integer"
+                },
+                "validRows": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.TravelCost": {
+            "type": "object",
+            "properties": {
+                "distanceCost": {
+                    "description": "The round trip at the configured cost per km",
+                    "type": "integer"
+                },
+                "storeCost": {
+                    "description": "The configured cost of each store after the first",
+                    "type": "integer"
+                },
                 "total": {
                     "type": "integer"
                 }
             }
         },
-        "handlers.IngestionError": {
+        "handlers.UnavailableItem": {
             "type": "object",
             "properties": {
-                "chunkId": {
+                "itemId": {
                     "type": "string"
                 },
-                "createdAt": {
+                "itemName": {
                     "type": "string"
                 },
-                "entryId": {
+                "quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.UnlinkedChainCount": {
+            "type": "object",
+            "properties": {
+                "chainSlug": {
                     "type": "string"
                 },
-                "errorDetails": {
-                    "type": "string"
+                "total": {
+                    "type": "integer"
                 },
-                "errorMessage": {
+                "unlinked": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.UpdateChainRequest": {
+            "type": "object",
+            "properties": {
+                "brandColor": {
                     "type": "string"
                 },
-                "errorType": {
+                "displayName": {
                     "type": "string"
                 },
-                "fileId": {
-                    "type": "string"
+                "enabled": {
+                    "type": "boolean"
                 },
-                "id": {
+                "logoUrl": {
                     "type": "string"
                 },
-                "runId": {
+                "metadata": {
+                    "type": "object"
+                },
+                "name": {
                     "type": "string"
                 },
-                "severity": {
+                "website": {
                     "type": "string"
                 }
             }
         },
-        "handlers.IngestionFile": {
+        "handlers.UserDataExport": {
             "type": "object",
             "properties": {
-                "chunkSize": {
-                    "type": "integer"
-                },
-                "createdAt": {
+                "exportedAt": {
                     "type": "string"
                 },
-                "entryCount": {
-                    "type": "integer"
+                "optimizationResults": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.OptimizationResult"
+                    }
                 },
-                "fileHash": {
+                "userRef": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.WebhookRequest": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "description": {
                     "type": "string"
                 },
-                "fileSize": {
-                    "type": "integer"
+                "enabled": {
+                    "description": "Default true",
+                    "type": "boolean"
                 },
-                "fileType": {
-                    "type": "string"
+                "eventTypes": {
+                    "description": "Empty subscribes to every event",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "filename": {
+                "secret": {
+                    "description": "Generated on create, kept on update when empty",
                     "type": "string"
                 },
-                "id": {
+                "url": {
+                    "description": "http or https",
                     "type": "string"
-                },
-                "metadata": {
+                }
+            }
+        },
+        "jobs.AnchorComplianceStats": {
+            "type": "object",
+            "properties": {
+                "chainSlug": {
                     "type": "string"
                 },
-                "processedAt": {
+                "computedAt": {
                     "type": "string"
                 },
-                "processedChunks": {
+                "discountedPrices": {
                     "type": "integer"
                 },
-                "runId": {
-                    "type": "string"
+                "minAnchorAgeDays": {
+                    "type": "integer"
                 },
-                "status": {
-                    "type": "string"
+                "missingAnchor": {
+                    "type": "integer"
                 },
-                "totalChunks": {
+                "missingAnchorShare": {
+                    "description": "0-1 of the discounted prices",
+                    "type": "number"
+                },
+                "nonCompliantShare": {
+                    "description": "0-1 of the discounted prices",
+                    "type": "number"
+                },
+                "youngAnchor": {
                     "type": "integer"
+                },
+                "youngAnchorShare": {
+                    "description": "0-1 of the discounted prices",
+                    "type": "number"
                 }
             }
         },
-        "handlers.IngestionRun": {
+        "jobs.ParseProfileComparison": {
             "type": "object",
             "properties": {
-                "chainSlug": {
-                    "type": "string"
-                },
-                "completedAt": {
-                    "type": "string"
+                "allocsPerRowChange": {
+                    "description": "relative, positive is costlier",
+                    "type": "number"
                 },
-                "createdAt": {
+                "chainSlug": {
                     "type": "string"
                 },
-                "errorCount": {
-                    "type": "integer"
+                "cpuPerRowChange": {
+                    "description": "relative, positive is costlier",
+                    "type": "number"
                 },
-                "id": {
-                    "type": "string"
+                "current": {
+                    "$ref": "#/definitions/jobs.ParseProfileStats"
                 },
-                "metadata": {
-                    "type": "string"
+                "previous": {
+                    "$ref": "#/definitions/jobs.ParseProfileStats"
                 },
-                "processedEntries": {
-                    "type": "integer"
+                "regressed": {
+                    "type": "boolean"
                 },
-                "processedFiles": {
-                    "type": "integer"
+                "rowsPerSecondChange": {
+                    "description": "relative, negative is slower",
+                    "type": "number"
+                }
+            }
+        },
+        "jobs.ParseProfileReport": {
+            "type": "object",
+            "properties": {
+                "chains": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/jobs.ParseProfileComparison"
+                    }
                 },
-                "source": {
+                "from": {
                     "type": "string"
                 },
-                "startedAt": {
-                    "type": "string"
+                "threshold": {
+                    "type": "number"
                 },
-                "status": {
+                "to": {
                     "type": "string"
-                },
-                "totalEntries": {
-                    "type": "integer"
-                },
-                "totalFiles": {
-                    "type": "integer"
                 }
             }
         },
-        "handlers.ItemPriceInfo": {
+        "jobs.ParseProfileStats": {
             "type": "object",
             "properties": {
-                "basePrice": {
-                    "type": "integer"
-                },
-                "discountPrice": {
+                "allocBytes": {
                     "type": "integer"
                 },
-                "effectivePrice": {
+                "allocs": {
                     "type": "integer"
                 },
-                "hasDiscount": {
-                    "type": "boolean"
+                "allocsPerRow": {
+                    "type": "number"
                 },
-                "itemId": {
+                "chainSlug": {
                     "type": "string"
                 },
-                "itemName": {
-                    "type": "string"
+                "cpuMicrosPerRow": {
+                    "type": "number"
                 },
-                "lineTotal": {
+                "cpuSeconds": {
+                    "type": "number"
+                },
+                "files": {
                     "type": "integer"
                 },
-                "quantity": {
+                "rows": {
                     "type": "integer"
-                }
-            }
-        },
-        "handlers.ListErrorsResponse": {
-            "type": "object",
-            "properties": {
-                "errors": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/handlers.IngestionError"
-                    }
                 },
-                "total": {
-                    "type": "integer"
+                "rowsPerSecond": {
+                    "type": "number"
+                },
+                "wallSeconds": {
+                    "type": "number"
                 }
             }
         },
-        "handlers.ListFilesResponse": {
+        "mappings.ColumnProfile": {
             "type": "object",
             "properties": {
-                "files": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/handlers.IngestionFile"
+                "avgLen": {
+                    "type": "number"
+                },
+                "distinct": {
+                    "type": "integer"
+                },
+                "header": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "description": "Dominant value kind",
+                    "type": "string"
+                },
+                "kinds": {
+                    "description": "Share of non-empty values per kind",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
                     }
                 },
-                "total": {
+                "nonEmpty": {
                     "type": "integer"
-                }
-            }
-        },
-        "handlers.ListRunsResponse": {
-            "type": "object",
-            "properties": {
-                "runs": {
+                },
+                "samples": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/handlers.IngestionRun"
+                        "type": "string"
                     }
-                },
-                "total": {
-                    "type": "integer"
                 }
             }
         },
-        "handlers.Location": {
+        "mappings.FieldProposal": {
             "type": "object",
-            "required": [
-                "latitude",
-                "longitude"
-            ],
             "properties": {
-                "latitude": {
-                    "type": "number",
-                    "maximum": 90,
-                    "minimum": -90
+                "column": {
+                    "description": "Header name, or column index when the file has no header",
+                    "type": "string"
                 },
-                "longitude": {
-                    "type": "number",
-                    "maximum": 180,
-                    "minimum": -180
+                "confidence": {
+                    "description": "0..1",
+                    "type": "number"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
                 }
             }
         },
-        "handlers.MissingItem": {
+        "mappings.Proposal": {
             "type": "object",
             "properties": {
-                "isOptional": {
-                    "type": "boolean"
+                "columns": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/mappings.ColumnProfile"
+                    }
                 },
-                "itemId": {
+                "delimiter": {
                     "type": "string"
                 },
-                "itemName": {
+                "encoding": {
                     "type": "string"
                 },
-                "penalty": {
+                "fields": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/mappings.FieldProposal"
+                    }
+                },
+                "format": {
+                    "description": "csv or xlsx",
+                    "type": "string"
+                },
+                "hasHeader": {
+                    "type": "boolean"
+                },
+                "mapping": {
+                    "description": "Mapping is the proposal in column mapping override form",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "missing": {
+                    "description": "Missing lists required fields no column was found for",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "rowsRead": {
                     "type": "integer"
                 }
             }
         },
-        "handlers.MultiStoreResult": {
+        "optimizer.CanaryReport": {
             "type": "object",
             "properties": {
-                "algorithmUsed": {
-                    "type": "string"
+                "activeTimeMs": {
+                    "type": "number"
                 },
-                "combinedTotal": {
+                "baskets": {
                     "type": "integer"
                 },
-                "coverageRatio": {
+                "candidateTimeMs": {
                     "type": "number"
                 },
-                "stores": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/handlers.StoreAllocation"
-                    }
-                },
-                "unassignedItems": {
+                "failures": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/handlers.MissingItem"
+                        "type": "string"
                     }
                 }
             }
         },
-        "handlers.OptimizeRequest": {
+        "optimizer.FailedBuild": {
             "type": "object",
-            "required": [
-                "basketItems",
-                "chainSlug"
-            ],
             "properties": {
-                "basketItems": {
-                    "type": "array",
-                    "maxItems": 100,
-                    "minItems": 1,
-                    "items": {
-                        "$ref": "#/definitions/handlers.BasketItem"
-                    }
+                "canary": {
+                    "$ref": "#/definitions/optimizer.CanaryReport"
                 },
-                "chainSlug": {
+                "error": {
                     "type": "string"
                 },
-                "location": {
-                    "$ref": "#/definitions/handlers.Location"
-                },
-                "maxDistance": {
-                    "type": "number"
+                "failedAt": {
+                    "type": "string"
                 },
-                "maxStores": {
+                "version": {
                     "type": "integer"
                 }
             }
         },
-        "handlers.RerunRunRequest": {
+        "storelocator.SyncResult": {
             "type": "object",
-            "required": [
-                "rerunType",
-                "targetId"
-            ],
             "properties": {
-                "rerunType": {
-                    "description": "\"file\", \"chunk\", \"entry\"",
+                "chainSlug": {
                     "type": "string"
                 },
-                "targetId": {
-                    "description": "ID of file/chunk/entry to rerun",
-                    "type": "string"
+                "coordinatesFilled": {
+                    "type": "integer"
+                },
+                "entries": {
+                    "description": "Stores listed by the locator",
+                    "type": "integer"
+                },
+                "matched": {
+                    "type": "integer"
+                },
+                "stores": {
+                    "description": "Registered stores reconciled",
+                    "type": "integer"
+                },
+                "unmatched": {
+                    "type": "integer"
                 }
             }
         },
-        "handlers.SearchItem": {
+        "storelocator.UnmatchedStore": {
             "type": "object",
             "properties": {
-                "avgPrice": {
-                    "description": "Average price across stores",
-                    "type": "integer"
-                },
-                "brand": {
-                    "type": "string"
-                },
-                "category": {
+                "address": {
                     "type": "string"
                 },
                 "chainSlug": {
                     "type": "string"
                 },
-                "description": {
-                    "type": "string"
-                },
-                "externalId": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "imageUrl": {
+                "checkedAt": {
                     "type": "string"
                 },
-                "name": {
+                "city": {
                     "type": "string"
                 },
-                "storeCount": {
-                    "description": "Number of stores with this item",
-                    "type": "integer"
-                },
-                "subcategory": {
+                "postalCode": {
                     "type": "string"
                 },
-                "unit": {
+                "storeId": {
                     "type": "string"
                 },
-                "unitQuantity": {
+                "storeName": {
                     "type": "string"
                 }
             }
         },
-        "handlers.SearchItemsResponse": {
+        "sweepers.StoreChurn": {
             "type": "object",
             "properties": {
-                "items": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/handlers.SearchItem"
-                    }
+                "chainSlug": {
+                    "type": "string"
                 },
-                "query": {
+                "flaggedAt": {
                     "type": "string"
                 },
-                "total": {
-                    "type": "integer"
-                }
-            }
-        },
-        "handlers.StatsBucket": {
-            "type": "object",
-            "properties": {
-                "completed": {
-                    "type": "integer"
+                "history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/sweepers.StoreGroupPeriod"
+                    }
                 },
-                "failed": {
+                "reassignments": {
                     "type": "integer"
                 },
-                "label": {
-                    "description": "\"24h\", \"7d\", \"30d\"",
+                "storeId": {
                     "type": "string"
                 },
-                "pending": {
-                    "type": "integer"
-                },
-                "running": {
-                    "type": "integer"
-                },
-                "totalErrors": {
-                    "type": "integer"
+                "storeName": {
+                    "type": "string"
                 },
-                "totalFiles": {
-                    "type": "integer"
+                "updatedAt": {
+                    "type": "string"
                 },
-                "totalRuns": {
+                "windowDays": {
                     "type": "integer"
                 }
             }
         },
-        "handlers.StoreAllocation": {
+        "sweepers.StoreGroupPeriod": {
             "type": "object",
             "properties": {
-                "distance": {
-                    "type": "number"
-                },
-                "items": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/handlers.ItemPriceInfo"
-                    }
-                },
-                "storeId": {
+                "priceGroupId": {
                     "type": "string"
                 },
-                "storeTotal": {
-                    "type": "integer"
+                "validFrom": {
+                    "type": "string"
                 },
-                "visitOrder": {
-                    "type": "integer"
+                "validTo": {
+                    "description": "nil while current",
+                    "type": "string"
                 }
             }
         },
-        "handlers.StorePrice": {
+        "types.NormalizedRow": {
             "type": "object",
             "properties": {
                 "anchorPrice": {
                     "type": "integer"
                 },
+                "anchorPriceAsOf": {
+                    "type": "string"
+                },
+                "barcodes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "brand": {
                     "type": "string"
                 },
-                "currentPrice": {
-                    "type": "integer"
+                "category": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
                 },
                 "discountEnd": {
                     "type": "string"
@@ -1366,34 +8201,46 @@ const docTemplate = `{
                 "discountStart": {
                     "type": "string"
                 },
-                "inStock": {
-                    "type": "boolean"
-                },
-                "itemExternalId": {
+                "externalId": {
                     "type": "string"
                 },
-                "itemName": {
+                "imageUrl": {
                     "type": "string"
                 },
-                "lastSeenAt": {
+                "lowestPrice30d": {
+                    "type": "integer"
+                },
+                "name": {
                     "type": "string"
                 },
-                "lowestPrice30d": {
+                "nameI18n": {
+                    "description": "NameI18n holds names in other languages by ISO 639-1 code, for chains\npublishing bilingual names; Name stays the Croatian one",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "price": {
+                    "description": "cents",
                     "type": "integer"
                 },
-                "previousPrice": {
+                "rawData": {
+                    "type": "string"
+                },
+                "rowNumber": {
                     "type": "integer"
                 },
-                "priceSignature": {
+                "storeIdentifier": {
                     "type": "string"
                 },
-                "retailerItemId": {
+                "subcategory": {
                     "type": "string"
                 },
                 "unit": {
                     "type": "string"
                 },
                 "unitPrice": {
+                    "description": "Croatian transparency fields",
                     "type": "integer"
                 },
                 "unitPriceBaseQuantity": {
@@ -1406,6 +8253,23 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "types.ParseError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "originalValue": {
+                    "type": "string"
+                },
+                "rowNumber": {
+                    "type": "integer"
+                }
+            }
         }
     }
 }`
@@ -10,11 +10,17 @@ import (
 	"time"
 
 	"github.com/kosarica/price-service/internal/adapters/config"
+	"github.com/kosarica/price-service/internal/chainsettings"
 	httpclient "github.com/kosarica/price-service/internal/http"
 	"github.com/kosarica/price-service/internal/http/ratelimit"
+	"github.com/kosarica/price-service/internal/pkg/patterns"
 	"github.com/kosarica/price-service/internal/types"
 )
 
+// DefaultParserVersion is the parser version of adapters that do not set
+// their own
+const DefaultParserVersion = "1.0.0"
+
 // BaseAdapterConfig contains configuration for base chain adapter
 type BaseAdapterConfig struct {
 	Slug                   string
@@ -24,6 +30,10 @@ type BaseAdapterConfig struct {
 	FilenamePrefixPatterns []string
 	FileExtensionPattern   *regexp.Regexp
 	RateLimitOverrides     *ratelimit.PartialConfig
+	// ParserVersion identifies the adapter's parsing logic and is recorded on
+	// every file it parses. Bump it with every fix that changes parse output
+	// so files parsed before the fix can be reprocessed.
+	ParserVersion string
 }
 
 // ChainAdapter interface defines the contract for all chain adapters
@@ -50,8 +60,16 @@ type BaseChainAdapter struct {
 	rateLimiter            *ratelimit.RateLimiter
 	rateLimitConfig        ratelimit.Config
 	httpClient             *httpclient.Client
+	parserVersion          string
+
+	// baseRateLimitConfig is the adapter's own rate limit, before the chain's
+	// settings are applied
+	baseRateLimitConfig ratelimit.Config
 }
 
+// csvExtensionPattern is the default file extension pattern of adapters
+var csvExtensionPattern = regexp.MustCompile(`\.(csv|CSV)$`)
+
 // NewBaseChainAdapter creates a new base chain adapter
 func NewBaseChainAdapter(cfg BaseAdapterConfig) (*BaseChainAdapter, error) {
 	// Validate supported types is not empty
@@ -61,12 +79,12 @@ func NewBaseChainAdapter(cfg BaseAdapterConfig) (*BaseChainAdapter, error) {
 
 	fileExtensionPattern := cfg.FileExtensionPattern
 	if fileExtensionPattern == nil {
-		fileExtensionPattern = regexp.MustCompile(`\.(csv|CSV)$`)
+		fileExtensionPattern = csvExtensionPattern
 	}
 
 	filenamePrefixPatterns := make([]*regexp.Regexp, 0, len(cfg.FilenamePrefixPatterns)+2)
 	for _, pattern := range cfg.FilenamePrefixPatterns {
-		re, err := regexp.Compile(pattern)
+		re, err := patterns.Compile(pattern)
 		if err != nil {
 			return nil, fmt.Errorf("invalid filename prefix pattern %q: %w", pattern, err)
 		}
@@ -80,7 +98,7 @@ func NewBaseChainAdapter(cfg BaseAdapterConfig) (*BaseChainAdapter, error) {
 			`(?i)^cjenik[_-]?`,
 		}
 		for _, pattern := range defaultPatterns {
-			re, err := regexp.Compile(pattern)
+			re, err := patterns.Compile(pattern)
 			if err != nil {
 				return nil, fmt.Errorf("invalid default filename prefix pattern %q: %w", pattern, err)
 			}
@@ -95,6 +113,11 @@ func NewBaseChainAdapter(cfg BaseAdapterConfig) (*BaseChainAdapter, error) {
 		rateLimitConfig = ratelimit.DefaultConfig()
 	}
 
+	parserVersion := cfg.ParserVersion
+	if parserVersion == "" {
+		parserVersion = DefaultParserVersion
+	}
+
 	return &BaseChainAdapter{
 		slug:                   cfg.Slug,
 		name:                   cfg.Name,
@@ -104,7 +127,9 @@ func NewBaseChainAdapter(cfg BaseAdapterConfig) (*BaseChainAdapter, error) {
 		fileExtensionPattern:   fileExtensionPattern,
 		rateLimiter:            ratelimit.NewRateLimiter(rateLimitConfig),
 		rateLimitConfig:        rateLimitConfig,
+		baseRateLimitConfig:    rateLimitConfig,
 		httpClient:             httpclient.NewClient(rateLimitConfig),
+		parserVersion:          parserVersion,
 	}, nil
 }
 
@@ -118,6 +143,11 @@ func (a *BaseChainAdapter) Name() string {
 	return a.name
 }
 
+// ParserVersion returns the version of the adapter's parsing logic
+func (a *BaseChainAdapter) ParserVersion() string {
+	return a.parserVersion
+}
+
 // SupportedTypes returns supported file types
 func (a *BaseChainAdapter) SupportedTypes() []types.FileType {
 	return a.supportedTypes
@@ -128,6 +158,19 @@ func (a *BaseChainAdapter) BaseURL() string {
 	return a.config.BaseURL
 }
 
+// ApplySettings applies the chain's current rate limit settings to the
+// adapter's HTTP client. The pipeline calls it before every run, so changed
+// settings take effect on the next run.
+func (a *BaseChainAdapter) ApplySettings() {
+	cfg := chainsettings.RateLimit(a.slug, a.baseRateLimitConfig)
+	if cfg == a.rateLimitConfig {
+		return
+	}
+	a.rateLimitConfig = cfg
+	a.rateLimiter.SetConfig(cfg)
+	a.httpClient.SetConfig(cfg)
+}
+
 // HTTPClient returns the HTTP client for making requests
 func (a *BaseChainAdapter) HTTPClient() *httpclient.Client {
 	return a.httpClient
@@ -159,7 +202,7 @@ func (a *BaseChainAdapter) Discover(targetDate string) ([]types.DiscoveredFile,
 	// Get file extensions to look for
 	extensions := a.getDiscoverableExtensions()
 	extensionPattern := strings.Join(extensions, "|")
-	linkPattern := regexp.MustCompile(`(?i)href=["']([^"']*\.(` + extensionPattern + `)(?:\?[^"']*)?)["']`)
+	linkPattern := patterns.MustCompile(`(?i)href=["']([^"']*\.(` + extensionPattern + `)(?:\?[^"']*)?)["']`)
 
 	matches := linkPattern.FindAllStringSubmatch(html, -1)
 	seenURLs := make(map[string]bool)
@@ -225,6 +268,8 @@ func (a *BaseChainAdapter) getDiscoverableExtensions() []string {
 			extensions = append(extensions, "xlsx", "xls")
 		case types.FileTypeXML:
 			extensions = append(extensions, "xml")
+		case types.FileTypeJSON:
+			extensions = append(extensions, "json")
 		case types.FileTypeZIP:
 			extensions = append(extensions, "zip")
 		}
@@ -262,6 +307,9 @@ func (a *BaseChainAdapter) detectFileType(filename string) types.FileType {
 	if strings.HasSuffix(lowerFilename, ".xml") {
 		return types.FileTypeXML
 	}
+	if strings.HasSuffix(lowerFilename, ".json") {
+		return types.FileTypeJSON
+	}
 	if strings.HasSuffix(lowerFilename, ".zip") {
 		return types.FileTypeZIP
 	}
@@ -1,9 +1,9 @@
 package base
 
 import (
-	"regexp"
-
 	_ "github.com/kosarica/price-service/internal/adapters/config"
+	"github.com/kosarica/price-service/internal/chainsettings"
+	"github.com/kosarica/price-service/internal/mappings"
 	"github.com/kosarica/price-service/internal/parsers/csv"
 	"github.com/kosarica/price-service/internal/types"
 )
@@ -27,7 +27,7 @@ type BaseCsvAdapter struct {
 func NewBaseCsvAdapter(cfg CsvAdapterConfig) (*BaseCsvAdapter, error) {
 	// Set CSV file extension pattern
 	if cfg.FileExtensionPattern == nil {
-		cfg.FileExtensionPattern = regexp.MustCompile(`\.(csv|CSV)$`)
+		cfg.FileExtensionPattern = csvExtensionPattern
 	}
 
 	// Create base adapter
@@ -77,9 +77,14 @@ func (a *BaseCsvAdapter) Parse(content []byte, filename string, options *types.P
 	// Extract store identifier from filename
 	storeIdentifier := a.extractStoreIdentifierFromFilename(filename)
 
+	parser, err := a.parserFor(options)
+	if err != nil {
+		return nil, err
+	}
+
 	// Parse with store identifier
 	// The parser handles alternative mapping internally if no valid rows found
-	result, err := a.csvParser.ParseWithStoreID(processedContent, storeIdentifier)
+	result, err := parser.ParseWithStoreID(processedContent, storeIdentifier)
 	if err != nil {
 		return nil, err
 	}
@@ -88,6 +93,35 @@ func (a *BaseCsvAdapter) Parse(content []byte, filename string, options *types.P
 	return a.postprocessResult(result), nil
 }
 
+// parserFor returns the parser to use for a parse, with the chain's CSV
+// settings applied and overlaying the column mapping override from the
+// options or, failing that, the chain's enabled override from the database
+func (a *BaseCsvAdapter) parserFor(options *types.ParseOptions) (*csv.Parser, error) {
+	parser := a.csvParser
+	if parserOptions := chainsettings.CSVOptions(a.Slug(), parser.Options()); parserOptions != parser.Options() {
+		parser = parser.WithOptions(parserOptions)
+	}
+
+	var override map[string]string
+	if options != nil && options.ColumnMappingOverride != nil {
+		override = options.ColumnMappingOverride
+	} else {
+		override = mappings.Override(a.Slug())
+	}
+	if len(override) == 0 {
+		return parser, nil
+	}
+
+	mapping, err := csv.ApplyOverride(a.columnMapping, override)
+	if err != nil {
+		return nil, &AdapterError{
+			Chain: a.Slug(),
+			Msg:   "invalid column mapping override: " + err.Error(),
+		}
+	}
+	return parser.WithColumnMapping(&mapping), nil
+}
+
 // preprocessContent preprocesses content before parsing
 // Can be overridden by subclasses
 func (a *BaseCsvAdapter) preprocessContent(content []byte) []byte {
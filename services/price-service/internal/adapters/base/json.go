@@ -0,0 +1,74 @@
+package base
+
+import (
+	"regexp"
+
+	"github.com/kosarica/price-service/internal/parsers/json"
+	"github.com/kosarica/price-service/internal/types"
+)
+
+// JsonAdapterConfig contains configuration for JSON-based chain adapters
+type JsonAdapterConfig struct {
+	BaseAdapterConfig
+	FieldMapping            json.JsonFieldMapping
+	AlternativeFieldMapping *json.JsonFieldMapping
+	// ItemsPath is the path to the items array; empty when the file is the
+	// array itself or keeps it under a common key like "products"
+	ItemsPath string
+}
+
+// BaseJsonAdapter provides common JSON parsing logic
+type BaseJsonAdapter struct {
+	*BaseChainAdapter
+	jsonParser   *json.Parser
+	fieldMapping json.JsonFieldMapping
+	altMapping   *json.JsonFieldMapping
+}
+
+var jsonExtensionPattern = regexp.MustCompile(`\.(json|JSON)$`)
+
+// NewBaseJsonAdapter creates a new base JSON adapter
+func NewBaseJsonAdapter(cfg JsonAdapterConfig) (*BaseJsonAdapter, error) {
+	// Set JSON file extension pattern
+	if cfg.FileExtensionPattern == nil {
+		cfg.FileExtensionPattern = jsonExtensionPattern
+	}
+
+	// Create base adapter
+	base, err := NewBaseChainAdapter(cfg.BaseAdapterConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonParser := json.NewParser(json.JsonParserOptions{
+		ItemsPath:    cfg.ItemsPath,
+		FieldMapping: cfg.FieldMapping,
+	})
+	if cfg.AlternativeFieldMapping != nil {
+		jsonParser.SetAlternativeMapping(cfg.AlternativeFieldMapping)
+	}
+
+	return &BaseJsonAdapter{
+		BaseChainAdapter: base,
+		jsonParser:       jsonParser,
+		fieldMapping:     cfg.FieldMapping,
+		altMapping:       cfg.AlternativeFieldMapping,
+	}, nil
+}
+
+// Parse parses JSON content into normalized rows
+// Tries primary field mapping first, then alternative if no valid rows
+func (a *BaseJsonAdapter) Parse(content []byte, filename string, options *types.ParseOptions) (*types.ParseResult, error) {
+	storeIdentifier := a.extractStoreIdentifierFromFilename(filename)
+	return a.jsonParser.ParseWithStoreID(content, storeIdentifier)
+}
+
+// GetFieldMapping returns the primary field mapping
+func (a *BaseJsonAdapter) GetFieldMapping() json.JsonFieldMapping {
+	return a.fieldMapping
+}
+
+// GetAlternativeMapping returns the alternative field mapping
+func (a *BaseJsonAdapter) GetAlternativeMapping() *json.JsonFieldMapping {
+	return a.altMapping
+}
@@ -31,11 +31,16 @@ type BaseXlsxAdapter struct {
 	defaultStoreIdentifier   string
 }
 
+var (
+	xlsxExtensionPattern         = regexp.MustCompile(`\.(xlsx|xls|XLSX|XLS)$`)
+	surroundingWhitespacePattern = regexp.MustCompile(`^\s+|\s+$`)
+)
+
 // NewBaseXlsxAdapter creates a new base XLSX adapter
 func NewBaseXlsxAdapter(cfg XlsxAdapterConfig) (*BaseXlsxAdapter, error) {
 	// Set XLSX file extension pattern
 	if cfg.FileExtensionPattern == nil {
-		cfg.FileExtensionPattern = regexp.MustCompile(`\.(xlsx|xls|XLSX|XLS)$`)
+		cfg.FileExtensionPattern = xlsxExtensionPattern
 	}
 
 	// Create base adapter
@@ -139,5 +144,5 @@ func (a *BaseXlsxAdapter) extractStoreIdentifierFromFilename(filename string) st
 
 // trimWhitespace trims whitespace from string
 func trimWhitespace(s string) string {
-	return regexp.MustCompile(`^\s+|\s+$`).ReplaceAllString(s, "")
+	return surroundingWhitespacePattern.ReplaceAllString(s, "")
 }
@@ -1,6 +1,7 @@
 package base
 
 import (
+	"io"
 	"regexp"
 	"strings"
 
@@ -26,11 +27,22 @@ type BaseXmlAdapter struct {
 	itemPaths       []string
 }
 
+var (
+	xmlExtensionPattern = regexp.MustCompile(`\.(xml|XML)$`)
+
+	// storeIDPatterns extract a store ID from names like "store_123" or
+	// "poslovnica_456", most specific first
+	storeIDPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?:store|poslovnica|trgovina)[_-]?(\d+)`),
+		regexp.MustCompile(`(?:store|poslovnica|trgovina)[_-]?([A-Za-z0-9]+)`),
+	}
+)
+
 // NewBaseXmlAdapter creates a new base XML adapter
 func NewBaseXmlAdapter(cfg XmlAdapterConfig) (*BaseXmlAdapter, error) {
 	// Set XML file extension pattern
 	if cfg.FileExtensionPattern == nil {
-		cfg.FileExtensionPattern = regexp.MustCompile(`\.(xml|XML)$`)
+		cfg.FileExtensionPattern = xmlExtensionPattern
 	}
 
 	// Create base adapter
@@ -133,6 +145,27 @@ func (a *BaseXmlAdapter) parseWithItemsPath(content []byte, itemsPath string, fi
 	return result, nil
 }
 
+// ParseStream parses XML from r one item at a time, passing each valid row to
+// emit, so large files are never built in memory. The items are those at the
+// first of the adapter's item paths found in the file; the mapping is chosen
+// on the first item, falling back to the alternative mapping as Parse does.
+// The returned result has counts, errors and warnings but no rows.
+func (a *BaseXmlAdapter) ParseStream(r io.Reader, filename string, emit func(types.NormalizedRow) error) (*types.ParseResult, error) {
+	storeIdentifier := a.extractStoreIdentifierFromFilename(filename)
+
+	parser := xml.NewParser(xml.XmlParserOptions{
+		FieldMapping:           a.fieldMapping,
+		DefaultStoreIdentifier: storeIdentifier,
+		AttributePrefix:        "@_",
+		Encoding:               "auto",
+	})
+	if a.altMapping != nil {
+		parser.SetAlternativeMapping(a.altMapping)
+	}
+
+	return parser.ParseStream(r, a.itemPaths, storeIdentifier, emit)
+}
+
 // ExtractStoreIdentifier extracts store identifier from XML file
 // For XML files, store ID is typically embedded in content or filename
 func (a *BaseXmlAdapter) ExtractStoreIdentifier(file types.DiscoveredFile) *types.StoreIdentifier {
@@ -168,13 +201,7 @@ func (a *BaseXmlAdapter) extractStoreIdentifierFromFilename(filename string) str
 	cleanName = strings.TrimSpace(cleanName)
 
 	// Try to extract store ID from patterns like "store_123" or "poslovnica_456"
-	storeIdPatterns := []string{
-		`(?:store|poslovnica|trgovina)[_-]?(\d+)`,
-		`(?:store|poslovnica|trgovina)[_-]?([A-Za-z0-9]+)`,
-	}
-
-	for _, pattern := range storeIdPatterns {
-		re := regexp.MustCompile(pattern)
+	for _, re := range storeIDPatterns {
 		if match := re.FindStringSubmatch(cleanName); len(match) > 1 {
 			return match[1]
 		}
@@ -14,8 +14,12 @@ import (
 	"github.com/kosarica/price-service/internal/adapters/base"
 	"github.com/kosarica/price-service/internal/adapters/config"
 	"github.com/kosarica/price-service/internal/parsers/xlsx"
+	"github.com/kosarica/price-service/internal/pkg/clock"
 	"github.com/kosarica/price-service/internal/types"
-	"github.com/rs/zerolog/log"
+)
+
+var (
+	dmFilenamePattern = regexp.MustCompile(`^(dm|DM)[_-](\d{4}-\d{2}-\d{2})\.(xlsx|xls)$`)
 )
 
 const (
@@ -171,11 +175,11 @@ func (a *DmAdapter) Discover(targetDate string) ([]types.DiscoveredFile, error)
 		date = a.discoveryDate
 	}
 	if date == "" {
-		date = time.Now().Format("2006-01-02")
+		date = clock.Today()
 	}
 
 	// Primary: Try to discover from web
-	log.Info().Msg("Discovering DM price list from web portal")
+	logger.Info().Msg("Discovering DM price list from web portal")
 
 	resp, err := a.HTTPClient().Get(dmPriceListURL)
 	if err == nil && resp.StatusCode == 200 {
@@ -227,43 +231,41 @@ func (a *DmAdapter) Discover(targetDate string) ([]types.DiscoveredFile, error)
 			},
 		})
 
-		log.Info().Str("filename", urlFilename).Msg("Found DM price list")
+		logger.Info().Str("filename", urlFilename).Msg("Found DM price list")
 		return discoveredFiles, nil
 	}
 
 	if err != nil {
-		log.Warn().Err(err).Msg("Failed to access DM web portal")
+		logger.Warn().Err(err).Msg("Failed to access DM web portal")
 	} else if resp != nil {
-		log.Warn().Int("status_code", resp.StatusCode).Msg("DM web portal returned non-200, falling back to local files")
+		logger.Warn().Int("status_code", resp.StatusCode).Msg("DM web portal returned non-200, falling back to local files")
 		resp.Body.Close()
 	}
-	log.Warn().Msg("Falling back to local files")
+	logger.Warn().Msg("Falling back to local files")
 
 	// Fallback: Look for local files in ./data/ingestion/dm/ directory
 	dataDir := filepath.Join(".", "data", "ingestion", "dm")
-	log.Debug().Str("directory", dataDir).Msg("Scanning DM local directory")
+	logger.Debug().Str("directory", dataDir).Msg("Scanning DM local directory")
 
 	if _, err := os.Stat(dataDir); os.IsNotExist(err) {
-		log.Warn().Str("directory", dataDir).Msg("DM data directory not found")
+		logger.Warn().Str("directory", dataDir).Msg("DM data directory not found")
 		return discoveredFiles, nil
 	}
 
 	entries, err := os.ReadDir(dataDir)
 	if err != nil {
-		log.Error().Err(err).Msg("Error reading DM data directory")
+		logger.Error().Err(err).Msg("Error reading DM data directory")
 		return discoveredFiles, nil
 	}
 
 	// Match DM filename patterns: dm_YYYY-MM-DD.xlsx or DM_YYYY-MM-DD.xlsx
-	filenamePattern := regexp.MustCompile(`^(dm|DM)[_-](\d{4}-\d{2}-\d{2})\.(xlsx|xls)$`)
-
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
 		}
 
 		filename := entry.Name()
-		match := filenamePattern.FindStringSubmatch(filename)
+		match := dmFilenamePattern.FindStringSubmatch(filename)
 		if match == nil {
 			continue
 		}
@@ -285,7 +287,7 @@ func (a *DmAdapter) Discover(targetDate string) ([]types.DiscoveredFile, error)
 
 		// Parse date from filename
 		var lastModified *time.Time
-		if t, err := time.Parse("2006-01-02", fileDate); err == nil {
+		if t, err := clock.ParseDate(fileDate); err == nil {
 			lastModified = &t
 		}
 
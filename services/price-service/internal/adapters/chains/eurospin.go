@@ -13,14 +13,20 @@ import (
 	"github.com/kosarica/price-service/internal/adapters/config"
 	zipexpand "github.com/kosarica/price-service/internal/ingestion/zip"
 	"github.com/kosarica/price-service/internal/parsers/csv"
+	"github.com/kosarica/price-service/internal/pkg/clock"
 	"github.com/kosarica/price-service/internal/types"
-	"github.com/rs/zerolog/log"
+)
+
+var (
+	eurospinOptionPattern = regexp.MustCompile(`(?i)<option[^>]*value=["']([^"']*cjenik_[^"']*\.zip)["'][^>]*>([^<]*)</option>`)
+	eurospinDatePattern   = regexp.MustCompile(`cjenik_(\d{2})\.(\d{2})\.(\d{4})`)
 )
 
 // eurospinColumnMapping is the primary column mapping for Eurospin CSV files
 var eurospinColumnMapping = csv.CsvColumnMapping{
 	ExternalID:          types.StringPtr("ŠIFRA_PROIZVODA"),
 	Name:                "NAZIV_PROIZVODA",
+	NameIt:              types.StringPtr("NAZIV_PROIZVODA_IT"),
 	Category:            types.StringPtr("KATEGORIJA_PROIZVODA"),
 	Brand:               types.StringPtr("MARKA_PROIZVODA"),
 	Unit:                types.StringPtr("JEDINICA_MJERE"),
@@ -42,6 +48,7 @@ var eurospinColumnMapping = csv.CsvColumnMapping{
 var eurospinColumnMappingAlt = csv.CsvColumnMapping{
 	ExternalID:          types.StringPtr("SIFRA_PROIZVODA"),
 	Name:                "NAZIV_PROIZVODA",
+	NameIt:              types.StringPtr("NAZIV_IT"),
 	Category:            types.StringPtr("KATEGORIJA"),
 	Brand:               types.StringPtr("MARKA"),
 	Unit:                types.StringPtr("JM"),
@@ -80,7 +87,7 @@ func NewEurospinAdapter() (*EurospinAdapter, error) {
 				`(?i)^cjenik[_-]?`,
 				`(?i)^diskontna[_-]?`,
 			},
-			FileExtensionPattern: regexp.MustCompile(`\.(csv|CSV|zip|ZIP)$`),
+			FileExtensionPattern: csvOrZipExtensionPattern,
 		},
 		ColumnMapping:            eurospinColumnMapping,
 		AlternativeColumnMapping: &eurospinColumnMappingAlt,
@@ -113,21 +120,21 @@ func (a *EurospinAdapter) Discover(targetDate string) ([]types.DiscoveredFile, e
 		date = a.discoveryDate
 	}
 	if date == "" {
-		date = time.Now().Format("2006-01-02")
+		date = clock.Today()
 	}
 
-	log.Debug().Str("date", date).Msg("Fetching Eurospin portal")
-	log.Debug().Str("url", a.BaseURL()).Msg("Using base URL")
+	logger.Debug().Str("date", date).Msg("Fetching Eurospin portal")
+	logger.Debug().Str("url", a.BaseURL()).Msg("Using base URL")
 
 	resp, err := a.HTTPClient().Get(a.BaseURL())
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to fetch Eurospin portal")
+		logger.Error().Err(err).Msg("Failed to fetch Eurospin portal")
 		return nil, fmt.Errorf("failed to fetch Eurospin portal: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		log.Error().Int("status", resp.StatusCode).Msg("Eurospin portal returned non-200 status")
+		logger.Error().Int("status", resp.StatusCode).Msg("Eurospin portal returned non-200 status")
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
@@ -139,9 +146,7 @@ func (a *EurospinAdapter) Discover(targetDate string) ([]types.DiscoveredFile, e
 	html := string(bodyBytes)
 
 	// Extract download links from dropdown: <option value="URL">filename</option>
-	optionPattern := regexp.MustCompile(`(?i)<option[^>]*value=["']([^"']*cjenik_[^"']*\.zip)["'][^>]*>([^<]*)</option>`)
-
-	matches := optionPattern.FindAllStringSubmatch(html, -1)
+	matches := eurospinOptionPattern.FindAllStringSubmatch(html, -1)
 	for _, match := range matches {
 		if len(match) < 3 {
 			continue
@@ -174,7 +179,7 @@ func (a *EurospinAdapter) Discover(targetDate string) ([]types.DiscoveredFile, e
 
 		var lastModified *time.Time
 		if fileDate != "" {
-			if t, err := time.Parse("2006-01-02", fileDate); err == nil {
+			if t, err := clock.ParseDate(fileDate); err == nil {
 				lastModified = &t
 			}
 		}
@@ -193,7 +198,7 @@ func (a *EurospinAdapter) Discover(targetDate string) ([]types.DiscoveredFile, e
 		})
 	}
 
-	log.Debug().Int("count", len(discoveredFiles)).Str("date", date).Msg("Found files for date")
+	logger.Debug().Int("count", len(discoveredFiles)).Str("date", date).Msg("Found files for date")
 	return discoveredFiles, nil
 }
 
@@ -235,7 +240,7 @@ func (a *EurospinAdapter) extractFilenameFromURL(fileURL string) string {
 // extractDateFromFilename extracts date from Eurospin filename
 // Format: cjenik_DD.MM.YYYY-7.30.zip -> YYYY-MM-DD
 func (a *EurospinAdapter) extractDateFromFilename(filename string) string {
-	match := regexp.MustCompile(`cjenik_(\d{2})\.(\d{2})\.(\d{4})`).FindStringSubmatch(filename)
+	match := eurospinDatePattern.FindStringSubmatch(filename)
 	if len(match) >= 4 {
 		day := match[1]
 		month := match[2]
@@ -260,14 +265,14 @@ func (a *EurospinAdapter) ExpandZIP(ctx context.Context, content []byte, filenam
 		}
 	}
 
-	log.Debug().Int("count", len(csvFiles)).Str("filename", filename).Msg("Expanded CSV files from ZIP")
+	logger.Debug().Int("count", len(csvFiles)).Str("filename", filename).Msg("Expanded CSV files from ZIP")
 	return csvFiles, nil
 }
 
 // ExtractStoreMetadata extracts store metadata from Eurospin filename
 // Pattern: {type}-{storeId}-{address}-{city}-{postal}-{code}-{date}-{time}.csv
 func (a *EurospinAdapter) ExtractStoreMetadata(file types.DiscoveredFile) *types.StoreMetadata {
-	baseName := regexp.MustCompile(`\.(csv|CSV)$`).ReplaceAllString(file.Filename, "")
+	baseName := csvExtensionPattern.ReplaceAllString(file.Filename, "")
 	parts := strings.Split(baseName, "-")
 	if len(parts) < 5 {
 		storeID := a.ExtractStoreIdentifierFromFilename(file.Filename)
@@ -303,7 +308,7 @@ func (a *EurospinAdapter) ExtractStoreMetadata(file types.DiscoveredFile) *types
 
 // ExtractStoreIdentifierFromFilename extracts store identifier from Eurospin filename
 func (a *EurospinAdapter) ExtractStoreIdentifierFromFilename(filename string) string {
-	baseName := regexp.MustCompile(`\.(csv|CSV)$`).ReplaceAllString(filename, "")
+	baseName := csvExtensionPattern.ReplaceAllString(filename, "")
 	parts := strings.Split(baseName, "-")
 	if len(parts) >= 2 {
 		return parts[1]
@@ -7,14 +7,18 @@ import (
 	"regexp"
 	"strings"
 	"time"
-
-	"github.com/rs/zerolog/log"
 	"github.com/kosarica/price-service/internal/adapters/base"
 	"github.com/kosarica/price-service/internal/adapters/config"
 	"github.com/kosarica/price-service/internal/parsers/csv"
+	"github.com/kosarica/price-service/internal/pkg/clock"
 	"github.com/kosarica/price-service/internal/types"
 )
 
+var (
+	intersparStoreCodePattern = regexp.MustCompile(`[_-](\d{4})[_-]`)
+	intersparLocationPattern  = regexp.MustCompile(`(?i)^(?:Interspar|Spar)[_-]?(.+?)(?:[_-]\d{4}[_-]\d{2}[_-]\d{2})?$`)
+)
+
 // intersparJsonFile represents a file entry from Interspar's JSON API
 type intersparJsonFile struct {
 	Name string `json:"name"`
@@ -116,7 +120,7 @@ func (a *IntersparAdapter) Discover(targetDate string) ([]types.DiscoveredFile,
 		date = a.discoveryDate
 	}
 	if date == "" {
-		date = time.Now().Format("2006-01-02")
+		date = clock.Today()
 	}
 
 	// Convert date from YYYY-MM-DD to YYYYMMDD format for the API
@@ -124,17 +128,17 @@ func (a *IntersparAdapter) Discover(targetDate string) ([]types.DiscoveredFile,
 
 	// Construct the JSON API URL
 	apiUrl := fmt.Sprintf("https://www.spar.hr/datoteke_cjenici/Cjenik%s.json", dateForApi)
-	log.Debug().Str("url", apiUrl).Msg("Fetching Interspar JSON API")
+	logger.Debug().Str("url", apiUrl).Msg("Fetching Interspar JSON API")
 
 	resp, err := a.HTTPClient().Get(apiUrl)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to fetch Interspar JSON API")
+		logger.Error().Err(err).Msg("Failed to fetch Interspar JSON API")
 		return nil, fmt.Errorf("failed to fetch Interspar JSON API: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		log.Error().Int("status_code", resp.StatusCode).Msg("Interspar JSON API returned error status")
+		logger.Error().Int("status_code", resp.StatusCode).Msg("Interspar JSON API returned error status")
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
@@ -149,13 +153,13 @@ func (a *IntersparAdapter) Discover(targetDate string) ([]types.DiscoveredFile,
 	}
 
 	if len(data.Files) == 0 {
-		log.Debug().Str("date", date).Msg("No files found in JSON response")
+		logger.Debug().Str("date", date).Msg("No files found in JSON response")
 		return discoveredFiles, nil
 	}
 
-	log.Debug().Int("file_count", len(data.Files)).Msg("Found files in JSON response")
+	logger.Debug().Int("file_count", len(data.Files)).Msg("Found files in JSON response")
 
-	lastModified, _ := time.Parse("2006-01-02", date)
+	lastModified, _ := clock.ParseDate(date)
 	for _, file := range data.Files {
 		discoveredFiles = append(discoveredFiles, types.DiscoveredFile{
 			URL:          file.URL,
@@ -177,16 +181,16 @@ func (a *IntersparAdapter) Discover(targetDate string) ([]types.DiscoveredFile,
 
 // ExtractStoreIdentifierFromFilename extracts store identifier from Interspar filename
 func (a *IntersparAdapter) ExtractStoreIdentifierFromFilename(filename string) string {
-	baseName := regexp.MustCompile(`\.(csv|CSV)$`).ReplaceAllString(filename, "")
+	baseName := csvExtensionPattern.ReplaceAllString(filename, "")
 
 	// Try to extract 4-digit store code
-	match := regexp.MustCompile(`[_-](\d{4})[_-]`).FindStringSubmatch(baseName)
+	match := intersparStoreCodePattern.FindStringSubmatch(baseName)
 	if len(match) >= 2 {
 		return match[1]
 	}
 
 	// Try to extract location name after Interspar prefix
-	locationMatch := regexp.MustCompile(`(?i)^(?:Interspar|Spar)[_-]?(.+?)(?:[_-]\d{4}[_-]\d{2}[_-]\d{2})?$`).FindStringSubmatch(baseName)
+	locationMatch := intersparLocationPattern.FindStringSubmatch(baseName)
 	if len(locationMatch) >= 2 {
 		return locationMatch[1]
 	}
@@ -202,7 +206,7 @@ func (a *IntersparAdapter) ExtractStoreIdentifierFromFilename(filename string) s
 // ExtractStoreMetadata extracts store metadata from Interspar filename
 // Pattern: {type}_{city}_{address...}_{storeId}_interspar_{city}_{code}_{date}_{time}.csv
 func (a *IntersparAdapter) ExtractStoreMetadata(file types.DiscoveredFile) *types.StoreMetadata {
-	baseName := regexp.MustCompile(`\.(csv|CSV)$`).ReplaceAllString(file.Filename, "")
+	baseName := csvExtensionPattern.ReplaceAllString(file.Filename, "")
 	parts := strings.Split(baseName, "_")
 	if len(parts) < 8 {
 		storeID := a.ExtractStoreIdentifierFromFilename(file.Filename)
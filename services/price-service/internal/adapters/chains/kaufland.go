@@ -11,8 +11,14 @@ import (
 	"github.com/kosarica/price-service/internal/adapters/base"
 	"github.com/kosarica/price-service/internal/adapters/config"
 	"github.com/kosarica/price-service/internal/parsers/csv"
+	"github.com/kosarica/price-service/internal/pkg/clock"
 	"github.com/kosarica/price-service/internal/types"
-	"github.com/rs/zerolog/log"
+)
+
+var (
+	kauflandDatePattern              = regexp.MustCompile(`_(\d{8})_`)
+	kauflandStoreCodePattern         = regexp.MustCompile(`_(\d{4})_\d{8}_`)
+	kauflandFallbackStoreCodePattern = regexp.MustCompile(`_(\d{4})_`)
 )
 
 // kauflandAsset represents a single asset from Kaufland's JSON API
@@ -115,7 +121,7 @@ func (a *KauflandAdapter) Discover(targetDate string) ([]types.DiscoveredFile, e
 		date = a.discoveryDate
 	}
 	if date == "" {
-		date = time.Now().Format("2006-01-02")
+		date = clock.Today()
 	}
 
 	// Convert YYYY-MM-DD to DDMMYYYY format used in Kaufland filenames
@@ -125,14 +131,14 @@ func (a *KauflandAdapter) Discover(targetDate string) ([]types.DiscoveredFile, e
 	}
 	targetDatePattern := fmt.Sprintf("%s%s%s", parts[2], parts[1], parts[0]) // DDMMYYYY
 
-	log.Debug().
+	logger.Debug().
 		Str("url", kauflandAssetAPIURL).
 		Str("date_pattern", targetDatePattern).
 		Msg("Fetching Kaufland asset API")
 
 	resp, err := a.HTTPClient().Get(kauflandAssetAPIURL)
 	if err != nil {
-		log.Error().
+		logger.Error().
 			Err(err).
 			Str("url", kauflandAssetAPIURL).
 			Msg("Failed to fetch Kaufland asset API")
@@ -141,7 +147,7 @@ func (a *KauflandAdapter) Discover(targetDate string) ([]types.DiscoveredFile, e
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		log.Error().
+		logger.Error().
 			Int("status_code", resp.StatusCode).
 			Str("url", kauflandAssetAPIURL).
 			Msg("Kaufland asset API returned non-200 status")
@@ -163,7 +169,7 @@ func (a *KauflandAdapter) Discover(targetDate string) ([]types.DiscoveredFile, e
 		path := asset.Path
 
 		// Extract date from filename (format: ..._{DDMMYYYY}_...)
-		dateMatch := regexp.MustCompile(`_(\d{8})_`).FindStringSubmatch(filename)
+		dateMatch := kauflandDatePattern.FindStringSubmatch(filename)
 		if len(dateMatch) < 2 {
 			continue
 		}
@@ -185,7 +191,7 @@ func (a *KauflandAdapter) Discover(targetDate string) ([]types.DiscoveredFile, e
 		seenURLs[fileURL] = true
 
 		var lastModified *time.Time
-		if t, err := time.Parse("2006-01-02", date); err == nil {
+		if t, err := clock.ParseDate(date); err == nil {
 			lastModified = &t
 		}
 
@@ -205,12 +211,12 @@ func (a *KauflandAdapter) Discover(targetDate string) ([]types.DiscoveredFile, e
 	}
 
 	if len(discoveredFiles) == 0 {
-		log.Debug().
+		logger.Debug().
 			Str("date", date).
 			Str("pattern", targetDatePattern).
 			Msg("No CSV files found for date")
 	} else {
-		log.Debug().
+		logger.Debug().
 			Int("file_count", len(discoveredFiles)).
 			Str("date", date).
 			Msg("Found CSV files for date")
@@ -223,13 +229,13 @@ func (a *KauflandAdapter) Discover(targetDate string) ([]types.DiscoveredFile, e
 // Pattern: {StoreType}_{Address}_{City}_{StoreId}_{DDMMYYYY}_{Version}.csv
 func (a *KauflandAdapter) ExtractStoreIdentifierFromFilename(filename string) string {
 	// Match 4-digit store code before date pattern: _{NNNN}_{DDMMYYYY}
-	match := regexp.MustCompile(`_(\d{4})_\d{8}_`).FindStringSubmatch(filename)
+	match := kauflandStoreCodePattern.FindStringSubmatch(filename)
 	if len(match) >= 2 {
 		return match[1]
 	}
 
 	// Fallback: try to find any 4-digit sequence
-	fallbackMatch := regexp.MustCompile(`_(\d{4})_`).FindStringSubmatch(filename)
+	fallbackMatch := kauflandFallbackStoreCodePattern.FindStringSubmatch(filename)
 	if len(fallbackMatch) >= 2 {
 		return fallbackMatch[1]
 	}
@@ -245,7 +251,7 @@ func (a *KauflandAdapter) ExtractStoreIdentifierFromFilename(filename string) st
 // ExtractStoreMetadata extracts store metadata from Kaufland filename
 // Pattern: {StoreType}_{Address...}_{PostalCode}_{City}_{StoreId}_{DATE}_{Ver}.csv
 func (a *KauflandAdapter) ExtractStoreMetadata(file types.DiscoveredFile) *types.StoreMetadata {
-	baseName := regexp.MustCompile(`\.(csv|CSV)$`).ReplaceAllString(file.Filename, "")
+	baseName := csvExtensionPattern.ReplaceAllString(file.Filename, "")
 	parts := strings.Split(baseName, "_")
 	if len(parts) < 6 {
 		storeID := a.ExtractStoreIdentifierFromFilename(file.Filename)
@@ -259,7 +265,7 @@ func (a *KauflandAdapter) ExtractStoreMetadata(file types.DiscoveredFile) *types
 	// Find postal code (5-digit pattern) working backwards
 	postalIdx := -1
 	for i := len(parts) - 4; i > 0; i-- {
-		if regexp.MustCompile(`^\d{5}$`).MatchString(parts[i]) {
+		if postalCodePattern.MatchString(parts[i]) {
 			postalIdx = i
 			break
 		}
@@ -8,14 +8,20 @@ import (
 	"strings"
 	"time"
 
-	"github.com/rs/zerolog/log"
-
 	"github.com/kosarica/price-service/internal/adapters/base"
 	"github.com/kosarica/price-service/internal/adapters/config"
 	"github.com/kosarica/price-service/internal/parsers/csv"
+	"github.com/kosarica/price-service/internal/pkg/clock"
 	"github.com/kosarica/price-service/internal/types"
 )
 
+var (
+	konzumDownloadPattern          = regexp.MustCompile(`href=["'](\/cjenici\/download\?title=([^"'&]+)[^"']*)["']`)
+	konzumStoreCodePattern         = regexp.MustCompile(`,(\d{4}),`)
+	konzumFallbackStoreCodePattern = regexp.MustCompile(`\b(\d{4})\b`)
+	konzumPostalCodePattern        = regexp.MustCompile(`(\d{5})`)
+)
+
 // konzumColumnMapping is the primary column mapping for Konzum CSV files (Croatian headers)
 var konzumColumnMapping = csv.CsvColumnMapping{
 	ExternalID:          types.StringPtr("ŠIFRA PROIZVODA"),
@@ -91,7 +97,7 @@ func (a *KonzumAdapter) Discover(targetDate string) ([]types.DiscoveredFile, err
 	// Use provided date or default to today
 	date := targetDate
 	if date == "" {
-		date = time.Now().Format("2006-01-02")
+		date = clock.Today()
 	}
 
 	// Maximum pages to crawl (safety limit)
@@ -99,11 +105,11 @@ func (a *KonzumAdapter) Discover(targetDate string) ([]types.DiscoveredFile, err
 
 	for page := 1; page <= maxPages; page++ {
 		pageURL := fmt.Sprintf("%s?date=%s&page=%d", a.BaseURL(), date, page)
-		log.Debug().Int("page", page).Str("url", pageURL).Msg("Fetching Konzum page")
+		logger.Debug().Int("page", page).Str("url", pageURL).Msg("Fetching Konzum page")
 
 		files, err := a.discoverPage(pageURL, seenURLs, date, page)
 		if err != nil {
-			log.Error().Int("page", page).Err(err).Msg("Failed to fetch page")
+			logger.Error().Int("page", page).Err(err).Msg("Failed to fetch page")
 			break
 		}
 
@@ -138,9 +144,7 @@ func (a *KonzumAdapter) discoverPage(pageURL string, seenURLs map[string]bool, d
 	body := string(bodyBytes)
 
 	// Extract download links: href="/cjenici/download?title=..."
-	downloadPattern := regexp.MustCompile(`href=["'](\/cjenici\/download\?title=([^"'&]+)[^"']*)["']`)
-
-	matches := downloadPattern.FindAllStringSubmatch(body, -1)
+	matches := konzumDownloadPattern.FindAllStringSubmatch(body, -1)
 	files := make([]types.DiscoveredFile, 0)
 
 	for _, match := range matches {
@@ -221,13 +225,13 @@ func (a *KonzumAdapter) decodeFilename(encoded string) string {
 // Store ID is a 4-digit code (e.g., 0204)
 func (a *KonzumAdapter) ExtractStoreIdentifierFromFilename(filename string) string {
 	// Match 4-digit store code pattern: ,NNNN,
-	match := regexp.MustCompile(`,(\d{4}),`).FindStringSubmatch(filename)
+	match := konzumStoreCodePattern.FindStringSubmatch(filename)
 	if len(match) >= 2 {
 		return match[1]
 	}
 
 	// Fallback: try to find any 4-digit sequence
-	match = regexp.MustCompile(`\b(\d{4})\b`).FindStringSubmatch(filename)
+	match = konzumFallbackStoreCodePattern.FindStringSubmatch(filename)
 	if len(match) >= 2 {
 		return match[1]
 	}
@@ -260,7 +264,7 @@ func (a *KonzumAdapter) ExtractStoreMetadata(file types.DiscoveredFile) *types.S
 	decodedAddress := strings.ReplaceAll(addressPart, "+", " ")
 
 	// Try to extract postal code (5-digit number)
-	postalMatch := regexp.MustCompile(`(\d{5})`).FindStringSubmatch(decodedAddress)
+	postalMatch := konzumPostalCodePattern.FindStringSubmatch(decodedAddress)
 	var address, city, postalCode string
 
 	if len(postalMatch) >= 2 {
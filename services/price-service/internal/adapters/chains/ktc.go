@@ -7,14 +7,22 @@ import (
 	"regexp"
 	"strings"
 	"time"
-
-	"github.com/rs/zerolog/log"
 	"github.com/kosarica/price-service/internal/adapters/base"
 	"github.com/kosarica/price-service/internal/adapters/config"
 	"github.com/kosarica/price-service/internal/parsers/csv"
+	"github.com/kosarica/price-service/internal/pkg/clock"
 	"github.com/kosarica/price-service/internal/types"
 )
 
+var (
+	ktcDatePattern            = regexp.MustCompile(`(\d{4})(\d{2})(\d{2})-\d{6}\.csv$`)
+	ktcStorePattern           = regexp.MustCompile(`poslovnica=([^"&]+)`)
+	ktcCSVLinkPattern         = regexp.MustCompile(`href="([^"]*\.csv)"`)
+	ktcStoreCodePattern       = regexp.MustCompile(`(PJ[\dA-Z]+-\d+)-\d{8}-\d{6}\.csv$`)
+	ktcSimpleStoreCodePattern = regexp.MustCompile(`(PJ[\dA-Z]+)-\d+-\d{8}`)
+	ktcStoreNamePattern       = regexp.MustCompile(`^TRGOVINA-(.+?)-(PJ[\dA-Z]+-\d+)-`)
+)
+
 // ktcColumnMapping is the primary column mapping for KTC CSV files
 var ktcColumnMapping = csv.CsvColumnMapping{
 	ExternalID:     types.StringPtr("Šifra proizvoda"),
@@ -90,7 +98,7 @@ func (a *KtcAdapter) SetDiscoveryDate(date string) {
 // Pattern: YYYYMMDD-HHMMSS at end of filename
 func (a *KtcAdapter) extractDateFromFilename(filename string) string {
 	// Match 8-digit date pattern (YYYYMMDD) before 6-digit time
-	match := regexp.MustCompile(`(\d{4})(\d{2})(\d{2})-\d{6}\.csv$`).FindStringSubmatch(filename)
+	match := ktcDatePattern.FindStringSubmatch(filename)
 	if len(match) >= 4 {
 		return fmt.Sprintf("%s-%s-%s", match[1], match[2], match[3])
 	}
@@ -103,18 +111,18 @@ func (a *KtcAdapter) Discover(targetDate string) ([]types.DiscoveredFile, error)
 	discoveredFiles := make([]types.DiscoveredFile, 0)
 	seenURLs := make(map[string]bool)
 
-	log.Debug().Str("chain", a.Name()).Str("portal", a.BaseURL()).Msg("Fetching portal")
+	logger.Debug().Str("chain", a.Name()).Str("portal", a.BaseURL()).Msg("Fetching portal")
 
 	// Fetch main page to get list of stores
 	resp, err := a.HTTPClient().Get(a.BaseURL())
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to fetch KTC portal")
+		logger.Error().Err(err).Msg("Failed to fetch KTC portal")
 		return nil, fmt.Errorf("failed to fetch KTC portal: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		log.Error().Int("status_code", resp.StatusCode).Msg("KTC portal returned unexpected status")
+		logger.Error().Int("status_code", resp.StatusCode).Msg("KTC portal returned unexpected status")
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
@@ -126,8 +134,7 @@ func (a *KtcAdapter) Discover(targetDate string) ([]types.DiscoveredFile, error)
 	html := string(bodyBytes)
 
 	// Extract store names from links like: ?poslovnica=RC%20BJELOVAR%20PJ-50
-	storePattern := regexp.MustCompile(`poslovnica=([^"&]+)`)
-	matches := storePattern.FindAllStringSubmatch(html, -1)
+	matches := ktcStorePattern.FindAllStringSubmatch(html, -1)
 
 	stores := make([]string, 0)
 	seenStores := make(map[string]bool)
@@ -141,7 +148,7 @@ func (a *KtcAdapter) Discover(targetDate string) ([]types.DiscoveredFile, error)
 		}
 	}
 
-	log.Debug().Int("store_count", len(stores)).Msg("Found stores on KTC portal")
+	logger.Debug().Int("store_count", len(stores)).Msg("Found stores on KTC portal")
 
 	// For each store, fetch store page and extract CSV links
 	for _, storeName := range stores {
@@ -149,13 +156,13 @@ func (a *KtcAdapter) Discover(targetDate string) ([]types.DiscoveredFile, error)
 
 		storeResp, err := a.HTTPClient().Get(storeURL)
 		if err != nil {
-			log.Warn().Str("store", storeName).Err(err).Msg("Failed to fetch store page")
+			logger.Warn().Str("store", storeName).Err(err).Msg("Failed to fetch store page")
 			continue
 		}
 		defer storeResp.Body.Close()
 
 		if storeResp.StatusCode != 200 {
-			log.Warn().Str("store", storeName).Int("status_code", storeResp.StatusCode).Msg("Store page returned unexpected status")
+			logger.Warn().Str("store", storeName).Int("status_code", storeResp.StatusCode).Msg("Store page returned unexpected status")
 			continue
 		}
 
@@ -167,8 +174,7 @@ func (a *KtcAdapter) Discover(targetDate string) ([]types.DiscoveredFile, error)
 		storeHtml := string(storeBodyBytes)
 
 		// Extract CSV links like: /ktcftp/Cjenici/STORE_NAME/FILENAME.csv
-		csvPattern := regexp.MustCompile(`href="([^"]*\.csv)"`)
-		csvMatches := csvPattern.FindAllStringSubmatch(storeHtml, -1)
+		csvMatches := ktcCSVLinkPattern.FindAllStringSubmatch(storeHtml, -1)
 
 		for _, csvMatch := range csvMatches {
 			if len(csvMatch) < 2 {
@@ -197,7 +203,7 @@ func (a *KtcAdapter) Discover(targetDate string) ([]types.DiscoveredFile, error)
 
 			var lastModified *time.Time
 			if fileDate != "" {
-				if t, err := time.Parse("2006-01-02", fileDate); err == nil {
+				if t, err := clock.ParseDate(fileDate); err == nil {
 					lastModified = &t
 				}
 			}
@@ -242,13 +248,13 @@ func (a *KtcAdapter) extractFilenameFromURL(fileURL string) string {
 // Store IDs like: PJ50-1, PJ7B-1, PJ8A-1
 func (a *KtcAdapter) ExtractStoreIdentifierFromFilename(filename string) string {
 	// Try to match PJ followed by alphanumeric + dash + digit before date
-	match := regexp.MustCompile(`(PJ[\dA-Z]+-\d+)-\d{8}-\d{6}\.csv$`).FindStringSubmatch(filename)
+	match := ktcStoreCodePattern.FindStringSubmatch(filename)
 	if len(match) >= 2 {
 		return match[1]
 	}
 
 	// Try simpler pattern: PJ followed by alphanumeric
-	simpleMatch := regexp.MustCompile(`(PJ[\dA-Z]+)-\d+-\d{8}`).FindStringSubmatch(filename)
+	simpleMatch := ktcSimpleStoreCodePattern.FindStringSubmatch(filename)
 	if len(simpleMatch) >= 2 {
 		return simpleMatch[1]
 	}
@@ -264,10 +270,10 @@ func (a *KtcAdapter) ExtractStoreIdentifierFromFilename(filename string) string
 // ExtractStoreMetadata extracts store metadata from KTC filename
 // Pattern: TRGOVINA-ADDRESS-STORE_ID-DATE-TIME.csv
 func (a *KtcAdapter) ExtractStoreMetadata(file types.DiscoveredFile) *types.StoreMetadata {
-	baseName := regexp.MustCompile(`\.(csv|CSV)$`).ReplaceAllString(file.Filename, "")
+	baseName := csvExtensionPattern.ReplaceAllString(file.Filename, "")
 
 	// Extract address between TRGOVINA- and -PJ
-	match := regexp.MustCompile(`^TRGOVINA-(.+?)-(PJ[\dA-Z]+-\d+)-`).FindStringSubmatch(baseName)
+	match := ktcStoreNamePattern.FindStringSubmatch(baseName)
 	if len(match) == 0 {
 		storeID := a.ExtractStoreIdentifierFromFilename(file.Filename)
 		return &types.StoreMetadata{
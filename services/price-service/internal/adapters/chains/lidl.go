@@ -7,15 +7,24 @@ import (
 	"regexp"
 	"strings"
 	"time"
-
-	"github.com/rs/zerolog/log"
 	"github.com/kosarica/price-service/internal/adapters/base"
 	"github.com/kosarica/price-service/internal/adapters/config"
 	zipexpand "github.com/kosarica/price-service/internal/ingestion/zip"
 	"github.com/kosarica/price-service/internal/parsers/csv"
+	"github.com/kosarica/price-service/internal/pkg/clock"
 	"github.com/kosarica/price-service/internal/types"
 )
 
+var (
+	lidlDownloadPattern         = regexp.MustCompile(`href=["'](https://tvrtka\.lidl\.hr/content/download/\d+/fileupload/([^"']+\.zip))["']`)
+	lidlRelativeDownloadPattern = regexp.MustCompile(`href=["'](/content/download/\d+/fileupload/([^"']+\.zip))["']`)
+	lidlDatePattern             = regexp.MustCompile(`(\d{2})_(\d{2})_(\d{4})\.zip$`)
+	lidlBarcodeSeparatorPattern = regexp.MustCompile(`[;|]`)
+	lidlDateStorePattern        = regexp.MustCompile(`(?i)^Lidl[_-]?\d{4}[_-]\d{2}[_-]\d{2}[_-](.+)$`)
+	lidlLocationPattern         = regexp.MustCompile(`(?i)^Lidl[_-]?Poslovnica[_-]?(.+)$`)
+	lidlStoreNumberPattern      = regexp.MustCompile(`(?i)^Lidl[_-]?(\d+)$`)
+)
+
 // lidlColumnMapping is the primary column mapping for Lidl CSV files (2026 format)
 // Maps Lidl's Croatian column names to NormalizedRow fields
 var lidlColumnMapping = csv.CsvColumnMapping{
@@ -70,7 +79,7 @@ func NewLidlAdapter() (*LidlAdapter, error) {
 				`(?i)^cjenik[_-]?`,
 				`^\d{4}[_-]\d{2}[_-]\d{2}[_-]?`, // Remove date prefix
 			},
-			FileExtensionPattern: regexp.MustCompile(`\.(csv|CSV|zip|ZIP)$`),
+			FileExtensionPattern: csvOrZipExtensionPattern,
 		},
 		ColumnMapping:            lidlColumnMapping,
 		AlternativeColumnMapping: &lidlColumnMappingAlt,
@@ -104,17 +113,17 @@ func (a *LidlAdapter) Discover(targetDate string) ([]types.DiscoveredFile, error
 		filterDate = a.discoveryDate
 	}
 
-	log.Debug().Str("url", a.BaseURL()).Msg("Fetching Lidl portal")
+	logger.Debug().Str("url", a.BaseURL()).Msg("Fetching Lidl portal")
 
 	resp, err := a.HTTPClient().Get(a.BaseURL())
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to fetch Lidl portal")
+		logger.Error().Err(err).Msg("Failed to fetch Lidl portal")
 		return nil, fmt.Errorf("failed to fetch Lidl portal: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		log.Error().Int("status_code", resp.StatusCode).Msg("Lidl portal returned error status")
+		logger.Error().Int("status_code", resp.StatusCode).Msg("Lidl portal returned error status")
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
@@ -127,9 +136,7 @@ func (a *LidlAdapter) Discover(targetDate string) ([]types.DiscoveredFile, error
 
 	// Extract download links matching Lidl's URL pattern
 	// Pattern: href="(https://tvrtka.lidl.hr/content/download/\d+/fileupload/[^"]+\.zip)"
-	downloadPattern := regexp.MustCompile(`href=["'](https://tvrtka\.lidl\.hr/content/download/\d+/fileupload/([^"']+\.zip))["']`)
-
-	matches := downloadPattern.FindAllStringSubmatch(html, -1)
+	matches := lidlDownloadPattern.FindAllStringSubmatch(html, -1)
 	for _, match := range matches {
 		if len(match) < 3 {
 			continue
@@ -154,7 +161,7 @@ func (a *LidlAdapter) Discover(targetDate string) ([]types.DiscoveredFile, error
 
 		var lastModified *time.Time
 		if fileDate != "" {
-			if t, err := time.Parse("2006-01-02", fileDate); err == nil {
+			if t, err := clock.ParseDate(fileDate); err == nil {
 				lastModified = &t
 			}
 		}
@@ -175,9 +182,7 @@ func (a *LidlAdapter) Discover(targetDate string) ([]types.DiscoveredFile, error
 
 	// If no files found with absolute URLs, try relative URL pattern
 	if len(discoveredFiles) == 0 {
-		relativePattern := regexp.MustCompile(`href=["'](/content/download/\d+/fileupload/([^"']+\.zip))["']`)
-
-		matches = relativePattern.FindAllStringSubmatch(html, -1)
+		matches = lidlRelativeDownloadPattern.FindAllStringSubmatch(html, -1)
 		for _, match := range matches {
 			if len(match) < 3 {
 				continue
@@ -202,7 +207,7 @@ func (a *LidlAdapter) Discover(targetDate string) ([]types.DiscoveredFile, error
 
 			var lastModified *time.Time
 			if fileDate != "" {
-				if t, err := time.Parse("2006-01-02", fileDate); err == nil {
+				if t, err := clock.ParseDate(fileDate); err == nil {
 					lastModified = &t
 				}
 			}
@@ -222,14 +227,14 @@ func (a *LidlAdapter) Discover(targetDate string) ([]types.DiscoveredFile, error
 		}
 	}
 
-	log.Debug().Int("file_count", len(discoveredFiles)).Msg("Discovered files from Lidl portal")
+	logger.Debug().Int("file_count", len(discoveredFiles)).Msg("Discovered files from Lidl portal")
 	return discoveredFiles, nil
 }
 
 // extractDateFromFilename extracts date from Lidl filename (DD_MM_YYYY) to YYYY-MM-DD format
 func (a *LidlAdapter) extractDateFromFilename(filename string) string {
 	// Pattern: Popis_cijena_po_trgovinama_na_dan_DD_MM_YYYY.zip
-	match := regexp.MustCompile(`(\d{2})_(\d{2})_(\d{4})\.zip$`).FindStringSubmatch(filename)
+	match := lidlDatePattern.FindStringSubmatch(filename)
 	if len(match) >= 4 {
 		day := match[1]
 		month := match[2]
@@ -254,7 +259,7 @@ func (a *LidlAdapter) ExpandZIP(ctx context.Context, content []byte, filename st
 		}
 	}
 
-	log.Debug().Int("csv_count", len(csvFiles)).Str("filename", filename).Msg("Expanded CSV files from ZIP")
+	logger.Debug().Int("csv_count", len(csvFiles)).Str("filename", filename).Msg("Expanded CSV files from ZIP")
 	return csvFiles, nil
 }
 
@@ -292,7 +297,7 @@ func (a *LidlAdapter) postprocessMultipleGTINs(result *types.ParseResult) *types
 // splitGTINs splits a barcode string containing multiple GTINs
 func splitGTINs(barcode string) []string {
 	// Split on semicolon or pipe
-	parts := regexp.MustCompile(`[;|]`).Split(barcode, -1)
+	parts := lidlBarcodeSeparatorPattern.Split(barcode, -1)
 
 	gtins := make([]string, 0, len(parts))
 	for _, part := range parts {
@@ -309,22 +314,22 @@ func splitGTINs(barcode string) []string {
 // Lidl has special patterns for store identification
 func (a *LidlAdapter) ExtractStoreIdentifierFromFilename(filename string) string {
 	// Remove file extension
-	baseName := regexp.MustCompile(`\.(csv|CSV)$`).ReplaceAllString(filename, "")
+	baseName := csvExtensionPattern.ReplaceAllString(filename, "")
 
 	// Pattern 1: Lidl_DATE_STOREID (e.g., "Lidl_2024-01-15_42")
-	dateStoreMatch := regexp.MustCompile(`(?i)^Lidl[_-]?\d{4}[_-]\d{2}[_-]\d{2}[_-](.+)$`).FindStringSubmatch(baseName)
+	dateStoreMatch := lidlDateStorePattern.FindStringSubmatch(baseName)
 	if len(dateStoreMatch) >= 2 {
 		return dateStoreMatch[1]
 	}
 
 	// Pattern 2: Lidl_Poslovnica_LOCATION (e.g., "Lidl_Poslovnica_Zagreb_Ilica_123")
-	locationMatch := regexp.MustCompile(`(?i)^Lidl[_-]?Poslovnica[_-]?(.+)$`).FindStringSubmatch(baseName)
+	locationMatch := lidlLocationPattern.FindStringSubmatch(baseName)
 	if len(locationMatch) >= 2 {
 		return locationMatch[1]
 	}
 
 	// Pattern 3: Just Lidl_STOREID (e.g., "Lidl_42")
-	simpleMatch := regexp.MustCompile(`(?i)^Lidl[_-]?(\d+)$`).FindStringSubmatch(baseName)
+	simpleMatch := lidlStoreNumberPattern.FindStringSubmatch(baseName)
 	if len(simpleMatch) >= 2 {
 		return simpleMatch[1]
 	}
@@ -403,7 +408,7 @@ func isValidGTIN(barcode string) bool {
 // Example: Supermarket 265_Ulica Franje Glada_13_40323_Prelog_1_16.12.2025_7.15h.csv
 func (a *LidlAdapter) ExtractStoreMetadata(file types.DiscoveredFile) *types.StoreMetadata {
 	// Remove extension
-	baseName := regexp.MustCompile(`\.(csv|CSV)$`).ReplaceAllString(file.Filename, "")
+	baseName := csvExtensionPattern.ReplaceAllString(file.Filename, "")
 
 	// Split by underscore
 	parts := strings.Split(baseName, "_")
@@ -425,7 +430,7 @@ func (a *LidlAdapter) ExtractStoreMetadata(file types.DiscoveredFile) *types.Sto
 	// Find postal code (5 digits) to anchor the structure
 	postalIdx := -1
 	for i := 1; i < len(parts)-2; i++ {
-		if regexp.MustCompile(`^\d{5}$`).MatchString(parts[i]) {
+		if postalCodePattern.MatchString(parts[i]) {
 			postalIdx = i
 			break
 		}
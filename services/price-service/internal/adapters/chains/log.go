@@ -0,0 +1,6 @@
+package chains
+
+import "github.com/kosarica/price-service/internal/logging"
+
+// logger is shared by the chain adapters
+var logger = logging.For(logging.Adapters)
@@ -12,10 +12,18 @@ import (
 	"github.com/kosarica/price-service/internal/types"
 )
 
+var (
+	metroAnchorDatePattern  = regexp.MustCompile(`SIDRENA_\d{2}_\d{2}`)
+	metroTimestampPattern   = regexp.MustCompile(`METRO_(\d{4})(\d{2})(\d{2})T(\d{2})(\d{2})`)
+	metroStoreNumberPattern = regexp.MustCompile(`_S(\d+)_`)
+	metroStoreNamePattern   = regexp.MustCompile(`_S(\d+)_(.+)\.csv$`)
+)
+
 // metroColumnMapping is the primary column mapping for Metro CSV files
 var metroColumnMapping = csv.CsvColumnMapping{
 	ExternalID:     types.StringPtr("SIFRA"),
 	Name:           "NAZIV",
+	NameEn:         types.StringPtr("NAZIV_EN"),
 	Category:       types.StringPtr("KATEGORIJA"),
 	Brand:          types.StringPtr("MARKA"),
 	Unit:           types.StringPtr("JED_MJERE"),
@@ -33,6 +41,7 @@ var metroColumnMapping = csv.CsvColumnMapping{
 var metroColumnMappingAlt = csv.CsvColumnMapping{
 	ExternalID:     types.StringPtr("Šifra"),
 	Name:           "Naziv",
+	NameEn:         types.StringPtr("Naziv (EN)"),
 	Category:       types.StringPtr("Kategorija"),
 	Brand:          types.StringPtr("Marka"),
 	Unit:           types.StringPtr("Mjerna jedinica"),
@@ -96,7 +105,7 @@ func (a *MetroAdapter) preprocessCSVContent(content []byte) []byte {
 	text := string(content)
 
 	// Normalize SIDRENA_XX_XX to SIDRENA (date suffix varies)
-	text = regexp.MustCompile(`SIDRENA_\d{2}_\d{2}`).ReplaceAllString(text, "SIDRENA")
+	text = metroAnchorDatePattern.ReplaceAllString(text, "SIDRENA")
 
 	return []byte(text)
 }
@@ -104,7 +113,7 @@ func (a *MetroAdapter) preprocessCSVContent(content []byte) []byte {
 // extractDateFromFilename extracts date from Metro filename
 // Pattern: ..._METRO_YYYYMMDDTHHMM_...
 func (a *MetroAdapter) extractDateFromFilename(filename string) *time.Time {
-	match := regexp.MustCompile(`METRO_(\d{4})(\d{2})(\d{2})T(\d{2})(\d{2})`).FindStringSubmatch(filename)
+	match := metroTimestampPattern.FindStringSubmatch(filename)
 	if len(match) >= 6 {
 		year := match[1]
 		month := match[2]
@@ -121,7 +130,7 @@ func (a *MetroAdapter) extractDateFromFilename(filename string) *time.Time {
 
 // extractStoreCodeFromFilename extracts store code (S10, S11, etc.) from filename
 func (a *MetroAdapter) extractStoreCodeFromFilename(filename string) string {
-	match := regexp.MustCompile(`_S(\d+)_`).FindStringSubmatch(filename)
+	match := metroStoreNumberPattern.FindStringSubmatch(filename)
 	if len(match) >= 2 {
 		return "S" + match[1]
 	}
@@ -168,7 +177,7 @@ func (a *MetroAdapter) ExtractStoreIdentifierFromFilename(filename string) strin
 // Pattern: ..._METRO_YYYYMMDDTHHM_S{code}_{LOCATION},{CITY}.csv
 func (a *MetroAdapter) ExtractStoreMetadata(file types.DiscoveredFile) *types.StoreMetadata {
 	// Extract everything after S{code}_
-	match := regexp.MustCompile(`_S(\d+)_(.+)\.csv$`).FindStringSubmatch(file.Filename)
+	match := metroStoreNamePattern.FindStringSubmatch(file.Filename)
 	if len(match) == 0 {
 		storeID := a.ExtractStoreIdentifierFromFilename(file.Filename)
 		return &types.StoreMetadata{
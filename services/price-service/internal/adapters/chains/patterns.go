@@ -0,0 +1,16 @@
+package chains
+
+import "regexp"
+
+// Patterns shared by several chain adapters. Adapter-specific patterns live
+// in package-level variables next to the adapter that uses them.
+var (
+	csvExtensionPattern         = regexp.MustCompile(`\.(csv|CSV)$`)
+	csvOrZipExtensionPattern    = regexp.MustCompile(`\.(csv|CSV|zip|ZIP)$`)
+	xmlExtensionPattern         = regexp.MustCompile(`\.(xml|XML)$`)
+	jsonExtensionPattern        = regexp.MustCompile(`\.(json|JSON)$`)
+	xmlLinkPattern              = regexp.MustCompile(`href=["']([^"']*\.xml(?:\?[^"']*)?)["']`)
+	postalCodePattern           = regexp.MustCompile(`^\d{5}$`)
+	isoDateInFilenamePattern    = regexp.MustCompile(`(\d{4})-(\d{2})-(\d{2})`)
+	dashedDateInFilenamePattern = regexp.MustCompile(`(\d{2})-(\d{2})-(\d{4})`)
+)
@@ -8,15 +8,31 @@ import (
 	"strings"
 	"time"
 
-	"github.com/rs/zerolog/log"
-
 	"github.com/kosarica/price-service/internal/adapters/base"
 	"github.com/kosarica/price-service/internal/adapters/config"
 	zipexpand "github.com/kosarica/price-service/internal/ingestion/zip"
 	"github.com/kosarica/price-service/internal/parsers/csv"
+	"github.com/kosarica/price-service/internal/pkg/clock"
 	"github.com/kosarica/price-service/internal/types"
 )
 
+// plodineZipPatterns find ZIP links on the price list page, most specific first
+var plodineZipPatterns = []*regexp.Regexp{
+	// Primary: /cjenici/ path with full timestamp
+	regexp.MustCompile(`href=["'](https://[^"']*\/cjenici\/cjeniki_(\d{2}_\d{2}_\d{4})_\d{2}_\d{2}_\d{2}\.zip)["']`),
+	// Alternative: any path with cjenici_ prefix
+	regexp.MustCompile(`href=["'](https://[^"']*\/cjenici_(\d{2}_\d{2}_\d{4})_\d{2}_\d{2}_\d{2}\.zip)["']`),
+	// Relative URLs
+	regexp.MustCompile(`href=["']([^"']*cjenici_(\d{2}_\d{2}_\d{4})_\d{2}_\d{2}_\d{2}\.zip)["']`),
+}
+
+var (
+	plodineAnchorDatePattern        = regexp.MustCompile(`Sidrena cijena na \d+\.\d+\.\d+`)
+	plodineBareDecimalFieldPattern  = regexp.MustCompile(`;,(\d)`)
+	plodineBareDecimalLinePattern   = regexp.MustCompile(`^,(\d)`)
+	plodineBareDecimalQuotedPattern = regexp.MustCompile(`",(\d)`)
+)
+
 // plodineColumnMapping is the primary column mapping for Plodine CSV files
 var plodineColumnMapping = csv.CsvColumnMapping{
 	ExternalID:     types.StringPtr("Sifra proizvoda"),
@@ -76,7 +92,7 @@ func NewPlodineAdapter() (*PlodineAdapter, error) {
 				`(?i)^cjenik[_-]?`,
 				`(?i)^cjenici[_-]?`,
 			},
-			FileExtensionPattern: regexp.MustCompile(`\.(csv|CSV|zip|ZIP)$`),
+			FileExtensionPattern: csvOrZipExtensionPattern,
 		},
 		ColumnMapping:            plodineColumnMapping,
 		AlternativeColumnMapping: &plodineColumnMappingAlt,
@@ -109,7 +125,7 @@ func (a *PlodineAdapter) Discover(targetDate string) ([]types.DiscoveredFile, er
 		date = a.discoveryDate
 	}
 	if date == "" {
-		date = time.Now().Format("2006-01-02")
+		date = clock.Today()
 	}
 
 	// Convert YYYY-MM-DD to DD_MM_YYYY format used in Plodine filenames
@@ -119,18 +135,18 @@ func (a *PlodineAdapter) Discover(targetDate string) ([]types.DiscoveredFile, er
 	}
 	targetDatePattern := fmt.Sprintf("%s_%s_%s", parts[2], parts[1], parts[0])
 
-	log.Debug().Str("url", a.BaseURL()).Msg("Fetching Plodine page")
-	log.Debug().Str("datePattern", targetDatePattern).Msg("Looking for date pattern")
+	logger.Debug().Str("url", a.BaseURL()).Msg("Fetching Plodine page")
+	logger.Debug().Str("datePattern", targetDatePattern).Msg("Looking for date pattern")
 
 	resp, err := a.HTTPClient().Get(a.BaseURL())
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to fetch Plodine portal")
+		logger.Error().Err(err).Msg("Failed to fetch Plodine portal")
 		return nil, fmt.Errorf("failed to fetch Plodine portal: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		log.Error().Int("statusCode", resp.StatusCode).Msg("Plodine portal returned non-200 status")
+		logger.Error().Int("statusCode", resp.StatusCode).Msg("Plodine portal returned non-200 status")
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
@@ -142,16 +158,7 @@ func (a *PlodineAdapter) Discover(targetDate string) ([]types.DiscoveredFile, er
 	html := string(bodyBytes)
 
 	// Try multiple patterns to find ZIP files
-	patterns := []*regexp.Regexp{
-		// Primary: /cjenici/ path with full timestamp
-		regexp.MustCompile(`href=["'(https://[^"']*\/cjenici\/cjeniki_(\d{2}_\d{2}_\d{4})_\d{2}_\d{2}_\d{2}\.zip)["']`),
-		// Alternative: any path with cjenici_ prefix
-		regexp.MustCompile(`href=["'](https://[^"']*\/cjenici_(\d{2}_\d{2}_\d{4})_\d{2}_\d{2}_\d{2}\.zip)["']`),
-		// Relative URLs
-		regexp.MustCompile(`href["']([^"']*cjenici_(\d{2}_\d{2}_\d{4})_\d{2}_\d{2}_\d{2}\.zip)["']`),
-	}
-
-	for _, zipPattern := range patterns {
+	for _, zipPattern := range plodineZipPatterns {
 		matches := zipPattern.FindAllStringSubmatch(html, -1)
 		for _, match := range matches {
 			if len(match) < 3 {
@@ -180,7 +187,7 @@ func (a *PlodineAdapter) Discover(targetDate string) ([]types.DiscoveredFile, er
 
 			filename := fileURL[strings.LastIndex(fileURL, "/")+1:]
 
-			lastModified, _ := time.Parse("2006-01-02", date)
+			lastModified, _ := clock.ParseDate(date)
 
 			discoveredFiles = append(discoveredFiles, types.DiscoveredFile{
 				URL:          fileURL,
@@ -203,9 +210,9 @@ func (a *PlodineAdapter) Discover(targetDate string) ([]types.DiscoveredFile, er
 	}
 
 	if len(discoveredFiles) == 0 {
-		log.Debug().Str("date", date).Msg("No ZIP files found")
+		logger.Debug().Str("date", date).Msg("No ZIP files found")
 	} else {
-		log.Debug().Str("date", date).Int("count", len(discoveredFiles)).Msg("Found files")
+		logger.Debug().Str("date", date).Int("count", len(discoveredFiles)).Msg("Found files")
 	}
 
 	return discoveredFiles, nil
@@ -237,15 +244,15 @@ func (a *PlodineAdapter) preprocessCSVContent(content []byte) []byte {
 
 	// Normalize anchor price column header (remove the dynamic date suffix)
 	// "Sidrena cijena na 2.5.2025" -> "Sidrena cijena"
-	text = regexp.MustCompile(`Sidrena cijena na \d+\.\d+\.\d+`).ReplaceAllString(text, "Sidrena cijena")
+	text = plodineAnchorDatePattern.ReplaceAllString(text, "Sidrena cijena")
 
 	// Fix missing leading zeros in prices
 	// Pattern matches: semicolon followed by comma and digits (;,69) -> ;0,69
-	text = regexp.MustCompile(`;,(\d)`).ReplaceAllString(text, ";0,$1")
+	text = plodineBareDecimalFieldPattern.ReplaceAllString(text, ";0,$1")
 
 	// Also handle case where value might be at start or in quotes
-	text = regexp.MustCompile(`^,(\d)`).ReplaceAllString(text, "0,$1")
-	text = regexp.MustCompile(`",(\d)`).ReplaceAllString(text, `"0,$1`)
+	text = plodineBareDecimalLinePattern.ReplaceAllString(text, "0,$1")
+	text = plodineBareDecimalQuotedPattern.ReplaceAllString(text, `"0,$1`)
 
 	return []byte(text)
 }
@@ -262,7 +269,7 @@ func (a *PlodineAdapter) Parse(content []byte, filename string, options *types.P
 // ExtractStoreMetadata extracts store metadata from Plodine filename
 // Pattern: {type}_{address...}_{postal}_{city}_{storeId}_{seq}_{date}.csv
 func (a *PlodineAdapter) ExtractStoreMetadata(file types.DiscoveredFile) *types.StoreMetadata {
-	baseName := regexp.MustCompile(`\.(csv|CSV)$`).ReplaceAllString(file.Filename, "")
+	baseName := csvExtensionPattern.ReplaceAllString(file.Filename, "")
 	parts := strings.Split(baseName, "_")
 	if len(parts) < 6 {
 		storeID := a.ExtractStoreIdentifierFromFilename(file.Filename)
@@ -276,7 +283,7 @@ func (a *PlodineAdapter) ExtractStoreMetadata(file types.DiscoveredFile) *types.
 	// Find postal code (5 digits) working from index 1
 	postalIdx := -1
 	for i := 1; i < len(parts)-3; i++ {
-		if regexp.MustCompile(`^\d{5}$`).MatchString(parts[i]) {
+		if postalCodePattern.MatchString(parts[i]) {
 			postalIdx = i
 			break
 		}
@@ -307,7 +314,7 @@ func (a *PlodineAdapter) ExtractStoreMetadata(file types.DiscoveredFile) *types.
 
 // ExtractStoreIdentifierFromFilename extracts store identifier from Plodine filename
 func (a *PlodineAdapter) ExtractStoreIdentifierFromFilename(filename string) string {
-	baseName := regexp.MustCompile(`\.(csv|CSV)$`).ReplaceAllString(filename, "")
+	baseName := csvExtensionPattern.ReplaceAllString(filename, "")
 	parts := strings.Split(baseName, "_")
 	if len(parts) >= 6 {
 		// Store ID is typically the 5th element (index 5)
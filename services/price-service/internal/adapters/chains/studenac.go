@@ -8,14 +8,18 @@ import (
 	"strings"
 	"time"
 
-	"github.com/rs/zerolog/log"
-
 	"github.com/kosarica/price-service/internal/adapters/base"
 	"github.com/kosarica/price-service/internal/adapters/config"
 	"github.com/kosarica/price-service/internal/parsers/xml"
+	"github.com/kosarica/price-service/internal/pkg/clock"
 	"github.com/kosarica/price-service/internal/types"
 )
 
+var (
+	studenacStoreCodePattern = regexp.MustCompile(`-T(\d+)-`)
+	studenacStoreNamePattern = regexp.MustCompile(`^([A-Z]+)-(.+?)-T\d+-`)
+)
+
 // studenacFieldMapping is the primary field mapping for Studenac XML files (lowercase/snake_case)
 var studenacFieldMapping = xml.XmlFieldMapping{
 	// StoreIdentifier uses an extractor function - we'll set it at runtime
@@ -197,7 +201,7 @@ func (a *StudenacAdapter) Discover(targetDate string) ([]types.DiscoveredFile, e
 		filterDate = a.discoveryDate
 	}
 
-	log.Debug().Str("url", a.BaseURL()).Msg("Fetching Studenac portal")
+	logger.Debug().Str("url", a.BaseURL()).Msg("Fetching Studenac portal")
 
 	resp, err := a.HTTPClient().Get(a.BaseURL())
 	if err != nil {
@@ -217,8 +221,7 @@ func (a *StudenacAdapter) Discover(targetDate string) ([]types.DiscoveredFile, e
 	body := string(bodyBytes)
 
 	// Extract XML file links
-	xmlPattern := regexp.MustCompile(`href=["']([^"']*\.xml(?:\?[^"']*)?)["']`)
-	matches := xmlPattern.FindAllStringSubmatch(body, -1)
+	matches := xmlLinkPattern.FindAllStringSubmatch(body, -1)
 
 	for _, match := range matches {
 		if len(match) < 2 {
@@ -245,7 +248,7 @@ func (a *StudenacAdapter) Discover(targetDate string) ([]types.DiscoveredFile, e
 
 		var lastModified *time.Time
 		if fileDate != "" {
-			if t, err := time.Parse("2006-01-02", fileDate); err == nil {
+			if t, err := clock.ParseDate(fileDate); err == nil {
 				lastModified = &t
 			}
 		}
@@ -309,14 +312,12 @@ func (a *StudenacAdapter) extractFilenameFromURL(fileURL string) string {
 // Example: SUPERMARKET-Bijela_uvala_5_FUNTANA-T598-229-2026-12-29-07-00-14-559375.xml
 func (a *StudenacAdapter) extractDateFromFilename(filename string) string {
 	// Try YYYY-MM-DD pattern
-	isoPattern := regexp.MustCompile(`(\d{4})-(\d{2})-(\d{2})`)
-	if match := isoPattern.FindStringSubmatch(filename); len(match) == 4 {
+	if match := isoDateInFilenamePattern.FindStringSubmatch(filename); len(match) == 4 {
 		return fmt.Sprintf("%s-%s-%s", match[1], match[2], match[3])
 	}
 
 	// Try DD-MM-YYYY pattern
-	euPattern := regexp.MustCompile(`(\d{2})-(\d{2})-(\d{4})`)
-	if match := euPattern.FindStringSubmatch(filename); len(match) == 4 {
+	if match := dashedDateInFilenamePattern.FindStringSubmatch(filename); len(match) == 4 {
 		return fmt.Sprintf("%s-%s-%s", match[3], match[2], match[1])
 	}
 
@@ -327,8 +328,7 @@ func (a *StudenacAdapter) extractDateFromFilename(filename string) string {
 // Pattern: {TYPE}-{LOCATION}-T{CODE}-{DATE...}.xml
 func (a *StudenacAdapter) ExtractStoreIdentifierFromFilename(filename string) string {
 	// Try to extract T-code (e.g., T598)
-	tCodePattern := regexp.MustCompile(`-T(\d+)-`)
-	if match := tCodePattern.FindStringSubmatch(filename); len(match) >= 2 {
+	if match := studenacStoreCodePattern.FindStringSubmatch(filename); len(match) >= 2 {
 		return match[1]
 	}
 
@@ -346,8 +346,7 @@ func (a *StudenacAdapter) ExtractStoreIdentifierFromFilename(filename string) st
 // Example: SUPERMARKET-Bijela_uvala_5_FUNTANA-T598-229-2026-12-29-07-00-14-559375.xml
 func (a *StudenacAdapter) ExtractStoreMetadata(file types.DiscoveredFile) *types.StoreMetadata {
 	// Extract type and location from pattern: {TYPE}-{LOCATION}-T{CODE}-
-	pattern := regexp.MustCompile(`^([A-Z]+)-(.+?)-T\d+-`)
-	match := pattern.FindStringSubmatch(file.Filename)
+	match := studenacStoreNamePattern.FindStringSubmatch(file.Filename)
 
 	if len(match) < 3 {
 		// Fall back to default behavior
@@ -403,6 +402,18 @@ func (a *StudenacAdapter) Parse(content []byte, filename string, options *types.
 	return result, nil
 }
 
+// ParseStream overrides the base ParseStream to set the store ID of items
+// without one, as Parse does
+func (a *StudenacAdapter) ParseStream(r io.Reader, filename string, emit func(types.NormalizedRow) error) (*types.ParseResult, error) {
+	storeID := a.ExtractStoreIdentifierFromFilename(filename)
+	return a.BaseXmlAdapter.ParseStream(r, filename, func(row types.NormalizedRow) error {
+		if row.StoreIdentifier == "" {
+			row.StoreIdentifier = storeID
+		}
+		return emit(row)
+	})
+}
+
 // isAllUpper checks if a string is all uppercase
 func isAllUpper(s string) bool {
 	for _, r := range s {
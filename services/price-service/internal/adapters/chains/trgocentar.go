@@ -4,16 +4,23 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"math"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/kosarica/price-service/internal/adapters/base"
 	"github.com/kosarica/price-service/internal/adapters/config"
+	"github.com/kosarica/price-service/internal/parsers/csv"
 	"github.com/kosarica/price-service/internal/parsers/xml"
+	"github.com/kosarica/price-service/internal/pkg/clock"
 	"github.com/kosarica/price-service/internal/types"
-	"github.com/rs/zerolog/log"
+)
+
+var (
+	trgocentarDatePattern         = regexp.MustCompile(`(\d{2})(\d{2})(\d{4})\d{4}\.xml$`)
+	trgocentarStoreCodePattern    = regexp.MustCompile(`P(\d{3})`)
+	trgocentarStoreNamePattern    = regexp.MustCompile(`^SUPERMARKET_(.+?)_P\d{3}`)
+	trgocentarDynamicFieldPattern = regexp.MustCompile(`^c_(\d{6})$`)
 )
 
 // TrgocentarAdapter is the chain adapter for Trgocentar retail chain
@@ -65,7 +72,7 @@ func NewTrgocentarAdapter() (*TrgocentarAdapter, error) {
 				`(?i)^cjenik[_-]?`,
 				`(?i)^SUPERMARKET[_-]?`,
 			},
-			FileExtensionPattern: regexp.MustCompile(`\.(xml|XML)$`),
+			FileExtensionPattern: xmlExtensionPattern,
 		},
 		FieldMapping:     trgocentarFieldMapping,
 		DefaultItemsPath: "DocumentElement.cjenik",
@@ -91,19 +98,19 @@ func (a *TrgocentarAdapter) SetDiscoveryDate(date string) {
 // Pattern: DDMMYYYYHHMM at end before .xml
 func (a *TrgocentarAdapter) extractDateFromFilename(filename string) string {
 	// Try DDMMYYYYHHMM pattern (Trgocentar specific format)
-	match := regexp.MustCompile(`(\d{2})(\d{2})(\d{4})\d{4}\.xml$`).FindStringSubmatch(filename)
+	match := trgocentarDatePattern.FindStringSubmatch(filename)
 	if len(match) >= 4 {
 		return fmt.Sprintf("%s-%s-%s", match[3], match[2], match[1])
 	}
 
 	// Try YYYY-MM-DD pattern
-	match = regexp.MustCompile(`(\d{4})-(\d{2})-(\d{2})`).FindStringSubmatch(filename)
+	match = isoDateInFilenamePattern.FindStringSubmatch(filename)
 	if len(match) >= 4 {
 		return fmt.Sprintf("%s-%s-%s", match[1], match[2], match[3])
 	}
 
 	// Try DD-MM-YYYY pattern
-	match = regexp.MustCompile(`(\d{2})-(\d{2})-(\d{4})`).FindStringSubmatch(filename)
+	match = dashedDateInFilenamePattern.FindStringSubmatch(filename)
 	if len(match) >= 4 {
 		return fmt.Sprintf("%s-%s-%s", match[3], match[2], match[1])
 	}
@@ -116,17 +123,17 @@ func (a *TrgocentarAdapter) Discover(targetDate string) ([]types.DiscoveredFile,
 	discoveredFiles := make([]types.DiscoveredFile, 0)
 	seenURLs := make(map[string]bool)
 
-	log.Debug().Str("url", a.BaseURL()).Msg("Fetching Trgocentar portal")
+	logger.Debug().Str("url", a.BaseURL()).Msg("Fetching Trgocentar portal")
 
 	resp, err := a.HTTPClient().Get(a.BaseURL())
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to fetch Trgocentar portal")
+		logger.Error().Err(err).Msg("Failed to fetch Trgocentar portal")
 		return nil, fmt.Errorf("failed to fetch Trgocentar portal: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		log.Error().Int("status", resp.StatusCode).Msg("Trgocentar portal returned non-200 status")
+		logger.Error().Int("status", resp.StatusCode).Msg("Trgocentar portal returned non-200 status")
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
@@ -138,8 +145,7 @@ func (a *TrgocentarAdapter) Discover(targetDate string) ([]types.DiscoveredFile,
 	html := string(bodyBytes)
 
 	// Extract XML file links
-	xmlPattern := regexp.MustCompile(`href=["']([^"']*\.xml(?:\?[^"']*)?)["']`)
-	matches := xmlPattern.FindAllStringSubmatch(html, -1)
+	matches := xmlLinkPattern.FindAllStringSubmatch(html, -1)
 
 	for _, match := range matches {
 		if len(match) < 2 {
@@ -169,7 +175,7 @@ func (a *TrgocentarAdapter) Discover(targetDate string) ([]types.DiscoveredFile,
 
 		var lastModified *time.Time
 		if fileDate != "" {
-			if t, err := time.Parse("2006-01-02", fileDate); err == nil {
+			if t, err := clock.ParseDate(fileDate); err == nil {
 				lastModified = &t
 			}
 		}
@@ -208,10 +214,10 @@ func (a *TrgocentarAdapter) extractFilenameFromURL(fileURL string) string {
 // ExtractStoreIdentifierFromFilename extracts store identifier from Trgocentar filename
 // Trgocentar filenames contain store codes like P220, P195, P120
 func (a *TrgocentarAdapter) ExtractStoreIdentifierFromFilename(filename string) string {
-	baseName := regexp.MustCompile(`\.(xml|XML)$`).ReplaceAllString(filename, "")
+	baseName := xmlExtensionPattern.ReplaceAllString(filename, "")
 
 	// Try to extract Trgocentar store code (P followed by 3 digits)
-	match := regexp.MustCompile(`P(\d{3})`).FindStringSubmatch(baseName)
+	match := trgocentarStoreCodePattern.FindStringSubmatch(baseName)
 	if len(match) >= 2 {
 		return "P" + match[1]
 	}
@@ -227,10 +233,10 @@ func (a *TrgocentarAdapter) ExtractStoreIdentifierFromFilename(filename string)
 // ExtractStoreMetadata extracts store metadata from Trgocentar filename
 // Pattern: SUPERMARKET_HUM_NA_SUTLI_185_P220_005_050120260747.xml
 func (a *TrgocentarAdapter) ExtractStoreMetadata(file types.DiscoveredFile) *types.StoreMetadata {
-	baseName := regexp.MustCompile(`\.(xml|XML)$`).ReplaceAllString(file.Filename, "")
+	baseName := xmlExtensionPattern.ReplaceAllString(file.Filename, "")
 
 	// Extract location between SUPERMARKET_ and _P{code}
-	match := regexp.MustCompile(`^SUPERMARKET_(.+?)_P\d{3}`).FindStringSubmatch(baseName)
+	match := trgocentarStoreNamePattern.FindStringSubmatch(baseName)
 	if len(match) == 0 {
 		storeID := a.ExtractStoreIdentifierFromFilename(file.Filename)
 		return &types.StoreMetadata{
@@ -269,6 +275,17 @@ func (a *TrgocentarAdapter) Parse(content []byte, filename string, options *type
 	return result, nil
 }
 
+// ParseStream overrides the base ParseStream to extract dynamic anchor price
+// fields, as Parse does
+func (a *TrgocentarAdapter) ParseStream(r io.Reader, filename string, emit func(types.NormalizedRow) error) (*types.ParseResult, error) {
+	return a.BaseXmlAdapter.ParseStream(r, filename, func(row types.NormalizedRow) error {
+		if anchorPrice := a.extractDynamicAnchorPrice(row.RawData); anchorPrice != nil {
+			row.AnchorPrice = anchorPrice
+		}
+		return emit(row)
+	})
+}
+
 // extractDynamicAnchorPrice extracts anchor price from raw XML data
 // Looks for fields matching pattern c_YYMMDD (6 digits after c_)
 func (a *TrgocentarAdapter) extractDynamicAnchorPrice(rawData string) *int {
@@ -279,10 +296,8 @@ func (a *TrgocentarAdapter) extractDynamicAnchorPrice(rawData string) *int {
 	}
 
 	// Look for fields matching c_ followed by exactly 6 digits
-	dynamicFieldPattern := regexp.MustCompile(`^c_(\d{6})$`)
-
 	for key, value := range rawDataMap {
-		if dynamicFieldPattern.MatchString(key) {
+		if trgocentarDynamicFieldPattern.MatchString(key) {
 			// Found a potential anchor price field
 			if strValue, ok := value.(string); ok && strings.TrimSpace(strValue) != "" {
 				// Parse the price value to cents
@@ -300,51 +315,7 @@ func (a *TrgocentarAdapter) extractDynamicAnchorPrice(rawData string) *int {
 }
 
 // parsePriceFromString parses a price string to cents
-// Copied from xml/parser.go logic to avoid circular dependency
+// using the shared CSV price parser
 func parsePriceFromString(value string) (int, error) {
-	if value == "" {
-		return 0, fmt.Errorf("empty price value")
-	}
-
-	// Remove currency symbols and whitespace
-	cleaned := strings.TrimSpace(value)
-	for _, r := range cleaned {
-		if r == '€' || r == '$' || r == '£' || r == '₹' ||
-			r == '¥' || r == '¢' || r == '\u00A0' {
-			cleaned = strings.ReplaceAll(cleaned, string(r), "")
-		}
-	}
-
-	// Remove common currency text
-	cleaned = strings.ToUpper(cleaned)
-	cleaned = regexp.MustCompile(`\s*(KN|KUNA|HRK|EUR|USD)\s*$`).ReplaceAllString(cleaned, "")
-	cleaned = strings.TrimSpace(cleaned)
-
-	if cleaned == "" {
-		return 0, fmt.Errorf("no numeric value found")
-	}
-
-	// Determine decimal separator
-	lastDot := strings.LastIndex(cleaned, ".")
-	lastComma := strings.LastIndex(cleaned, ",")
-
-	if lastComma > lastDot {
-		// European format: 1.234,56 -> comma is decimal
-		cleaned = strings.ReplaceAll(cleaned, ".", "")
-		cleaned = strings.ReplaceAll(cleaned, ",", ".")
-	} else if lastDot > lastComma {
-		// US format: 1,234.56 -> just remove commas
-		cleaned = strings.ReplaceAll(cleaned, ",", "")
-	}
-
-	// Parse the float
-	var result float64
-	n, err := fmt.Sscanf(cleaned, "%f", &result)
-	if err != nil || n != 1 {
-		return 0, fmt.Errorf("invalid price format: %w", err)
-	}
-
-	// Convert to cents
-	cents := math.Round(result * 100)
-	return int(cents), nil
+	return csv.ParsePrice(value)
 }
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/kosarica/price-service/internal/pkg/patterns"
 	"github.com/kosarica/price-service/internal/types"
 )
 
@@ -130,7 +131,7 @@ func (d *HTMLDiscoverer) extractLinks(html, pageURL string) []types.DiscoveredFi
 	extensionPattern := strings.Join(d.options.Extensions, "|")
 	linkPattern := d.options.LinkPattern
 	if linkPattern == nil {
-		linkPattern = regexp.MustCompile(`href=["']([^"']*\.(` + extensionPattern + `)(?:\?[^"']*)?)["']`)
+		linkPattern = patterns.MustCompile(`href=["']([^"']*\.(` + extensionPattern + `)(?:\?[^"']*)?)["']`)
 	}
 
 	matches := linkPattern.FindAllStringSubmatch(html, -1)
@@ -229,6 +230,9 @@ func (d *HTMLDiscoverer) detectFileType(filename string) types.FileType {
 	if strings.HasSuffix(lowerFilename, ".xml") {
 		return types.FileTypeXML
 	}
+	if strings.HasSuffix(lowerFilename, ".json") {
+		return types.FileTypeJSON
+	}
 	if strings.HasSuffix(lowerFilename, ".zip") {
 		return types.FileTypeZIP
 	}
@@ -2,6 +2,8 @@ package registry
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/kosarica/price-service/internal/adapters/chains"
@@ -130,6 +132,32 @@ func (r *Registry) Unregister(chainID config.ChainID) {
 	delete(r.adapters, chainID)
 }
 
+// ValidateAgainst checks the registered adapters against the chain registry.
+// It returns an error naming every registered adapter whose chain is not in
+// known (missing or disabled in the chains table).
+func (r *Registry) ValidateAgainst(known []string) error {
+	knownSet := make(map[string]bool, len(known))
+	for _, slug := range known {
+		knownSet[slug] = true
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var unknown []string
+	for id := range r.adapters {
+		if !knownSet[string(id)] {
+			unknown = append(unknown, string(id))
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknown)
+	return fmt.Errorf("adapters registered for chains not enabled in the chain registry: %s", strings.Join(unknown, ", "))
+}
+
 // GetAdapter is a convenience function to get an adapter from the default registry
 func GetAdapter(chainID config.ChainID) (ChainAdapter, error) {
 	return DefaultRegistry.GetOrInit(chainID)
@@ -1,7 +1,32 @@
 package chains
 
-// ValidChains returns the list of supported chain slugs
-func ValidChains() []string {
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kosarica/price-service/internal/database"
+)
+
+// CacheTTL is how long the chain registry loaded from the database is reused
+// before it is refreshed
+const CacheTTL = time.Minute
+
+// refreshTimeout bounds the lazy database refresh done by ValidChains
+const refreshTimeout = 2 * time.Second
+
+var cache struct {
+	mu       sync.RWMutex
+	rows     []database.Chain // Every chain, including disabled ones
+	slugs    []string         // Slugs of enabled chains
+	loadedAt time.Time
+}
+
+// DefaultChains returns the built-in chain slugs.
+// They seed the chains table and are used when the database is unavailable.
+func DefaultChains() []string {
 	return []string{
 		"konzum",
 		"lidl",
@@ -17,11 +42,117 @@ func ValidChains() []string {
 	}
 }
 
+// ValidChains returns the slugs of enabled chains from the chains table.
+// Results are cached for CacheTTL; if the database cannot be read the last
+// loaded list is returned, or DefaultChains if nothing was loaded yet.
+func ValidChains() []string {
+	cache.mu.RLock()
+	slugs, loadedAt := cache.slugs, cache.loadedAt
+	cache.mu.RUnlock()
+
+	if slugs != nil && time.Since(loadedAt) < CacheTTL {
+		return slugs
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+	defer cancel()
+	if err := Refresh(ctx); err != nil {
+		if slugs != nil {
+			return slugs
+		}
+		return DefaultChains()
+	}
+
+	cache.mu.RLock()
+	defer cache.mu.RUnlock()
+	return cache.slugs
+}
+
 // IsValidChain checks if a chain slug is valid
 func IsValidChain(chainID string) bool {
-	validChains := make(map[string]bool, len(ValidChains()))
 	for _, c := range ValidChains() {
-		validChains[c] = true
+		if c == chainID {
+			return true
+		}
+	}
+	return false
+}
+
+// List returns the chains of the registry, ordered as database.ListChains
+// orders them, from the cache while it is younger than CacheTTL. If the
+// database cannot be read the last loaded chains are returned. The returned
+// chains are shared and must not be modified.
+func List(ctx context.Context, includeDisabled bool) ([]database.Chain, error) {
+	cache.mu.RLock()
+	rows, loadedAt := cache.rows, cache.loadedAt
+	cache.mu.RUnlock()
+
+	if rows == nil || time.Since(loadedAt) >= CacheTTL {
+		if err := Refresh(ctx); err != nil && rows == nil {
+			return nil, err
+		}
+		cache.mu.RLock()
+		rows = cache.rows
+		cache.mu.RUnlock()
+	}
+
+	if includeDisabled {
+		return rows, nil
+	}
+	enabled := make([]database.Chain, 0, len(rows))
+	for _, chain := range rows {
+		if chain.Enabled {
+			enabled = append(enabled, chain)
+		}
+	}
+	return enabled, nil
+}
+
+// Get returns a chain from the cache, including disabled chains, or
+// pgx.ErrNoRows if it does not exist
+func Get(ctx context.Context, slug string) (*database.Chain, error) {
+	rows, err := List(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		if rows[i].Slug == slug {
+			chain := rows[i]
+			return &chain, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+// Refresh reloads the chains from the database into the cache
+func Refresh(ctx context.Context) error {
+	if database.Pool() == nil {
+		return fmt.Errorf("database not connected")
 	}
-	return validChains[chainID]
+
+	rows, err := database.ListChains(ctx, true)
+	if err != nil {
+		return fmt.Errorf("failed to load chains: %w", err)
+	}
+
+	slugs := make([]string, 0, len(rows))
+	for _, chain := range rows {
+		if chain.Enabled {
+			slugs = append(slugs, chain.Slug)
+		}
+	}
+
+	cache.mu.Lock()
+	cache.rows = rows
+	cache.slugs = slugs
+	cache.loadedAt = time.Now()
+	cache.mu.Unlock()
+	return nil
+}
+
+// Invalidate drops the cached chain list so the next lookup reloads it
+func Invalidate() {
+	cache.mu.Lock()
+	cache.loadedAt = time.Time{}
+	cache.mu.Unlock()
 }
@@ -0,0 +1,45 @@
+package chains
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestListServesCachedRegistry tests that fresh registry reads do not reach the database
+func TestListServesCachedRegistry(t *testing.T) {
+	cache.mu.Lock()
+	cache.rows = []database.Chain{
+		{Slug: "dm", Name: "dm", Enabled: false},
+		{Slug: "konzum", Name: "Konzum", Enabled: true},
+	}
+	cache.slugs = []string{"konzum"}
+	cache.loadedAt = time.Now()
+	cache.mu.Unlock()
+	t.Cleanup(Invalidate)
+
+	ctx := context.Background()
+
+	all, err := List(ctx, true)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	enabled, err := List(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, enabled, 1)
+	assert.Equal(t, "konzum", enabled[0].Slug)
+
+	chain, err := Get(ctx, "dm")
+	require.NoError(t, err)
+	assert.False(t, chain.Enabled, "disabled chains are found")
+
+	_, err = Get(ctx, "spar")
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+	assert.Equal(t, []string{"konzum"}, ValidChains())
+}
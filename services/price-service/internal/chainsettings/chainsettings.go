@@ -0,0 +1,210 @@
+// Package chainsettings resolves per-chain ingest settings: the chain
+// adapter's compiled-in values, overridden by the chains section of the
+// config file, overridden in turn by the chain_settings table.
+package chainsettings
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	appconfig "github.com/kosarica/price-service/config"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/http/ratelimit"
+	"github.com/kosarica/price-service/internal/parsers/csv"
+)
+
+// CacheTTL is how long overrides loaded from the database are reused
+const CacheTTL = time.Minute
+
+// refreshTimeout bounds the lazy database refresh done on lookup
+const refreshTimeout = 2 * time.Second
+
+var cache struct {
+	mu        sync.RWMutex
+	overrides map[string]Overrides
+	loadedAt  time.Time
+}
+
+// Overrides are a chain's settings at one layer; nil fields are not set there
+type Overrides struct {
+	DiscoveryEnabled  *bool   `json:"discoveryEnabled,omitempty"`
+	RequestsPerSecond *int    `json:"requestsPerSecond,omitempty"`
+	MaxRetries        *int    `json:"maxRetries,omitempty"`
+	CSVDelimiter      *string `json:"csvDelimiter,omitempty"`
+	CSVEncoding       *string `json:"csvEncoding,omitempty"`
+	CSVHasHeader      *bool   `json:"csvHasHeader,omitempty"`
+}
+
+// Merge returns o with the fields set in over replaced
+func (o Overrides) Merge(over Overrides) Overrides {
+	if over.DiscoveryEnabled != nil {
+		o.DiscoveryEnabled = over.DiscoveryEnabled
+	}
+	if over.RequestsPerSecond != nil {
+		o.RequestsPerSecond = over.RequestsPerSecond
+	}
+	if over.MaxRetries != nil {
+		o.MaxRetries = over.MaxRetries
+	}
+	if over.CSVDelimiter != nil {
+		o.CSVDelimiter = over.CSVDelimiter
+	}
+	if over.CSVEncoding != nil {
+		o.CSVEncoding = over.CSVEncoding
+	}
+	if over.CSVHasHeader != nil {
+		o.CSVHasHeader = over.CSVHasHeader
+	}
+	return o
+}
+
+// Validate checks that the set fields hold usable values
+func (o Overrides) Validate() error {
+	if o.RequestsPerSecond != nil && *o.RequestsPerSecond <= 0 {
+		return fmt.Errorf("requestsPerSecond must be positive")
+	}
+	if o.MaxRetries != nil && *o.MaxRetries < 0 {
+		return fmt.Errorf("maxRetries must not be negative")
+	}
+	if o.CSVDelimiter != nil {
+		switch csv.CsvDelimiter(*o.CSVDelimiter) {
+		case csv.DelimiterComma, csv.DelimiterSemicolon, csv.DelimiterTab:
+		default:
+			return fmt.Errorf("csvDelimiter must be \",\", \";\" or a tab")
+		}
+	}
+	if o.CSVEncoding != nil {
+		switch csv.CsvEncoding(*o.CSVEncoding) {
+		case csv.EncodingUTF8, csv.EncodingWindows1250, csv.EncodingISO88592:
+		default:
+			return fmt.Errorf("csvEncoding must be utf-8, windows-1250 or iso-8859-2")
+		}
+	}
+	return nil
+}
+
+// FromDatabase returns the overrides of a chain_settings row
+func FromDatabase(s database.ChainSettings) Overrides {
+	return Overrides{
+		DiscoveryEnabled:  s.DiscoveryEnabled,
+		RequestsPerSecond: s.RequestsPerSecond,
+		MaxRetries:        s.MaxRetries,
+		CSVDelimiter:      s.CSVDelimiter,
+		CSVEncoding:       s.CSVEncoding,
+		CSVHasHeader:      s.CSVHasHeader,
+	}
+}
+
+// ConfigDefaults returns a chain's settings from the config file
+func ConfigDefaults(chainSlug string) Overrides {
+	cfg := appconfig.Get()
+	if cfg == nil {
+		return Overrides{}
+	}
+	c, ok := cfg.Chains[chainSlug]
+	if !ok {
+		return Overrides{}
+	}
+	return Overrides{
+		DiscoveryEnabled:  c.DiscoveryEnabled,
+		RequestsPerSecond: c.RateLimit.RequestsPerSecond,
+		MaxRetries:        c.RateLimit.MaxRetries,
+		CSVDelimiter:      c.CSV.Delimiter,
+		CSVEncoding:       c.CSV.Encoding,
+		CSVHasHeader:      c.CSV.HasHeader,
+	}
+}
+
+// Get returns a chain's settings from the config file and the database
+// merged; unset fields keep the adapter's own values
+func Get(chainSlug string) Overrides {
+	return ConfigDefaults(chainSlug).Merge(databaseOverrides(chainSlug))
+}
+
+// DiscoveryEnabled reports whether a chain's files may be discovered and
+// downloaded from its portal; on unless a setting turns it off
+func DiscoveryEnabled(chainSlug string) bool {
+	s := Get(chainSlug)
+	return s.DiscoveryEnabled == nil || *s.DiscoveryEnabled
+}
+
+// RateLimit returns a chain adapter's rate limit configuration with the
+// chain's settings applied
+func RateLimit(chainSlug string, base ratelimit.Config) ratelimit.Config {
+	s := Get(chainSlug)
+	if s.RequestsPerSecond != nil {
+		base.RequestsPerSecond = *s.RequestsPerSecond
+	}
+	if s.MaxRetries != nil {
+		base.MaxRetries = *s.MaxRetries
+	}
+	return base
+}
+
+// CSVOptions returns a chain adapter's CSV parser options with the chain's
+// settings applied
+func CSVOptions(chainSlug string, base csv.CsvParserOptions) csv.CsvParserOptions {
+	s := Get(chainSlug)
+	if s.CSVDelimiter != nil {
+		base.Delimiter = csv.CsvDelimiter(*s.CSVDelimiter)
+	}
+	if s.CSVEncoding != nil {
+		base.Encoding = csv.CsvEncoding(*s.CSVEncoding)
+	}
+	if s.CSVHasHeader != nil {
+		base.HasHeader = *s.CSVHasHeader
+	}
+	return base
+}
+
+// databaseOverrides returns a chain's overrides from the cached
+// chain_settings table, refreshing it when older than CacheTTL
+func databaseOverrides(chainSlug string) Overrides {
+	cache.mu.RLock()
+	overrides, loadedAt := cache.overrides, cache.loadedAt
+	cache.mu.RUnlock()
+
+	if overrides == nil || time.Since(loadedAt) >= CacheTTL {
+		ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+		defer cancel()
+		if err := Refresh(ctx); err == nil {
+			cache.mu.RLock()
+			overrides = cache.overrides
+			cache.mu.RUnlock()
+		}
+	}
+
+	return overrides[chainSlug]
+}
+
+// Refresh reloads the overrides from the database into the cache
+func Refresh(ctx context.Context) error {
+	if database.Pool() == nil {
+		return fmt.Errorf("database not connected")
+	}
+
+	rows, err := database.ListChainSettings(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load chain settings: %w", err)
+	}
+
+	overrides := make(map[string]Overrides, len(rows))
+	for _, s := range rows {
+		overrides[s.ChainSlug] = FromDatabase(s)
+	}
+
+	cache.mu.Lock()
+	cache.overrides = overrides
+	cache.loadedAt = time.Now()
+	cache.mu.Unlock()
+	return nil
+}
+
+// Invalidate drops the cached overrides so the next lookup reloads them
+func Invalidate() {
+	cache.mu.Lock()
+	cache.loadedAt = time.Time{}
+	cache.mu.Unlock()
+}
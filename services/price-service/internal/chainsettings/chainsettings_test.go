@@ -0,0 +1,100 @@
+package chainsettings
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kosarica/price-service/internal/http/ratelimit"
+	"github.com/kosarica/price-service/internal/parsers/csv"
+	"github.com/stretchr/testify/assert"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// useOverrides makes the cache hold overrides as if just loaded from the database
+func useOverrides(t *testing.T, overrides map[string]Overrides) {
+	cache.mu.Lock()
+	cache.overrides = overrides
+	cache.loadedAt = time.Now()
+	cache.mu.Unlock()
+
+	t.Cleanup(func() {
+		cache.mu.Lock()
+		cache.overrides = nil
+		cache.loadedAt = time.Time{}
+		cache.mu.Unlock()
+	})
+}
+
+func TestMerge(t *testing.T) {
+	base := Overrides{
+		DiscoveryEnabled:  ptr(true),
+		RequestsPerSecond: ptr(2),
+		CSVDelimiter:      ptr(";"),
+	}
+	over := Overrides{
+		RequestsPerSecond: ptr(1),
+		MaxRetries:        ptr(5),
+	}
+
+	merged := base.Merge(over)
+
+	assert.Equal(t, Overrides{
+		DiscoveryEnabled:  ptr(true),
+		RequestsPerSecond: ptr(1),
+		MaxRetries:        ptr(5),
+		CSVDelimiter:      ptr(";"),
+	}, merged)
+	assert.Equal(t, 2, *base.RequestsPerSecond, "merge must not modify the receiver")
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides Overrides
+		wantErr   bool
+	}{
+		{"empty", Overrides{}, false},
+		{"all valid", Overrides{RequestsPerSecond: ptr(1), MaxRetries: ptr(0), CSVDelimiter: ptr("\t"), CSVEncoding: ptr("windows-1250")}, false},
+		{"zero requests per second", Overrides{RequestsPerSecond: ptr(0)}, true},
+		{"negative retries", Overrides{MaxRetries: ptr(-1)}, true},
+		{"unknown delimiter", Overrides{CSVDelimiter: ptr("|")}, true},
+		{"unknown encoding", Overrides{CSVEncoding: ptr("latin1")}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.overrides.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAccessorsApplyDatabaseOverrides(t *testing.T) {
+	useOverrides(t, map[string]Overrides{
+		"lidl": {
+			DiscoveryEnabled:  ptr(false),
+			RequestsPerSecond: ptr(1),
+			CSVEncoding:       ptr("utf-8"),
+			CSVHasHeader:      ptr(false),
+		},
+	})
+
+	baseLimit := ratelimit.Config{RequestsPerSecond: 2, MaxRetries: 3}
+	baseCSV := csv.CsvParserOptions{Delimiter: csv.DelimiterSemicolon, Encoding: csv.EncodingWindows1250, HasHeader: true}
+
+	assert.False(t, DiscoveryEnabled("lidl"))
+	assert.Equal(t, ratelimit.Config{RequestsPerSecond: 1, MaxRetries: 3}, RateLimit("lidl", baseLimit))
+	assert.Equal(t, csv.CsvParserOptions{Delimiter: csv.DelimiterSemicolon, Encoding: csv.EncodingUTF8, HasHeader: false}, CSVOptions("lidl", baseCSV))
+
+	// A chain without settings keeps the adapter's own
+	assert.True(t, DiscoveryEnabled("konzum"))
+	assert.Equal(t, baseLimit, RateLimit("konzum", baseLimit))
+	assert.Equal(t, baseCSV, CSVOptions("konzum", baseCSV))
+}
@@ -0,0 +1,197 @@
+// Package daily runs the nightly pipeline: ingestion of every chain, product
+// matching, the price cache refresh, stats and the post-ingestion checks, in
+// order. Each step's status is recorded in pipeline_runs, so running the
+// pipeline again for a day skips the steps that already completed.
+package daily
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+// Step statuses, in pipeline_runs.status
+const (
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+	StatusSkipped   = "skipped"
+)
+
+// ErrAlreadyRunning is returned when the pipeline of a day is already being
+// run by another process
+var ErrAlreadyRunning = errors.New("daily pipeline is already running for this date")
+
+// Step is one stage of the pipeline. Run returns a summary recorded with the
+// step's status.
+type Step struct {
+	Name string
+	Run  func(ctx context.Context) (any, error)
+}
+
+// SkipError is returned by a step that cannot run in this setup, e.g. for
+// lack of configuration. Skipped steps are retried by the next run.
+type SkipError struct {
+	Reason string
+}
+
+func (e SkipError) Error() string {
+	return e.Reason
+}
+
+// StepResult is the outcome of a step
+type StepResult struct {
+	Step     string          `json:"step"`
+	Status   string          `json:"status"`
+	Detail   json.RawMessage `json:"detail,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	Attempts int             `json:"attempts"`
+	Duration time.Duration   `json:"duration"`
+	// Reused is set when the step completed in an earlier run and was not
+	// run again
+	Reused bool `json:"reused"`
+}
+
+// Runner runs pipeline steps and records their status
+type Runner struct {
+	pool   *pgxpool.Pool
+	logger *zerolog.Logger
+}
+
+// NewRunner creates a new pipeline runner
+func NewRunner(pool *pgxpool.Pool, logger *zerolog.Logger) *Runner {
+	return &Runner{pool: pool, logger: logger}
+}
+
+// Run runs the steps of a day's pipeline in order. Steps that completed in
+// an earlier run for the day are skipped unless force is set; a failing step
+// does not stop the ones after it. Only one process runs a day's pipeline at
+// a time, so a step found running was left by a process that died and is
+// run again.
+func (r *Runner) Run(ctx context.Context, date time.Time, steps []Step, force bool) ([]StepResult, error) {
+	conn, err := r.pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	lockKey := "daily:" + date.Format(time.DateOnly)
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock(hashtext($1))`, lockKey).Scan(&locked); err != nil {
+		return nil, fmt.Errorf("failed to lock daily pipeline: %w", err)
+	}
+	if !locked {
+		return nil, ErrAlreadyRunning
+	}
+	defer conn.Exec(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock(hashtext($1))`, lockKey)
+
+	results := make([]StepResult, 0, len(steps))
+	for _, step := range steps {
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+		result, err := r.runStep(ctx, date, step, force)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, *result)
+	}
+	return results, nil
+}
+
+// runStep runs one step unless it already completed, recording its status
+// before and after. The error is only for failures to record the status; a
+// failing step is reported in its result.
+func (r *Runner) runStep(ctx context.Context, date time.Time, step Step, force bool) (*StepResult, error) {
+	result := &StepResult{Step: step.Name}
+
+	if !force {
+		var detail []byte
+		err := r.pool.QueryRow(ctx, `
+			SELECT status, attempts, detail FROM pipeline_runs
+			WHERE run_date = $1 AND step = $2
+		`, date, step.Name).Scan(&result.Status, &result.Attempts, &detail)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("failed to read status of step %s: %w", step.Name, err)
+		}
+		if result.Status == StatusCompleted {
+			result.Detail = detail
+			result.Reused = true
+			r.logger.Info().Str("step", step.Name).Msg("Step already completed, skipping")
+			return result, nil
+		}
+	}
+
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO pipeline_runs (run_date, step, status, attempts, started_at)
+		VALUES ($1, $2, $3, 1, NOW())
+		ON CONFLICT (run_date, step) DO UPDATE SET
+			status = EXCLUDED.status,
+			attempts = pipeline_runs.attempts + 1,
+			detail = NULL,
+			error = NULL,
+			started_at = EXCLUDED.started_at,
+			finished_at = NULL
+		RETURNING attempts
+	`, date, step.Name, StatusRunning).Scan(&result.Attempts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start step %s: %w", step.Name, err)
+	}
+
+	r.logger.Info().Str("step", step.Name).Int("attempt", result.Attempts).Msg("Running step")
+	start := time.Now()
+	summary, stepErr := step.Run(ctx)
+	result.Duration = time.Since(start)
+
+	if summary != nil {
+		if result.Detail, err = json.Marshal(summary); err != nil {
+			return nil, fmt.Errorf("failed to encode summary of step %s: %w", step.Name, err)
+		}
+	}
+
+	var skip SkipError
+	switch {
+	case errors.As(stepErr, &skip):
+		result.Status = StatusSkipped
+		result.Error = skip.Reason
+		r.logger.Info().Str("step", step.Name).Str("reason", skip.Reason).Msg("Step skipped")
+	case stepErr != nil:
+		result.Status = StatusFailed
+		result.Error = stepErr.Error()
+		r.logger.Error().Err(stepErr).Str("step", step.Name).Dur("duration", result.Duration).Msg("Step failed")
+	default:
+		result.Status = StatusCompleted
+		r.logger.Info().Str("step", step.Name).Dur("duration", result.Duration).Msg("Step completed")
+	}
+
+	var stepError *string
+	if result.Error != "" {
+		stepError = &result.Error
+	}
+	_, err = r.pool.Exec(context.WithoutCancel(ctx), `
+		UPDATE pipeline_runs
+		SET status = $3, detail = $4, error = $5, finished_at = NOW()
+		WHERE run_date = $1 AND step = $2
+	`, date, step.Name, result.Status, result.Detail, stepError)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record status of step %s: %w", step.Name, err)
+	}
+	return result, nil
+}
+
+// Failed returns the steps of results that failed
+func Failed(results []StepResult) []string {
+	var failed []string
+	for _, r := range results {
+		if r.Status == StatusFailed {
+			failed = append(failed, r.Step)
+		}
+	}
+	return failed
+}
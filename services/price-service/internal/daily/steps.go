@@ -0,0 +1,184 @@
+package daily
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kosarica/price-service/internal/jobs"
+	"github.com/kosarica/price-service/internal/matching"
+	"github.com/kosarica/price-service/internal/pipeline"
+	"github.com/kosarica/price-service/internal/pkg/cuid2"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// IngestStepPrefix starts the name of each chain's ingestion step
+	IngestStepPrefix = "ingest:"
+	// cacheWarmupTimeout bounds the server's reload of every chain's prices
+	cacheWarmupTimeout = 15 * time.Minute
+)
+
+// Options configures the pipeline steps
+type Options struct {
+	Chains []string
+	// Date is the discovery date passed to ingestion, empty for today
+	Date             string
+	BarcodeBatchSize int
+	// EmbeddingProvider runs AI matching; nil skips it
+	EmbeddingProvider matching.EmbeddingProvider
+	// ServerURL is the base URL of the server whose price cache is warmed,
+	// authenticated with APIKey; empty skips the refresh
+	ServerURL        string
+	APIKey           string
+	AnchorMinAgeDays int
+}
+
+// Steps returns the steps of the nightly pipeline, in order: ingestion of
+// each chain, barcode and AI matching, the price cache refresh, the stats
+// rollup, the anchor price compliance report and the check for suspect runs
+func Steps(pool *pgxpool.Pool, logger *zerolog.Logger, date time.Time, opts Options) []Step {
+	steps := make([]Step, 0, len(opts.Chains)+6)
+	for _, chainSlug := range opts.Chains {
+		steps = append(steps, Step{
+			Name: IngestStepPrefix + chainSlug,
+			Run: func(ctx context.Context) (any, error) {
+				return ingest(ctx, chainSlug, opts.Date)
+			},
+		})
+	}
+
+	return append(steps,
+		Step{Name: "match_barcode", Run: func(ctx context.Context) (any, error) {
+			return matching.AutoMatchByBarcode(ctx, pool, opts.BarcodeBatchSize)
+		}},
+		Step{Name: "match_ai", Run: func(ctx context.Context) (any, error) {
+			if opts.EmbeddingProvider == nil {
+				return nil, SkipError{Reason: "no embedding provider configured"}
+			}
+			runID := cuid2.GeneratePrefixedId("run", cuid2.PrefixedIdOptions{})
+			return matching.RunAIMatching(ctx, pool, matching.DefaultAIMatcherConfig(opts.EmbeddingProvider), runID)
+		}},
+		Step{Name: "refresh_caches", Run: func(ctx context.Context) (any, error) {
+			return nil, warmServerCache(ctx, opts.ServerURL, opts.APIKey)
+		}},
+		Step{Name: "stats_rollup", Run: func(ctx context.Context) (any, error) {
+			return nil, jobs.NewStatsRollupJob(pool, logger, 0).RunOnce(ctx)
+		}},
+		Step{Name: "anchor_compliance", Run: func(ctx context.Context) (any, error) {
+			stats, err := jobs.ComputeAnchorCompliance(ctx, pool, time.Now().UTC(), opts.AnchorMinAgeDays)
+			if err != nil {
+				return nil, err
+			}
+			return stats, jobs.RecordAnchorCompliance(ctx, pool, stats)
+		}},
+		Step{Name: "anomaly_check", Run: func(ctx context.Context) (any, error) {
+			return checkSuspectRuns(ctx, pool, date)
+		}},
+	)
+}
+
+// ingestSummary is the recorded summary of a chain's ingestion
+type ingestSummary struct {
+	RunID            string `json:"runId"`
+	FilesProcessed   int    `json:"filesProcessed"`
+	EntriesPersisted int    `json:"entriesPersisted"`
+	Errors           int    `json:"errors"`
+	BaselineStatus   string `json:"baselineStatus,omitempty"`
+}
+
+// ingest runs a chain's ingestion; a run that completes with errors fails
+// the step so the next pipeline run ingests the chain again
+func ingest(ctx context.Context, chainSlug, date string) (any, error) {
+	result, err := pipeline.Run(ctx, chainSlug, date)
+	if err != nil {
+		return nil, err
+	}
+	summary := ingestSummary{
+		RunID:            result.RunID,
+		FilesProcessed:   result.FilesProcessed,
+		EntriesPersisted: result.EntriesPersisted,
+		Errors:           len(result.Errors),
+		BaselineStatus:   result.BaselineStatus,
+	}
+	if !result.Success {
+		return summary, fmt.Errorf("ingestion completed with %d errors", len(result.Errors))
+	}
+	return summary, nil
+}
+
+// warmServerCache asks the server to reload every chain's prices into its
+// price cache
+func warmServerCache(ctx context.Context, serverURL, apiKey string) error {
+	if serverURL == "" {
+		return SkipError{Reason: "no server URL configured"}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cacheWarmupTimeout)
+	defer cancel()
+
+	url := strings.TrimRight(serverURL, "/") + "/internal/basket/cache/warmup"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build cache warmup request: %w", err)
+	}
+	req.Header.Set("X-Internal-API-Key", apiKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("cache warmup request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("cache warmup returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// suspectRun is a run of the day flagged by its baseline check
+type suspectRun struct {
+	RunID     string `json:"runId"`
+	ChainSlug string `json:"chainSlug"`
+}
+
+// checkSuspectRuns fails when a run ingested by the day's pipeline is still
+// suspect, i.e. deviates from its baseline run and has not been cleared
+func checkSuspectRuns(ctx context.Context, pool *pgxpool.Pool, date time.Time) (any, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT r.id, r.chain_slug
+		FROM pipeline_runs p
+		JOIN ingestion_runs r ON r.id = p.detail->>'runId'
+		WHERE p.run_date = $1 AND p.step LIKE $2 || '%'
+		  AND r.baseline_status = 'suspect'
+		ORDER BY r.chain_slug
+	`, date, IngestStepPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query suspect runs: %w", err)
+	}
+	defer rows.Close()
+
+	suspect := make([]suspectRun, 0)
+	for rows.Next() {
+		var run suspectRun
+		if err := rows.Scan(&run.RunID, &run.ChainSlug); err != nil {
+			return nil, fmt.Errorf("failed to scan suspect run: %w", err)
+		}
+		suspect = append(suspect, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query suspect runs: %w", err)
+	}
+
+	if len(suspect) > 0 {
+		chains := make([]string, len(suspect))
+		for i, run := range suspect {
+			chains[i] = run.ChainSlug
+		}
+		return suspect, fmt.Errorf("%d suspect runs: %s", len(suspect), strings.Join(chains, ", "))
+	}
+	return suspect, nil
+}
@@ -0,0 +1,44 @@
+package daily
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarmServerCache(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/internal/basket/cache/warmup", r.URL.Path)
+		assert.Equal(t, "key", r.Header.Get("X-Internal-API-Key"))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	require.NoError(t, warmServerCache(context.Background(), server.URL+"/", "key"))
+
+	status = http.StatusInternalServerError
+	assert.ErrorContains(t, warmServerCache(context.Background(), server.URL, "key"), "500")
+}
+
+func TestWarmServerCacheSkipsWithoutServer(t *testing.T) {
+	var skip SkipError
+	assert.True(t, errors.As(warmServerCache(context.Background(), "", ""), &skip))
+}
+
+func TestFailed(t *testing.T) {
+	results := []StepResult{
+		{Step: "ingest:konzum", Status: StatusCompleted},
+		{Step: "ingest:lidl", Status: StatusFailed},
+		{Step: "match_ai", Status: StatusSkipped},
+		{Step: "anomaly_check", Status: StatusFailed},
+	}
+	assert.Equal(t, []string{"ingest:lidl", "anomaly_check"}, Failed(results))
+	assert.Empty(t, Failed(results[:1]))
+}
@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kosarica/price-service/internal/pkg/cuid2"
+)
+
+// apiKeyPrefix starts every API key, so leaked keys are easy to recognize
+const apiKeyPrefix = "kp_"
+
+// APIKey represents an api_keys row. The key itself is never stored.
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	KeyPrefix  string     `json:"keyPrefix"`
+	RateLimit  *float64   `json:"rateLimit"` // Requests per second; nil uses the default
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	RevokedAt  *time.Time `json:"revokedAt"`
+}
+
+// HashAPIKey returns the stored hash of an API key
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIKey creates an API key and returns it with its row. The key is
+// returned only here; afterwards only its hash is known.
+func CreateAPIKey(ctx context.Context, name string, rateLimit *float64) (string, *APIKey, error) {
+	pool := Pool()
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, err
+	}
+	key := apiKeyPrefix + hex.EncodeToString(secret)
+
+	apiKey := &APIKey{
+		ID:        cuid2.GeneratePrefixedId("key", cuid2.PrefixedIdOptions{}),
+		Name:      name,
+		KeyPrefix: key[:len(apiKeyPrefix)+8],
+		RateLimit: rateLimit,
+	}
+	err := pool.QueryRow(ctx, `
+		INSERT INTO api_keys (id, name, key_hash, key_prefix, rate_limit, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		RETURNING created_at
+	`, apiKey.ID, apiKey.Name, HashAPIKey(key), apiKey.KeyPrefix, apiKey.RateLimit).Scan(&apiKey.CreatedAt)
+	if err != nil {
+		return "", nil, err
+	}
+	return key, apiKey, nil
+}
+
+// UseAPIKey returns the unrevoked API key with the given hash and records
+// that it was used, nil when there is none
+func UseAPIKey(ctx context.Context, keyHash string) (*APIKey, error) {
+	pool := Pool()
+
+	var apiKey APIKey
+	err := pool.QueryRow(ctx, `
+		UPDATE api_keys SET last_used_at = NOW()
+		WHERE key_hash = $1 AND revoked_at IS NULL
+		RETURNING id, name, key_prefix, rate_limit, created_at, last_used_at, revoked_at
+	`, keyHash).Scan(
+		&apiKey.ID, &apiKey.Name, &apiKey.KeyPrefix, &apiKey.RateLimit,
+		&apiKey.CreatedAt, &apiKey.LastUsedAt, &apiKey.RevokedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// ListAPIKeys returns all API keys, newest first
+func ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	pool := Pool()
+
+	rows, err := pool.Query(ctx, `
+		SELECT id, name, key_prefix, rate_limit, created_at, last_used_at, revoked_at
+		FROM api_keys
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]APIKey, 0)
+	for rows.Next() {
+		var apiKey APIKey
+		if err := rows.Scan(
+			&apiKey.ID, &apiKey.Name, &apiKey.KeyPrefix, &apiKey.RateLimit,
+			&apiKey.CreatedAt, &apiKey.LastUsedAt, &apiKey.RevokedAt,
+		); err != nil {
+			return nil, err
+		}
+		keys = append(keys, apiKey)
+	}
+
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey revokes an API key, reporting whether an unrevoked key existed
+func RevokeAPIKey(ctx context.Context, id string) (bool, error) {
+	pool := Pool()
+
+	tag, err := pool.Exec(ctx, `UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
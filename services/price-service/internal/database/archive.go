@@ -183,6 +183,140 @@ func GetArchivesByChain(ctx context.Context, chainSlug string, limit, offset int
 	return archives, nil
 }
 
+// GetArchivesDownloadedBetween returns a chain's archives downloaded in
+// [from, to), the newest one per filename, ordered by filename
+func GetArchivesDownloadedBetween(ctx context.Context, chainSlug string, from, to time.Time) ([]Archive, error) {
+	pool := Pool()
+
+	query := `
+		SELECT DISTINCT ON (filename)
+			id, chain_slug, source_url, filename, original_format,
+			archive_path, archive_type, content_type, file_size,
+			compressed_size, checksum, downloaded_at, metadata,
+			created_at, updated_at
+		FROM archives
+		WHERE chain_slug = $1
+		  AND downloaded_at >= $2
+		  AND downloaded_at < $3
+		ORDER BY filename, downloaded_at DESC
+	`
+
+	rows, err := pool.Query(ctx, query, chainSlug, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	archives := make([]Archive, 0)
+	for rows.Next() {
+		var archive Archive
+		err := rows.Scan(
+			&archive.ID, &archive.ChainSlug, &archive.SourceURL, &archive.Filename,
+			&archive.OriginalFormat, &archive.ArchivePath, &archive.ArchiveType,
+			&archive.ContentType, &archive.FileSize, &archive.CompressedSize,
+			&archive.Checksum, &archive.DownloadedAt, &archive.Metadata,
+			&archive.CreatedAt, &archive.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		archives = append(archives, archive)
+	}
+
+	return archives, rows.Err()
+}
+
+// GetArchiveForIngestionFile resolves the archive a file of an ingestion run was parsed from.
+// Files record their archive ID in metadata; older files are matched by content hash.
+func GetArchiveForIngestionFile(ctx context.Context, runID, fileID string) (*Archive, error) {
+	pool := Pool()
+
+	query := `
+		SELECT a.id, a.chain_slug, a.source_url, a.filename, a.original_format,
+			a.archive_path, a.archive_type, a.content_type, a.file_size,
+			a.compressed_size, a.checksum, a.downloaded_at, a.metadata,
+			a.created_at, a.updated_at
+		FROM ingestion_files f
+		JOIN archives a
+			ON a.id = (f.metadata::jsonb ->> 'archiveId')
+			OR a.checksum = f.file_hash
+		WHERE f.run_id = $1 AND f.id = $2
+		ORDER BY CASE WHEN a.id = (f.metadata::jsonb ->> 'archiveId') THEN 0 ELSE 1 END
+		LIMIT 1
+	`
+
+	row := pool.QueryRow(ctx, query, runID, fileID)
+
+	var archive Archive
+	err := row.Scan(
+		&archive.ID, &archive.ChainSlug, &archive.SourceURL, &archive.Filename,
+		&archive.OriginalFormat, &archive.ArchivePath, &archive.ArchiveType,
+		&archive.ContentType, &archive.FileSize, &archive.CompressedSize,
+		&archive.Checksum, &archive.DownloadedAt, &archive.Metadata,
+		&archive.CreatedAt, &archive.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &archive, nil
+}
+
+// GetExpiredArchives returns archives downloaded before cutoff, excluding the
+// newest keepPerChain archives of each chain
+func GetExpiredArchives(ctx context.Context, cutoff time.Time, keepPerChain int) ([]Archive, error) {
+	pool := Pool()
+
+	query := `
+		SELECT id, chain_slug, source_url, filename, original_format,
+			archive_path, archive_type, content_type, file_size,
+			compressed_size, checksum, downloaded_at, metadata,
+			created_at, updated_at
+		FROM (
+			SELECT *,
+				ROW_NUMBER() OVER (PARTITION BY chain_slug ORDER BY downloaded_at DESC) as chain_rank
+			FROM archives
+		) ranked
+		WHERE downloaded_at < $1
+		  AND chain_rank > $2
+		ORDER BY downloaded_at ASC
+	`
+
+	rows, err := pool.Query(ctx, query, cutoff, keepPerChain)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	archives := make([]Archive, 0)
+	for rows.Next() {
+		var archive Archive
+		err := rows.Scan(
+			&archive.ID, &archive.ChainSlug, &archive.SourceURL, &archive.Filename,
+			&archive.OriginalFormat, &archive.ArchivePath, &archive.ArchiveType,
+			&archive.ContentType, &archive.FileSize, &archive.CompressedSize,
+			&archive.Checksum, &archive.DownloadedAt, &archive.Metadata,
+			&archive.CreatedAt, &archive.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		archives = append(archives, archive)
+	}
+
+	return archives, rows.Err()
+}
+
+// DeleteArchive removes an archive record
+// Runs and retailer items referencing it keep their rows with archive_id set to NULL
+func DeleteArchive(ctx context.Context, id string) error {
+	pool := Pool()
+
+	_, err := pool.Exec(ctx, `DELETE FROM archives WHERE id = $1`, id)
+	return err
+}
+
 // LinkArchiveToIngestionRun associates an archive with an ingestion run
 // It also sets the source_url from the archive's source_url
 func LinkArchiveToIngestionRun(ctx context.Context, archiveID, runID string) error {
@@ -0,0 +1,137 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// setupArchiveTestDB starts Postgres with the archives table from its
+// migration and the run and file columns archives are resolved through
+func setupArchiveTestDB(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping archive test in short mode (requires Docker)")
+	}
+
+	ctx := context.Background()
+	container, err := postgres.Run(ctx, "postgres:16-alpine",
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(30*time.Second)),
+	)
+	require.NoError(t, err, "Failed to start postgres container")
+	t.Cleanup(func() { testcontainers.TerminateContainer(container) })
+
+	connStr, err := container.ConnectionString(ctx, "sslmode=disable")
+	require.NoError(t, err)
+	require.NoError(t, Connect(ctx, connStr, 4, 1, 0, 0))
+	t.Cleanup(Close)
+
+	_, err = Pool().Exec(ctx, `
+		CREATE TABLE ingestion_runs (id TEXT PRIMARY KEY);
+		CREATE TABLE retailer_items (id TEXT PRIMARY KEY);
+		CREATE TABLE ingestion_files (
+			id TEXT PRIMARY KEY,
+			run_id TEXT NOT NULL,
+			file_hash TEXT,
+			metadata TEXT
+		);
+	`)
+	require.NoError(t, err)
+
+	migration, err := os.ReadFile("../../migrations/0001_add_archive_tracking.sql")
+	require.NoError(t, err)
+	_, err = Pool().Exec(ctx, string(migration))
+	require.NoError(t, err)
+}
+
+// TestArchiveRetentionAndLookup checks retention keeps the newest archives of
+// every chain, deleting an archive unlinks its runs, and ingestion files
+// resolve to their archive by ID before content hash
+func TestArchiveRetentionAndLookup(t *testing.T) {
+	setupArchiveTestDB(t)
+	ctx := context.Background()
+	pool := Pool()
+
+	now := time.Now()
+	archive := func(id, chain string, age time.Duration, checksum string) {
+		_, err := pool.Exec(ctx, `
+			INSERT INTO archives (id, chain_slug, source_url, filename, original_format,
+				archive_path, archive_type, checksum, downloaded_at)
+			VALUES ($1::text, $2::text, 'https://example.com/' || $1, $1 || '.csv', 'csv', $2 || '/' || $1, 'local', $3, $4)
+		`, id, chain, checksum, now.Add(-age))
+		require.NoError(t, err)
+	}
+	day := 24 * time.Hour
+	archive("arc_k1", "konzum", 40*day, "h1")
+	archive("arc_k2", "konzum", 35*day, "h2")
+	archive("arc_k3", "konzum", 30*day, "h3")
+	archive("arc_k4", "konzum", day, "h4")
+	archive("arc_l1", "lidl", 50*day, "h5")
+	archive("arc_l2", "lidl", 60*day, "h5")
+
+	ids := func(archives []Archive) []string {
+		var out []string
+		for _, a := range archives {
+			out = append(out, a.ID)
+		}
+		return out
+	}
+
+	// The newest two of every chain are kept however old they are
+	expired, err := GetExpiredArchives(ctx, now.Add(-10*day), 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"arc_k1", "arc_k2"}, ids(expired))
+
+	expired, err = GetExpiredArchives(ctx, now.Add(-10*day), 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"arc_l2", "arc_l1", "arc_k1", "arc_k2", "arc_k3"}, ids(expired))
+
+	expired, err = GetExpiredArchives(ctx, now.Add(-100*day), 0)
+	require.NoError(t, err)
+	assert.Empty(t, expired)
+
+	// Files resolve by the archive ID in their metadata, falling back to the hash
+	_, err = pool.Exec(ctx, `
+		INSERT INTO ingestion_runs (id, archive_id) VALUES ('run-1', 'arc_k1');
+		INSERT INTO ingestion_files (id, run_id, file_hash, metadata) VALUES
+			('file-id', 'run-1', 'h5', '{"archiveId": "arc_k3"}'),
+			('file-hash', 'run-1', 'h2', NULL),
+			('file-none', 'run-1', 'unknown', '{}');
+	`)
+	require.NoError(t, err)
+
+	found, err := GetArchiveForIngestionFile(ctx, "run-1", "file-id")
+	require.NoError(t, err)
+	assert.Equal(t, "arc_k3", found.ID)
+
+	found, err = GetArchiveForIngestionFile(ctx, "run-1", "file-hash")
+	require.NoError(t, err)
+	assert.Equal(t, "arc_k2", found.ID)
+
+	_, err = GetArchiveForIngestionFile(ctx, "run-1", "file-none")
+	assert.True(t, errors.Is(err, pgx.ErrNoRows), "got %v", err)
+	_, err = GetArchiveForIngestionFile(ctx, "run-2", "file-id")
+	assert.True(t, errors.Is(err, pgx.ErrNoRows), "got %v", err)
+
+	// Deleting an archive keeps the runs that referenced it
+	require.NoError(t, DeleteArchive(ctx, "arc_k1"))
+	var archiveID *string
+	err = pool.QueryRow(ctx, `SELECT archive_id FROM ingestion_runs WHERE id = 'run-1'`).Scan(&archiveID)
+	require.NoError(t, err)
+	assert.Nil(t, archiveID)
+
+	expired, err = GetExpiredArchives(ctx, now.Add(-10*day), 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"arc_k2"}, ids(expired))
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// CacheInvalidationChannel is the LISTEN/NOTIFY channel price cache
+// invalidations are sent on
+const CacheInvalidationChannel = "price_cache_invalidate"
+
+// CacheInvalidation tells the price caches of every replica that a chain's
+// prices changed. With a StoreID only that store changed: it moved to another
+// price group or its exceptions changed, and caches re-read the store's group,
+// exceptions and assortment, and the group's prices when they do not hold
+// them yet. Without one the whole chain is refreshed.
+type CacheInvalidation struct {
+	ChainSlug string `json:"chainSlug"`
+	StoreID   string `json:"storeId,omitempty"`
+	// Origin identifies the cache that sent the invalidation after already
+	// applying it, so it skips its own; empty for ingestion
+	Origin string `json:"origin,omitempty"`
+}
+
+// Execer is satisfied by pgx.Tx, pgx.Conn and pgxpool.Pool
+type Execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// NotifyCacheInvalidation sends an invalidation. Sent within a transaction,
+// Postgres delivers it to listeners when the transaction commits and drops it
+// when it rolls back, so caches never see changes that were not written.
+func NotifyCacheInvalidation(ctx context.Context, db Execer, inv CacheInvalidation) error {
+	payload, err := json.Marshal(inv)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache invalidation: %w", err)
+	}
+	if _, err := db.Exec(ctx, `SELECT pg_notify($1, $2)`, CacheInvalidationChannel, string(payload)); err != nil {
+		return fmt.Errorf("failed to notify cache invalidation: %w", err)
+	}
+	return nil
+}
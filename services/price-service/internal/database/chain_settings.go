@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ChainSettings represents a chain's ingest setting overrides. A nil field
+// is not overridden.
+type ChainSettings struct {
+	ChainSlug         string    `json:"chainSlug"`
+	DiscoveryEnabled  *bool     `json:"discoveryEnabled"`
+	RequestsPerSecond *int      `json:"requestsPerSecond"`
+	MaxRetries        *int      `json:"maxRetries"`
+	CSVDelimiter      *string   `json:"csvDelimiter"`
+	CSVEncoding       *string   `json:"csvEncoding"`
+	CSVHasHeader      *bool     `json:"csvHasHeader"`
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+const chainSettingsColumns = `chain_slug, discovery_enabled, requests_per_second, max_retries,
+	csv_delimiter, csv_encoding, csv_has_header, created_at, updated_at`
+
+func scanChainSettings(row pgx.Row) (*ChainSettings, error) {
+	var s ChainSettings
+	err := row.Scan(&s.ChainSlug, &s.DiscoveryEnabled, &s.RequestsPerSecond, &s.MaxRetries,
+		&s.CSVDelimiter, &s.CSVEncoding, &s.CSVHasHeader, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ListChainSettings returns the setting overrides of every chain that has them, ordered by chain
+func ListChainSettings(ctx context.Context) ([]ChainSettings, error) {
+	pool := Pool()
+
+	rows, err := pool.Query(ctx, `
+		SELECT `+chainSettingsColumns+`
+		FROM chain_settings
+		ORDER BY chain_slug
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	settings := make([]ChainSettings, 0)
+	for rows.Next() {
+		s, err := scanChainSettings(rows)
+		if err != nil {
+			return nil, err
+		}
+		settings = append(settings, *s)
+	}
+
+	return settings, rows.Err()
+}
+
+// GetChainSettings returns the setting overrides of a chain.
+// Returns pgx.ErrNoRows if the chain has none.
+func GetChainSettings(ctx context.Context, chainSlug string) (*ChainSettings, error) {
+	pool := Pool()
+
+	return scanChainSettings(pool.QueryRow(ctx, `
+		SELECT `+chainSettingsColumns+`
+		FROM chain_settings
+		WHERE chain_slug = $1
+	`, chainSlug))
+}
+
+// UpsertChainSettings creates or replaces the setting overrides of a chain
+func UpsertChainSettings(ctx context.Context, s *ChainSettings) error {
+	pool := Pool()
+
+	return pool.QueryRow(ctx, `
+		INSERT INTO chain_settings (
+			chain_slug, discovery_enabled, requests_per_second, max_retries,
+			csv_delimiter, csv_encoding, csv_has_header, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		ON CONFLICT (chain_slug) DO UPDATE SET
+			discovery_enabled = EXCLUDED.discovery_enabled,
+			requests_per_second = EXCLUDED.requests_per_second,
+			max_retries = EXCLUDED.max_retries,
+			csv_delimiter = EXCLUDED.csv_delimiter,
+			csv_encoding = EXCLUDED.csv_encoding,
+			csv_has_header = EXCLUDED.csv_has_header,
+			updated_at = NOW()
+		RETURNING created_at, updated_at
+	`, s.ChainSlug, s.DiscoveryEnabled, s.RequestsPerSecond, s.MaxRetries,
+		s.CSVDelimiter, s.CSVEncoding, s.CSVHasHeader).Scan(&s.CreatedAt, &s.UpdatedAt)
+}
+
+// DeleteChainSettings removes the setting overrides of a chain.
+// Returns false if the chain had none.
+func DeleteChainSettings(ctx context.Context, chainSlug string) (bool, error) {
+	pool := Pool()
+
+	tag, err := pool.Exec(ctx, `DELETE FROM chain_settings WHERE chain_slug = $1`, chainSlug)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
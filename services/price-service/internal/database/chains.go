@@ -0,0 +1,136 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrChainExists is returned by CreateChain when the slug is already taken
+var ErrChainExists = errors.New("chain already exists")
+
+// ChainUpdate contains the fields of a chain that can be changed.
+// Nil fields are left unchanged.
+type ChainUpdate struct {
+	Name        *string
+	DisplayName *string
+	BrandColor  *string
+	Website     *string
+	LogoURL     *string
+	Enabled     *bool
+	Metadata    json.RawMessage
+}
+
+const chainColumns = `slug, name, display_name, brand_color, website, logo_url, enabled, metadata, created_at, updated_at`
+
+func scanChain(row pgx.Row) (*Chain, error) {
+	var chain Chain
+	err := row.Scan(
+		&chain.Slug, &chain.Name, &chain.DisplayName, &chain.BrandColor, &chain.Website, &chain.LogoURL,
+		&chain.Enabled, &chain.Metadata, &chain.CreatedAt, &chain.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &chain, nil
+}
+
+// ListChains returns registered chains ordered by slug.
+// Disabled chains are only included when includeDisabled is set.
+func ListChains(ctx context.Context, includeDisabled bool) ([]Chain, error) {
+	pool := Pool()
+
+	query := `SELECT ` + chainColumns + ` FROM chains`
+	if !includeDisabled {
+		query += ` WHERE enabled = true`
+	}
+	query += ` ORDER BY slug`
+
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	chains := make([]Chain, 0)
+	for rows.Next() {
+		chain, err := scanChain(rows)
+		if err != nil {
+			return nil, err
+		}
+		chains = append(chains, *chain)
+	}
+
+	return chains, rows.Err()
+}
+
+// GetChain retrieves a chain by slug, returning pgx.ErrNoRows if it does not exist
+func GetChain(ctx context.Context, slug string) (*Chain, error) {
+	pool := Pool()
+
+	row := pool.QueryRow(ctx, `SELECT `+chainColumns+` FROM chains WHERE slug = $1`, slug)
+	return scanChain(row)
+}
+
+// CreateChain inserts a new chain into the registry, returning
+// ErrChainExists if its slug is taken
+func CreateChain(ctx context.Context, chain *Chain) error {
+	pool := Pool()
+
+	if len(chain.Metadata) == 0 {
+		chain.Metadata = json.RawMessage(`{}`)
+	}
+
+	query := `
+		INSERT INTO chains (
+			slug, name, display_name, brand_color, website, logo_url,
+			enabled, metadata, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+		RETURNING created_at, updated_at
+	`
+
+	err := pool.QueryRow(ctx, query,
+		chain.Slug, chain.Name, chain.DisplayName, chain.BrandColor, chain.Website, chain.LogoURL,
+		chain.Enabled, chain.Metadata,
+	).Scan(&chain.CreatedAt, &chain.UpdatedAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrChainExists
+	}
+	return err
+}
+
+// UpdateChain applies the non-nil fields of update to a chain and returns the
+// updated row, or pgx.ErrNoRows if the chain does not exist
+func UpdateChain(ctx context.Context, slug string, update ChainUpdate) (*Chain, error) {
+	pool := Pool()
+
+	query := `
+		UPDATE chains
+		SET name = COALESCE($2, name),
+		    display_name = COALESCE($3, display_name),
+		    brand_color = COALESCE($4, brand_color),
+		    website = COALESCE($5, website),
+		    logo_url = COALESCE($6, logo_url),
+		    enabled = COALESCE($7, enabled),
+		    metadata = COALESCE($8::jsonb, metadata),
+		    updated_at = NOW()
+		WHERE slug = $1
+		RETURNING ` + chainColumns
+
+	var metadata *string
+	if len(update.Metadata) > 0 {
+		s := string(update.Metadata)
+		metadata = &s
+	}
+
+	row := pool.QueryRow(ctx, query,
+		slug, update.Name, update.DisplayName, update.BrandColor,
+		update.Website, update.LogoURL, update.Enabled, metadata,
+	)
+	return scanChain(row)
+}
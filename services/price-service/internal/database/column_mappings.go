@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ColumnMappingOverride represents a per-chain overlay on the compiled-in
+// column mapping. Mapping keys are column mapping field names (e.g. "price")
+// and values are header names or column indices.
+type ColumnMappingOverride struct {
+	ChainSlug   string            `json:"chainSlug"`
+	Mapping     map[string]string `json:"mapping"`
+	Enabled     bool              `json:"enabled"`
+	Description *string           `json:"description"`
+	CreatedAt   time.Time         `json:"createdAt"`
+	UpdatedAt   time.Time         `json:"updatedAt"`
+}
+
+const columnMappingOverrideColumns = `chain_slug, mapping, enabled, description, created_at, updated_at`
+
+func scanColumnMappingOverride(row pgx.Row) (*ColumnMappingOverride, error) {
+	var o ColumnMappingOverride
+	if err := row.Scan(&o.ChainSlug, &o.Mapping, &o.Enabled, &o.Description, &o.CreatedAt, &o.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// ListColumnMappingOverrides returns all column mapping overrides ordered by chain
+func ListColumnMappingOverrides(ctx context.Context) ([]ColumnMappingOverride, error) {
+	pool := Pool()
+
+	rows, err := pool.Query(ctx, `
+		SELECT `+columnMappingOverrideColumns+`
+		FROM column_mapping_overrides
+		ORDER BY chain_slug
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	overrides := make([]ColumnMappingOverride, 0)
+	for rows.Next() {
+		o, err := scanColumnMappingOverride(rows)
+		if err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, *o)
+	}
+
+	return overrides, rows.Err()
+}
+
+// GetColumnMappingOverride returns the override of a chain.
+// Returns pgx.ErrNoRows if the chain has no override.
+func GetColumnMappingOverride(ctx context.Context, chainSlug string) (*ColumnMappingOverride, error) {
+	pool := Pool()
+
+	return scanColumnMappingOverride(pool.QueryRow(ctx, `
+		SELECT `+columnMappingOverrideColumns+`
+		FROM column_mapping_overrides
+		WHERE chain_slug = $1
+	`, chainSlug))
+}
+
+// UpsertColumnMappingOverride creates or replaces the override of a chain
+func UpsertColumnMappingOverride(ctx context.Context, o *ColumnMappingOverride) error {
+	pool := Pool()
+
+	return pool.QueryRow(ctx, `
+		INSERT INTO column_mapping_overrides (chain_slug, mapping, enabled, description, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		ON CONFLICT (chain_slug) DO UPDATE SET
+			mapping = EXCLUDED.mapping,
+			enabled = EXCLUDED.enabled,
+			description = EXCLUDED.description,
+			updated_at = NOW()
+		RETURNING created_at, updated_at
+	`, o.ChainSlug, o.Mapping, o.Enabled, o.Description).Scan(&o.CreatedAt, &o.UpdatedAt)
+}
+
+// DeleteColumnMappingOverride removes the override of a chain.
+// Returns false if the chain had no override.
+func DeleteColumnMappingOverride(ctx context.Context, chainSlug string) (bool, error) {
+	pool := Pool()
+
+	tag, err := pool.Exec(ctx, `DELETE FROM column_mapping_overrides WHERE chain_slug = $1`, chainSlug)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,17 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kosarica/price-service/internal/pkg/clock"
+)
+
+// DiscountActiveSQL returns a condition true when the discount window of the
+// store_item_state row alias is active at the SQL expression at, evaluated
+// in the service's time zone as the optimizer does (see the discount_active
+// function).
+func DiscountActiveSQL(alias, at string) string {
+	zone := "'" + strings.ReplaceAll(clock.Zone().String(), "'", "''") + "'"
+	return fmt.Sprintf("discount_active(%[1]s.discount_start, %[1]s.discount_end, %[2]s, %[3]s)", alias, at, zone)
+}
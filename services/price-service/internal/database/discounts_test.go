@@ -0,0 +1,13 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscountActiveSQL(t *testing.T) {
+	assert.Equal(t,
+		"discount_active(sis.discount_start, sis.discount_end, NOW(), 'Europe/Zagreb')",
+		DiscountActiveSQL("sis", "NOW()"))
+}
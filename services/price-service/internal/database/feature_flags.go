@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// FeatureFlag represents a feature flag row
+type FeatureFlag struct {
+	Key         string    `json:"key"`
+	Description *string   `json:"description"`
+	Enabled     bool      `json:"enabled"`
+	Chains      []string  `json:"chains"` // Empty means all chains
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// ListFeatureFlags returns all feature flags ordered by key
+func ListFeatureFlags(ctx context.Context) ([]FeatureFlag, error) {
+	pool := Pool()
+
+	rows, err := pool.Query(ctx, `
+		SELECT key, description, enabled, chains, created_at, updated_at
+		FROM feature_flags
+		ORDER BY key
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	flags := make([]FeatureFlag, 0)
+	for rows.Next() {
+		var flag FeatureFlag
+		if err := rows.Scan(
+			&flag.Key, &flag.Description, &flag.Enabled, &flag.Chains,
+			&flag.CreatedAt, &flag.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		flags = append(flags, flag)
+	}
+
+	return flags, rows.Err()
+}
+
+// UpsertFeatureFlag creates or replaces a feature flag
+func UpsertFeatureFlag(ctx context.Context, flag *FeatureFlag) error {
+	pool := Pool()
+
+	if flag.Chains == nil {
+		flag.Chains = []string{}
+	}
+
+	return pool.QueryRow(ctx, `
+		INSERT INTO feature_flags (key, description, enabled, chains, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		ON CONFLICT (key) DO UPDATE SET
+			description = EXCLUDED.description,
+			enabled = EXCLUDED.enabled,
+			chains = EXCLUDED.chains,
+			updated_at = NOW()
+		RETURNING created_at, updated_at
+	`, flag.Key, flag.Description, flag.Enabled, flag.Chains).Scan(&flag.CreatedAt, &flag.UpdatedAt)
+}
+
+// DeleteFeatureFlag removes a feature flag, reporting whether it existed
+func DeleteFeatureFlag(ctx context.Context, key string) (bool, error) {
+	pool := Pool()
+
+	tag, err := pool.Exec(ctx, `DELETE FROM feature_flags WHERE key = $1`, key)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// IngestionBatch is a group of API runs started by one bulk ingest request
+type IngestionBatch struct {
+	ID          int64
+	Concurrency int
+	TargetDate  *string
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+	Runs        []IngestionBatchRun // In start order
+}
+
+// IngestionBatchRun is one chain's run in a batch
+type IngestionBatchRun struct {
+	RunID            int64
+	ChainSlug        string
+	Status           string // 'pending', 'running', 'completed', 'failed' or 'interrupted'
+	StartedAt        *time.Time
+	CompletedAt      *time.Time
+	ProcessedFiles   int
+	ProcessedEntries int
+}
+
+// CreateIngestionBatch creates a batch with a pending API run for each chain,
+// positioned in the given order, and returns the batch with its runs
+func CreateIngestionBatch(ctx context.Context, chainSlugs []string, concurrency int, targetDate string) (*IngestionBatch, error) {
+	var date *string
+	if targetDate != "" {
+		date = &targetDate
+	}
+
+	batch := &IngestionBatch{Concurrency: concurrency, TargetDate: date}
+	err := pgx.BeginFunc(ctx, Pool(), func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `
+			INSERT INTO ingestion_batches (concurrency, target_date, created_at)
+			VALUES ($1, $2, NOW())
+			RETURNING id, created_at
+		`, concurrency, date).Scan(&batch.ID, &batch.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create batch: %w", err)
+		}
+
+		for i, slug := range chainSlugs {
+			run := IngestionBatchRun{ChainSlug: slug, Status: "pending"}
+			err := tx.QueryRow(ctx, `
+				INSERT INTO ingestion_runs (chain_slug, source, status, batch_id, batch_position, created_at)
+				VALUES ($1, 'api', 'pending', $2, $3, NOW())
+				RETURNING id
+			`, slug, batch.ID, i).Scan(&run.RunID)
+			if err != nil {
+				return fmt.Errorf("failed to create run for %s: %w", slug, err)
+			}
+			batch.Runs = append(batch.Runs, run)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// GetIngestionBatch returns a batch and its runs, or pgx.ErrNoRows
+func GetIngestionBatch(ctx context.Context, batchID int64) (*IngestionBatch, error) {
+	pool := Pool()
+
+	batch := &IngestionBatch{}
+	err := pool.QueryRow(ctx, `
+		SELECT id, concurrency, target_date, created_at, completed_at
+		FROM ingestion_batches WHERE id = $1
+	`, batchID).Scan(&batch.ID, &batch.Concurrency, &batch.TargetDate, &batch.CreatedAt, &batch.CompletedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT id, chain_slug, status, started_at, completed_at,
+		       COALESCE(processed_files, 0), COALESCE(processed_entries, 0)
+		FROM ingestion_runs
+		WHERE batch_id = $1
+		ORDER BY batch_position
+	`, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query batch runs: %w", err)
+	}
+	defer rows.Close()
+
+	batch.Runs = make([]IngestionBatchRun, 0)
+	for rows.Next() {
+		var run IngestionBatchRun
+		if err := rows.Scan(&run.RunID, &run.ChainSlug, &run.Status, &run.StartedAt, &run.CompletedAt,
+			&run.ProcessedFiles, &run.ProcessedEntries); err != nil {
+			return nil, fmt.Errorf("failed to scan batch run: %w", err)
+		}
+		batch.Runs = append(batch.Runs, run)
+	}
+	return batch, rows.Err()
+}
+
+// CompleteIngestionBatch records that every run of a batch has finished
+func CompleteIngestionBatch(ctx context.Context, batchID int64) error {
+	_, err := Pool().Exec(ctx, `UPDATE ingestion_batches SET completed_at = NOW() WHERE id = $1`, batchID)
+	return err
+}
+
+// ChainIngestionSizes returns the entries processed by the latest completed
+// run of each chain that has one
+func ChainIngestionSizes(ctx context.Context, chainSlugs []string) (map[string]int, error) {
+	rows, err := Pool().Query(ctx, `
+		SELECT DISTINCT ON (chain_slug) chain_slug, COALESCE(processed_entries, 0)
+		FROM ingestion_runs
+		WHERE chain_slug = ANY($1) AND status = 'completed' AND COALESCE(processed_entries, 0) > 0
+		ORDER BY chain_slug, completed_at DESC
+	`, chainSlugs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sizes := make(map[string]int, len(chainSlugs))
+	for rows.Next() {
+		var slug string
+		var entries int
+		if err := rows.Scan(&slug, &entries); err != nil {
+			return nil, err
+		}
+		sizes[slug] = entries
+	}
+	return sizes, rows.Err()
+}
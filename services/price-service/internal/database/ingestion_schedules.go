@@ -0,0 +1,170 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Ingestion schedule outcomes recorded in last_status
+const (
+	ScheduleStatusStarted   = "started"
+	ScheduleStatusSkipped   = "skipped"
+	ScheduleStatusCompleted = "completed"
+	ScheduleStatusFailed    = "failed"
+)
+
+// IngestionSchedule represents an ingestion_schedules row: when a chain is
+// ingested automatically
+type IngestionSchedule struct {
+	ChainSlug       string     `json:"chainSlug"`
+	CronExpression  string     `json:"cronExpression"`
+	Enabled         bool       `json:"enabled"`
+	NextRunAt       *time.Time `json:"nextRunAt"`
+	LastTriggeredAt *time.Time `json:"lastTriggeredAt"`
+	LastStatus      *string    `json:"lastStatus"`
+	LastRunID       *string    `json:"lastRunId"`
+	LastError       *string    `json:"lastError"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+}
+
+const ingestionScheduleColumns = `
+	chain_slug, cron_expression, enabled, next_run_at, last_triggered_at,
+	last_status, last_run_id, last_error, created_at, updated_at
+`
+
+func scanIngestionSchedule(row pgx.Row) (*IngestionSchedule, error) {
+	var s IngestionSchedule
+	err := row.Scan(&s.ChainSlug, &s.CronExpression, &s.Enabled, &s.NextRunAt, &s.LastTriggeredAt,
+		&s.LastStatus, &s.LastRunID, &s.LastError, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ListIngestionSchedules returns every ingestion schedule by chain
+func ListIngestionSchedules(ctx context.Context) ([]IngestionSchedule, error) {
+	return queryIngestionSchedules(ctx, `SELECT `+ingestionScheduleColumns+` FROM ingestion_schedules ORDER BY chain_slug`)
+}
+
+// ListDueIngestionSchedules returns the enabled schedules whose next run is
+// at or before now
+func ListDueIngestionSchedules(ctx context.Context, now time.Time) ([]IngestionSchedule, error) {
+	return queryIngestionSchedules(ctx, `
+		SELECT `+ingestionScheduleColumns+`
+		FROM ingestion_schedules
+		WHERE enabled AND next_run_at <= $1
+		ORDER BY next_run_at, chain_slug
+	`, now)
+}
+
+func queryIngestionSchedules(ctx context.Context, query string, args ...any) ([]IngestionSchedule, error) {
+	rows, err := Pool().Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := make([]IngestionSchedule, 0)
+	for rows.Next() {
+		s, err := scanIngestionSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, *s)
+	}
+	return schedules, rows.Err()
+}
+
+// GetIngestionSchedule returns a chain's schedule, nil when it has none
+func GetIngestionSchedule(ctx context.Context, chainSlug string) (*IngestionSchedule, error) {
+	s, err := scanIngestionSchedule(Pool().QueryRow(ctx, `
+		SELECT `+ingestionScheduleColumns+` FROM ingestion_schedules WHERE chain_slug = $1
+	`, chainSlug))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return s, err
+}
+
+// UpsertIngestionSchedule creates or replaces a chain's cron expression,
+// enabled state and next run; the outcome of its last run is kept
+func UpsertIngestionSchedule(ctx context.Context, chainSlug, cronExpression string, enabled bool, nextRunAt *time.Time) (*IngestionSchedule, error) {
+	return scanIngestionSchedule(Pool().QueryRow(ctx, `
+		INSERT INTO ingestion_schedules (chain_slug, cron_expression, enabled, next_run_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		ON CONFLICT (chain_slug) DO UPDATE SET
+			cron_expression = EXCLUDED.cron_expression,
+			enabled = EXCLUDED.enabled,
+			next_run_at = EXCLUDED.next_run_at,
+			updated_at = NOW()
+		RETURNING `+ingestionScheduleColumns, chainSlug, cronExpression, enabled, nextRunAt))
+}
+
+// InsertIngestionScheduleIfMissing creates a chain's schedule unless it has
+// one, reporting whether it was created
+func InsertIngestionScheduleIfMissing(ctx context.Context, chainSlug, cronExpression string, nextRunAt *time.Time) (bool, error) {
+	tag, err := Pool().Exec(ctx, `
+		INSERT INTO ingestion_schedules (chain_slug, cron_expression, enabled, next_run_at, created_at, updated_at)
+		VALUES ($1, $2, true, $3, NOW(), NOW())
+		ON CONFLICT (chain_slug) DO NOTHING
+	`, chainSlug, cronExpression, nextRunAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// DeleteIngestionSchedule removes a chain's schedule, reporting whether it existed
+func DeleteIngestionSchedule(ctx context.Context, chainSlug string) (bool, error) {
+	tag, err := Pool().Exec(ctx, `DELETE FROM ingestion_schedules WHERE chain_slug = $1`, chainSlug)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ClaimIngestionSchedule moves a due schedule's next run from dueAt to
+// nextRunAt, reporting whether this caller claimed it. Only one of several
+// servers sharing the database claims each due run.
+func ClaimIngestionSchedule(ctx context.Context, chainSlug string, dueAt, nextRunAt time.Time) (bool, error) {
+	tag, err := Pool().Exec(ctx, `
+		UPDATE ingestion_schedules
+		SET next_run_at = $3,
+		    last_triggered_at = NOW()
+		WHERE chain_slug = $1 AND enabled AND next_run_at = $2
+	`, chainSlug, dueAt, nextRunAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// RecordIngestionScheduleOutcome records the outcome of a schedule's last
+// trigger; runID and errMsg may be empty
+func RecordIngestionScheduleOutcome(ctx context.Context, chainSlug, status, runID, errMsg string) error {
+	_, err := Pool().Exec(ctx, `
+		UPDATE ingestion_schedules
+		SET last_status = $2,
+		    last_run_id = COALESCE(NULLIF($3, ''), last_run_id),
+		    last_error = NULLIF($4, '')
+		WHERE chain_slug = $1
+	`, chainSlug, status, runID, errMsg)
+	return err
+}
+
+// HasActiveIngestionRun reports whether a run of the chain is pending or running
+func HasActiveIngestionRun(ctx context.Context, chainSlug string) (bool, error) {
+	var active bool
+	err := Pool().QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM ingestion_runs
+			WHERE chain_slug = $1 AND status IN ('pending', 'running')
+		)
+	`, chainSlug).Scan(&active)
+	return active, err
+}
@@ -1,16 +1,22 @@
 package database
 
 import (
+	"encoding/json"
 	"time"
 )
 
 // Chain represents a retail chain (Konzum, Lidl, etc.)
 type Chain struct {
-	Slug      string    `json:"slug"`       // konzum, lidl, plodine, etc.
-	Name      string    `json:"name"`       // Human-readable name
-	Website   *string   `json:"website"`    // Optional website URL
-	LogoURL   *string   `json:"logo_url"`   // Optional logo URL
-	CreatedAt time.Time `json:"created_at"`
+	Slug        string          `json:"slug"`                          // konzum, lidl, plodine, etc.
+	Name        string          `json:"name"`                          // Human-readable name
+	Website     *string         `json:"website"`                       // Optional website URL
+	LogoURL     *string         `json:"logo_url"`                      // Optional logo URL
+	DisplayName *string         `json:"display_name"`                  // Optional presentation name, falls back to Name
+	BrandColor  *string         `json:"brand_color"`                   // Optional hex color, e.g. '#e30613'
+	Enabled     bool            `json:"enabled"`                       // Disabled chains are rejected by ingestion
+	Metadata    json.RawMessage `json:"metadata" swaggertype:"object"` // Free-form JSON metadata
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
 }
 
 // Store represents a physical or virtual store location
@@ -117,7 +123,7 @@ type IngestionFile struct {
 	FileType       string     `json:"file_type"`       // 'csv', 'xml', 'xlsx', 'zip'
 	FileSize       *int       `json:"file_size"`       // Size in bytes
 	FileHash       *string    `json:"file_hash"`       // For deduplication
-	Status         string     `json:"status"`          // 'pending', 'processing', 'completed', 'failed'
+	Status         string     `json:"status"`          // 'pending', 'downloading', 'parsing', 'persisting', 'completed', 'failed', 'skipped'
 	EntryCount     *int       `json:"entry_count"`     // Number of entries
 	ProcessedAt    *time.Time `json:"processed_at"`
 	Metadata       *string    `json:"metadata"`        // JSON for file-specific info
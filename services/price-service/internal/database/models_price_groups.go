@@ -27,6 +27,8 @@ type GroupPrice struct {
 	DiscountPrice  *int    `json:"discount_price"`   // NULL = no discount (distinct from 0!)
 	UnitPrice      *int    `json:"unit_price"`       // price per unit in cents (e.g., per kg/l)
 	AnchorPrice    *int    `json:"anchor_price"`     // "sidrena cijena" anchor/reference price in cents
+	DiscountStart  *time.Time `json:"discount_start"` // NULL = discount valid from any time
+	DiscountEnd    *time.Time `json:"discount_end"`   // NULL = discount valid until further notice
 	CreatedAt      time.Time `json:"created_at"`
 }
 
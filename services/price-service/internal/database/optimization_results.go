@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/kosarica/price-service/internal/pkg/cuid2"
+)
+
+// OptimizationResult represents a stored basket optimization result.
+// Latitude and longitude are already rounded when stored.
+type OptimizationResult struct {
+	ID        string          `json:"id"` // opt_{cuid2}
+	UserRef   string          `json:"userRef"`
+	Kind      string          `json:"kind"` // 'single' or 'multi'
+	ChainSlug string          `json:"chainSlug"`
+	Latitude  *float64        `json:"latitude"`
+	Longitude *float64        `json:"longitude"`
+	Request   json.RawMessage `json:"request" swaggertype:"object"`
+	Result    json.RawMessage `json:"result" swaggertype:"object"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// CreateOptimizationResult stores an optimization result and fills in its ID
+// and creation time
+func CreateOptimizationResult(ctx context.Context, r *OptimizationResult) error {
+	pool := Pool()
+
+	r.ID = cuid2.GeneratePrefixedId("opt", cuid2.PrefixedIdOptions{})
+
+	return pool.QueryRow(ctx, `
+		INSERT INTO optimization_results (
+			id, user_ref, kind, chain_slug, latitude, longitude, request, result, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		RETURNING created_at
+	`, r.ID, r.UserRef, r.Kind, r.ChainSlug, r.Latitude, r.Longitude, r.Request, r.Result).Scan(&r.CreatedAt)
+}
+
+// ListOptimizationResultsByUserRef returns every stored result of a user
+// reference, oldest first
+func ListOptimizationResultsByUserRef(ctx context.Context, userRef string) ([]OptimizationResult, error) {
+	pool := Pool()
+
+	rows, err := pool.Query(ctx, `
+		SELECT id, user_ref, kind, chain_slug, latitude, longitude, request, result, created_at
+		FROM optimization_results
+		WHERE user_ref = $1
+		ORDER BY created_at, id
+	`, userRef)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]OptimizationResult, 0)
+	for rows.Next() {
+		var r OptimizationResult
+		if err := rows.Scan(&r.ID, &r.UserRef, &r.Kind, &r.ChainSlug, &r.Latitude, &r.Longitude,
+			&r.Request, &r.Result, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+
+	return results, rows.Err()
+}
+
+// DeleteOptimizationResultsByUserRef removes every stored result of a user
+// reference and returns how many were deleted
+func DeleteOptimizationResultsByUserRef(ctx context.Context, userRef string) (int64, error) {
+	pool := Pool()
+
+	tag, err := pool.Exec(ctx, `DELETE FROM optimization_results WHERE user_ref = $1`, userRef)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// PriceChangeRecord represents a row of the price change log
+type PriceChangeRecord struct {
+	Cursor           int64      `json:"cursor,string"`
+	ChainSlug        string     `json:"chainSlug"`
+	StoreID          string     `json:"storeId"`
+	RetailerItemID   string     `json:"retailerItemId"`
+	RunID            *string    `json:"runId"`
+	FileID           *string    `json:"fileId"`
+	OldPrice         *int       `json:"oldPrice"`
+	NewPrice         int        `json:"newPrice"`
+	OldDiscountPrice *int       `json:"oldDiscountPrice"`
+	NewDiscountPrice *int       `json:"newDiscountPrice"`
+	DiscountStart    *time.Time `json:"discountStart"`
+	DiscountEnd      *time.Time `json:"discountEnd"`
+	ChangedAt        time.Time  `json:"changedAt"`
+}
+
+// StreamPriceChanges calls fn for up to limit price changes after the cursor,
+// in cursor order. chainSlug filters by chain when non-empty. Rows are passed
+// to fn while the query is still running so large pages are not buffered.
+func StreamPriceChanges(ctx context.Context, since int64, chainSlug string, limit int, fn func(*PriceChangeRecord) error) error {
+	pool := Pool()
+
+	rows, err := pool.Query(ctx, `
+		SELECT id, chain_slug, store_id, retailer_item_id, run_id, file_id,
+			old_price, new_price, old_discount_price, new_discount_price,
+			discount_start, discount_end, changed_at
+		FROM price_change_log
+		WHERE id > $1 AND ($2 = '' OR chain_slug = $2)
+		ORDER BY id
+		LIMIT $3
+	`, since, chainSlug, limit)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var record PriceChangeRecord
+		if err := rows.Scan(
+			&record.Cursor, &record.ChainSlug, &record.StoreID, &record.RetailerItemID,
+			&record.RunID, &record.FileID,
+			&record.OldPrice, &record.NewPrice, &record.OldDiscountPrice, &record.NewDiscountPrice,
+			&record.DiscountStart, &record.DiscountEnd, &record.ChangedAt,
+		); err != nil {
+			return err
+		}
+		if err := fn(&record); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,165 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// PriceDeltaRetention is how far back a store price delta reaches. Expired
+// exceptions are kept this long so deltas can report the prices items revert
+// to; older versions get the full price list.
+const PriceDeltaRetention = 7 * 24 * time.Hour
+
+// priceDeltaOverlap is subtracted from a delta's version before comparing, so
+// a group transition or exception committed after the previous delta was read
+// but stamped before it is not missed. Items changed in the overlap are sent
+// again with their current prices, which is harmless.
+const priceDeltaOverlap = 15 * time.Minute
+
+// StorePriceDelta is the change of a store's prices since a version
+type StorePriceDelta struct {
+	Version time.Time          // Version of the prices after applying the delta
+	Full    bool               // Changed holds every price; the version was too old or unknown
+	Changed []StorePriceResult // Current prices of items changed since the version
+	Removed []string           // Items the store no longer prices
+}
+
+// GetStorePriceDelta returns the prices of a store in a chain that changed
+// since a version, as returned in an earlier delta's Version. Prices change
+// only through transitions to another price group, as groups are immutable,
+// and through exceptions created or expiring, so the delta is the difference
+// between the store's group at the version and its current group, plus the
+// items of those exceptions. A zero since, one older than PriceDeltaRetention
+// or one before the store had a group returns every price with Full set.
+// Returns pgx.ErrNoRows if the store is not in the chain.
+func GetStorePriceDelta(ctx context.Context, chainSlug, storeID string, since time.Time) (*StorePriceDelta, error) {
+	tx, err := Pool().BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	delta := &StorePriceDelta{}
+	err = tx.QueryRow(ctx, `
+		SELECT NOW() FROM stores WHERE id = $1 AND chain_slug = $2
+	`, storeID, chainSlug).Scan(&delta.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	var currentGroup *string
+	err = tx.QueryRow(ctx, `
+		SELECT price_group_id FROM store_group_history
+		WHERE store_id = $1 AND valid_to IS NULL
+	`, storeID).Scan(&currentGroup)
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, fmt.Errorf("failed to read current price group: %w", err)
+	}
+
+	from := since.Add(-priceDeltaOverlap)
+	var previousGroup *string
+	if !since.IsZero() && delta.Version.Sub(since) <= PriceDeltaRetention {
+		err = tx.QueryRow(ctx, `
+			SELECT price_group_id FROM store_group_history
+			WHERE store_id = $1 AND valid_from <= $2 AND (valid_to IS NULL OR valid_to > $2)
+		`, storeID, from).Scan(&previousGroup)
+		if err != nil && err != pgx.ErrNoRows {
+			return nil, fmt.Errorf("failed to read previous price group: %w", err)
+		}
+	}
+	delta.Full = previousGroup == nil
+
+	var itemIDs []string
+	if delta.Full {
+		err = collectStrings(ctx, tx, &itemIDs, `
+			SELECT retailer_item_id FROM group_prices WHERE price_group_id = $1
+			UNION
+			SELECT retailer_item_id FROM store_price_exceptions
+			WHERE store_id = $2 AND expires_at > $3
+		`, currentGroup, storeID, delta.Version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list store items: %w", err)
+		}
+	} else {
+		// Items priced differently by the two groups, then items whose
+		// exception was created or expired in between
+		err = collectStrings(ctx, tx, &itemIDs, `
+			SELECT retailer_item_id
+			FROM (SELECT * FROM group_prices WHERE price_group_id = $1) old
+			FULL JOIN (SELECT * FROM group_prices WHERE price_group_id = $2) cur USING (retailer_item_id)
+			WHERE $1 IS DISTINCT FROM $2
+			  AND (old.price IS DISTINCT FROM cur.price
+			    OR old.discount_price IS DISTINCT FROM cur.discount_price
+			    OR old.unit_price IS DISTINCT FROM cur.unit_price
+			    OR old.anchor_price IS DISTINCT FROM cur.anchor_price)
+			UNION
+			SELECT retailer_item_id FROM store_price_exceptions
+			WHERE store_id = $3
+			  AND (created_at > $4 OR (expires_at > $4 AND expires_at <= $5))
+		`, previousGroup, currentGroup, storeID, from, delta.Version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list changed items: %w", err)
+		}
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT
+			c.id,
+			COALESCE(spe.price, gp.price),
+			CASE WHEN spe.store_id IS NOT NULL THEN spe.discount_price ELSE gp.discount_price END,
+			gp.unit_price,
+			gp.anchor_price,
+			spe.store_id IS NOT NULL
+		FROM unnest($1::text[]) AS c(id)
+		LEFT JOIN group_prices gp ON gp.price_group_id = $2 AND gp.retailer_item_id = c.id
+		LEFT JOIN store_price_exceptions spe ON spe.store_id = $3
+		    AND spe.retailer_item_id = c.id
+		    AND spe.expires_at > $4
+		ORDER BY c.id
+	`, itemIDs, currentGroup, storeID, delta.Version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query store prices: %w", err)
+	}
+	defer rows.Close()
+
+	delta.Changed = make([]StorePriceResult, 0, len(itemIDs))
+	delta.Removed = make([]string, 0)
+	for rows.Next() {
+		var result StorePriceResult
+		var price *int
+		if err := rows.Scan(
+			&result.RetailerItemID, &price, &result.DiscountPrice,
+			&result.UnitPrice, &result.AnchorPrice, &result.IsException,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan store price: %w", err)
+		}
+		if price == nil {
+			delta.Removed = append(delta.Removed, result.RetailerItemID)
+			continue
+		}
+		result.Price = *price
+		delta.Changed = append(delta.Changed, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating store prices: %w", err)
+	}
+
+	return delta, nil
+}
+
+// collectStrings appends the single text column of a query's rows to dst
+func collectStrings(ctx context.Context, tx pgx.Tx, dst *[]string, query string, args ...any) error {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	values, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return err
+	}
+	*dst = append(*dst, values...)
+	return nil
+}
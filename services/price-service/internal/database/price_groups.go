@@ -3,28 +3,29 @@ package database
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/kosarica/price-service/internal/pkg/cuid2"
+	"github.com/kosarica/price-service/internal/pricegroups"
 )
 
-// FindOrCreatePriceGroup finds an existing price group by hash or creates a new one
+// FindOrCreatePriceGroup finds an existing price group by hash and hash version or creates a new one within tx
 // Uses INSERT ON CONFLICT DO NOTHING pattern for race condition safety
 // Returns the price group, whether it was newly created, and any error
-func FindOrCreatePriceGroup(ctx context.Context, chainSlug, priceHash string) (*PriceGroup, bool, error) {
-	pool := Pool()
-
+func FindOrCreatePriceGroup(ctx context.Context, tx pgx.Tx, chainSlug, priceHash string, hashVersion int) (*PriceGroup, bool, error) {
 	// First, try to find existing group
 	var existingGroup PriceGroup
 	query := `
 		SELECT id, chain_slug, price_hash, hash_version, store_count, item_count,
 		       first_seen_at, last_seen_at, created_at, updated_at
 		FROM price_groups
-		WHERE chain_slug = $1 AND price_hash = $2 AND hash_version = 1
+		WHERE chain_slug = $1 AND price_hash = $2 AND hash_version = $3
 		LIMIT 1
 	`
-	err := pool.QueryRow(ctx, query, chainSlug, priceHash).Scan(
+	err := tx.QueryRow(ctx, query, chainSlug, priceHash, hashVersion).Scan(
 		&existingGroup.ID, &existingGroup.ChainSlug, &existingGroup.PriceHash,
 		&existingGroup.HashVersion, &existingGroup.StoreCount, &existingGroup.ItemCount,
 		&existingGroup.FirstSeenAt, &existingGroup.LastSeenAt,
@@ -50,7 +51,7 @@ func FindOrCreatePriceGroup(ctx context.Context, chainSlug, priceHash string) (*
 			id, chain_slug, price_hash, hash_version, store_count, item_count,
 			first_seen_at, last_seen_at, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, 1, 0, 0, $4, $4, $4, $4
+			$1, $2, $3, $5, 0, 0, $4, $4, $4, $4
 		)
 		ON CONFLICT (chain_slug, price_hash, hash_version) DO NOTHING
 		RETURNING id, chain_slug, price_hash, hash_version, store_count, item_count,
@@ -58,7 +59,7 @@ func FindOrCreatePriceGroup(ctx context.Context, chainSlug, priceHash string) (*
 	`
 
 	var createdGroup PriceGroup
-	err = pool.QueryRow(ctx, insertQuery, newGroupID, chainSlug, priceHash, now).Scan(
+	err = tx.QueryRow(ctx, insertQuery, newGroupID, chainSlug, priceHash, now, hashVersion).Scan(
 		&createdGroup.ID, &createdGroup.ChainSlug, &createdGroup.PriceHash,
 		&createdGroup.HashVersion, &createdGroup.StoreCount, &createdGroup.ItemCount,
 		&createdGroup.FirstSeenAt, &createdGroup.LastSeenAt,
@@ -66,10 +67,10 @@ func FindOrCreatePriceGroup(ctx context.Context, chainSlug, priceHash string) (*
 	)
 
 	if err != nil {
-		// Check if another goroutine created it first (race condition)
+		// Check if another transaction created it first (race condition)
 		if err == pgx.ErrNoRows {
-			// Query again to get the group created by another goroutine
-			err = pool.QueryRow(ctx, query, chainSlug, priceHash).Scan(
+			// Query again to get the group created by the other transaction
+			err = tx.QueryRow(ctx, query, chainSlug, priceHash, hashVersion).Scan(
 				&existingGroup.ID, &existingGroup.ChainSlug, &existingGroup.PriceHash,
 				&existingGroup.HashVersion, &existingGroup.StoreCount, &existingGroup.ItemCount,
 				&existingGroup.FirstSeenAt, &existingGroup.LastSeenAt,
@@ -86,55 +87,52 @@ func FindOrCreatePriceGroup(ctx context.Context, chainSlug, priceHash string) (*
 	return &createdGroup, true, nil
 }
 
-// BulkInsertGroupPrices inserts multiple group prices in a single transaction
-// Returns an error if the insertion fails
-func BulkInsertGroupPrices(ctx context.Context, groupID string, prices []GroupPrice) error {
+// BulkInsertGroupPrices inserts multiple group prices within tx and updates
+// the group's item count. Returns an error if the insertion fails
+func BulkInsertGroupPrices(ctx context.Context, tx pgx.Tx, groupID string, prices []GroupPrice) error {
 	if len(prices) == 0 {
 		return nil
 	}
 
-	pool := Pool()
-
-	// Begin transaction for bulk insert
-	tx, err := pool.Begin(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback(ctx)
-
-	// Prepare batch insert statement
+	// Prepare batch insert statement, in primary key order so concurrent
+	// writers lock rows in the same order
 	batch := &pgx.Batch{}
 	now := time.Now()
 
-	for _, price := range prices {
+	sorted := slices.SortedFunc(slices.Values(prices), func(a, b GroupPrice) int {
+		return strings.Compare(a.RetailerItemID, b.RetailerItemID)
+	})
+	for _, price := range sorted {
 		batch.Queue(`
 			INSERT INTO group_prices (
 				price_group_id, retailer_item_id, price, discount_price,
-				unit_price, anchor_price, created_at
-			) VALUES ($1, $2, $3, $4, $5, $6, $7)
+				unit_price, anchor_price, discount_start, discount_end, created_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 			ON CONFLICT (price_group_id, retailer_item_id) DO UPDATE SET
 				price = EXCLUDED.price,
 				discount_price = EXCLUDED.discount_price,
 				unit_price = EXCLUDED.unit_price,
-				anchor_price = EXCLUDED.anchor_price
+				anchor_price = EXCLUDED.anchor_price,
+				discount_start = EXCLUDED.discount_start,
+				discount_end = EXCLUDED.discount_end
 		`, groupID, price.RetailerItemID, price.Price, price.DiscountPrice,
-			price.UnitPrice, price.AnchorPrice, now)
+			price.UnitPrice, price.AnchorPrice, price.DiscountStart, price.DiscountEnd, now)
 	}
 
-	// Execute batch
+	// Execute batch; the results must be closed before tx is used again
 	br := tx.SendBatch(ctx, batch)
-	defer br.Close()
-
-	// Check for errors
 	for i := 0; i < len(prices); i++ {
-		_, err := br.Exec()
-		if err != nil {
+		if _, err := br.Exec(); err != nil {
+			br.Close()
 			return fmt.Errorf("failed to insert group price %d: %w", i, err)
 		}
 	}
+	if err := br.Close(); err != nil {
+		return fmt.Errorf("failed to insert group prices: %w", err)
+	}
 
 	// Update item_count on price_groups table
-	_, err = tx.Exec(ctx, `
+	_, err := tx.Exec(ctx, `
 		UPDATE price_groups
 		SET item_count = (
 			SELECT COUNT(*) FROM group_prices WHERE price_group_id = $1
@@ -146,36 +144,28 @@ func BulkInsertGroupPrices(ctx context.Context, groupID string, prices []GroupPr
 		return fmt.Errorf("failed to update item_count: %w", err)
 	}
 
-	// Commit transaction
-	if err := tx.Commit(ctx); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	return nil
 }
 
-// AssignStoreToGroup assigns a store to a price group
+// AssignStoreToGroup assigns a store to a price group within tx
 // Closes previous membership (sets valid_to = NOW()) and opens new membership
-func AssignStoreToGroup(ctx context.Context, storeID, groupID string) error {
-	pool := Pool()
-
-	// Begin transaction
-	tx, err := pool.Begin(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback(ctx)
-
+// Returns whether the store moved, i.e. had no group or a different one
+func AssignStoreToGroup(ctx context.Context, tx pgx.Tx, storeID, groupID string) (bool, error) {
 	now := time.Now()
 
-	// Get the old group ID BEFORE closing the membership (for store_count update)
+	// Get the old group ID BEFORE closing the membership (for store_count update),
+	// locking it so concurrent assignments of the same store serialize here
 	var oldGroupID *string
-	err = tx.QueryRow(ctx, `
+	err := tx.QueryRow(ctx, `
 		SELECT price_group_id
 		FROM store_group_history
 		WHERE store_id = $1 AND valid_to IS NULL
 		LIMIT 1
+		FOR UPDATE
 	`, storeID).Scan(&oldGroupID)
+	if err != nil && err != pgx.ErrNoRows {
+		return false, fmt.Errorf("failed to read current membership: %w", err)
+	}
 
 	// Close previous membership for this store
 	_, err = tx.Exec(ctx, `
@@ -184,7 +174,7 @@ func AssignStoreToGroup(ctx context.Context, storeID, groupID string) error {
 		WHERE store_id = $2 AND valid_to IS NULL
 	`, now, storeID)
 	if err != nil {
-		return fmt.Errorf("failed to close previous membership: %w", err)
+		return false, fmt.Errorf("failed to close previous membership: %w", err)
 	}
 
 	// Create new membership entry
@@ -194,12 +184,13 @@ func AssignStoreToGroup(ctx context.Context, storeID, groupID string) error {
 		VALUES ($1, $2, $3, $4, NULL, NOW())
 	`, historyID, storeID, groupID, now)
 	if err != nil {
-		return fmt.Errorf("failed to insert new membership: %w", err)
+		return false, fmt.Errorf("failed to insert new membership: %w", err)
 	}
 
 	// Update store_count on price_groups table
 	// If there was an old group and it's different from the new one, decrement its count
-	if oldGroupID != nil && *oldGroupID != groupID {
+	moved := oldGroupID == nil || *oldGroupID != groupID
+	if oldGroupID != nil && moved {
 		_, err = tx.Exec(ctx, `
 			UPDATE price_groups
 			SET store_count = store_count - 1,
@@ -207,7 +198,7 @@ func AssignStoreToGroup(ctx context.Context, storeID, groupID string) error {
 			WHERE id = $1
 		`, *oldGroupID)
 		if err != nil {
-			return fmt.Errorf("failed to decrement old group store_count: %w", err)
+			return false, fmt.Errorf("failed to decrement old group store_count: %w", err)
 		}
 	}
 
@@ -219,15 +210,10 @@ func AssignStoreToGroup(ctx context.Context, storeID, groupID string) error {
 		WHERE id = $1
 	`, groupID)
 	if err != nil {
-		return fmt.Errorf("failed to increment new group store_count: %w", err)
-	}
-
-	// Commit transaction
-	if err := tx.Commit(ctx); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return false, fmt.Errorf("failed to increment new group store_count: %w", err)
 	}
 
-	return nil
+	return moved, nil
 }
 
 // GetCurrentPriceForStore retrieves the current price for an item at a store
@@ -302,11 +288,9 @@ func GetHistoricalPriceForStore(ctx context.Context, storeID, itemID string, asO
 	return price, discountPrice, nil
 }
 
-// UpdateGroupLastSeen updates the last_seen_at timestamp for a price group
-func UpdateGroupLastSeen(ctx context.Context, groupID string) error {
-	pool := Pool()
-
-	_, err := pool.Exec(ctx, `
+// UpdateGroupLastSeen updates the last_seen_at timestamp for a price group within tx
+func UpdateGroupLastSeen(ctx context.Context, tx pgx.Tx, groupID string) error {
+	_, err := tx.Exec(ctx, `
 		UPDATE price_groups
 		SET last_seen_at = NOW(), updated_at = NOW()
 		WHERE id = $1
@@ -319,15 +303,16 @@ func UpdateGroupLastSeen(ctx context.Context, groupID string) error {
 	return nil
 }
 
-// CleanupExpiredExceptions removes expired price exceptions
+// CleanupExpiredExceptions removes price exceptions expired longer than
+// PriceDeltaRetention ago, keeping recent ones for store price deltas
 // Returns the number of exceptions deleted
 func CleanupExpiredExceptions(ctx context.Context) (int, error) {
 	pool := Pool()
 
 	result, err := pool.Exec(ctx, `
 		DELETE FROM store_price_exceptions
-		WHERE expires_at <= NOW()
-	`)
+		WHERE expires_at <= $1
+	`, time.Now().Add(-PriceDeltaRetention))
 
 	if err != nil {
 		return 0, fmt.Errorf("failed to cleanup expired exceptions: %w", err)
@@ -484,3 +469,59 @@ func ListPriceGroups(ctx context.Context, chainSlug string, limit, offset int) (
 
 	return groups, nil
 }
+
+// ForEachCurrentGroupPrices calls fn with the prices of every price group
+// with current member stores, optionally of one chain, one group at a time
+// in group ID order. Prices are read in a single pass, so memory holds one
+// group's prices; fn must not keep the slice.
+func ForEachCurrentGroupPrices(ctx context.Context, chainSlug string, fn func(chainSlug string, storeCount int, prices []pricegroups.ItemPrice) error) error {
+	pool := Pool()
+
+	rows, err := pool.Query(ctx, `
+		WITH current_groups AS (
+			SELECT sgh.price_group_id, COUNT(*) AS store_count
+			FROM store_group_history sgh
+			WHERE sgh.valid_to IS NULL
+			GROUP BY sgh.price_group_id
+		)
+		SELECT pg.id, pg.chain_slug, cg.store_count,
+		       gp.retailer_item_id, gp.price, gp.discount_price,
+		       gp.discount_start, gp.discount_end
+		FROM current_groups cg
+		JOIN price_groups pg ON pg.id = cg.price_group_id
+		JOIN group_prices gp ON gp.price_group_id = pg.id
+		WHERE ($1 = '' OR pg.chain_slug = $1)
+		ORDER BY pg.id
+	`, chainSlug)
+	if err != nil {
+		return fmt.Errorf("error querying current group prices: %w", err)
+	}
+	defer rows.Close()
+
+	var groupID, groupChain string
+	var storeCount int
+	prices := make([]pricegroups.ItemPrice, 0)
+	for rows.Next() {
+		var id, chain string
+		var count int
+		var p pricegroups.ItemPrice
+		if err := rows.Scan(&id, &chain, &count, &p.ItemID, &p.Price, &p.DiscountPrice, &p.DiscountStart, &p.DiscountEnd); err != nil {
+			return fmt.Errorf("error scanning group price: %w", err)
+		}
+		if id != groupID && len(prices) > 0 {
+			if err := fn(groupChain, storeCount, prices); err != nil {
+				return err
+			}
+			prices = prices[:0]
+		}
+		groupID, groupChain, storeCount = id, chain, count
+		prices = append(prices, p)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error querying current group prices: %w", err)
+	}
+	if len(prices) > 0 {
+		return fn(groupChain, storeCount, prices)
+	}
+	return nil
+}
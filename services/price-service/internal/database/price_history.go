@@ -0,0 +1,61 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// PriceHistoryPoint is an item's price at a store from a day until the next
+// point
+type PriceHistoryPoint struct {
+	Day           time.Time
+	Price         int
+	DiscountPrice *int
+	UnitPrice     *int
+}
+
+// GetPriceHistory returns an item's daily price series at a store in a chain
+// between from and to, both days inclusive, in day order. The first point is
+// the last one on or before from, so the price in effect at from is known.
+// Returns pgx.ErrNoRows if the store is not in the chain.
+func GetPriceHistory(ctx context.Context, chainSlug, storeID, itemID string, from, to time.Time) ([]PriceHistoryPoint, error) {
+	pool := Pool()
+
+	var exists bool
+	err := pool.QueryRow(ctx, `
+		SELECT true FROM stores WHERE id = $1 AND chain_slug = $2
+	`, storeID, chainSlug).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := pool.Query(ctx, `
+		(
+			SELECT day, price, discount_price, unit_price
+			FROM price_history
+			WHERE store_id = $1 AND retailer_item_id = $2 AND day <= $3::date
+			ORDER BY day DESC
+			LIMIT 1
+		)
+		UNION ALL
+		SELECT day, price, discount_price, unit_price
+		FROM price_history
+		WHERE store_id = $1 AND retailer_item_id = $2 AND day > $3::date AND day <= $4::date
+		ORDER BY day
+	`, storeID, itemID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price history: %w", err)
+	}
+	defer rows.Close()
+
+	points := make([]PriceHistoryPoint, 0)
+	for rows.Next() {
+		var p PriceHistoryPoint
+		if err := rows.Scan(&p.Day, &p.Price, &p.DiscountPrice, &p.UnitPrice); err != nil {
+			return nil, fmt.Errorf("failed to scan price history: %w", err)
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
@@ -0,0 +1,189 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/klauspost/compress/zstd"
+	"github.com/kosarica/price-service/internal/pkg/cuid2"
+)
+
+// RawPayloadEncoding is the compression of stored raw payloads
+const RawPayloadEncoding = "zstd"
+
+// Encoder and decoder are safe for concurrent EncodeAll/DecodeAll calls
+var (
+	rawEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+	rawDecoder, _ = zstd.NewReader(nil)
+)
+
+// RawPayload represents a compressed raw source row
+type RawPayload struct {
+	ID             string    `json:"id"` // raw_{cuid2}
+	Encoding       string    `json:"encoding"`
+	OriginalSize   int       `json:"originalSize"`
+	CompressedSize int       `json:"compressedSize"`
+	Data           []byte    `json:"-"` // decompressed payload
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// CompressRawPayload compresses a raw payload for storage
+func CompressRawPayload(raw []byte) []byte {
+	return rawEncoder.EncodeAll(raw, make([]byte, 0, len(raw)/4))
+}
+
+// DecompressRawPayload reverses CompressRawPayload
+func DecompressRawPayload(compressed []byte) ([]byte, error) {
+	return rawDecoder.DecodeAll(compressed, nil)
+}
+
+// CreateRawPayload compresses and stores a raw payload, returning its ID
+func CreateRawPayload(ctx context.Context, raw string) (string, error) {
+	pool := Pool()
+
+	id := cuid2.GeneratePrefixedId("raw", cuid2.PrefixedIdOptions{})
+	compressed := CompressRawPayload([]byte(raw))
+
+	_, err := pool.Exec(ctx, `
+		INSERT INTO raw_payloads (id, encoding, original_size, data, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+	`, id, RawPayloadEncoding, len(raw), compressed)
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// GetRawPayload loads and decompresses a raw payload.
+// Returns pgx.ErrNoRows if it does not exist.
+func GetRawPayload(ctx context.Context, id string) (*RawPayload, error) {
+	pool := Pool()
+
+	var p RawPayload
+	var compressed []byte
+	err := pool.QueryRow(ctx, `
+		SELECT id, encoding, original_size, data, created_at
+		FROM raw_payloads
+		WHERE id = $1
+	`, id).Scan(&p.ID, &p.Encoding, &p.OriginalSize, &compressed, &p.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Encoding != RawPayloadEncoding {
+		return nil, fmt.Errorf("unsupported raw payload encoding: %s", p.Encoding)
+	}
+	p.Data, err = DecompressRawPayload(compressed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress raw payload %s: %w", id, err)
+	}
+	p.CompressedSize = len(compressed)
+
+	return &p, nil
+}
+
+// GetFailedRowRawPayload loads the raw data of a failed row, whether it is
+// stored inline (rows written before raw payloads existed) or compressed.
+// Returns pgx.ErrNoRows if the row does not exist.
+func GetFailedRowRawPayload(ctx context.Context, failedRowID string) (*RawPayload, error) {
+	pool := Pool()
+
+	var payloadID, inline *string
+	err := pool.QueryRow(ctx, `
+		SELECT raw_payload_id, raw_data FROM retailer_items_failed WHERE id = $1
+	`, failedRowID).Scan(&payloadID, &inline)
+	if err != nil {
+		return nil, err
+	}
+
+	if payloadID != nil {
+		return GetRawPayload(ctx, *payloadID)
+	}
+	if inline == nil {
+		return nil, pgx.ErrNoRows
+	}
+	return &RawPayload{
+		Encoding:     "identity",
+		OriginalSize: len(*inline),
+		Data:         []byte(*inline),
+	}, nil
+}
+
+// RawPayloadMigrationResult summarizes one batch of MigrateFailedRowRawData
+type RawPayloadMigrationResult struct {
+	Rows            int
+	OriginalBytes   int64
+	CompressedBytes int64
+}
+
+// MigrateFailedRowRawData moves up to batchSize inline raw_data values of
+// retailer_items_failed into compressed raw payloads. Call repeatedly until
+// it reports zero rows.
+func MigrateFailedRowRawData(ctx context.Context, batchSize int) (*RawPayloadMigrationResult, error) {
+	pool := Pool()
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, raw_data
+		FROM retailer_items_failed
+		WHERE raw_payload_id IS NULL AND raw_data IS NOT NULL
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`, batchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	type inlineRow struct {
+		id  string
+		raw string
+	}
+	var pending []inlineRow
+	for rows.Next() {
+		var r inlineRow
+		if err := rows.Scan(&r.id, &r.raw); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		pending = append(pending, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &RawPayloadMigrationResult{}
+	for _, r := range pending {
+		payloadID := cuid2.GeneratePrefixedId("raw", cuid2.PrefixedIdOptions{})
+		compressed := CompressRawPayload([]byte(r.raw))
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO raw_payloads (id, encoding, original_size, data, created_at)
+			VALUES ($1, $2, $3, $4, NOW())
+		`, payloadID, RawPayloadEncoding, len(r.raw), compressed); err != nil {
+			return nil, fmt.Errorf("failed to store raw payload for %s: %w", r.id, err)
+		}
+		if _, err := tx.Exec(ctx, `
+			UPDATE retailer_items_failed SET raw_payload_id = $1, raw_data = NULL WHERE id = $2
+		`, payloadID, r.id); err != nil {
+			return nil, fmt.Errorf("failed to update failed row %s: %w", r.id, err)
+		}
+
+		result.Rows++
+		result.OriginalBytes += int64(len(r.raw))
+		result.CompressedBytes += int64(len(compressed))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
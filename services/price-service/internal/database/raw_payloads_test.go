@@ -0,0 +1,21 @@
+package database
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRawPayloadCompressionRoundTrip(t *testing.T) {
+	raw := `{"naziv":"Mlijeko 2,8% 1L","cijena":"1,29","barkod":"3850108020120"}`
+	raw = strings.Repeat(raw, 20)
+
+	compressed := CompressRawPayload([]byte(raw))
+	assert.Less(t, len(compressed), len(raw))
+
+	decompressed, err := DecompressRawPayload(compressed)
+	require.NoError(t, err)
+	assert.Equal(t, raw, string(decompressed))
+}
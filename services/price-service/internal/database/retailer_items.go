@@ -0,0 +1,320 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// RetailerItemIdentityHash returns the secondary identity of a retailer item
+// from a chain that publishes no external ID: a hash of its normalized name,
+// unit and unit quantity. Case and whitespace do not change the hash.
+func RetailerItemIdentityHash(name string, unit, unitQuantity *string) string {
+	normalized := []string{
+		strings.Join(strings.Fields(strings.ToLower(name)), " "),
+		normalizeIdentityPart(unit),
+		strings.ReplaceAll(normalizeIdentityPart(unitQuantity), ",", "."),
+	}
+	sum := sha256.Sum256([]byte(strings.Join(normalized, "\x1f")))
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizeIdentityPart(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(*s))
+}
+
+// itemRefTable describes a table referencing retailer_items(id) that duplicate
+// items are merged in
+type itemRefTable struct {
+	name string
+	// unique lists the columns that together with retailer_item_id must be
+	// unique; rows colliding after the merge keep the survivor's row, then
+	// the newest
+	unique []string
+	// newest orders colliding rows by recency, empty when the table has no
+	// timestamp to go by
+	newest string
+	// derived tables hold data regenerated from the item; the duplicates'
+	// rows are dropped instead of merged
+	derived bool
+	// shared tables have no uniqueness on retailer_item_id; all rows move
+	shared bool
+}
+
+var itemRefTables = []itemRefTable{
+	{name: "store_item_state", unique: []string{"store_id"}, newest: "last_seen_at"},
+	{name: "group_prices", unique: []string{"price_group_id"}, newest: "created_at"},
+	{name: "store_price_exceptions", unique: []string{"store_id"}},
+	{name: "store_item_assortment", unique: []string{"store_id"}, newest: "last_seen_at"},
+	{name: "retailer_item_barcodes", unique: []string{"barcode"}, newest: "created_at"},
+	{name: "product_links", unique: nil},
+	{name: "product_match_queue", unique: nil},
+	{name: "product_match_rejections", unique: []string{"rejected_product_id"}},
+	{name: "product_match_candidates", derived: true},
+	{name: "retailer_item_embeddings", derived: true},
+	{name: "price_change_log", shared: true},
+}
+
+// RetailerItemDedupResult summarizes a retailer item deduplication
+type RetailerItemDedupResult struct {
+	Identities int // identities that had duplicates
+	Merged     int // duplicate items merged into a survivor
+	Backfilled int // items given their identity hash
+}
+
+// legacyItem is a retailer item without an external ID
+type legacyItem struct {
+	id           string
+	hasHash      bool
+	identityHash string
+}
+
+// DedupRetailerItems merges retailer items without an external ID that share
+// a chain and identity hash, which earlier runs created anew every time. The
+// item that already carries the identity hash survives, otherwise the oldest;
+// references of the others are moved to it and the others are deleted.
+// Every item is left with its identity hash so later runs find it. An empty
+// chainSlug processes every chain. With dryRun nothing is written.
+func DedupRetailerItems(ctx context.Context, chainSlug string, dryRun bool) (*RetailerItemDedupResult, error) {
+	pool := Pool()
+
+	rows, err := pool.Query(ctx, `
+		SELECT id, chain_slug, name, unit, unit_quantity, identity_hash
+		FROM retailer_items
+		WHERE (external_id IS NULL OR external_id = '')
+		  AND ($1 = '' OR chain_slug = $1)
+		ORDER BY created_at NULLS LAST, id
+	`, chainSlug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load retailer items: %w", err)
+	}
+
+	type identity struct{ chain, hash string }
+	groups := make(map[identity][]legacyItem)
+	var order []identity
+	for rows.Next() {
+		var id, chain, name string
+		var unit, unitQuantity, storedHash *string
+		if err := rows.Scan(&id, &chain, &name, &unit, &unitQuantity, &storedHash); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan retailer item: %w", err)
+		}
+
+		key := identity{chain: chain, hash: RetailerItemIdentityHash(name, unit, unitQuantity)}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], legacyItem{
+			id:           id,
+			hasHash:      storedHash != nil && *storedHash == key.hash,
+			identityHash: key.hash,
+		})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load retailer items: %w", err)
+	}
+
+	tables, err := existingItemRefTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RetailerItemDedupResult{}
+	for _, key := range order {
+		items := groups[key]
+		survivor, duplicates := pickSurvivor(items)
+		if len(duplicates) == 0 && survivor.hasHash {
+			continue
+		}
+
+		if len(duplicates) > 0 {
+			result.Identities++
+			result.Merged += len(duplicates)
+		}
+		if !survivor.hasHash {
+			result.Backfilled++
+		}
+		if dryRun {
+			continue
+		}
+
+		err := WithRetry(ctx, "dedup_retailer_items", func(ctx context.Context) error {
+			return mergeRetailerItems(ctx, tables, survivor, duplicates)
+		})
+		if err != nil {
+			return result, fmt.Errorf("failed to merge duplicates of item %s: %w", survivor.id, err)
+		}
+	}
+
+	return result, nil
+}
+
+// pickSurvivor returns the item duplicates are merged into and the
+// duplicates; items are in creation order
+func pickSurvivor(items []legacyItem) (legacyItem, []legacyItem) {
+	survivor := 0
+	for i, item := range items {
+		if item.hasHash {
+			survivor = i
+			break
+		}
+	}
+
+	duplicates := make([]legacyItem, 0, len(items)-1)
+	for i, item := range items {
+		if i != survivor {
+			duplicates = append(duplicates, item)
+		}
+	}
+	return items[survivor], duplicates
+}
+
+// mergeRetailerItems moves the references of duplicates to survivor, deletes
+// the duplicates and sets the survivor's identity hash, in one transaction
+func mergeRetailerItems(ctx context.Context, tables []itemRefTable, survivor legacyItem, duplicates []legacyItem) error {
+	tx, err := Pool().Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if len(duplicates) > 0 {
+		dupIDs := make([]string, len(duplicates))
+		for i, d := range duplicates {
+			dupIDs[i] = d.id
+		}
+		allIDs := append([]string{survivor.id}, dupIDs...)
+
+		for _, table := range tables {
+			if err := mergeItemRefs(ctx, tx, table, survivor.id, dupIDs, allIDs); err != nil {
+				return fmt.Errorf("failed to merge %s: %w", table.name, err)
+			}
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM retailer_items WHERE id = ANY($1)`, dupIDs); err != nil {
+			return fmt.Errorf("failed to delete duplicate items: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE retailer_items
+		SET identity_hash = $1, external_id = NULL, updated_at = $2
+		WHERE id = $3
+	`, survivor.identityHash, time.Now(), survivor.id)
+	if err != nil {
+		return fmt.Errorf("failed to set identity hash: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// existingItemRefTables returns the referencing tables present in the
+// database; some only exist once the feature writing them was enabled
+func existingItemRefTables(ctx context.Context) ([]itemRefTable, error) {
+	names := make([]string, len(itemRefTables))
+	for i, table := range itemRefTables {
+		names[i] = table.name
+	}
+
+	rows, err := Pool().Query(ctx, `
+		SELECT table_name FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_name = ANY($1)
+	`, names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list referencing tables: %w", err)
+	}
+	existing, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to list referencing tables: %w", err)
+	}
+
+	tables := make([]itemRefTable, 0, len(existing))
+	for _, table := range itemRefTables {
+		if slices.Contains(existing, table.name) {
+			tables = append(tables, table)
+		}
+	}
+	return tables, nil
+}
+
+// mergeItemRefs points the rows of table referencing a duplicate at the
+// survivor, first dropping rows that would collide with each other
+func mergeItemRefs(ctx context.Context, tx pgx.Tx, table itemRefTable, survivorID string, dupIDs, allIDs []string) error {
+	if table.derived {
+		_, err := tx.Exec(ctx, `DELETE FROM `+table.name+` WHERE retailer_item_id = ANY($1)`, dupIDs)
+		return err
+	}
+
+	if !table.shared {
+		partition := "true"
+		if len(table.unique) > 0 {
+			partition = strings.Join(table.unique, ", ")
+		}
+		orderBy := partition + ", retailer_item_id = $2 DESC"
+		if table.newest != "" {
+			orderBy += ", " + table.newest + " DESC NULLS LAST"
+		}
+
+		_, err := tx.Exec(ctx, `
+			DELETE FROM `+table.name+`
+			WHERE retailer_item_id = ANY($1)
+			  AND ctid NOT IN (
+				SELECT DISTINCT ON (`+partition+`) ctid
+				FROM `+table.name+`
+				WHERE retailer_item_id = ANY($1)
+				ORDER BY `+orderBy+`
+			  )
+		`, allIDs, survivorID)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := tx.Exec(ctx, `
+		UPDATE `+table.name+` SET retailer_item_id = $1 WHERE retailer_item_id = ANY($2)
+	`, survivorID, dupIDs)
+	return err
+}
+
+// ChainItemIDs returns, for each of the given retailer items, its ID in each
+// of the given chains: the item itself in its own chain, otherwise an item
+// linked to the same product. Items and chains without an equivalent are
+// absent from the result.
+func ChainItemIDs(ctx context.Context, itemIDs, chainSlugs []string) (map[string]map[string]string, error) {
+	rows, err := Pool().Query(ctx, `
+		SELECT DISTINCT ON (src.id, ri.chain_slug) src.id, ri.chain_slug, ri.id
+		FROM retailer_items src
+		LEFT JOIN product_links pl ON pl.retailer_item_id = src.id
+		LEFT JOIN product_links peer ON peer.product_id = pl.product_id
+		JOIN retailer_items ri ON ri.id = src.id OR ri.id = peer.retailer_item_id
+		WHERE src.id = ANY($1) AND ri.chain_slug = ANY($2)
+		ORDER BY src.id, ri.chain_slug, (ri.id = src.id) DESC, ri.id
+	`, itemIDs, chainSlugs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve chain item IDs: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]map[string]string, len(itemIDs))
+	for rows.Next() {
+		var itemID, chainSlug, chainItemID string
+		if err := rows.Scan(&itemID, &chainSlug, &chainItemID); err != nil {
+			return nil, fmt.Errorf("failed to scan chain item ID: %w", err)
+		}
+		if result[itemID] == nil {
+			result[itemID] = make(map[string]string)
+		}
+		result[itemID][chainSlug] = chainItemID
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,33 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetailerItemIdentityHash(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+
+	base := RetailerItemIdentityHash("Mlijeko 2,8% m.m.", ptr("L"), ptr("1,5"))
+	assert.Equal(t, base, RetailerItemIdentityHash("  mlijeko  2,8%   M.M. ", ptr(" l"), ptr("1.5")))
+
+	assert.NotEqual(t, base, RetailerItemIdentityHash("Mlijeko 2,8% m.m.", ptr("L"), ptr("1")))
+	assert.NotEqual(t, base, RetailerItemIdentityHash("Mlijeko 2,8% m.m.", nil, ptr("1,5")))
+	assert.NotEqual(t, base, RetailerItemIdentityHash("Mlijeko 3,2% m.m.", ptr("L"), ptr("1,5")))
+	assert.NotEqual(t,
+		RetailerItemIdentityHash("a b", nil, nil),
+		RetailerItemIdentityHash("a", ptr("b"), nil),
+		"fields are separated")
+}
+
+func TestPickSurvivor(t *testing.T) {
+	items := []legacyItem{{id: "itm_1"}, {id: "itm_2", hasHash: true}, {id: "itm_3"}}
+	survivor, duplicates := pickSurvivor(items)
+	assert.Equal(t, "itm_2", survivor.id, "the item lookups already find survives")
+	assert.Equal(t, []legacyItem{{id: "itm_1"}, {id: "itm_3"}}, duplicates)
+
+	survivor, duplicates = pickSurvivor([]legacyItem{{id: "itm_1"}, {id: "itm_2"}})
+	assert.Equal(t, "itm_1", survivor.id, "otherwise the oldest")
+	assert.Len(t, duplicates, 1)
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	// maxTxAttempts bounds how often WithRetry runs a transaction
+	maxTxAttempts = 3
+	// baseRetryDelay is the delay before the first retry; it doubles per
+	// attempt and gets up to as much again of random jitter
+	baseRetryDelay = 50 * time.Millisecond
+)
+
+var txRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "db_transaction_retries_total",
+	Help: "Transactions retried after a serialization failure or deadlock by operation",
+}, []string{"operation"})
+
+// IsRetryable reports whether err is a serialization failure or deadlock,
+// after which the whole transaction can be retried
+func IsRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
+
+// WithRetry runs fn, running it again after a jittered backoff while it
+// fails with a serialization failure or deadlock. fn must begin and commit
+// its own transaction so every attempt starts from scratch. Retries are
+// counted in db_transaction_retries_total under operation.
+func WithRetry(ctx context.Context, operation string, fn func(ctx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil || !IsRetryable(err) || attempt == maxTxAttempts {
+			return err
+		}
+
+		txRetries.WithLabelValues(operation).Inc()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retryDelay(attempt)):
+		}
+	}
+}
+
+// retryDelay returns the backoff after the given failed attempt. The jitter
+// keeps two transactions that deadlocked each other from retrying in step.
+func retryDelay(attempt int) time.Duration {
+	delay := baseRetryDelay << (attempt - 1)
+	return delay + rand.N(delay)
+}
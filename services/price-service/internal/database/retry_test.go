@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRetry(t *testing.T) {
+	deadlock := fmt.Errorf("failed to upsert: %w", &pgconn.PgError{Code: "40P01"})
+	before := testutil.ToFloat64(txRetries.WithLabelValues("test"))
+
+	calls := 0
+	err := WithRetry(context.Background(), "test", func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			return deadlock
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, before+1, testutil.ToFloat64(txRetries.WithLabelValues("test")))
+
+	calls = 0
+	err = WithRetry(context.Background(), "test", func(ctx context.Context) error {
+		calls++
+		return deadlock
+	})
+	assert.ErrorIs(t, err, deadlock)
+	assert.Equal(t, maxTxAttempts, calls, "gives up after the last attempt")
+
+	calls = 0
+	notRetryable := errors.New("constraint violation")
+	err = WithRetry(context.Background(), "test", func(ctx context.Context) error {
+		calls++
+		return notRetryable
+	})
+	assert.ErrorIs(t, err, notRetryable)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetryDelayJitter(t *testing.T) {
+	for attempt := 1; attempt <= maxTxAttempts; attempt++ {
+		base := baseRetryDelay << (attempt - 1)
+		for range 20 {
+			delay := retryDelay(attempt)
+			assert.GreaterOrEqual(t, delay, base)
+			assert.Less(t, delay, 2*base)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Webhook represents a webhooks row: a URL notified of ingestion run events.
+// The secret signing its deliveries is never returned after creation.
+type Webhook struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Secret      string    `json:"-"`
+	EventTypes  []string  `json:"eventTypes"` // Empty subscribes to every event
+	Enabled     bool      `json:"enabled"`
+	Description *string   `json:"description"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// WebhookDelivery represents a webhook_deliveries row: one event sent to one webhook
+type WebhookDelivery struct {
+	ID          int64           `json:"id"`
+	WebhookID   string          `json:"webhookId"`
+	EventType   string          `json:"eventType"`
+	AggregateID string          `json:"aggregateId"`
+	Payload     json.RawMessage `json:"payload" swaggertype:"object"`
+	Attempts    int             `json:"attempts"`
+	AvailableAt time.Time       `json:"availableAt"` // Next attempt while pending
+	DeliveredAt *time.Time      `json:"deliveredAt"`
+	FailedAt    *time.Time      `json:"failedAt"` // Set when the attempts ran out
+	LastStatus  *int            `json:"lastStatus"`
+	LastError   *string         `json:"lastError"`
+	CreatedAt   time.Time       `json:"createdAt"`
+}
+
+const webhookColumns = `id, url, secret, event_types, enabled, description, created_at, updated_at`
+
+func scanWebhook(row pgx.Row) (*Webhook, error) {
+	var w Webhook
+	err := row.Scan(&w.ID, &w.URL, &w.Secret, &w.EventTypes, &w.Enabled, &w.Description, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// ListWebhooks returns every webhook, oldest first
+func ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	rows, err := Pool().Query(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := make([]Webhook, 0)
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, *w)
+	}
+	return webhooks, rows.Err()
+}
+
+// GetWebhook returns a webhook, nil when it does not exist
+func GetWebhook(ctx context.Context, id string) (*Webhook, error) {
+	w, err := scanWebhook(Pool().QueryRow(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return w, err
+}
+
+// CreateWebhook inserts a webhook
+func CreateWebhook(ctx context.Context, w *Webhook) (*Webhook, error) {
+	return scanWebhook(Pool().QueryRow(ctx, `
+		INSERT INTO webhooks (id, url, secret, event_types, enabled, description, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		RETURNING `+webhookColumns, w.ID, w.URL, w.Secret, w.EventTypes, w.Enabled, w.Description))
+}
+
+// UpdateWebhook replaces a webhook's settings, keeping its secret when
+// w.Secret is empty. Returns nil when the webhook does not exist.
+func UpdateWebhook(ctx context.Context, w *Webhook) (*Webhook, error) {
+	updated, err := scanWebhook(Pool().QueryRow(ctx, `
+		UPDATE webhooks
+		SET url = $2,
+		    secret = COALESCE(NULLIF($3, ''), secret),
+		    event_types = $4,
+		    enabled = $5,
+		    description = $6,
+		    updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+webhookColumns, w.ID, w.URL, w.Secret, w.EventTypes, w.Enabled, w.Description))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return updated, err
+}
+
+// DeleteWebhook removes a webhook and its deliveries, reporting whether it existed
+func DeleteWebhook(ctx context.Context, id string) (bool, error) {
+	tag, err := Pool().Exec(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListWebhookDeliveries returns a webhook's most recent deliveries, newest first
+func ListWebhookDeliveries(ctx context.Context, webhookID string, limit int) ([]WebhookDelivery, error) {
+	rows, err := Pool().Query(ctx, `
+		SELECT id, webhook_id, event_type, aggregate_id, payload, attempts, available_at,
+		       delivered_at, failed_at, last_status, last_error, created_at
+		FROM webhook_deliveries
+		WHERE webhook_id = $1
+		ORDER BY id DESC
+		LIMIT $2
+	`, webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]WebhookDelivery, 0)
+	for rows.Next() {
+		var d WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.EventType, &d.AggregateID, &d.Payload, &d.Attempts, &d.AvailableAt,
+			&d.DeliveredAt, &d.FailedAt, &d.LastStatus, &d.LastError, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
@@ -0,0 +1,123 @@
+// Package events publishes per-item price change events to a message broker
+// (Kafka or NATS) so downstream consumers can react without polling.
+//
+// Publishing is best effort and happens after persist has committed; consumers
+// that need guaranteed delivery should use the outbox webhooks instead.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// PriceChangeSchemaVersion is the version of the PriceChangeEvent schema.
+// Bump it on breaking changes; it is part of the topic/subject name and sent
+// in every message header so consumers can handle both during migrations.
+const PriceChangeSchemaVersion = 1
+
+// SchemaVersionHeader carries the schema version of a message
+const SchemaVersionHeader = "schema-version"
+
+// DefaultPriceChangeTopic is the topic (Kafka) or subject (NATS) prefix for
+// price change events; the schema version is appended as ".v<N>"
+const DefaultPriceChangeTopic = "kosarica.prices.changed"
+
+// PriceChangeEvent describes a change of an item's price or discount in a store
+type PriceChangeEvent struct {
+	SchemaVersion    int        `json:"schemaVersion"`
+	OccurredAt       time.Time  `json:"occurredAt"`
+	RunID            string     `json:"runId"`
+	FileID           string     `json:"fileId"`
+	ChainSlug        string     `json:"chainSlug"`
+	StoreID          string     `json:"storeId"`
+	RetailerItemID   string     `json:"retailerItemId"`
+	OldPrice         *int       `json:"oldPrice"` // nil for items seen for the first time
+	NewPrice         int        `json:"newPrice"`
+	OldDiscountPrice *int       `json:"oldDiscountPrice"`
+	NewDiscountPrice *int       `json:"newDiscountPrice"`
+	DiscountStart    *time.Time `json:"discountStart,omitempty"`
+	DiscountEnd      *time.Time `json:"discountEnd,omitempty"`
+}
+
+// Key returns the partitioning key of the event, keeping the changes of one
+// item in one store ordered
+func (e PriceChangeEvent) Key() string {
+	return e.StoreID + ":" + e.RetailerItemID
+}
+
+// Publisher publishes a batch of price change events, typically all changes
+// of one persisted file
+type Publisher interface {
+	PublishPriceChanges(ctx context.Context, batch []PriceChangeEvent) error
+	Close() error
+}
+
+// Topic returns the versioned topic or subject for price change events
+func Topic(prefix string) string {
+	if prefix == "" {
+		prefix = DefaultPriceChangeTopic
+	}
+	return prefix + ".v" + strconv.Itoa(PriceChangeSchemaVersion)
+}
+
+// encode marshals an event, stamping the current schema version
+func encode(event PriceChangeEvent) ([]byte, error) {
+	event.SchemaVersion = PriceChangeSchemaVersion
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal price change event: %w", err)
+	}
+	return data, nil
+}
+
+// NoopPublisher discards events; it is used when no broker is configured
+type NoopPublisher struct{}
+
+// PublishPriceChanges discards the batch
+func (NoopPublisher) PublishPriceChanges(ctx context.Context, batch []PriceChangeEvent) error {
+	return nil
+}
+
+// Close is a no-op
+func (NoopPublisher) Close() error { return nil }
+
+// NewPublisher creates the publisher for a driver: "kafka", "nats", or "" for
+// a NoopPublisher. topic is the topic or subject prefix.
+func NewPublisher(driver string, kafkaBrokers []string, natsURL string, topic string) (Publisher, error) {
+	switch driver {
+	case "":
+		return NoopPublisher{}, nil
+	case "kafka":
+		return NewKafkaPublisher(kafkaBrokers, topic)
+	case "nats":
+		return NewNATSPublisher(natsURL, topic)
+	default:
+		return nil, fmt.Errorf("unknown event publisher driver: %s", driver)
+	}
+}
+
+var (
+	defaultMu        sync.RWMutex
+	defaultPublisher Publisher = NoopPublisher{}
+)
+
+// SetDefault installs the publisher used by the ingestion pipeline
+func SetDefault(p Publisher) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if p == nil {
+		p = NoopPublisher{}
+	}
+	defaultPublisher = p
+}
+
+// Default returns the publisher used by the ingestion pipeline
+func Default() Publisher {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultPublisher
+}
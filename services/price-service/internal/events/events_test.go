@@ -0,0 +1,36 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopicIsVersioned(t *testing.T) {
+	assert.Equal(t, "kosarica.prices.changed.v1", Topic(""))
+	assert.Equal(t, "custom.v1", Topic("custom"))
+}
+
+func TestEncodeStampsSchemaVersion(t *testing.T) {
+	oldPrice := 199
+	data, err := encode(PriceChangeEvent{
+		StoreID:        "sto_1",
+		RetailerItemID: "rit_1",
+		OldPrice:       &oldPrice,
+		NewPrice:       249,
+	})
+	require.NoError(t, err)
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.EqualValues(t, PriceChangeSchemaVersion, decoded["schemaVersion"])
+	assert.EqualValues(t, 199, decoded["oldPrice"])
+	assert.EqualValues(t, 249, decoded["newPrice"])
+	assert.Nil(t, decoded["oldDiscountPrice"])
+}
+
+func TestEventKey(t *testing.T) {
+	assert.Equal(t, "sto_1:rit_1", PriceChangeEvent{StoreID: "sto_1", RetailerItemID: "rit_1"}.Key())
+}
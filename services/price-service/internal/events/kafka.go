@@ -0,0 +1,63 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaPublisher writes price change events to a Kafka topic, one message per
+// event keyed by store and item
+type KafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaPublisher creates a publisher writing to the versioned topic derived
+// from topicPrefix
+func NewKafkaPublisher(brokers []string, topicPrefix string) (*KafkaPublisher, error) {
+	if len(brokers) == 0 {
+		return nil, fmt.Errorf("kafka publisher requires at least one broker")
+	}
+
+	return &KafkaPublisher{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        Topic(topicPrefix),
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+		},
+	}, nil
+}
+
+// PublishPriceChanges writes the batch in a single produce call
+func (p *KafkaPublisher) PublishPriceChanges(ctx context.Context, batch []PriceChangeEvent) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	version := []byte(strconv.Itoa(PriceChangeSchemaVersion))
+	messages := make([]kafka.Message, 0, len(batch))
+	for _, event := range batch {
+		data, err := encode(event)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, kafka.Message{
+			Key:     []byte(event.Key()),
+			Value:   data,
+			Headers: []kafka.Header{{Key: SchemaVersionHeader, Value: version}},
+		})
+	}
+
+	if err := p.writer.WriteMessages(ctx, messages...); err != nil {
+		return fmt.Errorf("failed to write %d price change events to kafka: %w", len(messages), err)
+	}
+	return nil
+}
+
+// Close flushes pending writes and closes the writer
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
@@ -0,0 +1,63 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSPublisher publishes price change events to a NATS subject, one message
+// per event, flushing once per batch
+type NATSPublisher struct {
+	conn    *nats.Conn
+	subject string
+}
+
+// NewNATSPublisher connects to NATS and publishes to the versioned subject
+// derived from subjectPrefix
+func NewNATSPublisher(url string, subjectPrefix string) (*NATSPublisher, error) {
+	conn, err := nats.Connect(url, nats.Name("price-service"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+
+	return &NATSPublisher{
+		conn:    conn,
+		subject: Topic(subjectPrefix),
+	}, nil
+}
+
+// PublishPriceChanges publishes the batch and waits for the server to
+// acknowledge the flush
+func (p *NATSPublisher) PublishPriceChanges(ctx context.Context, batch []PriceChangeEvent) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	version := strconv.Itoa(PriceChangeSchemaVersion)
+	for _, event := range batch {
+		data, err := encode(event)
+		if err != nil {
+			return err
+		}
+		msg := nats.NewMsg(p.subject)
+		msg.Data = data
+		msg.Header.Set(SchemaVersionHeader, version)
+		msg.Header.Set("key", event.Key())
+		if err := p.conn.PublishMsg(msg); err != nil {
+			return fmt.Errorf("failed to publish price change event to nats: %w", err)
+		}
+	}
+
+	if err := p.conn.FlushWithContext(ctx); err != nil {
+		return fmt.Errorf("failed to flush nats connection: %w", err)
+	}
+	return nil
+}
+
+// Close drains and closes the connection
+func (p *NATSPublisher) Close() error {
+	return p.conn.Drain()
+}
@@ -0,0 +1,152 @@
+// Package featureflags gates risky features behind flags stored in the
+// feature_flags table. Flags can be limited to a set of chains and forced on
+// or off per request through the X-Feature-Flags header.
+package featureflags
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kosarica/price-service/internal/database"
+)
+
+// Well-known flags
+const (
+	OptimizerNewAlgorithms  = "optimizer_new_algorithms"
+	NewAdapters             = "new_adapters"
+	LoyaltyPricing          = "loyalty_pricing"
+	IncrementalCacheRefresh = "incremental_cache_refresh"
+	ParseProfiling          = "parse_profiling"
+	StagedIngestion         = "staged_ingestion"
+)
+
+// HeaderName is the request header carrying per-request overrides, as a
+// comma-separated list of flag keys; a leading '-' forces a flag off.
+// Example: "optimizer_new_algorithms,-loyalty_pricing"
+const HeaderName = "X-Feature-Flags"
+
+// CacheTTL is how long flags loaded from the database are reused
+const CacheTTL = 30 * time.Second
+
+// refreshTimeout bounds the lazy database refresh done on lookup
+const refreshTimeout = 2 * time.Second
+
+var cache struct {
+	mu       sync.RWMutex
+	flags    map[string]database.FeatureFlag
+	loadedAt time.Time
+}
+
+type overridesKey struct{}
+
+// ParseOverrides parses the X-Feature-Flags header value
+func ParseOverrides(header string) map[string]bool {
+	overrides := make(map[string]bool)
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if strings.HasPrefix(part, "-") {
+			if key := strings.TrimSpace(part[1:]); key != "" {
+				overrides[key] = false
+			}
+			continue
+		}
+		overrides[strings.TrimPrefix(part, "+")] = true
+	}
+	return overrides
+}
+
+// WithOverrides returns a context carrying per-request flag overrides
+func WithOverrides(ctx context.Context, overrides map[string]bool) context.Context {
+	if len(overrides) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, overridesKey{}, overrides)
+}
+
+// IsEnabled reports whether a flag is on for the given chain.
+// Request overrides in ctx take precedence; chainSlug may be empty when the
+// caller is not chain-scoped, in which case chain-limited flags are off.
+func IsEnabled(ctx context.Context, key, chainSlug string) bool {
+	if overrides, ok := ctx.Value(overridesKey{}).(map[string]bool); ok {
+		if on, ok := overrides[key]; ok {
+			return on
+		}
+	}
+
+	flag, ok := lookup(key)
+	if !ok {
+		return false
+	}
+	return evaluate(flag, chainSlug)
+}
+
+// evaluate applies a flag's stored rules to a chain
+func evaluate(flag database.FeatureFlag, chainSlug string) bool {
+	if !flag.Enabled {
+		return false
+	}
+	if len(flag.Chains) == 0 {
+		return true
+	}
+	for _, c := range flag.Chains {
+		if c == chainSlug {
+			return true
+		}
+	}
+	return false
+}
+
+func lookup(key string) (database.FeatureFlag, bool) {
+	cache.mu.RLock()
+	flags, loadedAt := cache.flags, cache.loadedAt
+	cache.mu.RUnlock()
+
+	if flags == nil || time.Since(loadedAt) >= CacheTTL {
+		ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+		defer cancel()
+		if err := Refresh(ctx); err == nil {
+			cache.mu.RLock()
+			flags = cache.flags
+			cache.mu.RUnlock()
+		}
+	}
+
+	flag, ok := flags[key]
+	return flag, ok
+}
+
+// Refresh reloads all flags from the database into the cache
+func Refresh(ctx context.Context) error {
+	if database.Pool() == nil {
+		return fmt.Errorf("database not connected")
+	}
+
+	rows, err := database.ListFeatureFlags(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load feature flags: %w", err)
+	}
+
+	flags := make(map[string]database.FeatureFlag, len(rows))
+	for _, flag := range rows {
+		flags[flag.Key] = flag
+	}
+
+	cache.mu.Lock()
+	cache.flags = flags
+	cache.loadedAt = time.Now()
+	cache.mu.Unlock()
+	return nil
+}
+
+// Invalidate drops the cached flags so the next lookup reloads them
+func Invalidate() {
+	cache.mu.Lock()
+	cache.loadedAt = time.Time{}
+	cache.mu.Unlock()
+}
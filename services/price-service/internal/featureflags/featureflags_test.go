@@ -0,0 +1,53 @@
+package featureflags
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOverrides(t *testing.T) {
+	overrides := ParseOverrides(" optimizer_new_algorithms, -loyalty_pricing,,+new_adapters, - ")
+
+	assert.Equal(t, map[string]bool{
+		"optimizer_new_algorithms": true,
+		"loyalty_pricing":          false,
+		"new_adapters":             true,
+	}, overrides)
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name  string
+		flag  database.FeatureFlag
+		chain string
+		want  bool
+	}{
+		{"disabled", database.FeatureFlag{Enabled: false}, "konzum", false},
+		{"enabled for all chains", database.FeatureFlag{Enabled: true}, "konzum", true},
+		{"enabled without chain", database.FeatureFlag{Enabled: true}, "", true},
+		{"chain in rollout", database.FeatureFlag{Enabled: true, Chains: []string{"lidl", "konzum"}}, "konzum", true},
+		{"chain outside rollout", database.FeatureFlag{Enabled: true, Chains: []string{"lidl"}}, "konzum", false},
+		{"chain-limited without chain", database.FeatureFlag{Enabled: true, Chains: []string{"lidl"}}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, evaluate(tt.flag, tt.chain))
+		})
+	}
+}
+
+func TestIsEnabledOverrides(t *testing.T) {
+	ctx := WithOverrides(context.Background(), map[string]bool{
+		LoyaltyPricing: true,
+		NewAdapters:    false,
+	})
+
+	assert.True(t, IsEnabled(ctx, LoyaltyPricing, "konzum"))
+	assert.False(t, IsEnabled(ctx, NewAdapters, "konzum"))
+	// Unknown flags are off when the database is unavailable
+	assert.False(t, IsEnabled(ctx, IncrementalCacheRefresh, "konzum"))
+}
@@ -0,0 +1,103 @@
+package graph
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kosarica/price-service/internal/graph/model"
+	"github.com/kosarica/price-service/internal/optimizer"
+)
+
+// cachePriceSource is a PriceSource backed by a map keyed by store and item
+type cachePriceSource map[[2]string]optimizer.CachedPrice
+
+func (c cachePriceSource) GetPrice(chainSlug string, storeID, itemID string) (optimizer.CachedPrice, bool) {
+	p, ok := c[[2]string{storeID, itemID}]
+	return p, ok
+}
+
+func (c cachePriceSource) GetAveragePrice(chainSlug string, itemID string) int64 { return 0 }
+
+func (c cachePriceSource) GetWeightedAveragePrice(chainSlug string, itemID string) int64 { return 0 }
+
+func (c cachePriceSource) GetItemCategory(chainSlug string, itemID string) (string, bool) {
+	return "", false
+}
+
+func (c cachePriceSource) GetCategoryAveragePrice(chainSlug string, category string) int64 { return 0 }
+
+func (c cachePriceSource) GetItemPriceStats(chainSlug string, itemID string) (optimizer.ItemPriceStats, bool) {
+	return optimizer.ItemPriceStats{}, false
+}
+
+func (c cachePriceSource) GetNearestStores(chainSlug string, lat, lon, maxDistanceKm float64, limit int) []optimizer.StoreWithDistance {
+	return nil
+}
+
+func (c cachePriceSource) GetStoreIDs(chainSlug string) []string { return nil }
+
+func (c cachePriceSource) IsHealthy(ctx context.Context) bool { return true }
+
+func TestClampLimit(t *testing.T) {
+	ten, huge, negative := 10, 10000, -1
+	assert.Equal(t, 20, clampLimit(nil, 20, 100))
+	assert.Equal(t, 10, clampLimit(&ten, 20, 100))
+	assert.Equal(t, 100, clampLimit(&huge, 20, 100))
+	assert.Equal(t, 20, clampLimit(&negative, 20, 100))
+}
+
+func TestGroupByKeyKeepsKeyOrder(t *testing.T) {
+	query := func(ctx context.Context, keys []string) ([]*barcode, error) {
+		return []*barcode{
+			{RetailerItemID: "b", Barcode: "2"},
+			{RetailerItemID: "a", Barcode: "1"},
+			{RetailerItemID: "b", Barcode: "3"},
+		}, nil
+	}
+	fetch := groupByKey(query,
+		func(b *barcode) string { return b.RetailerItemID },
+		func(b *barcode) string { return b.Barcode },
+	)
+
+	result, errs := fetch(context.Background(), []string{"a", "b", "c"})
+	require.Nil(t, errs)
+	assert.Equal(t, [][]string{{"1"}, {"2", "3"}, {}}, result)
+}
+
+func TestPricesForServesCacheHitsWithoutDatabase(t *testing.T) {
+	r := &Resolver{
+		repo: &repository{}, // no pool: a database query would panic
+		prices: cachePriceSource{
+			{"sto_1", "rit_1"}: {Price: 199},
+			{"sto_2", "rit_1"}: {Price: 249, DiscountPrice: 219, HasDiscount: true},
+		},
+	}
+
+	prices, err := r.pricesFor(context.Background(), "konzum", []string{"sto_1", "sto_2"}, []string{"rit_1"})
+	require.NoError(t, err)
+	require.Len(t, prices, 2)
+
+	assert.Equal(t, 199, prices[0].Price)
+	assert.Nil(t, prices[0].DiscountPrice)
+	assert.Equal(t, 249, prices[1].Price)
+	require.NotNil(t, prices[1].DiscountPrice)
+	assert.Equal(t, 219, *prices[1].DiscountPrice)
+}
+
+func TestStoresByIDServesCachedStoresWithoutDatabase(t *testing.T) {
+	repo := &repository{} // no pool: a database query would panic
+	repo.stores.put([]*model.Store{{ID: "s1", Name: "Konzum Ilica"}, {ID: "s2", Name: "Konzum Vukovarska"}})
+
+	stores, err := repo.storesByID(context.Background(), []string{"s2", "s1"})
+	require.NoError(t, err)
+	require.Len(t, stores, 2)
+	assert.Equal(t, "Konzum Vukovarska", stores[0].Name)
+
+	repo.stores.entries["s1"] = cachedStore{store: stores[1], loadedAt: time.Now().Add(-storeCacheTTL)}
+	_, missing := repo.stores.get([]string{"s1", "s2"})
+	assert.Equal(t, []string{"s1"}, missing, "expired entries are reloaded")
+}
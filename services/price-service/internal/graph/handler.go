@@ -0,0 +1,34 @@
+// Package graph serves a read-only GraphQL API over products, retailer items,
+// stores and prices for clients that need nested joins in one round trip.
+// Nested fields are batched per request with dataloaders.
+package graph
+
+//go:generate go run github.com/99designs/gqlgen generate --config ../../gqlgen.yml
+
+import (
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kosarica/price-service/internal/optimizer"
+)
+
+// maxQueryComplexity bounds the cost of a single query
+const maxQueryComplexity = 500
+
+// NewHandler returns the GraphQL endpoint handler. prices may be nil, in
+// which case all prices are read from the database.
+func NewHandler(pool *pgxpool.Pool, prices optimizer.PriceSource) gin.HandlerFunc {
+	repo := &repository{db: pool}
+	srv := handler.New(NewExecutableSchema(Config{Resolvers: &Resolver{repo: repo, prices: prices}}))
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.Use(extension.Introspection{})
+	srv.Use(extension.FixedComplexityLimit(maxQueryComplexity))
+
+	h := loadersMiddleware(repo, srv)
+	return func(c *gin.Context) {
+		h.ServeHTTP(c.Writer, c.Request)
+	}
+}
@@ -0,0 +1,136 @@
+package graph
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/vikstrous/dataloadgen"
+
+	"github.com/kosarica/price-service/internal/graph/model"
+)
+
+// loaderWait is how long a loader collects keys before running its batch
+const loaderWait = 2 * time.Millisecond
+
+// Loaders batches the per-object lookups of one request so nested queries
+// cost one SQL query per level instead of one per object
+type Loaders struct {
+	ProductByID              *dataloadgen.Loader[string, *model.Product]
+	ProductByRetailerItemID  *dataloadgen.Loader[string, *model.Product]
+	RetailerItemByID         *dataloadgen.Loader[string, *model.RetailerItem]
+	RetailerItemsByProductID *dataloadgen.Loader[string, []*model.RetailerItem]
+	BarcodesByRetailerItemID *dataloadgen.Loader[string, []string]
+	StoreByID                *dataloadgen.Loader[string, *model.Store]
+	ChainBySlug              *dataloadgen.Loader[string, *model.Chain]
+	PricesByRetailerItemID   *dataloadgen.Loader[string, []*model.StorePrice]
+}
+
+// newLoaders creates a fresh set of loaders; loaders cache results and must
+// not be shared between requests
+func newLoaders(repo *repository) *Loaders {
+	wait := dataloadgen.WithWait(loaderWait)
+
+	return &Loaders{
+		ProductByID: dataloadgen.NewLoader(byKey(repo.productsByID, func(p *model.Product) string { return p.ID }), wait),
+		ProductByRetailerItemID: dataloadgen.NewLoader(func(ctx context.Context, itemIDs []string) ([]*model.Product, []error) {
+			links, err := repo.productLinksByRetailerItemID(ctx, itemIDs)
+			if err != nil {
+				return nil, []error{err}
+			}
+			productIDs := make([]string, 0, len(links))
+			productOf := make(map[string]string, len(links))
+			for _, l := range links {
+				productIDs = append(productIDs, l.ProductID)
+				productOf[l.RetailerItemID] = l.ProductID
+			}
+			products, err := repo.productsByID(ctx, productIDs)
+			if err != nil {
+				return nil, []error{err}
+			}
+			byID := make(map[string]*model.Product, len(products))
+			for _, p := range products {
+				byID[p.ID] = p
+			}
+			result := make([]*model.Product, len(itemIDs))
+			for i, id := range itemIDs {
+				result[i] = byID[productOf[id]]
+			}
+			return result, nil
+		}, wait),
+		RetailerItemByID: dataloadgen.NewLoader(byKey(repo.retailerItemsByID, func(ri *model.RetailerItem) string { return ri.ID }), wait),
+		RetailerItemsByProductID: dataloadgen.NewLoader(groupByKey(repo.retailerItemsByProductID,
+			func(l *linkedRetailerItem) string { return l.ProductID },
+			func(l *linkedRetailerItem) *model.RetailerItem { return l.Item },
+		), wait),
+		BarcodesByRetailerItemID: dataloadgen.NewLoader(groupByKey(repo.barcodesByRetailerItemID,
+			func(b *barcode) string { return b.RetailerItemID },
+			func(b *barcode) string { return b.Barcode },
+		), wait),
+		StoreByID:   dataloadgen.NewLoader(byKey(repo.storesByID, func(s *model.Store) string { return s.ID }), wait),
+		ChainBySlug: dataloadgen.NewLoader(byKey(repo.chainsBySlug, func(c *model.Chain) string { return c.Slug }), wait),
+		PricesByRetailerItemID: dataloadgen.NewLoader(groupByKey(repo.pricesByRetailerItemID,
+			func(sp *model.StorePrice) string { return sp.RetailerItemID },
+			func(sp *model.StorePrice) *model.StorePrice { return sp },
+		), wait),
+	}
+}
+
+// byKey adapts a query returning at most one row per key into a batch
+// function; missing keys resolve to nil
+func byKey[T any](query func(context.Context, []string) ([]*T, error), key func(*T) string) func(context.Context, []string) ([]*T, []error) {
+	return func(ctx context.Context, keys []string) ([]*T, []error) {
+		rows, err := query(ctx, keys)
+		if err != nil {
+			return nil, []error{err}
+		}
+		byKey := make(map[string]*T, len(rows))
+		for _, row := range rows {
+			byKey[key(row)] = row
+		}
+		result := make([]*T, len(keys))
+		for i, k := range keys {
+			result[i] = byKey[k]
+		}
+		return result, nil
+	}
+}
+
+// groupByKey adapts a query returning many rows per key into a batch function
+// returning one slice per key, keeping the query's row order
+func groupByKey[R any, V any](query func(context.Context, []string) ([]*R, error), key func(*R) string, value func(*R) V) func(context.Context, []string) ([][]V, []error) {
+	return func(ctx context.Context, keys []string) ([][]V, []error) {
+		rows, err := query(ctx, keys)
+		if err != nil {
+			return nil, []error{err}
+		}
+		grouped := make(map[string][]V, len(keys))
+		for _, row := range rows {
+			k := key(row)
+			grouped[k] = append(grouped[k], value(row))
+		}
+		result := make([][]V, len(keys))
+		for i, k := range keys {
+			result[i] = grouped[k]
+			if result[i] == nil {
+				result[i] = []V{}
+			}
+		}
+		return result, nil
+	}
+}
+
+type loadersKey struct{}
+
+// loadersMiddleware attaches a new set of loaders to every request
+func loadersMiddleware(repo *repository, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), loadersKey{}, newLoaders(repo))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// loadersFor returns the loaders of the current request
+func loadersFor(ctx context.Context) *Loaders {
+	return ctx.Value(loadersKey{}).(*Loaders)
+}
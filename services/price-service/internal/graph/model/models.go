@@ -0,0 +1,66 @@
+// Package model holds the GraphQL read models. Fields without a struct
+// counterpart (relations) are resolved by the graph package.
+package model
+
+import "time"
+
+// Chain is a retail chain with its display metadata
+type Chain struct {
+	Slug        string
+	Name        string
+	DisplayName string
+	LogoURL     *string
+	BrandColor  *string
+	Website     *string
+}
+
+// Product is a canonical catalog product
+type Product struct {
+	ID           string
+	Name         string
+	Description  *string
+	Brand        *string
+	Category     *string
+	Subcategory  *string
+	Unit         *string
+	UnitQuantity *string
+	ImageURL     *string
+}
+
+// RetailerItem is a chain-specific item as published in price lists
+type RetailerItem struct {
+	ID           string
+	ChainSlug    *string
+	Name         string
+	ExternalID   *string
+	Brand        *string
+	Category     *string
+	Unit         *string
+	UnitQuantity *string
+	ImageURL     *string
+}
+
+// Store is a physical or virtual store of a chain
+type Store struct {
+	ID         string
+	ChainSlug  string
+	Name       string
+	Address    *string
+	City       *string
+	PostalCode *string
+	Latitude   *string
+	Longitude  *string
+}
+
+// StorePrice is the current price of a retailer item in a store
+type StorePrice struct {
+	StoreID        string
+	RetailerItemID string
+	Price          int
+	DiscountPrice  *int
+	UnitPrice      *int
+	LowestPrice30d *int
+	AnchorPrice    *int
+	InStock        bool
+	LastSeenAt     *time.Time
+}
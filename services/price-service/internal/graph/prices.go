@@ -0,0 +1,86 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"github.com/kosarica/price-service/internal/graph/model"
+)
+
+// Upper bounds for list arguments
+const (
+	maxSearchLimit = 100
+	maxStoresLimit = 500
+	maxPricesLimit = 500
+)
+
+// clampLimit returns the limit argument, or def when unset, capped at max
+func clampLimit(limit *int, def, max int) int {
+	if limit == nil || *limit <= 0 {
+		return def
+	}
+	if *limit > max {
+		return max
+	}
+	return *limit
+}
+
+// pricesFor returns the prices of the given items in the given stores. Pairs
+// found in the price cache are served from memory; the rest are read from the
+// database in one query.
+func (r *Resolver) pricesFor(ctx context.Context, chainSlug string, storeIDs, itemIDs []string) ([]*model.StorePrice, error) {
+	if r.prices == nil || chainSlug == "" {
+		return r.repo.prices(ctx, storeIDs, itemIDs)
+	}
+
+	now := time.Now()
+	result := make([]*model.StorePrice, 0, len(storeIDs)*len(itemIDs))
+	missingStores := make(map[string]bool)
+	missingItems := make(map[string]bool)
+	for _, storeID := range storeIDs {
+		for _, itemID := range itemIDs {
+			cached, ok := r.prices.GetPrice(chainSlug, storeID, itemID)
+			if !ok {
+				missingStores[storeID] = true
+				missingItems[itemID] = true
+				continue
+			}
+			sp := &model.StorePrice{
+				StoreID:        storeID,
+				RetailerItemID: itemID,
+				Price:          int(cached.Price),
+				InStock:        true,
+			}
+			if cached.DiscountActive(now) {
+				discount := int(cached.DiscountPrice)
+				sp.DiscountPrice = &discount
+			}
+			result = append(result, sp)
+		}
+	}
+
+	if len(missingStores) == 0 {
+		return result, nil
+	}
+
+	// Missing pairs are usually items the cache does not hold at all, so a
+	// cross product of the missing stores and items is close enough
+	fallback, err := r.repo.prices(ctx, keys(missingStores), keys(missingItems))
+	if err != nil {
+		return nil, err
+	}
+	for _, sp := range fallback {
+		if _, ok := r.prices.GetPrice(chainSlug, sp.StoreID, sp.RetailerItemID); !ok {
+			result = append(result, sp)
+		}
+	}
+	return result, nil
+}
+
+func keys(m map[string]bool) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	return result
+}
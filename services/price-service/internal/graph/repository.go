@@ -0,0 +1,233 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kosarica/price-service/internal/chains"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/graph/model"
+)
+
+// repository runs the batched read queries behind the dataloaders
+type repository struct {
+	db     *pgxpool.Pool
+	stores storeCache
+}
+
+const productColumns = `p.id, p.name, p.description, p.brand, p.category, p.subcategory, p.unit, p.unit_quantity, p.image_url`
+
+func scanProduct(row pgx.Row) (*model.Product, error) {
+	var p model.Product
+	err := row.Scan(&p.ID, &p.Name, &p.Description, &p.Brand, &p.Category, &p.Subcategory, &p.Unit, &p.UnitQuantity, &p.ImageURL)
+	return &p, err
+}
+
+const retailerItemColumns = `ri.id, ri.chain_slug, ri.name, ri.external_id, ri.brand, ri.category, ri.unit, ri.unit_quantity, ri.image_url`
+
+func scanRetailerItem(row pgx.Row) (*model.RetailerItem, error) {
+	var ri model.RetailerItem
+	err := row.Scan(&ri.ID, &ri.ChainSlug, &ri.Name, &ri.ExternalID, &ri.Brand, &ri.Category, &ri.Unit, &ri.UnitQuantity, &ri.ImageURL)
+	return &ri, err
+}
+
+const storeColumns = `s.id, s.chain_slug, s.name, s.address, s.city, s.postal_code, s.latitude, s.longitude`
+
+func scanStore(row pgx.Row) (*model.Store, error) {
+	var s model.Store
+	err := row.Scan(&s.ID, &s.ChainSlug, &s.Name, &s.Address, &s.City, &s.PostalCode, &s.Latitude, &s.Longitude)
+	return &s, err
+}
+
+const storePriceColumns = `sis.store_id, sis.retailer_item_id, sis.current_price, sis.discount_price, sis.unit_price,
+	sis.lowest_price_30d, sis.anchor_price, COALESCE(sis.in_stock, true), sis.last_seen_at`
+
+func scanStorePrice(row pgx.Row) (*model.StorePrice, error) {
+	var sp model.StorePrice
+	err := row.Scan(&sp.StoreID, &sp.RetailerItemID, &sp.Price, &sp.DiscountPrice, &sp.UnitPrice,
+		&sp.LowestPrice30d, &sp.AnchorPrice, &sp.InStock, &sp.LastSeenAt)
+	return &sp, err
+}
+
+// queryRows runs a query and scans every row with scan
+func queryRows[T any](ctx context.Context, db *pgxpool.Pool, scan func(pgx.Row) (*T, error), query string, args ...any) ([]*T, error) {
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*T, 0)
+	for rows.Next() {
+		v, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+	}
+	return result, rows.Err()
+}
+
+func (r *repository) productsByID(ctx context.Context, ids []string) ([]*model.Product, error) {
+	return queryRows(ctx, r.db, scanProduct,
+		`SELECT `+productColumns+` FROM products p WHERE p.id = ANY($1)`, ids)
+}
+
+func (r *repository) searchProducts(ctx context.Context, query string, limit int) ([]*model.Product, error) {
+	return queryRows(ctx, r.db, scanProduct,
+		`SELECT `+productColumns+` FROM products p WHERE p.name ILIKE '%' || $1 || '%' ORDER BY p.name LIMIT $2`,
+		query, limit)
+}
+
+func (r *repository) retailerItemsByID(ctx context.Context, ids []string) ([]*model.RetailerItem, error) {
+	return queryRows(ctx, r.db, scanRetailerItem,
+		`SELECT `+retailerItemColumns+` FROM retailer_items ri WHERE ri.id = ANY($1)`, ids)
+}
+
+// linkedRetailerItem is a retailer item together with the product it is linked to
+type linkedRetailerItem struct {
+	ProductID string
+	Item      *model.RetailerItem
+}
+
+func (r *repository) retailerItemsByProductID(ctx context.Context, productIDs []string) ([]*linkedRetailerItem, error) {
+	return queryRows(ctx, r.db, func(row pgx.Row) (*linkedRetailerItem, error) {
+		var l linkedRetailerItem
+		var ri model.RetailerItem
+		err := row.Scan(&l.ProductID, &ri.ID, &ri.ChainSlug, &ri.Name, &ri.ExternalID, &ri.Brand, &ri.Category, &ri.Unit, &ri.UnitQuantity, &ri.ImageURL)
+		l.Item = &ri
+		return &l, err
+	}, `
+		SELECT pl.product_id, `+retailerItemColumns+`
+		FROM product_links pl
+		JOIN retailer_items ri ON ri.id = pl.retailer_item_id
+		WHERE pl.product_id = ANY($1)
+		ORDER BY ri.chain_slug, ri.name
+	`, productIDs)
+}
+
+// productLink maps a retailer item to its product
+type productLink struct {
+	RetailerItemID string
+	ProductID      string
+}
+
+func (r *repository) productLinksByRetailerItemID(ctx context.Context, itemIDs []string) ([]*productLink, error) {
+	return queryRows(ctx, r.db, func(row pgx.Row) (*productLink, error) {
+		var l productLink
+		err := row.Scan(&l.RetailerItemID, &l.ProductID)
+		return &l, err
+	}, `SELECT retailer_item_id, product_id FROM product_links WHERE retailer_item_id = ANY($1)`, itemIDs)
+}
+
+// barcode is a barcode of a retailer item
+type barcode struct {
+	RetailerItemID string
+	Barcode        string
+}
+
+func (r *repository) barcodesByRetailerItemID(ctx context.Context, itemIDs []string) ([]*barcode, error) {
+	return queryRows(ctx, r.db, func(row pgx.Row) (*barcode, error) {
+		var b barcode
+		err := row.Scan(&b.RetailerItemID, &b.Barcode)
+		return &b, err
+	}, `
+		SELECT retailer_item_id, barcode
+		FROM retailer_item_barcodes
+		WHERE retailer_item_id = ANY($1)
+		ORDER BY is_primary DESC, barcode
+	`, itemIDs)
+}
+
+func (r *repository) storesByID(ctx context.Context, ids []string) ([]*model.Store, error) {
+	found, missing := r.stores.get(ids)
+	if len(missing) == 0 {
+		return found, nil
+	}
+
+	loaded, err := queryRows(ctx, r.db, scanStore,
+		`SELECT `+storeColumns+` FROM stores s WHERE s.id = ANY($1)`, missing)
+	if err != nil {
+		return nil, err
+	}
+	r.stores.put(loaded)
+	return append(found, loaded...), nil
+}
+
+func (r *repository) storesByChain(ctx context.Context, chainSlug string, city *string, limit, offset int) ([]*model.Store, error) {
+	return queryRows(ctx, r.db, scanStore, `
+		SELECT `+storeColumns+`
+		FROM stores s
+		WHERE s.chain_slug = $1 AND ($2::text IS NULL OR s.city ILIKE $2)
+		ORDER BY s.name
+		LIMIT $3 OFFSET $4
+	`, chainSlug, city, limit, offset)
+}
+
+func (r *repository) pricesByRetailerItemID(ctx context.Context, itemIDs []string) ([]*model.StorePrice, error) {
+	return queryRows(ctx, r.db, scanStorePrice, `
+		SELECT `+storePriceColumns+`
+		FROM store_item_state sis
+		WHERE sis.retailer_item_id = ANY($1) AND sis.current_price IS NOT NULL
+		ORDER BY sis.retailer_item_id, sis.current_price
+	`, itemIDs)
+}
+
+func (r *repository) prices(ctx context.Context, storeIDs, itemIDs []string) ([]*model.StorePrice, error) {
+	return queryRows(ctx, r.db, scanStorePrice, `
+		SELECT `+storePriceColumns+`
+		FROM store_item_state sis
+		WHERE sis.store_id = ANY($1) AND sis.retailer_item_id = ANY($2) AND sis.current_price IS NOT NULL
+		ORDER BY sis.current_price
+	`, storeIDs, itemIDs)
+}
+
+func (r *repository) chains(ctx context.Context) ([]*model.Chain, error) {
+	rows, err := chains.List(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.Chain, 0, len(rows))
+	for i := range rows {
+		result = append(result, toChain(&rows[i]))
+	}
+	return result, nil
+}
+
+func (r *repository) chainsBySlug(ctx context.Context, slugs []string) ([]*model.Chain, error) {
+	// The chains table is tiny; filter the cached registry in memory
+	rows, err := chains.List(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(slugs))
+	for _, slug := range slugs {
+		wanted[slug] = true
+	}
+
+	result := make([]*model.Chain, 0, len(slugs))
+	for i := range rows {
+		if wanted[rows[i].Slug] {
+			result = append(result, toChain(&rows[i]))
+		}
+	}
+	return result, nil
+}
+
+func toChain(c *database.Chain) *model.Chain {
+	displayName := c.Name
+	if c.DisplayName != nil && *c.DisplayName != "" {
+		displayName = *c.DisplayName
+	}
+	return &model.Chain{
+		Slug:        c.Slug,
+		Name:        c.Name,
+		DisplayName: displayName,
+		LogoURL:     c.LogoURL,
+		BrandColor:  c.BrandColor,
+		Website:     c.Website,
+	}
+}
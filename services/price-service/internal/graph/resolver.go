@@ -0,0 +1,150 @@
+package graph
+
+// THIS CODE IS A STARTING POINT ONLY. IT WILL NOT BE UPDATED WITH SCHEMA CHANGES.
+
+import (
+	"context"
+
+	"github.com/kosarica/price-service/internal/graph/model"
+	"github.com/kosarica/price-service/internal/optimizer"
+)
+
+// Resolver resolves queries against the repositories through the per-request
+// loaders. prices is optional; when set, prices for explicit store/item pairs
+// are served from the in-memory price cache.
+type Resolver struct {
+	repo   *repository
+	prices optimizer.PriceSource
+}
+
+// RetailerItems is the resolver for the retailerItems field.
+func (r *productResolver) RetailerItems(ctx context.Context, obj *model.Product) ([]*model.RetailerItem, error) {
+	return loadersFor(ctx).RetailerItemsByProductID.Load(ctx, obj.ID)
+}
+
+// Product is the resolver for the product field.
+func (r *queryResolver) Product(ctx context.Context, id string) (*model.Product, error) {
+	return loadersFor(ctx).ProductByID.Load(ctx, id)
+}
+
+// Products is the resolver for the products field.
+func (r *queryResolver) Products(ctx context.Context, ids []string) ([]*model.Product, error) {
+	return loadersFor(ctx).ProductByID.LoadAll(ctx, ids)
+}
+
+// SearchProducts is the resolver for the searchProducts field.
+func (r *queryResolver) SearchProducts(ctx context.Context, query string, limit *int) ([]*model.Product, error) {
+	return r.repo.searchProducts(ctx, query, clampLimit(limit, 20, maxSearchLimit))
+}
+
+// RetailerItem is the resolver for the retailerItem field.
+func (r *queryResolver) RetailerItem(ctx context.Context, id string) (*model.RetailerItem, error) {
+	return loadersFor(ctx).RetailerItemByID.Load(ctx, id)
+}
+
+// Store is the resolver for the store field.
+func (r *queryResolver) Store(ctx context.Context, id string) (*model.Store, error) {
+	return loadersFor(ctx).StoreByID.Load(ctx, id)
+}
+
+// Stores is the resolver for the stores field.
+func (r *queryResolver) Stores(ctx context.Context, chainSlug string, city *string, limit *int, offset *int) ([]*model.Store, error) {
+	off := 0
+	if offset != nil && *offset > 0 {
+		off = *offset
+	}
+	return r.repo.storesByChain(ctx, chainSlug, city, clampLimit(limit, 100, maxStoresLimit), off)
+}
+
+// Chains is the resolver for the chains field.
+func (r *queryResolver) Chains(ctx context.Context) ([]*model.Chain, error) {
+	return r.repo.chains(ctx)
+}
+
+// Barcodes is the resolver for the barcodes field.
+func (r *retailerItemResolver) Barcodes(ctx context.Context, obj *model.RetailerItem) ([]string, error) {
+	return loadersFor(ctx).BarcodesByRetailerItemID.Load(ctx, obj.ID)
+}
+
+// Product is the resolver for the product field.
+func (r *retailerItemResolver) Product(ctx context.Context, obj *model.RetailerItem) (*model.Product, error) {
+	return loadersFor(ctx).ProductByRetailerItemID.Load(ctx, obj.ID)
+}
+
+// Chain is the resolver for the chain field.
+func (r *retailerItemResolver) Chain(ctx context.Context, obj *model.RetailerItem) (*model.Chain, error) {
+	if obj.ChainSlug == nil {
+		return nil, nil
+	}
+	return loadersFor(ctx).ChainBySlug.Load(ctx, *obj.ChainSlug)
+}
+
+// Prices is the resolver for the prices field.
+func (r *retailerItemResolver) Prices(ctx context.Context, obj *model.RetailerItem, storeIds []string, limit *int) ([]*model.StorePrice, error) {
+	max := clampLimit(limit, 50, maxPricesLimit)
+
+	if len(storeIds) > 0 {
+		chainSlug := ""
+		if obj.ChainSlug != nil {
+			chainSlug = *obj.ChainSlug
+		}
+		prices, err := r.pricesFor(ctx, chainSlug, storeIds, []string{obj.ID})
+		if err != nil {
+			return nil, err
+		}
+		if len(prices) > max {
+			prices = prices[:max]
+		}
+		return prices, nil
+	}
+
+	prices, err := loadersFor(ctx).PricesByRetailerItemID.Load(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(prices) > max {
+		prices = prices[:max]
+	}
+	return prices, nil
+}
+
+// Chain is the resolver for the chain field.
+func (r *storeResolver) Chain(ctx context.Context, obj *model.Store) (*model.Chain, error) {
+	return loadersFor(ctx).ChainBySlug.Load(ctx, obj.ChainSlug)
+}
+
+// Prices is the resolver for the prices field.
+func (r *storeResolver) Prices(ctx context.Context, obj *model.Store, retailerItemIds []string) ([]*model.StorePrice, error) {
+	return r.pricesFor(ctx, obj.ChainSlug, []string{obj.ID}, retailerItemIds)
+}
+
+// Store is the resolver for the store field.
+func (r *storePriceResolver) Store(ctx context.Context, obj *model.StorePrice) (*model.Store, error) {
+	return loadersFor(ctx).StoreByID.Load(ctx, obj.StoreID)
+}
+
+// RetailerItem is the resolver for the retailerItem field.
+func (r *storePriceResolver) RetailerItem(ctx context.Context, obj *model.StorePrice) (*model.RetailerItem, error) {
+	return loadersFor(ctx).RetailerItemByID.Load(ctx, obj.RetailerItemID)
+}
+
+// Product returns ProductResolver implementation.
+func (r *Resolver) Product() ProductResolver { return &productResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// RetailerItem returns RetailerItemResolver implementation.
+func (r *Resolver) RetailerItem() RetailerItemResolver { return &retailerItemResolver{r} }
+
+// Store returns StoreResolver implementation.
+func (r *Resolver) Store() StoreResolver { return &storeResolver{r} }
+
+// StorePrice returns StorePriceResolver implementation.
+func (r *Resolver) StorePrice() StorePriceResolver { return &storePriceResolver{r} }
+
+type productResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type retailerItemResolver struct{ *Resolver }
+type storeResolver struct{ *Resolver }
+type storePriceResolver struct{ *Resolver }
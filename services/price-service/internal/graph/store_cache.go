@@ -0,0 +1,60 @@
+package graph
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kosarica/price-service/internal/graph/model"
+)
+
+// storeCacheTTL is how long store metadata is reused across requests. Stores
+// are only inserted by ingestion, never edited, so a new store is a cache miss
+// and the TTL only bounds how long a deleted one lingers.
+const storeCacheTTL = 5 * time.Minute
+
+// storeCache keeps store metadata by ID across requests. The zero value is
+// ready to use.
+type storeCache struct {
+	mu      sync.RWMutex
+	entries map[string]cachedStore
+}
+
+type cachedStore struct {
+	store    *model.Store
+	loadedAt time.Time
+}
+
+// get returns the fresh cached stores of ids and the IDs that must be loaded
+func (c *storeCache) get(ids []string) (found []*model.Store, missing []string) {
+	now := time.Now()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, id := range ids {
+		if e, ok := c.entries[id]; ok && now.Sub(e.loadedAt) < storeCacheTTL {
+			found = append(found, e.store)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return found, missing
+}
+
+// put caches loaded stores, dropping expired entries while at it
+func (c *storeCache) put(stores []*model.Store) {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[string]cachedStore, len(stores))
+	}
+	for id, e := range c.entries {
+		if now.Sub(e.loadedAt) >= storeCacheTTL {
+			delete(c.entries, id)
+		}
+	}
+	for _, s := range stores {
+		c.entries[s.ID] = cachedStore{store: s, loadedAt: now}
+	}
+}
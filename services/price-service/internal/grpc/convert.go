@@ -0,0 +1,212 @@
+package grpc
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// newMessage converts a pointer to a struct to its message
+func (s *schema) newMessage(v any) (*dynamicpb.Message, error) {
+	rv := reflect.ValueOf(v).Elem()
+	mt, ok := s.messages[rv.Type()]
+	if !ok {
+		return nil, fmt.Errorf("no message for %s", rv.Type())
+	}
+	m := dynamicpb.NewMessage(mt.desc)
+	if err := s.fill(m, rv); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// fill sets the fields of a message from a struct
+func (s *schema) fill(m protoreflect.Message, v reflect.Value) error {
+	mt, ok := s.messages[v.Type()]
+	if !ok {
+		return fmt.Errorf("no message for %s", v.Type())
+	}
+	for _, f := range mt.fields {
+		if err := s.setField(m, f.desc, v.FieldByIndex(f.index)); err != nil {
+			return fmt.Errorf("%s: %w", f.desc.FullName(), err)
+		}
+	}
+	return nil
+}
+
+// setField sets a message field from a struct field; nil and empty values
+// leave it unset
+func (s *schema) setField(m protoreflect.Message, fd protoreflect.FieldDescriptor, v reflect.Value) error {
+	switch {
+	case fd.IsMap():
+		if v.Len() == 0 {
+			return nil
+		}
+		entries := m.Mutable(fd).Map()
+		iter := v.MapRange()
+		for iter.Next() {
+			value, err := s.newValue(fd.MapValue(), iter.Value(), entries.NewValue)
+			if err != nil {
+				return err
+			}
+			entries.Set(protoreflect.ValueOfString(iter.Key().String()).MapKey(), value)
+		}
+		return nil
+
+	case fd.IsList():
+		if v.Len() == 0 {
+			return nil
+		}
+		list := m.Mutable(fd).List()
+		for i := 0; i < v.Len(); i++ {
+			value, err := s.newValue(fd, v.Index(i), list.NewElement)
+			if err != nil {
+				return err
+			}
+			list.Append(value)
+		}
+		return nil
+	}
+
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil
+	}
+	value, err := s.newValue(fd, v, func() protoreflect.Value { return m.NewField(fd) })
+	if err != nil {
+		return err
+	}
+	m.Set(fd, value)
+	return nil
+}
+
+// newValue converts a Go value to the value of a field, using newMessage for
+// the message of a message field
+func (s *schema) newValue(fd protoreflect.FieldDescriptor, v reflect.Value, newMessage func() protoreflect.Value) (protoreflect.Value, error) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return newMessage(), nil
+		}
+		v = v.Elem()
+	}
+
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		value := newMessage()
+		if v.Type() == timeType {
+			setTimestamp(value.Message(), v.Interface().(time.Time))
+			return value, nil
+		}
+		return value, s.fill(value.Message(), v)
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(v.String()), nil
+	case protoreflect.BoolKind:
+		return protoreflect.ValueOfBool(v.Bool()), nil
+	case protoreflect.Int64Kind:
+		return protoreflect.ValueOfInt64(v.Int()), nil
+	case protoreflect.Int32Kind:
+		return protoreflect.ValueOfInt32(int32(v.Int())), nil
+	case protoreflect.DoubleKind:
+		return protoreflect.ValueOfFloat64(v.Float()), nil
+	case protoreflect.FloatKind:
+		return protoreflect.ValueOfFloat32(float32(v.Float())), nil
+	case protoreflect.BytesKind:
+		return protoreflect.ValueOfBytes(v.Bytes()), nil
+	}
+	return protoreflect.Value{}, fmt.Errorf("unsupported kind %s", fd.Kind())
+}
+
+// read sets the fields of a struct from a message; unset fields keep their
+// zero value
+func (s *schema) read(m protoreflect.Message, v reflect.Value) error {
+	mt, ok := s.messages[v.Type()]
+	if !ok {
+		return fmt.Errorf("no message for %s", v.Type())
+	}
+	for _, f := range mt.fields {
+		if !m.Has(f.desc) {
+			continue
+		}
+		if err := s.readField(m.Get(f.desc), f.desc, v.FieldByIndex(f.index)); err != nil {
+			return fmt.Errorf("%s: %w", f.desc.FullName(), err)
+		}
+	}
+	return nil
+}
+
+// readField sets a struct field from a set message field
+func (s *schema) readField(value protoreflect.Value, fd protoreflect.FieldDescriptor, v reflect.Value) error {
+	switch {
+	case fd.IsMap():
+		entries := value.Map()
+		v.Set(reflect.MakeMapWithSize(v.Type(), entries.Len()))
+		var err error
+		entries.Range(func(key protoreflect.MapKey, value protoreflect.Value) bool {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err = s.readValue(value, fd.MapValue(), elem); err != nil {
+				return false
+			}
+			v.SetMapIndex(reflect.ValueOf(key.String()).Convert(v.Type().Key()), elem)
+			return true
+		})
+		return err
+
+	case fd.IsList():
+		list := value.List()
+		v.Set(reflect.MakeSlice(v.Type(), list.Len(), list.Len()))
+		for i := 0; i < list.Len(); i++ {
+			if err := s.readValue(list.Get(i), fd, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return s.readValue(value, fd, v)
+}
+
+// readValue sets a Go value from the value of a field, allocating pointers
+func (s *schema) readValue(value protoreflect.Value, fd protoreflect.FieldDescriptor, v reflect.Value) error {
+	if v.Kind() == reflect.Pointer {
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		if v.Type() == timeType {
+			v.Set(reflect.ValueOf(readTimestamp(value.Message())))
+			return nil
+		}
+		return s.read(value.Message(), v)
+	case protoreflect.StringKind:
+		v.SetString(value.String())
+	case protoreflect.BoolKind:
+		v.SetBool(value.Bool())
+	case protoreflect.Int64Kind, protoreflect.Int32Kind:
+		v.SetInt(value.Int())
+	case protoreflect.DoubleKind, protoreflect.FloatKind:
+		v.SetFloat(value.Float())
+	case protoreflect.BytesKind:
+		v.SetBytes(value.Bytes())
+	default:
+		return fmt.Errorf("unsupported kind %s", fd.Kind())
+	}
+	return nil
+}
+
+// setTimestamp sets a google.protobuf.Timestamp message to a time
+func setTimestamp(m protoreflect.Message, t time.Time) {
+	fields := m.Descriptor().Fields()
+	m.Set(fields.ByName("seconds"), protoreflect.ValueOfInt64(t.Unix()))
+	m.Set(fields.ByName("nanos"), protoreflect.ValueOfInt32(int32(t.Nanosecond())))
+}
+
+// readTimestamp reads the time of a google.protobuf.Timestamp message, in UTC
+func readTimestamp(m protoreflect.Message) time.Time {
+	fields := m.Descriptor().Fields()
+	seconds := m.Get(fields.ByName("seconds")).Int()
+	nanos := m.Get(fields.ByName("nanos")).Int()
+	return time.Unix(seconds, nanos).UTC()
+}
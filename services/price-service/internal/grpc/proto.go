@@ -0,0 +1,114 @@
+package grpc
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ProtoFile returns the service's protobuf definitions as a .proto file, for
+// clients generating their stubs from it
+func ProtoFile() (string, error) {
+	s, err := loadSchema()
+	if err != nil {
+		return "", err
+	}
+	return printProto(s.file), nil
+}
+
+// printProto prints a file descriptor as .proto source
+func printProto(file protoreflect.FileDescriptor) string {
+	var sb strings.Builder
+	sb.WriteString("// Code generated by price-service proto from the Go types of internal/grpc.\n")
+	sb.WriteString("// DO NOT EDIT.\n\n")
+	fmt.Fprintf(&sb, "syntax = %q;\n\n", "proto3")
+	fmt.Fprintf(&sb, "package %s;\n", file.Package())
+
+	if imports := file.Imports(); imports.Len() > 0 {
+		sb.WriteString("\n")
+		for i := 0; i < imports.Len(); i++ {
+			fmt.Fprintf(&sb, "import %q;\n", imports.Get(i).Path())
+		}
+	}
+
+	services := file.Services()
+	for i := 0; i < services.Len(); i++ {
+		svc := services.Get(i)
+		fmt.Fprintf(&sb, "\nservice %s {\n", svc.Name())
+		methods := svc.Methods()
+		for j := 0; j < methods.Len(); j++ {
+			m := methods.Get(j)
+			fmt.Fprintf(&sb, "  rpc %s(%s) returns (%s);\n", m.Name(), typeName(file, m.Input()), typeName(file, m.Output()))
+		}
+		sb.WriteString("}\n")
+	}
+
+	messages := file.Messages()
+	for i := 0; i < messages.Len(); i++ {
+		msg := messages.Get(i)
+		fmt.Fprintf(&sb, "\nmessage %s {\n", msg.Name())
+		fields := msg.Fields()
+		for j := 0; j < fields.Len(); j++ {
+			fmt.Fprintf(&sb, "  %s;\n", printField(file, fields.Get(j)))
+		}
+		sb.WriteString("}\n")
+	}
+	return sb.String()
+}
+
+// printField prints a field declaration
+func printField(file protoreflect.FileDescriptor, fd protoreflect.FieldDescriptor) string {
+	var decl string
+	switch {
+	case fd.IsMap():
+		decl = fmt.Sprintf("map<%s, %s>", fieldType(file, fd.MapKey()), fieldType(file, fd.MapValue()))
+	case fd.IsList():
+		decl = "repeated " + fieldType(file, fd)
+	case fd.HasOptionalKeyword():
+		decl = "optional " + fieldType(file, fd)
+	default:
+		decl = fieldType(file, fd)
+	}
+
+	decl = fmt.Sprintf("%s %s = %d", decl, fd.Name(), fd.Number())
+	if fd.JSONName() != defaultJSONName(string(fd.Name())) {
+		decl += fmt.Sprintf(" [json_name = %q]", fd.JSONName())
+	}
+	return decl
+}
+
+// fieldType returns the type of a field as written in the file
+func fieldType(file protoreflect.FileDescriptor, fd protoreflect.FieldDescriptor) string {
+	if fd.Kind() == protoreflect.MessageKind {
+		return typeName(file, fd.Message())
+	}
+	return fd.Kind().String()
+}
+
+// typeName returns the name of a message as written in the file: relative
+// to the file's package when it is in it
+func typeName(file protoreflect.FileDescriptor, md protoreflect.MessageDescriptor) string {
+	if md.ParentFile().Package() == file.Package() {
+		return string(md.Name())
+	}
+	return string(md.FullName())
+}
+
+// defaultJSONName returns the JSON name protoc derives from a field name
+func defaultJSONName(name string) string {
+	var sb strings.Builder
+	upper := false
+	for _, r := range name {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper && r >= 'a' && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		upper = false
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
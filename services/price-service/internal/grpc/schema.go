@@ -0,0 +1,338 @@
+package grpc
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	_ "google.golang.org/protobuf/types/known/timestamppb" // Registers google/protobuf/timestamp.proto
+)
+
+const timestampFile = "google/protobuf/timestamp.proto"
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// schema is the protobuf file of the service, built from the Go types its
+// messages are converted from
+type schema struct {
+	file     protoreflect.FileDescriptor
+	messages map[reflect.Type]*messageType
+}
+
+// messageType maps a message to the Go struct it is converted from
+type messageType struct {
+	desc   protoreflect.MessageDescriptor
+	fields []messageField
+}
+
+// messageField maps a message field to its Go struct field
+type messageField struct {
+	desc  protoreflect.FieldDescriptor
+	index []int // Through embedded structs
+}
+
+// schemaBuilder collects the descriptors of a schema's messages
+type schemaBuilder struct {
+	file    *descriptorpb.FileDescriptorProto
+	types   map[string]reflect.Type
+	indexes map[reflect.Type][][]int // Go field of each message field, in order
+}
+
+// buildSchema builds the protobuf file of a service from the Go types of its
+// methods. Each struct is a message named after its type:
+//
+//   - fields are named after their JSON names, in snake case, and numbered
+//     by their proto tag, e.g. proto:"3", which must never change once
+//     released
+//   - embedded structs are flattened, as in JSON, their fields numbered
+//     from the proto tag of the embedding onwards, and fields tagged
+//     json:"-" are left out
+//   - pointers to scalars are optional fields, slices repeated fields, maps
+//     map fields and time.Time a google.protobuf.Timestamp
+func buildSchema(service string, methods []method) (*schema, error) {
+	b := &schemaBuilder{
+		file: &descriptorpb.FileDescriptorProto{
+			Name:    proto.String(protoFile),
+			Package: proto.String(protoPackage),
+			Syntax:  proto.String("proto3"),
+		},
+		types:   make(map[string]reflect.Type),
+		indexes: make(map[reflect.Type][][]int),
+	}
+
+	svc := &descriptorpb.ServiceDescriptorProto{Name: proto.String(service)}
+	for _, m := range methods {
+		input, err := b.addMessage(m.request)
+		if err != nil {
+			return nil, fmt.Errorf("method %s: %w", m.name, err)
+		}
+		output, err := b.addMessage(m.response)
+		if err != nil {
+			return nil, fmt.Errorf("method %s: %w", m.name, err)
+		}
+		svc.Method = append(svc.Method, &descriptorpb.MethodDescriptorProto{
+			Name:       proto.String(m.name),
+			InputType:  proto.String(input),
+			OutputType: proto.String(output),
+		})
+	}
+	b.file.Service = append(b.file.Service, svc)
+
+	file, err := protodesc.NewFile(b.file, protoregistry.GlobalFiles)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	s := &schema{file: file, messages: make(map[reflect.Type]*messageType, len(b.types))}
+	for name, t := range b.types {
+		desc := file.Messages().ByName(protoreflect.Name(name))
+		mt := &messageType{desc: desc}
+		for i, index := range b.indexes[t] {
+			mt.fields = append(mt.fields, messageField{desc: desc.Fields().Get(i), index: index})
+		}
+		s.messages[t] = mt
+	}
+	return s, nil
+}
+
+// addMessage adds the message of a struct type and the ones it refers to,
+// returning its fully-qualified name
+func (b *schemaBuilder) addMessage(t reflect.Type) (string, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return "", fmt.Errorf("%s is not a struct", t)
+	}
+
+	name := t.Name()
+	fullName := "." + protoPackage + "." + name
+	if existing, ok := b.types[name]; ok {
+		if existing != t {
+			return "", fmt.Errorf("message %s is defined by both %s and %s", name, existing, t)
+		}
+		return fullName, nil
+	}
+	b.types[name] = t
+
+	msg := &descriptorpb.DescriptorProto{Name: proto.String(name)}
+	b.file.MessageType = append(b.file.MessageType, msg)
+
+	fields, err := structFields(t, nil, 1)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", t, err)
+	}
+	numbers := make(map[int32]string, len(fields))
+	for _, f := range fields {
+		if other, ok := numbers[f.number]; ok {
+			return "", fmt.Errorf("%s.%s: field number %d is taken by %s", t, f.Name, f.number, other)
+		}
+		numbers[f.number] = f.Name
+
+		field, err := b.newField(msg, f.StructField, f.number)
+		if err != nil {
+			return "", fmt.Errorf("%s.%s: %w", t, f.Name, err)
+		}
+		msg.Field = append(msg.Field, field)
+		b.indexes[t] = append(b.indexes[t], f.Index)
+	}
+	return fullName, nil
+}
+
+// newField describes a message field, adding the entry message of a map
+// field to msg
+func (b *schemaBuilder) newField(msg *descriptorpb.DescriptorProto, f reflect.StructField, number int32) (*descriptorpb.FieldDescriptorProto, error) {
+	jsonName := jsonFieldName(f)
+	field := &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(snakeCase(jsonName)),
+		JsonName: proto.String(jsonName),
+		Number:   proto.Int32(number),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+	}
+
+	t := f.Type
+	switch {
+	case t.Kind() == reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("map keys must be strings, not %s", t.Key())
+		}
+		entry := &descriptorpb.DescriptorProto{
+			Name:    proto.String(f.Name + "Entry"),
+			Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+		}
+		key := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String("key"),
+			JsonName: proto.String("key"),
+			Number:   proto.Int32(1),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+		}
+		value := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String("value"),
+			JsonName: proto.String("value"),
+			Number:   proto.Int32(2),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		}
+		if err := b.setType(value, t.Elem()); err != nil {
+			return nil, err
+		}
+		entry.Field = []*descriptorpb.FieldDescriptorProto{key, value}
+		msg.NestedType = append(msg.NestedType, entry)
+
+		field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+		field.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+		field.TypeName = proto.String("." + protoPackage + "." + msg.GetName() + "." + entry.GetName())
+		return field, nil
+
+	case t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8:
+		field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+		elem := t.Elem()
+		if elem.Kind() == reflect.Pointer && elem.Elem().Kind() != reflect.Struct {
+			return nil, fmt.Errorf("slices of pointers to %s are not supported", elem.Elem())
+		}
+		return field, b.setType(field, elem)
+
+	case t.Kind() == reflect.Pointer && t.Elem().Kind() != reflect.Struct:
+		// A pointer to a scalar is an optional field, which proto3 describes
+		// with a synthetic oneof
+		field.Proto3Optional = proto.Bool(true)
+		field.OneofIndex = proto.Int32(int32(len(msg.OneofDecl)))
+		msg.OneofDecl = append(msg.OneofDecl, &descriptorpb.OneofDescriptorProto{
+			Name: proto.String("_" + field.GetName()),
+		})
+		return field, b.setType(field, t.Elem())
+	}
+	return field, b.setType(field, t)
+}
+
+// setType sets the type of a field holding values of a Go type
+func (b *schemaBuilder) setType(field *descriptorpb.FieldDescriptorProto, t reflect.Type) error {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var kind descriptorpb.FieldDescriptorProto_Type
+	switch {
+	case t == timeType:
+		if !slices.Contains(b.file.Dependency, timestampFile) {
+			b.file.Dependency = append(b.file.Dependency, timestampFile)
+		}
+		field.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+		field.TypeName = proto.String(".google.protobuf.Timestamp")
+		return nil
+	case t.Kind() == reflect.Struct:
+		name, err := b.addMessage(t)
+		if err != nil {
+			return err
+		}
+		field.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+		field.TypeName = proto.String(name)
+		return nil
+	case t.Kind() == reflect.String:
+		kind = descriptorpb.FieldDescriptorProto_TYPE_STRING
+	case t.Kind() == reflect.Bool:
+		kind = descriptorpb.FieldDescriptorProto_TYPE_BOOL
+	case t.Kind() == reflect.Int || t.Kind() == reflect.Int64:
+		kind = descriptorpb.FieldDescriptorProto_TYPE_INT64
+	case t.Kind() == reflect.Int32:
+		kind = descriptorpb.FieldDescriptorProto_TYPE_INT32
+	case t.Kind() == reflect.Float64:
+		kind = descriptorpb.FieldDescriptorProto_TYPE_DOUBLE
+	case t.Kind() == reflect.Float32:
+		kind = descriptorpb.FieldDescriptorProto_TYPE_FLOAT
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		kind = descriptorpb.FieldDescriptorProto_TYPE_BYTES
+	default:
+		return fmt.Errorf("type %s is not supported", t)
+	}
+	field.Type = kind.Enum()
+	return nil
+}
+
+// numberedField is a struct field that becomes a message field
+type numberedField struct {
+	reflect.StructField
+	number int32
+}
+
+// structFields returns the fields of a struct that become message fields,
+// with those of embedded structs in their place. Field numbers are offset so
+// that a proto tag of 1 is numbered base.
+func structFields(t reflect.Type, index []int, base int32) ([]numberedField, error) {
+	var fields []numberedField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		f.Index = append(append([]int(nil), index...), i)
+
+		tag := f.Tag.Get("json")
+		if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		number, err := fieldNumber(f, base)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		if f.Anonymous && strings.Split(tag, ",")[0] == "" {
+			if f.Type.Kind() != reflect.Struct {
+				return nil, fmt.Errorf("embedded %s must be a struct", f.Type)
+			}
+			embedded, err := structFields(f.Type, f.Index, number)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, embedded...)
+			continue
+		}
+		fields = append(fields, numberedField{StructField: f, number: number})
+	}
+	return fields, nil
+}
+
+// fieldNumber returns the message field number a struct field is tagged
+// with, offset so that a tag of 1 is numbered base
+func fieldNumber(f reflect.StructField, base int32) (int32, error) {
+	tag, ok := f.Tag.Lookup("proto")
+	if !ok {
+		return 0, fmt.Errorf("missing proto tag with the field number")
+	}
+	n, err := strconv.ParseInt(tag, 10, 32)
+	number := protowire.Number(n) + protowire.Number(base) - 1
+	if err != nil || n < 1 || !number.IsValid() ||
+		(number >= protowire.FirstReservedNumber && number <= protowire.LastReservedNumber) {
+		return 0, fmt.Errorf("invalid field number %q", tag)
+	}
+	return int32(number), nil
+}
+
+// jsonFieldName returns the JSON name of a struct field
+func jsonFieldName(f reflect.StructField) string {
+	if name := strings.Split(f.Tag.Get("json"), ",")[0]; name != "" {
+		return name
+	}
+	return f.Name
+}
+
+// snakeCase converts a camelCase JSON name to a protobuf field name
+func snakeCase(name string) string {
+	var sb strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				sb.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
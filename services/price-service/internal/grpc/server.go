@@ -0,0 +1,376 @@
+// Package grpc serves basket optimization, store prices and item search over
+// gRPC, for internal callers that want to skip JSON. The protobuf messages
+// are built from the Go types of the HTTP API and the calls are served by the
+// same code as its endpoints.
+package grpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/kosarica/price-service/internal/handlers"
+	"github.com/kosarica/price-service/internal/lanes"
+	"github.com/kosarica/price-service/internal/pkg/i18n"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	protoPackage = "kosarica.price.v1"
+	protoFile    = "kosarica/price/v1/price.proto"
+	serviceName  = "PriceService"
+	// apiKeyMetadata carries the internal API key, as X-Internal-API-Key does
+	// over HTTP
+	apiKeyMetadata = "x-internal-api-key"
+)
+
+// Optimize modes
+const (
+	ModeSingle = "single"
+	ModeMulti  = "multi"
+)
+
+// OptimizeRequest is a basket optimization request with the optimizer to run
+type OptimizeRequest struct {
+	Mode                     string `json:"mode" binding:"required,oneof=single multi" proto:"1"`
+	IncludeFormatted         bool   `json:"includeFormatted" proto:"2"` // Add display strings of the amounts
+	handlers.OptimizeRequest `proto:"3"`
+}
+
+// OptimizeResponse holds the result of the optimizer that ran
+type OptimizeResponse struct {
+	Single *handlers.SingleStoreResponse `json:"single,omitempty" proto:"1"`
+	Multi  *handlers.MultiStoreResult    `json:"multi,omitempty" proto:"2"`
+}
+
+// GetStorePricesRequest is a request for a page of a store's prices
+type GetStorePricesRequest struct {
+	IncludeFormatted               bool `json:"includeFormatted" proto:"1"` // Add display strings of the amounts
+	handlers.GetStorePricesRequest `proto:"2"`
+}
+
+// method is a unary method of the service
+type method struct {
+	name     string
+	request  reflect.Type
+	response reflect.Type
+	serve    func(ctx context.Context, req any) (any, error)
+}
+
+// unary describes a method served by serve
+func unary[Req, Resp any](name string, serve func(context.Context, *Req) (*Resp, error)) method {
+	return method{
+		name:     name,
+		request:  reflect.TypeFor[Req](),
+		response: reflect.TypeFor[Resp](),
+		serve: func(ctx context.Context, req any) (any, error) {
+			return serve(ctx, req.(*Req))
+		},
+	}
+}
+
+// methods are the methods of the service. Their request and response types
+// define the protobuf messages, see buildSchema.
+var methods = []method{
+	unary("Optimize", optimize),
+	unary("GetStorePrices", getStorePrices),
+	unary("SearchItems", searchItems),
+}
+
+// loadSchema builds the service's schema once and registers it for the
+// reflection service
+var loadSchema = sync.OnceValues(func() (*schema, error) {
+	s, err := buildSchema(serviceName, methods)
+	if err != nil {
+		return nil, err
+	}
+	if err := protoregistry.GlobalFiles.RegisterFile(s.file); err != nil {
+		return nil, fmt.Errorf("failed to register schema: %w", err)
+	}
+	return s, nil
+})
+
+// optimize runs the single- or multi-store optimizer
+func optimize(ctx context.Context, req *OptimizeRequest) (*OptimizeResponse, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	// Messages are in the language of the accept-language metadata, as over
+	// HTTP
+	accepted := metadata.ValueFromIncomingContext(ctx, "accept-language")
+	ctx = i18n.WithLanguage(ctx, i18n.Negotiate(strings.Join(accepted, ",")))
+
+	if req.Mode == ModeSingle {
+		result, err := handlers.ServeOptimizeSingle(ctx, &req.OptimizeRequest, req.IncludeFormatted)
+		if err != nil {
+			return nil, err
+		}
+		return &OptimizeResponse{Single: result}, nil
+	}
+	result, err := handlers.ServeOptimizeMulti(ctx, &req.OptimizeRequest, req.IncludeFormatted)
+	if err != nil {
+		return nil, err
+	}
+	return &OptimizeResponse{Multi: result}, nil
+}
+
+// getStorePrices returns a page of a store's prices
+func getStorePrices(ctx context.Context, req *GetStorePricesRequest) (*handlers.GetStorePricesResponse, error) {
+	if req.ChainSlug == "" || req.StoreID == "" {
+		return nil, status.Error(codes.InvalidArgument, "chainSlug and storeId are required")
+	}
+	if req.Limit == 0 {
+		req.Limit = handlers.DefaultStorePricesLimit
+	}
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	return handlers.ServeStorePrices(ctx, req.GetStorePricesRequest, req.IncludeFormatted)
+}
+
+// searchItems searches items by name
+func searchItems(ctx context.Context, req *handlers.SearchItemsRequest) (*handlers.SearchItemsResponse, error) {
+	if req.Limit == 0 {
+		req.Limit = handlers.DefaultSearchLimit
+	}
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	return handlers.ServeSearchItems(ctx, *req)
+}
+
+// validate checks a request against the binding rules of the HTTP API
+func validate(req any) error {
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
+
+// Server serves the price service over gRPC, along with the standard health
+// and reflection services
+type Server struct {
+	server *grpc.Server
+	health *health.Server
+	schema *schema
+	logger *zerolog.Logger
+}
+
+// NewServer creates a gRPC server. Calls other than health checks must carry
+// apiKey in the x-internal-api-key metadata.
+func NewServer(logger *zerolog.Logger, apiKey string) (*Server, error) {
+	s, err := loadSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	srv := &Server{
+		server: grpc.NewServer(
+			grpc.ChainUnaryInterceptor(unaryAuth(apiKey), unaryLane),
+			grpc.StreamInterceptor(streamAuth(apiKey)),
+		),
+		health: health.NewServer(),
+		schema: s,
+		logger: logger,
+	}
+	srv.server.RegisterService(srv.serviceDesc(), nil)
+	healthpb.RegisterHealthServer(srv.server, srv.health)
+	reflection.Register(srv.server)
+
+	srv.health.SetServingStatus(protoPackage+"."+serviceName, healthpb.HealthCheckResponse_SERVING)
+	return srv, nil
+}
+
+// Serve accepts connections on lis until the server is stopped
+func (s *Server) Serve(lis net.Listener) error {
+	return s.server.Serve(lis)
+}
+
+// Shutdown reports the server as not serving and waits for calls in flight
+// to finish, cancelling them when ctx is done
+func (s *Server) Shutdown(ctx context.Context) {
+	s.health.Shutdown()
+
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.server.Stop()
+	}
+}
+
+// serviceDesc describes the service's methods to grpc. Requests are decoded
+// into dynamic messages of the schema and converted to their Go types.
+func (s *Server) serviceDesc() *grpc.ServiceDesc {
+	svc := s.schema.file.Services().ByName(serviceName)
+	desc := &grpc.ServiceDesc{
+		ServiceName: string(svc.FullName()),
+		Metadata:    protoFile,
+	}
+
+	for _, m := range methods {
+		input := svc.Methods().ByName(protoreflect.Name(m.name)).Input()
+		fullMethod := "/" + desc.ServiceName + "/" + m.name
+		desc.Methods = append(desc.Methods, grpc.MethodDesc{
+			MethodName: m.name,
+			Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+				in := dynamicpb.NewMessage(input)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				call := func(ctx context.Context, req any) (any, error) {
+					return s.call(ctx, m, req.(*dynamicpb.Message))
+				}
+				if interceptor == nil {
+					return call(ctx, in)
+				}
+				return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod}, call)
+			},
+		})
+	}
+	return desc
+}
+
+// call converts a request to its Go type, serves it and converts the response
+func (s *Server) call(ctx context.Context, m method, in *dynamicpb.Message) (any, error) {
+	req := reflect.New(m.request)
+	if err := s.schema.read(in, req.Elem()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	resp, err := m.serve(ctx, req.Interface())
+	if err != nil {
+		return nil, s.statusError(ctx, m, err)
+	}
+
+	out, err := s.schema.newMessage(resp)
+	if err != nil {
+		s.logger.Error().Err(err).Str("method", m.name).Msg("Failed to encode gRPC response")
+		return nil, status.Error(codes.Internal, "failed to encode response")
+	}
+	return out, nil
+}
+
+// statusError converts a failed call's error to a gRPC status. Request
+// errors map their HTTP status to a code and set the retry-after trailer
+// when the call may be retried later.
+func (s *Server) statusError(ctx context.Context, m method, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var reqErr *handlers.RequestError
+	if errors.As(err, &reqErr) {
+		if reqErr.RetryAfter > 0 {
+			seconds := strconv.Itoa(int(math.Ceil(reqErr.RetryAfter.Seconds())))
+			grpc.SetTrailer(ctx, metadata.Pairs("retry-after", seconds))
+		}
+		code := statusCode(reqErr.Status)
+		if code == codes.Internal {
+			s.logger.Error().Err(err).Str("method", m.name).Msg("gRPC call failed")
+		}
+		return status.Error(code, reqErr.Message)
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	}
+	s.logger.Error().Err(err).Str("method", m.name).Msg("gRPC call failed")
+	return status.Error(codes.Internal, err.Error())
+}
+
+// statusCode maps the HTTP status of a request error to a gRPC code
+func statusCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.FailedPrecondition
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+	return codes.Internal
+}
+
+// authorize checks the internal API key of a call. Health checks need none,
+// so probes can run without it.
+func authorize(ctx context.Context, fullMethod, apiKey string) error {
+	if strings.HasPrefix(fullMethod, "/"+healthpb.Health_ServiceDesc.ServiceName+"/") {
+		return nil
+	}
+	if apiKey == "" {
+		return status.Error(codes.Internal, "server misconfigured: INTERNAL_API_KEY not set")
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	keys := md.Get(apiKeyMetadata)
+	// Use subtle.ConstantTimeCompare to prevent timing attacks
+	if len(keys) != 1 || subtle.ConstantTimeCompare([]byte(keys[0]), []byte(apiKey)) != 1 {
+		return status.Error(codes.Unauthenticated, "unauthorized")
+	}
+	return nil
+}
+
+// unaryAuth rejects unary calls without the internal API key
+func unaryAuth(apiKey string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := authorize(ctx, info.FullMethod, apiKey); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// streamAuth rejects streaming calls, i.e. reflection, without the internal
+// API key
+func streamAuth(apiKey string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authorize(ss.Context(), info.FullMethod, apiKey); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// unaryLane runs calls in the interactive lane, as the HTTP endpoints are
+func unaryLane(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx = lanes.WithLane(ctx, lanes.Interactive)
+	release, err := lanes.Acquire(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, "Request cancelled while waiting for capacity")
+	}
+	defer release()
+	return handler(ctx, req)
+}
@@ -0,0 +1,262 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kosarica/price-service/internal/handlers"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const testAPIKey = "test-key"
+
+var testBasket = handlers.OptimizeRequest{
+	ChainSlug:   "konzum",
+	BasketItems: []*handlers.BasketItem{{ItemID: "ri-1", Name: "Mlijeko", Quantity: 1}},
+}
+
+// TestSchema tests the messages built from the Go types.
+func TestSchema(t *testing.T) {
+	s, err := loadSchema()
+	require.NoError(t, err)
+
+	req := s.file.Messages().ByName("OptimizeRequest")
+	require.NotNil(t, req)
+	assert.Equal(t, protoreflect.FieldNumber(1), req.Fields().ByName("mode").Number())
+	assert.Equal(t, protoreflect.FieldNumber(3), req.Fields().ByName("chain_slug").Number(), "embedded request follows the wrapper's fields")
+	assert.True(t, req.Fields().ByName("basket_items").IsList())
+
+	price := s.file.Messages().ByName("StorePrice")
+	require.NotNil(t, price)
+	assert.True(t, price.Fields().ByName("brand").HasOptionalKeyword(), "pointers are optional")
+	assert.NotNil(t, price.Fields().ByName("discount_percent"), "embedded savings are flattened")
+	assert.Equal(t, "lowestPrice30d", price.Fields().ByName("lowest_price30d").JSONName())
+
+	item := s.file.Messages().ByName("SearchItem")
+	require.NotNil(t, item)
+	assert.True(t, item.Fields().ByName("name_i18n").IsMap())
+
+	staleness := s.file.Messages().ByName("Staleness")
+	require.NotNil(t, staleness)
+	assert.Equal(t, protoreflect.FullName("google.protobuf.Timestamp"), staleness.Fields().ByName("loaded_at").Message().FullName())
+
+	_, err = protoregistry.GlobalFiles.FindDescriptorByName(protoPackage + "." + serviceName)
+	assert.NoError(t, err, "schema is registered for reflection")
+}
+
+// TestSchemaRejectsUnsupportedTypes tests that types without a protobuf
+// mapping fail the build.
+func TestSchemaRejectsUnsupportedTypes(t *testing.T) {
+	type Unsupported struct {
+		Value any `json:"value" proto:"1"`
+	}
+	_, err := buildSchema("Test", []method{unary("Get", func(context.Context, *Unsupported) (*Unsupported, error) {
+		return nil, nil
+	})})
+	assert.ErrorContains(t, err, "not supported")
+}
+
+// TestSchemaRequiresFieldNumbers tests that message fields must be tagged
+// with a valid field number of their own.
+func TestSchemaRequiresFieldNumbers(t *testing.T) {
+	type Untagged struct {
+		Value string `json:"value"`
+	}
+	type Reserved struct {
+		Value string `json:"value" proto:"19000"`
+	}
+	type Embedded struct {
+		Inner string `json:"inner" proto:"1"`
+	}
+	type Duplicate struct {
+		Value string `json:"value" proto:"1"`
+		Embedded `proto:"1"`
+	}
+
+	for _, tc := range []struct {
+		name string
+		m    method
+		want string
+	}{
+		{"untagged", unary("Get", func(context.Context, *Untagged) (*Untagged, error) { return nil, nil }), "missing proto tag"},
+		{"reserved", unary("Get", func(context.Context, *Reserved) (*Reserved, error) { return nil, nil }), "invalid field number"},
+		{"duplicate", unary("Get", func(context.Context, *Duplicate) (*Duplicate, error) { return nil, nil }), "field number 1 is taken by Value"},
+	} {
+		_, err := buildSchema("Test", []method{tc.m})
+		assert.ErrorContains(t, err, tc.want, tc.name)
+	}
+}
+
+// TestConvertRoundTrip tests converting a response to its message and back
+// through the wire format.
+func TestConvertRoundTrip(t *testing.T) {
+	s, err := loadSchema()
+	require.NoError(t, err)
+
+	avgPrice := 199
+	discount := 25
+	brand := "Dukat"
+	want := &handlers.SearchItemsResponse{
+		Items: []handlers.SearchItem{{
+			ID:         "ri-1",
+			ChainSlug:  "konzum",
+			Name:       "Mlijeko 1L",
+			NameI18n:   map[string]string{"en": "Milk 1L"},
+			Brand:      &brand,
+			AvgPrice:   &avgPrice,
+			StoreCount: 3,
+			PriceSavings: handlers.PriceSavings{
+				DiscountPercent: &discount,
+			},
+		}},
+		Total: 1,
+		Query: "mlijeko",
+		Facets: handlers.SearchFacets{
+			Chains:   []handlers.FacetCount{{Value: "konzum", Count: 1}},
+			Discount: handlers.DiscountFacet{Active: 1},
+		},
+	}
+
+	m, err := s.newMessage(want)
+	require.NoError(t, err)
+	data, err := proto.Marshal(m)
+	require.NoError(t, err)
+
+	decoded := dynamicpb.NewMessage(m.Descriptor())
+	require.NoError(t, proto.Unmarshal(data, decoded))
+	var got handlers.SearchItemsResponse
+	require.NoError(t, s.read(decoded, reflect.ValueOf(&got).Elem()))
+
+	// Empty slices are not sent, so they come back nil
+	want.Facets.Categories = nil
+	want.Facets.Brands = nil
+	assert.Equal(t, want, &got)
+	assert.Nil(t, got.Items[0].Description, "unset optional fields stay nil")
+}
+
+// TestConvertTimestamp tests time fields.
+func TestConvertTimestamp(t *testing.T) {
+	s, err := loadSchema()
+	require.NoError(t, err)
+
+	loadedAt := time.Date(2026, 1, 19, 6, 30, 0, 500, time.UTC)
+	want := &OptimizeResponse{Multi: &handlers.MultiStoreResult{
+		Stores:    []*handlers.StoreAllocation{{StoreID: "s1", ChainSlug: "lidl", Items: []*handlers.ItemPriceInfo{}}},
+		Staleness: &handlers.Staleness{LoadedAt: loadedAt, AgeSeconds: 90, Stale: true},
+	}}
+
+	m, err := s.newMessage(want)
+	require.NoError(t, err)
+	var got OptimizeResponse
+	require.NoError(t, s.read(m, reflect.ValueOf(&got).Elem()))
+
+	require.NotNil(t, got.Multi)
+	assert.Nil(t, got.Single)
+	assert.Equal(t, loadedAt, got.Multi.Staleness.LoadedAt)
+	assert.Equal(t, "lidl", got.Multi.Stores[0].ChainSlug)
+}
+
+// TestProtoFileUpToDate tests that the checked-in .proto file matches the Go
+// types; regenerate it with `price-service proto` when they change.
+func TestProtoFileUpToDate(t *testing.T) {
+	want, err := ProtoFile()
+	require.NoError(t, err)
+
+	got, err := os.ReadFile("../../proto/kosarica/price/v1/price.proto")
+	require.NoError(t, err)
+	assert.Equal(t, want, string(got), "proto/kosarica/price/v1/price.proto is out of date, regenerate it with `price-service proto`")
+}
+
+// TestServer tests calls through a gRPC connection.
+func TestServer(t *testing.T) {
+	conn := startTestServer(t)
+	authed := metadata.AppendToOutgoingContext(context.Background(), apiKeyMetadata, testAPIKey)
+
+	t.Run("health needs no key", func(t *testing.T) {
+		resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{
+			Service: protoPackage + "." + serviceName,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+	})
+
+	t.Run("calls need the key", func(t *testing.T) {
+		_, err := invoke(t, conn, context.Background(), "SearchItems", &handlers.SearchItemsRequest{Query: "mlijeko"})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		_, err := invoke(t, conn, authed, "SearchItems", &handlers.SearchItemsRequest{Query: "ml"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = invoke(t, conn, authed, "Optimize", &OptimizeRequest{Mode: "cheapest", OptimizeRequest: testBasket})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = invoke(t, conn, authed, "GetStorePrices", &GetStorePricesRequest{
+			GetStorePricesRequest: handlers.GetStorePricesRequest{ChainSlug: "konzum"},
+		})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("request errors map to codes", func(t *testing.T) {
+		// The optimizers are not initialized, so the cache is unavailable
+		_, err := invoke(t, conn, authed, "Optimize", &OptimizeRequest{Mode: ModeMulti, OptimizeRequest: testBasket})
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Equal(t, "Cache not initialized", status.Convert(err).Message())
+	})
+}
+
+// startTestServer serves a test server over an in-memory connection
+func startTestServer(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+
+	logger := zerolog.Nop()
+	srv, err := NewServer(&logger, testAPIKey)
+	require.NoError(t, err)
+
+	lis := bufconn.Listen(1 << 20)
+	go srv.Serve(lis)
+	t.Cleanup(func() { srv.Shutdown(context.Background()) })
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// invoke calls a method with the message of a request
+func invoke(t *testing.T, conn *grpc.ClientConn, ctx context.Context, name string, req any) (*dynamicpb.Message, error) {
+	t.Helper()
+
+	s, err := loadSchema()
+	require.NoError(t, err)
+	in, err := s.newMessage(req)
+	require.NoError(t, err)
+
+	md := s.file.Services().ByName(serviceName).Methods().ByName(protoreflect.Name(name))
+	out := dynamicpb.NewMessage(md.Output())
+	err = conn.Invoke(ctx, "/"+protoPackage+"."+serviceName+"/"+name, in, out)
+	return out, err
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kosarica/price-service/internal/optimizer"
+)
+
+// BasketAvailabilityRequest asks which items of a basket one store has
+type BasketAvailabilityRequest struct {
+	ChainSlug   string        `json:"chainSlug" binding:"required" jsonschema:"required"`
+	StoreID     string        `json:"storeId" binding:"required" jsonschema:"required"`
+	BasketItems []*BasketItem `json:"basketItems" binding:"required,min=1,max=100,dive" jsonschema:"required,minItems=1,maxItems=100"`
+}
+
+// UnavailableItem is a basket item the store does not have
+type UnavailableItem struct {
+	ItemID   string `json:"itemId" jsonschema:"required"`
+	ItemName string `json:"itemName" jsonschema:"required"`
+	Quantity int    `json:"quantity" jsonschema:"required"`
+}
+
+// BasketAvailabilityResponse lists the available items of a basket at a
+// store with their prices, and the missing ones
+type BasketAvailabilityResponse struct {
+	ChainSlug     string             `json:"chainSlug" jsonschema:"required"`
+	StoreID       string             `json:"storeId" jsonschema:"required"`
+	Items         []*ItemPriceInfo   `json:"items" jsonschema:"required"`
+	MissingItems  []*UnavailableItem `json:"missingItems" jsonschema:"required"`
+	CoverageRatio float64            `json:"coverageRatio" jsonschema:"required"`
+	Total         int64              `json:"total" jsonschema:"required"`
+	Staleness     *Staleness         `json:"staleness,omitempty"`
+
+	// FormattedTotal is Total for display, with ?includeFormatted=true
+	FormattedTotal *string `json:"formattedTotal,omitempty"`
+}
+
+// CheckBasketAvailability handles basket availability checks at one store
+// @Summary Check basket availability at a store
+// @Description Returns which items of a basket a given store has, priced as the optimizers price them, and which are missing. No stores are compared or ranked and missing items carry no penalty; use it to check a known store rather than running the single-store optimizer. Responses served from a stale snapshot carry a staleness field.
+// @Tags basket
+// @Accept json
+// @Produce json
+// @Param request body BasketAvailabilityRequest true "Basket and store"
+// @Param includeFormatted query bool false "Add display strings of the amounts, e.g. 1,99 €, and discount percentages"
+// @Success 200 {object} BasketAvailabilityResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 404 {object} map[string]string "Chain not cached or store not found"
+// @Failure 503 {object} map[string]string "Cache not initialized"
+// @Router /internal/basket/availability [post]
+func CheckBasketAvailability(c *gin.Context) {
+	var req BasketAvailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := ServeBasketAvailability(c.Request.Context(), &req, includeFormatted(c))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ServeBasketAvailability runs a validated availability check; failures to
+// report to the caller are RequestErrors
+func ServeBasketAvailability(ctx context.Context, req *BasketAvailabilityRequest, withFormatted bool) (*BasketAvailabilityResponse, error) {
+	if priceCache == nil {
+		return nil, requestError(http.StatusServiceUnavailable, "Cache not initialized")
+	}
+
+	freshness, loaded := priceCache.ChainFreshness(req.ChainSlug)
+	if !loaded {
+		return nil, requestError(http.StatusNotFound, "Chain not cached")
+	}
+	if !priceCache.HasStore(req.ChainSlug, req.StoreID) {
+		return nil, requestError(http.StatusNotFound, "Store not found")
+	}
+
+	basketItems := make([]*optimizer.BasketItem, len(req.BasketItems))
+	for i, item := range req.BasketItems {
+		basketItems[i] = &optimizer.BasketItem{
+			ItemID:   item.ItemID,
+			Name:     item.Name,
+			Quantity: item.Quantity,
+		}
+	}
+	result := optimizer.CheckAvailability(priceCache, req.ChainSlug, req.StoreID, basketItems, time.Now())
+
+	missingItems := make([]*UnavailableItem, len(result.MissingItems))
+	for i, item := range result.MissingItems {
+		missingItems[i] = &UnavailableItem{
+			ItemID:   item.ItemID,
+			ItemName: item.Name,
+			Quantity: item.Quantity,
+		}
+	}
+
+	response := &BasketAvailabilityResponse{
+		ChainSlug:     req.ChainSlug,
+		StoreID:       req.StoreID,
+		Items:         newItemPriceInfos(result.Items, withFormatted),
+		MissingItems:  missingItems,
+		CoverageRatio: float64(len(result.Items)) / float64(len(basketItems)),
+		Total:         result.Total,
+	}
+	if freshness.IsStale {
+		response.Staleness = newStaleness(freshness, "")
+	}
+	if withFormatted {
+		response.FormattedTotal = formatTotal(result.Total)
+	}
+	return response, nil
+}
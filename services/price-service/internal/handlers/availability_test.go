@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kosarica/price-service/internal/optimizer"
+)
+
+// TestCheckBasketAvailabilityErrors tests the requests answered without
+// pricing anything.
+func TestCheckBasketAvailabilityErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/internal/basket/availability", CheckBasketAvailability)
+
+	post := func(body BasketAvailabilityRequest) *httptest.ResponseRecorder {
+		jsonBody, err := json.Marshal(body)
+		require.NoError(t, err)
+		req, err := http.NewRequest("POST", "/internal/basket/availability", bytes.NewBuffer(jsonBody))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	valid := BasketAvailabilityRequest{
+		ChainSlug:   "test-chain",
+		StoreID:     "sto-1",
+		BasketItems: []*BasketItem{{ItemID: "rit-aaa-111", Name: "Item A", Quantity: 1}},
+	}
+
+	previous := priceCache
+	t.Cleanup(func() { priceCache = previous })
+
+	priceCache = nil
+	assert.Equal(t, http.StatusServiceUnavailable, post(valid).Code)
+
+	priceCache = optimizer.NewPriceCache(nil, optimizer.DefaultOptimizerConfig())
+
+	w := post(valid)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "Chain not cached")
+
+	noStore := valid
+	noStore.StoreID = ""
+	assert.Equal(t, http.StatusBadRequest, post(noStore).Code)
+
+	zeroQuantity := valid
+	zeroQuantity.BasketItems = []*BasketItem{{ItemID: "rit-aaa-111", Name: "Item A"}}
+	assert.Equal(t, http.StatusBadRequest, post(zeroQuantity).Code, "basket items are validated")
+}
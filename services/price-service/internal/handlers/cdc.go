@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kosarica/price-service/internal/database"
+)
+
+// cdcFlushEvery is how many records are written between flushes
+const cdcFlushEvery = 500
+
+// NextCursorHeader carries the cursor to resume from. It is sent as a trailer
+// because it is only known after the last record has been streamed.
+const NextCursorHeader = "X-Next-Cursor"
+
+// StreamPriceChangesRequest represents query parameters for the price CDC feed
+type StreamPriceChangesRequest struct {
+	Since     string `form:"since" json:"since"` // Cursor of the last record already loaded; empty starts from the beginning
+	ChainSlug string `form:"chainSlug" json:"chainSlug"`
+	Limit     int    `form:"limit" json:"limit" binding:"omitempty,min=1,max=50000" jsonschema:"minimum=1,maximum=50000"`
+}
+
+// StreamPriceChanges streams price changes after a cursor as NDJSON
+// @Summary Stream price changes (CDC)
+// @Description Streams per-item price changes recorded by persist as newline-delimited JSON, in cursor order. Every record carries its cursor; pass the last cursor seen (also sent as the X-Next-Cursor trailer) as since to resume. An empty page means the consumer is caught up.
+// @Tags prices
+// @Produce application/x-ndjson
+// @Param since query string false "Resume after this cursor"
+// @Param chainSlug query string false "Filter by chain slug"
+// @Param limit query int false "Maximum records to return" default(10000) minimum(1) maximum(50000)
+// @Success 200 {object} database.PriceChangeRecord "One record per line"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Router /internal/cdc/prices [get]
+func StreamPriceChanges(c *gin.Context) {
+	var req StreamPriceChangesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = 10000
+	}
+
+	var since int64
+	if req.Since != "" {
+		var err error
+		since, err = strconv.ParseInt(req.Since, 10, 64)
+		if err != nil || since < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return
+		}
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Trailer", NextCursorHeader)
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	next := since
+	written := 0
+	err := database.StreamPriceChanges(c.Request.Context(), since, req.ChainSlug, req.Limit, func(record *database.PriceChangeRecord) error {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+		next = record.Cursor
+		written++
+		if written%cdcFlushEvery == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		// Headers are already sent; stop the stream without a trailer so the
+		// consumer resumes from the last complete line it received
+		logger.Error().Err(err).Int64("since", since).Int("written", written).Msg("Price change stream aborted")
+		return
+	}
+
+	c.Writer.Header().Set(NextCursorHeader, strconv.FormatInt(next, 10))
+}
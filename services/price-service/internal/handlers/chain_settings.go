@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/kosarica/price-service/internal/chains"
+	"github.com/kosarica/price-service/internal/chainsettings"
+	"github.com/kosarica/price-service/internal/database"
+)
+
+// ListChainSettingsResponse represents the chains with stored setting overrides
+type ListChainSettingsResponse struct {
+	Settings []database.ChainSettings `json:"settings" jsonschema:"required"`
+}
+
+// ChainSettingsResponse represents a chain's settings at each layer
+type ChainSettingsResponse struct {
+	ChainSlug      string                  `json:"chainSlug" jsonschema:"required"`
+	ConfigDefaults chainsettings.Overrides `json:"configDefaults" jsonschema:"required"` // From the chains section of the config file
+	Overrides      *database.ChainSettings `json:"overrides"`                            // Stored in the database
+	Effective      chainsettings.Overrides `json:"effective" jsonschema:"required"`      // Used for ingestion; unset fields keep the adapter's values
+}
+
+// SetChainSettingsRequest represents the request body for setting a chain's
+// overrides. Omitted or null fields are not overridden.
+type SetChainSettingsRequest struct {
+	DiscoveryEnabled  *bool   `json:"discoveryEnabled"`
+	RequestsPerSecond *int    `json:"requestsPerSecond"`
+	MaxRetries        *int    `json:"maxRetries"`
+	CSVDelimiter      *string `json:"csvDelimiter"` // ",", ";" or a tab
+	CSVEncoding       *string `json:"csvEncoding"`  // utf-8, windows-1250 or iso-8859-2
+	CSVHasHeader      *bool   `json:"csvHasHeader"`
+}
+
+// ListChainSettings returns the stored chain setting overrides
+// @Summary List chain settings overrides
+// @Description Returns the ingest setting overrides stored in the database, one entry per chain that has them
+// @Tags chains
+// @Accept json
+// @Produce json
+// @Success 200 {object} ListChainSettingsResponse
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/chain-settings [get]
+func ListChainSettings(c *gin.Context) {
+	settings, err := database.ListChainSettings(c.Request.Context())
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to list chain settings")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list chain settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListChainSettingsResponse{Settings: settings})
+}
+
+// GetChainSettings returns a chain's ingest settings
+// @Summary Get chain settings
+// @Description Returns a chain's ingest settings from the config file, its overrides stored in the database and the merged settings used for ingestion
+// @Tags chains
+// @Accept json
+// @Produce json
+// @Param slug path string true "Chain slug"
+// @Success 200 {object} ChainSettingsResponse
+// @Failure 404 {object} map[string]string "Chain not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/chains/{slug}/settings [get]
+func GetChainSettings(c *gin.Context) {
+	slug := c.Param("slug")
+	if !chains.IsValidChain(slug) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chain not found: " + slug})
+		return
+	}
+
+	resp := ChainSettingsResponse{
+		ChainSlug:      slug,
+		ConfigDefaults: chainsettings.ConfigDefaults(slug),
+	}
+	resp.Effective = resp.ConfigDefaults
+
+	overrides, err := database.GetChainSettings(c.Request.Context(), slug)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		logger.Error().Err(err).Msg("Failed to get chain settings")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get chain settings"})
+		return
+	}
+	if err == nil {
+		resp.Overrides = overrides
+		resp.Effective = resp.ConfigDefaults.Merge(chainsettings.FromDatabase(*overrides))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// SetChainSettings creates or replaces a chain's setting overrides
+// @Summary Set chain settings overrides
+// @Description Validates and stores a chain's ingest setting overrides, replacing any stored before. They apply from the chain's next run or parsed file, within a minute.
+// @Tags chains
+// @Accept json
+// @Produce json
+// @Param slug path string true "Chain slug"
+// @Param request body SetChainSettingsRequest true "Overrides"
+// @Success 200 {object} database.ChainSettings
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 404 {object} map[string]string "Chain not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/chains/{slug}/settings [put]
+func SetChainSettings(c *gin.Context) {
+	slug := c.Param("slug")
+	if !chains.IsValidChain(slug) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chain not found: " + slug})
+		return
+	}
+
+	var req SetChainSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings := &database.ChainSettings{
+		ChainSlug:         slug,
+		DiscoveryEnabled:  req.DiscoveryEnabled,
+		RequestsPerSecond: req.RequestsPerSecond,
+		MaxRetries:        req.MaxRetries,
+		CSVDelimiter:      req.CSVDelimiter,
+		CSVEncoding:       req.CSVEncoding,
+		CSVHasHeader:      req.CSVHasHeader,
+	}
+	if err := chainsettings.FromDatabase(*settings).Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := database.UpsertChainSettings(c.Request.Context(), settings); err != nil {
+		logger.Error().Err(err).Msg("Failed to save chain settings")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save chain settings"})
+		return
+	}
+	chainsettings.Invalidate()
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// DeleteChainSettings removes a chain's setting overrides
+// @Summary Delete chain settings overrides
+// @Description Removes a chain's stored overrides, so it is ingested with the config file's settings
+// @Tags chains
+// @Accept json
+// @Produce json
+// @Param slug path string true "Chain slug"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string "Overrides not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/chains/{slug}/settings [delete]
+func DeleteChainSettings(c *gin.Context) {
+	slug := c.Param("slug")
+
+	deleted, err := database.DeleteChainSettings(c.Request.Context(), slug)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to delete chain settings")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete chain settings"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chain settings not found"})
+		return
+	}
+	chainsettings.Invalidate()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Chain settings deleted successfully",
+		"chainSlug": slug,
+	})
+}
@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/kosarica/price-service/internal/chains"
+	"github.com/kosarica/price-service/internal/database"
+)
+
+var (
+	chainSlugPattern  = regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*$`)
+	brandColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+)
+
+// ChainMetadata represents the presentation data the frontend needs for a chain
+type ChainMetadata struct {
+	Slug        string  `json:"slug" jsonschema:"required"`
+	DisplayName string  `json:"displayName" jsonschema:"required"`
+	LogoURL     *string `json:"logoUrl"`
+	BrandColor  *string `json:"brandColor"`
+	Website     *string `json:"website"`
+}
+
+// ListChainMetadataResponse represents the response for listing chain metadata
+type ListChainMetadataResponse struct {
+	Chains []ChainMetadata `json:"chains" jsonschema:"required"`
+}
+
+// ListAdminChainsResponse represents the response for listing the chain registry
+type ListAdminChainsResponse struct {
+	Chains []database.Chain `json:"chains" jsonschema:"required"`
+}
+
+// CreateChainRequest represents the request body for registering a chain
+type CreateChainRequest struct {
+	Slug        string          `json:"slug" binding:"required" jsonschema:"required"`
+	Name        string          `json:"name" binding:"required" jsonschema:"required"`
+	DisplayName *string         `json:"displayName"`
+	BrandColor  *string         `json:"brandColor"`
+	Website     *string         `json:"website"`
+	LogoURL     *string         `json:"logoUrl"`
+	Enabled     *bool           `json:"enabled"`
+	Metadata    json.RawMessage `json:"metadata" swaggertype:"object"`
+}
+
+// UpdateChainRequest represents the request body for updating a chain.
+// Omitted fields are left unchanged.
+type UpdateChainRequest struct {
+	Name        *string         `json:"name"`
+	DisplayName *string         `json:"displayName"`
+	BrandColor  *string         `json:"brandColor"`
+	Website     *string         `json:"website"`
+	LogoURL     *string         `json:"logoUrl"`
+	Enabled     *bool           `json:"enabled"`
+	Metadata    json.RawMessage `json:"metadata" swaggertype:"object"`
+}
+
+// ListChainMetadata returns display metadata for all enabled chains
+// @Summary List chain metadata
+// @Description Returns display name, logo URL, brand color and website for every enabled chain. The response carries an ETag; send it back in If-None-Match to get 304 while the chains are unchanged.
+// @Tags chains
+// @Accept json
+// @Produce json
+// @Param If-None-Match header string false "ETag of a cached response"
+// @Success 200 {object} ListChainMetadataResponse
+// @Header 200 {string} ETag "Hash of the response body"
+// @Header 200 {string} Cache-Control "private, max-age=60, must-revalidate"
+// @Success 304 "Not modified"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/chains/metadata [get]
+func ListChainMetadata(c *gin.Context) {
+	rows, err := chains.List(c.Request.Context(), false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list chains"})
+		return
+	}
+
+	metadata := make([]ChainMetadata, 0, len(rows))
+	for i := range rows {
+		metadata = append(metadata, toChainMetadata(&rows[i]))
+	}
+
+	writeCachedJSON(c, chainsMaxAge, ListChainMetadataResponse{Chains: metadata})
+}
+
+// GetChainMetadata returns display metadata for a single chain
+// @Summary Get chain metadata
+// @Description Returns display name, logo URL, brand color and website for a chain, including disabled chains. The response carries an ETag; send it back in If-None-Match to get 304 while the chain is unchanged.
+// @Tags chains
+// @Accept json
+// @Produce json
+// @Param slug path string true "Chain slug"
+// @Param If-None-Match header string false "ETag of a cached response"
+// @Success 200 {object} ChainMetadata
+// @Header 200 {string} ETag "Hash of the response body"
+// @Header 200 {string} Cache-Control "private, max-age=60, must-revalidate"
+// @Success 304 "Not modified"
+// @Failure 404 {object} map[string]string "Chain not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/chains/{slug}/metadata [get]
+func GetChainMetadata(c *gin.Context) {
+	chain, err := chains.Get(c.Request.Context(), c.Param("slug"))
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chain not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get chain"})
+		return
+	}
+
+	writeCachedJSON(c, chainsMaxAge, toChainMetadata(chain))
+}
+
+// ListAdminChains returns every chain in the registry, including disabled ones
+// @Summary List chain registry
+// @Description Returns all chains from the chains table with their metadata, including disabled chains
+// @Tags chains
+// @Accept json
+// @Produce json
+// @Success 200 {object} ListAdminChainsResponse
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/chains [get]
+func ListAdminChains(c *gin.Context) {
+	rows, err := chains.List(c.Request.Context(), true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list chains"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListAdminChainsResponse{Chains: rows})
+}
+
+// CreateChain registers a new chain
+// @Summary Create chain
+// @Description Adds a chain to the chain registry. Ingestion still requires an adapter for the slug.
+// @Tags chains
+// @Accept json
+// @Produce json
+// @Param request body CreateChainRequest true "Chain to create"
+// @Success 201 {object} database.Chain
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 409 {object} map[string]string "Chain already exists"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/chains [post]
+func CreateChain(c *gin.Context) {
+	var req CreateChainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !chainSlugPattern.MatchString(req.Slug) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Slug must be lowercase letters, digits and dashes"})
+		return
+	}
+	if len(req.Metadata) > 0 && !isJSONObject(req.Metadata) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Metadata must be a JSON object"})
+		return
+	}
+	if req.BrandColor != nil && !brandColorPattern.MatchString(*req.BrandColor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Brand color must be a hex color like #e30613"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	if _, err := database.GetChain(ctx, req.Slug); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Chain already exists"})
+		return
+	} else if err != pgx.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up chain"})
+		return
+	}
+
+	chain := &database.Chain{
+		Slug:        req.Slug,
+		Name:        req.Name,
+		DisplayName: req.DisplayName,
+		BrandColor:  req.BrandColor,
+		Website:     req.Website,
+		LogoURL:     req.LogoURL,
+		Enabled:     true,
+		Metadata:    req.Metadata,
+	}
+	if req.Enabled != nil {
+		chain.Enabled = *req.Enabled
+	}
+
+	// A chain created since the lookup still conflicts
+	if err := database.CreateChain(ctx, chain); errors.Is(err, database.ErrChainExists) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Chain already exists"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create chain"})
+		return
+	}
+	chains.Invalidate()
+
+	c.JSON(http.StatusCreated, chain)
+}
+
+// UpdateChain updates a chain's metadata or enabled state
+// @Summary Update chain
+// @Description Updates the name, display name, brand color, website, logo, metadata or enabled flag of a chain. Omitted fields are left unchanged.
+// @Tags chains
+// @Accept json
+// @Produce json
+// @Param slug path string true "Chain slug"
+// @Param request body UpdateChainRequest true "Fields to update"
+// @Success 200 {object} database.Chain
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 404 {object} map[string]string "Chain not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/chains/{slug} [patch]
+func UpdateChain(c *gin.Context) {
+	var req UpdateChainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.Metadata) > 0 && !isJSONObject(req.Metadata) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Metadata must be a JSON object"})
+		return
+	}
+	if req.BrandColor != nil && !brandColorPattern.MatchString(*req.BrandColor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Brand color must be a hex color like #e30613"})
+		return
+	}
+
+	chain, err := database.UpdateChain(c.Request.Context(), c.Param("slug"), database.ChainUpdate{
+		Name:        req.Name,
+		DisplayName: req.DisplayName,
+		BrandColor:  req.BrandColor,
+		Website:     req.Website,
+		LogoURL:     req.LogoURL,
+		Enabled:     req.Enabled,
+		Metadata:    req.Metadata,
+	})
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chain not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update chain"})
+		return
+	}
+	chains.Invalidate()
+
+	c.JSON(http.StatusOK, chain)
+}
+
+// DisableChain disables a chain so it is no longer accepted for ingestion
+// @Summary Disable chain
+// @Description Marks a chain as disabled. Its data is kept; re-enable it with PATCH.
+// @Tags chains
+// @Accept json
+// @Produce json
+// @Param slug path string true "Chain slug"
+// @Success 200 {object} database.Chain
+// @Failure 404 {object} map[string]string "Chain not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/chains/{slug} [delete]
+func DisableChain(c *gin.Context) {
+	disabled := false
+	chain, err := database.UpdateChain(c.Request.Context(), c.Param("slug"), database.ChainUpdate{
+		Enabled: &disabled,
+	})
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chain not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable chain"})
+		return
+	}
+	chains.Invalidate()
+
+	c.JSON(http.StatusOK, chain)
+}
+
+func toChainMetadata(chain *database.Chain) ChainMetadata {
+	displayName := chain.Name
+	if chain.DisplayName != nil && *chain.DisplayName != "" {
+		displayName = *chain.DisplayName
+	}
+	return ChainMetadata{
+		Slug:        chain.Slug,
+		DisplayName: displayName,
+		LogoURL:     chain.LogoURL,
+		BrandColor:  chain.BrandColor,
+		Website:     chain.Website,
+	}
+}
+
+func isJSONObject(raw json.RawMessage) bool {
+	var obj map[string]any
+	return json.Unmarshal(raw, &obj) == nil && obj != nil
+}
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kosarica/price-service/internal/chains"
+	"github.com/kosarica/price-service/internal/database"
+)
+
+// newChainsRouter routes the chain registry and metadata endpoints
+func newChainsRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/internal/chains/metadata", ListChainMetadata)
+	router.GET("/internal/chains/:slug/metadata", GetChainMetadata)
+	router.GET("/internal/admin/chains", ListAdminChains)
+	router.POST("/internal/admin/chains", CreateChain)
+	router.PATCH("/internal/admin/chains/:slug", UpdateChain)
+	router.DELETE("/internal/admin/chains/:slug", DisableChain)
+	return router
+}
+
+// serveJSON sends a request with an optional JSON body and returns the response
+func serveJSON(t *testing.T, router *gin.Engine, method, path string, body any) *httptest.ResponseRecorder {
+	var reader *bytes.Reader
+	if body != nil {
+		var raw []byte
+		if s, ok := body.(string); ok {
+			raw = []byte(s)
+		} else {
+			var err error
+			raw, err = json.Marshal(body)
+			require.NoError(t, err)
+		}
+		reader = bytes.NewReader(raw)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, path, reader)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// setupChainsTestDB starts Postgres with the chain registry columns and
+// connects the database package to it, as the chain handlers use its pool
+func setupChainsTestDB(t *testing.T) {
+	ctx := context.Background()
+
+	container, pool, cleanup := setupHandlersTestDB(t)
+	t.Cleanup(cleanup)
+
+	_, err := pool.Exec(ctx, `
+		ALTER TABLE chains
+			ADD COLUMN display_name TEXT,
+			ADD COLUMN brand_color TEXT,
+			ADD COLUMN enabled BOOLEAN NOT NULL DEFAULT true,
+			ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}',
+			ADD COLUMN updated_at TIMESTAMPTZ DEFAULT NOW()
+	`)
+	require.NoError(t, err)
+
+	connStr, err := container.ConnectionString(ctx, "sslmode=disable")
+	require.NoError(t, err)
+	require.NoError(t, database.Connect(ctx, connStr, 4, 1, 0, 0))
+	t.Cleanup(database.Close)
+	t.Cleanup(chains.Invalidate)
+	chains.Invalidate()
+}
+
+// TestChainRegistryValidation checks requests the chain registry rejects
+// before reaching the database
+func TestChainRegistryValidation(t *testing.T) {
+	router := newChainsRouter()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+		want   string
+	}{
+		{"create without name", http.MethodPost, "/internal/admin/chains", map[string]any{"slug": "kaufland"}, "Name"},
+		{"create with uppercase slug", http.MethodPost, "/internal/admin/chains", map[string]any{"slug": "Kaufland", "name": "Kaufland"}, "Slug must be"},
+		{"create with trailing dash", http.MethodPost, "/internal/admin/chains", map[string]any{"slug": "kaufland-", "name": "Kaufland"}, "Slug must be"},
+		{"create with array metadata", http.MethodPost, "/internal/admin/chains", `{"slug": "kaufland", "name": "Kaufland", "metadata": [1]}`, "Metadata must be"},
+		{"create with named color", http.MethodPost, "/internal/admin/chains", map[string]any{"slug": "kaufland", "name": "Kaufland", "brandColor": "red"}, "Brand color must be"},
+		{"create with malformed JSON", http.MethodPost, "/internal/admin/chains", `{"slug":`, ""},
+		{"update with short color", http.MethodPatch, "/internal/admin/chains/konzum", map[string]any{"brandColor": "#fff"}, "Brand color must be"},
+		{"update with string metadata", http.MethodPatch, "/internal/admin/chains/konzum", `{"metadata": "x"}`, "Metadata must be"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveJSON(t, router, tt.method, tt.path, tt.body)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var response map[string]string
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Contains(t, response["error"], tt.want)
+		})
+	}
+}
+
+// TestChainRegistryCRUD creates, updates and disables a chain through the
+// admin endpoints
+func TestChainRegistryCRUD(t *testing.T) {
+	setupChainsTestDB(t)
+	router := newChainsRouter()
+
+	w := serveJSON(t, router, http.MethodPost, "/internal/admin/chains", map[string]any{
+		"slug":       "kaufland",
+		"name":       "Kaufland",
+		"brandColor": "#e10915",
+		"metadata":   map[string]any{"country": "hr"},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var created database.Chain
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "kaufland", created.Slug)
+	assert.True(t, created.Enabled)
+	require.NotNil(t, created.BrandColor)
+	assert.Equal(t, "#e10915", *created.BrandColor)
+	assert.JSONEq(t, `{"country": "hr"}`, string(created.Metadata))
+
+	// The slug is taken now
+	w = serveJSON(t, router, http.MethodPost, "/internal/admin/chains", map[string]any{"slug": "kaufland", "name": "Other"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Omitted fields are left unchanged
+	w = serveJSON(t, router, http.MethodPatch, "/internal/admin/chains/kaufland", map[string]any{"displayName": "Kaufland Hrvatska"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var updated database.Chain
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+	assert.Equal(t, "Kaufland", updated.Name)
+	require.NotNil(t, updated.DisplayName)
+	assert.Equal(t, "Kaufland Hrvatska", *updated.DisplayName)
+	require.NotNil(t, updated.BrandColor)
+	assert.Equal(t, "#e10915", *updated.BrandColor)
+
+	w = serveJSON(t, router, http.MethodPatch, "/internal/admin/chains/missing", map[string]any{"name": "Missing"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Disabling keeps the chain in the registry but hides its metadata
+	w = serveJSON(t, router, http.MethodDelete, "/internal/admin/chains/kaufland", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var disabled database.Chain
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &disabled))
+	assert.False(t, disabled.Enabled)
+
+	w = serveJSON(t, router, http.MethodGet, "/internal/admin/chains", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var registry ListAdminChainsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &registry))
+	require.Len(t, registry.Chains, 1)
+	assert.False(t, registry.Chains[0].Enabled)
+
+	w = serveJSON(t, router, http.MethodGet, "/internal/chains/metadata", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var metadata ListChainMetadataResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &metadata))
+	assert.Empty(t, metadata.Chains)
+
+	w = serveJSON(t, router, http.MethodDelete, "/internal/admin/chains/missing", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// TestChainMetadata lists enabled chains' display metadata, gets a single
+// chain including disabled ones, and answers a matching ETag with 304
+func TestChainMetadata(t *testing.T) {
+	setupChainsTestDB(t)
+	router := newChainsRouter()
+
+	_, err := database.Pool().Exec(context.Background(), `
+		INSERT INTO chains (slug, name, display_name, brand_color, website, enabled) VALUES
+			('konzum', 'Konzum', 'Konzum plus', '#e30613', 'https://www.konzum.hr', true),
+			('lidl', 'Lidl', NULL, NULL, NULL, true),
+			('spar', 'Spar', '', NULL, NULL, false)
+	`)
+	require.NoError(t, err)
+
+	w := serveJSON(t, router, http.MethodGet, "/internal/chains/metadata", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	var list ListChainMetadataResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Chains, 2)
+	assert.Equal(t, "konzum", list.Chains[0].Slug)
+	assert.Equal(t, "Konzum plus", list.Chains[0].DisplayName)
+	require.NotNil(t, list.Chains[0].BrandColor)
+	assert.Equal(t, "#e30613", *list.Chains[0].BrandColor)
+	// Without a display name the chain name is shown
+	assert.Equal(t, "Lidl", list.Chains[1].DisplayName)
+	assert.Nil(t, list.Chains[1].BrandColor)
+
+	req := httptest.NewRequest(http.MethodGet, "/internal/chains/metadata", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	w = serveJSON(t, router, http.MethodGet, "/internal/chains/spar/metadata", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var chain ChainMetadata
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &chain))
+	assert.Equal(t, "Spar", chain.DisplayName)
+
+	w = serveJSON(t, router, http.MethodGet, "/internal/chains/missing/metadata", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	var response map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Chain not found", response["error"])
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/sync/singleflight"
+)
+
+// coalescedRequests counts requests answered with the response computed for
+// an identical concurrent request
+var coalescedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "http_coalesced_requests_total",
+	Help: "Requests served from an identical in-flight request instead of computing their own response, by endpoint",
+}, []string{"endpoint"})
+
+// storePricesFlight coalesces identical concurrent GetStorePrices requests
+var storePricesFlight singleflight.Group
+
+// storePricesKey identifies a store prices page at a snapshot version, so a
+// request arriving after the chain's prices were reloaded never joins a
+// computation over the old ones
+func storePricesKey(chainSlug, storeID string, version int64, limit, offset int) string {
+	return fmt.Sprintf("%s\x00%s\x00%d\x00%d\x00%d", chainSlug, storeID, version, limit, offset)
+}
+
+// storePricesVersion returns the version of a chain's prices: the load time of
+// its price cache snapshot, which is reloaded after every ingestion. It is 0
+// when the chain has no snapshot.
+func storePricesVersion(chainSlug string) int64 {
+	if priceCache == nil {
+		return 0
+	}
+	freshness, ok := priceCache.ChainFreshness(chainSlug)
+	if !ok {
+		return 0
+	}
+	return freshness.LoadedAt
+}
+
+// coalesce runs load once for concurrent calls with the same key on group and
+// hands every caller its result. load runs detached from the caller's
+// cancellation, since callers that joined it still wait for the result.
+func coalesce[T any](ctx context.Context, group *singleflight.Group, endpoint, key string, load func(context.Context) (T, error)) (T, error) {
+	loaded := false
+	v, err, shared := group.Do(key, func() (any, error) {
+		loaded = true
+		return load(context.WithoutCancel(ctx))
+	})
+	if shared && !loaded {
+		coalescedRequests.WithLabelValues(endpoint).Inc()
+	}
+
+	result, _ := v.(T)
+	return result, err
+}
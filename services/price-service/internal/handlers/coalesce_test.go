@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sync/singleflight"
+)
+
+// TestCoalesce verifies identical concurrent calls share one load and are
+// counted as coalesced, and that a cancelled caller does not cancel the load
+func TestCoalesce(t *testing.T) {
+	var group singleflight.Group
+	var loads atomic.Int32
+	release := make(chan struct{})
+	before := testutil.ToFloat64(coalescedRequests.WithLabelValues("test"))
+
+	load := func(ctx context.Context) (*GetStorePricesResponse, error) {
+		loads.Add(1)
+		<-release
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return &GetStorePricesResponse{Total: 7}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	const callers = 5
+	results := make([]*GetStorePricesResponse, callers)
+	var wg sync.WaitGroup
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = coalesce(ctx, &group, "test", storePricesKey("konzum", "s1", 1, 100, 0), load)
+		}()
+	}
+
+	// Let every caller join the flight before the load finishes
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), loads.Load())
+	for _, r := range results {
+		assert.Equal(t, &GetStorePricesResponse{Total: 7}, r)
+	}
+	assert.Equal(t, float64(callers-1), testutil.ToFloat64(coalescedRequests.WithLabelValues("test"))-before)
+
+	// Another snapshot version is a different key
+	assert.NotEqual(t, storePricesKey("konzum", "s1", 1, 100, 0), storePricesKey("konzum", "s1", 2, 100, 0))
+}
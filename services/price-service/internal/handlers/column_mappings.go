@@ -0,0 +1,334 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/kosarica/price-service/internal/adapters/config"
+	"github.com/kosarica/price-service/internal/adapters/registry"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/mappings"
+	"github.com/kosarica/price-service/internal/parsers/csv"
+	"github.com/kosarica/price-service/internal/types"
+)
+
+// maxTestParseFileSize bounds the sample files accepted by the test-parse and infer endpoints
+const maxTestParseFileSize = 32 << 20
+
+// testParseSampleRows and testParseMaxErrors bound the test-parse response
+const (
+	testParseSampleRows = 10
+	testParseMaxErrors  = 50
+)
+
+// csvMappingAdapter is implemented by adapters built on the base CSV adapter
+type csvMappingAdapter interface {
+	GetColumnMapping() csv.CsvColumnMapping
+	Parse(content []byte, filename string, options *types.ParseOptions) (*types.ParseResult, error)
+}
+
+// ColumnMappingResponse represents a chain's compiled-in, override and effective column mappings
+type ColumnMappingResponse struct {
+	ChainSlug string                          `json:"chainSlug" jsonschema:"required"`
+	Compiled  csv.CsvColumnMapping            `json:"compiled" jsonschema:"required"`
+	Override  *database.ColumnMappingOverride `json:"override"`
+	Effective csv.CsvColumnMapping            `json:"effective" jsonschema:"required"` // Mapping used for ingestion
+	Fields    []string                        `json:"fields" jsonschema:"required"`    // Field names an override may set
+}
+
+// SetColumnMappingRequest represents the request body for setting a chain's override
+type SetColumnMappingRequest struct {
+	Mapping     map[string]string `json:"mapping" binding:"required" jsonschema:"required"` // Field name -> header name or column index; "" unmaps an optional field
+	Enabled     bool              `json:"enabled"`
+	Description *string           `json:"description"`
+}
+
+// TestParseResponse represents the outcome of parsing a sample file with a mapping
+type TestParseResponse struct {
+	Mapping    csv.CsvColumnMapping  `json:"mapping" jsonschema:"required"`
+	TotalRows  int                   `json:"totalRows" jsonschema:"required"`
+	ValidRows  int                   `json:"validRows" jsonschema:"required"`
+	ErrorCount int                   `json:"errorCount" jsonschema:"required"`
+	Errors     []types.ParseError    `json:"errors" jsonschema:"required"`     // First errors only
+	SampleRows []types.NormalizedRow `json:"sampleRows" jsonschema:"required"` // First parsed rows
+}
+
+// GetColumnMapping returns a chain's column mappings
+// @Summary Get column mapping
+// @Description Returns the compiled-in column mapping of a CSV chain, its stored override and the mapping used for ingestion
+// @Tags chains
+// @Accept json
+// @Produce json
+// @Param slug path string true "Chain slug"
+// @Success 200 {object} ColumnMappingResponse
+// @Failure 404 {object} map[string]string "Chain not found or not CSV-based"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/chains/{slug}/mapping [get]
+func GetColumnMapping(c *gin.Context) {
+	slug := c.Param("slug")
+	adapter, ok := csvAdapterFor(c, slug)
+	if !ok {
+		return
+	}
+
+	resp := ColumnMappingResponse{
+		ChainSlug: slug,
+		Compiled:  adapter.GetColumnMapping(),
+		Effective: adapter.GetColumnMapping(),
+		Fields:    csv.MappingFields(),
+	}
+
+	override, err := database.GetColumnMappingOverride(c.Request.Context(), slug)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get column mapping override"})
+		return
+	}
+	if err == nil {
+		resp.Override = override
+		if override.Enabled {
+			if effective, err := csv.ApplyOverride(resp.Compiled, override.Mapping); err == nil {
+				resp.Effective = effective
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// SetColumnMapping creates or replaces a chain's column mapping override
+// @Summary Set column mapping override
+// @Description Validates and stores a column mapping override for a CSV chain. Verify it with the test-parse endpoint before enabling it.
+// @Tags chains
+// @Accept json
+// @Produce json
+// @Param slug path string true "Chain slug"
+// @Param request body SetColumnMappingRequest true "Override"
+// @Success 200 {object} database.ColumnMappingOverride
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 404 {object} map[string]string "Chain not found or not CSV-based"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/chains/{slug}/mapping [put]
+func SetColumnMapping(c *gin.Context) {
+	slug := c.Param("slug")
+	adapter, ok := csvAdapterFor(c, slug)
+	if !ok {
+		return
+	}
+
+	var req SetColumnMappingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := csv.ApplyOverride(adapter.GetColumnMapping(), req.Mapping); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	override := &database.ColumnMappingOverride{
+		ChainSlug:   slug,
+		Mapping:     req.Mapping,
+		Enabled:     req.Enabled,
+		Description: req.Description,
+	}
+	if err := database.UpsertColumnMappingOverride(c.Request.Context(), override); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save column mapping override"})
+		return
+	}
+	mappings.Invalidate()
+
+	c.JSON(http.StatusOK, override)
+}
+
+// DeleteColumnMapping removes a chain's column mapping override
+// @Summary Delete column mapping override
+// @Description Removes the override so the chain is parsed with its compiled-in mapping
+// @Tags chains
+// @Accept json
+// @Produce json
+// @Param slug path string true "Chain slug"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string "Override not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/chains/{slug}/mapping [delete]
+func DeleteColumnMapping(c *gin.Context) {
+	slug := c.Param("slug")
+
+	deleted, err := database.DeleteColumnMappingOverride(c.Request.Context(), slug)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete column mapping override"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Column mapping override not found"})
+		return
+	}
+	mappings.Invalidate()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Column mapping override deleted successfully",
+		"chainSlug": slug,
+	})
+}
+
+// TestParseColumnMapping parses a sample file with a column mapping override
+// @Summary Test-parse with column mapping
+// @Description Parses an uploaded sample file through the chain's adapter with the given override (or the stored one, enabled or not) without persisting anything
+// @Tags chains
+// @Accept multipart/form-data
+// @Produce json
+// @Param slug path string true "Chain slug"
+// @Param file formData file true "Sample price file"
+// @Param mapping formData string false "Override as a JSON object; defaults to the stored override"
+// @Success 200 {object} TestParseResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 404 {object} map[string]string "Chain not found or not CSV-based"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/chains/{slug}/mapping/test [post]
+func TestParseColumnMapping(c *gin.Context) {
+	slug := c.Param("slug")
+	adapter, ok := csvAdapterFor(c, slug)
+	if !ok {
+		return
+	}
+
+	override := map[string]string{}
+	if raw := c.PostForm("mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &override); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "mapping must be a JSON object of strings"})
+			return
+		}
+	} else {
+		stored, err := database.GetColumnMappingOverride(c.Request.Context(), slug)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get column mapping override"})
+			return
+		}
+		if err == nil {
+			override = stored.Mapping
+		}
+	}
+
+	mapping, err := csv.ApplyOverride(adapter.GetColumnMapping(), override)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	content, filename, ok := readSampleFile(c)
+	if !ok {
+		return
+	}
+
+	// An empty non-nil override keeps a stored, enabled override from applying
+	result, err := adapter.Parse(content, filename, &types.ParseOptions{ColumnMappingOverride: override})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := TestParseResponse{
+		Mapping:    mapping,
+		TotalRows:  result.TotalRows,
+		ValidRows:  result.ValidRows,
+		ErrorCount: len(result.Errors),
+		Errors:     result.Errors,
+		SampleRows: result.Rows,
+	}
+	if resp.Errors == nil {
+		resp.Errors = []types.ParseError{}
+	}
+	if resp.SampleRows == nil {
+		resp.SampleRows = []types.NormalizedRow{}
+	}
+	if len(resp.Errors) > testParseMaxErrors {
+		resp.Errors = resp.Errors[:testParseMaxErrors]
+	}
+	if len(resp.SampleRows) > testParseSampleRows {
+		resp.SampleRows = resp.SampleRows[:testParseSampleRows]
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// InferColumnMapping proposes a column mapping for a sample file
+// @Summary Infer column mapping
+// @Description Profiles the columns of an uploaded CSV or XLSX sample (price-, date-, barcode-, integer- and text-like values plus header names) and proposes a column mapping for a human to confirm
+// @Tags chains
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Sample price file"
+// @Param rows formData int false "Number of data rows to profile" default(500)
+// @Success 200 {object} mappings.Proposal
+// @Failure 400 {object} map[string]string "Bad request"
+// @Router /internal/admin/mappings/infer [post]
+func InferColumnMapping(c *gin.Context) {
+	content, filename, ok := readSampleFile(c)
+	if !ok {
+		return
+	}
+
+	rows := mappings.DefaultProfileRows
+	if raw := c.PostForm("rows"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > 100000 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "rows must be between 1 and 100000"})
+			return
+		}
+		rows = n
+	}
+
+	proposal, err := mappings.Infer(content, filename, rows)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, proposal)
+}
+
+// readSampleFile reads the uploaded "file" form field, writing a 400 on failure
+func readSampleFile(c *gin.Context) ([]byte, string, bool) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return nil, "", false
+	}
+	if fileHeader.Size > maxTestParseFileSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is too large"})
+		return nil, "", false
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+		return nil, "", false
+	}
+	defer file.Close()
+	content, err := io.ReadAll(io.LimitReader(file, maxTestParseFileSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+		return nil, "", false
+	}
+	return content, fileHeader.Filename, true
+}
+
+// csvAdapterFor returns the CSV adapter of a chain, writing a 404 if the
+// chain has no adapter or is not CSV-based
+func csvAdapterFor(c *gin.Context, slug string) (csvMappingAdapter, bool) {
+	adapter, err := registry.GetAdapter(config.ChainID(slug))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chain adapter not found"})
+		return nil, false
+	}
+	csvAdapter, ok := adapter.(csvMappingAdapter)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chain is not CSV-based; column mapping overrides are not supported"})
+		return nil, false
+	}
+	return csvAdapter, true
+}
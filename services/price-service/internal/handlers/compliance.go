@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/jobs"
+)
+
+// GetAnchorPriceComplianceRequest represents query parameters for the anchor
+// price compliance history
+type GetAnchorPriceComplianceRequest struct {
+	ChainSlug string `form:"chainSlug" json:"chainSlug"`
+	Days      int    `form:"days" json:"days" binding:"omitempty,min=1,max=365" jsonschema:"minimum=1,maximum=365"`
+}
+
+// GetAnchorPriceComplianceResponse represents the stored anchor price
+// compliance reports
+type GetAnchorPriceComplianceResponse struct {
+	From    time.Time                    `json:"from" jsonschema:"required"`
+	Reports []jobs.AnchorComplianceStats `json:"reports" jsonschema:"required"`
+}
+
+// GetAnchorPriceCompliance returns the anchor price compliance history
+// @Summary Get anchor price compliance reports
+// @Description Returns the stored anchor price ("sidrena cijena") compliance reports, newest first: per chain, the store prices on an active discount with no anchor price or with one set fewer than the configured minimum days before the report, and their shares of all discounted prices. Reports are computed by a scheduled job, daily by default.
+// @Tags compliance
+// @Produce json
+// @Param chainSlug query string false "Filter by chain slug"
+// @Param days query int false "Number of days of history to include" default(30) minimum(1) maximum(365)
+// @Success 200 {object} GetAnchorPriceComplianceResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/compliance/anchor-prices [get]
+func GetAnchorPriceCompliance(c *gin.Context) {
+	var req GetAnchorPriceComplianceRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Days == 0 {
+		req.Days = 30
+	}
+
+	from := time.Now().UTC().AddDate(0, 0, -req.Days)
+	reports, err := jobs.QueryAnchorComplianceHistory(c.Request.Context(), database.Pool(), from, req.ChainSlug)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to fetch anchor price compliance reports")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch anchor price compliance reports"})
+		return
+	}
+
+	c.JSON(http.StatusOK, GetAnchorPriceComplianceResponse{From: from, Reports: reports})
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"cmp"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/optimizer"
+)
+
+// GetChainDiscountsRequest represents query parameters for listing a chain's
+// current discounts
+type GetChainDiscountsRequest struct {
+	MinPercent float64 `form:"minPercent" json:"minPercent" binding:"min=0,max=100" jsonschema:"minimum=0,maximum=100"`
+	Category   string  `form:"category" json:"category"`
+	Sort       string  `form:"sort" json:"sort" binding:"omitempty,oneof=asc desc" jsonschema:"enum=asc,enum=desc"`
+	Limit      int     `form:"limit" json:"limit" binding:"omitempty,min=1,max=500" jsonschema:"minimum=1,maximum=500"`
+	Offset     int     `form:"offset" json:"offset" binding:"min=0" jsonschema:"minimum=0"`
+}
+
+// ChainDiscount is an item currently on discount in a chain
+type ChainDiscount struct {
+	RetailerItemID  string     `json:"retailerItemId" jsonschema:"required"`
+	ItemName        *string    `json:"itemName"`
+	Brand           *string    `json:"brand"`
+	Category        *string    `json:"category"`
+	Price           int64      `json:"price" jsonschema:"required"`
+	DiscountPrice   int64      `json:"discountPrice" jsonschema:"required"`
+	DiscountPercent float64    `json:"discountPercent" jsonschema:"required"`
+	DiscountStart   *time.Time `json:"discountStart"`
+	DiscountEnd     *time.Time `json:"discountEnd"`
+	StoreCount      int        `json:"storeCount" jsonschema:"required"`
+}
+
+// GetChainDiscountsResponse represents the response for a chain's discounts
+type GetChainDiscountsResponse struct {
+	Discounts []ChainDiscount `json:"discounts" jsonschema:"required"`
+	Total     int             `json:"total" jsonschema:"required"`
+}
+
+// GetChainDiscounts lists the items currently on discount in a chain
+// @Summary List current discounts
+// @Description Returns the items of a chain on discount right now, from the price cache, with the deepest discount across price groups, its validity window and how many stores have the item on discount. Discounts whose window has not started or has ended are left out. Sorted by discount percent, deepest first unless sort=asc.
+// @Tags prices
+// @Accept json
+// @Produce json
+// @Param chainSlug path string true "Chain slug identifier"
+// @Param minPercent query number false "Minimum discount percent" default(0) minimum(0) maximum(100)
+// @Param category query string false "Only items in this category"
+// @Param sort query string false "Sort order by discount percent" Enums(desc, asc) default(desc)
+// @Param limit query int false "Number of items to return" default(50) minimum(1) maximum(500)
+// @Param offset query int false "Number of items to skip" default(0) minimum(0)
+// @Success 200 {object} GetChainDiscountsResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 404 {object} map[string]string "Chain not cached"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 503 {object} map[string]string "Cache not initialized"
+// @Router /internal/prices/{chainSlug}/discounts [get]
+func GetChainDiscounts(c *gin.Context) {
+	chainSlug := c.Param("chainSlug")
+
+	var req GetChainDiscountsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Set defaults
+	if req.Limit == 0 {
+		req.Limit = 50
+	}
+
+	if priceCache == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Cache not initialized"})
+		return
+	}
+
+	items, ok := priceCache.CurrentDiscounts(chainSlug, time.Now())
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chain not cached"})
+		return
+	}
+
+	items = filterDiscounts(items, req.MinPercent, req.Category)
+	sortDiscounts(items, req.Sort == "asc")
+
+	total := len(items)
+	page := items[min(req.Offset, total):min(req.Offset+req.Limit, total)]
+
+	discounts := make([]ChainDiscount, len(page))
+	ids := make([]string, len(page))
+	for i, item := range page {
+		discounts[i] = ChainDiscount{
+			RetailerItemID:  item.ItemID,
+			Price:           item.Price,
+			DiscountPrice:   item.DiscountPrice,
+			DiscountPercent: math.Round(item.DiscountPercent*10) / 10,
+			DiscountStart:   item.DiscountStart,
+			DiscountEnd:     item.DiscountEnd,
+			StoreCount:      item.StoreCount,
+		}
+		if item.Category != "" {
+			discounts[i].Category = &item.Category
+		}
+		ids[i] = item.ItemID
+	}
+
+	// Names and brands for the page only; the cache holds prices
+	rows, err := database.Pool().Query(c.Request.Context(), `
+		SELECT id, name, brand
+		FROM retailer_items
+		WHERE id = ANY($1)
+	`, ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch items"})
+		return
+	}
+	defer rows.Close()
+
+	positions := make(map[string]int, len(ids))
+	for i, id := range ids {
+		positions[id] = i
+	}
+	for rows.Next() {
+		var id string
+		var name, brand *string
+		if err := rows.Scan(&id, &name, &brand); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan item"})
+			return
+		}
+		discounts[positions[id]].ItemName = name
+		discounts[positions[id]].Brand = brand
+	}
+	if rows.Err() != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error iterating items"})
+		return
+	}
+
+	c.JSON(http.StatusOK, GetChainDiscountsResponse{
+		Discounts: discounts,
+		Total:     total,
+	})
+}
+
+// filterDiscounts keeps the discounts at least minPercent deep and, when
+// category is set, in that category
+func filterDiscounts(items []optimizer.DiscountedItem, minPercent float64, category string) []optimizer.DiscountedItem {
+	return slices.DeleteFunc(items, func(item optimizer.DiscountedItem) bool {
+		return item.DiscountPercent < minPercent || (category != "" && item.Category != category)
+	})
+}
+
+// sortDiscounts orders discounts by percent, deepest first unless ascending.
+// Ties go by item ID so pages are stable.
+func sortDiscounts(items []optimizer.DiscountedItem, ascending bool) {
+	slices.SortFunc(items, func(a, b optimizer.DiscountedItem) int {
+		byPercent := cmp.Compare(b.DiscountPercent, a.DiscountPercent)
+		if ascending {
+			byPercent = -byPercent
+		}
+		if byPercent != 0 {
+			return byPercent
+		}
+		return strings.Compare(a.ItemID, b.ItemID)
+	})
+}
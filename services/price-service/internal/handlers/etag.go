@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// chainsMaxAge is how long clients may reuse chain lists and metadata before
+// revalidating. The registry rarely changes, and revalidation is cheap.
+const chainsMaxAge = 60
+
+// writeCachedJSON writes body as JSON with a strong ETag, the hash of the
+// encoded body, and a Cache-Control header allowing clients to reuse it for
+// maxAge seconds. A request whose If-None-Match carries the ETag gets an
+// empty 304 Not Modified instead.
+func writeCachedJSON(c *gin.Context, maxAge int, body any) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode response"})
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d, must-revalidate", maxAge))
+
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+}
+
+// etagMatches reports whether an If-None-Match header matches etag. It uses
+// the weak comparison RFC 9110 requires for If-None-Match, so a W/ prefix
+// added by a proxy still matches.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
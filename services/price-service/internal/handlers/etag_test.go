@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWriteCachedJSON tests ETag and Cache-Control headers and 304 handling
+func TestWriteCachedJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	body := ListChainsResponse{Chains: []string{"konzum", "lidl"}}
+
+	serve := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.GET("/chains", func(c *gin.Context) { writeCachedJSON(c, chainsMaxAge, body) })
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/chains", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := serve("")
+	require.Equal(t, http.StatusOK, first.Code)
+	assert.JSONEq(t, `{"chains":["konzum","lidl"]}`, first.Body.String())
+	assert.Equal(t, "private, max-age=60, must-revalidate", first.Header().Get("Cache-Control"))
+
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Equal(t, etag, serve("").Header().Get("ETag"), "same content has the same ETag")
+
+	for _, header := range []string{etag, "W/" + etag, `"other", ` + etag, "*"} {
+		w := serve(header)
+		assert.Equal(t, http.StatusNotModified, w.Code, "If-None-Match %s", header)
+		assert.Empty(t, w.Body.String())
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+	}
+
+	w := serve(`"stale"`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, w.Body.String())
+}
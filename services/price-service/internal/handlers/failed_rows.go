@@ -18,6 +18,7 @@ type FailedRow struct {
 	StoreIdentifier  string   `json:"storeIdentifier"`
 	RowNumber        int      `json:"rowNumber"`
 	RawData          string   `json:"rawData"`
+	RawPayloadID     *string  `json:"rawPayloadId"`
 	ValidationErrors []string `json:"validationErrors"`
 	FailedAt         string   `json:"failedAt"`
 	Reviewed         bool     `json:"reviewed"`
@@ -74,7 +75,8 @@ func GetFailedRows(c *gin.Context) {
 			file_id,
 			store_identifier,
 			row_number,
-			raw_data,
+			COALESCE(raw_data, ''),
+			raw_payload_id,
 			validation_errors,
 			failed_at,
 			reviewed,
@@ -109,6 +111,7 @@ func GetFailedRows(c *gin.Context) {
 			&row.StoreIdentifier,
 			&row.RowNumber,
 			&row.RawData,
+			&row.RawPayloadID,
 			&validationErrors,
 			&row.FailedAt,
 			&row.Reviewed,
@@ -198,7 +201,7 @@ func ReprocessFailedRows(c *gin.Context) {
 			file_id,
 			store_identifier,
 			row_number,
-			raw_data,
+			COALESCE(raw_data, ''),
 			validation_errors
 		FROM retailer_items_failed
 		WHERE id = ANY($1) AND reprocessable = true
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/featureflags"
+)
+
+var featureFlagKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// ListFeatureFlagsResponse represents the response for listing feature flags
+type ListFeatureFlagsResponse struct {
+	Flags []database.FeatureFlag `json:"flags" jsonschema:"required"`
+}
+
+// SetFeatureFlagRequest represents the request body for creating or replacing a flag
+type SetFeatureFlagRequest struct {
+	Description *string  `json:"description"`
+	Enabled     bool     `json:"enabled"`
+	Chains      []string `json:"chains"` // Limit the flag to these chains; empty means all
+}
+
+// EvaluateFeatureFlagsRequest represents query parameters for flag evaluation
+type EvaluateFeatureFlagsRequest struct {
+	ChainSlug string `form:"chainSlug" json:"chainSlug"`
+}
+
+// EvaluateFeatureFlagsResponse represents the evaluated state of every flag
+type EvaluateFeatureFlagsResponse struct {
+	Flags map[string]bool `json:"flags" jsonschema:"required"`
+}
+
+// ListFeatureFlags returns all feature flags
+// @Summary List feature flags
+// @Description Returns all feature flags with their rollout rules
+// @Tags flags
+// @Accept json
+// @Produce json
+// @Success 200 {object} ListFeatureFlagsResponse
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/flags [get]
+func ListFeatureFlags(c *gin.Context) {
+	flags, err := database.ListFeatureFlags(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list feature flags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListFeatureFlagsResponse{Flags: flags})
+}
+
+// SetFeatureFlag creates or replaces a feature flag
+// @Summary Set feature flag
+// @Description Creates or replaces a feature flag. Chains limits the flag to a staged rollout; an empty list enables it for every chain.
+// @Tags flags
+// @Accept json
+// @Produce json
+// @Param key path string true "Flag key"
+// @Param request body SetFeatureFlagRequest true "Flag settings"
+// @Success 200 {object} database.FeatureFlag
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/flags/{key} [put]
+func SetFeatureFlag(c *gin.Context) {
+	key := c.Param("key")
+	if !featureFlagKeyPattern.MatchString(key) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Flag key must be lowercase letters, digits and underscores"})
+		return
+	}
+
+	var req SetFeatureFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	flag := &database.FeatureFlag{
+		Key:         key,
+		Description: req.Description,
+		Enabled:     req.Enabled,
+		Chains:      req.Chains,
+	}
+	if err := database.UpsertFeatureFlag(c.Request.Context(), flag); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save feature flag"})
+		return
+	}
+	featureflags.Invalidate()
+
+	c.JSON(http.StatusOK, flag)
+}
+
+// DeleteFeatureFlag removes a feature flag
+// @Summary Delete feature flag
+// @Description Removes a feature flag; lookups of a missing flag evaluate to off
+// @Tags flags
+// @Accept json
+// @Produce json
+// @Param key path string true "Flag key"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string "Flag not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/flags/{key} [delete]
+func DeleteFeatureFlag(c *gin.Context) {
+	key := c.Param("key")
+
+	deleted, err := database.DeleteFeatureFlag(c.Request.Context(), key)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete feature flag"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Feature flag not found"})
+		return
+	}
+	featureflags.Invalidate()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Feature flag deleted successfully",
+		"key":     key,
+	})
+}
+
+// EvaluateFeatureFlags returns the effective state of every flag
+// @Summary Evaluate feature flags
+// @Description Evaluates all flags for a chain, applying X-Feature-Flags request overrides
+// @Tags flags
+// @Accept json
+// @Produce json
+// @Param chainSlug query string false "Chain to evaluate chain-limited flags for"
+// @Param X-Feature-Flags header string false "Comma-separated overrides; prefix a key with '-' to force it off"
+// @Success 200 {object} EvaluateFeatureFlagsResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/flags [get]
+func EvaluateFeatureFlags(c *gin.Context) {
+	var req EvaluateFeatureFlagsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	flags, err := database.ListFeatureFlags(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list feature flags"})
+		return
+	}
+
+	evaluated := make(map[string]bool, len(flags))
+	for _, flag := range flags {
+		evaluated[flag.Key] = featureflags.IsEnabled(ctx, flag.Key, req.ChainSlug)
+	}
+	for key, on := range featureflags.ParseOverrides(c.GetHeader(featureflags.HeaderName)) {
+		evaluated[key] = on
+	}
+
+	c.JSON(http.StatusOK, EvaluateFeatureFlagsResponse{Flags: evaluated})
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kosarica/price-service/internal/pkg/money"
+)
+
+// FormattedStorePrice holds display strings of a store price's amounts
+type FormattedStorePrice struct {
+	CurrentPrice    *string `json:"currentPrice,omitempty" proto:"1"`
+	PreviousPrice   *string `json:"previousPrice,omitempty" proto:"2"`
+	DiscountPrice   *string `json:"discountPrice,omitempty" proto:"3"`
+	UnitPrice       *string `json:"unitPrice,omitempty" proto:"4"`
+	LowestPrice30d  *string `json:"lowestPrice30d,omitempty" proto:"5"`
+	AnchorPrice     *string `json:"anchorPrice,omitempty" proto:"6"`
+	DiscountPercent *string `json:"discountPercent,omitempty" proto:"7"` // Discount off the current price, e.g. "-25%"
+}
+
+// FormattedItemPrice holds display strings of an optimized item's amounts
+type FormattedItemPrice struct {
+	BasePrice       string  `json:"basePrice" jsonschema:"required" proto:"1"`
+	EffectivePrice  string  `json:"effectivePrice" jsonschema:"required" proto:"2"`
+	DiscountPrice   *string `json:"discountPrice,omitempty" proto:"3"`
+	LineTotal       string  `json:"lineTotal" jsonschema:"required" proto:"4"`
+	DiscountPercent *string `json:"discountPercent,omitempty" proto:"5"` // Discount off the base price, e.g. "-25%"
+}
+
+// includeFormatted reports whether a request asks for display strings of its
+// response's amounts with ?includeFormatted=true
+func includeFormatted(c *gin.Context) bool {
+	include, _ := strconv.ParseBool(c.Query("includeFormatted"))
+	return include
+}
+
+// formatCents formats an optional amount in cents
+func formatCents(cents *int) *string {
+	if cents == nil {
+		return nil
+	}
+	s := money.Format(int64(*cents))
+	return &s
+}
+
+// formatDiscount formats the discount of an optional discounted price off a
+// regular price, nil when it is no discount
+func formatDiscount(price, discountPrice int64) *string {
+	percent, ok := money.DiscountPercent(price, discountPrice)
+	if !ok {
+		return nil
+	}
+	s := money.FormatDiscount(percent)
+	return &s
+}
+
+// formatStorePrice returns the display strings of a store price
+func formatStorePrice(price StorePrice) *FormattedStorePrice {
+	formatted := &FormattedStorePrice{
+		CurrentPrice:   formatCents(price.CurrentPrice),
+		PreviousPrice:  formatCents(price.PreviousPrice),
+		DiscountPrice:  formatCents(price.DiscountPrice),
+		UnitPrice:      formatCents(price.UnitPrice),
+		LowestPrice30d: formatCents(price.LowestPrice30d),
+		AnchorPrice:    formatCents(price.AnchorPrice),
+	}
+	if price.CurrentPrice != nil && price.DiscountPrice != nil {
+		formatted.DiscountPercent = formatDiscount(int64(*price.CurrentPrice), int64(*price.DiscountPrice))
+	}
+	return formatted
+}
+
+// formatItemPrice returns the display strings of an optimized item
+func formatItemPrice(item *ItemPriceInfo) *FormattedItemPrice {
+	formatted := &FormattedItemPrice{
+		BasePrice:      money.Format(item.BasePrice),
+		EffectivePrice: money.Format(item.EffectivePrice),
+		LineTotal:      money.Format(item.LineTotal),
+	}
+	if item.DiscountPrice != nil {
+		s := money.Format(*item.DiscountPrice)
+		formatted.DiscountPrice = &s
+		formatted.DiscountPercent = formatDiscount(item.BasePrice, *item.DiscountPrice)
+	}
+	return formatted
+}
+
+// formatTotal formats a basket total
+func formatTotal(cents int64) *string {
+	s := money.Format(cents)
+	return &s
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncludeFormatted(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for query, want := range map[string]bool{"": false, "?includeFormatted=true": true, "?includeFormatted=1": true, "?includeFormatted=no": false} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/internal/prices/konzum/s1"+query, nil)
+		assert.Equal(t, want, includeFormatted(c), query)
+	}
+}
+
+func TestFormatStorePrice(t *testing.T) {
+	current, discount, unit := 400, 300, 123456
+	formatted := formatStorePrice(StorePrice{CurrentPrice: &current, DiscountPrice: &discount, UnitPrice: &unit})
+
+	require.NotNil(t, formatted.CurrentPrice)
+	assert.Equal(t, "4,00 €", *formatted.CurrentPrice)
+	assert.Equal(t, "3,00 €", *formatted.DiscountPrice)
+	assert.Equal(t, "1.234,56 €", *formatted.UnitPrice)
+	assert.Equal(t, "-25%", *formatted.DiscountPercent)
+	assert.Nil(t, formatted.PreviousPrice)
+	assert.Nil(t, formatted.AnchorPrice)
+}
+
+func TestFormatItemPrice(t *testing.T) {
+	discount := int64(150)
+	formatted := formatItemPrice(&ItemPriceInfo{BasePrice: 200, EffectivePrice: 150, DiscountPrice: &discount, LineTotal: 450})
+
+	assert.Equal(t, "2,00 €", formatted.BasePrice)
+	assert.Equal(t, "1,50 €", formatted.EffectivePrice)
+	assert.Equal(t, "4,50 €", formatted.LineTotal)
+	require.NotNil(t, formatted.DiscountPercent)
+	assert.Equal(t, "-25%", *formatted.DiscountPercent)
+
+	plain := formatItemPrice(&ItemPriceInfo{BasePrice: 200, EffectivePrice: 200, LineTotal: 200})
+	assert.Nil(t, plain.DiscountPrice)
+	assert.Nil(t, plain.DiscountPercent)
+}
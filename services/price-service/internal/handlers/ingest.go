@@ -5,17 +5,42 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"sync"
 
 	"github.com/gin-gonic/gin"
 	"github.com/kosarica/price-service/internal/chains"
 	"github.com/kosarica/price-service/internal/database"
 	"github.com/kosarica/price-service/internal/pipeline"
-	"github.com/rs/zerolog/log"
 )
 
 // ingestionSem limits concurrent ingestion goroutines to prevent resource exhaustion
 var ingestionSem = make(chan struct{}, 10) // Max 10 concurrent ingestion runs
 
+// chainIngestLocks allows one ingestion of a chain at a time; a second waits
+// for the first to finish
+var chainIngestLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// lockChainIngestion waits until no other ingestion of a chain runs and
+// returns the function releasing it
+func lockChainIngestion(chainID string) func() {
+	chainIngestLocks.mu.Lock()
+	if chainIngestLocks.locks == nil {
+		chainIngestLocks.locks = make(map[string]*sync.Mutex)
+	}
+	lock, ok := chainIngestLocks.locks[chainID]
+	if !ok {
+		lock = &sync.Mutex{}
+		chainIngestLocks.locks[chainID] = lock
+	}
+	chainIngestLocks.mu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
 // IngestChainRequest represents a request body for triggering ingestion
 type IngestChainRequest struct {
 	TargetDate string `json:"targetDate,omitempty"` // YYYY-MM-DD format
@@ -77,25 +102,8 @@ func IngestChain(c *gin.Context) {
 		return
 	}
 
-	// Spawn goroutine for actual processing
-	go func() {
-		// Acquire semaphore slot (blocks if max concurrent reached)
-		ingestionSem <- struct{}{}
-		defer func() { <-ingestionSem }() // Release semaphore slot when done
-
-		// Use a background context for the goroutine
-		bgCtx := context.Background()
-		result, runErr := pipeline.Run(bgCtx, chainID, req.TargetDate)
-
-		// Update run status based on result
-		if runErr != nil {
-			markRunFailed(bgCtx, runID, runErr.Error())
-		} else if !result.Success {
-			markRunFailed(bgCtx, runID, fmt.Sprintf("Ingestion completed with %d errors", len(result.Errors)))
-		} else {
-			markRunCompleted(bgCtx, runID, result.FilesProcessed, result.EntriesPersisted)
-		}
-	}()
+	// Spawn goroutine for actual processing, using a background context
+	go runChainIngestion(context.Background(), runID, chainID, req.TargetDate)
 
 	// Return 202 Accepted immediately
 	c.JSON(http.StatusAccepted, IngestChainStartedResponse{
@@ -189,6 +197,44 @@ func ListIngestionRuns(c *gin.Context) {
 	})
 }
 
+// runChainIngestion runs the pipeline for a chain and records the outcome on
+// an API run. It waits for any other ingestion of the chain and for a free
+// ingestion slot first.
+func runChainIngestion(ctx context.Context, runID int64, chainID, targetDate string) {
+	unlock := lockChainIngestion(chainID)
+	defer unlock()
+
+	// Acquire semaphore slot (blocks if max concurrent reached)
+	ingestionSem <- struct{}{}
+	defer func() { <-ingestionSem }() // Release semaphore slot when done
+
+	markRunStarted(ctx, runID)
+	result, runErr := pipeline.Run(ctx, chainID, targetDate)
+
+	// Update run status based on result
+	if runErr != nil {
+		markRunFailed(ctx, runID, runErr.Error())
+	} else if !result.Success {
+		markRunFailed(ctx, runID, fmt.Sprintf("Ingestion completed with %d errors", len(result.Errors)))
+	} else {
+		markRunCompleted(ctx, runID, result.FilesProcessed, result.EntriesPersisted)
+	}
+}
+
+// markRunStarted moves a pending ingestion run to running
+func markRunStarted(ctx context.Context, runID int64) {
+	pool := database.Pool()
+	_, err := pool.Exec(ctx, `
+		UPDATE ingestion_runs
+		SET status = 'running',
+		    started_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+	`, runID)
+	if err != nil {
+		logger.Error().Err(err).Int64("runID", runID).Msg("Failed to mark run as started")
+	}
+}
+
 // markRunFailed marks an ingestion run as failed
 func markRunFailed(ctx context.Context, runID int64, errorMsg string) {
 	pool := database.Pool()
@@ -200,22 +246,23 @@ func markRunFailed(ctx context.Context, runID int64, errorMsg string) {
 		WHERE id = $1
 	`, runID, fmt.Sprintf(`{"error": "%s"}`, errorMsg))
 	if err != nil {
-		log.Error().Err(err).Int64("runID", runID).Msg("Failed to mark run as failed")
+		logger.Error().Err(err).Int64("runID", runID).Msg("Failed to mark run as failed")
 	}
 }
 
-// markRunCompleted marks an ingestion run as completed
+// markRunCompleted marks an ingestion run as completed. The counters only
+// move forward, so a concurrent increment is never overwritten by a lower value.
 func markRunCompleted(ctx context.Context, runID int64, filesProcessed int, entriesPersisted int) {
 	pool := database.Pool()
 	_, err := pool.Exec(ctx, `
 		UPDATE ingestion_runs
 		SET status = 'completed',
 		    completed_at = NOW(),
-		    processed_files = $2,
-		    processed_entries = $3
+		    processed_files = GREATEST(COALESCE(processed_files, 0), $2),
+		    processed_entries = GREATEST(COALESCE(processed_entries, 0), $3)
 		WHERE id = $1
 	`, runID, filesProcessed, entriesPersisted)
 	if err != nil {
-		log.Error().Err(err).Int64("runID", runID).Msg("Failed to mark run as completed")
+		logger.Error().Err(err).Int64("runID", runID).Msg("Failed to mark run as completed")
 	}
 }
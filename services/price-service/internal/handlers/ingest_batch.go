@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/kosarica/price-service/internal/chains"
+	"github.com/kosarica/price-service/internal/database"
+)
+
+// Chains of a bulk ingest batch running at the same time
+const (
+	defaultBatchConcurrency = 2
+	maxBatchConcurrency     = 10
+)
+
+// allChains selects every enabled chain in a bulk ingest request
+const allChains = "all"
+
+// IngestChainsRequest represents a bulk ingest request
+type IngestChainsRequest struct {
+	Chains      []string `json:"chains" binding:"required,min=1" jsonschema:"required,minItems=1"` // Chain slugs, or ["all"]
+	Concurrency int      `json:"concurrency,omitempty" binding:"omitempty,min=1,max=10" jsonschema:"minimum=1,maximum=10"`
+	TargetDate  string   `json:"targetDate,omitempty"` // YYYY-MM-DD format
+}
+
+// IngestChainsStartedResponse represents the 202 response of a bulk ingest
+type IngestChainsStartedResponse struct {
+	BatchID string   `json:"batchId" jsonschema:"required"`
+	Status  string   `json:"status" jsonschema:"required"`
+	Chains  []string `json:"chains" jsonschema:"required"` // In start order
+	PollURL string   `json:"pollUrl" jsonschema:"required"`
+}
+
+// IngestionBatchResponse represents a bulk ingest batch and its progress
+type IngestionBatchResponse struct {
+	BatchID     string                  `json:"batchId" jsonschema:"required"`
+	Status      string                  `json:"status" jsonschema:"required,enum=running,enum=completed,enum=partial,enum=failed"`
+	Concurrency int                     `json:"concurrency" jsonschema:"required"`
+	TargetDate  *string                 `json:"targetDate,omitempty"`
+	CreatedAt   time.Time               `json:"createdAt" jsonschema:"required"`
+	CompletedAt *time.Time              `json:"completedAt,omitempty"`
+	Progress    IngestionBatchProgress  `json:"progress" jsonschema:"required"`
+	Runs        []IngestionBatchRunInfo `json:"runs" jsonschema:"required"`
+}
+
+// IngestionBatchProgress represents the aggregate progress of a batch's runs
+type IngestionBatchProgress struct {
+	Total            int     `json:"total" jsonschema:"required"`
+	Pending          int     `json:"pending" jsonschema:"required"`
+	Running          int     `json:"running" jsonschema:"required"`
+	Completed        int     `json:"completed" jsonschema:"required"`
+	Failed           int     `json:"failed" jsonschema:"required"` // Failed or interrupted
+	Percent          float64 `json:"percent" jsonschema:"required"`
+	FilesProcessed   int     `json:"filesProcessed" jsonschema:"required"`
+	EntriesPersisted int     `json:"entriesPersisted" jsonschema:"required"`
+}
+
+// IngestionBatchRunInfo represents one chain's run in a batch
+type IngestionBatchRunInfo struct {
+	RunID            string     `json:"runId" jsonschema:"required"`
+	ChainSlug        string     `json:"chainSlug" jsonschema:"required"`
+	Status           string     `json:"status" jsonschema:"required"`
+	StartedAt        *time.Time `json:"startedAt,omitempty"`
+	CompletedAt      *time.Time `json:"completedAt,omitempty"`
+	FilesProcessed   int        `json:"filesProcessed" jsonschema:"required"`
+	EntriesPersisted int        `json:"entriesPersisted" jsonschema:"required"`
+}
+
+// IngestChains triggers ingestion of several chains as one batch
+// @Summary Ingest several chains
+// @Description Creates a pending run for each chain, or every enabled chain for ["all"], and ingests them in the background with at most concurrency chains at a time. Chains start smallest first, by the entries of their last completed run; chains never ingested start last. A chain already being ingested waits for that run to finish. Returns the batch ID to poll for aggregate progress.
+// @Tags ingestion
+// @Accept json
+// @Produce json
+// @Param request body IngestChainsRequest true "Chains to ingest"
+// @Success 202 {object} IngestChainsStartedResponse
+// @Failure 400 {object} map[string]string "Invalid request or chain"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/ingest [post]
+func IngestChains(c *gin.Context) {
+	var req IngestChainsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	slugs, err := resolveBatchChains(req.Chains)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	concurrency := req.Concurrency
+	if concurrency == 0 {
+		concurrency = defaultBatchConcurrency
+	}
+
+	ctx := c.Request.Context()
+	sizes, err := database.ChainIngestionSizes(ctx, slugs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read chain sizes"})
+		return
+	}
+	orderChainsBySize(slugs, sizes)
+
+	batch, err := database.CreateIngestionBatch(ctx, slugs, concurrency, req.TargetDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create ingestion batch"})
+		return
+	}
+
+	go runIngestionBatch(context.Background(), batch, req.TargetDate)
+
+	batchID := strconv.FormatInt(batch.ID, 10)
+	c.JSON(http.StatusAccepted, IngestChainsStartedResponse{
+		BatchID: batchID,
+		Status:  "started",
+		Chains:  slugs,
+		PollURL: "/internal/admin/ingest/batches/" + batchID,
+	})
+}
+
+// GetIngestionBatch returns the progress of a bulk ingest batch
+// @Summary Get ingestion batch
+// @Description Returns a bulk ingest batch with the status of each chain's run and the aggregate progress. The batch is running while any run is pending or running, then completed, partial when some runs failed or were interrupted, or failed when none completed.
+// @Tags ingestion
+// @Produce json
+// @Param batchId path int true "Batch ID"
+// @Success 200 {object} IngestionBatchResponse
+// @Failure 400 {object} map[string]string "Invalid batch ID"
+// @Failure 404 {object} map[string]string "Batch not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/ingest/batches/{batchId} [get]
+func GetIngestionBatch(c *gin.Context) {
+	batchID, err := strconv.ParseInt(c.Param("batchId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid batch ID"})
+		return
+	}
+
+	batch, err := database.GetIngestionBatch(c.Request.Context(), batchID)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Batch not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get ingestion batch"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toIngestionBatchResponse(batch))
+}
+
+// resolveBatchChains expands "all" and validates and deduplicates the chains
+// of a bulk ingest request
+func resolveBatchChains(requested []string) ([]string, error) {
+	if len(requested) == 1 && requested[0] == allChains {
+		return append([]string(nil), chains.ValidChains()...), nil
+	}
+
+	seen := make(map[string]bool, len(requested))
+	slugs := make([]string, 0, len(requested))
+	for _, slug := range requested {
+		if !chains.IsValidChain(slug) {
+			return nil, fmt.Errorf("Invalid chain ID: %s", slug)
+		}
+		if !seen[slug] {
+			seen[slug] = true
+			slugs = append(slugs, slug)
+		}
+	}
+	return slugs, nil
+}
+
+// orderChainsBySize sorts chains by the entries of their last completed run,
+// smallest first, so quick chains finish early; chains of unknown size go
+// last, by slug
+func orderChainsBySize(slugs []string, sizes map[string]int) {
+	sort.SliceStable(slugs, func(i, j int) bool {
+		si, iKnown := sizes[slugs[i]]
+		sj, jKnown := sizes[slugs[j]]
+		switch {
+		case iKnown && jKnown && si != sj:
+			return si < sj
+		case iKnown != jKnown:
+			return iKnown
+		default:
+			return slugs[i] < slugs[j]
+		}
+	})
+}
+
+// runIngestionBatch starts the runs of a batch in order, keeping at most the
+// batch's concurrency running, and marks the batch completed when all finish
+func runIngestionBatch(ctx context.Context, batch *database.IngestionBatch, targetDate string) {
+	slots := make(chan struct{}, batch.Concurrency)
+	var wg sync.WaitGroup
+	for _, run := range batch.Runs {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			runChainIngestion(ctx, run.RunID, run.ChainSlug, targetDate)
+		}()
+	}
+	wg.Wait()
+
+	if err := database.CompleteIngestionBatch(ctx, batch.ID); err != nil {
+		logger.Error().Err(err).Int64("batchID", batch.ID).Msg("Failed to mark batch as completed")
+	}
+	logger.Info().Int64("batchID", batch.ID).Int("runs", len(batch.Runs)).Msg("Ingestion batch finished")
+}
+
+// toIngestionBatchResponse aggregates the runs of a batch
+func toIngestionBatchResponse(batch *database.IngestionBatch) IngestionBatchResponse {
+	resp := IngestionBatchResponse{
+		BatchID:     strconv.FormatInt(batch.ID, 10),
+		Concurrency: batch.Concurrency,
+		TargetDate:  batch.TargetDate,
+		CreatedAt:   batch.CreatedAt,
+		CompletedAt: batch.CompletedAt,
+		Runs:        make([]IngestionBatchRunInfo, 0, len(batch.Runs)),
+	}
+
+	p := &resp.Progress
+	p.Total = len(batch.Runs)
+	for _, run := range batch.Runs {
+		switch run.Status {
+		case "pending":
+			p.Pending++
+		case "running":
+			p.Running++
+		case "completed":
+			p.Completed++
+		default:
+			p.Failed++
+		}
+		p.FilesProcessed += run.ProcessedFiles
+		p.EntriesPersisted += run.ProcessedEntries
+
+		resp.Runs = append(resp.Runs, IngestionBatchRunInfo{
+			RunID:            strconv.FormatInt(run.RunID, 10),
+			ChainSlug:        run.ChainSlug,
+			Status:           run.Status,
+			StartedAt:        run.StartedAt,
+			CompletedAt:      run.CompletedAt,
+			FilesProcessed:   run.ProcessedFiles,
+			EntriesPersisted: run.ProcessedEntries,
+		})
+	}
+	if p.Total > 0 {
+		p.Percent = float64(p.Completed+p.Failed) / float64(p.Total) * 100
+	}
+
+	switch {
+	case p.Pending+p.Running > 0:
+		resp.Status = "running"
+	case p.Failed == 0:
+		resp.Status = "completed"
+	case p.Completed == 0:
+		resp.Status = "failed"
+	default:
+		resp.Status = "partial"
+	}
+	return resp
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderChainsBySize(t *testing.T) {
+	slugs := []string{"konzum", "metro", "dm", "lidl", "ktc"}
+	orderChainsBySize(slugs, map[string]int{"konzum": 900000, "dm": 12000, "lidl": 40000})
+	assert.Equal(t, []string{"dm", "lidl", "konzum", "ktc", "metro"}, slugs)
+}
+
+func TestToIngestionBatchResponse(t *testing.T) {
+	batch := &database.IngestionBatch{ID: 7, Concurrency: 2, Runs: []database.IngestionBatchRun{
+		{RunID: 1, ChainSlug: "dm", Status: "completed", ProcessedFiles: 3, ProcessedEntries: 1200},
+		{RunID: 2, ChainSlug: "lidl", Status: "failed", ProcessedFiles: 1},
+		{RunID: 3, ChainSlug: "konzum", Status: "running"},
+		{RunID: 4, ChainSlug: "ktc", Status: "pending"},
+	}}
+
+	resp := toIngestionBatchResponse(batch)
+	assert.Equal(t, "running", resp.Status)
+	assert.Equal(t, IngestionBatchProgress{
+		Total: 4, Pending: 1, Running: 1, Completed: 1, Failed: 1,
+		Percent: 50, FilesProcessed: 4, EntriesPersisted: 1200,
+	}, resp.Progress)
+	assert.Equal(t, "3", resp.Runs[2].RunID)
+
+	batch.Runs[2].Status = "completed"
+	batch.Runs[3].Status = "interrupted"
+	assert.Equal(t, "partial", toIngestionBatchResponse(batch).Status)
+
+	batch.Runs = batch.Runs[:1]
+	assert.Equal(t, "completed", toIngestionBatchResponse(batch).Status)
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kosarica/price-service/internal/database"
+)
+
+// maxResolveItems bounds the item IDs plus external IDs of one resolve request
+const maxResolveItems = 1000
+
+// ItemExternalRef identifies a retailer item by its chain's own ID
+type ItemExternalRef struct {
+	ChainSlug  string `json:"chainSlug" binding:"required" jsonschema:"required"`
+	ExternalID string `json:"externalId" binding:"required" jsonschema:"required"`
+}
+
+// ResolveItemsRequest represents the request body for resolving retailer
+// items to their products
+type ResolveItemsRequest struct {
+	ItemIDs     []string          `json:"itemIds"`
+	ExternalIDs []ItemExternalRef `json:"externalIds" binding:"dive"`
+}
+
+// ResolvedItem is a retailer item with the product it is linked to
+type ResolvedItem struct {
+	RetailerItemID string   `json:"retailerItemId" jsonschema:"required"`
+	ChainSlug      string   `json:"chainSlug" jsonschema:"required"`
+	ExternalID     *string  `json:"externalId"`
+	ProductID      *string  `json:"productId"` // Null when the item is not linked to a product
+	ProductName    *string  `json:"productName"`
+	Barcodes       []string `json:"barcodes" jsonschema:"required"` // The product's canonical barcodes
+}
+
+// ResolveItemsResponse represents the resolved retailer items and the
+// requested ones that do not exist
+type ResolveItemsResponse struct {
+	Items              []ResolvedItem    `json:"items" jsonschema:"required"`
+	MissingItemIDs     []string          `json:"missingItemIds" jsonschema:"required"`
+	MissingExternalIDs []ItemExternalRef `json:"missingExternalIds" jsonschema:"required"`
+}
+
+// ResolveItems resolves retailer items to their linked products in bulk
+// @Summary Resolve items to products
+// @Description Returns the product linked to each retailer item, by item ID or by chain and external ID, with the product's name and canonical barcodes, so other services read product links here instead of keeping their own copy. Items not linked to a product are returned without one; items that do not exist are listed as missing. At most 1000 item and external IDs per request.
+// @Tags items
+// @Accept json
+// @Produce json
+// @Param request body ResolveItemsRequest true "Items to resolve"
+// @Success 200 {object} ResolveItemsResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/items/resolve [post]
+func ResolveItems(c *gin.Context) {
+	var req ResolveItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	count := len(req.ItemIDs) + len(req.ExternalIDs)
+	if count == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "itemIds or externalIds is required"})
+		return
+	}
+	if count > maxResolveItems {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At most 1000 itemIds and externalIds may be resolved at once"})
+		return
+	}
+
+	chainSlugs := make([]string, len(req.ExternalIDs))
+	externalIDs := make([]string, len(req.ExternalIDs))
+	for i, ref := range req.ExternalIDs {
+		chainSlugs[i] = ref.ChainSlug
+		externalIDs[i] = ref.ExternalID
+	}
+
+	rows, err := database.Pool().Query(c.Request.Context(), `
+		WITH wanted AS (
+			SELECT ri.id, ri.chain_slug, ri.external_id
+			FROM retailer_items ri
+			WHERE ri.id = ANY($1)
+			UNION
+			SELECT ri.id, ri.chain_slug, ri.external_id
+			FROM unnest($2::text[], $3::text[]) AS ref(chain_slug, external_id)
+			JOIN retailer_items ri ON ri.chain_slug = ref.chain_slug AND ri.external_id = ref.external_id
+		)
+		SELECT w.id, w.chain_slug, w.external_id, p.id, p.name,
+		       COALESCE(
+		           (SELECT array_agg(cb.barcode ORDER BY cb.barcode)
+		            FROM canonical_barcodes cb
+		            WHERE cb.product_id = p.id),
+		           '{}'
+		       )
+		FROM wanted w
+		LEFT JOIN product_links pl ON pl.retailer_item_id = w.id
+		LEFT JOIN products p ON p.id = pl.product_id
+		ORDER BY w.id
+	`, req.ItemIDs, chainSlugs, externalIDs)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to resolve items")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve items"})
+		return
+	}
+	defer rows.Close()
+
+	items := []ResolvedItem{}
+	for rows.Next() {
+		var item ResolvedItem
+		if err := rows.Scan(&item.RetailerItemID, &item.ChainSlug, &item.ExternalID, &item.ProductID, &item.ProductName, &item.Barcodes); err != nil {
+			logger.Error().Err(err).Msg("Failed to scan resolved item")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan resolved item"})
+			return
+		}
+		items = append(items, item)
+	}
+	if rows.Err() != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error iterating resolved items"})
+		return
+	}
+
+	missingItemIDs, missingExternalIDs := missingResolvedItems(req, items)
+	c.JSON(http.StatusOK, ResolveItemsResponse{
+		Items:              items,
+		MissingItemIDs:     missingItemIDs,
+		MissingExternalIDs: missingExternalIDs,
+	})
+}
+
+// missingResolvedItems returns the requested item and external IDs that no
+// resolved item has, each once
+func missingResolvedItems(req ResolveItemsRequest, items []ResolvedItem) ([]string, []ItemExternalRef) {
+	ids := make(map[string]bool, len(items))
+	refs := make(map[ItemExternalRef]bool, len(items))
+	for _, item := range items {
+		ids[item.RetailerItemID] = true
+		if item.ExternalID != nil {
+			refs[ItemExternalRef{ChainSlug: item.ChainSlug, ExternalID: *item.ExternalID}] = true
+		}
+	}
+
+	missingItemIDs := []string{}
+	for _, id := range req.ItemIDs {
+		if !ids[id] {
+			missingItemIDs = append(missingItemIDs, id)
+			ids[id] = true
+		}
+	}
+	missingExternalIDs := []ItemExternalRef{}
+	for _, ref := range req.ExternalIDs {
+		if !refs[ref] {
+			missingExternalIDs = append(missingExternalIDs, ref)
+			refs[ref] = true
+		}
+	}
+	return missingItemIDs, missingExternalIDs
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMissingResolvedItems(t *testing.T) {
+	externalID := "12345"
+	req := ResolveItemsRequest{
+		ItemIDs: []string{"rit_a", "rit_gone", "rit_gone"},
+		ExternalIDs: []ItemExternalRef{
+			{ChainSlug: "konzum", ExternalID: "12345"},
+			{ChainSlug: "lidl", ExternalID: "12345"},
+		},
+	}
+	items := []ResolvedItem{
+		{RetailerItemID: "rit_a", ChainSlug: "spar"},
+		{RetailerItemID: "rit_b", ChainSlug: "konzum", ExternalID: &externalID},
+	}
+
+	missingItemIDs, missingExternalIDs := missingResolvedItems(req, items)
+
+	assert.Equal(t, []string{"rit_gone"}, missingItemIDs)
+	assert.Equal(t, []ItemExternalRef{{ChainSlug: "lidl", ExternalID: "12345"}}, missingExternalIDs)
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/optimizer"
+	"github.com/kosarica/price-service/internal/pkg/i18n"
+)
+
+// Staleness is attached to optimize responses served from a cache snapshot
+// older than the cache TTL or while the cache is degraded
+type Staleness struct {
+	LoadedAt   time.Time `json:"loadedAt" jsonschema:"required" proto:"1"`
+	AgeSeconds int64     `json:"ageSeconds" jsonschema:"required" proto:"2"`
+	Stale      bool      `json:"stale" jsonschema:"required" proto:"3"`
+	Degraded   string    `json:"degraded,omitempty" jsonschema:"enum=queue_depth,enum=circuit_open,enum=db_saturated" proto:"4"`
+}
+
+// loadShedder applies the load shedding policy to optimize requests
+var loadShedder = optimizer.NewLoadShedder(optimizer.DefaultLoadShedConfig(), optimizeHealthSignals)
+
+// optimizeHealthSignals reads the cache circuit breaker and the DB pool
+func optimizeHealthSignals() optimizer.HealthSignals {
+	var signals optimizer.HealthSignals
+	if priceCache != nil {
+		signals.CircuitOpen = priceCache.GetCircuitBreakerState() != optimizer.CircuitClosed
+	}
+	if stats := database.Stats(); stats != nil && stats.MaxConns() > 0 {
+		signals.DBSaturated = stats.AcquiredConns() >= stats.MaxConns()
+	}
+	return signals
+}
+
+// warmupRetryAfter is the Retry-After of requests rejected during warmup
+const warmupRetryAfter = 5 * time.Second
+
+// admitOptimize applies the warmup policy and load shedding to an optimize
+// request. It returns a RequestError when the request must not be served;
+// otherwise the caller must release the admission when done. The returned
+// staleness is nil when the snapshots of the chains are fresh and healthy.
+func admitOptimize(ctx context.Context, chainSlugs ...string) (*optimizer.Admission, *Staleness, error) {
+	if priceCache == nil {
+		return nil, nil, localizedError(ctx, http.StatusServiceUnavailable, "cache.not_initialized", nil)
+	}
+
+	// Chains warmup has loaded are served; the others wait for warmup or are
+	// rejected, as configured. Held requests take no load shedding slot.
+	if !anyChainLoaded(chainSlugs) {
+		err := priceCache.AwaitWarmup(ctx)
+		var warmingUp optimizer.ErrWarmingUp
+		if errors.As(err, &warmingUp) {
+			reqErr := localizedError(ctx, http.StatusServiceUnavailable, "cache.warming_up", i18n.Args{
+				"loaded": warmingUp.Progress.ChainsLoaded,
+				"chains": warmingUp.Progress.Chains,
+			})
+			reqErr.RetryAfter = warmupRetryAfter
+			return nil, nil, reqErr
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	admission, err := loadShedder.Admit()
+	if err != nil {
+		reqErr := localizedError(ctx, http.StatusServiceUnavailable, "optimize.overloaded", nil)
+		reqErr.RetryAfter = loadShedder.RetryAfter()
+		return nil, nil, reqErr
+	}
+
+	// Serve from any loaded snapshot, however old; only refuse when there is
+	// nothing to serve from. Across chains the oldest snapshot is reported.
+	var freshness optimizer.CacheFreshness
+	loaded := false
+	for _, chainSlug := range chainSlugs {
+		f, ok := priceCache.ChainFreshness(chainSlug)
+		if ok && (!loaded || f.LoadedAt < freshness.LoadedAt) {
+			freshness = f
+			loaded = true
+		}
+	}
+	if !loaded && !priceCache.IsHealthy(ctx) {
+		admission.Release()
+		reqErr := localizedError(ctx, http.StatusServiceUnavailable, "cache.unavailable", nil)
+		reqErr.RetryAfter = loadShedder.RetryAfter()
+		return nil, nil, reqErr
+	}
+
+	var staleness *Staleness
+	if loaded && (freshness.IsStale || admission.Reason != "") {
+		staleness = newStaleness(freshness, admission.Reason)
+		if freshness.IsStale {
+			optimizer.NewMetricsRecorder().RecordLoadShed("stale", "ttl_expired")
+		}
+	}
+
+	return admission, staleness, nil
+}
+
+// anyChainLoaded reports whether the cache holds a snapshot of any of the
+// chains
+func anyChainLoaded(chainSlugs []string) bool {
+	for _, chainSlug := range chainSlugs {
+		if _, ok := priceCache.ChainFreshness(chainSlug); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// newStaleness describes a snapshot's freshness, degraded for a reason or not
+func newStaleness(freshness optimizer.CacheFreshness, degraded string) *Staleness {
+	loadedAt := time.Unix(freshness.LoadedAt, 0).UTC()
+	return &Staleness{
+		LoadedAt:   loadedAt,
+		AgeSeconds: int64(time.Since(loadedAt).Seconds()),
+		Stale:      freshness.IsStale,
+		Degraded:   degraded,
+	}
+}
+
+// setRetryAfter sets the Retry-After header in whole seconds
+func setRetryAfter(c *gin.Context, d time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(d.Seconds()))))
+}
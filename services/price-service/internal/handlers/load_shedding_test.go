@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/kosarica/price-service/internal/optimizer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOptimizeDuringWarmup tests that optimize requests for chains not loaded
+// yet are rejected with the warmup's progress until it completes.
+func TestOptimizeDuringWarmup(t *testing.T) {
+	previous := priceCache
+	t.Cleanup(func() { priceCache = previous })
+	priceCache = optimizer.NewPriceCache(nil, optimizer.DefaultOptimizerConfig())
+
+	req := &OptimizeRequest{
+		ChainSlug:   "test-chain",
+		BasketItems: []*BasketItem{{ItemID: "rit-aaa-111", Name: "Item A", Quantity: 1}},
+	}
+	_, err := ServeOptimizeSingle(context.Background(), req, false)
+
+	var reqErr *RequestError
+	require.ErrorAs(t, err, &reqErr)
+	assert.Equal(t, http.StatusServiceUnavailable, reqErr.Status)
+	assert.Equal(t, "Cache warming up, 0 of 0 chains loaded", reqErr.Message)
+	assert.Equal(t, warmupRetryAfter, reqErr.RetryAfter)
+	assert.EqualValues(t, 1, priceCache.WarmupProgress().Rejected)
+}
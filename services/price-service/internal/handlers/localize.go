@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/kosarica/price-service/internal/pkg/i18n"
+)
+
+// requestContext returns the context of a request carrying the language of
+// its Accept-Language header, and tells the client that language
+func requestContext(c *gin.Context) context.Context {
+	tag := i18n.Negotiate(c.GetHeader("Accept-Language"))
+	c.Header("Content-Language", tag.String())
+	c.Writer.Header().Add("Vary", "Accept-Language")
+	return i18n.WithLanguage(c.Request.Context(), tag)
+}
+
+// localizedError returns a RequestError with a catalog message in the
+// language of the request
+func localizedError(ctx context.Context, status int, key string, args i18n.Args) *RequestError {
+	return &RequestError{Status: status, Message: i18n.T(ctx, key, args), Code: key}
+}
+
+// bindError returns the localized RequestError of a request body that failed
+// to bind: the first invalid field, or a malformed body
+func bindError(ctx context.Context, err error) *RequestError {
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) || len(invalid) == 0 {
+		return localizedError(ctx, http.StatusBadRequest, "validation.malformed", nil)
+	}
+
+	fe := invalid[0]
+	key := "validation." + fe.Tag()
+	switch fe.Tag() {
+	case "required", "min", "max", "oneof":
+	default:
+		key = "validation.invalid"
+	}
+	return localizedError(ctx, http.StatusBadRequest, key, i18n.Args{
+		"field": jsonFieldPath(fe.Namespace()),
+		"param": strings.ReplaceAll(fe.Param(), " ", ", "),
+	})
+}
+
+// jsonFieldPath turns the namespace of a struct field, e.g.
+// "OptimizeRequest.BasketItems[0].Quantity", into its JSON path,
+// "basketItems[0].quantity". Request fields are named as their JSON keys
+// in camel case.
+func jsonFieldPath(namespace string) string {
+	parts := strings.Split(namespace, ".")
+	if len(parts) > 1 {
+		parts = parts[1:] // The request type
+	}
+	for i, part := range parts {
+		if r := []rune(part); len(r) > 0 {
+			r[0] = unicode.ToLower(r[0])
+			parts[i] = string(r)
+		}
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOptimizeErrorsLocalized tests that optimize errors are answered in the
+// language of Accept-Language, with the catalog key as their code.
+func TestOptimizeErrorsLocalized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/optimize/single", OptimizeSingle)
+	router.POST("/optimize/multi", OptimizeMulti)
+
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		acceptLanguage string
+		language       string
+		code           string
+		message        string
+	}{
+		{
+			name:     "validation in English",
+			path:     "/optimize/single",
+			body:     `{"chainSlug": "konzum"}`,
+			language: "en",
+			code:     "validation.required",
+			message:  "basketItems is required",
+		},
+		{
+			name:           "validation in Croatian",
+			path:           "/optimize/single",
+			body:           `{"chainSlug": "konzum", "basketItems": [{"itemId": "a", "name": "A", "quantity": 1}], "limit": 60}`,
+			acceptLanguage: "hr-HR,hr;q=0.9,en;q=0.8",
+			language:       "hr",
+			code:           "validation.max",
+			message:        "limit smije biti najviše 50",
+		},
+		{
+			name:           "enum in Croatian",
+			path:           "/optimize/single",
+			body:           `{"chainSlug": "konzum", "basketItems": [{"itemId": "a", "name": "A", "quantity": 1}], "sortBy": "name"}`,
+			acceptLanguage: "hr",
+			language:       "hr",
+			code:           "validation.oneof",
+			message:        "sortBy mora biti jedno od: price, distance, balanced",
+		},
+		{
+			name:           "malformed body",
+			path:           "/optimize/multi",
+			body:           `{"chainSlug": `,
+			acceptLanguage: "hr",
+			language:       "hr",
+			code:           "validation.malformed",
+			message:        "Tijelo zahtjeva nije ispravan JSON",
+		},
+		{
+			name:           "request check in Croatian",
+			path:           "/optimize/multi",
+			body:           `{"chainSlug": "konzum", "chainSlugs": ["lidl"], "basketItems": [{"itemId": "a", "name": "A", "quantity": 1}]}`,
+			acceptLanguage: "hr",
+			language:       "hr",
+			code:           "optimize.one_chain_source",
+			message:        "potreban je točno jedan od chainSlug i chainSlugs",
+		},
+		{
+			name:           "unsupported language",
+			path:           "/optimize/multi",
+			body:           `{"chainSlugs": ["nope"], "basketItems": [{"itemId": "a", "name": "A", "quantity": 1}]}`,
+			acceptLanguage: "de-DE",
+			language:       "en",
+			code:           "optimize.chain_invalid",
+			message:        "invalid chain: nope",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Equal(t, tt.language, w.Header().Get("Content-Language"))
+			var body map[string]string
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.code, body["code"])
+			assert.Equal(t, tt.message, body["error"])
+		})
+	}
+}
+
+func TestJSONFieldPath(t *testing.T) {
+	assert.Equal(t, "chainSlug", jsonFieldPath("OptimizeRequest.ChainSlug"))
+	assert.Equal(t, "basketItems[0].quantity", jsonFieldPath("OptimizeRequest.BasketItems[0].Quantity"))
+	assert.Equal(t, "location.latitude", jsonFieldPath("OptimizeRequest.Location.Latitude"))
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kosarica/price-service/internal/logging"
+)
+
+// logger is shared by the handlers
+var logger = logging.For(logging.Handlers)
+
+// LogLevelsResponse reports the default log level and each component's
+// effective level
+type LogLevelsResponse struct {
+	Default    string            `json:"default" jsonschema:"required"`
+	Components map[string]string `json:"components" jsonschema:"required"`
+}
+
+// SetLogLevelsRequest changes log levels. An omitted default keeps the
+// current one; a component set to "" goes back to the default.
+type SetLogLevelsRequest struct {
+	Default    string            `json:"default"`
+	Components map[string]string `json:"components"`
+}
+
+// GetLogLevels returns the current log levels
+// @Summary Get log levels
+// @Description Returns the default log level and the effective level of each component (cache, optimizer, pipeline, handlers, adapters)
+// @Tags config
+// @Accept json
+// @Produce json
+// @Success 200 {object} LogLevelsResponse
+// @Router /internal/admin/config/logging [get]
+func GetLogLevels(c *gin.Context) {
+	c.JSON(http.StatusOK, currentLogLevels())
+}
+
+// SetLogLevels changes log levels at runtime
+// @Summary Set log levels
+// @Description Changes the default log level and per-component overrides without a restart. Changes are not persisted and reset to the configured levels on restart.
+// @Tags config
+// @Accept json
+// @Produce json
+// @Param request body SetLogLevelsRequest true "Log levels"
+// @Success 200 {object} LogLevelsResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Router /internal/admin/config/logging [put]
+func SetLogLevels(c *gin.Context) {
+	var req SetLogLevelsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := logging.Update(req.Default, req.Components); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, currentLogLevels())
+}
+
+func currentLogLevels() LogLevelsResponse {
+	defaultLevel, components := logging.Levels()
+	return LogLevelsResponse{Default: defaultLevel, Components: components}
+}
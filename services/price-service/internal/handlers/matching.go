@@ -89,6 +89,7 @@ type AIMatchingResponse struct {
 	QueuedForReview int    `json:"queuedForReview"`
 	NoMatch         int    `json:"noMatch"`
 	CacheHits       int    `json:"cacheHits"`
+	Refreshed       int    `json:"refreshed"`
 }
 
 // TriggerAIMatching triggers AI-based product matching
@@ -121,6 +122,8 @@ func (h *MatchingHandler) TriggerAIMatching(c *gin.Context) {
 		BatchSize:         req.BatchSize,
 		MaxCandidates:     5,
 		TrgmPrefilter:     200,
+		RefreshLimit:      500,
+		RejectionPenalty:  0.05,
 	}
 
 	ctx := c.Request.Context()
@@ -141,6 +144,7 @@ func (h *MatchingHandler) TriggerAIMatching(c *gin.Context) {
 		QueuedForReview: result.QueuedForReview,
 		NoMatch:         result.NoMatch,
 		CacheHits:       result.CacheHits,
+		Refreshed:       result.Refreshed,
 	})
 }
 
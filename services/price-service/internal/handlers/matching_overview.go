@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/kosarica/price-service/internal/database"
+)
+
+// maxConflictingBarcodes caps the conflicting barcodes in the overview, most
+// items first
+const maxConflictingBarcodes = 10
+
+// MatchingOverviewRequest represents query parameters for the matching overview
+type MatchingOverviewRequest struct {
+	Days int `form:"days" json:"days" binding:"omitempty,min=1,max=365" jsonschema:"minimum=1,maximum=365"`
+}
+
+// UnlinkedChainCount counts a chain's retailer items not linked to a product
+type UnlinkedChainCount struct {
+	ChainSlug string `json:"chainSlug" jsonschema:"required"`
+	Unlinked  int    `json:"unlinked" jsonschema:"required"`
+	Total     int    `json:"total" jsonschema:"required"`
+}
+
+// QueueStatusCount counts review queue entries with a status
+type QueueStatusCount struct {
+	Status string `json:"status" jsonschema:"required"`
+	Count  int    `json:"count" jsonschema:"required"`
+}
+
+// AutoLinkDay is the share of a day's matching decisions that linked items
+// automatically instead of queueing them for review
+type AutoLinkDay struct {
+	Date       string  `json:"date" jsonschema:"required"`
+	AutoLinked int     `json:"autoLinked" jsonschema:"required"`
+	Queued     int     `json:"queued" jsonschema:"required"`
+	Rate       float64 `json:"rate" jsonschema:"required"`
+}
+
+// ConflictingBarcode is a barcode whose items were flagged as suspicious by
+// barcode matching, e.g. for differing names, brands or units
+type ConflictingBarcode struct {
+	Barcode string   `json:"barcode" jsonschema:"required"`
+	Items   int      `json:"items" jsonschema:"required"`
+	Chains  int      `json:"chains" jsonschema:"required"`
+	Flags   []string `json:"flags" jsonschema:"required"`
+}
+
+// MatchingOverviewResponse represents the matching dashboard statistics
+type MatchingOverviewResponse struct {
+	UnlinkedByChain     []UnlinkedChainCount `json:"unlinkedByChain" jsonschema:"required"`
+	Queue               []QueueStatusCount   `json:"queue" jsonschema:"required"`
+	AutoLinkRate        []AutoLinkDay        `json:"autoLinkRate" jsonschema:"required"`
+	ConflictingBarcodes []ConflictingBarcode `json:"conflictingBarcodes" jsonschema:"required"`
+	GeneratedAt         time.Time            `json:"generatedAt" jsonschema:"required"`
+}
+
+// GetMatchingOverview returns the statistics of the matching review dashboard
+// @Summary Matching overview
+// @Description Summarizes product matching for the review dashboard in one call: unlinked retailer items per chain, review queue depth by status, the daily auto-link rate (automatic links over automatic links plus items queued for review) and the barcodes with the most items flagged as suspicious. All statistics are read in a single database round trip.
+// @Tags matching
+// @Accept json
+// @Produce json
+// @Param days query int false "Days of auto-link rate to return, ending today" default(30) minimum(1) maximum(365)
+// @Success 200 {object} MatchingOverviewResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/matching/overview [get]
+func GetMatchingOverview(c *gin.Context) {
+	var req MatchingOverviewRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Set default window
+	if req.Days == 0 {
+		req.Days = 30
+	}
+
+	batch := &pgx.Batch{}
+	batch.Queue(`
+		SELECT ri.chain_slug,
+		       COUNT(*) FILTER (WHERE pl.retailer_item_id IS NULL),
+		       COUNT(*)
+		FROM retailer_items ri
+		LEFT JOIN product_links pl ON pl.retailer_item_id = ri.id
+		GROUP BY ri.chain_slug
+		ORDER BY 2 DESC, ri.chain_slug
+	`)
+	batch.Queue(`
+		SELECT COALESCE(status, 'pending'), COUNT(*)
+		FROM product_match_queue
+		GROUP BY 1
+		ORDER BY 1
+	`)
+	batch.Queue(`
+		WITH days AS (
+			SELECT generate_series(CURRENT_DATE - ($1::int - 1), CURRENT_DATE, interval '1 day')::date AS day
+		),
+		linked AS (
+			SELECT created_at::date AS day, COUNT(*) AS n
+			FROM product_links
+			WHERE match_type IN ('barcode', 'ai')
+			  AND created_at >= CURRENT_DATE - ($1::int - 1)
+			GROUP BY 1
+		),
+		queued AS (
+			SELECT created_at::date AS day, COUNT(*) AS n
+			FROM product_match_queue
+			WHERE created_at >= CURRENT_DATE - ($1::int - 1)
+			GROUP BY 1
+		)
+		SELECT to_char(d.day, 'YYYY-MM-DD'), COALESCE(l.n, 0), COALESCE(q.n, 0)
+		FROM days d
+		LEFT JOIN linked l ON l.day = d.day
+		LEFT JOIN queued q ON q.day = d.day
+		ORDER BY d.day
+	`, req.Days)
+	batch.Queue(`
+		SELECT rib.barcode,
+		       COUNT(DISTINCT rib.retailer_item_id),
+		       COUNT(DISTINCT ri.chain_slug),
+		       array_agg(DISTINCT pmc.flags ORDER BY pmc.flags)
+		FROM product_match_candidates pmc
+		JOIN retailer_item_barcodes rib ON rib.retailer_item_id = pmc.retailer_item_id
+		JOIN retailer_items ri ON ri.id = pmc.retailer_item_id
+		WHERE pmc.flags LIKE 'suspicious_barcode%'
+		GROUP BY rib.barcode
+		ORDER BY 2 DESC, rib.barcode
+		LIMIT $1
+	`, maxConflictingBarcodes)
+
+	results := database.Pool().SendBatch(c.Request.Context(), batch)
+	defer results.Close()
+
+	resp := MatchingOverviewResponse{
+		UnlinkedByChain:     []UnlinkedChainCount{},
+		Queue:               []QueueStatusCount{},
+		AutoLinkRate:        []AutoLinkDay{},
+		ConflictingBarcodes: []ConflictingBarcode{},
+		GeneratedAt:         time.Now(),
+	}
+
+	rows, err := results.Query()
+	if err == nil {
+		resp.UnlinkedByChain, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (UnlinkedChainCount, error) {
+			var u UnlinkedChainCount
+			err := row.Scan(&u.ChainSlug, &u.Unlinked, &u.Total)
+			return u, err
+		})
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count unlinked items"})
+		return
+	}
+
+	rows, err = results.Query()
+	if err == nil {
+		resp.Queue, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (QueueStatusCount, error) {
+			var q QueueStatusCount
+			err := row.Scan(&q.Status, &q.Count)
+			return q, err
+		})
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count review queue"})
+		return
+	}
+
+	rows, err = results.Query()
+	if err == nil {
+		resp.AutoLinkRate, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (AutoLinkDay, error) {
+			var d AutoLinkDay
+			err := row.Scan(&d.Date, &d.AutoLinked, &d.Queued)
+			d.Rate = autoLinkRate(d.AutoLinked, d.Queued)
+			return d, err
+		})
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute auto-link rate"})
+		return
+	}
+
+	rows, err = results.Query()
+	if err == nil {
+		resp.ConflictingBarcodes, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (ConflictingBarcode, error) {
+			var b ConflictingBarcode
+			err := row.Scan(&b.Barcode, &b.Items, &b.Chains, &b.Flags)
+			return b, err
+		})
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list conflicting barcodes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// autoLinkRate is the share of decisions that linked automatically, 0 on a
+// day without decisions
+func autoLinkRate(autoLinked, queued int) float64 {
+	if autoLinked+queued == 0 {
+		return 0
+	}
+	return float64(autoLinked) / float64(autoLinked+queued)
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kosarica/price-service/internal/database"
+)
+
+func serveMatchingOverview(query string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/internal/matching/overview", GetMatchingOverview)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/internal/matching/overview"+query, nil))
+	return w
+}
+
+func TestAutoLinkRate(t *testing.T) {
+	assert.Zero(t, autoLinkRate(0, 0))
+	assert.Equal(t, 1.0, autoLinkRate(4, 0))
+	assert.Zero(t, autoLinkRate(0, 3))
+	assert.Equal(t, 0.25, autoLinkRate(1, 3))
+}
+
+func TestGetMatchingOverviewValidation(t *testing.T) {
+	for _, query := range []string{"?days=0x", "?days=-1", "?days=366"} {
+		w := serveMatchingOverview(query)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+// TestGetMatchingOverview reads every statistic of the overview from one
+// database, and fails with the statistic that could not be read
+func TestGetMatchingOverview(t *testing.T) {
+	ctx := context.Background()
+
+	container, db, cleanup := setupHandlersTestDB(t)
+	defer cleanup()
+	connStr, err := container.ConnectionString(ctx, "sslmode=disable")
+	require.NoError(t, err)
+	require.NoError(t, database.Connect(ctx, connStr, 4, 1, 0, 0))
+	defer database.Close()
+
+	_, err = db.Exec(ctx, `
+		INSERT INTO chains (slug, name) VALUES ('konzum', 'Konzum'), ('lidl', 'Lidl');
+		INSERT INTO retailer_items (id, chain_slug, name) VALUES
+			('rit1', 'konzum', 'Mlijeko'), ('rit2', 'konzum', 'Mlijeko 1L'), ('rit3', 'konzum', 'Jogurt'),
+			('rit4', 'lidl', 'Mlijeko'), ('rit5', 'lidl', 'Kruh');
+
+		CREATE TABLE product_links (
+			id TEXT PRIMARY KEY,
+			product_id TEXT NOT NULL,
+			retailer_item_id TEXT NOT NULL UNIQUE,
+			match_type TEXT,
+			created_at TIMESTAMPTZ DEFAULT NOW()
+		);
+		INSERT INTO product_links (id, product_id, retailer_item_id, match_type, created_at) VALUES
+			('pl1', 'p1', 'rit1', 'barcode', NOW()),
+			('pl2', 'p1', 'rit2', 'ai', NOW() - interval '1 day');
+
+		CREATE TABLE retailer_item_barcodes (
+			id TEXT PRIMARY KEY,
+			retailer_item_id TEXT NOT NULL,
+			barcode TEXT NOT NULL
+		);
+		INSERT INTO retailer_item_barcodes (id, retailer_item_id, barcode) VALUES
+			('rib1', 'rit1', '385'), ('rib2', 'rit2', '385'), ('rib3', 'rit3', '999'), ('rib4', 'rit4', '385');
+
+		CREATE TABLE product_match_candidates (
+			id TEXT PRIMARY KEY,
+			retailer_item_id TEXT NOT NULL,
+			candidate_product_id TEXT,
+			match_type TEXT NOT NULL,
+			flags TEXT
+		);
+		INSERT INTO product_match_candidates (id, retailer_item_id, candidate_product_id, match_type, flags) VALUES
+			('pmc1', 'rit1', 'p1', 'barcode', 'suspicious_barcode:name'),
+			('pmc2', 'rit2', 'p1', 'barcode', 'suspicious_barcode:brand'),
+			('pmc3', 'rit3', 'p2', 'barcode', 'suspicious_barcode:unit'),
+			('pmc4', 'rit4', 'p1', 'ai', NULL);
+	`)
+	require.NoError(t, err)
+
+	// The review queue is read second, after unlinked items
+	w := serveMatchingOverview("")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	var failure map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &failure))
+	assert.Equal(t, "Failed to count review queue", failure["error"])
+
+	_, err = db.Exec(ctx, `
+		CREATE TABLE product_match_queue (
+			id TEXT PRIMARY KEY,
+			retailer_item_id TEXT NOT NULL UNIQUE,
+			status TEXT,
+			created_at TIMESTAMPTZ DEFAULT NOW()
+		);
+		INSERT INTO product_match_queue (id, retailer_item_id, status, created_at) VALUES
+			('pmq1', 'rit3', NULL, NOW()),
+			('pmq2', 'rit4', 'pending', NOW()),
+			('pmq3', 'rit5', 'approved', NOW() - interval '5 days');
+	`)
+	require.NoError(t, err)
+
+	w = serveMatchingOverview("?days=3")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp MatchingOverviewResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+	assert.Equal(t, []UnlinkedChainCount{
+		{ChainSlug: "lidl", Unlinked: 2, Total: 2},
+		{ChainSlug: "konzum", Unlinked: 1, Total: 3},
+	}, resp.UnlinkedByChain)
+
+	assert.Equal(t, []QueueStatusCount{
+		{Status: "approved", Count: 1},
+		{Status: "pending", Count: 2},
+	}, resp.Queue)
+
+	today := time.Now().UTC()
+	assert.Equal(t, []AutoLinkDay{
+		{Date: today.AddDate(0, 0, -2).Format("2006-01-02")},
+		{Date: today.AddDate(0, 0, -1).Format("2006-01-02"), AutoLinked: 1, Rate: 1},
+		{Date: today.Format("2006-01-02"), AutoLinked: 1, Queued: 2, Rate: 1.0 / 3},
+	}, resp.AutoLinkRate)
+
+	assert.Equal(t, []ConflictingBarcode{
+		{Barcode: "385", Items: 2, Chains: 1, Flags: []string{"suspicious_barcode:brand", "suspicious_barcode:name"}},
+		{Barcode: "999", Items: 1, Chains: 1, Flags: []string{"suspicious_barcode:unit"}},
+	}, resp.ConflictingBarcodes)
+}
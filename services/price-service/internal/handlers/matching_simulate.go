@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/matching"
+)
+
+// SimulateMatchingRequest represents the request to simulate AI matching
+type SimulateMatchingRequest struct {
+	ItemIDs           []string `json:"itemIds" binding:"omitempty,max=500" jsonschema:"maxItems=500"`
+	SampleSize        int      `json:"sampleSize" binding:"omitempty,min=1,max=500" jsonschema:"minimum=1,maximum=500"`
+	AutoLinkThreshold float32  `json:"autoLinkThreshold" binding:"omitempty,gt=0,lte=1" jsonschema:"exclusiveMinimum=0,maximum=1"`
+	ReviewThreshold   float32  `json:"reviewThreshold" binding:"omitempty,gt=0,lte=1" jsonschema:"exclusiveMinimum=0,maximum=1"`
+	ModelVersion      string   `json:"modelVersion"`
+}
+
+// SimulatedCandidate is a product a simulated item could match
+type SimulatedCandidate struct {
+	ProductID  string  `json:"productId" jsonschema:"required"`
+	Name       string  `json:"name" jsonschema:"required"`
+	Brand      string  `json:"brand"`
+	Similarity float32 `json:"similarity" jsonschema:"required"`
+}
+
+// SimulatedItem is what AI matching would do with an item
+type SimulatedItem struct {
+	ItemID          string               `json:"itemId" jsonschema:"required"`
+	Name            string               `json:"name" jsonschema:"required"`
+	ChainSlug       string               `json:"chainSlug" jsonschema:"required"`
+	LinkedProductID *string              `json:"linkedProductId,omitempty"`
+	Decision        string               `json:"decision" jsonschema:"required,enum=auto_link,enum=review,enum=no_match,enum=not_embedded"`
+	Reason          string               `json:"reason,omitempty"`
+	Candidates      []SimulatedCandidate `json:"candidates" jsonschema:"required"`
+}
+
+// SimulationSummary counts simulated items by decision
+type SimulationSummary struct {
+	Items       int `json:"items" jsonschema:"required"`
+	AutoLinked  int `json:"autoLinked" jsonschema:"required"`
+	Queued      int `json:"queued" jsonschema:"required"`
+	NoMatch     int `json:"noMatch" jsonschema:"required"`
+	NotEmbedded int `json:"notEmbedded" jsonschema:"required"`
+	// AutoLinkedElsewhere counts linked items that would be auto-linked to
+	// another product than their current one
+	AutoLinkedElsewhere int `json:"autoLinkedElsewhere" jsonschema:"required"`
+}
+
+// SimulateMatchingResponse represents the response of a matching simulation
+type SimulateMatchingResponse struct {
+	ModelVersion      string            `json:"modelVersion" jsonschema:"required"`
+	AutoLinkThreshold float32           `json:"autoLinkThreshold" jsonschema:"required"`
+	ReviewThreshold   float32           `json:"reviewThreshold" jsonschema:"required"`
+	Summary           SimulationSummary `json:"summary" jsonschema:"required"`
+	Items             []SimulatedItem   `json:"items" jsonschema:"required"`
+}
+
+// SimulateMatching dry-runs AI matching under the given thresholds
+// @Summary Simulate AI matching
+// @Description Runs AI candidate generation and scoring for the given items, or a random sample of the unlinked items the next matching run would pick up, and returns what would be auto-linked, queued for review or left unmatched under the supplied thresholds. Nothing is written: no candidates, links or review queue entries. Items are scored from their cached embeddings, so items without one for the model are reported as not_embedded instead of being embedded. Use it to tune thresholds before a production matching run.
+// @Tags matching
+// @Accept json
+// @Produce json
+// @Param request body SimulateMatchingRequest false "Items and thresholds to simulate"
+// @Success 200 {object} SimulateMatchingResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 409 {object} map[string]string "No product embeddings to match against"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/matching/simulate [post]
+func SimulateMatching(c *gin.Context) {
+	var req SimulateMatchingRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	cfg := matching.DefaultAIMatcherConfig(nil)
+	if req.AutoLinkThreshold != 0 {
+		cfg.AutoLinkThreshold = req.AutoLinkThreshold
+	}
+	if req.ReviewThreshold != 0 {
+		cfg.ReviewThreshold = req.ReviewThreshold
+	}
+	if cfg.ReviewThreshold > cfg.AutoLinkThreshold {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reviewThreshold must not exceed autoLinkThreshold"})
+		return
+	}
+
+	// Set default sample size
+	if req.SampleSize == 0 {
+		req.SampleSize = 50
+	}
+
+	ctx := c.Request.Context()
+	db := database.Pool()
+
+	if req.ModelVersion == "" {
+		model, err := matching.LatestEmbeddingModel(ctx, db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find embedding model"})
+			return
+		}
+		if model == "" {
+			c.JSON(http.StatusConflict, gin.H{"error": "No product embeddings to match against"})
+			return
+		}
+		req.ModelVersion = model
+	}
+
+	limit := req.SampleSize
+	if len(req.ItemIDs) > 0 {
+		limit = len(req.ItemIDs)
+	}
+	items, err := matching.GetSimulationItems(ctx, db, req.ItemIDs, req.ModelVersion, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load items"})
+		return
+	}
+
+	if err := matching.SimulateAIMatching(ctx, db, cfg, req.ModelVersion, items); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to simulate matching"})
+		return
+	}
+
+	resp := SimulateMatchingResponse{
+		ModelVersion:      req.ModelVersion,
+		AutoLinkThreshold: cfg.AutoLinkThreshold,
+		ReviewThreshold:   cfg.ReviewThreshold,
+		Items:             make([]SimulatedItem, 0, len(items)),
+	}
+	for _, sim := range items {
+		resp.Items = append(resp.Items, newSimulatedItem(sim))
+		resp.Summary.add(sim)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// newSimulatedItem converts a simulated match to its response form
+func newSimulatedItem(sim matching.SimulatedMatch) SimulatedItem {
+	item := SimulatedItem{
+		ItemID:          sim.Item.ID,
+		Name:            sim.Item.Name,
+		ChainSlug:       sim.Item.ChainSlug,
+		LinkedProductID: sim.LinkedProductID,
+		Decision:        sim.Decision,
+		Reason:          sim.Reason,
+		Candidates:      make([]SimulatedCandidate, 0, len(sim.Candidates)),
+	}
+	for _, cand := range sim.Candidates {
+		item.Candidates = append(item.Candidates, SimulatedCandidate{
+			ProductID:  cand.ProductID,
+			Name:       cand.ProductName,
+			Brand:      cand.Product.Brand,
+			Similarity: cand.Similarity,
+		})
+	}
+	return item
+}
+
+// add counts a simulated match in the summary
+func (s *SimulationSummary) add(sim matching.SimulatedMatch) {
+	s.Items++
+	switch sim.Decision {
+	case matching.DecisionAutoLink:
+		s.AutoLinked++
+		if sim.LinkedProductID != nil && *sim.LinkedProductID != sim.Candidates[0].ProductID {
+			s.AutoLinkedElsewhere++
+		}
+	case matching.DecisionReview:
+		s.Queued++
+	case matching.DecisionNoMatch:
+		s.NoMatch++
+	case matching.DecisionNotEmbedded:
+		s.NotEmbedded++
+	}
+}
@@ -1,10 +1,18 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"slices"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kosarica/price-service/internal/chains"
+	"github.com/kosarica/price-service/internal/database"
 	"github.com/kosarica/price-service/internal/optimizer"
+	"github.com/kosarica/price-service/internal/pkg/i18n"
+	"github.com/kosarica/price-service/internal/profiling"
 )
 
 // ============================================================================
@@ -13,91 +21,143 @@ import (
 
 // BasketItem represents an item in the optimization basket
 type BasketItem struct {
-	ItemID   string `json:"itemId" binding:"required" jsonschema:"required"`
-	Name     string `json:"name" binding:"required" jsonschema:"required"`
-	Quantity int    `json:"quantity" binding:"required,min=1" jsonschema:"required,minimum=1"`
+	ItemID   string `json:"itemId" binding:"required" jsonschema:"required" proto:"1"`
+	Name     string `json:"name" binding:"required" jsonschema:"required" proto:"2"`
+	Quantity int    `json:"quantity" binding:"required,min=1" jsonschema:"required,minimum=1" proto:"3"`
 }
 
 // Location represents a geographic location
 type Location struct {
-	Latitude  float64 `json:"latitude" binding:"required,min=-90,max=90" jsonschema:"required,minimum=-90,maximum=90"`
-	Longitude float64 `json:"longitude" binding:"required,min=-180,max=180" jsonschema:"required,minimum=-180,maximum=180"`
+	Latitude  float64 `json:"latitude" binding:"required,min=-90,max=90" jsonschema:"required,minimum=-90,maximum=90" proto:"1"`
+	Longitude float64 `json:"longitude" binding:"required,min=-180,max=180" jsonschema:"required,minimum=-180,maximum=180" proto:"2"`
 }
 
-// OptimizeRequest represents the basket optimization request
+// OptimizeRequest represents the basket optimization request. Exactly one of
+// chainSlug and chainSlugs is set.
 type OptimizeRequest struct {
-	ChainSlug   string        `json:"chainSlug" binding:"required" jsonschema:"required"`
-	BasketItems []*BasketItem `json:"basketItems" binding:"required,min=1,max=100" jsonschema:"required,minItems=1,maxItems=100"`
-	Location    *Location     `json:"location,omitempty"`
-	MaxDistance float64       `json:"maxDistance,omitempty"`
-	MaxStores   int           `json:"maxStores,omitempty" jsonschema:"minimum=1,maximum=10"`
+	ChainSlug string `json:"chainSlug,omitempty" proto:"1"`
+	// ChainSlugs combines stores of several chains (multi-store only); ["all"]
+	// means every chain with loaded prices. Items are matched across chains
+	// through the products they are linked to.
+	ChainSlugs  []string      `json:"chainSlugs,omitempty" binding:"omitempty,max=20" jsonschema:"maxItems=20" proto:"2"`
+	BasketItems []*BasketItem `json:"basketItems" binding:"required,min=1,max=100" jsonschema:"required,minItems=1,maxItems=100" proto:"3"`
+	Location    *Location     `json:"location,omitempty" proto:"4"`
+	MaxDistance float64       `json:"maxDistance,omitempty" proto:"5"`
+	MaxStores   int           `json:"maxStores,omitempty" jsonschema:"minimum=1,maximum=10" proto:"6"`
+	// PenaltyStrategy overrides the configured missing-item penalty strategy
+	PenaltyStrategy string `json:"penaltyStrategy,omitempty" binding:"omitempty,oneof=mean simple_mean median max category_average category_table" jsonschema:"enum=mean,enum=simple_mean,enum=median,enum=max,enum=category_average,enum=category_table" proto:"7"`
+	// UserRef is an opaque caller-side user reference; when set the result is
+	// stored (with rounded coordinates) and can be exported or deleted by it
+	UserRef string `json:"userRef,omitempty" binding:"omitempty,max=200" jsonschema:"maxLength=200" proto:"8"`
+	// MinCoverage leaves out stores with a lower coverage ratio, Offset and
+	// Limit page through the rest (single-store only). Limit defaults to 50.
+	MinCoverage float64 `json:"minCoverage,omitempty" binding:"omitempty,min=0,max=1" jsonschema:"minimum=0,maximum=1" proto:"9"`
+	Offset      int     `json:"offset,omitempty" binding:"omitempty,min=0" jsonschema:"minimum=0" proto:"10"`
+	Limit       int     `json:"limit,omitempty" binding:"omitempty,min=1,max=50" jsonschema:"minimum=1,maximum=50" proto:"11"`
+	// SortBy ranks stores within a coverage bin by price (the default),
+	// distance or both (single-store only); distance and balanced need a
+	// location
+	SortBy string `json:"sortBy,omitempty" binding:"omitempty,oneof=price distance balanced" jsonschema:"enum=price,enum=distance,enum=balanced" proto:"12"`
 }
 
 // MissingItem represents an item not available at a store
 type MissingItem struct {
-	ItemID     string `json:"itemId" jsonschema:"required"`
-	ItemName   string `json:"itemName" jsonschema:"required"`
-	Penalty    int64  `json:"penalty" jsonschema:"required"`
-	IsOptional bool   `json:"isOptional" jsonschema:"required"`
+	ItemID     string `json:"itemId" jsonschema:"required" proto:"1"`
+	ItemName   string `json:"itemName" jsonschema:"required" proto:"2"`
+	Penalty    int64  `json:"penalty" jsonschema:"required" proto:"3"`
+	IsOptional bool   `json:"isOptional" jsonschema:"required" proto:"4"`
+	// Reason says why the item is missing, in the request's language
+	Reason string `json:"reason" jsonschema:"required" proto:"5"`
 }
 
 // ItemPriceInfo contains price information for an item
 type ItemPriceInfo struct {
-	ItemID         string `json:"itemId" jsonschema:"required"`
-	ItemName       string `json:"itemName" jsonschema:"required"`
-	Quantity       int    `json:"quantity" jsonschema:"required"`
-	BasePrice      int64  `json:"basePrice" jsonschema:"required"`
-	EffectivePrice int64  `json:"effectivePrice" jsonschema:"required"`
-	HasDiscount    bool   `json:"hasDiscount" jsonschema:"required"`
-	DiscountPrice  *int64 `json:"discountPrice,omitempty"`
-	LineTotal      int64  `json:"lineTotal" jsonschema:"required"`
+	ItemID         string `json:"itemId" jsonschema:"required" proto:"1"`
+	ItemName       string `json:"itemName" jsonschema:"required" proto:"2"`
+	Quantity       int    `json:"quantity" jsonschema:"required" proto:"3"`
+	BasePrice      int64  `json:"basePrice" jsonschema:"required" proto:"4"`
+	EffectivePrice int64  `json:"effectivePrice" jsonschema:"required" proto:"5"`
+	HasDiscount    bool   `json:"hasDiscount" jsonschema:"required" proto:"6"`
+	DiscountPrice  *int64 `json:"discountPrice,omitempty" proto:"7"`
+	LineTotal      int64  `json:"lineTotal" jsonschema:"required" proto:"8"`
+
+	// PriceSavings are the discount figures of the base price. The optimizer
+	// holds no anchor prices, so isBelowAnchorPrice is always omitted.
+	PriceSavings `proto:"9"`
+
+	// Formatted holds display strings of the amounts, with ?includeFormatted=true
+	Formatted *FormattedItemPrice `json:"formatted,omitempty" proto:"12"`
 }
 
 // SingleStoreResult represents the optimization result for a single store
 type SingleStoreResult struct {
-	StoreID       string           `json:"storeId" jsonschema:"required"`
-	CoverageRatio float64          `json:"coverageRatio" jsonschema:"required"`
-	CoverageBin   int              `json:"coverageBin" jsonschema:"required"`
-	SortingTotal  int64            `json:"sortingTotal" jsonschema:"required"`
-	RealTotal     int64            `json:"realTotal" jsonschema:"required"`
-	MissingItems  []*MissingItem   `json:"missingItems,omitempty"`
-	Items         []*ItemPriceInfo `json:"items,omitempty"`
-	Distance      float64          `json:"distance" jsonschema:"required"`
+	StoreID       string           `json:"storeId" jsonschema:"required" proto:"1"`
+	CoverageRatio float64          `json:"coverageRatio" jsonschema:"required" proto:"2"`
+	CoverageBin   int              `json:"coverageBin" jsonschema:"required" proto:"3"`
+	SortingTotal  int64            `json:"sortingTotal" jsonschema:"required" proto:"4"`
+	RealTotal     int64            `json:"realTotal" jsonschema:"required" proto:"5"`
+	MissingItems  []*MissingItem   `json:"missingItems,omitempty" proto:"6"`
+	Items         []*ItemPriceInfo `json:"items,omitempty" proto:"7"`
+	Distance      float64          `json:"distance" jsonschema:"required" proto:"8"`
+
+	// FormattedRealTotal is RealTotal for display, with ?includeFormatted=true
+	FormattedRealTotal *string `json:"formattedRealTotal,omitempty" proto:"9"`
 }
 
 // StoreAllocation represents a store in a multi-store optimization
 type StoreAllocation struct {
-	StoreID    string           `json:"storeId" jsonschema:"required"`
-	Items      []*ItemPriceInfo `json:"items" jsonschema:"required"`
-	StoreTotal int64            `json:"storeTotal" jsonschema:"required"`
-	Distance   float64          `json:"distance" jsonschema:"required"`
-	VisitOrder int              `json:"visitOrder" jsonschema:"required"`
+	StoreID    string           `json:"storeId" jsonschema:"required" proto:"1"`
+	ChainSlug  string           `json:"chainSlug" jsonschema:"required" proto:"2"`
+	Items      []*ItemPriceInfo `json:"items" jsonschema:"required" proto:"3"`
+	StoreTotal int64            `json:"storeTotal" jsonschema:"required" proto:"4"`
+	Distance   float64          `json:"distance" jsonschema:"required" proto:"5"`
+	VisitOrder int              `json:"visitOrder" jsonschema:"required" proto:"6"`
+
+	// FormattedStoreTotal is StoreTotal for display, with ?includeFormatted=true
+	FormattedStoreTotal *string `json:"formattedStoreTotal,omitempty" proto:"7"`
 }
 
 // MultiStoreResult represents the optimization result across multiple stores
 type MultiStoreResult struct {
-	Stores          []*StoreAllocation `json:"stores" jsonschema:"required"`
-	CombinedTotal   int64              `json:"combinedTotal" jsonschema:"required"`
-	CoverageRatio   float64            `json:"coverageRatio" jsonschema:"required"`
-	UnassignedItems []*MissingItem     `json:"unassignedItems,omitempty"`
-	AlgorithmUsed   string             `json:"algorithmUsed" jsonschema:"required"`
+	Stores          []*StoreAllocation `json:"stores" jsonschema:"required" proto:"1"`
+	CombinedTotal   int64              `json:"combinedTotal" jsonschema:"required" proto:"2"`
+	CoverageRatio   float64            `json:"coverageRatio" jsonschema:"required" proto:"3"`
+	UnassignedItems []*MissingItem     `json:"unassignedItems,omitempty" proto:"4"`
+	AlgorithmUsed   string             `json:"algorithmUsed" jsonschema:"required" proto:"5"`
+	PenaltyStrategy string             `json:"penaltyStrategy" jsonschema:"required" proto:"6"`
+	Staleness       *Staleness         `json:"staleness,omitempty" proto:"7"`
+
+	// FormattedCombinedTotal is CombinedTotal for display, with
+	// ?includeFormatted=true
+	FormattedCombinedTotal *string `json:"formattedCombinedTotal,omitempty" proto:"8"`
+
+	// TravelDistanceKm is the round trip from the location through the
+	// stores in visitOrder and back, omitted without a location
+	TravelDistanceKm float64 `json:"travelDistanceKm,omitempty" proto:"9"`
+
+	// TravelCost is the cost of the trip the stores were chosen with
+	TravelCost TravelCost `json:"travelCost" jsonschema:"required" proto:"10"`
+}
+
+// TravelCost is the travel cost weighed against a multi-store result's total,
+// in minor units
+type TravelCost struct {
+	DistanceCost int64 `json:"distanceCost" jsonschema:"required" proto:"1"` // The round trip at the configured cost per km
+	StoreCost    int64 `json:"storeCost" jsonschema:"required" proto:"2"`    // The configured cost of each store after the first
+	Total        int64 `json:"total" jsonschema:"required" proto:"3"`
 }
 
 // Global optimizer instances (initialized by the application)
 var (
-	singleStoreOptimizer *optimizer.SingleStoreOptimizer
-	multiStoreOptimizer  *optimizer.MultiStoreOptimizer
-	priceCache           *optimizer.PriceCache
-	optimizerConfig      *optimizer.OptimizerConfig
+	optimizerRegistry *optimizer.Registry
+	priceCache        *optimizer.PriceCache
 )
 
 // InitOptimizers initializes the optimizer instances
 // This should be called during application startup
 func InitOptimizers(cache *optimizer.PriceCache, config *optimizer.OptimizerConfig, metrics *optimizer.MetricsRecorder) {
 	priceCache = cache
-	optimizerConfig = config
-	singleStoreOptimizer = optimizer.NewSingleStoreOptimizer(cache, config)
-	multiStoreOptimizer = optimizer.NewMultiStoreOptimizer(cache, config, metrics)
+	optimizerRegistry = optimizer.NewRegistry(cache, config, metrics)
 }
 
 // GetPriceCache returns the price cache instance
@@ -105,67 +165,76 @@ func GetPriceCache() *optimizer.PriceCache {
 	return priceCache
 }
 
+// SingleStoreResponse represents the single-store optimization results
+type SingleStoreResponse struct {
+	Results         []*SingleStoreResult `json:"results" jsonschema:"required" proto:"1"`
+	Total           int                  `json:"total" jsonschema:"required" proto:"2"`
+	PenaltyStrategy string               `json:"penaltyStrategy" jsonschema:"required" proto:"3"`
+	Staleness       *Staleness           `json:"staleness,omitempty" proto:"4"`
+	TotalCandidates int                  `json:"totalCandidates" jsonschema:"required" proto:"5"` // Stores meeting minCoverage, on all pages
+}
+
 // OptimizeSingle handles single-store basket optimization
 // @Summary Optimize basket for single store
-// @Description Finds the best single store for a basket of items based on price and coverage
+// @Description Finds the best single store for a basket of items based on price and coverage. Stores are ranked by coverage, then by sortBy: price (the default), distance from the location, or balanced, the price plus a configured cost per km, and with a location each store carries its distance in km. minCoverage leaves out stores with less coverage, and offset and limit (default 50) page through the rest, with totalCandidates counting them all. Missing items are priced by the configured penalty strategy unless penaltyStrategy overrides it; the strategy used is reported. Results served from a stale or degraded cache carry a staleness field.
 // @Tags basket
 // @Accept json
 // @Produce json
 // @Param request body OptimizeRequest true "Optimization request"
-// @Success 200 {object} map[string]interface{} "Optimization results"
+// @Param includeFormatted query bool false "Add display strings of the amounts, e.g. 1,99 €, and discount percentages"
+// @Param Accept-Language header string false "Language of messages: hr or en (default)"
+// @Success 200 {object} SingleStoreResponse
 // @Failure 400 {object} map[string]string "Bad request"
 // @Failure 500 {object} map[string]string "Internal server error"
-// @Failure 503 {object} map[string]string "Cache unavailable"
+// @Failure 503 {object} map[string]string "Cache unavailable or optimizer overloaded (see Retry-After)"
 // @Router /internal/basket/optimize/single [post]
+// @Router /api/v1/basket/optimize/single [post]
 func OptimizeSingle(c *gin.Context) {
+	ctx := requestContext(c)
 	var req OptimizeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeError(c, bindError(ctx, err))
 		return
 	}
 
-	// Convert request to internal format
-	basketItems := make([]*optimizer.BasketItem, len(req.BasketItems))
-	for i, item := range req.BasketItems {
-		basketItems[i] = &optimizer.BasketItem{
-			ItemID:   item.ItemID,
-			Name:     item.Name,
-			Quantity: item.Quantity,
-		}
+	response, err := ServeOptimizeSingle(ctx, &req, includeFormatted(c))
+	if err != nil {
+		writeError(c, err)
+		return
 	}
+	c.JSON(http.StatusOK, response)
+}
 
-	optimizeReq := &optimizer.OptimizeRequest{
-		ChainSlug:   req.ChainSlug,
-		BasketItems: basketItems,
-		Location:    nil,
-		MaxDistance: req.MaxDistance,
-		MaxStores:   req.MaxStores,
+// ServeOptimizeSingle runs a validated single-store optimization request;
+// failures to report to the caller are RequestErrors
+func ServeOptimizeSingle(ctx context.Context, req *OptimizeRequest, withFormatted bool) (*SingleStoreResponse, error) {
+	if len(req.ChainSlugs) > 0 {
+		return nil, localizedError(ctx, http.StatusBadRequest, "optimize.chains_single_only", nil)
 	}
-
-	if req.Location != nil {
-		optimizeReq.Location = &optimizer.Location{
-			Latitude:  req.Location.Latitude,
-			Longitude: req.Location.Longitude,
-		}
+	if req.ChainSlug == "" {
+		return nil, localizedError(ctx, http.StatusBadRequest, "optimize.chain_required", nil)
 	}
-
-	// Check if cache is healthy
-	if priceCache == nil {
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Cache not initialized"})
-		return
+	if req.SortBy != "" && req.SortBy != optimizer.SortByPrice && req.Location == nil {
+		return nil, localizedError(ctx, http.StatusBadRequest, "optimize.sort_needs_location", i18n.Args{"sortBy": req.SortBy})
 	}
 
-	if !priceCache.IsHealthy(c.Request.Context()) {
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Cache unavailable or stale"})
-		return
+	optimizeReq := newOptimizerRequest(req, nil)
+
+	// Apply load shedding; stale snapshots are served with a staleness field
+	admission, staleness, err := admitOptimize(ctx, req.ChainSlug)
+	if err != nil {
+		return nil, err
 	}
+	defer admission.Release()
+	defer profiling.ObserveOptimize(time.Now())
 
-	// Run optimization
-	results, err := singleStoreOptimizer.Optimize(c.Request.Context(), optimizeReq)
+	// Run optimization on the active version for the whole request
+	version := optimizerRegistry.Active()
+	results, totalCandidates, err := version.Single.Optimize(ctx, optimizeReq)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+		return nil, err
 	}
+	optimizerRegistry.RecordCanary(optimizeReq)
 
 	// Convert results to response format
 	response := make([]*SingleStoreResult, len(results))
@@ -177,22 +246,7 @@ func OptimizeSingle(c *gin.Context) {
 				ItemName:   m.ItemName,
 				Penalty:    m.Penalty,
 				IsOptional: m.IsOptional,
-			}
-		}
-
-		items := make([]*ItemPriceInfo, len(r.Items))
-		for j, item := range r.Items {
-			items[j] = &ItemPriceInfo{
-				ItemID:         item.ItemID,
-				ItemName:       item.ItemName,
-				Quantity:       item.Quantity,
-				BasePrice:      item.BasePrice,
-				EffectivePrice: item.EffectivePrice,
-				HasDiscount:    item.HasDiscount,
-				LineTotal:      item.LineTotal,
-			}
-			if item.DiscountPrice != nil {
-				items[j].DiscountPrice = item.DiscountPrice
+				Reason:     i18n.T(ctx, "missing.not_at_store", nil),
 			}
 		}
 
@@ -203,120 +257,125 @@ func OptimizeSingle(c *gin.Context) {
 			SortingTotal:  r.SortingTotal,
 			RealTotal:     r.RealTotal,
 			MissingItems:  missingItems,
-			Items:         items,
+			Items:         newItemPriceInfos(r.Items, withFormatted),
 			Distance:      r.Distance,
 		}
+		if withFormatted {
+			response[i].FormattedRealTotal = formatTotal(r.RealTotal)
+		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"results": response,
-		"total":   len(response),
-	})
+	logOptimization("single", req)
+	storeOptimizationResult(ctx, "single", req, response)
+
+	return &SingleStoreResponse{
+		Results:         response,
+		Total:           len(response),
+		TotalCandidates: totalCandidates,
+		PenaltyStrategy: version.Config.PenaltyStrategyName(req.PenaltyStrategy),
+		Staleness:       staleness,
+	}, nil
 }
 
 // OptimizeMulti handles multi-store basket optimization
 // @Summary Optimize basket across multiple stores
-// @Description Finds the optimal distribution of basket items across multiple stores
+// @Description Finds the optimal distribution of basket items across multiple stores. With a location the stores are ordered as a short round trip from it, numbered by visitOrder, with travelDistanceKm its length. Stores are chosen by basket total plus travelCost: a configured cost per km of the trip and per store after the first. Missing items are priced by the configured penalty strategy unless penaltyStrategy overrides it; the strategy used is reported. Under load only the greedy algorithm runs, and results served from a stale or degraded cache carry a staleness field.
 // @Tags basket
 // @Accept json
 // @Produce json
 // @Param request body OptimizeRequest true "Optimization request"
+// @Param includeFormatted query bool false "Add display strings of the amounts, e.g. 1,99 €, and discount percentages"
+// @Param Accept-Language header string false "Language of messages: hr or en (default)"
 // @Success 200 {object} MultiStoreResult
 // @Failure 400 {object} map[string]string "Bad request"
 // @Failure 500 {object} map[string]string "Internal server error"
-// @Failure 503 {object} map[string]string "Cache unavailable"
+// @Failure 503 {object} map[string]string "Cache unavailable or optimizer overloaded (see Retry-After)"
 // @Failure 504 {object} map[string]string "Optimization timed out"
 // @Router /internal/basket/optimize/multi [post]
+// @Router /api/v1/basket/optimize/multi [post]
 func OptimizeMulti(c *gin.Context) {
+	ctx := requestContext(c)
 	var req OptimizeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeError(c, bindError(ctx, err))
 		return
 	}
 
+	response, err := ServeOptimizeMulti(ctx, &req, includeFormatted(c))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ServeOptimizeMulti runs a validated multi-store optimization request;
+// failures to report to the caller are RequestErrors
+func ServeOptimizeMulti(ctx context.Context, req *OptimizeRequest, withFormatted bool) (*MultiStoreResult, error) {
 	// Validate multi-store specific constraints
 	if req.MaxStores <= 0 {
 		req.MaxStores = 5 // Default max stores
 	}
 	if req.MaxStores > 10 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "maxStores cannot exceed 10"})
-		return
+		return nil, localizedError(ctx, http.StatusBadRequest, "optimize.max_stores", i18n.Args{"max": 10})
 	}
-
-	// Convert request to internal format
-	basketItems := make([]*optimizer.BasketItem, len(req.BasketItems))
-	for i, item := range req.BasketItems {
-		basketItems[i] = &optimizer.BasketItem{
-			ItemID:   item.ItemID,
-			Name:     item.Name,
-			Quantity: item.Quantity,
-		}
+	if (req.ChainSlug == "") == (len(req.ChainSlugs) == 0) {
+		return nil, localizedError(ctx, http.StatusBadRequest, "optimize.one_chain_source", nil)
 	}
-
-	optimizeReq := &optimizer.OptimizeRequest{
-		ChainSlug:   req.ChainSlug,
-		BasketItems: basketItems,
-		Location:    nil,
-		MaxDistance: req.MaxDistance,
-		MaxStores:   req.MaxStores,
+	chainSlugs, err := optimizeChains(ctx, req.ChainSlugs)
+	if err != nil {
+		return nil, err
 	}
 
-	if req.Location != nil {
-		optimizeReq.Location = &optimizer.Location{
-			Latitude:  req.Location.Latitude,
-			Longitude: req.Location.Longitude,
-		}
-	}
+	optimizeReq := newOptimizerRequest(req, chainSlugs)
 
-	// Check if cache is healthy
-	if priceCache == nil {
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Cache not initialized"})
-		return
+	// Apply load shedding; stale snapshots are served with a staleness field
+	if chainSlugs == nil {
+		chainSlugs = []string{req.ChainSlug}
 	}
+	admission, staleness, err := admitOptimize(ctx, chainSlugs...)
+	if err != nil {
+		return nil, err
+	}
+	defer admission.Release()
+	defer profiling.ObserveOptimize(time.Now())
 
-	if !priceCache.IsHealthy(c.Request.Context()) {
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Cache unavailable or stale"})
-		return
+	// Skip the optimal algorithm under pressure
+	optimizeReq.GreedyOnly = admission.GreedyOnly
+
+	// Look up each item's equivalent in the other chains
+	if len(optimizeReq.ChainSlugs) > 0 {
+		if err := resolveChainItems(ctx, optimizeReq); err != nil {
+			logger.Error().Err(err).Msg("Failed to resolve basket items across chains")
+			return nil, localizedError(ctx, http.StatusInternalServerError, "optimize.resolve_failed", nil)
+		}
 	}
 
 	// Run optimization
-	result, err := multiStoreOptimizer.Optimize(c.Request.Context(), optimizeReq)
+	result, err := optimizerRegistry.Active().Multi.Optimize(ctx, optimizeReq)
 	if err != nil {
 		// Check for timeout
 		if err.Error() == "context deadline exceeded" {
-			c.JSON(http.StatusGatewayTimeout, gin.H{"error": "Optimization timed out"})
-			return
+			return nil, localizedError(ctx, http.StatusGatewayTimeout, "optimize.timed_out", nil)
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+		return nil, err
 	}
+	optimizerRegistry.RecordCanary(optimizeReq)
 
 	// Convert result to response format
 	stores := make([]*StoreAllocation, len(result.Stores))
 	for i, s := range result.Stores {
-		items := make([]*ItemPriceInfo, len(s.Items))
-		for j, item := range s.Items {
-			items[j] = &ItemPriceInfo{
-				ItemID:         item.ItemID,
-				ItemName:       item.ItemName,
-				Quantity:       item.Quantity,
-				BasePrice:      item.BasePrice,
-				EffectivePrice: item.EffectivePrice,
-				HasDiscount:    item.HasDiscount,
-				LineTotal:      item.LineTotal,
-			}
-			if item.DiscountPrice != nil {
-				items[j].DiscountPrice = item.DiscountPrice
-			}
-		}
-
 		stores[i] = &StoreAllocation{
 			StoreID:    s.StoreID,
-			Items:      items,
+			ChainSlug:  s.ChainSlug,
+			Items:      newItemPriceInfos(s.Items, withFormatted),
 			StoreTotal: s.StoreTotal,
 			Distance:   s.Distance,
 			VisitOrder: s.VisitOrder,
 		}
+		if withFormatted {
+			stores[i].FormattedStoreTotal = formatTotal(s.StoreTotal)
+		}
 	}
 
 	unassignedItems := make([]*MissingItem, len(result.UnassignedItems))
@@ -326,18 +385,149 @@ func OptimizeMulti(c *gin.Context) {
 			ItemName:   u.ItemName,
 			Penalty:    u.Penalty,
 			IsOptional: u.IsOptional,
+			Reason:     i18n.T(ctx, "missing.not_at_any_store", nil),
 		}
 	}
 
 	response := &MultiStoreResult{
-		Stores:          stores,
-		CombinedTotal:   result.CombinedTotal,
-		CoverageRatio:   result.CoverageRatio,
-		UnassignedItems: unassignedItems,
-		AlgorithmUsed:   result.AlgorithmUsed,
+		Stores:           stores,
+		CombinedTotal:    result.CombinedTotal,
+		CoverageRatio:    result.CoverageRatio,
+		UnassignedItems:  unassignedItems,
+		AlgorithmUsed:    result.AlgorithmUsed,
+		PenaltyStrategy:  result.PenaltyStrategy,
+		Staleness:        staleness,
+		TravelDistanceKm: result.TravelDistanceKm,
+		TravelCost: TravelCost{
+			DistanceCost: result.TravelCost.DistanceCost,
+			StoreCost:    result.TravelCost.StoreCost,
+			Total:        result.TravelCost.Total,
+		},
+	}
+	if withFormatted {
+		response.FormattedCombinedTotal = formatTotal(result.CombinedTotal)
 	}
 
-	c.JSON(http.StatusOK, response)
+	logOptimization("multi", req)
+	storeOptimizationResult(ctx, "multi", req, response)
+
+	return response, nil
+}
+
+// newOptimizerRequest converts a request to the optimizer's format
+func newOptimizerRequest(req *OptimizeRequest, chainSlugs []string) *optimizer.OptimizeRequest {
+	basketItems := make([]*optimizer.BasketItem, len(req.BasketItems))
+	for i, item := range req.BasketItems {
+		basketItems[i] = &optimizer.BasketItem{
+			ItemID:   item.ItemID,
+			Name:     item.Name,
+			Quantity: item.Quantity,
+		}
+	}
+
+	optimizeReq := &optimizer.OptimizeRequest{
+		ChainSlug:   req.ChainSlug,
+		ChainSlugs:  chainSlugs,
+		BasketItems: basketItems,
+		Location:    nil,
+		MaxDistance: req.MaxDistance,
+		MaxStores:   req.MaxStores,
+		MinCoverage: req.MinCoverage,
+		Offset:      req.Offset,
+		Limit:       req.Limit,
+		SortBy:      req.SortBy,
+
+		PenaltyStrategy: req.PenaltyStrategy,
+	}
+
+	if req.Location != nil {
+		optimizeReq.Location = &optimizer.Location{
+			Latitude:  req.Location.Latitude,
+			Longitude: req.Location.Longitude,
+		}
+	}
+	return optimizeReq
+}
+
+// newItemPriceInfos converts the priced items of a store to response format
+func newItemPriceInfos(priced []*optimizer.ItemPriceInfo, withFormatted bool) []*ItemPriceInfo {
+	items := make([]*ItemPriceInfo, len(priced))
+	for j, item := range priced {
+		items[j] = &ItemPriceInfo{
+			ItemID:         item.ItemID,
+			ItemName:       item.ItemName,
+			Quantity:       item.Quantity,
+			BasePrice:      item.BasePrice,
+			EffectivePrice: item.EffectivePrice,
+			HasDiscount:    item.HasDiscount,
+			LineTotal:      item.LineTotal,
+		}
+		if item.DiscountPrice != nil {
+			items[j].DiscountPrice = item.DiscountPrice
+		}
+		items[j].PriceSavings = newPriceSavings(item.BasePrice, item.DiscountPrice, nil)
+		if withFormatted {
+			items[j].Formatted = formatItemPrice(items[j])
+		}
+	}
+	return items
+}
+
+// optimizeChains validates the chains of a cross-chain request, expanding
+// ["all"] to every chain with loaded prices. Returns nil for a single-chain
+// request, and a RequestError for invalid chains.
+func optimizeChains(ctx context.Context, chainSlugs []string) ([]string, error) {
+	if len(chainSlugs) == 0 {
+		return nil, nil
+	}
+	if len(chainSlugs) == 1 && chainSlugs[0] == "all" {
+		all := make([]string, 0)
+		if priceCache != nil {
+			for chainSlug := range priceCache.GetFreshness(ctx) {
+				all = append(all, chainSlug)
+			}
+		}
+		if len(all) == 0 {
+			return nil, localizedError(ctx, http.StatusBadRequest, "optimize.chains_not_loaded", nil)
+		}
+		slices.Sort(all)
+		return all, nil
+	}
+
+	unique := make([]string, 0, len(chainSlugs))
+	for _, chainSlug := range chainSlugs {
+		if !chains.IsValidChain(chainSlug) {
+			return nil, localizedError(ctx, http.StatusBadRequest, "optimize.chain_invalid", i18n.Args{"chain": chainSlug})
+		}
+		if !slices.Contains(unique, chainSlug) {
+			unique = append(unique, chainSlug)
+		}
+	}
+	return unique, nil
+}
+
+// resolveChainItems sets each basket item's IDs in the request's chains.
+// Without a database every chain is looked up under the item's own ID.
+func resolveChainItems(ctx context.Context, req *optimizer.OptimizeRequest) error {
+	if database.Pool() == nil {
+		return nil
+	}
+
+	itemIDs := make([]string, len(req.BasketItems))
+	for i, item := range req.BasketItems {
+		itemIDs[i] = item.ItemID
+	}
+	chainItemIDs, err := database.ChainItemIDs(ctx, itemIDs, req.ChainSlugs)
+	if err != nil {
+		return err
+	}
+	for _, item := range req.BasketItems {
+		item.ChainItemIDs = chainItemIDs[item.ItemID]
+		if item.ChainItemIDs == nil {
+			item.ChainItemIDs = map[string]string{}
+		}
+	}
+	return nil
 }
 
 // CacheWarmup handles cache warmup requests
@@ -377,6 +567,7 @@ func CacheWarmup(c *gin.Context) {
 // @Param chainSlug path string true "Chain slug identifier"
 // @Success 200 {object} map[string]interface{} "Cache refreshed"
 // @Failure 400 {object} map[string]string "Bad request"
+// @Failure 409 {object} map[string]string "Refresh held while the chain's latest run is suspect"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Failure 503 {object} map[string]string "Cache not initialized"
 // @Router /internal/basket/cache/refresh/{chainSlug} [post]
@@ -393,11 +584,20 @@ func CacheRefresh(c *gin.Context) {
 	}
 
 	err := priceCache.RefreshChain(c.Request.Context(), chainSlug)
+	if errors.Is(err, optimizer.ErrRefreshHeld) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh cache: " + err.Error()})
 		return
 	}
 
+	// Other replicas refresh too; they keep serving their own copy if not
+	if err := priceCache.BroadcastRefresh(c.Request.Context(), chainSlug); err != nil {
+		logger.Warn().Err(err).Str("chain", chainSlug).Msg("Failed to broadcast cache refresh")
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"status": "ok",
 		"message": "Chain cache refreshed successfully",
@@ -407,7 +607,7 @@ func CacheRefresh(c *gin.Context) {
 
 // CacheHealth handles cache health check requests
 // @Summary Get cache health
-// @Description Returns the health status and freshness information for all cached chains
+// @Description Returns the health status and freshness information for all cached chains, and the progress of the latest warmup with the optimize requests it held or rejected
 // @Tags cache
 // @Accept json
 // @Produce json
@@ -432,6 +632,8 @@ func CacheHealth(c *gin.Context) {
 			"loadedAt":  info.LoadedAt,
 			"isStale":   info.IsStale,
 			"estimatedMB": info.EstimatedMB,
+			"internedStrings": info.InternedStrings,
+			"internSavedBytes": info.InternSavedBytes,
 		})
 	}
 
@@ -444,5 +646,6 @@ func CacheHealth(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"status": status,
 		"chains": chains,
+		"warmup": priceCache.WarmupProgress(),
 	})
 }
@@ -246,6 +246,26 @@ func TestOptimizeCacheUnavailable(t *testing.T) {
 	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
 }
 
+// TestOptimizeChains tests validation of the chains of a cross-chain request.
+func TestOptimizeChains(t *testing.T) {
+	priceCache = nil
+	ctx := context.Background()
+
+	chainSlugs, err := optimizeChains(ctx, nil)
+	require.NoError(t, err)
+	assert.Nil(t, chainSlugs, "single-chain requests have no chain list")
+
+	chainSlugs, err = optimizeChains(ctx, []string{"lidl", "konzum", "lidl"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lidl", "konzum"}, chainSlugs)
+
+	_, err = optimizeChains(ctx, []string{"lidl", "no-such-chain"})
+	assert.ErrorContains(t, err, "no-such-chain")
+
+	_, err = optimizeChains(ctx, []string{"all"})
+	assert.Error(t, err, "all needs loaded prices")
+}
+
 // TestHaversineEdgeCases tests Haversine calculation edge cases.
 func TestHaversineEdgeCases(t *testing.T) {
 	tests := []struct {
@@ -481,6 +501,8 @@ func runHandlersTestMigrations(ctx context.Context, db *pgxpool.Pool) error {
 		discount_price INTEGER,
 		unit_price INTEGER,
 		anchor_price INTEGER,
+		discount_start TIMESTAMPTZ,
+		discount_end TIMESTAMPTZ,
 		created_at TIMESTAMPTZ DEFAULT NOW(),
 		PRIMARY KEY (price_group_id, retailer_item_id)
 	);
@@ -497,6 +519,13 @@ func runHandlersTestMigrations(ctx context.Context, db *pgxpool.Pool) error {
 		PRIMARY KEY (store_id, retailer_item_id)
 	);
 
+	CREATE TABLE IF NOT EXISTS store_item_assortment (
+		store_id TEXT NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+		retailer_item_id TEXT NOT NULL REFERENCES retailer_items(id) ON DELETE CASCADE,
+		last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (store_id, retailer_item_id)
+	);
+
 	CREATE INDEX IF NOT EXISTS stores_chain_slug_idx ON stores(chain_slug);
 	CREATE INDEX IF NOT EXISTS store_group_history_store_id_idx ON store_group_history(store_id);
 	CREATE INDEX IF NOT EXISTS store_group_history_valid_to_idx ON store_group_history(valid_to) WHERE valid_to IS NULL;
@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kosarica/price-service/internal/optimizer"
+)
+
+// OptimizerSettings are the optimizer settings a new version can change.
+// Cache settings are shared by every version and apply at startup.
+type OptimizerSettings struct {
+	TopCheapestStores            int              `json:"topCheapestStores" jsonschema:"required"`
+	TopNearestStores             int              `json:"topNearestStores" jsonschema:"required"`
+	MaxCandidates                int              `json:"maxCandidates" jsonschema:"required"`
+	MaxDistanceKm                float64          `json:"maxDistanceKm" jsonschema:"required"`
+	OptimalTimeoutMs             int              `json:"optimalTimeoutMs" jsonschema:"required"`
+	MaxBasketItems               int              `json:"maxBasketItems" jsonschema:"required"`
+	MinBasketItems               int              `json:"minBasketItems" jsonschema:"required"`
+	MissingItemPenaltyMult       float64          `json:"missingItemPenaltyMult" jsonschema:"required"`
+	MissingItemFallback          int64            `json:"missingItemFallback" jsonschema:"required"`
+	MissingItemPenaltyStrategy   string           `json:"missingItemPenaltyStrategy" jsonschema:"required"`
+	MissingItemCategoryPenalties map[string]int64 `json:"missingItemCategoryPenalties,omitempty"`
+	CoverageBins                 []float64        `json:"coverageBins" jsonschema:"required"`
+	BalancedDistanceCostPerKm    int64            `json:"balancedDistanceCostPerKm" jsonschema:"required"`
+	TravelCostPerKm              int64            `json:"travelCostPerKm" jsonschema:"required"`
+	ExtraStoreCost               int64            `json:"extraStoreCost" jsonschema:"required"`
+}
+
+// SetOptimizerConfigRequest represents the settings to change in a new
+// optimizer version; omitted settings keep the active version's value
+type SetOptimizerConfigRequest struct {
+	TopCheapestStores            *int             `json:"topCheapestStores"`
+	TopNearestStores             *int             `json:"topNearestStores"`
+	MaxCandidates                *int             `json:"maxCandidates"`
+	MaxDistanceKm                *float64         `json:"maxDistanceKm"`
+	OptimalTimeoutMs             *int             `json:"optimalTimeoutMs"`
+	MaxBasketItems               *int             `json:"maxBasketItems"`
+	MinBasketItems               *int             `json:"minBasketItems"`
+	MissingItemPenaltyMult       *float64         `json:"missingItemPenaltyMult"`
+	MissingItemFallback          *int64           `json:"missingItemFallback"`
+	MissingItemPenaltyStrategy   *string          `json:"missingItemPenaltyStrategy"`
+	MissingItemCategoryPenalties map[string]int64 `json:"missingItemCategoryPenalties"` // Replaces the whole table
+	CoverageBins                 []float64        `json:"coverageBins"`
+	BalancedDistanceCostPerKm    *int64           `json:"balancedDistanceCostPerKm"`
+	TravelCostPerKm              *int64           `json:"travelCostPerKm"`
+	ExtraStoreCost               *int64           `json:"extraStoreCost"`
+}
+
+// SetOptimizerConfigResponse represents a version accepted for building
+type SetOptimizerConfigResponse struct {
+	Version int    `json:"version" jsonschema:"required"`
+	Status  string `json:"status" jsonschema:"required"`
+}
+
+// OptimizerVersionInfo describes an optimizer version
+type OptimizerVersionInfo struct {
+	Version     int                     `json:"version" jsonschema:"required"`
+	Settings    OptimizerSettings       `json:"settings" jsonschema:"required"`
+	BuiltAt     time.Time               `json:"builtAt" jsonschema:"required"`
+	ActivatedAt time.Time               `json:"activatedAt" jsonschema:"required"`
+	Canary      *optimizer.CanaryReport `json:"canary,omitempty"` // Absent for the version built at startup
+}
+
+// OptimizerVersionsResponse represents the optimizer versions
+type OptimizerVersionsResponse struct {
+	Active      OptimizerVersionInfo   `json:"active" jsonschema:"required"`
+	Previous    *OptimizerVersionInfo  `json:"previous,omitempty"`    // Target of a rollback
+	Building    *int                   `json:"building,omitempty"`    // Version being built and validated
+	LastFailure *optimizer.FailedBuild `json:"lastFailure,omitempty"` // Last version rejected by its canary run
+}
+
+// GetOptimizerVersions returns the optimizer versions
+// @Summary Get optimizer versions
+// @Description Returns the optimizer version serving requests, the previous version kept for rollback, the version being built, if any, and the last version rejected by its canary run
+// @Tags optimizer
+// @Accept json
+// @Produce json
+// @Success 200 {object} OptimizerVersionsResponse
+// @Router /internal/admin/optimizer/versions [get]
+func GetOptimizerVersions(c *gin.Context) {
+	status := optimizerRegistry.Status()
+
+	response := OptimizerVersionsResponse{
+		Active:      newOptimizerVersionInfo(status.Active),
+		LastFailure: status.LastFailure,
+	}
+	if status.Previous != nil {
+		previous := newOptimizerVersionInfo(status.Previous)
+		response.Previous = &previous
+	}
+	if status.Building != 0 {
+		response.Building = &status.Building
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SetOptimizerConfig builds a new optimizer version
+// @Summary Set optimizer config
+// @Description Builds a new optimizer version from the active version's settings with the given ones changed. The version is built in the background and validated by running the last 20 served baskets through it and the active version: it is activated only if it serves every basket the active version serves and takes at most twice as long. Requests in flight finish on the version they started on. The replaced version is kept for rollback.
+// @Tags optimizer
+// @Accept json
+// @Produce json
+// @Param request body SetOptimizerConfigRequest true "Settings to change"
+// @Success 202 {object} SetOptimizerConfigResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 409 {object} map[string]string "A version is already being built"
+// @Router /internal/admin/optimizer/config [put]
+func SetOptimizerConfig(c *gin.Context) {
+	var req SetOptimizerConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	config := applyOptimizerSettings(optimizerRegistry.Active().Config, &req)
+	version, err := optimizerRegistry.StageAsync(config)
+	if errors.Is(err, optimizer.ErrBuildInProgress) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SetOptimizerConfigResponse{Version: version, Status: "building"})
+}
+
+// RollbackOptimizer reactivates the previous optimizer version
+// @Summary Roll back optimizer version
+// @Description Swaps the previous optimizer version back in without rebuilding it. The replaced version becomes the previous one, so rolling back again restores it.
+// @Tags optimizer
+// @Accept json
+// @Produce json
+// @Success 200 {object} OptimizerVersionInfo
+// @Failure 409 {object} map[string]string "No previous version"
+// @Router /internal/admin/optimizer/rollback [post]
+func RollbackOptimizer(c *gin.Context) {
+	restored, err := optimizerRegistry.Rollback()
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newOptimizerVersionInfo(restored))
+}
+
+// applyOptimizerSettings returns a copy of base with the request's settings
+// applied. Slices and maps are replaced, never modified, so base is untouched.
+func applyOptimizerSettings(base *optimizer.OptimizerConfig, req *SetOptimizerConfigRequest) *optimizer.OptimizerConfig {
+	config := *base
+
+	if req.TopCheapestStores != nil {
+		config.TopCheapestStores = *req.TopCheapestStores
+	}
+	if req.TopNearestStores != nil {
+		config.TopNearestStores = *req.TopNearestStores
+	}
+	if req.MaxCandidates != nil {
+		config.MaxCandidates = *req.MaxCandidates
+	}
+	if req.MaxDistanceKm != nil {
+		config.MaxDistanceKm = *req.MaxDistanceKm
+	}
+	if req.OptimalTimeoutMs != nil {
+		config.OptimalTimeoutMs = *req.OptimalTimeoutMs
+	}
+	if req.MaxBasketItems != nil {
+		config.MaxBasketItems = *req.MaxBasketItems
+	}
+	if req.MinBasketItems != nil {
+		config.MinBasketItems = *req.MinBasketItems
+	}
+	if req.MissingItemPenaltyMult != nil {
+		config.MissingItemPenaltyMult = *req.MissingItemPenaltyMult
+	}
+	if req.MissingItemFallback != nil {
+		config.MissingItemFallback = *req.MissingItemFallback
+	}
+	if req.MissingItemPenaltyStrategy != nil {
+		config.MissingItemPenaltyStrategy = *req.MissingItemPenaltyStrategy
+	}
+	if req.MissingItemCategoryPenalties != nil {
+		config.MissingItemCategoryPenalties = req.MissingItemCategoryPenalties
+	}
+	if req.CoverageBins != nil {
+		config.CoverageBins = req.CoverageBins
+	}
+	if req.BalancedDistanceCostPerKm != nil {
+		config.BalancedDistanceCostPerKm = *req.BalancedDistanceCostPerKm
+	}
+	if req.TravelCostPerKm != nil {
+		config.TravelCostPerKm = *req.TravelCostPerKm
+	}
+	if req.ExtraStoreCost != nil {
+		config.ExtraStoreCost = *req.ExtraStoreCost
+	}
+	return &config
+}
+
+// newOptimizerVersionInfo describes an optimizer version
+func newOptimizerVersionInfo(v *optimizer.OptimizerVersion) OptimizerVersionInfo {
+	return OptimizerVersionInfo{
+		Version: v.Version,
+		Settings: OptimizerSettings{
+			TopCheapestStores:            v.Config.TopCheapestStores,
+			TopNearestStores:             v.Config.TopNearestStores,
+			MaxCandidates:                v.Config.MaxCandidates,
+			MaxDistanceKm:                v.Config.MaxDistanceKm,
+			OptimalTimeoutMs:             v.Config.OptimalTimeoutMs,
+			MaxBasketItems:               v.Config.MaxBasketItems,
+			MinBasketItems:               v.Config.MinBasketItems,
+			MissingItemPenaltyMult:       v.Config.MissingItemPenaltyMult,
+			MissingItemFallback:          v.Config.MissingItemFallback,
+			MissingItemPenaltyStrategy:   v.Config.MissingItemPenaltyStrategy,
+			MissingItemCategoryPenalties: v.Config.MissingItemCategoryPenalties,
+			CoverageBins:                 v.Config.CoverageBins,
+			BalancedDistanceCostPerKm:    v.Config.BalancedDistanceCostPerKm,
+			TravelCostPerKm:              v.Config.TravelCostPerKm,
+			ExtraStoreCost:               v.Config.ExtraStoreCost,
+		},
+		BuiltAt:     v.BuiltAt,
+		ActivatedAt: v.ActivatedAt,
+		Canary:      v.Canary,
+	}
+}
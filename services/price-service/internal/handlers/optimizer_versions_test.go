@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/kosarica/price-service/internal/optimizer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyOptimizerSettings(t *testing.T) {
+	base := optimizer.DefaultOptimizerConfig()
+	strategy := optimizer.PenaltyStrategyMedian
+	maxCandidates := 30
+
+	config := applyOptimizerSettings(base, &SetOptimizerConfigRequest{
+		MaxCandidates:              &maxCandidates,
+		MissingItemPenaltyStrategy: &strategy,
+		CoverageBins:               []float64{1.0, 0.95, 0.85},
+	})
+
+	assert.Equal(t, 30, config.MaxCandidates)
+	assert.Equal(t, optimizer.PenaltyStrategyMedian, config.MissingItemPenaltyStrategy)
+	assert.Equal(t, []float64{1.0, 0.95, 0.85}, config.CoverageBins)
+	// Omitted settings keep the base value
+	assert.Equal(t, base.TopCheapestStores, config.TopCheapestStores)
+	assert.Equal(t, base.OptimalTimeoutMs, config.OptimalTimeoutMs)
+
+	// The base config is untouched
+	assert.Equal(t, 20, base.MaxCandidates)
+	assert.Equal(t, optimizer.PenaltyStrategyMean, base.MissingItemPenaltyStrategy)
+	assert.Equal(t, []float64{1.0, 0.9, 0.8}, base.CoverageBins)
+}
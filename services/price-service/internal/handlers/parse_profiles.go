@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/jobs"
+)
+
+// GetParseProfilesRequest represents query parameters for parser profiles
+type GetParseProfilesRequest struct {
+	ChainSlug string `form:"chainSlug" json:"chainSlug"`
+	Days      int    `form:"days" json:"days" binding:"omitempty,min=1,max=90" jsonschema:"minimum=1,maximum=90"`
+}
+
+// GetParseProfilesResponse represents aggregated parser profiles per chain
+type GetParseProfilesResponse struct {
+	From     time.Time                `json:"from" jsonschema:"required"`
+	To       time.Time                `json:"to" jsonschema:"required"`
+	Profiles []jobs.ParseProfileStats `json:"profiles" jsonschema:"required"`
+}
+
+// GetParseProfileReportRequest represents query parameters for the parser
+// performance report
+type GetParseProfileReportRequest struct {
+	Threshold float64 `form:"threshold" json:"threshold" binding:"omitempty,gt=0,lte=10" jsonschema:"exclusiveMinimum=0,maximum=10"`
+}
+
+// GetParseProfiles returns parse CPU time, allocations and throughput per chain
+// @Summary Get parser profiles
+// @Description Returns parse CPU time, allocation counts and rows/sec per chain, aggregated from the file metadata of profiled parses. Parses are profiled for chains with the parse_profiling feature flag on.
+// @Tags ingestion
+// @Produce json
+// @Param chainSlug query string false "Filter by chain slug"
+// @Param days query int false "Number of days to include" default(7) minimum(1) maximum(90)
+// @Success 200 {object} GetParseProfilesResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/ingestion/parse-profiles [get]
+func GetParseProfiles(c *gin.Context) {
+	var req GetParseProfilesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Days == 0 {
+		req.Days = 7
+	}
+
+	to := time.Now().UTC()
+	from := to.AddDate(0, 0, -req.Days)
+
+	profiles, err := jobs.QueryParseProfileStats(c.Request.Context(), database.Pool(), from, to, req.ChainSlug)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to fetch parse profiles")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch parse profiles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, GetParseProfilesResponse{From: from, To: to, Profiles: profiles})
+}
+
+// GetParseProfileReport returns the weekly parser performance report
+// @Summary Get parser performance report
+// @Description Compares each chain's profiled parses over the last 7 days with the 7 days before and flags chains whose rows/sec dropped, or CPU or allocations per row grew, by more than the threshold. Regressed chains are listed first.
+// @Tags ingestion
+// @Produce json
+// @Param threshold query number false "Relative change that counts as a regression" default(0.2)
+// @Success 200 {object} jobs.ParseProfileReport
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/ingestion/parse-profiles/report [get]
+func GetParseProfileReport(c *gin.Context) {
+	var req GetParseProfileReportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Threshold == 0 {
+		req.Threshold = jobs.DefaultParseRegressionThreshold
+	}
+
+	report, err := jobs.BuildParseProfileReport(c.Request.Context(), database.Pool(), time.Now().UTC(), req.Threshold)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to build parse profile report")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build parse profile report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
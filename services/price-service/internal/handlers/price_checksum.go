@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StorePriceChecksumResponse represents the checksum of a store's price set
+type StorePriceChecksumResponse struct {
+	ChainSlug string    `json:"chainSlug" jsonschema:"required"`
+	StoreID   string    `json:"storeId" jsonschema:"required"`
+	Checksum  string    `json:"checksum" jsonschema:"required"`
+	ItemCount int       `json:"itemCount" jsonschema:"required"`
+	LoadedAt  time.Time `json:"loadedAt" jsonschema:"required"`
+}
+
+// GetStorePriceChecksum returns a checksum of a store's current prices
+// @Summary Get store price checksum
+// @Description Returns a hash of a store's current price set in the price cache, with its item count and when the chain's prices were loaded. The hash changes only when the store's prices, discounts or assortment do, so clients holding a price list can compare it before downloading the full list again.
+// @Tags prices
+// @Accept json
+// @Produce json
+// @Param chainSlug path string true "Chain slug identifier"
+// @Param storeId path string true "Store ID"
+// @Success 200 {object} StorePriceChecksumResponse
+// @Failure 404 {object} map[string]string "Chain not cached or store not found"
+// @Failure 503 {object} map[string]string "Cache not initialized"
+// @Router /internal/prices/{chainSlug}/{storeId}/checksum [get]
+func GetStorePriceChecksum(c *gin.Context) {
+	chainSlug := c.Param("chainSlug")
+	storeID := c.Param("storeId")
+
+	if priceCache == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Cache not initialized"})
+		return
+	}
+
+	if _, loaded := priceCache.ChainFreshness(chainSlug); !loaded {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chain not cached"})
+		return
+	}
+
+	sum, ok := priceCache.StoreChecksum(chainSlug, storeID)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Store not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, StorePriceChecksumResponse{
+		ChainSlug: chainSlug,
+		StoreID:   storeID,
+		Checksum:  sum.Checksum,
+		ItemCount: sum.ItemCount,
+		LoadedAt:  sum.LoadedAt.UTC(),
+	})
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/kosarica/price-service/internal/database"
+)
+
+// errInvalidPriceVersion is returned for a malformed or future version token
+var errInvalidPriceVersion = errors.New("invalid version")
+
+// GetStorePriceDeltaRequest represents query parameters for a store price delta
+type GetStorePriceDeltaRequest struct {
+	Since string `form:"since" json:"since"` // Version of the prices the client holds; empty returns every price
+}
+
+// StorePriceChange is the current price of an item changed since a version
+type StorePriceChange struct {
+	RetailerItemID string `json:"retailerItemId" jsonschema:"required"`
+	Price          int    `json:"price" jsonschema:"required"`
+	DiscountPrice  *int   `json:"discountPrice"`
+	UnitPrice      *int   `json:"unitPrice"`
+	AnchorPrice    *int   `json:"anchorPrice"`
+	IsException    bool   `json:"isException" jsonschema:"required"`
+}
+
+// GetStorePriceDeltaResponse represents the change of a store's prices
+type GetStorePriceDeltaResponse struct {
+	ChainSlug string             `json:"chainSlug" jsonschema:"required"`
+	StoreID   string             `json:"storeId" jsonschema:"required"`
+	Version   string             `json:"version" jsonschema:"required"`
+	Full      bool               `json:"full" jsonschema:"required"`
+	Changed   []StorePriceChange `json:"changed" jsonschema:"required"`
+	Removed   []string           `json:"removed" jsonschema:"required"`
+}
+
+// GetStorePriceDelta returns the prices of a store changed since a version
+// @Summary Get store price delta
+// @Description Returns the current prices of a store's items that changed since a version, from price group transitions and exceptions created or expired since, and the items it no longer prices. Pass the returned version as since on the next sync. Without since, or when since is older than 7 days or predates the store's price group, every price is returned with full set and the client should replace its list.
+// @Tags prices
+// @Accept json
+// @Produce json
+// @Param chainSlug path string true "Chain slug identifier"
+// @Param storeId path string true "Store ID"
+// @Param since query string false "Version returned by the previous delta"
+// @Success 200 {object} GetStorePriceDeltaResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 404 {object} map[string]string "Store not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/prices/{chainSlug}/{storeId}/delta [get]
+func GetStorePriceDelta(c *gin.Context) {
+	chainSlug := c.Param("chainSlug")
+	storeID := c.Param("storeId")
+
+	var req GetStorePriceDeltaRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	since, err := parsePriceVersion(req.Since, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version"})
+		return
+	}
+
+	delta, err := database.GetStorePriceDelta(c.Request.Context(), chainSlug, storeID, since)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Store not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute price delta"})
+		return
+	}
+
+	changed := make([]StorePriceChange, len(delta.Changed))
+	for i, p := range delta.Changed {
+		changed[i] = StorePriceChange{
+			RetailerItemID: p.RetailerItemID,
+			Price:          p.Price,
+			DiscountPrice:  p.DiscountPrice,
+			UnitPrice:      p.UnitPrice,
+			AnchorPrice:    p.AnchorPrice,
+			IsException:    p.IsException,
+		}
+	}
+
+	c.JSON(http.StatusOK, GetStorePriceDeltaResponse{
+		ChainSlug: chainSlug,
+		StoreID:   storeID,
+		Version:   formatPriceVersion(delta.Version),
+		Full:      delta.Full,
+		Changed:   changed,
+		Removed:   delta.Removed,
+	})
+}
+
+// formatPriceVersion encodes a price version as Unix milliseconds
+func formatPriceVersion(t time.Time) string {
+	return strconv.FormatInt(t.UnixMilli(), 10)
+}
+
+// parsePriceVersion decodes a version from formatPriceVersion. An empty
+// version is the zero time; one after now was not issued by this service.
+func parsePriceVersion(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	ms, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || ms <= 0 {
+		return time.Time{}, errInvalidPriceVersion
+	}
+	t := time.UnixMilli(ms)
+	if t.After(now) {
+		return time.Time{}, errInvalidPriceVersion
+	}
+	return t, nil
+}
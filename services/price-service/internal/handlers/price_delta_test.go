@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePriceVersion(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	version := formatPriceVersion(now.Add(-time.Hour))
+	since, err := parsePriceVersion(version, now)
+	require.NoError(t, err)
+	assert.True(t, since.Equal(now.Add(-time.Hour)))
+
+	since, err = parsePriceVersion("", now)
+	require.NoError(t, err)
+	assert.True(t, since.IsZero(), "no version asks for every price")
+
+	for _, invalid := range []string{"abc", "-5", "0", formatPriceVersion(now.Add(time.Minute))} {
+		_, err := parsePriceVersion(invalid, now)
+		assert.ErrorIs(t, err, errInvalidPriceVersion, invalid)
+	}
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/kosarica/price-service/internal/database"
+)
+
+const (
+	// priceHistoryDayLayout is the format of price history days
+	priceHistoryDayLayout = "2006-01-02"
+	// defaultPriceHistoryDays is the range returned without from
+	defaultPriceHistoryDays = 90
+	// maxPriceHistoryDays bounds the range of one request
+	maxPriceHistoryDays = 730
+)
+
+// GetPriceHistoryRequest represents query parameters for an item's price history
+type GetPriceHistoryRequest struct {
+	From string `form:"from" json:"from"` // YYYY-MM-DD; defaults to 90 days before to
+	To   string `form:"to" json:"to"`     // YYYY-MM-DD; defaults to today
+}
+
+// PriceHistoryPoint is an item's price from a day until the next point
+type PriceHistoryPoint struct {
+	Date          string `json:"date" jsonschema:"required"`
+	Price         int    `json:"price" jsonschema:"required"`
+	DiscountPrice *int   `json:"discountPrice"`
+	UnitPrice     *int   `json:"unitPrice"`
+}
+
+// GetPriceHistoryResponse represents an item's daily price series at a store
+type GetPriceHistoryResponse struct {
+	ChainSlug      string              `json:"chainSlug" jsonschema:"required"`
+	StoreID        string              `json:"storeId" jsonschema:"required"`
+	RetailerItemID string              `json:"retailerItemId" jsonschema:"required"`
+	From           string              `json:"from" jsonschema:"required"`
+	To             string              `json:"to" jsonschema:"required"`
+	Points         []PriceHistoryPoint `json:"points" jsonschema:"required"`
+}
+
+// GetPriceHistory returns an item's price history at a store
+// @Summary Get item price history
+// @Description Returns an item's daily price series at a store between from and to, inclusive. A point is recorded for the day the item was first seen at the store and for every day its price or discount changed, holding the day's last price; the price stays the same until the next point. The first point may predate from: it is the price in effect at from. At most 730 days per request.
+// @Tags prices
+// @Accept json
+// @Produce json
+// @Param chainSlug path string true "Chain slug identifier"
+// @Param storeId path string true "Store ID"
+// @Param itemId path string true "Retailer item ID"
+// @Param from query string false "First day, YYYY-MM-DD (default 90 days before to)"
+// @Param to query string false "Last day, YYYY-MM-DD (default today)"
+// @Success 200 {object} GetPriceHistoryResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 404 {object} map[string]string "Store not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/prices/{chainSlug}/{storeId}/{itemId}/history [get]
+func GetPriceHistory(c *gin.Context) {
+	chainSlug := c.Param("chainSlug")
+	storeID := c.Param("storeId")
+	itemID := c.Param("itemId")
+
+	var req GetPriceHistoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	from, to, err := parsePriceHistoryRange(req.From, req.To, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	history, err := database.GetPriceHistory(c.Request.Context(), chainSlug, storeID, itemID, from, to)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Store not found"})
+		return
+	}
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to fetch price history")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch price history"})
+		return
+	}
+
+	points := make([]PriceHistoryPoint, len(history))
+	for i, p := range history {
+		points[i] = PriceHistoryPoint{
+			Date:          p.Day.Format(priceHistoryDayLayout),
+			Price:         p.Price,
+			DiscountPrice: p.DiscountPrice,
+			UnitPrice:     p.UnitPrice,
+		}
+	}
+
+	c.JSON(http.StatusOK, GetPriceHistoryResponse{
+		ChainSlug:      chainSlug,
+		StoreID:        storeID,
+		RetailerItemID: itemID,
+		From:           from.Format(priceHistoryDayLayout),
+		To:             to.Format(priceHistoryDayLayout),
+		Points:         points,
+	})
+}
+
+// parsePriceHistoryRange parses the days of a price history request. to
+// defaults to now's day and from to defaultPriceHistoryDays before to.
+func parsePriceHistoryRange(fromParam, toParam string, now time.Time) (time.Time, time.Time, error) {
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if toParam != "" {
+		parsed, err := time.Parse(priceHistoryDayLayout, toParam)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid to, use YYYY-MM-DD")
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(0, 0, -defaultPriceHistoryDays)
+	if fromParam != "" {
+		parsed, err := time.Parse(priceHistoryDayLayout, fromParam)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid from, use YYYY-MM-DD")
+		}
+		from = parsed
+	}
+
+	if from.After(to) {
+		return time.Time{}, time.Time{}, errors.New("from must not be after to")
+	}
+	if to.Sub(from) > maxPriceHistoryDays*24*time.Hour {
+		return time.Time{}, time.Time{}, errors.New("at most 730 days of price history may be requested at once")
+	}
+	return from, to, nil
+}
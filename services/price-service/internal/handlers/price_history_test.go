@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePriceHistoryRange(t *testing.T) {
+	now := time.Date(2026, 3, 1, 18, 30, 0, 0, time.UTC)
+	day := func(s string) time.Time {
+		d, err := time.Parse(priceHistoryDayLayout, s)
+		require.NoError(t, err)
+		return d
+	}
+
+	from, to, err := parsePriceHistoryRange("", "", now)
+	require.NoError(t, err)
+	assert.Equal(t, day("2026-03-01"), to)
+	assert.Equal(t, day("2025-12-01"), from, "defaults to 90 days before to")
+
+	from, to, err = parsePriceHistoryRange("2026-01-10", "2026-02-01", now)
+	require.NoError(t, err)
+	assert.Equal(t, day("2026-01-10"), from)
+	assert.Equal(t, day("2026-02-01"), to)
+
+	from, to, err = parsePriceHistoryRange("", "2026-02-01", now)
+	require.NoError(t, err)
+	assert.Equal(t, day("2025-11-03"), from)
+	assert.Equal(t, day("2026-02-01"), to)
+
+	for _, invalid := range [][2]string{
+		{"2026-01-10T00:00:00Z", ""},
+		{"", "yesterday"},
+		{"2026-02-02", "2026-02-01"},
+		{"2023-01-01", "2026-02-01"},
+	} {
+		_, _, err := parsePriceHistoryRange(invalid[0], invalid[1], now)
+		assert.Error(t, err, invalid)
+	}
+}
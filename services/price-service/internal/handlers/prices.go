@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -8,49 +10,62 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
 	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/search"
+)
+
+// Default page sizes of the price and search endpoints
+const (
+	DefaultStorePricesLimit = 100
+	DefaultSearchLimit      = 20
 )
 
 // GetStorePricesRequest represents query parameters for getting store prices
 // Note: chainSlug and storeId come from URL path params, not query string
 type GetStorePricesRequest struct {
-	ChainSlug string `form:"chainSlug" json:"chainSlug"`
-	StoreID   string `form:"storeId" json:"storeId"`
-	Limit     int    `form:"limit" json:"limit" binding:"min=1,max=500" jsonschema:"minimum=1,maximum=500"`
-	Offset    int    `form:"offset" json:"offset" binding:"min=0" jsonschema:"minimum=0"`
+	ChainSlug string `form:"chainSlug" json:"chainSlug" proto:"1"`
+	StoreID   string `form:"storeId" json:"storeId" proto:"2"`
+	Limit     int    `form:"limit" json:"limit" binding:"min=1,max=500" jsonschema:"minimum=1,maximum=500" proto:"3"`
+	Offset    int    `form:"offset" json:"offset" binding:"min=0" jsonschema:"minimum=0" proto:"4"`
 }
 
 // StorePrice represents a price entry for a store
 type StorePrice struct {
-	RetailerItemID    string  `json:"retailerItemId" jsonschema:"required"`
-	ItemName          string  `json:"itemName" jsonschema:"required"`
-	ItemExternalID    *string `json:"itemExternalId"`
-	Brand             *string `json:"brand"`
-	Unit              *string `json:"unit"`
-	UnitQuantity      *string `json:"unitQuantity"`
-	CurrentPrice      *int    `json:"currentPrice"`
-	PreviousPrice     *int    `json:"previousPrice"`
-	DiscountPrice     *int    `json:"discountPrice"`
-	DiscountStart     *string `json:"discountStart"`
-	DiscountEnd       *string `json:"discountEnd"`
-	InStock           bool    `json:"inStock" jsonschema:"required"`
-	UnitPrice         *int    `json:"unitPrice"`
-	UnitPriceBaseQty  *string `json:"unitPriceBaseQuantity"`
-	UnitPriceBaseUnit *string `json:"unitPriceBaseUnit"`
-	LowestPrice30d    *int    `json:"lowestPrice30d"`
-	AnchorPrice       *int    `json:"anchorPrice"`
-	PriceSignature    *string `json:"priceSignature"`
-	LastSeenAt        string  `json:"lastSeenAt" jsonschema:"required"`
+	RetailerItemID    string  `json:"retailerItemId" jsonschema:"required" proto:"1"`
+	ItemName          string  `json:"itemName" jsonschema:"required" proto:"2"`
+	ItemExternalID    *string `json:"itemExternalId" proto:"3"`
+	Brand             *string `json:"brand" proto:"4"`
+	Unit              *string `json:"unit" proto:"5"`
+	UnitQuantity      *string `json:"unitQuantity" proto:"6"`
+	CurrentPrice      *int    `json:"currentPrice" proto:"7"`
+	PreviousPrice     *int    `json:"previousPrice" proto:"8"`
+	DiscountPrice     *int    `json:"discountPrice" proto:"9"`
+	DiscountStart     *string `json:"discountStart" proto:"10"`
+	DiscountEnd       *string `json:"discountEnd" proto:"11"`
+	InStock           bool    `json:"inStock" jsonschema:"required" proto:"12"`
+	UnitPrice         *int    `json:"unitPrice" proto:"13"`
+	UnitPriceBaseQty  *string `json:"unitPriceBaseQuantity" proto:"14"`
+	UnitPriceBaseUnit *string `json:"unitPriceBaseUnit" proto:"15"`
+	LowestPrice30d    *int    `json:"lowestPrice30d" proto:"16"`
+	AnchorPrice       *int    `json:"anchorPrice" proto:"17"`
+	PriceSignature    *string `json:"priceSignature" proto:"18"`
+	LastSeenAt        string  `json:"lastSeenAt" jsonschema:"required" proto:"19"`
+
+	// PriceSavings are the discount figures of the current price
+	PriceSavings `proto:"20"`
+
+	// Formatted holds display strings of the amounts, with ?includeFormatted=true
+	Formatted *FormattedStorePrice `json:"formatted,omitempty" proto:"23"`
 }
 
 // GetStorePricesResponse represents the response for store prices
 type GetStorePricesResponse struct {
-	Prices []StorePrice `json:"prices" jsonschema:"required"`
-	Total  int          `json:"total" jsonschema:"required"`
+	Prices []StorePrice `json:"prices" jsonschema:"required" proto:"1"`
+	Total  int          `json:"total" jsonschema:"required" proto:"2"`
 }
 
 // GetStorePrices returns prices for a specific store in a chain
 // @Summary Get store prices
-// @Description Returns paginated prices for a specific store in a chain
+// @Description Returns paginated prices for a specific store in a chain. Identical concurrent requests share one computed response.
 // @Tags prices
 // @Accept json
 // @Produce json
@@ -58,10 +73,12 @@ type GetStorePricesResponse struct {
 // @Param storeId path string true "Store ID"
 // @Param limit query int false "Number of items to return" default(100) minimum(1) maximum(500)
 // @Param offset query int false "Number of items to skip" default(0) minimum(0)
+// @Param includeFormatted query bool false "Add display strings of the amounts, e.g. 1,99 €, and the discount percentage"
 // @Success 200 {object} GetStorePricesResponse
 // @Failure 400 {object} map[string]string "Bad request"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /internal/prices/{chainSlug}/{storeId} [get]
+// @Router /api/v1/prices/{chainSlug}/{storeId} [get]
 func GetStorePrices(c *gin.Context) {
 	chainSlug := c.Param("chainSlug")
 	storeID := c.Param("storeId")
@@ -77,13 +94,48 @@ func GetStorePrices(c *gin.Context) {
 		return
 	}
 
+	req.ChainSlug = chainSlug
+	req.StoreID = storeID
+	resp, err := ServeStorePrices(c.Request.Context(), req, includeFormatted(c))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ServeStorePrices returns a page of a store's prices. The response is shared
+// with identical concurrent requests and must not be modified.
+func ServeStorePrices(ctx context.Context, req GetStorePricesRequest, withFormatted bool) (*GetStorePricesResponse, error) {
 	// Set defaults
 	if req.Limit == 0 {
-		req.Limit = 100
+		req.Limit = DefaultStorePricesLimit
+	}
+
+	key := storePricesKey(req.ChainSlug, req.StoreID, storePricesVersion(req.ChainSlug), req.Limit, req.Offset)
+	resp, err := coalesce(ctx, &storePricesFlight, "store_prices", key, func(ctx context.Context) (*GetStorePricesResponse, error) {
+		return queryStorePrices(ctx, req.ChainSlug, req.StoreID, req.Limit, req.Offset)
+	})
+	if err != nil {
+		return nil, requestError(http.StatusInternalServerError, "Failed to fetch prices")
+	}
+
+	// The coalesced response is shared, so display strings go on a copy
+	if withFormatted {
+		formatted := &GetStorePricesResponse{Prices: make([]StorePrice, len(resp.Prices)), Total: resp.Total}
+		for i, price := range resp.Prices {
+			price.Formatted = formatStorePrice(price)
+			formatted.Prices[i] = price
+		}
+		resp = formatted
 	}
+	return resp, nil
+}
 
+// queryStorePrices reads a page of a store's prices and their total count
+func queryStorePrices(ctx context.Context, chainSlug, storeID string, limit, offset int) (*GetStorePricesResponse, error) {
 	pool := database.Pool()
-	ctx := c.Request.Context()
 
 	// Get total count
 	var total int
@@ -96,8 +148,7 @@ func GetStorePrices(c *gin.Context) {
 	`, storeID, chainSlug).Scan(&total)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count prices"})
-		return
+		return nil, fmt.Errorf("failed to count prices: %w", err)
 	}
 
 	// Get prices with pagination
@@ -130,10 +181,9 @@ func GetStorePrices(c *gin.Context) {
 		LIMIT $3 OFFSET $4
 	`
 
-	rows, err := pool.Query(ctx, query, storeID, chainSlug, req.Limit, req.Offset)
+	rows, err := pool.Query(ctx, query, storeID, chainSlug, limit, offset)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch prices"})
-		return
+		return nil, fmt.Errorf("failed to fetch prices: %w", err)
 	}
 	defer rows.Close()
 
@@ -150,67 +200,278 @@ func GetStorePrices(c *gin.Context) {
 			&price.LastSeenAt,
 		)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan price"})
-			return
+			return nil, fmt.Errorf("failed to scan price: %w", err)
 		}
+		price.PriceSavings = storePriceSavings(price)
 		prices = append(prices, price)
 	}
 
-	if rows.Err() != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error iterating prices"})
-		return
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating prices: %w", err)
 	}
 
-	c.JSON(http.StatusOK, GetStorePricesResponse{
+	return &GetStorePricesResponse{
 		Prices: prices,
 		Total:  total,
-	})
+	}, nil
 }
 
 // SearchItemsRequest represents query parameters for searching items
 type SearchItemsRequest struct {
-	Query     string `form:"q" json:"q" binding:"required,min=3" jsonschema:"required,minLength=3"`
-	ChainSlug string `form:"chainSlug" json:"chainSlug"`
-	Limit     int    `form:"limit" json:"limit" binding:"min=1,max=100" jsonschema:"minimum=1,maximum=100"`
+	Query      string `form:"q" json:"q" binding:"required,min=3" jsonschema:"required,minLength=3" proto:"1"`
+	ChainSlug  string `form:"chainSlug" json:"chainSlug" proto:"2"`
+	Category   string `form:"category" json:"category" proto:"3"`
+	Brand      string `form:"brand" json:"brand" proto:"4"`
+	OnDiscount *bool  `form:"onDiscount" json:"onDiscount" proto:"5"`
+	Limit      int    `form:"limit" json:"limit" binding:"min=1,max=100" jsonschema:"minimum=1,maximum=100" proto:"6"`
 }
 
 // SearchItem represents a search result item
 type SearchItem struct {
-	ID           string  `json:"id" jsonschema:"required"`
-	ChainSlug    string  `json:"chainSlug" jsonschema:"required"`
-	ExternalID   *string `json:"externalId"`
-	Name         string  `json:"name" jsonschema:"required"`
-	Description  *string `json:"description"`
-	Brand        *string `json:"brand"`
-	Category     *string `json:"category"`
-	Subcategory  *string `json:"subcategory"`
-	Unit         *string `json:"unit"`
-	UnitQuantity *string `json:"unitQuantity"`
-	ImageURL     *string `json:"imageUrl"`
-	AvgPrice     *int    `json:"avgPrice"`                     // Average price across stores
-	StoreCount   int     `json:"storeCount" jsonschema:"required"` // Number of stores with this item
+	ID           string  `json:"id" jsonschema:"required" proto:"1"`
+	ChainSlug    string  `json:"chainSlug" jsonschema:"required" proto:"2"`
+	ExternalID   *string `json:"externalId" proto:"3"`
+	Name         string  `json:"name" jsonschema:"required" proto:"4"`
+	NameI18n     map[string]string `json:"nameI18n,omitempty" proto:"5"` // Names in other languages by ISO 639-1 code
+	Description  *string `json:"description" proto:"6"`
+	Brand        *string `json:"brand" proto:"7"`
+	Category     *string `json:"category" proto:"8"`
+	Subcategory  *string `json:"subcategory" proto:"9"`
+	Unit         *string `json:"unit" proto:"10"`
+	UnitQuantity *string `json:"unitQuantity" proto:"11"`
+	ImageURL     *string `json:"imageUrl" proto:"12"`
+	AvgPrice     *int    `json:"avgPrice" proto:"13"`                     // Average price across stores
+	StoreCount   int     `json:"storeCount" jsonschema:"required" proto:"14"` // Number of stores with this item
+
+	// PriceSavings are the discount figures of the average price against the
+	// average price paid, counting discounts active now, and the average
+	// anchor price
+	PriceSavings `proto:"15"`
+}
+
+// FacetCount is the number of matching items with a facet value
+type FacetCount struct {
+	Value string `json:"value" jsonschema:"required" proto:"1"`
+	Count int    `json:"count" jsonschema:"required" proto:"2"`
+}
+
+// DiscountFacet counts matching items on discount in at least one store and
+// the others
+type DiscountFacet struct {
+	Active   int `json:"active" jsonschema:"required" proto:"1"`
+	Inactive int `json:"inactive" jsonschema:"required" proto:"2"`
+}
+
+// SearchFacets are the counts of matching items per filter value. Each facet
+// applies every filter but its own, so the other values of a facet stay
+// selectable.
+type SearchFacets struct {
+	Chains     []FacetCount  `json:"chains" jsonschema:"required" proto:"1"`
+	Categories []FacetCount  `json:"categories" jsonschema:"required" proto:"2"`
+	Brands     []FacetCount  `json:"brands" jsonschema:"required" proto:"3"`
+	Discount   DiscountFacet `json:"discount" jsonschema:"required" proto:"4"`
 }
 
 // SearchItemsResponse represents the response for item search
 type SearchItemsResponse struct {
-	Items []SearchItem `json:"items" jsonschema:"required"`
-	Total int          `json:"total" jsonschema:"required"`
-	Query string       `json:"query" jsonschema:"required"`
+	Items  []SearchItem `json:"items" jsonschema:"required" proto:"1"`
+	Total  int          `json:"total" jsonschema:"required" proto:"2"`
+	Query  string       `json:"query" jsonschema:"required" proto:"3"`
+	Facets SearchFacets `json:"facets" jsonschema:"required" proto:"4"`
+	// Fuzzy is set when nothing matched the query as typed and the items are
+	// those within a few typos of it
+	Fuzzy bool `json:"fuzzy" proto:"5"`
+}
+
+// maxFacetValues caps the values returned per facet, most frequent first
+const maxFacetValues = 20
+
+// itemOnDiscount returns a condition true for a retailer_items row ri on
+// discount in at least one store right now
+func itemOnDiscount() string {
+	return `EXISTS (
+	SELECT 1 FROM store_item_state d
+	WHERE d.retailer_item_id = ri.id
+	  AND d.discount_price IS NOT NULL
+	  AND d.discount_price < d.current_price
+	  AND ` + database.DiscountActiveSQL("d", "NOW()") + `
+)`
+}
+
+// maxFuzzyCandidates caps the items most similar to a misspelled query that
+// are checked for edit distance
+const maxFuzzyCandidates = 200
+
+// searchFilters are the SQL conditions of an item search over retailer_items
+// ri, one per filter, with their arguments
+type searchFilters struct {
+	match      string
+	chain      string
+	category   string
+	brand      string
+	onDiscount string
+	args       []interface{}
+}
+
+// newSearchFilters builds the conditions of a search. Items match when their
+// folded name contains the folded query, or, given itemIDs, when they are one
+// of those. Filters that are not set are TRUE.
+func newSearchFilters(req SearchItemsRequest, itemIDs []string) searchFilters {
+	f := searchFilters{chain: "TRUE", category: "TRUE", brand: "TRUE", onDiscount: "TRUE"}
+	arg := func(v interface{}) string {
+		f.args = append(f.args, v)
+		return "$" + strconv.Itoa(len(f.args))
+	}
+
+	if itemIDs != nil {
+		f.match = "ri.id = ANY(" + arg(itemIDs) + ")"
+	} else {
+		f.match = "LENGTH(" + arg(req.Query) + ") >= 3 AND ri.search_name LIKE " + arg("%"+escapeLike(search.Fold(req.Query))+"%")
+	}
+	if req.ChainSlug != "" {
+		f.chain = "ri.chain_slug = " + arg(req.ChainSlug)
+	}
+	if req.Category != "" {
+		f.category = "ri.category = " + arg(req.Category)
+	}
+	if req.Brand != "" {
+		f.brand = "ri.brand = " + arg(req.Brand)
+	}
+	if req.OnDiscount != nil {
+		f.onDiscount = itemOnDiscount()
+		if !*req.OnDiscount {
+			f.onDiscount = "NOT " + itemOnDiscount()
+		}
+	}
+	return f
+}
+
+// where returns the conditions of all filters
+func (f searchFilters) where() string {
+	return f.match + " AND " + f.chain + " AND " + f.category + " AND " + f.brand + " AND " + f.onDiscount
+}
+
+// facetsQuery counts the total and every facet in a single query. The
+// matching items are computed once with a column per filter, and each facet
+// groups those passing the other filters.
+func (f searchFilters) facetsQuery() string {
+	facet := func(kind, column, filters string) string {
+		return `(SELECT '` + kind + `', ` + column + `, COUNT(*) FROM matched
+			WHERE ` + column + ` IS NOT NULL AND ` + filters + `
+			GROUP BY ` + column + `
+			ORDER BY COUNT(*) DESC, ` + column + `
+			LIMIT ` + strconv.Itoa(maxFacetValues) + `)`
+	}
+
+	return `
+		WITH matched AS (
+			SELECT ri.chain_slug, ri.category, ri.brand,
+			       ` + itemOnDiscount() + `::text AS discount,
+			       ` + f.chain + ` AS by_chain,
+			       ` + f.category + ` AS by_category,
+			       ` + f.brand + ` AS by_brand,
+			       ` + f.onDiscount + ` AS by_discount
+			FROM retailer_items ri
+			WHERE ` + f.match + `
+		)
+		SELECT 'total', NULL, COUNT(*) FROM matched
+		WHERE by_chain AND by_category AND by_brand AND by_discount
+		UNION ALL ` + facet("chain", "chain_slug", "by_category AND by_brand AND by_discount") + `
+		UNION ALL ` + facet("category", "category", "by_chain AND by_brand AND by_discount") + `
+		UNION ALL ` + facet("brand", "brand", "by_chain AND by_category AND by_discount") + `
+		UNION ALL ` + facet("discount", "discount", "by_chain AND by_category AND by_brand")
+}
+
+// fuzzyItemIDs returns the items whose name is within a few typos of a
+// query. Candidates are the names most similar by trigrams, checked for edit
+// distance word by word.
+func fuzzyItemIDs(ctx context.Context, query, chainSlug string) ([]string, error) {
+	folded := search.Fold(query)
+	if len([]rune(folded)) < search.MinFuzzyLength {
+		return nil, nil
+	}
+
+	rows, err := database.Pool().Query(ctx, `
+		SELECT ri.id, ri.search_name
+		FROM retailer_items ri
+		WHERE ($2 = '' OR ri.chain_slug = $2)
+		  AND $1 <% ri.search_name
+		ORDER BY $1 <<-> ri.search_name
+		LIMIT $3
+	`, folded, chainSlug, maxFuzzyCandidates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id, name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		if search.FuzzyMatch(folded, name) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, rows.Err()
+}
+
+// countSearchFacets returns the total and facet counts of a search in one
+// query
+func countSearchFacets(ctx context.Context, filters searchFilters) (int, SearchFacets, error) {
+	total := 0
+	facets := SearchFacets{Chains: []FacetCount{}, Categories: []FacetCount{}, Brands: []FacetCount{}}
+	rows, err := database.Pool().Query(ctx, filters.facetsQuery(), filters.args...)
+	if err != nil {
+		return 0, facets, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var kind string
+		var value *string
+		var count int
+		if err := rows.Scan(&kind, &value, &count); err != nil {
+			return 0, facets, err
+		}
+		switch kind {
+		case "total":
+			total = count
+		case "chain":
+			facets.Chains = append(facets.Chains, FacetCount{Value: *value, Count: count})
+		case "category":
+			facets.Categories = append(facets.Categories, FacetCount{Value: *value, Count: count})
+		case "brand":
+			facets.Brands = append(facets.Brands, FacetCount{Value: *value, Count: count})
+		case "discount":
+			if *value == "true" {
+				facets.Discount.Active = count
+			} else {
+				facets.Discount.Inactive = count
+			}
+		}
+	}
+	return total, facets, rows.Err()
 }
 
 // SearchItems searches for items by name
 // @Summary Search items
-// @Description Search for items by name with optional chain filter. Requires minimum 3 characters.
+// @Description Search for items by name with optional chain, category, brand and discount filters. Requires minimum 3 characters. Matching ignores case and Croatian diacritics and spelling variants, so "mljeko", "mlijeko" and "MLIJEKO" find the same items, and names chains publish in other languages (nameI18n) match too; when nothing matches, items within a few typos of the query are returned with fuzzy set. Facet counts per chain, category, brand and discount state are returned with the results; each facet applies every filter but its own, and at most 20 values are returned per facet, most frequent first.
 // @Tags items
 // @Accept json
 // @Produce json
 // @Param q query string true "Search query (min 3 chars)" minLength(3)
 // @Param chainSlug query string false "Filter by chain slug"
+// @Param category query string false "Filter by category"
+// @Param brand query string false "Filter by brand"
+// @Param onDiscount query bool false "Only items on discount in at least one store (true) or in none (false)"
 // @Param limit query int false "Number of items to return" default(20) minimum(1) maximum(100)
 // @Success 200 {object} SearchItemsResponse
 // @Failure 400 {object} map[string]string "Bad request"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /internal/items/search [get]
+// @Router /api/v1/items/search [get]
 func SearchItems(c *gin.Context) {
 	var req SearchItemsRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -218,34 +479,59 @@ func SearchItems(c *gin.Context) {
 		return
 	}
 
+	resp, err := ServeSearchItems(c.Request.Context(), req)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ServeSearchItems runs a validated item search; failures to report to the
+// caller are RequestErrors
+func ServeSearchItems(ctx context.Context, req SearchItemsRequest) (*SearchItemsResponse, error) {
 	// Validate minimum query length (IMPORTANT: prevents full table scan)
 	if len(req.Query) < 3 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Query must be at least 3 characters long",
-		})
-		return
+		return nil, requestError(http.StatusBadRequest, "Query must be at least 3 characters long")
 	}
 
 	// Set default limit
 	if req.Limit == 0 {
-		req.Limit = 20
+		req.Limit = DefaultSearchLimit
 	}
 
 	pool := database.Pool()
-	ctx := c.Request.Context()
 
-	// Build query with dynamic chain filter
-	countQuery := `
-		SELECT COUNT(DISTINCT ri.id)
-		FROM retailer_items ri
-		WHERE 1=1
-	`
+	filters := newSearchFilters(req, nil)
+	total, facets, err := countSearchFacets(ctx, filters)
+	if err != nil {
+		return nil, requestError(http.StatusInternalServerError, "Failed to count items")
+	}
+
+	// Nothing matched as typed: fall back to names within a few typos
+	fuzzy := false
+	if total == 0 {
+		ids, err := fuzzyItemIDs(ctx, req.Query, req.ChainSlug)
+		if err != nil {
+			return nil, requestError(http.StatusInternalServerError, "Failed to search items")
+		}
+		if len(ids) > 0 {
+			fuzzy = true
+			filters = newSearchFilters(req, ids)
+			total, facets, err = countSearchFacets(ctx, filters)
+			if err != nil {
+				return nil, requestError(http.StatusInternalServerError, "Failed to count items")
+			}
+		}
+	}
+
 	searchQuery := `
 		SELECT DISTINCT
 			ri.id,
 			ri.chain_slug,
 			ri.external_id,
 			ri.name,
+			ri.name_i18n,
 			ri.description,
 			ri.brand,
 			ri.category,
@@ -254,75 +540,59 @@ func SearchItems(c *gin.Context) {
 			ri.unit_quantity,
 			ri.image_url,
 			AVG(sis.current_price) as avg_price,
-			COUNT(DISTINCT sis.store_id) as store_count
+			COUNT(DISTINCT sis.store_id) as store_count,
+			ROUND(AVG(CASE
+				WHEN sis.discount_price IS NOT NULL
+				  AND ` + database.DiscountActiveSQL("sis", "NOW()") + `
+				THEN LEAST(sis.discount_price, sis.current_price)
+				ELSE sis.current_price
+			END))::bigint as avg_paid_price,
+			ROUND(AVG(sis.anchor_price))::bigint as avg_anchor_price
 		FROM retailer_items ri
 		LEFT JOIN store_item_state sis ON ri.id = sis.retailer_item_id
-		WHERE 1=1
-	`
-
-	args := []interface{}{}
-	argIdx := 1
-
-	if req.ChainSlug != "" {
-		countQuery += " AND ri.chain_slug = $" + strconv.Itoa(argIdx)
-		searchQuery += " AND ri.chain_slug = $" + strconv.Itoa(argIdx)
-		args = append(args, req.ChainSlug)
-		argIdx++
-	}
-
-	// Add search term with ILIKE
-	countQuery += " AND LENGTH($" + strconv.Itoa(argIdx) + ") >= 3 AND ri.name ILIKE $" + strconv.Itoa(argIdx+1)
-	searchQuery += " AND LENGTH($" + strconv.Itoa(argIdx) + ") >= 3 AND ri.name ILIKE $" + strconv.Itoa(argIdx+1)
-	args = append(args, req.Query, "%"+req.Query+"%")
-	argIdx += 2
-
-	searchQuery += " GROUP BY ri.id, ri.chain_slug, ri.external_id, ri.name, ri.description, ri.brand, ri.category, ri.subcategory, ri.unit, ri.unit_quantity, ri.image_url"
-	searchQuery += " ORDER BY ri.name"
-	searchQuery += " LIMIT $" + strconv.Itoa(argIdx)
-	args = append(args, req.Limit)
-
-	// Get total count
-	var total int
-	err := pool.QueryRow(ctx, countQuery, args[:len(args)-1]...).Scan(&total)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count items"})
-		return
-	}
+		WHERE ` + filters.where() + `
+		GROUP BY ri.id, ri.chain_slug, ri.external_id, ri.name, ri.name_i18n, ri.description, ri.brand, ri.category, ri.subcategory, ri.unit, ri.unit_quantity, ri.image_url
+		ORDER BY ri.name
+		LIMIT $` + strconv.Itoa(len(filters.args)+1)
+	args := append(filters.args, req.Limit)
 
 	// Search items
 	rows, err := pool.Query(ctx, searchQuery, args...)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search items"})
-		return
+		return nil, requestError(http.StatusInternalServerError, "Failed to search items")
 	}
 	defer rows.Close()
 
 	items := []SearchItem{}
 	for rows.Next() {
 		var item SearchItem
+		var avgPaidPrice, avgAnchorPrice *int64
 		err := rows.Scan(
-			&item.ID, &item.ChainSlug, &item.ExternalID, &item.Name,
+			&item.ID, &item.ChainSlug, &item.ExternalID, &item.Name, &item.NameI18n,
 			&item.Description, &item.Brand, &item.Category, &item.Subcategory,
 			&item.Unit, &item.UnitQuantity, &item.ImageURL,
-			&item.AvgPrice, &item.StoreCount,
+			&item.AvgPrice, &item.StoreCount, &avgPaidPrice, &avgAnchorPrice,
 		)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan item"})
-			return
+			return nil, requestError(http.StatusInternalServerError, "Failed to scan item")
+		}
+		if item.AvgPrice != nil {
+			item.PriceSavings = newPriceSavings(int64(*item.AvgPrice), avgPaidPrice, avgAnchorPrice)
 		}
 		items = append(items, item)
 	}
 
 	if rows.Err() != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error iterating items"})
-		return
+		return nil, requestError(http.StatusInternalServerError, "Error iterating items")
 	}
 
-	c.JSON(http.StatusOK, SearchItemsResponse{
+	return &SearchItemsResponse{
 		Items:  items,
 		Total:  total,
 		Query:  req.Query,
-	})
+		Facets: facets,
+		Fuzzy:  fuzzy,
+	}, nil
 }
 
 // ============================================================================
@@ -369,7 +639,7 @@ func GetStorePricesViaGroup(c *gin.Context) {
 			itemName = "Unknown Item"
 		}
 
-		enrichedPrices = append(enrichedPrices, StorePrice{
+		enriched := StorePrice{
 			RetailerItemID: price.RetailerItemID,
 			ItemName:       itemName,
 			ItemExternalID: itemExternalID,
@@ -380,7 +650,9 @@ func GetStorePricesViaGroup(c *gin.Context) {
 			DiscountPrice:  price.DiscountPrice,
 			UnitPrice:      price.UnitPrice,
 			AnchorPrice:    price.AnchorPrice,
-		})
+		}
+		enriched.PriceSavings = storePriceSavings(enriched)
+		enrichedPrices = append(enrichedPrices, enriched)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
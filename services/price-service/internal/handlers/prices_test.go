@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSearchFilters verifies unset filters match everything and set ones are
+// numbered after the search term
+func TestSearchFilters(t *testing.T) {
+	onDiscount := false
+	f := newSearchFilters(SearchItemsRequest{Query: "Mlijeko", Brand: "Dukat", OnDiscount: &onDiscount}, nil)
+
+	assert.Equal(t, []interface{}{"Mlijeko", "%mljeko%", "Dukat"}, f.args)
+	assert.Equal(t, "TRUE", f.chain)
+	assert.Equal(t, "TRUE", f.category)
+	assert.Equal(t, "ri.brand = $3", f.brand)
+	assert.True(t, strings.HasPrefix(f.onDiscount, "NOT EXISTS"))
+
+	// Each facet leaves out its own filter
+	query := f.facetsQuery()
+	assert.Contains(t, query, "WHERE brand IS NOT NULL AND by_chain AND by_category AND by_discount")
+	assert.Contains(t, query, "WHERE discount IS NOT NULL AND by_chain AND by_category AND by_brand")
+
+	// The fuzzy fallback matches the given items instead
+	f = newSearchFilters(SearchItemsRequest{Query: "mljko", ChainSlug: "konzum"}, []string{"ri_1"})
+	assert.Equal(t, []interface{}{[]string{"ri_1"}, "konzum"}, f.args)
+	assert.Equal(t, "ri.id = ANY($1)", f.match)
+	assert.Equal(t, "ri.chain_slug = $2", f.chain)
+}
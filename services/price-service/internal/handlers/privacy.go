@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/privacy"
+)
+
+// UserDataExport represents all stored data of a user reference
+type UserDataExport struct {
+	UserRef             string                        `json:"userRef" jsonschema:"required"`
+	ExportedAt          time.Time                     `json:"exportedAt" jsonschema:"required"`
+	OptimizationResults []database.OptimizationResult `json:"optimizationResults" jsonschema:"required"`
+}
+
+// DeleteUserDataResponse represents the result of deleting a user's data
+type DeleteUserDataResponse struct {
+	UserRef                    string `json:"userRef" jsonschema:"required"`
+	DeletedOptimizationResults int64  `json:"deletedOptimizationResults" jsonschema:"required"`
+}
+
+// ExportUserData returns all stored data of a user reference
+// @Summary Export user data
+// @Description Returns every stored record keyed by a caller-provided user reference (currently basket optimization results), for data subject access requests.
+// @Tags privacy
+// @Produce json
+// @Param userRef path string true "Caller-provided user reference"
+// @Success 200 {object} UserDataExport
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/privacy/users/{userRef} [get]
+func ExportUserData(c *gin.Context) {
+	userRef := c.Param("userRef")
+
+	results, err := database.ListOptimizationResultsByUserRef(c.Request.Context(), userRef)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to export user data")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export user data"})
+		return
+	}
+
+	c.JSON(http.StatusOK, UserDataExport{
+		UserRef:             userRef,
+		ExportedAt:          time.Now().UTC(),
+		OptimizationResults: results,
+	})
+}
+
+// DeleteUserData deletes all stored data of a user reference
+// @Summary Delete user data
+// @Description Deletes every stored record keyed by a caller-provided user reference, for erasure requests. Deleting an unknown reference succeeds with zero counts.
+// @Tags privacy
+// @Produce json
+// @Param userRef path string true "Caller-provided user reference"
+// @Success 200 {object} DeleteUserDataResponse
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/privacy/users/{userRef} [delete]
+func DeleteUserData(c *gin.Context) {
+	userRef := c.Param("userRef")
+
+	deleted, err := database.DeleteOptimizationResultsByUserRef(c.Request.Context(), userRef)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to delete user data")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user data"})
+		return
+	}
+
+	c.JSON(http.StatusOK, DeleteUserDataResponse{
+		UserRef:                    userRef,
+		DeletedOptimizationResults: deleted,
+	})
+}
+
+// logOptimization logs an optimization request with its location rounded
+func logOptimization(kind string, req *OptimizeRequest) {
+	event := logger.Debug().
+		Str("kind", kind).
+		Str("chain", req.ChainSlug).
+		Int("items", len(req.BasketItems))
+	if req.Location != nil {
+		event = privacy.LogLocation(event, req.Location.Latitude, req.Location.Longitude)
+	}
+	event.Msg("Basket optimized")
+}
+
+// storeOptimizationResult stores the result of a request that carries a user
+// reference. The stored request keeps only rounded coordinates. Failures are
+// logged and never fail the request.
+func storeOptimizationResult(ctx context.Context, kind string, req *OptimizeRequest, result any) {
+	if req.UserRef == "" || database.Pool() == nil {
+		return
+	}
+
+	stored := *req
+	record := &database.OptimizationResult{
+		UserRef:   req.UserRef,
+		Kind:      kind,
+		ChainSlug: req.ChainSlug,
+	}
+	if req.Location != nil {
+		lat, lon := privacy.RoundLocation(req.Location.Latitude, req.Location.Longitude)
+		stored.Location = &Location{Latitude: lat, Longitude: lon}
+		record.Latitude = &lat
+		record.Longitude = &lon
+	}
+
+	var err error
+	if record.Request, err = json.Marshal(stored); err == nil {
+		record.Result, err = json.Marshal(result)
+	}
+	if err == nil {
+		err = database.CreateOptimizationResult(ctx, record)
+	}
+	if err != nil {
+		logger.Error().Err(err).Str("kind", kind).Msg("Failed to store optimization result")
+	}
+}
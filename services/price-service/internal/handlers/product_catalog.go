@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/matching"
+)
+
+// MergeProductsRequest represents the request to merge duplicates into a product
+type MergeProductsRequest struct {
+	ProductIDs []string `json:"productIds" binding:"required,min=1,max=50,dive,required" jsonschema:"required,minItems=1,maxItems=50"`
+	MergedBy   string   `json:"mergedBy" binding:"required" jsonschema:"required"`
+	Reason     string   `json:"reason"`
+}
+
+// MergeProductsResponse represents the response of a product merge
+type MergeProductsResponse struct {
+	ProductID       string   `json:"productId" jsonschema:"required"`
+	MergedIDs       []string `json:"mergedIds" jsonschema:"required"`
+	RetailerItemIDs []string `json:"retailerItemIds" jsonschema:"required"`
+	Barcodes        int      `json:"barcodes" jsonschema:"required"`
+	Aliases         int      `json:"aliases" jsonschema:"required"`
+}
+
+// SplitProductRequest represents the request to detach items into a new product
+type SplitProductRequest struct {
+	RetailerItemIDs []string `json:"retailerItemIds" binding:"required,min=1,max=500,dive,required" jsonschema:"required,minItems=1,maxItems=500"`
+	Name            string   `json:"name" binding:"max=500" jsonschema:"maxLength=500"`
+	SplitBy         string   `json:"splitBy" binding:"required" jsonschema:"required"`
+	Reason          string   `json:"reason"`
+}
+
+// SplitProductResponse represents the response of a product split
+type SplitProductResponse struct {
+	ProductID       string   `json:"productId" jsonschema:"required"`
+	NewProductID    string   `json:"newProductId" jsonschema:"required"`
+	RetailerItemIDs []string `json:"retailerItemIds" jsonschema:"required"`
+	Barcodes        int      `json:"barcodes" jsonschema:"required"`
+}
+
+// MergeProducts merges duplicate products into a product
+// @Summary Merge products
+// @Description Merges duplicate products into the product in one transaction. Their retailer item links, canonical barcodes, aliases, relations, match candidates, rejections and reviews move to the product, their names become its aliases, and they are deleted. The merge is logged in product_catalog_audit with the deleted rows, and a catalog.products.merged outbox event tells caches and search indexes to drop them.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param productId path string true "Product to merge into"
+// @Param request body MergeProductsRequest true "Duplicates to merge, who merges them and why"
+// @Success 200 {object} MergeProductsResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 404 {object} map[string]string "Product not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/products/{productId}/merge [post]
+func MergeProducts(c *gin.Context) {
+	productID := c.Param("productId")
+
+	var req MergeProductsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	slices.Sort(req.ProductIDs)
+	req.ProductIDs = slices.Compact(req.ProductIDs)
+
+	result, err := matching.MergeProducts(c.Request.Context(), database.Pool(), productID, req.ProductIDs, req.MergedBy, req.Reason)
+	if err != nil {
+		writeCatalogError(c, err, "Failed to merge products")
+		return
+	}
+
+	c.JSON(http.StatusOK, MergeProductsResponse{
+		ProductID:       result.ProductID,
+		MergedIDs:       result.MergedIDs,
+		RetailerItemIDs: result.RetailerItemIDs,
+		Barcodes:        result.Barcodes,
+		Aliases:         result.Aliases,
+	})
+}
+
+// SplitProduct detaches retailer items of a product into a new product
+// @Summary Split product
+// @Description Moves some of a product's linked retailer items to a new product, described by the first of them and named name if given, in one transaction. Canonical barcodes carried only by the moved items move with them. At least one item must stay with the product. The split is logged in product_catalog_audit, and a catalog.product.split outbox event tells caches and search indexes to refresh both products.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param productId path string true "Product to split"
+// @Param request body SplitProductRequest true "Items to detach, who splits them and why"
+// @Success 200 {object} SplitProductResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 404 {object} map[string]string "Product not found"
+// @Failure 409 {object} map[string]string "Items are not some of the product's items"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/products/{productId}/split [post]
+func SplitProduct(c *gin.Context) {
+	productID := c.Param("productId")
+
+	var req SplitProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Dedupe keeping order, as the first item describes the new product
+	itemIDs := make([]string, 0, len(req.RetailerItemIDs))
+	for _, id := range req.RetailerItemIDs {
+		if !slices.Contains(itemIDs, id) {
+			itemIDs = append(itemIDs, id)
+		}
+	}
+
+	result, err := matching.SplitProduct(c.Request.Context(), database.Pool(), productID, itemIDs, req.Name, req.SplitBy, req.Reason)
+	if err != nil {
+		writeCatalogError(c, err, "Failed to split product")
+		return
+	}
+
+	c.JSON(http.StatusOK, SplitProductResponse{
+		ProductID:       result.ProductID,
+		NewProductID:    result.NewProductID,
+		RetailerItemIDs: result.RetailerItemIDs,
+		Barcodes:        result.Barcodes,
+	})
+}
+
+// writeCatalogError maps a merge or split error to its response
+func writeCatalogError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, matching.ErrMergeIntoSelf):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot merge a product into itself"})
+	case errors.Is(err, matching.ErrProductNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+	case errors.Is(err, matching.ErrItemsNotLinked):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
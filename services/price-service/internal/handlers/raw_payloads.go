@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/kosarica/price-service/internal/database"
+)
+
+// Response headers describing how a raw payload was stored
+const (
+	RawEncodingHeader       = "X-Raw-Encoding"
+	RawCompressedSizeHeader = "X-Raw-Compressed-Size"
+)
+
+// GetRawPayload returns a decompressed raw source row
+// @Summary Get raw payload
+// @Description Returns a raw source row stored compressed out of line. The body is the original row, served as JSON when it is valid JSON.
+// @Tags ingestion
+// @Produce json
+// @Param id path string true "Raw payload ID"
+// @Success 200 {object} map[string]interface{} "Original raw row"
+// @Failure 404 {object} map[string]string "Raw payload not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/raw-payloads/{id} [get]
+func GetRawPayload(c *gin.Context) {
+	payload, err := database.GetRawPayload(c.Request.Context(), c.Param("id"))
+	writeRawPayload(c, payload, err)
+}
+
+// GetFailedRowRawData returns the raw source row of a failed row
+// @Summary Get raw data of a failed row
+// @Description Lazily loads the raw source row of a failed row, whether it is stored inline or as a compressed raw payload.
+// @Tags ingestion
+// @Produce json
+// @Param id path string true "Failed row ID"
+// @Success 200 {object} map[string]interface{} "Original raw row"
+// @Failure 404 {object} map[string]string "Failed row or raw data not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/failed-rows/{id}/raw [get]
+func GetFailedRowRawData(c *gin.Context) {
+	payload, err := database.GetFailedRowRawPayload(c.Request.Context(), c.Param("id"))
+	writeRawPayload(c, payload, err)
+}
+
+// writeRawPayload writes a raw payload as the response body
+func writeRawPayload(c *gin.Context, payload *database.RawPayload, err error) {
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Raw data not found"})
+		return
+	}
+	if err != nil {
+		logger.Error().Err(err).Str("id", c.Param("id")).Msg("Failed to load raw payload")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load raw data"})
+		return
+	}
+
+	c.Header(RawEncodingHeader, payload.Encoding)
+	if payload.CompressedSize > 0 {
+		c.Header(RawCompressedSizeHeader, strconv.Itoa(payload.CompressedSize))
+	}
+
+	contentType := "text/plain; charset=utf-8"
+	if json.Valid(payload.Data) {
+		contentType = "application/json; charset=utf-8"
+	}
+	c.Data(http.StatusOK, contentType, payload.Data)
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestError is a failure of a request served by the Serve* functions, with
+// the HTTP status it is answered with. Other transports, e.g. gRPC, map the
+// status to their own codes.
+type RequestError struct {
+	Status  int
+	Message string
+	// Code is the catalog key of a localized message, for clients to tell
+	// errors apart without matching their text
+	Code string
+	// RetryAfter is set when the request may be retried after a while
+	RetryAfter time.Duration
+}
+
+func (e *RequestError) Error() string {
+	return e.Message
+}
+
+// requestError returns a RequestError with a status and message
+func requestError(status int, message string) *RequestError {
+	return &RequestError{Status: status, Message: message}
+}
+
+// writeError writes err as the JSON error response. Errors other than
+// RequestError are answered with 500.
+func writeError(c *gin.Context, err error) {
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if reqErr.RetryAfter > 0 {
+		setRetryAfter(c, reqErr.RetryAfter)
+	}
+	if reqErr.Code != "" {
+		c.JSON(reqErr.Status, gin.H{"error": reqErr.Message, "code": reqErr.Code})
+		return
+	}
+	c.JSON(reqErr.Status, gin.H{"error": reqErr.Message})
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/kosarica/price-service/internal/database"
+)
+
+// maxLineageDepth bounds how far lineage queries follow parent_run_id, so a
+// corrupt cycle cannot make them recurse forever
+const maxLineageDepth = 100
+
+// RunLineageNode is a run in a rerun tree
+type RunLineageNode struct {
+	ID            string           `json:"id" jsonschema:"required"`
+	ParentRunID   *string          `json:"parentRunId"`
+	Status        string           `json:"status" jsonschema:"required,enum=pending,enum=running,enum=completed,enum=failed"`
+	Source        string           `json:"source" jsonschema:"required"`
+	RerunType     *string          `json:"rerunType" jsonschema:"enum=file,enum=chunk,enum=entry"`
+	RerunTargetID *string          `json:"rerunTargetId"`
+	CreatedAt     time.Time        `json:"createdAt" jsonschema:"required"`
+	Children      []RunLineageNode `json:"children" jsonschema:"required"`
+}
+
+// RunLineageResponse is the full rerun tree a run belongs to
+type RunLineageResponse struct {
+	RunID     string         `json:"runId" jsonschema:"required"`
+	RootRunID string         `json:"rootRunId" jsonschema:"required"`
+	Tree      RunLineageNode `json:"tree" jsonschema:"required"`
+}
+
+// GetRunLineage returns the rerun tree a run belongs to
+// @Summary Get run lineage
+// @Description Returns the full rerun tree containing a run: the original run at the root and every rerun below the run it reruns, oldest first
+// @Tags ingestion
+// @Accept json
+// @Produce json
+// @Param runId path string true "Run ID"
+// @Success 200 {object} RunLineageResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 404 {object} map[string]string "Run not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/ingestion/runs/{runId}/lineage [get]
+func GetRunLineage(c *gin.Context) {
+	runID := c.Param("runId")
+	if runID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "runId is required"})
+		return
+	}
+
+	pool := database.Pool()
+	ctx := c.Request.Context()
+
+	// Walk up to the original run
+	var rootID string
+	err := pool.QueryRow(ctx, `
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_run_id, 0 AS depth
+			FROM ingestion_runs
+			WHERE id = $1
+			UNION ALL
+			SELECT r.id, r.parent_run_id, a.depth + 1
+			FROM ingestion_runs r
+			JOIN ancestors a ON r.id = a.parent_run_id
+			WHERE a.depth < $2
+		)
+		SELECT id::text FROM ancestors ORDER BY depth DESC LIMIT 1
+	`, runID, maxLineageDepth).Scan(&rootID)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Run not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch run ancestors"})
+		return
+	}
+
+	// Load every rerun below it
+	rows, err := pool.Query(ctx, `
+		SELECT r.id::text, r.parent_run_id::text, r.status, r.source,
+		       r.rerun_type, r.rerun_target_id::text, r.created_at
+		FROM ingestion_runs r
+		WHERE r.id IN (`+descendantsQuery+`)
+		ORDER BY r.created_at, r.id
+	`, rootID, maxLineageDepth)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch run lineage"})
+		return
+	}
+	defer rows.Close()
+
+	var runs []RunLineageNode
+	for rows.Next() {
+		var node RunLineageNode
+		if err := rows.Scan(
+			&node.ID, &node.ParentRunID, &node.Status, &node.Source,
+			&node.RerunType, &node.RerunTargetID, &node.CreatedAt,
+		); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan run"})
+			return
+		}
+		runs = append(runs, node)
+	}
+	if rows.Err() != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error iterating runs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, RunLineageResponse{
+		RunID:     runID,
+		RootRunID: rootID,
+		Tree:      buildLineageTree(rootID, runs),
+	})
+}
+
+// descendantsQuery selects the IDs of run $1 and all its reruns, following
+// parent_run_id at most $2 levels down
+const descendantsQuery = `
+	WITH RECURSIVE tree AS (
+		SELECT id, 0 AS depth
+		FROM ingestion_runs
+		WHERE id = $1
+		UNION ALL
+		SELECT r.id, t.depth + 1
+		FROM ingestion_runs r
+		JOIN tree t ON r.parent_run_id = t.id
+		WHERE t.depth < $2
+	)
+	SELECT DISTINCT id FROM tree
+`
+
+// runDescendants returns the IDs of a run's reruns, their reruns and so on,
+// not including the run itself
+func runDescendants(ctx context.Context, tx pgx.Tx, runID string) ([]int64, error) {
+	rows, err := tx.Query(ctx, descendantsQuery, runID, maxLineageDepth)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, err
+	}
+
+	descendants := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if strconv.FormatInt(id, 10) != runID {
+			descendants = append(descendants, id)
+		}
+	}
+	return descendants, nil
+}
+
+// buildLineageTree nests runs under their parents, starting at rootID. runs
+// are expected in creation order, which children keep.
+func buildLineageTree(rootID string, runs []RunLineageNode) RunLineageNode {
+	byID := make(map[string]RunLineageNode, len(runs))
+	children := make(map[string][]string, len(runs))
+	for _, run := range runs {
+		byID[run.ID] = run
+		if run.ParentRunID != nil && run.ID != rootID {
+			children[*run.ParentRunID] = append(children[*run.ParentRunID], run.ID)
+		}
+	}
+
+	visited := make(map[string]bool, len(runs))
+	var build func(id string) RunLineageNode
+	build = func(id string) RunLineageNode {
+		visited[id] = true
+		node := byID[id]
+		node.ID = id
+		node.Children = []RunLineageNode{}
+		for _, childID := range children[id] {
+			if !visited[childID] {
+				node.Children = append(node.Children, build(childID))
+			}
+		}
+		return node
+	}
+	return build(rootID)
+}
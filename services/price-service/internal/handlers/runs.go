@@ -1,22 +1,30 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/kosarica/price-service/internal/chains"
 	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/jobs"
+	"github.com/kosarica/price-service/internal/pkg/clock"
 )
 
 // ListRunsRequest represents query parameters for listing ingestion runs
 type ListRunsRequest struct {
-	ChainSlug string `form:"chainSlug" json:"chainSlug"`
-	Status    string `form:"status" json:"status" jsonschema:"enum=pending,enum=running,enum=completed,enum=failed"`
-	Limit     int    `form:"limit" json:"limit" binding:"min=1,max=100" jsonschema:"minimum=1,maximum=100"`
-	Offset    int    `form:"offset" json:"offset" binding:"min=0" jsonschema:"minimum=0"`
+	ChainSlug      string `form:"chainSlug" json:"chainSlug"`
+	Status         string `form:"status" json:"status" jsonschema:"enum=pending,enum=running,enum=completed,enum=failed"`
+	StagingStatus  string `form:"stagingStatus" json:"stagingStatus" jsonschema:"enum=staging,enum=staged,enum=published,enum=rejected"`
+	BaselineStatus string `form:"baselineStatus" json:"baselineStatus" jsonschema:"enum=passed,enum=suspect,enum=cleared"`
+	Limit          int    `form:"limit" json:"limit" binding:"min=1,max=100" jsonschema:"minimum=1,maximum=100"`
+	Offset         int    `form:"offset" json:"offset" binding:"min=0" jsonschema:"minimum=0"`
 }
 
 // ListRunsResponse represents the response for listing ingestion runs
@@ -27,29 +35,73 @@ type ListRunsResponse struct {
 
 // IngestionRun represents an ingestion run response
 type IngestionRun struct {
-	ID               string     `json:"id" jsonschema:"required"`
-	ChainSlug        string     `json:"chainSlug" jsonschema:"required"`
-	Source           string     `json:"source" jsonschema:"required"`
-	Status           string     `json:"status" jsonschema:"required,enum=pending,enum=running,enum=completed,enum=failed"`
-	StartedAt        *time.Time `json:"startedAt"`
-	CompletedAt      *time.Time `json:"completedAt"`
-	TotalFiles       *int       `json:"totalFiles"`
-	ProcessedFiles   *int       `json:"processedFiles"`
-	TotalEntries     *int       `json:"totalEntries"`
-	ProcessedEntries *int       `json:"processedEntries"`
-	ErrorCount       *int       `json:"errorCount"`
-	Metadata         *string    `json:"metadata"`
-	CreatedAt        time.Time  `json:"createdAt" jsonschema:"required"`
+	ID                string     `json:"id" jsonschema:"required"`
+	ChainSlug         string     `json:"chainSlug" jsonschema:"required"`
+	Source            string     `json:"source" jsonschema:"required"`
+	Status            string     `json:"status" jsonschema:"required,enum=pending,enum=running,enum=completed,enum=failed"`
+	StartedAt         *time.Time `json:"startedAt"`
+	CompletedAt       *time.Time `json:"completedAt"`
+	TotalFiles        *int       `json:"totalFiles"`
+	ProcessedFiles    *int       `json:"processedFiles"`
+	TotalEntries      *int       `json:"totalEntries"`
+	ProcessedEntries  *int       `json:"processedEntries"`
+	ErrorCount        *int       `json:"errorCount"`
+	CompletionPercent *float64   `json:"completionPercent"`
+	Metadata          *string    `json:"metadata"`
+	ParentRunID       *string    `json:"parentRunId"`
+	RerunType         *string    `json:"rerunType" jsonschema:"enum=file,enum=chunk,enum=entry"`
+	RerunTargetID     *string    `json:"rerunTargetId"`
+	ChildRunIDs       []string   `json:"childRunIds" jsonschema:"required"`
+	StagingStatus     *string    `json:"stagingStatus" jsonschema:"enum=staging,enum=staged,enum=published,enum=rejected"`
+	QualityReport     *string    `json:"qualityReport"`
+	AveragePrice      *float64   `json:"averagePrice"`
+	BaselineStatus    *string    `json:"baselineStatus" jsonschema:"enum=passed,enum=suspect,enum=cleared"`
+	BaselineReport    *string    `json:"baselineReport"`
+	CreatedAt         time.Time  `json:"createdAt" jsonschema:"required"`
+}
+
+// runColumns are the ingestion_runs columns scanned into an IngestionRun,
+// including the IDs of the run's direct reruns
+const runColumns = `
+	id, chain_slug, source, status, started_at, completed_at,
+	total_files, processed_files, total_entries, processed_entries,
+	error_count,
+	COALESCE(completion_percent, processed_files * 100.0 / NULLIF(total_files, 0))::float8,
+	metadata, parent_run_id, rerun_type, rerun_target_id,
+	ARRAY(
+		SELECT child.id::text FROM ingestion_runs child
+		WHERE child.parent_run_id = ingestion_runs.id
+		ORDER BY child.created_at
+	),
+	staging_status, quality_report,
+	average_price, baseline_status, baseline_report,
+	created_at
+`
+
+// scanRun scans a row selected with runColumns
+func scanRun(row pgx.Row) (IngestionRun, error) {
+	var run IngestionRun
+	err := row.Scan(
+		&run.ID, &run.ChainSlug, &run.Source, &run.Status,
+		&run.StartedAt, &run.CompletedAt, &run.TotalFiles, &run.ProcessedFiles,
+		&run.TotalEntries, &run.ProcessedEntries, &run.ErrorCount,
+		&run.CompletionPercent, &run.Metadata, &run.ParentRunID, &run.RerunType, &run.RerunTargetID,
+		&run.ChildRunIDs, &run.StagingStatus, &run.QualityReport,
+		&run.AveragePrice, &run.BaselineStatus, &run.BaselineReport, &run.CreatedAt,
+	)
+	return run, err
 }
 
 // ListRuns returns a paginated list of ingestion runs with optional filters
 // @Summary List ingestion runs
-// @Description Returns a paginated list of ingestion runs with optional chain and status filters
+// @Description Returns a paginated list of ingestion runs with optional chain and status filters. Each run links to the run it reruns (parentRunId) and to its own reruns (childRunIds).
 // @Tags ingestion
 // @Accept json
 // @Produce json
 // @Param chainSlug query string false "Filter by chain slug"
 // @Param status query string false "Filter by status" Enums(pending, running, completed, failed)
+// @Param stagingStatus query string false "Filter by staging status; staged lists runs awaiting review" Enums(staging, staged, published, rejected)
+// @Param baselineStatus query string false "Filter by baseline status; suspect lists runs holding cache refresh" Enums(passed, suspect, cleared)
 // @Param limit query int false "Number of items to return" default(20) minimum(1) maximum(100)
 // @Param offset query int false "Number of items to skip" default(0) minimum(0)
 // @Success 200 {object} ListRunsResponse
@@ -72,13 +124,7 @@ func ListRuns(c *gin.Context) {
 	ctx := c.Request.Context()
 
 	// Build query with dynamic filters
-	query := `
-		SELECT id, chain_slug, source, status, started_at, completed_at,
-		       total_files, processed_files, total_entries, processed_entries,
-		       error_count, metadata, created_at
-		FROM ingestion_runs
-		WHERE 1=1
-	`
+	query := `SELECT ` + runColumns + ` FROM ingestion_runs WHERE 1=1`
 	args := []interface{}{}
 	argIdx := 1
 
@@ -94,6 +140,18 @@ func ListRuns(c *gin.Context) {
 		argIdx++
 	}
 
+	if req.StagingStatus != "" {
+		query += fmt.Sprintf(" AND staging_status = $%d", argIdx)
+		args = append(args, req.StagingStatus)
+		argIdx++
+	}
+
+	if req.BaselineStatus != "" {
+		query += fmt.Sprintf(" AND baseline_status = $%d", argIdx)
+		args = append(args, req.BaselineStatus)
+		argIdx++
+	}
+
 	// Get total count
 	countQuery := "SELECT COUNT(*) FROM ingestion_runs WHERE 1=1"
 	countArgs := []interface{}{}
@@ -111,6 +169,18 @@ func ListRuns(c *gin.Context) {
 		countArgIdx++
 	}
 
+	if req.StagingStatus != "" {
+		countQuery += fmt.Sprintf(" AND staging_status = $%d", countArgIdx)
+		countArgs = append(countArgs, req.StagingStatus)
+		countArgIdx++
+	}
+
+	if req.BaselineStatus != "" {
+		countQuery += fmt.Sprintf(" AND baseline_status = $%d", countArgIdx)
+		countArgs = append(countArgs, req.BaselineStatus)
+		countArgIdx++
+	}
+
 	var total int
 	err := pool.QueryRow(ctx, countQuery, countArgs...).Scan(&total)
 	if err != nil {
@@ -132,13 +202,7 @@ func ListRuns(c *gin.Context) {
 
 	runs := []IngestionRun{}
 	for rows.Next() {
-		var run IngestionRun
-		err := rows.Scan(
-			&run.ID, &run.ChainSlug, &run.Source, &run.Status,
-			&run.StartedAt, &run.CompletedAt, &run.TotalFiles, &run.ProcessedFiles,
-			&run.TotalEntries, &run.ProcessedEntries, &run.ErrorCount,
-			&run.Metadata, &run.CreatedAt,
-		)
+		run, err := scanRun(rows)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan run"})
 			return
@@ -159,7 +223,7 @@ func ListRuns(c *gin.Context) {
 
 // GetRun returns a single ingestion run by ID
 // @Summary Get ingestion run
-// @Description Returns a single ingestion run by its ID
+// @Description Returns a single ingestion run by its ID. completionPercent is the share of files completed or deduplicated, reconciled from the run's files when it completes and estimated from processedFiles/totalFiles before that. The run links to the run it reruns (parentRunId) and to its own reruns (childRunIds)
 // @Tags ingestion
 // @Accept json
 // @Produce json
@@ -179,21 +243,7 @@ func GetRun(c *gin.Context) {
 	pool := database.Pool()
 	ctx := c.Request.Context()
 
-	query := `
-		SELECT id, chain_slug, source, status, started_at, completed_at,
-		       total_files, processed_files, total_entries, processed_entries,
-		       error_count, metadata, created_at
-		FROM ingestion_runs
-		WHERE id = $1
-	`
-
-	var run IngestionRun
-	err := pool.QueryRow(ctx, query, runID).Scan(
-		&run.ID, &run.ChainSlug, &run.Source, &run.Status,
-		&run.StartedAt, &run.CompletedAt, &run.TotalFiles, &run.ProcessedFiles,
-		&run.TotalEntries, &run.ProcessedEntries, &run.ErrorCount,
-		&run.Metadata, &run.CreatedAt,
-	)
+	run, err := scanRun(pool.QueryRow(ctx, `SELECT `+runColumns+` FROM ingestion_runs WHERE id = $1`, runID))
 
 	if err == pgx.ErrNoRows {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Run not found"})
@@ -221,25 +271,54 @@ type ListFilesResponse struct {
 
 // IngestionFile represents an ingestion file response
 type IngestionFile struct {
-	ID              *string    `json:"id"`
-	RunID           string     `json:"runId" jsonschema:"required"`
-	Filename        string     `json:"filename" jsonschema:"required"`
-	FileType        string     `json:"fileType" jsonschema:"required"`
-	FileSize        *int       `json:"fileSize"`
-	FileHash        *string    `json:"fileHash"`
-	Status          string     `json:"status" jsonschema:"required,enum=pending,enum=processing,enum=completed,enum=failed"`
-	EntryCount      *int       `json:"entryCount"`
-	ProcessedAt     *time.Time `json:"processedAt"`
-	Metadata        *string    `json:"metadata"`
-	TotalChunks     *int       `json:"totalChunks"`
-	ProcessedChunks *int       `json:"processedChunks"`
-	ChunkSize       *int       `json:"chunkSize"`
-	CreatedAt       time.Time  `json:"createdAt" jsonschema:"required"`
+	ID                *string              `json:"id"`
+	RunID             string               `json:"runId" jsonschema:"required"`
+	Filename          string               `json:"filename" jsonschema:"required"`
+	FileType          string               `json:"fileType" jsonschema:"required"`
+	FileSize          *int                 `json:"fileSize"`
+	FileHash          *string              `json:"fileHash"`
+	Status            string               `json:"status" jsonschema:"required,enum=pending,enum=downloading,enum=parsing,enum=persisting,enum=completed,enum=failed,enum=skipped"`
+	DiscrepancyReason *string              `json:"discrepancyReason" jsonschema:"enum=skipped,enum=deduplicated,enum=failed"`
+	EntryCount        *int                 `json:"entryCount"`
+	ProcessedAt       *time.Time           `json:"processedAt"`
+	Metadata          *string              `json:"metadata"`
+	TotalChunks       *int                 `json:"totalChunks"`
+	ProcessedChunks   *int                 `json:"processedChunks"`
+	ChunkSize         *int                 `json:"chunkSize"`
+	WarningCount      int                  `json:"warningCount" jsonschema:"required"`
+	ParserVersion     *string              `json:"parserVersion"`
+	CreatedAt         time.Time            `json:"createdAt" jsonschema:"required"`
+	StatusTimestamps  map[string]time.Time `json:"statusTimestamps" jsonschema:"required"`
+	StageDurationsMs  map[string]int64     `json:"stageDurationsMs" jsonschema:"required"`
+}
+
+// fileStages are the statuses an ingestion file passes through in order,
+// ending in one of the terminal statuses
+var fileStages = []string{"pending", "downloading", "parsing", "persisting", "completed", "failed", "skipped"}
+
+// fileStageDurations returns how long a file spent in each stage it has
+// left, in milliseconds, from the time it entered each status. A stage the
+// file is still in has no duration yet.
+func fileStageDurations(timestamps map[string]time.Time) map[string]int64 {
+	durations := make(map[string]int64)
+	for i, stage := range fileStages {
+		start, ok := timestamps[stage]
+		if !ok {
+			continue
+		}
+		for _, next := range fileStages[i+1:] {
+			if end, ok := timestamps[next]; ok {
+				durations[stage] = end.Sub(start).Milliseconds()
+				break
+			}
+		}
+	}
+	return durations
 }
 
 // ListFiles returns a paginated list of files for a run
 // @Summary List ingestion files
-// @Description Returns a paginated list of files for a specific ingestion run
+// @Description Returns a paginated list of files for a specific ingestion run. Files move from pending through downloading, parsing and persisting to completed, failed or skipped; statusTimestamps records when each status was entered and stageDurationsMs how long each finished stage took. Files that did not count as processed carry a discrepancyReason: skipped, deduplicated or failed. parserVersion is the version of the chain adapter's parser that parsed the file.
 // @Tags ingestion
 // @Accept json
 // @Produce json
@@ -282,8 +361,8 @@ func ListFiles(c *gin.Context) {
 	// Get files with pagination
 	query := `
 		SELECT id, run_id, filename, file_type, file_size, file_hash, status,
-		       entry_count, processed_at, metadata, total_chunks, processed_chunks,
-		       chunk_size, created_at
+		       discrepancy_reason, entry_count, processed_at, metadata, total_chunks, processed_chunks,
+		       chunk_size, warning_count, parser_version, created_at, status_timestamps
 		FROM ingestion_files
 		WHERE run_id = $1
 		ORDER BY created_at DESC
@@ -302,14 +381,18 @@ func ListFiles(c *gin.Context) {
 		var file IngestionFile
 		err := rows.Scan(
 			&file.ID, &file.RunID, &file.Filename, &file.FileType, &file.FileSize,
-			&file.FileHash, &file.Status, &file.EntryCount, &file.ProcessedAt,
+			&file.FileHash, &file.Status, &file.DiscrepancyReason, &file.EntryCount, &file.ProcessedAt,
 			&file.Metadata, &file.TotalChunks, &file.ProcessedChunks,
-			&file.ChunkSize, &file.CreatedAt,
+			&file.ChunkSize, &file.WarningCount, &file.ParserVersion, &file.CreatedAt, &file.StatusTimestamps,
 		)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan file"})
 			return
 		}
+		if file.StatusTimestamps == nil {
+			file.StatusTimestamps = map[string]time.Time{}
+		}
+		file.StageDurationsMs = fileStageDurations(file.StatusTimestamps)
 		files = append(files, file)
 	}
 
@@ -326,8 +409,9 @@ func ListFiles(c *gin.Context) {
 
 // ListErrorsRequest represents query parameters for listing ingestion errors
 type ListErrorsRequest struct {
-	Limit  int `form:"limit" json:"limit" binding:"min=1,max=100" jsonschema:"minimum=1,maximum=100"`
-	Offset int `form:"offset" json:"offset" binding:"min=0" jsonschema:"minimum=0"`
+	Limit    int    `form:"limit" json:"limit" binding:"min=1,max=100" jsonschema:"minimum=1,maximum=100"`
+	Offset   int    `form:"offset" json:"offset" binding:"min=0" jsonschema:"minimum=0"`
+	Severity string `form:"severity" json:"severity" binding:"omitempty,oneof=warning error critical" jsonschema:"enum=warning,enum=error,enum=critical"`
 }
 
 // ListErrorsResponse represents the response for listing ingestion errors
@@ -352,13 +436,14 @@ type IngestionError struct {
 
 // ListErrors returns a paginated list of errors for a run
 // @Summary List ingestion errors
-// @Description Returns a paginated list of errors for a specific ingestion run
+// @Description Returns a paginated list of errors and aggregated parse warnings for a specific ingestion run
 // @Tags ingestion
 // @Accept json
 // @Produce json
 // @Param runId path string true "Run ID"
 // @Param limit query int false "Number of items to return" default(50) minimum(1) maximum(100)
 // @Param offset query int false "Number of items to skip" default(0) minimum(0)
+// @Param severity query string false "Only return entries of this severity" Enums(warning, error, critical)
 // @Success 200 {object} ListErrorsResponse
 // @Failure 400 {object} map[string]string "Bad request"
 // @Failure 500 {object} map[string]string "Internal server error"
@@ -386,7 +471,10 @@ func ListErrors(c *gin.Context) {
 
 	// Get total count
 	var total int
-	err := pool.QueryRow(ctx, "SELECT COUNT(*) FROM ingestion_errors WHERE run_id = $1", runID).Scan(&total)
+	err := pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM ingestion_errors
+		WHERE run_id = $1 AND ($2 = '' OR severity = $2)
+	`, runID, req.Severity).Scan(&total)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count errors"})
 		return
@@ -397,12 +485,12 @@ func ListErrors(c *gin.Context) {
 		SELECT id, run_id, file_id, chunk_id, entry_id, error_type, error_message,
 		       error_details, severity, created_at
 		FROM ingestion_errors
-		WHERE run_id = $1
+		WHERE run_id = $1 AND ($2 = '' OR severity = $2)
 		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
+		LIMIT $3 OFFSET $4
 	`
 
-	rows, err := pool.Query(ctx, query, runID, req.Limit, req.Offset)
+	rows, err := pool.Query(ctx, query, runID, req.Severity, req.Limit, req.Offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch errors"})
 		return
@@ -439,6 +527,8 @@ func ListErrors(c *gin.Context) {
 type GetStatsRequest struct {
 	From string `form:"from" json:"from" binding:"required" jsonschema:"required"`
 	To   string `form:"to" json:"to" binding:"required" jsonschema:"required"`
+	// GroupBy optionally splits the buckets per chain; only "chain" is supported
+	GroupBy string `form:"groupBy" json:"groupBy" binding:"omitempty,oneof=chain" jsonschema:"enum=chain"`
 }
 
 // StatsBucket represents a single time bucket in stats
@@ -451,21 +541,37 @@ type StatsBucket struct {
 	Pending     int    `json:"pending" jsonschema:"required"`
 	TotalFiles  int    `json:"totalFiles" jsonschema:"required"`
 	TotalErrors int    `json:"totalErrors" jsonschema:"required"`
+
+	// Duration and throughput aggregates over finished runs in the bucket.
+	// Nil when no run in the bucket has both started_at and completed_at.
+	MedianDurationSeconds *float64 `json:"medianDurationSeconds"`
+	P95DurationSeconds    *float64 `json:"p95DurationSeconds"`
+	FilesPerHour          *float64 `json:"filesPerHour"`
+	RowsPersisted         int      `json:"rowsPersisted" jsonschema:"required"`
+	PriceChanges          int      `json:"priceChanges" jsonschema:"required"`
 }
 
 // GetStatsResponse represents the response for ingestion stats
 type GetStatsResponse struct {
 	Buckets []StatsBucket `json:"buckets" jsonschema:"required"`
+	Chains  []ChainStats  `json:"chains,omitempty"` // Populated when groupBy=chain
+}
+
+// ChainStats holds the stats buckets for a single chain
+type ChainStats struct {
+	ChainSlug string        `json:"chainSlug" jsonschema:"required"`
+	Buckets   []StatsBucket `json:"buckets" jsonschema:"required"`
 }
 
 // GetStats returns aggregated statistics for a time range
 // @Summary Get ingestion stats
-// @Description Returns aggregated statistics for ingestion runs within a time range (24h/7d/30d buckets)
+// @Description Returns aggregated statistics for ingestion runs within a time range (24h/7d/30d buckets, the 7d and 30d ones in calendar days of the service's time zone), including run duration percentiles, files/hour throughput, rows persisted and price changes
 // @Tags ingestion
 // @Accept json
 // @Produce json
-// @Param from query string true "Start date (RFC3339 format)"
-// @Param to query string true "End date (RFC3339 format)"
+// @Param from query string true "Start (RFC3339, or YYYY-MM-DD for the start of that day in the service's time zone)"
+// @Param to query string true "End (RFC3339, or YYYY-MM-DD for the end of that day in the service's time zone)"
+// @Param groupBy query string false "Group buckets per chain; errors of deleted runs are grouped under the chain \"unknown\"" Enums(chain)
 // @Success 200 {object} GetStatsResponse
 // @Failure 400 {object} map[string]string "Bad request"
 // @Failure 500 {object} map[string]string "Internal server error"
@@ -478,80 +584,477 @@ func GetStats(c *gin.Context) {
 	}
 
 	// Parse dates
-	from, err := time.Parse(time.RFC3339, req.From)
+	from, err := parseStatsTime(req.From, false)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date format, use RFC3339"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date format, use RFC3339 or YYYY-MM-DD"})
 		return
 	}
 
-	to, err := time.Parse(time.RFC3339, req.To)
+	to, err := parseStatsTime(req.To, true)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date format, use RFC3339"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date format, use RFC3339 or YYYY-MM-DD"})
 		return
 	}
 
 	pool := database.Pool()
 	ctx := c.Request.Context()
 
+	groupByChain := req.GroupBy == "chain"
+
 	// Calculate 24h, 7d, 30d bucket boundaries from the "to" date
-	buckets := []StatsBucket{
-		{Label: "24h"},
-		{Label: "7d"},
-		{Label: "30d"},
-	}
-
-	for i := range buckets {
-		var bucketFrom time.Time
-		switch buckets[i].Label {
-		case "24h":
-			bucketFrom = to.Add(-24 * time.Hour)
-		case "7d":
-			bucketFrom = to.Add(-7 * 24 * time.Hour)
-		case "30d":
-			bucketFrom = to.Add(-30 * 24 * time.Hour)
-		}
+	labels := []string{"24h", "7d", "30d"}
+	buckets := make([]StatsBucket, 0, len(labels))
+	chainBuckets := make(map[string][]StatsBucket)
+
+	for _, label := range labels {
+		bucketFrom := statsBucketFrom(label, to)
 
 		// Clamp to from date
 		if bucketFrom.Before(from) {
 			bucketFrom = from
 		}
 
-		// Get run counts by status
-		query := `
-			SELECT
-				COUNT(*) as total_runs,
-				COUNT(*) FILTER (WHERE status = 'completed') as completed,
-				COUNT(*) FILTER (WHERE status = 'failed') as failed,
-				COUNT(*) FILTER (WHERE status = 'running') as running,
-				COUNT(*) FILTER (WHERE status = 'pending') as pending,
-				COALESCE(SUM(total_files), 0) as total_files
-			FROM ingestion_runs
-			WHERE created_at >= $1 AND created_at <= $2
-		`
-
-		err := pool.QueryRow(ctx, query, bucketFrom, to).Scan(
-			&buckets[i].TotalRuns, &buckets[i].Completed, &buckets[i].Failed,
-			&buckets[i].Running, &buckets[i].Pending, &buckets[i].TotalFiles,
-		)
+		totals, err := queryStatsBuckets(ctx, pool, label, bucketFrom, to, false)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch stats"})
 			return
 		}
+		bucket, ok := totals[""]
+		if !ok {
+			bucket = &StatsBucket{Label: label}
+		}
+		buckets = append(buckets, *bucket)
+
+		if !groupByChain {
+			continue
+		}
 
-		// Get error count
-		err = pool.QueryRow(ctx, `
-			SELECT COUNT(*)
-			FROM ingestion_errors
-			WHERE created_at >= $1 AND created_at <= $2
-		`, bucketFrom, to).Scan(&buckets[i].TotalErrors)
+		byChain, err := queryStatsBuckets(ctx, pool, label, bucketFrom, to, true)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch error stats"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch chain stats"})
 			return
 		}
+		for slug, bucket := range byChain {
+			chainBuckets[slug] = append(chainBuckets[slug], *bucket)
+		}
+	}
+
+	// Every chain gets all three buckets, zero-filled where it had no runs
+	slugs := make([]string, 0, len(chainBuckets))
+	for slug := range chainBuckets {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+
+	chainStats := make([]ChainStats, 0, len(slugs))
+	for _, slug := range slugs {
+		byLabel := make(map[string]StatsBucket, len(chainBuckets[slug]))
+		for _, bucket := range chainBuckets[slug] {
+			byLabel[bucket.Label] = bucket
+		}
+
+		filled := make([]StatsBucket, 0, len(labels))
+		for _, label := range labels {
+			bucket, ok := byLabel[label]
+			if !ok {
+				bucket = StatsBucket{Label: label}
+			}
+			filled = append(filled, bucket)
+		}
+
+		chainStats = append(chainStats, ChainStats{
+			ChainSlug: slug,
+			Buckets:   filled,
+		})
 	}
 
 	c.JSON(http.StatusOK, GetStatsResponse{
 		Buckets: buckets,
+		Chains:  chainStats,
+	})
+}
+
+// statsAccumulator merges run metrics from hourly rollups and live queries
+type statsAccumulator struct {
+	bucket        StatsBucket
+	finishedFiles int
+	durationTotal float64
+	durations     []float64
+}
+
+// parseStatsTime parses a stats range bound: an RFC3339 time, or a date
+// meaning the start of that day in the service's time zone, or its end, the
+// next day's start, when end is set.
+func parseStatsTime(value string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	day, err := clock.ParseDate(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if end {
+		return clock.AddDays(day, 1), nil
+	}
+	return day, nil
+}
+
+// statsBucketFrom returns the start of a stats bucket ending at to. The 7d
+// and 30d buckets span calendar days in the service's time zone, starting at
+// the same wall clock time as to, so across a clock change they are an hour
+// longer or shorter than whole multiples of 24 hours.
+func statsBucketFrom(label string, to time.Time) time.Time {
+	switch label {
+	case "7d":
+		return clock.AddDays(to, -7)
+	case "30d":
+		return clock.AddDays(to, -30)
+	default:
+		return to.Add(-24 * time.Hour)
+	}
+}
+
+// unknownChainKey groups errors whose run no longer exists when stats are
+// grouped by chain, so the chains' errors still add up to the total. The
+// rollups group them the same way.
+const unknownChainKey = jobs.StatsUnknownChain
+
+// queryStatsBuckets computes bucket metrics for runs created in [bucketFrom, to].
+// Full hours already covered by the stats rollup job are read from
+// ingestion_stats_hourly; the partial hours at either edge and anything past
+// the rollup watermark are computed from ingestion_runs directly.
+// Results are keyed by chain slug, or by "" when groupByChain is false.
+func queryStatsBuckets(ctx context.Context, pool *pgxpool.Pool, label string, bucketFrom, to time.Time, groupByChain bool) (map[string]*StatsBucket, error) {
+	accs := make(map[string]*statsAccumulator)
+
+	var watermark *time.Time
+	err := pool.QueryRow(ctx, `
+		SELECT rolled_up_to FROM ingestion_stats_rollup_state WHERE id
+	`).Scan(&watermark)
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, fmt.Errorf("failed to fetch rollup watermark: %w", err)
+	}
+
+	rollupFrom := bucketFrom.Truncate(time.Hour)
+	if rollupFrom.Before(bucketFrom) {
+		rollupFrom = rollupFrom.Add(time.Hour)
+	}
+	rollupTo := to.Truncate(time.Hour)
+	if watermark == nil {
+		rollupTo = rollupFrom
+	} else if watermark.Before(rollupTo) {
+		rollupTo = *watermark
+	}
+
+	if rollupFrom.Before(rollupTo) {
+		if err := addRollupStats(ctx, pool, accs, rollupFrom, rollupTo, groupByChain); err != nil {
+			return nil, err
+		}
+		if err := addLiveStats(ctx, pool, accs, bucketFrom, rollupFrom, false, groupByChain); err != nil {
+			return nil, err
+		}
+		if err := addLiveStats(ctx, pool, accs, rollupTo, to, true, groupByChain); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := addLiveStats(ctx, pool, accs, bucketFrom, to, true, groupByChain); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make(map[string]*StatsBucket, len(accs))
+	for key, acc := range accs {
+		bucket := acc.bucket
+		bucket.Label = label
+		if len(acc.durations) > 0 {
+			sort.Float64s(acc.durations)
+			median := percentileCont(acc.durations, 0.5)
+			p95 := percentileCont(acc.durations, 0.95)
+			bucket.MedianDurationSeconds = &median
+			bucket.P95DurationSeconds = &p95
+		}
+		if acc.durationTotal > 0 {
+			filesPerHour := float64(acc.finishedFiles) * 3600 / acc.durationTotal
+			bucket.FilesPerHour = &filesPerHour
+		}
+		result[key] = &bucket
+	}
+
+	return result, nil
+}
+
+// accumulatorFor returns the accumulator for key, creating it if needed
+func accumulatorFor(accs map[string]*statsAccumulator, key string) *statsAccumulator {
+	acc, ok := accs[key]
+	if !ok {
+		acc = &statsAccumulator{}
+		accs[key] = acc
+	}
+	return acc
+}
+
+// addRollupStats adds precomputed hourly rollups for [from, to)
+func addRollupStats(ctx context.Context, pool *pgxpool.Pool, accs map[string]*statsAccumulator, from, to time.Time, groupByChain bool) error {
+	rows, err := pool.Query(ctx, `
+		SELECT
+			chain_slug, total_runs, completed, failed, running, pending,
+			total_files, total_errors, rows_persisted, price_changes,
+			finished_files, finished_duration_seconds, run_durations
+		FROM ingestion_stats_hourly
+		WHERE hour_start >= $1 AND hour_start < $2
+	`, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to fetch stats rollups: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var chainSlug string
+		var b StatsBucket
+		var finishedFiles int
+		var durationTotal float64
+		var durations []float64
+		if err := rows.Scan(
+			&chainSlug, &b.TotalRuns, &b.Completed, &b.Failed, &b.Running, &b.Pending,
+			&b.TotalFiles, &b.TotalErrors, &b.RowsPersisted, &b.PriceChanges,
+			&finishedFiles, &durationTotal, &durations,
+		); err != nil {
+			return fmt.Errorf("failed to scan stats rollup: %w", err)
+		}
+
+		key := ""
+		if groupByChain {
+			key = chainSlug
+		}
+		acc := accumulatorFor(accs, key)
+		acc.add(b, finishedFiles, durationTotal, durations)
+	}
+
+	return rows.Err()
+}
+
+// addLiveStats computes run metrics directly from ingestion_runs for runs
+// created in [from, to), or [from, to] when includeEnd is set
+func addLiveStats(ctx context.Context, pool *pgxpool.Pool, accs map[string]*statsAccumulator, from, to time.Time, includeEnd bool, groupByChain bool) error {
+	endOp := "<"
+	if includeEnd {
+		endOp = "<="
+	}
+
+	// Get run counts by status, plus durations of finished runs
+	rows, err := pool.Query(ctx, fmt.Sprintf(`
+		WITH runs AS (
+			SELECT
+				CASE WHEN $3 THEN chain_slug ELSE '' END as group_key,
+				status,
+				COALESCE(total_files, 0) as total_files,
+				COALESCE(processed_files, 0) as processed_files,
+				COALESCE(processed_entries, 0) as processed_entries,
+				COALESCE(price_changes, 0) as price_changes,
+				CASE
+					WHEN started_at IS NOT NULL AND completed_at IS NOT NULL AND completed_at >= started_at
+					THEN EXTRACT(EPOCH FROM (completed_at - started_at))::double precision
+				END as duration_seconds
+			FROM ingestion_runs
+			WHERE created_at >= $1 AND created_at %s $2
+		)
+		SELECT
+			group_key,
+			COUNT(*) as total_runs,
+			COUNT(*) FILTER (WHERE status = 'completed') as completed,
+			COUNT(*) FILTER (WHERE status = 'failed') as failed,
+			COUNT(*) FILTER (WHERE status = 'running') as running,
+			COUNT(*) FILTER (WHERE status = 'pending') as pending,
+			SUM(total_files) as total_files,
+			SUM(processed_entries) as rows_persisted,
+			SUM(price_changes) as price_changes,
+			COALESCE(SUM(processed_files) FILTER (WHERE duration_seconds IS NOT NULL), 0) as finished_files,
+			COALESCE(SUM(duration_seconds), 0) as finished_duration_seconds,
+			COALESCE(array_agg(duration_seconds) FILTER (WHERE duration_seconds IS NOT NULL), '{}') as run_durations
+		FROM runs
+		GROUP BY group_key
+	`, endOp), from, to, groupByChain)
+	if err != nil {
+		return fmt.Errorf("failed to fetch stats: %w", err)
+	}
+	for rows.Next() {
+		var key string
+		var b StatsBucket
+		var finishedFiles int
+		var durationTotal float64
+		var durations []float64
+		if err := rows.Scan(
+			&key, &b.TotalRuns, &b.Completed, &b.Failed,
+			&b.Running, &b.Pending, &b.TotalFiles,
+			&b.RowsPersisted, &b.PriceChanges,
+			&finishedFiles, &durationTotal, &durations,
+		); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan stats: %w", err)
+		}
+		accumulatorFor(accs, key).add(b, finishedFiles, durationTotal, durations)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to fetch stats: %w", err)
+	}
+
+	// Get error count; errors of a deleted run still count, under the
+	// unknown chain when grouped
+	rows, err = pool.Query(ctx, fmt.Sprintf(`
+		SELECT
+			CASE WHEN $3 THEN COALESCE(r.chain_slug, $4) ELSE '' END as group_key,
+			COUNT(*)
+		FROM ingestion_errors e
+		LEFT JOIN ingestion_runs r ON r.id = e.run_id
+		WHERE e.created_at >= $1 AND e.created_at %s $2 AND e.severity <> 'warning'
+		GROUP BY 1
+	`, endOp), from, to, groupByChain, unknownChainKey)
+	if err != nil {
+		return fmt.Errorf("failed to fetch error stats: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key string
+		var count int
+		if err := rows.Scan(&key, &count); err != nil {
+			return fmt.Errorf("failed to scan error stats: %w", err)
+		}
+		accumulatorFor(accs, key).bucket.TotalErrors += count
+	}
+
+	return rows.Err()
+}
+
+// add merges a partial bucket into the accumulator
+func (a *statsAccumulator) add(b StatsBucket, finishedFiles int, durationTotal float64, durations []float64) {
+	a.bucket.TotalRuns += b.TotalRuns
+	a.bucket.Completed += b.Completed
+	a.bucket.Failed += b.Failed
+	a.bucket.Running += b.Running
+	a.bucket.Pending += b.Pending
+	a.bucket.TotalFiles += b.TotalFiles
+	a.bucket.TotalErrors += b.TotalErrors
+	a.bucket.RowsPersisted += b.RowsPersisted
+	a.bucket.PriceChanges += b.PriceChanges
+	a.finishedFiles += finishedFiles
+	a.durationTotal += durationTotal
+	a.durations = append(a.durations, durations...)
+}
+
+// percentileCont returns the p-th percentile of sorted values using linear
+// interpolation, matching PostgreSQL's percentile_cont
+func percentileCont(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	pos := p * float64(len(sorted)-1)
+	lower := int(pos)
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	frac := pos - float64(lower)
+	return sorted[lower] + frac*(sorted[lower+1]-sorted[lower])
+}
+
+// GetUsageRequest represents query parameters for ingestion usage accounting
+type GetUsageRequest struct {
+	ChainSlug string `form:"chainSlug" json:"chainSlug"`
+	Months    int    `form:"months" json:"months" binding:"omitempty,min=1,max=24" jsonschema:"minimum=1,maximum=24"`
+}
+
+// MonthlyUsage represents aggregated portal traffic for one chain in one month
+type MonthlyUsage struct {
+	Month            string  `json:"month" jsonschema:"required"` // "2006-01"
+	ChainSlug        string  `json:"chainSlug" jsonschema:"required"`
+	Runs             int     `json:"runs" jsonschema:"required"`
+	Requests         int64   `json:"requests" jsonschema:"required"`
+	BytesDownloaded  int64   `json:"bytesDownloaded" jsonschema:"required"`
+	WallClockSeconds float64 `json:"wallClockSeconds" jsonschema:"required"`
+}
+
+// GetUsageResponse represents the response for ingestion usage accounting
+type GetUsageResponse struct {
+	Usage []MonthlyUsage `json:"usage" jsonschema:"required"`
+}
+
+// GetUsage returns monthly ingestion traffic and time per chain
+// @Summary Get ingestion usage
+// @Description Returns bytes downloaded, request counts and wall-clock time per chain per month, aggregated from run metadata
+// @Tags ingestion
+// @Accept json
+// @Produce json
+// @Param chainSlug query string false "Filter by chain slug"
+// @Param months query int false "Number of months to include, counting the current one" default(6) minimum(1) maximum(24)
+// @Success 200 {object} GetUsageResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/ingestion/usage [get]
+func GetUsage(c *gin.Context) {
+	var req GetUsageRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Set defaults
+	if req.Months == 0 {
+		req.Months = 6
+	}
+
+	pool := database.Pool()
+	ctx := c.Request.Context()
+
+	now := time.Now()
+	since := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, -(req.Months - 1), 0)
+
+	query := `
+		SELECT
+			to_char(date_trunc('month', created_at), 'YYYY-MM') as month,
+			chain_slug,
+			COUNT(*) as runs,
+			COALESCE(SUM((metadata::jsonb -> 'usage' ->> 'requests')::bigint), 0) as requests,
+			COALESCE(SUM((metadata::jsonb -> 'usage' ->> 'bytesDownloaded')::bigint), 0) as bytes_downloaded,
+			COALESCE(SUM((metadata::jsonb -> 'usage' ->> 'wallClockSeconds')::double precision), 0) as wall_clock_seconds
+		FROM ingestion_runs
+		WHERE created_at >= $1
+		  AND metadata IS NOT NULL
+		  AND metadata::jsonb ? 'usage'
+	`
+	args := []interface{}{since}
+	argIdx := 2
+
+	if req.ChainSlug != "" {
+		query += fmt.Sprintf(" AND chain_slug = $%d", argIdx)
+		args = append(args, req.ChainSlug)
+		argIdx++
+	}
+
+	query += " GROUP BY 1, 2 ORDER BY 1 DESC, bytes_downloaded DESC"
+
+	rows, err := pool.Query(ctx, query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch usage"})
+		return
+	}
+	defer rows.Close()
+
+	usage := []MonthlyUsage{}
+	for rows.Next() {
+		var u MonthlyUsage
+		if err := rows.Scan(
+			&u.Month, &u.ChainSlug, &u.Runs,
+			&u.Requests, &u.BytesDownloaded, &u.WallClockSeconds,
+		); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan usage"})
+			return
+		}
+		usage = append(usage, u)
+	}
+
+	c.JSON(http.StatusOK, GetUsageResponse{
+		Usage: usage,
 	})
 }
 
@@ -630,22 +1133,24 @@ func RerunRun(c *gin.Context) {
 	// For now, just return the created run ID
 
 	c.JSON(http.StatusCreated, gin.H{
-		"runId":  newRunID,
-		"status": "pending",
+		"runId":   newRunID,
+		"status":  "pending",
 		"message": fmt.Sprintf("Rerun created for %s: %s", req.RerunType, req.TargetID),
 	})
 }
 
 // DeleteRun deletes an ingestion run and its associated data
 // @Summary Delete ingestion run
-// @Description Deletes an ingestion run and all its associated files and errors
+// @Description Deletes an ingestion run and all its associated files and errors. A run that has reruns is only deleted with cascade=true, which deletes its reruns (and theirs) as well.
 // @Tags ingestion
 // @Accept json
 // @Produce json
 // @Param runId path string true "Run ID"
+// @Param cascade query bool false "Also delete the run's reruns" default(false)
 // @Success 200 {object} map[string]interface{} "Run deleted"
 // @Failure 400 {object} map[string]string "Bad request"
 // @Failure 404 {object} map[string]string "Run not found"
+// @Failure 409 {object} map[string]interface{} "Run has reruns and cascade is not set"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /internal/ingestion/runs/{runId} [delete]
 func DeleteRun(c *gin.Context) {
@@ -655,6 +1160,12 @@ func DeleteRun(c *gin.Context) {
 		return
 	}
 
+	cascade, err := strconv.ParseBool(c.DefaultQuery("cascade", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cascade must be a boolean"})
+		return
+	}
+
 	pool := database.Pool()
 	ctx := c.Request.Context()
 
@@ -678,23 +1189,50 @@ func DeleteRun(c *gin.Context) {
 		return
 	}
 
-	// Delete associated errors, files, then run (in transaction)
-	_, err = tx.Exec(ctx, "DELETE FROM ingestion_errors WHERE run_id = $1", runID)
+	// Reruns would be left pointing at a run that no longer exists
+	reruns, err := runDescendants(ctx, tx, runID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete errors"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reruns"})
 		return
 	}
-
-	_, err = tx.Exec(ctx, "DELETE FROM ingestion_files WHERE run_id = $1", runID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete files"})
+	if len(reruns) > 0 && !cascade {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":      "Run has reruns; pass cascade=true to delete them too",
+			"rerunCount": len(reruns),
+		})
 		return
 	}
 
-	_, err = tx.Exec(ctx, "DELETE FROM ingestion_runs WHERE id = $1", runID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete run"})
-		return
+	runIDs := []any{runID}
+	for _, id := range reruns {
+		runIDs = append(runIDs, id)
+	}
+
+	// Delete associated errors, files, then run (in transaction)
+	for _, id := range runIDs {
+		_, err = tx.Exec(ctx, "DELETE FROM ingestion_errors WHERE run_id = $1", id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete errors"})
+			return
+		}
+
+		_, err = tx.Exec(ctx, "DELETE FROM ingestion_staged_files WHERE run_id = $1", id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete staged rows"})
+			return
+		}
+
+		_, err = tx.Exec(ctx, "DELETE FROM ingestion_files WHERE run_id = $1", id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete files"})
+			return
+		}
+
+		_, err = tx.Exec(ctx, "DELETE FROM ingestion_runs WHERE id = $1", id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete run"})
+			return
+		}
 	}
 
 	// Commit transaction
@@ -704,8 +1242,9 @@ func DeleteRun(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Run deleted successfully",
-		"runId":   runID,
+		"message":         "Run deleted successfully",
+		"runId":           runID,
+		"deletedRerunIds": reruns,
 	})
 }
 
@@ -714,10 +1253,11 @@ type ListChainsResponse struct {
 	Chains []string `json:"chains" jsonschema:"required"`
 }
 
-// ListChains returns the list of valid chain slugs
+// ListChains returns the list of valid chain slugs. The response carries an
+// ETag, so clients revalidating with If-None-Match get 304 while it is unchanged.
 // GET /internal/chains
 func ListChains(c *gin.Context) {
-	c.JSON(http.StatusOK, ListChainsResponse{
+	writeCachedJSON(c, chainsMaxAge, ListChainsResponse{
 		Chains: chains.ValidChains(),
 	})
 }
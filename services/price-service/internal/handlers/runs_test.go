@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kosarica/price-service/internal/database"
+)
+
+// TestPercentileCont verifies interpolation matches PostgreSQL's percentile_cont.
+func TestPercentileCont(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		p      float64
+		want   float64
+	}{
+		{"single value", []float64{42}, 0.95, 42},
+		{"median odd", []float64{10, 20, 30}, 0.5, 20},
+		{"median even", []float64{10, 20, 30, 40}, 0.5, 25},
+		{"p95 interpolated", []float64{0, 100}, 0.95, 95},
+		{"max", []float64{1, 2, 3}, 1, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, percentileCont(tt.values, tt.p), 1e-9)
+		})
+	}
+}
+
+func TestBuildLineageTree(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+	runs := []RunLineageNode{
+		{ID: "1"},
+		{ID: "2", ParentRunID: ptr("1")},
+		{ID: "3", ParentRunID: ptr("2")},
+		{ID: "4", ParentRunID: ptr("1")},
+	}
+
+	tree := buildLineageTree("1", runs)
+	assert.Equal(t, "1", tree.ID)
+	if assert.Len(t, tree.Children, 2) {
+		assert.Equal(t, "2", tree.Children[0].ID, "children keep creation order")
+		assert.Equal(t, "4", tree.Children[1].ID)
+		assert.Equal(t, "3", tree.Children[0].Children[0].ID)
+		assert.NotNil(t, tree.Children[1].Children, "leaves have an empty list")
+	}
+
+	// A corrupt cycle must not recurse forever
+	cyclic := []RunLineageNode{{ID: "1", ParentRunID: ptr("2")}, {ID: "2", ParentRunID: ptr("1")}}
+	tree = buildLineageTree("1", cyclic)
+	assert.Len(t, tree.Children, 1)
+	assert.Empty(t, tree.Children[0].Children)
+}
+
+func TestFileStageDurations(t *testing.T) {
+	start := time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	t.Run("completed file", func(t *testing.T) {
+		got := fileStageDurations(map[string]time.Time{
+			"pending":     at(0),
+			"downloading": at(100),
+			"parsing":     at(400),
+			"persisting":  at(1000),
+			"completed":   at(2500),
+		})
+		assert.Equal(t, map[string]int64{
+			"pending":     100,
+			"downloading": 300,
+			"parsing":     600,
+			"persisting":  1500,
+		}, got)
+	})
+
+	t.Run("skipped stages and stage in progress", func(t *testing.T) {
+		got := fileStageDurations(map[string]time.Time{
+			"pending":     at(0),
+			"downloading": at(50),
+			"skipped":     at(250),
+		})
+		assert.Equal(t, map[string]int64{"pending": 50, "downloading": 200}, got)
+
+		got = fileStageDurations(map[string]time.Time{"pending": at(0), "downloading": at(50)})
+		assert.Equal(t, map[string]int64{"pending": 50}, got)
+	})
+}
+
+// TestStatsBucketsAcrossClockChanges verifies the 7d and 30d buckets span
+// calendar days in Zagreb, which lost an hour on 2024-03-31 and gained one on
+// 2024-10-27.
+func TestStatsBucketsAcrossClockChanges(t *testing.T) {
+	// Noon in Zagreb a few days after each change
+	afterSpring := time.Date(2024, 4, 3, 10, 0, 0, 0, time.UTC)
+	afterFall := time.Date(2024, 10, 30, 11, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 24*time.Hour, afterSpring.Sub(statsBucketFrom("24h", afterSpring)))
+	assert.Equal(t, 7*24*time.Hour-time.Hour, afterSpring.Sub(statsBucketFrom("7d", afterSpring)))
+	assert.Equal(t, 7*24*time.Hour+time.Hour, afterFall.Sub(statsBucketFrom("7d", afterFall)))
+	assert.True(t, statsBucketFrom("30d", afterFall).Equal(time.Date(2024, 9, 30, 10, 0, 0, 0, time.UTC)), "noon in Zagreb 30 days before")
+
+	// Outside a change the buckets are whole days
+	summer := time.Date(2024, 7, 15, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, 30*24*time.Hour, summer.Sub(statsBucketFrom("30d", summer)))
+}
+
+func TestParseStatsTime(t *testing.T) {
+	exact, err := parseStatsTime("2024-10-27T12:00:00Z", true)
+	require.NoError(t, err)
+	assert.True(t, exact.Equal(time.Date(2024, 10, 27, 12, 0, 0, 0, time.UTC)))
+
+	// A date is a whole day in Zagreb, 25 hours on the day clocks go back
+	from, err := parseStatsTime("2024-10-27", false)
+	require.NoError(t, err)
+	to, err := parseStatsTime("2024-10-27", true)
+	require.NoError(t, err)
+	assert.True(t, from.Equal(time.Date(2024, 10, 26, 22, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 25*time.Hour, to.Sub(from))
+
+	_, err = parseStatsTime("27.10.2024", false)
+	assert.Error(t, err)
+}
+
+// TestStatsErrorsWithoutRun verifies errors whose run was deleted still count
+// in the totals, and under the unknown chain when grouped by chain
+func TestStatsErrorsWithoutRun(t *testing.T) {
+	ctx := context.Background()
+
+	_, db, cleanup := setupHandlersTestDB(t)
+	defer cleanup()
+
+	_, err := db.Exec(ctx, `
+		CREATE TABLE ingestion_runs (
+			id TEXT PRIMARY KEY,
+			chain_slug TEXT NOT NULL,
+			status TEXT NOT NULL,
+			total_files INTEGER,
+			processed_files INTEGER,
+			processed_entries INTEGER,
+			price_changes INTEGER,
+			started_at TIMESTAMP,
+			completed_at TIMESTAMP,
+			created_at TIMESTAMP NOT NULL
+		);
+		CREATE TABLE ingestion_errors (
+			id BIGSERIAL PRIMARY KEY,
+			run_id TEXT NOT NULL,
+			severity TEXT NOT NULL DEFAULT 'error',
+			created_at TIMESTAMP NOT NULL
+		);
+		CREATE TABLE ingestion_stats_rollup_state (
+			id BOOLEAN PRIMARY KEY DEFAULT true CHECK (id),
+			rolled_up_to TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL DEFAULT now()
+		);
+	`)
+	require.NoError(t, err)
+
+	to := time.Date(2024, 7, 15, 12, 0, 0, 0, time.UTC)
+	runAt := to.Add(-3 * time.Hour)
+	errorAt := to.Add(-2 * time.Hour)
+	_, err = db.Exec(ctx, `
+		INSERT INTO ingestion_runs (id, chain_slug, status, created_at) VALUES ('run-1', 'konzum', 'failed', $1)
+	`, runAt)
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, `
+		INSERT INTO ingestion_errors (run_id, severity, created_at)
+		VALUES ('run-1', 'error', $1), ('run-1', 'warning', $1), ('deleted-run', 'error', $1), ('deleted-run', 'error', $1)
+	`, errorAt)
+	require.NoError(t, err)
+
+	totals, err := queryStatsBuckets(ctx, db, "24h", to.Add(-24*time.Hour), to, false)
+	require.NoError(t, err)
+	require.Contains(t, totals, "")
+	assert.Equal(t, 3, totals[""].TotalErrors)
+	assert.Equal(t, 1, totals[""].TotalRuns)
+
+	byChain, err := queryStatsBuckets(ctx, db, "24h", to.Add(-24*time.Hour), to, true)
+	require.NoError(t, err)
+	require.Contains(t, byChain, "konzum")
+	require.Contains(t, byChain, unknownChainKey)
+	assert.Equal(t, 1, byChain["konzum"].TotalErrors)
+	assert.Equal(t, 2, byChain[unknownChainKey].TotalErrors)
+	assert.Zero(t, byChain[unknownChainKey].TotalRuns)
+}
+
+// TestGetUsage sums the usage recorded in run metadata per month and chain,
+// leaving out runs without usage and months outside the window
+func TestGetUsage(t *testing.T) {
+	ctx := context.Background()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/internal/ingestion/usage", GetUsage)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/internal/ingestion/usage"+query, nil))
+		return w
+	}
+
+	for _, query := range []string{"?months=0x", "?months=25", "?months=-1"} {
+		assert.Equal(t, http.StatusBadRequest, get(query).Code, query)
+	}
+
+	container, db, cleanup := setupHandlersTestDB(t)
+	defer cleanup()
+	connStr, err := container.ConnectionString(ctx, "sslmode=disable")
+	require.NoError(t, err)
+	require.NoError(t, database.Connect(ctx, connStr, 4, 1, 0, 0))
+	defer database.Close()
+
+	_, err = db.Exec(ctx, `
+		CREATE TABLE ingestion_runs (
+			id TEXT PRIMARY KEY,
+			chain_slug TEXT NOT NULL,
+			metadata TEXT,
+			created_at TIMESTAMP NOT NULL
+		)
+	`)
+	require.NoError(t, err)
+
+	now := time.Now()
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 12, 0, 0, 0, now.Location())
+	lastMonth := thisMonth.AddDate(0, -1, 0)
+	usage := func(requests, bytes int, seconds float64) string {
+		return fmt.Sprintf(`{"usage": {"requests": %d, "bytesDownloaded": %d, "wallClockSeconds": %g}}`, requests, bytes, seconds)
+	}
+	_, err = db.Exec(ctx, `
+		INSERT INTO ingestion_runs (id, chain_slug, metadata, created_at) VALUES
+			('run-1', 'konzum', $1, $6),
+			('run-2', 'konzum', $2, $6),
+			('run-3', 'spar', $3, $6),
+			('run-4', 'konzum', $4, $7),
+			('run-5', 'konzum', NULL, $6),
+			('run-6', 'konzum', '{"source": "manual"}', $6),
+			('run-7', 'konzum', $5, $8)
+	`, usage(10, 1000, 1.5), usage(5, 3000, 2), usage(1, 100, 0.5), usage(7, 700, 3), usage(99, 99, 99),
+		thisMonth, lastMonth, thisMonth.AddDate(0, -6, 0))
+	require.NoError(t, err)
+
+	w := get("")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response GetUsageResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []MonthlyUsage{
+		{Month: thisMonth.Format("2006-01"), ChainSlug: "konzum", Runs: 2, Requests: 15, BytesDownloaded: 4000, WallClockSeconds: 3.5},
+		{Month: thisMonth.Format("2006-01"), ChainSlug: "spar", Runs: 1, Requests: 1, BytesDownloaded: 100, WallClockSeconds: 0.5},
+		{Month: lastMonth.Format("2006-01"), ChainSlug: "konzum", Runs: 1, Requests: 7, BytesDownloaded: 700, WallClockSeconds: 3},
+	}, response.Usage)
+
+	w = get("?chainSlug=spar&months=1")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Usage, 1)
+	assert.Equal(t, "spar", response.Usage[0].ChainSlug)
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import "github.com/kosarica/price-service/internal/pkg/money"
+
+// PriceSavings are the discount figures of a price. They are derived by
+// newPriceSavings only, so every response computes them alike and clients
+// need not.
+type PriceSavings struct {
+	// DiscountPercent is the discount off the regular price in whole percent,
+	// omitted without a discount
+	DiscountPercent *int `json:"discountPercent,omitempty" proto:"1"`
+	// AbsoluteSavings is the discount off the regular price in cents, omitted
+	// without a discount
+	AbsoluteSavings *int64 `json:"absoluteSavings,omitempty" proto:"2"`
+	// IsBelowAnchorPrice reports whether the price paid, discounted or not,
+	// is below the anchor price; omitted without an anchor price
+	IsBelowAnchorPrice *bool `json:"isBelowAnchorPrice,omitempty" proto:"3"`
+}
+
+// newPriceSavings derives the discount figures of a regular price, an
+// optional discounted price and an optional anchor price, all in cents. A
+// discounted price not below the regular price is no discount, and an anchor
+// price of zero or less counts as no anchor price.
+func newPriceSavings(price int64, discountPrice, anchorPrice *int64) PriceSavings {
+	var savings PriceSavings
+	paid := price
+	if discountPrice != nil {
+		if amount, ok := money.Savings(price, *discountPrice); ok {
+			percent, _ := money.DiscountPercent(price, *discountPrice)
+			savings.DiscountPercent = &percent
+			savings.AbsoluteSavings = &amount
+			paid = *discountPrice
+		}
+	}
+	if anchorPrice != nil && *anchorPrice > 0 && price > 0 {
+		below := paid < *anchorPrice
+		savings.IsBelowAnchorPrice = &below
+	}
+	return savings
+}
+
+// storePriceSavings derives the discount figures of a store price
+func storePriceSavings(price StorePrice) PriceSavings {
+	if price.CurrentPrice == nil {
+		return PriceSavings{}
+	}
+	return newPriceSavings(int64(*price.CurrentPrice), widenCents(price.DiscountPrice), widenCents(price.AnchorPrice))
+}
+
+// widenCents converts an optional amount in cents to int64
+func widenCents(cents *int) *int64 {
+	if cents == nil {
+		return nil
+	}
+	wide := int64(*cents)
+	return &wide
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPriceSavings(t *testing.T) {
+	cents := func(v int64) *int64 { return &v }
+
+	discounted := newPriceSavings(400, cents(300), cents(350))
+	require.NotNil(t, discounted.DiscountPercent)
+	assert.Equal(t, 25, *discounted.DiscountPercent)
+	assert.Equal(t, int64(100), *discounted.AbsoluteSavings)
+	assert.True(t, *discounted.IsBelowAnchorPrice, "discounted price is compared to the anchor")
+
+	regular := newPriceSavings(400, nil, cents(350))
+	assert.Nil(t, regular.DiscountPercent)
+	assert.Nil(t, regular.AbsoluteSavings)
+	require.NotNil(t, regular.IsBelowAnchorPrice)
+	assert.False(t, *regular.IsBelowAnchorPrice)
+
+	// A discounted price at or above the regular price is no discount
+	for _, discount := range []int64{400, 450} {
+		none := newPriceSavings(400, cents(discount), nil)
+		assert.Nil(t, none.DiscountPercent, discount)
+		assert.Nil(t, none.AbsoluteSavings, discount)
+	}
+
+	// Zero anchor and regular prices carry no comparison
+	assert.Nil(t, newPriceSavings(400, nil, cents(0)).IsBelowAnchorPrice)
+	assert.Nil(t, newPriceSavings(0, nil, cents(350)).IsBelowAnchorPrice)
+	assert.Nil(t, newPriceSavings(0, cents(0), nil).DiscountPercent)
+	assert.Equal(t, PriceSavings{}, newPriceSavings(400, nil, nil))
+}
+
+func TestStorePriceSavings(t *testing.T) {
+	current, discount, anchor := 400, 300, 0
+	price := StorePrice{CurrentPrice: &current, DiscountPrice: &discount, AnchorPrice: &anchor}
+	price.PriceSavings = storePriceSavings(price)
+
+	body, err := json.Marshal(price)
+	require.NoError(t, err)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &fields))
+	assert.Equal(t, float64(25), fields["discountPercent"])
+	assert.Equal(t, float64(100), fields["absoluteSavings"])
+	assert.NotContains(t, fields, "isBelowAnchorPrice")
+
+	assert.Equal(t, PriceSavings{}, storePriceSavings(StorePrice{DiscountPrice: &discount}))
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kosarica/price-service/internal/chains"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/pkg/clock"
+	"github.com/kosarica/price-service/internal/scheduler"
+)
+
+// scheduleLocation is the time zone cron expressions are evaluated in
+var scheduleLocation = time.UTC
+
+// InitSchedules sets the time zone the scheduler evaluates cron expressions
+// in, so next run times computed here match its own
+func InitSchedules(location *time.Location) {
+	scheduleLocation = location
+}
+
+// ListSchedulesResponse represents the response for listing ingestion schedules
+type ListSchedulesResponse struct {
+	Schedules []database.IngestionSchedule `json:"schedules" jsonschema:"required"`
+	Timezone  string                       `json:"timezone" jsonschema:"required"`
+}
+
+// SetScheduleRequest represents the request body for creating or replacing a schedule
+type SetScheduleRequest struct {
+	CronExpression string `json:"cronExpression" binding:"required"` // e.g. "30 6 * * *" or "@daily"
+	Enabled        *bool  `json:"enabled"`                           // Default true
+}
+
+// ListSchedules returns all ingestion schedules
+// @Summary List ingestion schedules
+// @Description Returns the cron schedule of every chain ingested automatically, with its next run time and the outcome of its last trigger
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Success 200 {object} ListSchedulesResponse
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/schedules [get]
+func ListSchedules(c *gin.Context) {
+	schedules, err := database.ListIngestionSchedules(c.Request.Context())
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to list ingestion schedules")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list ingestion schedules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListSchedulesResponse{Schedules: schedules, Timezone: scheduleLocation.String()})
+}
+
+// GetSchedule returns a chain's ingestion schedule
+// @Summary Get ingestion schedule
+// @Description Returns a chain's cron schedule with its next run time and the outcome of its last trigger
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param chain path string true "Chain slug"
+// @Success 200 {object} database.IngestionSchedule
+// @Failure 404 {object} map[string]string "Schedule not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/schedules/{chain} [get]
+func GetSchedule(c *gin.Context) {
+	schedule, err := database.GetIngestionSchedule(c.Request.Context(), c.Param("chain"))
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to fetch ingestion schedule")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ingestion schedule"})
+		return
+	}
+	if schedule == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// SetSchedule creates or replaces a chain's ingestion schedule
+// @Summary Set ingestion schedule
+// @Description Creates or replaces a chain's cron schedule: five fields (minute, hour, day of month, month, day of week) or a macro such as @daily, evaluated in the scheduler's time zone. The next run time is recomputed from now. A due run is skipped while a run of the chain is still pending or running.
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param chain path string true "Chain slug"
+// @Param request body SetScheduleRequest true "Schedule settings"
+// @Success 200 {object} database.IngestionSchedule
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/schedules/{chain} [put]
+func SetSchedule(c *gin.Context) {
+	chainSlug := c.Param("chain")
+	if !chains.IsValidChain(chainSlug) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chain ID: " + chainSlug})
+		return
+	}
+
+	var req SetScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	next, err := scheduler.NextRun(req.CronExpression, scheduleLocation, clock.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	enabled := req.Enabled == nil || *req.Enabled
+
+	schedule, err := database.UpsertIngestionSchedule(c.Request.Context(), chainSlug, req.CronExpression, enabled, &next)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to save ingestion schedule")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save ingestion schedule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// DeleteSchedule removes a chain's ingestion schedule
+// @Summary Delete ingestion schedule
+// @Description Removes a chain's schedule, so it is only ingested on request. A schedule in the config is created again at the next startup; disable it instead to keep it off.
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param chain path string true "Chain slug"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string "Schedule not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/schedules/{chain} [delete]
+func DeleteSchedule(c *gin.Context) {
+	chainSlug := c.Param("chain")
+
+	deleted, err := database.DeleteIngestionSchedule(c.Request.Context(), chainSlug)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to delete ingestion schedule")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete ingestion schedule"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Schedule deleted successfully",
+		"chain":   chainSlug,
+	})
+}
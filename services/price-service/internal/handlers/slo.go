@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kosarica/price-service/internal/slo"
+)
+
+// SLOStatusResponse represents the state of every service level objective
+type SLOStatusResponse struct {
+	FastBurnRate float64              `json:"fastBurnRate" jsonschema:"required"`
+	Objectives   []SLOObjectiveStatus `json:"objectives" jsonschema:"required"`
+}
+
+// SLOObjectiveStatus represents the state of one objective over its window
+type SLOObjectiveStatus struct {
+	Name                 string             `json:"name" jsonschema:"required"`
+	Route                string             `json:"route" jsonschema:"required"`
+	Method               string             `json:"method,omitempty"`
+	LatencyMs            int64              `json:"latencyMs,omitempty"`
+	Target               float64            `json:"target" jsonschema:"required"`
+	WindowSeconds        int64              `json:"windowSeconds" jsonschema:"required"`
+	Requests             int64              `json:"requests" jsonschema:"required"`
+	Good                 int64              `json:"good" jsonschema:"required"`
+	Compliance           float64            `json:"compliance" jsonschema:"required"`
+	ErrorBudgetRemaining float64            `json:"errorBudgetRemaining" jsonschema:"required"`
+	BurnRates            map[string]float64 `json:"burnRates" jsonschema:"required"`
+	FastBurn             bool               `json:"fastBurn" jsonschema:"required"`
+}
+
+// GetSLOStatus returns the compliance and burn rates of the service level objectives
+// @Summary Get SLO status
+// @Description Returns each configured service level objective with its rolling compliance, remaining error budget and budget burn rates over 5 minutes, 1 hour and 6 hours. A burn rate of 1 spends the budget exactly over the compliance window; fastBurn is set while both the 5 minute and 1 hour rates are at or above fastBurnRate, which is when webhook alerts fire.
+// @Tags health
+// @Produce json
+// @Success 200 {object} SLOStatusResponse
+// @Failure 503 {object} map[string]string "SLO tracking not configured"
+// @Router /internal/slo [get]
+func GetSLOStatus(c *gin.Context) {
+	tracker := slo.Default()
+	if tracker == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "SLO tracking not configured"})
+		return
+	}
+
+	statuses := tracker.Status()
+	objectives := make([]SLOObjectiveStatus, 0, len(statuses))
+	for _, s := range statuses {
+		objectives = append(objectives, SLOObjectiveStatus{
+			Name:                 s.Name,
+			Route:                s.Route,
+			Method:               s.Method,
+			LatencyMs:            s.Latency.Milliseconds(),
+			Target:               s.Target,
+			WindowSeconds:        int64(s.Window.Seconds()),
+			Requests:             s.Requests,
+			Good:                 s.Good,
+			Compliance:           s.Compliance,
+			ErrorBudgetRemaining: s.ErrorBudgetRemaining,
+			BurnRates:            s.BurnRates,
+			FastBurn:             s.FastBurn,
+		})
+	}
+
+	c.JSON(http.StatusOK, SLOStatusResponse{
+		FastBurnRate: tracker.FastBurnRate(),
+		Objectives:   objectives,
+	})
+}
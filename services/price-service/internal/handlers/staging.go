@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kosarica/price-service/internal/pipeline"
+)
+
+// PublishRunRequest represents the request body for publishing a staged run
+type PublishRunRequest struct {
+	ApprovedBy string `json:"approvedBy" binding:"required" jsonschema:"required"`
+}
+
+// PublishRunResponse represents what publishing a staged run wrote
+type PublishRunResponse struct {
+	RunID        string `json:"runId" jsonschema:"required"`
+	Files        int    `json:"files" jsonschema:"required"`
+	Persisted    int    `json:"persisted" jsonschema:"required"`
+	PriceChanges int    `json:"priceChanges" jsonschema:"required"`
+}
+
+// RejectRunRequest represents the request body for rejecting a staged run
+type RejectRunRequest struct {
+	RejectedBy string `json:"rejectedBy" binding:"required" jsonschema:"required"`
+	Reason     string `json:"reason" binding:"required" jsonschema:"required"`
+}
+
+// ClearSuspectRunRequest represents the request body for clearing a suspect run
+type ClearSuspectRunRequest struct {
+	ClearedBy string `json:"clearedBy" binding:"required" jsonschema:"required"`
+}
+
+// PublishRun publishes a staged ingestion run held for review
+// @Summary Publish staged run
+// @Description Writes the rows of a staged run to the live tables in a single transaction and marks it published. Only runs held for review (stagingStatus staged) can be published; the run's qualityReport says which checks failed.
+// @Tags ingestion
+// @Accept json
+// @Produce json
+// @Param runId path string true "Run ID"
+// @Param request body PublishRunRequest true "Who approves the run"
+// @Success 200 {object} PublishRunResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 404 {object} map[string]string "Run not found"
+// @Failure 409 {object} map[string]string "Run is not awaiting publish"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/ingestion/runs/{runId}/publish [post]
+func PublishRun(c *gin.Context) {
+	runID := c.Param("runId")
+	if runID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "runId is required"})
+		return
+	}
+
+	var req PublishRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := pipeline.PublishRun(c.Request.Context(), runID, req.ApprovedBy)
+	if err != nil {
+		writeStagingError(c, err, "Failed to publish run")
+		return
+	}
+
+	c.JSON(http.StatusOK, PublishRunResponse{
+		RunID:        result.RunID,
+		Files:        result.Files,
+		Persisted:    result.Persisted,
+		PriceChanges: result.PriceChanges,
+	})
+}
+
+// RejectRun discards a staged ingestion run held for review
+// @Summary Reject staged run
+// @Description Discards the rows of a staged run without publishing them and marks it rejected. Only runs held for review (stagingStatus staged) can be rejected.
+// @Tags ingestion
+// @Accept json
+// @Produce json
+// @Param runId path string true "Run ID"
+// @Param request body RejectRunRequest true "Who rejects the run and why"
+// @Success 200 {object} map[string]interface{} "Run rejected"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 404 {object} map[string]string "Run not found"
+// @Failure 409 {object} map[string]string "Run is not awaiting publish"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/ingestion/runs/{runId}/reject [post]
+func RejectRun(c *gin.Context) {
+	runID := c.Param("runId")
+	if runID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "runId is required"})
+		return
+	}
+
+	var req RejectRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := pipeline.RejectRun(c.Request.Context(), runID, req.RejectedBy, req.Reason); err != nil {
+		writeStagingError(c, err, "Failed to reject run")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"runId":         runID,
+		"stagingStatus": pipeline.StagingRejected,
+	})
+}
+
+// ClearSuspectRun clears a run flagged suspect by its baseline check
+// @Summary Clear suspect run
+// @Description Marks a run flagged suspect by its comparison with the same weekday a week earlier as cleared after review, releasing the hold on the chain's price cache refresh. Only suspect runs (baselineStatus suspect) can be cleared; the run's baselineReport says which checks failed.
+// @Tags ingestion
+// @Accept json
+// @Produce json
+// @Param runId path string true "Run ID"
+// @Param request body ClearSuspectRunRequest true "Who clears the run"
+// @Success 200 {object} map[string]interface{} "Run cleared"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 404 {object} map[string]string "Run not found"
+// @Failure 409 {object} map[string]string "Run is not suspect"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/ingestion/runs/{runId}/clear-suspect [post]
+func ClearSuspectRun(c *gin.Context) {
+	runID := c.Param("runId")
+	if runID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "runId is required"})
+		return
+	}
+
+	var req ClearSuspectRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := pipeline.ClearSuspectRun(c.Request.Context(), runID, req.ClearedBy); err != nil {
+		writeStagingError(c, err, "Failed to clear run")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"runId":          runID,
+		"baselineStatus": pipeline.BaselineCleared,
+	})
+}
+
+// writeStagingError maps a publish, reject or clear error to its response
+func writeStagingError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, pipeline.ErrRunNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Run not found"})
+	case errors.Is(err, pipeline.ErrRunNotStaged), errors.Is(err, pipeline.ErrRunNotSuspect):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
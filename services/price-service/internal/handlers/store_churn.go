@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/sweepers"
+)
+
+// ListStoreChurnRequest represents query parameters for listing churning stores
+type ListStoreChurnRequest struct {
+	ChainSlug string `form:"chainSlug" json:"chainSlug"`
+}
+
+// ListStoreChurnResponse represents the stores flagged for price group churn
+type ListStoreChurnResponse struct {
+	Stores []sweepers.StoreChurn `json:"stores" jsonschema:"required"`
+	Total  int                   `json:"total" jsonschema:"required"`
+}
+
+// ListStoreChurn lists stores flagged for changing price groups too often
+// @Summary List stores with price group churn
+// @Description Returns the stores the churn sweeper flagged for being reassigned to another price group at least the configured number of times within its window, most reassigned first, each with its group history over the window. Frequent reassignment usually points at an unstable price hash or inconsistent parsing of the store's files. Flags are cleared once a store settles.
+// @Tags ingestion
+// @Produce json
+// @Param chainSlug query string false "Filter by chain slug"
+// @Success 200 {object} ListStoreChurnResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/ingestion/store-churn [get]
+func ListStoreChurn(c *gin.Context) {
+	var req ListStoreChurnRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stores, err := sweepers.ListChurningStores(c.Request.Context(), database.Pool(), req.ChainSlug)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to fetch churning stores")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch churning stores"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListStoreChurnResponse{Stores: stores, Total: len(stores)})
+}
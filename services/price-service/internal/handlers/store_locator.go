@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kosarica/price-service/internal/chains"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/storelocator"
+)
+
+// storeLocatorSyncer reconciles stores with store locators on request
+var storeLocatorSyncer *storelocator.Syncer
+
+// InitStoreLocator sets the syncer run by the store locator sync endpoint
+func InitStoreLocator(syncer *storelocator.Syncer) {
+	storeLocatorSyncer = syncer
+}
+
+// ListUnmatchedLocatorStoresRequest represents query parameters for listing
+// stores no store locator entry matched
+type ListUnmatchedLocatorStoresRequest struct {
+	ChainSlug string `form:"chainSlug" json:"chainSlug"`
+}
+
+// ListUnmatchedLocatorStoresResponse represents the stores flagged for review
+// by the store locator sync
+type ListUnmatchedLocatorStoresResponse struct {
+	Stores []storelocator.UnmatchedStore `json:"stores" jsonschema:"required"`
+	Total  int                           `json:"total" jsonschema:"required"`
+}
+
+// SyncStoreLocator reconciles a chain's stores with its store locator
+// @Summary Sync stores with store locator
+// @Description Fetches the chain's store locator and matches each physical store to a listed store by address similarity, within the same city and postal code. Matched stores get the listed coordinates where they have none and the listed opening hours; unmatched stores are flagged for review. A locator that fails or lists no stores changes nothing.
+// @Tags stores
+// @Produce json
+// @Param chain path string true "Chain slug"
+// @Success 200 {object} storelocator.SyncResult
+// @Failure 404 {object} map[string]string "Chain or store locator not found"
+// @Failure 502 {object} map[string]string "Store locator could not be read"
+// @Router /internal/admin/store-locator/{chain}/sync [post]
+func SyncStoreLocator(c *gin.Context) {
+	chainSlug := c.Param("chain")
+	if !chains.IsValidChain(chainSlug) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chain not found: " + chainSlug})
+		return
+	}
+	if storeLocatorSyncer == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Store locator sync is not configured"})
+		return
+	}
+
+	result, err := storeLocatorSyncer.SyncChain(c.Request.Context(), chainSlug)
+	if errors.Is(err, storelocator.ErrNoLocator) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No store locator configured for chain: " + chainSlug})
+		return
+	}
+	if err != nil {
+		logger.Error().Err(err).Str("chain", chainSlug).Msg("Failed to sync store locator")
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListUnmatchedLocatorStores lists the stores no store locator entry matched
+// @Summary List stores unmatched by store locator
+// @Description Returns the physical stores the last store locator sync of their chain could not match to a listed store, for review: usually stores with a missing or misspelled address, or ones the chain no longer lists.
+// @Tags stores
+// @Produce json
+// @Param chainSlug query string false "Filter by chain slug"
+// @Success 200 {object} ListUnmatchedLocatorStoresResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/store-locator/unmatched [get]
+func ListUnmatchedLocatorStores(c *gin.Context) {
+	var req ListUnmatchedLocatorStoresRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stores, err := storelocator.ListUnmatchedStores(c.Request.Context(), database.Pool(), req.ChainSlug)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to fetch unmatched stores")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch unmatched stores"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListUnmatchedLocatorStoresResponse{Stores: stores, Total: len(stores)})
+}
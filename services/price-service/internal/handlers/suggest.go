@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/search"
+)
+
+// Suggest cache settings: popular prefixes are typed again and again, so
+// their completions are served from memory for a while
+const (
+	suggestCacheSize = 2000
+	suggestCacheTTL  = 5 * time.Minute
+)
+
+// minSubstringSuggestLength is the shortest query also matched inside names;
+// shorter queries only complete name and brand prefixes, as the trigram index
+// cannot serve them
+const minSubstringSuggestLength = 3
+
+// SuggestItemsRequest represents query parameters for item typeahead
+type SuggestItemsRequest struct {
+	Query     string `form:"q" json:"q" binding:"required,min=2,max=100" jsonschema:"required,minLength=2,maxLength=100"`
+	ChainSlug string `form:"chainSlug" json:"chainSlug"`
+	Limit     int    `form:"limit" json:"limit" binding:"omitempty,min=1,max=20" jsonschema:"minimum=1,maximum=20"`
+}
+
+// Suggestion is a completion of a typeahead query
+type Suggestion struct {
+	Text      string `json:"text" jsonschema:"required"`
+	Kind      string `json:"kind" jsonschema:"required,enum=name,enum=brand"`
+	ItemCount int    `json:"itemCount" jsonschema:"required"`
+}
+
+// SuggestItemsResponse represents the response for item typeahead
+type SuggestItemsResponse struct {
+	Query       string       `json:"query" jsonschema:"required"`
+	Suggestions []Suggestion `json:"suggestions" jsonschema:"required"`
+}
+
+// suggestions caches completions of recent queries
+var suggestions = newSuggestCache(suggestCacheSize, suggestCacheTTL)
+
+// SuggestItems completes item names and brands as the user types
+// @Summary Suggest items
+// @Description Returns name and brand completions of a partial query for typeahead, ignoring case and Croatian diacritics and spelling variants, ranked names and brands starting with the query first, then names with a word starting with it, then names containing it, each by how many items carry them. Queries shorter than 3 characters only complete prefixes. Backed by prefix and trigram indexes and an in-memory cache of recent queries; use the search endpoint for full results.
+// @Tags items
+// @Accept json
+// @Produce json
+// @Param q query string true "Partial query (min 2 chars)" minLength(2) maxLength(100)
+// @Param chainSlug query string false "Filter by chain slug"
+// @Param limit query int false "Number of suggestions to return" default(10) minimum(1) maximum(20)
+// @Success 200 {object} SuggestItemsResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/items/suggest [get]
+func SuggestItems(c *gin.Context) {
+	var req SuggestItemsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Set default limit
+	if req.Limit == 0 {
+		req.Limit = 10
+	}
+
+	query := search.Fold(req.Query)
+	if len([]rune(query)) < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query must be at least 2 characters long"})
+		return
+	}
+
+	key := suggestKey(query, req.ChainSlug, req.Limit)
+	if cached, ok := suggestions.get(key); ok {
+		c.JSON(http.StatusOK, SuggestItemsResponse{Query: req.Query, Suggestions: cached})
+		return
+	}
+
+	// Ranks: 0 starts with the query, 1 has a word starting with it, 2 contains it
+	pattern := escapeLike(query)
+	substring := len([]rune(query)) >= minSubstringSuggestLength
+	rows, err := database.Pool().Query(c.Request.Context(), `
+		WITH candidates AS (
+			SELECT ri.name AS text, 'name' AS kind,
+			       CASE
+			           WHEN ri.search_name LIKE $1 || '%' THEN 0
+			           WHEN ri.search_name LIKE '% ' || $1 || '%' THEN 1
+			           ELSE 2
+			       END AS rank
+			FROM retailer_items ri
+			WHERE ($2 = '' OR ri.chain_slug = $2)
+			  AND (ri.search_name LIKE $1 || '%' OR ($3 AND ri.search_name LIKE '%' || $1 || '%'))
+			UNION ALL
+			SELECT ri.brand, 'brand', 0
+			FROM retailer_items ri
+			WHERE ($2 = '' OR ri.chain_slug = $2)
+			  AND ri.brand IS NOT NULL
+			  AND search_fold(ri.brand) LIKE $1 || '%'
+		)
+		SELECT text, kind, COUNT(*) AS items
+		FROM candidates
+		GROUP BY text, kind
+		ORDER BY MIN(rank), COUNT(*) DESC, length(text), text
+		LIMIT $4
+	`, pattern, req.ChainSlug, substring, req.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to suggest items"})
+		return
+	}
+	defer rows.Close()
+
+	results := []Suggestion{}
+	for rows.Next() {
+		var s Suggestion
+		if err := rows.Scan(&s.Text, &s.Kind, &s.ItemCount); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan suggestion"})
+			return
+		}
+		results = append(results, s)
+	}
+	if rows.Err() != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error iterating suggestions"})
+		return
+	}
+
+	suggestions.put(key, results)
+	c.JSON(http.StatusOK, SuggestItemsResponse{Query: req.Query, Suggestions: results})
+}
+
+// escapeLike escapes the LIKE wildcards of s, with backslash as the escape
+// character Postgres uses by default
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// suggestKey identifies a normalized typeahead query
+func suggestKey(query, chainSlug string, limit int) string {
+	return chainSlug + "\x00" + strconv.Itoa(limit) + "\x00" + query
+}
+
+// suggestCache is a bounded cache of typeahead results that expire after a
+// TTL. When full, expired entries are dropped first, then the least recently
+// used one.
+type suggestCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]*suggestEntry
+}
+
+type suggestEntry struct {
+	suggestions []Suggestion
+	expiresAt   time.Time
+	usedAt      time.Time
+}
+
+func newSuggestCache(size int, ttl time.Duration) *suggestCache {
+	return &suggestCache{
+		size:    size,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]*suggestEntry, size),
+	}
+}
+
+// get returns the cached suggestions of a key that has not expired
+func (c *suggestCache) get(key string) ([]Suggestion, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	now := c.now()
+	if !now.Before(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	entry.usedAt = now
+	return entry.suggestions, true
+}
+
+// put caches the suggestions of a key, evicting to stay within size
+func (c *suggestCache) put(key string, suggestions []Suggestion) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= c.size {
+		c.evict(now)
+	}
+	c.entries[key] = &suggestEntry{suggestions: suggestions, expiresAt: now.Add(c.ttl), usedAt: now}
+}
+
+// evict drops expired entries, or the least recently used one if none has
+// expired
+func (c *suggestCache) evict(now time.Time) {
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, key)
+			continue
+		}
+		if oldestKey == "" || entry.usedAt.Before(oldest) {
+			oldestKey, oldest = key, entry.usedAt
+		}
+	}
+	if len(c.entries) >= c.size {
+		delete(c.entries, oldestKey)
+	}
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSuggestCache verifies entries expire and the least recently used one is
+// evicted when the cache is full
+func TestSuggestCache(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cache := newSuggestCache(2, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	milk := []Suggestion{{Text: "Mlijeko", Kind: "name", ItemCount: 3}}
+	cache.put("a", milk)
+	now = now.Add(time.Second)
+	cache.put("b", nil)
+	now = now.Add(time.Second)
+
+	// Using a makes b the least recently used
+	got, ok := cache.get("a")
+	assert.True(t, ok)
+	assert.Equal(t, milk, got)
+
+	cache.put("c", nil)
+	_, ok = cache.get("b")
+	assert.False(t, ok, "least recently used entry is evicted")
+	_, ok = cache.get("a")
+	assert.True(t, ok)
+
+	now = now.Add(time.Minute)
+	_, ok = cache.get("c")
+	assert.False(t, ok, "entries expire after the TTL")
+}
+
+func TestEscapeLike(t *testing.T) {
+	assert.Equal(t, `100\% sok\_jabuka \\`, escapeLike(`100% sok_jabuka \`))
+}
@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/pkg/cuid2"
+	"github.com/kosarica/price-service/internal/webhooks"
+)
+
+const (
+	// defaultWebhookDeliveries is the number of deliveries listed without limit
+	defaultWebhookDeliveries = 50
+	// maxWebhookDeliveries bounds the deliveries listed per request
+	maxWebhookDeliveries = 500
+)
+
+// WebhookRequest represents the request body for creating or replacing a webhook
+type WebhookRequest struct {
+	URL         string   `json:"url" binding:"required"` // http or https
+	Secret      string   `json:"secret"`                 // Generated on create, kept on update when empty
+	EventTypes  []string `json:"eventTypes"`             // Empty subscribes to every event
+	Enabled     *bool    `json:"enabled"`                // Default true
+	Description *string  `json:"description"`
+}
+
+// ListWebhooksResponse represents the response for listing webhooks
+type ListWebhooksResponse struct {
+	Webhooks   []database.Webhook `json:"webhooks" jsonschema:"required"`
+	EventTypes []string           `json:"eventTypes" jsonschema:"required"` // Events webhooks can subscribe to
+}
+
+// CreateWebhookResponse represents a created webhook with its secret, which
+// is not returned again
+type CreateWebhookResponse struct {
+	Webhook *database.Webhook `json:"webhook" jsonschema:"required"`
+	Secret  string            `json:"secret" jsonschema:"required"`
+}
+
+// ListWebhookDeliveriesResponse represents a webhook's recent deliveries
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []database.WebhookDelivery `json:"deliveries" jsonschema:"required"`
+}
+
+// ListWebhooks returns all webhooks
+// @Summary List webhooks
+// @Description Returns every webhook notified of ingestion run events, and the event types webhooks can subscribe to. Secrets are not returned.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Success 200 {object} ListWebhooksResponse
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/webhooks [get]
+func ListWebhooks(c *gin.Context) {
+	list, err := database.ListWebhooks(c.Request.Context())
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to list webhooks")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListWebhooksResponse{Webhooks: list, EventTypes: webhooks.EventTypes})
+}
+
+// GetWebhook returns a webhook
+// @Summary Get webhook
+// @Description Returns a webhook's URL, subscribed event types and enabled state. The secret is not returned.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} database.Webhook
+// @Failure 404 {object} map[string]string "Webhook not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/webhooks/{id} [get]
+func GetWebhook(c *gin.Context) {
+	webhook, err := database.GetWebhook(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to fetch webhook")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhook"})
+		return
+	}
+	if webhook == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// CreateWebhook registers a webhook
+// @Summary Create webhook
+// @Description Registers a URL to be notified of ingestion run events. Each delivery is a POST of the event as JSON with X-Webhook-Id, X-Webhook-Delivery-Id and X-Webhook-Event-Type headers and an X-Webhook-Signature of "sha256=" followed by the hex HMAC-SHA256 of the body under the webhook's secret. A secret is generated when none is given; it is returned only in this response. Delivery is at-least-once, so receivers should deduplicate on the delivery ID.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param request body WebhookRequest true "Webhook settings"
+// @Success 201 {object} CreateWebhookResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/webhooks [post]
+func CreateWebhook(c *gin.Context) {
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateWebhookRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook := webhookFromRequest(cuid2.GeneratePrefixedId("whk", cuid2.PrefixedIdOptions{}), &req)
+	if webhook.Secret == "" {
+		secret, err := webhooks.NewSecret()
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to generate webhook secret")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+			return
+		}
+		webhook.Secret = secret
+	}
+
+	created, err := database.CreateWebhook(c.Request.Context(), webhook)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to create webhook")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateWebhookResponse{Webhook: created, Secret: webhook.Secret})
+}
+
+// UpdateWebhook replaces a webhook's settings
+// @Summary Update webhook
+// @Description Replaces a webhook's URL, event types, enabled state and description. The secret is replaced only when one is given. Pending deliveries of a disabled webhook wait until it is enabled again.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param request body WebhookRequest true "Webhook settings"
+// @Success 200 {object} database.Webhook
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 404 {object} map[string]string "Webhook not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/webhooks/{id} [put]
+func UpdateWebhook(c *gin.Context) {
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateWebhookRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := database.UpdateWebhook(c.Request.Context(), webhookFromRequest(c.Param("id"), &req))
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to update webhook")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook"})
+		return
+	}
+	if updated == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteWebhook removes a webhook
+// @Summary Delete webhook
+// @Description Removes a webhook together with its pending and past deliveries
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string "Webhook not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/webhooks/{id} [delete]
+func DeleteWebhook(c *gin.Context) {
+	id := c.Param("id")
+
+	deleted, err := database.DeleteWebhook(c.Request.Context(), id)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to delete webhook")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Webhook deleted successfully",
+		"id":      id,
+	})
+}
+
+// ListWebhookDeliveries returns a webhook's recent deliveries
+// @Summary List webhook deliveries
+// @Description Returns a webhook's most recent deliveries, newest first, with their attempts and the status and error of the last one. Delivered and given-up deliveries are pruned after seven days.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param limit query int false "Maximum deliveries (default 50, max 500)"
+// @Success 200 {object} ListWebhookDeliveriesResponse
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 404 {object} map[string]string "Webhook not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /internal/admin/webhooks/{id}/deliveries [get]
+func ListWebhookDeliveries(c *gin.Context) {
+	id := c.Param("id")
+
+	limit := defaultWebhookDeliveries
+	if param := c.Query("limit"); param != "" {
+		parsed, err := strconv.Atoi(param)
+		if err != nil || parsed < 1 || parsed > maxWebhookDeliveries {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+			return
+		}
+		limit = parsed
+	}
+
+	webhook, err := database.GetWebhook(c.Request.Context(), id)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to fetch webhook")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list webhook deliveries"})
+		return
+	}
+	if webhook == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	deliveries, err := database.ListWebhookDeliveries(c.Request.Context(), id, limit)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to list webhook deliveries")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list webhook deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListWebhookDeliveriesResponse{Deliveries: deliveries})
+}
+
+// validateWebhookRequest checks a webhook's URL and event types
+func validateWebhookRequest(req *WebhookRequest) error {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	for _, eventType := range req.EventTypes {
+		if !webhooks.ValidEventType(eventType) {
+			return fmt.Errorf("unknown event type %q", eventType)
+		}
+	}
+	return nil
+}
+
+// webhookFromRequest builds the webhook a request describes
+func webhookFromRequest(id string, req *WebhookRequest) *database.Webhook {
+	eventTypes := req.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+	return &database.Webhook{
+		ID:          id,
+		URL:         req.URL,
+		Secret:      req.Secret,
+		EventTypes:  eventTypes,
+		Enabled:     req.Enabled == nil || *req.Enabled,
+		Description: req.Description,
+	}
+}
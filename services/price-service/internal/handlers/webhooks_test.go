@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/kosarica/price-service/internal/outbox"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateWebhookRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     WebhookRequest
+		wantErr string
+	}{
+		{
+			name: "valid",
+			req:  WebhookRequest{URL: "https://ops.example.com/hooks", EventTypes: []string{outbox.EventRunFailed}},
+		},
+		{
+			name: "every event",
+			req:  WebhookRequest{URL: "http://localhost:8080/hook"},
+		},
+		{
+			name:    "relative url",
+			req:     WebhookRequest{URL: "/hooks"},
+			wantErr: "url must be an absolute http or https URL",
+		},
+		{
+			name:    "unsupported scheme",
+			req:     WebhookRequest{URL: "ftp://ops.example.com/hooks"},
+			wantErr: "url must be an absolute http or https URL",
+		},
+		{
+			name:    "unknown event type",
+			req:     WebhookRequest{URL: "https://ops.example.com/hooks", EventTypes: []string{outbox.EventRunCompleted, "prices.changed"}},
+			wantErr: `unknown event type "prices.changed"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWebhookRequest(&tt.req)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/kosarica/price-service/internal/http/ratelimit"
@@ -16,6 +17,24 @@ type Client struct {
 	httpClient  *http.Client
 	rateLimiter *ratelimit.RateLimiter
 	config      ratelimit.Config
+
+	// Traffic counters for ingestion cost accounting
+	requests        atomic.Int64
+	bytesDownloaded atomic.Int64
+}
+
+// TrafficStats is a snapshot of the traffic generated by a client
+type TrafficStats struct {
+	Requests        int64
+	BytesDownloaded int64
+}
+
+// Sub returns the traffic generated between an earlier snapshot and s
+func (s TrafficStats) Sub(earlier TrafficStats) TrafficStats {
+	return TrafficStats{
+		Requests:        s.Requests - earlier.Requests,
+		BytesDownloaded: s.BytesDownloaded - earlier.BytesDownloaded,
+	}
 }
 
 // NewClient creates a new HTTP client with rate limiting
@@ -71,6 +90,7 @@ func (c *Client) Do(method, url string, body io.Reader) (*http.Response, error)
 		req.Header.Set("Accept", "*/*")
 
 		// Execute request
+		c.requests.Add(1)
 		resp, err := c.httpClient.Do(req)
 		if err != nil {
 			lastErr = err
@@ -89,8 +109,9 @@ func (c *Client) Do(method, url string, body io.Reader) (*http.Response, error)
 		// Check status
 		lastStatus = resp.StatusCode
 
-		// Success - return immediately
+		// Success - return immediately, counting body bytes as they are read
 		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			resp.Body = &countingReadCloser{ReadCloser: resp.Body, counter: &c.bytesDownloaded}
 			return resp, nil
 		}
 
@@ -160,6 +181,26 @@ func (c *Client) GetBytes(url string) ([]byte, error) {
 	return data, nil
 }
 
+// TrafficStats returns the requests made and response bytes read so far
+func (c *Client) TrafficStats() TrafficStats {
+	return TrafficStats{
+		Requests:        c.requests.Load(),
+		BytesDownloaded: c.bytesDownloaded.Load(),
+	}
+}
+
+// countingReadCloser counts bytes read from a response body
+type countingReadCloser struct {
+	io.ReadCloser
+	counter *atomic.Int64
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.counter.Add(int64(n))
+	return n, err
+}
+
 // GetConfig returns the current rate limit config
 func (c *Client) GetConfig() ratelimit.Config {
 	return c.config
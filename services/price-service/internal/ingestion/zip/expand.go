@@ -45,6 +45,7 @@ func DefaultExpandOptions() ExpandOptions {
 			".csv", ".CSV",
 			".xml", ".XML",
 			".xlsx", ".XLSX",
+			".json", ".JSON",
 		},
 		SkipPatterns: []string{
 			"__MACOSX",
@@ -333,6 +334,8 @@ func detectFileType(filename string) types.FileType {
 		return types.FileTypeXML
 	case ".xlsx", ".xls":
 		return types.FileTypeXLSX
+	case ".json":
+		return types.FileTypeJSON
 	case ".zip":
 		return types.FileTypeZIP
 	default:
@@ -348,6 +351,8 @@ func detectContentType(filename string) string {
 		return "text/csv"
 	case ".xml":
 		return "application/xml"
+	case ".json":
+		return "application/json"
 	case ".xlsx":
 		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
 	case ".xls":
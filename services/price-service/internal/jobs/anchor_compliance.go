@@ -0,0 +1,209 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kosarica/price-service/internal/lanes"
+	"github.com/kosarica/price-service/internal/pkg/clock"
+	"github.com/rs/zerolog"
+)
+
+// AnchorComplianceStats counts a chain's store prices on an active discount
+// that break the anchor price rule: no anchor price, or an anchor price set
+// fewer than MinAnchorAgeDays before the report
+type AnchorComplianceStats struct {
+	ChainSlug         string    `json:"chainSlug" jsonschema:"required"`
+	ComputedAt        time.Time `json:"computedAt" jsonschema:"required"`
+	MinAnchorAgeDays  int       `json:"minAnchorAgeDays" jsonschema:"required"`
+	DiscountedPrices  int       `json:"discountedPrices" jsonschema:"required"`
+	MissingAnchor     int       `json:"missingAnchor" jsonschema:"required"`
+	YoungAnchor       int       `json:"youngAnchor" jsonschema:"required"`
+	MissingShare      float64   `json:"missingAnchorShare" jsonschema:"required"` // 0-1 of the discounted prices
+	YoungShare        float64   `json:"youngAnchorShare" jsonschema:"required"`   // 0-1 of the discounted prices
+	NonCompliantShare float64   `json:"nonCompliantShare" jsonschema:"required"`  // 0-1 of the discounted prices
+}
+
+// derive fills in the shares of the discounted prices
+func (s *AnchorComplianceStats) derive() {
+	if s.DiscountedPrices == 0 {
+		return
+	}
+	total := float64(s.DiscountedPrices)
+	s.MissingShare = float64(s.MissingAnchor) / total
+	s.YoungShare = float64(s.YoungAnchor) / total
+	s.NonCompliantShare = float64(s.MissingAnchor+s.YoungAnchor) / total
+}
+
+// ComputeAnchorCompliance counts, per chain with discounts active at now,
+// the discounted store prices missing an anchor price or with one younger
+// than minAgeDays. Anchor prices without a date count as old enough.
+func ComputeAnchorCompliance(ctx context.Context, pool *pgxpool.Pool, now time.Time, minAgeDays int) ([]AnchorComplianceStats, error) {
+	cutoff := now.AddDate(0, 0, -minAgeDays)
+
+	rows, err := pool.Query(ctx, `
+		SELECT
+			s.chain_slug,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE sis.anchor_price IS NULL OR sis.anchor_price <= 0),
+			COUNT(*) FILTER (WHERE sis.anchor_price > 0 AND sis.anchor_price_as_of > $2)
+		FROM store_item_state sis
+		JOIN stores s ON s.id = sis.store_id
+		WHERE sis.discount_price IS NOT NULL
+		  AND sis.discount_price < sis.current_price
+		  AND discount_active(sis.discount_start, sis.discount_end, $1, $3)
+		GROUP BY s.chain_slug
+		ORDER BY s.chain_slug
+	`, now, cutoff, clock.Zone().String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute anchor price compliance: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make([]AnchorComplianceStats, 0)
+	for rows.Next() {
+		s := AnchorComplianceStats{ComputedAt: now, MinAnchorAgeDays: minAgeDays}
+		if err := rows.Scan(&s.ChainSlug, &s.DiscountedPrices, &s.MissingAnchor, &s.YoungAnchor); err != nil {
+			return nil, fmt.Errorf("failed to scan anchor price compliance: %w", err)
+		}
+		s.derive()
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// RecordAnchorCompliance stores a report's chain stats in the history
+func RecordAnchorCompliance(ctx context.Context, pool *pgxpool.Pool, stats []AnchorComplianceStats) error {
+	if len(stats) == 0 {
+		return nil
+	}
+	batch := &pgx.Batch{}
+	for _, s := range stats {
+		batch.Queue(`
+			INSERT INTO anchor_price_compliance_reports (
+				chain_slug, computed_at, min_anchor_age_days,
+				discounted_prices, missing_anchor, young_anchor
+			) VALUES ($1, $2, $3, $4, $5, $6)
+		`, s.ChainSlug, s.ComputedAt, s.MinAnchorAgeDays, s.DiscountedPrices, s.MissingAnchor, s.YoungAnchor)
+	}
+	if err := pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to record anchor price compliance: %w", err)
+	}
+	return nil
+}
+
+// QueryAnchorComplianceHistory returns the stored reports computed since
+// from, newest first, optionally for one chain
+func QueryAnchorComplianceHistory(ctx context.Context, pool *pgxpool.Pool, from time.Time, chainSlug string) ([]AnchorComplianceStats, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT chain_slug, computed_at, min_anchor_age_days,
+		       discounted_prices, missing_anchor, young_anchor
+		FROM anchor_price_compliance_reports
+		WHERE computed_at >= $1
+		  AND ($2 = '' OR chain_slug = $2)
+		ORDER BY computed_at DESC, chain_slug
+	`, from, chainSlug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query anchor price compliance: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make([]AnchorComplianceStats, 0)
+	for rows.Next() {
+		var s AnchorComplianceStats
+		if err := rows.Scan(&s.ChainSlug, &s.ComputedAt, &s.MinAnchorAgeDays, &s.DiscountedPrices, &s.MissingAnchor, &s.YoungAnchor); err != nil {
+			return nil, fmt.Errorf("failed to scan anchor price compliance: %w", err)
+		}
+		s.derive()
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// AnchorComplianceJob periodically computes and stores the anchor price
+// compliance report
+type AnchorComplianceJob struct {
+	pool       *pgxpool.Pool
+	logger     *zerolog.Logger
+	minAgeDays int
+	interval   time.Duration
+	stopChan   chan struct{}
+}
+
+// NewAnchorComplianceJob creates a new anchor price compliance job
+func NewAnchorComplianceJob(pool *pgxpool.Pool, logger *zerolog.Logger, minAgeDays int, interval time.Duration) *AnchorComplianceJob {
+	return &AnchorComplianceJob{
+		pool:       pool,
+		logger:     logger,
+		minAgeDays: minAgeDays,
+		interval:   interval,
+		stopChan:   make(chan struct{}),
+	}
+}
+
+// Start computes a report on every interval
+func (j *AnchorComplianceJob) Start(ctx context.Context) {
+	j.logger.Info().
+		Dur("interval", j.interval).
+		Int("minAnchorAgeDays", j.minAgeDays).
+		Msg("Starting anchor price compliance job")
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			j.logger.Info().Msg("Anchor price compliance job stopping (context cancelled)")
+			return
+		case <-j.stopChan:
+			j.logger.Info().Msg("Anchor price compliance job stopping (stop signal)")
+			return
+		case <-ticker.C:
+			if err := j.RunOnce(ctx); err != nil {
+				j.logger.Error().Err(err).Msg("Failed to build anchor price compliance report")
+			}
+		}
+	}
+}
+
+// Stop signals the job to stop
+func (j *AnchorComplianceJob) Stop() {
+	close(j.stopChan)
+}
+
+// RunOnce computes the report as of now and stores it
+func (j *AnchorComplianceJob) RunOnce(ctx context.Context) error {
+	ctx = lanes.WithLane(ctx, lanes.Batch)
+	release, err := lanes.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	stats, err := ComputeAnchorCompliance(ctx, j.pool, time.Now().UTC(), j.minAgeDays)
+	if err != nil {
+		return err
+	}
+	if err := RecordAnchorCompliance(ctx, j.pool, stats); err != nil {
+		return err
+	}
+
+	for _, s := range stats {
+		if s.MissingAnchor+s.YoungAnchor == 0 {
+			continue
+		}
+		j.logger.Warn().
+			Str("chain", s.ChainSlug).
+			Int("discountedPrices", s.DiscountedPrices).
+			Int("missingAnchor", s.MissingAnchor).
+			Int("youngAnchor", s.YoungAnchor).
+			Msg("Discounted prices break the anchor price rule")
+	}
+
+	j.logger.Info().Int("chains", len(stats)).Msg("Built anchor price compliance report")
+	return nil
+}
@@ -0,0 +1,19 @@
+package jobs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnchorComplianceShares(t *testing.T) {
+	s := AnchorComplianceStats{DiscountedPrices: 200, MissingAnchor: 30, YoungAnchor: 10}
+	s.derive()
+	assert.InDelta(t, 0.15, s.MissingShare, 1e-9)
+	assert.InDelta(t, 0.05, s.YoungShare, 1e-9)
+	assert.InDelta(t, 0.2, s.NonCompliantShare, 1e-9)
+
+	empty := AnchorComplianceStats{}
+	empty.derive()
+	assert.Zero(t, empty.NonCompliantShare)
+}
@@ -8,15 +8,21 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// cleanupExpiredExceptionsImpl removes expired price exceptions from the database
+// expiredExceptionRetention is how long expired price exceptions are kept.
+// Store price deltas report the prices items revert to from them, so it must
+// not be shorter than database.PriceDeltaRetention.
+const expiredExceptionRetention = 7 * 24 * time.Hour
+
+// cleanupExpiredExceptionsImpl removes price exceptions expired longer than
+// expiredExceptionRetention ago from the database
 // Returns the number of exceptions deleted
 func cleanupExpiredExceptionsImpl(ctx context.Context) (int, error) {
 	pool := getPool()
 
 	result, err := pool.Exec(ctx, `
 		DELETE FROM store_price_exceptions
-		WHERE expires_at <= NOW()
-	`)
+		WHERE expires_at <= $1
+	`, time.Now().Add(-expiredExceptionRetention))
 
 	if err != nil {
 		return 0, fmt.Errorf("failed to cleanup expired exceptions: %w", err)
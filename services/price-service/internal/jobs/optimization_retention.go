@@ -0,0 +1,93 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kosarica/price-service/internal/lanes"
+	"github.com/rs/zerolog"
+)
+
+// OptimizationRetentionJob periodically deletes stored optimization results
+// older than the retention period
+type OptimizationRetentionJob struct {
+	pool      *pgxpool.Pool
+	logger    *zerolog.Logger
+	retention time.Duration
+	interval  time.Duration
+	stopChan  chan struct{}
+}
+
+// NewOptimizationRetentionJob creates a new optimization result retention job
+func NewOptimizationRetentionJob(pool *pgxpool.Pool, logger *zerolog.Logger, retentionDays int, interval time.Duration) *OptimizationRetentionJob {
+	return &OptimizationRetentionJob{
+		pool:      pool,
+		logger:    logger,
+		retention: time.Duration(retentionDays) * 24 * time.Hour,
+		interval:  interval,
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// Start applies retention immediately and then on every interval
+func (j *OptimizationRetentionJob) Start(ctx context.Context) {
+	j.logger.Info().
+		Dur("retention", j.retention).
+		Dur("interval", j.interval).
+		Msg("Starting optimization result retention job")
+
+	if err := j.RunOnce(ctx); err != nil {
+		j.logger.Error().Err(err).Msg("Failed to apply optimization result retention")
+	}
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			j.logger.Info().Msg("Optimization result retention job stopping (context cancelled)")
+			return
+		case <-j.stopChan:
+			j.logger.Info().Msg("Optimization result retention job stopping (stop signal)")
+			return
+		case <-ticker.C:
+			if err := j.RunOnce(ctx); err != nil {
+				j.logger.Error().Err(err).Msg("Failed to apply optimization result retention")
+			}
+		}
+	}
+}
+
+// Stop signals the job to stop
+func (j *OptimizationRetentionJob) Stop() {
+	close(j.stopChan)
+}
+
+// RunOnce deletes optimization results older than the retention period
+func (j *OptimizationRetentionJob) RunOnce(ctx context.Context) error {
+	ctx = lanes.WithLane(ctx, lanes.Batch)
+	release, err := lanes.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	cutoff := time.Now().Add(-j.retention)
+
+	tag, err := j.pool.Exec(ctx, `DELETE FROM optimization_results WHERE created_at < $1`, cutoff)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired optimization results: %w", err)
+	}
+	deleted := tag.RowsAffected()
+
+	if deleted > 0 {
+		j.logger.Info().
+			Int64("deleted", deleted).
+			Time("cutoff", cutoff).
+			Msg("Deleted expired optimization results")
+	}
+	return nil
+}
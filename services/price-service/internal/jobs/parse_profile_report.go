@@ -0,0 +1,249 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kosarica/price-service/internal/lanes"
+	"github.com/rs/zerolog"
+)
+
+// DefaultParseRegressionThreshold is the relative worsening of a chain's
+// parse throughput, CPU or allocations per row that counts as a regression
+const DefaultParseRegressionThreshold = 0.2
+
+// ParseProfileStats aggregates the profiled parses of a chain over a window
+type ParseProfileStats struct {
+	ChainSlug     string  `json:"chainSlug" jsonschema:"required"`
+	Files         int     `json:"files" jsonschema:"required"`
+	Rows          int64   `json:"rows" jsonschema:"required"`
+	CPUSeconds    float64 `json:"cpuSeconds" jsonschema:"required"`
+	WallSeconds   float64 `json:"wallSeconds" jsonschema:"required"`
+	Allocs        int64   `json:"allocs" jsonschema:"required"`
+	AllocBytes    int64   `json:"allocBytes" jsonschema:"required"`
+	RowsPerSecond float64 `json:"rowsPerSecond" jsonschema:"required"`
+	CPUPerRow     float64 `json:"cpuMicrosPerRow" jsonschema:"required"`
+	AllocsPerRow  float64 `json:"allocsPerRow" jsonschema:"required"`
+}
+
+// ParseProfileComparison compares a chain's current window to the previous one
+type ParseProfileComparison struct {
+	ChainSlug           string             `json:"chainSlug" jsonschema:"required"`
+	Current             ParseProfileStats  `json:"current" jsonschema:"required"`
+	Previous            *ParseProfileStats `json:"previous,omitempty"`
+	RowsPerSecondChange *float64           `json:"rowsPerSecondChange,omitempty"` // relative, negative is slower
+	CPUPerRowChange     *float64           `json:"cpuPerRowChange,omitempty"`     // relative, positive is costlier
+	AllocsPerRowChange  *float64           `json:"allocsPerRowChange,omitempty"`  // relative, positive is costlier
+	Regressed           bool               `json:"regressed" jsonschema:"required"`
+}
+
+// ParseProfileReport is the weekly parser performance report
+type ParseProfileReport struct {
+	From      time.Time                `json:"from" jsonschema:"required"`
+	To        time.Time                `json:"to" jsonschema:"required"`
+	Threshold float64                  `json:"threshold" jsonschema:"required"`
+	Chains    []ParseProfileComparison `json:"chains" jsonschema:"required"`
+}
+
+// QueryParseProfileStats aggregates the parse profiles stored in file
+// metadata per chain for files created in [from, to)
+func QueryParseProfileStats(ctx context.Context, pool *pgxpool.Pool, from, to time.Time, chainSlug string) ([]ParseProfileStats, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT
+			r.chain_slug,
+			COUNT(*),
+			COALESCE(SUM((f.metadata::jsonb -> 'parseProfile' ->> 'rows')::bigint), 0),
+			COALESCE(SUM((f.metadata::jsonb -> 'parseProfile' ->> 'cpuSeconds')::double precision), 0),
+			COALESCE(SUM((f.metadata::jsonb -> 'parseProfile' ->> 'wallSeconds')::double precision), 0),
+			COALESCE(SUM((f.metadata::jsonb -> 'parseProfile' ->> 'allocs')::bigint), 0),
+			COALESCE(SUM((f.metadata::jsonb -> 'parseProfile' ->> 'allocBytes')::bigint), 0)
+		FROM ingestion_files f
+		JOIN ingestion_runs r ON r.id = f.run_id
+		WHERE f.created_at >= $1 AND f.created_at < $2
+		  AND f.metadata IS NOT NULL
+		  AND f.metadata::jsonb ? 'parseProfile'
+		  AND ($3 = '' OR r.chain_slug = $3)
+		GROUP BY r.chain_slug
+		ORDER BY r.chain_slug
+	`, from, to, chainSlug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query parse profiles: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make([]ParseProfileStats, 0)
+	for rows.Next() {
+		var s ParseProfileStats
+		if err := rows.Scan(&s.ChainSlug, &s.Files, &s.Rows, &s.CPUSeconds, &s.WallSeconds, &s.Allocs, &s.AllocBytes); err != nil {
+			return nil, fmt.Errorf("failed to scan parse profile: %w", err)
+		}
+		s.derive()
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// derive fills in the per-row and per-second ratios
+func (s *ParseProfileStats) derive() {
+	if s.WallSeconds > 0 {
+		s.RowsPerSecond = float64(s.Rows) / s.WallSeconds
+	}
+	if s.Rows > 0 {
+		s.CPUPerRow = s.CPUSeconds * 1e6 / float64(s.Rows)
+		s.AllocsPerRow = float64(s.Allocs) / float64(s.Rows)
+	}
+}
+
+// BuildParseProfileReport compares the week ending at to with the week before
+func BuildParseProfileReport(ctx context.Context, pool *pgxpool.Pool, to time.Time, threshold float64) (*ParseProfileReport, error) {
+	from := to.AddDate(0, 0, -7)
+
+	current, err := QueryParseProfileStats(ctx, pool, from, to, "")
+	if err != nil {
+		return nil, err
+	}
+	previous, err := QueryParseProfileStats(ctx, pool, from.AddDate(0, 0, -7), from, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &ParseProfileReport{
+		From:      from,
+		To:        to,
+		Threshold: threshold,
+		Chains:    CompareParseProfiles(current, previous, threshold),
+	}, nil
+}
+
+// CompareParseProfiles compares current chain stats with previous ones.
+// Regressed chains come first.
+func CompareParseProfiles(current, previous []ParseProfileStats, threshold float64) []ParseProfileComparison {
+	previousByChain := make(map[string]ParseProfileStats, len(previous))
+	for _, p := range previous {
+		previousByChain[p.ChainSlug] = p
+	}
+
+	comparisons := make([]ParseProfileComparison, 0, len(current))
+	for _, cur := range current {
+		c := ParseProfileComparison{ChainSlug: cur.ChainSlug, Current: cur}
+		if prev, ok := previousByChain[cur.ChainSlug]; ok {
+			c.Previous = &prev
+			c.RowsPerSecondChange = relativeChange(prev.RowsPerSecond, cur.RowsPerSecond)
+			c.CPUPerRowChange = relativeChange(prev.CPUPerRow, cur.CPUPerRow)
+			c.AllocsPerRowChange = relativeChange(prev.AllocsPerRow, cur.AllocsPerRow)
+			c.Regressed = (c.RowsPerSecondChange != nil && *c.RowsPerSecondChange < -threshold) ||
+				(c.CPUPerRowChange != nil && *c.CPUPerRowChange > threshold) ||
+				(c.AllocsPerRowChange != nil && *c.AllocsPerRowChange > threshold)
+		}
+		comparisons = append(comparisons, c)
+	}
+
+	sort.SliceStable(comparisons, func(i, j int) bool {
+		return comparisons[i].Regressed && !comparisons[j].Regressed
+	})
+	return comparisons
+}
+
+// relativeChange returns (cur-prev)/prev, or nil when prev is zero
+func relativeChange(prev, cur float64) *float64 {
+	if prev == 0 {
+		return nil
+	}
+	change := (cur - prev) / prev
+	return &change
+}
+
+// ParseProfileReportJob periodically builds the parser performance report
+// and logs chains whose parsing regressed
+type ParseProfileReportJob struct {
+	pool      *pgxpool.Pool
+	logger    *zerolog.Logger
+	threshold float64
+	interval  time.Duration
+	stopChan  chan struct{}
+}
+
+// NewParseProfileReportJob creates a new parser performance report job
+func NewParseProfileReportJob(pool *pgxpool.Pool, logger *zerolog.Logger, threshold float64, interval time.Duration) *ParseProfileReportJob {
+	return &ParseProfileReportJob{
+		pool:      pool,
+		logger:    logger,
+		threshold: threshold,
+		interval:  interval,
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// Start builds a report on every interval
+func (j *ParseProfileReportJob) Start(ctx context.Context) {
+	j.logger.Info().
+		Dur("interval", j.interval).
+		Float64("threshold", j.threshold).
+		Msg("Starting parse profile report job")
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			j.logger.Info().Msg("Parse profile report job stopping (context cancelled)")
+			return
+		case <-j.stopChan:
+			j.logger.Info().Msg("Parse profile report job stopping (stop signal)")
+			return
+		case <-ticker.C:
+			if err := j.RunOnce(ctx); err != nil {
+				j.logger.Error().Err(err).Msg("Failed to build parse profile report")
+			}
+		}
+	}
+}
+
+// Stop signals the job to stop
+func (j *ParseProfileReportJob) Stop() {
+	close(j.stopChan)
+}
+
+// RunOnce builds the report for the week ending now and logs regressions
+func (j *ParseProfileReportJob) RunOnce(ctx context.Context) error {
+	ctx = lanes.WithLane(ctx, lanes.Batch)
+	release, err := lanes.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	report, err := BuildParseProfileReport(ctx, j.pool, time.Now().UTC(), j.threshold)
+	if err != nil {
+		return err
+	}
+
+	regressed := 0
+	for _, c := range report.Chains {
+		if !c.Regressed {
+			continue
+		}
+		regressed++
+		event := j.logger.Warn().
+			Str("chain", c.ChainSlug).
+			Float64("rowsPerSecond", c.Current.RowsPerSecond).
+			Float64("allocsPerRow", c.Current.AllocsPerRow)
+		if c.RowsPerSecondChange != nil {
+			event = event.Float64("rowsPerSecondChange", *c.RowsPerSecondChange)
+		}
+		if c.AllocsPerRowChange != nil {
+			event = event.Float64("allocsPerRowChange", *c.AllocsPerRowChange)
+		}
+		event.Msg("Parser performance regressed")
+	}
+
+	j.logger.Info().
+		Int("chains", len(report.Chains)).
+		Int("regressed", regressed).
+		Msg("Built parse profile report")
+	return nil
+}
@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func profileStats(chain string, rows int64, cpu, wall float64, allocs int64) ParseProfileStats {
+	s := ParseProfileStats{ChainSlug: chain, Files: 1, Rows: rows, CPUSeconds: cpu, WallSeconds: wall, Allocs: allocs}
+	s.derive()
+	return s
+}
+
+func TestCompareParseProfiles(t *testing.T) {
+	previous := []ParseProfileStats{
+		profileStats("konzum", 1000, 1, 1, 10000),
+		profileStats("lidl", 1000, 1, 1, 10000),
+	}
+	current := []ParseProfileStats{
+		profileStats("konzum", 1000, 1, 1.1, 10500), // within threshold
+		profileStats("lidl", 1000, 1, 2, 10000),     // half the throughput
+		profileStats("spar", 1000, 1, 1, 10000),     // no baseline
+	}
+
+	comparisons := CompareParseProfiles(current, previous, 0.2)
+	require.Len(t, comparisons, 3)
+
+	assert.Equal(t, "lidl", comparisons[0].ChainSlug)
+	assert.True(t, comparisons[0].Regressed)
+	assert.InDelta(t, -0.5, *comparisons[0].RowsPerSecondChange, 1e-9)
+
+	assert.Equal(t, "konzum", comparisons[1].ChainSlug)
+	assert.False(t, comparisons[1].Regressed)
+	assert.InDelta(t, 0.05, *comparisons[1].AllocsPerRowChange, 1e-9)
+
+	assert.Equal(t, "spar", comparisons[2].ChainSlug)
+	assert.False(t, comparisons[2].Regressed)
+	assert.Nil(t, comparisons[2].Previous)
+	assert.Nil(t, comparisons[2].RowsPerSecondChange)
+}
@@ -0,0 +1,139 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kosarica/price-service/internal/lanes"
+	"github.com/rs/zerolog"
+)
+
+// priceHistoryCompactDays is how many recent days of price history are
+// compacted per run; older days were compacted by earlier runs
+const priceHistoryCompactDays = 7
+
+// PriceHistoryRetentionJob periodically deletes price history older than the
+// retention period and compacts the rows that repeat the price before them,
+// left by a price that changed and changed back within a day
+type PriceHistoryRetentionJob struct {
+	pool      *pgxpool.Pool
+	logger    *zerolog.Logger
+	retention time.Duration
+	interval  time.Duration
+	stopChan  chan struct{}
+}
+
+// NewPriceHistoryRetentionJob creates a new price history retention job.
+// A retentionDays of 0 keeps the history forever and only compacts it.
+func NewPriceHistoryRetentionJob(pool *pgxpool.Pool, logger *zerolog.Logger, retentionDays int, interval time.Duration) *PriceHistoryRetentionJob {
+	return &PriceHistoryRetentionJob{
+		pool:      pool,
+		logger:    logger,
+		retention: time.Duration(retentionDays) * 24 * time.Hour,
+		interval:  interval,
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// Start applies retention immediately and then on every interval
+func (j *PriceHistoryRetentionJob) Start(ctx context.Context) {
+	j.logger.Info().
+		Dur("retention", j.retention).
+		Dur("interval", j.interval).
+		Msg("Starting price history retention job")
+
+	if err := j.RunOnce(ctx); err != nil {
+		j.logger.Error().Err(err).Msg("Failed to apply price history retention")
+	}
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			j.logger.Info().Msg("Price history retention job stopping (context cancelled)")
+			return
+		case <-j.stopChan:
+			j.logger.Info().Msg("Price history retention job stopping (stop signal)")
+			return
+		case <-ticker.C:
+			if err := j.RunOnce(ctx); err != nil {
+				j.logger.Error().Err(err).Msg("Failed to apply price history retention")
+			}
+		}
+	}
+}
+
+// Stop signals the job to stop
+func (j *PriceHistoryRetentionJob) Stop() {
+	close(j.stopChan)
+}
+
+// RunOnce deletes the price history older than the retention period, keeping
+// each item's last row before the cutoff as the price its series starts at,
+// and compacts the recent days
+func (j *PriceHistoryRetentionJob) RunOnce(ctx context.Context) error {
+	ctx = lanes.WithLane(ctx, lanes.Batch)
+	release, err := lanes.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	now := time.Now()
+
+	var expired int64
+	if j.retention > 0 {
+		cutoff := now.Add(-j.retention)
+		tag, err := j.pool.Exec(ctx, `
+			DELETE FROM price_history ph
+			WHERE ph.day < $1::date
+			  AND EXISTS (
+				SELECT 1 FROM price_history later
+				WHERE later.store_id = ph.store_id
+				  AND later.retailer_item_id = ph.retailer_item_id
+				  AND later.day > ph.day
+				  AND later.day <= $1::date
+			  )
+		`, cutoff)
+		if err != nil {
+			return fmt.Errorf("failed to delete expired price history: %w", err)
+		}
+		expired = tag.RowsAffected()
+	}
+
+	tag, err := j.pool.Exec(ctx, `
+		DELETE FROM price_history ph
+		WHERE ph.day >= $1::date
+		  AND EXISTS (
+			SELECT 1
+			FROM (
+				SELECT prev.price, prev.discount_price, prev.unit_price
+				FROM price_history prev
+				WHERE prev.store_id = ph.store_id
+				  AND prev.retailer_item_id = ph.retailer_item_id
+				  AND prev.day < ph.day
+				ORDER BY prev.day DESC
+				LIMIT 1
+			) p
+			WHERE p.price = ph.price
+			  AND p.discount_price IS NOT DISTINCT FROM ph.discount_price
+			  AND p.unit_price IS NOT DISTINCT FROM ph.unit_price
+		  )
+	`, now.AddDate(0, 0, -priceHistoryCompactDays))
+	if err != nil {
+		return fmt.Errorf("failed to compact price history: %w", err)
+	}
+	compacted := tag.RowsAffected()
+
+	if expired > 0 || compacted > 0 {
+		j.logger.Info().
+			Int64("expired", expired).
+			Int64("compacted", compacted).
+			Msg("Applied price history retention")
+	}
+	return nil
+}
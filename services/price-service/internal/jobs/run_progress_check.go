@@ -0,0 +1,137 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kosarica/price-service/internal/lanes"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog"
+)
+
+// RunProgressCheckLookback is how far back runs are checked. Older runs were
+// checked while they were recent.
+const RunProgressCheckLookback = 7 * 24 * time.Hour
+
+var runProgressViolations = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "ingestion_run_progress_violations_total",
+	Help: "Ingestion runs found with processed files or entries above their totals",
+})
+
+// RunProgressCheckJob periodically flags ingestion runs whose progress
+// counters exceed their totals. That should never happen; when it does, a
+// counter was incremented twice or a total was recorded too low.
+type RunProgressCheckJob struct {
+	pool     *pgxpool.Pool
+	logger   *zerolog.Logger
+	interval time.Duration
+	stopChan chan struct{}
+}
+
+// NewRunProgressCheckJob creates a new run progress invariant check job
+func NewRunProgressCheckJob(pool *pgxpool.Pool, logger *zerolog.Logger, interval time.Duration) *RunProgressCheckJob {
+	return &RunProgressCheckJob{
+		pool:     pool,
+		logger:   logger,
+		interval: interval,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start checks runs immediately and then on every interval
+func (j *RunProgressCheckJob) Start(ctx context.Context) {
+	j.logger.Info().
+		Dur("interval", j.interval).
+		Msg("Starting run progress check job")
+
+	if err := j.RunOnce(ctx); err != nil {
+		j.logger.Error().Err(err).Msg("Failed to check run progress")
+	}
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			j.logger.Info().Msg("Run progress check job stopping (context cancelled)")
+			return
+		case <-j.stopChan:
+			j.logger.Info().Msg("Run progress check job stopping (stop signal)")
+			return
+		case <-ticker.C:
+			if err := j.RunOnce(ctx); err != nil {
+				j.logger.Error().Err(err).Msg("Failed to check run progress")
+			}
+		}
+	}
+}
+
+// Stop signals the job to stop
+func (j *RunProgressCheckJob) Stop() {
+	close(j.stopChan)
+}
+
+// RunOnce flags runs in the lookback window whose processed counts exceed
+// their totals. Each run is flagged once, in metadata.progress_violation with
+// the counters seen, and logged as a warning.
+func (j *RunProgressCheckJob) RunOnce(ctx context.Context) error {
+	ctx = lanes.WithLane(ctx, lanes.Batch)
+	release, err := lanes.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	rows, err := j.pool.Query(ctx, `
+		UPDATE ingestion_runs
+		SET metadata = jsonb_set(
+		        COALESCE(metadata, '{}'::jsonb),
+		        '{progress_violation}',
+		        jsonb_build_object(
+		            'processed_files', processed_files,
+		            'total_files', total_files,
+		            'processed_entries', processed_entries,
+		            'total_entries', total_entries,
+		            'detected_at', NOW()
+		        )
+		    )
+		WHERE created_at >= $1
+		  AND (processed_files > total_files OR processed_entries > total_entries)
+		  AND NOT (COALESCE(metadata, '{}'::jsonb) ? 'progress_violation')
+		RETURNING id::text, chain_slug, processed_files, total_files, processed_entries, total_entries
+	`, time.Now().Add(-RunProgressCheckLookback))
+	if err != nil {
+		return fmt.Errorf("failed to check run progress: %w", err)
+	}
+	defer rows.Close()
+
+	flagged := 0
+	for rows.Next() {
+		var runID string
+		var chainSlug string
+		var processedFiles, totalFiles, processedEntries, totalEntries *int
+		if err := rows.Scan(&runID, &chainSlug, &processedFiles, &totalFiles, &processedEntries, &totalEntries); err != nil {
+			return fmt.Errorf("failed to scan flagged run: %w", err)
+		}
+		flagged++
+
+		event := j.logger.Warn().Str("run_id", runID).Str("chain", chainSlug)
+		if processedFiles != nil && totalFiles != nil {
+			event = event.Int("processed_files", *processedFiles).Int("total_files", *totalFiles)
+		}
+		if processedEntries != nil && totalEntries != nil {
+			event = event.Int("processed_entries", *processedEntries).Int("total_entries", *totalEntries)
+		}
+		event.Msg("Run progress exceeds its totals")
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to check run progress: %w", err)
+	}
+
+	runProgressViolations.Add(float64(flagged))
+	return nil
+}
@@ -0,0 +1,272 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kosarica/price-service/internal/lanes"
+	"github.com/rs/zerolog"
+)
+
+// StatsRollupLookback is how far behind the watermark each pass recomputes.
+// Runs are bucketed by created_at but keep changing status and counters until
+// they finish, so recent hours are rebuilt until they settle. Older hours are
+// rebuilt when one of their runs changes.
+const StatsRollupLookback = 48 * time.Hour
+
+// statsRollupChangeMargin widens the search for runs changed since the last
+// pass, to also catch updates committed while it ran
+const statsRollupChangeMargin = 5 * time.Minute
+
+// StatsUnknownChain is the chain errors whose run no longer exists are
+// rolled up under
+const StatsUnknownChain = "unknown"
+
+// StatsRollupJob periodically aggregates ingestion runs into ingestion_stats_hourly
+type StatsRollupJob struct {
+	pool     *pgxpool.Pool
+	logger   *zerolog.Logger
+	interval time.Duration
+	stopChan chan struct{}
+}
+
+// NewStatsRollupJob creates a new hourly stats rollup job
+func NewStatsRollupJob(pool *pgxpool.Pool, logger *zerolog.Logger, interval time.Duration) *StatsRollupJob {
+	return &StatsRollupJob{
+		pool:     pool,
+		logger:   logger,
+		interval: interval,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start runs a rollup immediately and then on every interval
+func (j *StatsRollupJob) Start(ctx context.Context) {
+	j.logger.Info().
+		Dur("interval", j.interval).
+		Msg("Starting stats rollup job")
+
+	if err := j.RunOnce(ctx); err != nil {
+		j.logger.Error().Err(err).Msg("Failed to roll up ingestion stats")
+	}
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			j.logger.Info().Msg("Stats rollup job stopping (context cancelled)")
+			return
+		case <-j.stopChan:
+			j.logger.Info().Msg("Stats rollup job stopping (stop signal)")
+			return
+		case <-ticker.C:
+			if err := j.RunOnce(ctx); err != nil {
+				j.logger.Error().Err(err).Msg("Failed to roll up ingestion stats")
+			}
+		}
+	}
+}
+
+// Stop signals the job to stop
+func (j *StatsRollupJob) Stop() {
+	close(j.stopChan)
+}
+
+// RunOnce rebuilds rollups from the lookback window up to the last full hour,
+// and the earlier hours holding runs that changed since the last pass
+func (j *StatsRollupJob) RunOnce(ctx context.Context) error {
+	ctx = lanes.WithLane(ctx, lanes.Batch)
+	release, err := lanes.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	to := time.Now().UTC().Truncate(time.Hour)
+
+	var watermark, lastPass *time.Time
+	err = j.pool.QueryRow(ctx, `
+		SELECT rolled_up_to, updated_at FROM ingestion_stats_rollup_state WHERE id
+	`).Scan(&watermark, &lastPass)
+	if err != nil && err != pgx.ErrNoRows {
+		return fmt.Errorf("failed to read rollup watermark: %w", err)
+	}
+
+	var from time.Time
+	if watermark != nil {
+		from = watermark.Add(-StatsRollupLookback)
+	} else {
+		// First pass: start from the oldest run
+		var oldest *time.Time
+		if err := j.pool.QueryRow(ctx, `SELECT MIN(created_at) FROM ingestion_runs`).Scan(&oldest); err != nil {
+			return fmt.Errorf("failed to find oldest run: %w", err)
+		}
+		if oldest == nil {
+			from = to
+		} else {
+			from = oldest.Truncate(time.Hour)
+		}
+	}
+
+	start := time.Now()
+	rows, err := RollupIngestionStats(ctx, j.pool, from, to)
+	if err != nil {
+		return err
+	}
+
+	var changedHours []time.Time
+	if lastPass != nil {
+		changedHours, err = changedRollupHours(ctx, j.pool, lastPass.Add(-statsRollupChangeMargin), from)
+		if err != nil {
+			return err
+		}
+		for _, hour := range changedHours {
+			hourRows, err := RollupIngestionStats(ctx, j.pool, hour, hour.Add(time.Hour))
+			if err != nil {
+				return err
+			}
+			rows += hourRows
+		}
+	}
+
+	j.logger.Info().
+		Time("from", from).
+		Time("to", to).
+		Int("changed_hours", len(changedHours)).
+		Int64("rows", rows).
+		Dur("duration", time.Since(start)).
+		Msg("Rolled up ingestion stats")
+
+	return nil
+}
+
+// changedRollupHours returns the hours before "before" holding runs updated
+// since "since", whose rollups are out of date
+func changedRollupHours(ctx context.Context, db *pgxpool.Pool, since, before time.Time) ([]time.Time, error) {
+	rows, err := db.Query(ctx, `
+		SELECT DISTINCT date_trunc('hour', created_at)
+		FROM ingestion_runs
+		WHERE updated_at >= $1 AND created_at < $2
+		ORDER BY 1
+	`, since, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find changed runs: %w", err)
+	}
+	hours, err := pgx.CollectRows(rows, pgx.RowTo[time.Time])
+	if err != nil {
+		return nil, fmt.Errorf("failed to find changed runs: %w", err)
+	}
+	return hours, nil
+}
+
+// RollupIngestionStats replaces the hourly rollups for [from, to) and advances
+// the watermark to "to". Both bounds must be aligned to the hour.
+func RollupIngestionStats(ctx context.Context, db *pgxpool.Pool, from, to time.Time) (int64, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin rollup transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM ingestion_stats_hourly
+		WHERE hour_start >= $1 AND hour_start < $2
+	`, from, to); err != nil {
+		return 0, fmt.Errorf("failed to clear rollups: %w", err)
+	}
+
+	result, err := tx.Exec(ctx, `
+		WITH runs AS (
+			SELECT
+				date_trunc('hour', created_at) as hour_start,
+				chain_slug,
+				status,
+				COALESCE(total_files, 0) as total_files,
+				COALESCE(processed_files, 0) as processed_files,
+				COALESCE(processed_entries, 0) as processed_entries,
+				COALESCE(price_changes, 0) as price_changes,
+				CASE
+					WHEN started_at IS NOT NULL AND completed_at IS NOT NULL AND completed_at >= started_at
+					THEN EXTRACT(EPOCH FROM (completed_at - started_at))::double precision
+				END as duration_seconds
+			FROM ingestion_runs
+			WHERE created_at >= $1 AND created_at < $2
+		),
+		run_stats AS (
+			SELECT
+				hour_start,
+				chain_slug,
+				COUNT(*) as total_runs,
+				COUNT(*) FILTER (WHERE status = 'completed') as completed,
+				COUNT(*) FILTER (WHERE status = 'failed') as failed,
+				COUNT(*) FILTER (WHERE status = 'running') as running,
+				COUNT(*) FILTER (WHERE status = 'pending') as pending,
+				SUM(total_files) as total_files,
+				SUM(processed_entries) as rows_persisted,
+				SUM(price_changes) as price_changes,
+				COALESCE(SUM(processed_files) FILTER (WHERE duration_seconds IS NOT NULL), 0) as finished_files,
+				COALESCE(SUM(duration_seconds), 0) as finished_duration_seconds,
+				COALESCE(array_agg(duration_seconds) FILTER (WHERE duration_seconds IS NOT NULL), '{}') as run_durations
+			FROM runs
+			GROUP BY hour_start, chain_slug
+		),
+		error_stats AS (
+			SELECT
+				date_trunc('hour', e.created_at) as hour_start,
+				COALESCE(r.chain_slug, $3) as chain_slug,
+				COUNT(*) as total_errors
+			FROM ingestion_errors e
+			LEFT JOIN ingestion_runs r ON r.id = e.run_id
+			WHERE e.created_at >= $1 AND e.created_at < $2 AND e.severity <> 'warning'
+			GROUP BY 1, 2
+		)
+		INSERT INTO ingestion_stats_hourly (
+			hour_start, chain_slug, total_runs, completed, failed, running, pending,
+			total_files, total_errors, rows_persisted, price_changes,
+			finished_files, finished_duration_seconds, run_durations, computed_at
+		)
+		SELECT
+			COALESCE(rs.hour_start, es.hour_start),
+			COALESCE(rs.chain_slug, es.chain_slug),
+			COALESCE(rs.total_runs, 0),
+			COALESCE(rs.completed, 0),
+			COALESCE(rs.failed, 0),
+			COALESCE(rs.running, 0),
+			COALESCE(rs.pending, 0),
+			COALESCE(rs.total_files, 0),
+			COALESCE(es.total_errors, 0),
+			COALESCE(rs.rows_persisted, 0),
+			COALESCE(rs.price_changes, 0),
+			COALESCE(rs.finished_files, 0),
+			COALESCE(rs.finished_duration_seconds, 0),
+			COALESCE(rs.run_durations, '{}'),
+			NOW()
+		FROM run_stats rs
+		FULL OUTER JOIN error_stats es
+			ON es.hour_start = rs.hour_start AND es.chain_slug = rs.chain_slug
+	`, from, to, StatsUnknownChain)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert rollups: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO ingestion_stats_rollup_state (id, rolled_up_to, updated_at)
+		VALUES (true, $1, NOW())
+		ON CONFLICT (id) DO UPDATE
+		SET rolled_up_to = GREATEST(ingestion_stats_rollup_state.rolled_up_to, EXCLUDED.rolled_up_to),
+		    updated_at = NOW()
+	`, to); err != nil {
+		return 0, fmt.Errorf("failed to advance rollup watermark: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit rollups: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
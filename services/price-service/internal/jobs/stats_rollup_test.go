@@ -0,0 +1,123 @@
+package jobs
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// setupStatsRollupDB starts Postgres with the runs and errors the rollup
+// reads and the rollup tables and run trigger from their migrations
+func setupStatsRollupDB(t *testing.T) *pgxpool.Pool {
+	if testing.Short() {
+		t.Skip("skipping stats rollup test in short mode (requires Docker)")
+	}
+
+	ctx := context.Background()
+	container, err := postgres.Run(ctx, "postgres:16-alpine",
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(30*time.Second)),
+	)
+	require.NoError(t, err, "Failed to start postgres container")
+	t.Cleanup(func() { testcontainers.TerminateContainer(container) })
+
+	connStr, err := container.ConnectionString(ctx, "sslmode=disable")
+	require.NoError(t, err)
+	pool, err := pgxpool.New(ctx, connStr)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	_, err = pool.Exec(ctx, `
+		CREATE TABLE ingestion_runs (
+			id TEXT PRIMARY KEY,
+			chain_slug TEXT NOT NULL,
+			status TEXT NOT NULL,
+			total_files INTEGER,
+			processed_files INTEGER,
+			processed_entries INTEGER,
+			price_changes INTEGER,
+			started_at TIMESTAMP,
+			completed_at TIMESTAMP,
+			created_at TIMESTAMP NOT NULL
+		);
+		CREATE TABLE ingestion_errors (
+			id BIGSERIAL PRIMARY KEY,
+			run_id TEXT NOT NULL,
+			severity TEXT NOT NULL DEFAULT 'error',
+			created_at TIMESTAMP NOT NULL
+		);
+	`)
+	require.NoError(t, err)
+
+	for _, migration := range []string{"0007_add_ingestion_stats_hourly.sql", "0046_add_ingestion_run_updated_at.sql"} {
+		sql, err := os.ReadFile("../../migrations/" + migration)
+		require.NoError(t, err)
+		_, err = pool.Exec(ctx, string(sql))
+		require.NoError(t, err, migration)
+	}
+	return pool
+}
+
+// TestStatsRollupRefoldsRunsFinishedLate verifies a run finishing after its
+// hour left the lookback window is folded into its hour again, and errors of
+// deleted runs are rolled up under the unknown chain
+func TestStatsRollupRefoldsRunsFinishedLate(t *testing.T) {
+	pool := setupStatsRollupDB(t)
+	ctx := context.Background()
+	logger := zerolog.Nop()
+	job := NewStatsRollupJob(pool, &logger, time.Hour)
+
+	createdAt := time.Now().UTC().Add(-StatsRollupLookback - 24*time.Hour)
+	hour := createdAt.Truncate(time.Hour)
+	_, err := pool.Exec(ctx, `
+		INSERT INTO ingestion_runs (id, chain_slug, status, total_files, started_at, created_at)
+		VALUES ('run-slow', 'konzum', 'running', 4, $1, $1)
+	`, createdAt)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, `
+		INSERT INTO ingestion_errors (run_id, created_at) VALUES ('deleted-run', $1)
+	`, createdAt)
+	require.NoError(t, err)
+
+	rollup := func(chain string) (running, completed, rowsPersisted, totalErrors int) {
+		err := pool.QueryRow(ctx, `
+			SELECT running, completed, rows_persisted, total_errors
+			FROM ingestion_stats_hourly WHERE hour_start = $1 AND chain_slug = $2
+		`, hour, chain).Scan(&running, &completed, &rowsPersisted, &totalErrors)
+		require.NoError(t, err)
+		return
+	}
+
+	// The first pass rolls up every hour
+	require.NoError(t, job.RunOnce(ctx))
+	running, completed, _, _ := rollup("konzum")
+	assert.Equal(t, 1, running)
+	assert.Zero(t, completed)
+	_, _, _, unknownErrors := rollup(StatsUnknownChain)
+	assert.Equal(t, 1, unknownErrors)
+
+	// The run finishes long after its hour left the lookback window
+	_, err = pool.Exec(ctx, `
+		UPDATE ingestion_runs
+		SET status = 'completed', completed_at = $1, processed_files = 4, processed_entries = 50
+		WHERE id = 'run-slow'
+	`, time.Now().UTC())
+	require.NoError(t, err)
+
+	require.NoError(t, job.RunOnce(ctx))
+	running, completed, rowsPersisted, _ := rollup("konzum")
+	assert.Zero(t, running)
+	assert.Equal(t, 1, completed)
+	assert.Equal(t, 50, rowsPersisted)
+}
@@ -0,0 +1,143 @@
+// Package lanes schedules database-bound work in two priority lanes.
+//
+// Interactive traffic (basket optimization, price lookups, search) and batch
+// work (ingestion, reruns, exports, nightly jobs) share one connection pool.
+// Each lane has its own weighted semaphore sized below the pool, so batch
+// work can never hold every connection and interactive requests always keep
+// headroom.
+package lanes
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/sync/semaphore"
+)
+
+// Lane is a request class
+type Lane string
+
+const (
+	// Interactive is user-facing work where latency matters
+	Interactive Lane = "interactive"
+	// Batch is background work that may wait for capacity
+	Batch Lane = "batch"
+)
+
+var (
+	laneInflight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "lane_inflight",
+		Help: "Number of units of work holding a slot by lane",
+	}, []string{"lane"})
+
+	laneCapacity = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "lane_capacity",
+		Help: "Number of slots by lane",
+	}, []string{"lane"})
+
+	laneWaitDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "lane_wait_duration_seconds",
+		Help:    "Time spent waiting for a lane slot",
+		Buckets: []float64{0.001, 0.01, 0.05, 0.1, 0.5, 1, 5, 30},
+	}, []string{"lane"})
+
+	laneAcquireFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "lane_acquire_failures_total",
+		Help: "Total number of slot acquisitions abandoned because the context ended, by lane",
+	}, []string{"lane"})
+)
+
+// Scheduler holds one semaphore per lane
+type Scheduler struct {
+	interactive *semaphore.Weighted
+	batch       *semaphore.Weighted
+}
+
+// NewScheduler creates a scheduler with the given slots per lane. A lane with
+// fewer than one slot gets one.
+func NewScheduler(interactiveSlots, batchSlots int) *Scheduler {
+	interactiveSlots = max(interactiveSlots, 1)
+	batchSlots = max(batchSlots, 1)
+	laneCapacity.WithLabelValues(string(Interactive)).Set(float64(interactiveSlots))
+	laneCapacity.WithLabelValues(string(Batch)).Set(float64(batchSlots))
+	return &Scheduler{
+		interactive: semaphore.NewWeighted(int64(interactiveSlots)),
+		batch:       semaphore.NewWeighted(int64(batchSlots)),
+	}
+}
+
+// SplitConnections derives lane slots from the database pool size: batch work
+// gets a quarter of the connections, interactive work the rest
+func SplitConnections(maxConns int) (interactiveSlots, batchSlots int) {
+	batchSlots = max(maxConns/4, 1)
+	interactiveSlots = max(maxConns-batchSlots, 1)
+	return interactiveSlots, batchSlots
+}
+
+// Acquire blocks until the lane has a free slot or ctx ends. The returned
+// release function must be called when the work is done; it is safe to call
+// more than once.
+func (s *Scheduler) Acquire(ctx context.Context, lane Lane) (func(), error) {
+	sem := s.interactive
+	if lane == Batch {
+		sem = s.batch
+	} else {
+		lane = Interactive
+	}
+
+	start := time.Now()
+	if err := sem.Acquire(ctx, 1); err != nil {
+		laneAcquireFailures.WithLabelValues(string(lane)).Inc()
+		return nil, err
+	}
+	laneWaitDuration.WithLabelValues(string(lane)).Observe(time.Since(start).Seconds())
+	laneInflight.WithLabelValues(string(lane)).Inc()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			laneInflight.WithLabelValues(string(lane)).Dec()
+			sem.Release(1)
+		})
+	}, nil
+}
+
+var (
+	defaultMu        sync.RWMutex
+	defaultScheduler = NewScheduler(SplitConnections(25))
+)
+
+// Configure replaces the process-wide scheduler. Work holding slots of the
+// previous scheduler releases them there.
+func Configure(interactiveSlots, batchSlots int) {
+	s := NewScheduler(interactiveSlots, batchSlots)
+	defaultMu.Lock()
+	defaultScheduler = s
+	defaultMu.Unlock()
+}
+
+// Acquire takes a slot in the context's lane from the process-wide scheduler
+func Acquire(ctx context.Context) (func(), error) {
+	defaultMu.RLock()
+	s := defaultScheduler
+	defaultMu.RUnlock()
+	return s.Acquire(ctx, FromContext(ctx))
+}
+
+type contextKey struct{}
+
+// WithLane tags ctx with a lane
+func WithLane(ctx context.Context, lane Lane) context.Context {
+	return context.WithValue(ctx, contextKey{}, lane)
+}
+
+// FromContext returns the lane ctx is tagged with, Interactive when untagged
+func FromContext(ctx context.Context) Lane {
+	if lane, ok := ctx.Value(contextKey{}).(Lane); ok {
+		return lane
+	}
+	return Interactive
+}
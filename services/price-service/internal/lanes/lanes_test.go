@@ -0,0 +1,54 @@
+package lanes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitConnections(t *testing.T) {
+	interactive, batch := SplitConnections(100)
+	assert.Equal(t, 75, interactive)
+	assert.Equal(t, 25, batch)
+
+	interactive, batch = SplitConnections(2)
+	assert.Equal(t, 1, interactive)
+	assert.Equal(t, 1, batch)
+}
+
+func TestFromContext(t *testing.T) {
+	assert.Equal(t, Interactive, FromContext(context.Background()))
+	assert.Equal(t, Batch, FromContext(WithLane(context.Background(), Batch)))
+}
+
+func TestBatchCannotStarveInteractive(t *testing.T) {
+	s := NewScheduler(1, 1)
+
+	releaseBatch, err := s.Acquire(context.Background(), Batch)
+	require.NoError(t, err)
+	defer releaseBatch()
+
+	// The batch lane is full, so more batch work waits
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = s.Acquire(ctx, Batch)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// while interactive work still gets a slot
+	releaseInteractive, err := s.Acquire(context.Background(), Interactive)
+	require.NoError(t, err)
+	releaseInteractive()
+	releaseInteractive()
+
+	// Releasing twice must not free a second slot
+	release, err := s.Acquire(context.Background(), Interactive)
+	require.NoError(t, err)
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel2()
+	_, err = s.Acquire(ctx2, Interactive)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	release()
+}
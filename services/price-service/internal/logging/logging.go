@@ -0,0 +1,191 @@
+// Package logging provides per-component loggers whose levels can be changed
+// at runtime, so one noisy subsystem can be debugged without flooding the
+// others.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+// Components with their own log level.
+const (
+	Cache     = "cache"
+	Optimizer = "optimizer"
+	Pipeline  = "pipeline"
+	Handlers  = "handlers"
+	Adapters  = "adapters"
+)
+
+// Components lists every component with its own log level.
+var Components = []string{Cache, Optimizer, Pipeline, Handlers, Adapters}
+
+// inherit marks a component without its own level; it logs at the default.
+const inherit = int32(-128)
+
+var (
+	out          = &switchWriter{}
+	defaultLevel atomic.Int32
+	levels       = make(map[string]*atomic.Int32, len(Components))
+
+	// mu serializes level updates so a multi-component change is applied
+	// as a whole; readers use the atomics directly
+	mu sync.Mutex
+)
+
+func init() {
+	out.set(os.Stderr)
+	defaultLevel.Store(int32(zerolog.InfoLevel))
+	for _, c := range Components {
+		level := &atomic.Int32{}
+		level.Store(inherit)
+		levels[c] = level
+	}
+}
+
+// For returns the logger for a component. It can be created before Configure
+// runs: output and level are resolved when each event is written.
+func For(component string) zerolog.Logger {
+	return zerolog.New(out).
+		With().Timestamp().Str("component", component).Logger().
+		Hook(levelHook{component: component})
+}
+
+// Configure sets the output and levels of all component loggers. components
+// maps component names to level names; components not listed log at
+// defaultLvl.
+func Configure(w io.Writer, defaultLvl zerolog.Level, components map[string]string) error {
+	parsed, err := parseLevels(components)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	out.set(w)
+	defaultLevel.Store(int32(defaultLvl))
+	for _, c := range Components {
+		levels[c].Store(inherit)
+	}
+	for c, level := range parsed {
+		levels[c].Store(int32(level))
+	}
+	return nil
+}
+
+// Update changes levels at runtime. An empty defaultLvl keeps the current
+// default; a component mapped to "" goes back to the default.
+func Update(defaultLvl string, components map[string]string) error {
+	var newDefault *zerolog.Level
+	if defaultLvl != "" {
+		level, err := zerolog.ParseLevel(defaultLvl)
+		if err != nil {
+			return fmt.Errorf("invalid default log level %q", defaultLvl)
+		}
+		newDefault = &level
+	}
+
+	var reset []string
+	set := make(map[string]string, len(components))
+	for c, level := range components {
+		if level == "" {
+			if _, ok := levels[c]; !ok {
+				return fmt.Errorf("unknown log component %q", c)
+			}
+			reset = append(reset, c)
+			continue
+		}
+		set[c] = level
+	}
+	parsed, err := parseLevels(set)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if newDefault != nil {
+		defaultLevel.Store(int32(*newDefault))
+	}
+	for _, c := range reset {
+		levels[c].Store(inherit)
+	}
+	for c, level := range parsed {
+		levels[c].Store(int32(level))
+	}
+	return nil
+}
+
+// Levels reports the default level and the effective level of each component.
+func Levels() (string, map[string]string) {
+	effective := make(map[string]string, len(Components))
+	for _, c := range Components {
+		effective[c] = Level(c).String()
+	}
+	return zerolog.Level(defaultLevel.Load()).String(), effective
+}
+
+// Level returns the effective level of a component.
+func Level(component string) zerolog.Level {
+	if level, ok := levels[component]; ok {
+		if l := level.Load(); l != inherit {
+			return zerolog.Level(l)
+		}
+	}
+	return zerolog.Level(defaultLevel.Load())
+}
+
+// parseLevels validates component names and level names.
+func parseLevels(components map[string]string) (map[string]zerolog.Level, error) {
+	names := make([]string, 0, len(components))
+	for c := range components {
+		names = append(names, c)
+	}
+	sort.Strings(names)
+
+	parsed := make(map[string]zerolog.Level, len(components))
+	for _, c := range names {
+		if _, ok := levels[c]; !ok {
+			return nil, fmt.Errorf("unknown log component %q", c)
+		}
+		level, err := zerolog.ParseLevel(components[c])
+		if err != nil || components[c] == "" {
+			return nil, fmt.Errorf("invalid log level %q for component %s", components[c], c)
+		}
+		parsed[c] = level
+	}
+	return parsed, nil
+}
+
+// levelHook drops events below the component's current level.
+type levelHook struct {
+	component string
+}
+
+func (h levelHook) Run(e *zerolog.Event, level zerolog.Level, _ string) {
+	if level < Level(h.component) {
+		e.Discard()
+	}
+}
+
+// switchWriter forwards to a writer that can be replaced after component
+// loggers were created.
+type switchWriter struct {
+	w atomic.Pointer[io.Writer]
+}
+
+func (s *switchWriter) set(w io.Writer) {
+	s.w.Store(&w)
+}
+
+func (s *switchWriter) Write(p []byte) (int, error) {
+	return (*s.w.Load()).Write(p)
+}
@@ -0,0 +1,49 @@
+package logging
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponentLevels(t *testing.T) {
+	var buf bytes.Buffer
+	t.Cleanup(func() { _ = Configure(os.Stderr, zerolog.InfoLevel, nil) })
+
+	// Created before Configure, as package-level loggers are
+	optimizer := For(Optimizer)
+	pipeline := For(Pipeline)
+
+	require.NoError(t, Configure(&buf, zerolog.InfoLevel, map[string]string{Optimizer: "debug"}))
+
+	optimizer.Debug().Msg("optimizer debug")
+	pipeline.Debug().Msg("pipeline debug")
+	pipeline.Info().Msg("pipeline info")
+
+	assert.Contains(t, buf.String(), `"component":"optimizer"`)
+	assert.Contains(t, buf.String(), "optimizer debug")
+	assert.NotContains(t, buf.String(), "pipeline debug")
+	assert.Contains(t, buf.String(), "pipeline info")
+}
+
+func TestUpdateLevels(t *testing.T) {
+	t.Cleanup(func() { _ = Configure(os.Stderr, zerolog.InfoLevel, nil) })
+	require.NoError(t, Configure(io.Discard, zerolog.InfoLevel, map[string]string{Cache: "error"}))
+
+	require.NoError(t, Update("warn", map[string]string{Cache: "", Adapters: "trace"}))
+	defaultLevel, levels := Levels()
+	assert.Equal(t, "warn", defaultLevel)
+	assert.Equal(t, "warn", levels[Cache], "reset to the default")
+	assert.Equal(t, "trace", levels[Adapters])
+	assert.Equal(t, "warn", levels[Handlers])
+
+	assert.Error(t, Update("", map[string]string{"ui": "debug"}))
+	assert.Error(t, Update("", map[string]string{Cache: "loud"}))
+	assert.Error(t, Update("loud", nil))
+	assert.Equal(t, zerolog.TraceLevel, Level(Adapters), "failed updates change nothing")
+}
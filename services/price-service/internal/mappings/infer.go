@@ -0,0 +1,451 @@
+package mappings
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/kosarica/price-service/internal/parsers/charset"
+	"github.com/kosarica/price-service/internal/parsers/csv"
+)
+
+// Value kinds detected by column profiling
+const (
+	KindPrice   = "price"
+	KindDate    = "date"
+	KindBarcode = "barcode"
+	KindInteger = "integer"
+	KindText    = "text"
+	KindEmpty   = "empty"
+)
+
+// DefaultProfileRows is how many data rows are profiled when no limit is given
+const DefaultProfileRows = 500
+
+// profileSamples is how many distinct sample values are kept per column
+const profileSamples = 5
+
+var (
+	pricePattern   = regexp.MustCompile(`^-?\d{1,3}([.\s]?\d{3})*[.,]\d{1,2}$|^-?\d+[.,]\d{1,2}$`)
+	barcodePattern = regexp.MustCompile(`^(\d{8}|\d{12,14})$`)
+	integerPattern = regexp.MustCompile(`^-?\d+$`)
+	datePattern    = regexp.MustCompile(`^(\d{4}[-/.]\d{1,2}[-/.]\d{1,2}|\d{1,2}[-/.]\d{1,2}[-/.]\d{4}\.?)([ T]\d{1,2}:\d{2}(:\d{2})?)?$`)
+)
+
+// ColumnProfile describes the values found in one column of a sample file
+type ColumnProfile struct {
+	Index    int                `json:"index"`
+	Header   string             `json:"header"`
+	NonEmpty int                `json:"nonEmpty"`
+	Distinct int                `json:"distinct"`
+	Kind     string             `json:"kind"`  // Dominant value kind
+	Kinds    map[string]float64 `json:"kinds"` // Share of non-empty values per kind
+	AvgLen   float64            `json:"avgLen"`
+	Samples  []string           `json:"samples"`
+}
+
+// FieldProposal is the proposed column for one mapping field
+type FieldProposal struct {
+	Column     string  `json:"column"` // Header name, or column index when the file has no header
+	Index      int     `json:"index"`
+	Confidence float64 `json:"confidence"` // 0..1
+	Reason     string  `json:"reason"`
+}
+
+// Proposal is an inferred column mapping for a sample file, to be confirmed
+// by a human and stored as a column mapping override or compiled into an adapter
+type Proposal struct {
+	Format    string                   `json:"format"` // csv or xlsx
+	Delimiter string                   `json:"delimiter,omitempty"`
+	Encoding  string                   `json:"encoding,omitempty"`
+	HasHeader bool                     `json:"hasHeader"`
+	RowsRead  int                      `json:"rowsRead"`
+	Columns   []ColumnProfile          `json:"columns"`
+	Fields    map[string]FieldProposal `json:"fields"`
+	// Mapping is the proposal in column mapping override form
+	Mapping map[string]string `json:"mapping"`
+	// Missing lists required fields no column was found for
+	Missing []string `json:"missing"`
+}
+
+// fieldHint describes how to recognise the column of a mapping field
+type fieldHint struct {
+	field    string
+	kinds    []string // Acceptable value kinds, best first
+	keywords []string // Header keywords (lowercase, without diacritics)
+	required bool
+}
+
+// fieldHints are evaluated in order; earlier fields claim columns first
+var fieldHints = []fieldHint{
+	{field: "barcodes", kinds: []string{KindBarcode}, keywords: []string{"barkod", "barcode", "ean", "gtin"}},
+	{field: "anchorPrice", kinds: []string{KindPrice}, keywords: []string{"sidren", "anchor"}},
+	{field: "lowestPrice30d", kinds: []string{KindPrice}, keywords: []string{"najniz", "30 dana", "lowest"}},
+	{field: "unitPrice", kinds: []string{KindPrice}, keywords: []string{"jedinicu mjere", "unit price", "cijena za jedinicu", "cijena jed", "cijena po"}},
+	{field: "discountPrice", kinds: []string{KindPrice}, keywords: []string{"posebnog oblika", "poseb", "akcij", "popust", "discount", "sale"}},
+	{field: "price", kinds: []string{KindPrice}, keywords: []string{"maloprod", "mpc", "cijena", "price"}, required: true},
+	{field: "discountStart", kinds: []string{KindDate}, keywords: []string{"pocetak", "od", "start", "from"}},
+	{field: "discountEnd", kinds: []string{KindDate}, keywords: []string{"kraj", "do", "end", "until"}},
+	{field: "anchorPriceAsOf", kinds: []string{KindDate}, keywords: []string{"sidren", "anchor"}},
+	{field: "name", kinds: []string{KindText}, keywords: []string{"naziv", "name", "proizvod", "artikl", "product"}, required: true},
+	{field: "externalId", kinds: []string{KindInteger, KindText, KindBarcode}, keywords: []string{"sifra", "code", "sku", "artikl id", "id"}},
+	{field: "brand", kinds: []string{KindText}, keywords: []string{"marka", "brand", "proizvodac"}},
+	{field: "category", kinds: []string{KindText}, keywords: []string{"kategorija", "category", "grupa"}},
+	{field: "unitQuantity", kinds: []string{KindText, KindInteger, KindPrice}, keywords: []string{"kolicina", "neto", "quantity", "gramaza"}},
+	{field: "unit", kinds: []string{KindText}, keywords: []string{"jedinica mjere", "jedinica", "unit", "jm"}},
+	{field: "storeIdentifier", kinds: []string{KindInteger, KindText}, keywords: []string{"trgovina", "poslovnica", "store", "prodavaonica"}},
+}
+
+// Infer profiles a sample price file and proposes a column mapping. Both CSV
+// and XLSX files are accepted; maxRows bounds the profiled data rows.
+func Infer(content []byte, filename string, maxRows int) (*Proposal, error) {
+	if maxRows <= 0 {
+		maxRows = DefaultProfileRows
+	}
+
+	proposal := &Proposal{}
+	var rows [][]string
+	var err error
+	if isXLSX(content, filename) {
+		proposal.Format = "xlsx"
+		rows, err = readXLSXRows(content, maxRows+1)
+	} else {
+		proposal.Format = "csv"
+		rows, err = readCSVRows(content, maxRows+1, proposal)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("file has no rows")
+	}
+
+	rows = skipPreamble(rows)
+	proposal.HasHeader = looksLikeHeader(rows)
+	var headers []string
+	data := rows
+	if proposal.HasHeader {
+		headers = rows[0]
+		data = rows[1:]
+	}
+	proposal.RowsRead = len(data)
+	proposal.Columns = profileColumns(headers, data)
+	proposal.Fields, proposal.Mapping, proposal.Missing = propose(proposal.Columns, proposal.HasHeader)
+	return proposal, nil
+}
+
+func isXLSX(content []byte, filename string) bool {
+	lower := strings.ToLower(filename)
+	if strings.HasSuffix(lower, ".xlsx") || strings.HasSuffix(lower, ".xlsm") {
+		return true
+	}
+	// XLSX files are ZIP archives
+	return bytes.HasPrefix(content, []byte("PK\x03\x04"))
+}
+
+func readCSVRows(content []byte, limit int, proposal *Proposal) ([][]string, error) {
+	encoding := charset.DetectEncoding(content)
+	decoded, err := charset.Decode(content, encoding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode content: %w", err)
+	}
+	decoded = strings.TrimPrefix(decoded, "\uFEFF")
+
+	delimiter := csv.DetectDelimiter(decoded)
+	proposal.Encoding = string(encoding)
+	proposal.Delimiter = string(delimiter)
+
+	rows := make([][]string, 0, limit)
+	for _, line := range strings.Split(decoded, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := csv.SplitCSVLine(line, rune(delimiter[0]), '"')
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		rows = append(rows, fields)
+		if len(rows) >= limit {
+			break
+		}
+	}
+	return rows, nil
+}
+
+func readXLSXRows(content []byte, limit int) ([][]string, error) {
+	f, err := excelize.OpenReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open XLSX: %w", err)
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, fmt.Errorf("XLSX has no sheets")
+	}
+	all, err := f.GetRows(sheets[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read XLSX rows: %w", err)
+	}
+
+	rows := make([][]string, 0, limit)
+	for _, row := range all {
+		empty := true
+		for i := range row {
+			row[i] = strings.TrimSpace(row[i])
+			if row[i] != "" {
+				empty = false
+			}
+		}
+		if empty {
+			continue
+		}
+		rows = append(rows, row)
+		if len(rows) >= limit {
+			break
+		}
+	}
+	return rows, nil
+}
+
+// currencyAffixes are stripped from values before classification
+var currencyAffixes = strings.NewReplacer("€", "", "EUR", "", "eur", "", "kn", "")
+
+// classify returns the kind of a single value
+func classify(value string) string {
+	v := strings.TrimSpace(currencyAffixes.Replace(strings.TrimPrefix(strings.TrimSpace(value), "'")))
+	switch {
+	case v == "":
+		return KindEmpty
+	case barcodePattern.MatchString(v):
+		return KindBarcode
+	case datePattern.MatchString(v):
+		return KindDate
+	case pricePattern.MatchString(v):
+		return KindPrice
+	case integerPattern.MatchString(v):
+		return KindInteger
+	default:
+		return KindText
+	}
+}
+
+// preambleScanRows is how many leading rows are checked for title lines
+const preambleScanRows = 20
+
+// skipPreamble drops title and note lines above the table, recognised as
+// leading rows with far fewer filled cells than the widest row
+func skipPreamble(rows [][]string) [][]string {
+	filled := func(row []string) int {
+		n := 0
+		for _, v := range row {
+			if v != "" {
+				n++
+			}
+		}
+		return n
+	}
+
+	widest := 0
+	for i := 0; i < len(rows) && i < preambleScanRows; i++ {
+		widest = max(widest, filled(rows[i]))
+	}
+	for i := 0; i < len(rows) && i < preambleScanRows; i++ {
+		if filled(rows[i])*2 > widest {
+			return rows[i:]
+		}
+	}
+	return rows
+}
+
+// looksLikeHeader reports whether the first row is a header: it holds no
+// price, date or barcode values while the following rows do
+func looksLikeHeader(rows [][]string) bool {
+	if len(rows) < 2 {
+		return true
+	}
+	typed := func(row []string) int {
+		n := 0
+		for _, v := range row {
+			switch classify(v) {
+			case KindPrice, KindDate, KindBarcode:
+				n++
+			}
+		}
+		return n
+	}
+	first := typed(rows[0])
+	return first == 0 || first < typed(rows[1])
+}
+
+func profileColumns(headers []string, data [][]string) []ColumnProfile {
+	width := len(headers)
+	for _, row := range data {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+
+	profiles := make([]ColumnProfile, width)
+	for col := 0; col < width; col++ {
+		p := ColumnProfile{Index: col, Kinds: map[string]float64{}, Samples: []string{}}
+		if col < len(headers) {
+			p.Header = headers[col]
+		}
+
+		counts := map[string]int{}
+		distinct := map[string]bool{}
+		totalLen := 0
+		for _, row := range data {
+			if col >= len(row) || row[col] == "" {
+				continue
+			}
+			v := row[col]
+			p.NonEmpty++
+			totalLen += len([]rune(v))
+			counts[classify(v)]++
+			if !distinct[v] {
+				distinct[v] = true
+				if len(p.Samples) < profileSamples {
+					p.Samples = append(p.Samples, v)
+				}
+			}
+		}
+		p.Distinct = len(distinct)
+
+		p.Kind = KindEmpty
+		if p.NonEmpty > 0 {
+			p.AvgLen = float64(totalLen) / float64(p.NonEmpty)
+			best := 0
+			for kind, n := range counts {
+				p.Kinds[kind] = float64(n) / float64(p.NonEmpty)
+				if n > best || (n == best && kind < p.Kind) {
+					best, p.Kind = n, kind
+				}
+			}
+		}
+		profiles[col] = p
+	}
+	return profiles
+}
+
+// normalizeHeader lowercases a header and strips Croatian diacritics
+func normalizeHeader(h string) string {
+	r := strings.NewReplacer("š", "s", "č", "c", "ć", "c", "ž", "z", "đ", "d", "_", " ")
+	return strings.Join(strings.Fields(r.Replace(strings.ToLower(h))), " ")
+}
+
+// headerScore scores how well a header matches a field's keywords. Short
+// keywords must match a whole word to avoid matching inside other words.
+func headerScore(header string, keywords []string) float64 {
+	h := normalizeHeader(header)
+	if h == "" {
+		return 0
+	}
+	words := strings.FieldsFunc(h, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	for i, kw := range keywords {
+		matched := false
+		if len(kw) <= 3 {
+			for _, w := range words {
+				if w == kw {
+					matched = true
+					break
+				}
+			}
+		} else {
+			matched = strings.Contains(h, kw)
+		}
+		if matched {
+			// Earlier keywords are more specific
+			return 1 - float64(i)*0.05
+		}
+	}
+	return 0
+}
+
+// valueScore scores how well a column's values fit a field's kinds
+func valueScore(p ColumnProfile, hint fieldHint) float64 {
+	best := 0.0
+	for i, kind := range hint.kinds {
+		if s := p.Kinds[kind] * (1 - float64(i)*0.2); s > best {
+			best = s
+		}
+	}
+	// Names are long and mostly distinct
+	if hint.field == "name" && p.NonEmpty > 0 {
+		best *= min(p.AvgLen/20, 1) * (0.5 + 0.5*float64(p.Distinct)/float64(p.NonEmpty))
+	}
+	return best
+}
+
+func propose(columns []ColumnProfile, hasHeader bool) (map[string]FieldProposal, map[string]string, []string) {
+	fields := make(map[string]FieldProposal)
+	mapping := make(map[string]string)
+	missing := make([]string, 0)
+	used := make(map[int]bool)
+
+	columnName := func(p ColumnProfile) string {
+		if hasHeader && p.Header != "" {
+			return p.Header
+		}
+		return strconv.Itoa(p.Index)
+	}
+
+	for _, hint := range fieldHints {
+		bestIdx, bestScore, bestReason := -1, 0.0, ""
+		for _, p := range columns {
+			if used[p.Index] || p.NonEmpty == 0 {
+				continue
+			}
+			vs := valueScore(p, hint)
+			if vs < 0.5 {
+				continue
+			}
+			hs := 0.0
+			if hasHeader {
+				hs = headerScore(p.Header, hint.keywords)
+			}
+			// Without a header match only required fields are guessed, from values alone
+			if hs == 0 && !hint.required {
+				continue
+			}
+			score := 0.6*hs + 0.4*vs
+			if hs == 0 {
+				score = 0.4 * vs
+			}
+			if score > bestScore {
+				bestIdx, bestScore = p.Index, score
+				bestReason = fmt.Sprintf("%.0f%% %s values", p.Kinds[p.Kind]*100, p.Kind)
+				if hs > 0 {
+					bestReason = "header match, " + bestReason
+				}
+			}
+		}
+
+		if bestIdx == -1 {
+			if hint.required {
+				missing = append(missing, hint.field)
+			}
+			continue
+		}
+		used[bestIdx] = true
+		p := columns[bestIdx]
+		fields[hint.field] = FieldProposal{
+			Column:     columnName(p),
+			Index:      bestIdx,
+			Confidence: float64(int(bestScore*100)) / 100,
+			Reason:     bestReason,
+		}
+		mapping[hint.field] = columnName(p)
+	}
+
+	sort.Strings(missing)
+	return fields, mapping, missing
+}
@@ -0,0 +1,55 @@
+package mappings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInferCSVWithHeader(t *testing.T) {
+	sample := "ŠIFRA PROIZVODA;NAZIV PROIZVODA;MARKA PROIZVODA;NETO KOLIČINA;JEDINICA MJERE;MALOPRODAJNA CIJENA;MPC ZA VRIJEME POSEBNOG OBLIKA PRODAJE;CIJENA ZA JEDINICU MJERE;BARKOD\n" +
+		"10001;Mlijeko svježe 2,8% m.m. 1 l;Dukat;1;l;1,29;;1,29;3850102123456\n" +
+		"10002;Kruh bijeli narezani 500 g;Klara;0,5;kg;1,99;1,49;3,98;3858881234567\n" +
+		"10003;Jogurt tekući 1 kg;Vindija;1;kg;1,59;;1,59;3850104001234\n"
+
+	p, err := Infer([]byte(sample), "sample.csv", 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, "csv", p.Format)
+	assert.Equal(t, ";", p.Delimiter)
+	assert.True(t, p.HasHeader)
+	assert.Equal(t, 3, p.RowsRead)
+	assert.Empty(t, p.Missing)
+
+	assert.Equal(t, "NAZIV PROIZVODA", p.Mapping["name"])
+	assert.Equal(t, "MALOPRODAJNA CIJENA", p.Mapping["price"])
+	assert.Equal(t, "MPC ZA VRIJEME POSEBNOG OBLIKA PRODAJE", p.Mapping["discountPrice"])
+	assert.Equal(t, "CIJENA ZA JEDINICU MJERE", p.Mapping["unitPrice"])
+	assert.Equal(t, "BARKOD", p.Mapping["barcodes"])
+	assert.Equal(t, "ŠIFRA PROIZVODA", p.Mapping["externalId"])
+	assert.Equal(t, "MARKA PROIZVODA", p.Mapping["brand"])
+}
+
+func TestInferCSVWithoutHeaderUsesIndices(t *testing.T) {
+	sample := "3850102123456,\"Mlijeko svježe 2,8% m.m. 1 l\",1.29\n" +
+		"3858881234567,Kruh bijeli narezani 500 g,1.99\n"
+
+	p, err := Infer([]byte(sample), "sample.csv", 0)
+	require.NoError(t, err)
+
+	assert.False(t, p.HasHeader)
+	assert.Equal(t, "1", p.Mapping["name"])
+	assert.Equal(t, "2", p.Mapping["price"])
+}
+
+func TestClassify(t *testing.T) {
+	assert.Equal(t, KindPrice, classify("1,99"))
+	assert.Equal(t, KindPrice, classify("1.234,56"))
+	assert.Equal(t, KindBarcode, classify("3850102123456"))
+	assert.Equal(t, KindDate, classify("15.05.2024."))
+	assert.Equal(t, KindDate, classify("2024-05-15"))
+	assert.Equal(t, KindInteger, classify("10001"))
+	assert.Equal(t, KindText, classify("Mlijeko"))
+	assert.Equal(t, KindEmpty, classify(" "))
+}
@@ -0,0 +1,76 @@
+// Package mappings serves the per-chain column mapping overrides stored in
+// the column_mapping_overrides table. Adapters overlay an enabled override on
+// their compiled-in mapping at parse time.
+package mappings
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kosarica/price-service/internal/database"
+)
+
+// CacheTTL is how long overrides loaded from the database are reused
+const CacheTTL = time.Minute
+
+// refreshTimeout bounds the lazy database refresh done on lookup
+const refreshTimeout = 2 * time.Second
+
+var cache struct {
+	mu        sync.RWMutex
+	overrides map[string]map[string]string
+	loadedAt  time.Time
+}
+
+// Override returns the enabled column mapping override of a chain, or nil
+func Override(chainSlug string) map[string]string {
+	cache.mu.RLock()
+	overrides, loadedAt := cache.overrides, cache.loadedAt
+	cache.mu.RUnlock()
+
+	if overrides == nil || time.Since(loadedAt) >= CacheTTL {
+		ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+		defer cancel()
+		if err := Refresh(ctx); err == nil {
+			cache.mu.RLock()
+			overrides = cache.overrides
+			cache.mu.RUnlock()
+		}
+	}
+
+	return overrides[chainSlug]
+}
+
+// Refresh reloads the enabled overrides from the database into the cache
+func Refresh(ctx context.Context) error {
+	if database.Pool() == nil {
+		return fmt.Errorf("database not connected")
+	}
+
+	rows, err := database.ListColumnMappingOverrides(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load column mapping overrides: %w", err)
+	}
+
+	overrides := make(map[string]map[string]string, len(rows))
+	for _, o := range rows {
+		if o.Enabled {
+			overrides[o.ChainSlug] = o.Mapping
+		}
+	}
+
+	cache.mu.Lock()
+	cache.overrides = overrides
+	cache.loadedAt = time.Now()
+	cache.mu.Unlock()
+	return nil
+}
+
+// Invalidate drops the cached overrides so the next lookup reloads them
+func Invalidate() {
+	cache.mu.Lock()
+	cache.loadedAt = time.Time{}
+	cache.mu.Unlock()
+}
@@ -18,6 +18,7 @@ type AIMatchResult struct {
 	QueuedForReview int
 	NoMatch         int
 	CacheHits       int
+	Refreshed       int
 }
 
 // AIMatcherConfig configures the AI matching behavior
@@ -28,6 +29,8 @@ type AIMatcherConfig struct {
 	BatchSize         int     // Embedding batch size (default 100)
 	MaxCandidates     int     // Top-N candidates to store (default 5)
 	TrgmPrefilter     int     // Top-N from pg_trgm before embeddings (default 200)
+	RefreshLimit      int     // Changed items re-embedded per run (default 500)
+	RejectionPenalty  float32 // Similarity lost per reviewer rejecting a candidate for similar items (default 0.05)
 }
 
 // DefaultAIMatcherConfig returns sensible defaults
@@ -39,6 +42,8 @@ func DefaultAIMatcherConfig(provider EmbeddingProvider) AIMatcherConfig {
 		BatchSize:         100,
 		MaxCandidates:     5,
 		TrgmPrefilter:     200,
+		RefreshLimit:      500,
+		RejectionPenalty:  0.05,
 	}
 }
 
@@ -46,6 +51,12 @@ func DefaultAIMatcherConfig(provider EmbeddingProvider) AIMatcherConfig {
 func RunAIMatching(ctx context.Context, db *pgxpool.Pool, cfg AIMatcherConfig, runID string) (*AIMatchResult, error) {
 	result := &AIMatchResult{}
 
+	// 0. Re-embed items whose name or brand changed since their embedding
+	if err := RefreshChangedItems(ctx, db, cfg, runID, result); err != nil {
+		slog.Error("embedding refresh failed", "error", err)
+		// Continue with unmatched items
+	}
+
 	// 1. Get unmatched items (excluding already linked items)
 	items, err := getUnmatchedItemsForAI(ctx, db, cfg.BatchSize*10) // Process in chunks
 	if err != nil {
@@ -82,52 +93,9 @@ func processAIBatch(
 	items []RetailerItem,
 	result *AIMatchResult,
 ) error {
-	// 1. Normalize all items and compute text hashes
-	texts := make([]string, len(items))
-	hashes := make([]string, len(items))
-	itemIDs := make([]string, len(items))
-
-	for i, item := range items {
-		normalized := NormalizeForEmbedding(item.Name, item.Brand, item.Category, item.Unit)
-		texts[i] = normalized
-		hashes[i] = hashText(normalized)
-		itemIDs[i] = item.ID
-	}
-
-	// 2. Check embedding cache
-	cached, err := getCachedEmbeddings(ctx, db, itemIDs, hashes, cfg.Provider.ModelVersion())
+	texts, hashes, cached, err := embedItems(ctx, db, cfg, items, result)
 	if err != nil {
-		return fmt.Errorf("get cached embeddings: %w", err)
-	}
-
-	// 3. Generate missing embeddings in batch
-	toGenerate := make([]int, 0)
-	for i := range items {
-		if cached[i] == nil {
-			toGenerate = append(toGenerate, i)
-		} else {
-			result.CacheHits++
-		}
-	}
-
-	if len(toGenerate) > 0 {
-		batchTexts := make([]string, len(toGenerate))
-		for i, idx := range toGenerate {
-			batchTexts[i] = texts[idx]
-		}
-
-		embeddings, err := GenerateWithRetry(ctx, cfg.Provider, batchTexts, DefaultEmbeddingRetryConfig())
-		if err != nil {
-			return fmt.Errorf("generate embeddings: %w", err)
-		}
-
-		// Store in cache
-		for i, idx := range toGenerate {
-			cached[idx] = embeddings[i]
-			if err := storeEmbeddingCache(ctx, db, items[idx].ID, embeddings[i], texts[idx], hashes[idx], cfg.Provider.ModelVersion(), false); err != nil {
-				slog.Error("cache store failed", "item_id", items[idx].ID, "error", err)
-			}
-		}
+		return err
 	}
 
 	// 4. For each item, run 2-stage matching
@@ -137,36 +105,17 @@ func processAIBatch(
 			continue
 		}
 
-		// Stage 1: pg_trgm prefilter (cheap, in-DB)
-		trgmProductIDs, err := getTrgmCandidates(ctx, db, texts[i], cfg.TrgmPrefilter)
+		candidates, rejections, err := scoreCandidates(ctx, db, cfg, cfg.Provider.ModelVersion(), item, texts[i], embedding)
 		if err != nil {
-			slog.Error("trgm prefilter failed", "error", err)
+			slog.Error("score candidates failed", "item_id", item.ID, "error", err)
 			continue
 		}
-
-		if len(trgmProductIDs) == 0 {
-			result.NoMatch++
-			continue
+		if err := deleteRejectedCandidates(ctx, db, item.ID, rejections.rejected); err != nil {
+			slog.Error("delete rejected candidates failed", "error", err)
 		}
 
-		// Get product embeddings
-		productEmbeddings, err := getCachedProductEmbeddings(ctx, db, trgmProductIDs, cfg.Provider.ModelVersion())
-		if err != nil {
-			slog.Error("get product embeddings failed", "error", err)
-			continue
-		}
-
-		// Stage 2: Embedding rerank on prefiltered candidates
-		candidates := rerankWithEmbeddings(embedding, productEmbeddings, trgmProductIDs, cfg.MaxCandidates)
-
 		// 5. Store candidates with versioning
 		for rank, cand := range candidates {
-			_, err := getProductInfo(ctx, db, cand.ProductID)
-			if err != nil {
-				slog.Error("get product info failed", "product_id", cand.ProductID, "error", err)
-				continue
-			}
-
 			if err := storeCandidateMatch(ctx, db, StoreCandidateParams{
 				RetailerItemID:     item.ID,
 				CandidateProductID: cand.ProductID,
@@ -181,31 +130,20 @@ func processAIBatch(
 			}
 		}
 
-		// 6. Decision based on best candidate (excluding scoped rejections)
-		best := filterRejections(ctx, db, item.ID, candidates)
-		if best == nil || best.Similarity < cfg.ReviewThreshold {
+		// 6. Decision based on best candidate
+		decision, reason := decideAIMatch(item, candidates, cfg)
+		switch decision {
+		case DecisionNoMatch:
 			result.NoMatch++
 			continue
-		}
-
-		// Check for private label conflict
-		if hasPrivateLabelConflict(item, best) {
-			if err := queueForReview(ctx, db, item.ID, "ai_private_label_conflict"); err != nil {
-				slog.Error("queue failed", "error", err)
-			}
-			result.QueuedForReview++
-			result.Processed++
-			continue
-		}
-
-		if best.Similarity >= cfg.AutoLinkThreshold {
-			if err := createProductLink(ctx, db, best.ProductID, item.ID, "ai", best.Similarity); err != nil {
+		case DecisionAutoLink:
+			if err := createProductLink(ctx, db, candidates[0].ProductID, item.ID, "ai", candidates[0].Similarity); err != nil {
 				slog.Error("auto-link failed", "error", err)
 				continue
 			}
 			result.HighConfidence++
-		} else {
-			if err := queueForReview(ctx, db, item.ID, "ai_uncertain"); err != nil {
+		case DecisionReview:
+			if err := queueForReview(ctx, db, item.ID, reason); err != nil {
 				slog.Error("queue failed", "error", err)
 				continue
 			}
@@ -218,12 +156,131 @@ func processAIBatch(
 	return nil
 }
 
+// scoreCandidates ranks the products an item may match, best first: pg_trgm
+// prefilters products named like the item's normalized text, then cached
+// embeddings of modelVersion rerank them. Products rejected for the item are
+// excluded, those rejected for similar items penalized, and at most
+// cfg.MaxCandidates are returned with their product info. The item's
+// rejections are returned too.
+func scoreCandidates(
+	ctx context.Context,
+	db *pgxpool.Pool,
+	cfg AIMatcherConfig,
+	modelVersion string,
+	item RetailerItem,
+	text string,
+	embedding []float32,
+) ([]Candidate, rejectionMemory, error) {
+	// Stage 1: pg_trgm prefilter (cheap, in-DB)
+	productIDs, err := getTrgmCandidates(ctx, db, text, cfg.TrgmPrefilter)
+	if err != nil {
+		return nil, rejectionMemory{}, fmt.Errorf("trgm prefilter: %w", err)
+	}
+
+	// Never suggest products rejected for this item again
+	rejections, err := getRejectionMemory(ctx, db, item, productIDs)
+	if err != nil {
+		return nil, rejectionMemory{}, fmt.Errorf("get rejections: %w", err)
+	}
+	productIDs = rejections.withoutRejected(productIDs)
+	if len(productIDs) == 0 {
+		return nil, rejections, nil
+	}
+
+	productEmbeddings, err := getCachedProductEmbeddings(ctx, db, productIDs, modelVersion)
+	if err != nil {
+		return nil, rejections, fmt.Errorf("get product embeddings: %w", err)
+	}
+
+	// Stage 2: Embedding rerank on prefiltered candidates
+	candidates := rerankWithEmbeddings(embedding, productEmbeddings, productIDs, len(productIDs))
+	candidates = penalizeRejected(candidates, rejections.reviewers, cfg.RejectionPenalty)
+	if len(candidates) > cfg.MaxCandidates {
+		candidates = candidates[:cfg.MaxCandidates]
+	}
+
+	scored := make([]Candidate, 0, len(candidates))
+	for _, cand := range candidates {
+		product, err := getProductInfo(ctx, db, cand.ProductID)
+		if err != nil {
+			slog.Error("get product info failed", "product_id", cand.ProductID, "error", err)
+			continue
+		}
+		cand.Product = product
+		cand.ProductName = product.Name
+		scored = append(scored, cand)
+	}
+
+	return scored, rejections, nil
+}
+
+// embedItems returns the normalized texts, their hashes and the embeddings of
+// items, generating those not cached for their current text
+func embedItems(
+	ctx context.Context,
+	db *pgxpool.Pool,
+	cfg AIMatcherConfig,
+	items []RetailerItem,
+	result *AIMatchResult,
+) ([]string, []string, [][]float32, error) {
+	// 1. Normalize all items and compute text hashes
+	texts := make([]string, len(items))
+	hashes := make([]string, len(items))
+	itemIDs := make([]string, len(items))
+
+	for i, item := range items {
+		normalized := NormalizeForEmbedding(FeatureName(item.Name, item.NameI18n), item.Brand, item.Category, item.Unit)
+		texts[i] = normalized
+		hashes[i] = hashText(normalized)
+		itemIDs[i] = item.ID
+	}
+
+	// 2. Check embedding cache
+	cached, err := getCachedEmbeddings(ctx, db, itemIDs, hashes, cfg.Provider.ModelVersion())
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("get cached embeddings: %w", err)
+	}
+
+	// 3. Generate missing embeddings in batch
+	toGenerate := make([]int, 0)
+	for i := range items {
+		if cached[i] == nil {
+			toGenerate = append(toGenerate, i)
+		} else {
+			result.CacheHits++
+		}
+	}
+
+	if len(toGenerate) > 0 {
+		batchTexts := make([]string, len(toGenerate))
+		for i, idx := range toGenerate {
+			batchTexts[i] = texts[idx]
+		}
+
+		embeddings, err := GenerateWithRetry(ctx, cfg.Provider, batchTexts, DefaultEmbeddingRetryConfig())
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("generate embeddings: %w", err)
+		}
+
+		// Store in cache
+		for i, idx := range toGenerate {
+			cached[idx] = embeddings[i]
+			if err := storeEmbeddingCache(ctx, db, items[idx].ID, embeddings[i], texts[idx], hashes[idx], cfg.Provider.ModelVersion(), false); err != nil {
+				slog.Error("cache store failed", "item_id", items[idx].ID, "error", err)
+			}
+		}
+	}
+
+	return texts, hashes, cached, nil
+}
+
 // getUnmatchedItemsForAI gets items that aren't linked to products
 func getUnmatchedItemsForAI(ctx context.Context, db *pgxpool.Pool, limit int) ([]RetailerItem, error) {
 	rows, err := db.Query(ctx, `
 		SELECT
 			ri.id,
 			ri.name,
+			ri.name_i18n,
 			ri.brand,
 			ri.unit,
 			ri.unit_quantity,
@@ -251,6 +308,7 @@ func getUnmatchedItemsForAI(ctx context.Context, db *pgxpool.Pool, limit int) ([
 		if err := rows.Scan(
 			&item.ID,
 			&item.Name,
+			&item.NameI18n,
 			&item.Brand,
 			&item.Unit,
 			&item.UnitQuantity,
@@ -333,29 +391,12 @@ func rerankWithEmbeddings(
 	return candidates
 }
 
-// filterRejections removes candidates that were explicitly rejected
-func filterRejections(ctx context.Context, db *pgxpool.Pool, itemID string, candidates []Candidate) *Candidate {
-	for _, c := range candidates {
-		var exists bool
-		err := db.QueryRow(ctx, `
-			SELECT EXISTS(
-				SELECT 1 FROM product_match_rejections
-				WHERE retailer_item_id = $1 AND rejected_product_id = $2
-			)
-		`, itemID, c.ProductID).Scan(&exists)
-
-		if err != nil || !exists {
-			return &c // First non-rejected candidate
-		}
-	}
-	return nil
-}
-
 // getProductInfo fetches product information
 func getProductInfo(ctx context.Context, db *pgxpool.Pool, productID string) (ProductInfo, error) {
 	var p ProductInfo
 	err := db.QueryRow(ctx, `
-		SELECT id, name, brand, category, unit, unit_quantity, image_url
+		SELECT id, name, COALESCE(brand, ''), COALESCE(category, ''), COALESCE(unit, ''),
+		       COALESCE(unit_quantity, ''), COALESCE(image_url, '')
 		FROM products WHERE id = $1
 	`, productID).Scan(
 		&p.ID,
@@ -428,6 +469,28 @@ func createProductLink(ctx context.Context, db DBExecutor, productID, itemID, ma
 	return err
 }
 
+// AI matching decisions
+const (
+	DecisionAutoLink = "auto_link"
+	DecisionReview   = "review"
+	DecisionNoMatch  = "no_match"
+)
+
+// decideAIMatch returns what AI matching does with an item given its ranked
+// candidates, and for review decisions the reason the item is queued with
+func decideAIMatch(item RetailerItem, candidates []Candidate, cfg AIMatcherConfig) (string, string) {
+	if len(candidates) == 0 || candidates[0].Similarity < cfg.ReviewThreshold {
+		return DecisionNoMatch, ""
+	}
+	if hasPrivateLabelConflict(item, &candidates[0]) {
+		return DecisionReview, "ai_private_label_conflict"
+	}
+	if candidates[0].Similarity >= cfg.AutoLinkThreshold {
+		return DecisionAutoLink, ""
+	}
+	return DecisionReview, "ai_uncertain"
+}
+
 // hasPrivateLabelConflict checks if there's a private label brand conflict
 func hasPrivateLabelConflict(item RetailerItem, candidate *Candidate) bool {
 	// Both have specific, different brands
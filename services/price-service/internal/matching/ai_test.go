@@ -175,6 +175,65 @@ func TestDefaultAIMatcherConfig(t *testing.T) {
 	assert.Equal(t, 100, cfg.BatchSize)
 	assert.Equal(t, 5, cfg.MaxCandidates)
 	assert.Equal(t, 200, cfg.TrgmPrefilter)
+	assert.Equal(t, 500, cfg.RefreshLimit)
+	assert.Equal(t, float32(0.05), cfg.RejectionPenalty)
+}
+
+// TestDecideAIMatch tests AI matching decisions on ranked candidates
+func TestDecideAIMatch(t *testing.T) {
+	cfg := DefaultAIMatcherConfig(nil)
+	item := RetailerItem{ID: "i1", Name: "Mlijeko 2.8%", Brand: "Dukat"}
+	candidate := func(similarity float32, brand string) []Candidate {
+		return []Candidate{{ProductID: "p1", Similarity: similarity, Product: ProductInfo{Brand: brand}}}
+	}
+
+	tests := []struct {
+		name       string
+		candidates []Candidate
+		decision   string
+		reason     string
+	}{
+		{"no candidates", nil, DecisionNoMatch, ""},
+		{"below review threshold", candidate(0.79, "Dukat"), DecisionNoMatch, ""},
+		{"uncertain", candidate(0.90, "Dukat"), DecisionReview, "ai_uncertain"},
+		{"confident", candidate(0.95, "Dukat"), DecisionAutoLink, ""},
+		{"private label conflict", candidate(0.99, "Vindija"), DecisionReview, "ai_private_label_conflict"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, reason := decideAIMatch(item, tt.candidates, cfg)
+			assert.Equal(t, tt.decision, decision)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
+// TestRejectionMemory tests exclusion and penalizing of rejected candidates
+func TestRejectionMemory(t *testing.T) {
+	memory := rejectionMemory{
+		rejected:  map[string]bool{"p2": true},
+		reviewers: map[string]int{"p1": 1, "p3": 5},
+	}
+
+	assert.Equal(t, []string{"p1", "p3", "p4"}, memory.withoutRejected([]string{"p1", "p2", "p3", "p4"}))
+
+	candidates := penalizeRejected([]Candidate{
+		{ProductID: "p1", Similarity: 0.97},
+		{ProductID: "p3", Similarity: 0.96},
+		{ProductID: "p4", Similarity: 0.93},
+	}, memory.reviewers, 0.05)
+
+	// One reviewer costs 0.05, five are capped at maxRejectionPenalty
+	assert.Equal(t, "p4", candidates[0].ProductID)
+	assert.Equal(t, "p1", candidates[1].ProductID)
+	assert.InDelta(t, 0.92, float64(candidates[1].Similarity), 0.00001)
+	assert.Equal(t, "p3", candidates[2].ProductID)
+	assert.InDelta(t, 0.81, float64(candidates[2].Similarity), 0.00001)
+
+	// A zero penalty leaves candidates untouched
+	unchanged := penalizeRejected([]Candidate{{ProductID: "p3", Similarity: 0.96}}, memory.reviewers, 0)
+	assert.InDelta(t, 0.96, float64(unchanged[0].Similarity), 0.00001)
 }
 
 // Mock implementation for testing
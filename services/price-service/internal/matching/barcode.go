@@ -23,6 +23,7 @@ type BarcodeResult struct {
 type RetailerItem struct {
 	ID           string
 	Name         string
+	NameI18n     map[string]string
 	Brand        string
 	Unit         string
 	UnitQuantity string
@@ -44,6 +45,7 @@ func AutoMatchByBarcode(ctx context.Context, db *pgxpool.Pool, batchSize int) (*
 			rib.barcode,
 			ri.id,
 			ri.name,
+			ri.name_i18n,
 			ri.brand,
 			ri.unit,
 			ri.unit_quantity,
@@ -73,6 +75,7 @@ func AutoMatchByBarcode(ctx context.Context, db *pgxpool.Pool, batchSize int) (*
 			&item.Barcode,
 			&item.ID,
 			&item.Name,
+			&item.NameI18n,
 			&item.Brand,
 			&item.Unit,
 			&item.UnitQuantity,
@@ -279,10 +282,10 @@ func createProduct(ctx context.Context, tx pgx.Tx, item RetailerItem) (string, e
 	var productID string
 
 	err := tx.QueryRow(ctx, `
-		INSERT INTO products (id, name, brand, category, subcategory, unit, unit_quantity, image_url, created_at, updated_at)
-		VALUES (gen_random_text(), $1, $2, $3, $4, $5, $6, $7, now(), now())
+		INSERT INTO products (id, name, name_i18n, brand, category, subcategory, unit, unit_quantity, image_url, created_at, updated_at)
+		VALUES (gen_random_text(), $1, $8, $2, $3, $4, $5, $6, $7, now(), now())
 		RETURNING id
-	`, item.Name, item.Brand, item.Category, nil, item.Unit, item.UnitQuantity, item.ImageURL).Scan(&productID)
+	`, item.Name, item.Brand, item.Category, nil, item.Unit, item.UnitQuantity, item.ImageURL, item.NameI18n).Scan(&productID)
 
 	if err != nil {
 		return "", fmt.Errorf("insert product: %w", err)
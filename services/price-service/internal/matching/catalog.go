@@ -0,0 +1,353 @@
+package matching
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kosarica/price-service/internal/outbox"
+)
+
+var (
+	// ErrProductNotFound is returned when a product to merge or split does
+	// not exist
+	ErrProductNotFound = errors.New("product not found")
+	// ErrItemsNotLinked is returned when splitting items that are not linked
+	// to the product, or all of its items
+	ErrItemsNotLinked = errors.New("items must be some of the product's linked items")
+	// ErrMergeIntoSelf is returned when a product is among those merged into it
+	ErrMergeIntoSelf = errors.New("cannot merge a product into itself")
+)
+
+// productRefTable describes a column referencing products(id) that merged
+// products' rows are moved in
+type productRefTable struct {
+	name   string
+	column string
+	// unique lists the columns that together with column must be unique;
+	// rows colliding after the merge keep the target's row
+	unique []string
+	// derived tables hold data regenerated from the product; the merged
+	// products' rows are dropped instead of moved
+	derived bool
+}
+
+var productRefTables = []productRefTable{
+	{name: "product_links", column: "product_id"},
+	{name: "canonical_barcodes", column: "product_id"},
+	{name: "product_aliases", column: "product_id", unique: []string{"lower(alias)"}},
+	{name: "product_relations", column: "product_id", unique: []string{"related_product_id", "relation_type"}},
+	{name: "product_relations", column: "related_product_id", unique: []string{"product_id", "relation_type"}},
+	{name: "product_match_candidates", column: "candidate_product_id", unique: []string{"retailer_item_id"}},
+	{name: "product_match_rejections", column: "rejected_product_id", unique: []string{"retailer_item_id"}},
+	{name: "product_match_queue", column: "linked_product_id"},
+	{name: "product_embeddings", column: "product_id", derived: true},
+}
+
+// MergeResult summarizes a product merge
+type MergeResult struct {
+	ProductID       string
+	MergedIDs       []string
+	RetailerItemIDs []string // items moved to the product
+	Barcodes        int      // canonical barcodes moved to the product
+	Aliases         int      // aliases moved or added to the product
+}
+
+// MergeProducts merges duplicate products into target in one transaction:
+// their links, canonical barcodes, aliases, relations, match candidates,
+// rejections and reviews are moved to target, their names become aliases of
+// target and they are deleted. The merge is recorded in product_catalog_audit
+// and announced by a catalog.products.merged event for caches and search
+// indexes of the merged products to drop.
+func MergeProducts(ctx context.Context, db *pgxpool.Pool, targetID string, sourceIDs []string, actor, reason string) (*MergeResult, error) {
+	if slices.Contains(sourceIDs, targetID) {
+		return nil, ErrMergeIntoSelf
+	}
+
+	tables, err := existingProductRefTables(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	allIDs := append([]string{targetID}, sourceIDs...)
+	var locked int
+	if err := tx.QueryRow(ctx, `
+		SELECT COUNT(*) FROM (SELECT 1 FROM products WHERE id = ANY($1) FOR UPDATE) p
+	`, allIDs).Scan(&locked); err != nil {
+		return nil, fmt.Errorf("lock products: %w", err)
+	}
+	if locked != len(allIDs) {
+		return nil, ErrProductNotFound
+	}
+
+	result := &MergeResult{ProductID: targetID, MergedIDs: sourceIDs}
+
+	// Keep the merged products' rows for the audit log
+	var previous []byte
+	if err := tx.QueryRow(ctx, `
+		SELECT jsonb_agg(to_jsonb(p) ORDER BY p.id) FROM products p WHERE p.id = ANY($1)
+	`, sourceIDs).Scan(&previous); err != nil {
+		return nil, fmt.Errorf("read merged products: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT retailer_item_id FROM product_links WHERE product_id = ANY($1) ORDER BY retailer_item_id
+	`, sourceIDs)
+	if err != nil {
+		return nil, fmt.Errorf("read merged links: %w", err)
+	}
+	if result.RetailerItemIDs, err = pgx.CollectRows(rows, pgx.RowTo[string]); err != nil {
+		return nil, fmt.Errorf("read merged links: %w", err)
+	}
+
+	for _, table := range tables {
+		moved, err := moveProductRefs(ctx, tx, table, targetID, sourceIDs)
+		if err != nil {
+			return nil, fmt.Errorf("merge %s.%s: %w", table.name, table.column, err)
+		}
+		switch table.name {
+		case "canonical_barcodes":
+			result.Barcodes = moved
+		case "product_aliases":
+			result.Aliases = moved
+		}
+	}
+
+	if slices.ContainsFunc(tables, func(t productRefTable) bool { return t.name == "product_relations" }) {
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM product_relations WHERE product_id = $1 AND related_product_id = $1
+		`, targetID); err != nil {
+			return nil, fmt.Errorf("drop self relations: %w", err)
+		}
+	}
+
+	// Keep the merged products' names findable
+	if slices.ContainsFunc(tables, func(t productRefTable) bool { return t.name == "product_aliases" }) {
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO product_aliases (id, product_id, alias, source, created_at)
+			SELECT gen_random_text(), $1, s.name, 'merge', now()
+			FROM products s
+			WHERE s.id = ANY($2)
+			  AND lower(s.name) <> (SELECT lower(name) FROM products WHERE id = $1)
+			  AND NOT EXISTS (
+				SELECT 1 FROM product_aliases a
+				WHERE a.product_id = $1 AND lower(a.alias) = lower(s.name)
+			  )
+		`, targetID, sourceIDs)
+		if err != nil {
+			return nil, fmt.Errorf("add merged names as aliases: %w", err)
+		}
+		result.Aliases += int(tag.RowsAffected())
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM products WHERE id = ANY($1)`, sourceIDs); err != nil {
+		return nil, fmt.Errorf("delete merged products: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE products SET updated_at = now() WHERE id = $1`, targetID); err != nil {
+		return nil, fmt.Errorf("touch product: %w", err)
+	}
+
+	if err := recordCatalogAudit(ctx, tx, "merge", targetID, sourceIDs, result.RetailerItemIDs, previous, actor, reason); err != nil {
+		return nil, err
+	}
+	if err := outbox.Enqueue(ctx, tx, outbox.EventProductsMerged, targetID, outbox.ProductsMergedPayload{
+		ProductID:       targetID,
+		MergedIDs:       sourceIDs,
+		RetailerItemIDs: result.RetailerItemIDs,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit merge: %w", err)
+	}
+	return result, nil
+}
+
+// SplitResult summarizes a product split
+type SplitResult struct {
+	ProductID       string
+	NewProductID    string
+	RetailerItemIDs []string // items moved to the new product
+	Barcodes        int      // canonical barcodes moved to the new product
+}
+
+// SplitProduct detaches some of a product's linked items into a new product
+// in one transaction. The new product is described by the first of the items,
+// named name if given. Canonical barcodes only the detached items carry move
+// with them. The split is recorded in product_catalog_audit and announced by
+// a catalog.product.split event.
+func SplitProduct(ctx context.Context, db *pgxpool.Pool, productID string, itemIDs []string, name, actor, reason string) (*SplitResult, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var previous []byte
+	err = tx.QueryRow(ctx, `SELECT to_jsonb(p) FROM products p WHERE id = $1 FOR UPDATE`, productID).Scan(&previous)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrProductNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lock product: %w", err)
+	}
+
+	var selected, linked int
+	if err := tx.QueryRow(ctx, `
+		SELECT COUNT(*) FILTER (WHERE retailer_item_id = ANY($2)), COUNT(*)
+		FROM product_links WHERE product_id = $1
+	`, productID, itemIDs).Scan(&selected, &linked); err != nil {
+		return nil, fmt.Errorf("count links: %w", err)
+	}
+	if selected != len(itemIDs) || selected == linked {
+		return nil, ErrItemsNotLinked
+	}
+
+	result := &SplitResult{ProductID: productID, RetailerItemIDs: itemIDs}
+
+	var nameArg *string
+	if strings.TrimSpace(name) != "" {
+		nameArg = &name
+	}
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO products (id, name, name_i18n, brand, category, subcategory, unit, unit_quantity, image_url, created_at, updated_at)
+		SELECT gen_random_text(), COALESCE($2, ri.name), ri.name_i18n, ri.brand, COALESCE(ri.category, p.category),
+		       p.subcategory, ri.unit, ri.unit_quantity, COALESCE(ri.image_url, p.image_url), now(), now()
+		FROM retailer_items ri, products p
+		WHERE ri.id = $1 AND p.id = $3
+		RETURNING id
+	`, itemIDs[0], nameArg, productID).Scan(&result.NewProductID); err != nil {
+		return nil, fmt.Errorf("create product: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE product_links SET product_id = $1 WHERE product_id = $2 AND retailer_item_id = ANY($3)
+	`, result.NewProductID, productID, itemIDs); err != nil {
+		return nil, fmt.Errorf("move links: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE canonical_barcodes cb SET product_id = $1
+		WHERE cb.product_id = $2
+		  AND EXISTS (
+			SELECT 1 FROM retailer_item_barcodes rib
+			WHERE rib.barcode = cb.barcode AND rib.retailer_item_id = ANY($3)
+		  )
+		  AND NOT EXISTS (
+			SELECT 1 FROM retailer_item_barcodes rib
+			JOIN product_links pl ON pl.retailer_item_id = rib.retailer_item_id
+			WHERE rib.barcode = cb.barcode AND pl.product_id = $2
+		  )
+	`, result.NewProductID, productID, itemIDs)
+	if err != nil {
+		return nil, fmt.Errorf("move barcodes: %w", err)
+	}
+	result.Barcodes = int(tag.RowsAffected())
+
+	if _, err := tx.Exec(ctx, `UPDATE products SET updated_at = now() WHERE id = $1`, productID); err != nil {
+		return nil, fmt.Errorf("touch product: %w", err)
+	}
+
+	if err := recordCatalogAudit(ctx, tx, "split", productID, []string{result.NewProductID}, itemIDs, previous, actor, reason); err != nil {
+		return nil, err
+	}
+	if err := outbox.Enqueue(ctx, tx, outbox.EventProductSplit, productID, outbox.ProductSplitPayload{
+		ProductID:       productID,
+		NewProductID:    result.NewProductID,
+		RetailerItemIDs: itemIDs,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit split: %w", err)
+	}
+	return result, nil
+}
+
+// existingProductRefTables returns the referencing tables present in the
+// database; some only exist once the feature writing them was enabled
+func existingProductRefTables(ctx context.Context, db *pgxpool.Pool) ([]productRefTable, error) {
+	names := make([]string, len(productRefTables))
+	for i, table := range productRefTables {
+		names[i] = table.name
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT table_name FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_name = ANY($1)
+	`, names)
+	if err != nil {
+		return nil, fmt.Errorf("list referencing tables: %w", err)
+	}
+	existing, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("list referencing tables: %w", err)
+	}
+
+	tables := make([]productRefTable, 0, len(existing))
+	for _, table := range productRefTables {
+		if slices.Contains(existing, table.name) {
+			tables = append(tables, table)
+		}
+	}
+	return tables, nil
+}
+
+// moveProductRefs points the rows of table referencing a merged product at
+// the target, first dropping rows that would collide with the target's or
+// each other's, and returns how many rows were moved
+func moveProductRefs(ctx context.Context, tx pgx.Tx, table productRefTable, targetID string, sourceIDs []string) (int, error) {
+	if table.derived {
+		_, err := tx.Exec(ctx, `DELETE FROM `+table.name+` WHERE `+table.column+` = ANY($1)`, sourceIDs)
+		return 0, err
+	}
+
+	if len(table.unique) > 0 {
+		partition := strings.Join(table.unique, ", ")
+		_, err := tx.Exec(ctx, `
+			DELETE FROM `+table.name+`
+			WHERE `+table.column+` = ANY($1)
+			  AND ctid NOT IN (
+				SELECT DISTINCT ON (`+partition+`) ctid
+				FROM `+table.name+`
+				WHERE `+table.column+` = ANY($1) OR `+table.column+` = $2
+				ORDER BY `+partition+`, `+table.column+` = $2 DESC
+			  )
+		`, sourceIDs, targetID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE `+table.name+` SET `+table.column+` = $1 WHERE `+table.column+` = ANY($2)
+	`, targetID, sourceIDs)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// recordCatalogAudit logs a merge or split with the product rows it replaced
+func recordCatalogAudit(ctx context.Context, tx pgx.Tx, action, productID string, otherIDs, itemIDs []string, previous []byte, actor, reason string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO product_catalog_audit (action, product_id, other_product_ids, retailer_item_ids, previous_state, actor, reason)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''))
+	`, action, productID, otherIDs, itemIDs, previous, actor, reason)
+	if err != nil {
+		return fmt.Errorf("record %s audit: %w", action, err)
+	}
+	return nil
+}
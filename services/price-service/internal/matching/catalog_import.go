@@ -0,0 +1,456 @@
+package matching
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kosarica/price-service/internal/search"
+)
+
+// CatalogRecord is a product of an external catalog, such as a GS1 Croatia
+// export or an open dataset, identified by its GTIN
+type CatalogRecord struct {
+	Barcode      string // normalized GTIN
+	Name         string
+	Brand        string
+	Category     string
+	Unit         string // g, kg, ml, l or kom
+	UnitQuantity string // net quantity in Unit, "." decimals
+}
+
+// catalogColumns lists the header names each field is read from, after
+// lowercasing and replacing spaces and dashes with underscores
+var catalogColumns = map[string][]string{
+	"barcode":  {"gtin", "ean", "barcode", "barkod", "code"},
+	"name":     {"name", "product_name", "naziv", "naziv_proizvoda", "trade_item_description"},
+	"brand":    {"brand", "brand_name", "brands", "marka"},
+	"category": {"category", "kategorija", "gpc_category_description", "categories"},
+	"quantity": {"net_content", "neto_kolicina", "quantity", "kolicina"},
+	"unit":     {"net_content_uom", "unit", "jedinica_mjere"},
+}
+
+// catalogUnits maps net content units, including GS1 (UN/ECE) unit codes, to
+// the units retailer items use. Centiliters are converted to milliliters.
+var catalogUnits = map[string]string{
+	"g": "g", "gr": "g", "grm": "g",
+	"kg": "kg", "kgm": "kg",
+	"ml": "ml", "mlt": "ml", "cl": "cl", "clt": "cl",
+	"l": "l", "ltr": "l",
+	"kom": "kom", "pcs": "kom", "h87": "kom", "ea": "kom",
+}
+
+// netContentRe matches a net content with its unit, like "500 g" or "1,5L"
+var netContentRe = regexp.MustCompile(`^(\d+(?:[.,]\d+)?)\s*([a-zA-Z0-9]+)$`)
+
+// ErrNoCatalogColumns is returned when a catalog file lacks a barcode or a
+// name column
+var ErrNoCatalogColumns = errors.New("catalog needs a barcode (gtin, ean) and a name column")
+
+// ReadCatalogCSV reads catalog records from a CSV export with a header row,
+// separated by commas, semicolons or tabs. Records without a valid GTIN or a
+// name are skipped and counted in invalid; of records sharing a GTIN only the
+// first is kept.
+func ReadCatalogCSV(r io.Reader) (records []CatalogRecord, invalid int, err error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, 0, fmt.Errorf("read catalog: %w", err)
+	}
+
+	reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(string(content), "\uFEFF")))
+	reader.Comma = detectCatalogDelimiter(string(content))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, 0, fmt.Errorf("read catalog header: %w", err)
+	}
+	columns := catalogColumnIndexes(header)
+	if columns["barcode"] < 0 || columns["name"] < 0 {
+		return nil, 0, ErrNoCatalogColumns
+	}
+
+	seen := make(map[string]bool)
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("read catalog row: %w", err)
+		}
+
+		field := func(name string) string {
+			if i := columns[name]; i >= 0 && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		rec := CatalogRecord{
+			Barcode:  normalizeGTIN(field("barcode")),
+			Name:     field("name"),
+			Brand:    firstListValue(field("brand")),
+			Category: firstListValue(field("category")),
+		}
+		rec.UnitQuantity, rec.Unit = parseNetContent(field("quantity"), field("unit"))
+
+		if rec.Barcode == "" || rec.Name == "" {
+			invalid++
+			continue
+		}
+		if seen[rec.Barcode] {
+			continue
+		}
+		seen[rec.Barcode] = true
+		records = append(records, rec)
+	}
+
+	return records, invalid, nil
+}
+
+// normalizeGTIN returns a GTIN as barcode matching stores it, empty unless it
+// is a valid EAN-8 or EAN-13, or a GTIN-14 without packaging indicator
+func normalizeGTIN(gtin string) string {
+	bc := nonDigitRe.ReplaceAllString(gtin, "")
+	if len(bc) == 14 && bc[0] == '0' {
+		bc = bc[1:]
+	}
+	bc = NormalizeBarcode(bc)
+	if len(bc) != 8 && len(bc) != 13 {
+		return ""
+	}
+	return bc
+}
+
+// detectCatalogDelimiter picks the most frequent of comma, semicolon and tab
+// in the header line
+func detectCatalogDelimiter(content string) rune {
+	header, _, _ := strings.Cut(content, "\n")
+	delimiter, best := ',', 0
+	for _, candidate := range []rune{',', ';', '\t'} {
+		if n := strings.Count(header, string(candidate)); n > best {
+			delimiter, best = candidate, n
+		}
+	}
+	return delimiter
+}
+
+// catalogColumnIndexes returns the header index of each catalog field, -1
+// when absent
+func catalogColumnIndexes(header []string) map[string]int {
+	normalized := make([]string, len(header))
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(h))
+		normalized[i] = strings.NewReplacer(" ", "_", "-", "_").Replace(RemoveDiacritics(h))
+	}
+
+	indexes := make(map[string]int, len(catalogColumns))
+	for field, names := range catalogColumns {
+		indexes[field] = -1
+		for _, name := range names {
+			if i := slices.Index(normalized, name); i >= 0 {
+				indexes[field] = i
+				break
+			}
+		}
+	}
+	return indexes
+}
+
+// firstListValue returns the first of comma separated values, as open
+// datasets list several brands or categories per product
+func firstListValue(s string) string {
+	first, _, _ := strings.Cut(s, ",")
+	return strings.TrimSpace(first)
+}
+
+// parseNetContent returns the net quantity and unit of a product, from a
+// quantity and a unit column or a quantity with its unit like "500 g".
+// Unknown units yield no net content.
+func parseNetContent(quantity, unit string) (string, string) {
+	quantity = strings.TrimSpace(quantity)
+	unit = strings.ToLower(strings.TrimSpace(unit))
+	if unit == "" {
+		m := netContentRe.FindStringSubmatch(quantity)
+		if m == nil {
+			return "", ""
+		}
+		quantity, unit = m[1], strings.ToLower(m[2])
+	}
+
+	canonical, ok := catalogUnits[unit]
+	if !ok {
+		return "", ""
+	}
+	value, err := strconv.ParseFloat(strings.ReplaceAll(quantity, ",", "."), 64)
+	if err != nil || value <= 0 {
+		return "", ""
+	}
+	if canonical == "cl" {
+		canonical, value = "ml", value*10
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64), canonical
+}
+
+// Catalog conflict policies: which value wins when an imported brand or net
+// content disagrees with the existing product's
+const (
+	PreferExisting = "existing"
+	PreferCatalog  = "catalog"
+)
+
+// CatalogImportOptions configures a catalog import
+type CatalogImportOptions struct {
+	Source string // catalog the records come from, e.g. "gs1-hr"
+	Prefer string // PreferExisting or PreferCatalog
+	DryRun bool   // roll every change back
+}
+
+// CatalogImportResult summarizes a catalog import
+type CatalogImportResult struct {
+	Records   int
+	Created   int // products created for unknown barcodes
+	Updated   int // existing products given missing or preferred values
+	Verified  int // canonical barcodes confirmed by the catalog
+	Aliases   int // catalog names added as aliases of existing products
+	Conflicts int // disagreements recorded for review
+}
+
+// catalogProduct is the part of an existing product a record is checked against
+type catalogProduct struct {
+	ID           string
+	Name         string
+	Brand        string
+	Category     string
+	Unit         string
+	UnitQuantity string
+}
+
+// catalogConflict is a field an imported record disagrees on
+type catalogConflict struct {
+	Field    string
+	Existing string
+	Imported string
+}
+
+// catalogResolution is how a record is applied to an existing product
+type catalogResolution struct {
+	// Values to set, empty when unchanged
+	Brand        string
+	Category     string
+	Unit         string
+	UnitQuantity string
+	Alias        string // catalog name to add as an alias
+	Conflicts    []catalogConflict
+}
+
+func (r catalogResolution) updates() bool {
+	return r.Brand != "" || r.Category != "" || r.Unit != ""
+}
+
+// resolveCatalogRecord compares a record with the product its barcode maps
+// to. Missing values are filled in; differing brands and net contents are
+// conflicts, resolved by prefer. A differing name becomes an alias, unless it
+// shares no word with the product's, which suggests the barcode maps to the
+// wrong product: that is a conflict left for review.
+func resolveCatalogRecord(product catalogProduct, rec CatalogRecord, prefer string) catalogResolution {
+	var res catalogResolution
+
+	switch {
+	case rec.Brand == "":
+	case product.Brand == "" || isGenericBrand(product.Brand):
+		res.Brand = rec.Brand
+	case search.Fold(product.Brand) != search.Fold(rec.Brand):
+		res.Conflicts = append(res.Conflicts, catalogConflict{"brand", product.Brand, rec.Brand})
+		if prefer == PreferCatalog {
+			res.Brand = rec.Brand
+		}
+	}
+
+	if rec.Unit != "" {
+		existing := NormalizeUnit(product.Unit, strings.ReplaceAll(product.UnitQuantity, ",", "."))
+		imported := NormalizeUnit(rec.Unit, rec.UnitQuantity)
+		switch {
+		case product.Unit == "" || product.UnitQuantity == "":
+			res.Unit, res.UnitQuantity = rec.Unit, rec.UnitQuantity
+		case existing != imported:
+			res.Conflicts = append(res.Conflicts, catalogConflict{"net_content", existing, imported})
+			if prefer == PreferCatalog {
+				res.Unit, res.UnitQuantity = rec.Unit, rec.UnitQuantity
+			}
+		}
+	}
+
+	if product.Category == "" && rec.Category != "" {
+		res.Category = rec.Category
+	}
+
+	if search.Fold(product.Name) != search.Fold(rec.Name) {
+		if sharesWord(product.Name, rec.Name) {
+			res.Alias = rec.Name
+		} else {
+			res.Conflicts = append(res.Conflicts, catalogConflict{"name", product.Name, rec.Name})
+		}
+	}
+
+	return res
+}
+
+// sharesWord reports whether two names have a word of at least 3 letters in
+// common, ignoring case and diacritics
+func sharesWord(a, b string) bool {
+	words := make(map[string]bool)
+	for _, w := range strings.Fields(search.Fold(a)) {
+		if len([]rune(w)) >= 3 {
+			words[w] = true
+		}
+	}
+	for _, w := range strings.Fields(search.Fold(b)) {
+		if words[w] {
+			return true
+		}
+	}
+	return false
+}
+
+// ImportCatalog seeds canonical products from catalog records. A record whose
+// barcode is unknown creates a product and registers the barcode; one whose
+// barcode maps to a product fills in the product's missing values and records
+// disagreements in catalog_import_conflicts, resolved per opts.Prefer. Either
+// way the barcode is marked verified by the source. Each record is applied in
+// its own transaction under the barcode's advisory lock, which barcode
+// matching takes too.
+func ImportCatalog(ctx context.Context, db *pgxpool.Pool, records []CatalogRecord, opts CatalogImportOptions) (*CatalogImportResult, error) {
+	result := &CatalogImportResult{}
+	for _, rec := range records {
+		if err := importCatalogRecord(ctx, db, rec, opts, result); err != nil {
+			return result, fmt.Errorf("import %s: %w", rec.Barcode, err)
+		}
+		result.Records++
+	}
+	return result, nil
+}
+
+// importCatalogRecord applies one record, rolling back in a dry run
+func importCatalogRecord(ctx context.Context, db *pgxpool.Pool, rec CatalogRecord, opts CatalogImportOptions, result *CatalogImportResult) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, rec.Barcode); err != nil {
+		return fmt.Errorf("advisory lock: %w", err)
+	}
+
+	var product catalogProduct
+	err = tx.QueryRow(ctx, `
+		SELECT p.id, p.name, COALESCE(p.brand, ''), COALESCE(p.category, ''),
+		       COALESCE(p.unit, ''), COALESCE(p.unit_quantity, '')
+		FROM canonical_barcodes cb
+		JOIN products p ON p.id = cb.product_id
+		WHERE cb.barcode = $1
+	`, rec.Barcode).Scan(&product.ID, &product.Name, &product.Brand, &product.Category, &product.Unit, &product.UnitQuantity)
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		if err := tx.QueryRow(ctx, `
+			INSERT INTO products (id, name, brand, category, unit, unit_quantity, created_at, updated_at)
+			VALUES (gen_random_text(), $1, NULLIF($2, ''), NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), now(), now())
+			RETURNING id
+		`, rec.Name, rec.Brand, rec.Category, rec.Unit, rec.UnitQuantity).Scan(&product.ID); err != nil {
+			return fmt.Errorf("create product: %w", err)
+		}
+		result.Created++
+	case err != nil:
+		return fmt.Errorf("query canonical barcode: %w", err)
+	default:
+		if err := applyCatalogResolution(ctx, tx, product, rec, opts, result); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO canonical_barcodes (barcode, product_id, source, verified_at)
+		VALUES ($1, $2, $3, now())
+		ON CONFLICT (barcode) DO UPDATE SET
+			product_id = EXCLUDED.product_id,
+			source = EXCLUDED.source,
+			verified_at = EXCLUDED.verified_at
+	`, rec.Barcode, product.ID, opts.Source); err != nil {
+		return fmt.Errorf("register barcode: %w", err)
+	}
+	result.Verified++
+
+	if opts.DryRun {
+		return nil
+	}
+	return tx.Commit(ctx)
+}
+
+// applyCatalogResolution updates an existing product from a record and
+// records the conflicts
+func applyCatalogResolution(ctx context.Context, tx pgx.Tx, product catalogProduct, rec CatalogRecord, opts CatalogImportOptions, result *CatalogImportResult) error {
+	res := resolveCatalogRecord(product, rec, opts.Prefer)
+
+	if res.updates() {
+		if _, err := tx.Exec(ctx, `
+			UPDATE products SET
+				brand = COALESCE(NULLIF($2, ''), brand),
+				category = COALESCE(NULLIF($3, ''), category),
+				unit = COALESCE(NULLIF($4, ''), unit),
+				unit_quantity = COALESCE(NULLIF($5, ''), unit_quantity),
+				updated_at = now()
+			WHERE id = $1
+		`, product.ID, res.Brand, res.Category, res.Unit, res.UnitQuantity); err != nil {
+			return fmt.Errorf("update product: %w", err)
+		}
+		result.Updated++
+	}
+
+	if res.Alias != "" {
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO product_aliases (id, product_id, alias, source, created_at)
+			SELECT gen_random_text(), $1, $2, $3, now()
+			WHERE NOT EXISTS (
+				SELECT 1 FROM product_aliases WHERE product_id = $1 AND lower(alias) = lower($2)
+			)
+		`, product.ID, res.Alias, opts.Source)
+		if err != nil {
+			return fmt.Errorf("add alias: %w", err)
+		}
+		result.Aliases += int(tag.RowsAffected())
+	}
+
+	for _, conflict := range res.Conflicts {
+		resolution := "kept"
+		if opts.Prefer == PreferCatalog && conflict.Field != "name" {
+			resolution = "overwritten"
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO catalog_import_conflicts (source, barcode, product_id, field, existing_value, imported_value, resolution)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (source, barcode, field) DO UPDATE SET
+				product_id = EXCLUDED.product_id,
+				existing_value = EXCLUDED.existing_value,
+				imported_value = EXCLUDED.imported_value,
+				resolution = EXCLUDED.resolution,
+				created_at = now()
+		`, opts.Source, rec.Barcode, product.ID, conflict.Field, conflict.Existing, conflict.Imported, resolution); err != nil {
+			return fmt.Errorf("record conflict: %w", err)
+		}
+		result.Conflicts++
+	}
+
+	return nil
+}
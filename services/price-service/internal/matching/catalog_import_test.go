@@ -0,0 +1,121 @@
+package matching
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReadCatalogCSV tests reading GS1 style and open dataset exports
+func TestReadCatalogCSV(t *testing.T) {
+	t.Run("GS1 export", func(t *testing.T) {
+		content := "\uFEFFGTIN;Naziv proizvoda;Marka;Neto količina;Jedinica mjere\n" +
+			"03850012345678;Mlijeko 2,8% m.m.;Dukat;1;LTR\n" +
+			"4006381333931;Olovka;Stabilo;33;CLT\n" +
+			"3850012345679;Neispravan barkod;Dukat;1;LTR\n" +
+			"3850012345678;Duplikat;Dukat;1;LTR\n"
+
+		records, invalid, err := ReadCatalogCSV(strings.NewReader(content))
+		require.NoError(t, err)
+		assert.Equal(t, 1, invalid)
+		assert.Equal(t, []CatalogRecord{
+			{Barcode: "3850012345678", Name: "Mlijeko 2,8% m.m.", Brand: "Dukat", Unit: "l", UnitQuantity: "1"},
+			{Barcode: "4006381333931", Name: "Olovka", Brand: "Stabilo", Unit: "ml", UnitQuantity: "330"},
+		}, records)
+	})
+
+	t.Run("open dataset", func(t *testing.T) {
+		content := "code,product_name,brands,quantity,categories\n" +
+			"3850012345678,Fresh milk,\"Dukat, Lactalis\",1 l,\"Dairies, Milks\"\n"
+
+		records, invalid, err := ReadCatalogCSV(strings.NewReader(content))
+		require.NoError(t, err)
+		assert.Zero(t, invalid)
+		assert.Equal(t, []CatalogRecord{
+			{Barcode: "3850012345678", Name: "Fresh milk", Brand: "Dukat", Category: "Dairies", Unit: "l", UnitQuantity: "1"},
+		}, records)
+	})
+
+	t.Run("missing columns", func(t *testing.T) {
+		_, _, err := ReadCatalogCSV(strings.NewReader("name,brand\nMlijeko,Dukat\n"))
+		assert.ErrorIs(t, err, ErrNoCatalogColumns)
+	})
+}
+
+// TestParseNetContent tests net content parsing
+func TestParseNetContent(t *testing.T) {
+	tests := []struct {
+		quantity, unit    string
+		wantQty, wantUnit string
+	}{
+		{"500 g", "", "500", "g"},
+		{"1,5L", "", "1.5", "l"},
+		{"0.75", "LTR", "0.75", "l"},
+		{"25", "clt", "250", "ml"},
+		{"6", "H87", "6", "kom"},
+		{"1 pack", "", "", ""},
+		{"", "", "", ""},
+		{"-1", "g", "", ""},
+	}
+
+	for _, tt := range tests {
+		qty, unit := parseNetContent(tt.quantity, tt.unit)
+		assert.Equal(t, tt.wantQty, qty, "quantity of %q %q", tt.quantity, tt.unit)
+		assert.Equal(t, tt.wantUnit, unit, "unit of %q %q", tt.quantity, tt.unit)
+	}
+}
+
+// TestResolveCatalogRecord tests conflict resolution against existing products
+func TestResolveCatalogRecord(t *testing.T) {
+	product := catalogProduct{ID: "p1", Name: "Dukat mlijeko 2.8% 1L", Brand: "Dukat", Unit: "l", UnitQuantity: "1"}
+	rec := CatalogRecord{Barcode: "3850012345678", Name: "Trajno mlijeko 2,8% m.m.", Brand: "Dukat", Category: "Mlijeko", Unit: "ml", UnitQuantity: "1000"}
+
+	t.Run("agreeing record fills gaps and adds alias", func(t *testing.T) {
+		res := resolveCatalogRecord(product, rec, PreferExisting)
+		assert.Empty(t, res.Conflicts)
+		assert.Empty(t, res.Brand)
+		assert.Empty(t, res.Unit, "1000 ml is 1 l")
+		assert.Equal(t, "Mlijeko", res.Category)
+		assert.Equal(t, rec.Name, res.Alias)
+	})
+
+	t.Run("missing values are filled", func(t *testing.T) {
+		bare := catalogProduct{ID: "p1", Name: "Mlijeko", Brand: "n/a"}
+		res := resolveCatalogRecord(bare, rec, PreferExisting)
+		assert.Empty(t, res.Conflicts)
+		assert.Equal(t, "Dukat", res.Brand)
+		assert.Equal(t, "ml", res.Unit)
+		assert.Equal(t, "1000", res.UnitQuantity)
+	})
+
+	disagreeing := rec
+	disagreeing.Brand = "Vindija"
+	disagreeing.UnitQuantity = "500"
+
+	t.Run("existing values are preferred", func(t *testing.T) {
+		res := resolveCatalogRecord(product, disagreeing, PreferExisting)
+		assert.Equal(t, []catalogConflict{
+			{"brand", "Dukat", "Vindija"},
+			{"net_content", "1l", "500ml"},
+		}, res.Conflicts)
+		assert.Empty(t, res.Brand)
+		assert.Empty(t, res.Unit)
+	})
+
+	t.Run("catalog values are preferred", func(t *testing.T) {
+		res := resolveCatalogRecord(product, disagreeing, PreferCatalog)
+		assert.Len(t, res.Conflicts, 2)
+		assert.Equal(t, "Vindija", res.Brand)
+		assert.Equal(t, "500", res.UnitQuantity)
+	})
+
+	t.Run("unrelated name is a conflict", func(t *testing.T) {
+		unrelated := rec
+		unrelated.Name = "Čokolada s lješnjacima"
+		res := resolveCatalogRecord(product, unrelated, PreferCatalog)
+		assert.Equal(t, []catalogConflict{{"name", product.Name, unrelated.Name}}, res.Conflicts)
+		assert.Empty(t, res.Alias)
+	})
+}
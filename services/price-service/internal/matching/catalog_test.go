@@ -0,0 +1,216 @@
+package matching
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupCatalogSchema adds what merges and splits write to the integration
+// test schema: product timestamps, aliases, relations, the audit log and
+// the outbox
+func setupCatalogSchema(ctx context.Context, t *testing.T, db *pgxpool.Pool) {
+	_, err := db.Exec(ctx, `
+		CREATE OR REPLACE FUNCTION gen_random_text() RETURNS text
+			LANGUAGE sql AS $$ SELECT gen_random_uuid()::text $$;
+
+		ALTER TABLE products
+			ADD COLUMN subcategory TEXT,
+			ADD COLUMN created_at TIMESTAMP DEFAULT now(),
+			ADD COLUMN updated_at TIMESTAMP DEFAULT now();
+
+		CREATE TABLE product_aliases (
+			id TEXT PRIMARY KEY,
+			product_id TEXT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+			alias TEXT NOT NULL,
+			source TEXT,
+			created_at TIMESTAMP DEFAULT now()
+		);
+
+		CREATE TABLE product_relations (
+			id TEXT PRIMARY KEY,
+			product_id TEXT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+			related_product_id TEXT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+			relation_type TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT now()
+		);
+
+		CREATE TABLE product_catalog_audit (
+			id BIGSERIAL PRIMARY KEY,
+			action TEXT NOT NULL,
+			product_id TEXT NOT NULL,
+			other_product_ids TEXT[] NOT NULL,
+			retailer_item_ids TEXT[] NOT NULL DEFAULT '{}',
+			previous_state JSONB,
+			actor TEXT,
+			reason TEXT,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		);
+
+		CREATE TABLE event_outbox (
+			id BIGSERIAL PRIMARY KEY,
+			event_type TEXT NOT NULL,
+			aggregate_id TEXT NOT NULL,
+			payload JSONB NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT now(),
+			available_at TIMESTAMP NOT NULL DEFAULT now(),
+			delivered_at TIMESTAMP,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT
+		);
+	`)
+	require.NoError(t, err)
+}
+
+// TestMergeAndSplitProductsIntegration merges a duplicate into a product,
+// checks everything referencing the duplicate now references the product,
+// then splits the duplicate's items off again
+func TestMergeAndSplitProductsIntegration(t *testing.T) {
+	ctx := context.Background()
+
+	db, cleanup, err := setupIntegrationTestDB(ctx, t)
+	if err != nil {
+		t.Skipf("Skipping integration test: %v", err)
+		return
+	}
+	defer cleanup()
+	setupCatalogSchema(ctx, t, db)
+
+	_, err = db.Exec(ctx, `
+		INSERT INTO chains (slug, name) VALUES ('test', 'Test Chain');
+
+		INSERT INTO retailer_items (id, chain_slug, name, brand, unit, unit_quantity)
+		VALUES
+			('rit1', 'test', 'Mlijeko 1L', 'Dukat', 'l', '1'),
+			('rit2', 'test', 'Mlijeko Dukat 1 l', 'Dukat', 'l', '1'),
+			('rit3', 'test', 'Dukat mlijeko 1l', 'Dukat', 'l', '1');
+
+		INSERT INTO retailer_item_barcodes (id, retailer_item_id, barcode)
+		VALUES ('rib1', 'rit1', '111'), ('rib2', 'rit2', '222'), ('rib3', 'rit3', '333');
+
+		INSERT INTO products (id, name, brand) VALUES ('p1', 'Mlijeko 1L', 'Dukat'), ('p2', 'Mlijeko Dukat 1 l', 'Dukat');
+
+		INSERT INTO product_links (id, product_id, retailer_item_id)
+		VALUES ('pl1', 'p1', 'rit1'), ('pl2', 'p2', 'rit2'), ('pl3', 'p2', 'rit3');
+
+		INSERT INTO canonical_barcodes (barcode, product_id) VALUES ('111', 'p1'), ('222', 'p2'), ('333', 'p2');
+
+		INSERT INTO product_aliases (id, product_id, alias)
+		VALUES ('pa1', 'p1', 'Dukat mlijeko'), ('pa2', 'p2', 'dukat mlijeko'), ('pa3', 'p2', 'trajno mlijeko');
+
+		INSERT INTO product_relations (id, product_id, related_product_id, relation_type)
+		VALUES ('pr1', 'p1', 'p2', 'substitute');
+
+		INSERT INTO product_match_candidates (id, retailer_item_id, candidate_product_id, match_type)
+		VALUES ('pmc1', 'rit1', 'p2', 'ai');
+	`)
+	require.NoError(t, err)
+
+	merged, err := MergeProducts(ctx, db, "p1", []string{"p2"}, "admin", "duplicate")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rit2", "rit3"}, merged.RetailerItemIDs)
+	assert.Equal(t, 2, merged.Barcodes)
+	// The duplicate's second alias moves and its name is added; its first
+	// alias collides with the product's own
+	assert.Equal(t, 2, merged.Aliases)
+
+	var links, barcodes, candidates, relations, duplicates int
+	err = db.QueryRow(ctx, `
+		SELECT (SELECT COUNT(*) FROM product_links WHERE product_id = 'p1'),
+		       (SELECT COUNT(*) FROM canonical_barcodes WHERE product_id = 'p1'),
+		       (SELECT COUNT(*) FROM product_match_candidates WHERE candidate_product_id = 'p1'),
+		       (SELECT COUNT(*) FROM product_relations),
+		       (SELECT COUNT(*) FROM products WHERE id = 'p2')
+	`).Scan(&links, &barcodes, &candidates, &relations, &duplicates)
+	require.NoError(t, err)
+	assert.Equal(t, 3, links)
+	assert.Equal(t, 3, barcodes)
+	assert.Equal(t, 1, candidates)
+	assert.Zero(t, relations, "a relation between the merged products is dropped")
+	assert.Zero(t, duplicates)
+
+	rows, err := db.Query(ctx, `SELECT alias FROM product_aliases WHERE product_id = 'p1' ORDER BY alias`)
+	require.NoError(t, err)
+	var aliases []string
+	for rows.Next() {
+		var alias string
+		require.NoError(t, rows.Scan(&alias))
+		aliases = append(aliases, alias)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []string{"Dukat mlijeko", "Mlijeko Dukat 1 l", "trajno mlijeko"}, aliases)
+
+	split, err := SplitProduct(ctx, db, "p1", []string{"rit2", "rit3"}, "Mlijeko Dukat 1 l", "admin", "not a duplicate")
+	require.NoError(t, err)
+	assert.Equal(t, 2, split.Barcodes)
+
+	var name string
+	err = db.QueryRow(ctx, `
+		SELECT p.name,
+		       (SELECT COUNT(*) FROM product_links WHERE product_id = p.id),
+		       (SELECT COUNT(*) FROM canonical_barcodes WHERE product_id = p.id)
+		FROM products p WHERE p.id = $1
+	`, split.NewProductID).Scan(&name, &links, &barcodes)
+	require.NoError(t, err)
+	assert.Equal(t, "Mlijeko Dukat 1 l", name)
+	assert.Equal(t, 2, links)
+	assert.Equal(t, 2, barcodes)
+
+	var barcode string
+	err = db.QueryRow(ctx, `SELECT barcode FROM canonical_barcodes WHERE product_id = 'p1'`).Scan(&barcode)
+	require.NoError(t, err)
+	assert.Equal(t, "111", barcode)
+
+	var audits, events int
+	err = db.QueryRow(ctx, `
+		SELECT (SELECT COUNT(*) FROM product_catalog_audit),
+		       (SELECT COUNT(*) FROM event_outbox WHERE event_type IN ('catalog.products.merged', 'catalog.product.split'))
+	`).Scan(&audits, &events)
+	require.NoError(t, err)
+	assert.Equal(t, 2, audits)
+	assert.Equal(t, 2, events)
+
+	// Failed operations change nothing
+	tests := []struct {
+		name string
+		run  func() error
+		want error
+	}{
+		{"merge into a missing product", func() error {
+			_, err := MergeProducts(ctx, db, "missing", []string{"p1"}, "admin", "")
+			return err
+		}, ErrProductNotFound},
+		{"merge a missing product", func() error {
+			_, err := MergeProducts(ctx, db, "p1", []string{"missing"}, "admin", "")
+			return err
+		}, ErrProductNotFound},
+		{"merge into itself", func() error {
+			_, err := MergeProducts(ctx, db, "p1", []string{"p1"}, "admin", "")
+			return err
+		}, ErrMergeIntoSelf},
+		{"split a missing product", func() error {
+			_, err := SplitProduct(ctx, db, "missing", []string{"rit1"}, "", "admin", "")
+			return err
+		}, ErrProductNotFound},
+		{"split all items", func() error {
+			_, err := SplitProduct(ctx, db, "p1", []string{"rit1"}, "", "admin", "")
+			return err
+		}, ErrItemsNotLinked},
+		{"split an item of another product", func() error {
+			_, err := SplitProduct(ctx, db, "p1", []string{"rit2"}, "", "admin", "")
+			return err
+		}, ErrItemsNotLinked},
+	}
+	for _, tt := range tests {
+		err := tt.run()
+		assert.True(t, errors.Is(err, tt.want), "%s: got %v", tt.name, err)
+	}
+
+	err = db.QueryRow(ctx, `SELECT COUNT(*) FROM product_catalog_audit`).Scan(&audits)
+	require.NoError(t, err)
+	assert.Equal(t, 2, audits)
+}
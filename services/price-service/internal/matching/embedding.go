@@ -263,7 +263,7 @@ func EnsureProductEmbeddings(
 ) error {
 	// Get products without embeddings for current model version
 	rows, err := db.Query(ctx, `
-		SELECT p.id, p.name, p.brand, p.category, p.unit
+		SELECT p.id, p.name, p.name_i18n, p.brand, p.category, p.unit
 		FROM products p
 		WHERE NOT EXISTS (
 			SELECT 1 FROM product_embeddings pe
@@ -279,6 +279,7 @@ func EnsureProductEmbeddings(
 	type productInfo struct {
 		ID       string
 		Name     string
+		NameI18n map[string]string
 		Brand    string
 		Category string
 		Unit     string
@@ -287,7 +288,7 @@ func EnsureProductEmbeddings(
 	products := []productInfo{}
 	for rows.Next() {
 		var p productInfo
-		if err := rows.Scan(&p.ID, &p.Name, &p.Brand, &p.Category, &p.Unit); err != nil {
+		if err := rows.Scan(&p.ID, &p.Name, &p.NameI18n, &p.Brand, &p.Category, &p.Unit); err != nil {
 			slog.Error("scan product row", "error", err)
 			continue
 		}
@@ -310,7 +311,7 @@ func EnsureProductEmbeddings(
 		texts := make([]string, len(batch))
 		hashes := make([]string, len(batch))
 		for j, p := range batch {
-			normalized := NormalizeForEmbedding(p.Name, p.Brand, p.Category, p.Unit)
+			normalized := NormalizeForEmbedding(FeatureName(p.Name, p.NameI18n), p.Brand, p.Category, p.Unit)
 			texts[j] = normalized
 			hashes[j] = hashText(normalized)
 		}
@@ -114,6 +114,7 @@ func runTestMigrations(ctx context.Context, db *pgxpool.Pool) error {
 			id TEXT PRIMARY KEY,
 			chain_slug TEXT NOT NULL REFERENCES chains(slug),
 			name TEXT NOT NULL,
+			name_i18n JSONB,
 			brand TEXT,
 			category TEXT,
 			unit TEXT,
@@ -125,6 +126,7 @@ func runTestMigrations(ctx context.Context, db *pgxpool.Pool) error {
 		CREATE TABLE IF NOT EXISTS products (
 			id TEXT PRIMARY KEY,
 			name TEXT NOT NULL,
+			name_i18n JSONB,
 			brand TEXT,
 			category TEXT,
 			unit TEXT,
@@ -178,6 +180,7 @@ func runTestMigrations(ctx context.Context, db *pgxpool.Pool) error {
 		CREATE TABLE IF NOT EXISTS product_match_rejections (
 			retailer_item_id TEXT NOT NULL REFERENCES retailer_items(id),
 			rejected_product_id TEXT NOT NULL REFERENCES products(id),
+			rejected_by TEXT,
 			PRIMARY KEY (retailer_item_id, rejected_product_id)
 		);
 	`
@@ -2,6 +2,7 @@ package matching
 
 import (
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"unicode"
@@ -136,6 +137,24 @@ func NormalizeUnit(unit, quantity string) string {
 	return u
 }
 
+// FeatureName returns a name followed by its names in other languages, in
+// language order, so bilingual names contribute to matching
+func FeatureName(name string, nameI18n map[string]string) string {
+	languages := make([]string, 0, len(nameI18n))
+	for language := range nameI18n {
+		languages = append(languages, language)
+	}
+	slices.Sort(languages)
+
+	parts := []string{name}
+	for _, language := range languages {
+		if secondary := strings.TrimSpace(nameI18n[language]); secondary != "" {
+			parts = append(parts, secondary)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
 // NormalizeForEmbedding normalizes text for AI embedding generation
 // Includes diacritic removal, lowercasing, and extra whitespace cleanup
 func NormalizeForEmbedding(name, brand, category, unit string) string {
@@ -136,6 +136,27 @@ func TestNormalizeForEmbedding(t *testing.T) {
 	}
 }
 
+func TestFeatureName(t *testing.T) {
+	tests := []struct {
+		name     string
+		nameI18n map[string]string
+		expected string
+	}{
+		{"Mlijeko", nil, "Mlijeko"},
+		{"Mlijeko", map[string]string{"en": "Milk"}, "Mlijeko Milk"},
+		{"Mlijeko", map[string]string{"it": "Latte", "en": "Milk", "de": " "}, "Mlijeko Milk Latte"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			result := FeatureName(tt.name, tt.nameI18n)
+			if result != tt.expected {
+				t.Errorf("FeatureName() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestIsGenericBrand(t *testing.T) {
 	tests := []struct {
 		brand    string
@@ -0,0 +1,178 @@
+package matching
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// changedItem is a retailer item queued for embedding refresh
+type changedItem struct {
+	RetailerItem
+	linked     bool
+	enqueuedAt time.Time
+}
+
+// RefreshChangedItems re-embeds items whose name or brand changed after their
+// embedding was cached, as queued in embedding_refresh_queue by a trigger on
+// retailer_items. Items not yet linked to a product also have their AI
+// candidates re-evaluated; linked items keep their link. At most
+// cfg.RefreshLimit items are processed per call, those failing least often
+// and longest queued first.
+func RefreshChangedItems(ctx context.Context, db *pgxpool.Pool, cfg AIMatcherConfig, runID string, result *AIMatchResult) error {
+	if cfg.RefreshLimit <= 0 {
+		return nil
+	}
+
+	items, err := getChangedItems(ctx, db, cfg.RefreshLimit)
+	if err != nil {
+		return fmt.Errorf("get changed items: %w", err)
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	for i := 0; i < len(items); i += cfg.BatchSize {
+		end := min(i+cfg.BatchSize, len(items))
+		batch := items[i:end]
+
+		if err := refreshBatch(ctx, db, cfg, runID, batch, result); err != nil {
+			slog.Error("embedding refresh batch failed", "batch_start", i, "error", err)
+			if err := markRefreshFailed(ctx, db, batch); err != nil {
+				slog.Error("mark refresh failed", "error", err)
+			}
+			continue
+		}
+
+		if err := dequeueRefreshed(ctx, db, batch); err != nil {
+			return fmt.Errorf("dequeue refreshed items: %w", err)
+		}
+		result.Refreshed += len(batch)
+	}
+
+	slog.Info("refreshed changed item embeddings", "run_id", runID, "items", result.Refreshed)
+	return nil
+}
+
+// refreshBatch re-embeds a batch of changed items and re-evaluates the
+// candidates of the unlinked ones, replacing their previous AI candidates
+func refreshBatch(ctx context.Context, db *pgxpool.Pool, cfg AIMatcherConfig, runID string, batch []changedItem, result *AIMatchResult) error {
+	var linked, unlinked []RetailerItem
+	var unlinkedIDs []string
+	for _, item := range batch {
+		if item.linked {
+			linked = append(linked, item.RetailerItem)
+		} else {
+			unlinked = append(unlinked, item.RetailerItem)
+			unlinkedIDs = append(unlinkedIDs, item.ID)
+		}
+	}
+
+	if len(linked) > 0 {
+		if _, _, _, err := embedItems(ctx, db, cfg, linked, result); err != nil {
+			return err
+		}
+	}
+
+	if len(unlinked) > 0 {
+		if _, err := db.Exec(ctx, `
+			DELETE FROM product_match_candidates
+			WHERE retailer_item_id = ANY($1) AND match_type = 'ai'
+		`, unlinkedIDs); err != nil {
+			return fmt.Errorf("delete stale candidates: %w", err)
+		}
+		if err := processAIBatch(ctx, db, cfg, runID, unlinked, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getChangedItems returns queued items, those failing least often and longest
+// queued first
+func getChangedItems(ctx context.Context, db *pgxpool.Pool, limit int) ([]changedItem, error) {
+	rows, err := db.Query(ctx, `
+		SELECT
+			ri.id,
+			ri.name,
+			ri.name_i18n,
+			COALESCE(ri.brand, ''),
+			COALESCE(ri.unit, ''),
+			COALESCE(ri.unit_quantity, ''),
+			COALESCE(ri.category, ''),
+			COALESCE(ri.image_url, ''),
+			ri.chain_slug,
+			EXISTS (SELECT 1 FROM product_links pl WHERE pl.retailer_item_id = ri.id),
+			q.enqueued_at
+		FROM embedding_refresh_queue q
+		JOIN retailer_items ri ON ri.id = q.retailer_item_id
+		ORDER BY q.attempts, q.enqueued_at
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []changedItem
+	for rows.Next() {
+		var item changedItem
+		if err := rows.Scan(
+			&item.ID,
+			&item.Name,
+			&item.NameI18n,
+			&item.Brand,
+			&item.Unit,
+			&item.UnitQuantity,
+			&item.Category,
+			&item.ImageURL,
+			&item.ChainSlug,
+			&item.linked,
+			&item.enqueuedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// dequeueRefreshed removes refreshed items from the queue, unless they were
+// changed again since they were read
+func dequeueRefreshed(ctx context.Context, db *pgxpool.Pool, batch []changedItem) error {
+	enqueuedAt := make([]time.Time, len(batch))
+	for i, item := range batch {
+		enqueuedAt[i] = item.enqueuedAt
+	}
+
+	_, err := db.Exec(ctx, `
+		DELETE FROM embedding_refresh_queue q
+		USING unnest($1::text[], $2::timestamptz[]) AS refreshed(id, enqueued_at)
+		WHERE q.retailer_item_id = refreshed.id
+		  AND q.enqueued_at = refreshed.enqueued_at
+	`, changedItemIDs(batch), enqueuedAt)
+	return err
+}
+
+// markRefreshFailed counts a failed attempt, so failing items do not hold
+// back the rest of the queue
+func markRefreshFailed(ctx context.Context, db *pgxpool.Pool, batch []changedItem) error {
+	_, err := db.Exec(ctx, `
+		UPDATE embedding_refresh_queue
+		SET attempts = attempts + 1, last_error_at = now()
+		WHERE retailer_item_id = ANY($1)
+	`, changedItemIDs(batch))
+	return err
+}
+
+func changedItemIDs(batch []changedItem) []string {
+	ids := make([]string, len(batch))
+	for i, item := range batch {
+		ids[i] = item.ID
+	}
+	return ids
+}
@@ -0,0 +1,123 @@
+package matching
+
+import (
+	"context"
+	"slices"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Rejection memory settings: a product rejected by reviewers for items named
+// like the one being matched loses similarity per distinct reviewer, up to a
+// cap, so it needs a clearly better embedding match to be suggested again
+const (
+	similarRejectionThreshold = 0.6 // pg_trgm name similarity of "similar" items
+	maxRejectionPenalty       = 0.15
+)
+
+// rejectionMemory is what reviewers decided about an item's candidate products
+type rejectionMemory struct {
+	// rejected holds the products rejected for the item itself
+	rejected map[string]bool
+	// reviewers counts, per product, the distinct reviewers who rejected it
+	// for similar items
+	reviewers map[string]int
+}
+
+// getRejectionMemory loads the rejections of productIDs for an item and for
+// items with a similar name, in one query
+func getRejectionMemory(ctx context.Context, db *pgxpool.Pool, item RetailerItem, productIDs []string) (rejectionMemory, error) {
+	memory := rejectionMemory{rejected: map[string]bool{}, reviewers: map[string]int{}}
+	if len(productIDs) == 0 {
+		return memory, nil
+	}
+
+	// Rejections without a reviewer count once per item
+	rows, err := db.Query(ctx, `
+		SELECT r.rejected_product_id,
+		       bool_or(r.retailer_item_id = $1),
+		       COUNT(DISTINCT COALESCE(r.rejected_by, r.retailer_item_id))
+		           FILTER (WHERE r.retailer_item_id <> $1)
+		FROM product_match_rejections r
+		JOIN retailer_items ri ON ri.id = r.retailer_item_id
+		WHERE r.rejected_product_id = ANY($2)
+		  AND (r.retailer_item_id = $1 OR similarity(lower(ri.name), lower($3)) >= $4)
+		GROUP BY r.rejected_product_id
+	`, item.ID, productIDs, item.Name, similarRejectionThreshold)
+	if err != nil {
+		return memory, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID string
+		var rejected bool
+		var reviewers int
+		if err := rows.Scan(&productID, &rejected, &reviewers); err != nil {
+			return memory, err
+		}
+		if rejected {
+			memory.rejected[productID] = true
+		}
+		if reviewers > 0 {
+			memory.reviewers[productID] = reviewers
+		}
+	}
+
+	return memory, rows.Err()
+}
+
+// withoutRejected returns productIDs minus the products rejected for the item
+func (m rejectionMemory) withoutRejected(productIDs []string) []string {
+	if len(m.rejected) == 0 {
+		return productIDs
+	}
+	kept := make([]string, 0, len(productIDs))
+	for _, id := range productIDs {
+		if !m.rejected[id] {
+			kept = append(kept, id)
+		}
+	}
+	return kept
+}
+
+// penalizeRejected lowers the similarity of candidates rejected for similar
+// items by penalty per reviewer, capped at maxRejectionPenalty, and re-sorts
+// them by similarity descending
+func penalizeRejected(candidates []Candidate, reviewers map[string]int, penalty float32) []Candidate {
+	if len(reviewers) == 0 || penalty <= 0 {
+		return candidates
+	}
+	for i := range candidates {
+		if n := reviewers[candidates[i].ProductID]; n > 0 {
+			candidates[i].Similarity -= min(penalty*float32(n), maxRejectionPenalty)
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b Candidate) int {
+		switch {
+		case a.Similarity > b.Similarity:
+			return -1
+		case a.Similarity < b.Similarity:
+			return 1
+		}
+		return 0
+	})
+	return candidates
+}
+
+// deleteRejectedCandidates drops stored candidates that were rejected for
+// their item since they were generated
+func deleteRejectedCandidates(ctx context.Context, db *pgxpool.Pool, itemID string, rejected map[string]bool) error {
+	if len(rejected) == 0 {
+		return nil
+	}
+	productIDs := make([]string, 0, len(rejected))
+	for id := range rejected {
+		productIDs = append(productIDs, id)
+	}
+	_, err := db.Exec(ctx, `
+		DELETE FROM product_match_candidates
+		WHERE retailer_item_id = $1 AND candidate_product_id = ANY($2)
+	`, itemID, productIDs)
+	return err
+}
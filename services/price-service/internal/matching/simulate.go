@@ -0,0 +1,134 @@
+package matching
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DecisionNotEmbedded is the simulated decision for items without a cached
+// embedding of the simulated model, which a real run would embed first
+const DecisionNotEmbedded = "not_embedded"
+
+// SimulatedMatch is what AI matching would do with an item
+type SimulatedMatch struct {
+	Item            RetailerItem
+	LinkedProductID *string // product the item is linked to now, if any
+	Decision        string
+	Reason          string // queue reason of review decisions
+	Candidates      []Candidate
+}
+
+// SimulateAIMatching scores items as AI matching would under cfg, from
+// cached embeddings of modelVersion, without generating embeddings or
+// writing candidates, links or queue entries. cfg.Provider is not used.
+func SimulateAIMatching(ctx context.Context, db *pgxpool.Pool, cfg AIMatcherConfig, modelVersion string, items []SimulatedMatch) error {
+	texts := make([]string, len(items))
+	hashes := make([]string, len(items))
+	itemIDs := make([]string, len(items))
+	for i, sim := range items {
+		texts[i] = NormalizeForEmbedding(FeatureName(sim.Item.Name, sim.Item.NameI18n), sim.Item.Brand, sim.Item.Category, sim.Item.Unit)
+		hashes[i] = hashText(texts[i])
+		itemIDs[i] = sim.Item.ID
+	}
+
+	embeddings, err := getCachedEmbeddings(ctx, db, itemIDs, hashes, modelVersion)
+	if err != nil {
+		return fmt.Errorf("get cached embeddings: %w", err)
+	}
+
+	for i := range items {
+		sim := &items[i]
+		if embeddings[i] == nil {
+			sim.Decision = DecisionNotEmbedded
+			continue
+		}
+
+		candidates, _, err := scoreCandidates(ctx, db, cfg, modelVersion, sim.Item, texts[i], embeddings[i])
+		if err != nil {
+			return fmt.Errorf("score candidates of %s: %w", sim.Item.ID, err)
+		}
+		sim.Candidates = candidates
+		sim.Decision, sim.Reason = decideAIMatch(sim.Item, candidates, cfg)
+	}
+
+	return nil
+}
+
+// LatestEmbeddingModel returns the model version most products are embedded
+// with, empty if no product is
+func LatestEmbeddingModel(ctx context.Context, db *pgxpool.Pool) (string, error) {
+	var model string
+	err := db.QueryRow(ctx, `
+		SELECT COALESCE((
+			SELECT model_version
+			FROM product_embeddings
+			GROUP BY model_version
+			ORDER BY COUNT(*) DESC, MAX(created_at) DESC
+			LIMIT 1
+		), '')
+	`).Scan(&model)
+	return model, err
+}
+
+// GetSimulationItems loads items to simulate: those with the given IDs, or
+// else a random sample of limit items AI matching would pick up next, i.e.
+// unlinked and not pending review, with a cached embedding of modelVersion
+func GetSimulationItems(ctx context.Context, db *pgxpool.Pool, itemIDs []string, modelVersion string, limit int) ([]SimulatedMatch, error) {
+	rows, err := db.Query(ctx, `
+		SELECT
+			ri.id,
+			ri.name,
+			ri.name_i18n,
+			COALESCE(ri.brand, ''),
+			COALESCE(ri.unit, ''),
+			COALESCE(ri.unit_quantity, ''),
+			COALESCE(ri.category, ''),
+			COALESCE(ri.image_url, ''),
+			ri.chain_slug,
+			pl.product_id
+		FROM retailer_items ri
+		LEFT JOIN product_links pl ON pl.retailer_item_id = ri.id
+		WHERE CASE
+			WHEN cardinality($1::text[]) > 0 THEN ri.id = ANY($1)
+			ELSE pl.retailer_item_id IS NULL
+			 AND NOT EXISTS (
+				SELECT 1 FROM product_match_queue pmq
+				WHERE pmq.retailer_item_id = ri.id AND pmq.status = 'pending'
+			 )
+			 AND EXISTS (
+				SELECT 1 FROM retailer_item_embeddings e
+				WHERE e.retailer_item_id = ri.id AND e.model_version = $2
+			 )
+		END
+		ORDER BY CASE WHEN cardinality($1::text[]) > 0 THEN ri.id END, random()
+		LIMIT $3
+	`, itemIDs, modelVersion, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []SimulatedMatch
+	for rows.Next() {
+		var sim SimulatedMatch
+		if err := rows.Scan(
+			&sim.Item.ID,
+			&sim.Item.Name,
+			&sim.Item.NameI18n,
+			&sim.Item.Brand,
+			&sim.Item.Unit,
+			&sim.Item.UnitQuantity,
+			&sim.Item.Category,
+			&sim.Item.ImageURL,
+			&sim.Item.ChainSlug,
+			&sim.LinkedProductID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, sim)
+	}
+
+	return items, rows.Err()
+}
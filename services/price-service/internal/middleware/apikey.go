@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kosarica/price-service/internal/database"
+	"golang.org/x/time/rate"
+)
+
+// APIKeyHeader carries the key of a public API request. An
+// "Authorization: Bearer <key>" header is accepted too.
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyContextKey is the gin context key holding the authenticated key's ID
+const APIKeyContextKey = "apiKeyID"
+
+// apiKeyCacheTTL is how long a looked-up key is trusted before it is looked
+// up again, so a revoked key stops working within this time
+const apiKeyCacheTTL = time.Minute
+
+// apiKeyMissTTL is how long an unknown key is rejected without a lookup, so
+// a client repeating a bogus key does not reach the database
+const apiKeyMissTTL = 10 * time.Second
+
+// maxAPIKeyMisses bounds the number of unknown keys remembered at once
+const maxAPIKeyMisses = 10000
+
+// apiKeyLookupLimit bounds how often a client IP may have keys that are not
+// cached looked up, so made-up keys cannot load the database either
+var apiKeyLookupLimit = RateLimiterConfig{RequestsPerSecond: 1, BurstSize: 10}
+
+// APIKeyLookup returns the unrevoked API key with the given hash, nil when
+// there is none
+type APIKeyLookup func(ctx context.Context, keyHash string) (*database.APIKey, error)
+
+// apiKeyEntry is a cached lookup of a key with its rate limiter
+type apiKeyEntry struct {
+	key     *database.APIKey
+	limiter *rate.Limiter
+	expires time.Time
+}
+
+// APIKeyAuth authenticates public API requests by a per-client key, looked
+// up by its hash with lookup and limited to its own request rate: the key's
+// rate_limit, else defaults. Lookups are cached for a minute per key, so
+// lookup runs about once a minute for a busy key and a key's last use is
+// recorded to the minute. Unknown keys are remembered for apiKeyMissTTL, and
+// lookups are limited per client IP.
+func APIKeyAuth(lookup APIKeyLookup, defaults RateLimiterConfig) gin.HandlerFunc {
+	var mu sync.Mutex
+	entries := make(map[string]*apiKeyEntry)
+	misses := make(map[string]time.Time)
+
+	lookupLimiter := NewIPRateLimiter(apiKeyLookupLimit)
+	go func() {
+		ticker := time.NewTicker(5 * time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			lookupLimiter.CleanupOldLimiters()
+		}
+	}()
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(APIKeyHeader)
+		if key == "" {
+			key, _ = strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		}
+		if key == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing API key"})
+			return
+		}
+		keyHash := database.HashAPIKey(key)
+
+		mu.Lock()
+		entry := entries[keyHash]
+		fresh := entry != nil && time.Now().Before(entry.expires)
+		missed := time.Now().Before(misses[keyHash])
+		mu.Unlock()
+
+		if missed {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		if !fresh {
+			if !lookupLimiter.GetLimiter(c.ClientIP()).Allow() {
+				c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded"})
+				return
+			}
+			apiKey, err := lookup(c.Request.Context(), keyHash)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to verify API key"})
+				return
+			}
+
+			mu.Lock()
+			if apiKey == nil {
+				delete(entries, keyHash)
+				rememberMiss(misses, keyHash)
+				mu.Unlock()
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+				return
+			}
+			// A key looked up again keeps its limiter, so its spent requests
+			// still count
+			limiter := newAPIKeyLimiter(apiKey, defaults)
+			if previous := entries[keyHash]; previous != nil && sameRateLimit(previous.key, apiKey) {
+				limiter = previous.limiter
+			}
+			entry = &apiKeyEntry{key: apiKey, limiter: limiter, expires: time.Now().Add(apiKeyCacheTTL)}
+			entries[keyHash] = entry
+			mu.Unlock()
+		}
+
+		if !entry.limiter.Allow() {
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded"})
+			return
+		}
+
+		c.Set(APIKeyContextKey, entry.key.ID)
+		c.Next()
+	}
+}
+
+// rememberMiss records an unknown key until apiKeyMissTTL from now, first
+// dropping expired misses, or all of them, when maxAPIKeyMisses are held
+func rememberMiss(misses map[string]time.Time, keyHash string) {
+	now := time.Now()
+	if len(misses) >= maxAPIKeyMisses {
+		for hash, until := range misses {
+			if !now.Before(until) {
+				delete(misses, hash)
+			}
+		}
+		if len(misses) >= maxAPIKeyMisses {
+			clear(misses)
+		}
+	}
+	misses[keyHash] = now.Add(apiKeyMissTTL)
+}
+
+// sameRateLimit reports whether two lookups of a key have the same rate limit
+func sameRateLimit(a, b *database.APIKey) bool {
+	if a.RateLimit == nil || b.RateLimit == nil {
+		return a.RateLimit == b.RateLimit
+	}
+	return *a.RateLimit == *b.RateLimit
+}
+
+// newAPIKeyLimiter returns the rate limiter of a key: its own rate with a
+// burst of twice that, or the defaults
+func newAPIKeyLimiter(apiKey *database.APIKey, defaults RateLimiterConfig) *rate.Limiter {
+	if apiKey.RateLimit == nil || *apiKey.RateLimit <= 0 {
+		return rate.NewLimiter(rate.Limit(defaults.RequestsPerSecond), defaults.BurstSize)
+	}
+	burst := int(*apiKey.RateLimit * 2)
+	if burst < 1 {
+		burst = 1
+	}
+	return rate.NewLimiter(rate.Limit(*apiKey.RateLimit), burst)
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	lookups := 0
+	one := 1.0
+	lookup := func(ctx context.Context, keyHash string) (*database.APIKey, error) {
+		lookups++
+		if keyHash == database.HashAPIKey("kp_valid") {
+			return &database.APIKey{ID: "key_1", RateLimit: &one}, nil
+		}
+		return nil, nil
+	}
+
+	router := gin.New()
+	router.Use(APIKeyAuth(lookup, DefaultRateLimiterConfig()))
+	router.GET("/api/v1/items/search", func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString(APIKeyContextKey))
+	})
+
+	request := func(header, value string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/v1/items/search", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, request("", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, request(APIKeyHeader, "kp_unknown").Code)
+
+	w := request(APIKeyHeader, "kp_valid")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "key_1", w.Body.String())
+	assert.Equal(t, http.StatusOK, request("Authorization", "Bearer kp_valid").Code)
+
+	// A rate of 1/s allows a burst of 2, then the key is limited
+	assert.Equal(t, http.StatusTooManyRequests, request(APIKeyHeader, "kp_valid").Code)
+	assert.Equal(t, 2, lookups, "a valid key is looked up once while cached")
+
+	// An unknown key is remembered, so repeating it is no lookup
+	assert.Equal(t, http.StatusUnauthorized, request(APIKeyHeader, "kp_unknown").Code)
+	assert.Equal(t, 2, lookups, "an unknown key is remembered")
+}
+
+func TestAPIKeyAuthLimitsLookupsPerIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	lookups := 0
+	lookup := func(ctx context.Context, keyHash string) (*database.APIKey, error) {
+		lookups++
+		return nil, nil
+	}
+
+	router := gin.New()
+	router.Use(APIKeyAuth(lookup, DefaultRateLimiterConfig()))
+	router.GET("/api/v1/items/search", func(c *gin.Context) {})
+
+	request := func(remoteAddr, key string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/v1/items/search", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set(APIKeyHeader, key)
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Made-up keys are looked up up to the burst, then refused unlooked
+	for i := 0; i < apiKeyLookupLimit.BurstSize; i++ {
+		assert.Equal(t, http.StatusUnauthorized, request("192.0.2.1:1234", fmt.Sprintf("kp_guess_%d", i)))
+	}
+	assert.Equal(t, http.StatusTooManyRequests, request("192.0.2.1:1234", "kp_guess_next"))
+	assert.Equal(t, apiKeyLookupLimit.BurstSize, lookups)
+
+	// Other clients are not held up
+	assert.Equal(t, http.StatusUnauthorized, request("192.0.2.2:1234", "kp_guess_next"))
+	assert.Equal(t, apiKeyLookupLimit.BurstSize+1, lookups)
+}
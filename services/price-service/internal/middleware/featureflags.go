@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kosarica/price-service/internal/featureflags"
+)
+
+// FeatureFlagsMiddleware attaches per-request feature flag overrides from the
+// X-Feature-Flags header to the request context
+func FeatureFlagsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if header := c.GetHeader(featureflags.HeaderName); header != "" {
+			ctx := featureflags.WithOverrides(c.Request.Context(), featureflags.ParseOverrides(header))
+			c.Request = c.Request.WithContext(ctx)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kosarica/price-service/internal/lanes"
+)
+
+// LaneMiddleware tags the request context with a priority lane and holds a
+// slot in that lane for the duration of the request. Requests whose client
+// goes away while waiting are answered with 503.
+func LaneMiddleware(lane lanes.Lane) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := lanes.WithLane(c.Request.Context(), lane)
+		c.Request = c.Request.WithContext(ctx)
+
+		release, err := lanes.Acquire(ctx)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Request cancelled while waiting for capacity"})
+			return
+		}
+		defer release()
+
+		c.Next()
+	}
+}
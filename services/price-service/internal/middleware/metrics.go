@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "http_request_duration_seconds",
+	Help:    "Duration of HTTP requests by route, method and status",
+	Buckets: []float64{.01, .025, .05, .1, .3, .5, 1, 2.5, 5, 10},
+}, []string{"route", "method", "status"})
+
+// RequestObserver is told about every finished request
+type RequestObserver interface {
+	ObserveRequest(route, method string, status int, latency time.Duration)
+}
+
+// MetricsMiddleware records the duration of every request by route pattern,
+// method and status, and passes it on to the observers. Requests matching no
+// route are recorded as "unmatched".
+func MetricsMiddleware(observers ...RequestObserver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		latency := time.Since(start)
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := c.Writer.Status()
+		requestDuration.WithLabelValues(route, c.Request.Method, strconv.Itoa(status)).Observe(latency.Seconds())
+
+		for _, o := range observers {
+			o.ObserveRequest(route, c.Request.Method, status, latency)
+		}
+	}
+}
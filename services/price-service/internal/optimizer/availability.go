@@ -0,0 +1,35 @@
+package optimizer
+
+import "time"
+
+// StoreAvailability is which items of a basket one store has and at what
+// price, for checking a known store without comparing stores.
+type StoreAvailability struct {
+	StoreID      string
+	Items        []*ItemPriceInfo // Available items, priced as the optimizers price them
+	MissingItems []*BasketItem    // Items the store does not price or carry
+	Total        int64            // Sum of the available items' line totals
+}
+
+// CheckAvailability prices a basket at one store of a chain at the given
+// time. Missing items are listed without penalties, since nothing is ranked.
+func CheckAvailability(source PriceSource, chainSlug, storeID string, items []*BasketItem, at time.Time) *StoreAvailability {
+	result := &StoreAvailability{
+		StoreID:      storeID,
+		Items:        make([]*ItemPriceInfo, 0, len(items)),
+		MissingItems: make([]*BasketItem, 0),
+	}
+
+	for _, item := range items {
+		price, ok := source.GetPrice(chainSlug, storeID, item.ItemID)
+		if !ok {
+			result.MissingItems = append(result.MissingItems, item)
+			continue
+		}
+
+		itemInfo := newItemPriceInfo(item, price, at)
+		result.Items = append(result.Items, itemInfo)
+		result.Total += itemInfo.LineTotal
+	}
+	return result
+}
@@ -0,0 +1,45 @@
+package optimizer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckAvailability(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	ended := now.Add(-time.Hour)
+
+	source := newMockPriceSource()
+	source.prices["chain"] = map[string]map[string]CachedPrice{
+		"store-1": {
+			"item-a": {Price: 200, DiscountPrice: 150, HasDiscount: true},
+			"item-b": {Price: 500, DiscountPrice: 400, HasDiscount: true, DiscountEnd: &ended},
+		},
+		"store-2": {
+			"item-c": {Price: 100, DiscountPrice: 100},
+		},
+	}
+
+	items := []*BasketItem{
+		{ItemID: "item-a", Name: "A", Quantity: 2},
+		{ItemID: "item-b", Name: "B", Quantity: 1},
+		{ItemID: "item-c", Name: "C", Quantity: 3},
+	}
+	result := CheckAvailability(source, "chain", "store-1", items, now)
+
+	require.Len(t, result.Items, 2)
+	assert.Equal(t, "store-1", result.StoreID)
+	discountPrice := int64(150)
+	assert.Equal(t, &ItemPriceInfo{
+		ItemID: "item-a", ItemName: "A", Quantity: 2,
+		BasePrice: 200, EffectivePrice: 150, HasDiscount: true, DiscountPrice: &discountPrice,
+		LineTotal: 300,
+	}, result.Items[0])
+	assert.False(t, result.Items[1].HasDiscount, "the discount has ended")
+	assert.EqualValues(t, 500, result.Items[1].LineTotal)
+	assert.EqualValues(t, 800, result.Total)
+	assert.Equal(t, []*BasketItem{items[2]}, result.MissingItems, "priced only at another store")
+}
@@ -0,0 +1,202 @@
+package optimizer
+
+import "math/bits"
+
+// bloomFilter is a fixed-size Bloom filter over 64-bit keys. It never reports
+// a false negative, so a miss proves the key was not added.
+type bloomFilter struct {
+	bits   []uint64
+	hashes int
+}
+
+// newBloomFilter sizes a filter for n keys at bitsPerKey bits each, with the
+// number of hash functions that minimizes false positives for that size.
+func newBloomFilter(n, bitsPerKey int) *bloomFilter {
+	words := (max(n, 1)*bitsPerKey + 63) / 64
+	hashes := max(bitsPerKey*69/100, 1) // bitsPerKey * ln 2
+	return &bloomFilter{bits: make([]uint64, words), hashes: hashes}
+}
+
+// add inserts a key.
+func (f *bloomFilter) add(key uint64) {
+	h1, h2, m := bloomHashes(key, len(f.bits))
+	for i := 0; i < f.hashes; i++ {
+		bit := (h1 + uint64(i)*h2) % m
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// mayContain reports whether key may have been added.
+func (f *bloomFilter) mayContain(key uint64) bool {
+	h1, h2, m := bloomHashes(key, len(f.bits))
+	for i := 0; i < f.hashes; i++ {
+		bit := (h1 + uint64(i)*h2) % m
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// sizeBytes returns the size of the bit array.
+func (f *bloomFilter) sizeBytes() int64 {
+	return int64(len(f.bits)) * 8
+}
+
+// bloomHashes derives the two hashes used for double hashing from a single
+// mixed key, and returns the filter size in bits.
+func bloomHashes(key uint64, words int) (h1, h2, m uint64) {
+	h := mix64(key)
+	return h, bits.RotateLeft64(h, 32) | 1, uint64(words) * 64
+}
+
+// mix64 is the splitmix64 finalizer. Item ordinals and category indexes are
+// small consecutive integers, so they need mixing before use as hashes.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// Filter keys. Items and categories share one filter per price group, so
+// category keys are tagged to keep them apart from item ordinals.
+const categoryKeyTag = 1 << 40
+
+func itemFilterKey(ordinal int32) uint64 {
+	return uint64(uint32(ordinal))
+}
+
+func categoryFilterKey(index int32) uint64 {
+	return uint64(uint32(index)) | categoryKeyTag
+}
+
+// buildGroupFilters builds a Bloom filter for each price group holding the
+// ordinals of the items it prices and the categories of those items. A
+// bitsPerKey of 0 disables the filters.
+//
+// Filters are per group rather than per store: stores sharing a group carry
+// the same assortment, and their rare exception prices are checked exactly.
+func (s *ChainCacheSnapshot) buildGroupFilters(bitsPerKey int) {
+	if bitsPerKey <= 0 {
+		return
+	}
+
+	s.categoryIndex = make(map[string]int32)
+	itemCategory := make([]int32, len(s.itemIDs))
+	for i, itemID := range s.itemIDs {
+		itemCategory[i] = -1
+		category, ok := s.itemCategories[itemID]
+		if !ok {
+			continue
+		}
+		index, ok := s.categoryIndex[category]
+		if !ok {
+			index = int32(len(s.categoryIndex))
+			s.categoryIndex[category] = index
+		}
+		itemCategory[i] = index
+	}
+
+	s.groupFilters = make(map[string]*bloomFilter, len(s.groupPrices))
+	seen := make(map[int32]bool)
+	for groupID, table := range s.groupPrices {
+		s.groupFilters[groupID] = newGroupFilter(table, itemCategory, seen, bitsPerKey)
+	}
+}
+
+// newGroupFilter builds the Bloom filter of one group's table. itemCategory
+// holds the category key index of each item ordinal, -1 when uncategorized,
+// and seen is scratch space reused across groups.
+func newGroupFilter(table *groupPriceTable, itemCategory []int32, seen map[int32]bool, bitsPerKey int) *bloomFilter {
+	clear(seen)
+	for _, ordinal := range table.items {
+		if index := itemCategory[ordinal]; index >= 0 {
+			seen[index] = true
+		}
+	}
+
+	filter := newBloomFilter(table.len()+len(seen), bitsPerKey)
+	for _, ordinal := range table.items {
+		filter.add(itemFilterKey(ordinal))
+	}
+	for index := range seen {
+		filter.add(categoryFilterKey(index))
+	}
+	return filter
+}
+
+// BasketFootprint bounds how much of a basket each store of a chain can
+// cover, without pricing the items. It is bound to the snapshot it was built
+// from, so every store is checked against the same data.
+type BasketFootprint struct {
+	snapshot *ChainCacheSnapshot
+	itemIDs  []string
+	keys     []footprintKeys
+}
+
+// footprintKeys are the filter keys of one basket item.
+type footprintKeys struct {
+	indexed     bool // whether any price group prices the item
+	item        uint64
+	categorized bool
+	category    uint64
+}
+
+// newBasketFootprint resolves the basket's filter keys once, so checking a
+// store costs a few bit probes per item. Returns nil when the snapshot has no
+// filters.
+func (s *ChainCacheSnapshot) newBasketFootprint(itemIDs []string) *BasketFootprint {
+	if s.groupFilters == nil {
+		return nil
+	}
+
+	fp := &BasketFootprint{
+		snapshot: s,
+		itemIDs:  itemIDs,
+		keys:     make([]footprintKeys, len(itemIDs)),
+	}
+	for i, itemID := range itemIDs {
+		ordinal, ok := s.itemOrdinal(itemID)
+		if !ok {
+			continue
+		}
+		fp.keys[i] = footprintKeys{indexed: true, item: itemFilterKey(ordinal)}
+		if category, ok := s.itemCategories[itemID]; ok {
+			fp.keys[i].categorized = true
+			fp.keys[i].category = categoryFilterKey(s.categoryIndex[category])
+		}
+	}
+	return fp
+}
+
+// MaxCoverage returns an upper bound on the number of basket items the store
+// prices. It never under-counts; a false positive of the filter only makes
+// the bound looser.
+func (fp *BasketFootprint) MaxCoverage(storeID string) int {
+	exceptions := fp.snapshot.exceptions[storeID]
+	filter := fp.snapshot.groupFilters[fp.snapshot.storeToGroup[storeID]]
+
+	count := 0
+	for i, itemID := range fp.itemIDs {
+		if _, ok := exceptions[itemID]; ok {
+			count++
+			continue
+		}
+		keys := fp.keys[i]
+		if filter == nil || !keys.indexed {
+			continue
+		}
+		// A group without the item's category cannot price the item, and
+		// requiring both probes to pass compounds the false positive rates
+		if keys.categorized && !filter.mayContain(keys.category) {
+			continue
+		}
+		if filter.mayContain(keys.item) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,98 @@
+package optimizer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBloomFilterFalsePositiveRate(t *testing.T) {
+	const n = 10000
+	f := newBloomFilter(n, 10)
+	for i := 0; i < n; i++ {
+		f.add(itemFilterKey(int32(i)))
+	}
+
+	for i := 0; i < n; i++ {
+		require.True(t, f.mayContain(itemFilterKey(int32(i))), "false negative for %d", i)
+	}
+
+	falsePositives := 0
+	for i := n; i < 2*n; i++ {
+		if f.mayContain(itemFilterKey(int32(i))) {
+			falsePositives++
+		}
+	}
+	// About 1% at 10 bits per key
+	assert.Less(t, falsePositives, n/50)
+}
+
+// prefilterSnapshot builds a chain with grocery and drugstore price groups
+// whose assortments do not overlap. There are fewer grocery stores than
+// TopCheapestStores, so candidate selection does not depend on tie order.
+func prefilterSnapshot(bitsPerKey int) *ChainCacheSnapshot {
+	b := newSnapshotBuilder()
+	for st := 0; st < 8; st++ {
+		b.addStore(fmt.Sprintf("grocery-%02d", st), "grp-grocery", nil, nil)
+	}
+	for st := 0; st < 30; st++ {
+		b.addStore(fmt.Sprintf("drogerie-%02d", st), "grp-drogerie", nil, nil)
+	}
+	for i := 0; i < 10; i++ {
+		food, care := fmt.Sprintf("food-%d", i), fmt.Sprintf("care-%d", i)
+		b.addGroupPrice("grp-grocery", food, 100+i, nil)
+		b.addGroupPrice("grp-drogerie", care, 200+i, nil)
+		b.addItemCategory(food, "food")
+		b.addItemCategory(care, "care")
+	}
+	// One drugstore also sells a food item
+	b.addException("drogerie-07", "food-0", 90, nil)
+
+	s := b.build()
+	s.buildGroupFilters(bitsPerKey)
+	return s
+}
+
+func TestBasketFootprintMaxCoverage(t *testing.T) {
+	s := prefilterSnapshot(10)
+	fp := s.newBasketFootprint([]string{"food-0", "food-1", "food-2", "unknown"})
+	require.NotNil(t, fp)
+
+	assert.Equal(t, 3, fp.MaxCoverage("grocery-00"))
+	assert.Equal(t, 0, fp.MaxCoverage("drogerie-00"))
+	assert.Equal(t, 1, fp.MaxCoverage("drogerie-07"), "exception prices count")
+	assert.Equal(t, 0, fp.MaxCoverage("no-such-store"))
+
+	assert.Nil(t, prefilterSnapshot(0).newBasketFootprint([]string{"food-0"}), "disabled filters")
+}
+
+func TestSelectCandidatesPrefilterKeepsCandidates(t *testing.T) {
+	ctx := context.Background()
+	req := &OptimizeRequest{ChainSlug: "test"}
+	for i := 0; i < 5; i++ {
+		req.BasketItems = append(req.BasketItems, &BasketItem{ItemID: fmt.Sprintf("food-%d", i), Quantity: 1})
+	}
+
+	candidateIDs := func(bitsPerKey int) []string {
+		cache := &PriceCache{chains: map[string]*ChainCache{"test": {}}}
+		cache.chains["test"].snapshot.Store(prefilterSnapshot(bitsPerKey))
+		config := DefaultOptimizerConfig()
+		config.CandidateFilterBitsPerKey = bitsPerKey
+
+		var ids []string
+		for _, c := range NewMultiStoreOptimizer(cache, config, nil).selectCandidates(ctx, req) {
+			ids = append(ids, c.storeID)
+		}
+		return ids
+	}
+
+	filtered := candidateIDs(10)
+	assert.Len(t, filtered, 8)
+	assert.ElementsMatch(t, candidateIDs(0), filtered)
+	for _, id := range filtered {
+		assert.Contains(t, id, "grocery")
+	}
+}
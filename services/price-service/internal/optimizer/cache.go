@@ -2,15 +2,20 @@ package optimizer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kosarica/price-service/internal/logging"
+	"github.com/kosarica/price-service/internal/pkg/cuid2"
 	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/errgroup"
 	"golang.org/x/sync/semaphore"
 )
 
@@ -33,6 +38,7 @@ type PriceCache struct {
 
 	// Warmup gate blocks requests until warmup is complete
 	warmupGate *WarmupGate
+	warmup     warmupState
 
 	// Metrics recorder
 	metrics *MetricsRecorder
@@ -40,6 +46,9 @@ type PriceCache struct {
 	// Logger for structured logging
 	logger *zerolog.Logger
 
+	// instanceID tells this cache's invalidations from other replicas'
+	instanceID string
+
 	// Shutdown handling
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -69,10 +78,14 @@ type ChainCache struct {
 // ChainCacheSnapshot is an immutable snapshot of chain's price data.
 // It is built off-lock and swapped atomically to minimize lock contention.
 type ChainCacheSnapshot struct {
-	// groupPrices maps groupID -> itemID -> price
+	// itemIDs is the sorted chain-wide item index. Group price tables refer
+	// to items by their position in it.
+	itemIDs []string
+
+	// groupPrices maps groupID -> columnar item prices
 	// This mirrors the database structure and enables price group deduplication.
 	// If 300 stores share the same "National Price Group", prices are stored ONCE.
-	groupPrices map[string]map[string]CachedPrice
+	groupPrices map[string]*groupPriceTable
 
 	// storeToGroup maps storeID -> current groupID
 	storeToGroup map[string]string
@@ -84,9 +97,44 @@ type ChainCacheSnapshot struct {
 	// storeLocations maps storeID -> geographic coordinates
 	storeLocations map[string]Location
 
-	// itemAveragePrice maps itemID -> chain-wide average price
+	// storeIndex finds the stores nearest to a point, built from
+	// storeLocations
+	storeIndex spatialIndex
+
+	// unstocked maps storeID -> sorted ordinals of items its group prices but
+	// its own assortment lacks. Only stores with an assortment signal have
+	// entries, and most of those carry their whole group.
+	unstocked map[string][]int32
+
+	// itemStats holds chain-wide price statistics, parallel to itemIDs.
 	// Used for penalty calculation when items are missing at stores.
-	itemAveragePrice map[string]int64
+	itemStats []ItemPriceStats
+
+	// itemCategories maps itemID -> category for every categorized item of
+	// the chain, priced or not
+	itemCategories map[string]string
+
+	// categoryAverages maps category -> mean of its items' weighted averages
+	categoryAverages map[string]int64
+
+	// groupFilters maps groupID -> Bloom filter of the group's items and
+	// their categories, used to bound store coverage before evaluation.
+	// Nil when candidate filtering is disabled.
+	groupFilters map[string]*bloomFilter
+
+	// categoryIndex maps category -> its key in the group filters
+	categoryIndex map[string]int32
+
+	// internedStrings is the number of distinct IDs the snapshot retains.
+	// Every key and value above that holds the same ID shares one copy.
+	internedStrings int
+
+	// internedBytes is the total length of those distinct IDs
+	internedBytes int64
+
+	// internSavedBytes is the ID bytes interning kept out of the snapshot,
+	// i.e. what the duplicate copies scanned from the database would have cost
+	internSavedBytes int64
 
 	// estimatedSizeBytes is the approximate memory footprint
 	estimatedSizeBytes int64
@@ -97,7 +145,7 @@ func NewPriceCache(db *pgxpool.Pool, config *OptimizerConfig) *PriceCache {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	metrics := NewMetricsRecorder()
-	logger := log.With().Str("component", "price_cache").Logger()
+	logger := logging.For(logging.Cache)
 
 	pc := &PriceCache{
 		chains:         make(map[string]*ChainCache),
@@ -108,6 +156,7 @@ func NewPriceCache(db *pgxpool.Pool, config *OptimizerConfig) *PriceCache {
 		warmupGate:     NewWarmupGate(&logger),
 		metrics:        metrics,
 		logger:         &logger,
+		instanceID:     cuid2.GeneratePrefixedId("pc", cuid2.PrefixedIdOptions{}),
 		ctx:            ctx,
 		cancel:         cancel,
 	}
@@ -125,6 +174,7 @@ func (c *PriceCache) StartWarmup(ctx context.Context) error {
 	}
 
 	c.logger.Info().Int("chains", len(chains)).Msg("Starting cache warmup")
+	c.warmup.start(len(chains))
 
 	var wg sync.WaitGroup
 	errCh := make(chan error, len(chains))
@@ -148,6 +198,7 @@ func (c *PriceCache) StartWarmup(ctx context.Context) error {
 				c.logger.Error().Err(err).Str("chain", chainSlug).Msg("Failed to warm chain cache")
 				errCh <- fmt.Errorf("chain %s: %w", chainSlug, err)
 			} else {
+				c.warmup.update(func(p *WarmupProgress) { p.ChainsLoaded++ })
 				c.logger.Info().Str("chain", chainSlug).Msg("Warmed chain cache")
 			}
 		}(chain)
@@ -166,7 +217,11 @@ func (c *PriceCache) StartWarmup(ctx context.Context) error {
 		}
 	}
 
-	c.logger.Info().Msg("Cache warmup completed")
+	progress := c.warmup.get()
+	c.logger.Info().
+		Int64("held_requests", progress.Held).
+		Int64("rejected_requests", progress.Rejected).
+		Msg("Cache warmup completed")
 	c.warmupGate.Ready()
 	return nil
 }
@@ -195,7 +250,9 @@ func (c *PriceCache) LoadChain(ctx context.Context, chainSlug string) error {
 		loadCtx, cancel := context.WithTimeout(context.Background(), c.config.CacheLoadTimeout)
 		defer cancel()
 
+		loadStart := time.Now()
 		snapshot, loadErr := c.loadChainSnapshot(loadCtx, chainSlug)
+		c.metrics.RecordCacheLoad(chainSlug, time.Since(loadStart).Seconds(), loadErr == nil)
 		if loadErr != nil {
 			c.circuitBreaker.RecordFailure(loadErr)
 			return nil, loadErr
@@ -232,29 +289,94 @@ func (c *PriceCache) LoadChain(ctx context.Context, chainSlug string) error {
 	return err
 }
 
-// RefreshChain is an alias for LoadChain for clarity.
+// ErrRefreshHeld is returned by RefreshChain while the chain's latest
+// baseline-checked run is suspect
+var ErrRefreshHeld = errors.New("cache refresh held: latest run is suspect")
+
+// RefreshChain reloads a chain like LoadChain, unless a snapshot is already
+// loaded and the chain's latest baseline-checked ingestion run is suspect,
+// in which case the loaded prices are kept until the run is cleared or a
+// later run passes and ErrRefreshHeld is returned.
 func (c *PriceCache) RefreshChain(ctx context.Context, chainSlug string) error {
+	if _, loaded := c.ChainFreshness(chainSlug); loaded && c.db != nil {
+		held, err := c.refreshHeld(ctx, chainSlug)
+		if err != nil {
+			return err
+		}
+		if held {
+			return fmt.Errorf("%w: chain %s", ErrRefreshHeld, chainSlug)
+		}
+	}
 	return c.LoadChain(ctx, chainSlug)
 }
 
+// refreshHeld reports whether the chain's latest baseline-checked run is
+// suspect
+func (c *PriceCache) refreshHeld(ctx context.Context, chainSlug string) (bool, error) {
+	var status string
+	err := c.db.QueryRow(ctx, `
+		SELECT baseline_status FROM ingestion_runs
+		WHERE chain_slug = $1 AND baseline_status IS NOT NULL
+		ORDER BY completed_at DESC NULLS LAST
+		LIMIT 1
+	`, chainSlug).Scan(&status)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check latest run: %w", err)
+	}
+	return status == "suspect", nil
+}
+
 // loadChainSnapshot loads a complete snapshot of chain's price data in a single transaction.
 // This ensures consistency between store->group mappings and group prices.
+//
+// Group prices dominate the load, so they are scanned in partitions of price
+// groups on up to SnapshotScanWorkers extra connections. Each partition
+// transaction imports this transaction's exported snapshot, so every query
+// sees the same data, while this transaction reads stores and exceptions.
 func (c *PriceCache) loadChainSnapshot(ctx context.Context, chainSlug string) (*ChainCacheSnapshot, error) {
 	startTime := time.Now()
 
-	// Use a single transaction for consistent snapshot
-	tx, err := c.db.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	// Use a single repeatable-read transaction for a consistent snapshot
+	tx, err := c.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
-	snapshot := &ChainCacheSnapshot{
-		groupPrices:      make(map[string]map[string]CachedPrice),
-		storeToGroup:     make(map[string]string),
-		exceptions:       make(map[string]map[string]CachedPrice),
-		storeLocations:   make(map[string]Location),
-		itemAveragePrice: make(map[string]int64),
+	builder := newSnapshotBuilder()
+
+	groups, err := queryChainGroups(ctx, tx, chainSlug)
+	if err != nil {
+		return nil, err
+	}
+	partitions := partitionGroups(groups, c.config.SnapshotScanWorkers)
+
+	// Partition scans must finish before the exporting transaction ends
+	scanCtx, cancelScans := context.WithCancel(ctx)
+	scans, scanCtx := errgroup.WithContext(scanCtx)
+	defer func() {
+		cancelScans()
+		_ = scans.Wait()
+	}()
+
+	var shards []*snapshotBuilder
+	if len(partitions) > 1 {
+		var snapshotID string
+		if err := tx.QueryRow(ctx, `SELECT pg_export_snapshot()`).Scan(&snapshotID); err != nil {
+			return nil, fmt.Errorf("failed to export snapshot: %w", err)
+		}
+
+		shards = make([]*snapshotBuilder, len(partitions))
+		for i, partition := range partitions {
+			shard := newSnapshotBuilder()
+			shards[i] = shard
+			scans.Go(func() error {
+				return c.scanGroupPricePartition(scanCtx, snapshotID, partition, shard)
+			})
+		}
 	}
 
 	// Load store->group mappings with locations
@@ -280,71 +402,133 @@ func (c *PriceCache) loadChainSnapshot(ctx context.Context, chainSlug string) (*
 		if err := storeRows.Scan(&storeID, &lat, &lon, &groupID); err != nil {
 			return nil, fmt.Errorf("failed to scan store: %w", err)
 		}
-
-		snapshot.storeToGroup[storeID] = groupID
-
-		// Parse location if available
-		if lat != nil && lon != nil {
-			snapshot.storeLocations[storeID] = Location{
-				Latitude:  *lat,
-				Longitude: *lon,
-			}
-		}
+		builder.addStore(storeID, groupID, lat, lon)
 	}
 
 	if err := storeRows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating stores: %w", err)
 	}
 
-	// Load group prices for all groups in this chain
-	groupPriceRows, err := tx.Query(ctx, `
-		SELECT gp.price_group_id, gp.retailer_item_id,
-		       gp.price, gp.discount_price
+	// Aggregate item price statistics in the database rather than
+	// accumulating every group price row here. The weighted average counts
+	// each group once per active member store, the same mappings loaded
+	// above; items priced only in groups without stores fall back to the
+	// simple average.
+	statsRows, err := tx.Query(ctx, `
+		WITH members AS (
+			SELECT sgh.price_group_id, COUNT(*) AS stores
+			FROM store_group_history sgh
+			JOIN stores s ON s.id = sgh.store_id
+			WHERE s.chain_slug = $1
+			  AND s.status = 'active'
+			  AND sgh.valid_to IS NULL
+			GROUP BY sgh.price_group_id
+		)
+		SELECT gp.retailer_item_id,
+		       SUM(gp.price) / COUNT(*),
+		       COALESCE(SUM(gp.price::bigint * m.stores) / NULLIF(SUM(m.stores), 0),
+		                SUM(gp.price) / COUNT(*)),
+		       ROUND(percentile_cont(0.5) WITHIN GROUP (ORDER BY gp.price))::bigint,
+		       MIN(gp.price),
+		       MAX(gp.price)
 		FROM group_prices gp
 		JOIN price_groups pg ON pg.id = gp.price_group_id
+		LEFT JOIN members m ON m.price_group_id = gp.price_group_id
 		WHERE pg.chain_slug = $1
+		GROUP BY gp.retailer_item_id
 	`, chainSlug)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query group prices: %w", err)
+		return nil, fmt.Errorf("failed to query item price stats: %w", err)
 	}
-	defer groupPriceRows.Close()
-
-	// Track prices for average calculation
-	itemPrices := make(map[string][]int64)
+	defer statsRows.Close()
 
-	for groupPriceRows.Next() {
-		var groupID, itemID string
-		var price int
-		var discountPrice *int
-		if err := groupPriceRows.Scan(&groupID, &itemID, &price, &discountPrice); err != nil {
-			return nil, fmt.Errorf("failed to scan group price: %w", err)
+	for statsRows.Next() {
+		var itemID string
+		var stats ItemPriceStats
+		if err := statsRows.Scan(&itemID, &stats.Average, &stats.WeightedAverage, &stats.Median, &stats.Min, &stats.Max); err != nil {
+			return nil, fmt.Errorf("failed to scan item price stats: %w", err)
 		}
+		builder.addItemStats(itemID, stats)
+	}
+
+	if err := statsRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating item price stats: %w", err)
+	}
 
-		// Initialize group map if needed
-		if snapshot.groupPrices[groupID] == nil {
-			snapshot.groupPrices[groupID] = make(map[string]CachedPrice)
+	// Load item categories for category-based penalties
+	categoryRows, err := tx.Query(ctx, `
+		SELECT id, category
+		FROM retailer_items
+		WHERE chain_slug = $1
+		  AND category IS NOT NULL
+		  AND category <> ''
+	`, chainSlug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query item categories: %w", err)
+	}
+	defer categoryRows.Close()
+
+	for categoryRows.Next() {
+		var itemID, category string
+		if err := categoryRows.Scan(&itemID, &category); err != nil {
+			return nil, fmt.Errorf("failed to scan item category: %w", err)
 		}
+		builder.addItemCategory(itemID, category)
+	}
+
+	if err := categoryRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating item categories: %w", err)
+	}
 
-		// Build cached price
-		cachedPrice := CachedPrice{
-			Price:       int64(price),
-			IsException: false,
+	// Load group prices for all groups in this chain, unless partitions
+	// are already scanning them
+	if shards == nil {
+		groupPriceRows, err := tx.Query(ctx, `
+			SELECT gp.price_group_id, gp.retailer_item_id,
+			       gp.price, gp.discount_price,
+			       gp.discount_start, gp.discount_end
+			FROM group_prices gp
+			JOIN price_groups pg ON pg.id = gp.price_group_id
+			WHERE pg.chain_slug = $1
+		`, chainSlug)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query group prices: %w", err)
 		}
-		if discountPrice != nil && *discountPrice > 0 && *discountPrice < price {
-			cachedPrice.DiscountPrice = int64(*discountPrice)
-			cachedPrice.HasDiscount = true
-		} else {
-			cachedPrice.DiscountPrice = cachedPrice.Price
+		if err := scanGroupPriceRows(groupPriceRows, builder); err != nil {
+			return nil, err
 		}
+	}
 
-		snapshot.groupPrices[groupID][itemID] = cachedPrice
+	// Load the items of stores with an assortment signal that their group
+	// prices but their assortment lacks
+	unstockedRows, err := tx.Query(ctx, `
+		SELECT s.id, gp.retailer_item_id
+		FROM stores s
+		JOIN store_group_history sgh ON sgh.store_id = s.id AND sgh.valid_to IS NULL
+		JOIN group_prices gp ON gp.price_group_id = sgh.price_group_id
+		WHERE s.chain_slug = $1
+		  AND s.status = 'active'
+		  AND EXISTS (SELECT 1 FROM store_item_assortment sa WHERE sa.store_id = s.id)
+		  AND NOT EXISTS (
+		      SELECT 1 FROM store_item_assortment sa
+		      WHERE sa.store_id = s.id AND sa.retailer_item_id = gp.retailer_item_id
+		  )
+	`, chainSlug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query store assortments: %w", err)
+	}
+	defer unstockedRows.Close()
 
-		// Track for average calculation
-		itemPrices[itemID] = append(itemPrices[itemID], cachedPrice.Price)
+	for unstockedRows.Next() {
+		var storeID, itemID string
+		if err := unstockedRows.Scan(&storeID, &itemID); err != nil {
+			return nil, fmt.Errorf("failed to scan store assortment: %w", err)
+		}
+		builder.addUnstocked(storeID, itemID)
 	}
 
-	if err := groupPriceRows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating group prices: %w", err)
+	if err := unstockedRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating store assortments: %w", err)
 	}
 
 	// Load store exceptions
@@ -366,58 +550,127 @@ func (c *PriceCache) loadChainSnapshot(ctx context.Context, chainSlug string) (*
 		if err := exceptionRows.Scan(&storeID, &itemID, &price, &discountPrice); err != nil {
 			return nil, fmt.Errorf("failed to scan exception: %w", err)
 		}
-
-		// Initialize store exception map if needed
-		if snapshot.exceptions[storeID] == nil {
-			snapshot.exceptions[storeID] = make(map[string]CachedPrice)
-		}
-
-		cachedPrice := CachedPrice{
-			Price:       int64(price),
-			IsException: true,
-		}
-		if discountPrice != nil && *discountPrice > 0 && *discountPrice < price {
-			cachedPrice.DiscountPrice = int64(*discountPrice)
-			cachedPrice.HasDiscount = true
-		} else {
-			cachedPrice.DiscountPrice = cachedPrice.Price
-		}
-
-		snapshot.exceptions[storeID][itemID] = cachedPrice
+		builder.addException(storeID, itemID, price, discountPrice)
 	}
 
 	if err := exceptionRows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating exceptions: %w", err)
 	}
 
+	if err := scans.Wait(); err != nil {
+		return nil, err
+	}
+	for _, shard := range shards {
+		builder.mergeGroupPrices(shard)
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	// Compute item average prices
-	for itemID, prices := range itemPrices {
-		var sum int64
-		for _, p := range prices {
-			sum += p
-		}
-		snapshot.itemAveragePrice[itemID] = sum / int64(len(prices))
-	}
+	// Index group prices and item statistics
+	snapshot := builder.build()
+	snapshot.buildGroupFilters(c.config.CandidateFilterBitsPerKey)
 
 	// Estimate memory size
 	snapshot.estimatedSizeBytes = c.estimateSnapshotSize(snapshot)
 
 	duration := time.Since(startTime)
-	log.Info().
+	c.logger.Info().
 		Str("chain", chainSlug).
 		Int("stores", len(snapshot.storeToGroup)).
 		Int("groups", len(snapshot.groupPrices)).
+		Int("scanPartitions", max(len(shards), 1)).
 		Int("exceptions", len(snapshot.exceptions)).
+		Int("internedStrings", snapshot.internedStrings).
+		Int64("internSavedBytes", snapshot.internSavedBytes).
+		Int64("estimatedBytes", snapshot.estimatedSizeBytes).
 		Dur("duration", duration).
 		Msg("Loaded chain cache snapshot")
 
 	return snapshot, nil
 }
 
+// queryChainGroups returns the chain's price groups sorted by ID, with their
+// item counts.
+func queryChainGroups(ctx context.Context, tx pgx.Tx, chainSlug string) ([]groupSize, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT id, item_count
+		FROM price_groups
+		WHERE chain_slug = $1
+		ORDER BY id
+	`, chainSlug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []groupSize
+	for rows.Next() {
+		var g groupSize
+		if err := rows.Scan(&g.id, &g.items); err != nil {
+			return nil, fmt.Errorf("failed to scan price group: %w", err)
+		}
+		groups = append(groups, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating price groups: %w", err)
+	}
+	return groups, nil
+}
+
+// scanGroupPricePartition scans the group prices of one partition of price
+// groups into shard, reading the snapshot exported by the loading transaction.
+func (c *PriceCache) scanGroupPricePartition(ctx context.Context, snapshotID string, groupIDs []string, shard *snapshotBuilder) error {
+	tx, err := c.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return fmt.Errorf("failed to begin partition transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// SET TRANSACTION SNAPSHOT takes no bind parameters
+	if _, err := tx.Exec(ctx, "SET TRANSACTION SNAPSHOT '"+strings.ReplaceAll(snapshotID, "'", "''")+"'"); err != nil {
+		return fmt.Errorf("failed to import snapshot: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT gp.price_group_id, gp.retailer_item_id,
+		       gp.price, gp.discount_price,
+		       gp.discount_start, gp.discount_end
+		FROM group_prices gp
+		WHERE gp.price_group_id = ANY($1)
+	`, groupIDs)
+	if err != nil {
+		return fmt.Errorf("failed to query group prices: %w", err)
+	}
+	if err := scanGroupPriceRows(rows, shard); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// scanGroupPriceRows feeds group price rows into b and closes them.
+func scanGroupPriceRows(rows pgx.Rows, b *snapshotBuilder) error {
+	defer rows.Close()
+
+	for rows.Next() {
+		var groupID, itemID string
+		var price int
+		var discountPrice *int
+		var discountStart, discountEnd *time.Time
+		if err := rows.Scan(&groupID, &itemID, &price, &discountPrice, &discountStart, &discountEnd); err != nil {
+			return fmt.Errorf("failed to scan group price: %w", err)
+		}
+		b.addGroupPriceWindow(groupID, itemID, price, discountPrice, discountStart, discountEnd)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating group prices: %w", err)
+	}
+	return nil
+}
+
 // GetPrice retrieves the price for a specific item at a store.
 // It checks exceptions first, then resolves via group mapping.
 // Safe for concurrent use and handles nil-maps gracefully.
@@ -454,12 +707,17 @@ func (c *PriceCache) GetPrice(chainSlug string, storeID, itemID string) (CachedP
 		return CachedPrice{}, false
 	}
 
-	price, ok := groupPrices[itemID]
+	ordinal, ok := snapshot.itemOrdinal(itemID)
 	if !ok {
 		return CachedPrice{}, false
 	}
 
-	return price, true
+	// 4. Priced in the group but not carried by the store
+	if _, unstocked := slices.BinarySearch(snapshot.unstocked[storeID], ordinal); unstocked {
+		return CachedPrice{}, false
+	}
+
+	return groupPrices.get(ordinal)
 }
 
 // GetAveragePrice returns the chain-wide average price for an item.
@@ -477,16 +735,85 @@ func (c *PriceCache) GetAveragePrice(chainSlug string, itemID string) int64 {
 		return 0
 	}
 
-	avgPrice, ok := snapshot.itemAveragePrice[itemID]
+	ordinal, ok := snapshot.itemOrdinal(itemID)
 	if !ok {
 		return 0
 	}
 
-	return avgPrice
+	return snapshot.itemStats[ordinal].Average
 }
 
-// GetNearestStores returns stores within maxDistanceKm of the given location.
-func (c *PriceCache) GetNearestStores(chainSlug string, lat, lon, maxDistanceKm float64, limit int) []StoreWithDistance {
+// GetWeightedAveragePrice returns the chain-wide average price for an item,
+// weighting each price group by its number of active stores.
+func (c *PriceCache) GetWeightedAveragePrice(chainSlug string, itemID string) int64 {
+	stats, _ := c.GetItemPriceStats(chainSlug, itemID)
+	return stats.WeightedAverage
+}
+
+// GetItemPriceStats returns the chain-wide price statistics for an item.
+func (c *PriceCache) GetItemPriceStats(chainSlug string, itemID string) (ItemPriceStats, bool) {
+	c.chainsMu.RLock()
+	chainCache, exists := c.chains[chainSlug]
+	c.chainsMu.RUnlock()
+
+	if !exists {
+		return ItemPriceStats{}, false
+	}
+
+	snapshot := c.getSnapshot(chainCache)
+	if snapshot == nil {
+		return ItemPriceStats{}, false
+	}
+
+	ordinal, ok := snapshot.itemOrdinal(itemID)
+	if !ok {
+		return ItemPriceStats{}, false
+	}
+
+	return snapshot.itemStats[ordinal], true
+}
+
+// GetItemCategory returns the item's category.
+func (c *PriceCache) GetItemCategory(chainSlug string, itemID string) (string, bool) {
+	c.chainsMu.RLock()
+	chainCache, exists := c.chains[chainSlug]
+	c.chainsMu.RUnlock()
+
+	if !exists {
+		return "", false
+	}
+
+	snapshot := c.getSnapshot(chainCache)
+	if snapshot == nil {
+		return "", false
+	}
+
+	category, ok := snapshot.itemCategories[itemID]
+	return category, ok
+}
+
+// GetCategoryAveragePrice returns the average price of a category's items.
+func (c *PriceCache) GetCategoryAveragePrice(chainSlug string, category string) int64 {
+	c.chainsMu.RLock()
+	chainCache, exists := c.chains[chainSlug]
+	c.chainsMu.RUnlock()
+
+	if !exists {
+		return 0
+	}
+
+	snapshot := c.getSnapshot(chainCache)
+	if snapshot == nil {
+		return 0
+	}
+
+	return snapshot.categoryAverages[category]
+}
+
+// BasketFootprint returns a footprint for bounding the coverage of the
+// basket's items at each store of the chain, or nil when the chain is not
+// cached or its snapshot has no candidate filters.
+func (c *PriceCache) BasketFootprint(chainSlug string, itemIDs []string) *BasketFootprint {
 	c.chainsMu.RLock()
 	chainCache, exists := c.chains[chainSlug]
 	c.chainsMu.RUnlock()
@@ -499,36 +826,43 @@ func (c *PriceCache) GetNearestStores(chainSlug string, lat, lon, maxDistanceKm
 	if snapshot == nil {
 		return nil
 	}
+	return snapshot.newBasketFootprint(itemIDs)
+}
 
-	// Calculate distances for all stores
-	// TODO: For large number of stores (>1000), use an R-Tree or similar spatial index
-	var candidates []StoreWithDistance
+// GetStoreLocation returns a store's location, or false when the chain is
+// not cached or the store's location is unknown.
+func (c *PriceCache) GetStoreLocation(chainSlug, storeID string) (Location, bool) {
+	c.chainsMu.RLock()
+	chainCache, exists := c.chains[chainSlug]
+	c.chainsMu.RUnlock()
 
-	for storeID, location := range snapshot.storeLocations {
-		dist := HaversineKm(lat, lon, location.Latitude, location.Longitude)
-		if maxDistanceKm > 0 && dist > maxDistanceKm {
-			continue
-		}
-		candidates = append(candidates, StoreWithDistance{
-			StoreID:  storeID,
-			Distance: dist,
-		})
+	if !exists {
+		return Location{}, false
 	}
 
-	// Sort by distance (simple bubble sort for small N or standard sort)
-	// Since we need top K, we could use a heap, but for N=500 stores, sort is fine.
-	// Optimizing: just find top K.
-	// For simplicity in this implementation, we won't sort yet, let the caller or separate util sort.
-	// BUT the interface says "sorted by distance". So we MUST sort.
+	snapshot := c.getSnapshot(chainCache)
+	if snapshot == nil {
+		return Location{}, false
+	}
+	location, ok := snapshot.storeLocations[storeID]
+	return location, ok
+}
+
+// GetNearestStores returns stores within maxDistanceKm of the given location.
+func (c *PriceCache) GetNearestStores(chainSlug string, lat, lon, maxDistanceKm float64, limit int) []StoreWithDistance {
+	c.chainsMu.RLock()
+	chainCache, exists := c.chains[chainSlug]
+	c.chainsMu.RUnlock()
 
-	// Basic selection sort for top K is faster than full sort if K is small
-	if limit > 0 && len(candidates) > limit {
-		SortStoresByDistance(candidates)
-		return candidates[:limit]
+	if !exists {
+		return nil
 	}
 
-	SortStoresByDistance(candidates)
-	return candidates
+	snapshot := c.getSnapshot(chainCache)
+	if snapshot == nil || snapshot.storeIndex == nil {
+		return nil
+	}
+	return snapshot.storeIndex.nearest(lat, lon, maxDistanceKm, limit)
 }
 
 // GetStoreIDs returns all store IDs for a chain.
@@ -554,6 +888,26 @@ func (c *PriceCache) GetStoreIDs(chainSlug string) []string {
 	return storeIDs
 }
 
+// HasStore reports whether a store of a chain is cached, i.e. is active and
+// has a price group.
+func (c *PriceCache) HasStore(chainSlug, storeID string) bool {
+	c.chainsMu.RLock()
+	chainCache, exists := c.chains[chainSlug]
+	c.chainsMu.RUnlock()
+
+	if !exists {
+		return false
+	}
+
+	snapshot := c.getSnapshot(chainCache)
+	if snapshot == nil {
+		return false
+	}
+
+	_, ok := snapshot.storeToGroup[storeID]
+	return ok
+}
+
 // getSnapshot safely gets the current snapshot for a chain cache.
 func (c *PriceCache) getSnapshot(chainCache *ChainCache) *ChainCacheSnapshot {
 	val := chainCache.snapshot.Load()
@@ -564,40 +918,54 @@ func (c *PriceCache) getSnapshot(chainCache *ChainCache) *ChainCacheSnapshot {
 }
 
 // estimateSnapshotSize estimates the memory footprint of a snapshot in bytes.
+// IDs are interned during the build, so their bytes are counted once via
+// internedBytes; each key or value referencing an ID costs only its header.
 func (c *PriceCache) estimateSnapshotSize(s *ChainCacheSnapshot) int64 {
-	size := int64(0)
-
-	// groupPrices: map overhead + entries
-	size += int64(len(s.groupPrices)) * 64 // map overhead
-	for groupID, items := range s.groupPrices {
-		size += int64(len(groupID)) + 64 // groupID + map entry overhead
-		size += int64(len(items)) * 64   // items map overhead
-		for itemID := range items {
-			size += int64(len(itemID)) + 32 // itemID + CachedPrice
-		}
-	}
+	size := s.internedBytes
+
+	// itemIDs: one header per item
+	size += int64(len(s.itemIDs)) * stringHeaderBytes
 
-	// storeToGroup
-	size += int64(len(s.storeToGroup)) * 64
-	for storeID, groupID := range s.storeToGroup {
-		size += int64(len(storeID)+len(groupID)) + 16
+	// groupPrices: map entry + table header + 12 bytes per priced item
+	for _, table := range s.groupPrices {
+		size += stringHeaderBytes + 64 + 3*24
+		size += int64(table.len()) * 12
+		size += int64(len(table.windows)) * (64 + 4 + 16)
 	}
 
+	// storeToGroup: storeID + groupID per entry
+	size += int64(len(s.storeToGroup)) * (64 + 2*stringHeaderBytes)
+
 	// exceptions
 	size += int64(len(s.exceptions)) * 64
-	for storeID, items := range s.exceptions {
-		size += int64(len(storeID)) + 64
-		size += int64(len(items)) * 64
-		for itemID := range items {
-			size += int64(len(itemID)) + 32
-		}
+	for _, items := range s.exceptions {
+		size += stringHeaderBytes + 64
+		size += int64(len(items)) * (64 + stringHeaderBytes + 32)
+	}
+
+	// unstocked: slice header per store + 4 bytes per item
+	for _, items := range s.unstocked {
+		size += 64 + stringHeaderBytes + 24 + int64(len(items))*4
 	}
 
 	// storeLocations
-	size += int64(len(s.storeLocations)) * (64 + 16) // string key + Location struct
+	size += int64(len(s.storeLocations)) * (64 + stringHeaderBytes + 16) // string key + Location struct
+	if s.storeIndex != nil {
+		size += s.storeIndex.sizeBytes()
+	}
+
+	// itemStats: five int64 per item
+	size += int64(len(s.itemStats)) * 40
+
+	// itemCategories and categoryAverages
+	size += int64(len(s.itemCategories)) * (64 + 2*stringHeaderBytes)
+	size += int64(len(s.categoryAverages)) * (64 + stringHeaderBytes + 8)
 
-	// itemAveragePrice
-	size += int64(len(s.itemAveragePrice)) * (64 + 16) // string key + int64
+	// groupFilters and categoryIndex
+	for _, filter := range s.groupFilters {
+		size += 64 + stringHeaderBytes + 8 + 32 + filter.sizeBytes()
+	}
+	size += int64(len(s.categoryIndex)) * (64 + stringHeaderBytes + 4)
 
 	return size
 }
@@ -717,15 +1085,46 @@ func (c *PriceCache) GetFreshness(ctx context.Context) map[string]CacheFreshness
 		}
 
 		result[chainSlug] = CacheFreshness{
-			LoadedAt:    loadedAt.Unix(),
-			IsStale:     time.Since(loadedAt) > c.config.CacheTTL,
-			EstimatedMB: snapshot.estimatedSizeBytes / (1024 * 1024),
+			LoadedAt:         loadedAt.Unix(),
+			IsStale:          time.Since(loadedAt) > c.config.CacheTTL,
+			EstimatedMB:      snapshot.estimatedSizeBytes / (1024 * 1024),
+			InternedStrings:  snapshot.internedStrings,
+			InternSavedBytes: snapshot.internSavedBytes,
 		}
 	}
 
 	return result
 }
 
+// ChainFreshness returns the freshness of one chain's snapshot.
+// Returns false if the chain has no snapshot loaded.
+func (c *PriceCache) ChainFreshness(chainSlug string) (CacheFreshness, bool) {
+	c.chainsMu.RLock()
+	chainCache, ok := c.chains[chainSlug]
+	c.chainsMu.RUnlock()
+	if !ok {
+		return CacheFreshness{IsStale: true}, false
+	}
+
+	snapshot := c.getSnapshot(chainCache)
+	if snapshot == nil {
+		return CacheFreshness{IsStale: true}, false
+	}
+
+	var loadedAt time.Time
+	if v := chainCache.loadedAt.Load(); v != nil {
+		loadedAt = v.(time.Time)
+	}
+
+	return CacheFreshness{
+		LoadedAt:         loadedAt.Unix(),
+		IsStale:          time.Since(loadedAt) > c.config.CacheTTL,
+		EstimatedMB:      snapshot.estimatedSizeBytes / (1024 * 1024),
+		InternedStrings:  snapshot.internedStrings,
+		InternSavedBytes: snapshot.internSavedBytes,
+	}, true
+}
+
 // GetCircuitBreakerState returns the current state of the circuit breaker.
 func (c *PriceCache) GetCircuitBreakerState() CircuitBreakerState {
 	return c.circuitBreaker.State()
@@ -117,11 +117,10 @@ func TestNilMapSafety(t *testing.T) {
 
 	chainCache := &ChainCache{}
 	snapshot := &ChainCacheSnapshot{
-		groupPrices:      make(map[string]map[string]CachedPrice),
-		storeToGroup:     make(map[string]string),
-		exceptions:       make(map[string]map[string]CachedPrice),
-		storeLocations:   make(map[string]Location),
-		itemAveragePrice: make(map[string]int64),
+		groupPrices:    make(map[string]*groupPriceTable),
+		storeToGroup:   make(map[string]string),
+		exceptions:     make(map[string]map[string]CachedPrice),
+		storeLocations: make(map[string]Location),
 	}
 	chainCache.snapshot.Store(snapshot)
 	cache.chains["test"] = chainCache
@@ -522,6 +521,8 @@ func runTestMigrations(ctx context.Context, db *pgxpool.Pool) error {
 		discount_price INTEGER,
 		unit_price INTEGER,
 		anchor_price INTEGER,
+		discount_start TIMESTAMPTZ,
+		discount_end TIMESTAMPTZ,
 		created_at TIMESTAMPTZ DEFAULT NOW(),
 		PRIMARY KEY (price_group_id, retailer_item_id)
 	);
@@ -539,6 +540,13 @@ func runTestMigrations(ctx context.Context, db *pgxpool.Pool) error {
 		PRIMARY KEY (store_id, retailer_item_id)
 	);
 
+	CREATE TABLE IF NOT EXISTS store_item_assortment (
+		store_id TEXT NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+		retailer_item_id TEXT NOT NULL REFERENCES retailer_items(id) ON DELETE CASCADE,
+		last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (store_id, retailer_item_id)
+	);
+
 	-- Create indexes
 	CREATE INDEX IF NOT EXISTS stores_chain_slug_idx ON stores(chain_slug);
 	CREATE INDEX IF NOT EXISTS store_group_history_store_id_idx ON store_group_history(store_id);
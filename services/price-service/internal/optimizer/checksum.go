@@ -0,0 +1,114 @@
+package optimizer
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"time"
+)
+
+// StoreChecksum identifies a store's current price set in the cache.
+type StoreChecksum struct {
+	Checksum  string    // FNV-1a hash of the store's prices, in hex
+	ItemCount int       // Number of items the store prices
+	LoadedAt  time.Time // When the chain's snapshot was loaded
+}
+
+// StoreChecksum returns a checksum of the prices a store has in the chain's
+// snapshot: its group's prices for the items it carries, overridden by its
+// exceptions, with discount windows. Equal price sets hash equally across
+// snapshot reloads. Returns false if the chain is not cached or the store is
+// not in the snapshot.
+func (c *PriceCache) StoreChecksum(chainSlug, storeID string) (StoreChecksum, bool) {
+	c.chainsMu.RLock()
+	chainCache, exists := c.chains[chainSlug]
+	c.chainsMu.RUnlock()
+
+	if !exists {
+		return StoreChecksum{}, false
+	}
+
+	snapshot := c.getSnapshot(chainCache)
+	if snapshot == nil {
+		return StoreChecksum{}, false
+	}
+
+	sum, ok := snapshot.storeChecksum(storeID)
+	if !ok {
+		return StoreChecksum{}, false
+	}
+	if v := chainCache.loadedAt.Load(); v != nil {
+		sum.LoadedAt = v.(time.Time)
+	}
+	return sum, true
+}
+
+// storeChecksum hashes a store's effective prices in item ID order. Only the
+// hashed content matters, not ordinals, which shift when the chain's items
+// change.
+func (s *ChainCacheSnapshot) storeChecksum(storeID string) (StoreChecksum, bool) {
+	groupID, ok := s.storeToGroup[storeID]
+	if !ok {
+		return StoreChecksum{}, false
+	}
+
+	exceptions := s.exceptions[storeID]
+	exceptionIDs := make([]string, 0, len(exceptions))
+	for itemID := range exceptions {
+		exceptionIDs = append(exceptionIDs, itemID)
+	}
+	slices.Sort(exceptionIDs)
+
+	h := fnv.New64a()
+	var buf [8]byte
+	writeEntry := func(itemID string, price, discountPrice int64, window discountWindow) {
+		h.Write([]byte(itemID))
+		h.Write([]byte{0})
+		for _, v := range []int64{price, discountPrice, window.start, window.end} {
+			binary.LittleEndian.PutUint64(buf[:], uint64(v))
+			h.Write(buf[:])
+		}
+	}
+	writeException := func(itemID string) {
+		p := exceptions[itemID]
+		discountPrice := p.Price
+		if p.HasDiscount {
+			discountPrice = p.DiscountPrice
+		}
+		writeEntry(itemID, p.Price, discountPrice, newDiscountWindow(p.DiscountStart, p.DiscountEnd))
+	}
+
+	// Merge the group's items, which are in item ID order as ordinals follow
+	// the sorted item index, with the sorted exceptions
+	count := 0
+	next := 0
+	if table := s.groupPrices[groupID]; table != nil {
+		unstocked := s.unstocked[storeID]
+		for i, ordinal := range table.items {
+			itemID := s.itemIDs[ordinal]
+			for next < len(exceptionIDs) && exceptionIDs[next] < itemID {
+				writeException(exceptionIDs[next])
+				next++
+				count++
+			}
+			if next < len(exceptionIDs) && exceptionIDs[next] == itemID {
+				continue
+			}
+			if _, ok := slices.BinarySearch(unstocked, ordinal); ok {
+				continue
+			}
+			writeEntry(itemID, int64(table.prices[i]), int64(table.discountPrices[i]), table.window(i))
+			count++
+		}
+	}
+	for ; next < len(exceptionIDs); next++ {
+		writeException(exceptionIDs[next])
+		count++
+	}
+
+	return StoreChecksum{
+		Checksum:  fmt.Sprintf("%016x", h.Sum64()),
+		ItemCount: count,
+	}, true
+}
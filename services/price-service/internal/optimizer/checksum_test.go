@@ -0,0 +1,64 @@
+package optimizer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreChecksum(t *testing.T) {
+	price := func(p int) *int { return &p }
+	build := func(milkPrice int, extraItem bool) *ChainCacheSnapshot {
+		b := newSnapshotBuilder()
+		b.addStore("sto-a", "grp-1", nil, nil)
+		b.addStore("sto-b", "grp-1", nil, nil)
+		b.addStore("sto-c", "grp-1", nil, nil)
+		b.addGroupPrice("grp-1", "itm-bread", 150, nil)
+		b.addGroupPrice("grp-1", "itm-milk", milkPrice, price(99))
+		b.addGroupPrice("grp-1", "itm-water", 60, nil)
+		b.addException("sto-b", "itm-milk", 120, nil)
+		b.addException("sto-b", "itm-juice", 200, nil)
+		b.addUnstocked("sto-c", "itm-water")
+		if extraItem {
+			// Shifts the ordinals of every item after it
+			b.addGroupPrice("grp-2", "itm-apple", 80, nil)
+		}
+		return b.build()
+	}
+
+	loadedAt := time.Date(2025, 8, 1, 6, 0, 0, 0, time.UTC)
+	cache := &PriceCache{chains: map[string]*ChainCache{"test": {}}}
+	cache.chains["test"].snapshot.Store(build(110, false))
+	cache.chains["test"].loadedAt.Store(loadedAt)
+
+	a, ok := cache.StoreChecksum("test", "sto-a")
+	require.True(t, ok)
+	assert.Len(t, a.Checksum, 16)
+	assert.Equal(t, 3, a.ItemCount)
+	assert.Equal(t, loadedAt, a.LoadedAt)
+
+	b, ok := cache.StoreChecksum("test", "sto-b")
+	require.True(t, ok)
+	assert.Equal(t, 4, b.ItemCount, "exceptions override and extend the group")
+	assert.NotEqual(t, a.Checksum, b.Checksum)
+
+	c, ok := cache.StoreChecksum("test", "sto-c")
+	require.True(t, ok)
+	assert.Equal(t, 2, c.ItemCount, "unstocked items are left out")
+
+	// The same prices hash equally even when other items shift ordinals
+	reloaded, ok := build(110, true).storeChecksum("sto-a")
+	require.True(t, ok)
+	assert.Equal(t, a.Checksum, reloaded.Checksum)
+
+	changed, ok := build(115, false).storeChecksum("sto-a")
+	require.True(t, ok)
+	assert.NotEqual(t, a.Checksum, changed.Checksum)
+
+	_, ok = cache.StoreChecksum("test", "missing")
+	assert.False(t, ok)
+	_, ok = cache.StoreChecksum("missing", "sto-a")
+	assert.False(t, ok)
+}
@@ -12,14 +12,27 @@ type Config struct {
 
 	// Warmup settings
 	WarmupConcurrency int `mapstructure:"warmup_concurrency" env:"WARMUP_CONCURRENCY" default:"3"`
+	// How long optimize requests for chains not loaded yet wait for warmup; 0 rejects them at once
+	WarmupHoldTimeout time.Duration `mapstructure:"warmup_hold_timeout" env:"WARMUP_HOLD_TIMEOUT" default:"0s"`
+
+	// Connections each chain load scans group prices on
+	SnapshotScanWorkers int `mapstructure:"snapshot_scan_workers" env:"SNAPSHOT_SCAN_WORKERS" default:"4"`
 
 	// Candidate selection for multi-store optimization
 	TopCheapestStores int `mapstructure:"top_cheapest_stores" env:"TOP_CHEAPEST_STORES" default:"10"`
 	TopNearestStores  int `mapstructure:"top_nearest_stores" env:"TOP_NEAREST_STORES" default:"5"`
 	MaxCandidates     int `mapstructure:"max_candidates" env:"MAX_CANDIDATES" default:"20"`
+	// Bloom filter bits per priced item used to skip stores before evaluation; 0 disables
+	CandidateFilterBitsPerKey int `mapstructure:"candidate_filter_bits_per_key" env:"CANDIDATE_FILTER_BITS_PER_KEY" default:"10"`
 
 	// Geographic filtering
 	MaxDistanceKm float64 `mapstructure:"max_distance_km" env:"MAX_DISTANCE_KM" default:"50.0"`
+	// Cost of each km to a store, in minor units, in balanced single-store sorting
+	BalancedDistanceCostPerKm int64 `mapstructure:"balanced_distance_cost_per_km" env:"BALANCED_DISTANCE_COST_PER_KM" default:"200"`
+	// Travel costs weighed against savings in multi-store optimization, in minor
+	// units: each km of the round trip from the location and each extra store
+	TravelCostPerKm int64 `mapstructure:"travel_cost_per_km" env:"TRAVEL_COST_PER_KM" default:"0"`
+	ExtraStoreCost  int64 `mapstructure:"extra_store_cost" env:"EXTRA_STORE_COST" default:"0"`
 
 	// Algorithm settings
 	OptimalTimeoutMs int `mapstructure:"optimal_timeout_ms" env:"OPTIMAL_TIMEOUT_MS" default:"100"`
@@ -31,6 +44,10 @@ type Config struct {
 	// Missing item penalty
 	MissingItemPenaltyMult float64 `mapstructure:"missing_item_penalty_mult" env:"MISSING_ITEM_PENALTY_MULT" default:"2.0"`
 	MissingItemFallback    int64   `mapstructure:"missing_item_fallback" env:"MISSING_ITEM_FALLBACK" default:"10000"`
+	// Default penalty strategy, see PenaltyStrategyNames
+	MissingItemPenaltyStrategy string `mapstructure:"missing_item_penalty_strategy" env:"MISSING_ITEM_PENALTY_STRATEGY" default:"mean"`
+	// Fixed penalty per category for the category_table strategy
+	MissingItemCategoryPenalties map[string]int64 `mapstructure:"missing_item_category_penalties"`
 
 	// Coverage bins (must be descending: full, high, medium)
 	CoverageBins []float64 `mapstructure:"coverage_bins" env:"COVERAGE_BINS" default:"[1.0,0.9,0.8]"`
@@ -42,41 +59,55 @@ type Config struct {
 // Defaults returns the default configuration.
 func Defaults() *Config {
 	return &Config{
-		CacheLoadTimeout:       30 * time.Second,
-		CacheTTL:               1 * time.Hour,
-		CacheRefreshJitter:     5 * time.Minute,
-		WarmupConcurrency:      3,
-		TopCheapestStores:      10,
-		TopNearestStores:       5,
-		MaxCandidates:          20,
-		MaxDistanceKm:          50.0,
-		OptimalTimeoutMs:       100,
-		MaxBasketItems:         100,
-		MinBasketItems:         1,
-		MissingItemPenaltyMult: 2.0,
-		MissingItemFallback:    10000,
-		CoverageBins:           []float64{1.0, 0.9, 0.8},
-		EnableMultiStore:       true,
+		CacheLoadTimeout:           30 * time.Second,
+		CacheTTL:                   1 * time.Hour,
+		CacheRefreshJitter:         5 * time.Minute,
+		WarmupConcurrency:          3,
+		SnapshotScanWorkers:        4,
+		TopCheapestStores:          10,
+		TopNearestStores:           5,
+		MaxCandidates:              20,
+		CandidateFilterBitsPerKey:  10,
+		MaxDistanceKm:              50.0,
+		BalancedDistanceCostPerKm:  200,
+		TravelCostPerKm:            0,
+		ExtraStoreCost:             0,
+		OptimalTimeoutMs:           100,
+		MaxBasketItems:             100,
+		MinBasketItems:             1,
+		MissingItemPenaltyMult:     2.0,
+		MissingItemFallback:        10000,
+		MissingItemPenaltyStrategy: PenaltyStrategyMean,
+		CoverageBins:               []float64{1.0, 0.9, 0.8},
+		EnableMultiStore:           true,
 	}
 }
 
 // ToOptimizerConfig converts Config to OptimizerConfig for use in the optimizer.
 func (c *Config) ToOptimizerConfig() *OptimizerConfig {
 	return &OptimizerConfig{
-		CacheLoadTimeout:       c.CacheLoadTimeout,
-		CacheTTL:               c.CacheTTL,
-		CacheRefreshJitter:     c.CacheRefreshJitter,
-		WarmupConcurrency:      c.WarmupConcurrency,
-		TopCheapestStores:      c.TopCheapestStores,
-		TopNearestStores:       c.TopNearestStores,
-		MaxCandidates:          c.MaxCandidates,
-		MaxDistanceKm:          c.MaxDistanceKm,
-		OptimalTimeoutMs:       c.OptimalTimeoutMs,
-		MaxBasketItems:         c.MaxBasketItems,
-		MinBasketItems:         c.MinBasketItems,
-		MissingItemPenaltyMult: c.MissingItemPenaltyMult,
-		MissingItemFallback:    c.MissingItemFallback,
-		CoverageBins:           c.CoverageBins,
+		CacheLoadTimeout:             c.CacheLoadTimeout,
+		CacheTTL:                     c.CacheTTL,
+		CacheRefreshJitter:           c.CacheRefreshJitter,
+		WarmupConcurrency:            c.WarmupConcurrency,
+		WarmupHoldTimeout:            c.WarmupHoldTimeout,
+		SnapshotScanWorkers:          c.SnapshotScanWorkers,
+		TopCheapestStores:            c.TopCheapestStores,
+		TopNearestStores:             c.TopNearestStores,
+		MaxCandidates:                c.MaxCandidates,
+		CandidateFilterBitsPerKey:    c.CandidateFilterBitsPerKey,
+		MaxDistanceKm:                c.MaxDistanceKm,
+		BalancedDistanceCostPerKm:    c.BalancedDistanceCostPerKm,
+		TravelCostPerKm:              c.TravelCostPerKm,
+		ExtraStoreCost:               c.ExtraStoreCost,
+		OptimalTimeoutMs:             c.OptimalTimeoutMs,
+		MaxBasketItems:               c.MaxBasketItems,
+		MinBasketItems:               c.MinBasketItems,
+		MissingItemPenaltyMult:       c.MissingItemPenaltyMult,
+		MissingItemFallback:          c.MissingItemFallback,
+		MissingItemPenaltyStrategy:   c.MissingItemPenaltyStrategy,
+		MissingItemCategoryPenalties: c.MissingItemCategoryPenalties,
+		CoverageBins:                 c.CoverageBins,
 	}
 }
 
@@ -91,6 +122,12 @@ func (c *Config) Validate() error {
 	if c.WarmupConcurrency < 1 {
 		return ErrInvalidConfig{Field: "warmup_concurrency", Reason: "must be at least 1"}
 	}
+	if c.WarmupHoldTimeout < 0 {
+		return ErrInvalidConfig{Field: "warmup_hold_timeout", Reason: "must be non-negative"}
+	}
+	if c.SnapshotScanWorkers < 1 {
+		return ErrInvalidConfig{Field: "snapshot_scan_workers", Reason: "must be at least 1"}
+	}
 	if c.TopCheapestStores < 1 {
 		return ErrInvalidConfig{Field: "top_cheapest_stores", Reason: "must be at least 1"}
 	}
@@ -100,9 +137,21 @@ func (c *Config) Validate() error {
 	if c.MaxCandidates < c.TopCheapestStores+c.TopNearestStores {
 		return ErrInvalidConfig{Field: "max_candidates", Reason: "must be >= top_cheapest_stores + top_nearest_stores"}
 	}
+	if c.CandidateFilterBitsPerKey < 0 || c.CandidateFilterBitsPerKey > 32 {
+		return ErrInvalidConfig{Field: "candidate_filter_bits_per_key", Reason: "must be between 0 and 32"}
+	}
 	if c.MaxDistanceKm <= 0 {
 		return ErrInvalidConfig{Field: "max_distance_km", Reason: "must be positive"}
 	}
+	if c.BalancedDistanceCostPerKm < 0 {
+		return ErrInvalidConfig{Field: "balanced_distance_cost_per_km", Reason: "must be non-negative"}
+	}
+	if c.TravelCostPerKm < 0 {
+		return ErrInvalidConfig{Field: "travel_cost_per_km", Reason: "must be non-negative"}
+	}
+	if c.ExtraStoreCost < 0 {
+		return ErrInvalidConfig{Field: "extra_store_cost", Reason: "must be non-negative"}
+	}
 	if c.OptimalTimeoutMs < 1 {
 		return ErrInvalidConfig{Field: "optimal_timeout_ms", Reason: "must be at least 1"}
 	}
@@ -115,6 +164,14 @@ func (c *Config) Validate() error {
 	if c.MissingItemFallback < 0 {
 		return ErrInvalidConfig{Field: "missing_item_fallback", Reason: "must be non-negative"}
 	}
+	if !IsPenaltyStrategy(c.MissingItemPenaltyStrategy) {
+		return ErrInvalidConfig{Field: "missing_item_penalty_strategy", Reason: "unknown penalty strategy"}
+	}
+	for category, penalty := range c.MissingItemCategoryPenalties {
+		if penalty < 0 {
+			return ErrInvalidConfig{Field: "missing_item_category_penalties." + category, Reason: "must be non-negative"}
+		}
+	}
 	if len(c.CoverageBins) != 3 {
 		return ErrInvalidConfig{Field: "coverage_bins", Reason: "must have exactly 3 values"}
 	}
@@ -127,6 +184,35 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// Validate validates the optimizer settings with the same rules as Config.
+func (c *OptimizerConfig) Validate() error {
+	cfg := Config{
+		CacheLoadTimeout:             c.CacheLoadTimeout,
+		CacheTTL:                     c.CacheTTL,
+		CacheRefreshJitter:           c.CacheRefreshJitter,
+		WarmupConcurrency:            c.WarmupConcurrency,
+		WarmupHoldTimeout:            c.WarmupHoldTimeout,
+		SnapshotScanWorkers:          c.SnapshotScanWorkers,
+		TopCheapestStores:            c.TopCheapestStores,
+		TopNearestStores:             c.TopNearestStores,
+		MaxCandidates:                c.MaxCandidates,
+		CandidateFilterBitsPerKey:    c.CandidateFilterBitsPerKey,
+		MaxDistanceKm:                c.MaxDistanceKm,
+		BalancedDistanceCostPerKm:    c.BalancedDistanceCostPerKm,
+		TravelCostPerKm:              c.TravelCostPerKm,
+		ExtraStoreCost:               c.ExtraStoreCost,
+		OptimalTimeoutMs:             c.OptimalTimeoutMs,
+		MaxBasketItems:               c.MaxBasketItems,
+		MinBasketItems:               c.MinBasketItems,
+		MissingItemPenaltyMult:       c.MissingItemPenaltyMult,
+		MissingItemFallback:          c.MissingItemFallback,
+		MissingItemPenaltyStrategy:   c.MissingItemPenaltyStrategy,
+		MissingItemCategoryPenalties: c.MissingItemCategoryPenalties,
+		CoverageBins:                 c.CoverageBins,
+	}
+	return cfg.Validate()
+}
+
 // ErrInvalidConfig is returned when the configuration is invalid.
 type ErrInvalidConfig struct {
 	Field  string
@@ -0,0 +1,108 @@
+package optimizer
+
+import "time"
+
+// DiscountedItem is an item on discount in at least one of a chain's price
+// groups. The deepest discount wins; its prices and window are reported.
+type DiscountedItem struct {
+	ItemID          string
+	Category        string
+	Price           int64
+	DiscountPrice   int64
+	DiscountPercent float64
+	DiscountStart   *time.Time
+	DiscountEnd     *time.Time
+	// StoreCount is the number of active stores carrying the item whose
+	// price group has it on discount at any depth
+	StoreCount int
+}
+
+// CurrentDiscounts returns the chain's items on discount at the given time,
+// in no particular order. Discounts whose window has not started or has ended
+// are left out, as are groups no active store belongs to. Store-specific
+// exceptions are not considered. Returns false if the chain is not cached.
+func (c *PriceCache) CurrentDiscounts(chainSlug string, at time.Time) ([]DiscountedItem, bool) {
+	c.chainsMu.RLock()
+	chainCache, exists := c.chains[chainSlug]
+	c.chainsMu.RUnlock()
+
+	if !exists {
+		return nil, false
+	}
+
+	snapshot := c.getSnapshot(chainCache)
+	if snapshot == nil {
+		return nil, false
+	}
+
+	return snapshot.currentDiscounts(at), true
+}
+
+// currentDiscounts collects the snapshot's discounts in effect at the given
+// time, one per item.
+func (s *ChainCacheSnapshot) currentDiscounts(at time.Time) []DiscountedItem {
+	groupStores := make(map[string]int, len(s.groupPrices))
+	for _, groupID := range s.storeToGroup {
+		groupStores[groupID]++
+	}
+
+	byOrdinal := make(map[int32]*DiscountedItem)
+	for groupID, table := range s.groupPrices {
+		stores := groupStores[groupID]
+		if stores == 0 {
+			continue
+		}
+		for i, ordinal := range table.items {
+			price, discountPrice := int64(table.prices[i]), int64(table.discountPrices[i])
+			if discountPrice == price {
+				continue
+			}
+			window := table.window(i)
+			if !window.contains(at) {
+				continue
+			}
+
+			percent := float64(price-discountPrice) * 100 / float64(price)
+			item, ok := byOrdinal[ordinal]
+			if !ok {
+				item = &DiscountedItem{ItemID: s.itemIDs[ordinal]}
+				byOrdinal[ordinal] = item
+			}
+			item.StoreCount += stores
+			if ok && percent <= item.DiscountPercent {
+				continue
+			}
+			item.Price = price
+			item.DiscountPrice = discountPrice
+			item.DiscountPercent = percent
+			item.DiscountStart, item.DiscountEnd = window.bounds()
+		}
+	}
+
+	// Stores that do not carry an item do not offer its discount
+	for storeID, ordinals := range s.unstocked {
+		table := s.groupPrices[s.storeToGroup[storeID]]
+		if table == nil {
+			continue
+		}
+		for _, ordinal := range ordinals {
+			item, ok := byOrdinal[ordinal]
+			if !ok {
+				continue
+			}
+			if p, ok := table.get(ordinal); ok && p.DiscountActive(at) {
+				item.StoreCount--
+			}
+		}
+	}
+
+	items := make([]DiscountedItem, 0, len(byOrdinal))
+	for _, item := range byOrdinal {
+		if item.StoreCount <= 0 {
+			continue
+		}
+		item.Category = s.itemCategories[item.ItemID]
+		items = append(items, *item)
+	}
+	return items
+}
@@ -0,0 +1,64 @@
+package optimizer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrentDiscounts(t *testing.T) {
+	now := time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC)
+	yesterday, tomorrow := now.Add(-24*time.Hour), now.Add(24*time.Hour)
+	lastWeek := now.Add(-7 * 24 * time.Hour)
+	price := func(p int) *int { return &p }
+
+	b := newSnapshotBuilder()
+	b.addStore("sto-a", "grp-1", nil, nil)
+	b.addStore("sto-b", "grp-1", nil, nil)
+	b.addStore("sto-c", "grp-2", nil, nil)
+	b.addGroupPriceWindow("grp-1", "itm-a", 200, price(150), &yesterday, &tomorrow)
+	b.addGroupPriceWindow("grp-2", "itm-a", 200, price(100), nil, &tomorrow)
+	b.addGroupPriceWindow("grp-1", "itm-b", 100, price(90), nil, nil)
+	b.addGroupPriceWindow("grp-1", "itm-expired", 100, price(50), &lastWeek, &yesterday)
+	b.addGroupPriceWindow("grp-1", "itm-upcoming", 100, price(50), &tomorrow, nil)
+	b.addGroupPriceWindow("grp-orphan", "itm-orphan", 100, price(50), nil, nil)
+	b.addGroupPrice("grp-1", "itm-regular", 100, nil)
+	b.addItemCategory("itm-a", "dairy")
+
+	cache := &PriceCache{chains: map[string]*ChainCache{"test": {}}}
+	cache.chains["test"].snapshot.Store(b.build())
+
+	items, ok := cache.CurrentDiscounts("test", now)
+	require.True(t, ok)
+	require.Len(t, items, 2)
+
+	byID := map[string]DiscountedItem{}
+	for _, item := range items {
+		byID[item.ItemID] = item
+	}
+
+	a := byID["itm-a"]
+	assert.Equal(t, "dairy", a.Category)
+	assert.EqualValues(t, 100, a.DiscountPrice)
+	assert.InDelta(t, 50, a.DiscountPercent, 0.001)
+	assert.Nil(t, a.DiscountStart)
+	require.NotNil(t, a.DiscountEnd)
+	assert.True(t, a.DiscountEnd.Equal(tomorrow))
+	assert.Equal(t, 3, a.StoreCount)
+
+	bItem := byID["itm-b"]
+	assert.InDelta(t, 10, bItem.DiscountPercent, 0.001)
+	assert.Equal(t, 2, bItem.StoreCount)
+
+	// Lookups carry the window, so an expired discount no longer applies
+	expired, ok := cache.GetPrice("test", "sto-a", "itm-expired")
+	require.True(t, ok)
+	assert.True(t, expired.HasDiscount)
+	assert.False(t, expired.DiscountActive(now))
+	assert.EqualValues(t, 100, GetEffectivePrice(expired, now))
+
+	_, ok = cache.CurrentDiscounts("missing", now)
+	assert.False(t, ok)
+}
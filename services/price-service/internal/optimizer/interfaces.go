@@ -2,6 +2,9 @@ package optimizer
 
 import (
 	"context"
+	"time"
+
+	"github.com/kosarica/price-service/internal/pkg/clock"
 )
 
 // Location represents a store's geographic coordinates.
@@ -19,11 +22,50 @@ type StoreWithDistance struct {
 // CachedPrice represents price data for an item at a store or price group.
 // Uses int64 for all money values to reduce GC pressure.
 // DiscountPrice of 0 with HasDiscount=false means no discount available.
+// A discount only applies within its validity window; see DiscountActive.
 type CachedPrice struct {
-	Price         int64 // Base price in minor currency units (e.g., lipa)
-	DiscountPrice int64 // Discounted price if HasDiscount is true
-	HasDiscount   bool  // Whether a discount is available
-	IsException   bool  // Whether this is a store-specific exception price
+	Price         int64      // Base price in minor currency units (e.g., lipa)
+	DiscountPrice int64      // Discounted price if HasDiscount is true
+	HasDiscount   bool       // Whether a discount is recorded
+	IsException   bool       // Whether this is a store-specific exception price
+	DiscountStart *time.Time // When the discount starts; nil if unknown
+	DiscountEnd   *time.Time // When the discount ends; nil if unknown
+}
+
+// DiscountActive reports whether the price's discount applies at the given
+// time. Unknown window bounds are open. A bound holding a date without a time
+// (see clock.IsDate) is a whole day in the service's time zone: the discount
+// starts when its first day starts there and lasts through its last day.
+func (p CachedPrice) DiscountActive(at time.Time) bool {
+	if !p.HasDiscount {
+		return false
+	}
+	if p.DiscountStart != nil {
+		start := *p.DiscountStart
+		if clock.IsDate(start) {
+			start = clock.DateStart(start)
+		}
+		if at.Before(start) {
+			return false
+		}
+	}
+	if p.DiscountEnd == nil {
+		return true
+	}
+	if clock.IsDate(*p.DiscountEnd) {
+		return at.Before(clock.DateStart(p.DiscountEnd.AddDate(0, 0, 1)))
+	}
+	return !at.After(*p.DiscountEnd)
+}
+
+// ItemPriceStats summarizes an item's prices across a chain's price groups.
+// All values are in minor currency units.
+type ItemPriceStats struct {
+	Average         int64 // Mean of the group prices, truncated
+	WeightedAverage int64 // Mean weighted by each group's active store count, truncated
+	Median          int64 // Median of the group prices, rounded
+	Min             int64 // Lowest group price
+	Max             int64 // Highest group price
 }
 
 // PriceSource defines the interface for accessing price data.
@@ -37,6 +79,22 @@ type PriceSource interface {
 	// Used for penalty calculations when items are missing at stores.
 	GetAveragePrice(chainSlug string, itemID string) int64
 
+	// GetWeightedAveragePrice returns the chain-wide average price for an
+	// item, weighting each price group by its number of active stores.
+	GetWeightedAveragePrice(chainSlug string, itemID string) int64
+
+	// GetItemPriceStats returns the chain-wide price statistics for an item.
+	// Returns false if the item has no group prices in the chain.
+	GetItemPriceStats(chainSlug string, itemID string) (ItemPriceStats, bool)
+
+	// GetItemCategory returns the item's category.
+	// Returns false if the item has no category.
+	GetItemCategory(chainSlug string, itemID string) (string, bool)
+
+	// GetCategoryAveragePrice returns the mean of the store-weighted average
+	// prices of the category's priced items, or 0 if none are priced.
+	GetCategoryAveragePrice(chainSlug string, category string) int64
+
 	// GetNearestStores returns stores within maxDistanceKm of the given location,
 	// sorted by distance (closest first) and limited to the specified count.
 	GetNearestStores(chainSlug string, lat, lon, maxDistanceKm float64, limit int) []StoreWithDistance
@@ -49,6 +107,22 @@ type PriceSource interface {
 	IsHealthy(ctx context.Context) bool
 }
 
+// BasketFootprinter is implemented by price sources that can bound a store's
+// coverage of a basket without pricing every item. The multi-store optimizer
+// uses it to skip stores that cannot become candidates.
+type BasketFootprinter interface {
+	// BasketFootprint prepares the basket's items for per-store checks.
+	// Returns nil when no bound is available for the chain.
+	BasketFootprint(chainSlug string, itemIDs []string) *BasketFootprint
+}
+
+// StoreLocator is implemented by price sources that know where stores are.
+// The multi-store optimizer uses it to plan the route between its stores.
+type StoreLocator interface {
+	// GetStoreLocation returns a store's location, or false when unknown.
+	GetStoreLocation(chainSlug, storeID string) (Location, bool)
+}
+
 // Optimizer is the main interface for basket optimization operations.
 type Optimizer interface {
 	// SingleStoreOptimize finds the best single stores for a basket.
@@ -81,4 +155,7 @@ type CacheFreshness struct {
 	LoadedAt    int64 // Unix timestamp of last load
 	IsStale     bool  // Whether cache is considered stale
 	EstimatedMB int64 // Estimated memory usage in megabytes
+
+	InternedStrings  int   // Distinct IDs retained by the snapshot
+	InternSavedBytes int64 // Duplicate ID bytes interning kept out of the snapshot
 }
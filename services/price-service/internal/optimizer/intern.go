@@ -0,0 +1,46 @@
+package optimizer
+
+// stringHeaderBytes is the size of a string header (data pointer + length)
+// on 64-bit platforms. Every map key or value holding an ID costs this much
+// even when its bytes are shared.
+const stringHeaderBytes = 16
+
+// stringInterner deduplicates ID strings while a snapshot is built.
+//
+// pgx allocates a fresh string for every scanned column, so without interning
+// an item priced in 300 groups keeps 300 copies of its ID alive, plus one more
+// from the statistics query. Routing every ID through intern makes all map keys
+// and values share a single backing array per distinct ID; the per-row copies
+// become garbage as soon as the scan loop moves on.
+//
+// Lookups still take plain strings, so callers are unaffected. The interner is
+// only used during the build and is dropped with the loader's stack frame.
+type stringInterner struct {
+	strings map[string]string
+
+	// uniqueBytes is the total length of the distinct strings retained
+	uniqueBytes int64
+	// savedBytes is the total length of the duplicates that were replaced by
+	// an existing copy and so are not retained by the snapshot
+	savedBytes int64
+}
+
+func newStringInterner(sizeHint int) *stringInterner {
+	return &stringInterner{strings: make(map[string]string, sizeHint)}
+}
+
+// intern returns the canonical copy of s.
+func (in *stringInterner) intern(s string) string {
+	if canonical, ok := in.strings[s]; ok {
+		in.savedBytes += int64(len(s))
+		return canonical
+	}
+	in.strings[s] = s
+	in.uniqueBytes += int64(len(s))
+	return s
+}
+
+// len returns the number of distinct strings interned.
+func (in *stringInterner) len() int {
+	return len(in.strings)
+}
@@ -0,0 +1,144 @@
+package optimizer
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringInterner(t *testing.T) {
+	in := newStringInterner(0)
+
+	// Build the duplicates at run time so they do not share a literal
+	first := fmt.Sprintf("item-%d", 42)
+	second := fmt.Sprintf("item-%d", 42)
+	require.NotSame(t, unsafe.StringData(first), unsafe.StringData(second))
+
+	a := in.intern(first)
+	b := in.intern(second)
+	c := in.intern("other")
+
+	assert.Same(t, unsafe.StringData(a), unsafe.StringData(b))
+	assert.Equal(t, "other", c)
+	assert.Equal(t, 2, in.len())
+	assert.EqualValues(t, len("item-42")+len("other"), in.uniqueBytes)
+	assert.EqualValues(t, len("item-42"), in.savedBytes)
+}
+
+// syntheticSnapshot builds a snapshot through the same builder
+// loadChainSnapshot uses, with every ID freshly allocated per row as pgx does
+func syntheticSnapshot(stores, groups, items int) *ChainCacheSnapshot {
+	b := newSnapshotBuilder()
+	lat, lon := 45.0, 16.0
+	for st := 0; st < stores; st++ {
+		b.addStore(syntheticID("sto", st), syntheticID("grp", st%groups), &lat, &lon)
+	}
+	for g := 0; g < groups; g++ {
+		for i := 0; i < items; i++ {
+			b.addGroupPrice(syntheticID("grp", g), syntheticID("itm", i), 100+i%7, nil)
+		}
+	}
+	return b.build()
+}
+
+func syntheticID(kind string, n int) string {
+	return fmt.Sprintf("%s_%024d", kind, n)
+}
+
+func TestSyntheticSnapshotSharesItemIDs(t *testing.T) {
+	s := syntheticSnapshot(10, 3, 50)
+
+	assert.Equal(t, 10+3+50, s.internedStrings)
+	// Each item ID is repeated in the other two groups; group IDs repeat
+	// across the ten stores and again for every group price row
+	const idLen = len("itm_000000000000000000000000")
+	assert.EqualValues(t, (50*2+(10-3)+3*50)*idLen, s.internSavedBytes)
+
+	var fromStores, fromGroups *byte
+	for _, groupID := range s.storeToGroup {
+		if groupID == "grp_000000000000000000000001" {
+			fromStores = unsafe.StringData(groupID)
+		}
+	}
+	for groupID := range s.groupPrices {
+		if groupID == "grp_000000000000000000000001" {
+			fromGroups = unsafe.StringData(groupID)
+		}
+	}
+	require.NotNil(t, fromStores)
+	assert.Same(t, fromStores, fromGroups)
+}
+
+func TestEstimateSnapshotSizeCountsIDsOnce(t *testing.T) {
+	c := &PriceCache{}
+	small := c.estimateSnapshotSize(syntheticSnapshot(10, 1, 100))
+	large := c.estimateSnapshotSize(syntheticSnapshot(10, 5, 100))
+
+	// Four more groups add their own ID and a table, but no item ID bytes
+	const idLen = len("grp_000000000000000000000000")
+	perExtraGroup := (large - small) / 4
+	assert.Equal(t, int64(idLen+stringHeaderBytes+64+3*24+100*12), perExtraGroup)
+}
+
+// BenchmarkSnapshotRetainedHeap reports the live heap a synthetic snapshot
+// retains, against the nested-map layout it replaced (without interning).
+// The shapes approximate a chain with a single national price group, one with
+// regional groups, and one pricing most stores individually.
+func BenchmarkSnapshotRetainedHeap(b *testing.B) {
+	shapes := []struct {
+		name                  string
+		stores, groups, items int
+	}{
+		{"national", 300, 1, 20000},
+		{"regional", 300, 20, 20000},
+		{"per-store", 200, 150, 15000},
+	}
+
+	for _, shape := range shapes {
+		b.Run(shape.name+"/maps", func(b *testing.B) {
+			var retained uint64
+			for i := 0; i < b.N; i++ {
+				before := liveHeap()
+				m := syntheticGroupPriceMaps(shape.groups, shape.items)
+				retained = liveHeap() - before
+				runtime.KeepAlive(m)
+			}
+			b.ReportMetric(float64(retained)/(1<<20), "MB")
+		})
+		b.Run(shape.name+"/columnar", func(b *testing.B) {
+			var retained uint64
+			for i := 0; i < b.N; i++ {
+				before := liveHeap()
+				s := syntheticSnapshot(shape.stores, shape.groups, shape.items)
+				retained = liveHeap() - before
+				runtime.KeepAlive(s)
+			}
+			b.ReportMetric(float64(retained)/(1<<20), "MB")
+		})
+	}
+}
+
+// syntheticGroupPriceMaps builds group prices in the groupID -> itemID ->
+// price layout snapshots used before the columnar tables, for comparison
+func syntheticGroupPriceMaps(groups, items int) map[string]map[string]CachedPrice {
+	m := make(map[string]map[string]CachedPrice)
+	for g := 0; g < groups; g++ {
+		prices := make(map[string]CachedPrice)
+		for i := 0; i < items; i++ {
+			prices[syntheticID("itm", i)] = newCachedPrice(100+i%7, nil, false)
+		}
+		m[syntheticID("grp", g)] = prices
+	}
+	return m
+}
+
+func liveHeap() uint64 {
+	runtime.GC()
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.HeapAlloc
+}
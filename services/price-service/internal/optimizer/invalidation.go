@@ -0,0 +1,468 @@
+package optimizer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/kosarica/price-service/internal/database"
+)
+
+const (
+	// invalidationBatchWindow is how long invalidations following the first
+	// of a burst are collected, so a run moving many stores patches each
+	// chain once per window and reloads it at most once
+	invalidationBatchWindow = 2 * time.Second
+
+	// invalidationReconnectDelay is the wait before listening again after
+	// the listening connection failed
+	invalidationReconnectDelay = 5 * time.Second
+
+	// maxPatchAttempts bounds the retries of a patch racing other swaps of
+	// the same chain's snapshot
+	maxPatchAttempts = 3
+)
+
+// errPatchNeedsReload is returned by applyDelta when the delta prices items
+// the snapshot's item index lacks, which only a full load can add
+var errPatchNeedsReload = errors.New("patch needs a full reload")
+
+// chainInvalidations are the invalidations of one chain collected in a batch
+type chainInvalidations struct {
+	refresh  bool     // The whole chain is refreshed, so its stores are not patched
+	storeIDs []string // Stores to patch, once each
+}
+
+// storeDelta is the current state of one store, read to patch a snapshot.
+type storeDelta struct {
+	storeID string
+
+	// groupID is the store's current group, empty when the store is no
+	// longer active or has no group and is dropped from the snapshot
+	groupID  string
+	location *Location
+
+	// groupRows holds the group's prices when the snapshot did not hold the
+	// group when the delta was read, nil otherwise
+	groupRows []groupPriceRow
+
+	exceptions map[string]CachedPrice
+	unstocked  []string
+}
+
+// ListenForInvalidations keeps loaded snapshots current with changes made by
+// ingestion and other replicas, instead of waiting for the next full load.
+// It listens for database.CacheInvalidation notifications on a dedicated
+// connection until the cache is closed: store invalidations are patched in
+// and chain invalidations refresh the chain. It reconnects when the
+// connection fails; invalidations sent while disconnected are lost, so
+// loaded chains are refreshed on reconnecting.
+func (c *PriceCache) ListenForInvalidations() {
+	if c.db == nil {
+		return
+	}
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		resync := false
+		for {
+			err := c.receiveInvalidations(c.ctx, resync)
+			if c.ctx.Err() != nil {
+				return
+			}
+			c.logger.Warn().Err(err).Dur("retry_in", invalidationReconnectDelay).Msg("Cache invalidation listener disconnected")
+
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-time.After(invalidationReconnectDelay):
+			}
+			resync = true
+		}
+	}()
+}
+
+// receiveInvalidations listens on one connection and applies invalidations
+// in batches until the connection fails or ctx ends. With resync set, loaded
+// chains are refreshed once listening.
+func (c *PriceCache) receiveInvalidations(ctx context.Context, resync bool) error {
+	pooled, err := c.db.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	// A listening connection must not return to the pool
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{database.CacheInvalidationChannel}.Sanitize()); err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	c.logger.Info().Str("channel", database.CacheInvalidationChannel).Msg("Listening for cache invalidations")
+
+	if resync {
+		c.refreshLoadedChains(ctx)
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		batch := make(map[string]*chainInvalidations)
+		c.addInvalidation(batch, notification)
+
+		// Collect the rest of the burst
+		deadline := time.Now().Add(invalidationBatchWindow)
+		for {
+			waitCtx, cancel := context.WithDeadline(ctx, deadline)
+			notification, err := conn.WaitForNotification(waitCtx)
+			cancel()
+			if err != nil {
+				if ctx.Err() != nil {
+					return err
+				}
+				if !pgconn.Timeout(err) {
+					return err
+				}
+				break
+			}
+			c.addInvalidation(batch, notification)
+		}
+
+		for chainSlug, invs := range batch {
+			applyCtx, cancel := context.WithTimeout(ctx, c.config.CacheLoadTimeout)
+			c.applyChainInvalidations(applyCtx, chainSlug, invs)
+			cancel()
+		}
+	}
+}
+
+// addInvalidation adds a notified invalidation to a batch by chain, skipping
+// the ones this cache sent
+func (c *PriceCache) addInvalidation(batch map[string]*chainInvalidations, notification *pgconn.Notification) {
+	var inv database.CacheInvalidation
+	if err := json.Unmarshal([]byte(notification.Payload), &inv); err != nil || inv.ChainSlug == "" {
+		c.logger.Warn().Err(err).Str("payload", notification.Payload).Msg("Ignoring malformed cache invalidation")
+		return
+	}
+	if inv.Origin == c.instanceID {
+		return
+	}
+
+	invs, ok := batch[inv.ChainSlug]
+	if !ok {
+		invs = &chainInvalidations{}
+		batch[inv.ChainSlug] = invs
+	}
+	switch {
+	case inv.StoreID == "":
+		invs.refresh = true
+		invs.storeIDs = nil
+	case !invs.refresh && !slices.Contains(invs.storeIDs, inv.StoreID):
+		invs.storeIDs = append(invs.storeIDs, inv.StoreID)
+	}
+}
+
+// applyChainInvalidations refreshes or patches a chain for its invalidations
+// of a batch, logging failures. Only loaded chains are refreshed; the others
+// load with current prices when first used.
+func (c *PriceCache) applyChainInvalidations(ctx context.Context, chainSlug string, invs *chainInvalidations) {
+	if !invs.refresh {
+		if err := c.ApplyInvalidations(ctx, chainSlug, invs.storeIDs); err != nil && !errors.Is(err, ErrRefreshHeld) {
+			c.logger.Warn().Err(err).Str("chain", chainSlug).Int("stores", len(invs.storeIDs)).Msg("Failed to apply cache invalidations")
+		}
+		return
+	}
+
+	if _, loaded := c.ChainFreshness(chainSlug); !loaded {
+		return
+	}
+	if err := c.RefreshChain(ctx, chainSlug); err != nil && !errors.Is(err, ErrRefreshHeld) {
+		c.logger.Warn().Err(err).Str("chain", chainSlug).Msg("Failed to refresh invalidated chain")
+		return
+	}
+	c.logger.Info().Str("chain", chainSlug).Msg("Refreshed invalidated chain")
+}
+
+// BroadcastRefresh tells the caches of other replicas to refresh a chain this
+// cache has just refreshed.
+func (c *PriceCache) BroadcastRefresh(ctx context.Context, chainSlug string) error {
+	if c.db == nil {
+		return nil
+	}
+	return database.NotifyCacheInvalidation(ctx, c.db, database.CacheInvalidation{
+		ChainSlug: chainSlug,
+		Origin:    c.instanceID,
+	})
+}
+
+// refreshLoadedChains refreshes every loaded chain, logging failures
+func (c *PriceCache) refreshLoadedChains(ctx context.Context) {
+	c.chainsMu.RLock()
+	chainSlugs := slices.Collect(maps.Keys(c.chains))
+	c.chainsMu.RUnlock()
+
+	for _, chainSlug := range chainSlugs {
+		if err := c.RefreshChain(ctx, chainSlug); err != nil && !errors.Is(err, ErrRefreshHeld) {
+			c.logger.Warn().Err(err).Str("chain", chainSlug).Msg("Failed to refresh chain after reconnecting")
+		}
+	}
+}
+
+// ApplyInvalidations patches a loaded chain's snapshot for stores whose
+// prices changed: each store's group mapping, location, exceptions and
+// unstocked items are replaced, and a group new to the snapshot gets its
+// prices. Everything else is shared with the current snapshot, so the patch
+// costs a few small queries rather than a full load.
+//
+// Item statistics and category averages stay as of the last full load, as
+// does the chain's load time. A store pricing items the snapshot has never
+// seen needs a full load, which is done instead. Chains not loaded are left
+// alone, and while refreshes are held (see RefreshChain) nothing is patched
+// and ErrRefreshHeld is returned.
+func (c *PriceCache) ApplyInvalidations(ctx context.Context, chainSlug string, storeIDs []string) error {
+	c.chainsMu.RLock()
+	chainCache, exists := c.chains[chainSlug]
+	c.chainsMu.RUnlock()
+
+	if !exists || c.getSnapshot(chainCache) == nil {
+		return nil
+	}
+
+	held, err := c.refreshHeld(ctx, chainSlug)
+	if err != nil {
+		c.metrics.RecordCachePatch(chainSlug, "failed")
+		return err
+	}
+	if held {
+		c.metrics.RecordCachePatch(chainSlug, "held")
+		return fmt.Errorf("%w: chain %s", ErrRefreshHeld, chainSlug)
+	}
+
+	for _, storeID := range storeIDs {
+		err := c.patchStore(ctx, chainSlug, chainCache, storeID)
+		if errors.Is(err, errPatchNeedsReload) {
+			c.logger.Info().Str("chain", chainSlug).Str("store_id", storeID).Msg("Store prices new items, reloading chain")
+			c.metrics.RecordCachePatch(chainSlug, "reloaded")
+			return c.LoadChain(ctx, chainSlug)
+		}
+		if err != nil {
+			c.metrics.RecordCachePatch(chainSlug, "failed")
+			return fmt.Errorf("store %s: %w", storeID, err)
+		}
+	}
+
+	c.metrics.RecordCachePatch(chainSlug, "patched")
+	if snapshot := c.getSnapshot(chainCache); snapshot != nil {
+		c.metrics.RecordSnapshotMemory(chainSlug, snapshot.estimatedSizeBytes)
+	}
+	c.logger.Debug().Str("chain", chainSlug).Int("stores", len(storeIDs)).Msg("Patched chain cache snapshot")
+	return nil
+}
+
+// patchStore reads a store's delta and swaps in a patched snapshot, retrying
+// when another load or patch swapped the snapshot meanwhile.
+func (c *PriceCache) patchStore(ctx context.Context, chainSlug string, chainCache *ChainCache, storeID string) error {
+	for attempt := 0; attempt < maxPatchAttempts; attempt++ {
+		current := c.getSnapshot(chainCache)
+		delta, err := c.loadStoreDelta(ctx, chainSlug, storeID, current)
+		if err != nil {
+			return err
+		}
+		patched, err := current.applyDelta(delta, c.config.CandidateFilterBitsPerKey)
+		if err != nil {
+			return err
+		}
+		patched.estimatedSizeBytes = c.estimateSnapshotSize(patched)
+		if chainCache.snapshot.CompareAndSwap(current, patched) {
+			return nil
+		}
+	}
+	return errPatchNeedsReload
+}
+
+// loadStoreDelta reads the current state of a store in one transaction,
+// with its group's prices when snapshot does not hold the group.
+func (c *PriceCache) loadStoreDelta(ctx context.Context, chainSlug, storeID string, snapshot *ChainCacheSnapshot) (*storeDelta, error) {
+	tx, err := c.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	delta := &storeDelta{storeID: storeID}
+	var lat, lon *float64
+	err = tx.QueryRow(ctx, `
+		SELECT s.latitude, s.longitude, sgh.price_group_id
+		FROM stores s
+		JOIN store_group_history sgh ON sgh.store_id = s.id
+		WHERE s.id = $1
+		  AND s.chain_slug = $2
+		  AND s.status = 'active'
+		  AND sgh.valid_to IS NULL
+	`, storeID, chainSlug).Scan(&lat, &lon, &delta.groupID)
+	if err == pgx.ErrNoRows {
+		return delta, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query store: %w", err)
+	}
+	if lat != nil && lon != nil {
+		delta.location = &Location{Latitude: *lat, Longitude: *lon}
+	}
+
+	if _, ok := snapshot.groupPrices[delta.groupID]; !ok {
+		rows, err := tx.Query(ctx, `
+			SELECT retailer_item_id, price, discount_price, discount_start, discount_end
+			FROM group_prices
+			WHERE price_group_id = $1
+		`, delta.groupID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query group prices: %w", err)
+		}
+		delta.groupRows, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (groupPriceRow, error) {
+			var itemID string
+			var price int
+			var discountPrice *int
+			var discountStart, discountEnd *time.Time
+			if err := row.Scan(&itemID, &price, &discountPrice, &discountStart, &discountEnd); err != nil {
+				return groupPriceRow{}, err
+			}
+			return newGroupPriceRow(itemID, price, discountPrice, discountStart, discountEnd), nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan group prices: %w", err)
+		}
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT retailer_item_id, price, discount_price
+		FROM store_price_exceptions
+		WHERE store_id = $1 AND valid_to > NOW()
+	`, storeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query exceptions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var itemID string
+		var price int
+		var discountPrice *int
+		if err := rows.Scan(&itemID, &price, &discountPrice); err != nil {
+			return nil, fmt.Errorf("failed to scan exception: %w", err)
+		}
+		if delta.exceptions == nil {
+			delta.exceptions = make(map[string]CachedPrice)
+		}
+		delta.exceptions[itemID] = newCachedPrice(price, discountPrice, true)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating exceptions: %w", err)
+	}
+
+	rows, err = tx.Query(ctx, `
+		SELECT gp.retailer_item_id
+		FROM group_prices gp
+		WHERE gp.price_group_id = $2
+		  AND EXISTS (SELECT 1 FROM store_item_assortment sa WHERE sa.store_id = $1)
+		  AND NOT EXISTS (
+		      SELECT 1 FROM store_item_assortment sa
+		      WHERE sa.store_id = $1 AND sa.retailer_item_id = gp.retailer_item_id
+		  )
+	`, storeID, delta.groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query store assortment: %w", err)
+	}
+	delta.unstocked, err = pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan store assortment: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return delta, nil
+}
+
+// applyDelta returns a copy of the snapshot with a store's delta applied.
+// Only the maps keyed by store, and by group when the delta adds one, are
+// copied, and the store index rebuilt; group tables, filters and the item
+// index are shared. It returns
+// errPatchNeedsReload when the delta prices items the snapshot lacks or
+// brings no prices for a group the snapshot lacks.
+func (s *ChainCacheSnapshot) applyDelta(d *storeDelta, bitsPerKey int) (*ChainCacheSnapshot, error) {
+	p := *s
+	p.storeToGroup = maps.Clone(s.storeToGroup)
+	p.storeLocations = maps.Clone(s.storeLocations)
+	p.exceptions = maps.Clone(s.exceptions)
+	p.unstocked = maps.Clone(s.unstocked)
+
+	delete(p.storeToGroup, d.storeID)
+	delete(p.storeLocations, d.storeID)
+	delete(p.exceptions, d.storeID)
+	delete(p.unstocked, d.storeID)
+	if d.groupID == "" {
+		p.storeIndex = newStoreIndex(p.storeLocations)
+		return &p, nil
+	}
+
+	if _, ok := s.groupPrices[d.groupID]; !ok {
+		if d.groupRows == nil {
+			return nil, errPatchNeedsReload
+		}
+		table, ok := newGroupPriceTable(d.groupRows, s.itemOrdinal)
+		if !ok {
+			return nil, errPatchNeedsReload
+		}
+		p.groupPrices = maps.Clone(s.groupPrices)
+		p.groupPrices[d.groupID] = table
+
+		if s.groupFilters != nil && bitsPerKey > 0 {
+			p.groupFilters = maps.Clone(s.groupFilters)
+			p.groupFilters[d.groupID] = newGroupFilter(table, s.itemCategoryIndexes(), make(map[int32]bool), bitsPerKey)
+		}
+	}
+
+	p.storeToGroup[d.storeID] = d.groupID
+	if d.location != nil {
+		p.storeLocations[d.storeID] = *d.location
+	}
+	p.storeIndex = newStoreIndex(p.storeLocations)
+	if len(d.exceptions) > 0 {
+		p.exceptions[d.storeID] = d.exceptions
+	}
+	if len(d.unstocked) > 0 {
+		items := make([]int32, 0, len(d.unstocked))
+		for _, itemID := range d.unstocked {
+			ordinal, ok := s.itemOrdinal(itemID)
+			if !ok {
+				return nil, errPatchNeedsReload
+			}
+			items = append(items, ordinal)
+		}
+		slices.Sort(items)
+		p.unstocked[d.storeID] = slices.Compact(items)
+	}
+	return &p, nil
+}
+
+// itemCategoryIndexes returns the category key index of each item ordinal,
+// -1 when uncategorized, as the group filters were built with.
+func (s *ChainCacheSnapshot) itemCategoryIndexes() []int32 {
+	itemCategory := make([]int32, len(s.itemIDs))
+	for i, itemID := range s.itemIDs {
+		itemCategory[i] = -1
+		if index, ok := s.categoryIndex[s.itemCategories[itemID]]; ok {
+			itemCategory[i] = index
+		}
+	}
+	return itemCategory
+}
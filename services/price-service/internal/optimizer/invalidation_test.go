@@ -0,0 +1,138 @@
+package optimizer
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// invalidationSnapshot builds a chain with two stores in one group and a
+// second, empty-store group, with candidate filters.
+func invalidationSnapshot() *ChainCacheSnapshot {
+	b := newSnapshotBuilder()
+	lat, lon := 45.81, 15.98
+	b.addStore("sto-a", "grp-1", &lat, &lon)
+	b.addStore("sto-b", "grp-1", nil, nil)
+	b.addGroupPrice("grp-1", "itm-a", 100, nil)
+	b.addGroupPrice("grp-1", "itm-b", 200, nil)
+	b.addGroupPrice("grp-2", "itm-a", 90, nil)
+	b.addGroupPrice("grp-old", "itm-c", 300, nil)
+	b.addException("sto-a", "itm-b", 150, nil)
+	b.addUnstocked("sto-b", "itm-b")
+	b.addItemCategory("itm-a", "dairy")
+
+	s := b.build()
+	s.buildGroupFilters(10)
+	return s
+}
+
+func TestApplyDeltaMovesStoreToNewGroup(t *testing.T) {
+	s := invalidationSnapshot()
+	discount := 180
+	delta := &storeDelta{
+		storeID:  "sto-a",
+		groupID:  "grp-3",
+		location: &Location{Latitude: 45.8, Longitude: 16.0},
+		groupRows: []groupPriceRow{
+			newGroupPriceRow("itm-c", 310, nil, nil, nil),
+			newGroupPriceRow("itm-b", 210, &discount, nil, nil),
+		},
+		unstocked: []string{"itm-c"},
+	}
+
+	p, err := s.applyDelta(delta, 10)
+	require.NoError(t, err)
+
+	cache := &PriceCache{chains: map[string]*ChainCache{"test": {}}}
+	cache.chains["test"].snapshot.Store(p)
+
+	price, ok := cache.GetPrice("test", "sto-a", "itm-b")
+	require.True(t, ok)
+	assert.Equal(t, CachedPrice{Price: 210, DiscountPrice: 180, HasDiscount: true}, price, "the exception is gone with the move")
+	_, ok = cache.GetPrice("test", "sto-a", "itm-c")
+	assert.False(t, ok, "unstocked in the store's assortment")
+	_, ok = cache.GetPrice("test", "sto-a", "itm-a")
+	assert.False(t, ok, "not priced in the new group")
+	assert.Equal(t, Location{Latitude: 45.8, Longitude: 16.0}, p.storeLocations["sto-a"])
+	require.Contains(t, p.groupFilters, "grp-3")
+	assert.True(t, p.groupFilters["grp-3"].mayContain(itemFilterKey(1)))
+
+	// Other stores and the original snapshot are untouched
+	price, ok = cache.GetPrice("test", "sto-b", "itm-a")
+	require.True(t, ok)
+	assert.EqualValues(t, 100, price.Price)
+	assert.Equal(t, "grp-1", s.storeToGroup["sto-a"])
+	assert.NotContains(t, s.groupPrices, "grp-3")
+	assert.NotContains(t, s.groupFilters, "grp-3")
+	assert.Contains(t, s.exceptions, "sto-a")
+	assert.Same(t, s.groupPrices["grp-1"], p.groupPrices["grp-1"], "group tables are shared")
+}
+
+func TestApplyDeltaKnownGroupAndExceptions(t *testing.T) {
+	s := invalidationSnapshot()
+	delta := &storeDelta{
+		storeID:    "sto-b",
+		groupID:    "grp-2",
+		exceptions: map[string]CachedPrice{"itm-a": newCachedPrice(80, nil, true)},
+	}
+
+	p, err := s.applyDelta(delta, 10)
+	require.NoError(t, err)
+	assert.Equal(t, "grp-2", p.storeToGroup["sto-b"])
+	assert.Equal(t, CachedPrice{Price: 80, DiscountPrice: 80, IsException: true}, p.exceptions["sto-b"]["itm-a"])
+	assert.NotContains(t, p.unstocked, "sto-b", "the assortment is re-read with the group")
+	assert.Same(t, s.groupFilters["grp-2"], p.groupFilters["grp-2"])
+}
+
+func TestApplyDeltaRemovesInactiveStore(t *testing.T) {
+	s := invalidationSnapshot()
+
+	p, err := s.applyDelta(&storeDelta{storeID: "sto-a"}, 10)
+	require.NoError(t, err)
+	assert.NotContains(t, p.storeToGroup, "sto-a")
+	assert.NotContains(t, p.storeLocations, "sto-a")
+	assert.NotContains(t, p.exceptions, "sto-a")
+	assert.Contains(t, p.storeToGroup, "sto-b")
+}
+
+func TestApplyDeltaNeedsReloadForNewItems(t *testing.T) {
+	s := invalidationSnapshot()
+
+	_, err := s.applyDelta(&storeDelta{
+		storeID:   "sto-a",
+		groupID:   "grp-new",
+		groupRows: []groupPriceRow{newGroupPriceRow("itm-new", 100, nil, nil, nil)},
+	}, 10)
+	assert.ErrorIs(t, err, errPatchNeedsReload)
+
+	_, err = s.applyDelta(&storeDelta{storeID: "sto-a", groupID: "grp-unread"}, 10)
+	assert.ErrorIs(t, err, errPatchNeedsReload, "a group the snapshot lacks needs its prices")
+}
+
+func TestAddInvalidationBatchesByChain(t *testing.T) {
+	logger := zerolog.Nop()
+	c := &PriceCache{instanceID: "pc-self", logger: &logger}
+	batch := make(map[string]*chainInvalidations)
+	add := func(payload string) {
+		c.addInvalidation(batch, &pgconn.Notification{Channel: database.CacheInvalidationChannel, Payload: payload})
+	}
+
+	add(`{"chainSlug":"konzum","storeId":"sto-a"}`)
+	add(`{"chainSlug":"konzum","storeId":"sto-b"}`)
+	add(`{"chainSlug":"konzum","storeId":"sto-a"}`)
+	add(`{"chainSlug":"lidl","storeId":"sto-c"}`)
+	add(`{"chainSlug":"lidl"}`)
+	add(`{"chainSlug":"lidl","storeId":"sto-d"}`)
+	add(`{"chainSlug":"spar","origin":"pc-self"}`)
+	add(`{"storeId":"sto-e"}`)
+	add(`not json`)
+
+	assert.Equal(t, map[string]*chainInvalidations{
+		"konzum": {storeIDs: []string{"sto-a", "sto-b"}},
+		"lidl":   {refresh: true},
+	}, batch, "own invalidations and malformed payloads are skipped")
+}
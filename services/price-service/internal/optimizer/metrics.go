@@ -33,6 +33,12 @@ var (
 		Help: "Total number of cache load errors by chain",
 	}, []string{"chain"})
 
+	// cachePatches tracks invalidations applied to loaded snapshots.
+	cachePatches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "optimizer_cache_patches_total",
+		Help: "Total number of cache invalidations by chain and outcome",
+	}, []string{"chain", "outcome"}) // outcome: patched, reloaded, held, failed
+
 	// optimizationDuration tracks the time taken for optimization calculations.
 	optimizationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
 		Name:    "optimizer_calculation_duration_seconds",
@@ -98,6 +104,44 @@ var (
 		Name: "optimizer_warmup_concurrent_operations",
 		Help: "Number of concurrent warmup operations in progress",
 	})
+
+	// inflightOptimizations tracks optimize requests currently admitted.
+	inflightOptimizations = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "optimizer_inflight_requests",
+		Help: "Number of optimize requests currently being served",
+	})
+
+	// candidatePrefilterSkipped tracks the share of a chain's stores skipped
+	// by the coverage bound before multi-store evaluation.
+	candidatePrefilterSkipped = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "optimizer_candidate_prefilter_skipped_ratio",
+		Help:    "Share of stores skipped before multi-store evaluation",
+		Buckets: []float64{0, 0.1, 0.25, 0.5, 0.75, 0.9, 1.0},
+	})
+
+	// loadShedDecisions tracks requests rejected or degraded by load shedding.
+	loadShedDecisions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "optimizer_load_shed_total",
+		Help: "Total number of optimize requests rejected or degraded by load shedding",
+	}, []string{"action", "reason"}) // action: rejected, greedy_only, stale
+
+	// warmupGateDecisions tracks optimize requests that arrived during warmup.
+	warmupGateDecisions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "optimizer_warmup_gate_total",
+		Help: "Total number of optimize requests held or rejected during cache warmup",
+	}, []string{"outcome"}) // outcome: held, rejected, timed_out
+
+	// circuitBreakerState tracks the state of each circuit breaker.
+	circuitBreakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "optimizer_circuit_breaker_state",
+		Help: "State of the circuit breaker by name (0 closed, 1 open, 2 half-open)",
+	}, []string{"name"})
+
+	// circuitBreakerTransitions tracks circuit breaker state changes.
+	circuitBreakerTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "optimizer_circuit_breaker_transitions_total",
+		Help: "Total number of circuit breaker state changes by name and new state",
+	}, []string{"name", "state"})
 )
 
 // MetricsRecorder provides methods to record optimizer metrics.
@@ -126,6 +170,11 @@ func (m *MetricsRecorder) RecordCacheLoad(chain string, durationSeconds float64,
 	}
 }
 
+// RecordCachePatch records the outcome of applying invalidations to a chain.
+func (m *MetricsRecorder) RecordCachePatch(chain, outcome string) {
+	cachePatches.WithLabelValues(chain, outcome).Inc()
+}
+
 // RecordOptimizationDuration records the duration of an optimization operation.
 func (m *MetricsRecorder) RecordOptimizationDuration(optType string, duration time.Duration) {
 	optimizationDuration.WithLabelValues(optType).Observe(duration.Seconds())
@@ -154,6 +203,14 @@ func (m *MetricsRecorder) RecordCandidateCount(optType string, count int) {
 	candidateCount.WithLabelValues(optType).Observe(float64(count))
 }
 
+// RecordCandidatePrefilter records how many of a chain's stores were skipped
+// before multi-store evaluation.
+func (m *MetricsRecorder) RecordCandidatePrefilter(stores, skipped int) {
+	if stores > 0 {
+		candidatePrefilterSkipped.Observe(float64(skipped) / float64(stores))
+	}
+}
+
 // RecordSnapshotMemory records the memory usage of a chain snapshot.
 func (m *MetricsRecorder) RecordSnapshotMemory(chain string, bytes int64) {
 	snapshotMemoryBytes.WithLabelValues(chain).Set(float64(bytes))
@@ -184,6 +241,27 @@ func (m *MetricsRecorder) DecrementWarmupConcurrency() {
 	warmupConcurrency.Dec()
 }
 
+// RecordWarmupGate records a request held or rejected during warmup.
+func (m *MetricsRecorder) RecordWarmupGate(outcome string) {
+	warmupGateDecisions.WithLabelValues(outcome).Inc()
+}
+
+// RecordLoadShed records a load shedding decision.
+func (m *MetricsRecorder) RecordLoadShed(action, reason string) {
+	loadShedDecisions.WithLabelValues(action, reason).Inc()
+}
+
+// RecordCircuitBreakerState records the current state of a circuit breaker.
+func (m *MetricsRecorder) RecordCircuitBreakerState(name string, state CircuitBreakerState) {
+	circuitBreakerState.WithLabelValues(name).Set(float64(state))
+}
+
+// RecordCircuitBreakerTransition records a circuit breaker state change.
+func (m *MetricsRecorder) RecordCircuitBreakerTransition(name string, state CircuitBreakerState) {
+	circuitBreakerTransitions.WithLabelValues(name, state.String()).Inc()
+	circuitBreakerState.WithLabelValues(name).Set(float64(state))
+}
+
 // ClearChainMetrics clears all metrics for a specific chain.
 // Useful when a chain is removed or cache is cleared.
 func (m *MetricsRecorder) ClearChainMetrics(chain string) {
@@ -14,7 +14,9 @@ import (
 type MultiStoreOptimizer struct {
 	priceSource PriceSource
 	config      *OptimizerConfig
+	penalties   map[string]PenaltyStrategy
 	metrics     *MetricsRecorder
+	clock       func() time.Time
 }
 
 // NewMultiStoreOptimizer creates a new multi-store optimizer.
@@ -25,10 +27,17 @@ func NewMultiStoreOptimizer(priceSource PriceSource, config *OptimizerConfig, me
 	return &MultiStoreOptimizer{
 		priceSource: priceSource,
 		config:      config,
+		penalties:   newPenaltyStrategies(config),
 		metrics:     metrics,
+		clock:       time.Now,
 	}
 }
 
+// SetClock replaces the clock requests without a time are evaluated at.
+func (o *MultiStoreOptimizer) SetClock(clock func() time.Time) {
+	o.clock = clock
+}
+
 // Optimize finds the optimal combination of stores for a basket.
 // It attempts the optimal algorithm first with a timeout, falling back
 // to greedy if the timeout is exceeded or if the problem is too large.
@@ -45,14 +54,20 @@ func (o *MultiStoreOptimizer) Optimize(ctx context.Context, req *OptimizeRequest
 	if err := req.Validate(o.config.MaxBasketItems); err != nil {
 		return nil, fmt.Errorf("invalid request: %w", err)
 	}
+	req = req.atTime(o.clock)
 
 	// Record metrics
 	o.metrics.RecordBasketSize(len(req.BasketItems))
 
-	// Select candidate stores
-	candidates := o.selectCandidates(ctx, req)
+	// Select candidate stores, from every requested chain in cross-chain mode
+	var candidates []*candidateStore
+	if req.crossChain() {
+		candidates = o.selectCandidatesAcrossChains(ctx, req)
+	} else {
+		candidates = o.selectCandidates(ctx, req)
+	}
 	if len(candidates) == 0 {
-		return nil, fmt.Errorf("no candidate stores found for chain %s", req.ChainSlug)
+		return nil, fmt.Errorf("no candidate stores found for chain %s", req.chainLabel())
 	}
 
 	o.metrics.RecordCandidateCount("multi_store", len(candidates))
@@ -61,8 +76,12 @@ func (o *MultiStoreOptimizer) Optimize(ctx context.Context, req *OptimizeRequest
 	var err error
 
 	// Try optimal algorithm for small problems
-	// Only attempt optimal if: basket <= 10 items AND candidates <= 15 stores
-	shouldTryOptimal := len(req.BasketItems) <= 10 && len(candidates) <= 15
+	// Only attempt optimal if: basket <= 20 items AND candidates <= 20 stores
+	// and load shedding has not asked for greedy only
+	shouldTryOptimal := !req.GreedyOnly && len(req.BasketItems) <= optimalMaxBasketItems && len(candidates) <= optimalMaxCandidates
+	if req.GreedyOnly {
+		algorithmUsed = "greedy_load_shed"
+	}
 
 	if shouldTryOptimal {
 		optCtx, cancel := context.WithTimeout(ctx, time.Duration(o.config.OptimalTimeoutMs)*time.Millisecond)
@@ -71,6 +90,8 @@ func (o *MultiStoreOptimizer) Optimize(ctx context.Context, req *OptimizeRequest
 		result, err = o.optimalAlgorithm(optCtx, req, candidates)
 		if err == nil {
 			algorithmUsed = "optimal"
+			result.PenaltyStrategy = o.config.PenaltyStrategyName(req.PenaltyStrategy)
+			o.planVisits(req, result)
 			return result, nil
 		}
 		if err == context.DeadlineExceeded {
@@ -88,6 +109,7 @@ func (o *MultiStoreOptimizer) Optimize(ctx context.Context, req *OptimizeRequest
 	}
 
 	result.AlgorithmUsed = algorithmUsed
+	result.PenaltyStrategy = o.config.PenaltyStrategyName(req.PenaltyStrategy)
 	return result, nil
 }
 
@@ -103,12 +125,37 @@ func (o *MultiStoreOptimizer) selectCandidates(ctx context.Context, req *Optimiz
 		return nil
 	}
 
-	// Evaluate all stores for coverage and price
+	// Nearest stores are candidates whatever their coverage
+	var nearestStores []StoreWithDistance
+	if req.Location != nil {
+		nearestStores = o.priceSource.GetNearestStores(
+			req.ChainSlug,
+			req.Location.Latitude,
+			req.Location.Longitude,
+			o.config.MaxDistanceKm,
+			o.config.TopNearestStores,
+		)
+	}
+	nearestIDs := make(map[string]bool, len(nearestStores))
+	for _, ns := range nearestStores {
+		nearestIDs[ns.StoreID] = true
+	}
+
+	// Evaluate all stores for coverage and price, skipping stores whose
+	// coverage bound rules them out of the cheapest set
+	footprint := o.basketFootprint(req)
 	storeResults := make([]*storeEvaluation, 0, len(allStores))
 	for _, storeID := range allStores {
+		if footprint != nil && !nearestIDs[storeID] {
+			maxRatio := float64(footprint.MaxCoverage(storeID)) / float64(len(req.BasketItems))
+			if CoverageBinFromRatio(maxRatio) < CoverageBinMedium {
+				continue
+			}
+		}
 		eval := o.evaluateStore(ctx, req, storeID)
 		storeResults = append(storeResults, eval)
 	}
+	o.metrics.RecordCandidatePrefilter(len(allStores), len(allStores)-len(storeResults))
 
 	// Sort by total cost (ascending) for cheapest selection
 	sortByCost := func(results []*storeEvaluation) {
@@ -138,14 +185,6 @@ func (o *MultiStoreOptimizer) selectCandidates(ctx context.Context, req *Optimiz
 	// Select top nearest stores (if location provided)
 	nearestSet := make(map[string]*storeEvaluation)
 	if req.Location != nil {
-		nearestStores := o.priceSource.GetNearestStores(
-			req.ChainSlug,
-			req.Location.Latitude,
-			req.Location.Longitude,
-			o.config.MaxDistanceKm,
-			o.config.TopNearestStores,
-		)
-
 		for _, ns := range nearestStores {
 			if len(nearestSet) >= o.config.TopNearestStores {
 				break
@@ -175,6 +214,7 @@ func (o *MultiStoreOptimizer) selectCandidates(ctx context.Context, req *Optimiz
 	for _, eval := range combinedSet {
 		candidates = append(candidates, &candidateStore{
 			storeID:      eval.storeID,
+			chainSlug:    req.ChainSlug,
 			totalCost:    eval.totalCost,
 			coverageBin:  eval.coverageBin,
 			itemPrices:   eval.itemPrices,
@@ -189,6 +229,67 @@ func (o *MultiStoreOptimizer) selectCandidates(ctx context.Context, req *Optimiz
 	return candidates
 }
 
+// selectCandidatesAcrossChains selects candidate stores in each requested
+// chain as selectCandidates does, keyed by the basket's item IDs, and keeps
+// the MaxCandidates best by coverage and cost across all of them.
+func (o *MultiStoreOptimizer) selectCandidatesAcrossChains(ctx context.Context, req *OptimizeRequest) []*candidateStore {
+	var candidates []*candidateStore
+	for _, chainSlug := range req.ChainSlugs {
+		if ctx.Err() != nil {
+			break
+		}
+		chainReq, itemIDs := chainRequest(req, chainSlug)
+		for _, candidate := range o.selectCandidates(ctx, chainReq) {
+			candidate.itemPrices = rekeyItems(candidate.itemPrices, itemIDs)
+			candidate.missingItems = rekeyItems(candidate.missingItems, itemIDs)
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].coverageBin != candidates[j].coverageBin {
+			return candidates[i].coverageBin > candidates[j].coverageBin
+		}
+		return candidates[i].totalCost < candidates[j].totalCost
+	})
+	if len(candidates) > o.config.MaxCandidates {
+		candidates = candidates[:o.config.MaxCandidates]
+	}
+	return candidates
+}
+
+// chainRequest returns a single-chain copy of a cross-chain request with
+// each item's ID in that chain, and a map from those IDs back to the
+// basket's. Items the chain does not sell keep their basket ID, which no
+// store of the chain prices.
+func chainRequest(req *OptimizeRequest, chainSlug string) (*OptimizeRequest, map[string]string) {
+	r := *req
+	r.ChainSlug = chainSlug
+	r.ChainSlugs = nil
+	r.BasketItems = make([]*BasketItem, len(req.BasketItems))
+	itemIDs := make(map[string]string, len(req.BasketItems))
+	for i, item := range req.BasketItems {
+		chainItem := *item
+		chainItem.ChainItemIDs = nil
+		if id, ok := item.itemIDIn(chainSlug); ok {
+			chainItem.ItemID = id
+		}
+		r.BasketItems[i] = &chainItem
+		itemIDs[chainItem.ItemID] = item.ItemID
+	}
+	return &r, itemIDs
+}
+
+// rekeyItems returns a map keyed by the basket item IDs that itemIDs maps
+// a chain's item IDs to
+func rekeyItems[T any](byChainID map[string]T, itemIDs map[string]string) map[string]T {
+	byItemID := make(map[string]T, len(byChainID))
+	for id, v := range byChainID {
+		byItemID[itemIDs[id]] = v
+	}
+	return byItemID
+}
+
 // greedyAlgorithm implements a greedy approach to multi-store optimization.
 // For each item, it assigns it to the store with the lowest effective price.
 // Then it runs a coverage post-pass to assign any remaining items.
@@ -244,89 +345,36 @@ func (o *MultiStoreOptimizer) greedyAlgorithm(ctx context.Context, req *Optimize
 	// Coverage post-pass: try to assign remaining items to any store
 	unassigned := o.runCoveragePostPass(ctx, req, candidates, storeItems, assigned)
 
+	// Drop stores not worth the trip
+	o.consolidateStores(candidates, storeItems, o.newTravelModel(req, candidates))
+
 	// Build result
 	return o.buildResult(req, candidates, storeItems, unassigned)
 }
 
-// optimalAlgorithm implements the optimal solution using exhaustive search.
-// It tries all combinations of up to 3 stores to find the absolute best solution.
-// This is computationally expensive and should only be used for small problems.
+// optimalAlgorithm finds the best combination of up to MaxStores candidates
+// (3 when unset): the best coverage at the lowest basket total plus travel
+// cost. Combinations are searched by branch and bound, so it is exact but
+// exponential in the worst case and only used for small problems; the
+// context's deadline cuts it short.
 func (o *MultiStoreOptimizer) optimalAlgorithm(ctx context.Context, req *OptimizeRequest, candidates []*candidateStore) (*MultiStoreResult, error) {
-	// For small problems, we can try all combinations of 1-3 stores
-	// This is still exponential but manageable for N=15 candidates
-
-	bestResult := &MultiStoreResult{
-		CombinedTotal: -1, // -1 indicates uninitialized
-		CoverageRatio: -1, // -1 indicates uninitialized
-	}
-
-	// Helper function to check if result is better than best
-	isBetter := func(result, best *MultiStoreResult) bool {
-		if best.CoverageRatio < 0 {
-			return true
-		}
-		// Coverage-first ranking
-		if result.CoverageRatio != best.CoverageRatio {
-			return result.CoverageRatio > best.CoverageRatio
-		}
-		// Same coverage, lower cost is better
-		return result.CombinedTotal < best.CombinedTotal
-	}
-
-	// Try single stores first (should match single-store optimizer)
-	for _, candidate := range candidates {
-		if ctx.Err() != nil {
-			return nil, ctx.Err()
-		}
-
-		result := o.evaluateStoreCombination(ctx, req, candidates, []*candidateStore{candidate})
-		if result != nil && isBetter(result, bestResult) {
-			bestResult = result
-		}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no valid store combination found")
 	}
-
-	// Try pairs
-	for i := 0; i < len(candidates); i++ {
-		for j := i + 1; j < len(candidates); j++ {
-			if ctx.Err() != nil {
-				return nil, ctx.Err()
-			}
-
-			result := o.evaluateStoreCombination(ctx, req, candidates, []*candidateStore{
-				candidates[i],
-				candidates[j],
-			})
-			if result != nil && isBetter(result, bestResult) {
-				bestResult = result
-			}
-		}
+	maxStores := req.MaxStores
+	if maxStores <= 0 {
+		maxStores = defaultOptimalMaxStores
 	}
 
-	// Try triplets
-	for i := 0; i < len(candidates); i++ {
-		for j := i + 1; j < len(candidates); j++ {
-			for k := j + 1; k < len(candidates); k++ {
-				if ctx.Err() != nil {
-					return nil, ctx.Err()
-				}
-
-				result := o.evaluateStoreCombination(ctx, req, candidates, []*candidateStore{
-					candidates[i],
-					candidates[j],
-					candidates[k],
-				})
-				if result != nil && isBetter(result, bestResult) {
-					bestResult = result
-				}
-			}
-		}
+	search := newStoreSearch(ctx, req, candidates, maxStores, o.newTravelModel(req, candidates))
+	selected, err := search.run()
+	if err != nil {
+		return nil, err
 	}
-
-	if bestResult.CombinedTotal < 0 {
+	if len(selected) == 0 {
 		return nil, fmt.Errorf("no valid store combination found")
 	}
-
-	return bestResult, nil
+	return o.evaluateStoreCombination(ctx, req, candidates, selected), nil
 }
 
 // evaluateStoreCombination evaluates a specific combination of stores
@@ -400,17 +448,20 @@ func (o *MultiStoreOptimizer) evaluateStoreCombination(
 			priceInfos = append(priceInfos, item.PriceInfo)
 		}
 
-		// Find distance
+		// Find distance and chain
 		distance := 0.0
+		chainSlug := ""
 		for _, store := range selectedStores {
 			if store.storeID == storeID {
 				distance = store.distance
+				chainSlug = store.chainSlug
 				break
 			}
 		}
 
 		result.Stores = append(result.Stores, &StoreAllocation{
 			StoreID:    storeID,
+			ChainSlug:  chainSlug,
 			Items:      priceInfos,
 			StoreTotal: storeTotal,
 			Distance:   distance,
@@ -457,7 +508,7 @@ func (o *MultiStoreOptimizer) runCoveragePostPass(
 		// Still unassigned after post-pass
 		if !assigned[basketItem.ItemID] {
 			// Calculate penalty for missing item
-			penalty := o.calculatePenalty(ctx, req.ChainSlug, basketItem.ItemID)
+			penalty := o.calculatePenalty(ctx, req, basketItem.ItemID)
 			unassigned = append(unassigned, &MissingItem{
 				ItemID:     basketItem.ItemID,
 				ItemName:   basketItem.Name,
@@ -497,17 +548,20 @@ func (o *MultiStoreOptimizer) buildResult(
 			priceInfos = append(priceInfos, item.PriceInfo)
 		}
 
-		// Find distance
+		// Find distance and chain
 		distance := 0.0
+		chainSlug := ""
 		for _, candidate := range candidates {
 			if candidate.storeID == storeID {
 				distance = candidate.distance
+				chainSlug = candidate.chainSlug
 				break
 			}
 		}
 
 		result.Stores = append(result.Stores, &StoreAllocation{
 			StoreID:    storeID,
+			ChainSlug:  chainSlug,
 			Items:      priceInfos,
 			StoreTotal: storeTotal,
 			Distance:   distance,
@@ -517,15 +571,7 @@ func (o *MultiStoreOptimizer) buildResult(
 	result.CombinedTotal = combinedTotal
 	result.CoverageRatio = float64(assignedCount) / float64(len(req.BasketItems))
 
-	// Sort stores by visit order (could be optimized by TSP in the future)
-	sort.Slice(result.Stores, func(i, j int) bool {
-		return result.Stores[i].Distance < result.Stores[j].Distance
-	})
-
-	// Set visit order
-	for i, store := range result.Stores {
-		store.VisitOrder = i + 1
-	}
+	o.planVisits(req, result)
 
 	return result, nil
 }
@@ -553,7 +599,7 @@ func (o *MultiStoreOptimizer) getUnassignedItems(
 			penalty := int64(0)
 			if !availableAt {
 				// Item not available at any candidate - use full penalty
-				penalty = o.calculatePenalty(context.Background(), req.ChainSlug, basketItem.ItemID)
+				penalty = o.calculatePenalty(context.Background(), req, basketItem.ItemID)
 			}
 
 			unassigned = append(unassigned, &MissingItem{
@@ -583,7 +629,7 @@ func (o *MultiStoreOptimizer) evaluateStore(ctx context.Context, req *OptimizeRe
 		price, ok := o.priceSource.GetPrice(req.ChainSlug, storeID, item.ItemID)
 		if !ok {
 			// Item not available
-			penalty := o.calculatePenalty(ctx, req.ChainSlug, item.ItemID)
+			penalty := o.calculatePenalty(ctx, req, item.ItemID)
 			eval.missingItems[item.ItemID] = &MissingItem{
 				ItemID:     item.ItemID,
 				ItemName:   item.Name,
@@ -596,7 +642,8 @@ func (o *MultiStoreOptimizer) evaluateStore(ctx context.Context, req *OptimizeRe
 
 		// Item available
 		availableCount++
-		effectivePrice := GetEffectivePrice(price)
+		effectivePrice := GetEffectivePrice(price, req.At)
+		hasDiscount := price.DiscountActive(req.At)
 		lineTotal := effectivePrice * int64(item.Quantity)
 		totalCost += lineTotal
 
@@ -606,11 +653,11 @@ func (o *MultiStoreOptimizer) evaluateStore(ctx context.Context, req *OptimizeRe
 			Quantity:       item.Quantity,
 			BasePrice:      price.Price,
 			EffectivePrice: effectivePrice,
-			HasDiscount:    price.HasDiscount,
+			HasDiscount:    hasDiscount,
 			LineTotal:      lineTotal,
 		}
 
-		if price.HasDiscount {
+		if hasDiscount {
 			eval.itemPrices[item.ItemID].DiscountPrice = &price.DiscountPrice
 		}
 	}
@@ -622,13 +669,44 @@ func (o *MultiStoreOptimizer) evaluateStore(ctx context.Context, req *OptimizeRe
 	return eval
 }
 
-// calculatePenalty computes the penalty for a missing item using chain average.
-func (o *MultiStoreOptimizer) calculatePenalty(ctx context.Context, chainSlug, itemID string) int64 {
-	avgPrice := o.priceSource.GetAveragePrice(chainSlug, itemID)
-	if avgPrice == 0 {
-		return o.config.MissingItemFallback
+// calculatePenalty computes the penalty for a missing item with the
+// request's penalty strategy. Cross-chain requests price it in the first
+// listed chain that sells it.
+func (o *MultiStoreOptimizer) calculatePenalty(ctx context.Context, req *OptimizeRequest, itemID string) int64 {
+	if !req.crossChain() {
+		return missingItemPenalty(o.priceSource, o.config, o.penalties, req, itemID)
+	}
+
+	chainReq := *req
+	chainReq.ChainSlug = req.ChainSlugs[0]
+	for _, item := range req.BasketItems {
+		if item.ItemID != itemID {
+			continue
+		}
+		for _, chainSlug := range req.ChainSlugs {
+			if chainItemID, ok := item.itemIDIn(chainSlug); ok {
+				chainReq.ChainSlug = chainSlug
+				itemID = chainItemID
+				break
+			}
+		}
+		break
+	}
+	return missingItemPenalty(o.priceSource, o.config, o.penalties, &chainReq, itemID)
+}
+
+// basketFootprint returns the coverage bound for the request's basket, or nil
+// when the price source cannot provide one.
+func (o *MultiStoreOptimizer) basketFootprint(req *OptimizeRequest) *BasketFootprint {
+	footprinter, ok := o.priceSource.(BasketFootprinter)
+	if !ok {
+		return nil
+	}
+	itemIDs := make([]string, len(req.BasketItems))
+	for i, item := range req.BasketItems {
+		itemIDs[i] = item.ItemID
 	}
-	return int64(float64(avgPrice) * o.config.MissingItemPenaltyMult)
+	return footprinter.BasketFootprint(req.ChainSlug, itemIDs)
 }
 
 // getAllStoreIDs returns all store IDs for a chain.
@@ -639,6 +717,7 @@ func (o *MultiStoreOptimizer) getAllStoreIDs(ctx context.Context, chainSlug stri
 // candidateStore represents a store candidate for multi-store optimization.
 type candidateStore struct {
 	storeID      string
+	chainSlug    string
 	totalCost    int64
 	coverageBin  int
 	itemPrices   map[string]*ItemPriceInfo
@@ -574,7 +574,7 @@ func TestCalculatePenaltyFallback(t *testing.T) {
 	optimizer := NewMultiStoreOptimizer(mock, config, metrics)
 
 	// No average price set for item-999
-	penalty := optimizer.calculatePenalty(ctx, "test-chain", "item-999")
+	penalty := optimizer.calculatePenalty(ctx, &OptimizeRequest{ChainSlug: "test-chain"}, "item-999")
 
 	// Should use fallback
 	assert.Equal(t, config.MissingItemFallback, penalty)
@@ -645,7 +645,7 @@ func createCandidatesFromMock(mock *mockPriceSource, storeIDs []string, req *Opt
 		for _, item := range req.BasketItems {
 			price, ok := mock.GetPrice(req.ChainSlug, storeID, item.ItemID)
 			if ok {
-				effectivePrice := GetEffectivePrice(price)
+				effectivePrice := GetEffectivePrice(price, req.At)
 				lineTotal := effectivePrice * int64(item.Quantity)
 				totalCost += lineTotal
 				availableCount++
@@ -779,3 +779,83 @@ func TestMultiStoreDiscountHandling(t *testing.T) {
 	assert.NotNil(t, result.Stores[0].Items[0].DiscountPrice)
 	assert.Equal(t, int64(80), *result.Stores[0].Items[0].DiscountPrice)
 }
+
+// TestMultiStoreCrossChain verifies stores of several chains are combined,
+// with each item priced under its ID in the store's chain.
+func TestMultiStoreCrossChain(t *testing.T) {
+	ctx := context.Background()
+	mock := newMockPriceSource()
+	optimizer := NewMultiStoreOptimizer(mock, DefaultOptimizerConfig(), NewMetricsRecorder())
+
+	// Milk is cheaper at Lidl, bread and coffee at Konzum
+	mock.setPrice("lidl", "lidl-1", "lidl-milk", 80, nil)
+	mock.setPrice("lidl", "lidl-1", "lidl-bread", 150, nil)
+	mock.setPrice("lidl", "lidl-1", "lidl-coffee", 600, nil)
+	mock.setPrice("konzum", "konzum-1", "konzum-milk", 100, nil)
+	mock.setPrice("konzum", "konzum-1", "konzum-bread", 120, nil)
+	mock.setPrice("konzum", "konzum-1", "konzum-coffee", 500, nil)
+
+	req := &OptimizeRequest{
+		ChainSlugs: []string{"lidl", "konzum"},
+		BasketItems: []*BasketItem{
+			{ItemID: "lidl-milk", Name: "Milk", Quantity: 1, ChainItemIDs: map[string]string{"lidl": "lidl-milk", "konzum": "konzum-milk"}},
+			{ItemID: "lidl-bread", Name: "Bread", Quantity: 1, ChainItemIDs: map[string]string{"lidl": "lidl-bread", "konzum": "konzum-bread"}},
+			{ItemID: "lidl-coffee", Name: "Coffee", Quantity: 1, ChainItemIDs: map[string]string{"lidl": "lidl-coffee", "konzum": "konzum-coffee"}},
+		},
+	}
+
+	result, err := optimizer.Optimize(ctx, req)
+	require.NoError(t, err)
+
+	assert.Empty(t, result.UnassignedItems)
+	assert.Equal(t, int64(80+120+500), result.CombinedTotal)
+
+	chains := make(map[string]string)
+	for _, store := range result.Stores {
+		chains[store.StoreID] = store.ChainSlug
+	}
+	assert.Equal(t, map[string]string{"lidl-1": "lidl", "konzum-1": "konzum"}, chains)
+}
+
+// TestMultiStoreCrossChainSharedIDs verifies items without per-chain IDs are
+// looked up under their own ID in every chain.
+func TestMultiStoreCrossChainSharedIDs(t *testing.T) {
+	ctx := context.Background()
+	mock := newMockPriceSource()
+	optimizer := NewMultiStoreOptimizer(mock, DefaultOptimizerConfig(), NewMetricsRecorder())
+
+	mock.setPrice("lidl", "lidl-1", "item-1", 90, nil)
+	mock.setPrice("konzum", "konzum-1", "item-1", 70, nil)
+
+	req := &OptimizeRequest{
+		ChainSlugs:  []string{"lidl", "konzum"},
+		BasketItems: []*BasketItem{{ItemID: "item-1", Name: "Item 1", Quantity: 2}},
+	}
+
+	result, err := optimizer.Optimize(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, result.Stores, 1)
+	assert.Equal(t, "konzum-1", result.Stores[0].StoreID)
+	assert.Equal(t, "konzum", result.Stores[0].ChainSlug)
+	assert.Equal(t, int64(140), result.CombinedTotal)
+}
+
+// TestChainRequestItemIDs verifies a chain's copy of a cross-chain request
+// carries the chain's item IDs and maps them back to the basket's.
+func TestChainRequestItemIDs(t *testing.T) {
+	req := &OptimizeRequest{
+		ChainSlugs: []string{"lidl", "konzum"},
+		BasketItems: []*BasketItem{
+			{ItemID: "lidl-milk", Quantity: 1, ChainItemIDs: map[string]string{"lidl": "lidl-milk", "konzum": "konzum-milk"}},
+			{ItemID: "lidl-bread", Quantity: 1, ChainItemIDs: map[string]string{"lidl": "lidl-bread"}},
+		},
+	}
+
+	chainReq, itemIDs := chainRequest(req, "konzum")
+	assert.Equal(t, "konzum", chainReq.ChainSlug)
+	assert.Empty(t, chainReq.ChainSlugs)
+	assert.Equal(t, "konzum-milk", chainReq.BasketItems[0].ItemID)
+	assert.Equal(t, "lidl-bread", chainReq.BasketItems[1].ItemID, "items the chain does not sell keep their ID")
+	assert.Equal(t, map[string]string{"konzum-milk": "lidl-milk", "lidl-bread": "lidl-bread"}, itemIDs)
+	assert.Equal(t, "lidl-milk", req.BasketItems[0].ItemID, "request must not change")
+}
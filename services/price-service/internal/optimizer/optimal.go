@@ -0,0 +1,236 @@
+package optimizer
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+)
+
+const (
+	// defaultOptimalMaxStores is the most stores the optimal algorithm
+	// combines when the request sets no limit
+	defaultOptimalMaxStores = 3
+
+	// The largest problems the optimal algorithm is tried on; larger ones go
+	// to greedy directly
+	optimalMaxBasketItems = 20
+	optimalMaxCandidates  = 20
+
+	// searchCheckEvery is how many search nodes are visited between checks
+	// of the context
+	searchCheckEvery = 1024
+)
+
+// storeSearch finds the best combination of up to maxStores candidates: the
+// one covering the most basket items, then costing the least with travel.
+// It searches combinations depth first, adding candidates in order, and skips
+// every extension of a combination that bounds show cannot beat the best
+// found so far.
+type storeSearch struct {
+	ctx        context.Context
+	candidates []*candidateStore // In search order, most coverage first
+	maxStores  int
+	model      *travelModel
+
+	lines     [][]int64 // Line total of each item at each candidate; -1 when not carried
+	suffixMin [][]int64 // Cheapest line of each item at candidates from an index on; -1 when none
+	distances []float64 // Distance from the request's location to each candidate, when priced
+	nodes     int
+
+	best        []int // Indexes of the stores of the best combination
+	bestCovered int
+	bestCost    int64
+}
+
+// newStoreSearch prepares the search of a request's candidates
+func newStoreSearch(ctx context.Context, req *OptimizeRequest, candidates []*candidateStore, maxStores int, model *travelModel) *storeSearch {
+	ordered := slices.Clone(candidates)
+	slices.SortStableFunc(ordered, func(a, b *candidateStore) int {
+		return cmp.Or(cmp.Compare(len(b.itemPrices), len(a.itemPrices)), cmp.Compare(a.totalCost, b.totalCost))
+	})
+
+	s := &storeSearch{
+		ctx:         ctx,
+		candidates:  ordered,
+		maxStores:   maxStores,
+		model:       model,
+		lines:       make([][]int64, len(ordered)),
+		suffixMin:   make([][]int64, len(ordered)+1),
+		distances:   make([]float64, len(ordered)),
+		bestCovered: -1,
+	}
+	for c, candidate := range ordered {
+		s.lines[c] = make([]int64, len(req.BasketItems))
+		for i, item := range req.BasketItems {
+			s.lines[c][i] = -1
+			if priceInfo, ok := candidate.itemPrices[item.ItemID]; ok {
+				s.lines[c][i] = priceInfo.EffectivePrice * int64(item.Quantity)
+			}
+		}
+		if location, ok := model.locations[candidate.storeID]; ok && model.origin != nil {
+			s.distances[c] = HaversineKm(model.origin.Latitude, model.origin.Longitude, location.Latitude, location.Longitude)
+		}
+	}
+
+	s.suffixMin[len(ordered)] = slices.Repeat([]int64{-1}, len(req.BasketItems))
+	for c := len(ordered) - 1; c >= 0; c-- {
+		s.suffixMin[c] = slices.Clone(s.suffixMin[c+1])
+		for i, line := range s.lines[c] {
+			if line >= 0 && (s.suffixMin[c][i] < 0 || line < s.suffixMin[c][i]) {
+				s.suffixMin[c][i] = line
+			}
+		}
+	}
+	return s
+}
+
+// run searches every combination and returns the candidates of the best, or
+// the context's error when it is done first
+func (s *storeSearch) run() ([]*candidateStore, error) {
+	current := slices.Repeat([]int64{-1}, len(s.suffixMin[0]))
+	if err := s.search(0, nil, current, 0); err != nil {
+		return nil, err
+	}
+
+	selected := make([]*candidateStore, len(s.best))
+	for i, c := range s.best {
+		selected[i] = s.candidates[c]
+	}
+	return selected, nil
+}
+
+// search extends the combination chosen, whose cheapest line of each item is
+// current and which covers covered items, with candidates from next on
+func (s *storeSearch) search(next int, chosen []int, current []int64, covered int) error {
+	s.nodes++
+	if s.nodes%searchCheckEvery == 1 {
+		if err := s.ctx.Err(); err != nil {
+			return err
+		}
+	}
+	if len(chosen) > 0 {
+		s.consider(chosen, current, covered)
+	}
+	if len(chosen) == s.maxStores {
+		return nil
+	}
+
+	extended := make([]int64, len(current))
+	for c := next; c < len(s.candidates); c++ {
+		copy(extended, current)
+		extendedCovered := covered
+		for i, line := range s.lines[c] {
+			if line < 0 {
+				continue
+			}
+			if extended[i] < 0 {
+				extendedCovered++
+				extended[i] = line
+			} else if line < extended[i] {
+				extended[i] = line
+			}
+		}
+		if extendedCovered == covered && len(chosen) > 0 {
+			// Adds no coverage, so at best cheaper lines; still worth a look
+			// unless it lowers no line either
+			if slices.Equal(extended, current) {
+				continue
+			}
+		}
+
+		withC := append(slices.Clip(chosen), c)
+		if !s.promising(withC, extended, extendedCovered, c+1) {
+			continue
+		}
+		if err := s.search(c+1, withC, slices.Clone(extended), extendedCovered); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// consider keeps a combination if it beats the best. Its cost counts only the
+// stores that sell it an item, as a result of the combination would.
+func (s *storeSearch) consider(chosen []int, current []int64, covered int) {
+	used := make([]int, 0, len(chosen))
+	total := int64(0)
+	for i, line := range current {
+		if line < 0 {
+			continue
+		}
+		total += line
+		for _, c := range chosen {
+			if s.lines[c][i] == line {
+				if !slices.Contains(used, c) {
+					used = append(used, c)
+				}
+				break
+			}
+		}
+	}
+
+	storeIDs := make([]string, len(used))
+	for i, c := range used {
+		storeIDs[i] = s.candidates[c].storeID
+	}
+	cost := total + s.model.cost(storeIDs).Total
+
+	if covered > s.bestCovered || covered == s.bestCovered && cost < s.bestCost {
+		slices.Sort(used)
+		s.best, s.bestCovered, s.bestCost = used, covered, cost
+	}
+}
+
+// promising reports whether some extension of a combination with candidates
+// from next on could beat the best. It bounds the items they could cover by
+// those any of them sells, and their cost by the cheapest lines of the items
+// to cover plus the travel to the stores already chosen.
+func (s *storeSearch) promising(chosen []int, current []int64, covered int, next int) bool {
+	if s.bestCovered < 0 {
+		return true
+	}
+	canAdd := len(chosen) < s.maxStores
+
+	var reachable []int64 // Cheapest lines of the uncovered items still reachable
+	lowerBound := int64(0)
+	for i, line := range current {
+		cheapest := s.suffixMin[next][i]
+		if !canAdd {
+			cheapest = -1
+		}
+		switch {
+		case line >= 0 && cheapest >= 0:
+			lowerBound += min(line, cheapest)
+		case line >= 0:
+			lowerBound += line
+		case cheapest >= 0:
+			reachable = append(reachable, cheapest)
+		}
+	}
+	if maxCovered := covered + len(reachable); maxCovered != s.bestCovered {
+		return maxCovered > s.bestCovered
+	}
+
+	// Matching the best's coverage means covering all the reachable items
+	for _, line := range reachable {
+		lowerBound += line
+	}
+	return lowerBound+s.travelLowerBound(chosen) < s.bestCost
+}
+
+// travelLowerBound returns at most the travel cost of any combination using
+// the chosen stores: the extra stores among them, and a round trip to the
+// farthest of them. Trips are planned through every store, so they are at
+// least that long.
+func (s *storeSearch) travelLowerBound(chosen []int) int64 {
+	bound := s.model.perExtraStore * int64(len(chosen)-1)
+	farthest := 0.0
+	for _, c := range chosen {
+		farthest = max(farthest, s.distances[c])
+	}
+	if farthest > 0 {
+		bound += int64(math.Floor(2*farthest*float64(s.model.perKm) - 1e-6))
+	}
+	return max(bound, 0)
+}
@@ -0,0 +1,169 @@
+package optimizer
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// randomMultiStoreProblem prices a random basket at stores that each carry
+// part of it, around a location in Zagreb
+func randomMultiStoreProblem(rng *rand.Rand, stores, items int) (*mockPriceSource, *OptimizeRequest, []string) {
+	mock := newMockPriceSource()
+	req := &OptimizeRequest{
+		ChainSlug: "test-chain",
+		Location:  &Location{Latitude: 45.81, Longitude: 15.98},
+	}
+	for i := range items {
+		req.BasketItems = append(req.BasketItems, &BasketItem{
+			ItemID:   fmt.Sprintf("item-%03d", i),
+			Name:     fmt.Sprintf("Item %d", i),
+			Quantity: 1 + rng.IntN(3),
+		})
+	}
+
+	storeIDs := make([]string, stores)
+	mock.storeLocations["test-chain"] = make(map[string]Location)
+	for s := range stores {
+		storeIDs[s] = fmt.Sprintf("store-%02d", s)
+		mock.storeLocations["test-chain"][storeIDs[s]] = Location{
+			Latitude:  45.81 + rng.NormFloat64()*0.03,
+			Longitude: 15.98 + rng.NormFloat64()*0.04,
+		}
+		for _, item := range req.BasketItems {
+			if rng.Float64() < 0.6 {
+				mock.setPrice("test-chain", storeIDs[s], item.ItemID, 100+rng.IntN(200), nil)
+			}
+		}
+	}
+	return mock, req, storeIDs
+}
+
+// bruteForceBest evaluates every combination of up to maxStores candidates
+// and returns the best coverage and its lowest cost with travel
+func bruteForceBest(ctx context.Context, o *MultiStoreOptimizer, req *OptimizeRequest, candidates []*candidateStore, maxStores int) (float64, int64) {
+	model := o.newTravelModel(req, candidates)
+	bestCoverage, bestCost := -1.0, int64(0)
+	var visit func(next int, selected []*candidateStore)
+	visit = func(next int, selected []*candidateStore) {
+		if len(selected) > 0 {
+			result := o.evaluateStoreCombination(ctx, req, candidates, selected)
+			cost := result.CombinedTotal + model.cost(resultStoreIDs(result)).Total
+			if result.CoverageRatio > bestCoverage || result.CoverageRatio == bestCoverage && cost < bestCost {
+				bestCoverage, bestCost = result.CoverageRatio, cost
+			}
+		}
+		if len(selected) == maxStores {
+			return
+		}
+		for c := next; c < len(candidates); c++ {
+			visit(c+1, append(selected[:len(selected):len(selected)], candidates[c]))
+		}
+	}
+	visit(0, nil)
+	return bestCoverage, bestCost
+}
+
+// TestOptimalAlgorithmMatchesBruteForce verifies the search finds the best
+// combination of every one, with and without travel costs.
+func TestOptimalAlgorithmMatchesBruteForce(t *testing.T) {
+	ctx := context.Background()
+	rng := rand.New(rand.NewPCG(7, 7))
+
+	for n := range 30 {
+		mock, req, storeIDs := randomMultiStoreProblem(rng, 8+rng.IntN(7), 5+rng.IntN(16))
+		req.MaxStores = 2 + rng.IntN(4)
+		candidates := createCandidatesFromMock(mock, storeIDs, req)
+		for _, candidate := range candidates {
+			candidate.chainSlug = "test-chain"
+		}
+
+		config := DefaultOptimizerConfig()
+		if n%2 == 1 {
+			config.ExtraStoreCost = int64(rng.IntN(150))
+			config.TravelCostPerKm = int64(rng.IntN(40))
+		}
+		o := NewMultiStoreOptimizer(mock, config, NewMetricsRecorder())
+
+		result, err := o.optimalAlgorithm(ctx, req, candidates)
+		require.NoError(t, err)
+		cost := result.CombinedTotal + o.newTravelModel(req, candidates).cost(resultStoreIDs(result)).Total
+		coverage, bestCost := bruteForceBest(ctx, o, req, candidates, req.MaxStores)
+
+		name := fmt.Sprintf("problem %d: %d stores, %d items, up to %d", n, len(candidates), len(req.BasketItems), req.MaxStores)
+		assert.Equal(t, coverage, result.CoverageRatio, name)
+		assert.Equal(t, bestCost, cost, name)
+		assert.LessOrEqual(t, len(result.Stores), req.MaxStores, name)
+	}
+}
+
+// TestOptimalAlgorithmMaxStores verifies combinations go beyond three stores
+// when the request allows.
+func TestOptimalAlgorithmMaxStores(t *testing.T) {
+	ctx := context.Background()
+	mock := newMockPriceSource()
+	req := &OptimizeRequest{ChainSlug: "test-chain"}
+	var storeIDs []string
+	for i := range 4 {
+		itemID, storeID := fmt.Sprintf("item-%03d", i), fmt.Sprintf("store-%d", i)
+		mock.setPrice("test-chain", storeID, itemID, 100, nil)
+		req.BasketItems = append(req.BasketItems, &BasketItem{ItemID: itemID, Name: itemID, Quantity: 1})
+		storeIDs = append(storeIDs, storeID)
+	}
+	candidates := createCandidatesFromMock(mock, storeIDs, req)
+	o := NewMultiStoreOptimizer(mock, DefaultOptimizerConfig(), NewMetricsRecorder())
+
+	result, err := o.optimalAlgorithm(ctx, req, candidates)
+	require.NoError(t, err)
+	assert.Equal(t, 0.75, result.CoverageRatio, "three stores when unset")
+
+	req.MaxStores = 4
+	result, err = o.optimalAlgorithm(ctx, req, candidates)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, result.CoverageRatio)
+	assert.Len(t, result.Stores, 4)
+}
+
+func TestOptimalAlgorithmCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	mock, req, storeIDs := randomMultiStoreProblem(rand.New(rand.NewPCG(1, 1)), 10, 10)
+	candidates := createCandidatesFromMock(mock, storeIDs, req)
+	o := NewMultiStoreOptimizer(mock, DefaultOptimizerConfig(), NewMetricsRecorder())
+
+	_, err := o.optimalAlgorithm(ctx, req, candidates)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// BenchmarkOptimalAlgorithm measures the search at the largest problems it
+// is tried on.
+func BenchmarkOptimalAlgorithm(b *testing.B) {
+	ctx := context.Background()
+	for _, maxStores := range []int{3, 5} {
+		for _, travel := range []bool{false, true} {
+			mock, req, storeIDs := randomMultiStoreProblem(rand.New(rand.NewPCG(3, 3)), optimalMaxCandidates, optimalMaxBasketItems)
+			req.MaxStores = maxStores
+			candidates := createCandidatesFromMock(mock, storeIDs, req)
+			for _, candidate := range candidates {
+				candidate.chainSlug = "test-chain"
+			}
+			config := DefaultOptimizerConfig()
+			if travel {
+				config.ExtraStoreCost, config.TravelCostPerKm = 100, 20
+			}
+			o := NewMultiStoreOptimizer(mock, config, NewMetricsRecorder())
+
+			b.Run(fmt.Sprintf("stores%d/travel=%t", maxStores, travel), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if _, err := o.optimalAlgorithm(ctx, req, candidates); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
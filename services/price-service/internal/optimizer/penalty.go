@@ -0,0 +1,143 @@
+package optimizer
+
+// Penalty strategy names, used in OptimizerConfig and as per-request overrides.
+const (
+	PenaltyStrategyMean            = "mean"             // multiplier x store-weighted mean
+	PenaltyStrategySimpleMean      = "simple_mean"      // multiplier x unweighted mean over price groups
+	PenaltyStrategyMedian          = "median"           // multiplier x median
+	PenaltyStrategyMax             = "max"              // multiplier x highest group price
+	PenaltyStrategyCategoryAverage = "category_average" // multiplier x average price of the item's category
+	PenaltyStrategyCategoryTable   = "category_table"   // fixed amount per category
+)
+
+// PenaltyStrategyNames lists the known penalty strategies.
+var PenaltyStrategyNames = []string{
+	PenaltyStrategyMean,
+	PenaltyStrategySimpleMean,
+	PenaltyStrategyMedian,
+	PenaltyStrategyMax,
+	PenaltyStrategyCategoryAverage,
+	PenaltyStrategyCategoryTable,
+}
+
+// PenaltyStrategy prices an item that is missing at a store, so stores with
+// gaps sort behind stores that cover the basket.
+type PenaltyStrategy interface {
+	// Name identifies the strategy in configuration, requests and results.
+	Name() string
+
+	// Penalty returns the penalty for the item, or false when the strategy
+	// has no basis for one and MissingItemFallback applies instead.
+	Penalty(source PriceSource, chainSlug, itemID string) (int64, bool)
+}
+
+// IsPenaltyStrategy reports whether name is a known penalty strategy.
+func IsPenaltyStrategy(name string) bool {
+	for _, known := range PenaltyStrategyNames {
+		if name == known {
+			return true
+		}
+	}
+	return false
+}
+
+// newPenaltyStrategies builds every known strategy from the configuration.
+func newPenaltyStrategies(config *OptimizerConfig) map[string]PenaltyStrategy {
+	mult := config.MissingItemPenaltyMult
+	strategies := []PenaltyStrategy{
+		statPenalty{name: PenaltyStrategyMean, mult: mult, stat: func(s ItemPriceStats) int64 { return s.WeightedAverage }},
+		statPenalty{name: PenaltyStrategySimpleMean, mult: mult, stat: func(s ItemPriceStats) int64 { return s.Average }},
+		statPenalty{name: PenaltyStrategyMedian, mult: mult, stat: func(s ItemPriceStats) int64 { return s.Median }},
+		statPenalty{name: PenaltyStrategyMax, mult: mult, stat: func(s ItemPriceStats) int64 { return s.Max }},
+		categoryAveragePenalty{mult: mult},
+		categoryTablePenalty{table: config.MissingItemCategoryPenalties},
+	}
+
+	byName := make(map[string]PenaltyStrategy, len(strategies))
+	for _, s := range strategies {
+		byName[s.Name()] = s
+	}
+	return byName
+}
+
+// PenaltyStrategyName returns the strategy a request uses: its override when
+// set, otherwise the configured default.
+func (c *OptimizerConfig) PenaltyStrategyName(override string) string {
+	if override != "" {
+		return override
+	}
+	if c.MissingItemPenaltyStrategy != "" {
+		return c.MissingItemPenaltyStrategy
+	}
+	return PenaltyStrategyMean
+}
+
+// missingItemPenalty computes the penalty for an item missing at a store with
+// the request's strategy, or MissingItemFallback when it has no basis.
+func missingItemPenalty(source PriceSource, config *OptimizerConfig, strategies map[string]PenaltyStrategy, req *OptimizeRequest, itemID string) int64 {
+	strategy, ok := strategies[config.PenaltyStrategyName(req.PenaltyStrategy)]
+	if !ok {
+		return config.MissingItemFallback
+	}
+	penalty, ok := strategy.Penalty(source, req.ChainSlug, itemID)
+	if !ok || penalty == 0 {
+		return config.MissingItemFallback
+	}
+	return penalty
+}
+
+// statPenalty multiplies one of the item's chain-wide price statistics.
+type statPenalty struct {
+	name string
+	mult float64
+	stat func(ItemPriceStats) int64
+}
+
+func (p statPenalty) Name() string { return p.name }
+
+func (p statPenalty) Penalty(source PriceSource, chainSlug, itemID string) (int64, bool) {
+	stats, ok := source.GetItemPriceStats(chainSlug, itemID)
+	if !ok {
+		return 0, false
+	}
+	return int64(float64(p.stat(stats)) * p.mult), true
+}
+
+// categoryAveragePenalty multiplies the average price of the item's category,
+// which also covers items the chain has never priced. Items without a category
+// fall back to their own store-weighted mean.
+type categoryAveragePenalty struct {
+	mult float64
+}
+
+func (p categoryAveragePenalty) Name() string { return PenaltyStrategyCategoryAverage }
+
+func (p categoryAveragePenalty) Penalty(source PriceSource, chainSlug, itemID string) (int64, bool) {
+	if category, ok := source.GetItemCategory(chainSlug, itemID); ok {
+		if avg := source.GetCategoryAveragePrice(chainSlug, category); avg > 0 {
+			return int64(float64(avg) * p.mult), true
+		}
+	}
+	avg := source.GetWeightedAveragePrice(chainSlug, itemID)
+	if avg == 0 {
+		return 0, false
+	}
+	return int64(float64(avg) * p.mult), true
+}
+
+// categoryTablePenalty charges a fixed amount per category, from
+// MissingItemCategoryPenalties.
+type categoryTablePenalty struct {
+	table map[string]int64
+}
+
+func (p categoryTablePenalty) Name() string { return PenaltyStrategyCategoryTable }
+
+func (p categoryTablePenalty) Penalty(source PriceSource, chainSlug, itemID string) (int64, bool) {
+	category, ok := source.GetItemCategory(chainSlug, itemID)
+	if !ok {
+		return 0, false
+	}
+	penalty, ok := p.table[category]
+	return penalty, ok
+}
@@ -0,0 +1,351 @@
+package optimizer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kosarica/price-service/internal/logging"
+	"github.com/rs/zerolog"
+)
+
+// Registry errors
+var (
+	ErrBuildInProgress   = errors.New("an optimizer version is already being built")
+	ErrNoPreviousVersion = errors.New("no previous optimizer version to roll back to")
+)
+
+const (
+	// maxCanaryBaskets is how many recently served baskets are kept to
+	// validate new optimizer versions with
+	maxCanaryBaskets = 20
+	// canaryTimeout bounds each canary optimization
+	canaryTimeout = 5 * time.Second
+	// maxCanarySlowdown is how much slower than the active version a new
+	// version may run the canary baskets, beyond canaryLatencySlack
+	maxCanarySlowdown = 2.0
+	// canaryLatencySlack absorbs timing noise on fast canary runs
+	canaryLatencySlack = 50 * time.Millisecond
+)
+
+// OptimizerVersion is a configuration with the optimizers built from it.
+// Versions are immutable once built.
+type OptimizerVersion struct {
+	Version     int
+	Config      *OptimizerConfig
+	Single      *SingleStoreOptimizer
+	Multi       *MultiStoreOptimizer
+	BuiltAt     time.Time
+	ActivatedAt time.Time
+	Canary      *CanaryReport // nil for the version built at startup
+}
+
+// CanaryReport is the outcome of running the canary baskets through a new
+// version and the version active at the time
+type CanaryReport struct {
+	Baskets         int           `json:"baskets" jsonschema:"required"`
+	Failures        []string      `json:"failures" jsonschema:"required"`
+	CandidateTimeMs float64       `json:"candidateTimeMs" jsonschema:"required"`
+	ActiveTimeMs    float64       `json:"activeTimeMs" jsonschema:"required"`
+	candidateTime   time.Duration // Total time of the new version
+	activeTime      time.Duration // Total time of the active version
+}
+
+// FailedBuild records a version that was rejected before activation
+type FailedBuild struct {
+	Version  int              `json:"version" jsonschema:"required"`
+	Config   *OptimizerConfig `json:"-"`
+	Error    string           `json:"error" jsonschema:"required"`
+	Canary   *CanaryReport    `json:"canary,omitempty"`
+	FailedAt time.Time        `json:"failedAt" jsonschema:"required"`
+}
+
+// RegistryStatus is a snapshot of a registry's versions
+type RegistryStatus struct {
+	Active      *OptimizerVersion
+	Previous    *OptimizerVersion
+	Building    int // Version being built, 0 when none
+	LastFailure *FailedBuild
+}
+
+// Registry holds the active optimizer version, swapped atomically when a new
+// configuration passes its canary run, and the previous one for rollback.
+// Requests read the active version without locking.
+type Registry struct {
+	source  PriceSource
+	metrics *MetricsRecorder
+	logger  zerolog.Logger
+
+	active atomic.Pointer[OptimizerVersion]
+
+	mu          sync.Mutex // Guards the fields below
+	previous    *OptimizerVersion
+	building    int
+	lastFailure *FailedBuild
+	nextVersion int
+
+	canaryMu sync.Mutex
+	canaries []*OptimizeRequest // Ring of recently served baskets
+	canaryAt int
+}
+
+// NewRegistry creates a registry whose first version is built from config,
+// or from DefaultOptimizerConfig when config is nil
+func NewRegistry(source PriceSource, config *OptimizerConfig, metrics *MetricsRecorder) *Registry {
+	if config == nil {
+		config = DefaultOptimizerConfig()
+	}
+	if metrics == nil {
+		metrics = NewMetricsRecorder()
+	}
+	r := &Registry{
+		source:      source,
+		metrics:     metrics,
+		logger:      logging.For(logging.Optimizer).With().Str("component", "registry").Logger(),
+		nextVersion: 2,
+	}
+	v := r.build(1, config)
+	v.ActivatedAt = v.BuiltAt
+	r.active.Store(v)
+	return r
+}
+
+// Active returns the version serving requests
+func (r *Registry) Active() *OptimizerVersion {
+	return r.active.Load()
+}
+
+// Status returns the active, previous and building versions and the last
+// rejected build
+func (r *Registry) Status() RegistryStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return RegistryStatus{
+		Active:      r.active.Load(),
+		Previous:    r.previous,
+		Building:    r.building,
+		LastFailure: r.lastFailure,
+	}
+}
+
+// RecordCanary keeps a served request as a canary basket for validating
+// later versions, replacing the oldest once maxCanaryBaskets are kept
+func (r *Registry) RecordCanary(req *OptimizeRequest) {
+	canary := *req
+	canary.BasketItems = make([]*BasketItem, len(req.BasketItems))
+	for i, item := range req.BasketItems {
+		copied := *item
+		copied.ChainItemIDs = maps.Clone(item.ChainItemIDs)
+		canary.BasketItems[i] = &copied
+	}
+	canary.ChainSlugs = slices.Clone(req.ChainSlugs)
+	if req.Location != nil {
+		loc := *req.Location
+		canary.Location = &loc
+	}
+	canary.At = time.Time{}
+	canary.GreedyOnly = false
+
+	r.canaryMu.Lock()
+	defer r.canaryMu.Unlock()
+	if len(r.canaries) < maxCanaryBaskets {
+		r.canaries = append(r.canaries, &canary)
+		return
+	}
+	r.canaries[r.canaryAt] = &canary
+	r.canaryAt = (r.canaryAt + 1) % maxCanaryBaskets
+}
+
+// StageAsync validates config and builds it as a new version in the
+// background, activating it if it passes the canary run. Returns the
+// version number being built.
+func (r *Registry) StageAsync(config *OptimizerConfig) (int, error) {
+	if err := config.Validate(); err != nil {
+		return 0, err
+	}
+
+	version, err := r.reserve()
+	if err != nil {
+		return 0, err
+	}
+
+	go func() {
+		if _, err := r.stage(context.Background(), version, config); err != nil {
+			r.logger.Warn().
+				Err(err).
+				Int("version", version).
+				Msg("Optimizer version rejected")
+		}
+	}()
+	return version, nil
+}
+
+// Stage validates config, builds it as a new version and activates it if it
+// passes the canary run
+func (r *Registry) Stage(ctx context.Context, config *OptimizerConfig) (*OptimizerVersion, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	version, err := r.reserve()
+	if err != nil {
+		return nil, err
+	}
+	return r.stage(ctx, version, config)
+}
+
+// Rollback reactivates the previous version; the version it replaces becomes
+// the previous one, so a second rollback rolls forward again
+func (r *Registry) Rollback() (*OptimizerVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.previous == nil {
+		return nil, ErrNoPreviousVersion
+	}
+	restored := *r.previous
+	restored.ActivatedAt = time.Now()
+	r.previous = r.active.Swap(&restored)
+
+	r.logger.Info().
+		Int("version", restored.Version).
+		Int("replaced", r.previous.Version).
+		Msg("Rolled back optimizer version")
+	return &restored, nil
+}
+
+// reserve claims the next version number, refusing while another version is
+// being built
+func (r *Registry) reserve() (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.building != 0 {
+		return 0, ErrBuildInProgress
+	}
+	r.building = r.nextVersion
+	r.nextVersion++
+	return r.building, nil
+}
+
+// stage builds a reserved version, runs the canaries and swaps it in
+func (r *Registry) stage(ctx context.Context, version int, config *OptimizerConfig) (*OptimizerVersion, error) {
+	candidate := r.build(version, cloneOptimizerConfig(config))
+	report := r.runCanaries(ctx, candidate, r.Active())
+	candidate.Canary = report
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.building = 0
+
+	if err := report.verdict(); err != nil {
+		r.lastFailure = &FailedBuild{
+			Version:  version,
+			Config:   candidate.Config,
+			Error:    err.Error(),
+			Canary:   report,
+			FailedAt: time.Now(),
+		}
+		return nil, err
+	}
+
+	candidate.ActivatedAt = time.Now()
+	r.previous = r.active.Swap(candidate)
+
+	r.logger.Info().
+		Int("version", version).
+		Int("canaries", report.Baskets).
+		Float64("candidate_ms", report.CandidateTimeMs).
+		Float64("active_ms", report.ActiveTimeMs).
+		Msg("Activated optimizer version")
+	return candidate, nil
+}
+
+// build constructs the optimizers of a version
+func (r *Registry) build(version int, config *OptimizerConfig) *OptimizerVersion {
+	return &OptimizerVersion{
+		Version: version,
+		Config:  config,
+		Single:  NewSingleStoreOptimizer(r.source, config),
+		Multi:   NewMultiStoreOptimizer(r.source, config, r.metrics),
+		BuiltAt: time.Now(),
+	}
+}
+
+// runCanaries runs the canary baskets through the candidate and the active
+// version. A basket fails the candidate when the active version serves it
+// and the candidate does not; baskets neither serves are not held against
+// it. Running them also warms the candidate before it takes traffic.
+func (r *Registry) runCanaries(ctx context.Context, candidate, active *OptimizerVersion) *CanaryReport {
+	r.canaryMu.Lock()
+	canaries := slices.Clone(r.canaries)
+	r.canaryMu.Unlock()
+
+	report := &CanaryReport{Baskets: len(canaries), Failures: []string{}}
+	for i, req := range canaries {
+		activeTime, activeErr := runCanary(ctx, active, req)
+		candidateTime, candidateErr := runCanary(ctx, candidate, req)
+		report.activeTime += activeTime
+		report.candidateTime += candidateTime
+
+		if candidateErr != nil && activeErr == nil {
+			report.Failures = append(report.Failures, fmt.Sprintf("basket %d (%s): %v", i+1, req.chainLabel(), candidateErr))
+		}
+	}
+	report.ActiveTimeMs = float64(report.activeTime.Microseconds()) / 1000
+	report.CandidateTimeMs = float64(report.candidateTime.Microseconds()) / 1000
+	return report
+}
+
+// verdict returns why a candidate with this report must not be activated
+func (c *CanaryReport) verdict() error {
+	if len(c.Failures) > 0 {
+		return fmt.Errorf("%d of %d canary baskets failed: %s", len(c.Failures), c.Baskets, c.Failures[0])
+	}
+	budget := time.Duration(float64(c.activeTime)*maxCanarySlowdown) + canaryLatencySlack
+	if c.candidateTime > budget {
+		return fmt.Errorf("canary baskets took %.1fms against %.1fms on the active version", c.CandidateTimeMs, c.ActiveTimeMs)
+	}
+	return nil
+}
+
+// runCanary runs one canary basket through both optimizers of a version, or
+// only the multi-store one for a cross-chain basket, turning a panic into an
+// error
+func runCanary(ctx context.Context, v *OptimizerVersion, req *OptimizeRequest) (elapsed time.Duration, err error) {
+	ctx, cancel := context.WithTimeout(ctx, canaryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	defer func() {
+		elapsed = time.Since(start)
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+
+	canary := *req
+	if !canary.crossChain() {
+		if _, _, err := v.Single.Optimize(ctx, &canary); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := v.Multi.Optimize(ctx, &canary); err != nil {
+		return 0, err
+	}
+	return 0, nil
+}
+
+// cloneOptimizerConfig copies a configuration so later changes to the
+// original do not reach a built version
+func cloneOptimizerConfig(c *OptimizerConfig) *OptimizerConfig {
+	copied := *c
+	copied.CoverageBins = slices.Clone(c.CoverageBins)
+	copied.MissingItemCategoryPenalties = maps.Clone(c.MissingItemCategoryPenalties)
+	return &copied
+}
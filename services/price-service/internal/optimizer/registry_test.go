@@ -0,0 +1,131 @@
+package optimizer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRegistryTestSource() *mockPriceSource {
+	mock := newMockPriceSource()
+	mock.setPrice("test-chain", "store-a", "item-001", 50, nil)
+	mock.setPrice("test-chain", "store-a", "item-002", 30, nil)
+	mock.setPrice("test-chain", "store-b", "item-001", 45, nil)
+	return mock
+}
+
+func registryTestBasket() *OptimizeRequest {
+	return &OptimizeRequest{
+		ChainSlug: "test-chain",
+		BasketItems: []*BasketItem{
+			{ItemID: "item-001", Name: "Item 1", Quantity: 1},
+			{ItemID: "item-002", Name: "Item 2", Quantity: 2},
+		},
+	}
+}
+
+func TestRegistryStageAndRollback(t *testing.T) {
+	registry := NewRegistry(newRegistryTestSource(), DefaultOptimizerConfig(), nil)
+	registry.RecordCanary(registryTestBasket())
+
+	config := DefaultOptimizerConfig()
+	config.MissingItemPenaltyStrategy = PenaltyStrategyMax
+
+	staged, err := registry.Stage(context.Background(), config)
+	require.NoError(t, err)
+	assert.Equal(t, 2, staged.Version)
+	assert.Equal(t, 1, staged.Canary.Baskets)
+	assert.Empty(t, staged.Canary.Failures)
+	assert.Same(t, staged, registry.Active())
+	assert.Equal(t, 1, registry.Status().Previous.Version)
+
+	// Later changes to the submitted config do not reach the built version
+	config.MissingItemPenaltyStrategy = PenaltyStrategyMedian
+	assert.Equal(t, PenaltyStrategyMax, registry.Active().Config.MissingItemPenaltyStrategy)
+
+	restored, err := registry.Rollback()
+	require.NoError(t, err)
+	assert.Equal(t, 1, restored.Version)
+	assert.Equal(t, 1, registry.Active().Version)
+	assert.Equal(t, 2, registry.Status().Previous.Version)
+
+	// A second rollback rolls forward again
+	restored, err = registry.Rollback()
+	require.NoError(t, err)
+	assert.Equal(t, 2, restored.Version)
+}
+
+func TestNewRegistryWithoutConfig(t *testing.T) {
+	registry := NewRegistry(newRegistryTestSource(), nil, nil)
+
+	active := registry.Active()
+	require.NotNil(t, active.Config)
+	assert.Equal(t, DefaultOptimizerConfig().MissingItemPenaltyStrategy, active.Config.MissingItemPenaltyStrategy)
+
+	results, _, err := active.Single.Optimize(context.Background(), registryTestBasket())
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	assert.Equal(t, "store-a", results[0].StoreID)
+}
+
+func TestRegistryRollbackWithoutPreviousVersion(t *testing.T) {
+	registry := NewRegistry(newRegistryTestSource(), DefaultOptimizerConfig(), nil)
+
+	_, err := registry.Rollback()
+	assert.ErrorIs(t, err, ErrNoPreviousVersion)
+}
+
+func TestRegistryRejectsInvalidConfig(t *testing.T) {
+	registry := NewRegistry(newRegistryTestSource(), DefaultOptimizerConfig(), nil)
+
+	config := DefaultOptimizerConfig()
+	config.MissingItemPenaltyStrategy = "cheapest"
+
+	_, err := registry.Stage(context.Background(), config)
+	assert.ErrorAs(t, err, &ErrInvalidConfig{})
+	assert.Equal(t, 1, registry.Active().Version)
+	assert.Zero(t, registry.Status().Building)
+}
+
+func TestRegistryKeepsActiveVersionWhenCanaryFails(t *testing.T) {
+	registry := NewRegistry(newRegistryTestSource(), DefaultOptimizerConfig(), nil)
+	registry.RecordCanary(registryTestBasket())
+
+	// The canary basket has two items, more than the candidate accepts
+	config := DefaultOptimizerConfig()
+	config.MaxBasketItems = 1
+
+	_, err := registry.Stage(context.Background(), config)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 of 1 canary baskets failed")
+
+	status := registry.Status()
+	assert.Equal(t, 1, status.Active.Version)
+	assert.Nil(t, status.Previous)
+	assert.Zero(t, status.Building)
+	require.NotNil(t, status.LastFailure)
+	assert.Equal(t, 2, status.LastFailure.Version)
+
+	// The rejected version number is not reused
+	staged, err := registry.Stage(context.Background(), DefaultOptimizerConfig())
+	require.NoError(t, err)
+	assert.Equal(t, 3, staged.Version)
+}
+
+func TestRegistryCanaryRing(t *testing.T) {
+	registry := NewRegistry(newRegistryTestSource(), DefaultOptimizerConfig(), nil)
+
+	for i := 0; i < maxCanaryBaskets+5; i++ {
+		req := registryTestBasket()
+		req.ChainSlug = fmt.Sprintf("chain-%d", i)
+		registry.RecordCanary(req)
+	}
+
+	assert.Len(t, registry.canaries, maxCanaryBaskets)
+	// The five oldest were replaced
+	assert.Equal(t, fmt.Sprintf("chain-%d", maxCanaryBaskets), registry.canaries[0].ChainSlug)
+	assert.Equal(t, "chain-5", registry.canaries[5].ChainSlug)
+}
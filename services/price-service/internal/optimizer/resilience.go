@@ -82,6 +82,10 @@ func NewCircuitBreaker(name string, config *CircuitBreakerConfig, metrics *Metri
 		logger = &nopLogger
 	}
 
+	if metrics != nil {
+		metrics.RecordCircuitBreakerState(name, CircuitClosed)
+	}
+
 	return &CircuitBreaker{
 		state:           CircuitClosed,
 		config:          config,
@@ -205,7 +209,7 @@ func (cb *CircuitBreaker) transitionTo(newState CircuitBreakerState, now time.Ti
 
 	// Record state change metric
 	if cb.metrics != nil {
-		// Note: We'd need to add a state metric to track this
+		cb.metrics.RecordCircuitBreakerTransition(cb.name, newState)
 	}
 }
 
@@ -0,0 +1,104 @@
+package optimizer
+
+import (
+	"cmp"
+	"slices"
+)
+
+// planVisits sets the visit order of a result's stores. With the request's
+// location and store locations known, the stores are ordered into a short
+// round trip from the location and back, and its length is the result's
+// travel distance. Stores without a known location are visited last.
+// Otherwise stores are visited nearest first. The trip is priced as the
+// result's travel cost.
+func (o *MultiStoreOptimizer) planVisits(req *OptimizeRequest, result *MultiStoreResult) {
+	slices.SortFunc(result.Stores, func(a, b *StoreAllocation) int {
+		return cmp.Or(cmp.Compare(a.Distance, b.Distance), cmp.Compare(a.StoreID, b.StoreID))
+	})
+
+	locator, ok := o.priceSource.(StoreLocator)
+	if req.Location != nil && ok {
+		var located, unlocated []*StoreAllocation
+		var stops []Location
+		for _, store := range result.Stores {
+			if location, ok := locator.GetStoreLocation(store.ChainSlug, store.StoreID); ok {
+				located = append(located, store)
+				stops = append(stops, location)
+			} else {
+				unlocated = append(unlocated, store)
+			}
+		}
+
+		order, travelKm := visitRoute(*req.Location, stops)
+		result.Stores = result.Stores[:0]
+		for _, i := range order {
+			result.Stores = append(result.Stores, located[i])
+		}
+		result.Stores = append(result.Stores, unlocated...)
+		result.TravelDistanceKm = travelKm
+	}
+
+	for i, store := range result.Stores {
+		store.VisitOrder = i + 1
+	}
+	result.TravelCost = o.travelRates().priced(len(result.Stores), result.TravelDistanceKm)
+}
+
+// visitRoute orders stops into a round trip from origin and back: nearest
+// neighbor first, then improved by 2-opt until no reversal of a stretch of
+// the route shortens it. It returns the indexes of the stops in visit order
+// and the length of the trip in km.
+func visitRoute(origin Location, stops []Location) ([]int, float64) {
+	// Point 0 is the origin, point i the stop i-1
+	points := append([]Location{origin}, stops...)
+	n := len(points)
+	dist := make([][]float64, n)
+	for i := range points {
+		dist[i] = make([]float64, n)
+		for j := range i {
+			d := HaversineKm(points[i].Latitude, points[i].Longitude, points[j].Latitude, points[j].Longitude)
+			dist[i][j], dist[j][i] = d, d
+		}
+	}
+
+	// Nearest neighbor
+	route := make([]int, 1, n)
+	visited := make([]bool, n)
+	visited[0] = true
+	for len(route) < n {
+		from, next := route[len(route)-1], -1
+		for to := 1; to < n; to++ {
+			if !visited[to] && (next < 0 || dist[from][to] < dist[from][next]) {
+				next = to
+			}
+		}
+		route = append(route, next)
+		visited[next] = true
+	}
+
+	// 2-opt: reversing route[i..j] replaces edges (a,b) and (c,d) with (a,c)
+	// and (b,d). The origin stays first.
+	const epsilon = 1e-9
+	for improved := true; improved; {
+		improved = false
+		for i := 1; i < n-1; i++ {
+			for j := i + 1; j < n; j++ {
+				a, b, c, d := route[i-1], route[i], route[j], route[(j+1)%n]
+				if dist[a][c]+dist[b][d] < dist[a][b]+dist[c][d]-epsilon {
+					slices.Reverse(route[i : j+1])
+					improved = true
+				}
+			}
+		}
+	}
+
+	order := make([]int, n-1)
+	length := 0.0
+	for i, point := range route {
+		length += dist[point][route[(i+1)%n]]
+		if i > 0 {
+			order[i-1] = point - 1
+		}
+	}
+	return order, length
+}
@@ -0,0 +1,123 @@
+package optimizer
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// routeLength returns the length of the round trip from origin through the
+// stops in order
+func routeLength(origin Location, stops []Location, order []int) float64 {
+	length, at := 0.0, origin
+	for _, i := range order {
+		length += HaversineKm(at.Latitude, at.Longitude, stops[i].Latitude, stops[i].Longitude)
+		at = stops[i]
+	}
+	return length + HaversineKm(at.Latitude, at.Longitude, origin.Latitude, origin.Longitude)
+}
+
+// TestVisitRouteUncrosses verifies 2-opt removes the crossing nearest
+// neighbor leaves when the nearest stop is on the far side.
+func TestVisitRouteUncrosses(t *testing.T) {
+	origin := Location{Latitude: 45.80, Longitude: 15.90}
+	stops := []Location{
+		{Latitude: 45.80, Longitude: 16.00}, // East
+		{Latitude: 45.85, Longitude: 15.95}, // North-east, nearest
+		{Latitude: 45.85, Longitude: 15.90}, // North
+		{Latitude: 45.80, Longitude: 15.95}, // Between origin and east
+	}
+
+	order, length := visitRoute(origin, stops)
+	assert.Contains(t, [][]int{{3, 0, 1, 2}, {2, 1, 0, 3}}, order, "around the loop, either way")
+	assert.InDelta(t, routeLength(origin, stops, order), length, 1e-9)
+}
+
+// TestVisitRouteNearOptimal compares routes with the shortest of every order
+// for small random trips.
+func TestVisitRouteNearOptimal(t *testing.T) {
+	rng := rand.New(rand.NewPCG(5, 5))
+	origin := Location{Latitude: 45.81, Longitude: 15.98}
+
+	worst := 1.0
+	for range 100 {
+		stops := make([]Location, 2+rng.IntN(5))
+		for i := range stops {
+			stops[i] = Location{Latitude: 45.7 + rng.Float64()*0.2, Longitude: 15.8 + rng.Float64()*0.3}
+		}
+
+		order, length := visitRoute(origin, stops)
+		sorted := slices.Clone(order)
+		slices.Sort(sorted)
+		require.Equal(t, []int{0, 1, 2, 3, 4, 5, 6}[:len(stops)], sorted, "every stop once")
+
+		best := -1.0
+		permutations(len(stops), func(p []int) {
+			if l := routeLength(origin, stops, p); best < 0 || l < best {
+				best = l
+			}
+		})
+		worst = max(worst, length/best)
+	}
+	assert.Less(t, worst, 1.1, "within 10% of the shortest trip")
+}
+
+// permutations calls f with every order of 0..n-1
+func permutations(n int, f func([]int)) {
+	p := make([]int, n)
+	for i := range p {
+		p[i] = i
+	}
+	var permute func(k int)
+	permute = func(k int) {
+		if k == n {
+			f(p)
+			return
+		}
+		for i := k; i < n; i++ {
+			p[k], p[i] = p[i], p[k]
+			permute(k + 1)
+			p[k], p[i] = p[i], p[k]
+		}
+	}
+	permute(0)
+}
+
+// TestPlanVisits verifies stores are routed when locations are known and
+// ordered by distance otherwise.
+func TestPlanVisits(t *testing.T) {
+	mock := newMockPriceSource()
+	mock.storeLocations["chain"] = map[string]Location{
+		"east":  {Latitude: 45.80, Longitude: 16.00},
+		"north": {Latitude: 45.85, Longitude: 15.90},
+	}
+	o := NewMultiStoreOptimizer(mock, DefaultOptimizerConfig(), NewMetricsRecorder())
+	newResult := func() *MultiStoreResult {
+		return &MultiStoreResult{Stores: []*StoreAllocation{
+			{StoreID: "unknown", ChainSlug: "chain", Distance: 0.5},
+			{StoreID: "east", ChainSlug: "chain", Distance: 7.8},
+			{StoreID: "north", ChainSlug: "chain", Distance: 5.6},
+		}}
+	}
+	storeIDs := func(result *MultiStoreResult) []string {
+		ids := make([]string, len(result.Stores))
+		for i, store := range result.Stores {
+			ids[i] = store.StoreID
+			assert.Equal(t, i+1, store.VisitOrder)
+		}
+		return ids
+	}
+
+	result := newResult()
+	o.planVisits(&OptimizeRequest{Location: &Location{Latitude: 45.80, Longitude: 15.90}}, result)
+	assert.Contains(t, [][]string{{"north", "east", "unknown"}, {"east", "north", "unknown"}}, storeIDs(result), "stores without a location last")
+	assert.InDelta(t, 7.8+9.5+5.6, result.TravelDistanceKm, 0.1, "out, between the stores and back")
+
+	result = newResult()
+	o.planVisits(&OptimizeRequest{}, result)
+	assert.Equal(t, []string{"unknown", "north", "east"}, storeIDs(result))
+	assert.Zero(t, result.TravelDistanceKm)
+}
@@ -0,0 +1,127 @@
+package optimizer
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrOverloaded is returned by LoadShedder.Admit when a request is rejected.
+var ErrOverloaded = errors.New("optimizer overloaded")
+
+// Load shedding reasons, used in metrics and responses.
+const (
+	ShedReasonQueueDepth  = "queue_depth"
+	ShedReasonCircuitOpen = "circuit_open"
+	ShedReasonDBSaturated = "db_saturated"
+)
+
+// HealthSignals are the health inputs of the load shedding policy.
+type HealthSignals struct {
+	CircuitOpen bool // Cache circuit breaker is not closed
+	DBSaturated bool // Every database connection is in use
+}
+
+// LoadShedConfig holds the thresholds of the load shedding policy.
+type LoadShedConfig struct {
+	// MaxInflight is the number of concurrent requests above which new
+	// requests are rejected with ErrOverloaded.
+	MaxInflight int
+
+	// GreedyOnlyInflight is the number of concurrent requests above which
+	// multi-store optimization skips the optimal algorithm.
+	GreedyOnlyInflight int
+
+	// RetryAfter is the back-off suggested to rejected callers.
+	RetryAfter time.Duration
+}
+
+// DefaultLoadShedConfig returns the default load shedding thresholds.
+func DefaultLoadShedConfig() LoadShedConfig {
+	return LoadShedConfig{
+		MaxInflight:        64,
+		GreedyOnlyInflight: 16,
+		RetryAfter:         2 * time.Second,
+	}
+}
+
+// Admission is an admitted request. Release must be called when it is done.
+type Admission struct {
+	// GreedyOnly asks multi-store optimization to skip the optimal algorithm.
+	GreedyOnly bool
+
+	// Reason explains why the request was degraded; empty when it was not.
+	Reason string
+
+	shedder  *LoadShedder
+	released atomic.Bool
+}
+
+// Release returns the admission's slot. It is safe to call more than once.
+func (a *Admission) Release() {
+	if a.released.CompareAndSwap(false, true) {
+		a.shedder.inflight.Add(-1)
+		inflightOptimizations.Dec()
+	}
+}
+
+// LoadShedder decides per request whether to serve it normally, serve it in
+// a cheaper degraded mode, or reject it, based on the number of requests in
+// flight and the health signals.
+type LoadShedder struct {
+	config   LoadShedConfig
+	signals  func() HealthSignals
+	metrics  *MetricsRecorder
+	inflight atomic.Int64
+}
+
+// NewLoadShedder creates a load shedder. signals may be nil when no health
+// signals are available.
+func NewLoadShedder(config LoadShedConfig, signals func() HealthSignals) *LoadShedder {
+	if signals == nil {
+		signals = func() HealthSignals { return HealthSignals{} }
+	}
+	return &LoadShedder{
+		config:  config,
+		signals: signals,
+		metrics: NewMetricsRecorder(),
+	}
+}
+
+// Admit admits a request or returns ErrOverloaded when the queue is too deep.
+func (s *LoadShedder) Admit() (*Admission, error) {
+	inflight := s.inflight.Add(1)
+	if s.config.MaxInflight > 0 && inflight > int64(s.config.MaxInflight) {
+		s.inflight.Add(-1)
+		s.metrics.RecordLoadShed("rejected", ShedReasonQueueDepth)
+		return nil, ErrOverloaded
+	}
+	inflightOptimizations.Inc()
+
+	a := &Admission{shedder: s}
+	signals := s.signals()
+	switch {
+	case s.config.GreedyOnlyInflight > 0 && inflight > int64(s.config.GreedyOnlyInflight):
+		a.Reason = ShedReasonQueueDepth
+	case signals.CircuitOpen:
+		a.Reason = ShedReasonCircuitOpen
+	case signals.DBSaturated:
+		a.Reason = ShedReasonDBSaturated
+	}
+	if a.Reason != "" {
+		a.GreedyOnly = true
+		s.metrics.RecordLoadShed("greedy_only", a.Reason)
+	}
+
+	return a, nil
+}
+
+// Inflight returns the number of admitted requests not yet released.
+func (s *LoadShedder) Inflight() int64 {
+	return s.inflight.Load()
+}
+
+// RetryAfter returns the back-off suggested to rejected callers.
+func (s *LoadShedder) RetryAfter() time.Duration {
+	return s.config.RetryAfter
+}
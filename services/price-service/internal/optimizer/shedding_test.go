@@ -0,0 +1,59 @@
+package optimizer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadShedderThresholds(t *testing.T) {
+	shedder := NewLoadShedder(LoadShedConfig{MaxInflight: 3, GreedyOnlyInflight: 1}, nil)
+
+	first, err := shedder.Admit()
+	require.NoError(t, err)
+	assert.False(t, first.GreedyOnly)
+
+	second, err := shedder.Admit()
+	require.NoError(t, err)
+	assert.True(t, second.GreedyOnly)
+	assert.Equal(t, ShedReasonQueueDepth, second.Reason)
+
+	third, err := shedder.Admit()
+	require.NoError(t, err)
+
+	_, err = shedder.Admit()
+	assert.ErrorIs(t, err, ErrOverloaded)
+	assert.EqualValues(t, 3, shedder.Inflight())
+
+	third.Release()
+	third.Release()
+	assert.EqualValues(t, 2, shedder.Inflight())
+
+	second.Release()
+	first.Release()
+	assert.EqualValues(t, 0, shedder.Inflight())
+}
+
+func TestLoadShedderHealthSignals(t *testing.T) {
+	signals := HealthSignals{CircuitOpen: true}
+	shedder := NewLoadShedder(DefaultLoadShedConfig(), func() HealthSignals { return signals })
+
+	a, err := shedder.Admit()
+	require.NoError(t, err)
+	assert.True(t, a.GreedyOnly)
+	assert.Equal(t, ShedReasonCircuitOpen, a.Reason)
+	a.Release()
+
+	signals = HealthSignals{DBSaturated: true}
+	a, err = shedder.Admit()
+	require.NoError(t, err)
+	assert.Equal(t, ShedReasonDBSaturated, a.Reason)
+	a.Release()
+
+	signals = HealthSignals{}
+	a, err = shedder.Admit()
+	require.NoError(t, err)
+	assert.False(t, a.GreedyOnly)
+	a.Release()
+}
@@ -2,19 +2,38 @@ package optimizer
 
 import (
 	"context"
+	"slices"
 	"sort"
 	"time"
 
+	"github.com/kosarica/price-service/internal/logging"
 	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
 )
 
+// DefaultSingleStoreLimit is how many ranked stores single-store optimization
+// returns when the request sets no limit
+const DefaultSingleStoreLimit = 50
+
+// Single-store sort orders: what ranks stores within a coverage bin.
+const (
+	SortByPrice    = "price"    // basket total, penalties included
+	SortByDistance = "distance" // distance from the request's location
+	SortByBalanced = "balanced" // basket total plus BalancedDistanceCostPerKm per km
+)
+
+// IsSortBy reports whether name is a known single-store sort order.
+func IsSortBy(name string) bool {
+	return name == SortByPrice || name == SortByDistance || name == SortByBalanced
+}
+
 // SingleStoreOptimizer implements coverage-first ranking for single store optimization.
 type SingleStoreOptimizer struct {
 	priceSource PriceSource
 	config      *OptimizerConfig
+	penalties   map[string]PenaltyStrategy
 	metrics     *MetricsRecorder
 	logger      zerolog.Logger
+	clock       func() time.Time
 }
 
 // NewSingleStoreOptimizer creates a new single-store optimizer.
@@ -22,13 +41,22 @@ func NewSingleStoreOptimizer(priceSource PriceSource, config *OptimizerConfig) *
 	return &SingleStoreOptimizer{
 		priceSource: priceSource,
 		config:      config,
+		penalties:   newPenaltyStrategies(config),
 		metrics:     NewMetricsRecorder(),
-		logger:      log.With().Str("component", "single_store_optimizer").Logger(),
+		logger:      logging.For(logging.Optimizer).With().Str("optimizer", "single_store").Logger(),
+		clock:       time.Now,
 	}
 }
 
-// Optimize finds the best single stores for a basket using coverage-first ranking.
-func (o *SingleStoreOptimizer) Optimize(ctx context.Context, req *OptimizeRequest) ([]*SingleStoreResult, error) {
+// SetClock replaces the clock requests without a time are evaluated at.
+func (o *SingleStoreOptimizer) SetClock(clock func() time.Time) {
+	o.clock = clock
+}
+
+// Optimize finds the best single stores for a basket using coverage-first
+// ranking. It returns the requested page of the stores meeting the minimum
+// coverage, and how many stores do.
+func (o *SingleStoreOptimizer) Optimize(ctx context.Context, req *OptimizeRequest) ([]*SingleStoreResult, int, error) {
 	startTime := time.Now()
 	defer func() {
 		o.metrics.RecordOptimizationDuration("single", time.Since(startTime))
@@ -36,8 +64,12 @@ func (o *SingleStoreOptimizer) Optimize(ctx context.Context, req *OptimizeReques
 
 	// Validate request
 	if err := req.Validate(o.config.MaxBasketItems); err != nil {
-		return nil, err
+		return nil, 0, err
 	}
+	if req.crossChain() {
+		return nil, 0, ErrInvalidRequest{Field: "chainSlugs", Reason: "cross-chain optimization is multi-store only"}
+	}
+	req = req.atTime(o.clock)
 
 	o.metrics.RecordBasketSize(len(req.BasketItems))
 
@@ -75,7 +107,7 @@ func (o *SingleStoreOptimizer) Optimize(ctx context.Context, req *OptimizeReques
 	}
 
 	if len(candidateStores) == 0 {
-		return []*SingleStoreResult{}, nil
+		return []*SingleStoreResult{}, 0, nil
 	}
 
 	o.metrics.RecordCandidateCount("single", len(candidateStores))
@@ -85,7 +117,7 @@ func (o *SingleStoreOptimizer) Optimize(ctx context.Context, req *OptimizeReques
 	for _, storeID := range candidateStores {
 		// Check context cancellation
 		if ctx.Err() != nil {
-			return nil, ctx.Err()
+			return nil, 0, ctx.Err()
 		}
 
 		result := o.calculateStoreResult(req, storeID)
@@ -100,21 +132,30 @@ func (o *SingleStoreOptimizer) Optimize(ctx context.Context, req *OptimizeReques
 		results = append(results, result)
 	}
 
-	// Sort by coverage bin (descending), then by sorting total (ascending)
-	sortResults(results)
+	// Sort by coverage bin (descending), then by the requested score (ascending)
+	sortResults(results, storeScore(req.SortBy, o.config.BalancedDistanceCostPerKm))
 
 	// Record coverage ratio of best result
 	if len(results) > 0 {
 		o.metrics.RecordCoverageRatio(results[0].CoverageRatio)
 	}
 
-	// Limit results (e.g. top 50)
-	limit := 50
-	if len(results) > limit {
-		results = results[:limit]
-	}
+	results = slices.DeleteFunc(results, func(r *SingleStoreResult) bool {
+		return r.CoverageRatio < req.MinCoverage
+	})
+	return pageResults(results, req.Offset, req.Limit), len(results), nil
+}
 
-	return results, nil
+// pageResults returns limit results from offset, DefaultSingleStoreLimit if
+// limit is 0
+func pageResults(results []*SingleStoreResult, offset, limit int) []*SingleStoreResult {
+	if limit == 0 {
+		limit = DefaultSingleStoreLimit
+	}
+	if offset >= len(results) {
+		return []*SingleStoreResult{}
+	}
+	return results[offset:min(offset+limit, len(results))]
 }
 
 // calculateStoreResult computes the optimization result for a single store.
@@ -133,7 +174,7 @@ func (o *SingleStoreOptimizer) calculateStoreResult(req *OptimizeRequest, storeI
 		price, ok := o.priceSource.GetPrice(req.ChainSlug, storeID, item.ItemID)
 		if !ok {
 			// Item not available at this store
-			penalty := o.calculatePenalty(req.ChainSlug, item.ItemID)
+			penalty := o.calculatePenalty(req, item.ItemID)
 			result.MissingItems = append(result.MissingItems, &MissingItem{
 				ItemID:     item.ItemID,
 				ItemName:   item.Name,
@@ -146,22 +187,7 @@ func (o *SingleStoreOptimizer) calculateStoreResult(req *OptimizeRequest, storeI
 
 		// Item is available
 		foundCount++
-		effectivePrice := GetEffectivePrice(price)
-
-		itemInfo := &ItemPriceInfo{
-			ItemID:         item.ItemID,
-			ItemName:       item.Name,
-			Quantity:       item.Quantity,
-			BasePrice:      price.Price,
-			EffectivePrice: effectivePrice,
-			HasDiscount:    price.HasDiscount,
-			LineTotal:      effectivePrice * int64(item.Quantity),
-		}
-
-		if price.HasDiscount {
-			itemInfo.DiscountPrice = &price.DiscountPrice
-		}
-
+		itemInfo := newItemPriceInfo(item, price, req.At)
 		result.Items = append(result.Items, itemInfo)
 		sortingTotal += itemInfo.LineTotal
 		realTotal += itemInfo.LineTotal
@@ -181,20 +207,31 @@ func (o *SingleStoreOptimizer) calculateStoreResult(req *OptimizeRequest, storeI
 	return result
 }
 
-// calculatePenalty computes the penalty for a missing item using chain average.
-func (o *SingleStoreOptimizer) calculatePenalty(chainSlug, itemID string) int64 {
-	avgPrice := o.priceSource.GetAveragePrice(chainSlug, itemID)
-	if avgPrice == 0 {
-		// Use fallback if no average available
-		return o.config.MissingItemFallback
+// calculatePenalty computes the penalty for a missing item with the
+// request's penalty strategy.
+func (o *SingleStoreOptimizer) calculatePenalty(req *OptimizeRequest, itemID string) int64 {
+	return missingItemPenalty(o.priceSource, o.config, o.penalties, req, itemID)
+}
+
+// storeScore returns the score of a sort order, lower is better. Balanced
+// scores add the cost of the distance, in minor units, to the sorting total.
+func storeScore(sortBy string, costPerKm int64) func(*SingleStoreResult) float64 {
+	switch sortBy {
+	case SortByDistance:
+		return func(r *SingleStoreResult) float64 { return r.Distance }
+	case SortByBalanced:
+		return func(r *SingleStoreResult) float64 {
+			return float64(r.SortingTotal) + r.Distance*float64(costPerKm)
+		}
+	default:
+		return func(r *SingleStoreResult) float64 { return float64(r.SortingTotal) }
 	}
-	return int64(float64(avgPrice) * o.config.MissingItemPenaltyMult)
 }
 
 // sortResults sorts optimization results by coverage bin (descending),
-// then by sorting total (ascending), then by distance (ascending),
-// then by store ID (ascending).
-func sortResults(results []*SingleStoreResult) {
+// then by score (ascending), then by sorting total (ascending), then by
+// distance (ascending), then by store ID (ascending).
+func sortResults(results []*SingleStoreResult, score func(*SingleStoreResult) float64) {
 	sort.Slice(results, func(i, j int) bool {
 		a, b := results[i], results[j]
 
@@ -203,12 +240,17 @@ func sortResults(results []*SingleStoreResult) {
 			return a.CoverageBin > b.CoverageBin
 		}
 
-		// 2. Sorting Total (lower is better)
+		// 2. Score (lower is better)
+		if sa, sb := score(a), score(b); sa != sb {
+			return sa < sb
+		}
+
+		// 3. Sorting Total (lower is better)
 		if a.SortingTotal != b.SortingTotal {
 			return a.SortingTotal < b.SortingTotal
 		}
 
-		// 3. Distance (lower is better)
+		// 4. Distance (lower is better)
 		// Only if both have distance. If one is 0 (unknown), prefer known distance?
 		// Assuming 0 means unknown/far.
 		if a.Distance != b.Distance {
@@ -219,7 +261,29 @@ func sortResults(results []*SingleStoreResult) {
 			return a.Distance > 0
 		}
 
-		// 4. Tie-breaker: store ID (for determinism)
+		// 5. Tie-breaker: store ID (for determinism)
 		return a.StoreID < b.StoreID
 	})
 }
+
+// newItemPriceInfo prices a basket item at its price in a store, with the
+// discount applied when it is active at the given time.
+func newItemPriceInfo(item *BasketItem, price CachedPrice, at time.Time) *ItemPriceInfo {
+	effectivePrice := GetEffectivePrice(price, at)
+	hasDiscount := price.DiscountActive(at)
+
+	itemInfo := &ItemPriceInfo{
+		ItemID:         item.ItemID,
+		ItemName:       item.Name,
+		Quantity:       item.Quantity,
+		BasePrice:      price.Price,
+		EffectivePrice: effectivePrice,
+		HasDiscount:    hasDiscount,
+		LineTotal:      effectivePrice * int64(item.Quantity),
+	}
+
+	if hasDiscount {
+		itemInfo.DiscountPrice = &price.DiscountPrice
+	}
+	return itemInfo
+}
@@ -3,14 +3,19 @@ package optimizer
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // mockPriceSource is a mock implementation of PriceSource for testing.
 type mockPriceSource struct {
 	prices         map[string]map[string]map[string]CachedPrice // chain -> store -> item -> price
 	averagePrices  map[string]map[string]int64                  // chain -> item -> avg
+	priceStats     map[string]map[string]ItemPriceStats         // chain -> item -> stats, overrides averagePrices
+	categories     map[string]map[string]string                 // chain -> item -> category
+	categoryAvgs   map[string]map[string]int64                  // chain -> category -> avg
 	storeLocations map[string]map[string]Location               // chain -> store -> location
 }
 
@@ -42,6 +47,30 @@ func (m *mockPriceSource) GetAveragePrice(chainSlug string, itemID string) int64
 	return 0
 }
 
+func (m *mockPriceSource) GetWeightedAveragePrice(chainSlug string, itemID string) int64 {
+	stats, _ := m.GetItemPriceStats(chainSlug, itemID)
+	return stats.WeightedAverage
+}
+
+func (m *mockPriceSource) GetItemPriceStats(chainSlug string, itemID string) (ItemPriceStats, bool) {
+	if stats, ok := m.priceStats[chainSlug][itemID]; ok {
+		return stats, true
+	}
+	if avg, ok := m.averagePrices[chainSlug][itemID]; ok {
+		return ItemPriceStats{Average: avg, WeightedAverage: avg, Median: avg, Min: avg, Max: avg}, true
+	}
+	return ItemPriceStats{}, false
+}
+
+func (m *mockPriceSource) GetItemCategory(chainSlug string, itemID string) (string, bool) {
+	category, ok := m.categories[chainSlug][itemID]
+	return category, ok
+}
+
+func (m *mockPriceSource) GetCategoryAveragePrice(chainSlug string, category string) int64 {
+	return m.categoryAvgs[chainSlug][category]
+}
+
 func (m *mockPriceSource) GetStoreIDs(chainSlug string) []string {
 	var storeIDs []string
 	if chainPrices, ok := m.prices[chainSlug]; ok {
@@ -56,6 +85,11 @@ func (m *mockPriceSource) GetNearestStores(chainSlug string, lat, lon, maxDistan
 	return []StoreWithDistance{} // Not used in single-store optimization
 }
 
+func (m *mockPriceSource) GetStoreLocation(chainSlug, storeID string) (Location, bool) {
+	location, ok := m.storeLocations[chainSlug][storeID]
+	return location, ok
+}
+
 func (m *mockPriceSource) IsHealthy(ctx context.Context) bool {
 	return true // Mock is always healthy
 }
@@ -243,11 +277,47 @@ func TestHighCoverageRanksAboveCheapButIncomplete(t *testing.T) {
 
 	// When sorted, Store A should rank above Store B due to higher coverage
 	results := []*SingleStoreResult{resultB, resultA}
-	sortResults(results)
+	sortResults(results, storeScore(SortByPrice, 0))
 
 	assert.Equal(t, "store-a", results[0].StoreID)
 }
 
+// TestSortOrders verifies each sort order ranks within coverage bins.
+func TestSortOrders(t *testing.T) {
+	stores := func() []*SingleStoreResult {
+		return []*SingleStoreResult{
+			{StoreID: "far-cheap", CoverageBin: CoverageBinFull, SortingTotal: 1000, Distance: 2.0},
+			{StoreID: "near-dear", CoverageBin: CoverageBinFull, SortingTotal: 1030, Distance: 0.3},
+			{StoreID: "mid", CoverageBin: CoverageBinFull, SortingTotal: 1500, Distance: 1.0},
+			{StoreID: "nearest-partial", CoverageBin: CoverageBinHigh, SortingTotal: 900, Distance: 0.1},
+		}
+	}
+	storeIDs := func(results []*SingleStoreResult) []string {
+		ids := make([]string, len(results))
+		for i, r := range results {
+			ids[i] = r.StoreID
+		}
+		return ids
+	}
+
+	tests := []struct {
+		sortBy string
+		want   []string
+	}{
+		{SortByPrice, []string{"far-cheap", "near-dear", "mid", "nearest-partial"}},
+		{SortByDistance, []string{"near-dear", "mid", "far-cheap", "nearest-partial"}},
+		// 2.00 per km: 1090 for near-dear, 1400 for far-cheap, 1700 for mid
+		{SortByBalanced, []string{"near-dear", "far-cheap", "mid", "nearest-partial"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sortBy, func(t *testing.T) {
+			results := stores()
+			sortResults(results, storeScore(tt.sortBy, 200))
+			assert.Equal(t, tt.want, storeIDs(results))
+		})
+	}
+}
+
 // TestPenaltyUsesChainAverage verifies that penalty uses chain average, not magic constant.
 func TestPenaltyUsesChainAverage(t *testing.T) {
 	mock := newMockPriceSource()
@@ -276,6 +346,55 @@ func TestPenaltyUsesChainAverage(t *testing.T) {
 	assert.Equal(t, int64(200), result.MissingItems[0].Penalty)
 }
 
+// TestPenaltyStrategies verifies each strategy, and that a request override
+// takes precedence over the configured default.
+func TestPenaltyStrategies(t *testing.T) {
+	mock := newMockPriceSource()
+	mock.priceStats = map[string]map[string]ItemPriceStats{
+		"test-chain": {"item-001": {Average: 400, WeightedAverage: 250, Median: 150, Min: 100, Max: 1500}},
+	}
+	mock.categories = map[string]map[string]string{
+		"test-chain": {"item-001": "dairy", "item-002": "dairy", "item-003": "bakery"},
+	}
+	mock.categoryAvgs = map[string]map[string]int64{
+		"test-chain": {"dairy": 180},
+	}
+
+	config := DefaultOptimizerConfig()
+	config.MissingItemPenaltyStrategy = PenaltyStrategyMedian
+	config.MissingItemCategoryPenalties = map[string]int64{"dairy": 999}
+	optimizer := NewSingleStoreOptimizer(mock, config)
+
+	tests := []struct {
+		strategy string
+		itemID   string
+		penalty  int64
+	}{
+		{"", "item-001", 300}, // configured default
+		{PenaltyStrategyMean, "item-001", 500},
+		{PenaltyStrategySimpleMean, "item-001", 800},
+		{PenaltyStrategyMedian, "item-001", 300},
+		{PenaltyStrategyMax, "item-001", 3000},
+		{PenaltyStrategyCategoryAverage, "item-001", 360},
+		// Never priced, but its category is
+		{PenaltyStrategyCategoryAverage, "item-002", 360},
+		{PenaltyStrategyCategoryAverage, "item-003", config.MissingItemFallback},
+		{PenaltyStrategyCategoryTable, "item-001", 999},
+		{PenaltyStrategyCategoryTable, "item-003", config.MissingItemFallback},
+		{PenaltyStrategyMean, "item-999", config.MissingItemFallback},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy+"/"+tt.itemID, func(t *testing.T) {
+			req := &OptimizeRequest{ChainSlug: "test-chain", PenaltyStrategy: tt.strategy}
+			assert.Equal(t, tt.penalty, optimizer.calculatePenalty(req, tt.itemID))
+		})
+	}
+
+	assert.Equal(t, PenaltyStrategyMedian, config.PenaltyStrategyName(""))
+	assert.Equal(t, PenaltyStrategyMax, config.PenaltyStrategyName(PenaltyStrategyMax))
+}
+
 // TestDiscountPriceHandling verifies that discount prices are correctly applied.
 func TestDiscountPriceHandling(t *testing.T) {
 	mock := newMockPriceSource()
@@ -394,12 +513,115 @@ func TestGetEffectivePrice(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			price := GetEffectivePrice(tt.price)
+			price := GetEffectivePrice(tt.price, time.Now())
 			assert.Equal(t, tt.expectedPrice, price)
 		})
 	}
 }
 
+// TestGetEffectivePriceWindow verifies discounts only apply within their
+// validity window.
+func TestGetEffectivePriceWindow(t *testing.T) {
+	// A date without a time starts at midnight in Zagreb, 22:00 UTC in summer
+	start := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	opens := time.Date(2025, 7, 31, 22, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 8, 7, 23, 59, 59, 0, time.UTC)
+	price := CachedPrice{
+		Price:         100,
+		DiscountPrice: 80,
+		HasDiscount:   true,
+		DiscountStart: &start,
+		DiscountEnd:   &end,
+	}
+
+	assert.EqualValues(t, 100, GetEffectivePrice(price, opens.Add(-time.Second)), "before the window")
+	assert.EqualValues(t, 80, GetEffectivePrice(price, opens), "window start")
+	assert.EqualValues(t, 80, GetEffectivePrice(price, end), "window end")
+	assert.EqualValues(t, 100, GetEffectivePrice(price, end.Add(time.Second)), "after the window")
+
+	price.DiscountStart = nil
+	assert.EqualValues(t, 80, GetEffectivePrice(price, start.AddDate(-1, 0, 0)), "open start")
+}
+
+// TestDiscountDatesAcrossClockChanges verifies a window of dates covers
+// whole days in Zagreb, including the 23 hour 2024-03-31 and the 25 hour
+// 2024-10-27.
+func TestDiscountDatesAcrossClockChanges(t *testing.T) {
+	date := func(month time.Month, day int) *time.Time {
+		d := time.Date(2024, month, day, 0, 0, 0, 0, time.UTC)
+		return &d
+	}
+	price := CachedPrice{Price: 100, DiscountPrice: 80, HasDiscount: true}
+
+	// Starting the day clocks go forward, ending the day they go back
+	price.DiscountStart, price.DiscountEnd = date(3, 31), date(10, 27)
+	tests := []struct {
+		at     time.Time
+		active bool
+	}{
+		{time.Date(2024, 3, 30, 22, 59, 59, 0, time.UTC), false}, // 23:59:59 CET on the 30th
+		{time.Date(2024, 3, 30, 23, 0, 0, 0, time.UTC), true},    // Midnight CET
+		{time.Date(2024, 10, 27, 22, 59, 59, 0, time.UTC), true}, // 23:59:59 CET on the 27th
+		{time.Date(2024, 10, 27, 23, 0, 0, 0, time.UTC), false},  // Midnight CET
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.active, price.DiscountActive(tt.at), "at %s", tt.at)
+	}
+
+	// A one-day window the day clocks go forward lasts 23 hours
+	price.DiscountStart, price.DiscountEnd = date(3, 31), date(3, 31)
+	assert.True(t, price.DiscountActive(time.Date(2024, 3, 31, 21, 59, 59, 0, time.UTC)))
+	assert.False(t, price.DiscountActive(time.Date(2024, 3, 31, 22, 0, 0, 0, time.UTC)))
+
+	// Bounds with a time are exact
+	exact := time.Date(2024, 10, 27, 12, 30, 0, 0, time.UTC)
+	price.DiscountStart, price.DiscountEnd = nil, &exact
+	assert.True(t, price.DiscountActive(exact))
+	assert.False(t, price.DiscountActive(exact.Add(time.Second)))
+}
+
+// TestExpiredDiscountDoesNotWin verifies an expired promotion no longer makes
+// its store the cheapest.
+func TestExpiredDiscountDoesNotWin(t *testing.T) {
+	now := time.Date(2025, 8, 10, 12, 0, 0, 0, time.UTC)
+	expired := now.Add(-time.Hour)
+
+	discount := 90
+	mock := newMockPriceSource()
+	mock.setPrice("chain", "promo", "milk", 150, &discount)
+	mock.setPrice("chain", "regular", "milk", 120, nil)
+	promo := mock.prices["chain"]["promo"]["milk"]
+	promo.DiscountEnd = &expired
+	mock.prices["chain"]["promo"]["milk"] = promo
+
+	o := NewSingleStoreOptimizer(mock, DefaultOptimizerConfig())
+	o.SetClock(func() time.Time { return now })
+
+	results, _, err := o.Optimize(context.Background(), &OptimizeRequest{
+		ChainSlug:   "chain",
+		BasketItems: []*BasketItem{{ItemID: "milk", Name: "Milk", Quantity: 1}},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	assert.Equal(t, "regular", results[0].StoreID)
+
+	for _, r := range results {
+		if r.StoreID == "promo" {
+			assert.EqualValues(t, 150, r.Items[0].EffectivePrice)
+			assert.False(t, r.Items[0].HasDiscount)
+		}
+	}
+
+	// Before it expired the promotion wins
+	o.SetClock(func() time.Time { return expired.Add(-time.Hour) })
+	results, _, err = o.Optimize(context.Background(), &OptimizeRequest{
+		ChainSlug:   "chain",
+		BasketItems: []*BasketItem{{ItemID: "milk", Name: "Milk", Quantity: 1}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "promo", results[0].StoreID)
+}
+
 // TestRequestValidation verifies request validation.
 func TestRequestValidation(t *testing.T) {
 	config := DefaultOptimizerConfig()
@@ -431,6 +653,27 @@ func TestRequestValidation(t *testing.T) {
 			expectError: true,
 			errorMsg:    "chainSlug",
 		},
+		{
+			name: "Cross-chain request",
+			req: &OptimizeRequest{
+				ChainSlugs: []string{"lidl", "konzum"},
+				BasketItems: []*BasketItem{
+					{ItemID: "item-1", Name: "Item 1", Quantity: 1},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Empty chain in cross-chain request",
+			req: &OptimizeRequest{
+				ChainSlugs: []string{"lidl", ""},
+				BasketItems: []*BasketItem{
+					{ItemID: "item-1", Name: "Item 1", Quantity: 1},
+				},
+			},
+			expectError: true,
+			errorMsg:    "chainSlugs",
+		},
 		{
 			name:        "No items",
 			req:         &OptimizeRequest{ChainSlug: "test-chain", BasketItems: []*BasketItem{}},
@@ -483,6 +726,30 @@ func TestRequestValidation(t *testing.T) {
 			expectError: true,
 			errorMsg:    "longitude",
 		},
+		{
+			name: "Invalid minimum coverage",
+			req: &OptimizeRequest{
+				ChainSlug: "test-chain",
+				BasketItems: []*BasketItem{
+					{ItemID: "item-1", Name: "Item 1", Quantity: 1},
+				},
+				MinCoverage: 1.5,
+			},
+			expectError: true,
+			errorMsg:    "minCoverage",
+		},
+		{
+			name: "Negative offset",
+			req: &OptimizeRequest{
+				ChainSlug: "test-chain",
+				BasketItems: []*BasketItem{
+					{ItemID: "item-1", Name: "Item 1", Quantity: 1},
+				},
+				Offset: -1,
+			},
+			expectError: true,
+			errorMsg:    "offset",
+		},
 	}
 
 	for _, tt := range tests {
@@ -498,6 +765,56 @@ func TestRequestValidation(t *testing.T) {
 	}
 }
 
+// TestMinCoverageAndPaging verifies stores below the minimum coverage are left
+// out and the rest are paged in ranking order.
+func TestMinCoverageAndPaging(t *testing.T) {
+	mock := newMockPriceSource()
+	optimizer := NewSingleStoreOptimizer(mock, DefaultOptimizerConfig())
+
+	// Stores a-c carry both items, cheapest first; d carries one
+	mock.setPrice("test-chain", "store-a", "item-1", 100, nil)
+	mock.setPrice("test-chain", "store-a", "item-2", 100, nil)
+	mock.setPrice("test-chain", "store-b", "item-1", 110, nil)
+	mock.setPrice("test-chain", "store-b", "item-2", 110, nil)
+	mock.setPrice("test-chain", "store-c", "item-1", 120, nil)
+	mock.setPrice("test-chain", "store-c", "item-2", 120, nil)
+	mock.setPrice("test-chain", "store-d", "item-1", 10, nil)
+
+	req := &OptimizeRequest{
+		ChainSlug: "test-chain",
+		BasketItems: []*BasketItem{
+			{ItemID: "item-1", Name: "Item 1", Quantity: 1},
+			{ItemID: "item-2", Name: "Item 2", Quantity: 1},
+		},
+	}
+	storeIDs := func(results []*SingleStoreResult) []string {
+		ids := make([]string, len(results))
+		for i, r := range results {
+			ids[i] = r.StoreID
+		}
+		return ids
+	}
+
+	results, total, err := optimizer.Optimize(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"store-a", "store-b", "store-c", "store-d"}, storeIDs(results))
+	assert.Equal(t, 4, total)
+
+	req.MinCoverage = 0.9
+	req.Offset = 1
+	req.Limit = 1
+	results, total, err = optimizer.Optimize(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"store-b"}, storeIDs(results))
+	assert.Equal(t, 3, total, "counts every page")
+
+	req.Offset = 3
+	results, total, err = optimizer.Optimize(context.Background(), req)
+	require.NoError(t, err)
+	assert.Empty(t, results, "past the last page")
+	assert.Equal(t, 3, total)
+}
+
 // TestSingleStoreContextCancellation verifies that context cancellation is handled.
 func TestSingleStoreContextCancellation(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -519,7 +836,7 @@ func TestSingleStoreContextCancellation(t *testing.T) {
 	// Cancel context immediately
 	cancel()
 
-	_, err := optimizer.Optimize(ctx, req)
+	_, _, err := optimizer.Optimize(ctx, req)
 
 	if err != nil {
 		assert.ErrorIs(t, err, context.Canceled)
@@ -0,0 +1,398 @@
+package optimizer
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// groupPriceTable holds one price group's prices in columnar form.
+//
+// items holds ordinals into the snapshot's itemIDs, sorted ascending, and
+// prices and discountPrices are parallel to it. An entry costs 12 bytes and the
+// table is three pointer-free slices, so the GC never scans it; the nested
+// map it replaces cost well over 100 bytes per entry once bucket overhead,
+// the key header and the padded CachedPrice were counted.
+type groupPriceTable struct {
+	items          []int32
+	prices         []int32
+	discountPrices []int32 // equal to the price when there is no discount
+
+	// windows holds the validity window of discounts that have one, by
+	// position in items. Most discounts carry none, so it stays small.
+	windows map[int32]discountWindow
+}
+
+// discountWindow is when a discount applies, in Unix seconds. A zero bound is
+// open.
+type discountWindow struct {
+	start int64
+	end   int64
+}
+
+// newDiscountWindow returns the window between start and end, either of which
+// may be unknown.
+func newDiscountWindow(start, end *time.Time) discountWindow {
+	var w discountWindow
+	if start != nil {
+		w.start = start.Unix()
+	}
+	if end != nil {
+		w.end = end.Unix()
+	}
+	return w
+}
+
+// contains reports whether the window includes t.
+func (w discountWindow) contains(t time.Time) bool {
+	unix := t.Unix()
+	return (w.start == 0 || unix >= w.start) && (w.end == 0 || unix <= w.end)
+}
+
+// bounds returns the window's start and end, nil when open.
+func (w discountWindow) bounds() (start, end *time.Time) {
+	if w.start != 0 {
+		t := time.Unix(w.start, 0).UTC()
+		start = &t
+	}
+	if w.end != 0 {
+		t := time.Unix(w.end, 0).UTC()
+		end = &t
+	}
+	return start, end
+}
+
+// get returns the price of the item with the given ordinal.
+func (t *groupPriceTable) get(ordinal int32) (CachedPrice, bool) {
+	i, ok := slices.BinarySearch(t.items, ordinal)
+	if !ok {
+		return CachedPrice{}, false
+	}
+	p := CachedPrice{
+		Price:         int64(t.prices[i]),
+		DiscountPrice: int64(t.discountPrices[i]),
+		HasDiscount:   t.discountPrices[i] != t.prices[i],
+	}
+	if window, ok := t.windows[int32(i)]; ok {
+		p.DiscountStart, p.DiscountEnd = window.bounds()
+	}
+	return p, true
+}
+
+// window returns the validity window of the discount at position i.
+func (t *groupPriceTable) window(i int) discountWindow {
+	return t.windows[int32(i)]
+}
+
+// len returns the number of priced items in the group.
+func (t *groupPriceTable) len() int {
+	return len(t.items)
+}
+
+// groupPriceRow is a scanned group price awaiting the columnar build.
+type groupPriceRow struct {
+	itemID        string
+	price         int32
+	discountPrice int32
+	window        discountWindow
+}
+
+// snapshotBuilder accumulates scanned rows and turns them into an immutable
+// ChainCacheSnapshot. All IDs go through one interner so the snapshot retains
+// a single copy of each.
+type snapshotBuilder struct {
+	snapshot  *ChainCacheSnapshot
+	interner  *stringInterner
+	groupRows map[string][]groupPriceRow
+	itemStats map[string]ItemPriceStats
+	unstocked map[string][]string
+}
+
+func newSnapshotBuilder() *snapshotBuilder {
+	return &snapshotBuilder{
+		snapshot: &ChainCacheSnapshot{
+			groupPrices:    make(map[string]*groupPriceTable),
+			storeToGroup:   make(map[string]string),
+			exceptions:     make(map[string]map[string]CachedPrice),
+			storeLocations: make(map[string]Location),
+			itemCategories: make(map[string]string),
+		},
+		interner:  newStringInterner(1024),
+		groupRows: make(map[string][]groupPriceRow),
+		itemStats: make(map[string]ItemPriceStats),
+		unstocked: make(map[string][]string),
+	}
+}
+
+// addStore records a store's current price group and, when known, location.
+func (b *snapshotBuilder) addStore(storeID, groupID string, lat, lon *float64) {
+	storeID, groupID = b.interner.intern(storeID), b.interner.intern(groupID)
+	b.snapshot.storeToGroup[storeID] = groupID
+	if lat != nil && lon != nil {
+		b.snapshot.storeLocations[storeID] = Location{
+			Latitude:  *lat,
+			Longitude: *lon,
+		}
+	}
+}
+
+// addGroupPrice records an item's price in a price group.
+func (b *snapshotBuilder) addGroupPrice(groupID, itemID string, price int, discountPrice *int) {
+	b.addGroupPriceWindow(groupID, itemID, price, discountPrice, nil, nil)
+}
+
+// addGroupPriceWindow records an item's price in a price group along with the
+// validity window of its discount, either bound of which may be unknown.
+func (b *snapshotBuilder) addGroupPriceWindow(groupID, itemID string, price int, discountPrice *int, discountStart, discountEnd *time.Time) {
+	groupID, itemID = b.interner.intern(groupID), b.interner.intern(itemID)
+	b.groupRows[groupID] = append(b.groupRows[groupID], newGroupPriceRow(itemID, price, discountPrice, discountStart, discountEnd))
+}
+
+// newGroupPriceRow builds a group price row, keeping the discount window only
+// for an applicable discount.
+func newGroupPriceRow(itemID string, price int, discountPrice *int, discountStart, discountEnd *time.Time) groupPriceRow {
+	p := newCachedPrice(price, discountPrice, false)
+	row := groupPriceRow{
+		itemID:        itemID,
+		price:         int32(p.Price),
+		discountPrice: int32(p.DiscountPrice),
+	}
+	if p.HasDiscount {
+		row.window = newDiscountWindow(discountStart, discountEnd)
+	}
+	return row
+}
+
+// addException records a store-specific price override.
+func (b *snapshotBuilder) addException(storeID, itemID string, price int, discountPrice *int) {
+	storeID, itemID = b.interner.intern(storeID), b.interner.intern(itemID)
+	if b.snapshot.exceptions[storeID] == nil {
+		b.snapshot.exceptions[storeID] = make(map[string]CachedPrice)
+	}
+	b.snapshot.exceptions[storeID][itemID] = newCachedPrice(price, discountPrice, true)
+}
+
+// addUnstocked records that a store does not carry an item its group prices.
+func (b *snapshotBuilder) addUnstocked(storeID, itemID string) {
+	storeID = b.interner.intern(storeID)
+	b.unstocked[storeID] = append(b.unstocked[storeID], b.interner.intern(itemID))
+}
+
+// addItemStats records an item's chain-wide price statistics.
+func (b *snapshotBuilder) addItemStats(itemID string, stats ItemPriceStats) {
+	b.itemStats[b.interner.intern(itemID)] = stats
+}
+
+// addItemCategory records an item's category.
+func (b *snapshotBuilder) addItemCategory(itemID, category string) {
+	b.snapshot.itemCategories[b.interner.intern(itemID)] = b.interner.intern(category)
+}
+
+// mergeGroupPrices moves a shard's group prices into b. Shards scan disjoint
+// groups, so rows are appended as they are; IDs are re-interned so the merged
+// snapshot still holds one copy of each. The shard must not be used afterwards.
+func (b *snapshotBuilder) mergeGroupPrices(shard *snapshotBuilder) {
+	// Map each distinct shard string to its canonical copy once, so the
+	// interner stats are not inflated by rows the shard already deduplicated
+	for s := range shard.interner.strings {
+		shard.interner.strings[s] = b.interner.intern(s)
+	}
+	b.interner.savedBytes += shard.interner.savedBytes
+
+	for groupID, rows := range shard.groupRows {
+		for i := range rows {
+			rows[i].itemID = shard.interner.strings[rows[i].itemID]
+		}
+		groupID = shard.interner.strings[groupID]
+		b.groupRows[groupID] = append(b.groupRows[groupID], rows...)
+	}
+
+	shard.snapshot, shard.interner, shard.groupRows, shard.itemStats = nil, nil, nil, nil
+}
+
+// build indexes the group prices and item statistics. The builder must not be
+// used afterwards.
+func (b *snapshotBuilder) build() *ChainCacheSnapshot {
+	s := b.snapshot
+
+	// Chain-wide item index: every item priced in at least one group. The
+	// statistics come from the same snapshot, so they cover the same items.
+	ordinals := make(map[string]int32, len(b.itemStats))
+	for itemID := range b.itemStats {
+		ordinals[itemID] = 0
+	}
+	for _, rows := range b.groupRows {
+		for _, row := range rows {
+			ordinals[row.itemID] = 0
+		}
+	}
+	s.itemIDs = make([]string, 0, len(ordinals))
+	for itemID := range ordinals {
+		s.itemIDs = append(s.itemIDs, itemID)
+	}
+	slices.Sort(s.itemIDs)
+	for i, itemID := range s.itemIDs {
+		ordinals[itemID] = int32(i)
+	}
+
+	for groupID, rows := range b.groupRows {
+		s.groupPrices[groupID], _ = newGroupPriceTable(rows, func(itemID string) (int32, bool) {
+			ordinal, ok := ordinals[itemID]
+			return ordinal, ok
+		})
+	}
+
+	s.unstocked = make(map[string][]int32, len(b.unstocked))
+	for storeID, itemIDs := range b.unstocked {
+		items := make([]int32, 0, len(itemIDs))
+		for _, itemID := range itemIDs {
+			if ordinal, ok := ordinals[itemID]; ok {
+				items = append(items, ordinal)
+			}
+		}
+		slices.Sort(items)
+		s.unstocked[storeID] = slices.Compact(items)
+	}
+
+	s.itemStats = make([]ItemPriceStats, len(s.itemIDs))
+	for itemID, stats := range b.itemStats {
+		s.itemStats[ordinals[itemID]] = stats
+	}
+
+	// Category averages over the categorized items that are priced
+	categorySums := make(map[string]int64)
+	categoryCounts := make(map[string]int64)
+	for itemID, category := range s.itemCategories {
+		ordinal, ok := ordinals[itemID]
+		if !ok || s.itemStats[ordinal].WeightedAverage == 0 {
+			continue
+		}
+		categorySums[category] += s.itemStats[ordinal].WeightedAverage
+		categoryCounts[category]++
+	}
+	s.categoryAverages = make(map[string]int64, len(categorySums))
+	for category, sum := range categorySums {
+		s.categoryAverages[category] = sum / categoryCounts[category]
+	}
+
+	s.storeIndex = newStoreIndex(s.storeLocations)
+
+	s.internedStrings = b.interner.len()
+	s.internedBytes = b.interner.uniqueBytes
+	s.internSavedBytes = b.interner.savedBytes
+
+	b.snapshot, b.interner, b.groupRows, b.itemStats, b.unstocked = nil, nil, nil, nil, nil
+	return s
+}
+
+// newGroupPriceTable indexes a group's price rows, reordering them, by the
+// item ordinals ordinal returns. It reports false when an item has none.
+func newGroupPriceTable(rows []groupPriceRow, ordinal func(itemID string) (int32, bool)) (*groupPriceTable, bool) {
+	slices.SortStableFunc(rows, func(a, b groupPriceRow) int {
+		return strings.Compare(a.itemID, b.itemID)
+	})
+	// A later row for the same item replaces the earlier one, as it did
+	// when group prices were kept in a map
+	n := 0
+	for _, row := range rows {
+		if n > 0 && rows[n-1].itemID == row.itemID {
+			rows[n-1] = row
+			continue
+		}
+		rows[n] = row
+		n++
+	}
+	rows = rows[:n]
+
+	table := &groupPriceTable{
+		items:          make([]int32, len(rows)),
+		prices:         make([]int32, len(rows)),
+		discountPrices: make([]int32, len(rows)),
+	}
+	for i, row := range rows {
+		item, ok := ordinal(row.itemID)
+		if !ok {
+			return nil, false
+		}
+		table.items[i] = item
+		table.prices[i] = row.price
+		table.discountPrices[i] = row.discountPrice
+		if row.window != (discountWindow{}) {
+			if table.windows == nil {
+				table.windows = make(map[int32]discountWindow)
+			}
+			table.windows[int32(i)] = row.window
+		}
+	}
+	return table, true
+}
+
+// itemOrdinal returns the position of itemID in the snapshot's item index.
+func (s *ChainCacheSnapshot) itemOrdinal(itemID string) (int32, bool) {
+	i, ok := slices.BinarySearch(s.itemIDs, itemID)
+	return int32(i), ok
+}
+
+// groupSize is a price group and the number of items it prices.
+type groupSize struct {
+	id    string
+	items int
+}
+
+// partitionGroups splits groups, sorted by ID, into at most n contiguous
+// ranges holding roughly equal numbers of items.
+func partitionGroups(groups []groupSize, n int) [][]string {
+	if n > len(groups) {
+		n = len(groups)
+	}
+	if n <= 0 {
+		return nil
+	}
+
+	var total int64
+	for _, g := range groups {
+		total += int64(max(g.items, 1))
+	}
+
+	partitions := make([][]string, 0, n)
+	var current []string
+	var acc int64
+	for i, g := range groups {
+		weight := int64(max(g.items, 1))
+
+		// Close the current partition when its boundary is nearer before g
+		// than after it, or when each remaining group needs a partition
+		remainingParts := n - len(partitions) - 1
+		if len(current) > 0 && remainingParts > 0 {
+			boundary := total * int64(len(partitions)+1)
+			under := boundary - acc*int64(n)
+			over := (acc+weight)*int64(n) - boundary
+			if over > under || len(groups)-i == remainingParts {
+				partitions = append(partitions, current)
+				current = nil
+			}
+		}
+
+		current = append(current, g.id)
+		acc += weight
+	}
+	if len(current) > 0 {
+		partitions = append(partitions, current)
+	}
+	return partitions
+}
+
+// newCachedPrice builds a cached price, ignoring discounts that are not
+// strictly below the regular price.
+func newCachedPrice(price int, discountPrice *int, isException bool) CachedPrice {
+	p := CachedPrice{
+		Price:         int64(price),
+		DiscountPrice: int64(price),
+		IsException:   isException,
+	}
+	if discountPrice != nil && *discountPrice > 0 && *discountPrice < price {
+		p.DiscountPrice = int64(*discountPrice)
+		p.HasDiscount = true
+	}
+	return p
+}
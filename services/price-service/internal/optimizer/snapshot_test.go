@@ -0,0 +1,141 @@
+package optimizer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotBuilderColumnarLookups(t *testing.T) {
+	b := newSnapshotBuilder()
+	discount := 80
+	noDiscount := 250
+
+	b.addStore("sto-a", "grp-1", nil, nil)
+	b.addStore("sto-b", "grp-2", nil, nil)
+	b.addGroupPrice("grp-1", "itm-c", 300, nil)
+	b.addGroupPrice("grp-1", "itm-a", 100, &discount)
+	b.addGroupPrice("grp-2", "itm-b", 200, &noDiscount) // not below the price
+	b.addGroupPrice("grp-2", "itm-a", 200, nil)
+	b.addGroupPrice("grp-2", "itm-a", 120, nil) // later row wins
+	b.addException("sto-b", "itm-c", 50, nil)
+	b.addItemStats("itm-a", ItemPriceStats{Average: 110, WeightedAverage: 115, Median: 110, Min: 100, Max: 120})
+	b.addItemStats("itm-b", ItemPriceStats{Average: 200, Median: 200, Min: 200, Max: 200})
+	b.addItemStats("itm-c", ItemPriceStats{Average: 300, WeightedAverage: 305, Median: 300, Min: 300, Max: 300})
+	b.addItemCategory("itm-a", "dairy")
+	b.addItemCategory("itm-c", "dairy")
+	b.addItemCategory("itm-unpriced", "bakery")
+
+	s := b.build()
+	assert.Equal(t, []string{"itm-a", "itm-b", "itm-c"}, s.itemIDs)
+	assert.Equal(t, []int32{0, 2}, s.groupPrices["grp-1"].items)
+
+	cache := &PriceCache{chains: map[string]*ChainCache{"test": {}}}
+	cache.chains["test"].snapshot.Store(s)
+
+	price, ok := cache.GetPrice("test", "sto-a", "itm-a")
+	require.True(t, ok)
+	assert.Equal(t, CachedPrice{Price: 100, DiscountPrice: 80, HasDiscount: true}, price)
+
+	price, ok = cache.GetPrice("test", "sto-b", "itm-b")
+	require.True(t, ok)
+	assert.Equal(t, CachedPrice{Price: 200, DiscountPrice: 200}, price)
+
+	price, ok = cache.GetPrice("test", "sto-b", "itm-a")
+	require.True(t, ok)
+	assert.EqualValues(t, 120, price.Price)
+
+	price, ok = cache.GetPrice("test", "sto-b", "itm-c")
+	require.True(t, ok)
+	assert.True(t, price.IsException)
+	assert.EqualValues(t, 50, price.Price)
+
+	// Known item, but not priced in this store's group
+	_, ok = cache.GetPrice("test", "sto-a", "itm-b")
+	assert.False(t, ok)
+	_, ok = cache.GetPrice("test", "sto-a", "itm-missing")
+	assert.False(t, ok)
+
+	assert.EqualValues(t, 110, cache.GetAveragePrice("test", "itm-a"))
+	assert.EqualValues(t, 300, cache.GetAveragePrice("test", "itm-c"))
+	assert.Zero(t, cache.GetAveragePrice("test", "itm-missing"))
+	assert.EqualValues(t, 115, cache.GetWeightedAveragePrice("test", "itm-a"))
+
+	stats, ok := cache.GetItemPriceStats("test", "itm-a")
+	require.True(t, ok)
+	assert.Equal(t, ItemPriceStats{Average: 110, WeightedAverage: 115, Median: 110, Min: 100, Max: 120}, stats)
+	_, ok = cache.GetItemPriceStats("test", "itm-missing")
+	assert.False(t, ok)
+
+	category, ok := cache.GetItemCategory("test", "itm-unpriced")
+	require.True(t, ok)
+	assert.Equal(t, "bakery", category)
+	_, ok = cache.GetItemCategory("test", "itm-b")
+	assert.False(t, ok)
+	assert.EqualValues(t, (115+305)/2, cache.GetCategoryAveragePrice("test", "dairy"))
+	assert.Zero(t, cache.GetCategoryAveragePrice("test", "bakery"))
+}
+
+func TestPartitionGroups(t *testing.T) {
+	groups := []groupSize{
+		{"g1", 1000}, {"g2", 10}, {"g3", 10}, {"g4", 980}, {"g5", 0}, {"g6", 1000},
+	}
+
+	assert.Nil(t, partitionGroups(nil, 4))
+	assert.Equal(t, [][]string{{"g1", "g2", "g3", "g4", "g5", "g6"}}, partitionGroups(groups, 1))
+	assert.Equal(t, [][]string{{"g1", "g2", "g3"}, {"g4", "g5", "g6"}}, partitionGroups(groups, 2))
+	assert.Equal(t, [][]string{{"g1"}, {"g2", "g3", "g4", "g5"}, {"g6"}}, partitionGroups(groups, 3))
+
+	// Never more partitions than groups, and every group exactly once
+	parts := partitionGroups(groups[:3], 8)
+	assert.Equal(t, [][]string{{"g1"}, {"g2"}, {"g3"}}, parts)
+}
+
+func TestSnapshotBuilderMergeGroupPrices(t *testing.T) {
+	b := newSnapshotBuilder()
+	b.addStore("sto-a", "grp-1", nil, nil)
+
+	first, second := newSnapshotBuilder(), newSnapshotBuilder()
+	first.addGroupPrice("grp-1", "itm-a", 100, nil)
+	first.addGroupPrice("grp-1", "itm-b", 200, nil)
+	second.addGroupPrice("grp-2", "itm-a", 300, nil)
+	second.addGroupPrice("grp-3", "itm-a", 500, nil)
+
+	b.mergeGroupPrices(first)
+	b.mergeGroupPrices(second)
+	s := b.build()
+
+	assert.Equal(t, []string{"itm-a", "itm-b"}, s.itemIDs)
+	assert.Len(t, s.groupPrices, 3)
+	assert.Equal(t, 6, s.internedStrings)
+	// grp-1 is deduplicated within the first shard and again on merge, as
+	// is itm-a within the second shard and on merge
+	assert.EqualValues(t, 2*len("grp-1")+2*len("itm-a"), s.internSavedBytes)
+}
+
+func TestSnapshotUnstockedItemsAreMissing(t *testing.T) {
+	b := newSnapshotBuilder()
+	b.addStore("sto-full", "grp-1", nil, nil)
+	b.addStore("sto-partial", "grp-1", nil, nil)
+	b.addGroupPrice("grp-1", "itm-a", 100, nil)
+	b.addGroupPrice("grp-1", "itm-b", 200, nil)
+	b.addUnstocked("sto-partial", "itm-b")
+	b.addUnstocked("sto-partial", "itm-unpriced")
+	b.addException("sto-partial", "itm-c", 50, nil)
+
+	s := b.build()
+	assert.Equal(t, []int32{1}, s.unstocked["sto-partial"])
+
+	cache := &PriceCache{chains: map[string]*ChainCache{"test": {}}}
+	cache.chains["test"].snapshot.Store(s)
+
+	_, ok := cache.GetPrice("test", "sto-full", "itm-b")
+	assert.True(t, ok)
+	_, ok = cache.GetPrice("test", "sto-partial", "itm-a")
+	assert.True(t, ok)
+	_, ok = cache.GetPrice("test", "sto-partial", "itm-b")
+	assert.False(t, ok, "priced in the group but not in the store's assortment")
+	_, ok = cache.GetPrice("test", "sto-partial", "itm-c")
+	assert.True(t, ok, "exceptions are store-specific, so the store carries the item")
+}
@@ -0,0 +1,211 @@
+package optimizer
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// gridCellDeg is the side of a grid index cell in degrees, about 5.5 km of
+// latitude. Cells hold a few stores in cities and none across most of the map.
+const gridCellDeg = 0.05
+
+// spatialIndex finds a chain's stores nearest to a point. Implementations are
+// immutable once built and safe for concurrent queries.
+type spatialIndex interface {
+	// nearest returns up to limit stores within maxDistanceKm, nearest first
+	// and by store ID at equal distance. A limit or maxDistanceKm of 0 or
+	// less means no limit.
+	nearest(lat, lon, maxDistanceKm float64, limit int) []StoreWithDistance
+
+	// sizeBytes is the approximate memory the index holds beyond the
+	// snapshot's store IDs
+	sizeBytes() int64
+}
+
+// newStoreIndex builds the spatial index of a snapshot's store locations.
+func newStoreIndex(locations map[string]Location) spatialIndex {
+	return newGridIndex(locations, gridCellDeg)
+}
+
+// indexedStore is a store location held by an index
+type indexedStore struct {
+	storeID  string
+	location Location
+}
+
+// distanceFrom returns the store with its distance from a point
+func (s indexedStore) distanceFrom(lat, lon float64) StoreWithDistance {
+	return StoreWithDistance{
+		StoreID:  s.storeID,
+		Distance: HaversineKm(lat, lon, s.location.Latitude, s.location.Longitude),
+	}
+}
+
+// scanIndex computes the distance of every store on each query. It is the
+// reference the other indexes must agree with.
+type scanIndex []indexedStore
+
+func newScanIndex(locations map[string]Location) scanIndex {
+	index := make(scanIndex, 0, len(locations))
+	for storeID, location := range locations {
+		index = append(index, indexedStore{storeID: storeID, location: location})
+	}
+	return index
+}
+
+func (s scanIndex) nearest(lat, lon, maxDistanceKm float64, limit int) []StoreWithDistance {
+	var found []StoreWithDistance
+	for _, store := range s {
+		if d := store.distanceFrom(lat, lon); maxDistanceKm <= 0 || d.Distance <= maxDistanceKm {
+			found = append(found, d)
+		}
+	}
+	return nearestFirst(found, limit)
+}
+
+func (s scanIndex) sizeBytes() int64 {
+	return 24 + int64(len(s))*(stringHeaderBytes+16)
+}
+
+// gridCell identifies a cell of a grid index by its row and column
+type gridCell struct {
+	row, col int32
+}
+
+// gridIndex buckets stores into cells of equal latitude and longitude
+// degrees. A query visits rings of cells around the point's cell, nearest
+// first, until the stores found are closer than anything outside the rings.
+type gridIndex struct {
+	cellDeg  float64
+	cells    map[gridCell][]indexedStore
+	min, max gridCell // Bounds of the non-empty cells
+	stores   int
+}
+
+func newGridIndex(locations map[string]Location, cellDeg float64) *gridIndex {
+	g := &gridIndex{
+		cellDeg: cellDeg,
+		cells:   make(map[gridCell][]indexedStore),
+		stores:  len(locations),
+	}
+	first := true
+	for storeID, location := range locations {
+		cell := g.cellOf(location.Latitude, location.Longitude)
+		g.cells[cell] = append(g.cells[cell], indexedStore{storeID: storeID, location: location})
+		if first {
+			g.min, g.max, first = cell, cell, false
+			continue
+		}
+		g.min = gridCell{min(g.min.row, cell.row), min(g.min.col, cell.col)}
+		g.max = gridCell{max(g.max.row, cell.row), max(g.max.col, cell.col)}
+	}
+	return g
+}
+
+// cellOf returns the cell a point falls in
+func (g *gridIndex) cellOf(lat, lon float64) gridCell {
+	return gridCell{
+		row: int32(math.Floor(lat / g.cellDeg)),
+		col: int32(math.Floor(lon / g.cellDeg)),
+	}
+}
+
+func (g *gridIndex) nearest(lat, lon, maxDistanceKm float64, limit int) []StoreWithDistance {
+	if g.stores == 0 {
+		return nil
+	}
+	if maxDistanceKm <= 0 && limit <= 0 {
+		// Every store, so every cell
+		found := make([]StoreWithDistance, 0, g.stores)
+		for _, stores := range g.cells {
+			for _, store := range stores {
+				found = append(found, store.distanceFrom(lat, lon))
+			}
+		}
+		return nearestFirst(found, 0)
+	}
+
+	center := g.cellOf(lat, lon)
+	var found []StoreWithDistance
+	visit := func(cell gridCell) {
+		for _, store := range g.cells[cell] {
+			if d := store.distanceFrom(lat, lon); maxDistanceKm <= 0 || d.Distance <= maxDistanceKm {
+				found = append(found, d)
+			}
+		}
+	}
+
+	for r := int32(0); ; r++ {
+		// The cells at Chebyshev distance r from the center, within the bounds
+		for row := max(center.row-r, g.min.row); row <= min(center.row+r, g.max.row); row++ {
+			if row == center.row-r || row == center.row+r {
+				for col := max(center.col-r, g.min.col); col <= min(center.col+r, g.max.col); col++ {
+					visit(gridCell{row, col})
+				}
+				continue
+			}
+			if col := center.col - r; col >= g.min.col {
+				visit(gridCell{row, col})
+			}
+			if col := center.col + r; col <= g.max.col {
+				visit(gridCell{row, col})
+			}
+		}
+
+		if center.row-r <= g.min.row && center.row+r >= g.max.row &&
+			center.col-r <= g.min.col && center.col+r >= g.max.col {
+			break // Every cell visited
+		}
+		outside := g.distanceOutside(lat, lon, center, r)
+		if maxDistanceKm > 0 && outside > maxDistanceKm {
+			break
+		}
+		if limit > 0 && len(found) >= limit {
+			found = nearestFirst(found, limit)
+			if found[limit-1].Distance < outside {
+				break
+			}
+		}
+	}
+	return nearestFirst(found, limit)
+}
+
+// distanceOutside returns a lower bound of the distance in km from a point
+// to any location outside the cells within Chebyshev distance r of its cell:
+// the distance to the nearest edge of that block, a parallel or a meridian.
+func (g *gridIndex) distanceOutside(lat, lon float64, center gridCell, r int32) float64 {
+	const R = 6371.0 // Earth radius km
+	south := float64(center.row-r) * g.cellDeg
+	north := float64(center.row+r+1) * g.cellDeg
+	west := float64(center.col-r) * g.cellDeg
+	east := float64(center.col+r+1) * g.cellDeg
+
+	toMeridian := func(dLon float64) float64 {
+		// Past a quarter turn of longitude the nearest such location is a pole
+		dLon = min(toRad(dLon), math.Pi/2)
+		return math.Asin(math.Cos(toRad(lat))*math.Sin(dLon)) * R
+	}
+	return min(
+		toRad(lat-south)*R,
+		toRad(north-lat)*R,
+		toMeridian(lon-west),
+		toMeridian(east-lon),
+	)
+}
+
+func (g *gridIndex) sizeBytes() int64 {
+	return 64 + int64(len(g.cells))*(64+8+24) + int64(g.stores)*(stringHeaderBytes+16)
+}
+
+// nearestFirst sorts stores nearest first, by store ID at equal distance, and
+// keeps the first limit when limit is positive.
+func nearestFirst(stores []StoreWithDistance, limit int) []StoreWithDistance {
+	slices.SortFunc(stores, func(a, b StoreWithDistance) int {
+		return cmp.Or(cmp.Compare(a.Distance, b.Distance), cmp.Compare(a.StoreID, b.StoreID))
+	})
+	if limit > 0 && len(stores) > limit {
+		stores = stores[:limit]
+	}
+	return stores
+}
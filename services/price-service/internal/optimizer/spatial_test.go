@@ -0,0 +1,156 @@
+package optimizer
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syntheticStoreLocations places stores across Croatia, most of them around
+// a few cities as real chains are.
+func syntheticStoreLocations(n int, seed uint64) map[string]Location {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	cities := []Location{
+		{Latitude: 45.815, Longitude: 15.982}, // Zagreb
+		{Latitude: 43.508, Longitude: 16.440}, // Split
+		{Latitude: 45.327, Longitude: 14.442}, // Rijeka
+		{Latitude: 45.555, Longitude: 18.696}, // Osijek
+	}
+
+	locations := make(map[string]Location, n)
+	for i := range n {
+		var location Location
+		if i%4 == 3 {
+			location = Location{Latitude: 42.4 + rng.Float64()*4.1, Longitude: 13.5 + rng.Float64()*5.9}
+		} else {
+			city := cities[rng.IntN(len(cities))]
+			location = Location{
+				Latitude:  city.Latitude + rng.NormFloat64()*0.05,
+				Longitude: city.Longitude + rng.NormFloat64()*0.07,
+			}
+		}
+		locations[fmt.Sprintf("sto-%04d", i)] = location
+	}
+	return locations
+}
+
+// TestGridIndexMatchesScan verifies the grid index finds the same stores as a
+// scan of every store.
+func TestGridIndexMatchesScan(t *testing.T) {
+	locations := syntheticStoreLocations(2500, 1)
+	grid := newGridIndex(locations, gridCellDeg)
+	scan := newScanIndex(locations)
+
+	points := []Location{
+		{Latitude: 45.813, Longitude: 15.977}, // In a city
+		{Latitude: 44.9, Longitude: 15.1},     // Between cities
+		{Latitude: 48.208, Longitude: 16.373}, // Outside the country
+		{Latitude: -33.9, Longitude: 151.2},   // Far away
+	}
+	queries := []struct {
+		maxDistanceKm float64
+		limit         int
+	}{
+		{0, 1}, {0, 5}, {0, 0}, {2, 0}, {10, 5}, {50, 1000}, {500, 20},
+	}
+
+	for _, p := range points {
+		for _, q := range queries {
+			name := fmt.Sprintf("%.1f,%.1f/%gkm/%d", p.Latitude, p.Longitude, q.maxDistanceKm, q.limit)
+			want := scan.nearest(p.Latitude, p.Longitude, q.maxDistanceKm, q.limit)
+			got := grid.nearest(p.Latitude, p.Longitude, q.maxDistanceKm, q.limit)
+			assert.Equal(t, want, got, name)
+		}
+	}
+}
+
+// TestGridIndexRandomQueries compares the grid index with a scan at random
+// points and cell sizes.
+func TestGridIndexRandomQueries(t *testing.T) {
+	rng := rand.New(rand.NewPCG(2, 2))
+	locations := syntheticStoreLocations(500, 3)
+	scan := newScanIndex(locations)
+
+	for _, cellDeg := range []float64{0.01, gridCellDeg, 0.5} {
+		grid := newGridIndex(locations, cellDeg)
+		for range 200 {
+			lat, lon := 42+rng.Float64()*5, 13+rng.Float64()*7
+			maxDistanceKm := float64(rng.IntN(60))
+			limit := rng.IntN(30)
+
+			want := scan.nearest(lat, lon, maxDistanceKm, limit)
+			got := grid.nearest(lat, lon, maxDistanceKm, limit)
+			require.Equal(t, want, got, "cell %g at %f,%f within %gkm, limit %d", cellDeg, lat, lon, maxDistanceKm, limit)
+		}
+	}
+}
+
+func TestGridIndexEmpty(t *testing.T) {
+	grid := newGridIndex(map[string]Location{}, gridCellDeg)
+	assert.Nil(t, grid.nearest(45.8, 15.9, 10, 5))
+}
+
+// TestStoreIndexFollowsDelta verifies a patched snapshot finds moved and
+// removed stores where they are now.
+func TestStoreIndexFollowsDelta(t *testing.T) {
+	s := invalidationSnapshot()
+	cache := &PriceCache{chains: map[string]*ChainCache{"test": {}}}
+	cache.chains["test"].snapshot.Store(s)
+	require.Len(t, cache.GetNearestStores("test", 45.81, 15.98, 1, 5), 1)
+
+	moved, err := s.applyDelta(&storeDelta{
+		storeID:  "sto-a",
+		groupID:  "grp-1",
+		location: &Location{Latitude: 43.51, Longitude: 16.44},
+	}, 10)
+	require.NoError(t, err)
+	cache.chains["test"].snapshot.Store(moved)
+	assert.Empty(t, cache.GetNearestStores("test", 45.81, 15.98, 1, 5))
+	nearest := cache.GetNearestStores("test", 43.5, 16.4, 10, 5)
+	require.Len(t, nearest, 1)
+	assert.Equal(t, "sto-a", nearest[0].StoreID)
+
+	removed, err := moved.applyDelta(&storeDelta{storeID: "sto-a"}, 10)
+	require.NoError(t, err)
+	cache.chains["test"].snapshot.Store(removed)
+	assert.Empty(t, cache.GetNearestStores("test", 43.5, 16.4, 10, 5))
+}
+
+// BenchmarkNearestStores compares the grid index with a scan for the queries
+// the optimizers make: the few nearest stores for multi-store candidates and
+// every store in range for single-store optimization.
+func BenchmarkNearestStores(b *testing.B) {
+	zagreb := Location{Latitude: 45.813, Longitude: 15.977}
+	queries := []struct {
+		name          string
+		maxDistanceKm float64
+		limit         int
+	}{
+		{"nearest5", 50, 5},
+		{"within10km", 10, 1000},
+		{"within50km", 50, 1000},
+	}
+
+	for _, stores := range []int{2000, 5000} {
+		locations := syntheticStoreLocations(stores, 1)
+		indexes := []struct {
+			name  string
+			index spatialIndex
+		}{
+			{"grid", newGridIndex(locations, gridCellDeg)},
+			{"scan", newScanIndex(locations)},
+		}
+		for _, q := range queries {
+			for _, idx := range indexes {
+				b.Run(fmt.Sprintf("%d/%s/%s", stores, q.name, idx.name), func(b *testing.B) {
+					for i := 0; i < b.N; i++ {
+						idx.index.nearest(zagreb.Latitude, zagreb.Longitude, q.maxDistanceKm, q.limit)
+					}
+				})
+			}
+		}
+	}
+}
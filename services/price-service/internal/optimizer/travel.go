@@ -0,0 +1,185 @@
+package optimizer
+
+import (
+	"maps"
+	"math"
+	"slices"
+)
+
+// TravelCost is what visiting a multi-store result's stores adds to its basket
+// total when combinations of stores are compared, in minor units.
+type TravelCost struct {
+	DistanceCost int64 // The round trip at TravelCostPerKm; 0 without a location
+	StoreCost    int64 // ExtraStoreCost for each store after the first
+	Total        int64
+}
+
+// travelModel prices the trips to combinations of a request's candidate
+// stores
+type travelModel struct {
+	perKm         int64
+	perExtraStore int64
+	origin        *Location           // Nil when distance costs nothing
+	locations     map[string]Location // Candidate locations by store ID
+}
+
+// newTravelModel returns the travel model of a request. Distance is only
+// priced with the request's location and the candidates' locations known.
+func (o *MultiStoreOptimizer) newTravelModel(req *OptimizeRequest, candidates []*candidateStore) *travelModel {
+	m := o.travelRates()
+	locator, ok := o.priceSource.(StoreLocator)
+	if req.Location == nil || !ok || m.perKm == 0 {
+		return m
+	}
+
+	m.origin = req.Location
+	m.locations = make(map[string]Location, len(candidates))
+	for _, candidate := range candidates {
+		if location, ok := locator.GetStoreLocation(candidate.chainSlug, candidate.storeID); ok {
+			m.locations[candidate.storeID] = location
+		}
+	}
+	return m
+}
+
+// travelRates returns a travel model pricing trips of a known length
+func (o *MultiStoreOptimizer) travelRates() *travelModel {
+	return &travelModel{perKm: o.config.TravelCostPerKm, perExtraStore: o.config.ExtraStoreCost}
+}
+
+// cost returns the travel cost of visiting stores, with the round trip
+// through those with a known location planned as planVisits plans it
+func (m *travelModel) cost(storeIDs []string) TravelCost {
+	km := 0.0
+	if m.origin != nil {
+		stops := make([]Location, 0, len(storeIDs))
+		for _, storeID := range storeIDs {
+			if location, ok := m.locations[storeID]; ok {
+				stops = append(stops, location)
+			}
+		}
+		if len(stops) > 0 {
+			_, km = visitRoute(*m.origin, stops)
+		}
+	}
+	return m.priced(len(storeIDs), km)
+}
+
+// priced returns the travel cost of a trip to a number of stores of a length
+// in km
+func (m *travelModel) priced(stores int, km float64) TravelCost {
+	var tc TravelCost
+	if stores > 1 {
+		tc.StoreCost = m.perExtraStore * int64(stores-1)
+	}
+	tc.DistanceCost = int64(math.Round(km * float64(m.perKm)))
+	tc.Total = tc.DistanceCost + tc.StoreCost
+	return tc
+}
+
+// resultStoreIDs returns the IDs of a result's stores
+func resultStoreIDs(result *MultiStoreResult) []string {
+	storeIDs := make([]string, len(result.Stores))
+	for i, store := range result.Stores {
+		storeIDs[i] = store.StoreID
+	}
+	return storeIDs
+}
+
+// consolidateStores cuts the travel of a greedy assignment. A single
+// candidate carrying every assigned item replaces the assignment when it costs
+// less with travel; otherwise stores are dropped while the travel cost saved
+// is more than moving their items to the other stores adds. Items only move
+// to stores carrying them, so coverage is unchanged.
+func (o *MultiStoreOptimizer) consolidateStores(
+	candidates []*candidateStore,
+	storeItems map[string][]*ItemAllocation,
+	model *travelModel,
+) {
+	if len(storeItems) < 2 || model.perKm == 0 && model.perExtraStore == 0 {
+		return
+	}
+	byID := make(map[string]*candidateStore, len(candidates))
+	for _, candidate := range candidates {
+		byID[candidate.storeID] = candidate
+	}
+
+	storeIDs := slices.Sorted(maps.Keys(storeItems))
+	var assigned []*ItemAllocation
+	for _, storeID := range storeIDs {
+		assigned = append(assigned, storeItems[storeID]...)
+	}
+	oneStop, bestCost := "", model.cost(storeIDs).Total
+	var oneStopItems []*ItemAllocation
+	for _, candidate := range candidates {
+		moves, added, ok := reassignItems(assigned, []string{candidate.storeID}, byID)
+		if !ok {
+			continue
+		}
+		if cost := added + model.cost([]string{candidate.storeID}).Total; cost < bestCost {
+			oneStop, bestCost, oneStopItems = candidate.storeID, cost, moves[candidate.storeID]
+		}
+	}
+	if oneStop != "" {
+		clear(storeItems)
+		storeItems[oneStop] = oneStopItems
+		return
+	}
+
+	for len(storeItems) > 1 {
+		storeIDs := slices.Sorted(maps.Keys(storeItems))
+		travel := model.cost(storeIDs).Total
+
+		dropID, bestGain := "", int64(0)
+		var bestMoves map[string][]*ItemAllocation
+		for _, storeID := range storeIDs {
+			others := slices.DeleteFunc(slices.Clone(storeIDs), func(id string) bool { return id == storeID })
+			moves, added, ok := reassignItems(storeItems[storeID], others, byID)
+			if !ok {
+				continue
+			}
+			if gain := travel - model.cost(others).Total - added; gain > bestGain {
+				dropID, bestGain, bestMoves = storeID, gain, moves
+			}
+		}
+		if dropID == "" {
+			return
+		}
+
+		delete(storeItems, dropID)
+		for storeID, items := range bestMoves {
+			storeItems[storeID] = append(storeItems[storeID], items...)
+		}
+	}
+}
+
+// reassignItems moves items to the cheapest of other stores carrying each.
+// It returns the items by their new store and how much they cost more, or
+// false when some item is carried by none of the stores.
+func reassignItems(items []*ItemAllocation, storeIDs []string, byID map[string]*candidateStore) (map[string][]*ItemAllocation, int64, bool) {
+	moves := make(map[string][]*ItemAllocation)
+	added := int64(0)
+	for _, item := range items {
+		bestStore, bestLine := "", int64(-1)
+		var bestInfo *ItemPriceInfo
+		for _, storeID := range storeIDs {
+			priceInfo, ok := byID[storeID].itemPrices[item.BasketItem.ItemID]
+			if !ok {
+				continue
+			}
+			if line := priceInfo.EffectivePrice * int64(item.BasketItem.Quantity); bestLine < 0 || line < bestLine {
+				bestStore, bestLine, bestInfo = storeID, line, priceInfo
+			}
+		}
+		if bestStore == "" {
+			return nil, 0, false
+		}
+		moves[bestStore] = append(moves[bestStore], &ItemAllocation{
+			BasketItem: item.BasketItem,
+			PriceInfo:  bestInfo,
+			LineTotal:  bestLine,
+		})
+		added += bestLine - item.LineTotal
+	}
+	return moves, added, true
+}
@@ -0,0 +1,140 @@
+package optimizer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExtraStoreCost verifies a second store is only added when it saves more
+// than the extra store cost, by both algorithms.
+func TestExtraStoreCost(t *testing.T) {
+	ctx := context.Background()
+	mock := newMockPriceSource()
+	mock.setPrice("test-chain", "store-a", "item-001", 100, nil)
+	mock.setPrice("test-chain", "store-a", "item-002", 100, nil)
+	mock.setPrice("test-chain", "store-b", "item-001", 80, nil)
+	mock.setPrice("test-chain", "store-c", "item-002", 80, nil)
+
+	req := &OptimizeRequest{
+		ChainSlug: "test-chain",
+		BasketItems: []*BasketItem{
+			{ItemID: "item-001", Name: "Item 1", Quantity: 1},
+			{ItemID: "item-002", Name: "Item 2", Quantity: 1},
+		},
+	}
+	candidates := createCandidatesFromMock(mock, []string{"store-a", "store-b", "store-c"}, req)
+
+	tests := []struct {
+		extraStoreCost int64
+		stores         []string
+		total          int64
+	}{
+		{0, []string{"store-b", "store-c"}, 160},
+		{30, []string{"store-b", "store-c"}, 160}, // Saves 40 for 30
+		{50, []string{"store-a"}, 200},            // Saves 40 for 50
+	}
+	for _, tt := range tests {
+		config := DefaultOptimizerConfig()
+		config.ExtraStoreCost = tt.extraStoreCost
+		o := NewMultiStoreOptimizer(mock, config, NewMetricsRecorder())
+
+		greedy, err := o.greedyAlgorithm(ctx, req, candidates)
+		require.NoError(t, err)
+		optimal, err := o.optimalAlgorithm(ctx, req, candidates)
+		require.NoError(t, err)
+
+		for name, result := range map[string]*MultiStoreResult{"greedy": greedy, "optimal": optimal} {
+			assert.ElementsMatch(t, tt.stores, resultStoreIDs(result), "%s at %d per store", name, tt.extraStoreCost)
+			assert.Equal(t, tt.total, result.CombinedTotal, "%s at %d per store", name, tt.extraStoreCost)
+			assert.Equal(t, 1.0, result.CoverageRatio)
+		}
+		assert.Equal(t, TravelCost{
+			StoreCost: tt.extraStoreCost * int64(len(tt.stores)-1),
+			Total:     tt.extraStoreCost * int64(len(tt.stores)-1),
+		}, greedy.TravelCost)
+	}
+}
+
+// TestConsolidateStoresDropsStore verifies greedy drops the store whose items
+// cost least more elsewhere, keeping the stores worth the trip.
+func TestConsolidateStoresDropsStore(t *testing.T) {
+	ctx := context.Background()
+	mock := newMockPriceSource()
+	mock.setPrice("test-chain", "store-a", "item-001", 50, nil)
+	mock.setPrice("test-chain", "store-a", "item-002", 50, nil)
+	mock.setPrice("test-chain", "store-b", "item-002", 45, nil)
+	mock.setPrice("test-chain", "store-b", "item-003", 40, nil)
+	mock.setPrice("test-chain", "store-c", "item-003", 38, nil)
+
+	req := &OptimizeRequest{
+		ChainSlug: "test-chain",
+		BasketItems: []*BasketItem{
+			{ItemID: "item-001", Name: "Item 1", Quantity: 1},
+			{ItemID: "item-002", Name: "Item 2", Quantity: 1},
+			{ItemID: "item-003", Name: "Item 3", Quantity: 1},
+		},
+	}
+	candidates := createCandidatesFromMock(mock, []string{"store-a", "store-b", "store-c"}, req)
+
+	config := DefaultOptimizerConfig()
+	config.ExtraStoreCost = 10
+	o := NewMultiStoreOptimizer(mock, config, NewMetricsRecorder())
+
+	// Without store-c item 3 costs 2 more and saves 10; without store-b item
+	// 2 costs 5 more. Neither of the two left can go.
+	greedy, err := o.greedyAlgorithm(ctx, req, candidates)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"store-a", "store-b"}, resultStoreIDs(greedy))
+	assert.Equal(t, int64(135), greedy.CombinedTotal)
+	assert.Equal(t, int64(10), greedy.TravelCost.Total)
+
+	optimal, err := o.optimalAlgorithm(ctx, req, candidates)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"store-a", "store-b"}, resultStoreIDs(optimal))
+}
+
+// TestTravelCostPerKm verifies a far cheaper store is chosen only when its
+// savings pay for the trip.
+func TestTravelCostPerKm(t *testing.T) {
+	ctx := context.Background()
+	mock := newMockPriceSource()
+	mock.setPrice("test-chain", "near", "item-001", 100, nil)
+	mock.setPrice("test-chain", "near", "item-002", 100, nil)
+	mock.setPrice("test-chain", "far", "item-001", 90, nil)
+	mock.setPrice("test-chain", "far", "item-002", 90, nil)
+	mock.storeLocations["test-chain"] = map[string]Location{
+		"near": {Latitude: 45.80, Longitude: 15.91}, // About 0.8 km
+		"far":  {Latitude: 45.80, Longitude: 16.20}, // About 22.5 km
+	}
+
+	req := &OptimizeRequest{
+		ChainSlug: "test-chain",
+		BasketItems: []*BasketItem{
+			{ItemID: "item-001", Name: "Item 1", Quantity: 1},
+			{ItemID: "item-002", Name: "Item 2", Quantity: 1},
+		},
+		Location: &Location{Latitude: 45.80, Longitude: 15.90},
+	}
+	candidates := createCandidatesFromMock(mock, []string{"near", "far"}, req)
+	for _, candidate := range candidates {
+		candidate.chainSlug = "test-chain"
+	}
+
+	config := DefaultOptimizerConfig()
+	o := NewMultiStoreOptimizer(mock, config, NewMetricsRecorder())
+	result, err := o.optimalAlgorithm(ctx, req, candidates)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"far"}, resultStoreIDs(result), "distance free")
+
+	config.TravelCostPerKm = 5
+	result, err = o.optimalAlgorithm(ctx, req, candidates)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"near"}, resultStoreIDs(result), "45 km for 20 saved")
+
+	o.planVisits(req, result)
+	assert.InDelta(t, 1.56, result.TravelDistanceKm, 0.01)
+	assert.Equal(t, TravelCost{DistanceCost: 8, Total: 8}, result.TravelCost)
+}
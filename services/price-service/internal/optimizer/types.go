@@ -2,16 +2,40 @@ package optimizer
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
 // OptimizeRequest contains the parameters for basket optimization.
 type OptimizeRequest struct {
 	ChainSlug   string        // The retail chain to optimize for
+	ChainSlugs  []string      // Chains to combine stores across (multi-store only); overrides ChainSlug
 	BasketItems []*BasketItem // Items in the basket
 	Location    *Location     // Optional user location for distance calculation
 	MaxDistance float64       // Maximum distance in km (0 = no limit)
-	MaxStores   int           // Maximum number of stores to return (multi-store only)
+	MaxStores   int           // Maximum number of stores the optimal algorithm combines (multi-store only; 3 when unset)
+	MinCoverage float64       // Minimum coverage ratio of returned stores (single-store only)
+	Offset      int           // Ranked stores to skip (single-store only)
+	Limit       int           // Stores to return, 0 for DefaultSingleStoreLimit (single-store only)
+	SortBy      string        // Ranks stores within a coverage bin, see SortByPrice (single-store only)
+	GreedyOnly  bool          // Skip the optimal algorithm (set by load shedding)
+
+	PenaltyStrategy string // Overrides the configured missing-item penalty strategy
+
+	// At is when discounts are evaluated. Optimize sets it from the
+	// optimizer's clock when zero, so one request sees one point in time.
+	At time.Time
+}
+
+// atTime returns req evaluated at the clock's current time unless it already
+// has one. req itself is not modified.
+func (req *OptimizeRequest) atTime(clock func() time.Time) *OptimizeRequest {
+	if !req.At.IsZero() {
+		return req
+	}
+	r := *req
+	r.At = clock()
+	return &r
 }
 
 // BasketItem represents a single item in the shopping basket.
@@ -19,6 +43,34 @@ type BasketItem struct {
 	ItemID   string // CUID2 item identifier from retailer_items
 	Name     string // Item name for display
 	Quantity int    // Quantity requested (must be > 0)
+
+	// ChainItemIDs maps a chain to the item's ID there, for cross-chain
+	// requests. Nil means ItemID is used in every chain; a chain missing from
+	// a non-nil map does not sell the item.
+	ChainItemIDs map[string]string
+}
+
+// itemIDIn returns the item's ID in a chain, or false if the chain does not
+// sell it
+func (b *BasketItem) itemIDIn(chainSlug string) (string, bool) {
+	if b.ChainItemIDs == nil {
+		return b.ItemID, true
+	}
+	id, ok := b.ChainItemIDs[chainSlug]
+	return id, ok
+}
+
+// crossChain reports whether the request combines stores of several chains
+func (req *OptimizeRequest) crossChain() bool {
+	return len(req.ChainSlugs) > 0
+}
+
+// chainLabel names the request's chain or chains for messages
+func (req *OptimizeRequest) chainLabel() string {
+	if req.crossChain() {
+		return strings.Join(req.ChainSlugs, ", ")
+	}
+	return req.ChainSlug
 }
 
 // CoverageBin represents the coverage tier for ranking stores.
@@ -71,11 +123,21 @@ type MultiStoreResult struct {
 	CoverageRatio   float64            // Combined coverage ratio (0-1)
 	UnassignedItems []*MissingItem     // Items not available at any selected store
 	AlgorithmUsed   string             // "greedy" or "optimal"
+	PenaltyStrategy string             // Missing-item penalty strategy used
+
+	// TravelDistanceKm is the length of the round trip from the request's
+	// location through the stores in visit order; 0 without a location
+	TravelDistanceKm float64
+
+	// TravelCost is the cost of the trip, weighed against CombinedTotal
+	// when choosing the stores
+	TravelCost TravelCost
 }
 
 // StoreAllocation represents a single store in a multi-store optimization.
 type StoreAllocation struct {
 	StoreID    string           // CUID2 store identifier
+	ChainSlug  string           // Chain the store belongs to
 	Items      []*ItemPriceInfo // Items allocated to this store
 	StoreTotal int64            // Total cost for this store
 	Distance   float64          // Distance from user location in km
@@ -101,16 +163,34 @@ type OptimizerConfig struct {
 	CacheRefreshJitter time.Duration // Random jitter to prevent thundering herd on refresh
 
 	// Warmup settings
-	WarmupConcurrency int // Maximum concurrent chain warmups
+	WarmupConcurrency   int // Maximum concurrent chain warmups
+	SnapshotScanWorkers int // Connections each chain load scans group prices on
+
+	// How long optimize requests for chains not loaded yet wait for warmup;
+	// 0 rejects them at once
+	WarmupHoldTimeout time.Duration
 
 	// Candidate selection
 	TopCheapestStores int // Number of cheapest stores to consider for multi-store
 	TopNearestStores  int // Number of nearest stores to consider for multi-store
 	MaxCandidates     int // Maximum total candidates for multi-store optimization
 
+	// Bloom filter bits per priced item used to skip stores that cannot reach
+	// the coverage candidates need; 0 disables the filter
+	CandidateFilterBitsPerKey int
+
 	// Geographic filtering
 	MaxDistanceKm float64 // Maximum distance for nearest store queries
 
+	// Cost of each km to a store, in minor units, in balanced single-store sorting
+	BalancedDistanceCostPerKm int64
+
+	// Travel costs weighed against savings in multi-store optimization, in
+	// minor units: each km of the round trip from the request's location and
+	// each store after the first
+	TravelCostPerKm int64
+	ExtraStoreCost  int64
+
 	// Algorithm settings
 	OptimalTimeoutMs int // Maximum time to spend on optimal algorithm (ms)
 
@@ -119,8 +199,10 @@ type OptimizerConfig struct {
 	MinBasketItems int // Minimum items required for optimization
 
 	// Missing item penalty
-	MissingItemPenaltyMult float64 // Multiplier for average price (e.g., 2.0 = 2x average)
-	MissingItemFallback    int64   // Fallback price when no average available
+	MissingItemPenaltyMult       float64          // Multiplier for average price (e.g., 2.0 = 2x average)
+	MissingItemFallback          int64            // Fallback price when no average available
+	MissingItemPenaltyStrategy   string           // Default penalty strategy, see PenaltyStrategyNames
+	MissingItemCategoryPenalties map[string]int64 // Fixed penalty per category for the category_table strategy
 
 	// Coverage bins (must be descending)
 	CoverageBins []float64 // Thresholds for coverage bins: [1.0, 0.9, 0.8]
@@ -129,26 +211,33 @@ type OptimizerConfig struct {
 // DefaultOptimizerConfig returns the default configuration for the optimizer.
 func DefaultOptimizerConfig() *OptimizerConfig {
 	return &OptimizerConfig{
-		CacheLoadTimeout:       30 * time.Second,
-		CacheTTL:               1 * time.Hour,
-		CacheRefreshJitter:     5 * time.Minute,
-		WarmupConcurrency:      3,
-		TopCheapestStores:      10,
-		TopNearestStores:       5,
-		MaxCandidates:          20,
-		MaxDistanceKm:          50.0,
-		OptimalTimeoutMs:       100,
-		MaxBasketItems:         100,
-		MinBasketItems:         1,
-		MissingItemPenaltyMult: 2.0,
-		MissingItemFallback:    10000, // 100.00 in minor units
-		CoverageBins:           []float64{1.0, 0.9, 0.8},
-	}
-}
-
-// GetEffectivePrice returns the effective price (discount if available, otherwise base price).
-func GetEffectivePrice(p CachedPrice) int64 {
-	if p.HasDiscount && p.DiscountPrice > 0 && p.DiscountPrice < p.Price {
+		CacheLoadTimeout:           30 * time.Second,
+		CacheTTL:                   1 * time.Hour,
+		CacheRefreshJitter:         5 * time.Minute,
+		WarmupConcurrency:          3,
+		SnapshotScanWorkers:        4,
+		TopCheapestStores:          10,
+		TopNearestStores:           5,
+		MaxCandidates:              20,
+		CandidateFilterBitsPerKey:  10,
+		MaxDistanceKm:              50.0,
+		BalancedDistanceCostPerKm:  200, // 2.00 per km
+		TravelCostPerKm:            0,
+		ExtraStoreCost:             0,
+		OptimalTimeoutMs:           100,
+		MaxBasketItems:             100,
+		MinBasketItems:             1,
+		MissingItemPenaltyMult:     2.0,
+		MissingItemFallback:        10000, // 100.00 in minor units
+		MissingItemPenaltyStrategy: PenaltyStrategyMean,
+		CoverageBins:               []float64{1.0, 0.9, 0.8},
+	}
+}
+
+// GetEffectivePrice returns the effective price at the given time: the
+// discount if one applies then, otherwise the base price.
+func GetEffectivePrice(p CachedPrice, at time.Time) int64 {
+	if p.DiscountActive(at) && p.DiscountPrice > 0 && p.DiscountPrice < p.Price {
 		return p.DiscountPrice
 	}
 	return p.Price
@@ -170,7 +259,13 @@ func CoverageBinFromRatio(ratio float64) CoverageBin {
 
 // Validate validates the optimization request and returns an error if invalid.
 func (r *OptimizeRequest) Validate(maxItems int) error {
-	if r.ChainSlug == "" {
+	if len(r.ChainSlugs) > 0 {
+		for i, chainSlug := range r.ChainSlugs {
+			if chainSlug == "" {
+				return ErrInvalidRequest{Field: "chainSlugs", Reason: fmt.Sprintf("chain at index %d is empty", i), Index: i}
+			}
+		}
+	} else if r.ChainSlug == "" {
 		return ErrInvalidRequest{Field: "chainSlug", Reason: "cannot be empty"}
 	}
 	if len(r.BasketItems) < 1 {
@@ -187,6 +282,24 @@ func (r *OptimizeRequest) Validate(maxItems int) error {
 			return ErrInvalidRequest{Field: "basketItems", Reason: fmt.Sprintf("item at index %d has invalid quantity", i), Index: i}
 		}
 	}
+	if r.MinCoverage < 0 || r.MinCoverage > 1 {
+		return ErrInvalidRequest{Field: "minCoverage", Reason: "must be between 0 and 1"}
+	}
+	if r.Offset < 0 {
+		return ErrInvalidRequest{Field: "offset", Reason: "cannot be negative"}
+	}
+	if r.Limit < 0 {
+		return ErrInvalidRequest{Field: "limit", Reason: "cannot be negative"}
+	}
+	if r.SortBy != "" && !IsSortBy(r.SortBy) {
+		return ErrInvalidRequest{Field: "sortBy", Reason: "unknown sort order"}
+	}
+	if (r.SortBy == SortByDistance || r.SortBy == SortByBalanced) && r.Location == nil {
+		return ErrInvalidRequest{Field: "sortBy", Reason: "needs a location"}
+	}
+	if r.PenaltyStrategy != "" && !IsPenaltyStrategy(r.PenaltyStrategy) {
+		return ErrInvalidRequest{Field: "penaltyStrategy", Reason: "unknown penalty strategy"}
+	}
 	if r.Location != nil {
 		if r.Location.Latitude < -90 || r.Location.Latitude > 90 {
 			return ErrInvalidRequest{Field: "location.latitude", Reason: "must be between -90 and 90"}
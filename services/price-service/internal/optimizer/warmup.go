@@ -0,0 +1,97 @@
+package optimizer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WarmupProgress describes the latest cache warmup and the requests it
+// turned away or held.
+type WarmupProgress struct {
+	Ready        bool      `json:"ready" jsonschema:"required"`
+	Chains       int       `json:"chains" jsonschema:"required"`       // Chains the warmup loads
+	ChainsLoaded int       `json:"chainsLoaded" jsonschema:"required"` // Chains loaded so far
+	StartedAt    time.Time `json:"startedAt" jsonschema:"required"`    // Zero before the first warmup
+	Held         int64     `json:"held" jsonschema:"required"`         // Requests held until warmup completed or timed out
+	Rejected     int64     `json:"rejected" jsonschema:"required"`     // Requests rejected without waiting, or after
+}
+
+// ErrWarmingUp is returned by AwaitWarmup when a request cannot wait for
+// warmup to complete.
+type ErrWarmingUp struct {
+	Progress WarmupProgress
+}
+
+func (e ErrWarmingUp) Error() string {
+	return fmt.Sprintf("cache warming up: %d of %d chains loaded", e.Progress.ChainsLoaded, e.Progress.Chains)
+}
+
+// warmupState tracks the progress of the latest warmup
+type warmupState struct {
+	mu       sync.Mutex
+	progress WarmupProgress
+}
+
+// start begins tracking a warmup of a number of chains
+func (w *warmupState) start(chains int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.progress = WarmupProgress{Chains: chains, StartedAt: time.Now()}
+}
+
+// update applies a change to the progress under the lock
+func (w *warmupState) update(change func(p *WarmupProgress)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	change(&w.progress)
+}
+
+// get returns the progress
+func (w *warmupState) get() WarmupProgress {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.progress
+}
+
+// WarmupProgress returns the progress of the latest warmup.
+func (c *PriceCache) WarmupProgress() WarmupProgress {
+	p := c.warmup.get()
+	p.Ready = c.warmupGate.IsReady()
+	return p
+}
+
+// AwaitWarmup applies the warmup policy to a request that needs a chain not
+// loaded yet. Once warmup has completed it returns nil at once. Before that
+// it returns ErrWarmingUp, unless WarmupHoldTimeout is set: then it holds the
+// request until warmup completes, returning nil, or the timeout passes. It
+// returns the context's error if the request is cancelled while held.
+func (c *PriceCache) AwaitWarmup(ctx context.Context) error {
+	if c.warmupGate.IsReady() {
+		return nil
+	}
+	if c.config.WarmupHoldTimeout <= 0 {
+		return c.rejectDuringWarmup("rejected")
+	}
+
+	c.warmup.update(func(p *WarmupProgress) { p.Held++ })
+	c.metrics.RecordWarmupGate("held")
+
+	waitCtx, cancel := context.WithTimeout(ctx, c.config.WarmupHoldTimeout)
+	defer cancel()
+	if c.warmupGate.Wait(waitCtx) {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.rejectDuringWarmup("timed_out")
+}
+
+// rejectDuringWarmup counts a request turned away by warmup
+func (c *PriceCache) rejectDuringWarmup(outcome string) error {
+	c.warmup.update(func(p *WarmupProgress) { p.Rejected++ })
+	c.metrics.RecordWarmupGate(outcome)
+	return ErrWarmingUp{Progress: c.WarmupProgress()}
+}
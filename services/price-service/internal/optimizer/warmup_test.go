@@ -0,0 +1,60 @@
+package optimizer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newWarmupTestCache(holdTimeout time.Duration) *PriceCache {
+	config := DefaultOptimizerConfig()
+	config.WarmupHoldTimeout = holdTimeout
+	c := &PriceCache{config: config, warmupGate: NewWarmupGate(nil), metrics: NewMetricsRecorder()}
+	c.warmup.start(4)
+	return c
+}
+
+func TestAwaitWarmupRejects(t *testing.T) {
+	c := newWarmupTestCache(0)
+	c.warmup.update(func(p *WarmupProgress) { p.ChainsLoaded = 1 })
+
+	err := c.AwaitWarmup(context.Background())
+	var warmingUp ErrWarmingUp
+	require.ErrorAs(t, err, &warmingUp)
+	assert.Equal(t, 1, warmingUp.Progress.ChainsLoaded)
+	assert.Equal(t, 4, warmingUp.Progress.Chains)
+	assert.EqualValues(t, 1, warmingUp.Progress.Rejected)
+
+	c.warmupGate.Ready()
+	assert.NoError(t, c.AwaitWarmup(context.Background()), "no policy once warm")
+	assert.EqualValues(t, 1, c.WarmupProgress().Rejected)
+	assert.True(t, c.WarmupProgress().Ready)
+}
+
+func TestAwaitWarmupHolds(t *testing.T) {
+	c := newWarmupTestCache(time.Minute)
+	time.AfterFunc(10*time.Millisecond, c.warmupGate.Ready)
+
+	require.NoError(t, c.AwaitWarmup(context.Background()))
+	progress := c.WarmupProgress()
+	assert.EqualValues(t, 1, progress.Held)
+	assert.Zero(t, progress.Rejected)
+}
+
+func TestAwaitWarmupHoldTimesOut(t *testing.T) {
+	c := newWarmupTestCache(10 * time.Millisecond)
+
+	err := c.AwaitWarmup(context.Background())
+	assert.ErrorAs(t, err, &ErrWarmingUp{})
+	progress := c.WarmupProgress()
+	assert.EqualValues(t, 1, progress.Held)
+	assert.EqualValues(t, 1, progress.Rejected)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, c.AwaitWarmup(ctx), context.Canceled, "a cancelled request is not a rejection")
+	assert.EqualValues(t, 1, c.WarmupProgress().Rejected)
+}
@@ -0,0 +1,108 @@
+// Package outbox implements a transactional outbox. Producers write events
+// with Enqueue inside the transaction that changes the state they describe;
+// the Relay delivers them at least once to a Publisher.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Event types
+const (
+	EventRunCompleted = "ingestion.run.completed"
+	EventRunFailed    = "ingestion.run.failed"
+	EventRunSuspect   = "ingestion.run.suspect"
+	EventPriceChanged = "prices.changed"
+
+	EventProductsMerged = "catalog.products.merged"
+	EventProductSplit   = "catalog.product.split"
+)
+
+// Event is a row of the event_outbox table
+type Event struct {
+	ID          int64           `json:"id"`
+	Type        string          `json:"type"`
+	AggregateID string          `json:"aggregateId"`
+	Payload     json.RawMessage `json:"payload"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	Attempts    int             `json:"-"`
+}
+
+// Execer is satisfied by pgx.Tx, pgx.Conn and pgxpool.Pool
+type Execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// Enqueue writes an event to the outbox. Pass the transaction that performs
+// the state change so the event is committed or rolled back with it.
+func Enqueue(ctx context.Context, db Execer, eventType, aggregateID string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s payload: %w", eventType, err)
+	}
+
+	_, err = db.Exec(ctx, `
+		INSERT INTO event_outbox (event_type, aggregate_id, payload)
+		VALUES ($1, $2, $3)
+	`, eventType, aggregateID, data)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue %s event: %w", eventType, err)
+	}
+	return nil
+}
+
+// RunFinishedPayload is the payload of run completed and failed events
+type RunFinishedPayload struct {
+	RunID            string `json:"runId"`
+	ChainSlug        string `json:"chainSlug,omitempty"`
+	Status           string `json:"status"`
+	ProcessedFiles   int    `json:"processedFiles"`
+	ProcessedEntries int    `json:"processedEntries"`
+	Error            string `json:"error,omitempty"`
+}
+
+// RunSuspectPayload is the payload of run suspect events, raised when a run
+// deviates from the chain's run on the same weekday a week earlier
+type RunSuspectPayload struct {
+	RunID         string `json:"runId"`
+	ChainSlug     string `json:"chainSlug"`
+	BaselineRunID string `json:"baselineRunId"`
+	// Relative deviation from the baseline of each failed check
+	Deviations map[string]float64 `json:"deviations"`
+}
+
+// PriceChange is a single significant price change of an item in a store
+type PriceChange struct {
+	RetailerItemID string `json:"retailerItemId"`
+	PreviousPrice  int    `json:"previousPrice"`
+	CurrentPrice   int    `json:"currentPrice"`
+}
+
+// PriceChangedPayload is the payload of price changed events, one per store
+// and persisted file
+type PriceChangedPayload struct {
+	RunID     string        `json:"runId"`
+	ChainSlug string        `json:"chainSlug"`
+	StoreID   string        `json:"storeId"`
+	Changes   []PriceChange `json:"changes"`
+}
+
+// ProductsMergedPayload is the payload of products merged events; consumers
+// drop cached entries and search documents of the merged products
+type ProductsMergedPayload struct {
+	ProductID       string   `json:"productId"`
+	MergedIDs       []string `json:"mergedIds"`
+	RetailerItemIDs []string `json:"retailerItemIds"`
+}
+
+// ProductSplitPayload is the payload of product split events
+type ProductSplitPayload struct {
+	ProductID       string   `json:"productId"`
+	NewProductID    string   `json:"newProductId"`
+	RetailerItemIDs []string `json:"retailerItemIds"`
+}
@@ -0,0 +1,62 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 2*time.Second, Backoff(1))
+	assert.Equal(t, 64*time.Second, Backoff(6))
+	assert.Equal(t, time.Hour, Backoff(12))
+	assert.Equal(t, time.Hour, Backoff(100))
+}
+
+func TestWebhookPublisherSignsEvents(t *testing.T) {
+	secret := []byte("s3cret")
+	var received Event
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		assert.Equal(t, "42", r.Header.Get(HeaderEventID))
+		assert.Equal(t, EventRunCompleted, r.Header.Get(HeaderEventType))
+		assert.Equal(t, "sha256="+Sign(secret, body), r.Header.Get(HeaderSignature))
+		require.NoError(t, json.Unmarshal(body, &received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	publisher := NewWebhookPublisher([]string{server.URL}, string(secret))
+	err := publisher.Publish(context.Background(), Event{
+		ID:          42,
+		Type:        EventRunCompleted,
+		AggregateID: "run_1",
+		Payload:     json.RawMessage(`{"runId":"run_1"}`),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "run_1", received.AggregateID)
+	assert.JSONEq(t, `{"runId":"run_1"}`, string(received.Payload))
+}
+
+func TestWebhookPublisherFailsOnNon2xx(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	publisher := NewWebhookPublisher([]string{server.URL}, "")
+	err := publisher.Publish(context.Background(), Event{ID: 1, Type: EventPriceChanged})
+
+	assert.ErrorContains(t, err, "unexpected status 502")
+}
@@ -0,0 +1,189 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+// DefaultBatchSize is the number of events claimed per relay pass
+const DefaultBatchSize = 100
+
+// DeliveredRetention is how long delivered events are kept before pruning
+const DeliveredRetention = 7 * 24 * time.Hour
+
+// maxBackoff caps the delay between delivery attempts of a failing event
+const maxBackoff = time.Hour
+
+// Publisher delivers events to their destination (webhooks, a message broker)
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// Relay periodically delivers pending outbox events to a Publisher.
+// Events are claimed with FOR UPDATE SKIP LOCKED, so several relays can run
+// side by side; failed deliveries are retried with exponential backoff and
+// never dropped.
+type Relay struct {
+	pool      *pgxpool.Pool
+	logger    *zerolog.Logger
+	interval  time.Duration
+	publisher Publisher
+	batchSize int
+	stopChan  chan struct{}
+}
+
+// NewRelay creates a new outbox relay
+func NewRelay(pool *pgxpool.Pool, logger *zerolog.Logger, interval time.Duration, publisher Publisher) *Relay {
+	return &Relay{
+		pool:      pool,
+		logger:    logger,
+		interval:  interval,
+		publisher: publisher,
+		batchSize: DefaultBatchSize,
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// Start delivers pending events on every interval until stopped
+func (r *Relay) Start(ctx context.Context) {
+	r.logger.Info().
+		Dur("interval", r.interval).
+		Msg("Starting outbox relay")
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Info().Msg("Outbox relay stopping (context cancelled)")
+			return
+		case <-r.stopChan:
+			r.logger.Info().Msg("Outbox relay stopping (stop signal)")
+			return
+		case <-ticker.C:
+			if err := r.RunOnce(ctx); err != nil {
+				r.logger.Error().Err(err).Msg("Outbox relay pass failed")
+			}
+		}
+	}
+}
+
+// Stop signals the relay to stop
+func (r *Relay) Stop() {
+	close(r.stopChan)
+}
+
+// RunOnce delivers pending events until a batch comes back short, then prunes
+// old delivered events
+func (r *Relay) RunOnce(ctx context.Context) error {
+	for {
+		claimed, err := r.deliverBatch(ctx)
+		if err != nil {
+			return err
+		}
+		if claimed < r.batchSize {
+			break
+		}
+	}
+
+	tag, err := r.pool.Exec(ctx, `
+		DELETE FROM event_outbox
+		WHERE delivered_at IS NOT NULL AND delivered_at < $1
+	`, time.Now().Add(-DeliveredRetention))
+	if err != nil {
+		return fmt.Errorf("failed to prune delivered events: %w", err)
+	}
+	if tag.RowsAffected() > 0 {
+		r.logger.Debug().Int64("count", tag.RowsAffected()).Msg("Pruned delivered outbox events")
+	}
+	return nil
+}
+
+// deliverBatch claims one batch of due events, publishes them and records the
+// outcome in the same transaction. It returns the number of events claimed.
+func (r *Relay) deliverBatch(ctx context.Context) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, event_type, aggregate_id, payload, created_at, attempts
+		FROM event_outbox
+		WHERE delivered_at IS NULL AND available_at <= NOW()
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`, r.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim events: %w", err)
+	}
+
+	var events []Event
+	for rows.Next() {
+		var event Event
+		if err := rows.Scan(&event.ID, &event.Type, &event.AggregateID, &event.Payload, &event.CreatedAt, &event.Attempts); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan event: %w", err)
+		}
+		events = append(events, event)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read events: %w", err)
+	}
+
+	for _, event := range events {
+		if pubErr := r.publisher.Publish(ctx, event); pubErr != nil {
+			r.logger.Warn().
+				Err(pubErr).
+				Int64("event_id", event.ID).
+				Str("event_type", event.Type).
+				Int("attempts", event.Attempts+1).
+				Msg("Failed to deliver outbox event")
+
+			_, err = tx.Exec(ctx, `
+				UPDATE event_outbox
+				SET attempts = attempts + 1,
+				    last_error = $2,
+				    available_at = NOW() + $3::interval
+				WHERE id = $1
+			`, event.ID, pubErr.Error(), Backoff(event.Attempts+1).String())
+		} else {
+			_, err = tx.Exec(ctx, `
+				UPDATE event_outbox
+				SET attempts = attempts + 1,
+				    last_error = NULL,
+				    delivered_at = NOW()
+				WHERE id = $1
+			`, event.ID)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to record delivery of event %d: %w", event.ID, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return len(events), nil
+}
+
+// Backoff returns the delay before the next delivery attempt after the given
+// number of failed attempts: 2^attempts seconds, capped at one hour
+func Backoff(attempts int) time.Duration {
+	if attempts > 12 {
+		return maxBackoff
+	}
+	d := time.Duration(1<<attempts) * time.Second
+	if d > maxBackoff {
+		return maxBackoff
+	}
+	return d
+}
@@ -0,0 +1,88 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Webhook request headers
+const (
+	HeaderEventID   = "X-Outbox-Event-Id"
+	HeaderEventType = "X-Outbox-Event-Type"
+	HeaderSignature = "X-Outbox-Signature"
+)
+
+// WebhookPublisher POSTs events as JSON to a set of webhook URLs.
+// An event counts as delivered only when every URL answers 2xx, so receivers
+// must deduplicate by event ID.
+type WebhookPublisher struct {
+	urls   []string
+	secret []byte
+	client *http.Client
+}
+
+// NewWebhookPublisher creates a publisher for the given URLs. When secret is
+// set, each request carries an HMAC-SHA256 signature of the body.
+func NewWebhookPublisher(urls []string, secret string) *WebhookPublisher {
+	return &WebhookPublisher{
+		urls:   urls,
+		secret: []byte(secret),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Publish delivers an event to every configured URL
+func (p *WebhookPublisher) Publish(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	for _, url := range p.urls {
+		if err := p.post(ctx, url, event, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *WebhookPublisher) post(ctx context.Context, url string, event Event, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventID, strconv.FormatInt(event.ID, 10))
+	req.Header.Set(HeaderEventType, event.Type)
+	if len(p.secret) > 0 {
+		req.Header.Set(HeaderSignature, "sha256="+Sign(p.secret, body))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s: unexpected status %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the hex HMAC-SHA256 of body
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -140,9 +140,7 @@ func ToUTF8Reader(r io.Reader, enc Encoding) (io.Reader, error) {
 
 	switch enc {
 	case EncodingWindows1250:
-		// For Windows-1250, we need custom handling
-		// Use Windows-1252 as base and handle Croatian chars separately
-		decoder = charmap.Windows1252
+		decoder = charmap.Windows1250
 	case EncodingISO88592:
 		decoder = charmap.ISO8859_2
 	default:
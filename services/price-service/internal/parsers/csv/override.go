@@ -0,0 +1,90 @@
+package csv
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// requiredMappingFields must map to a column in every mapping
+var requiredMappingFields = map[string]bool{"name": true, "price": true}
+
+// MappingFields returns the field names a column mapping override may set,
+// as used in the JSON form of CsvColumnMapping
+func MappingFields() []string {
+	t := reflect.TypeOf(CsvColumnMapping{})
+	fields := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// ApplyOverride overlays an override on a column mapping. Each override entry
+// replaces the column of one field; an empty value unmaps an optional field.
+// Unknown fields and unmapping required fields are errors.
+func ApplyOverride(base CsvColumnMapping, override map[string]string) (CsvColumnMapping, error) {
+	known := make(map[string]bool)
+	for _, field := range MappingFields() {
+		known[field] = true
+	}
+
+	data, err := json.Marshal(base)
+	if err != nil {
+		return base, err
+	}
+	var merged map[string]string
+	if err := json.Unmarshal(data, &merged); err != nil {
+		return base, err
+	}
+
+	for field, column := range override {
+		if !known[field] {
+			return base, fmt.Errorf("unknown mapping field %q", field)
+		}
+		column = strings.TrimSpace(column)
+		if column == "" {
+			if requiredMappingFields[field] {
+				return base, fmt.Errorf("required field %q cannot be unmapped", field)
+			}
+			delete(merged, field)
+			continue
+		}
+		merged[field] = column
+	}
+
+	data, err = json.Marshal(merged)
+	if err != nil {
+		return base, err
+	}
+	var result CsvColumnMapping
+	if err := json.Unmarshal(data, &result); err != nil {
+		return base, err
+	}
+	return result, nil
+}
+
+// WithColumnMapping returns a copy of the parser using a different primary
+// column mapping; the alternative mapping is kept
+func (p *Parser) WithColumnMapping(mapping *CsvColumnMapping) *Parser {
+	clone := *p
+	clone.options.ColumnMapping = mapping
+	return &clone
+}
+
+// Options returns the parser's options
+func (p *Parser) Options() CsvParserOptions {
+	return p.options
+}
+
+// WithOptions returns a copy of the parser using different options; the
+// alternative mapping is kept
+func (p *Parser) WithOptions(options CsvParserOptions) *Parser {
+	clone := *p
+	clone.options = options
+	return &clone
+}
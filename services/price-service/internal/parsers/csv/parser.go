@@ -11,6 +11,13 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// secondaryNameLanguages maps the secondary name fields of a column mapping
+// to the ISO 639-1 code of their language
+var secondaryNameLanguages = map[string]string{
+	"nameEn": "en",
+	"nameIt": "it",
+}
+
 // Parser implements CSV parsing with encoding detection and column mapping
 type Parser struct {
 	options CsvParserOptions
@@ -269,6 +276,8 @@ func (p *Parser) buildColumnIndices(headers []string, mapping *CsvColumnMapping)
 
 	// Optional fields
 	resolveIndex("storeIdentifier", mapping.StoreIdentifier, false)
+	resolveIndex("nameEn", mapping.NameEn, false)
+	resolveIndex("nameIt", mapping.NameIt, false)
 	resolveIndex("externalId", mapping.ExternalID, false)
 	resolveIndex("description", mapping.Description, false)
 	resolveIndex("category", mapping.Category, false)
@@ -403,6 +412,17 @@ func (p *Parser) mapRowToNormalized(rawRow []string, rowNumber int, indices map[
 		return nil, errors
 	}
 
+	// Secondary names, only those present and differing from the name
+	var nameI18n map[string]string
+	for field, language := range secondaryNameLanguages {
+		if value := getValue(field); value != nil && !strings.EqualFold(*value, name) {
+			if nameI18n == nil {
+				nameI18n = make(map[string]string)
+			}
+			nameI18n[language] = *value
+		}
+	}
+
 	// Build raw data JSON
 	rawDataJSON, _ := json.Marshal(rawRow)
 
@@ -410,6 +430,7 @@ func (p *Parser) mapRowToNormalized(rawRow []string, rowNumber int, indices map[
 		StoreIdentifier:       storeIdentifier,
 		ExternalID:            getValue("externalId"),
 		Name:                  name,
+		NameI18n:              nameI18n,
 		Description:           getValue("description"),
 		Category:              getValue("category"),
 		Subcategory:           getValue("subcategory"),
@@ -1,97 +1,147 @@
 package csv
 
 import (
+	"errors"
 	"fmt"
 	"math"
-	"regexp"
 	"strings"
-	"unicode"
 )
 
+// Price parsing errors. They are preallocated so that rejecting a value does
+// not allocate either.
+var (
+	errEmptyPrice    = errors.New("empty price value")
+	errNoPriceValue  = errors.New("no numeric value found")
+	errNoPriceDigits = errors.New("no digits found")
+	errPriceFormat   = errors.New("invalid price format")
+	errPriceOverflow = errors.New("price out of range")
+)
+
+// currencySuffixes are the currency codes stripped from the end of a price,
+// compared case-insensitively
+var currencySuffixes = [...]string{"KUNA", "HRK", "EUR", "USD", "KN"}
+
+// pow10 holds the powers of ten that fit an int64
+var pow10 = [...]int64{
+	1, 10, 100, 1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9,
+	1e10, 1e11, 1e12, 1e13, 1e14, 1e15, 1e16, 1e17, 1e18,
+}
+
 // ParsePrice parses a price string to cents (integer)
 // Handles various formats: "12.99", "12,99", "1.299,00", "1 299,00 kn"
+//
+// The last '.' or ',' is the decimal separator and earlier ones, like spaces,
+// group thousands. Currency symbols are ignored anywhere and currency codes at
+// the end. Text after the number ("12,99 /kg") is ignored. Fractions beyond
+// cents are rounded half away from zero. Parsing is a single pass over the
+// bytes and does not allocate.
 func ParsePrice(value string) (int, error) {
 	if value == "" {
-		return 0, fmt.Errorf("empty price value")
+		return 0, errEmptyPrice
 	}
 
-	// Remove currency symbols and whitespace
-	cleaned := strings.TrimSpace(value)
-	cleaned = strings.Map(func(r rune) rune {
-		// Remove currency symbols and thousands separators (space)
-		if r == '€' || r == '$' || r == '£' || r == '₹' ||
-		   r == '¥' || r == '¢' || r == '\u00A0' { // non-breaking space
-			return -1
-		}
-		// Keep other characters
-		return r
-	}, cleaned)
-
-	// Remove common currency text (kn, KUNA, etc.)
-	cleaned = strings.ToUpper(cleaned)
-	cleaned = regexp.MustCompile(`\s*(KN|KUNA|HRK|EUR|USD)\s*$`).ReplaceAllString(cleaned, "")
+	s := trimCurrencySuffix(strings.TrimSpace(value))
+	if s == "" {
+		return 0, errNoPriceValue
+	}
 
-	cleaned = strings.TrimSpace(cleaned)
-	if cleaned == "" {
-		return 0, fmt.Errorf("no numeric value found")
+	var mantissa int64
+	digits, fraction := 0, -1 // fraction < 0 until a separator is seen
+	negative := false
+
+scan:
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			if mantissa > (math.MaxInt64-9)/10 {
+				return 0, errPriceOverflow
+			}
+			mantissa = mantissa*10 + int64(c-'0')
+			digits++
+			if fraction >= 0 {
+				fraction++
+			}
+			i++
+		case c == '.' || c == ',':
+			fraction = 0
+			i++
+		case c == ' ' || c == '\t':
+			i++
+		case (c == '-' || c == '+') && digits == 0 && fraction < 0:
+			negative = c == '-'
+			i++
+		default:
+			if n := currencySymbolLen(s[i:]); n > 0 {
+				i += n
+				continue
+			}
+			if digits > 0 {
+				break scan
+			}
+			return 0, errPriceFormat
+		}
 	}
 
-	// Determine decimal separator
-	// If there's a comma after a dot, comma is decimal separator (European)
-	// If there's a dot after a comma, dot is decimal separator (US)
-	lastDot := strings.LastIndex(cleaned, ".")
-	lastComma := strings.LastIndex(cleaned, ",")
-
-	var result float64
-
-	if lastComma > lastDot {
-		// European format: 1.234,56 -> comma is decimal
-		// Remove dots (thousands separators)
-		cleaned = strings.ReplaceAll(cleaned, ".", "")
-		cleaned = strings.ReplaceAll(cleaned, ",", ".")
-	} else if lastDot > lastComma {
-		// US format: 1,234.56 -> just remove commas
-		cleaned = strings.ReplaceAll(cleaned, ",", "")
+	if digits == 0 {
+		return 0, errNoPriceDigits
 	}
-	// else: no separators found, use as-is
 
-	// Parse the float
-	result, err := parseFloat(cleaned)
+	cents, err := mantissaToCents(mantissa, max(fraction, 0))
 	if err != nil {
-		return 0, fmt.Errorf("invalid price format: %w", err)
+		return 0, err
+	}
+	if negative {
+		cents = -cents
 	}
-
-	// Convert to cents
-	cents := math.Round(result * 100)
 	return int(cents), nil
 }
 
-// parseFloat safely parses a float with better error handling
-func parseFloat(s string) (float64, error) {
-	s = strings.TrimSpace(s)
-	if s == "" {
-		return 0, fmt.Errorf("empty string")
-	}
-
-	// Check for valid numeric format
-	hasDigit := false
-	for _, r := range s {
-		if unicode.IsDigit(r) {
-			hasDigit = true
-			break
+// mantissaToCents scales a decimal mantissa with the given number of fraction
+// digits to cents
+func mantissaToCents(mantissa int64, fraction int) (int64, error) {
+	if fraction <= 2 {
+		scale := pow10[2-fraction]
+		if mantissa > math.MaxInt64/scale {
+			return 0, errPriceOverflow
 		}
+		return mantissa * scale, nil
 	}
-	if !hasDigit {
-		return 0, fmt.Errorf("no digits found")
+	if fraction-2 >= len(pow10) {
+		return 0, nil
 	}
 
-	var result float64
-	_, err := fmt.Sscanf(s, "%f", &result)
-	if err != nil {
-		return 0, err
+	div := pow10[fraction-2]
+	cents := mantissa / div
+	if rem := mantissa % div; rem >= div-rem {
+		cents++
 	}
+	return cents, nil
+}
+
+// trimCurrencySuffix strips a trailing currency code and the whitespace
+// before it
+func trimCurrencySuffix(s string) string {
+	for _, suffix := range currencySuffixes {
+		if len(s) >= len(suffix) && strings.EqualFold(s[len(s)-len(suffix):], suffix) {
+			return strings.TrimSpace(s[:len(s)-len(suffix)])
+		}
+	}
+	return s
+}
 
-	return result, nil
+// currencySymbolLen returns the byte length of the currency symbol or
+// non-breaking space at the start of s, or 0 when there is none
+func currencySymbolLen(s string) int {
+	switch {
+	case s[0] == '$':
+		return 1
+	case len(s) >= 2 && s[0] == 0xC2 && (s[1] == 0xA0 || s[1] == 0xA2 || s[1] == 0xA3 || s[1] == 0xA5): // NBSP ¢ £ ¥
+		return 2
+	case len(s) >= 3 && s[0] == 0xE2 && s[1] == 0x82 && (s[2] == 0xAC || s[2] == 0xB9): // € ₹
+		return 3
+	}
+	return 0
 }
 
 // FormatCents formats cents as a decimal string (e.g., 1299 -> "12.99")
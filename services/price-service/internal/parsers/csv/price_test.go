@@ -0,0 +1,179 @@
+package csv
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePrice(t *testing.T) {
+	tests := []struct {
+		input string
+		cents int
+	}{
+		// Croatian formats
+		{"12,99", 1299},
+		{"0,99", 99},
+		{"1.299,00", 129900},
+		{"1.234.567,89", 123456789},
+		{"1 299,00", 129900},
+		{"1 299,00", 129900},
+		{"12,99 kn", 1299},
+		{"12,99kn", 1299},
+		{"12,99 KN", 1299},
+		{"12,99 Kuna", 1299},
+		{"12,99 HRK", 1299},
+		{"12,99 €", 1299},
+		{"€ 12,99", 1299},
+		{"12,99 EUR", 1299},
+		{"12,99 eur", 1299},
+		{"12,9", 1290},
+		{"12,", 1200},
+		{",5", 50},
+
+		// US formats
+		{"12.99", 1299},
+		{"$12.99", 1299},
+		{"12.99 USD", 1299},
+		{"1,299.00", 129900},
+		{"1,234,567.89", 123456789},
+		{"£5.5", 550},
+		{"¥100", 10000},
+		{"₹ 10.00", 1000},
+		{"10¢", 1000},
+
+		// Integers and padding
+		{"100", 10000},
+		{"0", 0},
+		{"  7,50  ", 750},
+		{"\t7.50\n", 750},
+
+		// The last separator is the decimal one
+		{"1.299", 130},
+		{"1,299", 130},
+
+		// Rounding beyond cents, half away from zero
+		{"1,005", 101},
+		{"1,004", 100},
+		{"2.675", 268},
+		{"0,0049", 0},
+		{"-1,005", -101},
+
+		// Signs
+		{"-5,00", -500},
+		{"+5,00", 500},
+
+		// Trailing text after the number is ignored
+		{"12,99 /kg", 1299},
+		{"3,49 EUR/kom", 349},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			cents, err := ParsePrice(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.cents, cents)
+		})
+	}
+}
+
+func TestParsePriceErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   error
+	}{
+		{"", errEmptyPrice},
+		{"   ", errNoPriceValue},
+		{"kn", errNoPriceValue},
+		{"€", errNoPriceDigits},
+		{",", errNoPriceDigits},
+		{"-", errNoPriceDigits},
+		{"abc", errPriceFormat},
+		{"EUR 12,99", errPriceFormat},
+		{"N/A", errPriceFormat},
+		{"99999999999999999999", errPriceOverflow},
+		{"999999999999999999", errPriceOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := ParsePrice(tt.input)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestParsePriceMatchesFloatParse(t *testing.T) {
+	for cents := 0; cents < 200000; cents += 37 {
+		us := fmt.Sprintf("%d.%02d", cents/100, cents%100)
+		hr := FormatCentsEuropean(cents)
+
+		got, err := ParsePrice(us)
+		require.NoError(t, err)
+		assert.Equal(t, cents, got, us)
+
+		got, err = ParsePrice(hr)
+		require.NoError(t, err)
+		assert.Equal(t, cents, got, hr)
+	}
+}
+
+func TestParsePriceDoesNotAllocate(t *testing.T) {
+	inputs := []string{"1.299,00 kn", "$1,299.00", "12,99 €", "N/A", ""}
+	for _, input := range inputs {
+		allocs := testing.AllocsPerRun(100, func() {
+			_, _ = ParsePrice(input)
+		})
+		assert.Zero(t, allocs, input)
+	}
+}
+
+var benchmarkPrices = []string{"12,99", "1.299,00 kn", "$1,299.00", "0,49 €", "129.99 EUR"}
+
+func BenchmarkParsePrice(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = ParsePrice(benchmarkPrices[i%len(benchmarkPrices)])
+	}
+}
+
+// BenchmarkParsePriceRegexSscanf measures the regex and Sscanf based parser
+// ParsePrice replaced, for comparison
+func BenchmarkParsePriceRegexSscanf(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = regexSscanfParsePrice(benchmarkPrices[i%len(benchmarkPrices)])
+	}
+}
+
+func regexSscanfParsePrice(value string) (int, error) {
+	cleaned := strings.Map(func(r rune) rune {
+		if r == '€' || r == '$' || r == '£' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(value))
+	cleaned = strings.ToUpper(cleaned)
+	cleaned = regexp.MustCompile(`\s*(KN|KUNA|HRK|EUR|USD)\s*$`).ReplaceAllString(cleaned, "")
+	cleaned = strings.TrimSpace(cleaned)
+
+	lastDot := strings.LastIndex(cleaned, ".")
+	lastComma := strings.LastIndex(cleaned, ",")
+	if lastComma > lastDot {
+		cleaned = strings.ReplaceAll(cleaned, ".", "")
+		cleaned = strings.ReplaceAll(cleaned, ",", ".")
+	} else if lastDot > lastComma {
+		cleaned = strings.ReplaceAll(cleaned, ",", "")
+	}
+
+	var result float64
+	if _, err := fmt.Sscanf(cleaned, "%f", &result); err != nil {
+		return 0, err
+	}
+	return int(math.Round(result * 100)), nil
+}
@@ -23,6 +23,9 @@ type CsvColumnMapping struct {
 	StoreIdentifier     *string `json:"storeIdentifier,omitempty"`
 	ExternalID          *string `json:"externalId,omitempty"`
 	Name                string  `json:"name"`
+	// Secondary names, in the language of the field suffix
+	NameEn              *string `json:"nameEn,omitempty"`
+	NameIt              *string `json:"nameIt,omitempty"`
 	Description         *string `json:"description,omitempty"`
 	Category            *string `json:"category,omitempty"`
 	Subcategory         *string `json:"subcategory,omitempty"`
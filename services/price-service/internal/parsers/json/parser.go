@@ -0,0 +1,341 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kosarica/price-service/internal/parsers/csv"
+	"github.com/kosarica/price-service/internal/types"
+)
+
+var barcodeSeparatorPattern = regexp.MustCompile(`[,;|]`)
+
+// commonItemPaths are tried in order when the document is an object and no
+// items path is configured
+var commonItemPaths = []string{
+	"products",
+	"items",
+	"data",
+	"artikli",
+	"proizvodi",
+	"data.products",
+	"data.items",
+}
+
+// Parser implements JSON parsing of price lists published as arrays of item
+// objects, with field mapping by dot-notation paths
+type Parser struct {
+	options            JsonParserOptions
+	alternativeMapping *JsonFieldMapping
+}
+
+// NewParser creates a new JSON parser with the given options
+func NewParser(options JsonParserOptions) *Parser {
+	return &Parser{
+		options: options,
+	}
+}
+
+// SetAlternativeMapping sets an alternative field mapping to try if the primary fails
+func (p *Parser) SetAlternativeMapping(mapping *JsonFieldMapping) {
+	p.alternativeMapping = mapping
+}
+
+// Parse parses JSON content into normalized rows
+func (p *Parser) Parse(content []byte) (*types.ParseResult, error) {
+	return p.ParseWithStoreID(content, p.options.DefaultStoreIdentifier)
+}
+
+// ParseWithStoreID parses JSON content with a specific store identifier
+func (p *Parser) ParseWithStoreID(content []byte, storeID string) (*types.ParseResult, error) {
+	// Strip UTF-8 BOM
+	content = bytes.TrimPrefix(content, []byte{0xEF, 0xBB, 0xBF})
+
+	// Numbers are kept as written so prices are not rounded through float64
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	items, err := p.findItems(document)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse each item using primary field mapping
+	result := p.parseItems(items, p.options.FieldMapping, storeID)
+
+	// If no valid rows, try alternative mapping
+	if result.ValidRows == 0 && p.alternativeMapping != nil {
+		result = p.parseItems(items, *p.alternativeMapping, storeID)
+	}
+
+	return result, nil
+}
+
+// findItems returns the item objects of a document: the document itself when
+// it is an array, else the array at the items path or the first common path
+func (p *Parser) findItems(document interface{}) ([]map[string]interface{}, error) {
+	if p.options.ItemsPath != "" {
+		value := getValueAtPath(document, p.options.ItemsPath)
+		if value == nil {
+			return nil, fmt.Errorf("items path '%s' not found", p.options.ItemsPath)
+		}
+		return toItemSlice(value)
+	}
+
+	if array, ok := document.([]interface{}); ok {
+		return toItemSlice(array)
+	}
+	for _, path := range commonItemPaths {
+		if array, ok := getValueAtPath(document, path).([]interface{}); ok {
+			return toItemSlice(array)
+		}
+	}
+	return nil, fmt.Errorf("could not detect items array in JSON")
+}
+
+// toItemSlice converts an array, or a single object, to item objects.
+// Array elements that are not objects are skipped.
+func toItemSlice(value interface{}) ([]map[string]interface{}, error) {
+	switch v := value.(type) {
+	case []interface{}:
+		result := make([]map[string]interface{}, 0, len(v))
+		for _, item := range v {
+			if m, ok := item.(map[string]interface{}); ok {
+				result = append(result, m)
+			}
+		}
+		return result, nil
+	case map[string]interface{}:
+		return []map[string]interface{}{v}, nil
+	default:
+		return nil, fmt.Errorf("expected array or object, got %T", value)
+	}
+}
+
+// parseItems parses a slice of items into normalized rows
+func (p *Parser) parseItems(items []map[string]interface{}, mapping JsonFieldMapping, defaultStoreID string) *types.ParseResult {
+	result := &types.ParseResult{
+		TotalRows: len(items),
+		Rows:      make([]types.NormalizedRow, 0, len(items)),
+		Errors:    make([]types.ParseError, 0),
+		Warnings:  make([]types.ParseWarning, 0),
+	}
+
+	for i, item := range items {
+		rowNumber := i + 1
+		row, errors := mapItemToRow(item, rowNumber, mapping, defaultStoreID)
+		if len(errors) > 0 {
+			result.Errors = append(result.Errors, errors...)
+			continue
+		}
+
+		result.Rows = append(result.Rows, *row)
+		result.ValidRows++
+	}
+
+	return result
+}
+
+// mapItemToRow maps a single JSON item to a NormalizedRow
+func mapItemToRow(item map[string]interface{}, rowNumber int, mapping JsonFieldMapping, defaultStoreID string) (*types.NormalizedRow, []types.ParseError) {
+	var errors []types.ParseError
+
+	extract := func(path *string) *string {
+		if path == nil {
+			return nil
+		}
+		return valueToString(getValueAtPath(item, *path))
+	}
+	extractPrice := func(path *string) *int {
+		if value := extract(path); value != nil {
+			if parsed, err := csv.ParsePrice(*value); err == nil {
+				return &parsed
+			}
+		}
+		return nil
+	}
+
+	// Extract name (required)
+	var name string
+	if nameVal := extract(&mapping.Name); nameVal != nil {
+		name = *nameVal
+	}
+	if name == "" {
+		errors = append(errors, types.ParseError{
+			RowNumber: &rowNumber,
+			Field:     types.StringPtr("name"),
+			Message:   "Name is required",
+		})
+	}
+
+	// Extract price (required)
+	var price int
+	priceStr := extract(&mapping.Price)
+	if priceStr == nil {
+		errors = append(errors, types.ParseError{
+			RowNumber: &rowNumber,
+			Field:     types.StringPtr("price"),
+			Message:   "Price is required",
+		})
+	} else {
+		var err error
+		price, err = csv.ParsePrice(*priceStr)
+		if err != nil {
+			errors = append(errors, types.ParseError{
+				RowNumber:     &rowNumber,
+				Field:         types.StringPtr("price"),
+				Message:       "Invalid price value",
+				OriginalValue: priceStr,
+			})
+		}
+	}
+
+	if len(errors) > 0 {
+		return nil, errors
+	}
+
+	storeIdentifier := defaultStoreID
+	if storeVal := extract(mapping.StoreIdentifier); storeVal != nil {
+		storeIdentifier = *storeVal
+	}
+
+	barcodes := []string{}
+	if mapping.Barcodes != nil {
+		barcodes = extractBarcodes(getValueAtPath(item, *mapping.Barcodes))
+	}
+
+	rawDataJSON, _ := json.Marshal(item)
+
+	row := &types.NormalizedRow{
+		StoreIdentifier:       storeIdentifier,
+		ExternalID:            extract(mapping.ExternalID),
+		Name:                  name,
+		Description:           extract(mapping.Description),
+		Category:              extract(mapping.Category),
+		Subcategory:           extract(mapping.Subcategory),
+		Brand:                 extract(mapping.Brand),
+		Unit:                  extract(mapping.Unit),
+		UnitQuantity:          extract(mapping.UnitQuantity),
+		Price:                 price,
+		DiscountPrice:         extractPrice(mapping.DiscountPrice),
+		DiscountStart:         parseDate(extract(mapping.DiscountStart)),
+		DiscountEnd:           parseDate(extract(mapping.DiscountEnd)),
+		Barcodes:              barcodes,
+		ImageURL:              extract(mapping.ImageURL),
+		RowNumber:             rowNumber,
+		RawData:               string(rawDataJSON),
+		UnitPrice:             extractPrice(mapping.UnitPrice),
+		UnitPriceBaseQuantity: extract(mapping.UnitPriceBaseQuantity),
+		UnitPriceBaseUnit:     extract(mapping.UnitPriceBaseUnit),
+		LowestPrice30d:        extractPrice(mapping.LowestPrice30d),
+		AnchorPrice:           extractPrice(mapping.AnchorPrice),
+		AnchorPriceAsOf:       parseDate(extract(mapping.AnchorPriceAsOf)),
+	}
+
+	return row, nil
+}
+
+// getValueAtPath retrieves a value at a dot-notation path, matching keys
+// case-insensitively when there is no exact match
+func getValueAtPath(value interface{}, path string) interface{} {
+	current := value
+	for _, part := range strings.Split(path, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		next, found := object[part]
+		if !found {
+			for k, v := range object {
+				if strings.EqualFold(k, part) {
+					next, found = v, true
+					break
+				}
+			}
+		}
+		if !found {
+			return nil
+		}
+		current = next
+	}
+	return current
+}
+
+// valueToString converts a scalar JSON value to a trimmed string, nil when
+// it is missing, empty or not a scalar
+func valueToString(value interface{}) *string {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = strings.TrimSpace(v)
+	case json.Number:
+		s = v.String()
+	case bool:
+		s = fmt.Sprintf("%t", v)
+	default:
+		return nil
+	}
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// extractBarcodes reads barcodes from an array of codes or a string of codes
+// separated by commas, semicolons or pipes
+func extractBarcodes(value interface{}) []string {
+	barcodes := []string{}
+	switch v := value.(type) {
+	case []interface{}:
+		for _, code := range v {
+			if s := valueToString(code); s != nil {
+				barcodes = append(barcodes, *s)
+			}
+		}
+	default:
+		if s := valueToString(v); s != nil {
+			for _, part := range barcodeSeparatorPattern.Split(*s, -1) {
+				if trimmed := strings.TrimSpace(part); trimmed != "" {
+					barcodes = append(barcodes, trimmed)
+				}
+			}
+		}
+	}
+	return barcodes
+}
+
+// parseDate parses a date string into time.Time
+func parseDate(value *string) *time.Time {
+	if value == nil || *value == "" {
+		return nil
+	}
+
+	s := strings.TrimSpace(*value)
+
+	layouts := []string{
+		"2006-01-02",
+		"2006/01/02",
+		"02.01.2006",
+		"02/01/2006",
+		"02-01-2006",
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05",
+		time.RFC3339,
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return &t
+		}
+	}
+
+	return nil
+}
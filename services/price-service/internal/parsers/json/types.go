@@ -0,0 +1,37 @@
+package json
+
+// JsonFieldMapping maps NormalizedRow field names to paths in a JSON item.
+// Paths use dot notation for nested objects (e.g., "prices.regular")
+type JsonFieldMapping struct {
+	StoreIdentifier       *string `json:"storeIdentifier,omitempty"`
+	ExternalID            *string `json:"externalId,omitempty"`
+	Name                  string  `json:"name"` // Required
+	Description           *string `json:"description,omitempty"`
+	Category              *string `json:"category,omitempty"`
+	Subcategory           *string `json:"subcategory,omitempty"`
+	Brand                 *string `json:"brand,omitempty"`
+	Unit                  *string `json:"unit,omitempty"`
+	UnitQuantity          *string `json:"unitQuantity,omitempty"`
+	Price                 string  `json:"price"` // Required
+	DiscountPrice         *string `json:"discountPrice,omitempty"`
+	DiscountStart         *string `json:"discountStart,omitempty"`
+	DiscountEnd           *string `json:"discountEnd,omitempty"`
+	Barcodes              *string `json:"barcodes,omitempty"` // Array of codes or a separated string
+	ImageURL              *string `json:"imageUrl,omitempty"`
+	UnitPrice             *string `json:"unitPrice,omitempty"`
+	UnitPriceBaseQuantity *string `json:"unitPriceBaseQuantity,omitempty"`
+	UnitPriceBaseUnit     *string `json:"unitPriceBaseUnit,omitempty"`
+	LowestPrice30d        *string `json:"lowestPrice30d,omitempty"`
+	AnchorPrice           *string `json:"anchorPrice,omitempty"`
+	AnchorPriceAsOf       *string `json:"anchorPriceAsOf,omitempty"`
+}
+
+// JsonParserOptions represents JSON parser options
+type JsonParserOptions struct {
+	// ItemsPath is the dot-notation path to the items array. Empty means the
+	// document itself is the array, or else the first of the common paths
+	// holding one.
+	ItemsPath              string           `json:"itemsPath,omitempty"`
+	FieldMapping           JsonFieldMapping `json:"fieldMapping"`
+	DefaultStoreIdentifier string           `json:"defaultStoreIdentifier,omitempty"`
+}
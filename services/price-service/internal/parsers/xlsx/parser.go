@@ -4,17 +4,23 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"math"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/kosarica/price-service/internal/parsers/csv"
 	"github.com/kosarica/price-service/internal/types"
 	"github.com/rs/zerolog/log"
 	"github.com/xuri/excelize/v2"
 )
 
+var (
+	barcodeSeparatorPattern = regexp.MustCompile(`[,;|]`)
+	isoDatePattern          = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})`)
+	europeanDatePattern     = regexp.MustCompile(`^(\d{1,2})[./](\d{1,2})[./](\d{4})`)
+)
+
 // Parser is an XLSX parser implementation
 type Parser struct {
 	options XlsxParserOptions
@@ -367,7 +373,7 @@ func (p *Parser) mapRowToNormalized(rawRow []string, rowNumber int, indices *Res
 	var barcodes []string
 	if barcodesStr != "" {
 		// Split on comma, semicolon, or pipe
-		parts := regexp.MustCompile(`[,;|]`).Split(barcodesStr, -1)
+		parts := barcodeSeparatorPattern.Split(barcodesStr, -1)
 		for _, b := range parts {
 			b = strings.TrimSpace(b)
 			if b != "" {
@@ -492,42 +498,20 @@ func isEmptyRow(row []string) bool {
 // parsePrice parses a price string to cents (integer)
 // Handles various formats: "12.99", "12,99", "1.299,00"
 func parsePrice(value string) (int, error) {
-	if value == "" {
-		return 0, fmt.Errorf("empty price value")
-	}
-
-	// Remove currency symbols and whitespace
-	cleaned := regexp.MustCompile(`[€$£\s]`).ReplaceAllString(value, "")
-
-	// Determine decimal separator
-	lastDot := strings.LastIndex(cleaned, ".")
-	lastComma := strings.LastIndex(cleaned, ",")
-
-	if lastComma > lastDot {
-		// European format: 1.234,56 -> comma is decimal
-		cleaned = strings.ReplaceAll(cleaned, ".", "")
-		cleaned = strings.Replace(cleaned, ",", ".", 1)
-	} else if lastDot > lastComma {
-		// US format: 1,234.56 -> just remove commas
-		cleaned = strings.ReplaceAll(cleaned, ",", "")
-	}
-
-	parsed, err := strconv.ParseFloat(cleaned, 64)
+	cents, err := csv.ParsePrice(value)
 	if err != nil {
 		return 0, err
 	}
 
 	// Debug logging
-	if parsed <= 0 {
+	if cents <= 0 {
 		log.Debug().
 			Str("raw", value).
-			Str("cleaned", cleaned).
-			Float64("parsed", parsed).
+			Int("cents", cents).
 			Msg("parsePrice")
 	}
 
-	// Convert to cents
-	return int(math.Round(parsed * 100)), nil
+	return cents, nil
 }
 
 // parseDate parses a date string to time.Time
@@ -546,8 +530,7 @@ func parseDate(value string) *time.Time {
 	}
 
 	// Try ISO format (YYYY-MM-DD)
-	isoPattern := regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})`)
-	if match := isoPattern.FindStringSubmatch(value); len(match) == 4 {
+	if match := isoDatePattern.FindStringSubmatch(value); len(match) == 4 {
 		year, _ := strconv.Atoi(match[1])
 		month, _ := strconv.Atoi(match[2])
 		day, _ := strconv.Atoi(match[3])
@@ -556,8 +539,7 @@ func parseDate(value string) *time.Time {
 	}
 
 	// Try European format (DD.MM.YYYY or DD/MM/YYYY)
-	euPattern := regexp.MustCompile(`^(\d{1,2})[./](\d{1,2})[./](\d{4})`)
-	if match := euPattern.FindStringSubmatch(value); len(match) == 4 {
+	if match := europeanDatePattern.FindStringSubmatch(value); len(match) == 4 {
 		day, _ := strconv.Atoi(match[1])
 		month, _ := strconv.Atoi(match[2])
 		year, _ := strconv.Atoi(match[3])
@@ -6,16 +6,20 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
-	"math"
 	"regexp"
 	"strings"
 	"time"
-	"unicode"
 
 	"github.com/kosarica/price-service/internal/parsers/charset"
+	"github.com/kosarica/price-service/internal/parsers/csv"
 	"github.com/kosarica/price-service/internal/types"
 )
 
+var (
+	encodingDeclarationPattern = regexp.MustCompile(`<\?xml[^?]*encoding=["']([^"']+)["'][^?]*\?>`)
+	barcodeSeparatorPattern    = regexp.MustCompile(`[,;|]`)
+)
+
 // Parser implements XML parsing with multiple item path detection and field mapping
 type Parser struct {
 	options            XmlParserOptions
@@ -144,8 +148,7 @@ func (p *Parser) decodeContent(content []byte) (string, error) {
 // detectEncodingFromDeclaration extracts encoding from XML declaration
 func (p *Parser) detectEncodingFromDeclaration(content []byte) string {
 	// Look for <?xml ... encoding="..." ?>
-	re := regexp.MustCompile(`<\?xml[^?]*encoding=["']([^"']+)["'][^?]*\?>`)
-	if match := re.FindSubmatch(content[:min(200, len(content))]); len(match) > 1 {
+	if match := encodingDeclarationPattern.FindSubmatch(content[:min(200, len(content))]); len(match) > 1 {
 		enc := strings.ToLower(string(match[1]))
 		// Normalize encoding names
 		switch enc {
@@ -249,21 +252,7 @@ func (p *Parser) decodeElement(decoder *xml.Decoder, start *xml.StartElement) (m
 
 // detectItemsPath tries to find the path to items array in the XML data
 func (p *Parser) detectItemsPath(data map[string]interface{}) string {
-	// Common item paths to try
-	commonPaths := []string{
-		"products.product",
-		"Products.Product",
-		"items.item",
-		"Items.Item",
-		"data.product",
-		"Data.Product",
-		"Cjenik.Proizvod",
-		"cjenik.proizvod",
-		"catalog.product",
-		"Catalog.Product",
-	}
-
-	for _, path := range commonPaths {
+	for _, path := range commonItemPaths {
 		if items, err := p.getItemsAtPath(data, path); err == nil && len(items) > 0 {
 			return path
 		}
@@ -692,8 +681,7 @@ func (p *Parser) getValueAtPath(item map[string]interface{}, path string) interf
 // splitBarcodes splits a string into individual barcodes
 func splitBarcodes(s string) []string {
 	// Split on common separators
-	separators := regexp.MustCompile(`[,;|]`)
-	parts := separators.Split(s, -1)
+	parts := barcodeSeparatorPattern.Split(s, -1)
 
 	barcodes := make([]string, 0, len(parts))
 	for _, part := range parts {
@@ -706,65 +694,9 @@ func splitBarcodes(s string) []string {
 	return barcodes
 }
 
-// parsePrice parses a price string to cents
+// parsePrice parses a price string to cents using the shared CSV price parser
 func parsePrice(value string) (int, error) {
-	if value == "" {
-		return 0, fmt.Errorf("empty price value")
-	}
-
-	// Remove currency symbols and whitespace
-	cleaned := strings.TrimSpace(value)
-	cleaned = strings.Map(func(r rune) rune {
-		if r == '€' || r == '$' || r == '£' || r == '₹' ||
-			r == '¥' || r == '¢' || r == '\u00A0' {
-			return -1
-		}
-		return r
-	}, cleaned)
-
-	// Remove common currency text
-	cleaned = strings.ToUpper(cleaned)
-	cleaned = regexp.MustCompile(`\s*(KN|KUNA|HRK|EUR|USD)\s*$`).ReplaceAllString(cleaned, "")
-	cleaned = strings.TrimSpace(cleaned)
-
-	if cleaned == "" {
-		return 0, fmt.Errorf("no numeric value found")
-	}
-
-	// Determine decimal separator
-	lastDot := strings.LastIndex(cleaned, ".")
-	lastComma := strings.LastIndex(cleaned, ",")
-
-	if lastComma > lastDot {
-		// European format: 1.234,56 -> comma is decimal
-		cleaned = strings.ReplaceAll(cleaned, ".", "")
-		cleaned = strings.ReplaceAll(cleaned, ",", ".")
-	} else if lastDot > lastComma {
-		// US format: 1,234.56 -> just remove commas
-		cleaned = strings.ReplaceAll(cleaned, ",", "")
-	}
-
-	// Parse the float
-	var result float64
-	hasDigit := false
-	for _, r := range cleaned {
-		if unicode.IsDigit(r) {
-			hasDigit = true
-			break
-		}
-	}
-	if !hasDigit {
-		return 0, fmt.Errorf("no digits found")
-	}
-
-	_, err := fmt.Sscanf(cleaned, "%f", &result)
-	if err != nil {
-		return 0, fmt.Errorf("invalid price format: %w", err)
-	}
-
-	// Convert to cents
-	cents := math.Round(result * 100)
-	return int(cents), nil
+	return csv.ParsePrice(value)
 }
 
 // parseDate parses a date string into time.Time
@@ -0,0 +1,215 @@
+package xml
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kosarica/price-service/internal/parsers/charset"
+	"github.com/kosarica/price-service/internal/types"
+)
+
+// commonItemPaths are the items paths tried when none is configured
+var commonItemPaths = []string{
+	"products.product",
+	"Products.Product",
+	"items.item",
+	"Items.Item",
+	"data.product",
+	"Data.Product",
+	"Cjenik.Proizvod",
+	"cjenik.proizvod",
+	"catalog.product",
+	"Catalog.Product",
+}
+
+// RowHandler receives each valid row of a streamed parse. Returning an error
+// stops the parse.
+type RowHandler func(row types.NormalizedRow) error
+
+// ParseStream parses XML from r one item at a time, passing each valid row to
+// emit as soon as its item element ends. Only the current item is held in
+// memory, never the document, so files of hundreds of megabytes parse in
+// constant memory apart from what emit keeps.
+//
+// Items are the elements at the first of itemPaths found in the document,
+// matched case-insensitively from the root element; without itemPaths the
+// configured ItemsPath or, failing that, the common paths are tried. Unlike
+// Parse, the whole document cannot be searched for an items path or parsed a
+// second time, so the alternative mapping is chosen on the first item: when
+// the primary mapping fails on it and the alternative does not, the
+// alternative maps every item.
+//
+// The returned result carries the row counts, errors and warnings; its Rows
+// are empty as every row went to emit.
+func (p *Parser) ParseStream(r io.Reader, itemPaths []string, storeID string, emit RowHandler) (*types.ParseResult, error) {
+	if len(itemPaths) == 0 {
+		itemPaths = commonItemPaths
+		if p.options.ItemsPath != "" {
+			itemPaths = []string{p.options.ItemsPath}
+		}
+	}
+	candidates := make([][]string, len(itemPaths))
+	for i, path := range itemPaths {
+		candidates[i] = strings.Split(path, ".")
+	}
+
+	reader, err := p.decodeStream(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode content: %w", err)
+	}
+	decoder := xml.NewDecoder(reader)
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil // Already handled encoding
+	}
+
+	result := &types.ParseResult{
+		Rows:     []types.NormalizedRow{},
+		Errors:   make([]types.ParseError, 0),
+		Warnings: make([]types.ParseWarning, 0),
+	}
+
+	mapping := p.options.FieldMapping
+	var itemsPath []string
+	var stack []string
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse XML: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			stack = append(stack, t.Name.Local)
+			if itemsPath == nil {
+				itemsPath = matchItemsPath(stack, candidates)
+			}
+			if itemsPath == nil || !pathEqualFold(stack, itemsPath) {
+				continue
+			}
+
+			// decodeElement consumes the item up to its end element
+			stack = stack[:len(stack)-1]
+			item, err := p.decodeElement(decoder, &t)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse XML: %w", err)
+			}
+
+			result.TotalRows++
+			rowNumber := result.TotalRows
+			row, rowErrors := p.mapItemToRow(item, rowNumber, mapping, storeID)
+			if len(rowErrors) > 0 && rowNumber == 1 && p.alternativeMapping != nil {
+				if altRow, altErrors := p.mapItemToRow(item, rowNumber, *p.alternativeMapping, storeID); len(altErrors) == 0 {
+					mapping = *p.alternativeMapping
+					row, rowErrors = altRow, nil
+				}
+			}
+			if len(rowErrors) > 0 {
+				for _, e := range rowErrors {
+					result.Errors = append(result.Errors, types.ParseError{
+						RowNumber:     &rowNumber,
+						Field:         e.Field,
+						Message:       e.Message,
+						OriginalValue: e.OriginalValue,
+					})
+				}
+				continue
+			}
+
+			result.ValidRows++
+			if err := emit(*row); err != nil {
+				return nil, err
+			}
+
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	if itemsPath == nil {
+		return nil, fmt.Errorf("none of the items paths %s found in XML", strings.Join(itemPaths, ", "))
+	}
+	return result, nil
+}
+
+// decodeStream detects the encoding of a stream from its first bytes and
+// returns a reader of its UTF-8 content
+func (p *Parser) decodeStream(r io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReaderSize(r, 4096)
+	head, err := buffered.Peek(1024)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+
+	// A BOM means UTF-8 here, as in decodeContent
+	for _, bom := range [][]byte{{0xEF, 0xBB, 0xBF}, {0xFF, 0xFE}, {0xFE, 0xFF}} {
+		if len(head) >= len(bom) && string(head[:len(bom)]) == string(bom) {
+			buffered.Discard(len(bom))
+			return buffered, nil
+		}
+	}
+
+	enc := p.options.Encoding
+	if enc == "" || enc == "auto" {
+		enc = p.detectEncodingFromDeclaration(head)
+		if enc == "" {
+			enc = string(charset.DetectEncoding(head))
+		}
+	}
+
+	// Files declared in a single-byte encoding but written as UTF-8 are read
+	// as UTF-8, as Decode does
+	if enc != string(charset.EncodingUTF8) && hasMultibyteUTF8(head) {
+		enc = string(charset.EncodingUTF8)
+	}
+
+	return charset.ToUTF8Reader(buffered, charset.Encoding(enc))
+}
+
+// hasMultibyteUTF8 reports whether data holds valid UTF-8 with at least one
+// non-ASCII character, ignoring a character cut off at its end
+func hasMultibyteUTF8(data []byte) bool {
+	multibyte := false
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		if r == utf8.RuneError && size <= 1 {
+			return multibyte && !utf8.FullRune(data)
+		}
+		if size > 1 {
+			multibyte = true
+		}
+		data = data[size:]
+	}
+	return multibyte
+}
+
+// matchItemsPath returns the first candidate path the element stack is on
+func matchItemsPath(stack []string, candidates [][]string) []string {
+	for _, candidate := range candidates {
+		if pathEqualFold(stack, candidate) {
+			return candidate
+		}
+	}
+	return nil
+}
+
+// pathEqualFold reports whether an element stack equals a path, ignoring case
+func pathEqualFold(stack, path []string) bool {
+	if len(stack) != len(path) {
+		return false
+	}
+	for i := range stack {
+		if !strings.EqualFold(stack[i], path[i]) {
+			return false
+		}
+	}
+	return true
+}
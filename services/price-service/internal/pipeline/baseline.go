@@ -0,0 +1,256 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	appconfig "github.com/kosarica/price-service/config"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/outbox"
+)
+
+// Baseline statuses of a completed run, in ingestion_runs.baseline_status.
+// Runs without a baseline to compare with have none.
+const (
+	BaselinePassed  = "passed"
+	BaselineSuspect = "suspect"
+	BaselineCleared = "cleared"
+)
+
+// ErrRunNotSuspect is returned when clearing a run that is not suspect
+var ErrRunNotSuspect = errors.New("run is not suspect")
+
+// baselineBands are the largest relative deviations from the baseline run a
+// run may show before it is suspect
+type baselineBands struct {
+	Files float64
+	Rows  float64
+	Price float64
+}
+
+// runBaselineBands returns the configured bands, and false when baseline
+// checks are disabled
+func runBaselineBands() (baselineBands, bool) {
+	bands := baselineBands{Files: 0.25, Rows: 0.30, Price: 0.15}
+	cfg := appconfig.Get()
+	if cfg == nil {
+		return bands, true
+	}
+	rb := cfg.RunBaseline
+	if rb.MaxFileDeviation > 0 {
+		bands.Files = rb.MaxFileDeviation
+	}
+	if rb.MaxRowDeviation > 0 {
+		bands.Rows = rb.MaxRowDeviation
+	}
+	if rb.MaxPriceDeviation > 0 {
+		bands.Price = rb.MaxPriceDeviation
+	}
+	return bands, rb.Enabled
+}
+
+// BaselineReport is the outcome of comparing a run with its baseline run
+type BaselineReport struct {
+	Passed        bool           `json:"passed"`
+	BaselineRunID string         `json:"baselineRunId"`
+	Checks        []QualityCheck `json:"checks"`
+	CheckedAt     time.Time      `json:"checkedAt"`
+}
+
+// runFigures are the figures a run is compared with its baseline on
+type runFigures struct {
+	Files int
+	Rows  int
+	// AveragePrice is the average current price of the items the run saw,
+	// nil when it saw none
+	AveragePrice *float64
+}
+
+// baselineDay returns the start of the day a week before a run started, the
+// day whose runs serve as its baseline
+func baselineDay(startedAt time.Time) time.Time {
+	day := startedAt.AddDate(0, 0, -7)
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+}
+
+// relativeDeviation returns how far current is from baseline relative to
+// baseline
+func relativeDeviation(current, baseline float64) float64 {
+	return math.Abs(current-baseline) / baseline
+}
+
+// evaluateBaseline compares a run's figures with its baseline run's. A figure
+// the baseline has no value for is not checked.
+func evaluateBaseline(current, baseline runFigures, baselineRunID string, bands baselineBands, now time.Time) BaselineReport {
+	report := BaselineReport{BaselineRunID: baselineRunID, Checks: []QualityCheck{}, CheckedAt: now}
+
+	check := func(name string, cur, base, band float64) {
+		deviation := relativeDeviation(cur, base)
+		report.Checks = append(report.Checks, QualityCheck{
+			Name: name, Value: deviation, Threshold: band, Passed: deviation <= band,
+		})
+	}
+	if baseline.Files > 0 {
+		check("file_count_deviation", float64(current.Files), float64(baseline.Files), bands.Files)
+	}
+	if baseline.Rows > 0 {
+		check("row_count_deviation", float64(current.Rows), float64(baseline.Rows), bands.Rows)
+	}
+	if baseline.AveragePrice != nil && *baseline.AveragePrice > 0 && current.AveragePrice != nil {
+		check("average_price_deviation", *current.AveragePrice, *baseline.AveragePrice, bands.Price)
+	}
+
+	report.Passed = true
+	for _, c := range report.Checks {
+		report.Passed = report.Passed && c.Passed
+	}
+	return report
+}
+
+// checkRunBaseline compares a completed run with the chain's largest
+// completed run that started on the same weekday a week earlier and records
+// the outcome. A run deviating beyond the bands is suspect: the price cache
+// holds the chain's prices and a run suspect event is raised. The run's
+// average price is recorded whether or not there is a baseline, so later runs
+// can compare with it. Returns the run's baseline status, empty when checks
+// are disabled or there was no baseline.
+func checkRunBaseline(ctx context.Context, chainID string, runID string) (string, error) {
+	bands, enabled := runBaselineBands()
+	if !enabled {
+		return "", nil
+	}
+	pool := database.Pool()
+
+	var current runFigures
+	var startedAt time.Time
+	err := pool.QueryRow(ctx, `
+		SELECT COALESCE(processed_files, 0), COALESCE(processed_entries, 0), COALESCE(started_at, created_at)
+		FROM ingestion_runs WHERE id = $1
+	`, runID).Scan(&current.Files, &current.Rows, &startedAt)
+	if err == pgx.ErrNoRows {
+		return "", ErrRunNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to load run: %w", err)
+	}
+
+	err = pool.QueryRow(ctx, `
+		UPDATE ingestion_runs
+		SET average_price = (
+			SELECT AVG(sis.current_price)::float8
+			FROM store_item_state sis
+			JOIN stores s ON s.id = sis.store_id
+			WHERE s.chain_slug = $2 AND sis.last_seen_at >= $3 AND sis.current_price > 0
+		)
+		WHERE id = $1
+		RETURNING average_price
+	`, runID, chainID, startedAt).Scan(&current.AveragePrice)
+	if err != nil {
+		return "", fmt.Errorf("failed to record average price: %w", err)
+	}
+
+	day := baselineDay(startedAt)
+	var baselineRunID string
+	var baseline runFigures
+	err = pool.QueryRow(ctx, `
+		SELECT id, COALESCE(processed_files, 0), COALESCE(processed_entries, 0), average_price
+		FROM ingestion_runs
+		WHERE chain_slug = $1
+		  AND id <> $2
+		  AND source <> 'api'
+		  AND status = 'completed'
+		  AND COALESCE(started_at, created_at) >= $3
+		  AND COALESCE(started_at, created_at) < $4
+		  AND baseline_status IS DISTINCT FROM $5
+		ORDER BY processed_entries DESC NULLS LAST
+		LIMIT 1
+	`, chainID, runID, day, day.AddDate(0, 0, 1), BaselineSuspect).Scan(
+		&baselineRunID, &baseline.Files, &baseline.Rows, &baseline.AveragePrice,
+	)
+	if err == pgx.ErrNoRows {
+		logFrom(ctx).Debug().Time("baseline_day", day).Msg("No baseline run to compare with")
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to find baseline run: %w", err)
+	}
+
+	report := evaluateBaseline(current, baseline, baselineRunID, bands, time.Now())
+	status := BaselinePassed
+	if !report.Passed {
+		status = BaselineSuspect
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE ingestion_runs SET baseline_status = $2, baseline_report = $3 WHERE id = $1
+	`, runID, status, report)
+	if err != nil {
+		return "", fmt.Errorf("failed to record baseline check: %w", err)
+	}
+
+	if status == BaselineSuspect {
+		deviations := make(map[string]float64)
+		for _, c := range report.Checks {
+			if !c.Passed {
+				deviations[c.Name] = c.Value
+			}
+		}
+		if err := outbox.Enqueue(ctx, tx, outbox.EventRunSuspect, runID, outbox.RunSuspectPayload{
+			RunID:         runID,
+			ChainSlug:     chainID,
+			BaselineRunID: baselineRunID,
+			Deviations:    deviations,
+		}); err != nil {
+			return "", err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", fmt.Errorf("failed to commit baseline check: %w", err)
+	}
+
+	if status == BaselineSuspect {
+		logFrom(ctx).Warn().Str("baseline_run_id", baselineRunID).Interface("checks", report.Checks).Msg("Run deviates from baseline, holding cache refresh")
+	} else {
+		logFrom(ctx).Info().Str("baseline_run_id", baselineRunID).Msg("Run matches baseline")
+	}
+	return status, nil
+}
+
+// ClearSuspectRun clears a suspect run after review, releasing the hold on
+// the chain's cache refresh. Returns ErrRunNotFound if the run does not exist
+// and ErrRunNotSuspect if it is not suspect.
+func ClearSuspectRun(ctx context.Context, runID string, clearedBy string) error {
+	tag, err := database.Pool().Exec(ctx, `
+		UPDATE ingestion_runs
+		SET baseline_status = $2,
+		    baseline_report = baseline_report || jsonb_build_object('clearedBy', $3::text, 'clearedAt', NOW())
+		WHERE id = $1 AND baseline_status = $4
+	`, runID, BaselineCleared, clearedBy, BaselineSuspect)
+	if err != nil {
+		return fmt.Errorf("failed to clear run: %w", err)
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	var exists bool
+	err = database.Pool().QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM ingestion_runs WHERE id = $1)`, runID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to load run: %w", err)
+	}
+	if !exists {
+		return ErrRunNotFound
+	}
+	return ErrRunNotSuspect
+}
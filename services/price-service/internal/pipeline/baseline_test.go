@@ -0,0 +1,55 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateBaseline(t *testing.T) {
+	price := func(v float64) *float64 { return &v }
+	baseline := runFigures{Files: 40, Rows: 100000, AveragePrice: price(3.20)}
+	bands := baselineBands{Files: 0.25, Rows: 0.30, Price: 0.15}
+
+	tests := []struct {
+		name    string
+		current runFigures
+		checks  int
+		failed  string
+	}{
+		{"similar run passes", runFigures{Files: 38, Rows: 96000, AveragePrice: price(3.25)}, 3, ""},
+		{"missing files", runFigures{Files: 20, Rows: 96000, AveragePrice: price(3.25)}, 3, "file_count_deviation"},
+		{"row count doubled", runFigures{Files: 40, Rows: 210000, AveragePrice: price(3.25)}, 3, "row_count_deviation"},
+		{"prices in cents", runFigures{Files: 40, Rows: 100000, AveragePrice: price(320)}, 3, "average_price_deviation"},
+		{"no current prices", runFigures{Files: 40, Rows: 100000}, 2, ""},
+	}
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := evaluateBaseline(tt.current, baseline, "run-1", bands, now)
+
+			assert.Equal(t, tt.failed == "", report.Passed)
+			assert.Equal(t, "run-1", report.BaselineRunID)
+			assert.Len(t, report.Checks, tt.checks)
+			for _, check := range report.Checks {
+				assert.Equal(t, check.Name != tt.failed, check.Passed, check.Name)
+			}
+		})
+	}
+
+	t.Run("baseline without figures checks nothing", func(t *testing.T) {
+		report := evaluateBaseline(runFigures{Files: 1, Rows: 10}, runFigures{}, "run-1", bands, now)
+		assert.True(t, report.Passed)
+		assert.Empty(t, report.Checks)
+	})
+}
+
+func TestBaselineDay(t *testing.T) {
+	started := time.Date(2026, 3, 10, 6, 30, 0, 0, time.UTC)
+	day := baselineDay(started)
+
+	assert.Equal(t, time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), day)
+	assert.Equal(t, started.Weekday(), day.Weekday())
+}
@@ -9,8 +9,9 @@ import (
 	"github.com/kosarica/price-service/internal/adapters/config"
 	"github.com/kosarica/price-service/internal/adapters/registry"
 	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/outbox"
 	"github.com/kosarica/price-service/internal/types"
-	"github.com/rs/zerolog/log"
+	"github.com/kosarica/price-service/internal/webhooks"
 )
 
 // DiscoverPhase executes the discovery phase of the ingestion pipeline
@@ -22,13 +23,11 @@ func DiscoverPhase(ctx context.Context, chainID string, runID string, targetDate
 		return nil, fmt.Errorf("failed to get adapter for %s: %w", chainID, err)
 	}
 
-	log.Info().
-		Str("chain", chainID).
-		Str("run_id", runID).
+	logFrom(ctx).Info().
 		Msg("Starting discovery")
 
 	if targetDate != "" {
-		log.Info().
+		logFrom(ctx).Info().
 			Str("target_date", targetDate).
 			Msg("Discovery target date")
 	}
@@ -39,31 +38,38 @@ func DiscoverPhase(ctx context.Context, chainID string, runID string, targetDate
 		return nil, fmt.Errorf("discovery failed: %w", err)
 	}
 
-	log.Info().
-		Str("chain", chainID).
+	logFrom(ctx).Info().
 		Int("files_found", len(files)).
 		Msg("Discovery complete")
 
+	if err := recordDiscoveredFiles(ctx, runID, len(files)); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// recordDiscoveredFiles initializes the run's statistics with the number of
+// files found. A run without files is completed right away.
+func recordDiscoveredFiles(ctx context.Context, runID string, count int) error {
 	// Initialize run stats and record total files
 	if err := initializeRunStats(ctx, runID); err != nil {
-		return nil, fmt.Errorf("failed to initialize run stats: %w", err)
+		return fmt.Errorf("failed to initialize run stats: %w", err)
 	}
 
-	if err := recordTotalFiles(ctx, runID, len(files)); err != nil {
-		return nil, fmt.Errorf("failed to record total files: %w", err)
+	if err := recordTotalFiles(ctx, runID, count); err != nil {
+		return fmt.Errorf("failed to record total files: %w", err)
 	}
 
 	// If no files found, mark run as completed
-	if len(files) == 0 {
-		log.Warn().
-			Str("chain", chainID).
+	if count == 0 {
+		logFrom(ctx).Warn().
 			Msg("No files discovered")
-		if err := markRunCompleted(ctx, runID, 0, 0); err != nil {
-			return nil, fmt.Errorf("failed to mark run as completed: %w", err)
+		if err := markRunCompleted(ctx, runID); err != nil {
+			return fmt.Errorf("failed to mark run as completed: %w", err)
 		}
 	}
-
-	return files, nil
+	return nil
 }
 
 // initializeRunStats initializes the ingestion run statistics
@@ -88,19 +94,85 @@ func recordTotalFiles(ctx context.Context, runID string, totalFiles int) error {
 	return err
 }
 
-// markRunCompleted marks an ingestion run as completed
-func markRunCompleted(ctx context.Context, runID string, processedFiles int, processedEntries int) error {
+// markRunCompleted marks an ingestion run as completed, reconciling its
+// totals with the files it recorded. The first transition to completed
+// enqueues a run completed event and its webhook deliveries in the same
+// transaction. The progress counters are left alone: file workers may still
+// be adding to them, and the event reports the values they have reached.
+func markRunCompleted(ctx context.Context, runID string) error {
 	pool := database.Pool()
-	now := time.Now()
-	_, err := pool.Exec(ctx, `
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	chainSlug, previousStatus, err := lockRunStatus(ctx, tx, runID)
+	if err == pgx.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	reconciliation, err := reconcileRunTotals(ctx, tx, runID)
+	if err != nil {
+		return err
+	}
+	if reconciliation.Completed+reconciliation.Deduplicated < reconciliation.Files {
+		logFrom(ctx).Warn().
+			Int("files", reconciliation.Files).
+			Int("skipped", reconciliation.Skipped).
+			Int("failed", reconciliation.Failed).
+			Float64("completion_percent", reconciliation.CompletionPercent).
+			Msg("Run completed with unprocessed files")
+	}
+
+	var processedFiles, processedEntries int
+	err = tx.QueryRow(ctx, `
 		UPDATE ingestion_runs
 		SET status = 'completed',
-		    completed_at = $1,
-		    processed_files = COALESCE($2, processed_files),
-		    processed_entries = COALESCE($3, processed_entries)
-		WHERE id = $4
-	`, now, processedFiles, processedEntries, runID)
-	return err
+		    completed_at = $1
+		WHERE id = $2
+		RETURNING COALESCE(processed_files, 0), COALESCE(processed_entries, 0)
+	`, time.Now(), runID).Scan(&processedFiles, &processedEntries)
+	if err != nil {
+		return err
+	}
+
+	if previousStatus != "completed" {
+		payload := outbox.RunFinishedPayload{
+			RunID:            runID,
+			ChainSlug:        chainSlug,
+			Status:           "completed",
+			ProcessedFiles:   processedFiles,
+			ProcessedEntries: processedEntries,
+		}
+		if err := outbox.Enqueue(ctx, tx, outbox.EventRunCompleted, runID, payload); err != nil {
+			return err
+		}
+		if err := webhooks.Enqueue(ctx, tx, outbox.EventRunCompleted, runID, payload); err != nil {
+			return err
+		}
+		// Every replica refreshes the chain once the run is committed
+		if err := database.NotifyCacheInvalidation(ctx, tx, database.CacheInvalidation{ChainSlug: chainSlug}); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// lockRunStatus locks an ingestion run row for update and returns its chain and status
+func lockRunStatus(ctx context.Context, tx pgx.Tx, runID string) (string, string, error) {
+	var chainSlug, status string
+	err := tx.QueryRow(ctx, `
+		SELECT chain_slug, COALESCE(status, '')
+		FROM ingestion_runs
+		WHERE id = $1
+		FOR UPDATE
+	`, runID).Scan(&chainSlug, &status)
+	return chainSlug, status, err
 }
 
 // MarkRunInterrupted marks an ingestion run as interrupted (e.g., service restart)
@@ -114,7 +186,7 @@ func MarkRunInterrupted(ctx context.Context, runID string) error {
 		    metadata = jsonb_set(
 		        COALESCE(metadata, '{}'::jsonb),
 		        '{interrupted_reason}',
-		        to_jsonb('Service restarted during processing')
+		        to_jsonb('Service restarted during processing'::text)
 		    )
 		WHERE id = $2
 	`, now, runID)
@@ -124,10 +196,26 @@ func MarkRunInterrupted(ctx context.Context, runID string) error {
 	return nil
 }
 
-// markRunFailed marks an ingestion run as failed
+// markRunFailed marks an ingestion run as failed. The first transition to
+// failed enqueues a run failed event and its webhook deliveries in the same
+// transaction.
 func markRunFailed(ctx context.Context, runID string, errorMsg string) error {
 	pool := database.Pool()
-	_, err := pool.Exec(ctx, `
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	chainSlug, previousStatus, err := lockRunStatus(ctx, tx, runID)
+	if err == pgx.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
 		UPDATE ingestion_runs
 		SET status = 'failed',
 		    completed_at = NOW(),
@@ -138,28 +226,40 @@ func markRunFailed(ctx context.Context, runID string, errorMsg string) error {
 		    )
 		WHERE id = $2
 	`, errorMsg, runID)
-	return err
-}
+	if err != nil {
+		return err
+	}
 
-// incrementProcessedFiles increments the processed files count
-func incrementProcessedFiles(ctx context.Context, runID string) error {
-	pool := database.Pool()
-	_, err := pool.Exec(ctx, `
-		UPDATE ingestion_runs
-		SET processed_files = COALESCE(processed_files, 0) + 1
-		WHERE id = $1
-	`, runID)
-	return err
+	if previousStatus != "failed" {
+		payload := outbox.RunFinishedPayload{
+			RunID:     runID,
+			ChainSlug: chainSlug,
+			Status:    "failed",
+			Error:     errorMsg,
+		}
+		if err := outbox.Enqueue(ctx, tx, outbox.EventRunFailed, runID, payload); err != nil {
+			return err
+		}
+		if err := webhooks.Enqueue(ctx, tx, outbox.EventRunFailed, runID, payload); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
 }
 
-// incrementProcessedEntries increments the processed entries count
-func incrementProcessedEntries(ctx context.Context, runID string, count int) error {
+// incrementRunProgress adds a finished file's counts to the run's progress
+// counters. The counters are only ever changed by adding in a single UPDATE,
+// so parallel file workers cannot lose each other's updates.
+func incrementRunProgress(ctx context.Context, runID string, files, entries, priceChanges int) error {
 	pool := database.Pool()
 	_, err := pool.Exec(ctx, `
 		UPDATE ingestion_runs
-		SET processed_entries = COALESCE(processed_entries, 0) + $1
-		WHERE id = $2
-	`, count, runID)
+		SET processed_files = COALESCE(processed_files, 0) + $1,
+		    processed_entries = COALESCE(processed_entries, 0) + $2,
+		    price_changes = COALESCE(price_changes, 0) + $3
+		WHERE id = $4
+	`, files, entries, priceChanges, runID)
 	return err
 }
 
@@ -187,7 +287,7 @@ func checkAndUpdateRunCompletion(ctx context.Context, runID string) (bool, error
 
 	// Check if all files processed
 	if totalFiles > 0 && processedFiles >= totalFiles {
-		if err := markRunCompleted(ctx, runID, processedFiles, 0); err != nil {
+		if err := markRunCompleted(ctx, runID); err != nil {
 			return false, err
 		}
 		return true, nil
@@ -4,11 +4,11 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/rs/zerolog/log"
 	"github.com/kosarica/price-service/internal/adapters/config"
 	"github.com/kosarica/price-service/internal/adapters/registry"
 	"github.com/kosarica/price-service/internal/database"
@@ -34,7 +34,7 @@ func FetchPhase(ctx context.Context, chainID string, file types.DiscoveredFile,
 		return nil, fmt.Errorf("failed to get adapter for %s: %w", chainID, err)
 	}
 
-	log.Info().Str("filename", file.Filename).Str("url", file.URL).Msg("Fetching file")
+	logFrom(ctx).Info().Str("url", file.URL).Msg("Fetching file")
 
 	// Fetch the file
 	fetched, err := adapter.Fetch(file)
@@ -51,7 +51,7 @@ func FetchPhase(ctx context.Context, chainID string, file types.DiscoveredFile,
 		return nil, fmt.Errorf("failed to check archive: %w", err)
 	}
 	if existingArchive != nil {
-		log.Info().Str("filename", file.Filename).Str("existing_archive", existingArchive.ID).Msg("Skipping duplicate file")
+		logFrom(ctx).Info().Str("existing_archive", existingArchive.ID).Msg("Skipping duplicate file")
 		return &FetchResult{
 			ArchiveID: existingArchive.ID,
 			Content:   fetched.Content,
@@ -86,18 +86,19 @@ func FetchPhase(ctx context.Context, chainID string, file types.DiscoveredFile,
 		Filename:       file.Filename,
 		OriginalFormat: string(file.Type),
 		ArchivePath:    storageKey,
-		ArchiveType:    "local",
+		ArchiveType:    string(storageBackend.Type()),
 		FileSize:       &fileSize,
 		Checksum:       hash,
 		DownloadedAt:   time.Now(),
+		Metadata:       encodeArchiveMetadata(file),
 	}
 
 	if err := database.CreateArchive(ctx, archive); err != nil {
-		log.Warn().Err(err).Msg("Failed to create archive record")
+		logFrom(ctx).Warn().Err(err).Msg("Failed to create archive record")
 		// Continue anyway - file is stored
 	}
 
-	log.Info().Str("filename", file.Filename).Int64("file_size", fileSize).Str("hash", hash).Str("storage_key", storageKey).Msg("Archived file")
+	logFrom(ctx).Info().Int64("file_size", fileSize).Str("hash", hash).Str("storage_key", storageKey).Msg("Archived file")
 
 	return &FetchResult{
 		StorageKey: storageKey,
@@ -108,6 +109,26 @@ func FetchPhase(ctx context.Context, chainID string, file types.DiscoveredFile,
 	}, nil
 }
 
+// archiveMetadata is what an archive record keeps of the discovered file, so
+// the file can be replayed from the archive as the adapter discovered it
+type archiveMetadata struct {
+	Discovered map[string]string `json:"discovered,omitempty"`
+}
+
+// encodeArchiveMetadata returns the archive metadata of a discovered file, nil
+// if there is none to keep
+func encodeArchiveMetadata(file types.DiscoveredFile) *string {
+	if len(file.Metadata) == 0 {
+		return nil
+	}
+	encoded, err := json.Marshal(archiveMetadata{Discovered: file.Metadata})
+	if err != nil {
+		return nil
+	}
+	metadata := string(encoded)
+	return &metadata
+}
+
 // computeSha256 computes SHA256 hash of byte slice
 func computeSha256(data []byte) string {
 	h := sha256.Sum256(data)
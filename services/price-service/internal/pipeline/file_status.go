@@ -0,0 +1,131 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/types"
+)
+
+// Statuses of an ingestion file. A file moves through the stages in order and
+// ends completed, failed or skipped; transitionFile is the only place that
+// moves it forward. requeueUnfinishedFiles puts the unfinished files of a
+// resumed run back to pending.
+const (
+	filePending     = "pending"
+	fileDownloading = "downloading"
+	fileParsing     = "parsing"
+	filePersisting  = "persisting"
+	fileCompleted   = "completed"
+	fileFailed      = "failed"
+	fileSkipped     = "skipped"
+)
+
+// fileTransitions lists the statuses each status may move to. Terminal
+// statuses have none.
+var fileTransitions = map[string][]string{
+	filePending:     {fileDownloading, fileFailed, fileSkipped},
+	fileDownloading: {fileParsing, fileFailed, fileSkipped},
+	fileParsing:     {filePersisting, fileCompleted, fileFailed, fileSkipped},
+	filePersisting:  {fileCompleted, fileFailed, fileSkipped},
+}
+
+var errInvalidFileTransition = errors.New("invalid file status transition")
+
+// fileStatusesBefore returns the statuses a file may move to status from
+func fileStatusesBefore(status string) []string {
+	var from []string
+	for s, next := range fileTransitions {
+		for _, n := range next {
+			if n == status {
+				from = append(from, s)
+			}
+		}
+	}
+	return from
+}
+
+// isTerminalFileStatus reports whether a file in status is done
+func isTerminalFileStatus(status string) bool {
+	_, ok := fileTransitions[status]
+	return !ok
+}
+
+// createPendingFiles records every discovered file of a run as pending and
+// returns their IDs in the order of files
+func createPendingFiles(ctx context.Context, runID string, files []types.DiscoveredFile) ([]string, error) {
+	ids := make([]string, len(files))
+	filenames := make([]string, len(files))
+	fileTypes := make([]string, len(files))
+	discovered := make([]string, len(files))
+	for i, file := range files {
+		ids[i] = fmt.Sprintf("%s_%d", generateFileID(), i)
+		filenames[i] = file.Filename
+		fileTypes[i] = string(file.Type)
+		encoded, err := json.Marshal(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode discovered file: %w", err)
+		}
+		discovered[i] = string(encoded)
+	}
+
+	pool := database.Pool()
+	_, err := pool.Exec(ctx, `
+		INSERT INTO ingestion_files (id, run_id, filename, file_type, discovered, status, status_timestamps, created_at)
+		SELECT f.id, $1, f.filename, f.file_type, f.discovered::jsonb, $6, jsonb_build_object($6::text, NOW()), NOW()
+		FROM unnest($2::text[], $3::text[], $4::text[], $5::text[]) AS f(id, filename, file_type, discovered)
+	`, runID, ids, filenames, fileTypes, discovered, filePending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ingestion files: %w", err)
+	}
+	return ids, nil
+}
+
+// transitionFile moves an ingestion file to status, recording when it did.
+// reason is the discrepancy reason of a file that did not complete, empty
+// otherwise. A transition the state machine does not allow leaves the file
+// unchanged and is logged as an error.
+func transitionFile(ctx context.Context, fileID string, status string, reason string) error {
+	pool := database.Pool()
+	tag, err := pool.Exec(ctx, `
+		UPDATE ingestion_files
+		SET status = $2,
+		    status_timestamps = COALESCE(status_timestamps, '{}'::jsonb) || jsonb_build_object($2::text, NOW()),
+		    discrepancy_reason = COALESCE(NULLIF($3, ''), discrepancy_reason),
+		    processed_at = CASE WHEN $4 THEN NOW() ELSE processed_at END
+		WHERE id = $1 AND status = ANY($5)
+	`, fileID, status, reason, isTerminalFileStatus(status), fileStatusesBefore(status))
+	if err != nil {
+		logFrom(ctx).Warn().Err(err).Str("status", status).Msg("Failed to update file status")
+		return fmt.Errorf("failed to update file status: %w", err)
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	var current string
+	err = pool.QueryRow(ctx, `SELECT status FROM ingestion_files WHERE id = $1`, fileID).Scan(&current)
+	if errors.Is(err, pgx.ErrNoRows) {
+		current = "missing"
+	} else if err != nil {
+		return fmt.Errorf("failed to read file status: %w", err)
+	}
+
+	logFrom(ctx).Error().
+		Str("file_id", fileID).
+		Str("from", current).
+		Str("to", status).
+		Msg("Invalid file status transition")
+	return fmt.Errorf("%w: %s -> %s", errInvalidFileTransition, current, status)
+}
+
+// skipPendingFiles marks files that were never started as skipped
+func skipPendingFiles(ctx context.Context, files []runFile) {
+	for _, file := range files {
+		transitionFile(ctx, file.ID, fileSkipped, discrepancySkipped)
+	}
+}
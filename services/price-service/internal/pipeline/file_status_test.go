@@ -0,0 +1,21 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileStatusTransitions(t *testing.T) {
+	assert.ElementsMatch(t, []string{filePending}, fileStatusesBefore(fileDownloading))
+	assert.ElementsMatch(t, []string{fileDownloading}, fileStatusesBefore(fileParsing))
+	assert.ElementsMatch(t, []string{fileParsing, filePersisting}, fileStatusesBefore(fileCompleted))
+	assert.ElementsMatch(t, []string{filePending, fileDownloading, fileParsing, filePersisting}, fileStatusesBefore(fileFailed))
+	assert.Empty(t, fileStatusesBefore(filePending))
+
+	for _, status := range []string{fileCompleted, fileFailed, fileSkipped} {
+		assert.True(t, isTerminalFileStatus(status), status)
+		assert.NotContains(t, fileStatusesBefore(fileParsing), status)
+	}
+	assert.False(t, isTerminalFileStatus(filePersisting))
+}
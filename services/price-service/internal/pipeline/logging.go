@@ -0,0 +1,74 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/logging"
+	"github.com/rs/zerolog"
+)
+
+// logger is the pipeline's component logger. Code running inside a run logs
+// through logFrom(ctx) instead, so messages carry the run, chain and file.
+var logger = logging.For(logging.Pipeline)
+
+// withRunLogger returns ctx carrying a logger that tags every message with
+// the run and chain
+func withRunLogger(ctx context.Context, runID, chainID string) context.Context {
+	l := logger.With().Str("run_id", runID).Str("chain", chainID).Logger()
+	return l.WithContext(ctx)
+}
+
+// withFileLogger returns ctx carrying its run logger, also tagged with the
+// file being processed
+func withFileLogger(ctx context.Context, filename string) context.Context {
+	l := logFrom(ctx).With().Str("file", filename).Logger()
+	return l.WithContext(ctx)
+}
+
+// logFrom returns the logger carried by ctx, or the package logger outside
+// a run
+func logFrom(ctx context.Context) *zerolog.Logger {
+	if l := zerolog.Ctx(ctx); l.GetLevel() != zerolog.Disabled && l != zerolog.DefaultContextLogger {
+		return l
+	}
+	return &logger
+}
+
+// reportError logs a failure at error level and records it in
+// ingestion_errors, so it shows up in the run's error summary and not only
+// in the service logs. fileID may be empty for failures before a file record
+// exists.
+func reportError(ctx context.Context, runID, fileID, errorType string, err error, msg string, details map[string]any) {
+	event := logFrom(ctx).Error().Err(err).Str("error_type", errorType)
+	for k, v := range details {
+		event = event.Interface(k, v)
+	}
+	event.Msg(msg)
+
+	var detailsJSON []byte
+	if len(details) > 0 {
+		detailsJSON, _ = json.Marshal(details)
+	}
+	var fileRef *string
+	if fileID != "" {
+		fileRef = &fileID
+	}
+
+	_, dbErr := database.Pool().Exec(ctx, `
+		INSERT INTO ingestion_errors (run_id, file_id, error_type, error_message, error_details, severity)
+		VALUES ($1, $2, $3, $4, $5, 'error')
+	`, runID, fileRef, errorType, msg+": "+err.Error(), nullableJSON(detailsJSON))
+	if dbErr != nil {
+		logFrom(ctx).Warn().Err(dbErr).Str("error_type", errorType).Msg("Failed to record ingestion error")
+	}
+}
+
+// nullableJSON returns nil for empty JSON so the column stays NULL
+func nullableJSON(b []byte) any {
+	if len(b) == 0 {
+		return nil
+	}
+	return string(b)
+}
@@ -0,0 +1,46 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kosarica/price-service/internal/logging"
+)
+
+func TestRunLoggerFields(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, logging.Configure(&buf, zerolog.InfoLevel, nil))
+	t.Cleanup(func() { _ = logging.Configure(os.Stderr, zerolog.InfoLevel, nil) })
+
+	ctx := withRunLogger(context.Background(), "run_1", "konzum")
+	logFrom(ctx).Info().Msg("run message")
+	logFrom(withFileLogger(ctx, "prices.csv")).Info().Msg("file message")
+	logFrom(context.Background()).Info().Msg("outside a run")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+
+	var run, file, outside map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &run))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &file))
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &outside))
+
+	assert.Equal(t, "run_1", run["run_id"])
+	assert.Equal(t, "konzum", run["chain"])
+	assert.NotContains(t, run, "file")
+
+	assert.Equal(t, "run_1", file["run_id"])
+	assert.Equal(t, "prices.csv", file["file"])
+	assert.Equal(t, "pipeline", file["component"])
+
+	assert.Equal(t, "pipeline", outside["component"])
+	assert.NotContains(t, outside, "run_id")
+}
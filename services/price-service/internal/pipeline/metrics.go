@@ -0,0 +1,55 @@
+package pipeline
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	runsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "pipeline_runs_total",
+		Help: "Ingestion runs finished by chain and outcome (success, failure)",
+	}, []string{"chain", "outcome"})
+
+	runDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "pipeline_run_duration_seconds",
+		Help:    "Wall-clock duration of ingestion runs by chain",
+		Buckets: []float64{10, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+	}, []string{"chain"})
+
+	filesProcessedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "pipeline_files_processed_total",
+		Help: "Files parsed and persisted or staged by chain",
+	}, []string{"chain"})
+
+	rowsPersistedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "pipeline_rows_persisted_total",
+		Help: "Rows persisted or staged by chain",
+	}, []string{"chain"})
+
+	priceChangesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "pipeline_price_changes_total",
+		Help: "Price changes recorded by chain",
+	}, []string{"chain"})
+
+	runErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "pipeline_run_errors_total",
+		Help: "Errors reported by ingestion runs by chain",
+	}, []string{"chain"})
+)
+
+// recordRunMetrics records a finished run's outcome, duration and counts
+func recordRunMetrics(chainID string, result *IngestionResult, startedAt time.Time) {
+	outcome := "failure"
+	if result.Success {
+		outcome = "success"
+	}
+	runsTotal.WithLabelValues(chainID, outcome).Inc()
+	runDuration.WithLabelValues(chainID).Observe(time.Since(startedAt).Seconds())
+	filesProcessedTotal.WithLabelValues(chainID).Add(float64(result.FilesProcessed))
+	rowsPersistedTotal.WithLabelValues(chainID).Add(float64(result.EntriesPersisted))
+	priceChangesTotal.WithLabelValues(chainID).Add(float64(result.PriceChanges))
+	runErrorsTotal.WithLabelValues(chainID).Add(float64(len(result.Errors)))
+}
@@ -0,0 +1,22 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordRunMetrics(t *testing.T) {
+	chain := "metrics-test"
+	recordRunMetrics(chain, &IngestionResult{Success: true, FilesProcessed: 3, EntriesPersisted: 120, PriceChanges: 7}, time.Now())
+	recordRunMetrics(chain, &IngestionResult{Errors: []string{"fetch failed"}}, time.Now())
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(runsTotal.WithLabelValues(chain, "success")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(runsTotal.WithLabelValues(chain, "failure")))
+	assert.Equal(t, 3.0, testutil.ToFloat64(filesProcessedTotal.WithLabelValues(chain)))
+	assert.Equal(t, 120.0, testutil.ToFloat64(rowsPersistedTotal.WithLabelValues(chain)))
+	assert.Equal(t, 7.0, testutil.ToFloat64(priceChangesTotal.WithLabelValues(chain)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(runErrorsTotal.WithLabelValues(chain)))
+}
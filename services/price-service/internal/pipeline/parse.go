@@ -1,16 +1,17 @@
 package pipeline
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/kosarica/price-service/internal/adapters/config"
 	"github.com/kosarica/price-service/internal/adapters/registry"
 	"github.com/kosarica/price-service/internal/database"
 	"github.com/kosarica/price-service/internal/types"
-	"github.com/rs/zerolog/log"
 )
 
 // ParseResult represents the result of parsing a file
@@ -19,67 +20,117 @@ type ParseResult struct {
 	RowsByStore map[string][]types.NormalizedRow
 	TotalRows   int
 	ValidRows   int
+	// DoneChunks is the number of the file's stores persisted before its run
+	// was interrupted; persisting continues after them
+	DoneChunks int
+}
+
+// streamParseThreshold is the file size from which files are parsed as a
+// stream of rows when the chain's adapter supports it
+var streamParseThreshold = 32 << 20
+
+// streamingParser is implemented by adapters that can parse a file one row
+// at a time instead of building the whole document in memory. The returned
+// result has counts, errors and warnings but no rows.
+type streamingParser interface {
+	ParseStream(r io.Reader, filename string, emit func(types.NormalizedRow) error) (*types.ParseResult, error)
 }
 
 // ParsePhase executes the parse phase of the ingestion pipeline
-// It parses file content into normalized rows
-func ParsePhase(ctx context.Context, chainID string, fetchResult *FetchResult, file types.DiscoveredFile, runID string) (*ParseResult, error) {
+// It parses file content into normalized rows and records them on the
+// ingestion file fileID
+func ParsePhase(ctx context.Context, chainID string, fetchResult *FetchResult, file types.DiscoveredFile, runID string, fileID string) (*ParseResult, error) {
 	// Get adapter from registry
 	adapter, err := registry.GetAdapter(config.ChainID(chainID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get adapter for %s: %w", chainID, err)
 	}
 
-	log.Info().Str("filename", file.Filename).Msg("Parsing file")
-
-	// Parse the content
-	parseResult, err := adapter.Parse(fetchResult.Content, file.Filename, nil)
+	logFrom(ctx).Info().Msg("Parsing file")
+
+	// Parse the content, profiling it when enabled for the chain
+	// Large files of streaming adapters go straight into their stores' rows,
+	// row warnings collected on the way as the result keeps no rows
+	profiler := startParseProfile(ctx, chainID)
+	var parseResult *types.ParseResult
+	var rowsByStore map[string][]types.NormalizedRow
+	var rowWarnings []types.ParseWarning
+	if streamer, ok := adapter.(streamingParser); ok && len(fetchResult.Content) >= streamParseThreshold {
+		logFrom(ctx).Info().Int("bytes", len(fetchResult.Content)).Msg("Streaming parse of large file")
+		rowsByStore = make(map[string][]types.NormalizedRow)
+		parseResult, err = streamer.ParseStream(bytes.NewReader(fetchResult.Content), file.Filename, func(row types.NormalizedRow) error {
+			rowWarnings = append(rowWarnings, validationWarnings(row)...)
+			addRowToStore(rowsByStore, row)
+			return nil
+		})
+	} else {
+		parseResult, err = adapter.Parse(fetchResult.Content, file.Filename, nil)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("parse failed for %s: %w", file.Filename, err)
 	}
+	var profile *ParseProfile
+	if profiler != nil {
+		profile = profiler.stop(parseResult.TotalRows)
+		logFrom(ctx).Info().
+			Float64("cpu_seconds", profile.CPUSeconds).
+			Uint64("allocs", profile.Allocs).
+			Float64("rows_per_second", profile.RowsPerSecond).
+			Msg("Profiled parse")
+	}
 
-	log.Info().
+	logFrom(ctx).Info().
 		Int("total_rows", parseResult.TotalRows).
 		Int("valid_rows", parseResult.ValidRows).
-		Str("filename", file.Filename).
 		Msg("Parsed file")
 
 	// Log any parse errors
 	if len(parseResult.Errors) > 0 {
-		log.Warn().
+		logFrom(ctx).Warn().
 			Int("error_count", len(parseResult.Errors)).
-			Str("filename", file.Filename).
 			Msg("Parse errors found")
-		for _, e := range parseResult.Errors[:5] { // Log first 5 errors
-			event := log.Warn().
-				Str("error", e.Message).
-				Str("filename", file.Filename)
+		for _, e := range parseResult.Errors[:min(len(parseResult.Errors), 5)] { // Log first 5 errors
+			event := logFrom(ctx).Warn().
+				Str("error", e.Message)
 			if e.RowNumber != nil {
 				event = event.Int("row_number", *e.RowNumber)
 			}
 			event.Msg("Parse error")
 		}
 		if len(parseResult.Errors) > 5 {
-			log.Warn().
+			logFrom(ctx).Warn().
 				Int("additional_error_count", len(parseResult.Errors)-5).
-				Str("filename", file.Filename).
 				Msg("Additional parse errors not shown")
 		}
 	}
 
-	// Create file record in database
-	fileID := generateFileID()
+	// Aggregate parser and row validation warnings for data QA
+	warnings := append(collectWarnings(parseResult), rowWarnings...)
+	if len(warnings) > 0 {
+		logFrom(ctx).Info().
+			Int("warning_count", len(warnings)).
+			Msg("Parse warnings found")
+	}
+
+	// Record the parse on the file
 	storeIdentifier := "unknown"
 	if storeID := adapter.ExtractStoreIdentifier(file); storeID != nil {
 		storeIdentifier = storeID.Value
 	}
 
-	if err := createIngestionFile(ctx, fileID, runID, file, fetchResult, parseResult, storeIdentifier); err != nil {
-		return nil, fmt.Errorf("failed to create ingestion file record: %w", err)
+	if err := recordParsedFile(ctx, fileID, file, fetchResult, parseResult, storeIdentifier, len(warnings), profile, parserVersion(adapter)); err != nil {
+		return nil, fmt.Errorf("failed to update ingestion file record: %w", err)
+	}
+	if profile != nil {
+		recordParseProfile(ctx, chainID, runID, profile)
+	}
+
+	if err := recordParseWarnings(ctx, runID, fileID, aggregateWarnings(warnings)); err != nil {
+		logFrom(ctx).Error().Err(err).Msg("Failed to record parse warnings")
 	}
 
 	if parseResult.ValidRows == 0 {
-		log.Info().Str("filename", file.Filename).Msg("No valid rows to persist")
+		logFrom(ctx).Info().Msg("No valid rows to persist")
 		markFileCompleted(ctx, fileID, 0)
 		return &ParseResult{
 			FileID:    fileID,
@@ -89,7 +140,9 @@ func ParsePhase(ctx context.Context, chainID string, fetchResult *FetchResult, f
 	}
 
 	// Group rows by store identifier
-	rowsByStore := groupRowsByStore(parseResult.Rows)
+	if rowsByStore == nil {
+		rowsByStore = groupRowsByStore(parseResult.Rows)
+	}
 
 	return &ParseResult{
 		FileID:      fileID,
@@ -99,37 +152,62 @@ func ParsePhase(ctx context.Context, chainID string, fetchResult *FetchResult, f
 	}, nil
 }
 
-// createIngestionFile creates an ingestion file record in the database
-func createIngestionFile(ctx context.Context, fileID string, runID string, file types.DiscoveredFile, fetchResult *FetchResult, parseResult *types.ParseResult, storeIdentifier string) error {
+// recordParsedFile records the content, parse outcome and parser version of an
+// ingestion file
+func recordParsedFile(ctx context.Context, fileID string, file types.DiscoveredFile, fetchResult *FetchResult, parseResult *types.ParseResult, storeIdentifier string, warningCount int, profile *ParseProfile, parserVersion string) error {
 	pool := database.Pool()
 
-	metadataJSON, _ := json.Marshal(map[string]interface{}{
+	metadata := map[string]interface{}{
 		"storeIdentifier": storeIdentifier,
 		"url":             file.URL,
-	})
+		"archiveId":       fetchResult.ArchiveID,
+	}
+	if profile != nil {
+		metadata["parseProfile"] = profile
+	}
+	metadataJSON, _ := json.Marshal(metadata)
 
 	_, err := pool.Exec(ctx, `
-		INSERT INTO ingestion_files (
-			id, run_id, filename, file_type, file_size, file_hash,
-			status, entry_count, total_chunks, chunk_size, metadata, created_at
-		) VALUES (
-			$1, $2, $3, $4, $5, $6, 'processing', $7, 1, $7, $8, NOW()
-		)
-	`, fileID, runID, file.Filename, string(file.Type), len(fetchResult.Content), fetchResult.Hash,
-		parseResult.ValidRows, metadataJSON)
+		UPDATE ingestion_files
+		SET file_size = $2,
+		    file_hash = $3,
+		    entry_count = $4,
+		    total_chunks = 1,
+		    chunk_size = $4,
+		    metadata = $5,
+		    warning_count = $6,
+		    parser_version = NULLIF($7, '')
+		WHERE id = $1
+	`, fileID, len(fetchResult.Content), fetchResult.Hash, parseResult.ValidRows, metadataJSON, warningCount, parserVersion)
 
 	return err
 }
 
+// recordChunkProgress records how many of a file's chunks are persisted.
+// Failing to record it only means the chunks are persisted again on resume.
+func recordChunkProgress(ctx context.Context, fileID string, done int, total int) {
+	pool := database.Pool()
+	_, err := pool.Exec(ctx, `
+		UPDATE ingestion_files
+		SET processed_chunks = $2,
+		    total_chunks = $3
+		WHERE id = $1
+	`, fileID, done, total)
+	if err != nil {
+		logFrom(ctx).Warn().Err(err).Msg("Failed to record chunk progress")
+	}
+}
+
 // markFileCompleted marks an ingestion file as completed
 func markFileCompleted(ctx context.Context, fileID string, processedChunks int) error {
-	pool := database.Pool()
+	if err := transitionFile(ctx, fileID, fileCompleted, ""); err != nil {
+		return err
+	}
 
+	pool := database.Pool()
 	_, err := pool.Exec(ctx, `
 		UPDATE ingestion_files
-		SET status = 'completed',
-		    processed_chunks = $1,
-		    processed_at = NOW()
+		SET processed_chunks = $1
 		WHERE id = $2
 	`, processedChunks, fileID)
 
@@ -141,16 +219,21 @@ func groupRowsByStore(rows []types.NormalizedRow) map[string][]types.NormalizedR
 	result := make(map[string][]types.NormalizedRow)
 
 	for _, row := range rows {
-		storeID := row.StoreIdentifier
-		if storeID == "" {
-			storeID = "unknown"
-		}
-		result[storeID] = append(result[storeID], row)
+		addRowToStore(result, row)
 	}
 
 	return result
 }
 
+// addRowToStore adds a row to the rows of its store
+func addRowToStore(rowsByStore map[string][]types.NormalizedRow, row types.NormalizedRow) {
+	storeID := row.StoreIdentifier
+	if storeID == "" {
+		storeID = "unknown"
+	}
+	rowsByStore[storeID] = append(rowsByStore[storeID], row)
+}
+
 // generateFileID generates a unique file ID
 func generateFileID() string {
 	return fmt.Sprintf("igf_%d", time.Now().UnixNano())
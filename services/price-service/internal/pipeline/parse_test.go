@@ -0,0 +1,25 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/kosarica/price-service/internal/adapters/base"
+	"github.com/kosarica/price-service/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// XML adapters parse large files as a stream
+var _ streamingParser = (*base.BaseXmlAdapter)(nil)
+
+func TestGroupRowsByStore(t *testing.T) {
+	rows := []types.NormalizedRow{
+		{StoreIdentifier: "T1", Name: "a"},
+		{Name: "b"},
+		{StoreIdentifier: "T1", Name: "c"},
+	}
+
+	grouped := groupRowsByStore(rows)
+	assert.Len(t, grouped, 2)
+	assert.Equal(t, []types.NormalizedRow{rows[0], rows[2]}, grouped["T1"])
+	assert.Equal(t, []types.NormalizedRow{rows[1]}, grouped["unknown"])
+}
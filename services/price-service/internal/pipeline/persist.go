@@ -5,19 +5,28 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/rs/zerolog/log"
+	appconfig "github.com/kosarica/price-service/config"
 	"github.com/kosarica/price-service/internal/adapters/config"
 	"github.com/kosarica/price-service/internal/adapters/registry"
 	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/events"
+	"github.com/kosarica/price-service/internal/outbox"
 	"github.com/kosarica/price-service/internal/pkg/cuid2"
 	"github.com/kosarica/price-service/internal/pricegroups"
 	"github.com/kosarica/price-service/internal/types"
 )
 
+// significantPriceChangePercent is the relative price change, in percent, at
+// which a price change is published as an outbox event
+const significantPriceChangePercent = 10
+
 // PersistResult represents the result of persisting parsed data
 type PersistResult struct {
 	Persisted    int
@@ -27,64 +36,36 @@ type PersistResult struct {
 // PersistPhase executes the persist phase of the ingestion pipeline
 // It persists normalized rows to the database and links them to the archive
 func PersistPhase(ctx context.Context, chainID string, parseResult *ParseResult, file types.DiscoveredFile, runID string, archiveID string) (*PersistResult, error) {
-	// Get adapter from registry
-	adapter, err := registry.GetAdapter(config.ChainID(chainID))
+	outcome, err := persistFileRows(ctx, chainID, parseResult, file, runID, archiveID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get adapter for %s: %w", chainID, err)
+		return nil, err
 	}
+	outcome.publish(ctx, archiveID)
 
-	// Extract store metadata for auto-registration
-	storeMetadata := adapter.ExtractStoreMetadata(file)
-
-	totalPersisted := 0
-	totalPriceChanges := 0
-	var allItemIDs []string
-
-	for storeIdentifier, rows := range parseResult.RowsByStore {
-		// Resolve or register store
-		storeID, err := resolveOrCreateStore(ctx, chainID, storeIdentifier, storeMetadata)
-		if err != nil {
-			log.Warn().Err(err).Str("store_identifier", storeIdentifier).Msg("Failed to resolve store")
-			continue
-		}
-
-		// Persist rows for this store
-		persisted, priceChanges, itemIDs, err := persistRowsForStore(ctx, chainID, storeID, storeIdentifier, rows, archiveID, runID, parseResult.FileID)
-		if err != nil {
-			log.Error().Err(err).Str("store_identifier", storeIdentifier).Msg("Failed to persist rows for store")
-			continue
-		}
-
-		totalPersisted += persisted
-		totalPriceChanges += priceChanges
-		allItemIDs = append(allItemIDs, itemIDs...)
+	result := &PersistResult{
+		Persisted:    outcome.Persisted,
+		PriceChanges: outcome.PriceChanges,
 	}
-
-	// Link retailer items to archive
-	if archiveID != "" && len(allItemIDs) > 0 {
-		if err := database.UpdateRetailerItemArchiveID(ctx, allItemIDs, archiveID); err != nil {
-			log.Warn().Err(err).Str("archive_id", archiveID).Int("item_count", len(allItemIDs)).Msg("Failed to link items to archive")
-		} else {
-			log.Info().Str("archive_id", archiveID).Int("item_count", len(allItemIDs)).Msg("Linked items to archive")
-		}
+	if err := recordFileProgress(ctx, runID, parseResult.FileID, len(parseResult.RowsByStore), result.Persisted, result.PriceChanges); err != nil {
+		return result, err
 	}
+	return result, nil
+}
 
-	log.Info().Str("filename", file.Filename).Int("persisted", totalPersisted).Int("price_changes", totalPriceChanges).Msg("Persisted rows")
-
+// recordFileProgress marks a file completed with all its chunks, adds it to
+// the run's progress and completes the run when it was the last file
+func recordFileProgress(ctx context.Context, runID string, fileID string, chunks int, entries int, priceChanges int) error {
 	// Collect cleanup errors
 	var persistErrors []error
 
 	// Mark file as completed
-	if err := markFileCompleted(ctx, parseResult.FileID, 1); err != nil {
+	if err := markFileCompleted(ctx, fileID, chunks); err != nil {
 		persistErrors = append(persistErrors, fmt.Errorf("failed to mark file as completed: %w", err))
 	}
 
 	// Update run progress
-	if err := incrementProcessedFiles(ctx, runID); err != nil {
-		persistErrors = append(persistErrors, fmt.Errorf("failed to increment processed files: %w", err))
-	}
-	if err := incrementProcessedEntries(ctx, runID, totalPersisted); err != nil {
-		persistErrors = append(persistErrors, fmt.Errorf("failed to increment processed entries: %w", err))
+	if err := incrementRunProgress(ctx, runID, 1, entries, priceChanges); err != nil {
+		persistErrors = append(persistErrors, fmt.Errorf("failed to update run progress: %w", err))
 	}
 
 	// Check if run is complete
@@ -93,16 +74,113 @@ func PersistPhase(ctx context.Context, chainID string, parseResult *ParseResult,
 	}
 
 	if len(persistErrors) > 0 {
-		return &PersistResult{
-			Persisted:    totalPersisted,
-			PriceChanges: totalPriceChanges,
-		}, fmt.Errorf("encountered %d error(s) during cleanup: %w", len(persistErrors), errors.Join(persistErrors...))
+		return fmt.Errorf("encountered %d error(s) during cleanup: %w", len(persistErrors), errors.Join(persistErrors...))
 	}
+	return nil
+}
 
-	return &PersistResult{
-		Persisted:    totalPersisted,
-		PriceChanges: totalPriceChanges,
-	}, nil
+// fileOutcome is what persisting the rows of one file wrote
+type fileOutcome struct {
+	Persisted    int
+	PriceChanges int
+	ItemIDs      []string
+	// ChangeEvents holds one event per item whose price or discount changed
+	ChangeEvents []events.PriceChangeEvent
+	// FailedStores counts the stores whose rows could not be persisted
+	FailedStores int
+}
+
+// persistFileRows writes the rows of a parsed file store by store. A store
+// that fails is reported and counted, and the others are still written.
+func persistFileRows(ctx context.Context, chainID string, parseResult *ParseResult, file types.DiscoveredFile, runID string, archiveID string) (*fileOutcome, error) {
+	// Get adapter from registry
+	adapter, err := registry.GetAdapter(config.ChainID(chainID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get adapter for %s: %w", chainID, err)
+	}
+
+	// Extract store metadata for auto-registration
+	storeMetadata := adapter.ExtractStoreMetadata(file)
+
+	outcome := &fileOutcome{}
+
+	// A file listing a single store is that store's full list, so it also
+	// tells which items the store carries
+	perStoreList := len(parseResult.RowsByStore) == 1
+
+	// Each store's rows are a chunk, persisted in a fixed order and counted
+	// on the file, so a file interrupted while persisting continues after the
+	// chunks it already wrote. Publishing a staged run is all or nothing and
+	// has no chunks to skip.
+	storeIdentifiers := slices.Sorted(maps.Keys(parseResult.RowsByStore))
+	trackChunks := !publishing(ctx)
+	for i, storeIdentifier := range storeIdentifiers {
+		if i < parseResult.DoneChunks {
+			continue
+		}
+		if trackChunks {
+			recordChunkProgress(ctx, parseResult.FileID, i, len(storeIdentifiers))
+		}
+		rows := parseResult.RowsByStore[storeIdentifier]
+
+		// Files of a run processed at once take turns on a store
+		var storeID string
+		var storeResult *storePersistResult
+		var resolveErr error
+		err := withStoreLock(ctx, storeIdentifier, func() error {
+			// Resolve or register store
+			storeID, resolveErr = resolveOrCreateStore(ctx, chainID, storeIdentifier, storeMetadata)
+			if resolveErr != nil {
+				return resolveErr
+			}
+
+			// Persist rows for this store
+			var err error
+			storeResult, err = persistRowsForStore(ctx, chainID, storeID, storeIdentifier, rows, archiveID, runID, parseResult.FileID, perStoreList)
+			return err
+		})
+		if resolveErr != nil {
+			reportError(ctx, runID, parseResult.FileID, "store_resolution_failed", resolveErr, "Failed to resolve store",
+				map[string]any{"store_identifier": storeIdentifier})
+			outcome.FailedStores++
+			continue
+		}
+		if err != nil {
+			reportError(ctx, runID, parseResult.FileID, "store_persist_failed", err, "Failed to persist rows for store",
+				map[string]any{"store_identifier": storeIdentifier, "store_id": storeID, "rows": len(rows)})
+			outcome.FailedStores++
+			continue
+		}
+
+		outcome.Persisted += storeResult.Persisted
+		outcome.PriceChanges += storeResult.PriceChanges
+		outcome.ItemIDs = append(outcome.ItemIDs, storeResult.ItemIDs...)
+		outcome.ChangeEvents = append(outcome.ChangeEvents, storeResult.ChangeEvents...)
+	}
+	if trackChunks {
+		recordChunkProgress(ctx, parseResult.FileID, len(storeIdentifiers), len(storeIdentifiers))
+	}
+
+	logFrom(ctx).Info().Int("persisted", outcome.Persisted).Int("price_changes", outcome.PriceChanges).Msg("Persisted rows")
+	return outcome, nil
+}
+
+// publish announces what a file wrote once it is committed: per-item change
+// events go out and the retailer items are linked to the file's archive
+func (o *fileOutcome) publish(ctx context.Context, archiveID string) {
+	if len(o.ChangeEvents) > 0 {
+		if err := events.Default().PublishPriceChanges(ctx, o.ChangeEvents); err != nil {
+			logFrom(ctx).Warn().Err(err).Int("events", len(o.ChangeEvents)).Msg("Failed to publish price change events")
+		}
+	}
+
+	if archiveID != "" && len(o.ItemIDs) > 0 {
+		if err := database.UpdateRetailerItemArchiveID(ctx, o.ItemIDs, archiveID); err != nil {
+			logFrom(ctx).Warn().Err(err).Str("archive_id", archiveID).Int("item_count", len(o.ItemIDs)).Msg("Failed to link items to archive")
+		} else {
+			logFrom(ctx).Info().Str("archive_id", archiveID).Int("item_count", len(o.ItemIDs)).Msg("Linked items to archive")
+		}
+	}
 }
 
 // resolveOrCreateStore resolves an existing store or creates a new one
@@ -187,80 +265,189 @@ func createStore(ctx context.Context, chainID string, storeIdentifier string, me
 		return "", fmt.Errorf("failed to insert store identifier: %w", err)
 	}
 
-	log.Info().Str("store_name", name).Str("store_id", storeID).Msg("Auto-registered store")
+	logFrom(ctx).Info().Str("store_name", name).Str("store_id", storeID).Msg("Auto-registered store")
 	return storeID, nil
 }
 
-// persistRowsForStore persists normalized rows for a specific store using price groups
-func persistRowsForStore(ctx context.Context, chainID string, storeID string, storeIdentifier string, rows []types.NormalizedRow, archiveID string, runID string, fileID string) (int, int, []string, error) {
-	// Step 1: Collect all validated items with prices
-	itemPrices := make([]pricegroups.ItemPrice, 0, len(rows))
-	itemData := make(map[string]types.NormalizedRow) // Map itemID -> row data
-	persisted := 0
-	priceChanges := 0
-	itemIDs := make([]string, 0, len(rows))
+// storePersistResult represents the result of persisting the rows of one store
+type storePersistResult struct {
+	Persisted    int
+	PriceChanges int
+	ItemIDs      []string
+	// ChangeEvents holds one event per item whose price or discount changed
+	ChangeEvents []events.PriceChangeEvent
+}
 
-	// First pass: validate and find/create retailer items, build price hash input
+// persistRowsForStore persists normalized rows for a specific store using price groups.
+// Rows failing validation are saved to retailer_items_failed first, outside
+// the store's transaction, so they are kept whatever happens to it. The rest
+// is written by persistStoreTx, which is retried on serialization failures.
+// perStoreList marks rows that are the store's complete list, which then
+// replace its assortment.
+func persistRowsForStore(ctx context.Context, chainID string, storeID string, storeIdentifier string, rows []types.NormalizedRow, archiveID string, runID string, fileID string, perStoreList bool) (*storePersistResult, error) {
+	valid := make([]types.NormalizedRow, 0, len(rows))
 	for _, row := range rows {
-		// Validate row
 		validation := validateNormalizedRow(row)
 		if !validation.IsValid {
-			fmt.Printf("[DEBUG] VALIDATION FAILED - Row %d\n", row.RowNumber)
-			fmt.Printf("  Name: %q\n", row.Name)
-			fmt.Printf("  Price: %d\n", row.Price)
-			fmt.Printf("  Store: %s, Chain: %s\n", row.StoreIdentifier, chainID)
-			fmt.Printf("  Errors: %v\n", validation.Errors)
-			fmt.Printf("  Raw Data: %s\n", row.RawData)
+			logFrom(ctx).Debug().
+				Int("row_number", row.RowNumber).
+				Str("name", row.Name).
+				Int("price", row.Price).
+				Str("store_identifier", row.StoreIdentifier).
+				Strs("errors", validation.Errors).
+				Str("raw_data", row.RawData).
+				Msg("Row failed validation")
 
 			// Save failed row for later analysis and re-processing
 			if err := saveFailedRow(ctx, database.Pool(), chainID, runID, fileID, row, validation); err != nil {
-				log.Error().Err(err).Int("row_number", row.RowNumber).Msg("Failed to save failed row")
+				logFrom(ctx).Error().Err(err).Int("row_number", row.RowNumber).Msg("Failed to save failed row")
 			}
-
 			continue
 		}
+		valid = append(valid, row)
+	}
+
+	if len(valid) == 0 {
+		return &storePersistResult{}, nil // No valid items
+	}
+
+	// Upsert in key order so concurrent transactions lock shared rows in the
+	// same order and cannot deadlock each other
+	sortRowsByIdentity(valid)
+
+	var result *storePersistResult
+	err := database.WithRetry(ctx, "persist_store", func(ctx context.Context) error {
+		var err error
+		result, err = persistStoreTx(ctx, chainID, storeID, valid, archiveID, runID, fileID, perStoreList)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// sortRowsByIdentity orders rows by the key their retailer item is upserted
+// under: rows with an external ID by that ID, then the rest by identity hash
+func sortRowsByIdentity(rows []types.NormalizedRow) {
+	type keyedRow struct {
+		row        types.NormalizedRow
+		key        string
+		byIdentity bool
+	}
+	keyed := make([]keyedRow, len(rows))
+	for i, row := range rows {
+		key, byIdentity := retailerItemKey(row)
+		keyed[i] = keyedRow{row: row, key: key, byIdentity: byIdentity}
+	}
+
+	slices.SortStableFunc(keyed, func(a, b keyedRow) int {
+		if a.byIdentity != b.byIdentity {
+			if a.byIdentity {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(a.key, b.key)
+	})
+	for i := range keyed {
+		rows[i] = keyed[i].row
+	}
+}
+
+// retailerItemKey returns the key a row's retailer item is found under: its
+// external ID or, for chains that publish none, its identity hash
+func retailerItemKey(row types.NormalizedRow) (key string, byIdentity bool) {
+	if row.ExternalID != nil && *row.ExternalID != "" {
+		return *row.ExternalID, false
+	}
+	return database.RetailerItemIdentityHash(row.Name, row.Unit, row.UnitQuantity), true
+}
 
-		// Find or create retailer item
-		retailerItemID, err := findOrCreateRetailerItem(ctx, chainID, row, archiveID)
+// priceHashOptions returns the configured price hash, the original version
+// when there is no configuration
+func priceHashOptions() pricegroups.HashOptions {
+	cfg := appconfig.Get()
+	if cfg == nil {
+		return pricegroups.DefaultHashOptions()
+	}
+	pg := cfg.PriceGroups
+	return pricegroups.HashOptions{
+		Version:           pg.HashVersion,
+		IgnoreDiscountEnd: pg.IgnoreDiscountEnd,
+		PriceBucketCents:  pg.PriceBucketCents,
+		SortByItemID:      pg.SortByItemID,
+	}
+}
+
+// persistStoreTx makes one attempt at persisting a store's validated rows.
+// Retailer items, the price group and its prices, the store's assignment to
+// the group and the store's item state are written in one transaction, so a
+// failure leaves no partial data behind. Each step runs under a savepoint:
+// a single item that fails is rolled back to its savepoint and skipped,
+// while a failing group or assignment step fails the whole store. When a
+// staged run is being published, the store's transaction is a savepoint of
+// the publish transaction.
+func persistStoreTx(ctx context.Context, chainID string, storeID string, rows []types.NormalizedRow, archiveID string, runID string, fileID string, perStoreList bool) (*storePersistResult, error) {
+	tx, err := beginStoreTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Step 1: Find or create retailer items and build price hash input
+	itemPrices := make([]pricegroups.ItemPrice, 0, len(rows))
+	itemData := make(map[string]types.NormalizedRow) // Map itemID -> row data
+	itemIDs := make([]string, 0, len(rows))
+
+	for _, row := range rows {
+		var retailerItemID string
+		err := withSavepoint(ctx, tx, func(sp pgx.Tx) error {
+			var err error
+			retailerItemID, err = findOrCreateRetailerItemTx(ctx, sp, chainID, row, archiveID)
+			return err
+		})
 		if err != nil {
-			log.Warn().Err(err).Int("row_number", row.RowNumber).Msg("Failed to find/create retailer item")
+			if database.IsRetryable(err) {
+				return nil, err
+			}
+			logFrom(ctx).Warn().Err(err).Int("row_number", row.RowNumber).Msg("Failed to find/create retailer item")
 			continue
 		}
 
 		itemIDs = append(itemIDs, retailerItemID)
 		itemData[retailerItemID] = row
 
-		// Add to price hash input
 		itemPrices = append(itemPrices, pricegroups.ItemPrice{
 			ItemID:        retailerItemID,
 			Price:         row.Price,
 			DiscountPrice: row.DiscountPrice,
+			DiscountStart: row.DiscountStart,
+			DiscountEnd:   row.DiscountEnd,
 		})
 	}
 
 	if len(itemPrices) == 0 {
-		return 0, 0, nil, nil // No valid items
+		return &storePersistResult{}, nil // No valid items
 	}
 
-	// Step 2: Compute price hash
-	priceHash := pricegroups.ComputePriceHash(itemPrices)
+	// Step 2: Find or create the price group by hash; a new group gets its
+	// prices in the same step, so a group never exists without them
+	hashOptions := priceHashOptions()
+	priceHash := pricegroups.ComputePriceHashWith(itemPrices, hashOptions)
 
-	// Step 3: Find or create price group by hash
-	group, isNewGroup, err := database.FindOrCreatePriceGroup(ctx, chainID, priceHash)
-	if err != nil {
-		return 0, 0, nil, fmt.Errorf("failed to find/create price group: %w", err)
-	}
-
-	// Detect "Zombie Group" scenario:
-	// If a previous run created the group but failed to insert prices, we have an existing group with 0 items.
-	// We must treat this as a new group to retry the price insertion.
-	if !isNewGroup && len(itemPrices) > 0 && group.ItemCount == 0 {
-		log.Warn().Str("price_group_id", group.ID).Msg("Detected zombie price group (0 items). Attempting repair")
-		isNewGroup = true
-	}
+	var group *database.PriceGroup
+	var isNewGroup bool
+	err = withSavepoint(ctx, tx, func(sp pgx.Tx) error {
+		var err error
+		group, isNewGroup, err = database.FindOrCreatePriceGroup(ctx, sp, chainID, priceHash, hashOptions.EffectiveVersion())
+		if err != nil {
+			return fmt.Errorf("failed to find/create price group: %w", err)
+		}
+		if !isNewGroup {
+			return database.UpdateGroupLastSeen(ctx, sp, group.ID)
+		}
 
-	// Step 4: If new group (or zombie), bulk insert group prices
-	if isNewGroup {
 		groupPrices := make([]database.GroupPrice, 0, len(itemPrices))
 		for _, itemPrice := range itemPrices {
 			row := itemData[itemPrice.ItemID]
@@ -271,158 +458,367 @@ func persistRowsForStore(ctx context.Context, chainID string, storeID string, st
 				DiscountPrice:  itemPrice.DiscountPrice,
 				UnitPrice:      row.UnitPrice,
 				AnchorPrice:    row.AnchorPrice,
+				DiscountStart:  row.DiscountStart,
+				DiscountEnd:    row.DiscountEnd,
 			})
 		}
-
-		if err := database.BulkInsertGroupPrices(ctx, group.ID, groupPrices); err != nil {
-			return 0, 0, nil, fmt.Errorf("failed to bulk insert group prices: %w", err)
-		}
-		log.Info().Str("price_group_id", group.ID).Int("item_count", len(groupPrices)).Msg("Created new price group")
-	} else {
-		// Existing group: update last_seen_at
-		if err := database.UpdateGroupLastSeen(ctx, group.ID); err != nil {
-			log.Warn().Err(err).Str("price_group_id", group.ID).Msg("Failed to update group last_seen")
+		if err := database.BulkInsertGroupPrices(ctx, sp, group.ID, groupPrices); err != nil {
+			return fmt.Errorf("failed to bulk insert group prices: %w", err)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Step 5: Assign store to group (closes previous membership)
-	if err := database.AssignStoreToGroup(ctx, storeID, group.ID); err != nil {
-		return 0, 0, nil, fmt.Errorf("failed to assign store to group: %w", err)
-	}
-
-	// Step 6: Update store_item_state for price change tracking
-	// We still maintain store_item_state for historical price tracking
-	pool := database.Pool()
-
-	// Begin transaction for store item state updates
-	tx, err := pool.Begin(ctx)
+	// Step 3: Assign store to group (closes previous membership); a store
+	// that moved has its cached prices patched once the transaction commits
+	err = withSavepoint(ctx, tx, func(sp pgx.Tx) error {
+		moved, err := database.AssignStoreToGroup(ctx, sp, storeID, group.ID)
+		if err != nil || !moved {
+			return err
+		}
+		return database.NotifyCacheInvalidation(ctx, sp, database.CacheInvalidation{ChainSlug: chainID, StoreID: storeID})
+	})
 	if err != nil {
-		return 0, 0, nil, fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, fmt.Errorf("failed to assign store to group: %w", err)
 	}
-	defer tx.Rollback(ctx)
+
+	// Step 4: Update store_item_state for price change tracking, in
+	// retailer_item_id order like every other batch upsert
+	slices.Sort(itemIDs)
+	significantChanges := make([]outbox.PriceChange, 0)
+	changeEvents := make([]events.PriceChangeEvent, 0)
+	historyIDs := make([]string, 0)
+	persistedIDs := make([]string, 0, len(itemIDs))
+	now := time.Now()
+	priceChanges := 0
 
 	for _, itemID := range itemIDs {
 		row := itemData[itemID]
 
-		// Check for price change (from previous state)
-		priceChanged := false
-		var previousPrice *int
-
-		if err := tx.QueryRow(ctx, `
-			SELECT current_price
-			FROM store_item_state
-			WHERE store_id = $1 AND retailer_item_id = $2
-		`, storeID, itemID).Scan(&previousPrice); err == nil && previousPrice != nil {
-			if *previousPrice != row.Price {
-				priceChanged = true
+		var previousPrice, previousDiscountPrice *int
+		err := withSavepoint(ctx, tx, func(sp pgx.Tx) error {
+			return upsertStoreItemState(ctx, sp, storeID, itemID, row, &previousPrice, &previousDiscountPrice)
+		})
+		if err != nil {
+			if database.IsRetryable(err) {
+				return nil, err
+			}
+			logFrom(ctx).Warn().Err(err).Str("retailer_item_id", itemID).Msg("Failed to upsert store item state for item")
+			continue
+		}
+		persistedIDs = append(persistedIDs, itemID)
+
+		priceChanged := previousPrice != nil && *previousPrice != row.Price
+		discountChanged := previousPrice != nil && !equalIntPtr(previousDiscountPrice, row.DiscountPrice)
+		if previousPrice == nil || priceChanged || discountChanged {
+			historyIDs = append(historyIDs, itemID)
+		}
+		if priceChanged || discountChanged {
+			changeEvents = append(changeEvents, events.PriceChangeEvent{
+				OccurredAt:       now,
+				RunID:            runID,
+				FileID:           fileID,
+				ChainSlug:        chainID,
+				StoreID:          storeID,
+				RetailerItemID:   itemID,
+				OldPrice:         previousPrice,
+				NewPrice:         row.Price,
+				OldDiscountPrice: previousDiscountPrice,
+				NewDiscountPrice: row.DiscountPrice,
+				DiscountStart:    row.DiscountStart,
+				DiscountEnd:      row.DiscountEnd,
+			})
+		}
+		if priceChanged {
+			priceChanges++
+			if isSignificantPriceChange(*previousPrice, row.Price) {
+				significantChanges = append(significantChanges, outbox.PriceChange{
+					RetailerItemID: itemID,
+					PreviousPrice:  *previousPrice,
+					CurrentPrice:   row.Price,
+				})
 			}
 		}
+	}
 
-		// Upsert store item state (for tracking price history)
-		priceSignature := computePriceSignature(row)
+	// Step 5: Barcodes are best effort; a failure only loses the barcodes
+	err = withSavepoint(ctx, tx, func(sp pgx.Tx) error {
+		return insertBarcodes(ctx, sp, persistedIDs, itemData)
+	})
+	if err != nil {
+		if database.IsRetryable(err) {
+			return nil, err
+		}
+		logFrom(ctx).Warn().Err(err).Str("store_id", storeID).Msg("Failed to insert barcodes")
+	}
 
-		_, err = tx.Exec(ctx, `
-			INSERT INTO store_item_state (
-				id, store_id, retailer_item_id, current_price, previous_price,
-				discount_price, discount_start, discount_end, in_stock,
-				unit_price, unit_price_base_quantity, unit_price_base_unit,
-				lowest_price_30d, anchor_price, anchor_price_as_of,
-				price_signature, last_seen_at, updated_at
-			) VALUES (
-				$1, $2, $3, $4, $5, $6, $7, $8, true,
-				$9, $10, $11, $12, $13, $14, $15, NOW(), NOW()
-			)
-			ON CONFLICT (store_id, retailer_item_id) DO UPDATE SET
-				previous_price = store_item_state.current_price,
-				current_price = EXCLUDED.current_price,
-				discount_price = EXCLUDED.discount_price,
-				discount_start = EXCLUDED.discount_start,
-				discount_end = EXCLUDED.discount_end,
-				unit_price = EXCLUDED.unit_price,
-				unit_price_base_quantity = EXCLUDED.unit_price_base_quantity,
-				unit_price_base_unit = EXCLUDED.unit_price_base_unit,
-				lowest_price_30d = EXCLUDED.lowest_price_30d,
-				anchor_price = EXCLUDED.anchor_price,
-				anchor_price_as_of = EXCLUDED.anchor_price_as_of,
-				price_signature = EXCLUDED.price_signature,
-				last_seen_at = NOW(),
-				updated_at = NOW()
-		`, cuid2.GeneratePrefixedId("sid", cuid2.PrefixedIdOptions{}), storeID, itemID, row.Price, previousPrice,
-			row.DiscountPrice, row.DiscountStart, row.DiscountEnd,
-			row.UnitPrice, row.UnitPriceBaseQuantity, row.UnitPriceBaseUnit,
-			row.LowestPrice30d, row.AnchorPrice, row.AnchorPriceAsOf,
-			priceSignature)
+	// Step 6: A complete list of the store replaces its assortment
+	if perStoreList {
+		if err := replaceStoreAssortment(ctx, tx, storeID, itemIDs); err != nil {
+			return nil, err
+		}
+	}
 
-		if err != nil {
-			log.Warn().Err(err).Str("retailer_item_id", itemID).Msg("Failed to upsert store item state for item")
-			continue
+	// Publish significant price changes atomically with the state update
+	if len(significantChanges) > 0 {
+		if err := outbox.Enqueue(ctx, tx, outbox.EventPriceChanged, storeID, outbox.PriceChangedPayload{
+			RunID:     runID,
+			ChainSlug: chainID,
+			StoreID:   storeID,
+			Changes:   significantChanges,
+		}); err != nil {
+			return nil, err
 		}
+	}
+
+	// Record changes for change data capture in the same transaction
+	if err := recordPriceChanges(ctx, tx, changeEvents); err != nil {
+		return nil, err
+	}
+	if err := recordPriceHistory(ctx, tx, chainID, storeID, now, historyIDs, itemData); err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if isNewGroup {
+		logFrom(ctx).Info().Str("price_group_id", group.ID).Int("item_count", len(itemPrices)).Msg("Created new price group")
+	}
+	logFrom(ctx).Info().Str("store_id", storeID).Str("price_group_id", group.ID).Int("item_count", len(itemPrices)).Msg("Assigned store to price group")
+
+	return &storePersistResult{
+		Persisted:    len(persistedIDs),
+		PriceChanges: priceChanges,
+		ItemIDs:      itemIDs,
+		ChangeEvents: changeEvents,
+	}, nil
+}
+
+// withSavepoint runs fn under a savepoint of tx, rolling back to it when fn
+// fails so tx stays usable
+func withSavepoint(ctx context.Context, tx pgx.Tx, fn func(sp pgx.Tx) error) error {
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+	if err := fn(sp); err != nil {
+		_ = sp.Rollback(ctx)
+		return err
+	}
+	return sp.Commit(ctx)
+}
+
+// upsertStoreItemState writes an item's current price at a store, reporting
+// the previous price and discount through previousPrice and
+// previousDiscountPrice (nil for an item new to the store)
+func upsertStoreItemState(ctx context.Context, tx pgx.Tx, storeID string, itemID string, row types.NormalizedRow, previousPrice, previousDiscountPrice **int) error {
+	err := tx.QueryRow(ctx, `
+		SELECT current_price, discount_price
+		FROM store_item_state
+		WHERE store_id = $1 AND retailer_item_id = $2
+	`, storeID, itemID).Scan(previousPrice, previousDiscountPrice)
+	if err != nil && err != pgx.ErrNoRows {
+		return fmt.Errorf("failed to read store item state: %w", err)
+	}
+
+	// Upsert store item state (for tracking price history)
+	priceSignature := computePriceSignature(row)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO store_item_state (
+			id, store_id, retailer_item_id, current_price, previous_price,
+			discount_price, discount_start, discount_end, in_stock,
+			unit_price, unit_price_base_quantity, unit_price_base_unit,
+			lowest_price_30d, anchor_price, anchor_price_as_of,
+			price_signature, last_seen_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, true,
+			$9, $10, $11, $12, $13, $14, $15, NOW(), NOW()
+		)
+		ON CONFLICT (store_id, retailer_item_id) DO UPDATE SET
+			previous_price = store_item_state.current_price,
+			current_price = EXCLUDED.current_price,
+			discount_price = EXCLUDED.discount_price,
+			discount_start = EXCLUDED.discount_start,
+			discount_end = EXCLUDED.discount_end,
+			unit_price = EXCLUDED.unit_price,
+			unit_price_base_quantity = EXCLUDED.unit_price_base_quantity,
+			unit_price_base_unit = EXCLUDED.unit_price_base_unit,
+			lowest_price_30d = EXCLUDED.lowest_price_30d,
+			anchor_price = EXCLUDED.anchor_price,
+			anchor_price_as_of = EXCLUDED.anchor_price_as_of,
+			price_signature = EXCLUDED.price_signature,
+			last_seen_at = NOW(),
+			updated_at = NOW()
+	`, cuid2.GeneratePrefixedId("sid", cuid2.PrefixedIdOptions{}), storeID, itemID, row.Price, *previousPrice,
+		row.DiscountPrice, row.DiscountStart, row.DiscountEnd,
+		row.UnitPrice, row.UnitPriceBaseQuantity, row.UnitPriceBaseUnit,
+		row.LowestPrice30d, row.AnchorPrice, row.AnchorPriceAsOf,
+		priceSignature)
+	return err
+}
+
+// replaceStoreAssortment records itemIDs as the items a store carries,
+// dropping the items its previous list had and this one does not. itemIDs
+// must be sorted, so rows are written in key order.
+func replaceStoreAssortment(ctx context.Context, tx pgx.Tx, storeID string, itemIDs []string) error {
+	_, err := tx.Exec(ctx, `
+		DELETE FROM store_item_assortment
+		WHERE store_id = $1 AND NOT (retailer_item_id = ANY($2))
+	`, storeID, itemIDs)
+	if err != nil {
+		return fmt.Errorf("failed to prune store assortment: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO store_item_assortment (store_id, retailer_item_id, last_seen_at)
+		SELECT $1, item_id, NOW()
+		FROM unnest($2::text[]) AS item_id
+		ON CONFLICT (store_id, retailer_item_id) DO UPDATE SET
+			last_seen_at = EXCLUDED.last_seen_at
+	`, storeID, itemIDs)
+	if err != nil {
+		return fmt.Errorf("failed to record store assortment: %w", err)
+	}
+	return nil
+}
 
-		// Insert barcodes
-		for _, barcode := range row.Barcodes {
+// insertBarcodes records the barcodes of the persisted items in one
+// statement. itemIDs must be sorted, so rows are written in key order.
+func insertBarcodes(ctx context.Context, tx pgx.Tx, itemIDs []string, itemData map[string]types.NormalizedRow) error {
+	var ids, barcodeItemIDs, barcodes []string
+	for _, itemID := range itemIDs {
+		for _, barcode := range slices.Sorted(slices.Values(itemData[itemID].Barcodes)) {
 			if barcode == "" {
 				continue
 			}
-			barcodeID := cuid2.GeneratePrefixedId("bid", cuid2.PrefixedIdOptions{})
-			_, err = tx.Exec(ctx, `
-				INSERT INTO retailer_item_barcodes (id, retailer_item_id, barcode, is_primary, created_at)
-				VALUES ($1, $2, $3, true, NOW())
-				ON CONFLICT DO NOTHING
-			`, barcodeID, itemID, barcode)
+			ids = append(ids, cuid2.GeneratePrefixedId("bid", cuid2.PrefixedIdOptions{}))
+			barcodeItemIDs = append(barcodeItemIDs, itemID)
+			barcodes = append(barcodes, barcode)
 		}
+	}
+	if len(barcodes) == 0 {
+		return nil
+	}
 
-		persisted++
-		if priceChanged {
-			priceChanges++
-		}
+	_, err := tx.Exec(ctx, `
+		INSERT INTO retailer_item_barcodes (id, retailer_item_id, barcode, is_primary, created_at)
+		SELECT id, retailer_item_id, barcode, true, NOW()
+		FROM unnest($1::text[], $2::text[], $3::text[]) AS b(id, retailer_item_id, barcode)
+		ON CONFLICT DO NOTHING
+	`, ids, barcodeItemIDs, barcodes)
+	return err
+}
+
+// recordPriceHistory writes the day's price of the items new to a store or
+// whose price changed, replacing an earlier change of the same day. itemIDs
+// must be sorted, so rows are written in key order.
+func recordPriceHistory(ctx context.Context, tx pgx.Tx, chainID, storeID string, day time.Time, itemIDs []string, itemData map[string]types.NormalizedRow) error {
+	if len(itemIDs) == 0 {
+		return nil
 	}
 
-	// Commit transaction
-	if err := tx.Commit(ctx); err != nil {
-		return 0, 0, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	prices := make([]int, len(itemIDs))
+	discountPrices := make([]*int, len(itemIDs))
+	unitPrices := make([]*int, len(itemIDs))
+	for i, itemID := range itemIDs {
+		row := itemData[itemID]
+		prices[i] = row.Price
+		discountPrices[i] = row.DiscountPrice
+		unitPrices[i] = row.UnitPrice
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO price_history (store_id, retailer_item_id, day, chain_slug, price, discount_price, unit_price, recorded_at)
+		SELECT $1, h.item_id, $2::date, $3, h.price, h.discount_price, h.unit_price, NOW()
+		FROM unnest($4::text[], $5::int[], $6::int[], $7::int[]) AS h(item_id, price, discount_price, unit_price)
+		ON CONFLICT (store_id, retailer_item_id, day) DO UPDATE SET
+			price = EXCLUDED.price,
+			discount_price = EXCLUDED.discount_price,
+			unit_price = EXCLUDED.unit_price,
+			recorded_at = EXCLUDED.recorded_at
+	`, storeID, day, chainID, itemIDs, prices, discountPrices, unitPrices)
+	if err != nil {
+		return fmt.Errorf("failed to record price history: %w", err)
 	}
+	return nil
+}
 
-	log.Info().Str("store_id", storeID).Str("price_group_id", group.ID).Int("item_count", len(itemPrices)).Msg("Assigned store to price group")
+// recordPriceChanges appends price changes to the price change log read by
+// the CDC endpoint
+func recordPriceChanges(ctx context.Context, tx pgx.Tx, changes []events.PriceChangeEvent) error {
+	if len(changes) == 0 {
+		return nil
+	}
 
-	return persisted, priceChanges, itemIDs, nil
+	_, err := tx.CopyFrom(ctx,
+		pgx.Identifier{"price_change_log"},
+		[]string{
+			"chain_slug", "store_id", "retailer_item_id", "run_id", "file_id",
+			"old_price", "new_price", "old_discount_price", "new_discount_price",
+			"discount_start", "discount_end", "changed_at",
+		},
+		pgx.CopyFromSlice(len(changes), func(i int) ([]any, error) {
+			c := changes[i]
+			return []any{
+				c.ChainSlug, c.StoreID, c.RetailerItemID, c.RunID, c.FileID,
+				c.OldPrice, c.NewPrice, c.OldDiscountPrice, c.NewDiscountPrice,
+				c.DiscountStart, c.DiscountEnd, c.OccurredAt,
+			}, nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record price changes: %w", err)
+	}
+	return nil
 }
 
-// findOrCreateRetailerItem finds or creates a retailer item
-func findOrCreateRetailerItem(ctx context.Context, chainID string, row types.NormalizedRow, archiveID string) (string, error) {
-	pool := database.Pool()
+// findOrCreateRetailerItemTx finds or creates a retailer item within a
+// transaction. Items are identified by external ID or, when the chain
+// publishes none, by the identity hash of their name and unit.
+func findOrCreateRetailerItemTx(ctx context.Context, tx pgx.Tx, chainID string, row types.NormalizedRow, archiveID string) (string, error) {
+	key, byIdentity := retailerItemKey(row)
+	if byIdentity {
+		return upsertRetailerItemByIdentity(ctx, tx, chainID, row, key, archiveID)
+	}
 
 	// Try to find by external ID first
-	if row.ExternalID != nil && *row.ExternalID != "" {
-		var itemID string
-		err := pool.QueryRow(ctx, `
-			SELECT id FROM retailer_items
-			WHERE chain_slug = $1 AND external_id = $2
-			LIMIT 1
-		`, chainID, *row.ExternalID).Scan(&itemID)
-		if err == nil {
-			// Update the item (also update archive_id if provided)
-			_, err = pool.Exec(ctx, `
-				UPDATE retailer_items
-				SET name = $1, description = $2, category = $3, subcategory = $4,
-				    brand = $5, unit = $6, unit_quantity = $7, image_url = $8, updated_at = NOW()
-				WHERE id = $9
-			`, row.Name, row.Description, row.Category, row.Subcategory, row.Brand,
-				row.Unit, row.UnitQuantity, row.ImageURL, itemID)
-			return itemID, err
-		}
+	var itemID string
+	err := tx.QueryRow(ctx, `
+		SELECT id FROM retailer_items
+		WHERE chain_slug = $1 AND external_id = $2
+		LIMIT 1
+	`, chainID, key).Scan(&itemID)
+	if err == nil {
+		// Update the item (also update archive_id if provided)
+		_, err = tx.Exec(ctx, `
+			UPDATE retailer_items
+			SET name = $1, description = $2, category = $3, subcategory = $4,
+			    brand = $5, unit = $6, unit_quantity = $7, image_url = $8,
+			    name_i18n = $10::jsonb, updated_at = NOW()
+			WHERE id = $9
+		`, row.Name, row.Description, row.Category, row.Subcategory, row.Brand,
+			row.Unit, row.UnitQuantity, row.ImageURL, itemID, nameI18nJSON(row))
+		return itemID, err
 	}
 
 	// Create new item
-	itemID := cuid2.GeneratePrefixedId("itm", cuid2.PrefixedIdOptions{})
-	_, err := pool.Exec(ctx, `
+	itemID = cuid2.GeneratePrefixedId("itm", cuid2.PrefixedIdOptions{})
+	_, err = tx.Exec(ctx, `
 		INSERT INTO retailer_items (
 			id, chain_slug, external_id, name, description, category, subcategory,
-			brand, unit, unit_quantity, image_url, archive_id, created_at, updated_at
+			brand, unit, unit_quantity, image_url, archive_id, name_i18n, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW(), NOW()
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13::jsonb, NOW(), NOW()
 		)
 		ON CONFLICT (chain_slug, external_id) DO UPDATE SET
 			name = EXCLUDED.name,
+			name_i18n = EXCLUDED.name_i18n,
 			description = EXCLUDED.description,
 			category = EXCLUDED.category,
 			subcategory = EXCLUDED.subcategory,
@@ -433,60 +829,54 @@ func findOrCreateRetailerItem(ctx context.Context, chainID string, row types.Nor
 			archive_id = EXCLUDED.archive_id,
 			updated_at = NOW()
 	`, itemID, chainID, row.ExternalID, row.Name, row.Description, row.Category,
-		row.Subcategory, row.Brand, row.Unit, row.UnitQuantity, row.ImageURL, archiveID)
+		row.Subcategory, row.Brand, row.Unit, row.UnitQuantity, row.ImageURL, archiveID, nameI18nJSON(row))
 
 	return itemID, err
 }
 
-// findOrCreateRetailerItemTx finds or creates a retailer item within a transaction
-func findOrCreateRetailerItemTx(ctx context.Context, tx pgx.Tx, chainID string, row types.NormalizedRow, archiveID string) (string, error) {
-	// Try to find by external ID first
-	if row.ExternalID != nil && *row.ExternalID != "" {
-		var itemID string
-		err := tx.QueryRow(ctx, `
-			SELECT id FROM retailer_items
-			WHERE chain_slug = $1 AND external_id = $2
-			LIMIT 1
-		`, chainID, *row.ExternalID).Scan(&itemID)
-		if err == nil {
-			// Update the item (also update archive_id if provided)
-			_, err = tx.Exec(ctx, `
-				UPDATE retailer_items
-				SET name = $1, description = $2, category = $3, subcategory = $4,
-				    brand = $5, unit = $6, unit_quantity = $7, image_url = $8, updated_at = NOW()
-				WHERE id = $9
-			`, row.Name, row.Description, row.Category, row.Subcategory, row.Brand,
-				row.Unit, row.UnitQuantity, row.ImageURL, itemID)
-			return itemID, err
-		}
-	}
-
-	// Create new item
-	itemID := cuid2.GeneratePrefixedId("itm", cuid2.PrefixedIdOptions{})
-	_, err := tx.Exec(ctx, `
+// upsertRetailerItemByIdentity finds or creates a retailer item without an
+// external ID by its identity hash
+func upsertRetailerItemByIdentity(ctx context.Context, tx pgx.Tx, chainID string, row types.NormalizedRow, identityHash string, archiveID string) (string, error) {
+	var itemID string
+	err := tx.QueryRow(ctx, `
 		INSERT INTO retailer_items (
-			id, chain_slug, external_id, name, description, category, subcategory,
-			brand, unit, unit_quantity, image_url, archive_id, created_at, updated_at
+			id, chain_slug, external_id, identity_hash, name, description, category,
+			subcategory, brand, unit, unit_quantity, image_url, archive_id, name_i18n, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW(), NOW()
+			$1, $2, NULL, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13::jsonb, NOW(), NOW()
 		)
-		ON CONFLICT (chain_slug, external_id) DO UPDATE SET
+		ON CONFLICT (chain_slug, identity_hash) WHERE external_id IS NULL DO UPDATE SET
 			name = EXCLUDED.name,
+			name_i18n = EXCLUDED.name_i18n,
 			description = EXCLUDED.description,
 			category = EXCLUDED.category,
 			subcategory = EXCLUDED.subcategory,
 			brand = EXCLUDED.brand,
-			unit = EXCLUDED.unit,
-			unit_quantity = EXCLUDED.unit_quantity,
 			image_url = EXCLUDED.image_url,
 			archive_id = EXCLUDED.archive_id,
 			updated_at = NOW()
-	`, itemID, chainID, row.ExternalID, row.Name, row.Description, row.Category,
-		row.Subcategory, row.Brand, row.Unit, row.UnitQuantity, row.ImageURL, archiveID)
+		RETURNING id
+	`, cuid2.GeneratePrefixedId("itm", cuid2.PrefixedIdOptions{}), chainID, identityHash, row.Name,
+		row.Description, row.Category, row.Subcategory, row.Brand, row.Unit, row.UnitQuantity,
+		row.ImageURL, archiveID, nameI18nJSON(row)).Scan(&itemID)
 
 	return itemID, err
 }
 
+// nameI18nJSON returns the secondary names of a row as JSON, nil if it has
+// none
+func nameI18nJSON(row types.NormalizedRow) *string {
+	if len(row.NameI18n) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(row.NameI18n)
+	if err != nil {
+		return nil
+	}
+	s := string(data)
+	return &s
+}
+
 // saveFailedRow saves a failed row for later analysis and re-processing
 func saveFailedRow(ctx context.Context, pool *pgxpool.Pool, chainID string, runID string, fileID string, row types.NormalizedRow, validation types.NormalizedRowValidation) error {
 	// Marshal validation errors to JSON
@@ -495,19 +885,29 @@ func saveFailedRow(ctx context.Context, pool *pgxpool.Pool, chainID string, runI
 	// Generate unique ID using cuid2
 	itemID := cuid2.GeneratePrefixedId("failed", cuid2.PrefixedIdOptions{})
 
+	// Store the raw row compressed out of line; it is only read on demand
+	var rawPayloadID *string
+	if row.RawData != "" {
+		id, err := database.CreateRawPayload(ctx, row.RawData)
+		if err != nil {
+			return fmt.Errorf("failed to store raw data of row %d: %w", row.RowNumber, err)
+		}
+		rawPayloadID = &id
+	}
+
 	// Insert into retailer_items_failed table using pool.Exec
 	_, err := pool.Exec(ctx, `
 		INSERT INTO retailer_items_failed (
 			id, chain_slug, run_id, file_id, store_identifier, row_number,
-			raw_data, validation_errors, failed_at, reprocessable
+			raw_payload_id, validation_errors, failed_at, reprocessable
 		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), true)
-	`, itemID, chainID, runID, fileID, row.StoreIdentifier, row.RowNumber, row.RawData, errorsJSON)
+	`, itemID, chainID, runID, fileID, row.StoreIdentifier, row.RowNumber, rawPayloadID, errorsJSON)
 
 	if err != nil {
 		return fmt.Errorf("failed to save failed row %d: %w", row.RowNumber, err)
 	}
 
-	log.Info().Int("row_number", row.RowNumber).Msg("Saved failed row to retailer_items_failed")
+	logFrom(ctx).Info().Int("row_number", row.RowNumber).Msg("Saved failed row to retailer_items_failed")
 	return nil
 }
 
@@ -538,6 +938,27 @@ func validateNormalizedRow(row types.NormalizedRow) types.NormalizedRowValidatio
 	}
 }
 
+// equalIntPtr reports whether two optional prices are equal
+func equalIntPtr(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// isSignificantPriceChange reports whether a price moved by at least
+// significantPriceChangePercent relative to its previous value
+func isSignificantPriceChange(previous, current int) bool {
+	if previous <= 0 {
+		return current > 0
+	}
+	diff := current - previous
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff*100 >= previous*significantPriceChangePercent
+}
+
 // computePriceSignature computes a signature for price deduplication
 func computePriceSignature(row types.NormalizedRow) string {
 	sig := fmt.Sprintf("%d:%v:%v:%v:%v:%v:%v:%v:%v:%v",
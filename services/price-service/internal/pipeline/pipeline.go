@@ -3,14 +3,20 @@ package pipeline
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/kosarica/price-service/internal/adapters/config"
 	"github.com/kosarica/price-service/internal/adapters/registry"
+	"github.com/kosarica/price-service/internal/chains"
+	"github.com/kosarica/price-service/internal/chainsettings"
 	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/featureflags"
+	httpclient "github.com/kosarica/price-service/internal/http"
+	"github.com/kosarica/price-service/internal/lanes"
 	"github.com/kosarica/price-service/internal/pkg/cuid2"
 	"github.com/kosarica/price-service/internal/storage"
-	"github.com/rs/zerolog/log"
+	"github.com/kosarica/price-service/internal/types"
 )
 
 // IngestionResult represents the result of an ingestion run
@@ -19,142 +25,442 @@ type IngestionResult struct {
 	RunID            string
 	FilesProcessed   int
 	EntriesPersisted int
+	PriceChanges     int
 	Errors           []string
+	// StagingStatus is the run's staging status when it was staged rather
+	// than written straight to the live tables
+	StagingStatus string
+	// BaselineStatus is the outcome of comparing the run with the same
+	// weekday a week earlier, empty when there was nothing to compare with
+	BaselineStatus string
+}
+
+// fileSource supplies the files of a run: discovered and downloaded from the
+// chain's portal, or replayed from the archive
+type fileSource interface {
+	// name is recorded as the run's source
+	name() string
+	// discover lists the run's files and records how many there are
+	discover(ctx context.Context, chainID string, runID string) ([]types.DiscoveredFile, error)
+	// fetch returns a file's content and its archive
+	fetch(ctx context.Context, chainID string, file types.DiscoveredFile) (*FetchResult, error)
+}
+
+// portalSource discovers and downloads files from the chain's portal,
+// archiving what it downloads
+type portalSource struct {
+	targetDate string
+	storage    storage.Storage
+
+	// source is recorded as the run's source, "worker" when empty
+	source string
+}
+
+func (s *portalSource) name() string {
+	if s.source != "" {
+		return s.source
+	}
+	return "worker"
+}
+
+func (s *portalSource) discover(ctx context.Context, chainID string, runID string) ([]types.DiscoveredFile, error) {
+	return DiscoverPhase(ctx, chainID, runID, s.targetDate)
+}
+
+func (s *portalSource) fetch(ctx context.Context, chainID string, file types.DiscoveredFile) (*FetchResult, error) {
+	return FetchPhase(ctx, chainID, file, s.storage)
 }
 
 // Run executes the full ingestion pipeline for a chain
 // Returns the ingestion result with success status, run ID, and statistics
 func Run(ctx context.Context, chainID string, targetDate string) (*IngestionResult, error) {
+	if !chainsettings.DiscoveryEnabled(chainID) {
+		return nil, fmt.Errorf("discovery is disabled for chain %s", chainID)
+	}
+
+	// Initialize the configured storage backend
+	storageBackend, err := storage.Default()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	return run(ctx, chainID, &portalSource{targetDate: targetDate, storage: storageBackend})
+}
+
+// RunScheduled executes the full ingestion pipeline for a chain as a run
+// started by its ingestion schedule, recorded with source "schedule"
+func RunScheduled(ctx context.Context, chainID string) (*IngestionResult, error) {
+	if !chainsettings.DiscoveryEnabled(chainID) {
+		return nil, fmt.Errorf("discovery is disabled for chain %s", chainID)
+	}
+
+	storageBackend, err := storage.Default()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	return run(ctx, chainID, &portalSource{storage: storageBackend, source: "schedule"})
+}
+
+// runFile is a file of a run to process
+type runFile struct {
+	ID   string
+	File types.DiscoveredFile
+	// DoneChunks is the number of chunks persisted before the run was
+	// interrupted
+	DoneChunks int
+}
+
+// fetchFunc returns a file's content and its archive
+type fetchFunc func(ctx context.Context, chainID string, file types.DiscoveredFile) (*FetchResult, error)
+
+// prepareChain checks that a chain may be ingested and readies its adapter
+func prepareChain(chainID string) error {
 	// Validate chain ID
 	if !config.IsValidChainID(chainID) {
-		return nil, fmt.Errorf("invalid chain ID: %s", chainID)
+		return fmt.Errorf("invalid chain ID: %s", chainID)
+	}
+	if !chains.IsValidChain(chainID) {
+		return fmt.Errorf("chain %s is not enabled in the chain registry", chainID)
 	}
 
 	// Initialize chain registry
 	if err := registry.InitializeDefaultAdapters(); err != nil {
-		return nil, fmt.Errorf("failed to initialize chain registry: %w", err)
+		return fmt.Errorf("failed to initialize chain registry: %w", err)
 	}
 
-	// Initialize storage backend
-	storageBackend, err := storage.NewLocalStorage("./data/archives")
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+	// Apply the chain's current rate limit settings to its adapter
+	if adapter, err := registry.GetAdapter(config.ChainID(chainID)); err == nil {
+		if configurable, ok := adapter.(interface{ ApplySettings() }); ok {
+			configurable.ApplySettings()
+		}
+	}
+	return nil
+}
+
+// run ingests the files of source into a new run
+func run(ctx context.Context, chainID string, source fileSource) (*IngestionResult, error) {
+	if err := prepareChain(chainID); err != nil {
+		return nil, err
 	}
 
+	// Ingestion is background work; keep it out of the interactive lane
+	ctx = lanes.WithLane(ctx, lanes.Batch)
+
 	// Create ingestion run
-	runID := createIngestionRun(ctx, chainID)
+	runID := createIngestionRun(ctx, chainID, source.name())
 	if runID == "" {
 		return nil, fmt.Errorf("failed to create ingestion run")
 	}
 
-	log.Info().Str("runId", runID).Str("chain", chainID).Msg("Starting ingestion run")
+	ctx = withRunLogger(ctx, runID, chainID)
+	logFrom(ctx).Info().Msg("Starting ingestion run")
+
+	// Record portal traffic and wall-clock time for cost accounting
+	usage := startRunUsage(chainID)
+	defer usage.record(ctx, runID)
 
 	result := &IngestionResult{
 		RunID:  runID,
 		Errors: make([]string, 0),
 	}
+	defer recordRunMetrics(chainID, result, time.Now())
+
+	// Staged runs are written to staging tables and published once they pass
+	// the quality checks
+	staged := featureflags.IsEnabled(ctx, featureflags.StagedIngestion, chainID)
+	if staged {
+		if err := markRunStaging(ctx, runID); err != nil {
+			logFrom(ctx).Warn().Err(err).Msg("Failed to mark run as staging, persisting directly")
+			staged = false
+		} else {
+			result.StagingStatus = StagingInProgress
+		}
+	}
 
 	// Phase 1: Discover
-	log.Info().Msg("Phase 1: Discovery")
-	discoveredFiles, err := DiscoverPhase(ctx, chainID, runID, targetDate)
+	logFrom(ctx).Info().Msg("Phase 1: Discovery")
+	discoveredFiles, err := source.discover(ctx, chainID, runID)
 	if err != nil {
 		result.Errors = append(result.Errors, fmt.Sprintf("Discovery failed: %v", err))
-		markRunFailed(ctx, runID, err.Error())
+		reportError(ctx, runID, "", "discovery_failed", err, "Discovery failed", nil)
+		if err := markRunFailed(ctx, runID, err.Error()); err != nil {
+			logFrom(ctx).Warn().Err(err).Msg("Failed to mark run as failed")
+		}
 		result.Success = false
 		return result, nil
 	}
 
 	if len(discoveredFiles) == 0 {
-		log.Info().Msg("No files discovered, ingestion complete")
+		logFrom(ctx).Info().Msg("No files discovered, ingestion complete")
 		result.Success = true
 		return result, nil
 	}
 
-	log.Info().Int("count", len(discoveredFiles)).Msg("Discovered files")
+	logFrom(ctx).Info().Int("count", len(discoveredFiles)).Msg("Discovered files")
 
-	var firstArchiveID string
-
-	// Process each file through fetch, parse, persist phases
-	for _, file := range discoveredFiles {
-		log.Info().Str("filename", file.Filename).Msg("Processing file")
-
-		// Phase 2: Fetch (with storage backend)
-		fetchResult, err := FetchPhase(ctx, chainID, file, storageBackend)
-		if err != nil {
-			errMsg := fmt.Sprintf("Fetch failed for %s: %v", file.Filename, err)
-			result.Errors = append(result.Errors, errMsg)
-			log.Error().Str("error", errMsg).Msg("Fetch failed")
-			continue
+	fileIDs, err := createPendingFiles(ctx, runID, discoveredFiles)
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+		reportError(ctx, runID, "", "discovery_failed", err, "Failed to record discovered files", nil)
+		if err := markRunFailed(ctx, runID, err.Error()); err != nil {
+			logFrom(ctx).Warn().Err(err).Msg("Failed to mark run as failed")
 		}
+		result.Success = false
+		return result, nil
+	}
 
-		if fetchResult == nil {
-			// Duplicate file, skip
-			continue
-		}
+	files := make([]runFile, len(discoveredFiles))
+	for i, file := range discoveredFiles {
+		files[i] = runFile{ID: fileIDs[i], File: file}
+	}
+	processFiles(ctx, chainID, runID, source.fetch, files, staged, result)
+	finishRun(ctx, chainID, runID, staged, result)
+	return result, nil
+}
 
-		// Store first archive ID for linking to run
-		if firstArchiveID == "" && fetchResult.ArchiveID != "" {
-			firstArchiveID = fetchResult.ArchiveID
-		}
+// processFiles takes the pending files of a run through the fetch, parse and
+// persist phases on a pool of workers, adding what they processed to result.
+// A file that fails, even by panicking, fails alone. Files persist holding a
+// batch lane slot, which bounds the connections the workers use at once.
+func processFiles(ctx context.Context, chainID string, runID string, fetch fetchFunc, files []runFile, staged bool, result *IngestionResult) {
+	ctx = withStoreLocks(ctx)
+	archiveIDs := make([]string, len(files))
 
-		// Phase 3: Parse
-		parseResult, err := ParsePhase(ctx, chainID, fetchResult, file, runID)
-		if err != nil {
-			errMsg := fmt.Sprintf("Parse failed for %s: %v", file.Filename, err)
-			result.Errors = append(result.Errors, errMsg)
-			log.Error().Str("error", errMsg).Msg("Parse failed")
-			continue
-		}
+	var mu sync.Mutex
+	next := NewWorkerPool(fileWorkers()).Each(len(files), func(i int) bool {
+		processed := processFile(ctx, chainID, runID, fetch, files[i], staged)
 
-		if parseResult.ValidRows == 0 {
-			log.Info().Str("filename", file.Filename).Msg("No valid rows, skipping persist")
+		mu.Lock()
+		defer mu.Unlock()
+		archiveIDs[i] = processed.ArchiveID
+		if processed.Err != "" {
+			result.Errors = append(result.Errors, processed.Err)
+		}
+		if processed.Done {
 			result.FilesProcessed++
-			// Update run progress for empty files
-			if err := incrementProcessedFiles(ctx, runID); err != nil {
-				log.Warn().Err(err).Msg("Failed to increment processed files")
-			}
-			continue
+			result.EntriesPersisted += processed.Persisted
+			result.PriceChanges += processed.PriceChanges
 		}
+		return !processed.Cancelled
+	})
+	skipPendingFiles(ctx, files[next:])
 
-		// Phase 4: Persist (with archive ID)
-		persistResult, err := PersistPhase(ctx, chainID, parseResult, file, runID, fetchResult.ArchiveID)
-		if err != nil {
-			errMsg := fmt.Sprintf("Persist failed for %s: %v", file.Filename, err)
-			result.Errors = append(result.Errors, errMsg)
-			log.Error().Str("error", errMsg).Msg("Persist failed")
-			continue
+	// Link the archive of the first file to the run
+	var firstArchiveID string
+	for _, archiveID := range archiveIDs {
+		if archiveID != "" {
+			firstArchiveID = archiveID
+			break
 		}
-
-		result.FilesProcessed++
-		result.EntriesPersisted += persistResult.Persisted
 	}
-
-	// Link first archive to ingestion run
 	if firstArchiveID != "" {
 		if err := database.LinkArchiveToIngestionRun(ctx, firstArchiveID, runID); err != nil {
-			log.Warn().Err(err).Msg("Failed to link archive to run")
+			logFrom(ctx).Warn().Err(err).Msg("Failed to link archive to run")
 		} else {
-			log.Info().Str("archiveId", firstArchiveID).Str("runId", runID).Msg("Linked archive to run")
+			logFrom(ctx).Info().Str("archive_id", firstArchiveID).Msg("Linked archive to run")
+		}
+	}
+}
+
+// processedFile is what processing one file of a run did
+type processedFile struct {
+	// Done is set when the file was parsed and persisted
+	Done         bool
+	Persisted    int
+	PriceChanges int
+	ArchiveID    string
+	// Err describes why the file did not complete
+	Err string
+	// Cancelled is set when the run is cancelled, so no further file is started
+	Cancelled bool
+}
+
+// processFile takes one file of a run through the fetch, parse and persist
+// phases
+func processFile(runCtx context.Context, chainID string, runID string, fetch fetchFunc, f runFile, staged bool) (processed processedFile) {
+	file := f.File
+	ctx := withFileLogger(runCtx, file.Filename)
+	fileID := f.ID
+	logFrom(ctx).Info().Msg("Processing file")
+
+	defer func() {
+		if r := recover(); r != nil {
+			err := fmt.Errorf("panic: %v", r)
+			processed = processedFile{ArchiveID: processed.ArchiveID, Err: fmt.Sprintf("Processing failed for %s: %v", file.Filename, err)}
+			reportError(ctx, runID, fileID, "processing_failed", err, "Processing panicked", map[string]any{"file": file.Filename})
+			transitionFile(ctx, fileID, fileFailed, discrepancyFailed)
+		}
+	}()
+
+	// Phase 2: Fetch (with storage backend)
+	transitionFile(ctx, fileID, fileDownloading, "")
+	fetchResult, err := fetch(ctx, chainID, file)
+	if err != nil {
+		reportError(ctx, runID, fileID, "fetch_failed", err, "Fetch failed", map[string]any{"file": file.Filename})
+		transitionFile(ctx, fileID, fileFailed, discrepancyFailed)
+		return processedFile{Err: fmt.Sprintf("Fetch failed for %s: %v", file.Filename, err)}
+	}
+
+	if fetchResult == nil {
+		// Duplicate file, skip
+		transitionFile(ctx, fileID, fileSkipped, discrepancyDeduplicated)
+		return processedFile{}
+	}
+	processed.ArchiveID = fetchResult.ArchiveID
+
+	// Phase 3: Parse
+	transitionFile(ctx, fileID, fileParsing, "")
+	parseResult, err := ParsePhase(ctx, chainID, fetchResult, file, runID, fileID)
+	if err != nil {
+		reportError(ctx, runID, fileID, "parse_failed", err, "Parse failed", map[string]any{"file": file.Filename})
+		transitionFile(ctx, fileID, fileFailed, discrepancyFailed)
+		processed.Err = fmt.Sprintf("Parse failed for %s: %v", file.Filename, err)
+		return processed
+	}
+	parseResult.DoneChunks = f.DoneChunks
+
+	if parseResult.ValidRows == 0 {
+		logFrom(ctx).Info().Msg("No valid rows, skipping persist")
+		processed.Done = true
+		// Update run progress for empty files
+		if err := incrementRunProgress(ctx, runID, 1, 0, 0); err != nil {
+			logFrom(ctx).Warn().Err(err).Msg("Failed to update run progress")
 		}
+		return processed
 	}
 
+	// Phase 4: Persist or stage (with archive ID), holding a batch lane slot
+	release, err := lanes.Acquire(ctx)
+	if err != nil {
+		transitionFile(ctx, fileID, fileSkipped, discrepancySkipped)
+		processed.Err = fmt.Sprintf("Persist cancelled for %s: %v", file.Filename, err)
+		processed.Cancelled = true
+		return processed
+	}
+	defer release()
+	transitionFile(ctx, fileID, filePersisting, "")
+	var persistResult *PersistResult
+	if staged {
+		persistResult, err = StagePhase(ctx, parseResult, file, runID, fetchResult.ArchiveID)
+	} else {
+		persistResult, err = PersistPhase(ctx, chainID, parseResult, file, runID, fetchResult.ArchiveID)
+	}
+	if err != nil {
+		reportError(ctx, runID, parseResult.FileID, "persist_failed", err, "Persist failed", nil)
+		transitionFile(ctx, fileID, fileFailed, discrepancyFailed)
+		processed.Err = fmt.Sprintf("Persist failed for %s: %v", file.Filename, err)
+		return processed
+	}
+
+	processed.Done = true
+	processed.Persisted = persistResult.Persisted
+	processed.PriceChanges = persistResult.PriceChanges
+	return processed
+}
+
+// finishRun completes a run whose files are processed: it is marked
+// completed, published when staged and checked against its baseline
+func finishRun(ctx context.Context, chainID string, runID string, staged bool, result *IngestionResult) {
 	// Mark run as completed
-	log.Info().Str("runId", runID).Int("files", result.FilesProcessed).Int("entries", result.EntriesPersisted).Msg("Ingestion run complete")
+	logFrom(ctx).Info().Int("files", result.FilesProcessed).Int("entries", result.EntriesPersisted).Msg("Ingestion run complete")
 	if len(result.Errors) > 0 {
-		log.Warn().Int("errors", len(result.Errors)).Msg("Run completed with errors")
+		logFrom(ctx).Warn().Int("errors", len(result.Errors)).Msg("Run completed with errors")
 	}
 
 	// Update run status to completed
-	if err := markRunCompleted(ctx, runID, result.FilesProcessed, result.EntriesPersisted); err != nil {
-		log.Warn().Err(err).Msg("Failed to mark run as completed")
+	if err := markRunCompleted(ctx, runID); err != nil {
+		logFrom(ctx).Warn().Err(err).Msg("Failed to mark run as completed")
+	}
+
+	// Check staged rows and publish them if they pass
+	if staged {
+		status, published, err := finishStagedRun(ctx, chainID, runID)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("Publish failed: %v", err))
+			reportError(ctx, runID, "", "publish_failed", err, "Publishing staged run failed", nil)
+		}
+		result.StagingStatus = status
+		if published != nil {
+			result.PriceChanges = published.PriceChanges
+		}
+	}
+
+	// Compare live runs with the same weekday last week; a run held for
+	// review is checked by its quality report instead
+	if result.FilesProcessed > 0 && result.StagingStatus != StagingStaged && result.StagingStatus != StagingInProgress {
+		status, err := checkRunBaseline(ctx, chainID, runID)
+		if err != nil {
+			logFrom(ctx).Warn().Err(err).Msg("Failed to check run against baseline")
+		}
+		result.BaselineStatus = status
 	}
 
 	result.Success = len(result.Errors) == 0
-	return result, nil
+}
+
+// runUsage tracks the portal traffic and wall-clock time spent by a run.
+// Traffic is measured as the delta of the chain adapter's HTTP client counters,
+// so concurrent runs for the same chain share their counts.
+type runUsage struct {
+	client    *httpclient.Client
+	start     httpclient.TrafficStats
+	startedAt time.Time
+}
+
+// startRunUsage snapshots the chain adapter's traffic counters
+func startRunUsage(chainID string) *runUsage {
+	usage := &runUsage{startedAt: time.Now()}
+
+	adapter, err := registry.GetAdapter(config.ChainID(chainID))
+	if err != nil {
+		return usage
+	}
+	if withClient, ok := adapter.(interface{ HTTPClient() *httpclient.Client }); ok {
+		usage.client = withClient.HTTPClient()
+		usage.start = usage.client.TrafficStats()
+	}
+
+	return usage
+}
+
+// record adds the run's usage to the "usage" key of the run metadata, so a
+// resumed run counts the usage from before its restart too
+func (u *runUsage) record(ctx context.Context, runID string) {
+	var traffic httpclient.TrafficStats
+	if u.client != nil {
+		traffic = u.client.TrafficStats().Sub(u.start)
+	}
+	wallClock := time.Since(u.startedAt)
+
+	pool := database.Pool()
+	_, err := pool.Exec(ctx, `
+		UPDATE ingestion_runs
+		SET metadata = jsonb_set(
+		        COALESCE(metadata, '{}'::jsonb),
+		        '{usage}',
+		        jsonb_build_object(
+		            'requests', COALESCE((metadata -> 'usage' ->> 'requests')::bigint, 0) + $1::bigint,
+		            'bytesDownloaded', COALESCE((metadata -> 'usage' ->> 'bytesDownloaded')::bigint, 0) + $2::bigint,
+		            'wallClockSeconds', COALESCE((metadata -> 'usage' ->> 'wallClockSeconds')::double precision, 0) + $3::double precision
+		        )
+		    )
+		WHERE id = $4
+	`, traffic.Requests, traffic.BytesDownloaded, wallClock.Seconds(), runID)
+	if err != nil {
+		logFrom(ctx).Warn().Err(err).Msg("Failed to record run usage")
+		return
+	}
+
+	logFrom(ctx).Info().
+		Int64("requests", traffic.Requests).
+		Int64("bytes", traffic.BytesDownloaded).
+		Dur("wallClock", wallClock).
+		Msg("Recorded run usage")
 }
 
 // createIngestionRun creates an ingestion run record in the database
-func createIngestionRun(ctx context.Context, chainID string) string {
+func createIngestionRun(ctx context.Context, chainID string, source string) string {
 	pool := database.Pool()
 
 	runID := cuid2.GeneratePrefixedId("run", cuid2.PrefixedIdOptions{})
@@ -164,12 +470,12 @@ func createIngestionRun(ctx context.Context, chainID string) string {
 		INSERT INTO ingestion_runs (
 			id, chain_slug, source, status, started_at, created_at
 		) VALUES (
-			$1, $2, 'worker', 'running', $3, $4
+			$1, $2, $3, 'running', $4, $5
 		)
-	`, runID, chainID, now, now)
+	`, runID, chainID, source, now, now)
 
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to create ingestion run")
+		logger.Error().Err(err).Str("chain", chainID).Msg("Failed to create ingestion run")
 		return ""
 	}
 
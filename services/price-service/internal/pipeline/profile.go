@@ -0,0 +1,134 @@
+package pipeline
+
+import (
+	"context"
+	"runtime/metrics"
+	"syscall"
+	"time"
+
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/featureflags"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	parseCPUSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "pipeline_parse_cpu_seconds",
+		Help:    "CPU time spent parsing a file by chain (profiling mode only)",
+		Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 2, 5, 10, 30},
+	}, []string{"chain"})
+
+	parseAllocations = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "pipeline_parse_allocations",
+		Help:    "Heap allocations made while parsing a file by chain (profiling mode only)",
+		Buckets: prometheus.ExponentialBuckets(1e4, 4, 10),
+	}, []string{"chain"})
+
+	parseRowsPerSecond = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pipeline_parse_rows_per_second",
+		Help: "Rows per second of the last profiled parse by chain",
+	}, []string{"chain"})
+)
+
+// allocMetrics are the runtime metrics sampled around a profiled parse
+var allocMetrics = []string{"/gc/heap/allocs:objects", "/gc/heap/allocs:bytes"}
+
+// ParseProfile is the resource usage of parsing one file. CPU time and
+// allocations are process-wide deltas, so parses running concurrently with
+// other work are overstated; compare profiles over many files, not one.
+type ParseProfile struct {
+	CPUSeconds    float64 `json:"cpuSeconds"`
+	WallSeconds   float64 `json:"wallSeconds"`
+	Allocs        uint64  `json:"allocs"`
+	AllocBytes    uint64  `json:"allocBytes"`
+	Rows          int     `json:"rows"`
+	RowsPerSecond float64 `json:"rowsPerSecond"`
+}
+
+// parseProfiler samples CPU time and allocations at the start of a parse
+type parseProfiler struct {
+	startedAt time.Time
+	cpu       time.Duration
+	samples   []metrics.Sample
+}
+
+// startParseProfile starts profiling a parse when the parse_profiling flag
+// is on for the chain; it returns nil otherwise
+func startParseProfile(ctx context.Context, chainID string) *parseProfiler {
+	if !featureflags.IsEnabled(ctx, featureflags.ParseProfiling, chainID) {
+		return nil
+	}
+	p := &parseProfiler{samples: newAllocSamples()}
+	metrics.Read(p.samples)
+	p.cpu = processCPUTime()
+	p.startedAt = time.Now()
+	return p
+}
+
+// stop returns the profile of the parse that produced rows
+func (p *parseProfiler) stop(rows int) *ParseProfile {
+	wall := time.Since(p.startedAt)
+	cpu := processCPUTime() - p.cpu
+	end := newAllocSamples()
+	metrics.Read(end)
+
+	profile := &ParseProfile{
+		CPUSeconds:  cpu.Seconds(),
+		WallSeconds: wall.Seconds(),
+		Allocs:      end[0].Value.Uint64() - p.samples[0].Value.Uint64(),
+		AllocBytes:  end[1].Value.Uint64() - p.samples[1].Value.Uint64(),
+		Rows:        rows,
+	}
+	if wall > 0 {
+		profile.RowsPerSecond = float64(rows) / wall.Seconds()
+	}
+	return profile
+}
+
+func newAllocSamples() []metrics.Sample {
+	samples := make([]metrics.Sample, len(allocMetrics))
+	for i, name := range allocMetrics {
+		samples[i].Name = name
+	}
+	return samples
+}
+
+// processCPUTime returns the user plus system CPU time used by the process
+func processCPUTime() time.Duration {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return 0
+	}
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano())
+}
+
+// recordParseProfile exports a parse profile as metrics and adds it to the
+// "parseProfile" totals of the run metadata. The per-file profile is stored
+// in the file metadata by recordParsedFile.
+func recordParseProfile(ctx context.Context, chainID, runID string, profile *ParseProfile) {
+	parseCPUSeconds.WithLabelValues(chainID).Observe(profile.CPUSeconds)
+	parseAllocations.WithLabelValues(chainID).Observe(float64(profile.Allocs))
+	parseRowsPerSecond.WithLabelValues(chainID).Set(profile.RowsPerSecond)
+
+	pool := database.Pool()
+	_, err := pool.Exec(ctx, `
+		UPDATE ingestion_runs
+		SET metadata = jsonb_set(
+		        COALESCE(metadata, '{}'::jsonb),
+		        '{parseProfile}',
+		        jsonb_build_object(
+		            'files', COALESCE((metadata -> 'parseProfile' ->> 'files')::int, 0) + 1,
+		            'rows', COALESCE((metadata -> 'parseProfile' ->> 'rows')::bigint, 0) + $1::bigint,
+		            'cpuSeconds', COALESCE((metadata -> 'parseProfile' ->> 'cpuSeconds')::double precision, 0) + $2::double precision,
+		            'wallSeconds', COALESCE((metadata -> 'parseProfile' ->> 'wallSeconds')::double precision, 0) + $3::double precision,
+		            'allocs', COALESCE((metadata -> 'parseProfile' ->> 'allocs')::bigint, 0) + $4::bigint,
+		            'allocBytes', COALESCE((metadata -> 'parseProfile' ->> 'allocBytes')::bigint, 0) + $5::bigint
+		        )
+		    )
+		WHERE id = $6
+	`, profile.Rows, profile.CPUSeconds, profile.WallSeconds, int64(profile.Allocs), int64(profile.AllocBytes), runID)
+	if err != nil {
+		logFrom(ctx).Warn().Err(err).Msg("Failed to record parse profile")
+	}
+}
@@ -0,0 +1,92 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Reasons a discovered file did not add to a run's processed files, recorded
+// in ingestion_files.discrepancy_reason
+const (
+	// discrepancySkipped files were not processed, e.g. because the run was
+	// cancelled before reaching them
+	discrepancySkipped = "skipped"
+	// discrepancyDeduplicated files had the content of an archive already
+	// ingested
+	discrepancyDeduplicated = "deduplicated"
+	// discrepancyFailed files failed to fetch, parse or persist
+	discrepancyFailed = "failed"
+)
+
+// runReconciliation is the outcome of a run's files, recomputed from
+// ingestion_files when the run completes
+type runReconciliation struct {
+	Files             int     `json:"files"`
+	Completed         int     `json:"completed"`
+	Deduplicated      int     `json:"deduplicated"`
+	Skipped           int     `json:"skipped"`
+	Failed            int     `json:"failed"`
+	Entries           int     `json:"entries"`
+	CompletionPercent float64 `json:"completion_percent"`
+}
+
+// completionPercent is the share of files whose content made it into the
+// database: completed files and files deduplicated against an earlier archive.
+// A run without files is complete.
+func (r runReconciliation) completionPercent() float64 {
+	if r.Files == 0 {
+		return 100
+	}
+	return float64(r.Completed+r.Deduplicated) * 100 / float64(r.Files)
+}
+
+// reconcileRunTotals recomputes a run's totals from its ingestion files:
+// total_files becomes the number of files recorded and total_entries the
+// valid rows of its completed files. The breakdown by outcome is stored in
+// metadata.reconciliation and the completion percentage in
+// completion_percent.
+func reconcileRunTotals(ctx context.Context, tx pgx.Tx, runID string) (*runReconciliation, error) {
+	var r runReconciliation
+	err := tx.QueryRow(ctx, `
+		SELECT COUNT(*),
+		       COUNT(*) FILTER (WHERE status = 'completed'),
+		       COUNT(*) FILTER (WHERE discrepancy_reason = $2),
+		       COUNT(*) FILTER (WHERE discrepancy_reason = $3),
+		       COUNT(*) FILTER (WHERE discrepancy_reason = $4),
+		       COALESCE(SUM(entry_count) FILTER (WHERE status = 'completed'), 0)
+		FROM ingestion_files
+		WHERE run_id = $1
+	`, runID, discrepancyDeduplicated, discrepancySkipped, discrepancyFailed).Scan(
+		&r.Files, &r.Completed, &r.Deduplicated, &r.Skipped, &r.Failed, &r.Entries,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count run files: %w", err)
+	}
+	r.CompletionPercent = r.completionPercent()
+
+	reconciliationJSON, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE ingestion_runs
+		SET total_files = $1,
+		    total_entries = $2,
+		    completion_percent = $3,
+		    metadata = jsonb_set(
+		        COALESCE(metadata, '{}'::jsonb),
+		        '{reconciliation}',
+		        $4::jsonb
+		    )
+		WHERE id = $5
+	`, r.Files, r.Entries, r.CompletionPercent, string(reconciliationJSON), runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update run totals: %w", err)
+	}
+
+	return &r, nil
+}
@@ -0,0 +1,26 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompletionPercent(t *testing.T) {
+	tests := []struct {
+		name string
+		r    runReconciliation
+		want float64
+	}{
+		{"no files", runReconciliation{}, 100},
+		{"all completed", runReconciliation{Files: 4, Completed: 4}, 100},
+		{"deduplicated count as done", runReconciliation{Files: 4, Completed: 2, Deduplicated: 2}, 100},
+		{"skipped and failed do not", runReconciliation{Files: 4, Completed: 1, Skipped: 1, Failed: 2}, 25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, tt.r.completionPercent(), 1e-9)
+		})
+	}
+}
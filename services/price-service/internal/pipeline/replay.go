@@ -0,0 +1,105 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/pkg/clock"
+	"github.com/kosarica/price-service/internal/storage"
+	"github.com/kosarica/price-service/internal/types"
+)
+
+// archiveSource replays archived files instead of discovering and
+// downloading them, so it needs no network access
+type archiveSource struct {
+	storage storage.Storage
+	// list returns the archives to replay, in the order they are processed
+	list     func(ctx context.Context, chainID string) ([]database.Archive, error)
+	archives map[string]database.Archive
+}
+
+func (s *archiveSource) name() string { return "archive" }
+
+// discover lists the archived files to replay, at most one per filename.
+// Files are described as their adapter discovered them.
+func (s *archiveSource) discover(ctx context.Context, chainID string, runID string) ([]types.DiscoveredFile, error) {
+	archives, err := s.list(ctx, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archives: %w", err)
+	}
+
+	logFrom(ctx).Info().
+		Int("archives", len(archives)).
+		Msg("Replaying archived files")
+
+	s.archives = make(map[string]database.Archive, len(archives))
+	files := make([]types.DiscoveredFile, 0, len(archives))
+	for _, archive := range archives {
+		file := types.DiscoveredFile{
+			URL:      archive.SourceURL,
+			Filename: archive.Filename,
+			Type:     types.FileType(archive.OriginalFormat),
+		}
+		if archive.Metadata != nil {
+			var metadata archiveMetadata
+			if err := json.Unmarshal([]byte(*archive.Metadata), &metadata); err == nil {
+				file.Metadata = metadata.Discovered
+			}
+		}
+		s.archives[archive.Filename] = archive
+		files = append(files, file)
+	}
+
+	if err := recordDiscoveredFiles(ctx, runID, len(files)); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// fetch reads an archived file from storage, verifying its checksum
+func (s *archiveSource) fetch(ctx context.Context, chainID string, file types.DiscoveredFile) (*FetchResult, error) {
+	archive, ok := s.archives[file.Filename]
+	if !ok {
+		return nil, fmt.Errorf("no archive for %s", file.Filename)
+	}
+
+	content, err := s.storage.Get(ctx, archive.ArchivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive %s: %w", archive.ID, err)
+	}
+	hash := computeSha256(content)
+	if hash != archive.Checksum {
+		return nil, fmt.Errorf("checksum mismatch for archive %s: expected %s, got %s", archive.ID, archive.Checksum, hash)
+	}
+
+	logFrom(ctx).Info().Str("archive_id", archive.ID).Int("bytes", len(content)).Msg("Read archived file")
+
+	return &FetchResult{
+		StorageKey: archive.ArchivePath,
+		Hash:       hash,
+		Content:    content,
+		IsZip:      file.Type == types.FileTypeZIP,
+		ArchiveID:  archive.ID,
+	}, nil
+}
+
+// RunFromArchive reconstructs a run for a chain from the raw files archived
+// on date (YYYY-MM-DD, in the service's time zone), skipping discovery and download. Useful to
+// reprocess files after a parser fix, and in environments without access to
+// the chains' portals.
+func RunFromArchive(ctx context.Context, chainID string, date string, storageBackend storage.Storage) (*IngestionResult, error) {
+	day, err := clock.ParseDate(date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD: %w", date, err)
+	}
+
+	logFrom(ctx).Info().Str("chain", chainID).Str("date", date).Msg("Replaying archive")
+	return run(ctx, chainID, &archiveSource{
+		storage: storageBackend,
+		list: func(ctx context.Context, chainID string) ([]database.Archive, error) {
+			return database.GetArchivesDownloadedBetween(ctx, chainID, day, clock.AddDays(day, 1))
+		},
+	})
+}
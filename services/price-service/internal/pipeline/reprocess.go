@@ -0,0 +1,167 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/storage"
+)
+
+// parserVersion returns the version of an adapter's parsing logic, empty if
+// the adapter does not report one
+func parserVersion(adapter any) string {
+	if versioned, ok := adapter.(interface{ ParserVersion() string }); ok {
+		return versioned.ParserVersion()
+	}
+	return ""
+}
+
+// CompareParserVersions orders dotted parser versions part by part, numeric
+// parts numerically, so 1.10.0 is newer than 1.9.2. Missing parts count as 0
+// and an empty version, recorded before versions were, is older than any.
+func CompareParserVersions(a, b string) int {
+	if a == "" || b == "" {
+		return strings.Compare(a, b)
+	}
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := range max(len(as), len(bs)) {
+		pa, pb := "0", "0"
+		if i < len(as) {
+			pa = as[i]
+		}
+		if i < len(bs) {
+			pb = bs[i]
+		}
+		na, errA := strconv.Atoi(pa)
+		nb, errB := strconv.Atoi(pb)
+		var c int
+		if errA == nil && errB == nil {
+			c = na - nb
+		} else {
+			c = strings.Compare(pa, pb)
+		}
+		if c != 0 {
+			return max(-1, min(1, c))
+		}
+	}
+	return 0
+}
+
+// ParseParserVersionFilter parses a parser version filter of the form "<v",
+// matching files parsed by versions older than v, and returns v
+func ParseParserVersionFilter(filter string) (string, error) {
+	version, ok := strings.CutPrefix(strings.TrimSpace(filter), "<")
+	version = strings.TrimSpace(version)
+	if !ok || version == "" {
+		return "", fmt.Errorf("parser version filter must look like \"<1.2.0\", got %q", filter)
+	}
+	return version, nil
+}
+
+// StaleFile is an archived file whose latest parse was by an older parser
+// version than the one asked for
+type StaleFile struct {
+	FileID        string
+	RunID         string
+	Filename      string
+	ParserVersion string
+	ArchiveID     string
+	DownloadedAt  time.Time
+}
+
+// FindStaleFiles returns a chain's archived files last parsed by a parser
+// version older than before, oldest download first. An archive reparsed since
+// by a newer version is not stale. Of archives sharing a filename only the
+// newest is returned, as it supersedes the others.
+func FindStaleFiles(ctx context.Context, chainID string, before string) ([]StaleFile, error) {
+	rows, err := database.Pool().Query(ctx, `
+		SELECT DISTINCT ON (a.id)
+		       f.id, f.run_id, f.filename, COALESCE(f.parser_version, ''), a.id, a.downloaded_at
+		FROM ingestion_files f
+		JOIN ingestion_runs r ON r.id = f.run_id
+		JOIN archives a
+			ON a.id = (f.metadata::jsonb ->> 'archiveId')
+			OR a.checksum = f.file_hash
+		WHERE r.chain_slug = $1
+		  AND a.chain_slug = $1
+		  AND f.status = 'completed'
+		ORDER BY a.id, f.created_at DESC
+	`, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query parsed files: %w", err)
+	}
+	defer rows.Close()
+
+	newest := make(map[string]StaleFile)
+	for rows.Next() {
+		var f StaleFile
+		if err := rows.Scan(&f.FileID, &f.RunID, &f.Filename, &f.ParserVersion, &f.ArchiveID, &f.DownloadedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan parsed file: %w", err)
+		}
+		if current, ok := newest[f.Filename]; ok && !f.DownloadedAt.After(current.DownloadedAt) {
+			continue
+		}
+		newest[f.Filename] = f
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating parsed files: %w", err)
+	}
+
+	stale := make([]StaleFile, 0, len(newest))
+	for _, f := range newest {
+		if CompareParserVersions(f.ParserVersion, before) < 0 {
+			stale = append(stale, f)
+		}
+	}
+	slices.SortFunc(stale, func(a, b StaleFile) int {
+		if c := a.DownloadedAt.Compare(b.DownloadedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Filename, b.Filename)
+	})
+	return stale, nil
+}
+
+// Reprocess reruns stale files from their archives in one run, oldest
+// download first so the newest prices are written last. The run records
+// which parser version it replaced in metadata.reprocess.
+func Reprocess(ctx context.Context, chainID string, files []StaleFile, before string, storageBackend storage.Storage) (*IngestionResult, error) {
+	source := &archiveSource{
+		storage: storageBackend,
+		list: func(ctx context.Context, chainID string) ([]database.Archive, error) {
+			archives := make([]database.Archive, 0, len(files))
+			for _, f := range files {
+				archive, err := database.GetArchiveByID(ctx, f.ArchiveID)
+				if err != nil {
+					return nil, fmt.Errorf("failed to load archive %s: %w", f.ArchiveID, err)
+				}
+				archives = append(archives, *archive)
+			}
+			return archives, nil
+		},
+	}
+
+	result, err := run(ctx, chainID, source)
+	if err != nil || result.RunID == "" {
+		return result, err
+	}
+
+	_, err = database.Pool().Exec(ctx, `
+		UPDATE ingestion_runs
+		SET metadata = jsonb_set(
+		        COALESCE(metadata, '{}'::jsonb),
+		        '{reprocess}',
+		        jsonb_build_object('parserVersionBefore', $2::text, 'files', $3::int)
+		    )
+		WHERE id = $1
+	`, result.RunID, before, len(files))
+	if err != nil {
+		logFrom(withRunLogger(ctx, result.RunID, chainID)).Warn().Err(err).Msg("Failed to record reprocess metadata")
+	}
+	return result, nil
+}
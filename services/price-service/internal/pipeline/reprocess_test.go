@@ -0,0 +1,44 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareParserVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.0", "1.0.0", 0},
+		{"1.9.2", "1.10.0", -1},
+		{"2.0.0", "1.10.0", 1},
+		{"", "1.0.0", -1},
+		{"1.0.0", "", 1},
+		{"1.0.0-rc1", "1.0.0-rc2", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.want, CompareParserVersions(tt.a, tt.b))
+		})
+	}
+}
+
+func TestParseParserVersionFilter(t *testing.T) {
+	version, err := ParseParserVersionFilter("<1.2.0")
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.0", version)
+
+	version, err = ParseParserVersionFilter(" < 2 ")
+	require.NoError(t, err)
+	assert.Equal(t, "2", version)
+
+	for _, bad := range []string{"", "1.2.0", "<", ">1.0"} {
+		_, err := ParseParserVersionFilter(bad)
+		assert.Error(t, err, bad)
+	}
+}
@@ -0,0 +1,268 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kosarica/price-service/internal/chainsettings"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/lanes"
+	"github.com/kosarica/price-service/internal/storage"
+	"github.com/kosarica/price-service/internal/types"
+)
+
+// ErrRunNotResumable is returned for a run that cannot be resumed and should
+// be marked interrupted instead
+var ErrRunNotResumable = errors.New("run cannot be resumed")
+
+// interruptedRun is a run left running by a restart
+type interruptedRun struct {
+	chainID       string
+	status        string
+	source        string
+	stagingStatus string
+	files         int
+	resumes       int
+}
+
+// loadInterruptedRun returns the state of a run left running by a restart
+func loadInterruptedRun(ctx context.Context, runID string) (*interruptedRun, error) {
+	var run interruptedRun
+	err := database.Pool().QueryRow(ctx, `
+		SELECT r.chain_slug, r.status, COALESCE(r.source, ''), COALESCE(r.staging_status, ''),
+		       (SELECT COUNT(*) FROM ingestion_files f WHERE f.run_id = r.id),
+		       COALESCE((r.metadata -> 'resume' ->> 'count')::int, 0)
+		FROM ingestion_runs r
+		WHERE r.id = $1
+	`, runID).Scan(&run.chainID, &run.status, &run.source, &run.stagingStatus, &run.files, &run.resumes)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: run %s not found", ErrRunNotResumable, runID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load run %s: %w", runID, err)
+	}
+	return &run, nil
+}
+
+// resumable returns why the run cannot be resumed, nil if it can. Archive
+// replays are cheap to start again and their unfetched files have no
+// recorded archive, so they are not resumed.
+func (r *interruptedRun) resumable(maxResumes int) error {
+	switch {
+	case r.status != "running":
+		return fmt.Errorf("%w: run is %s", ErrRunNotResumable, r.status)
+	case r.source == "archive":
+		return fmt.Errorf("%w: archive replays are started again instead", ErrRunNotResumable)
+	case r.files == 0:
+		return fmt.Errorf("%w: no files were recorded before the restart", ErrRunNotResumable)
+	case r.resumes >= maxResumes:
+		return fmt.Errorf("%w: already resumed %d times", ErrRunNotResumable, r.resumes)
+	case !chainsettings.DiscoveryEnabled(r.chainID):
+		return fmt.Errorf("%w: discovery is disabled for chain %s", ErrRunNotResumable, r.chainID)
+	}
+	return nil
+}
+
+// CheckResumable returns nil if a run left running by a restart can be
+// resumed, else an error wrapping ErrRunNotResumable that says why
+func CheckResumable(ctx context.Context, runID string, maxResumes int) error {
+	run, err := loadInterruptedRun(ctx, runID)
+	if err != nil {
+		return err
+	}
+	return run.resumable(maxResumes)
+}
+
+// ResumeRun continues a run left running by a restart instead of starting
+// over. Files that completed, failed or were skipped are kept; the others
+// are processed again, read from the archive when they were downloaded
+// before the restart, and a file interrupted while persisting continues
+// after the chunks it already wrote. A run is resumed at most maxResumes
+// times, so a file that brings the service down cannot do so forever.
+func ResumeRun(ctx context.Context, runID string, maxResumes int, storageBackend storage.Storage) (*IngestionResult, error) {
+	run, err := loadInterruptedRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if err := run.resumable(maxResumes); err != nil {
+		return nil, err
+	}
+	chainID := run.chainID
+	if err := prepareChain(chainID); err != nil {
+		return nil, err
+	}
+
+	ctx = lanes.WithLane(ctx, lanes.Batch)
+	ctx = withRunLogger(ctx, runID, chainID)
+
+	files, archiveIDs, err := requeueUnfinishedFiles(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordResume(ctx, runID, len(files)); err != nil {
+		return nil, err
+	}
+	logFrom(ctx).Info().
+		Int("files", len(files)).
+		Int("resume", run.resumes+1).
+		Msg("Resuming interrupted run")
+
+	usage := startRunUsage(chainID)
+	defer usage.record(ctx, runID)
+
+	result := &IngestionResult{
+		RunID:  runID,
+		Errors: make([]string, 0),
+	}
+	defer recordRunMetrics(chainID, result, time.Now())
+
+	staged := run.stagingStatus == StagingInProgress
+	if staged {
+		result.StagingStatus = StagingInProgress
+	}
+
+	source := newResumeSource(ctx, archiveIDs, storageBackend)
+	processFiles(ctx, chainID, runID, source.fetch, files, staged, result)
+	finishRun(ctx, chainID, runID, staged, result)
+	return result, nil
+}
+
+// requeueUnfinishedFiles puts the files of a run that did not finish back to
+// pending, keeping their persisted chunks, and returns them in the order they
+// were discovered with the archives of those downloaded before the restart.
+// Their parse warnings are dropped as they are parsed again.
+func requeueUnfinishedFiles(ctx context.Context, runID string) ([]runFile, map[string]string, error) {
+	var unfinished []string
+	for status := range fileTransitions {
+		unfinished = append(unfinished, status)
+	}
+
+	tx, err := database.Pool().Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		WITH requeued AS (
+			UPDATE ingestion_files
+			SET status = $2,
+			    status_timestamps = COALESCE(status_timestamps, '{}'::jsonb) || jsonb_build_object($2::text, NOW())
+			WHERE run_id = $1 AND status = ANY($3)
+			RETURNING id, filename, file_type, discovered, COALESCE(processed_chunks, 0) AS processed_chunks,
+			          COALESCE(metadata::jsonb ->> 'url', '') AS url,
+			          COALESCE(metadata::jsonb ->> 'archiveId', '') AS archive_id,
+			          created_at
+		)
+		SELECT id, filename, file_type, discovered, processed_chunks, url, archive_id
+		FROM requeued
+		ORDER BY created_at, length(id), id
+	`, runID, filePending, unfinished)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to requeue unfinished files: %w", err)
+	}
+
+	files := make([]runFile, 0)
+	archiveIDs := make(map[string]string)
+	fileIDs := make([]string, 0)
+	for rows.Next() {
+		var f runFile
+		var fileType, url, archiveID string
+		var discovered []byte
+		if err := rows.Scan(&f.ID, &f.File.Filename, &fileType, &discovered, &f.DoneChunks, &url, &archiveID); err != nil {
+			rows.Close()
+			return nil, nil, fmt.Errorf("failed to scan requeued file: %w", err)
+		}
+		// Files recorded before discovered files were kept only have the URL
+		// recorded when they were parsed
+		if discovered == nil || json.Unmarshal(discovered, &f.File) != nil {
+			f.File.URL = url
+			f.File.Type = types.FileType(fileType)
+		}
+		if archiveID != "" {
+			archiveIDs[f.File.Filename] = archiveID
+		}
+		files = append(files, f)
+		fileIDs = append(fileIDs, f.ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating requeued files: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM ingestion_errors
+		WHERE file_id = ANY($1) AND error_type = 'parse_warning'
+	`, fileIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to drop parse warnings of requeued files: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit requeued files: %w", err)
+	}
+	return files, archiveIDs, nil
+}
+
+// recordResume counts a resume of the run in metadata.resume
+func recordResume(ctx context.Context, runID string, files int) error {
+	_, err := database.Pool().Exec(ctx, `
+		UPDATE ingestion_runs
+		SET metadata = jsonb_set(
+		        COALESCE(metadata, '{}'::jsonb),
+		        '{resume}',
+		        jsonb_build_object(
+		            'count', COALESCE((metadata -> 'resume' ->> 'count')::int, 0) + 1,
+		            'resumedAt', NOW(),
+		            'files', $2::int
+		        )
+		    )
+		WHERE id = $1
+	`, runID, files)
+	if err != nil {
+		return fmt.Errorf("failed to record run resume: %w", err)
+	}
+	return nil
+}
+
+// resumeSource fetches the unfinished files of a resumed run: from the
+// archive when they were downloaded before the restart, else from the chain's
+// portal
+type resumeSource struct {
+	archived *archiveSource
+	portal   *portalSource
+}
+
+// newResumeSource returns the source of a resumed run's files. archiveIDs
+// maps the filenames downloaded before the restart to their archives; one
+// that cannot be loaded is downloaded again.
+func newResumeSource(ctx context.Context, archiveIDs map[string]string, storageBackend storage.Storage) *resumeSource {
+	archives := make(map[string]database.Archive, len(archiveIDs))
+	for filename, archiveID := range archiveIDs {
+		archive, err := database.GetArchiveByID(ctx, archiveID)
+		if err != nil {
+			logFrom(ctx).Warn().Err(err).Str("archive_id", archiveID).Msg("Failed to load archive of requeued file, downloading it again")
+			continue
+		}
+		archives[filename] = *archive
+	}
+
+	return &resumeSource{
+		archived: &archiveSource{storage: storageBackend, archives: archives},
+		portal:   &portalSource{storage: storageBackend},
+	}
+}
+
+func (s *resumeSource) fetch(ctx context.Context, chainID string, file types.DiscoveredFile) (*FetchResult, error) {
+	if _, ok := s.archived.archives[file.Filename]; ok {
+		return s.archived.fetch(ctx, chainID, file)
+	}
+	if file.URL == "" {
+		return nil, fmt.Errorf("no URL recorded for %s", file.Filename)
+	}
+	return s.portal.fetch(ctx, chainID, file)
+}
@@ -0,0 +1,37 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterruptedRunResumable(t *testing.T) {
+	running := interruptedRun{chainID: "konzum", status: "running", source: "worker", files: 12, resumes: 1}
+
+	tests := []struct {
+		name string
+		edit func(r *interruptedRun)
+		want bool
+	}{
+		{"running with files", func(r *interruptedRun) {}, true},
+		{"scheduled run", func(r *interruptedRun) { r.source = "schedule" }, true},
+		{"already finished", func(r *interruptedRun) { r.status = "completed" }, false},
+		{"archive replay", func(r *interruptedRun) { r.source = "archive" }, false},
+		{"interrupted during discovery", func(r *interruptedRun) { r.files = 0 }, false},
+		{"resumed too often", func(r *interruptedRun) { r.resumes = 3 }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := running
+			tt.edit(&run)
+			err := run.resumable(3)
+			if tt.want {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrRunNotResumable)
+			}
+		})
+	}
+}
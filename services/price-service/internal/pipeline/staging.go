@@ -0,0 +1,516 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/types"
+)
+
+// Staging statuses of an ingestion run, in ingestion_runs.staging_status.
+// Runs written straight to the live tables have none.
+const (
+	StagingInProgress = "staging"
+	StagingStaged     = "staged"
+	StagingPublished  = "published"
+	StagingRejected   = "rejected"
+)
+
+// QualityGate is who publishes a staged run whose quality checks all passed
+const QualityGate = "quality_gate"
+
+// Quality thresholds a staged run must stay within to be published without
+// review
+const (
+	// maxInvalidRowShare is the largest share of parsed rows that may fail
+	// validation
+	maxInvalidRowShare = 0.10
+	// maxStoreRowDrop is the largest relative drop in a known store's item
+	// count compared with its recently seen items
+	maxStoreRowDrop = 0.50
+	// maxPriceShiftShare is the largest share of known items whose price may
+	// shift by more than priceShiftRatio
+	maxPriceShiftShare = 0.20
+	// priceShiftRatio is the relative price change that counts as a shift
+	priceShiftRatio = 0.50
+	// recentStateWindow is how recently a store item must have been seen to
+	// count towards the store's previous item count
+	recentStateWindow = 7 * 24 * time.Hour
+)
+
+var (
+	// ErrRunNotFound is returned when a run does not exist
+	ErrRunNotFound = errors.New("run not found")
+	// ErrRunNotStaged is returned when publishing or rejecting a run that is
+	// not awaiting publish
+	ErrRunNotStaged = errors.New("run is not awaiting publish")
+)
+
+// QualityCheck is the outcome of one quality check of a staged run
+type QualityCheck struct {
+	Name      string  `json:"name"`
+	Passed    bool    `json:"passed"`
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+}
+
+// QualityReport is the outcome of a staged run's quality checks
+type QualityReport struct {
+	Passed    bool           `json:"passed"`
+	Checks    []QualityCheck `json:"checks"`
+	CheckedAt time.Time      `json:"checkedAt"`
+}
+
+// PublishResult is what publishing a staged run wrote to the live tables
+type PublishResult struct {
+	RunID        string
+	Files        int
+	Persisted    int
+	PriceChanges int
+}
+
+// stagedRunStats are the figures a staged run's quality is judged on
+type stagedRunStats struct {
+	TotalRows int
+	ValidRows int
+	// WorstStoreDrop is the largest relative drop in item count among the
+	// stores that already had items
+	WorstStoreDrop float64
+	// MatchedPrices counts staged rows of items the store already prices, and
+	// ShiftedPrices those among them whose price shifted
+	MatchedPrices int
+	ShiftedPrices int
+}
+
+// evaluateQuality checks a staged run's figures against the thresholds
+func evaluateQuality(stats stagedRunStats, now time.Time) QualityReport {
+	invalidShare := 0.0
+	if stats.TotalRows > 0 {
+		invalidShare = float64(stats.TotalRows-stats.ValidRows) / float64(stats.TotalRows)
+	}
+	shiftShare := 0.0
+	if stats.MatchedPrices > 0 {
+		shiftShare = float64(stats.ShiftedPrices) / float64(stats.MatchedPrices)
+	}
+
+	report := QualityReport{
+		Checks: []QualityCheck{
+			{Name: "rows_staged", Value: float64(stats.ValidRows), Threshold: 1, Passed: stats.ValidRows >= 1},
+			{Name: "invalid_row_share", Value: invalidShare, Threshold: maxInvalidRowShare, Passed: invalidShare <= maxInvalidRowShare},
+			{Name: "store_row_drop", Value: stats.WorstStoreDrop, Threshold: maxStoreRowDrop, Passed: stats.WorstStoreDrop <= maxStoreRowDrop},
+			{Name: "price_shift_share", Value: shiftShare, Threshold: maxPriceShiftShare, Passed: shiftShare <= maxPriceShiftShare},
+		},
+		CheckedAt: now,
+	}
+	report.Passed = true
+	for _, check := range report.Checks {
+		report.Passed = report.Passed && check.Passed
+	}
+	return report
+}
+
+type publishTxKey struct{}
+
+// withPublishTx returns a context whose store transactions are savepoints of
+// tx, so a staged run is published all at once
+func withPublishTx(ctx context.Context, tx pgx.Tx) context.Context {
+	return context.WithValue(ctx, publishTxKey{}, tx)
+}
+
+// publishing reports whether ctx publishes a staged run
+func publishing(ctx context.Context) bool {
+	_, ok := ctx.Value(publishTxKey{}).(pgx.Tx)
+	return ok
+}
+
+// beginStoreTx begins the transaction a store's rows are persisted in: a
+// savepoint of the publish transaction while one is in progress, a new
+// transaction otherwise
+func beginStoreTx(ctx context.Context) (pgx.Tx, error) {
+	if tx, ok := ctx.Value(publishTxKey{}).(pgx.Tx); ok {
+		return tx.Begin(ctx)
+	}
+	return database.Pool().Begin(ctx)
+}
+
+// markRunStaging records that a run writes to the staging tables
+func markRunStaging(ctx context.Context, runID string) error {
+	_, err := database.Pool().Exec(ctx, `
+		UPDATE ingestion_runs SET staging_status = $2 WHERE id = $1
+	`, runID, StagingInProgress)
+	return err
+}
+
+// StagePhase writes a parsed file to the staging tables instead of the live
+// ones and records the file's progress like PersistPhase does. Price changes
+// are only known once the run is published.
+func StagePhase(ctx context.Context, parseResult *ParseResult, file types.DiscoveredFile, runID string, archiveID string) (*PersistResult, error) {
+	fileJSON, err := json.Marshal(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode discovered file: %w", err)
+	}
+
+	tx, err := database.Pool().Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO ingestion_staged_files (file_id, run_id, archive_id, discovered_file, total_rows, valid_rows)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6)
+	`, parseResult.FileID, runID, archiveID, fileJSON, parseResult.TotalRows, parseResult.ValidRows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stage file: %w", err)
+	}
+
+	for storeIdentifier, rows := range parseResult.RowsByStore {
+		rowsJSON, err := json.Marshal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode staged rows: %w", err)
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO ingestion_staged_rows (file_id, store_identifier, rows)
+			VALUES ($1, $2, $3)
+		`, parseResult.FileID, storeIdentifier, rowsJSON)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stage rows: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit staged file: %w", err)
+	}
+
+	logFrom(ctx).Info().Int("rows", parseResult.ValidRows).Int("stores", len(parseResult.RowsByStore)).Msg("Staged rows")
+
+	result := &PersistResult{Persisted: parseResult.ValidRows}
+	if err := recordFileProgress(ctx, runID, parseResult.FileID, 1, result.Persisted, 0); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// finishStagedRun runs the quality checks of a run whose files are all
+// staged. A run passing every check is published at once; otherwise it is
+// held for review. Returns the run's staging status and, when it was
+// published, what was published.
+func finishStagedRun(ctx context.Context, chainID string, runID string) (string, *PublishResult, error) {
+	stats, err := loadStagedRunStats(ctx, chainID, runID)
+	if err != nil {
+		return StagingInProgress, nil, err
+	}
+	report := evaluateQuality(stats, time.Now())
+
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		return StagingInProgress, nil, err
+	}
+	tag, err := database.Pool().Exec(ctx, `
+		UPDATE ingestion_runs
+		SET staging_status = $2, quality_report = $3
+		WHERE id = $1 AND staging_status = $4
+	`, runID, StagingStaged, reportJSON, StagingInProgress)
+	if err != nil {
+		return StagingInProgress, nil, fmt.Errorf("failed to record quality report: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		// Already checked by an earlier call
+		return StagingStaged, nil, nil
+	}
+
+	if !report.Passed {
+		event := logFrom(ctx).Warn()
+		for _, check := range report.Checks {
+			if !check.Passed {
+				event = event.Float64(check.Name, check.Value)
+			}
+		}
+		event.Msg("Staged run failed quality checks, held for review")
+		return StagingStaged, nil, nil
+	}
+
+	published, err := PublishRun(ctx, runID, QualityGate)
+	if err != nil {
+		return StagingStaged, nil, err
+	}
+	return StagingPublished, published, nil
+}
+
+// loadStagedRunStats computes the figures of a staged run's quality checks,
+// comparing its rows with the chain's live data
+func loadStagedRunStats(ctx context.Context, chainID string, runID string) (stagedRunStats, error) {
+	pool := database.Pool()
+	var stats stagedRunStats
+
+	err := pool.QueryRow(ctx, `
+		SELECT COALESCE(SUM(total_rows), 0), COALESCE(SUM(valid_rows), 0)
+		FROM ingestion_staged_files
+		WHERE run_id = $1
+	`, runID).Scan(&stats.TotalRows, &stats.ValidRows)
+	if err != nil {
+		return stats, fmt.Errorf("failed to count staged rows: %w", err)
+	}
+
+	err = pool.QueryRow(ctx, `
+		WITH staged AS (
+			SELECT sr.store_identifier, SUM(jsonb_array_length(sr.rows)) AS items
+			FROM ingestion_staged_rows sr
+			JOIN ingestion_staged_files sf ON sf.file_id = sr.file_id
+			WHERE sf.run_id = $1
+			GROUP BY sr.store_identifier
+		), previous AS (
+			SELECT sident.value AS store_identifier, COUNT(*) AS items
+			FROM stores s
+			JOIN store_identifiers sident ON sident.store_id = s.id AND sident.type = 'filename_code'
+			JOIN store_item_state sis ON sis.store_id = s.id
+			WHERE s.chain_slug = $2
+			  AND sis.last_seen_at >= $3
+			  AND sident.value IN (SELECT store_identifier FROM staged)
+			GROUP BY sident.value
+		)
+		SELECT COALESCE(MAX(1 - staged.items::float8 / previous.items), 0)
+		FROM staged
+		JOIN previous ON previous.store_identifier = staged.store_identifier
+	`, runID, chainID, time.Now().Add(-recentStateWindow)).Scan(&stats.WorstStoreDrop)
+	if err != nil {
+		return stats, fmt.Errorf("failed to compare store item counts: %w", err)
+	}
+
+	err = pool.QueryRow(ctx, `
+		SELECT COUNT(*),
+		       COUNT(*) FILTER (
+		           WHERE abs((r->>'price')::int - sis.current_price) > sis.current_price * $3
+		       )
+		FROM ingestion_staged_rows sr
+		JOIN ingestion_staged_files sf ON sf.file_id = sr.file_id
+		CROSS JOIN LATERAL jsonb_array_elements(sr.rows) r
+		JOIN store_identifiers sident ON sident.type = 'filename_code' AND sident.value = sr.store_identifier
+		JOIN stores s ON s.id = sident.store_id AND s.chain_slug = $2
+		JOIN retailer_items ri ON ri.chain_slug = $2 AND ri.external_id = r->>'externalId'
+		JOIN store_item_state sis ON sis.store_id = s.id AND sis.retailer_item_id = ri.id
+		WHERE sf.run_id = $1 AND sis.current_price > 0
+	`, runID, chainID, priceShiftRatio).Scan(&stats.MatchedPrices, &stats.ShiftedPrices)
+	if err != nil {
+		return stats, fmt.Errorf("failed to compare staged prices: %w", err)
+	}
+
+	return stats, nil
+}
+
+// stagedFile is a file of a staged run awaiting publish
+type stagedFile struct {
+	fileID    string
+	archiveID string
+	file      types.DiscoveredFile
+}
+
+// PublishRun writes a staged run's rows to the live tables in a single
+// transaction: either every store of every file is published or nothing is.
+// approvedBy names who published it, QualityGate for automatic publishes.
+func PublishRun(ctx context.Context, runID string, approvedBy string) (*PublishResult, error) {
+	tx, err := database.Pool().Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	chainID, err := lockStagedRun(ctx, tx, runID)
+	if err != nil {
+		return nil, err
+	}
+	ctx = withRunLogger(ctx, runID, chainID)
+
+	files, err := loadStagedFiles(ctx, tx, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PublishResult{RunID: runID, Files: len(files)}
+	outcomes := make([]*fileOutcome, len(files))
+	publishCtx := withPublishTx(ctx, tx)
+	for i, staged := range files {
+		rowsByStore, err := loadStagedRows(ctx, tx, staged.fileID)
+		if err != nil {
+			return nil, err
+		}
+
+		parseResult := &ParseResult{FileID: staged.fileID, RowsByStore: rowsByStore}
+		outcome, err := persistFileRows(withFileLogger(publishCtx, staged.file.Filename), chainID, parseResult, staged.file, runID, staged.archiveID)
+		if err != nil {
+			return nil, err
+		}
+		if outcome.FailedStores > 0 {
+			return nil, fmt.Errorf("failed to publish %d store(s) of %s", outcome.FailedStores, staged.file.Filename)
+		}
+
+		outcomes[i] = outcome
+		result.Persisted += outcome.Persisted
+		result.PriceChanges += outcome.PriceChanges
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE ingestion_runs
+		SET staging_status = $2,
+		    price_changes = COALESCE(price_changes, 0) + $3,
+		    metadata = jsonb_set(
+		        COALESCE(metadata, '{}'::jsonb),
+		        '{publish}',
+		        jsonb_build_object('approved_by', $4::text, 'published_at', NOW(), 'persisted', $5::int)
+		    )
+		WHERE id = $1
+	`, runID, StagingPublished, result.PriceChanges, approvedBy, result.Persisted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark run published: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM ingestion_staged_files WHERE run_id = $1`, runID); err != nil {
+		return nil, fmt.Errorf("failed to clear staged rows: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit publish: %w", err)
+	}
+
+	for i, outcome := range outcomes {
+		outcome.publish(ctx, files[i].archiveID)
+	}
+
+	logFrom(ctx).Info().
+		Str("approved_by", approvedBy).
+		Int("files", result.Files).
+		Int("persisted", result.Persisted).
+		Int("price_changes", result.PriceChanges).
+		Msg("Published staged run")
+	return result, nil
+}
+
+// RejectRun discards a staged run's rows without publishing them
+func RejectRun(ctx context.Context, runID string, rejectedBy string, reason string) error {
+	tx, err := database.Pool().Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	chainID, err := lockStagedRun(ctx, tx, runID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE ingestion_runs
+		SET staging_status = $2,
+		    metadata = jsonb_set(
+		        COALESCE(metadata, '{}'::jsonb),
+		        '{publish}',
+		        jsonb_build_object('rejected_by', $3::text, 'rejected_at', NOW(), 'reason', $4::text)
+		    )
+		WHERE id = $1
+	`, runID, StagingRejected, rejectedBy, reason)
+	if err != nil {
+		return fmt.Errorf("failed to mark run rejected: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM ingestion_staged_files WHERE run_id = $1`, runID); err != nil {
+		return fmt.Errorf("failed to clear staged rows: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit reject: %w", err)
+	}
+
+	logFrom(withRunLogger(ctx, runID, chainID)).Info().
+		Str("rejected_by", rejectedBy).
+		Str("reason", reason).
+		Msg("Rejected staged run")
+	return nil
+}
+
+// lockStagedRun locks a run awaiting publish and returns its chain
+func lockStagedRun(ctx context.Context, tx pgx.Tx, runID string) (string, error) {
+	var chainID, status string
+	err := tx.QueryRow(ctx, `
+		SELECT chain_slug, COALESCE(staging_status, '')
+		FROM ingestion_runs
+		WHERE id = $1
+		FOR UPDATE
+	`, runID).Scan(&chainID, &status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrRunNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to lock run: %w", err)
+	}
+	if status != StagingStaged {
+		return "", fmt.Errorf("%w: staging status is %q", ErrRunNotStaged, status)
+	}
+	return chainID, nil
+}
+
+// loadStagedFiles returns a staged run's files in the order they were staged
+func loadStagedFiles(ctx context.Context, tx pgx.Tx, runID string) ([]stagedFile, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT file_id, COALESCE(archive_id, ''), discovered_file
+		FROM ingestion_staged_files
+		WHERE run_id = $1
+		ORDER BY created_at, file_id
+	`, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query staged files: %w", err)
+	}
+	defer rows.Close()
+
+	var files []stagedFile
+	for rows.Next() {
+		var f stagedFile
+		var fileJSON []byte
+		if err := rows.Scan(&f.fileID, &f.archiveID, &fileJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan staged file: %w", err)
+		}
+		if err := json.Unmarshal(fileJSON, &f.file); err != nil {
+			return nil, fmt.Errorf("failed to decode staged file %s: %w", f.fileID, err)
+		}
+		files = append(files, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating staged files: %w", err)
+	}
+	return files, nil
+}
+
+// loadStagedRows returns a staged file's rows by store identifier
+func loadStagedRows(ctx context.Context, tx pgx.Tx, fileID string) (map[string][]types.NormalizedRow, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT store_identifier, rows
+		FROM ingestion_staged_rows
+		WHERE file_id = $1
+	`, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query staged rows: %w", err)
+	}
+	defer rows.Close()
+
+	rowsByStore := make(map[string][]types.NormalizedRow)
+	for rows.Next() {
+		var storeIdentifier string
+		var rowsJSON []byte
+		if err := rows.Scan(&storeIdentifier, &rowsJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan staged rows: %w", err)
+		}
+		var storeRows []types.NormalizedRow
+		if err := json.Unmarshal(rowsJSON, &storeRows); err != nil {
+			return nil, fmt.Errorf("failed to decode staged rows of %s: %w", storeIdentifier, err)
+		}
+		rowsByStore[storeIdentifier] = storeRows
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating staged rows: %w", err)
+	}
+	return rowsByStore, nil
+}
@@ -0,0 +1,42 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateQuality(t *testing.T) {
+	healthy := stagedRunStats{TotalRows: 1000, ValidRows: 980, WorstStoreDrop: 0.05, MatchedPrices: 900, ShiftedPrices: 10}
+
+	tests := []struct {
+		name   string
+		stats  func(s stagedRunStats) stagedRunStats
+		failed string
+	}{
+		{"healthy run passes", func(s stagedRunStats) stagedRunStats { return s }, ""},
+		{"no rows", func(s stagedRunStats) stagedRunStats { s.TotalRows, s.ValidRows = 0, 0; return s }, "rows_staged"},
+		{"too many invalid rows", func(s stagedRunStats) stagedRunStats { s.ValidRows = 800; return s }, "invalid_row_share"},
+		{"store lost most items", func(s stagedRunStats) stagedRunStats { s.WorstStoreDrop = 0.8; return s }, "store_row_drop"},
+		{"prices shifted", func(s stagedRunStats) stagedRunStats { s.ShiftedPrices = 400; return s }, "price_shift_share"},
+		{"new chain has nothing to compare", func(s stagedRunStats) stagedRunStats {
+			s.WorstStoreDrop, s.MatchedPrices, s.ShiftedPrices = 0, 0, 0
+			return s
+		}, ""},
+	}
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := evaluateQuality(tt.stats(healthy), now)
+
+			assert.Equal(t, tt.failed == "", report.Passed)
+			assert.Equal(t, now, report.CheckedAt)
+			assert.Len(t, report.Checks, 4)
+			for _, check := range report.Checks {
+				assert.Equal(t, check.Name != tt.failed, check.Passed, check.Name)
+			}
+		})
+	}
+}
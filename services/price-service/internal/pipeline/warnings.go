@@ -0,0 +1,121 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/types"
+)
+
+const (
+	// maxWarningGroups caps how many aggregated warning rows one file writes
+	maxWarningGroups = 50
+	// maxWarningSamples caps the sample rows kept per warning group
+	maxWarningSamples = 10
+)
+
+// warningSample is one sampled occurrence of a warning
+type warningSample struct {
+	RowNumber *int   `json:"rowNumber,omitempty"`
+	Message   string `json:"message"`
+}
+
+// warningGroup aggregates every occurrence of the same warning in a file
+type warningGroup struct {
+	Field   *string         `json:"field,omitempty"`
+	Message string          `json:"-"`
+	Count   int             `json:"count"`
+	Samples []warningSample `json:"samples"`
+}
+
+// collectWarnings returns the parser warnings together with the row-level
+// validation warnings persist would otherwise drop
+func collectWarnings(result *types.ParseResult) []types.ParseWarning {
+	warnings := append([]types.ParseWarning{}, result.Warnings...)
+	for _, row := range result.Rows {
+		warnings = append(warnings, validationWarnings(row)...)
+	}
+	return warnings
+}
+
+// validationWarnings returns the row validation warnings of a row
+func validationWarnings(row types.NormalizedRow) []types.ParseWarning {
+	validation := validateNormalizedRow(row)
+	warnings := make([]types.ParseWarning, 0, len(validation.Warnings))
+	for _, w := range validation.Warnings {
+		warnings = append(warnings, types.ParseWarning{
+			RowNumber: types.IntPtr(row.RowNumber),
+			Message:   w,
+		})
+	}
+	return warnings
+}
+
+// aggregateWarnings groups warnings by field and message, most frequent
+// first. Anything after a ": " in the message is treated as the offending
+// value, so "Invalid barcode format: 123" and "...: 456" share a group.
+func aggregateWarnings(warnings []types.ParseWarning) []warningGroup {
+	byKey := make(map[string]*warningGroup)
+	order := make([]string, 0)
+
+	for _, w := range warnings {
+		message, _, _ := strings.Cut(w.Message, ": ")
+		key := message
+		if w.Field != nil {
+			key = *w.Field + "\x00" + message
+		}
+
+		group, ok := byKey[key]
+		if !ok {
+			group = &warningGroup{Field: w.Field, Message: message}
+			byKey[key] = group
+			order = append(order, key)
+		}
+		group.Count++
+		if len(group.Samples) < maxWarningSamples {
+			group.Samples = append(group.Samples, warningSample{RowNumber: w.RowNumber, Message: w.Message})
+		}
+	}
+
+	groups := make([]warningGroup, 0, len(order))
+	for _, key := range order {
+		groups = append(groups, *byKey[key])
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Count > groups[j].Count })
+
+	if len(groups) > maxWarningGroups {
+		groups = groups[:maxWarningGroups]
+	}
+	return groups
+}
+
+// recordParseWarnings writes one ingestion_errors row with severity
+// 'warning' per warning group of a file
+func recordParseWarnings(ctx context.Context, runID string, fileID string, groups []warningGroup) error {
+	if len(groups) == 0 {
+		return nil
+	}
+
+	pool := database.Pool()
+
+	_, err := pool.CopyFrom(ctx,
+		pgx.Identifier{"ingestion_errors"},
+		[]string{"run_id", "file_id", "error_type", "error_message", "error_details", "severity"},
+		pgx.CopyFromSlice(len(groups), func(i int) ([]any, error) {
+			details, err := json.Marshal(groups[i])
+			if err != nil {
+				return nil, err
+			}
+			return []any{runID, fileID, "parse_warning", groups[i].Message, string(details), "warning"}, nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record parse warnings: %w", err)
+	}
+	return nil
+}
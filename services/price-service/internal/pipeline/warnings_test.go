@@ -0,0 +1,64 @@
+package pipeline
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kosarica/price-service/internal/types"
+)
+
+func TestAggregateWarnings(t *testing.T) {
+	var warnings []types.ParseWarning
+	for i := 1; i <= 25; i++ {
+		warnings = append(warnings, types.ParseWarning{
+			RowNumber: types.IntPtr(i),
+			Message:   "Discount price is not less than regular price",
+		})
+	}
+	warnings = append(warnings,
+		types.ParseWarning{RowNumber: types.IntPtr(3), Message: "Invalid barcode format: 123"},
+		types.ParseWarning{RowNumber: types.IntPtr(4), Message: "Invalid barcode format: 456"},
+		types.ParseWarning{RowNumber: types.IntPtr(5), Field: types.StringPtr("unitPrice"), Message: "Invalid unit price value, ignoring"},
+	)
+
+	groups := aggregateWarnings(warnings)
+	require.Len(t, groups, 3)
+
+	assert.Equal(t, "Discount price is not less than regular price", groups[0].Message)
+	assert.Equal(t, 25, groups[0].Count)
+	assert.Len(t, groups[0].Samples, maxWarningSamples)
+
+	assert.Equal(t, "Invalid barcode format", groups[1].Message)
+	assert.Equal(t, 2, groups[1].Count)
+	assert.Equal(t, "Invalid barcode format: 456", groups[1].Samples[1].Message)
+
+	require.NotNil(t, groups[2].Field)
+	assert.Equal(t, "unitPrice", *groups[2].Field)
+}
+
+func TestAggregateWarningsCapsGroups(t *testing.T) {
+	var warnings []types.ParseWarning
+	for i := 0; i < maxWarningGroups+10; i++ {
+		warnings = append(warnings, types.ParseWarning{Message: fmt.Sprintf("warning %d", i)})
+	}
+
+	assert.Len(t, aggregateWarnings(warnings), maxWarningGroups)
+}
+
+func TestCollectWarningsIncludesValidationWarnings(t *testing.T) {
+	result := &types.ParseResult{
+		Rows: []types.NormalizedRow{
+			{RowNumber: 7, Name: "Mlijeko", Price: 100, DiscountPrice: types.IntPtr(120)},
+			{RowNumber: 8, Name: "Kruh", Price: 150},
+		},
+		Warnings: []types.ParseWarning{{Message: "Excel file is empty"}},
+	}
+
+	warnings := collectWarnings(result)
+	require.Len(t, warnings, 2)
+	assert.Equal(t, "Excel file is empty", warnings[0].Message)
+	assert.Equal(t, 7, *warnings[1].RowNumber)
+}
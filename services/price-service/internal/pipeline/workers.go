@@ -0,0 +1,118 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+
+	appconfig "github.com/kosarica/price-service/config"
+)
+
+// defaultFileWorkers is the number of files of a run processed at once when
+// the config does not set it
+const defaultFileWorkers = 4
+
+// WorkerPool processes the files of a run on a fixed number of workers
+type WorkerPool struct {
+	workers int
+}
+
+// NewWorkerPool creates a pool of n workers, at least one
+func NewWorkerPool(n int) *WorkerPool {
+	return &WorkerPool{workers: max(n, 1)}
+}
+
+// Each calls fn with every index below n, on up to the pool's workers at a
+// time, and waits for the calls to return. Indexes are started in order;
+// once a call returns false no further index is started. Returns the first
+// index that was not started, n when all were.
+func (p *WorkerPool) Each(n int, fn func(i int) bool) int {
+	var mu sync.Mutex
+	next, stopped := 0, false
+	take := func() (int, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		if stopped || next >= n {
+			return 0, false
+		}
+		next++
+		return next - 1, true
+	}
+	stop := func() {
+		mu.Lock()
+		stopped = true
+		mu.Unlock()
+	}
+
+	var wg sync.WaitGroup
+	for range min(p.workers, n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i, ok := take()
+				if !ok {
+					return
+				}
+				if !fn(i) {
+					stop()
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return next
+}
+
+// fileWorkers returns how many files of a run are processed at once
+func fileWorkers() int {
+	cfg := appconfig.Get()
+	if cfg == nil || cfg.Ingestion.FileWorkers < 1 {
+		return defaultFileWorkers
+	}
+	return cfg.Ingestion.FileWorkers
+}
+
+type storeLocksKey struct{}
+
+// storeLocks serializes the persisting of each store's rows across the files
+// of a run processed at once, so a new store is registered only once
+type storeLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// withStoreLocks returns a context whose files lock the stores they persist
+func withStoreLocks(ctx context.Context) context.Context {
+	return context.WithValue(ctx, storeLocksKey{}, &storeLocks{locks: make(map[string]*sync.Mutex)})
+}
+
+// lockStore locks a store of the run in ctx until the returned function is
+// called. Without store locks in ctx it locks nothing.
+func lockStore(ctx context.Context, storeIdentifier string) func() {
+	s, ok := ctx.Value(storeLocksKey{}).(*storeLocks)
+	if !ok {
+		return func() {}
+	}
+
+	s.mu.Lock()
+	lock, ok := s.locks[storeIdentifier]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.locks[storeIdentifier] = lock
+	}
+	s.mu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+// withStoreLock calls fn holding the store's lock (see lockStore). The lock
+// is released however fn returns, a panic included, so the other files of
+// the run can still persist the store.
+func withStoreLock(ctx context.Context, storeIdentifier string, fn func() error) error {
+	unlock := lockStore(ctx, storeIdentifier)
+	defer unlock()
+	return fn()
+}
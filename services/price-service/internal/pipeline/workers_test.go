@@ -0,0 +1,103 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerPoolEach(t *testing.T) {
+	var running, peak atomic.Int32
+	var mu sync.Mutex
+	seen := make(map[int]bool)
+
+	next := NewWorkerPool(3).Each(10, func(i int) bool {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+
+		mu.Lock()
+		seen[i] = true
+		mu.Unlock()
+		return true
+	})
+
+	assert.Equal(t, 10, next)
+	assert.Len(t, seen, 10)
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+}
+
+func TestWorkerPoolEachStops(t *testing.T) {
+	var calls atomic.Int32
+	next := NewWorkerPool(1).Each(10, func(i int) bool {
+		calls.Add(1)
+		return i < 3
+	})
+
+	assert.Equal(t, 4, next, "no index is started after a call returns false")
+	assert.Equal(t, int32(4), calls.Load())
+}
+
+func TestNewWorkerPoolHasAWorker(t *testing.T) {
+	assert.Equal(t, 5, NewWorkerPool(0).Each(5, func(int) bool { return true }))
+}
+
+func TestLockStore(t *testing.T) {
+	// Without store locks nothing is locked
+	unlock := lockStore(context.Background(), "101")
+	unlock()
+
+	ctx := withStoreLocks(context.Background())
+	unlock = lockStore(ctx, "101")
+	other := lockStore(ctx, "102") // another store is not blocked
+	other()
+
+	locked := make(chan struct{})
+	go func() {
+		release := lockStore(ctx, "101")
+		close(locked)
+		release()
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("a store locked by one file must wait for it")
+	case <-time.After(20 * time.Millisecond):
+	}
+	unlock()
+	<-locked
+}
+
+// TestWithStoreLockReleasesOnPanic verifies a file panicking while it
+// persists a store, recovered as processFile does, leaves the store to the
+// next file.
+func TestWithStoreLockReleasesOnPanic(t *testing.T) {
+	ctx := withStoreLocks(context.Background())
+
+	func() {
+		defer func() { assert.NotNil(t, recover()) }()
+		_ = withStoreLock(ctx, "101", func() error { panic("persist failed") })
+	}()
+
+	persisted := make(chan error, 1)
+	go func() {
+		persisted <- withStoreLock(ctx, "101", func() error { return nil })
+	}()
+
+	select {
+	case err := <-persisted:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("the next file must be able to persist the store")
+	}
+}
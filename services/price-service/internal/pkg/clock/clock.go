@@ -0,0 +1,107 @@
+// Package clock tells the time and the calendar date in the service's time
+// zone, so ingestion, statistics and discounts agree on when a day starts.
+//
+// The zone is Europe/Zagreb unless SetZone changes it at startup. Calendar
+// dates without a time, such as discount windows read from price files, are
+// held as midnight UTC of the date; DateStart turns one into the instant the
+// day starts in the zone. Components that need a fixed time in tests take a
+// now function defaulting to Now, and Now itself is replaced by Set.
+package clock
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	// The zone must load in images without the system's zone database
+	_ "time/tzdata"
+)
+
+// DefaultZone is the service's time zone unless configured otherwise
+const DefaultZone = "Europe/Zagreb"
+
+// Fixed returns a clock stopped at t
+func Fixed(t time.Time) func() time.Time {
+	return func() time.Time { return t }
+}
+
+var (
+	current atomic.Pointer[func() time.Time]
+	zone    atomic.Pointer[time.Location]
+)
+
+func init() {
+	now := time.Now
+	current.Store(&now)
+	location, err := time.LoadLocation(DefaultZone)
+	if err != nil {
+		panic(fmt.Sprintf("clock: failed to load %s: %v", DefaultZone, err))
+	}
+	zone.Store(location)
+}
+
+// Now returns the current time of the package's clock
+func Now() time.Time {
+	return (*current.Load())()
+}
+
+// Set replaces the package's clock and returns a function restoring the
+// previous one. Meant for tests.
+func Set(now func() time.Time) (restore func()) {
+	previous := current.Swap(&now)
+	return func() { current.Store(previous) }
+}
+
+// Zone returns the service's time zone
+func Zone() *time.Location {
+	return zone.Load()
+}
+
+// SetZone sets the service's time zone by IANA name, e.g. "Europe/Zagreb".
+func SetZone(name string) error {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("invalid time zone %q: %w", name, err)
+	}
+	zone.Store(location)
+	return nil
+}
+
+// Today returns the current date in the zone as YYYY-MM-DD
+func Today() string {
+	return Now().In(Zone()).Format(time.DateOnly)
+}
+
+// StartOfDay returns the instant the day holding t starts in the zone. On
+// the days clocks change it is still midnight, 23 or 25 hours before the
+// next day's start.
+func StartOfDay(t time.Time) time.Time {
+	year, month, day := t.In(Zone()).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, Zone())
+}
+
+// AddDays returns the time n calendar days after t in the zone, at the same
+// wall clock time; across a clock change that is an hour more or less than
+// n times 24 hours.
+func AddDays(t time.Time, n int) time.Time {
+	return t.In(Zone()).AddDate(0, 0, n)
+}
+
+// ParseDate parses a YYYY-MM-DD date as the instant it starts in the zone
+func ParseDate(s string) (time.Time, error) {
+	return time.ParseInLocation(time.DateOnly, s, Zone())
+}
+
+// IsDate reports whether t holds a calendar date without a time: midnight
+// UTC, as parsers read dates such as "2025-08-01".
+func IsDate(t time.Time) bool {
+	t = t.UTC()
+	return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0
+}
+
+// DateStart returns the instant the calendar date held by date, as midnight
+// UTC, starts in the zone.
+func DateStart(date time.Time) time.Time {
+	year, month, day := date.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, Zone())
+}
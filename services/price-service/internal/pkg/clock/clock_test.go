@@ -0,0 +1,105 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Clocks in Zagreb go forward at 02:00 on 2024-03-31 and back at 03:00 on
+// 2024-10-27
+var (
+	springForward = time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	fallBack      = time.Date(2024, 10, 27, 0, 0, 0, 0, time.UTC)
+)
+
+func TestToday(t *testing.T) {
+	// 23:30 UTC is already the next day in Zagreb, summer and winter
+	defer Set(Fixed(time.Date(2024, 3, 30, 23, 30, 0, 0, time.UTC)))()
+	assert.Equal(t, "2024-03-31", Today())
+
+	defer Set(Fixed(time.Date(2024, 7, 1, 21, 59, 0, 0, time.UTC)))()
+	assert.Equal(t, "2024-07-01", Today())
+
+	defer Set(Fixed(time.Date(2024, 7, 1, 22, 0, 0, 0, time.UTC)))()
+	assert.Equal(t, "2024-07-02", Today())
+}
+
+func TestSetRestores(t *testing.T) {
+	fixed := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	restore := Set(Fixed(fixed))
+	assert.True(t, Now().Equal(fixed))
+	restore()
+	assert.WithinDuration(t, time.Now(), Now(), time.Minute)
+}
+
+func TestStartOfDayAcrossClockChanges(t *testing.T) {
+	tests := []struct {
+		name      string
+		at        time.Time
+		start     time.Time
+		dayLength time.Duration
+	}{
+		{"spring forward", time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC), time.Date(2024, 3, 30, 23, 0, 0, 0, time.UTC), 23 * time.Hour},
+		{"fall back", time.Date(2024, 10, 27, 12, 0, 0, 0, time.UTC), time.Date(2024, 10, 26, 22, 0, 0, 0, time.UTC), 25 * time.Hour},
+		{"summer", time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC), time.Date(2024, 6, 30, 22, 0, 0, 0, time.UTC), 24 * time.Hour},
+		{"late UTC evening", time.Date(2024, 7, 1, 23, 0, 0, 0, time.UTC), time.Date(2024, 7, 1, 22, 0, 0, 0, time.UTC), 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		start := StartOfDay(tt.at)
+		assert.True(t, start.Equal(tt.start), "%s: start %s", tt.name, start)
+		assert.Equal(t, tt.dayLength, AddDays(start, 1).Sub(start), tt.name)
+		assert.True(t, StartOfDay(AddDays(start, 1)).Equal(AddDays(start, 1)), "%s: next day starts at midnight", tt.name)
+	}
+}
+
+func TestAddDaysKeepsWallClock(t *testing.T) {
+	// 06:30 in Zagreb on the Saturday before each change
+	before := time.Date(2024, 3, 30, 5, 30, 0, 0, time.UTC)
+	after := AddDays(before, 7)
+	assert.Equal(t, 7*24*time.Hour-time.Hour, after.Sub(before))
+	assert.Equal(t, 6, after.Hour())
+	assert.Equal(t, 30, after.Minute())
+
+	before = time.Date(2024, 10, 26, 4, 30, 0, 0, time.UTC)
+	after = AddDays(before, 7)
+	assert.Equal(t, 7*24*time.Hour+time.Hour, after.Sub(before))
+	assert.Equal(t, 6, after.Hour())
+
+	assert.True(t, AddDays(after, -7).Equal(before))
+}
+
+func TestParseDate(t *testing.T) {
+	day, err := ParseDate("2024-10-27")
+	require.NoError(t, err)
+	assert.True(t, day.Equal(time.Date(2024, 10, 26, 22, 0, 0, 0, time.UTC)))
+
+	_, err = ParseDate("27.10.2024")
+	assert.Error(t, err)
+}
+
+func TestDateStart(t *testing.T) {
+	assert.True(t, IsDate(springForward))
+	assert.False(t, IsDate(springForward.Add(time.Second)))
+	assert.False(t, IsDate(DateStart(springForward)))
+
+	assert.True(t, DateStart(springForward).Equal(time.Date(2024, 3, 30, 23, 0, 0, 0, time.UTC)))
+	assert.True(t, DateStart(fallBack).Equal(time.Date(2024, 10, 26, 22, 0, 0, 0, time.UTC)))
+
+	// The day after a clock change starts at midnight of the new offset
+	assert.True(t, DateStart(springForward.AddDate(0, 0, 1)).Equal(time.Date(2024, 3, 31, 22, 0, 0, 0, time.UTC)))
+	assert.True(t, DateStart(fallBack.AddDate(0, 0, 1)).Equal(time.Date(2024, 10, 27, 23, 0, 0, 0, time.UTC)))
+}
+
+func TestSetZone(t *testing.T) {
+	defer func(previous *time.Location) { zone.Store(previous) }(Zone())
+
+	require.NoError(t, SetZone("UTC"))
+	assert.True(t, DateStart(fallBack).Equal(fallBack))
+	assert.Equal(t, 24*time.Hour, AddDays(fallBack, 1).Sub(fallBack))
+
+	assert.Error(t, SetZone("Europe/Atlantis"))
+	assert.Equal(t, "UTC", Zone().String())
+}
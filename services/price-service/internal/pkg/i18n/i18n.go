@@ -0,0 +1,101 @@
+// Package i18n translates the user-facing messages of responses, such as
+// validation errors and why a basket item is missing, into the language a
+// request accepts.
+//
+// Messages are looked up by key in catalogs embedded in the binary, one JSON
+// file per language. A key missing from the requested language falls back to
+// English, and a key missing from every catalog is returned as is. Messages
+// name their arguments in braces, e.g. "{field} is required".
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+//go:embed catalogs/*.json
+var catalogFiles embed.FS
+
+// Supported are the languages with a catalog. The first is the fallback.
+var Supported = []language.Tag{language.English, language.Croatian}
+
+// Args are the named arguments of a message
+type Args map[string]any
+
+var (
+	matcher  = language.NewMatcher(Supported)
+	catalogs = mustLoadCatalogs()
+)
+
+// mustLoadCatalogs reads the catalog of each supported language
+func mustLoadCatalogs() map[language.Tag]map[string]string {
+	loaded := make(map[language.Tag]map[string]string, len(Supported))
+	for _, tag := range Supported {
+		data, err := catalogFiles.ReadFile("catalogs/" + tag.String() + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("i18n: missing catalog for %s: %v", tag, err))
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog for %s: %v", tag, err))
+		}
+		loaded[tag] = messages
+	}
+	return loaded
+}
+
+// Negotiate returns the supported language that best matches an
+// Accept-Language header, e.g. Croatian for "hr-HR,hr;q=0.9,en;q=0.8". An
+// empty or invalid header, or one accepting no supported language, gets
+// English.
+func Negotiate(acceptLanguage string) language.Tag {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return Supported[0]
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return Supported[0]
+	}
+	return Supported[index]
+}
+
+type contextKey struct{}
+
+// WithLanguage returns a context carrying the language of a request
+func WithLanguage(ctx context.Context, tag language.Tag) context.Context {
+	return context.WithValue(ctx, contextKey{}, tag)
+}
+
+// FromContext returns the language of a request, English when not set
+func FromContext(ctx context.Context) language.Tag {
+	if tag, ok := ctx.Value(contextKey{}).(language.Tag); ok {
+		return tag
+	}
+	return Supported[0]
+}
+
+// Translate returns the message of a key in a language with its arguments
+// filled in.
+func Translate(tag language.Tag, key string, args Args) string {
+	message, ok := catalogs[tag][key]
+	if !ok {
+		if message, ok = catalogs[Supported[0]][key]; !ok {
+			message = key
+		}
+	}
+	for name, value := range args {
+		message = strings.ReplaceAll(message, "{"+name+"}", fmt.Sprint(value))
+	}
+	return message
+}
+
+// T returns the message of a key in the language of a request's context.
+func T(ctx context.Context, key string, args Args) string {
+	return Translate(FromContext(ctx), key, args)
+}
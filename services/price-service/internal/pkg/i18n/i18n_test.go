@@ -0,0 +1,72 @@
+package i18n
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   language.Tag
+	}{
+		{"", language.English},
+		{"hr", language.Croatian},
+		{"hr-HR,hr;q=0.9,en;q=0.8", language.Croatian},
+		{"en-US,en;q=0.9,hr;q=0.8", language.English},
+		{"de-DE,hr;q=0.5", language.Croatian},
+		{"de-DE", language.English},
+		{"*", language.English},
+		{"not a header;q=x", language.English},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Negotiate(tt.header), "header %q", tt.header)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	args := Args{"field": "limit", "param": 50}
+	assert.Equal(t, "limit must be at most 50", Translate(language.English, "validation.max", args))
+	assert.Equal(t, "limit smije biti najviše 50", Translate(language.Croatian, "validation.max", args))
+	assert.Equal(t, "no.such.key", Translate(language.Croatian, "no.such.key", nil), "unknown keys as is")
+
+	ctx := WithLanguage(context.Background(), language.Croatian)
+	assert.Equal(t, "Nije dostupno u ovoj trgovini", T(ctx, "missing.not_at_store", nil))
+	assert.Equal(t, "Not available at this store", T(context.Background(), "missing.not_at_store", nil))
+}
+
+// TestTranslateFallsBackToEnglish verifies a key only English has is served
+// in English.
+func TestTranslateFallsBackToEnglish(t *testing.T) {
+	catalogs[language.English]["test.only_english"] = "Only {what}"
+	t.Cleanup(func() { delete(catalogs[language.English], "test.only_english") })
+
+	assert.Equal(t, "Only English", Translate(language.Croatian, "test.only_english", Args{"what": "English"}))
+}
+
+// TestCatalogsMatch verifies every catalog has the keys of the English one,
+// with the same arguments.
+func TestCatalogsMatch(t *testing.T) {
+	placeholder := regexp.MustCompile(`\{\w+\}`)
+	arguments := func(message string) []string {
+		names := placeholder.FindAllString(message, -1)
+		slices.Sort(names)
+		return names
+	}
+
+	english := catalogs[Supported[0]]
+	for _, tag := range Supported[1:] {
+		assert.Len(t, catalogs[tag], len(english), "%s has other keys than English", tag)
+		for key, message := range english {
+			translated, ok := catalogs[tag][key]
+			if assert.True(t, ok, "%s is missing %s", tag, key) {
+				assert.Equal(t, arguments(message), arguments(translated), "arguments of %s in %s", key, tag)
+			}
+		}
+	}
+}
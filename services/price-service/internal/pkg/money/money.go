@@ -0,0 +1,95 @@
+// Package money rounds, formats and compares euro amounts.
+//
+// Amounts are integer cents everywhere in the service. Conversions from
+// decimal euros round half away from zero, as shelf prices are rounded, and
+// display strings follow Croatian conventions: a dot between thousands, a
+// comma before the cents and the euro sign after the amount ("1.234,56 €").
+package money
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Symbol is the currency symbol appended to formatted amounts
+const Symbol = "€"
+
+// Round rounds a fractional amount of cents, such as a computed unit price
+// or average, to whole cents, half away from zero
+func Round(cents float64) int64 {
+	return int64(math.Round(cents))
+}
+
+// FromEuros converts decimal euros to cents, rounding half away from zero.
+// The amount is scaled through its decimal representation so values like
+// 1.005 round as written rather than as their nearest binary fraction.
+func FromEuros(euros float64) int64 {
+	scaled, err := strconv.ParseFloat(strconv.FormatFloat(euros, 'f', -1, 64)+"e2", 64)
+	if err != nil {
+		scaled = euros * 100
+	}
+	return Round(scaled)
+}
+
+// FormatAmount formats cents as a decimal amount without the currency symbol,
+// e.g. 123456 -> "1.234,56"
+func FormatAmount(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+
+	whole := strconv.FormatInt(cents/100, 10)
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(digit)
+	}
+	fraction := cents % 100
+	b.WriteByte(',')
+	b.WriteByte(byte('0' + fraction/10))
+	b.WriteByte(byte('0' + fraction%10))
+	return b.String()
+}
+
+// Format formats cents as a euro amount, e.g. 123456 -> "1.234,56 €"
+func Format(cents int64) string {
+	return FormatAmount(cents) + " " + Symbol
+}
+
+// DiscountPercent returns how much cheaper a discounted price is than the
+// regular price, in whole percent rounded half up. Returns false when the
+// discounted price is no discount.
+func DiscountPercent(price, discountPrice int64) (int, bool) {
+	savings, ok := Savings(price, discountPrice)
+	if !ok {
+		return 0, false
+	}
+	percent := int(Round(float64(savings) * 100 / float64(price)))
+	if percent == 0 {
+		// A discount always shows, however small
+		percent = 1
+	}
+	return percent, true
+}
+
+// FormatDiscount formats a discount percentage as shown on shelf labels,
+// e.g. 25 -> "-25%"
+func FormatDiscount(percent int) string {
+	return "-" + strconv.Itoa(percent) + "%"
+}
+
+// Savings returns how many cents cheaper a discounted price is than the
+// regular price. Returns false when the discounted price is no discount, as
+// DiscountPercent does.
+func Savings(price, discountPrice int64) (int64, bool) {
+	if price <= 0 || discountPrice < 0 || discountPrice >= price {
+		return 0, false
+	}
+	return price - discountPrice, true
+}
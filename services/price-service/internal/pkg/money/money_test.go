@@ -0,0 +1,67 @@
+package money
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		cents int64
+		want  string
+	}{
+		{0, "0,00 €"},
+		{5, "0,05 €"},
+		{199, "1,99 €"},
+		{100000, "1.000,00 €"},
+		{123456789, "1.234.567,89 €"},
+		{-150, "-1,50 €"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Format(tt.cents), "cents %d", tt.cents)
+	}
+	assert.Equal(t, "12,99", FormatAmount(1299))
+}
+
+func TestRounding(t *testing.T) {
+	assert.Equal(t, int64(13), Round(12.5))
+	assert.Equal(t, int64(-13), Round(-12.5))
+	assert.Equal(t, int64(12), Round(12.49))
+
+	assert.Equal(t, int64(101), FromEuros(1.005))
+	assert.Equal(t, int64(1299), FromEuros(12.99))
+	assert.Equal(t, int64(-250), FromEuros(-2.5))
+}
+
+func TestDiscountPercent(t *testing.T) {
+	tests := []struct {
+		price, discount int64
+		want            int
+		ok              bool
+	}{
+		{400, 300, 25, true},
+		{299, 199, 33, true},
+		{1000, 999, 1, true},
+		{100, 100, 0, false},
+		{100, 120, 0, false},
+		{0, 0, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := DiscountPercent(tt.price, tt.discount)
+		assert.Equal(t, tt.ok, ok, "%d -> %d", tt.price, tt.discount)
+		assert.Equal(t, tt.want, got, "%d -> %d", tt.price, tt.discount)
+	}
+	assert.Equal(t, "-25%", FormatDiscount(25))
+}
+
+func TestSavings(t *testing.T) {
+	savings, ok := Savings(299, 199)
+	assert.True(t, ok)
+	assert.Equal(t, int64(100), savings)
+
+	for _, prices := range [][2]int64{{100, 100}, {100, 120}, {0, 0}, {100, -1}} {
+		_, ok := Savings(prices[0], prices[1])
+		assert.False(t, ok, "%d -> %d", prices[0], prices[1])
+	}
+}
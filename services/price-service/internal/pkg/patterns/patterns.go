@@ -0,0 +1,39 @@
+// Package patterns caches compiled regular expressions.
+//
+// Fixed patterns belong in package-level variables. Patterns built at run time
+// (from adapter configuration or discovered file extensions) are compiled
+// through this cache instead, so each distinct expression is compiled once per
+// process no matter how often the code building it runs.
+package patterns
+
+import (
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+var cache sync.Map // expr -> *regexp.Regexp
+
+// Compile returns the compiled expression, compiling and caching it on first
+// use. Invalid expressions are not cached.
+func Compile(expr string) (*regexp.Regexp, error) {
+	if re, ok := cache.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := cache.LoadOrStore(expr, re)
+	return actual.(*regexp.Regexp), nil
+}
+
+// MustCompile is like Compile but panics if the expression cannot be parsed
+func MustCompile(expr string) *regexp.Regexp {
+	re, err := Compile(expr)
+	if err != nil {
+		panic("patterns: Compile(" + strconv.Quote(expr) + "): " + err.Error())
+	}
+	return re
+}
@@ -0,0 +1,25 @@
+package patterns
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileCaches(t *testing.T) {
+	first, err := Compile(`\.(csv|zip)$`)
+	require.NoError(t, err)
+	second := MustCompile(`\.(csv|zip)$`)
+	assert.Same(t, first, second)
+	assert.True(t, second.MatchString("cjenik.zip"))
+}
+
+func TestCompileInvalid(t *testing.T) {
+	_, err := Compile(`href=["'(`)
+	assert.Error(t, err)
+	_, cached := cache.Load(`href=["'(`)
+	assert.False(t, cached)
+
+	assert.Panics(t, func() { MustCompile(`(`) })
+}
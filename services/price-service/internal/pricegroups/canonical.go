@@ -0,0 +1,123 @@
+package pricegroups
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CanonicalHashVersion is the hash version whose input canonicalization is
+// configurable with HashOptions
+const CanonicalHashVersion = 2
+
+// HashOptions selects the hash version and, for version 2, how prices are
+// canonicalized before hashing
+type HashOptions struct {
+	// Version is 1 (ComputePriceHash) or 2 (canonicalized)
+	Version int
+	// IgnoreDiscountEnd leaves discount end dates out of the hash, so stores
+	// whose open-ended promotions are republished with a rolling end date
+	// keep their group
+	IgnoreDiscountEnd bool
+	// PriceBucketCents rounds prices and discount prices to the nearest
+	// multiple of this many cents before hashing; 0 or 1 hashes exact cents.
+	// Stores whose prices differ by less than a bucket share a group, which
+	// carries the prices of the store that created it.
+	PriceBucketCents int
+	// SortByItemID orders entries by item ID alone and keeps one entry per
+	// item, so duplicate rows of an item do not change the hash
+	SortByItemID bool
+}
+
+// DefaultHashOptions returns the options of the current hash version
+func DefaultHashOptions() HashOptions {
+	return HashOptions{Version: HashVersion}
+}
+
+// EffectiveVersion returns the hash version ComputePriceHashWith applies,
+// stored with the groups it creates
+func (o HashOptions) EffectiveVersion() int {
+	if o.Version >= CanonicalHashVersion {
+		return CanonicalHashVersion
+	}
+	return HashVersion
+}
+
+// ComputePriceHashWith computes the price hash of the version selected by
+// opts. Version 2 hashes one line per item of
+// "item_id:price:discount:discount_start:discount_end", with the discount
+// window as UTC dates ("N" when unset, "-" when ignored) and empty for items
+// without a discount.
+func ComputePriceHashWith(prices []ItemPrice, opts HashOptions) string {
+	if opts.EffectiveVersion() < CanonicalHashVersion {
+		return ComputePriceHash(prices)
+	}
+
+	type entry struct {
+		itemID string
+		line   string
+	}
+	entries := make([]entry, 0, len(prices))
+	for _, p := range prices {
+		itemID := strings.ToLower(p.ItemID)
+		entries = append(entries, entry{itemID: itemID, line: canonicalLine(itemID, p, opts)})
+	}
+
+	// Entries are ordered by item ID, then by line, so the order is total
+	// and a deduplicated item keeps its lowest line
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].itemID != entries[j].itemID {
+			return entries[i].itemID < entries[j].itemID
+		}
+		return entries[i].line < entries[j].line
+	})
+
+	var buf bytes.Buffer
+	for i, e := range entries {
+		if opts.SortByItemID && i > 0 && entries[i-1].itemID == e.itemID {
+			continue
+		}
+		buf.WriteString(e.line)
+		buf.WriteByte('\n')
+	}
+
+	hash := sha256.Sum256(buf.Bytes())
+	return hex.EncodeToString(hash[:])
+}
+
+// canonicalLine returns the version 2 hash line of an item price
+func canonicalLine(itemID string, p ItemPrice, opts HashOptions) string {
+	price := bucketPrice(p.Price, opts.PriceBucketCents)
+	if p.DiscountPrice == nil {
+		return fmt.Sprintf("%s:%d:%s::", itemID, price, nullDiscountSentinel)
+	}
+
+	end := "-"
+	if !opts.IgnoreDiscountEnd {
+		end = canonicalDate(p.DiscountEnd)
+	}
+	return fmt.Sprintf("%s:%d:%s:%s:%s", itemID, price,
+		strconv.Itoa(bucketPrice(*p.DiscountPrice, opts.PriceBucketCents)),
+		canonicalDate(p.DiscountStart), end)
+}
+
+// bucketPrice rounds cents half up to the nearest multiple of bucket
+func bucketPrice(cents, bucket int) int {
+	if bucket <= 1 {
+		return cents
+	}
+	return (cents + bucket/2) / bucket * bucket
+}
+
+// canonicalDate formats a discount date as its UTC day, "N" when unset
+func canonicalDate(t *time.Time) string {
+	if t == nil {
+		return nullDiscountSentinel
+	}
+	return t.UTC().Format("2006-01-02")
+}
@@ -0,0 +1,123 @@
+package pricegroups
+
+import (
+	"testing"
+	"time"
+)
+
+func v2Options() HashOptions {
+	return HashOptions{Version: CanonicalHashVersion, IgnoreDiscountEnd: true, PriceBucketCents: 1, SortByItemID: true}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestComputePriceHashWithVersion1MatchesOriginal(t *testing.T) {
+	prices := []ItemPrice{
+		{ItemID: "item-1", Price: 1000, DiscountPrice: intPtr(800), DiscountEnd: timePtr(time.Now())},
+		{ItemID: "item-2", Price: 250},
+	}
+
+	if got, want := ComputePriceHashWith(prices, DefaultHashOptions()), ComputePriceHash(prices); got != want {
+		t.Errorf("version 1 hash = %s, want %s", got, want)
+	}
+	if ComputePriceHashWith(prices, v2Options()) == ComputePriceHash(prices) {
+		t.Error("version 2 hash should differ from version 1")
+	}
+}
+
+func TestComputePriceHashWithDiscountWindow(t *testing.T) {
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	item := func(end time.Time) []ItemPrice {
+		return []ItemPrice{{ItemID: "item-1", Price: 1000, DiscountPrice: intPtr(800), DiscountStart: &start, DiscountEnd: &end}}
+	}
+	today := item(time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC))
+	tomorrow := item(time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC))
+
+	opts := v2Options()
+	if ComputePriceHashWith(today, opts) != ComputePriceHashWith(tomorrow, opts) {
+		t.Error("rolling discount end date should not change the hash when ignored")
+	}
+
+	opts.IgnoreDiscountEnd = false
+	if ComputePriceHashWith(today, opts) == ComputePriceHashWith(tomorrow, opts) {
+		t.Error("discount end date should change the hash when not ignored")
+	}
+
+	later := []ItemPrice{{ItemID: "item-1", Price: 1000, DiscountPrice: intPtr(800), DiscountStart: timePtr(start.AddDate(0, 0, 1))}}
+	if ComputePriceHashWith(today, v2Options()) == ComputePriceHashWith(later, v2Options()) {
+		t.Error("discount start date should change the hash")
+	}
+}
+
+func TestComputePriceHashWithPriceBucket(t *testing.T) {
+	a := []ItemPrice{{ItemID: "item-1", Price: 1001}}
+	b := []ItemPrice{{ItemID: "item-1", Price: 1002}}
+
+	opts := v2Options()
+	if ComputePriceHashWith(a, opts) == ComputePriceHashWith(b, opts) {
+		t.Error("exact cents should distinguish 10.01 and 10.02")
+	}
+	opts.PriceBucketCents = 5
+	if ComputePriceHashWith(a, opts) != ComputePriceHashWith(b, opts) {
+		t.Error("5 cent buckets should group 10.01 and 10.02")
+	}
+
+	tests := []struct{ cents, bucket, want int }{
+		{1002, 5, 1000},
+		{1003, 5, 1005},
+		{1049, 100, 1000},
+		{1050, 100, 1100},
+		{1003, 0, 1003},
+	}
+	for _, tt := range tests {
+		if got := bucketPrice(tt.cents, tt.bucket); got != tt.want {
+			t.Errorf("bucketPrice(%d, %d) = %d, want %d", tt.cents, tt.bucket, got, tt.want)
+		}
+	}
+}
+
+func TestComputePriceHashWithSortByItemID(t *testing.T) {
+	single := []ItemPrice{{ItemID: "item-1", Price: 100}}
+	duplicated := []ItemPrice{{ItemID: "ITEM-1", Price: 200}, {ItemID: "item-1", Price: 100}}
+
+	opts := v2Options()
+	if ComputePriceHashWith(single, opts) != ComputePriceHashWith(duplicated, opts) {
+		t.Error("duplicate item should collapse to its lowest entry")
+	}
+	opts.SortByItemID = false
+	if ComputePriceHashWith(single, opts) == ComputePriceHashWith(duplicated, opts) {
+		t.Error("duplicate item should change the hash without sort by item ID")
+	}
+	reversed := []ItemPrice{duplicated[1], duplicated[0]}
+	if ComputePriceHashWith(duplicated, opts) != ComputePriceHashWith(reversed, opts) {
+		t.Error("order of duplicate items should not change the hash")
+	}
+}
+
+func TestSimulation(t *testing.T) {
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	withEnd := func(day int) []ItemPrice {
+		return []ItemPrice{{ItemID: "item-1", Price: 1000, DiscountPrice: intPtr(800), DiscountStart: &start, DiscountEnd: timePtr(start.AddDate(0, 0, day))}}
+	}
+
+	sim := NewSimulation(v2Options())
+	sim.Add("konzum", 3, withEnd(10))
+	sim.Add("konzum", 2, withEnd(11))
+	sim.Add("konzum", 1, []ItemPrice{{ItemID: "item-1", Price: 1000}})
+	sim.Add("lidl", 4, withEnd(10))
+
+	results := sim.Results()
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	want := SimulationResult{ChainSlug: "konzum", Groups: 3, Stores: 6, SimulatedGroups: 2, MergingGroups: 2, MergingStores: 5}
+	if results[0] != want {
+		t.Errorf("konzum = %+v, want %+v", results[0], want)
+	}
+	want = SimulationResult{ChainSlug: "lidl", Groups: 1, Stores: 4, SimulatedGroups: 1}
+	if results[1] != want {
+		t.Errorf("lidl = %+v, want %+v", results[1], want)
+	}
+}
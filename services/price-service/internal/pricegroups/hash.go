@@ -8,6 +8,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -24,6 +25,10 @@ type ItemPrice struct {
 	ItemID        string  // UUID (will be normalized to lowercase for hashing)
 	Price         int     // cents, NOT NULL
 	DiscountPrice *int    // cents, nullable (NULL ≠ 0!)
+
+	// Discount window, hashed by version 2 only
+	DiscountStart *time.Time
+	DiscountEnd   *time.Time
 }
 
 // ComputePriceHash computes a deterministic hash of a set of item prices
@@ -0,0 +1,65 @@
+package pricegroups
+
+import "sort"
+
+// SimulationResult reports, for one chain, how its current price groups
+// would regroup under other hash options
+type SimulationResult struct {
+	ChainSlug string `json:"chainSlug"`
+	// Groups is the number of groups with current member stores
+	Groups int `json:"groups"`
+	// Stores is the number of current member stores
+	Stores int `json:"stores"`
+	// SimulatedGroups is the number of groups under the simulated options
+	SimulatedGroups int `json:"simulatedGroups"`
+	// MergingGroups is the number of current groups that would share a
+	// group with at least one other
+	MergingGroups int `json:"mergingGroups"`
+	// MergingStores is the number of member stores of the merging groups
+	MergingStores int `json:"mergingStores"`
+}
+
+// Simulation regroups price groups under hash options, one group at a time
+type Simulation struct {
+	opts   HashOptions
+	chains map[string]map[string][]int // chain -> simulated hash -> store counts
+}
+
+// NewSimulation creates a simulation of the given hash options
+func NewSimulation(opts HashOptions) *Simulation {
+	return &Simulation{opts: opts, chains: make(map[string]map[string][]int)}
+}
+
+// Add hashes a current group's prices under the simulated options
+func (s *Simulation) Add(chainSlug string, storeCount int, prices []ItemPrice) {
+	hashes := s.chains[chainSlug]
+	if hashes == nil {
+		hashes = make(map[string][]int)
+		s.chains[chainSlug] = hashes
+	}
+	hash := ComputePriceHashWith(prices, s.opts)
+	hashes[hash] = append(hashes[hash], storeCount)
+}
+
+// Results returns the regrouping of each chain, by chain slug
+func (s *Simulation) Results() []SimulationResult {
+	results := make([]SimulationResult, 0, len(s.chains))
+	for chainSlug, hashes := range s.chains {
+		r := SimulationResult{ChainSlug: chainSlug, SimulatedGroups: len(hashes)}
+		for _, storeCounts := range hashes {
+			stores := 0
+			for _, n := range storeCounts {
+				stores += n
+			}
+			r.Groups += len(storeCounts)
+			r.Stores += stores
+			if len(storeCounts) > 1 {
+				r.MergingGroups += len(storeCounts)
+				r.MergingStores += stores
+			}
+		}
+		results = append(results, r)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].ChainSlug < results[j].ChainSlug })
+	return results
+}
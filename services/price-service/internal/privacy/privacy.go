@@ -0,0 +1,55 @@
+// Package privacy holds the handling rules for user-adjacent data such as
+// the caller locations sent with basket optimization requests.
+//
+// Caller coordinates are personal data: they are rounded before they reach
+// logs, metrics or storage, and stored optimization results are keyed by the
+// caller-provided user reference so they can be exported and deleted.
+package privacy
+
+import (
+	"math"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+// DefaultCoordinatePrecision keeps two decimal places, about 1 km
+const DefaultCoordinatePrecision = 2
+
+var precision atomic.Int32
+
+func init() {
+	precision.Store(DefaultCoordinatePrecision)
+}
+
+// SetCoordinatePrecision sets how many decimal places RoundCoordinate keeps;
+// a negative value disables rounding
+func SetCoordinatePrecision(decimals int) {
+	precision.Store(int32(decimals))
+}
+
+// CoordinatePrecision returns the configured number of decimal places
+func CoordinatePrecision() int {
+	return int(precision.Load())
+}
+
+// RoundCoordinate rounds a latitude or longitude to the configured precision
+func RoundCoordinate(v float64) float64 {
+	p := CoordinatePrecision()
+	if p < 0 {
+		return v
+	}
+	scale := math.Pow(10, float64(p))
+	return math.Round(v*scale) / scale
+}
+
+// RoundLocation rounds both coordinates of a location
+func RoundLocation(lat, lon float64) (float64, float64) {
+	return RoundCoordinate(lat), RoundCoordinate(lon)
+}
+
+// LogLocation adds a rounded location to a log event
+func LogLocation(e *zerolog.Event, lat, lon float64) *zerolog.Event {
+	lat, lon = RoundLocation(lat, lon)
+	return e.Float64("lat", lat).Float64("lon", lon)
+}
@@ -0,0 +1,21 @@
+package privacy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundCoordinate(t *testing.T) {
+	t.Cleanup(func() { SetCoordinatePrecision(DefaultCoordinatePrecision) })
+
+	lat, lon := RoundLocation(45.813177, 15.977048)
+	assert.Equal(t, 45.81, lat)
+	assert.Equal(t, 15.98, lon)
+
+	SetCoordinatePrecision(1)
+	assert.Equal(t, -43.5, RoundCoordinate(-43.508))
+
+	SetCoordinatePrecision(-1)
+	assert.Equal(t, 45.813177, RoundCoordinate(45.813177))
+}
@@ -0,0 +1,199 @@
+package profiling
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kosarica/price-service/internal/storage"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog"
+)
+
+// Conditions that trigger an automatic capture
+const (
+	TriggerHeapThreshold = "heap_threshold"
+	TriggerOptimizeP99   = "optimize_p99"
+)
+
+// profileKeyPrefix is where captured profiles are stored
+const profileKeyPrefix = "profiles"
+
+var profilerCaptures = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "profiler_captures_total",
+	Help: "Total profiles captured automatically, by triggering condition",
+}, []string{"trigger"})
+
+// AutoConfig holds the thresholds of the automatic profiler
+type AutoConfig struct {
+	CheckInterval      time.Duration // How often the conditions are checked
+	HeapThresholdBytes uint64        // Heap in use above which a heap profile is captured; 0 disables
+	OptimizeP99Budget  time.Duration // Optimize p99 above which a CPU profile is captured; 0 disables
+	MinSamples         int           // Optimize requests needed before the p99 is trusted
+	CPUDuration        time.Duration // Length of a CPU profile
+	Cooldown           time.Duration // Minimum time between captures of the same trigger
+}
+
+// AutoProfiler periodically checks heap usage and optimize latency and stores
+// a heap or CPU profile when either is over budget, tagged with the condition
+type AutoProfiler struct {
+	cfg      AutoConfig
+	store    storage.Storage
+	logger   *zerolog.Logger
+	latency  *LatencyWindow
+	readHeap func() uint64
+	now      func() time.Time
+	stopChan chan struct{}
+
+	mu          sync.Mutex
+	lastCapture map[string]time.Time
+}
+
+// NewAutoProfiler creates an automatic profiler storing captures in store
+func NewAutoProfiler(cfg AutoConfig, store storage.Storage, logger *zerolog.Logger) *AutoProfiler {
+	if cfg.MinSamples <= 0 {
+		cfg.MinSamples = 100
+	}
+	return &AutoProfiler{
+		cfg:         cfg,
+		store:       store,
+		logger:      logger,
+		latency:     OptimizeLatency,
+		readHeap:    heapInUse,
+		now:         time.Now,
+		stopChan:    make(chan struct{}),
+		lastCapture: make(map[string]time.Time),
+	}
+}
+
+// heapInUse returns the bytes of allocated heap objects
+func heapInUse() uint64 {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+// Start checks the conditions on every interval until stopped
+func (p *AutoProfiler) Start(ctx context.Context) {
+	p.logger.Info().
+		Uint64("heapThresholdBytes", p.cfg.HeapThresholdBytes).
+		Dur("optimizeP99Budget", p.cfg.OptimizeP99Budget).
+		Dur("interval", p.cfg.CheckInterval).
+		Msg("Starting automatic profiler")
+
+	ticker := time.NewTicker(p.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.Info().Msg("Automatic profiler stopping (context cancelled)")
+			return
+		case <-p.stopChan:
+			p.logger.Info().Msg("Automatic profiler stopping (stop signal)")
+			return
+		case <-ticker.C:
+			p.Check(ctx)
+		}
+	}
+}
+
+// Stop signals the profiler to stop
+func (p *AutoProfiler) Stop() {
+	close(p.stopChan)
+}
+
+// Check captures a heap profile if the heap is over its threshold and a CPU
+// profile if the optimize p99 is over budget, unless the same trigger
+// captured within the cooldown. Failures are logged.
+func (p *AutoProfiler) Check(ctx context.Context) {
+	if p.cfg.HeapThresholdBytes > 0 {
+		if heap := p.readHeap(); heap > p.cfg.HeapThresholdBytes {
+			p.capture(ctx, "heap", TriggerHeapThreshold,
+				strconv.FormatUint(heap, 10), strconv.FormatUint(p.cfg.HeapThresholdBytes, 10))
+		}
+	}
+
+	if p.cfg.OptimizeP99Budget > 0 {
+		p99, n := p.latency.Quantile(0.99)
+		if n >= p.cfg.MinSamples && p99 > p.cfg.OptimizeP99Budget {
+			p.capture(ctx, "cpu", TriggerOptimizeP99, p99.String(), p.cfg.OptimizeP99Budget.String())
+		}
+	}
+}
+
+// capture records a profile of the given kind and stores it
+func (p *AutoProfiler) capture(ctx context.Context, kind, trigger, value, threshold string) {
+	now := p.now()
+	p.mu.Lock()
+	if last, ok := p.lastCapture[trigger]; ok && now.Sub(last) < p.cfg.Cooldown {
+		p.mu.Unlock()
+		return
+	}
+	p.lastCapture[trigger] = now
+	p.mu.Unlock()
+
+	var buf bytes.Buffer
+	var err error
+	switch kind {
+	case "heap":
+		err = pprof.Lookup("heap").WriteTo(&buf, 0)
+	case "cpu":
+		err = p.profileCPU(ctx, &buf)
+	}
+	if err != nil {
+		p.logger.Warn().Err(err).Str("trigger", trigger).Msg("Failed to capture profile")
+		return
+	}
+
+	key := fmt.Sprintf("%s/%s/%s-%s-%s.pb.gz", profileKeyPrefix,
+		now.UTC().Format("2006-01-02"), now.UTC().Format("150405"), kind, trigger)
+	hostname, _ := os.Hostname()
+	metadata := &storage.Metadata{
+		ContentType:  "application/octet-stream",
+		OriginalName: kind + ".pb.gz",
+		DownloadedAt: now,
+		Custom: map[string]string{
+			"kind":      kind,
+			"trigger":   trigger,
+			"value":     value,
+			"threshold": threshold,
+			"host":      hostname,
+		},
+	}
+	if err := p.store.Put(ctx, key, buf.Bytes(), metadata); err != nil {
+		p.logger.Warn().Err(err).Str("trigger", trigger).Msg("Failed to store profile")
+		return
+	}
+
+	profilerCaptures.WithLabelValues(trigger).Inc()
+	p.logger.Warn().
+		Str("trigger", trigger).
+		Str("value", value).
+		Str("threshold", threshold).
+		Str("key", key).
+		Msg("Captured profile")
+}
+
+// profileCPU writes a CPU profile of the configured duration to buf. It fails
+// if another CPU profile, such as one requested through pprof, is running.
+func (p *AutoProfiler) profileCPU(ctx context.Context, buf *bytes.Buffer) error {
+	if err := pprof.StartCPUProfile(buf); err != nil {
+		return err
+	}
+	timer := time.NewTimer(p.cfg.CPUDuration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+	pprof.StopCPUProfile()
+	return nil
+}
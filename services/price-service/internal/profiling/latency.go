@@ -0,0 +1,84 @@
+package profiling
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// optimizeLatencySamples is how many recent optimize requests the p99 is taken over
+const optimizeLatencySamples = 1000
+
+// OptimizeLatency holds the durations of recent optimize requests
+var OptimizeLatency = NewLatencyWindow(optimizeLatencySamples)
+
+// ObserveOptimize records an optimize request that started at start. Meant to
+// be deferred: defer profiling.ObserveOptimize(time.Now())
+func ObserveOptimize(start time.Time) {
+	OptimizeLatency.Observe(time.Since(start))
+}
+
+// LatencyWindow keeps the most recent durations in a ring buffer
+type LatencyWindow struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+	full    bool
+}
+
+// NewLatencyWindow creates a window of the given number of samples
+func NewLatencyWindow(size int) *LatencyWindow {
+	return &LatencyWindow{samples: make([]time.Duration, size)}
+}
+
+// Observe records a duration, replacing the oldest once the window is full
+func (w *LatencyWindow) Observe(d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.samples[w.next] = d
+	w.next++
+	if w.next == len(w.samples) {
+		w.next = 0
+		w.full = true
+	}
+}
+
+// Len returns the number of samples in the window
+func (w *LatencyWindow) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.full {
+		return len(w.samples)
+	}
+	return w.next
+}
+
+// Quantile returns the q-quantile (0 to 1) of the samples, by nearest rank,
+// and the number of samples it was taken over
+func (w *LatencyWindow) Quantile(q float64) (time.Duration, int) {
+	w.mu.Lock()
+	n := w.next
+	if w.full {
+		n = len(w.samples)
+	}
+	sorted := make([]time.Duration, n)
+	copy(sorted, w.samples[:n])
+	w.mu.Unlock()
+
+	if n == 0 {
+		return 0, 0
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := int(math.Ceil(q*float64(n))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= n {
+		rank = n - 1
+	}
+	return sorted[rank], n
+}
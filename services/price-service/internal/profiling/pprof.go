@@ -0,0 +1,27 @@
+// Package profiling exposes runtime profiles and captures them automatically
+// when the service is under memory or latency pressure.
+package profiling
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"time"
+)
+
+// NewServer returns an admin server serving the net/http/pprof endpoints under
+// /debug/pprof/. It listens on its own address so profiles are never reachable
+// through the public API port.
+func NewServer(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	return &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
@@ -0,0 +1,93 @@
+package profiling
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kosarica/price-service/internal/storage"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLatencyWindow tests quantiles over a wrapping window
+func TestLatencyWindow(t *testing.T) {
+	w := NewLatencyWindow(100)
+	p99, n := w.Quantile(0.99)
+	assert.Zero(t, p99)
+	assert.Zero(t, n)
+
+	for i := 1; i <= 100; i++ {
+		w.Observe(time.Duration(i) * time.Millisecond)
+	}
+	p99, n = w.Quantile(0.99)
+	assert.Equal(t, 99*time.Millisecond, p99)
+	assert.Equal(t, 100, n)
+
+	// Overwrite the 50 fastest with slow requests
+	for i := 0; i < 50; i++ {
+		w.Observe(time.Second)
+	}
+	p50, n := w.Quantile(0.5)
+	assert.Equal(t, 100*time.Millisecond, p50)
+	assert.Equal(t, 100, n)
+	assert.Equal(t, 100, w.Len())
+}
+
+// TestAutoProfilerCheck tests captures, tagging and cooldown
+func TestAutoProfilerCheck(t *testing.T) {
+	store, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	logger := zerolog.Nop()
+
+	p := NewAutoProfiler(AutoConfig{
+		HeapThresholdBytes: 1 << 30,
+		OptimizeP99Budget:  time.Second,
+		MinSamples:         10,
+		CPUDuration:        10 * time.Millisecond,
+		Cooldown:           time.Hour,
+	}, store, &logger)
+	p.latency = NewLatencyWindow(10)
+	now := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+	heap := uint64(1 << 29)
+	p.readHeap = func() uint64 { return heap }
+
+	ctx := context.Background()
+
+	t.Run("under budget", func(t *testing.T) {
+		p.Check(ctx)
+		keys, err := store.List(ctx, "profiles/")
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+	})
+
+	t.Run("heap over threshold", func(t *testing.T) {
+		heap = 2 << 30
+		p.Check(ctx)
+		info, err := store.GetInfo(ctx, "profiles/2026-03-01/123000-heap-heap_threshold.pb.gz")
+		require.NoError(t, err)
+		require.NotNil(t, info.Metadata)
+		assert.Equal(t, TriggerHeapThreshold, info.Metadata.Custom["trigger"])
+		assert.Equal(t, "2147483648", info.Metadata.Custom["value"])
+		assert.Equal(t, "1073741824", info.Metadata.Custom["threshold"])
+	})
+
+	t.Run("optimize p99 over budget", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			p.latency.Observe(3 * time.Second)
+		}
+		now = now.Add(time.Minute)
+		p.Check(ctx)
+
+		keys, err := store.List(ctx, "profiles/")
+		require.NoError(t, err)
+		assert.Len(t, keys, 2, "heap trigger is in cooldown")
+
+		info, err := store.GetInfo(ctx, "profiles/2026-03-01/123100-cpu-optimize_p99.pb.gz")
+		require.NoError(t, err)
+		assert.Equal(t, TriggerOptimizeP99, info.Metadata.Custom["trigger"])
+		assert.Equal(t, "3s", info.Metadata.Custom["value"])
+	})
+}
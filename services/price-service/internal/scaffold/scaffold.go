@@ -0,0 +1,371 @@
+// Package scaffold generates the boilerplate for onboarding a new retail chain.
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var templatesFS embed.FS
+
+var slugPattern = regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*$`)
+
+// Options describes the chain to scaffold
+type Options struct {
+	Slug     string // e.g. "mlinar"
+	Name     string // display name, defaults to the capitalized slug
+	FileType string // "csv", "xml" or "json"
+	BaseURL  string // price list portal URL
+}
+
+// templateData is passed to every template
+type templateData struct {
+	Options
+	Ident     string // exported Go identifier, e.g. "Mlinar"
+	Var       string // unexported Go identifier, e.g. "mlinar"
+	ConstName string // config constant, e.g. "ChainMlinar"
+	TypeName  string // types.FileType suffix, e.g. "CSV"
+}
+
+// Result lists the files created and modified by Run
+type Result struct {
+	Created  []string
+	Modified []string
+}
+
+// Run scaffolds a new chain inside the price-service module rooted at root.
+// It creates the adapter, testdata directory and golden test, and registers
+// the slug in the chain config, the adapter registry and chains.DefaultChains.
+func Run(root string, opts Options) (*Result, error) {
+	data, err := newTemplateData(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	adapterPath := filepath.Join(root, "internal", "adapters", "chains", strings.ReplaceAll(data.Slug, "-", "")+".go")
+	if _, err := os.Stat(adapterPath); err == nil {
+		return nil, fmt.Errorf("adapter %s already exists", adapterPath)
+	}
+
+	result := &Result{}
+
+	// Register the chain first so a half-finished scaffold never leaves an
+	// adapter referencing a missing config constant
+	edits := []struct {
+		path string
+		edit func(src []byte, data templateData) ([]byte, error)
+	}{
+		{filepath.Join(root, "internal", "adapters", "config", "config.go"), addChainConfig},
+		{filepath.Join(root, "internal", "adapters", "registry", "registry.go"), addRegistryEntries},
+		{filepath.Join(root, "internal", "chains", "chains.go"), addDefaultChain},
+	}
+	for _, e := range edits {
+		if err := editGoFile(e.path, data, e.edit); err != nil {
+			return result, err
+		}
+		result.Modified = append(result.Modified, e.path)
+	}
+
+	adapterTemplate := "adapter_" + data.FileType + ".go.tmpl"
+	if err := renderFile(adapterTemplate, adapterPath, data); err != nil {
+		return result, err
+	}
+	result.Created = append(result.Created, adapterPath)
+
+	testdataDir := filepath.Join(root, "tests", "testdata", data.Slug)
+	if err := os.MkdirAll(testdataDir, 0755); err != nil {
+		return result, fmt.Errorf("failed to create testdata directory: %w", err)
+	}
+	keep := filepath.Join(testdataDir, ".gitkeep")
+	if err := os.WriteFile(keep, nil, 0644); err != nil {
+		return result, fmt.Errorf("failed to create %s: %w", keep, err)
+	}
+	result.Created = append(result.Created, keep)
+
+	helpersPath := filepath.Join(root, "tests", "unit", "golden_helpers_test.go")
+	if _, err := os.Stat(helpersPath); os.IsNotExist(err) {
+		if err := renderFile("golden_helpers_test.go.tmpl", helpersPath, data); err != nil {
+			return result, err
+		}
+		result.Created = append(result.Created, helpersPath)
+	}
+
+	goldenPath := filepath.Join(root, "tests", "unit", data.Var+"_golden_test.go")
+	if err := renderFile("golden_test.go.tmpl", goldenPath, data); err != nil {
+		return result, err
+	}
+	result.Created = append(result.Created, goldenPath)
+
+	return result, nil
+}
+
+// newTemplateData validates options and derives identifiers from the slug
+func newTemplateData(opts Options) (templateData, error) {
+	if !slugPattern.MatchString(opts.Slug) {
+		return templateData{}, fmt.Errorf("invalid slug %q: use lowercase letters, digits and single hyphens", opts.Slug)
+	}
+
+	opts.FileType = strings.ToLower(opts.FileType)
+	if opts.FileType == "" {
+		opts.FileType = "csv"
+	}
+	if opts.FileType != "csv" && opts.FileType != "xml" && opts.FileType != "json" {
+		return templateData{}, fmt.Errorf("unsupported file type %q: use csv, xml or json", opts.FileType)
+	}
+
+	ident := ""
+	for _, part := range strings.Split(opts.Slug, "-") {
+		ident += strings.ToUpper(part[:1]) + part[1:]
+	}
+	if opts.Name == "" {
+		opts.Name = ident
+	}
+
+	return templateData{
+		Options:   opts,
+		Ident:     ident,
+		Var:       strings.ToLower(ident[:1]) + ident[1:],
+		ConstName: "Chain" + ident,
+		TypeName:  strings.ToUpper(opts.FileType),
+	}, nil
+}
+
+// renderFile executes a template into path, gofmt-ing Go output
+func renderFile(name, path string, data templateData) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+
+	out, err := execute(name, data)
+	if err != nil {
+		return err
+	}
+
+	if strings.HasSuffix(path, ".go") {
+		if out, err = format.Source(out); err != nil {
+			return fmt.Errorf("failed to format %s: %w", path, err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	return os.WriteFile(path, out, 0644)
+}
+
+// execute renders an embedded template
+func execute(name string, data templateData) ([]byte, error) {
+	tmpl, err := template.ParseFS(templatesFS, "templates/"+name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// editGoFile applies edit to a Go source file and writes back the formatted result
+func editGoFile(path string, data templateData, edit func([]byte, templateData) ([]byte, error)) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	out, err := edit(src, data)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", path, err)
+	}
+
+	if out, err = format.Source(out); err != nil {
+		return fmt.Errorf("failed to format %s: %w", path, err)
+	}
+
+	return os.WriteFile(path, out, 0644)
+}
+
+// insertion is a piece of text to splice in at a byte offset
+type insertion struct {
+	offset int
+	text   string
+}
+
+// splice applies insertions to src; offsets refer to the original source
+func splice(src []byte, inserts ...insertion) []byte {
+	// Apply from the end so earlier offsets stay valid
+	sort.Slice(inserts, func(i, j int) bool { return inserts[i].offset < inserts[j].offset })
+
+	out := src
+	for i := len(inserts) - 1; i >= 0; i-- {
+		ins := inserts[i]
+		out = append(out[:ins.offset:ins.offset], append([]byte(ins.text), out[ins.offset:]...)...)
+	}
+	return out
+}
+
+// addDefaultChain appends the slug to the list returned by chains.DefaultChains
+func addDefaultChain(src []byte, data templateData) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	fn := findFunc(file, "DefaultChains")
+	if fn == nil {
+		return nil, fmt.Errorf("func DefaultChains not found")
+	}
+
+	var lit *ast.CompositeLit
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if ret, ok := n.(*ast.ReturnStmt); ok && len(ret.Results) == 1 {
+			lit, _ = ret.Results[0].(*ast.CompositeLit)
+		}
+		return lit == nil
+	})
+	if lit == nil {
+		return nil, fmt.Errorf("DefaultChains does not return a slice literal")
+	}
+
+	if containsStringLit(lit, data.Slug) {
+		return nil, fmt.Errorf("chain %q is already in DefaultChains", data.Slug)
+	}
+
+	return splice(src, insertion{fset.Position(lit.Rbrace).Offset, fmt.Sprintf("%q,\n", data.Slug)}), nil
+}
+
+// addChainConfig adds the ChainID constant, ChainIDs entry and ChainConfigs entry
+func addChainConfig(src []byte, data templateData) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var constBlock *ast.GenDecl
+	var chainIDs, chainConfigs *ast.CompositeLit
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs, ok := spec.(*ast.ValueSpec)
+			if !ok || len(vs.Names) == 0 {
+				continue
+			}
+			switch name := vs.Names[0].Name; {
+			case gen.Tok == token.CONST && name == "ChainKonzum":
+				constBlock = gen
+			case gen.Tok == token.VAR && name == "ChainIDs" && len(vs.Values) == 1:
+				chainIDs, _ = vs.Values[0].(*ast.CompositeLit)
+			case gen.Tok == token.VAR && name == "ChainConfigs" && len(vs.Values) == 1:
+				chainConfigs, _ = vs.Values[0].(*ast.CompositeLit)
+			}
+			if gen.Tok == token.CONST && vs.Names[0].Name == data.ConstName {
+				return nil, fmt.Errorf("%s is already declared", data.ConstName)
+			}
+		}
+	}
+	if constBlock == nil || !constBlock.Rparen.IsValid() || chainIDs == nil || chainConfigs == nil {
+		return nil, fmt.Errorf("ChainID constants, ChainIDs or ChainConfigs not found")
+	}
+
+	configEntry, err := execute("chain_config.tmpl", data)
+	if err != nil {
+		return nil, err
+	}
+
+	return splice(src,
+		insertion{fset.Position(constBlock.Rparen).Offset, fmt.Sprintf("\t%s ChainID = %q\n", data.ConstName, data.Slug)},
+		insertion{fset.Position(chainIDs.Rbrace).Offset, fmt.Sprintf("\t%s,\n", data.ConstName)},
+		insertion{fset.Position(chainConfigs.Rbrace).Offset, string(configEntry)},
+	), nil
+}
+
+// addRegistryEntries wires the adapter into GetOrInit and InitializeDefaultAdapters
+func addRegistryEntries(src []byte, data templateData) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	getOrInit := findFunc(file, "GetOrInit")
+	initDefaults := findFunc(file, "InitializeDefaultAdapters")
+	if getOrInit == nil || initDefaults == nil {
+		return nil, fmt.Errorf("GetOrInit or InitializeDefaultAdapters not found")
+	}
+
+	// The default clause of the chain switch in GetOrInit
+	var defaultClause *ast.CaseClause
+	ast.Inspect(getOrInit.Body, func(n ast.Node) bool {
+		if cc, ok := n.(*ast.CaseClause); ok && cc.List == nil {
+			defaultClause = cc
+		}
+		return defaultClause == nil
+	})
+	if defaultClause == nil {
+		return nil, fmt.Errorf("default case not found in GetOrInit")
+	}
+
+	// The final return of InitializeDefaultAdapters
+	stmts := initDefaults.Body.List
+	if len(stmts) == 0 {
+		return nil, fmt.Errorf("InitializeDefaultAdapters is empty")
+	}
+	ret, ok := stmts[len(stmts)-1].(*ast.ReturnStmt)
+	if !ok {
+		return nil, fmt.Errorf("InitializeDefaultAdapters does not end with a return")
+	}
+
+	caseText := fmt.Sprintf("case config.%s:\n\t\tadapter, err = chains.New%sAdapter()\n\t", data.ConstName, data.Ident)
+	registerText := fmt.Sprintf(`// Register %[1]s
+	%[2]sAdapter, err := chains.New%[3]sAdapter()
+	if err != nil {
+		return fmt.Errorf("failed to initialize %[1]s adapter: %%w", err)
+	}
+	DefaultRegistry.Register(config.%[4]s, %[2]sAdapter)
+
+	`, data.Name, data.Var, data.Ident, data.ConstName)
+
+	return splice(src,
+		insertion{fset.Position(defaultClause.Pos()).Offset, caseText},
+		insertion{fset.Position(ret.Pos()).Offset, registerText},
+	), nil
+}
+
+// findFunc returns the top-level function or method with the given name
+func findFunc(file *ast.File, name string) *ast.FuncDecl {
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name.Name == name {
+			return fn
+		}
+	}
+	return nil
+}
+
+// containsStringLit reports whether lit has a string element equal to value
+func containsStringLit(lit *ast.CompositeLit, value string) bool {
+	quoted := fmt.Sprintf("%q", value)
+	for _, elt := range lit.Elts {
+		if bl, ok := elt.(*ast.BasicLit); ok && bl.Kind == token.STRING && bl.Value == quoted {
+			return true
+		}
+	}
+	return false
+}
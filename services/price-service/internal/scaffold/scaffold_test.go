@@ -0,0 +1,100 @@
+package scaffold
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// copyModuleFiles copies the files edited by Run into a temporary module root
+func copyModuleFiles(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+	for _, rel := range []string{
+		"internal/adapters/config/config.go",
+		"internal/adapters/registry/registry.go",
+		"internal/chains/chains.go",
+	} {
+		src, err := os.ReadFile(filepath.Join("..", "..", rel))
+		require.NoError(t, err)
+		dst := filepath.Join(root, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(dst), 0755))
+		require.NoError(t, os.WriteFile(dst, src, 0644))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "internal", "adapters", "chains"), 0755))
+
+	return root
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(content)
+}
+
+// TestRunScaffoldsChain verifies every generated and edited file is valid Go
+// and references the new chain.
+func TestRunScaffoldsChain(t *testing.T) {
+	root := copyModuleFiles(t)
+
+	result, err := Run(root, Options{Slug: "mlinar", BaseURL: "https://example.hr/cjenici"})
+	require.NoError(t, err)
+	assert.Len(t, result.Modified, 3)
+
+	for _, path := range append(result.Modified, result.Created...) {
+		if !strings.HasSuffix(path, ".go") {
+			continue
+		}
+		_, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+		assert.NoError(t, err, "generated file %s should parse", path)
+	}
+
+	assert.Contains(t, readFile(t, filepath.Join(root, "internal/chains/chains.go")), `"mlinar",`)
+
+	cfg := readFile(t, filepath.Join(root, "internal/adapters/config/config.go"))
+	assert.Regexp(t, `ChainMlinar\s+ChainID = "mlinar"`, cfg)
+	assert.Regexp(t, `BaseURL:\s+"https://example.hr/cjenici"`, cfg)
+
+	reg := readFile(t, filepath.Join(root, "internal/adapters/registry/registry.go"))
+	assert.Contains(t, reg, "case config.ChainMlinar:")
+	assert.Contains(t, reg, "DefaultRegistry.Register(config.ChainMlinar, mlinarAdapter)")
+
+	assert.FileExists(t, filepath.Join(root, "internal/adapters/chains/mlinar.go"))
+	assert.FileExists(t, filepath.Join(root, "tests/testdata/mlinar/.gitkeep"))
+	assert.FileExists(t, filepath.Join(root, "tests/unit/mlinar_golden_test.go"))
+	assert.FileExists(t, filepath.Join(root, "tests/unit/golden_helpers_test.go"))
+}
+
+// TestRunRejectsExistingChain ensures an existing slug is not registered twice.
+func TestRunRejectsExistingChain(t *testing.T) {
+	root := copyModuleFiles(t)
+
+	_, err := Run(root, Options{Slug: "konzum"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already")
+}
+
+// TestNewTemplateData verifies identifiers derived from slugs.
+func TestNewTemplateData(t *testing.T) {
+	data, err := newTemplateData(Options{Slug: "foo-bar", FileType: "XML"})
+	require.NoError(t, err)
+	assert.Equal(t, "FooBar", data.Ident)
+	assert.Equal(t, "fooBar", data.Var)
+	assert.Equal(t, "ChainFooBar", data.ConstName)
+	assert.Equal(t, "FooBar", data.Name)
+	assert.Equal(t, "XML", data.TypeName)
+
+	_, err = newTemplateData(Options{Slug: "Bad_Slug"})
+	assert.Error(t, err)
+
+	_, err = newTemplateData(Options{Slug: "mlinar", FileType: "xlsx"})
+	assert.Error(t, err)
+}
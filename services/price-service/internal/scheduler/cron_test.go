@@ -0,0 +1,116 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCronRejectsInvalidExpressions(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"x * * * *",
+		"@often",
+	} {
+		_, err := ParseCron(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	// Friday 2026-10-16 10:07 UTC
+	from := time.Date(2026, 10, 16, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 10, 16, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 10, 16, 10, 15, 0, 0, time.UTC)},
+		{"30 6 * * *", time.Date(2026, 10, 17, 6, 30, 0, 0, time.UTC)},
+		{"0 7,19 * * *", time.Date(2026, 10, 16, 19, 0, 0, 0, time.UTC)},
+		{"0 8-18/4 * * *", time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)},
+		{"0 6 * * mon-fri", time.Date(2026, 10, 19, 6, 0, 0, 0, time.UTC)},
+		{"0 6 * * 7", time.Date(2026, 10, 18, 6, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 10, 16, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: the 20th or a Monday, whichever is first
+		{"0 0 20 * mon", time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		schedule, err := ParseCron(tt.expr)
+		require.NoError(t, err, tt.expr)
+		assert.Equal(t, tt.want, schedule.Next(from), tt.expr)
+	}
+}
+
+func TestScheduleNextNeverMatches(t *testing.T) {
+	schedule, err := ParseCron("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, schedule.Next(time.Now()).IsZero())
+
+	_, err = NextRun("0 0 30 2 *", time.UTC, time.Now())
+	assert.Error(t, err)
+}
+
+func TestNextRunInLocation(t *testing.T) {
+	zagreb, err := time.LoadLocation("Europe/Zagreb")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+
+	// 06:30 in Zagreb is 04:30 UTC in summer and 05:30 UTC in winter
+	next, err := NextRun("30 6 * * *", zagreb, time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 17, 4, 30, 0, 0, time.UTC), next)
+
+	next, err = NextRun("30 6 * * *", zagreb, time.Date(2026, 11, 16, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 11, 17, 5, 30, 0, 0, time.UTC), next)
+}
+
+// TestNextRunAcrossClockChanges verifies daily times stay put by wall clock
+// when Zagreb's clocks go forward at 02:00 on 2024-03-31 and back at 03:00 on
+// 2024-10-27: a skipped time runs an hour later and a repeated one once.
+func TestNextRunAcrossClockChanges(t *testing.T) {
+	zagreb, err := time.LoadLocation("Europe/Zagreb")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"before spring forward", "30 6 * * *", time.Date(2024, 3, 30, 12, 0, 0, 0, time.UTC), time.Date(2024, 3, 31, 4, 30, 0, 0, time.UTC)},
+		{"skipped time", "30 2 * * *", time.Date(2024, 3, 30, 12, 0, 0, 0, time.UTC), time.Date(2024, 3, 31, 1, 30, 0, 0, time.UTC)},
+		{"after skipped time", "30 2 * * *", time.Date(2024, 3, 31, 1, 30, 0, 0, time.UTC), time.Date(2024, 4, 1, 0, 30, 0, 0, time.UTC)},
+		{"before fall back", "30 6 * * *", time.Date(2024, 10, 26, 12, 0, 0, 0, time.UTC), time.Date(2024, 10, 27, 5, 30, 0, 0, time.UTC)},
+		{"repeated time", "30 2 * * *", time.Date(2024, 10, 26, 12, 0, 0, 0, time.UTC), time.Date(2024, 10, 27, 0, 30, 0, 0, time.UTC)},
+		{"repeated time once", "30 2 * * *", time.Date(2024, 10, 27, 0, 30, 0, 0, time.UTC), time.Date(2024, 10, 28, 1, 30, 0, 0, time.UTC)},
+		{"within repeated hour", "30 2 * * *", time.Date(2024, 10, 27, 1, 10, 0, 0, time.UTC), time.Date(2024, 10, 28, 1, 30, 0, 0, time.UTC)},
+		{"hourly through repeated hour", "0 * * * *", time.Date(2024, 10, 27, 0, 0, 0, 0, time.UTC), time.Date(2024, 10, 27, 2, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		next, err := NextRun(tt.expr, zagreb, tt.from)
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, tt.want, next, tt.name)
+	}
+}
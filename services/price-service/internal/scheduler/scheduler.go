@@ -0,0 +1,196 @@
+// Package scheduler starts ingestion runs on per-chain cron schedules kept
+// in the ingestion_schedules table.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kosarica/price-service/internal/chainsettings"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/lanes"
+	"github.com/kosarica/price-service/internal/pkg/clock"
+	"github.com/rs/zerolog"
+)
+
+// RunFunc ingests a chain and returns the ID of the run it created
+type RunFunc func(ctx context.Context, chainSlug string) (string, error)
+
+// Scheduler periodically starts the ingestion runs of due schedules
+type Scheduler struct {
+	logger   *zerolog.Logger
+	location *time.Location
+	run      RunFunc
+	interval time.Duration
+	now      func() time.Time
+	stopChan chan struct{}
+}
+
+// New creates a scheduler evaluating cron expressions in location and
+// checking for due schedules every interval
+func New(logger *zerolog.Logger, location *time.Location, run RunFunc, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		logger:   logger,
+		location: location,
+		run:      run,
+		interval: interval,
+		now:      clock.Now,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// SetClock replaces the clock due schedules and next run times are computed
+// from.
+func (s *Scheduler) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// NextRun returns the first time after now a cron expression matches in
+// location, in UTC as stored in ingestion_schedules
+func NextRun(cronExpression string, location *time.Location, now time.Time) (time.Time, error) {
+	schedule, err := ParseCron(cronExpression)
+	if err != nil {
+		return time.Time{}, err
+	}
+	next := schedule.Next(now.In(location))
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("cron expression %q never matches", cronExpression)
+	}
+	return next.UTC(), nil
+}
+
+// Seed inserts the schedules of chains that have none, mapping chain slug to
+// cron expression. Schedules already stored, including ones edited through
+// the admin API, are left as they are.
+func (s *Scheduler) Seed(ctx context.Context, schedules map[string]string) error {
+	now := s.now()
+	for chainSlug, cronExpression := range schedules {
+		next, err := NextRun(cronExpression, s.location, now)
+		if err != nil {
+			return fmt.Errorf("invalid schedule for chain %s: %w", chainSlug, err)
+		}
+		created, err := database.InsertIngestionScheduleIfMissing(ctx, chainSlug, cronExpression, &next)
+		if err != nil {
+			return fmt.Errorf("failed to seed schedule for chain %s: %w", chainSlug, err)
+		}
+		if created {
+			s.logger.Info().
+				Str("chain", chainSlug).
+				Str("cron", cronExpression).
+				Time("nextRunAt", next).
+				Msg("Created ingestion schedule from config")
+		}
+	}
+	return nil
+}
+
+// Start checks for due schedules on every interval
+func (s *Scheduler) Start(ctx context.Context) {
+	s.logger.Info().
+		Dur("interval", s.interval).
+		Str("location", s.location.String()).
+		Msg("Starting ingestion scheduler")
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info().Msg("Ingestion scheduler stopping (context cancelled)")
+			return
+		case <-s.stopChan:
+			s.logger.Info().Msg("Ingestion scheduler stopping (stop signal)")
+			return
+		case <-ticker.C:
+			if err := s.RunDue(ctx); err != nil {
+				s.logger.Error().Err(err).Msg("Failed to run due ingestion schedules")
+			}
+		}
+	}
+}
+
+// Stop signals the scheduler to stop
+func (s *Scheduler) Stop() {
+	close(s.stopChan)
+}
+
+// RunDue starts a run for every due schedule this server claims and moves
+// the schedule to its next time. A chain with a pending or running run, or
+// with discovery disabled in its settings, is skipped until its next time,
+// so runs never overlap. Runs continue in the
+// background after RunDue returns.
+func (s *Scheduler) RunDue(ctx context.Context) error {
+	ctx = lanes.WithLane(ctx, lanes.Batch)
+	release, err := lanes.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	now := s.now()
+
+	due, err := database.ListDueIngestionSchedules(ctx, now.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to list due schedules: %w", err)
+	}
+
+	for _, schedule := range due {
+		logger := s.logger.With().Str("chain", schedule.ChainSlug).Logger()
+
+		next, err := NextRun(schedule.CronExpression, s.location, now)
+		if err != nil {
+			logger.Error().Err(err).Msg("Invalid ingestion schedule")
+			continue
+		}
+		claimed, err := database.ClaimIngestionSchedule(ctx, schedule.ChainSlug, *schedule.NextRunAt, next)
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to claim ingestion schedule")
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		if !chainsettings.DiscoveryEnabled(schedule.ChainSlug) {
+			logger.Info().Time("nextRunAt", next).Msg("Skipping scheduled ingestion, discovery is disabled for the chain")
+			s.recordOutcome(ctx, schedule.ChainSlug, database.ScheduleStatusSkipped, "", "discovery disabled")
+			continue
+		}
+
+		active, err := database.HasActiveIngestionRun(ctx, schedule.ChainSlug)
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to check for a running ingestion")
+			s.recordOutcome(ctx, schedule.ChainSlug, database.ScheduleStatusFailed, "", err.Error())
+			continue
+		}
+		if active {
+			logger.Warn().Time("nextRunAt", next).Msg("Skipping scheduled ingestion, a run of the chain is still in progress")
+			s.recordOutcome(ctx, schedule.ChainSlug, database.ScheduleStatusSkipped, "", "")
+			continue
+		}
+
+		logger.Info().Time("nextRunAt", next).Msg("Starting scheduled ingestion")
+		s.recordOutcome(ctx, schedule.ChainSlug, database.ScheduleStatusStarted, "", "")
+		go s.runSchedule(context.WithoutCancel(ctx), schedule.ChainSlug)
+	}
+	return nil
+}
+
+// runSchedule ingests a chain and records the outcome on its schedule
+func (s *Scheduler) runSchedule(ctx context.Context, chainSlug string) {
+	runID, err := s.run(ctx, chainSlug)
+	if err != nil {
+		s.logger.Error().Err(err).Str("chain", chainSlug).Str("runID", runID).Msg("Scheduled ingestion failed")
+		s.recordOutcome(ctx, chainSlug, database.ScheduleStatusFailed, runID, err.Error())
+		return
+	}
+	s.recordOutcome(ctx, chainSlug, database.ScheduleStatusCompleted, runID, "")
+}
+
+func (s *Scheduler) recordOutcome(ctx context.Context, chainSlug, status, runID, errMsg string) {
+	if err := database.RecordIngestionScheduleOutcome(ctx, chainSlug, status, runID, errMsg); err != nil {
+		s.logger.Warn().Err(err).Str("chain", chainSlug).Msg("Failed to record ingestion schedule outcome")
+	}
+}
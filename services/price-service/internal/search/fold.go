@@ -0,0 +1,50 @@
+// Package search normalizes product names for item search: folding Croatian
+// diacritics and spelling variants, and matching misspelled queries.
+package search
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// foldRunes maps accented letters to their base letter. It mirrors the
+// translate() of the search_fold SQL function (migration 0026); keep both in
+// sync, as names are folded in Postgres and queries in Go.
+var foldRunes = map[rune]rune{
+	'č': 'c', 'ć': 'c', 'đ': 'd', 'š': 's', 'ž': 'z',
+	'á': 'a', 'à': 'a', 'â': 'a', 'ä': 'a', 'ã': 'a', 'å': 'a',
+	'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e',
+	'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i',
+	'ó': 'o', 'ò': 'o', 'ô': 'o', 'ö': 'o', 'õ': 'o',
+	'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u',
+	'ý': 'y', 'ÿ': 'y', 'ñ': 'n',
+}
+
+// Spelling variants typed interchangeably, replaced one after the other:
+// ijekavian "ije" is often typed "je" (mlijeko, mljeko), and đ is typed "dj"
+// or "d" once its diacritic is dropped
+var (
+	ijeVariant = strings.NewReplacer("ije", "je")
+	djVariant  = strings.NewReplacer("dj", "d")
+)
+
+// Fold normalizes text for search: lowercased, diacritics folded to their
+// base letter, spelling variants unified and whitespace collapsed. So
+// "MLIJEKO", "mlijeko" and "mljeko" fold alike, as do "Đumbir", "djumbir" and
+// "dumbir". Folding is the same as search_fold in SQL.
+func Fold(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if folded, ok := foldRunes[r]; ok {
+			return folded
+		}
+		return r
+	}, strings.ToLower(s))
+	s = ijeVariant.Replace(s)
+	s = djVariant.Replace(s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// runeLen is the number of characters of s
+func runeLen(s string) int {
+	return utf8.RuneCountInString(s)
+}
@@ -0,0 +1,66 @@
+package search
+
+import "strings"
+
+// MinFuzzyLength is the shortest folded query worth matching with edits;
+// shorter ones match too much
+const MinFuzzyLength = 4
+
+// maxEdits is how many typos a query word of the given length tolerates
+func maxEdits(length int) int {
+	if length <= 6 {
+		return 1
+	}
+	return 2
+}
+
+// FuzzyMatch reports whether every word of a folded query is within a few
+// edits of a word of the folded text, or of its beginning, so a word still
+// being typed matches too
+func FuzzyMatch(query, text string) bool {
+	words := strings.Fields(text)
+	for _, q := range strings.Fields(query) {
+		if !fuzzyWordMatch(q, words) {
+			return false
+		}
+	}
+	return true
+}
+
+// fuzzyWordMatch reports whether a query word is close to one of words
+func fuzzyWordMatch(q string, words []string) bool {
+	limit := maxEdits(runeLen(q))
+	for _, w := range words {
+		if Distance(q, w) <= limit {
+			return true
+		}
+		// A prefix as long as the query word, for words still being typed
+		if prefix := []rune(w); len(prefix) > runeLen(q) && Distance(q, string(prefix[:runeLen(q)])) <= limit {
+			return true
+		}
+	}
+	return false
+}
+
+// Distance is the Levenshtein edit distance between a and b, counted in
+// characters
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,117 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// corpus is a sample of Croatian product names as chains publish them
+var corpus = []string{
+	"Dukat Svježe mlijeko 2,8% m.m. 1 l",
+	"MLIJEKO TRAJNO 3,2% 1L",
+	"Čokolada mliječna s lješnjacima 100 g",
+	"Kraš Bajadera 200 g",
+	"Đumbir svježi kg",
+	"Kiseli kupus glavica",
+	"Pašteta od tune Podravka 95 g",
+	"Žitarice zobene pahuljice 500 g",
+	"Sir gauda narezani 150 g",
+	"Ćevapčići Gavrilović 400 g",
+}
+
+// find returns the corpus names containing the folded query, or within a
+// few typos of it when none does, as item search matches them
+func find(query string) []string {
+	q := Fold(query)
+	var found []string
+	for _, name := range corpus {
+		if strings.Contains(Fold(name), q) {
+			found = append(found, name)
+		}
+	}
+	if found != nil || runeLen(q) < MinFuzzyLength {
+		return found
+	}
+	for _, name := range corpus {
+		if FuzzyMatch(q, Fold(name)) {
+			found = append(found, name)
+		}
+	}
+	return found
+}
+
+// TestFold verifies case, diacritics and spelling variants fold alike
+func TestFold(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"MLIJEKO", "mljeko"},
+		{"mlijeko", "mljeko"},
+		{"mljeko", "mljeko"},
+		{"Čokolada", "cokolada"},
+		{"ćevapčići", "cevapcici"},
+		{"Đumbir", "dumbir"},
+		{"djumbir", "dumbir"},
+		{"Šampon  ŽUTI\tlimun", "sampon zuti limun"},
+		{"  Crème fraîche ", "creme fraiche"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, Fold(tt.input), tt.input)
+	}
+}
+
+// TestFindCorpus verifies spelling variants and typos find the same products
+func TestFindCorpus(t *testing.T) {
+	milk := []string{corpus[0], corpus[1]}
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"mlijeko", milk},
+		{"mljeko", milk},
+		{"MLIJEKO", milk},
+		{"mljko", milk},
+		{"cokolada", []string{corpus[2]}},
+		{"čokolda", []string{corpus[2]}},
+		{"lješnjak", []string{corpus[2]}},
+		{"djumbir", []string{corpus[4]}},
+		{"dumbir", []string{corpus[4]}},
+		{"pasteta", []string{corpus[6]}},
+		{"cevapcici", []string{corpus[9]}},
+		{"cevapicici", []string{corpus[9]}},
+		{"zobene pahuljce", []string{corpus[7]}},
+		{"kras bajadera", []string{corpus[3]}},
+		{"banana", nil},
+		{"sur", nil}, // too short to match with typos
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, find(tt.query), tt.query)
+	}
+}
+
+// TestDistance verifies edits are counted per character, not byte
+func TestDistance(t *testing.T) {
+	assert.Equal(t, 0, Distance("mlijeko", "mlijeko"))
+	assert.Equal(t, 1, Distance("mljko", "mljeko"))
+	assert.Equal(t, 1, Distance("čaj", "caj"))
+	assert.Equal(t, 3, Distance("", "sir"))
+	assert.Equal(t, 2, Distance("cokolada", "cokoldaa"))
+}
+
+// TestFuzzyMatch verifies short words tolerate one typo and a word being
+// typed matches the start of a name word
+func TestFuzzyMatch(t *testing.T) {
+	assert.True(t, FuzzyMatch("mljko", "svjeze mljeko"))
+	assert.False(t, FuzzyMatch("mko", "svjeze mljeko"), "two edits are too many for a short word")
+	assert.True(t, FuzzyMatch("cokol", "cokolada mljecna"))
+	assert.True(t, FuzzyMatch("cokolda mljecna", "cokolada mljecna"))
+	assert.False(t, FuzzyMatch("cokolada bijela", "cokolada mljecna"))
+	assert.False(t, FuzzyMatch("kava", "sir gauda"))
+}
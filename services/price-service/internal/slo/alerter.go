@@ -0,0 +1,185 @@
+package slo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog"
+)
+
+// Alert states sent to webhooks
+const (
+	AlertFiring   = "firing"
+	AlertResolved = "resolved"
+)
+
+var (
+	complianceGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "slo_compliance_ratio",
+		Help: "Fraction of good requests over the SLO window",
+	}, []string{"slo"})
+	budgetGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "slo_error_budget_remaining_ratio",
+		Help: "Fraction of the error budget left over the SLO window",
+	}, []string{"slo"})
+	burnRateGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "slo_burn_rate",
+		Help: "Error budget burn rate; 1 spends the budget exactly over the window",
+	}, []string{"slo", "window"})
+	fastBurnGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "slo_fast_burn",
+		Help: "1 while the SLO is burning its error budget fast",
+	}, []string{"slo"})
+)
+
+// Alert is the webhook payload sent when a fast burn starts or ends
+type Alert struct {
+	SLO        string             `json:"slo"`
+	State      string             `json:"state"`
+	Route      string             `json:"route"`
+	Method     string             `json:"method,omitempty"`
+	Target     float64            `json:"target"`
+	Compliance float64            `json:"compliance"`
+	BurnRates  map[string]float64 `json:"burnRates"`
+	Threshold  float64            `json:"threshold"`
+	At         time.Time          `json:"at"`
+}
+
+// Alerter periodically publishes SLO statuses as metrics and posts alerts to
+// webhooks when an objective starts or stops burning its budget fast
+type Alerter struct {
+	tracker  *Tracker
+	urls     []string
+	client   *http.Client
+	logger   *zerolog.Logger
+	interval time.Duration
+	firing   map[string]bool
+	stopChan chan struct{}
+}
+
+// NewAlerter creates an alerter posting to the given webhook URLs
+func NewAlerter(tracker *Tracker, urls []string, logger *zerolog.Logger, interval time.Duration) *Alerter {
+	return &Alerter{
+		tracker:  tracker,
+		urls:     urls,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		logger:   logger,
+		interval: interval,
+		firing:   make(map[string]bool),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start evaluates the objectives on every interval until stopped
+func (a *Alerter) Start(ctx context.Context) {
+	a.logger.Info().
+		Int("webhooks", len(a.urls)).
+		Dur("interval", a.interval).
+		Msg("Starting SLO alerter")
+
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			a.logger.Info().Msg("SLO alerter stopping (context cancelled)")
+			return
+		case <-a.stopChan:
+			a.logger.Info().Msg("SLO alerter stopping (stop signal)")
+			return
+		case <-ticker.C:
+			a.Evaluate(ctx)
+		}
+	}
+}
+
+// Stop signals the alerter to stop
+func (a *Alerter) Stop() {
+	close(a.stopChan)
+}
+
+// Evaluate updates the SLO metrics and alerts on fast burn transitions. An
+// alert that fails to post is retried on the next evaluation.
+func (a *Alerter) Evaluate(ctx context.Context) {
+	for _, status := range a.tracker.Status() {
+		complianceGauge.WithLabelValues(status.Name).Set(status.Compliance)
+		budgetGauge.WithLabelValues(status.Name).Set(status.ErrorBudgetRemaining)
+		for window, rate := range status.BurnRates {
+			burnRateGauge.WithLabelValues(status.Name, window).Set(rate)
+		}
+		fast := 0.0
+		if status.FastBurn {
+			fast = 1
+		}
+		fastBurnGauge.WithLabelValues(status.Name).Set(fast)
+
+		if status.FastBurn == a.firing[status.Name] {
+			continue
+		}
+
+		alert := Alert{
+			SLO:        status.Name,
+			State:      AlertResolved,
+			Route:      status.Route,
+			Method:     status.Method,
+			Target:     status.Target,
+			Compliance: status.Compliance,
+			BurnRates:  status.BurnRates,
+			Threshold:  a.tracker.FastBurnRate(),
+			At:         time.Now().UTC(),
+		}
+		event := a.logger.Info()
+		if status.FastBurn {
+			alert.State = AlertFiring
+			event = a.logger.Warn()
+		}
+		event.
+			Str("slo", status.Name).
+			Str("state", alert.State).
+			Float64("burnRate5m", status.BurnRates[WindowLabel(ShortWindow)]).
+			Float64("burnRate1h", status.BurnRates[WindowLabel(LongWindow)]).
+			Msg("SLO fast burn")
+
+		if err := a.post(ctx, alert); err != nil {
+			a.logger.Error().Err(err).Str("slo", status.Name).Msg("Failed to send SLO alert")
+			continue
+		}
+		a.firing[status.Name] = status.FastBurn
+	}
+}
+
+// post sends an alert to every webhook
+func (a *Alerter) post(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert: %w", err)
+	}
+
+	for _, url := range a.urls {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to build alert request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := a.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("webhook %s: %w", url, err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("webhook %s: unexpected status %d", url, resp.StatusCode)
+		}
+	}
+	return nil
+}
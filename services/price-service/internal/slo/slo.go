@@ -0,0 +1,243 @@
+// Package slo tracks per-endpoint service level objectives from the requests
+// the service handles and alerts when error budgets burn too fast.
+package slo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Burn rate windows. A fast burn needs both the long and the short window over
+// the threshold: the long one shows the budget is really being spent, the
+// short one that it still is, so alerts resolve soon after recovery.
+const (
+	ShortWindow = 5 * time.Minute
+	LongWindow  = time.Hour
+	SlowWindow  = 6 * time.Hour
+)
+
+// bucketWidth is the resolution requests are counted at
+const bucketWidth = time.Minute
+
+// DefaultFastBurnRate spends 2% of a 30 day error budget in an hour
+const DefaultFastBurnRate = 14.4
+
+var errInvalidObjective = errors.New("invalid objective")
+
+// Objective is a service level objective over the requests of a route
+type Objective struct {
+	Name    string
+	Route   string        // Gin route pattern; a trailing * matches every route with that prefix
+	Method  string        // HTTP method; empty matches any
+	Latency time.Duration // Requests slower than this are bad; 0 counts only errors
+	Target  float64       // Fraction of requests that must be good, e.g. 0.995
+}
+
+// matches reports whether a request to a route is covered by the objective
+func (o Objective) matches(route, method string) bool {
+	if o.Method != "" && !strings.EqualFold(o.Method, method) {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(o.Route, "*"); ok {
+		return strings.HasPrefix(route, prefix)
+	}
+	return route == o.Route
+}
+
+// good reports whether a request met the objective: no server error and, for
+// latency objectives, fast enough
+func (o Objective) good(status int, latency time.Duration) bool {
+	return status < 500 && (o.Latency == 0 || latency <= o.Latency)
+}
+
+// Options configures a Tracker
+type Options struct {
+	Window       time.Duration // Compliance window, e.g. 30 days
+	FastBurnRate float64       // Burn rate of both windows that is a fast burn
+	MinRequests  int64         // Requests in the short window needed to call a fast burn
+}
+
+// Status is the state of an objective
+type Status struct {
+	Objective
+	Window               time.Duration
+	Requests             int64              // Requests in the compliance window
+	Good                 int64              // Good requests in the compliance window
+	Compliance           float64            // Good fraction; 1 without requests
+	ErrorBudgetRemaining float64            // Fraction of the error budget left; negative once overspent
+	BurnRates            map[string]float64 // Budget burn rate per window label, 1 spending it exactly over the window
+	FastBurn             bool
+}
+
+// Tracker counts good and total requests per objective in minute buckets
+type Tracker struct {
+	objectives []Objective
+	series     []*series
+	opts       Options
+	now        func() time.Time
+}
+
+// NewTracker creates a tracker for the objectives
+func NewTracker(objectives []Objective, opts Options) (*Tracker, error) {
+	if opts.Window < SlowWindow {
+		opts.Window = SlowWindow
+	}
+	if opts.FastBurnRate <= 0 {
+		opts.FastBurnRate = DefaultFastBurnRate
+	}
+
+	names := make(map[string]bool, len(objectives))
+	t := &Tracker{opts: opts, now: time.Now}
+	for _, o := range objectives {
+		if o.Name == "" || o.Route == "" {
+			return nil, fmt.Errorf("%w: name and route are required", errInvalidObjective)
+		}
+		if names[o.Name] {
+			return nil, fmt.Errorf("%w: duplicate name %q", errInvalidObjective, o.Name)
+		}
+		if o.Target <= 0 || o.Target >= 1 {
+			return nil, fmt.Errorf("%w: target of %q must be between 0 and 1", errInvalidObjective, o.Name)
+		}
+		names[o.Name] = true
+		t.objectives = append(t.objectives, o)
+		t.series = append(t.series, newSeries(int(opts.Window/bucketWidth)))
+	}
+	return t, nil
+}
+
+// ObserveRequest counts a finished request against the objectives covering it
+func (t *Tracker) ObserveRequest(route, method string, status int, latency time.Duration) {
+	var minute int64
+	for i, o := range t.objectives {
+		if !o.matches(route, method) {
+			continue
+		}
+		if minute == 0 {
+			minute = t.now().Unix() / int64(bucketWidth/time.Second)
+		}
+		t.series[i].add(minute, o.good(status, latency))
+	}
+}
+
+// Status returns the state of every objective
+func (t *Tracker) Status() []Status {
+	minute := t.now().Unix() / int64(bucketWidth/time.Second)
+	statuses := make([]Status, 0, len(t.objectives))
+	for i, o := range t.objectives {
+		s := t.series[i]
+		budget := 1 - o.Target
+
+		good, total := s.sum(minute, t.opts.Window)
+		status := Status{
+			Objective:            o,
+			Window:               t.opts.Window,
+			Requests:             total,
+			Good:                 good,
+			Compliance:           1,
+			ErrorBudgetRemaining: 1,
+			BurnRates:            make(map[string]float64, 3),
+		}
+		if total > 0 {
+			status.Compliance = float64(good) / float64(total)
+			status.ErrorBudgetRemaining = 1 - (1-status.Compliance)/budget
+		}
+
+		var shortTotal int64
+		for _, window := range []time.Duration{ShortWindow, LongWindow, SlowWindow} {
+			good, total := s.sum(minute, window)
+			rate := 0.0
+			if total > 0 {
+				rate = (1 - float64(good)/float64(total)) / budget
+			}
+			status.BurnRates[WindowLabel(window)] = rate
+			if window == ShortWindow {
+				shortTotal = total
+			}
+		}
+		status.FastBurn = shortTotal >= t.opts.MinRequests &&
+			status.BurnRates[WindowLabel(ShortWindow)] >= t.opts.FastBurnRate &&
+			status.BurnRates[WindowLabel(LongWindow)] >= t.opts.FastBurnRate
+
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// FastBurnRate returns the burn rate that is a fast burn
+func (t *Tracker) FastBurnRate() float64 {
+	return t.opts.FastBurnRate
+}
+
+// WindowLabel formats a burn rate window as used in statuses and metrics, e.g. 5m or 1h
+func WindowLabel(d time.Duration) string {
+	if d%time.Hour == 0 {
+		return fmt.Sprintf("%dh", d/time.Hour)
+	}
+	return fmt.Sprintf("%dm", d/time.Minute)
+}
+
+// series is a ring of minute buckets
+type series struct {
+	mu      sync.Mutex
+	buckets []bucket
+}
+
+type bucket struct {
+	minute      int64
+	good, total int64
+}
+
+func newSeries(size int) *series {
+	return &series{buckets: make([]bucket, size)}
+}
+
+func (s *series) add(minute int64, good bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := &s.buckets[minute%int64(len(s.buckets))]
+	if b.minute != minute {
+		*b = bucket{minute: minute}
+	}
+	b.total++
+	if good {
+		b.good++
+	}
+}
+
+// sum adds up the buckets of the window ending with the current minute
+func (s *series) sum(minute int64, window time.Duration) (good, total int64) {
+	from := minute - int64(window/bucketWidth)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, b := range s.buckets {
+		if b.minute > from && b.minute <= minute {
+			good += b.good
+			total += b.total
+		}
+	}
+	return good, total
+}
+
+var (
+	defaultMu      sync.RWMutex
+	defaultTracker *Tracker
+)
+
+// SetDefault sets the tracker served by the SLO endpoint
+func SetDefault(t *Tracker) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultTracker = t
+}
+
+// Default returns the tracker set by SetDefault, or nil
+func Default() *Tracker {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultTracker
+}
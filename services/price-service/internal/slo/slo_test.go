@@ -0,0 +1,123 @@
+package slo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTracker(t *testing.T, now *time.Time) *Tracker {
+	t.Helper()
+	tracker, err := NewTracker([]Objective{
+		{Name: "optimize-latency", Route: "/internal/basket/optimize/*", Method: "POST", Latency: 300 * time.Millisecond, Target: 0.95},
+		{Name: "optimize-errors", Route: "/internal/basket/optimize/*", Method: "POST", Target: 0.995},
+	}, Options{Window: 24 * time.Hour, MinRequests: 10})
+	require.NoError(t, err)
+	tracker.now = func() time.Time { return *now }
+	return tracker
+}
+
+func statusByName(statuses []Status) map[string]Status {
+	byName := make(map[string]Status, len(statuses))
+	for _, s := range statuses {
+		byName[s.Name] = s
+	}
+	return byName
+}
+
+// TestTrackerStatus tests compliance, budgets and burn rates
+func TestTrackerStatus(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newTestTracker(t, &now)
+
+	statuses := statusByName(tracker.Status())
+	assert.Equal(t, 1.0, statuses["optimize-latency"].Compliance, "no requests is compliant")
+
+	// Two hours ago: 100 fast requests
+	now = now.Add(-2 * time.Hour)
+	for i := 0; i < 100; i++ {
+		tracker.ObserveRequest("/internal/basket/optimize/single", "POST", 200, 50*time.Millisecond)
+	}
+	// Now: 90 fast, 10 slow and 1 failing; other routes are ignored
+	now = now.Add(2 * time.Hour)
+	for i := 0; i < 90; i++ {
+		tracker.ObserveRequest("/internal/basket/optimize/multi", "POST", 200, 50*time.Millisecond)
+	}
+	for i := 0; i < 10; i++ {
+		tracker.ObserveRequest("/internal/basket/optimize/multi", "POST", 200, time.Second)
+	}
+	tracker.ObserveRequest("/internal/basket/optimize/single", "POST", 500, 10*time.Millisecond)
+	tracker.ObserveRequest("/internal/prices/:chainSlug/:storeId", "GET", 500, time.Second)
+
+	statuses = statusByName(tracker.Status())
+	latency := statuses["optimize-latency"]
+	assert.Equal(t, int64(201), latency.Requests)
+	assert.Equal(t, int64(190), latency.Good)
+	assert.InDelta(t, 190.0/201, latency.Compliance, 1e-9)
+	assert.InDelta(t, 11.0/101/0.05, latency.BurnRates["5m"], 1e-9)
+	assert.InDelta(t, 11.0/201/0.05, latency.BurnRates["6h"], 1e-9)
+	assert.False(t, latency.FastBurn)
+
+	errs := statuses["optimize-errors"]
+	assert.Equal(t, int64(200), errs.Good)
+	assert.InDelta(t, 1-(1.0/201)/0.005, errs.ErrorBudgetRemaining, 1e-9)
+	assert.InDelta(t, 1.0/101/0.005, errs.BurnRates["1h"], 1e-9)
+	assert.False(t, errs.FastBurn, "burn rate ~1.98 is under 14.4")
+}
+
+// TestNewTrackerValidation tests objective validation
+func TestNewTrackerValidation(t *testing.T) {
+	for _, objectives := range [][]Objective{
+		{{Name: "a", Route: "/x", Target: 1}},
+		{{Name: "a", Target: 0.9}},
+		{{Name: "a", Route: "/x", Target: 0.9}, {Name: "a", Route: "/y", Target: 0.9}},
+	} {
+		_, err := NewTracker(objectives, Options{})
+		assert.ErrorIs(t, err, errInvalidObjective)
+	}
+}
+
+// TestAlerterEvaluate tests that webhooks get firing and resolved alerts once
+func TestAlerterEvaluate(t *testing.T) {
+	var mu sync.Mutex
+	var alerts []Alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert Alert
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&alert))
+		mu.Lock()
+		alerts = append(alerts, alert)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newTestTracker(t, &now)
+	logger := zerolog.Nop()
+	alerter := NewAlerter(tracker, []string{server.URL}, &logger, time.Minute)
+	ctx := context.Background()
+
+	for i := 0; i < 20; i++ {
+		tracker.ObserveRequest("/internal/basket/optimize/single", "POST", 503, time.Millisecond)
+	}
+	alerter.Evaluate(ctx)
+	alerter.Evaluate(ctx)
+
+	now = now.Add(2 * time.Hour)
+	alerter.Evaluate(ctx)
+
+	require.Len(t, alerts, 4)
+	assert.Equal(t, AlertFiring, alerts[0].State)
+	assert.Equal(t, AlertFiring, alerts[1].State)
+	assert.Equal(t, AlertResolved, alerts[2].State)
+	assert.Equal(t, AlertResolved, alerts[3].State)
+	assert.ElementsMatch(t, []string{"optimize-latency", "optimize-errors"}, []string{alerts[0].SLO, alerts[1].SLO})
+	assert.Equal(t, DefaultFastBurnRate, alerts[0].Threshold)
+}
@@ -2,9 +2,18 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+// ErrNotFound is returned when no file exists at a key
+var ErrNotFound = errors.New("file not found")
+
+// IsNotFound reports whether err means a file does not exist
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
 // Metadata contains file metadata for storage
 type Metadata struct {
 	ContentType    string            `json:"contentType,omitempty"`
@@ -49,6 +58,9 @@ type Storage interface {
 
 	// GetChecksum returns the checksum for a file (without reading full content)
 	GetChecksum(ctx context.Context, key string) (string, error)
+
+	// Type returns the backend type, recorded with archived files
+	Type() StorageType
 }
 
 // StorageType represents the type of storage backend
@@ -57,4 +69,5 @@ type StorageType string
 const (
 	StorageTypeLocal StorageType = "local"
 	StorageTypeS3    StorageType = "s3"
+	StorageTypeGCS   StorageType = "gcs"
 )
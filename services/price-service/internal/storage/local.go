@@ -30,6 +30,11 @@ func NewLocalStorage(basePath string) (*LocalStorage, error) {
 	}, nil
 }
 
+// Type returns the backend type
+func (s *LocalStorage) Type() StorageType {
+	return StorageTypeLocal
+}
+
 // Put stores content at the given key with optional metadata
 func (s *LocalStorage) Put(ctx context.Context, key string, content []byte, metadata *Metadata) error {
 	fullPath := s.keyToPath(key)
@@ -67,7 +72,7 @@ func (s *LocalStorage) Get(ctx context.Context, key string) ([]byte, error) {
 	content, err := os.ReadFile(fullPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("file not found: %s", key)
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
 		}
 		return nil, fmt.Errorf("failed to read file %s: %w", fullPath, err)
 	}
@@ -82,7 +87,7 @@ func (s *LocalStorage) GetInfo(ctx context.Context, key string) (*FileInfo, erro
 	stat, err := os.Stat(fullPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("file not found: %s", key)
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
 		}
 		return nil, fmt.Errorf("failed to stat file %s: %w", fullPath, err)
 	}
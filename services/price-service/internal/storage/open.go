@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/kosarica/price-service/config"
+)
+
+// defaultLocalPath is where files are archived when no configuration is loaded
+const defaultLocalPath = "./data/archives"
+
+// Open creates the storage backend a configuration selects. Local storage
+// keeps files under base_path; S3 and GCS keep them in bucket under the
+// base_path prefix and never touch local disk.
+func Open(cfg config.StorageConfig) (Storage, error) {
+	opts := S3Options{
+		Bucket:          cfg.Bucket,
+		Prefix:          cfg.BasePath,
+		Region:          cfg.Region,
+		Endpoint:        cfg.Endpoint,
+		AccessKeyID:     cfg.AccessKeyID,
+		SecretAccessKey: cfg.SecretAccessKey,
+		SessionToken:    cfg.SessionToken,
+		ForcePathStyle:  cfg.ForcePathStyle,
+	}
+
+	switch StorageType(cfg.Type) {
+	case "", StorageTypeLocal:
+		basePath := cfg.BasePath
+		if basePath == "" {
+			basePath = defaultLocalPath
+		}
+		return NewLocalStorage(basePath)
+	case StorageTypeS3:
+		return NewS3Storage(opts)
+	case StorageTypeGCS:
+		return NewGCSStorage(opts)
+	default:
+		return nil, fmt.Errorf("unsupported storage type: %s", cfg.Type)
+	}
+}
+
+// Default opens the storage backend of the loaded configuration, or local
+// storage under ./data/archives when none is loaded
+func Default() (Storage, error) {
+	if cfg := config.Get(); cfg != nil {
+		return Open(cfg.Storage)
+	}
+	return NewLocalStorage(defaultLocalPath)
+}
@@ -0,0 +1,461 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// s3MetaChecksum holds the SHA256 of the content, so checksums do not
+	// need a download
+	s3MetaChecksum = "X-Amz-Meta-Sha256"
+	// s3MetaMetadata holds the Metadata as base64 encoded JSON
+	s3MetaMetadata = "X-Amz-Meta-Kosarica"
+
+	// gcsEndpoint is the S3 compatible XML API of Google Cloud Storage
+	gcsEndpoint = "https://storage.googleapis.com"
+)
+
+// S3Options configures an S3 compatible storage backend
+type S3Options struct {
+	Bucket          string
+	Prefix          string // Prepended to every key
+	Region          string
+	Endpoint        string // Defaults to AWS S3 in Region
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	ForcePathStyle  bool // Address the bucket in the path rather than the host, as MinIO needs
+	Timeout         time.Duration
+}
+
+// S3Storage implements Storage over the S3 REST API, signing requests with
+// AWS Signature Version 4. It works with AWS S3 and S3 compatible services
+// such as MinIO, Cloudflare R2 and Google Cloud Storage.
+type S3Storage struct {
+	opts        S3Options
+	storageType StorageType
+	endpoint    *url.URL
+	client      *http.Client
+	now         func() time.Time
+}
+
+// NewS3Storage creates an S3 storage backend
+func NewS3Storage(opts S3Options) (*S3Storage, error) {
+	return newS3Storage(opts, StorageTypeS3)
+}
+
+// NewGCSStorage creates a Google Cloud Storage backend using its S3
+// compatible XML API, authenticated with an HMAC key of a service account
+func NewGCSStorage(opts S3Options) (*S3Storage, error) {
+	if opts.Endpoint == "" {
+		opts.Endpoint = gcsEndpoint
+	}
+	if opts.Region == "" {
+		opts.Region = "auto"
+	}
+	opts.ForcePathStyle = true
+	return newS3Storage(opts, StorageTypeGCS)
+}
+
+func newS3Storage(opts S3Options, storageType StorageType) (*S3Storage, error) {
+	if opts.Bucket == "" {
+		return nil, fmt.Errorf("%s storage requires a bucket", storageType)
+	}
+	if opts.AccessKeyID == "" || opts.SecretAccessKey == "" {
+		return nil, fmt.Errorf("%s storage requires an access key ID and secret access key", storageType)
+	}
+	if opts.Region == "" {
+		opts.Region = "us-east-1"
+	}
+	if opts.Endpoint == "" {
+		opts.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", opts.Region)
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = 5 * time.Minute
+	}
+	opts.Prefix = strings.Trim(path.Clean("/"+opts.Prefix), "/")
+
+	endpoint, err := url.Parse(opts.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid %s endpoint %q", storageType, opts.Endpoint)
+	}
+
+	return &S3Storage{
+		opts:        opts,
+		storageType: storageType,
+		endpoint:    endpoint,
+		client:      &http.Client{Timeout: opts.Timeout},
+		now:         time.Now,
+	}, nil
+}
+
+// Type returns the backend type
+func (s *S3Storage) Type() StorageType {
+	return s.storageType
+}
+
+// Put stores content at the given key with optional metadata
+func (s *S3Storage) Put(ctx context.Context, key string, content []byte, metadata *Metadata) error {
+	header := http.Header{}
+	header.Set(s3MetaChecksum, ComputeChecksum(content))
+	header.Set("Content-Type", "application/octet-stream")
+	if metadata != nil {
+		metaBytes, err := json.Marshal(metadata)
+		if err != nil {
+			return fmt.Errorf("failed to marshal metadata: %w", err)
+		}
+		header.Set(s3MetaMetadata, base64.StdEncoding.EncodeToString(metaBytes))
+		if metadata.ContentType != "" {
+			header.Set("Content-Type", metadata.ContentType)
+		}
+	}
+
+	resp, err := s.do(ctx, http.MethodPut, s.objectKey(key), nil, header, content)
+	if err != nil {
+		return fmt.Errorf("failed to put %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to put %s: %w", key, s3Error(resp))
+	}
+	return nil
+}
+
+// Get retrieves content from the given key
+func (s *S3Storage) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, s.objectKey(key), nil, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	default:
+		return nil, fmt.Errorf("failed to get %s: %w", key, s3Error(resp))
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	return content, nil
+}
+
+// GetInfo retrieves file information without content
+func (s *S3Storage) GetInfo(ctx context.Context, key string) (*FileInfo, error) {
+	header, err := s.head(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	size, _ := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	modifiedAt, _ := http.ParseTime(header.Get("Last-Modified"))
+	info := &FileInfo{
+		Key:         key,
+		Size:        size,
+		Checksum:    header.Get(s3MetaChecksum),
+		ContentType: header.Get("Content-Type"),
+		ModifiedAt:  modifiedAt,
+	}
+
+	if encoded := header.Get(s3MetaMetadata); encoded != "" {
+		if metaBytes, err := base64.StdEncoding.DecodeString(encoded); err == nil {
+			var metadata Metadata
+			if err := json.Unmarshal(metaBytes, &metadata); err == nil {
+				info.Metadata = &metadata
+			}
+		}
+	}
+
+	// Objects written by other tools carry no checksum
+	if info.Checksum == "" {
+		content, err := s.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		info.Checksum = ComputeChecksum(content)
+	}
+
+	return info, nil
+}
+
+// Exists checks if a file exists at the given key
+func (s *S3Storage) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := s.head(ctx, key)
+	if err == nil {
+		return true, nil
+	}
+	if IsNotFound(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// Delete removes a file at the given key
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, s.objectKey(key), nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return fmt.Errorf("failed to delete %s: %w", key, s3Error(resp))
+	}
+}
+
+// s3ListResult is the response of a ListObjectsV2 request
+type s3ListResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List returns all keys matching the given prefix
+func (s *S3Storage) List(ctx context.Context, prefix string) ([]string, error) {
+	keys := []string{}
+	token := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", s.objectKey(prefix))
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := s.do(ctx, http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := s3Error(resp)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list files: %w", err)
+		}
+
+		var result s3ListResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode file list: %w", err)
+		}
+
+		for _, object := range result.Contents {
+			keys = append(keys, s.storageKey(object.Key))
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return keys, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// GetChecksum returns the checksum for a file
+func (s *S3Storage) GetChecksum(ctx context.Context, key string) (string, error) {
+	info, err := s.GetInfo(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	return info.Checksum, nil
+}
+
+// head returns the headers of an object
+func (s *S3Storage) head(ctx context.Context, key string) (http.Header, error) {
+	resp, err := s.do(ctx, http.MethodHead, s.objectKey(key), nil, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Header, nil
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	default:
+		return nil, fmt.Errorf("failed to stat %s: %w", key, s3Error(resp))
+	}
+}
+
+// objectKey converts a storage key to an object key under the prefix
+func (s *S3Storage) objectKey(key string) string {
+	key = strings.TrimPrefix(key, "/")
+	if s.opts.Prefix == "" {
+		return key
+	}
+	return s.opts.Prefix + "/" + key
+}
+
+// storageKey converts an object key back to a storage key
+func (s *S3Storage) storageKey(objectKey string) string {
+	if s.opts.Prefix == "" {
+		return objectKey
+	}
+	return strings.TrimPrefix(objectKey, s.opts.Prefix+"/")
+}
+
+// do sends a signed request for an object, or for the bucket when objectKey
+// is empty
+func (s *S3Storage) do(ctx context.Context, method, objectKey string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	u := *s.endpoint
+	if s.opts.ForcePathStyle {
+		u.Path = "/" + s.opts.Bucket + "/" + objectKey
+	} else {
+		u.Host = s.opts.Bucket + "." + u.Host
+		u.Path = "/" + objectKey
+	}
+	// Send the path exactly as it is signed
+	u.RawPath = s3Escape(u.Path, true)
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if s.opts.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.opts.SessionToken)
+	}
+	s.sign(req, body)
+
+	return s.client.Do(req)
+}
+
+// sign adds an AWS Signature Version 4 Authorization header to req, signing
+// the host and every header already set
+func (s *S3Storage) sign(req *http.Request, body []byte) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3Escape(req.URL.Path, true),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.opts.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.opts.SecretAccessKey), date)
+	key = hmacSHA256(key, s.opts.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.opts.AccessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+// canonicalQuery encodes query parameters sorted by name, as signing requires
+func canonicalQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		for _, value := range query[name] {
+			parts = append(parts, s3Escape(name, false)+"="+s3Escape(value, false))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3Escape percent-encodes every byte except unreserved characters and,
+// when keepSlash is set, slashes
+func s3Escape(s string, keepSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '.', c == '_', c == '~', c == '/' && keepSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// s3Error builds an error from an unexpected response
+func s3Error(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	var e struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	if xml.Unmarshal(body, &e) == nil && e.Code != "" {
+		return fmt.Errorf("%s: %s (HTTP %d)", e.Code, e.Message, resp.StatusCode)
+	}
+	return fmt.Errorf("unexpected HTTP %d", resp.StatusCode)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
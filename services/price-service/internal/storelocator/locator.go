@@ -0,0 +1,186 @@
+// Package storelocator reconciles auto-registered stores with the official
+// store-locator listings chains publish, filling in coordinates and opening
+// hours and flagging stores no listing entry matches.
+package storelocator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	appconfig "github.com/kosarica/price-service/config"
+	"github.com/kosarica/price-service/internal/chainsettings"
+	httpclient "github.com/kosarica/price-service/internal/http"
+	"github.com/kosarica/price-service/internal/http/ratelimit"
+)
+
+// Entry is a store as listed by a chain's store locator
+type Entry struct {
+	LocatorID  string          `json:"locatorId"`
+	Name       string          `json:"name"`
+	Address    string          `json:"address"`
+	City       string          `json:"city"`
+	PostalCode string          `json:"postalCode"`
+	Latitude   *float64        `json:"latitude"`
+	Longitude  *float64        `json:"longitude"`
+	Hours      json.RawMessage `json:"hours,omitempty"` // As published by the locator
+}
+
+// Fetcher lists a chain's stores from its store locator
+type Fetcher interface {
+	Fetch(ctx context.Context) ([]Entry, error)
+}
+
+var (
+	mu       sync.RWMutex
+	fetchers = make(map[string]Fetcher)
+)
+
+// Register sets the store locator fetcher of a chain, replacing any earlier one
+func Register(chainSlug string, f Fetcher) {
+	mu.Lock()
+	defer mu.Unlock()
+	fetchers[chainSlug] = f
+}
+
+// Get returns the store locator fetcher of a chain
+func Get(chainSlug string) (Fetcher, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	f, ok := fetchers[chainSlug]
+	return f, ok
+}
+
+// Chains returns the chains with a store locator fetcher, sorted
+func Chains() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	chains := make([]string, 0, len(fetchers))
+	for chainSlug := range fetchers {
+		chains = append(chains, chainSlug)
+	}
+	sort.Strings(chains)
+	return chains
+}
+
+// Configure registers a JSON fetcher for every chain with a locator in the
+// config
+func Configure(chains map[string]appconfig.StoreLocatorChainConfig) {
+	for chainSlug, cfg := range chains {
+		if cfg.URL == "" {
+			continue
+		}
+		Register(chainSlug, &JSONFetcher{ChainSlug: chainSlug, Config: cfg})
+	}
+}
+
+// JSONFetcher reads a store locator that returns its stores as JSON. Config
+// gives the path of the store list and of each field within a store as dot
+// separated keys, with numbers indexing arrays; an empty items path reads a
+// top-level list.
+type JSONFetcher struct {
+	ChainSlug string
+	Config    appconfig.StoreLocatorChainConfig
+}
+
+// Fetch downloads the locator and extracts its stores. Entries without a
+// locator ID or address are dropped, as they cannot be matched.
+func (f *JSONFetcher) Fetch(ctx context.Context) ([]Entry, error) {
+	client := httpclient.NewClient(chainsettings.RateLimit(f.ChainSlug, ratelimit.DefaultConfig()))
+	body, err := client.GetBytes(f.Config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch store locator: %w", err)
+	}
+	return parseJSONLocator(body, f.Config)
+}
+
+// parseJSONLocator extracts the stores of a JSON store locator response
+func parseJSONLocator(body []byte, cfg appconfig.StoreLocatorChainConfig) ([]Entry, error) {
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode store locator: %w", err)
+	}
+
+	items, ok := lookup(doc, cfg.ItemsPath).([]any)
+	if !ok {
+		return nil, fmt.Errorf("store locator has no list at %q", cfg.ItemsPath)
+	}
+
+	fields := cfg.Fields
+	entries := make([]Entry, 0, len(items))
+	for _, item := range items {
+		entry := Entry{
+			LocatorID:  stringValue(lookup(item, fields.ID)),
+			Name:       stringValue(lookup(item, fields.Name)),
+			Address:    stringValue(lookup(item, fields.Address)),
+			City:       stringValue(lookup(item, fields.City)),
+			PostalCode: stringValue(lookup(item, fields.PostalCode)),
+			Latitude:   floatValue(lookup(item, fields.Latitude)),
+			Longitude:  floatValue(lookup(item, fields.Longitude)),
+		}
+		if fields.Hours != "" {
+			if hours := lookup(item, fields.Hours); hours != nil {
+				entry.Hours, _ = json.Marshal(hours)
+			}
+		}
+		if entry.LocatorID == "" || entry.Address == "" {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// lookup follows a dot separated path into decoded JSON; an empty path
+// returns v itself. Returns nil when the path does not exist.
+func lookup(v any, path string) any {
+	if path == "" {
+		return v
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]any:
+			v = node[key]
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil
+			}
+			v = node[i]
+		default:
+			return nil
+		}
+	}
+	return v
+}
+
+// stringValue returns a JSON string or number as a trimmed string
+func stringValue(v any) string {
+	switch s := v.(type) {
+	case string:
+		return strings.TrimSpace(s)
+	case float64:
+		return strconv.FormatFloat(s, 'f', -1, 64)
+	}
+	return ""
+}
+
+// floatValue returns a JSON number, or a string holding one with a decimal
+// point or comma, as a float
+func floatValue(v any) *float64 {
+	switch n := v.(type) {
+	case float64:
+		return &n
+	case string:
+		f, err := strconv.ParseFloat(strings.Replace(strings.TrimSpace(n), ",", ".", 1), 64)
+		if err != nil {
+			return nil
+		}
+		return &f
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package storelocator
+
+import (
+	"testing"
+
+	appconfig "github.com/kosarica/price-service/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJSONLocator(t *testing.T) {
+	body := []byte(`{"data": {"stores": [
+		{"id": 101, "name": "Ilica", "address": {"street": "Ilica 242", "city": "Zagreb", "zip": "10000"},
+		 "location": {"lat": 45.8150, "lng": "15,9819"}, "hours": {"mon": "07-21", "sun": null}},
+		{"id": 102, "name": "No address", "address": {"city": "Split"}},
+		{"name": "No ID", "address": {"street": "Savska 41", "city": "Zagreb"}}
+	]}}`)
+	cfg := appconfig.StoreLocatorChainConfig{
+		ItemsPath: "data.stores",
+		Fields: appconfig.StoreLocatorFieldsConfig{
+			ID:         "id",
+			Name:       "name",
+			Address:    "address.street",
+			City:       "address.city",
+			PostalCode: "address.zip",
+			Latitude:   "location.lat",
+			Longitude:  "location.lng",
+			Hours:      "hours",
+		},
+	}
+
+	entries, err := parseJSONLocator(body, cfg)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "entries without an ID or address cannot be matched")
+
+	e := entries[0]
+	assert.Equal(t, "101", e.LocatorID)
+	assert.Equal(t, "Ilica 242", e.Address)
+	assert.Equal(t, "10000", e.PostalCode)
+	require.NotNil(t, e.Latitude)
+	require.NotNil(t, e.Longitude)
+	assert.Equal(t, 45.815, *e.Latitude)
+	assert.Equal(t, 15.9819, *e.Longitude)
+	assert.JSONEq(t, `{"mon": "07-21", "sun": null}`, string(e.Hours))
+
+	_, err = parseJSONLocator(body, appconfig.StoreLocatorChainConfig{ItemsPath: "data.missing"})
+	assert.Error(t, err)
+}
+
+func TestLookup(t *testing.T) {
+	doc := map[string]any{"a": []any{map[string]any{"b": "x"}}}
+	assert.Equal(t, "x", lookup(doc, "a.0.b"))
+	assert.Nil(t, lookup(doc, "a.1.b"))
+	assert.Nil(t, lookup(doc, "a.b"))
+	assert.Equal(t, doc, lookup(doc, ""))
+}
@@ -0,0 +1,173 @@
+package storelocator
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Store is a registered store reconciled against a locator
+type Store struct {
+	ID         string
+	Name       string
+	Address    string
+	City       string
+	PostalCode string
+}
+
+// Match pairs a store with the locator entry its address matches
+type Match struct {
+	StoreID    string
+	Entry      Entry
+	Similarity float64
+}
+
+// addressFiller holds address words that locators and price files add or
+// leave out at will
+var addressFiller = map[string]bool{
+	"ulica": true,
+	"ul":    true,
+	"hr":    true,
+}
+
+var diacritics = strings.NewReplacer(
+	"č", "c", "ć", "c", "š", "s", "ž", "z", "đ", "dj",
+)
+
+// addressTokens returns the words of an address, lower case and without
+// diacritics, punctuation or filler words
+func addressTokens(address string) []string {
+	address = diacritics.Replace(strings.ToLower(address))
+	words := strings.FieldsFunc(address, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := words[:0]
+	for _, w := range words {
+		if !addressFiller[w] {
+			tokens = append(tokens, w)
+		}
+	}
+	return tokens
+}
+
+// normalizePlace returns a city or postal code in a comparable form
+func normalizePlace(s string) string {
+	return strings.Join(addressTokens(s), "")
+}
+
+// addressSimilarity scores how alike two addresses are, from 0 to 1, as the
+// Dice coefficient of their words. Addresses whose house numbers differ are
+// different stores on the same street, so the score is halved.
+func addressSimilarity(a, b string) float64 {
+	ta, tb := addressTokens(a), addressTokens(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+
+	setA := make(map[string]bool, len(ta))
+	for _, t := range ta {
+		setA[t] = true
+	}
+	setB := make(map[string]bool, len(tb))
+	for _, t := range tb {
+		setB[t] = true
+	}
+
+	shared := 0
+	for t := range setA {
+		if setB[t] {
+			shared++
+		}
+	}
+	score := 2 * float64(shared) / float64(len(setA)+len(setB))
+
+	numbersA, numbersB := houseNumbers(setA), houseNumbers(setB)
+	if len(numbersA) > 0 && len(numbersB) > 0 && !overlaps(numbersA, numbersB) {
+		score /= 2
+	}
+	return score
+}
+
+// houseNumbers returns the tokens starting with a digit
+func houseNumbers(tokens map[string]bool) map[string]bool {
+	numbers := make(map[string]bool)
+	for t := range tokens {
+		if unicode.IsDigit(rune(t[0])) {
+			numbers[t] = true
+		}
+	}
+	return numbers
+}
+
+func overlaps(a, b map[string]bool) bool {
+	for t := range a {
+		if b[t] {
+			return true
+		}
+	}
+	return false
+}
+
+// samePlace reports whether a store and an entry may be in the same place:
+// their postal codes, and their cities, agree wherever both are known
+func samePlace(s Store, e Entry) bool {
+	if s.PostalCode != "" && e.PostalCode != "" && normalizePlace(s.PostalCode) != normalizePlace(e.PostalCode) {
+		return false
+	}
+	if s.City != "" && e.City != "" && normalizePlace(s.City) != normalizePlace(e.City) {
+		return false
+	}
+	return true
+}
+
+// MatchStores pairs stores with locator entries by address similarity. Each
+// store and each entry is used at most once, the most similar pairs first;
+// pairs below minSimilarity or in different places are not matched. Returns
+// the matches in store order and the IDs of the unmatched stores.
+func MatchStores(stores []Store, entries []Entry, minSimilarity float64) ([]Match, []string) {
+	type pair struct {
+		store, entry int
+		similarity   float64
+	}
+
+	var pairs []pair
+	for si, s := range stores {
+		if s.Address == "" {
+			continue
+		}
+		for ei, e := range entries {
+			if !samePlace(s, e) {
+				continue
+			}
+			if sim := addressSimilarity(s.Address, e.Address); sim >= minSimilarity {
+				pairs = append(pairs, pair{si, ei, sim})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].similarity > pairs[j].similarity
+	})
+
+	storeMatch := make(map[int]pair)
+	entryUsed := make(map[int]bool)
+	for _, p := range pairs {
+		if _, ok := storeMatch[p.store]; ok || entryUsed[p.entry] {
+			continue
+		}
+		storeMatch[p.store] = p
+		entryUsed[p.entry] = true
+	}
+
+	matches := make([]Match, 0, len(storeMatch))
+	unmatched := make([]string, 0)
+	for si, s := range stores {
+		p, ok := storeMatch[si]
+		if !ok {
+			unmatched = append(unmatched, s.ID)
+			continue
+		}
+		matches = append(matches, Match{StoreID: s.ID, Entry: entries[p.entry], Similarity: p.similarity})
+	}
+	return matches, unmatched
+}
@@ -0,0 +1,43 @@
+package storelocator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddressSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, addressSimilarity("Ulica grada Vukovara 10", "Grada Vukovara 10"))
+	assert.Equal(t, 1.0, addressSimilarity("Savska cesta 41", "savska  cesta 41."))
+	assert.Equal(t, 1.0, addressSimilarity("Ilica 242", "ILICA 242"))
+	assert.Equal(t, 1.0, addressSimilarity("Šetalište Kneza Višeslava 3", "Setaliste kneza Viseslava 3"))
+
+	// Same street, another house number
+	assert.Less(t, addressSimilarity("Ilica 242", "Ilica 10"), 0.5)
+	assert.Equal(t, 0.0, addressSimilarity("", "Ilica 10"))
+}
+
+func TestMatchStores(t *testing.T) {
+	stores := []Store{
+		{ID: "s1", Address: "Ilica 242", City: "Zagreb"},
+		{ID: "s2", Address: "Ul. Ivana Gundulića 5", City: "Split", PostalCode: "21000"},
+		{ID: "s3", Address: "Ilica 242", City: "Rijeka"},  // Same street name, other city
+		{ID: "s4", City: "Zagreb"},                        // No address to match by
+		{ID: "s5", Address: "Ilica 242a", City: "Zagreb"}, // Entry already taken by s1
+	}
+	entries := []Entry{
+		{LocatorID: "e1", Address: "Ilica 242", City: "ZAGREB"},
+		{LocatorID: "e2", Address: "Ivana Gundulica 5", City: "Split", PostalCode: "21 000"},
+		{LocatorID: "e3", Address: "Vukovarska 1", City: "Rijeka"},
+	}
+
+	matches, unmatched := MatchStores(stores, entries, 0.6)
+
+	if assert.Len(t, matches, 2) {
+		assert.Equal(t, "s1", matches[0].StoreID)
+		assert.Equal(t, "e1", matches[0].Entry.LocatorID)
+		assert.Equal(t, "s2", matches[1].StoreID)
+		assert.Equal(t, "e2", matches[1].Entry.LocatorID)
+	}
+	assert.Equal(t, []string{"s3", "s4", "s5"}, unmatched)
+}
@@ -0,0 +1,270 @@
+package storelocator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kosarica/price-service/internal/lanes"
+	"github.com/rs/zerolog"
+)
+
+// ErrNoLocator is returned when syncing a chain without a store locator
+var ErrNoLocator = errors.New("no store locator configured")
+
+// SyncResult summarizes the reconciliation of a chain's stores with its
+// store locator
+type SyncResult struct {
+	ChainSlug         string `json:"chainSlug" jsonschema:"required"`
+	Entries           int    `json:"entries" jsonschema:"required"` // Stores listed by the locator
+	Stores            int    `json:"stores" jsonschema:"required"`  // Registered stores reconciled
+	Matched           int    `json:"matched" jsonschema:"required"`
+	CoordinatesFilled int    `json:"coordinatesFilled" jsonschema:"required"`
+	Unmatched         int    `json:"unmatched" jsonschema:"required"`
+}
+
+// UnmatchedStore is a store no locator entry matched, for review
+type UnmatchedStore struct {
+	StoreID    string    `json:"storeId" jsonschema:"required"`
+	StoreName  string    `json:"storeName" jsonschema:"required"`
+	ChainSlug  string    `json:"chainSlug" jsonschema:"required"`
+	Address    *string   `json:"address"`
+	City       *string   `json:"city"`
+	PostalCode *string   `json:"postalCode"`
+	CheckedAt  time.Time `json:"checkedAt" jsonschema:"required"`
+}
+
+// Syncer periodically reconciles the stores of every chain with a store
+// locator
+type Syncer struct {
+	pool          *pgxpool.Pool
+	logger        *zerolog.Logger
+	minSimilarity float64
+	interval      time.Duration
+	stopChan      chan struct{}
+}
+
+// NewSyncer creates a new store locator syncer
+func NewSyncer(pool *pgxpool.Pool, logger *zerolog.Logger, minSimilarity float64, interval time.Duration) *Syncer {
+	return &Syncer{
+		pool:          pool,
+		logger:        logger,
+		minSimilarity: minSimilarity,
+		interval:      interval,
+		stopChan:      make(chan struct{}),
+	}
+}
+
+// Start begins the periodic sync
+func (s *Syncer) Start(ctx context.Context) {
+	s.logger.Info().
+		Dur("interval", s.interval).
+		Strs("chains", Chains()).
+		Msg("Starting store locator sync")
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info().Msg("Store locator sync stopping (context cancelled)")
+			return
+		case <-s.stopChan:
+			s.logger.Info().Msg("Store locator sync stopping (stop signal)")
+			return
+		case <-ticker.C:
+			for _, chainSlug := range Chains() {
+				if ctx.Err() != nil {
+					break
+				}
+				if _, err := s.SyncChain(ctx, chainSlug); err != nil {
+					s.logger.Error().Err(err).Str("chain", chainSlug).Msg("Failed to sync stores with store locator")
+				}
+			}
+		}
+	}
+}
+
+// Stop signals the syncer to stop
+func (s *Syncer) Stop() {
+	close(s.stopChan)
+}
+
+// SyncChain reconciles a chain's physical stores with its store locator.
+// Matched stores get the entry's coordinates where they have none and its
+// opening hours; unmatched stores are flagged for review. A locator that
+// fails or lists no stores changes nothing.
+func (s *Syncer) SyncChain(ctx context.Context, chainSlug string) (*SyncResult, error) {
+	fetcher, ok := Get(chainSlug)
+	if !ok {
+		return nil, fmt.Errorf("%w for chain %s", ErrNoLocator, chainSlug)
+	}
+
+	entries, err := fetcher.Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("store locator of chain %s lists no stores", chainSlug)
+	}
+
+	ctx = lanes.WithLane(ctx, lanes.Batch)
+	release, err := lanes.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	stores, err := s.loadStores(ctx, chainSlug)
+	if err != nil {
+		return nil, err
+	}
+	matches, unmatched := MatchStores(stores, entries, s.minSimilarity)
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin store locator sync: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result := &SyncResult{
+		ChainSlug: chainSlug,
+		Entries:   len(entries),
+		Stores:    len(stores),
+		Matched:   len(matches),
+		Unmatched: len(unmatched),
+	}
+
+	for _, m := range matches {
+		tag, err := tx.Exec(ctx, `
+			UPDATE stores
+			SET latitude = $2, longitude = $3, updated_at = NOW()
+			WHERE id = $1 AND $2::text IS NOT NULL AND $3::text IS NOT NULL
+			  AND (latitude IS NULL OR latitude = '' OR longitude IS NULL OR longitude = '')
+		`, m.StoreID, formatCoordinate(m.Entry.Latitude), formatCoordinate(m.Entry.Longitude))
+		if err != nil {
+			return nil, fmt.Errorf("failed to fill store coordinates: %w", err)
+		}
+		result.CoordinatesFilled += int(tag.RowsAffected())
+
+		var hours any
+		if m.Entry.Hours != nil {
+			hours = string(m.Entry.Hours)
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE stores
+			SET opening_hours = COALESCE($2::jsonb, opening_hours), updated_at = NOW()
+			WHERE id = $1
+		`, m.StoreID, hours)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set store opening hours: %w", err)
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO store_locator_matches (store_id, chain_slug, status, locator_id, locator_address, similarity, checked_at)
+			VALUES ($1, $2, 'matched', $3, $4, $5, NOW())
+			ON CONFLICT (store_id) DO UPDATE SET
+				status = EXCLUDED.status,
+				locator_id = EXCLUDED.locator_id,
+				locator_address = EXCLUDED.locator_address,
+				similarity = EXCLUDED.similarity,
+				checked_at = EXCLUDED.checked_at
+		`, m.StoreID, chainSlug, m.Entry.LocatorID, m.Entry.Address, m.Similarity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record store locator match: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO store_locator_matches (store_id, chain_slug, status, checked_at)
+		SELECT store_id, $2, 'unmatched', NOW()
+		FROM unnest($1::text[]) AS store_id
+		ON CONFLICT (store_id) DO UPDATE SET
+			status = EXCLUDED.status,
+			locator_id = NULL,
+			locator_address = NULL,
+			similarity = NULL,
+			checked_at = EXCLUDED.checked_at
+	`, unmatched, chainSlug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to flag unmatched stores: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit store locator sync: %w", err)
+	}
+
+	s.logger.Info().
+		Str("chain", chainSlug).
+		Int("entries", result.Entries).
+		Int("matched", result.Matched).
+		Int("coordinatesFilled", result.CoordinatesFilled).
+		Int("unmatched", result.Unmatched).
+		Msg("Synced stores with store locator")
+	return result, nil
+}
+
+// loadStores returns the chain's physical stores still in use
+func (s *Syncer) loadStores(ctx context.Context, chainSlug string) ([]Store, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT id, name, COALESCE(address, ''), COALESCE(city, ''), COALESCE(postal_code, '')
+		FROM stores
+		WHERE chain_slug = $1
+		  AND NOT COALESCE(is_virtual, false)
+		  AND COALESCE(status, 'active') NOT IN ('rejected', 'merged')
+		ORDER BY id
+	`, chainSlug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stores: %w", err)
+	}
+	defer rows.Close()
+
+	var stores []Store
+	for rows.Next() {
+		var st Store
+		if err := rows.Scan(&st.ID, &st.Name, &st.Address, &st.City, &st.PostalCode); err != nil {
+			return nil, fmt.Errorf("failed to scan store: %w", err)
+		}
+		stores = append(stores, st)
+	}
+	return stores, rows.Err()
+}
+
+// ListUnmatchedStores returns the stores the last sync of their chain could
+// not match to a locator entry, optionally for one chain
+func ListUnmatchedStores(ctx context.Context, pool *pgxpool.Pool, chainSlug string) ([]UnmatchedStore, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT m.store_id, s.name, m.chain_slug, s.address, s.city, s.postal_code, m.checked_at
+		FROM store_locator_matches m
+		JOIN stores s ON s.id = m.store_id
+		WHERE m.status = 'unmatched' AND ($1 = '' OR m.chain_slug = $1)
+		ORDER BY m.chain_slug, s.name, m.store_id
+	`, chainSlug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unmatched stores: %w", err)
+	}
+	defer rows.Close()
+
+	stores := make([]UnmatchedStore, 0)
+	for rows.Next() {
+		var st UnmatchedStore
+		if err := rows.Scan(&st.StoreID, &st.StoreName, &st.ChainSlug, &st.Address, &st.City, &st.PostalCode, &st.CheckedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan unmatched store: %w", err)
+		}
+		stores = append(stores, st)
+	}
+	return stores, rows.Err()
+}
+
+// formatCoordinate returns a coordinate as stores keep it, as text
+func formatCoordinate(v *float64) *string {
+	if v == nil {
+		return nil
+	}
+	s := strconv.FormatFloat(*v, 'f', -1, 64)
+	return &s
+}
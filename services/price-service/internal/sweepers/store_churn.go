@@ -0,0 +1,249 @@
+package sweepers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kosarica/price-service/internal/lanes"
+	"github.com/rs/zerolog"
+)
+
+// StoreGroupPeriod is one period of a store's price group membership
+type StoreGroupPeriod struct {
+	PriceGroupID string     `json:"priceGroupId" jsonschema:"required"`
+	ValidFrom    time.Time  `json:"validFrom" jsonschema:"required"`
+	ValidTo      *time.Time `json:"validTo,omitempty"` // nil while current
+}
+
+// StoreChurn is a store flagged for changing price groups too often, with
+// its group history over the churn window
+type StoreChurn struct {
+	StoreID       string             `json:"storeId" jsonschema:"required"`
+	StoreName     string             `json:"storeName" jsonschema:"required"`
+	ChainSlug     string             `json:"chainSlug" jsonschema:"required"`
+	Reassignments int                `json:"reassignments" jsonschema:"required"`
+	WindowDays    int                `json:"windowDays" jsonschema:"required"`
+	FlaggedAt     time.Time          `json:"flaggedAt" jsonschema:"required"`
+	UpdatedAt     time.Time          `json:"updatedAt" jsonschema:"required"`
+	History       []StoreGroupPeriod `json:"history" jsonschema:"required"`
+}
+
+// StoreChurnSweeper periodically flags stores reassigned to another price
+// group threshold or more times within the window. Frequent reassignment
+// usually means an unstable price hash or inconsistent parsing.
+type StoreChurnSweeper struct {
+	pool       *pgxpool.Pool
+	logger     *zerolog.Logger
+	windowDays int
+	threshold  int
+	interval   time.Duration
+	stopChan   chan struct{}
+}
+
+// NewStoreChurnSweeper creates a new sweeper for store price group churn
+func NewStoreChurnSweeper(pool *pgxpool.Pool, logger *zerolog.Logger, windowDays, threshold int, interval time.Duration) *StoreChurnSweeper {
+	return &StoreChurnSweeper{
+		pool:       pool,
+		logger:     logger,
+		windowDays: windowDays,
+		threshold:  threshold,
+		interval:   interval,
+		stopChan:   make(chan struct{}),
+	}
+}
+
+// Start begins the periodic churn sweep
+func (s *StoreChurnSweeper) Start(ctx context.Context) {
+	s.logger.Info().
+		Dur("interval", s.interval).
+		Int("windowDays", s.windowDays).
+		Int("threshold", s.threshold).
+		Msg("Starting store churn sweeper")
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info().Msg("Store churn sweeper stopping (context cancelled)")
+			return
+		case <-s.stopChan:
+			s.logger.Info().Msg("Store churn sweeper stopping (stop signal)")
+			return
+		case <-ticker.C:
+			if err := s.FlagChurningStores(ctx); err != nil {
+				s.logger.Error().Err(err).Msg("Failed to flag churning stores")
+			}
+		}
+	}
+}
+
+// Stop signals the sweeper to stop
+func (s *StoreChurnSweeper) Stop() {
+	close(s.stopChan)
+}
+
+// FlagChurningStores recounts each store's reassignments within the window,
+// flags the stores at or above the threshold and clears the flags of stores
+// that have settled. A reassignment is a membership period starting within
+// the window that follows an earlier period of the same store.
+func (s *StoreChurnSweeper) FlagChurningStores(ctx context.Context) error {
+	ctx = lanes.WithLane(ctx, lanes.Batch)
+	release, err := lanes.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	since := time.Now().UTC().AddDate(0, 0, -s.windowDays)
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin store churn sweep: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		CREATE TEMP TABLE churning_stores ON COMMIT DROP AS
+		SELECT sgh.store_id, s.chain_slug, COUNT(*)::int AS reassignments
+		FROM store_group_history sgh
+		JOIN stores s ON s.id = sgh.store_id
+		WHERE sgh.valid_from >= $1
+		  AND EXISTS (
+			SELECT 1 FROM store_group_history prev
+			WHERE prev.store_id = sgh.store_id
+			  AND prev.valid_from < sgh.valid_from
+		  )
+		GROUP BY sgh.store_id, s.chain_slug
+		HAVING COUNT(*) >= $2
+	`, since, s.threshold)
+	if err != nil {
+		return fmt.Errorf("failed to count store reassignments: %w", err)
+	}
+
+	cleared, err := tx.Exec(ctx, `
+		DELETE FROM store_group_churn
+		WHERE store_id NOT IN (SELECT store_id FROM churning_stores)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to clear settled stores: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		INSERT INTO store_group_churn (store_id, chain_slug, reassignments, window_days, flagged_at, updated_at)
+		SELECT store_id, chain_slug, reassignments, $1, now(), now()
+		FROM churning_stores
+		ON CONFLICT (store_id) DO UPDATE SET
+			chain_slug = EXCLUDED.chain_slug,
+			reassignments = EXCLUDED.reassignments,
+			window_days = EXCLUDED.window_days,
+			updated_at = EXCLUDED.updated_at
+		RETURNING store_id, chain_slug, reassignments, flagged_at = updated_at
+	`, s.windowDays)
+	if err != nil {
+		return fmt.Errorf("failed to flag churning stores: %w", err)
+	}
+
+	flagged := 0
+	for rows.Next() {
+		var storeID, chainSlug string
+		var reassignments int
+		var isNew bool
+		if err := rows.Scan(&storeID, &chainSlug, &reassignments, &isNew); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan churning store: %w", err)
+		}
+		flagged++
+		if isNew {
+			s.logger.Warn().
+				Str("store", storeID).
+				Str("chain", chainSlug).
+				Int("reassignments", reassignments).
+				Int("windowDays", s.windowDays).
+				Msg("Store changes price group too often")
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to flag churning stores: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit store churn sweep: %w", err)
+	}
+
+	s.logger.Info().
+		Int("flagged", flagged).
+		Int64("cleared", cleared.RowsAffected()).
+		Msg("Swept store price group churn")
+	return nil
+}
+
+// ListChurningStores returns the flagged stores, most reassigned first,
+// optionally for one chain, each with its group history since the start of
+// its churn window
+func ListChurningStores(ctx context.Context, pool *pgxpool.Pool, chainSlug string) ([]StoreChurn, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT c.store_id, s.name, c.chain_slug, c.reassignments, c.window_days,
+		       c.flagged_at, c.updated_at
+		FROM store_group_churn c
+		JOIN stores s ON s.id = c.store_id
+		WHERE ($1 = '' OR c.chain_slug = $1)
+		ORDER BY c.reassignments DESC, c.store_id
+	`, chainSlug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query churning stores: %w", err)
+	}
+
+	stores := make([]StoreChurn, 0)
+	for rows.Next() {
+		sc := StoreChurn{History: make([]StoreGroupPeriod, 0)}
+		if err := rows.Scan(&sc.StoreID, &sc.StoreName, &sc.ChainSlug, &sc.Reassignments, &sc.WindowDays, &sc.FlaggedAt, &sc.UpdatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan churning store: %w", err)
+		}
+		stores = append(stores, sc)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query churning stores: %w", err)
+	}
+
+	for i := range stores {
+		history, err := queryStoreGroupHistory(ctx, pool, stores[i].StoreID, stores[i].UpdatedAt.AddDate(0, 0, -stores[i].WindowDays))
+		if err != nil {
+			return nil, err
+		}
+		stores[i].History = history
+	}
+	return stores, nil
+}
+
+// queryStoreGroupHistory returns a store's group membership periods still
+// open at or started after since, newest first
+func queryStoreGroupHistory(ctx context.Context, pool *pgxpool.Pool, storeID string, since time.Time) ([]StoreGroupPeriod, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT price_group_id, valid_from, valid_to
+		FROM store_group_history
+		WHERE store_id = $1
+		  AND (valid_to IS NULL OR valid_to >= $2)
+		ORDER BY valid_from DESC
+	`, storeID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query store group history: %w", err)
+	}
+	defer rows.Close()
+
+	history := make([]StoreGroupPeriod, 0)
+	for rows.Next() {
+		var p StoreGroupPeriod
+		if err := rows.Scan(&p.PriceGroupID, &p.ValidFrom, &p.ValidTo); err != nil {
+			return nil, fmt.Errorf("failed to scan store group history: %w", err)
+		}
+		history = append(history, p)
+	}
+	return history, rows.Err()
+}
@@ -0,0 +1,146 @@
+package sweepers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// setupStoreChurnDB starts Postgres with stores, their group history and the
+// churn table from its migration
+func setupStoreChurnDB(t *testing.T) *pgxpool.Pool {
+	if testing.Short() {
+		t.Skip("skipping store churn test in short mode (requires Docker)")
+	}
+
+	ctx := context.Background()
+	container, err := postgres.Run(ctx, "postgres:16-alpine",
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(30*time.Second)),
+	)
+	require.NoError(t, err, "Failed to start postgres container")
+	t.Cleanup(func() { testcontainers.TerminateContainer(container) })
+
+	connStr, err := container.ConnectionString(ctx, "sslmode=disable")
+	require.NoError(t, err)
+	pool, err := pgxpool.New(ctx, connStr)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	_, err = pool.Exec(ctx, `
+		CREATE TABLE stores (
+			id TEXT PRIMARY KEY,
+			chain_slug TEXT NOT NULL,
+			name TEXT NOT NULL
+		);
+		CREATE TABLE store_group_history (
+			id BIGSERIAL PRIMARY KEY,
+			store_id TEXT NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+			price_group_id TEXT NOT NULL,
+			valid_from TIMESTAMPTZ NOT NULL,
+			valid_to TIMESTAMPTZ
+		);
+	`)
+	require.NoError(t, err)
+
+	migration, err := os.ReadFile("../../migrations/0036_add_store_group_churn.sql")
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, string(migration))
+	require.NoError(t, err)
+	return pool
+}
+
+// TestStoreChurnSweep flags stores reassigned threshold or more times within
+// the window, warns once per newly flagged store, clears settled stores and
+// lists the flagged ones with their history
+func TestStoreChurnSweep(t *testing.T) {
+	pool := setupStoreChurnDB(t)
+	ctx := context.Background()
+
+	// Each store's membership periods, as days ago; the last is current
+	periods := map[string][]int{
+		"store-a": {20, 6, 4, 2, 1}, // 4 reassignments in the window
+		"store-b": {30, 3, 2},       // 2, below the threshold
+		"store-c": {5, 3, 2, 1},     // 3, its first period is no reassignment
+		"store-d": {40},             // settled long ago
+	}
+	_, err := pool.Exec(ctx, `
+		INSERT INTO stores (id, chain_slug, name) VALUES
+			('store-a', 'konzum', 'Konzum A'), ('store-b', 'konzum', 'Konzum B'),
+			('store-c', 'lidl', 'Lidl C'), ('store-d', 'lidl', 'Lidl D');
+		INSERT INTO store_group_churn (store_id, chain_slug, reassignments, window_days)
+		VALUES ('store-d', 'lidl', 6, 7);
+	`)
+	require.NoError(t, err)
+	for storeID, daysAgo := range periods {
+		for i, from := range daysAgo {
+			var to *int
+			if i+1 < len(daysAgo) {
+				to = &daysAgo[i+1]
+			}
+			_, err := pool.Exec(ctx, `
+				INSERT INTO store_group_history (store_id, price_group_id, valid_from, valid_to)
+				VALUES ($1, $2, NOW() - make_interval(days => $3), NOW() - make_interval(days => $4::int))
+			`, storeID, fmt.Sprintf("%s-group-%d", storeID, i), from, to)
+			require.NoError(t, err)
+		}
+	}
+
+	var logs bytes.Buffer
+	logger := zerolog.New(&logs)
+	sweeper := NewStoreChurnSweeper(pool, &logger, 7, 3, time.Hour)
+
+	require.NoError(t, sweeper.FlagChurningStores(ctx))
+
+	stores, err := ListChurningStores(ctx, pool, "")
+	require.NoError(t, err)
+	require.Len(t, stores, 2)
+	assert.Equal(t, "store-a", stores[0].StoreID)
+	assert.Equal(t, "Konzum A", stores[0].StoreName)
+	assert.Equal(t, 4, stores[0].Reassignments)
+	assert.Equal(t, 7, stores[0].WindowDays)
+	// The period ending within the window is part of the history
+	require.Len(t, stores[0].History, 5)
+	assert.Nil(t, stores[0].History[0].ValidTo)
+	assert.Equal(t, "store-a-group-4", stores[0].History[0].PriceGroupID)
+	assert.Equal(t, "store-c", stores[1].StoreID)
+	assert.Equal(t, 3, stores[1].Reassignments)
+	flaggedAt := stores[0].FlaggedAt
+
+	lidl, err := ListChurningStores(ctx, pool, "lidl")
+	require.NoError(t, err)
+	require.Len(t, lidl, 1)
+	assert.Equal(t, "store-c", lidl[0].StoreID)
+
+	// A second sweep keeps the flags without warning again
+	require.NoError(t, sweeper.FlagChurningStores(ctx))
+	stores, err = ListChurningStores(ctx, pool, "konzum")
+	require.NoError(t, err)
+	require.Len(t, stores, 1)
+	assert.Equal(t, flaggedAt, stores[0].FlaggedAt)
+	assert.Equal(t, 2, strings.Count(logs.String(), "Store changes price group too often"))
+
+	// Without the churn table both the sweep and the listing fail
+	_, err = pool.Exec(ctx, `DROP TABLE store_group_churn`)
+	require.NoError(t, err)
+	err = sweeper.FlagChurningStores(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to clear settled stores")
+	_, err = ListChurningStores(ctx, pool, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to query churning stores")
+}
@@ -9,6 +9,7 @@ const (
 	FileTypeCSV  FileType = "csv"
 	FileTypeXML  FileType = "xml"
 	FileTypeXLSX FileType = "xlsx"
+	FileTypeJSON FileType = "json"
 	FileTypeZIP  FileType = "zip"
 )
 
@@ -17,6 +18,9 @@ type NormalizedRow struct {
 	StoreIdentifier string     `json:"storeIdentifier"`
 	ExternalID      *string    `json:"externalId,omitempty"`
 	Name            string     `json:"name"`
+	// NameI18n holds names in other languages by ISO 639-1 code, for chains
+	// publishing bilingual names; Name stays the Croatian one
+	NameI18n        map[string]string `json:"nameI18n,omitempty"`
 	Description     *string    `json:"description,omitempty"`
 	Category        *string    `json:"category,omitempty"`
 	Subcategory     *string    `json:"subcategory,omitempty"`
@@ -112,6 +116,9 @@ type ExpandedFile struct {
 type ParseOptions struct {
 	SkipInvalid *bool `json:"skipInvalid,omitempty"`
 	Limit       *int  `json:"limit,omitempty"`
+	// ColumnMappingOverride overlays the adapter's column mapping; nil uses
+	// the chain's enabled override from the database, if any
+	ColumnMappingOverride map[string]string `json:"columnMappingOverride,omitempty"`
 }
 
 // ParseError represents a parsing error
@@ -161,10 +168,13 @@ const (
 type FileStatus string
 
 const (
-	FileStatusPending    FileStatus = "pending"
-	FileStatusProcessing FileStatus = "processing"
-	FileStatusCompleted  FileStatus = "completed"
-	FileStatusFailed     FileStatus = "failed"
+	FileStatusPending     FileStatus = "pending"
+	FileStatusDownloading FileStatus = "downloading"
+	FileStatusParsing     FileStatus = "parsing"
+	FileStatusPersisting  FileStatus = "persisting"
+	FileStatusCompleted   FileStatus = "completed"
+	FileStatusFailed      FileStatus = "failed"
+	FileStatusSkipped     FileStatus = "skipped"
 )
 
 // ErrorSeverity represents severity levels
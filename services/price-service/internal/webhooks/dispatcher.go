@@ -0,0 +1,300 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kosarica/price-service/internal/outbox"
+	"github.com/rs/zerolog"
+)
+
+// batchSize is the number of deliveries claimed per dispatcher pass
+const batchSize = 50
+
+// sendTimeout bounds a single webhook request
+const sendTimeout = 10 * time.Second
+
+// sendConcurrency is the number of deliveries of a batch sent at once, so a
+// slow receiver holds up only its own deliveries
+const sendConcurrency = 8
+
+// claimLease is how long a claimed delivery is held by its dispatcher. It
+// outlasts sending a whole batch with every receiver timing out.
+const claimLease = 5 * time.Minute
+
+// maxErrorLength bounds the response excerpt kept as a delivery's last error
+const maxErrorLength = 500
+
+// Delivery is a pending event for a webhook, with where and how to send it
+type Delivery struct {
+	ID        int64
+	WebhookID string
+	URL       string
+	Secret    string
+	Event     outbox.Event
+}
+
+// Dispatcher periodically POSTs pending webhook deliveries. Deliveries are
+// leased with FOR UPDATE SKIP LOCKED, so several dispatchers can run side by
+// side, and sent outside any transaction; a failed delivery is retried with exponential backoff until it has
+// been attempted maxAttempts times. Deliveries of disabled webhooks wait
+// until the webhook is enabled again.
+type Dispatcher struct {
+	pool        *pgxpool.Pool
+	logger      *zerolog.Logger
+	interval    time.Duration
+	maxAttempts int
+	client      *http.Client
+	stopChan    chan struct{}
+}
+
+// NewDispatcher creates a new webhook dispatcher
+func NewDispatcher(pool *pgxpool.Pool, logger *zerolog.Logger, interval time.Duration, maxAttempts int) *Dispatcher {
+	return &Dispatcher{
+		pool:        pool,
+		logger:      logger,
+		interval:    interval,
+		maxAttempts: maxAttempts,
+		client:      &http.Client{Timeout: sendTimeout},
+		stopChan:    make(chan struct{}),
+	}
+}
+
+// Start sends pending deliveries on every interval until stopped
+func (d *Dispatcher) Start(ctx context.Context) {
+	d.logger.Info().
+		Dur("interval", d.interval).
+		Int("max_attempts", d.maxAttempts).
+		Msg("Starting webhook dispatcher")
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			d.logger.Info().Msg("Webhook dispatcher stopping (context cancelled)")
+			return
+		case <-d.stopChan:
+			d.logger.Info().Msg("Webhook dispatcher stopping (stop signal)")
+			return
+		case <-ticker.C:
+			if err := d.RunOnce(ctx); err != nil {
+				d.logger.Error().Err(err).Msg("Webhook dispatcher pass failed")
+			}
+		}
+	}
+}
+
+// Stop signals the dispatcher to stop
+func (d *Dispatcher) Stop() {
+	close(d.stopChan)
+}
+
+// RunOnce sends pending deliveries until a batch comes back short, then
+// prunes old finished deliveries
+func (d *Dispatcher) RunOnce(ctx context.Context) error {
+	for {
+		claimed, err := d.dispatchBatch(ctx)
+		if err != nil {
+			return err
+		}
+		if claimed < batchSize {
+			break
+		}
+	}
+
+	tag, err := d.pool.Exec(ctx, `
+		DELETE FROM webhook_deliveries
+		WHERE COALESCE(delivered_at, failed_at) < $1
+	`, time.Now().Add(-outbox.DeliveredRetention))
+	if err != nil {
+		return fmt.Errorf("failed to prune webhook deliveries: %w", err)
+	}
+	if tag.RowsAffected() > 0 {
+		d.logger.Debug().Int64("count", tag.RowsAffected()).Msg("Pruned webhook deliveries")
+	}
+	return nil
+}
+
+// dispatchBatch claims one batch of due deliveries and sends them, recording
+// each outcome as soon as it is known. It returns the number claimed.
+func (d *Dispatcher) dispatchBatch(ctx context.Context) (int, error) {
+	deliveries, err := d.claim(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	queue := make(chan Delivery)
+	for i := 0; i < sendConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for dl := range queue {
+				if err := d.deliver(ctx, dl); err != nil {
+					mu.Lock()
+					errs = append(errs, err)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, dl := range deliveries {
+		queue <- dl
+	}
+	close(queue)
+	wg.Wait()
+
+	return len(deliveries), errors.Join(errs...)
+}
+
+// claim leases one batch of due deliveries by moving them claimLease into the
+// future, so no row lock is held while they are sent. A delivery whose
+// outcome is never recorded becomes due again when its lease runs out.
+func (d *Dispatcher) claim(ctx context.Context) ([]Delivery, error) {
+	rows, err := d.pool.Query(ctx, `
+		UPDATE webhook_deliveries d
+		SET available_at = NOW() + $2::interval
+		FROM webhooks w
+		WHERE w.id = d.webhook_id
+		  AND d.id IN (
+			SELECT pd.id
+			FROM webhook_deliveries pd
+			JOIN webhooks pw ON pw.id = pd.webhook_id
+			WHERE pd.delivered_at IS NULL AND pd.failed_at IS NULL
+			  AND pd.available_at <= NOW()
+			  AND pw.enabled
+			ORDER BY pd.id
+			LIMIT $1
+			FOR UPDATE OF pd SKIP LOCKED
+		  )
+		RETURNING d.id, d.webhook_id, w.url, w.secret, d.event_type, d.aggregate_id, d.payload, d.created_at, d.attempts
+	`, batchSize, claimLease.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []Delivery
+	for rows.Next() {
+		var dl Delivery
+		if err := rows.Scan(&dl.ID, &dl.WebhookID, &dl.URL, &dl.Secret, &dl.Event.Type, &dl.Event.AggregateID,
+			&dl.Event.Payload, &dl.Event.CreatedAt, &dl.Event.Attempts); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		dl.Event.ID = dl.ID
+		deliveries = append(deliveries, dl)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// deliver sends one claimed delivery and records the outcome
+func (d *Dispatcher) deliver(ctx context.Context, dl Delivery) error {
+	status, sendErr := d.send(ctx, dl)
+	var lastStatus *int
+	if status != 0 {
+		lastStatus = &status
+	}
+	attempts := dl.Event.Attempts + 1
+
+	var err error
+	switch {
+	case sendErr == nil:
+		_, err = d.pool.Exec(ctx, `
+			UPDATE webhook_deliveries
+			SET attempts = attempts + 1,
+			    last_status = $2,
+			    last_error = NULL,
+			    delivered_at = NOW()
+			WHERE id = $1
+		`, dl.ID, lastStatus)
+	case attempts >= d.maxAttempts:
+		d.logger.Error().
+			Err(sendErr).
+			Int64("delivery_id", dl.ID).
+			Str("webhook_id", dl.WebhookID).
+			Str("event_type", dl.Event.Type).
+			Int("attempts", attempts).
+			Msg("Giving up on webhook delivery")
+
+		_, err = d.pool.Exec(ctx, `
+			UPDATE webhook_deliveries
+			SET attempts = attempts + 1,
+			    last_status = $2,
+			    last_error = $3,
+			    failed_at = NOW()
+			WHERE id = $1
+		`, dl.ID, lastStatus, sendErr.Error())
+	default:
+		d.logger.Warn().
+			Err(sendErr).
+			Int64("delivery_id", dl.ID).
+			Str("webhook_id", dl.WebhookID).
+			Str("event_type", dl.Event.Type).
+			Int("attempts", attempts).
+			Msg("Failed to deliver webhook")
+
+		_, err = d.pool.Exec(ctx, `
+			UPDATE webhook_deliveries
+			SET attempts = attempts + 1,
+			    last_status = $2,
+			    last_error = $3,
+			    available_at = NOW() + $4::interval
+			WHERE id = $1
+		`, dl.ID, lastStatus, sendErr.Error(), outbox.Backoff(attempts).String())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery %d: %w", dl.ID, err)
+	}
+	return nil
+}
+
+// send POSTs a delivery's event as JSON, signed with the webhook's secret.
+// It returns the response status, 0 when there was no response, and an
+// error unless the status is 2xx.
+func (d *Dispatcher) send(ctx context.Context, dl Delivery) (int, error) {
+	body, err := json.Marshal(dl.Event)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dl.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderWebhookID, dl.WebhookID)
+	req.Header.Set(HeaderDeliveryID, strconv.FormatInt(dl.ID, 10))
+	req.Header.Set(HeaderEventType, dl.Event.Type)
+	req.Header.Set(HeaderSignature, "sha256="+outbox.Sign([]byte(dl.Secret), body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		excerpt, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorLength))
+		return resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(excerpt))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,193 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kosarica/price-service/internal/outbox"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+func newTestDispatcher() *Dispatcher {
+	logger := zerolog.Nop()
+	return NewDispatcher(nil, &logger, 0, 3)
+}
+
+func TestSendSignsDelivery(t *testing.T) {
+	var received outbox.Event
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		assert.Equal(t, "whk_1", r.Header.Get(HeaderWebhookID))
+		assert.Equal(t, "7", r.Header.Get(HeaderDeliveryID))
+		assert.Equal(t, outbox.EventRunFailed, r.Header.Get(HeaderEventType))
+		assert.Equal(t, "sha256="+outbox.Sign([]byte("s3cret"), body), r.Header.Get(HeaderSignature))
+		require.NoError(t, json.Unmarshal(body, &received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	status, err := newTestDispatcher().send(context.Background(), Delivery{
+		ID:        7,
+		WebhookID: "whk_1",
+		URL:       server.URL,
+		Secret:    "s3cret",
+		Event: outbox.Event{
+			ID:          7,
+			Type:        outbox.EventRunFailed,
+			AggregateID: "run_1",
+			Payload:     json.RawMessage(`{"runId":"run_1","status":"failed"}`),
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, status)
+	assert.Equal(t, "run_1", received.AggregateID)
+	assert.JSONEq(t, `{"runId":"run_1","status":"failed"}`, string(received.Payload))
+}
+
+func TestSendFailsOnNon2xx(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("maintenance\n"))
+	}))
+	defer server.Close()
+
+	status, err := newTestDispatcher().send(context.Background(), Delivery{
+		ID:    1,
+		URL:   server.URL,
+		Event: outbox.Event{ID: 1, Type: outbox.EventRunCompleted},
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Contains(t, err.Error(), "maintenance")
+}
+
+func TestValidEventType(t *testing.T) {
+	assert.True(t, ValidEventType(outbox.EventRunCompleted))
+	assert.True(t, ValidEventType(outbox.EventRunFailed))
+	assert.False(t, ValidEventType(outbox.EventPriceChanged))
+	assert.False(t, ValidEventType("ingestion.run.*"))
+}
+
+// setupDispatcherDB starts Postgres with the webhook tables from their migration
+func setupDispatcherDB(t *testing.T) *pgxpool.Pool {
+	if testing.Short() {
+		t.Skip("skipping webhook dispatcher test in short mode (requires Docker)")
+	}
+
+	ctx := context.Background()
+	container, err := postgres.Run(ctx, "postgres:16-alpine",
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(30*time.Second)),
+	)
+	require.NoError(t, err, "Failed to start postgres container")
+	t.Cleanup(func() { testcontainers.TerminateContainer(container) })
+
+	connStr, err := container.ConnectionString(ctx, "sslmode=disable")
+	require.NoError(t, err)
+	pool, err := pgxpool.New(ctx, connStr)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	migration, err := os.ReadFile("../../migrations/0043_add_webhooks.sql")
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, string(migration))
+	require.NoError(t, err)
+	return pool
+}
+
+// TestDispatcherHangingReceiver verifies a receiver that never answers holds
+// up neither the other webhooks nor the delivery rows, and that delivered
+// events are not sent again
+func TestDispatcherHangingReceiver(t *testing.T) {
+	pool := setupDispatcherDB(t)
+	ctx := context.Background()
+
+	hanging := make(chan struct{}, 1)
+	hangServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hanging <- struct{}{}
+		<-r.Context().Done()
+	}))
+	defer hangServer.Close()
+
+	var received atomic.Int32
+	okServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer okServer.Close()
+
+	_, err := pool.Exec(ctx, `
+		INSERT INTO webhooks (id, url, secret) VALUES ('whk_hang', $1, 's1'), ('whk_ok', $2, 's2');
+		INSERT INTO webhook_deliveries (id, webhook_id, event_type, aggregate_id, payload) VALUES
+			(1, 'whk_hang', 'ingestion.run.completed', 'run_1', '{}'),
+			(2, 'whk_ok', 'ingestion.run.completed', 'run_1', '{}');
+	`, hangServer.URL, okServer.URL)
+	require.NoError(t, err)
+
+	dispatcher := newTestDispatcher()
+	dispatcher.pool = pool
+	dispatcher.client.Timeout = 2 * time.Second
+
+	done := make(chan error, 1)
+	go func() { done <- dispatcher.RunOnce(ctx) }()
+	<-hanging
+
+	// While the receiver hangs the other webhook is served and no row is locked
+	require.Eventually(t, func() bool {
+		var delivered bool
+		err := pool.QueryRow(ctx, `SELECT delivered_at IS NOT NULL FROM webhook_deliveries WHERE id = 2`).Scan(&delivered)
+		return err == nil && delivered
+	}, time.Second, 20*time.Millisecond)
+
+	tx, err := pool.Begin(ctx)
+	require.NoError(t, err)
+	_, err = tx.Exec(ctx, `SELECT id FROM webhook_deliveries FOR UPDATE NOWAIT`)
+	require.NoError(t, err, "deliveries stay locked while being sent")
+	require.NoError(t, tx.Rollback(ctx))
+
+	// A second dispatcher does not pick up the leased delivery
+	claimed, err := dispatcher.claim(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	require.NoError(t, <-done)
+
+	var attempts int
+	var lastError *string
+	var delivered bool
+	err = pool.QueryRow(ctx, `
+		SELECT attempts, last_error, delivered_at IS NOT NULL FROM webhook_deliveries WHERE id = 1
+	`).Scan(&attempts, &lastError, &delivered)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempts)
+	require.NotNil(t, lastError)
+	assert.False(t, delivered)
+
+	// The timed out delivery is due again after its backoff, the delivered one never
+	_, err = pool.Exec(ctx, `UPDATE webhook_deliveries SET available_at = NOW() WHERE id = 1`)
+	require.NoError(t, err)
+	go func() { done <- dispatcher.RunOnce(ctx) }()
+	<-hanging
+	require.NoError(t, <-done)
+	assert.Equal(t, int32(1), received.Load())
+}
@@ -0,0 +1,66 @@
+// Package webhooks notifies webhooks registered through the admin API of
+// ingestion run events. Producers call Enqueue inside the transaction that
+// finishes the run, writing a delivery per subscribed webhook; the Dispatcher
+// POSTs the deliveries, signed with each webhook's secret, and retries them
+// with backoff.
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/kosarica/price-service/internal/outbox"
+)
+
+// Webhook request headers
+const (
+	HeaderWebhookID  = "X-Webhook-Id"
+	HeaderDeliveryID = "X-Webhook-Delivery-Id"
+	HeaderEventType  = "X-Webhook-Event-Type"
+	HeaderSignature  = "X-Webhook-Signature"
+)
+
+// EventTypes are the events webhooks can subscribe to
+var EventTypes = []string{
+	outbox.EventRunCompleted,
+	outbox.EventRunFailed,
+}
+
+// ValidEventType reports whether webhooks can subscribe to an event type
+func ValidEventType(eventType string) bool {
+	return slices.Contains(EventTypes, eventType)
+}
+
+// Enqueue writes a delivery of an event to every enabled webhook subscribed
+// to it. Pass the transaction that performs the state change so the
+// deliveries are committed or rolled back with it.
+func Enqueue(ctx context.Context, db outbox.Execer, eventType, aggregateID string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s payload: %w", eventType, err)
+	}
+
+	_, err = db.Exec(ctx, `
+		INSERT INTO webhook_deliveries (webhook_id, event_type, aggregate_id, payload)
+		SELECT id, $1, $2, $3
+		FROM webhooks
+		WHERE enabled AND (cardinality(event_types) = 0 OR $1 = ANY(event_types))
+	`, eventType, aggregateID, data)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue %s webhook deliveries: %w", eventType, err)
+	}
+	return nil
+}
+
+// NewSecret returns a random secret for signing a webhook's deliveries
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,6 @@
+-- Migration: Add price_changes to ingestion_runs
+-- This migration adds a per-run counter of persisted price changes so that
+-- ingestion stats can report price churn without scanning store_item_state
+
+ALTER TABLE ingestion_runs
+ADD COLUMN IF NOT EXISTS price_changes integer DEFAULT 0;
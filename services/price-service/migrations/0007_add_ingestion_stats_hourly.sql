@@ -0,0 +1,33 @@
+-- Migration: Add hourly ingestion stats rollups
+-- Precomputed per-chain, per-hour aggregates of ingestion_runs and ingestion_errors
+-- so that GetStats does not scan the full runs table for 30-day windows.
+-- Rows are rebuilt by the stats rollup job; only hours before rolled_up_to are complete.
+
+CREATE TABLE IF NOT EXISTS ingestion_stats_hourly (
+    hour_start timestamp NOT NULL,
+    chain_slug text NOT NULL,
+    total_runs integer NOT NULL DEFAULT 0,
+    completed integer NOT NULL DEFAULT 0,
+    failed integer NOT NULL DEFAULT 0,
+    running integer NOT NULL DEFAULT 0,
+    pending integer NOT NULL DEFAULT 0,
+    total_files integer NOT NULL DEFAULT 0,
+    total_errors integer NOT NULL DEFAULT 0,
+    rows_persisted bigint NOT NULL DEFAULT 0,
+    price_changes bigint NOT NULL DEFAULT 0,
+    -- Finished runs only (started_at and completed_at set), used for throughput and percentiles
+    finished_files integer NOT NULL DEFAULT 0,
+    finished_duration_seconds double precision NOT NULL DEFAULT 0,
+    run_durations double precision[] NOT NULL DEFAULT '{}',
+    computed_at timestamp NOT NULL DEFAULT now(),
+    PRIMARY KEY (hour_start, chain_slug)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ingestion_stats_hourly_chain ON ingestion_stats_hourly(chain_slug, hour_start);
+
+-- Single-row watermark: hours strictly before rolled_up_to have been rolled up
+CREATE TABLE IF NOT EXISTS ingestion_stats_rollup_state (
+    id boolean PRIMARY KEY DEFAULT true CHECK (id),
+    rolled_up_to timestamp NOT NULL,
+    updated_at timestamp NOT NULL DEFAULT now()
+);
@@ -0,0 +1,10 @@
+-- Migration: Make the chains table the chain registry
+-- Adds an enabled flag and free-form metadata so chains can be added or
+-- disabled through the admin API instead of a code change
+
+ALTER TABLE chains
+ADD COLUMN IF NOT EXISTS enabled boolean NOT NULL DEFAULT true,
+ADD COLUMN IF NOT EXISTS metadata jsonb NOT NULL DEFAULT '{}'::jsonb,
+ADD COLUMN IF NOT EXISTS updated_at timestamp DEFAULT NOW();
+
+CREATE INDEX IF NOT EXISTS idx_chains_enabled ON chains(enabled);
@@ -0,0 +1,7 @@
+-- Migration: Add display metadata to chains
+-- Display name and brand color let the frontend render chains without
+-- hardcoding presentation data
+
+ALTER TABLE chains
+ADD COLUMN IF NOT EXISTS display_name text,
+ADD COLUMN IF NOT EXISTS brand_color text;
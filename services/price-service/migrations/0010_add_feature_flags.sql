@@ -0,0 +1,12 @@
+-- Migration: Add feature_flags table
+-- Flags gate risky features and can be limited to a set of chains for
+-- staged rollouts. An empty chains array enables the flag for every chain.
+
+CREATE TABLE IF NOT EXISTS feature_flags (
+  key text PRIMARY KEY,
+  description text,
+  enabled boolean NOT NULL DEFAULT false,
+  chains text[] NOT NULL DEFAULT '{}',
+  created_at timestamp DEFAULT NOW(),
+  updated_at timestamp DEFAULT NOW()
+);
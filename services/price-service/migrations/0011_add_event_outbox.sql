@@ -0,0 +1,19 @@
+-- Migration: Add event_outbox table
+-- Events are written in the same transaction as the state change they
+-- describe and delivered at least once by the outbox relay
+
+CREATE TABLE IF NOT EXISTS event_outbox (
+  id bigserial PRIMARY KEY,
+  event_type text NOT NULL,
+  aggregate_id text NOT NULL,
+  payload jsonb NOT NULL,
+  created_at timestamp NOT NULL DEFAULT NOW(),
+  available_at timestamp NOT NULL DEFAULT NOW(),
+  delivered_at timestamp,
+  attempts integer NOT NULL DEFAULT 0,
+  last_error text
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_outbox_pending
+  ON event_outbox(available_at, id)
+  WHERE delivered_at IS NULL;
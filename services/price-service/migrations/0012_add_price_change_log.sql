@@ -0,0 +1,22 @@
+-- Migration: Add price_change_log table
+-- Append-only log of per-item price changes written by persist; the
+-- bigserial id is the change data capture cursor for incremental loads
+
+CREATE TABLE IF NOT EXISTS price_change_log (
+  id bigserial PRIMARY KEY,
+  chain_slug text NOT NULL,
+  store_id text NOT NULL,
+  retailer_item_id text NOT NULL,
+  run_id text,
+  file_id text,
+  old_price integer,
+  new_price integer NOT NULL,
+  old_discount_price integer,
+  new_discount_price integer,
+  discount_start timestamp,
+  discount_end timestamp,
+  changed_at timestamp NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_change_log_chain_id
+  ON price_change_log(chain_slug, id);
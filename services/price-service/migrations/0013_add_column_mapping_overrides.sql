@@ -0,0 +1,12 @@
+-- Migration: Add column_mapping_overrides table
+-- Per-chain overlays on the compiled-in CSV column mapping, consulted by the
+-- adapters at parse time so a renamed column does not need a code change
+
+CREATE TABLE IF NOT EXISTS column_mapping_overrides (
+  chain_slug text PRIMARY KEY REFERENCES chains(slug) ON DELETE CASCADE,
+  mapping jsonb NOT NULL,
+  enabled boolean NOT NULL DEFAULT false,
+  description text,
+  created_at timestamp NOT NULL DEFAULT NOW(),
+  updated_at timestamp NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,10 @@
+-- Migration: Add warning_count to ingestion_files
+-- Number of parser and row validation warnings seen while parsing a file;
+-- the aggregated warnings themselves are stored in ingestion_errors with
+-- severity 'warning'
+
+ALTER TABLE ingestion_files
+  ADD COLUMN IF NOT EXISTS warning_count integer NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_ingestion_errors_run_severity
+  ON ingestion_errors(run_id, severity);
@@ -0,0 +1,18 @@
+-- Migration: Add raw_payloads table
+-- Raw source rows are stored zstd-compressed out of line and fetched lazily;
+-- retailer_items_failed references them instead of keeping raw_data inline.
+-- Existing rows are moved with `price-service rawdata migrate`.
+
+CREATE TABLE IF NOT EXISTS raw_payloads (
+  id text PRIMARY KEY,
+  encoding text NOT NULL DEFAULT 'zstd',
+  original_size integer NOT NULL,
+  data bytea NOT NULL,
+  created_at timestamp NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE retailer_items_failed
+  ADD COLUMN IF NOT EXISTS raw_payload_id text REFERENCES raw_payloads(id) ON DELETE SET NULL;
+
+ALTER TABLE retailer_items_failed
+  ALTER COLUMN raw_data DROP NOT NULL;
@@ -0,0 +1,23 @@
+-- Migration: Add optimization_results table
+-- Basket optimization results stored for callers that pass a user reference.
+-- Coordinates are rounded before they are written; rows are deleted after
+-- privacy.optimization_retention_days and can be exported or deleted per
+-- user reference through the admin privacy endpoints.
+
+CREATE TABLE IF NOT EXISTS optimization_results (
+  id text PRIMARY KEY,
+  user_ref text NOT NULL,
+  kind text NOT NULL,
+  chain_slug text NOT NULL,
+  latitude double precision,
+  longitude double precision,
+  request jsonb NOT NULL,
+  result jsonb NOT NULL,
+  created_at timestamp NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_optimization_results_user_ref
+  ON optimization_results(user_ref, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_optimization_results_created_at
+  ON optimization_results(created_at);
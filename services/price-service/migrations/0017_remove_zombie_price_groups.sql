@@ -0,0 +1,10 @@
+-- Migration: Remove zombie price groups
+-- Before each store was persisted in one transaction, a run could create a
+-- price group and then fail before inserting its prices, leaving a group
+-- with no prices. Those groups are deleted; their store_group_history rows
+-- go with them through ON DELETE CASCADE and the affected stores are
+-- assigned to a complete group by their next ingestion.
+
+DELETE FROM price_groups pg
+WHERE pg.item_count = 0
+  AND NOT EXISTS (SELECT 1 FROM group_prices gp WHERE gp.price_group_id = pg.id);
@@ -0,0 +1,12 @@
+-- Migration: Add retailer item identity hash
+-- Chains that publish no external ID had a new retailer item created for
+-- every row on every run. Such items are now identified by a hash of their
+-- normalized name, unit and unit quantity, unique per chain. Existing items
+-- keep a NULL hash until `price-service items dedup` merges their duplicates
+-- and backfills it.
+
+ALTER TABLE retailer_items ADD COLUMN IF NOT EXISTS identity_hash text;
+
+CREATE UNIQUE INDEX IF NOT EXISTS retailer_items_chain_identity_hash_uniq
+  ON retailer_items(chain_slug, identity_hash)
+  WHERE external_id IS NULL;
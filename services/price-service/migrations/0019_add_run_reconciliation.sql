@@ -0,0 +1,9 @@
+-- Migration: Add run reconciliation columns
+-- Files that do not count as processed (skipped, deduplicated against an
+-- earlier archive, or failed) record why in discrepancy_reason. When a run
+-- completes its totals are recomputed from its files and the share of files
+-- completed or deduplicated is stored in completion_percent.
+
+ALTER TABLE ingestion_files ADD COLUMN IF NOT EXISTS discrepancy_reason text;
+
+ALTER TABLE ingestion_runs ADD COLUMN IF NOT EXISTS completion_percent double precision;
@@ -0,0 +1,15 @@
+-- Migration: Add ingestion file status timestamps
+-- Ingestion files move pending -> downloading -> parsing -> persisting ->
+-- completed/failed/skipped. status_timestamps records when each status was
+-- entered, keyed by status, so per-stage durations can be reported.
+
+ALTER TABLE ingestion_files ADD COLUMN IF NOT EXISTS status_timestamps jsonb;
+
+-- Files were created after parsing in the 'processing' status, which is now
+-- 'persisting'
+UPDATE ingestion_files SET status = 'persisting' WHERE status = 'processing';
+
+UPDATE ingestion_files
+SET status_timestamps = jsonb_build_object('pending', created_at)
+    || CASE WHEN processed_at IS NOT NULL THEN jsonb_build_object(status, processed_at) ELSE '{}'::jsonb END
+WHERE status_timestamps IS NULL AND created_at IS NOT NULL;
@@ -0,0 +1,7 @@
+-- Migration: Add discount validity window to group prices
+-- Parsers read when a discount starts and ends, but only store_item_state kept
+-- it. Group prices carry it too so the price cache can tell current discounts
+-- from expired or upcoming ones. NULL bounds are open.
+
+ALTER TABLE group_prices ADD COLUMN IF NOT EXISTS discount_start timestamptz;
+ALTER TABLE group_prices ADD COLUMN IF NOT EXISTS discount_end timestamptz;
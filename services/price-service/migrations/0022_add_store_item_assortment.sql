@@ -0,0 +1,13 @@
+-- Migration: Add store item assortment
+-- Stores share a price group when their prices match, but a store need not
+-- carry every item its group prices. When a chain publishes one file per store,
+-- that file is the store's assortment; it is recorded here per store and item
+-- and replaced by each new file. Stores without rows here have no assortment
+-- signal and are assumed to carry their whole group.
+
+CREATE TABLE IF NOT EXISTS store_item_assortment (
+    store_id text NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    retailer_item_id text NOT NULL REFERENCES retailer_items(id) ON DELETE CASCADE,
+    last_seen_at timestamptz NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (store_id, retailer_item_id)
+);
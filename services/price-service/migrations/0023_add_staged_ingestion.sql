@@ -0,0 +1,35 @@
+-- Migration: Add staged ingestion
+-- Runs of chains with the staged_ingestion flag write their parsed rows to
+-- staging tables instead of the live ones. Quality checks then decide whether
+-- the run is published automatically or held for review; publishing writes
+-- the staged rows to the live tables in one transaction.
+--
+-- staging_status is NULL for runs written directly, otherwise 'staging'
+-- while the run is in progress, 'staged' while it awaits publish, then
+-- 'published' or 'rejected'.
+
+ALTER TABLE ingestion_runs ADD COLUMN IF NOT EXISTS staging_status text;
+ALTER TABLE ingestion_runs ADD COLUMN IF NOT EXISTS quality_report jsonb;
+
+CREATE INDEX IF NOT EXISTS ingestion_runs_staging_status_idx
+    ON ingestion_runs(staging_status) WHERE staging_status IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS ingestion_staged_files (
+    file_id text PRIMARY KEY,
+    run_id text NOT NULL,
+    archive_id text,
+    discovered_file jsonb NOT NULL,
+    total_rows integer NOT NULL DEFAULT 0,
+    valid_rows integer NOT NULL DEFAULT 0,
+    created_at timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS ingestion_staged_files_run_id_idx ON ingestion_staged_files(run_id);
+
+-- One row per store of a staged file, holding its normalized rows
+CREATE TABLE IF NOT EXISTS ingestion_staged_rows (
+    file_id text NOT NULL REFERENCES ingestion_staged_files(file_id) ON DELETE CASCADE,
+    store_identifier text NOT NULL,
+    rows jsonb NOT NULL,
+    PRIMARY KEY (file_id, store_identifier)
+);
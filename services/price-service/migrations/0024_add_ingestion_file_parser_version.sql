@@ -0,0 +1,7 @@
+-- Migration: Add parser version to ingestion files
+-- Records which version of the chain adapter's parsing logic parsed each file,
+-- so files parsed before a parser fix can be found and reprocessed from their
+-- archives. Files parsed before this migration have no version and count as
+-- older than every version.
+
+ALTER TABLE ingestion_files ADD COLUMN IF NOT EXISTS parser_version text;
@@ -0,0 +1,17 @@
+-- Migration: Add item suggest indexes
+-- The typeahead endpoint completes item names and brands as the user types.
+-- Prefix matches use the text_pattern_ops indexes on the lowercased columns;
+-- matches inside a name (a later word, e.g. "mlij" in "Svježe mlijeko") use
+-- the trigram index.
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS retailer_items_name_prefix_idx
+    ON retailer_items (lower(name) text_pattern_ops);
+
+CREATE INDEX IF NOT EXISTS retailer_items_brand_prefix_idx
+    ON retailer_items (lower(brand) text_pattern_ops)
+    WHERE brand IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS retailer_items_name_trgm_idx
+    ON retailer_items USING gin (lower(name) gin_trgm_ops);
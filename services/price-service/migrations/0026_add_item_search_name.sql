@@ -0,0 +1,41 @@
+-- Migration: Add folded item search names
+-- Item search and typeahead match the query against search_name, the item name
+-- with Croatian diacritics folded (č/ć -> c, đ -> d, š -> s, ž -> z) and
+-- spelling variants unified ("ije" -> "je", "dj" -> "d"), so "MLIJEKO",
+-- "mlijeko" and "mljeko" all find the same items. The query is folded the same
+-- way by search.Fold in the service; keep the two in sync.
+-- Uppercase letters are translated too, as lower() only folds ASCII under the
+-- C collation.
+
+CREATE OR REPLACE FUNCTION search_fold(s text) RETURNS text
+    LANGUAGE sql IMMUTABLE PARALLEL SAFE
+AS $$
+    SELECT btrim(regexp_replace(
+        replace(replace(
+            translate(lower(s),
+                'čćđšžáàâäãåéèêëíìîïóòôöõúùûüýÿñČĆĐŠŽÁÀÂÄÃÅÉÈÊËÍÌÎÏÓÒÔÖÕÚÙÛÜÝŸÑ',
+                'ccdszaaaaaaeeeeiiiiooooouuuuyynccdszaaaaaaeeeeiiiiooooouuuuyyn'),
+            'ije', 'je'),
+            'dj', 'd'),
+        '\s+', ' ', 'g'))
+$$;
+
+ALTER TABLE retailer_items
+    ADD COLUMN IF NOT EXISTS search_name text
+    GENERATED ALWAYS AS (search_fold(name)) STORED;
+
+-- Prefix and substring matches, and similarity for the misspelling fallback
+CREATE INDEX IF NOT EXISTS retailer_items_search_name_prefix_idx
+    ON retailer_items (search_name text_pattern_ops);
+
+CREATE INDEX IF NOT EXISTS retailer_items_search_name_trgm_idx
+    ON retailer_items USING gin (search_name gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS retailer_items_brand_search_prefix_idx
+    ON retailer_items (search_fold(brand) text_pattern_ops)
+    WHERE brand IS NOT NULL;
+
+-- Superseded by the folded indexes above
+DROP INDEX IF EXISTS retailer_items_name_prefix_idx;
+DROP INDEX IF EXISTS retailer_items_brand_prefix_idx;
+DROP INDEX IF EXISTS retailer_items_name_trgm_idx;
@@ -0,0 +1,29 @@
+-- Migration: Add multi-language item names
+-- Some chains (Metro, Eurospin) publish names in a second language. They are
+-- kept in name_i18n as {"<ISO 639-1 code>": "<name>"}, next to the Croatian
+-- name. Products carry them too, copied from the item they are created from.
+-- search_name now folds the secondary names after the name, so searching
+-- "fresh milk" finds "Svježe mlijeko" where the chain publishes both.
+
+ALTER TABLE retailer_items ADD COLUMN IF NOT EXISTS name_i18n jsonb;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS name_i18n jsonb;
+
+-- The name followed by its secondary names in language order
+CREATE OR REPLACE FUNCTION search_name_text(name text, name_i18n jsonb) RETURNS text
+    LANGUAGE sql IMMUTABLE PARALLEL SAFE
+AS $$
+    SELECT concat_ws(' ', name,
+        (SELECT string_agg(value, ' ' ORDER BY key) FROM jsonb_each_text(name_i18n)))
+$$;
+
+-- Dropping the column drops its indexes; both are recreated below
+ALTER TABLE retailer_items DROP COLUMN IF EXISTS search_name;
+ALTER TABLE retailer_items
+    ADD COLUMN search_name text
+    GENERATED ALWAYS AS (search_fold(search_name_text(name, name_i18n))) STORED;
+
+CREATE INDEX IF NOT EXISTS retailer_items_search_name_prefix_idx
+    ON retailer_items (search_name text_pattern_ops);
+
+CREATE INDEX IF NOT EXISTS retailer_items_search_name_trgm_idx
+    ON retailer_items USING gin (search_name gin_trgm_ops);
@@ -0,0 +1,42 @@
+-- Migration: Add embedding refresh queue
+-- An item's cached embedding is computed from its name and brand. When either
+-- changes, by re-ingestion or an edit, the trigger below queues the item so
+-- the next AI matching run re-embeds it and re-evaluates its match candidates.
+-- Only items with a cached embedding are queued; the others are embedded on
+-- their first matching run anyway.
+
+CREATE TABLE IF NOT EXISTS embedding_refresh_queue (
+    retailer_item_id text PRIMARY KEY REFERENCES retailer_items(id) ON DELETE CASCADE,
+    enqueued_at timestamptz NOT NULL DEFAULT NOW(),
+    -- Failed refresh attempts, so failing items sort after the others
+    attempts integer NOT NULL DEFAULT 0,
+    last_error_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS embedding_refresh_queue_order_idx
+    ON embedding_refresh_queue (attempts, enqueued_at);
+
+CREATE OR REPLACE FUNCTION enqueue_embedding_refresh() RETURNS trigger
+    LANGUAGE plpgsql
+AS $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM retailer_item_embeddings WHERE retailer_item_id = NEW.id) THEN
+        INSERT INTO embedding_refresh_queue (retailer_item_id, enqueued_at)
+        VALUES (NEW.id, NOW())
+        ON CONFLICT (retailer_item_id) DO UPDATE SET
+            enqueued_at = EXCLUDED.enqueued_at,
+            attempts = 0,
+            last_error_at = NULL;
+    END IF;
+    RETURN NEW;
+END
+$$;
+
+DROP TRIGGER IF EXISTS retailer_items_embedding_refresh ON retailer_items;
+CREATE TRIGGER retailer_items_embedding_refresh
+    AFTER UPDATE OF name, brand, name_i18n ON retailer_items
+    FOR EACH ROW
+    WHEN (OLD.name IS DISTINCT FROM NEW.name
+       OR OLD.brand IS DISTINCT FROM NEW.brand
+       OR OLD.name_i18n IS DISTINCT FROM NEW.name_i18n)
+    EXECUTE FUNCTION enqueue_embedding_refresh();
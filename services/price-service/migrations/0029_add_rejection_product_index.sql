@@ -0,0 +1,8 @@
+-- Migration: Index match rejections by product
+-- AI matching looks up the rejections of its candidate products, for the item
+-- being matched and for items named like it, to never suggest a rejected pair
+-- again and to rank products reviewers keep rejecting lower. The primary key
+-- leads with the retailer item, so it cannot serve lookups by product.
+
+CREATE INDEX IF NOT EXISTS product_match_rejections_product_idx
+    ON product_match_rejections (rejected_product_id);
@@ -0,0 +1,25 @@
+-- Migration: Add product catalog audit log
+-- Admins merge duplicate products created by barcode and AI matching, and
+-- split products that wrongly group different retailer items. Each operation
+-- is logged with the product rows it replaced, so it can be traced and undone
+-- by hand.
+
+CREATE TABLE IF NOT EXISTS product_catalog_audit (
+    id bigserial PRIMARY KEY,
+    -- 'merge' or 'split'
+    action text NOT NULL,
+    -- Merge target, or the product split
+    product_id text NOT NULL,
+    -- Merged (deleted) products, or the product created by the split
+    other_product_ids text[] NOT NULL,
+    -- Retailer items moved between products
+    retailer_item_ids text[] NOT NULL DEFAULT '{}',
+    -- Merged products' rows, or the split product's row, before the operation
+    previous_state jsonb,
+    actor text,
+    reason text,
+    created_at timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS product_catalog_audit_product_idx
+    ON product_catalog_audit (product_id, created_at DESC);
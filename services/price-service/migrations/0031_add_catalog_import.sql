@@ -0,0 +1,27 @@
+-- Migration: Add external catalog import
+-- Product catalogs such as GS1 Croatia exports seed canonical products with
+-- verified barcodes, brands and net quantities. A canonical barcode confirmed
+-- by a catalog records which one and when. Where a catalog disagrees with an
+-- existing product, the disagreement is kept for review along with how the
+-- import resolved it ('kept' the existing value or 'overwritten' it).
+
+ALTER TABLE canonical_barcodes ADD COLUMN IF NOT EXISTS source text;
+ALTER TABLE canonical_barcodes ADD COLUMN IF NOT EXISTS verified_at timestamptz;
+
+CREATE TABLE IF NOT EXISTS catalog_import_conflicts (
+    id bigserial PRIMARY KEY,
+    source text NOT NULL,
+    barcode text NOT NULL,
+    product_id text NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    -- 'brand', 'net_content' or 'name'
+    field text NOT NULL,
+    existing_value text,
+    imported_value text,
+    resolution text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    -- Reimporting a catalog updates its conflicts instead of repeating them
+    UNIQUE (source, barcode, field)
+);
+
+CREATE INDEX IF NOT EXISTS catalog_import_conflicts_product_idx
+    ON catalog_import_conflicts (product_id);
@@ -0,0 +1,21 @@
+-- Migration: Add ingestion batches
+-- A bulk ingest trigger creates one API run per chain up front, in the order
+-- they will start, and groups them in a batch whose progress is the
+-- aggregate of its runs. Runs wait as 'pending' until a batch slot frees up.
+
+CREATE TABLE IF NOT EXISTS ingestion_batches (
+    id bigserial PRIMARY KEY,
+    -- Chains running at the same time
+    concurrency integer NOT NULL,
+    target_date text,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    -- Set when the last run of the batch has finished
+    completed_at timestamptz
+);
+
+ALTER TABLE ingestion_runs ADD COLUMN IF NOT EXISTS batch_id bigint REFERENCES ingestion_batches(id) ON DELETE SET NULL;
+-- Start order of the run within its batch
+ALTER TABLE ingestion_runs ADD COLUMN IF NOT EXISTS batch_position integer;
+
+CREATE INDEX IF NOT EXISTS ingestion_runs_batch_idx
+    ON ingestion_runs (batch_id, batch_position) WHERE batch_id IS NOT NULL;
@@ -0,0 +1,15 @@
+-- Migration: Add run baseline check
+-- A completed run is compared with the chain's run on the same weekday a
+-- week earlier: file count, row count and average price. A run deviating
+-- beyond the configured bands is 'suspect', and the price cache keeps the
+-- chain's previous prices until an admin clears it ('cleared') or a later run
+-- passes. average_price is recorded on every checked run so it can serve as a
+-- baseline.
+
+ALTER TABLE ingestion_runs ADD COLUMN IF NOT EXISTS average_price double precision;
+-- 'passed', 'suspect' or 'cleared'; NULL when there was no baseline
+ALTER TABLE ingestion_runs ADD COLUMN IF NOT EXISTS baseline_status text;
+ALTER TABLE ingestion_runs ADD COLUMN IF NOT EXISTS baseline_report jsonb;
+
+CREATE INDEX IF NOT EXISTS ingestion_runs_baseline_idx
+    ON ingestion_runs (chain_slug, completed_at DESC) WHERE baseline_status IS NOT NULL;
@@ -0,0 +1,19 @@
+-- Migration: Add anchor price compliance reports
+-- Croatian price rules require a discounted price to show the "sidrena
+-- cijena" (anchor price) it is reduced from. The compliance job counts, per
+-- chain, the store prices on an active discount that have no anchor price or
+-- an anchor set more recently than the configured minimum age allows. Each
+-- run adds one row per chain, so the history shows how chains improve.
+
+CREATE TABLE IF NOT EXISTS anchor_price_compliance_reports (
+    id bigserial PRIMARY KEY,
+    chain_slug text NOT NULL,
+    computed_at timestamp NOT NULL DEFAULT now(),
+    min_anchor_age_days integer NOT NULL,
+    discounted_prices integer NOT NULL DEFAULT 0,
+    missing_anchor integer NOT NULL DEFAULT 0,
+    young_anchor integer NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS anchor_price_compliance_reports_chain_idx
+    ON anchor_price_compliance_reports (chain_slug, computed_at DESC);
@@ -0,0 +1,18 @@
+-- Migration: Add API keys
+-- Third parties call the read-only /api/v1 routes with a key of their own
+-- instead of the internal token. Only the SHA-256 hash of a key is stored;
+-- the key itself is shown once when it is created. A revoked key stays for
+-- the record but no longer authenticates.
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id text PRIMARY KEY,
+    name text NOT NULL,
+    key_hash text NOT NULL UNIQUE,
+    -- First characters of the key, to tell keys apart without the key
+    key_prefix text NOT NULL,
+    -- Requests per second allowed for the key; NULL uses the default
+    rate_limit double precision,
+    created_at timestamp NOT NULL DEFAULT now(),
+    last_used_at timestamp,
+    revoked_at timestamp
+);
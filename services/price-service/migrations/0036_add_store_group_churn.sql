@@ -0,0 +1,18 @@
+-- Migration: Add store group churn flags
+-- A store reassigned to another price group on most days usually means its
+-- price hash is unstable or its files parse inconsistently. The churn sweeper
+-- counts each store's reassignments in store_group_history over a window and
+-- keeps a row here for every store at or above the threshold; stores that
+-- settle are removed on the next sweep.
+
+CREATE TABLE IF NOT EXISTS store_group_churn (
+    store_id text PRIMARY KEY REFERENCES stores(id) ON DELETE CASCADE,
+    chain_slug text NOT NULL,
+    reassignments integer NOT NULL,
+    window_days integer NOT NULL,
+    flagged_at timestamp NOT NULL DEFAULT now(),
+    updated_at timestamp NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS store_group_churn_chain_idx
+    ON store_group_churn (chain_slug, reassignments DESC);
@@ -0,0 +1,22 @@
+-- Migration: Add ingestion schedules
+-- One cron expression per chain; the scheduler starts an ingestion run for a
+-- chain when its next_run_at passes, unless a run of the chain is still
+-- pending or running. Schedules from the config are inserted at startup
+-- without overwriting schedules edited through the admin API.
+
+CREATE TABLE IF NOT EXISTS ingestion_schedules (
+    chain_slug text PRIMARY KEY,
+    cron_expression text NOT NULL,
+    enabled boolean NOT NULL DEFAULT true,
+    next_run_at timestamp,
+    last_triggered_at timestamp,
+    last_status text, -- started, skipped, completed or failed
+    last_run_id text,
+    last_error text,
+    created_at timestamp NOT NULL DEFAULT now(),
+    updated_at timestamp NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS ingestion_schedules_due_idx
+    ON ingestion_schedules (next_run_at)
+    WHERE enabled;
@@ -0,0 +1,17 @@
+-- Migration: Add per-chain ingest settings
+-- Overrides of a chain's compiled-in adapter settings and of the chains
+-- section of the config file, edited through the admin API. A NULL column
+-- keeps the config file value, or the adapter's own when the config file
+-- has none.
+
+CREATE TABLE IF NOT EXISTS chain_settings (
+    chain_slug text PRIMARY KEY REFERENCES chains(slug) ON DELETE CASCADE,
+    discovery_enabled boolean,
+    requests_per_second integer CHECK (requests_per_second > 0),
+    max_retries integer CHECK (max_retries >= 0),
+    csv_delimiter text,
+    csv_encoding text,
+    csv_has_header boolean,
+    created_at timestamp NOT NULL DEFAULT now(),
+    updated_at timestamp NOT NULL DEFAULT now()
+);
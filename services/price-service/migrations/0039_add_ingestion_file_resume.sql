@@ -0,0 +1,7 @@
+-- Migration: Record discovered files for resuming interrupted runs
+-- Keeps each ingestion file as its adapter discovered it, so a run left
+-- running by a restart can fetch its unfinished files again without
+-- rediscovering them. processed_chunks counts the file's stores persisted so
+-- far, so a file interrupted while persisting continues after them.
+
+ALTER TABLE ingestion_files ADD COLUMN IF NOT EXISTS discovered jsonb;
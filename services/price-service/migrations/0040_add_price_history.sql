@@ -0,0 +1,21 @@
+-- Migration: Add price_history table
+-- Daily price series of each item at each store. Persist writes a row for the
+-- day an item is first seen at a store and for every day its price or
+-- discount changes; the last change of a day wins. A day without a row keeps
+-- the price of the row before it. The retention job deletes rows past the
+-- retention period and compacts rows that repeat the price before them.
+
+CREATE TABLE IF NOT EXISTS price_history (
+    store_id text NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    retailer_item_id text NOT NULL REFERENCES retailer_items(id) ON DELETE CASCADE,
+    day date NOT NULL,
+    chain_slug text NOT NULL,
+    price integer NOT NULL,
+    discount_price integer,
+    unit_price integer,
+    recorded_at timestamp NOT NULL DEFAULT now(),
+    PRIMARY KEY (store_id, retailer_item_id, day)
+);
+
+CREATE INDEX IF NOT EXISTS price_history_day_idx
+    ON price_history (day);
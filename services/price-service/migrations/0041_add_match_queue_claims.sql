@@ -0,0 +1,9 @@
+-- Migration: Add review claims to the product match queue
+-- A reviewer opening a queue item claims it until claimed_until, so a second
+-- reviewer sees who is working on it instead of submitting a conflicting
+-- decision. Claims are renewed while the item stays open and expire on their
+-- own when a reviewer walks away; a decision clears the claim.
+
+ALTER TABLE product_match_queue
+    ADD COLUMN IF NOT EXISTS claimed_by text REFERENCES "user"(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS claimed_until timestamp with time zone;
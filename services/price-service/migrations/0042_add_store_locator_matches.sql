@@ -0,0 +1,21 @@
+-- Migration: Add store locator reconciliation
+-- Chains publish store-locator listings with precise addresses, coordinates
+-- and opening hours. The store locator sync matches each physical store to a
+-- listing entry by address and keeps the outcome here: matched stores get the
+-- entry's coordinates where they have none and its opening hours, unmatched
+-- stores are listed for review.
+
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS opening_hours jsonb;
+
+CREATE TABLE IF NOT EXISTS store_locator_matches (
+    store_id text PRIMARY KEY REFERENCES stores(id) ON DELETE CASCADE,
+    chain_slug text NOT NULL,
+    status text NOT NULL CHECK (status IN ('matched', 'unmatched')),
+    locator_id text,
+    locator_address text,
+    similarity real,
+    checked_at timestamp NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS store_locator_matches_status_idx
+    ON store_locator_matches (status, chain_slug);
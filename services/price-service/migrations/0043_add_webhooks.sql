@@ -0,0 +1,38 @@
+-- Migration: Add webhooks
+-- Webhooks registered through the admin API are notified of ingestion run
+-- events. A delivery row is written per subscribed webhook in the transaction
+-- that finishes the run; the dispatcher POSTs it, retrying with backoff until
+-- the webhook answers 2xx or the attempts run out.
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id text PRIMARY KEY,
+    url text NOT NULL,
+    secret text NOT NULL,
+    event_types text[] NOT NULL DEFAULT '{}', -- empty subscribes to every event
+    enabled boolean NOT NULL DEFAULT true,
+    description text,
+    created_at timestamp NOT NULL DEFAULT now(),
+    updated_at timestamp NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id bigserial PRIMARY KEY,
+    webhook_id text NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event_type text NOT NULL,
+    aggregate_id text NOT NULL,
+    payload jsonb NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    available_at timestamp NOT NULL DEFAULT now(),
+    delivered_at timestamp,
+    failed_at timestamp, -- set when the attempts ran out
+    last_status integer,
+    last_error text,
+    created_at timestamp NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx
+    ON webhook_deliveries (available_at)
+    WHERE delivered_at IS NULL AND failed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_idx
+    ON webhook_deliveries (webhook_id, id DESC);
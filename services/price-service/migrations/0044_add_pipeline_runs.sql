@@ -0,0 +1,16 @@
+-- Migration: Add pipeline runs
+-- `price-service daily` records each step of the nightly pipeline here, one
+-- row per day and step. Running it again for a day skips the steps already
+-- completed and retries the rest.
+
+CREATE TABLE IF NOT EXISTS pipeline_runs (
+    run_date date NOT NULL,
+    step text NOT NULL,
+    status text NOT NULL, -- running, completed, failed or skipped
+    attempts integer NOT NULL DEFAULT 0,
+    detail jsonb,
+    error text,
+    started_at timestamp NOT NULL DEFAULT now(),
+    finished_at timestamp,
+    PRIMARY KEY (run_date, step)
+);
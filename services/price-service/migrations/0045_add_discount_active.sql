@@ -0,0 +1,23 @@
+-- Migration: Add discount_active
+-- Whether a store_item_state discount window is active at a time, as the
+-- optimizer evaluates it. Bounds are UTC timestamps; one at midnight holds a
+-- date without a time and is a whole day in the service's time zone tz: the
+-- window starts when its first day starts there and lasts through its last.
+
+CREATE OR REPLACE FUNCTION discount_active(
+    discount_start timestamp,
+    discount_end timestamp,
+    at_time timestamptz,
+    tz text
+) RETURNS boolean
+    LANGUAGE sql STABLE PARALLEL SAFE
+AS $$
+    SELECT (discount_start IS NULL OR at_time >= CASE
+                WHEN discount_start::time = '00:00' THEN discount_start AT TIME ZONE tz
+                ELSE discount_start AT TIME ZONE 'UTC'
+            END)
+       AND (discount_end IS NULL OR CASE
+                WHEN discount_end::time = '00:00' THEN at_time < (discount_end + interval '1 day') AT TIME ZONE tz
+                ELSE at_time <= discount_end AT TIME ZONE 'UTC'
+            END)
+$$;
@@ -0,0 +1,22 @@
+-- Migration: Add ingestion_runs.updated_at
+-- When a run last changed, kept by a trigger. The stats rollup job buckets
+-- runs by created_at and rebuilds only recent hours; runs created earlier
+-- that change later, by finishing or being published, are found by
+-- updated_at and their hours rebuilt.
+
+ALTER TABLE ingestion_runs ADD COLUMN IF NOT EXISTS updated_at timestamp;
+
+CREATE OR REPLACE FUNCTION touch_ingestion_run() RETURNS trigger
+    LANGUAGE plpgsql
+AS $$
+BEGIN
+    NEW.updated_at := NOW();
+    RETURN NEW;
+END
+$$;
+
+DROP TRIGGER IF EXISTS ingestion_runs_touch ON ingestion_runs;
+CREATE TRIGGER ingestion_runs_touch
+    BEFORE UPDATE ON ingestion_runs
+    FOR EACH ROW
+    EXECUTE FUNCTION touch_ingestion_run();
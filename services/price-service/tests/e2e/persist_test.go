@@ -0,0 +1,131 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kosarica/price-service/internal/adapters/registry"
+	"github.com/kosarica/price-service/internal/chains"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/pipeline"
+	"github.com/kosarica/price-service/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestE2EPersistStoreIsolation persists a file listing two stores where one
+// store cannot be assigned to its price group. That store's transaction is
+// rolled back as a whole, while the other store's rows are committed.
+func TestE2EPersistStoreIsolation(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping e2e test in short mode")
+	}
+
+	ctx := context.Background()
+
+	postgresContainer, err := setupTestDatabase(ctx)
+	require.NoError(t, err)
+	defer postgresContainer.Terminate(ctx)
+
+	connStr, err := postgresContainer.ConnectionString(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, database.Connect(ctx, connStr, 10, 2, 0, 0))
+	defer database.Close()
+	setupReplaySchema(ctx, t)
+	require.NoError(t, registry.InitializeDefaultAdapters())
+
+	pool := database.Pool()
+	_, err = pool.Exec(ctx, `INSERT INTO chains (slug, name) VALUES ('metro', 'metro')`)
+	require.NoError(t, err)
+	require.NoError(t, chains.Refresh(ctx))
+
+	_, err = pool.Exec(ctx, `
+		INSERT INTO ingestion_runs (id, chain_slug, source, status, total_files, started_at)
+		VALUES ('run-isolation', 'metro', 'test', 'running', 1, NOW());
+		INSERT INTO ingestion_files (id, run_id, filename, file_type, status)
+		VALUES ('file-isolation', 'run-isolation', 'stores.csv', 'csv', 'persisting')
+	`)
+	require.NoError(t, err)
+
+	// Assigning store S01 to a price group fails. S01 is persisted first, so
+	// S02 shows the file carries on after the failure.
+	_, err = pool.Exec(ctx, `
+		CREATE FUNCTION fail_store_s01() RETURNS trigger LANGUAGE plpgsql AS $$
+		BEGIN
+			IF EXISTS (SELECT 1 FROM store_identifiers WHERE store_id = NEW.store_id AND value = 'S01') THEN
+				RAISE EXCEPTION 'store S01 is unavailable';
+			END IF;
+			RETURN NEW;
+		END $$;
+		CREATE TRIGGER fail_store_s01 BEFORE INSERT ON store_group_history
+			FOR EACH ROW EXECUTE FUNCTION fail_store_s01();
+	`)
+	require.NoError(t, err)
+
+	row := func(store, externalID, name string, price int) types.NormalizedRow {
+		return types.NormalizedRow{StoreIdentifier: store, ExternalID: &externalID, Name: name, Price: price}
+	}
+	parseResult := &pipeline.ParseResult{
+		FileID: "file-isolation",
+		RowsByStore: map[string][]types.NormalizedRow{
+			"S01": {row("S01", "bad-1", "Mlijeko", 119), row("S01", "bad-2", "Kruh", 89)},
+			"S02": {row("S02", "good-1", "Jaja", 249), row("S02", "good-2", "Sir", 599)},
+		},
+	}
+	file := types.DiscoveredFile{Filename: "stores.csv", Type: types.FileTypeCSV}
+
+	result, err := pipeline.PersistPhase(ctx, "metro", parseResult, file, "run-isolation", "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Persisted)
+
+	// The good store's items, state and price group are committed
+	var items, states, members int
+	err = pool.QueryRow(ctx, `
+		SELECT (SELECT COUNT(*) FROM retailer_items WHERE external_id IN ('good-1', 'good-2')),
+		       (SELECT COUNT(*) FROM store_item_state s
+		        JOIN store_identifiers si ON si.store_id = s.store_id WHERE si.value = 'S02'),
+		       (SELECT COUNT(*) FROM store_group_history h
+		        JOIN store_identifiers si ON si.store_id = h.store_id
+		        WHERE si.value = 'S02' AND h.valid_to IS NULL)
+	`).Scan(&items, &states, &members)
+	require.NoError(t, err)
+	assert.Equal(t, 2, items)
+	assert.Equal(t, 2, states)
+	assert.Equal(t, 1, members)
+
+	// Nothing of the failed store's transaction is left behind
+	var badItems, badStates, groups int
+	err = pool.QueryRow(ctx, `
+		SELECT (SELECT COUNT(*) FROM retailer_items WHERE external_id IN ('bad-1', 'bad-2')),
+		       (SELECT COUNT(*) FROM store_item_state s
+		        JOIN store_identifiers si ON si.store_id = s.store_id WHERE si.value = 'S01'),
+		       (SELECT COUNT(*) FROM price_groups WHERE chain_slug = 'metro')
+	`).Scan(&badItems, &badStates, &groups)
+	require.NoError(t, err)
+	assert.Zero(t, badItems)
+	assert.Zero(t, badStates)
+	assert.Equal(t, 1, groups)
+
+	// The failure is recorded against the store, and the file still counts
+	// as processed
+	var storeErrors int
+	err = pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM ingestion_errors
+		WHERE run_id = 'run-isolation' AND error_type = 'store_persist_failed'
+		  AND error_details::jsonb ->> 'store_identifier' = 'S01'
+	`).Scan(&storeErrors)
+	require.NoError(t, err)
+	assert.Equal(t, 1, storeErrors)
+
+	var fileStatus string
+	var processedEntries int
+	err = pool.QueryRow(ctx, `
+		SELECT f.status, r.processed_entries
+		FROM ingestion_files f JOIN ingestion_runs r ON r.id = f.run_id
+		WHERE f.id = 'file-isolation'
+	`).Scan(&fileStatus, &processedEntries)
+	require.NoError(t, err)
+	assert.Equal(t, "completed", fileStatus)
+	assert.Equal(t, 2, processedEntries)
+}
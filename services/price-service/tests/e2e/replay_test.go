@@ -0,0 +1,568 @@
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kosarica/price-service/internal/adapters/config"
+	"github.com/kosarica/price-service/internal/adapters/registry"
+	"github.com/kosarica/price-service/internal/chains"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/optimizer"
+	"github.com/kosarica/price-service/internal/pipeline"
+	"github.com/kosarica/price-service/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// replayCase is a recorded chain portal: the files under
+// testdata/replay/<chain> and what a run over them must leave behind
+type replayCase struct {
+	chain       string
+	wantFiles   int
+	wantEntries int
+	wantStores  int
+	wantGroups  int
+	wantPrices  []replayPrice
+}
+
+// replayPrice is a price the cache must serve for an item of a fixture file
+type replayPrice struct {
+	file       string
+	externalID string
+	price      int64
+}
+
+var replayCases = []replayCase{
+	{
+		chain:       "metro",
+		wantFiles:   2,
+		wantEntries: 10,
+		wantStores:  2,
+		// The two stores differ in one price
+		wantGroups: 2,
+		wantPrices: []replayPrice{
+			{file: "cash_and_carry_prodavaonica_METRO_20250801T0630_S10_JANKOMIR_31,_ZAGREB.csv", externalID: "31268", price: 181},
+			{file: "cash_and_carry_prodavaonica_METRO_20250801T0630_S11_STUPNIK_1,_STUPNIK.csv", externalID: "31268", price: 199},
+			{file: "cash_and_carry_prodavaonica_METRO_20250801T0630_S11_STUPNIK_1,_STUPNIK.csv", externalID: "266060", price: 8837},
+		},
+	},
+}
+
+// TestE2EReplayFixtures replays full runs from recorded portal files: the
+// fixtures are served over HTTP, the chain's real adapter discovers and
+// downloads them, and the pipeline parses and persists them into Postgres
+func TestE2EReplayFixtures(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping e2e test in short mode")
+	}
+
+	ctx := context.Background()
+
+	postgresContainer, err := setupTestDatabase(ctx)
+	require.NoError(t, err)
+	defer postgresContainer.Terminate(ctx)
+
+	connStr, err := postgresContainer.ConnectionString(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, database.Connect(ctx, connStr, 10, 2, 0, 0))
+	defer database.Close()
+	setupReplaySchema(ctx, t)
+
+	// Archives are written below the working directory
+	t.Chdir(t.TempDir())
+
+	for _, rc := range replayCases {
+		t.Run(rc.chain, func(t *testing.T) {
+			replayChain(ctx, t, rc)
+		})
+	}
+}
+
+// replayChain runs the pipeline twice over a chain's fixtures and checks the
+// run counters, price groups and the prices the optimizer cache serves
+func replayChain(ctx context.Context, t *testing.T, rc replayCase) {
+	fixtures, err := filepath.Abs(filepath.Join("testdata", "replay", rc.chain))
+	require.NoError(t, err)
+	servePortal(t, rc.chain, fixtures)
+
+	pool := database.Pool()
+	_, err = pool.Exec(ctx, `INSERT INTO chains (slug, name) VALUES ($1, $1) ON CONFLICT (slug) DO NOTHING`, rc.chain)
+	require.NoError(t, err)
+	require.NoError(t, chains.Refresh(ctx))
+
+	result, err := pipeline.Run(ctx, rc.chain, "")
+	require.NoError(t, err)
+	require.Empty(t, result.Errors)
+	assert.True(t, result.Success)
+	assert.Equal(t, rc.wantFiles, result.FilesProcessed)
+	assert.Equal(t, rc.wantEntries, result.EntriesPersisted)
+
+	// Run counters
+	var status string
+	var totalFiles, processedFiles, totalEntries, processedEntries int
+	var completionPercent float64
+	err = pool.QueryRow(ctx, `
+		SELECT status, total_files, processed_files, total_entries, processed_entries, completion_percent
+		FROM ingestion_runs WHERE id = $1
+	`, result.RunID).Scan(&status, &totalFiles, &processedFiles, &totalEntries, &processedEntries, &completionPercent)
+	require.NoError(t, err)
+	assert.Equal(t, "completed", status)
+	assert.Equal(t, rc.wantFiles, totalFiles)
+	assert.Equal(t, rc.wantFiles, processedFiles)
+	assert.Equal(t, rc.wantEntries, totalEntries)
+	assert.Equal(t, rc.wantEntries, processedEntries)
+	assert.InDelta(t, 100, completionPercent, 1e-9)
+
+	var completedFiles int
+	err = pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM ingestion_files WHERE run_id = $1 AND status = 'completed'
+	`, result.RunID).Scan(&completedFiles)
+	require.NoError(t, err)
+	assert.Equal(t, rc.wantFiles, completedFiles)
+
+	// Price groups and memberships
+	var stores, groups, members int
+	err = pool.QueryRow(ctx, `
+		SELECT (SELECT COUNT(*) FROM stores WHERE chain_slug = $1),
+		       (SELECT COUNT(*) FROM price_groups WHERE chain_slug = $1),
+		       (SELECT COUNT(*) FROM store_group_history h
+		        JOIN stores s ON s.id = h.store_id
+		        WHERE s.chain_slug = $1 AND h.valid_to IS NULL)
+	`, rc.chain).Scan(&stores, &groups, &members)
+	require.NoError(t, err)
+	assert.Equal(t, rc.wantStores, stores)
+	assert.Equal(t, rc.wantGroups, groups)
+	assert.Equal(t, rc.wantStores, members)
+
+	// Stores registered by ingestion await approval before they are served
+	_, err = pool.Exec(ctx, `UPDATE stores SET status = 'active' WHERE chain_slug = $1`, rc.chain)
+	require.NoError(t, err)
+
+	cache := optimizer.NewPriceCache(pool, optimizer.Defaults().ToOptimizerConfig())
+	defer cache.Close()
+	require.NoError(t, cache.LoadChain(ctx, rc.chain))
+
+	adapter, err := registry.GetAdapter(config.ChainID(rc.chain))
+	require.NoError(t, err)
+	for _, want := range rc.wantPrices {
+		storeID, itemID := replayStoreAndItem(ctx, t, adapter, fixtures, rc.chain, want)
+		got, ok := cache.GetPrice(rc.chain, storeID, itemID)
+		require.True(t, ok, "no cached price for %s in %s", want.externalID, want.file)
+		assert.Equal(t, want.price, got.Price, "price of %s in %s", want.externalID, want.file)
+	}
+
+	// Replaying the same files changes nothing
+	again, err := pipeline.Run(ctx, rc.chain, "")
+	require.NoError(t, err)
+	assert.True(t, again.Success)
+	assert.Zero(t, again.PriceChanges)
+
+	err = pool.QueryRow(ctx, `SELECT COUNT(*) FROM price_groups WHERE chain_slug = $1`, rc.chain).Scan(&groups)
+	require.NoError(t, err)
+	assert.Equal(t, rc.wantGroups, groups)
+
+	// Replaying today's archive reads the same files without the portal
+	archives, err := storage.NewLocalStorage("./data/archives")
+	require.NoError(t, err)
+	replayed, err := pipeline.RunFromArchive(ctx, rc.chain, time.Now().Format("2006-01-02"), archives)
+	require.NoError(t, err)
+	require.Empty(t, replayed.Errors)
+	assert.Equal(t, rc.wantFiles, replayed.FilesProcessed)
+	assert.Equal(t, rc.wantEntries, replayed.EntriesPersisted)
+	assert.Zero(t, replayed.PriceChanges)
+
+	var source string
+	err = pool.QueryRow(ctx, `SELECT source FROM ingestion_runs WHERE id = $1`, replayed.RunID).Scan(&source)
+	require.NoError(t, err)
+	assert.Equal(t, "archive", source)
+}
+
+// servePortal serves a fixture directory as the chain's portal for the rest of
+// the test. The directory listing links every file, which is what adapters
+// discover from.
+func servePortal(t *testing.T, chain, dir string) {
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	t.Cleanup(server.Close)
+
+	chainID := config.ChainID(chain)
+	original := config.ChainConfigs[chainID]
+	patched := original
+	patched.BaseURL = server.URL + "/"
+	config.ChainConfigs[chainID] = patched
+	t.Cleanup(func() { config.ChainConfigs[chainID] = original })
+}
+
+// replayStoreAndItem resolves the store a fixture file was persisted to and
+// the retailer item of an external ID. The store identifier comes from
+// parsing the file with the chain's adapter, as the pipeline does.
+func replayStoreAndItem(ctx context.Context, t *testing.T, adapter registry.ChainAdapter, dir, chain string, want replayPrice) (string, string) {
+	content, err := os.ReadFile(filepath.Join(dir, want.file))
+	require.NoError(t, err)
+	parsed, err := adapter.Parse(content, want.file, nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, parsed.Rows)
+
+	var storeID, itemID string
+	err = database.Pool().QueryRow(ctx, `
+		SELECT si.store_id,
+		       (SELECT id FROM retailer_items WHERE chain_slug = $1 AND external_id = $3)
+		FROM store_identifiers si
+		JOIN stores s ON s.id = si.store_id
+		WHERE s.chain_slug = $1 AND si.value = $2
+	`, chain, parsed.Rows[0].StoreIdentifier, want.externalID).Scan(&storeID, &itemID)
+	require.NoError(t, err)
+	return storeID, itemID
+}
+
+// setupReplaySchema creates the tables a pipeline run and the optimizer cache
+// touch, with the text IDs the service writes
+func setupReplaySchema(ctx context.Context, t *testing.T) {
+	schema := `
+		CREATE TABLE chains (
+			slug text PRIMARY KEY,
+			name text NOT NULL,
+			display_name text,
+			brand_color text,
+			website text,
+			logo_url text,
+			enabled boolean NOT NULL DEFAULT true,
+			metadata jsonb NOT NULL DEFAULT '{}'::jsonb,
+			created_at timestamp DEFAULT NOW(),
+			updated_at timestamp DEFAULT NOW()
+		);
+
+		CREATE TABLE feature_flags (
+			key text PRIMARY KEY,
+			description text,
+			enabled boolean NOT NULL DEFAULT false,
+			chains text[] NOT NULL DEFAULT '{}',
+			created_at timestamp DEFAULT NOW(),
+			updated_at timestamp DEFAULT NOW()
+		);
+
+		CREATE TABLE column_mapping_overrides (
+			chain_slug text PRIMARY KEY REFERENCES chains(slug) ON DELETE CASCADE,
+			mapping jsonb NOT NULL,
+			enabled boolean NOT NULL DEFAULT false,
+			description text,
+			created_at timestamp NOT NULL DEFAULT NOW(),
+			updated_at timestamp NOT NULL DEFAULT NOW()
+		);
+
+		CREATE TABLE archives (
+			id text PRIMARY KEY,
+			chain_slug text NOT NULL,
+			source_url text NOT NULL,
+			filename text NOT NULL,
+			original_format text NOT NULL,
+			archive_path text NOT NULL,
+			archive_type text NOT NULL,
+			content_type text,
+			file_size bigint,
+			compressed_size bigint,
+			checksum text NOT NULL,
+			downloaded_at timestamptz NOT NULL,
+			metadata jsonb DEFAULT '{}'::jsonb,
+			created_at timestamptz NOT NULL DEFAULT NOW(),
+			updated_at timestamptz NOT NULL DEFAULT NOW()
+		);
+
+		CREATE TABLE ingestion_runs (
+			id text PRIMARY KEY,
+			chain_slug text NOT NULL,
+			source text NOT NULL,
+			source_url text,
+			status text NOT NULL DEFAULT 'pending',
+			started_at timestamp,
+			completed_at timestamp,
+			total_files integer DEFAULT 0,
+			processed_files integer DEFAULT 0,
+			total_entries integer DEFAULT 0,
+			processed_entries integer DEFAULT 0,
+			price_changes integer DEFAULT 0,
+			error_count integer DEFAULT 0,
+			completion_percent double precision,
+			metadata jsonb,
+			parent_run_id text,
+			rerun_type text,
+			rerun_target_id text,
+			staging_status text,
+			quality_report jsonb,
+			average_price double precision,
+			baseline_status text,
+			baseline_report jsonb,
+			archive_id text REFERENCES archives(id) ON DELETE SET NULL,
+			created_at timestamp DEFAULT NOW()
+		);
+
+		CREATE TABLE ingestion_files (
+			id text PRIMARY KEY,
+			run_id text NOT NULL REFERENCES ingestion_runs(id) ON DELETE CASCADE,
+			filename text NOT NULL,
+			file_type text NOT NULL,
+			file_size integer,
+			file_hash text,
+			status text NOT NULL DEFAULT 'pending',
+			discrepancy_reason text,
+			status_timestamps jsonb,
+			entry_count integer DEFAULT 0,
+			processed_at timestamp,
+			metadata text,
+			total_chunks integer DEFAULT 0,
+			processed_chunks integer DEFAULT 0,
+			chunk_size integer,
+			warning_count integer NOT NULL DEFAULT 0,
+			parser_version text,
+			discovered jsonb,
+			created_at timestamp DEFAULT NOW()
+		);
+
+		CREATE TABLE ingestion_errors (
+			id bigserial PRIMARY KEY,
+			run_id text NOT NULL,
+			file_id text,
+			chunk_id text,
+			entry_id text,
+			error_type text NOT NULL,
+			error_message text NOT NULL,
+			error_details text,
+			severity text NOT NULL DEFAULT 'error',
+			created_at timestamp DEFAULT NOW()
+		);
+
+		CREATE TABLE raw_payloads (
+			id text PRIMARY KEY,
+			encoding text NOT NULL DEFAULT 'zstd',
+			original_size integer NOT NULL,
+			data bytea NOT NULL,
+			created_at timestamp NOT NULL DEFAULT NOW()
+		);
+
+		CREATE TABLE retailer_items_failed (
+			id text PRIMARY KEY,
+			chain_slug text NOT NULL,
+			run_id text,
+			file_id text,
+			store_identifier text,
+			row_number integer,
+			raw_data text,
+			raw_payload_id text REFERENCES raw_payloads(id) ON DELETE SET NULL,
+			validation_errors jsonb NOT NULL,
+			failed_at timestamp DEFAULT NOW(),
+			reviewed boolean DEFAULT false,
+			reviewed_by text,
+			review_notes text,
+			reprocessable boolean DEFAULT true,
+			reprocessed_at timestamp
+		);
+
+		CREATE TABLE stores (
+			id text PRIMARY KEY,
+			chain_slug text NOT NULL REFERENCES chains(slug),
+			name text NOT NULL,
+			address text,
+			city text,
+			postal_code text,
+			latitude double precision,
+			longitude double precision,
+			is_virtual boolean DEFAULT true,
+			price_source_store_id text REFERENCES stores(id),
+			status text DEFAULT 'active',
+			created_at timestamp DEFAULT NOW(),
+			updated_at timestamp DEFAULT NOW()
+		);
+
+		CREATE TABLE store_identifiers (
+			id text PRIMARY KEY,
+			store_id text NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+			type text NOT NULL,
+			value text NOT NULL,
+			created_at timestamp DEFAULT NOW()
+		);
+
+		CREATE TABLE retailer_items (
+			id text PRIMARY KEY,
+			chain_slug text NOT NULL REFERENCES chains(slug),
+			external_id text,
+			identity_hash text,
+			name text NOT NULL,
+			description text,
+			category text,
+			subcategory text,
+			brand text,
+			unit text,
+			unit_quantity text,
+			image_url text,
+			archive_id text REFERENCES archives(id) ON DELETE SET NULL,
+			name_i18n jsonb,
+			created_at timestamp DEFAULT NOW(),
+			updated_at timestamp DEFAULT NOW(),
+			UNIQUE (chain_slug, external_id)
+		);
+
+		CREATE UNIQUE INDEX retailer_items_chain_identity_hash_uniq
+			ON retailer_items (chain_slug, identity_hash)
+			WHERE external_id IS NULL;
+
+		CREATE TABLE retailer_item_barcodes (
+			id text PRIMARY KEY,
+			retailer_item_id text NOT NULL REFERENCES retailer_items(id) ON DELETE CASCADE,
+			barcode text NOT NULL,
+			is_primary boolean DEFAULT false,
+			created_at timestamp DEFAULT NOW(),
+			UNIQUE (retailer_item_id, barcode)
+		);
+
+		CREATE TABLE price_groups (
+			id text PRIMARY KEY,
+			chain_slug text NOT NULL REFERENCES chains(slug) ON DELETE CASCADE,
+			price_hash text NOT NULL,
+			hash_version integer NOT NULL,
+			store_count integer NOT NULL DEFAULT 0,
+			item_count integer NOT NULL DEFAULT 0,
+			first_seen_at timestamptz DEFAULT NOW(),
+			last_seen_at timestamptz DEFAULT NOW(),
+			created_at timestamptz DEFAULT NOW(),
+			updated_at timestamptz DEFAULT NOW(),
+			UNIQUE (chain_slug, price_hash, hash_version)
+		);
+
+		CREATE TABLE group_prices (
+			price_group_id text NOT NULL REFERENCES price_groups(id) ON DELETE CASCADE,
+			retailer_item_id text NOT NULL REFERENCES retailer_items(id) ON DELETE CASCADE,
+			price integer NOT NULL,
+			discount_price integer,
+			unit_price integer,
+			anchor_price integer,
+			discount_start timestamptz,
+			discount_end timestamptz,
+			created_at timestamptz DEFAULT NOW(),
+			PRIMARY KEY (price_group_id, retailer_item_id)
+		);
+
+		CREATE TABLE store_group_history (
+			id text PRIMARY KEY,
+			store_id text NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+			price_group_id text NOT NULL REFERENCES price_groups(id) ON DELETE CASCADE,
+			valid_from timestamptz NOT NULL,
+			valid_to timestamptz,
+			created_at timestamptz DEFAULT NOW()
+		);
+
+		CREATE TABLE store_item_state (
+			id text PRIMARY KEY,
+			store_id text NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+			retailer_item_id text NOT NULL REFERENCES retailer_items(id) ON DELETE CASCADE,
+			current_price integer,
+			previous_price integer,
+			discount_price integer,
+			discount_start timestamp,
+			discount_end timestamp,
+			in_stock boolean DEFAULT true,
+			unit_price integer,
+			unit_price_base_quantity text,
+			unit_price_base_unit text,
+			lowest_price_30d integer,
+			anchor_price integer,
+			anchor_price_as_of timestamp,
+			price_signature text,
+			last_seen_at timestamp DEFAULT NOW(),
+			updated_at timestamp DEFAULT NOW(),
+			UNIQUE (store_id, retailer_item_id)
+		);
+
+		CREATE TABLE store_price_exceptions (
+			store_id text NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+			retailer_item_id text NOT NULL REFERENCES retailer_items(id) ON DELETE CASCADE,
+			price integer NOT NULL,
+			discount_price integer,
+			reason text NOT NULL,
+			valid_to timestamptz NOT NULL,
+			created_at timestamptz DEFAULT NOW(),
+			PRIMARY KEY (store_id, retailer_item_id)
+		);
+
+		CREATE TABLE store_item_assortment (
+			store_id text NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+			retailer_item_id text NOT NULL REFERENCES retailer_items(id) ON DELETE CASCADE,
+			last_seen_at timestamptz NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (store_id, retailer_item_id)
+		);
+
+		CREATE TABLE event_outbox (
+			id bigserial PRIMARY KEY,
+			event_type text NOT NULL,
+			aggregate_id text NOT NULL,
+			payload jsonb NOT NULL,
+			created_at timestamp NOT NULL DEFAULT NOW(),
+			available_at timestamp NOT NULL DEFAULT NOW(),
+			delivered_at timestamp,
+			attempts integer NOT NULL DEFAULT 0,
+			last_error text
+		);
+
+		CREATE TABLE price_change_log (
+			id bigserial PRIMARY KEY,
+			chain_slug text NOT NULL,
+			store_id text NOT NULL,
+			retailer_item_id text NOT NULL,
+			run_id text,
+			file_id text,
+			old_price integer,
+			new_price integer NOT NULL,
+			old_discount_price integer,
+			new_discount_price integer,
+			discount_start timestamp,
+			discount_end timestamp,
+			changed_at timestamp NOT NULL DEFAULT NOW()
+		);
+
+		CREATE TABLE price_history (
+			store_id text NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+			retailer_item_id text NOT NULL REFERENCES retailer_items(id) ON DELETE CASCADE,
+			day date NOT NULL,
+			chain_slug text NOT NULL,
+			price integer NOT NULL,
+			discount_price integer,
+			unit_price integer,
+			recorded_at timestamp NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (store_id, retailer_item_id, day)
+		);
+
+		CREATE TABLE webhooks (
+			id text PRIMARY KEY,
+			url text NOT NULL,
+			secret text NOT NULL,
+			event_types text[] NOT NULL DEFAULT '{}',
+			enabled boolean NOT NULL DEFAULT true,
+			description text,
+			created_at timestamp NOT NULL DEFAULT NOW(),
+			updated_at timestamp NOT NULL DEFAULT NOW()
+		);
+
+		CREATE TABLE webhook_deliveries (
+			id bigserial PRIMARY KEY,
+			webhook_id text NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+			event_type text NOT NULL,
+			aggregate_id text NOT NULL,
+			payload jsonb NOT NULL,
+			attempts integer NOT NULL DEFAULT 0,
+			available_at timestamp NOT NULL DEFAULT NOW(),
+			delivered_at timestamp,
+			failed_at timestamp,
+			last_status integer,
+			last_error text,
+			created_at timestamp NOT NULL DEFAULT NOW()
+		);
+	`
+
+	_, err := database.Pool().Exec(ctx, schema)
+	require.NoError(t, err, "failed to create replay schema")
+}
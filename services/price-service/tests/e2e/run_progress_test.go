@@ -0,0 +1,182 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/kosarica/price-service/internal/adapters/registry"
+	"github.com/kosarica/price-service/internal/chains"
+	"github.com/kosarica/price-service/internal/database"
+	"github.com/kosarica/price-service/internal/jobs"
+	"github.com/kosarica/price-service/internal/outbox"
+	"github.com/kosarica/price-service/internal/pipeline"
+	"github.com/kosarica/price-service/internal/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupRunProgressDatabase starts Postgres with the replay schema and the
+// metro chain registered
+func setupRunProgressDatabase(ctx context.Context, t *testing.T) {
+	postgresContainer, err := setupTestDatabase(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() { postgresContainer.Terminate(ctx) })
+
+	connStr, err := postgresContainer.ConnectionString(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, database.Connect(ctx, connStr, 20, 2, 0, 0))
+	t.Cleanup(database.Close)
+	setupReplaySchema(ctx, t)
+	require.NoError(t, registry.InitializeDefaultAdapters())
+
+	_, err = database.Pool().Exec(ctx, `INSERT INTO chains (slug, name) VALUES ('metro', 'metro')`)
+	require.NoError(t, err)
+	require.NoError(t, chains.Refresh(ctx))
+}
+
+// TestE2ERunProgressConcurrentFiles persists the files of a run at once, as
+// parallel file workers do, and checks no progress update is lost and the
+// run completes once. Run with -race to also check the workers' shared state.
+func TestE2ERunProgressConcurrentFiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping e2e test in short mode")
+	}
+
+	ctx := context.Background()
+	setupRunProgressDatabase(ctx, t)
+	pool := database.Pool()
+
+	const files = 12
+	const rowsPerFile = 3
+	_, err := pool.Exec(ctx, `
+		INSERT INTO ingestion_runs (id, chain_slug, source, status, total_files, total_entries, started_at)
+		VALUES ('run-progress', 'metro', 'test', 'running', $1, $2, NOW())
+	`, files, files*rowsPerFile)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, files)
+	for i := range files {
+		fileID := fmt.Sprintf("file-%02d", i)
+		store := fmt.Sprintf("S%02d", i)
+		_, err := pool.Exec(ctx, `
+			INSERT INTO ingestion_files (id, run_id, filename, file_type, status)
+			VALUES ($1, 'run-progress', $2, 'csv', 'persisting')
+		`, fileID, store+".csv")
+		require.NoError(t, err)
+
+		rows := make([]types.NormalizedRow, rowsPerFile)
+		for j := range rows {
+			externalID := fmt.Sprintf("item-%d", j)
+			rows[j] = types.NormalizedRow{StoreIdentifier: store, ExternalID: &externalID, Name: externalID, Price: 100 + i + j}
+		}
+		parseResult := &pipeline.ParseResult{FileID: fileID, RowsByStore: map[string][]types.NormalizedRow{store: rows}}
+		file := types.DiscoveredFile{Filename: store + ".csv", Type: types.FileTypeCSV}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := pipeline.PersistPhase(ctx, "metro", parseResult, file, "run-progress", "")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	var status string
+	var processedFiles, processedEntries int
+	err = pool.QueryRow(ctx, `
+		SELECT status, processed_files, processed_entries FROM ingestion_runs WHERE id = 'run-progress'
+	`).Scan(&status, &processedFiles, &processedEntries)
+	require.NoError(t, err)
+	assert.Equal(t, "completed", status)
+	assert.Equal(t, files, processedFiles)
+	assert.Equal(t, files*rowsPerFile, processedEntries)
+
+	var completedEvents int
+	err = pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM event_outbox WHERE event_type = $1 AND aggregate_id = 'run-progress'
+	`, outbox.EventRunCompleted).Scan(&completedEvents)
+	require.NoError(t, err)
+	assert.Equal(t, 1, completedEvents)
+
+	// The counters agree with the totals, so the checker has nothing to flag
+	logger := zerolog.Nop()
+	require.NoError(t, jobs.NewRunProgressCheckJob(pool, &logger, 0).RunOnce(ctx))
+	var flagged bool
+	err = pool.QueryRow(ctx, `
+		SELECT COALESCE(metadata, '{}'::jsonb) ? 'progress_violation' FROM ingestion_runs WHERE id = 'run-progress'
+	`).Scan(&flagged)
+	require.NoError(t, err)
+	assert.False(t, flagged)
+}
+
+// TestE2ERunProgressCheckFlagsViolations checks the invariant checker flags
+// runs whose progress exceeds their totals, once, and leaves the rest alone
+func TestE2ERunProgressCheckFlagsViolations(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping e2e test in short mode")
+	}
+
+	ctx := context.Background()
+	setupRunProgressDatabase(ctx, t)
+	pool := database.Pool()
+
+	_, err := pool.Exec(ctx, `
+		INSERT INTO ingestion_runs (id, chain_slug, source, status, total_files, processed_files, total_entries, processed_entries, created_at)
+		VALUES
+			('run-ok', 'metro', 'test', 'completed', 2, 2, 10, 10, NOW()),
+			('run-files', 'metro', 'test', 'completed', 2, 3, 10, 10, NOW()),
+			('run-entries', 'metro', 'test', 'completed', 2, 2, 10, 14, NOW()),
+			('run-old', 'metro', 'test', 'completed', 2, 3, 10, 10, NOW() - interval '30 days')
+	`)
+	require.NoError(t, err)
+
+	logger := zerolog.Nop()
+	job := jobs.NewRunProgressCheckJob(pool, &logger, 0)
+	require.NoError(t, job.RunOnce(ctx))
+
+	violations := func() map[string]map[string]any {
+		rows, err := pool.Query(ctx, `
+			SELECT id, metadata -> 'progress_violation' FROM ingestion_runs
+			WHERE metadata ? 'progress_violation'
+		`)
+		require.NoError(t, err)
+		defer rows.Close()
+		found := map[string]map[string]any{}
+		for rows.Next() {
+			var id string
+			var violation map[string]any
+			require.NoError(t, rows.Scan(&id, &violation))
+			found[id] = violation
+		}
+		require.NoError(t, rows.Err())
+		return found
+	}
+
+	found := violations()
+	require.Len(t, found, 2)
+	require.Contains(t, found, "run-files")
+	require.Contains(t, found, "run-entries")
+	assert.EqualValues(t, 3, found["run-files"]["processed_files"])
+	assert.EqualValues(t, 2, found["run-files"]["total_files"])
+	assert.EqualValues(t, 14, found["run-entries"]["processed_entries"])
+	assert.EqualValues(t, 10, found["run-entries"]["total_entries"])
+
+	// A flagged run keeps its first finding
+	detectedAt := found["run-files"]["detected_at"]
+	_, err = pool.Exec(ctx, `UPDATE ingestion_runs SET processed_files = 4 WHERE id = 'run-files'`)
+	require.NoError(t, err)
+	require.NoError(t, job.RunOnce(ctx))
+	found = violations()
+	assert.Len(t, found, 2)
+	assert.EqualValues(t, 3, found["run-files"]["processed_files"])
+	assert.Equal(t, detectedAt, found["run-files"]["detected_at"])
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/kosarica/price-service/internal/parsers/charset"
 	"github.com/kosarica/price-service/internal/parsers/csv"
+	"github.com/kosarica/price-service/internal/parsers/json"
 	"github.com/kosarica/price-service/internal/parsers/xlsx"
 	"github.com/kosarica/price-service/internal/parsers/xml"
 	"github.com/kosarica/price-service/internal/types"
@@ -171,6 +172,68 @@ func TestCSVParserAlternativeMapping(t *testing.T) {
 	assert.Equal(t, 10000, result.Rows[0].Price) // 100.00 in cents
 }
 
+// TestCSVColumnMappingOverride tests overlaying a column mapping override
+func TestCSVColumnMappingOverride(t *testing.T) {
+	base := csv.CsvColumnMapping{
+		Name:     "NAZIV",
+		Price:    "CIJENA",
+		Barcodes: types.StringPtr("BARKOD"),
+	}
+
+	t.Run("renamed column is overlaid", func(t *testing.T) {
+		mapping, err := csv.ApplyOverride(base, map[string]string{"price": "MPC"})
+		require.NoError(t, err)
+		assert.Equal(t, "NAZIV", mapping.Name)
+		assert.Equal(t, "MPC", mapping.Price)
+		require.NotNil(t, mapping.Barcodes)
+		assert.Equal(t, "BARKOD", *mapping.Barcodes)
+
+		parser := csv.NewParser(csv.CsvParserOptions{ColumnMapping: &base, HasHeader: true}).
+			WithColumnMapping(&mapping)
+		result, err := parser.Parse([]byte("NAZIV,MPC,BARKOD\nJabuka,1.99,385\n"))
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.ValidRows)
+		assert.Equal(t, 199, result.Rows[0].Price)
+	})
+
+	t.Run("empty value unmaps optional field", func(t *testing.T) {
+		mapping, err := csv.ApplyOverride(base, map[string]string{"barcodes": ""})
+		require.NoError(t, err)
+		assert.Nil(t, mapping.Barcodes)
+	})
+
+	t.Run("required field cannot be unmapped", func(t *testing.T) {
+		_, err := csv.ApplyOverride(base, map[string]string{"price": " "})
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown field is rejected", func(t *testing.T) {
+		_, err := csv.ApplyOverride(base, map[string]string{"cijena": "MPC"})
+		assert.Error(t, err)
+	})
+}
+
+// TestCSVParserSecondaryNames tests bilingual names are parsed by language,
+// skipping empty ones and those repeating the name
+func TestCSVParserSecondaryNames(t *testing.T) {
+	parser := csv.NewParser(csv.CsvParserOptions{
+		ColumnMapping: &csv.CsvColumnMapping{
+			Name:   "NAZIV",
+			NameEn: types.StringPtr("NAZIV_EN"),
+			Price:  "MPC",
+		},
+		HasHeader: true,
+	})
+
+	content := "NAZIV,NAZIV_EN,MPC\nSvježe mlijeko,Fresh milk,1.29\nKetchup,KETCHUP,2.49\nKruh,,0.99\n"
+	result, err := parser.Parse([]byte(content))
+	require.NoError(t, err)
+	require.Equal(t, 3, result.ValidRows)
+	assert.Equal(t, map[string]string{"en": "Fresh milk"}, result.Rows[0].NameI18n)
+	assert.Nil(t, result.Rows[1].NameI18n)
+	assert.Nil(t, result.Rows[2].NameI18n)
+}
+
 // TestXMLParserMultipleItemPaths tests various XML item path structures
 func TestXMLParserMultipleItemPaths(t *testing.T) {
 	tests := []struct {
@@ -353,6 +416,148 @@ func stringPtr(s string) *string {
 	return &s
 }
 
+// TestXMLParserStream tests that streamed parsing emits the rows Parse returns
+func TestXMLParserStream(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<Cjenik>
+	<Proizvod><Naziv id="1">Mlijeko 2,8%</Naziv><Cijena>1,29</Cijena><Barkod>3850012345678</Barkod></Proizvod>
+	<Proizvod><Naziv>Kruh</Naziv><Cijena>abc</Cijena></Proizvod>
+	<Proizvod><Naziv>Čokolada</Naziv><Cijena>2,49</Cijena></Proizvod>
+</Cjenik>`
+	barcodes := "Barkod"
+	parser := xml.NewParser(xml.XmlParserOptions{
+		ItemsPath:    "Cjenik.Proizvod",
+		FieldMapping: xml.XmlFieldMapping{Name: "Naziv", Price: "Cijena", Barcodes: &barcodes},
+	})
+
+	parsed, err := parser.ParseWithStoreID([]byte(content), "T100")
+	require.NoError(t, err)
+
+	var rows []types.NormalizedRow
+	streamed, err := parser.ParseStream(strings.NewReader(content), nil, "T100", func(row types.NormalizedRow) error {
+		rows = append(rows, row)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, parsed.TotalRows, streamed.TotalRows)
+	assert.Equal(t, parsed.ValidRows, streamed.ValidRows)
+	assert.Equal(t, parsed.Errors, streamed.Errors)
+	assert.Equal(t, parsed.Rows, rows)
+	assert.Empty(t, streamed.Rows)
+
+	t.Run("candidate paths are matched case-insensitively", func(t *testing.T) {
+		count := 0
+		result, err := parser.ParseStream(strings.NewReader(content), []string{"products.product", "cjenik.proizvod"}, "", func(types.NormalizedRow) error {
+			count++
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.ValidRows)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("missing items path", func(t *testing.T) {
+		_, err := parser.ParseStream(strings.NewReader(content), []string{"items.item"}, "", func(types.NormalizedRow) error { return nil })
+		assert.Error(t, err)
+	})
+
+	t.Run("windows-1250 declaration", func(t *testing.T) {
+		encoded := []byte("<?xml version=\"1.0\" encoding=\"windows-1250\"?><items><item><name>\xc8okolada</name><price>2.49</price></item></items>")
+		var names []string
+		_, err := xml.NewParser(xml.XmlParserOptions{Encoding: "auto", FieldMapping: xml.XmlFieldMapping{Name: "name", Price: "price"}}).
+			ParseStream(strings.NewReader(string(encoded)), nil, "", func(row types.NormalizedRow) error {
+				names = append(names, row.Name)
+				return nil
+			})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Čokolada"}, names)
+	})
+
+	t.Run("alternative mapping chosen on first item", func(t *testing.T) {
+		alt := xml.NewParser(xml.XmlParserOptions{
+			ItemsPath:    "items.item",
+			FieldMapping: xml.XmlFieldMapping{Name: "naziv", Price: "cijena"},
+		})
+		alt.SetAlternativeMapping(&xml.XmlFieldMapping{Name: "name", Price: "price"})
+		result, err := alt.ParseStream(strings.NewReader(`<items><item><name>A</name><price>1.00</price></item><item><name>B</name><price>2.00</price></item></items>`), nil, "", func(types.NormalizedRow) error { return nil })
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.ValidRows)
+		assert.Empty(t, result.Errors)
+	})
+}
+
 func intPtr(i int) *int {
 	return &i
 }
+
+// TestJSONParser tests item detection, nested paths and the alternative mapping
+func TestJSONParser(t *testing.T) {
+	mapping := json.JsonFieldMapping{
+		ExternalID:    stringPtr("sifra"),
+		Name:          "naziv",
+		Price:         "cijene.redovna",
+		DiscountPrice: stringPtr("cijene.akcijska"),
+		Barcodes:      stringPtr("barkodovi"),
+	}
+
+	t.Run("root array with nested paths", func(t *testing.T) {
+		content := []byte("\xEF\xBB\xBF" + `[
+			{"sifra": 1001, "naziv": "Mlijeko 1L", "cijene": {"redovna": 1.29, "akcijska": "0,99"}, "barkodovi": ["3850001", "3850002"]},
+			{"sifra": 1002, "naziv": "Kruh", "cijene": {"redovna": "2,10 €"}, "barkodovi": "3850003;3850004"},
+			{"sifra": 1003, "cijene": {"redovna": 3}}
+		]`)
+
+		result, err := json.NewParser(json.JsonParserOptions{FieldMapping: mapping}).ParseWithStoreID(content, "S1")
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.TotalRows)
+		assert.Equal(t, 2, result.ValidRows)
+		require.Len(t, result.Errors, 1)
+		assert.Equal(t, "name", *result.Errors[0].Field)
+
+		first := result.Rows[0]
+		assert.Equal(t, "S1", first.StoreIdentifier)
+		assert.Equal(t, "1001", *first.ExternalID)
+		assert.Equal(t, 129, first.Price)
+		require.NotNil(t, first.DiscountPrice)
+		assert.Equal(t, 99, *first.DiscountPrice)
+		assert.Equal(t, []string{"3850001", "3850002"}, first.Barcodes)
+
+		second := result.Rows[1]
+		assert.Equal(t, 210, second.Price)
+		assert.Nil(t, second.DiscountPrice)
+		assert.Equal(t, []string{"3850003", "3850004"}, second.Barcodes)
+	})
+
+	t.Run("items path and common paths", func(t *testing.T) {
+		nested := []byte(`{"meta": {"date": "2024-01-15"}, "cjenik": {"artikli": [{"naziv": "Sok", "cijene": {"redovna": "1.50"}}]}}`)
+		result, err := json.NewParser(json.JsonParserOptions{ItemsPath: "cjenik.artikli", FieldMapping: mapping}).Parse(nested)
+		require.NoError(t, err)
+		require.Equal(t, 1, result.ValidRows)
+		assert.Equal(t, 150, result.Rows[0].Price)
+
+		common := []byte(`{"Products": [{"naziv": "Sok", "cijene": {"redovna": "1.50"}}]}`)
+		result, err = json.NewParser(json.JsonParserOptions{FieldMapping: mapping}).Parse(common)
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.ValidRows)
+
+		_, err = json.NewParser(json.JsonParserOptions{ItemsPath: "missing", FieldMapping: mapping}).Parse(nested)
+		assert.Error(t, err)
+	})
+
+	t.Run("alternative mapping", func(t *testing.T) {
+		content := []byte(`[{"name": "Sok", "price": "1,50"}]`)
+		parser := json.NewParser(json.JsonParserOptions{FieldMapping: mapping})
+		parser.SetAlternativeMapping(&json.JsonFieldMapping{Name: "name", Price: "price"})
+
+		result, err := parser.Parse(content)
+		require.NoError(t, err)
+		require.Equal(t, 1, result.ValidRows)
+		assert.Equal(t, "Sok", result.Rows[0].Name)
+		assert.Equal(t, 150, result.Rows[0].Price)
+	})
+
+	t.Run("invalid document", func(t *testing.T) {
+		_, err := json.NewParser(json.JsonParserOptions{FieldMapping: mapping}).Parse([]byte(`{"broken":`))
+		assert.Error(t, err)
+	})
+}
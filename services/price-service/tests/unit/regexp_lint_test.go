@@ -0,0 +1,100 @@
+package unit
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// compilingRegexpFuncs are the regexp package functions that compile their
+// pattern on every call
+var compilingRegexpFuncs = map[string]bool{
+	"Compile":          true,
+	"CompilePOSIX":     true,
+	"MustCompile":      true,
+	"MustCompilePOSIX": true,
+	"Match":            true,
+	"MatchReader":      true,
+	"MatchString":      true,
+}
+
+// TestNoRegexpCompileInFunctions fails when a regexp is compiled inside a
+// function body. Fixed patterns belong in package-level variables; patterns
+// built at run time go through internal/pkg/patterns, which compiles each
+// expression once.
+func TestNoRegexpCompileInFunctions(t *testing.T) {
+	roots := []string{"../../internal", "../../cmd"}
+	fset := token.NewFileSet()
+
+	for _, root := range roots {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				// The cache itself is the one place allowed to compile
+				if filepath.ToSlash(path) == "../../internal/pkg/patterns" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+				return nil
+			}
+
+			file, err := parser.ParseFile(fset, path, nil, 0)
+			if err != nil {
+				return err
+			}
+			regexpName := regexpImportName(file)
+			if regexpName == "" {
+				return nil
+			}
+
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Body == nil {
+					continue
+				}
+				ast.Inspect(fn.Body, func(n ast.Node) bool {
+					call, ok := n.(*ast.CallExpr)
+					if !ok {
+						return true
+					}
+					sel, ok := call.Fun.(*ast.SelectorExpr)
+					if !ok {
+						return true
+					}
+					if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == regexpName && compilingRegexpFuncs[sel.Sel.Name] {
+						t.Errorf("%s: regexp.%s in %s; use a package-level variable or patterns.Compile",
+							fset.Position(call.Pos()), sel.Sel.Name, fn.Name.Name)
+					}
+					return true
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("walk %s: %v", root, err)
+		}
+	}
+}
+
+// regexpImportName returns the name the file imports package regexp under,
+// or "" when it does not import it
+func regexpImportName(file *ast.File) string {
+	for _, imp := range file.Imports {
+		if imp.Path.Value != `"regexp"` {
+			continue
+		}
+		if imp.Name != nil {
+			return imp.Name.Name
+		}
+		return "regexp"
+	}
+	return ""
+}
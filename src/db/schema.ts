@@ -128,6 +128,7 @@ export const stores = pgTable(
 		postalCode: text("postal_code"),
 		latitude: text("latitude"), // stored as text for precision
 		longitude: text("longitude"),
+		openingHours: jsonb("opening_hours"), // as published by the chain's store locator
 		// Virtual store support
 		isVirtual: boolean("is_virtual").default(true),
 		priceSourceStoreId: text("price_source_store_id").references(
@@ -696,6 +697,11 @@ export const productMatchQueue = pgTable(
 		reviewNotes: text("review_notes"),
 		// Version for optimistic locking (prevents concurrent review conflicts)
 		version: integer("version").default(1),
+		// Soft ownership while a reviewer has the item open; expires on its own
+		claimedBy: text("claimed_by").references(() => user.id, {
+			onDelete: "set null",
+		}),
+		claimedUntil: timestamp("claimed_until", { withTimezone: true }),
 		createdAt: timestamp("created_at", { withTimezone: true }).defaultNow(),
 	},
 	(table) => ({
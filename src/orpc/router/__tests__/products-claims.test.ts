@@ -0,0 +1,235 @@
+/**
+ * Unit tests for match queue review claims
+ *
+ * Tests claiming and releasing queue items, that the review list shows
+ * who holds a claim, and that decisions on an item another reviewer has
+ * claimed are refused.
+ * These tests use mocked database connections and do not require a real database.
+ */
+
+import { createRouterClient } from "@orpc/server";
+import { beforeEach, describe, expect, it, vi } from "vitest";
+import { getDb } from "@/utils/bindings";
+import {
+	approveMatch,
+	bulkApprove,
+	claimMatch,
+	getPendingMatches,
+	releaseMatch,
+} from "../products";
+
+const mocks = vi.hoisted(() => ({ userId: "reviewer-a" }));
+
+vi.mock("@/utils/bindings", () => ({
+	getDb: vi.fn(),
+}));
+
+vi.mock("@tanstack/react-start/server", () => ({
+	getRequestHeaders: () => ({}),
+}));
+
+vi.mock("@/lib/auth", () => ({
+	getAuth: () => ({
+		api: {
+			getSession: async () => ({ user: { id: mocks.userId } }),
+		},
+	}),
+}));
+
+// A select whose where() resolves to rows
+function selectReturning(rows: unknown[]) {
+	return { from: () => ({ where: async () => rows }) };
+}
+
+// The error a procedure rejected with, as thrown by its handler
+async function rejectionMessage(promise: Promise<unknown>): Promise<string> {
+	try {
+		await promise;
+	} catch (error: any) {
+		return (error.cause ?? error).message;
+	}
+	throw new Error("Expected the call to be rejected");
+}
+
+describe("Match Queue Claims Unit Tests", () => {
+	const client = createRouterClient({
+		approveMatch,
+		bulkApprove,
+		claimMatch,
+		getPendingMatches,
+		releaseMatch,
+	});
+	const claimedUntil = new Date(Date.now() + 10 * 60 * 1000);
+
+	const mockReturning = vi.fn();
+	const mockTxUpdate = vi.fn();
+	const mockDb = {
+		// The superadmin middleware looks up the caller
+		select: vi.fn(() =>
+			selectReturning([{ id: mocks.userId, role: "superadmin" }]),
+		),
+		execute: vi.fn(),
+		update: vi.fn(() => ({
+			set: () => ({ where: () => ({ returning: mockReturning }) }),
+		})),
+		transaction: vi.fn(),
+	};
+
+	beforeEach(() => {
+		vi.clearAllMocks();
+		vi.mocked(getDb).mockReturnValue(mockDb as any);
+		mocks.userId = "reviewer-a";
+	});
+
+	describe("claimMatch", () => {
+		it("should claim an unclaimed pending item", async () => {
+			mockDb.execute.mockResolvedValueOnce([
+				{ claimed_until: claimedUntil },
+			]);
+
+			const result = await client.claimMatch({ queueId: "pmq_1" });
+
+			expect(result).toEqual({ claimed: true, claimedUntil });
+			expect(mockDb.execute).toHaveBeenCalledTimes(1);
+		});
+
+		it("should return the holder of an unexpired claim", async () => {
+			mockDb.execute.mockResolvedValueOnce([]).mockResolvedValueOnce([
+				{
+					status: "pending",
+					claimed_by: "reviewer-b",
+					claimed_until: claimedUntil,
+					name: "Reviewer B",
+				},
+			]);
+
+			const result = await client.claimMatch({ queueId: "pmq_1" });
+
+			expect(result).toEqual({
+				claimed: false,
+				claimedBy: { userId: "reviewer-b", name: "Reviewer B" },
+				claimedUntil,
+			});
+		});
+
+		it("should reject a missing item", async () => {
+			mockDb.execute.mockResolvedValueOnce([]).mockResolvedValueOnce([]);
+
+			expect(
+				await rejectionMessage(client.claimMatch({ queueId: "pmq_x" })),
+			).toBe("Queue item not found");
+		});
+
+		it("should reject an item already decided", async () => {
+			mockDb.execute
+				.mockResolvedValueOnce([])
+				.mockResolvedValueOnce([{ status: "approved", claimed_by: null }]);
+
+			expect(
+				await rejectionMessage(client.claimMatch({ queueId: "pmq_1" })),
+			).toBe("Queue item already processed");
+		});
+	});
+
+	describe("releaseMatch", () => {
+		it("should release the caller's claim", async () => {
+			mockReturning.mockResolvedValueOnce([{ id: "pmq_1" }]);
+
+			expect(await client.releaseMatch({ queueId: "pmq_1" })).toEqual({
+				released: true,
+			});
+		});
+
+		it("should release nothing of another reviewer's claim", async () => {
+			mockReturning.mockResolvedValueOnce([]);
+
+			expect(await client.releaseMatch({ queueId: "pmq_1" })).toEqual({
+				released: false,
+			});
+		});
+	});
+
+	describe("getPendingMatches", () => {
+		it("should list pending items with the holder of their claim", async () => {
+			const claim = {
+				userId: "reviewer-b",
+				name: "Reviewer B",
+				until: claimedUntil.toISOString(),
+			};
+			mockDb.execute.mockResolvedValueOnce([
+				{ id: "pmq_1", status: "pending", claim, created_at: "2026-01-01" },
+				{ id: "pmq_2", status: "pending", claim: null, created_at: "2026-01-02" },
+			]);
+
+			const result = await client.getPendingMatches({ limit: 20 });
+
+			expect(result.hasMore).toBe(false);
+			expect(result.items).toHaveLength(2);
+			expect(result.items[0].claim).toEqual(claim);
+			expect(result.items[1].claim).toBeNull();
+		});
+	});
+
+	describe("bulkApprove", () => {
+		it("should return the number of items approved", async () => {
+			mockDb.execute.mockResolvedValueOnce([{ approved: 2 }]);
+
+			expect(
+				await client.bulkApprove({ queueIds: ["pmq_1", "pmq_2", "pmq_3"] }),
+			).toEqual({ approved: 2 });
+		});
+	});
+
+	describe("decisions on claimed items", () => {
+		const pendingQueue = {
+			id: "pmq_1",
+			retailerItemId: "rit_1",
+			status: "pending",
+			version: 1,
+			linkedProductId: null,
+			claimedBy: "reviewer-b",
+			claimedUntil,
+		};
+
+		it("should refuse approving another reviewer's claimed item", async () => {
+			mockDb.transaction.mockImplementationOnce(async (run: any) =>
+				run({
+					select: () => selectReturning([pendingQueue]),
+					update: mockTxUpdate,
+				}),
+			);
+
+			expect(
+				await rejectionMessage(
+					client.approveMatch({ queueId: "pmq_1", version: 1 }),
+				),
+			).toBe("Queue item is being reviewed by another user");
+			expect(mockTxUpdate).not.toHaveBeenCalled();
+		});
+
+		it("should let the holder past the claim check", async () => {
+			mocks.userId = "reviewer-b";
+			mockDb.transaction.mockImplementationOnce(async (run: any) =>
+				run({
+					// Without a candidate approval stops right after the claim check
+					select: vi
+						.fn()
+						.mockReturnValueOnce(selectReturning([pendingQueue]))
+						.mockReturnValueOnce({
+							from: () => ({
+								where: () => ({ limit: async () => [] }),
+							}),
+						}),
+					update: mockTxUpdate,
+				}),
+			);
+
+			expect(
+				await rejectionMessage(
+					client.approveMatch({ queueId: "pmq_1", version: 1 }),
+				),
+			).toBe("No product specified or available");
+			expect(mockTxUpdate).not.toHaveBeenCalled();
+		});
+	});
+});
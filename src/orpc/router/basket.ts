@@ -49,6 +49,7 @@ const MissingItemSchema = z.object({
 	itemName: z.string(),
 	penalty: z.number(),
 	isOptional: z.boolean(),
+	reason: z.string().optional(),
 });
 
 const ItemPriceInfoSchema = z.object({
@@ -9,10 +9,12 @@ import * as priceService from "./price-service";
 import {
 	approveMatch,
 	bulkApprove,
+	claimMatch,
 	getPendingMatchCount,
 	getPendingMatches,
 	getStats,
 	rejectMatch,
+	releaseMatch,
 	resolveSuspicious,
 	searchProducts,
 } from "./products";
@@ -126,6 +128,8 @@ export default {
 			rejectMatch,
 			bulkApprove,
 			resolveSuspicious,
+			claimMatch,
+			releaseMatch,
 			searchProducts,
 			getStats,
 		},
@@ -36,6 +36,33 @@ const resolveSuspiciousSchema = z.object({
 	notes: z.string().optional(),
 });
 
+const claimMatchSchema = z.object({
+	queueId: z.string(),
+});
+
+// How long a claim lasts; the dashboard renews it while the item stays open
+const CLAIM_MINUTES = 10;
+
+// Reject decisions on a queue item another reviewer holds an unexpired claim on
+function assertNotClaimedByOther(
+	queue: { claimedBy: string | null; claimedUntil: Date | null },
+	userId: string | undefined,
+) {
+	if (
+		queue.claimedBy &&
+		queue.claimedBy !== userId &&
+		queue.claimedUntil &&
+		queue.claimedUntil > new Date()
+	) {
+		throw new Error("Queue item is being reviewed by another user");
+	}
+}
+
+// Rows of a raw query; postgres-js returns them as the result itself
+function resultRows(result: unknown): any[] {
+	return Array.isArray(result) ? result : ((result as any).rows ?? []);
+}
+
 // Get pending matches with candidates for review
 // Uses set-based query with JSON aggregation to avoid N+1
 export const getPendingMatches = superadminProcedure
@@ -71,6 +98,14 @@ export const getPendingMatches = superadminProcedure
 					q.review_notes,
 					q.created_at,
 					q.version,
+					-- Unexpired claim, so the dashboard shows who is reviewing the item
+					CASE WHEN q.claimed_until > now() THEN
+						jsonb_build_object(
+							'userId', q.claimed_by,
+							'name', cu.name,
+							'until', q.claimed_until
+						)
+					END as claim,
 					jsonb_build_object(
 						'id', ri.id,
 						'name', ri.name,
@@ -95,6 +130,12 @@ export const getPendingMatches = superadminProcedure
 									'rank', c.rank,
 									'matchType', c.match_type,
 									'flags', c.flags,
+									-- Distinct reviewers who rejected the product for other items
+									'rejectedBy', (
+										SELECT COUNT(DISTINCT COALESCE(r.rejected_by, r.retailer_item_id))
+										FROM product_match_rejections r
+										WHERE r.rejected_product_id = c.candidate_product_id
+									),
 									'product', jsonb_build_object(
 										'id', p.id,
 										'name', p.name,
@@ -110,15 +151,34 @@ export const getPendingMatches = superadminProcedure
 								AND c.rank <= 5
 						),
 						'[]'::jsonb
-					) as candidates
+					) as candidates,
+					COALESCE(
+						(
+							SELECT jsonb_agg(
+								jsonb_build_object(
+									'productId', r.rejected_product_id,
+									'productName', p.name,
+									'reason', r.reason,
+									'rejectedBy', u.name,
+									'createdAt', r.created_at
+								) ORDER BY r.created_at DESC
+							)
+							FROM product_match_rejections r
+							JOIN products p ON p.id = r.rejected_product_id
+							LEFT JOIN "user" u ON u.id = r.rejected_by
+							WHERE r.retailer_item_id = q.retailer_item_id
+						),
+						'[]'::jsonb
+					) as rejections
 				FROM pending q
 				JOIN retailer_items ri ON ri.id = q.retailer_item_id
 				JOIN chains ch ON ch.slug = ri.chain_slug
+				LEFT JOIN "user" cu ON cu.id = q.claimed_by
 				ORDER BY q.created_at
 			`,
 		);
 
-		const rows: any[] = (result as any).rows ?? [];
+		const rows = resultRows(result);
 
 		// Check if there are more results
 		const hasMore = rows.length > input.limit;
@@ -173,6 +233,7 @@ export const approveMatch = superadminProcedure
 
 			// Get the current user ID from context
 			const userId = (context as { user: { id: string } }).user?.id;
+			assertNotClaimedByOther(queue, userId);
 
 			// If productId is not provided, use the best candidate
 			const productId =
@@ -215,6 +276,8 @@ export const approveMatch = superadminProcedure
 					reviewedAt: new Date(),
 					reviewNotes: input.notes,
 					version: (queue.version ?? 1) + 1,
+					claimedBy: null,
+					claimedUntil: null,
 				})
 				.where(eq(productMatchQueue.id, input.queueId));
 
@@ -253,15 +316,29 @@ export const rejectMatch = superadminProcedure
 			}
 
 			const userId = (context as { user: { id: string } }).user?.id;
+			assertNotClaimedByOther(queue, userId);
 
 			if (input.productId) {
 				// Scoped rejection - reject specific candidate
-				await tx.insert(productMatchRejections).values({
-					retailerItemId: queue.retailerItemId,
-					rejectedProductId: input.productId,
-					reason: input.reason ?? "rejected",
-					rejectedBy: userId,
-				});
+				await tx
+					.insert(productMatchRejections)
+					.values({
+						retailerItemId: queue.retailerItemId,
+						rejectedProductId: input.productId,
+						reason: input.reason ?? "rejected",
+						rejectedBy: userId,
+					})
+					.onConflictDoNothing();
+
+				// Matching never suggests the pair again, so drop the candidate
+				await tx
+					.delete(productMatchCandidates)
+					.where(
+						and(
+							eq(productMatchCandidates.retailerItemId, queue.retailerItemId),
+							eq(productMatchCandidates.candidateProductId, input.productId),
+						),
+					);
 
 				// Check if there are still candidates left
 				const remaining = await tx
@@ -282,6 +359,8 @@ export const rejectMatch = superadminProcedure
 							reviewedAt: new Date(),
 							reviewNotes: input.reason,
 							version: (queue.version ?? 1) + 1,
+							claimedBy: null,
+							claimedUntil: null,
 						})
 						.where(eq(productMatchQueue.id, input.queueId));
 				}
@@ -296,6 +375,8 @@ export const rejectMatch = superadminProcedure
 						reviewedAt: new Date(),
 						reviewNotes: input.reason,
 						version: (queue.version ?? 1) + 1,
+						claimedBy: null,
+						claimedUntil: null,
 					})
 					.where(eq(productMatchQueue.id, input.queueId));
 			}
@@ -335,6 +416,8 @@ export const bulkApprove = superadminProcedure
 					JOIN product_match_candidates c ON c.retailer_item_id = q.retailer_item_id
 					WHERE q.id = ANY(${input.queueIds}::text[])
 						AND q.status = 'pending'
+						-- Items another reviewer has claimed are left to them
+						AND (q.claimed_by IS NULL OR q.claimed_by = ${userId} OR q.claimed_until <= now())
 						AND c.rank = 1
 					ORDER BY q.retailer_item_id, c.rank
 				),
@@ -352,7 +435,9 @@ export const bulkApprove = superadminProcedure
 						linked_product_id = bc.candidate_product_id,
 						reviewed_by = ${userId},
 						reviewed_at = now(),
-						version = version + 1
+						version = version + 1,
+						claimed_by = NULL,
+						claimed_until = NULL
 					FROM best_candidates bc
 					WHERE q.id = bc.queue_id
 					RETURNING q.id
@@ -361,7 +446,7 @@ export const bulkApprove = superadminProcedure
 			`,
 		);
 
-		const rows: any[] = (bulkResult as any).rows ?? [];
+		const rows = resultRows(bulkResult);
 		return { approved: rows[0]?.approved ?? 0 };
 	});
 
@@ -387,6 +472,7 @@ export const resolveSuspicious = superadminProcedure
 			}
 
 			const userId = (context as { user: { id: string } }).user?.id;
+			assertNotClaimedByOther(queue, userId);
 
 			// Create link
 			await tx.insert(productLinks).values({
@@ -406,6 +492,8 @@ export const resolveSuspicious = superadminProcedure
 					reviewedAt: new Date(),
 					reviewNotes: input.notes,
 					version: (queue.version ?? 1) + 1,
+					claimedBy: null,
+					claimedUntil: null,
 				})
 				.where(eq(productMatchQueue.id, input.queueId));
 
@@ -425,6 +513,74 @@ export const resolveSuspicious = superadminProcedure
 		});
 	});
 
+// Claim a pending queue item while reviewing it, or renew the caller's claim.
+// Returns the holder instead when another reviewer's claim has not expired.
+export const claimMatch = superadminProcedure
+	.input(claimMatchSchema)
+	.handler(async ({ input, context }) => {
+		const db = getDb();
+		const userId = (context as { user: { id: string } }).user?.id;
+
+		const claimResult = await db.execute(
+			sql`
+				UPDATE product_match_queue
+				SET claimed_by = ${userId},
+					claimed_until = now() + make_interval(mins => ${CLAIM_MINUTES})
+				WHERE id = ${input.queueId}
+					AND status = 'pending'
+					AND (claimed_by IS NULL OR claimed_by = ${userId} OR claimed_until <= now())
+				RETURNING claimed_until
+			`,
+		);
+		const claimed = resultRows(claimResult);
+		if (claimed.length > 0) {
+			return { claimed: true, claimedUntil: claimed[0].claimed_until };
+		}
+
+		const holderResult = await db.execute(
+			sql`
+				SELECT q.status, q.claimed_by, q.claimed_until, u.name
+				FROM product_match_queue q
+				LEFT JOIN "user" u ON u.id = q.claimed_by
+				WHERE q.id = ${input.queueId}
+			`,
+		);
+		const [holder] = resultRows(holderResult);
+		if (!holder) {
+			throw new Error("Queue item not found");
+		}
+		if (holder.status !== "pending") {
+			throw new Error("Queue item already processed");
+		}
+
+		return {
+			claimed: false,
+			claimedBy: { userId: holder.claimed_by, name: holder.name },
+			claimedUntil: holder.claimed_until,
+		};
+	});
+
+// Release the caller's claim on a queue item, e.g. when closing it undecided
+export const releaseMatch = superadminProcedure
+	.input(claimMatchSchema)
+	.handler(async ({ input, context }) => {
+		const db = getDb();
+		const userId = (context as { user: { id: string } }).user?.id;
+
+		const released = await db
+			.update(productMatchQueue)
+			.set({ claimedBy: null, claimedUntil: null })
+			.where(
+				and(
+					eq(productMatchQueue.id, input.queueId),
+					eq(productMatchQueue.claimedBy, userId),
+				),
+			)
+			.returning({ id: productMatchQueue.id });
+
+		return { released: released.length > 0 };
+	});
+
 // Search products for manual linking
 export const searchProducts = superadminProcedure
 	.input(